@@ -281,8 +281,11 @@ type HealthCheckConfig struct {
 	IntervalSeconds int32                  `protobuf:"varint,3,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
 	TimeoutSeconds  int32                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
 	Retries         int32                  `protobuf:"varint,5,opt,name=retries,proto3" json:"retries,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Command run in the container; exit code 0 means healthy. Used
+	// instead of an HTTP or TCP check when set.
+	Command       []string `protobuf:"bytes,6,rep,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HealthCheckConfig) Reset() {
@@ -350,6 +353,13 @@ func (x *HealthCheckConfig) GetRetries() int32 {
 	return 0
 }
 
+func (x *HealthCheckConfig) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
 // DeployResponse is returned after a deploy request is processed.
 type DeployResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -789,13 +799,14 @@ const file_api_proto_agent_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"P\n" +
 	"\vPortMapping\x12%\n" +
 	"\x0econtainer_port\x18\x01 \x01(\x05R\rcontainerPort\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\"\xa9\x01\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\"\xc3\x01\n" +
 	"\x11HealthCheckConfig\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12)\n" +
 	"\x10interval_seconds\x18\x03 \x01(\x05R\x0fintervalSeconds\x12'\n" +
 	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\x12\x18\n" +
-	"\aretries\x18\x05 \x01(\x05R\aretries\"D\n" +
+	"\aretries\x18\x05 \x01(\x05R\aretries\x12\x18\n" +
+	"\acommand\x18\x06 \x03(\tR\acommand\"D\n" +
 	"\x0eDeployResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"2\n" +
@@ -31,6 +31,8 @@ const (
 	CommandType_COMMAND_RESTART       CommandType = 3
 	CommandType_COMMAND_UPDATE_CONFIG CommandType = 4
 	CommandType_COMMAND_STREAM_LOGS   CommandType = 5
+	CommandType_COMMAND_DEBUG         CommandType = 6
+	CommandType_COMMAND_STOP_DEBUG    CommandType = 7
 )
 
 // Enum value maps for CommandType.
@@ -42,6 +44,8 @@ var (
 		3: "COMMAND_RESTART",
 		4: "COMMAND_UPDATE_CONFIG",
 		5: "COMMAND_STREAM_LOGS",
+		6: "COMMAND_DEBUG",
+		7: "COMMAND_STOP_DEBUG",
 	}
 	CommandType_value = map[string]int32{
 		"COMMAND_UNKNOWN":       0,
@@ -50,6 +54,8 @@ var (
 		"COMMAND_RESTART":       3,
 		"COMMAND_UPDATE_CONFIG": 4,
 		"COMMAND_STREAM_LOGS":   5,
+		"COMMAND_DEBUG":         6,
+		"COMMAND_STOP_DEBUG":    7,
 	}
 )
 
@@ -397,9 +403,14 @@ type NodeInfo struct {
 	ActiveDeployments int32                  `protobuf:"varint,8,opt,name=active_deployments,json=activeDeployments,proto3" json:"active_deployments,omitempty"`
 	// Disk metrics for specific paths (nix store, container storage)
 	// **Validates: Requirements 20.1**
-	DiskMetrics   *NodeDiskMetrics `protobuf:"bytes,9,opt,name=disk_metrics,json=diskMetrics,proto3" json:"disk_metrics,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	DiskMetrics *NodeDiskMetrics `protobuf:"bytes,9,opt,name=disk_metrics,json=diskMetrics,proto3" json:"disk_metrics,omitempty"`
+	// Capabilities the node supports, such as "systemd-units". Agents that
+	// report none are assumed to have the default agent capabilities.
+	Capabilities []string `protobuf:"bytes,10,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// When the certificate the agent serves with expires, if it uses TLS
+	CertificateExpiresAt *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=certificate_expires_at,json=certificateExpiresAt,proto3" json:"certificate_expires_at,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *NodeInfo) Reset() {
@@ -495,6 +506,20 @@ func (x *NodeInfo) GetDiskMetrics() *NodeDiskMetrics {
 	return nil
 }
 
+func (x *NodeInfo) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *NodeInfo) GetCertificateExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CertificateExpiresAt
+	}
+	return nil
+}
+
 type ResourceMetrics struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	CpuTotal        float64                `protobuf:"fixed64,1,opt,name=cpu_total,json=cpuTotal,proto3" json:"cpu_total,omitempty"`
@@ -1068,6 +1093,8 @@ type DeploymentCommand struct {
 	//	*DeploymentCommand_Restart
 	//	*DeploymentCommand_UpdateConfig
 	//	*DeploymentCommand_StreamLogs
+	//	*DeploymentCommand_Debug
+	//	*DeploymentCommand_StopDebug
 	Command       isDeploymentCommand_Command `protobuf_oneof:"command"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1176,6 +1203,24 @@ func (x *DeploymentCommand) GetStreamLogs() *CPLogStreamRequest {
 	return nil
 }
 
+func (x *DeploymentCommand) GetDebug() *CPDebugRequest {
+	if x != nil {
+		if x, ok := x.Command.(*DeploymentCommand_Debug); ok {
+			return x.Debug
+		}
+	}
+	return nil
+}
+
+func (x *DeploymentCommand) GetStopDebug() *CPStopDebugRequest {
+	if x != nil {
+		if x, ok := x.Command.(*DeploymentCommand_StopDebug); ok {
+			return x.StopDebug
+		}
+	}
+	return nil
+}
+
 type isDeploymentCommand_Command interface {
 	isDeploymentCommand_Command()
 }
@@ -1200,6 +1245,14 @@ type DeploymentCommand_StreamLogs struct {
 	StreamLogs *CPLogStreamRequest `protobuf:"bytes,14,opt,name=stream_logs,json=streamLogs,proto3,oneof"`
 }
 
+type DeploymentCommand_Debug struct {
+	Debug *CPDebugRequest `protobuf:"bytes,15,opt,name=debug,proto3,oneof"`
+}
+
+type DeploymentCommand_StopDebug struct {
+	StopDebug *CPStopDebugRequest `protobuf:"bytes,16,opt,name=stop_debug,json=stopDebug,proto3,oneof"`
+}
+
 func (*DeploymentCommand_Deploy) isDeploymentCommand_Command() {}
 
 func (*DeploymentCommand_Stop) isDeploymentCommand_Command() {}
@@ -1210,6 +1263,10 @@ func (*DeploymentCommand_UpdateConfig) isDeploymentCommand_Command() {}
 
 func (*DeploymentCommand_StreamLogs) isDeploymentCommand_Command() {}
 
+func (*DeploymentCommand_Debug) isDeploymentCommand_Command() {}
+
+func (*DeploymentCommand_StopDebug) isDeploymentCommand_Command() {}
+
 type CPDeployRequest struct {
 	state        protoimpl.MessageState `protogen:"open.v1"`
 	DeploymentId string                 `protobuf:"bytes,1,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
@@ -1368,15 +1425,32 @@ func (x *CPResourceSpec) GetMemory() string {
 }
 
 type CPDeploymentConfig struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Resources     *CPResourceSpec        `protobuf:"bytes,1,opt,name=resources,proto3" json:"resources,omitempty"`
-	Replicas      int32                  `protobuf:"varint,2,opt,name=replicas,proto3" json:"replicas,omitempty"`
-	EnvVars       map[string]string      `protobuf:"bytes,3,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	DependsOn     []string               `protobuf:"bytes,4,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
-	HealthCheck   *CPHealthCheckConfig   `protobuf:"bytes,5,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
-	Port          int32                  `protobuf:"varint,6,opt,name=port,proto3" json:"port,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Resources   *CPResourceSpec        `protobuf:"bytes,1,opt,name=resources,proto3" json:"resources,omitempty"`
+	Replicas    int32                  `protobuf:"varint,2,opt,name=replicas,proto3" json:"replicas,omitempty"`
+	EnvVars     map[string]string      `protobuf:"bytes,3,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DependsOn   []string               `protobuf:"bytes,4,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	HealthCheck *CPHealthCheckConfig   `protobuf:"bytes,5,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
+	Port        int32                  `protobuf:"varint,6,opt,name=port,proto3" json:"port,omitempty"`
+	// Outbound network policy, enforced by the agent's network config.
+	// Unset allows all egress.
+	Egress *CPEgressPolicy `protobuf:"bytes,7,opt,name=egress,proto3" json:"egress,omitempty"`
+	// Command replaces the artifact's entrypoint. Set for cron service runs,
+	// which run once and report STATUS_STOPPED when the command exits 0 and
+	// STATUS_FAILED otherwise; agents must not restart them.
+	Command []string `protobuf:"bytes,8,rep,name=command,proto3" json:"command,omitempty"`
+	// Runtime driver for pure-nix artifacts: "container" (the default when
+	// empty) or "systemd" for a sandboxed systemd unit. Only sent to nodes
+	// that report the "systemd-units" capability.
+	Runtime string `protobuf:"bytes,9,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// Set on canary deployments: the deployment the canary is compared with.
+	// The agent keeps the baseline running and sends canary_weight percent
+	// of the service's requests to the canary until the control plane stops
+	// one of them.
+	CanaryBaselineId string `protobuf:"bytes,10,opt,name=canary_baseline_id,json=canaryBaselineId,proto3" json:"canary_baseline_id,omitempty"`
+	CanaryWeight     int32  `protobuf:"varint,11,opt,name=canary_weight,json=canaryWeight,proto3" json:"canary_weight,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CPDeploymentConfig) Reset() {
@@ -1451,6 +1525,164 @@ func (x *CPDeploymentConfig) GetPort() int32 {
 	return 0
 }
 
+func (x *CPDeploymentConfig) GetEgress() *CPEgressPolicy {
+	if x != nil {
+		return x.Egress
+	}
+	return nil
+}
+
+func (x *CPDeploymentConfig) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *CPDeploymentConfig) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *CPDeploymentConfig) GetCanaryBaselineId() string {
+	if x != nil {
+		return x.CanaryBaselineId
+	}
+	return ""
+}
+
+func (x *CPDeploymentConfig) GetCanaryWeight() int32 {
+	if x != nil {
+		return x.CanaryWeight
+	}
+	return 0
+}
+
+// CPEgressPolicy restricts outbound connections from a deployment's containers.
+type CPEgressPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// deny_all blocks all outbound traffic except to the allowlisted destinations
+	DenyAll       bool            `protobuf:"varint,1,opt,name=deny_all,json=denyAll,proto3" json:"deny_all,omitempty"`
+	Allow         []*CPEgressRule `protobuf:"bytes,2,rep,name=allow,proto3" json:"allow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPEgressPolicy) Reset() {
+	*x = CPEgressPolicy{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPEgressPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPEgressPolicy) ProtoMessage() {}
+
+func (x *CPEgressPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPEgressPolicy.ProtoReflect.Descriptor instead.
+func (*CPEgressPolicy) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CPEgressPolicy) GetDenyAll() bool {
+	if x != nil {
+		return x.DenyAll
+	}
+	return false
+}
+
+func (x *CPEgressPolicy) GetAllow() []*CPEgressRule {
+	if x != nil {
+		return x.Allow
+	}
+	return nil
+}
+
+// CPEgressRule allows outbound traffic to a host or CIDR.
+type CPEgressRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`           // DNS name, resolved by the agent; may start with "*."
+	Cidr          string                 `protobuf:"bytes,2,opt,name=cidr,proto3" json:"cidr,omitempty"`           // e.g., "10.0.0.0/8"
+	Ports         []int32                `protobuf:"varint,3,rep,packed,name=ports,proto3" json:"ports,omitempty"` // Empty allows all ports
+	Protocol      string                 `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`   // "tcp" or "udp", empty allows both
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPEgressRule) Reset() {
+	*x = CPEgressRule{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPEgressRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPEgressRule) ProtoMessage() {}
+
+func (x *CPEgressRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPEgressRule.ProtoReflect.Descriptor instead.
+func (*CPEgressRule) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CPEgressRule) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *CPEgressRule) GetCidr() string {
+	if x != nil {
+		return x.Cidr
+	}
+	return ""
+}
+
+func (x *CPEgressRule) GetPorts() []int32 {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *CPEgressRule) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
 type CPHealthCheckConfig struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Path               string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
@@ -1459,13 +1691,16 @@ type CPHealthCheckConfig struct {
 	TimeoutSeconds     int32                  `protobuf:"varint,4,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
 	HealthyThreshold   int32                  `protobuf:"varint,5,opt,name=healthy_threshold,json=healthyThreshold,proto3" json:"healthy_threshold,omitempty"`
 	UnhealthyThreshold int32                  `protobuf:"varint,6,opt,name=unhealthy_threshold,json=unhealthyThreshold,proto3" json:"unhealthy_threshold,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// Command run in the container; exit code 0 means healthy. Used
+	// instead of an HTTP or TCP check when set.
+	Command       []string `protobuf:"bytes,7,rep,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CPHealthCheckConfig) Reset() {
 	*x = CPHealthCheckConfig{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[16]
+	mi := &file_api_proto_controlplane_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1477,7 +1712,7 @@ func (x *CPHealthCheckConfig) String() string {
 func (*CPHealthCheckConfig) ProtoMessage() {}
 
 func (x *CPHealthCheckConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[16]
+	mi := &file_api_proto_controlplane_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1490,7 +1725,7 @@ func (x *CPHealthCheckConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPHealthCheckConfig.ProtoReflect.Descriptor instead.
 func (*CPHealthCheckConfig) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{16}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *CPHealthCheckConfig) GetPath() string {
@@ -1535,6 +1770,13 @@ func (x *CPHealthCheckConfig) GetUnhealthyThreshold() int32 {
 	return 0
 }
 
+func (x *CPHealthCheckConfig) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
 type CPStopRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	DeploymentId   string                 `protobuf:"bytes,1,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
@@ -1546,7 +1788,7 @@ type CPStopRequest struct {
 
 func (x *CPStopRequest) Reset() {
 	*x = CPStopRequest{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[17]
+	mi := &file_api_proto_controlplane_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1558,7 +1800,7 @@ func (x *CPStopRequest) String() string {
 func (*CPStopRequest) ProtoMessage() {}
 
 func (x *CPStopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[17]
+	mi := &file_api_proto_controlplane_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1571,7 +1813,7 @@ func (x *CPStopRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPStopRequest.ProtoReflect.Descriptor instead.
 func (*CPStopRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{17}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *CPStopRequest) GetDeploymentId() string {
@@ -1604,7 +1846,7 @@ type CPRestartRequest struct {
 
 func (x *CPRestartRequest) Reset() {
 	*x = CPRestartRequest{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[18]
+	mi := &file_api_proto_controlplane_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1616,7 +1858,7 @@ func (x *CPRestartRequest) String() string {
 func (*CPRestartRequest) ProtoMessage() {}
 
 func (x *CPRestartRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[18]
+	mi := &file_api_proto_controlplane_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1629,7 +1871,7 @@ func (x *CPRestartRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPRestartRequest.ProtoReflect.Descriptor instead.
 func (*CPRestartRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{18}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *CPRestartRequest) GetDeploymentId() string {
@@ -1649,7 +1891,7 @@ type CPUpdateConfigRequest struct {
 
 func (x *CPUpdateConfigRequest) Reset() {
 	*x = CPUpdateConfigRequest{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[19]
+	mi := &file_api_proto_controlplane_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1661,7 +1903,7 @@ func (x *CPUpdateConfigRequest) String() string {
 func (*CPUpdateConfigRequest) ProtoMessage() {}
 
 func (x *CPUpdateConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[19]
+	mi := &file_api_proto_controlplane_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1674,7 +1916,7 @@ func (x *CPUpdateConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPUpdateConfigRequest.ProtoReflect.Descriptor instead.
 func (*CPUpdateConfigRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{19}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *CPUpdateConfigRequest) GetDeploymentId() string {
@@ -1705,7 +1947,7 @@ type CPLogStreamRequest struct {
 
 func (x *CPLogStreamRequest) Reset() {
 	*x = CPLogStreamRequest{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[20]
+	mi := &file_api_proto_controlplane_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1717,7 +1959,7 @@ func (x *CPLogStreamRequest) String() string {
 func (*CPLogStreamRequest) ProtoMessage() {}
 
 func (x *CPLogStreamRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[20]
+	mi := &file_api_proto_controlplane_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1730,7 +1972,7 @@ func (x *CPLogStreamRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPLogStreamRequest.ProtoReflect.Descriptor instead.
 func (*CPLogStreamRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{20}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *CPLogStreamRequest) GetDeploymentId() string {
@@ -1775,24 +2017,164 @@ func (x *CPLogStreamRequest) GetLevelFilter() CPLogLevel {
 	return CPLogLevel_CP_LOG_UNKNOWN
 }
 
-type StatusReport struct {
+// CPDebugRequest starts a debug container from image on the node, sharing
+// the PID, network and IPC namespaces of the deployment's container, named
+// "narvana-debug-<session_id>". With an empty command the container idles
+// so it can be attached to. The agent removes it at expires_at even if no
+// stop command arrives.
+type CPDebugRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	DeploymentId  string                 `protobuf:"bytes,2,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
-	CommandId     string                 `protobuf:"bytes,3,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
-	Status        DeploymentStatus       `protobuf:"varint,4,opt,name=status,proto3,enum=controlplane.DeploymentStatus" json:"status,omitempty"`
-	ContainerId   string                 `protobuf:"bytes,5,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	ExitCode      int32                  `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,8,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	ResourceUsage *ResourceUsage         `protobuf:"bytes,9,opt,name=resource_usage,json=resourceUsage,proto3" json:"resource_usage,omitempty"`
+	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Command       []string               `protobuf:"bytes,4,rep,name=command,proto3" json:"command,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *CPDebugRequest) Reset() {
+	*x = CPDebugRequest{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPDebugRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPDebugRequest) ProtoMessage() {}
+
+func (x *CPDebugRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPDebugRequest.ProtoReflect.Descriptor instead.
+func (*CPDebugRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CPDebugRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CPDebugRequest) GetDeploymentId() string {
+	if x != nil {
+		return x.DeploymentId
+	}
+	return ""
+}
+
+func (x *CPDebugRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *CPDebugRequest) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *CPDebugRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// CPStopDebugRequest removes a session's debug container.
+type CPStopDebugRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPStopDebugRequest) Reset() {
+	*x = CPStopDebugRequest{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPStopDebugRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPStopDebugRequest) ProtoMessage() {}
+
+func (x *CPStopDebugRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPStopDebugRequest.ProtoReflect.Descriptor instead.
+func (*CPStopDebugRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CPStopDebugRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type StatusReport struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	NodeId       string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	DeploymentId string                 `protobuf:"bytes,2,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
+	CommandId    string                 `protobuf:"bytes,3,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Status       DeploymentStatus       `protobuf:"varint,4,opt,name=status,proto3,enum=controlplane.DeploymentStatus" json:"status,omitempty"`
+	ContainerId  string                 `protobuf:"bytes,5,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	StartedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	ExitCode     int32                  `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,8,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// Container resource usage of the deployment. Agents resend the current
+	// status with fresh usage about every 15 seconds while it runs; each report
+	// is stored as a metrics sample.
+	ResourceUsage *ResourceUsage `protobuf:"bytes,9,opt,name=resource_usage,json=resourceUsage,proto3" json:"resource_usage,omitempty"`
+	// Outbound connections blocked by the egress policy since the deployment
+	// started. Agents may resend the current status to update these counters.
+	EgressViolations []*EgressViolation `protobuf:"bytes,10,rep,name=egress_violations,json=egressViolations,proto3" json:"egress_violations,omitempty"`
+	// Readiness probe results of a deployment with a health check. Agents
+	// report STATUS_STARTING with the latest probe's result until a probe
+	// passes, then STATUS_RUNNING with health_check_passed set. After
+	// unhealthy_threshold failed probes in a row they report STATUS_FAILED
+	// with the probe output and recent container logs in error_message.
+	HealthCheckPassed   bool   `protobuf:"varint,11,opt,name=health_check_passed,json=healthCheckPassed,proto3" json:"health_check_passed,omitempty"`
+	HealthCheckFailures int32  `protobuf:"varint,12,opt,name=health_check_failures,json=healthCheckFailures,proto3" json:"health_check_failures,omitempty"` // Consecutive failed probes
+	HealthCheckOutput   string `protobuf:"bytes,13,opt,name=health_check_output,json=healthCheckOutput,proto3" json:"health_check_output,omitempty"`        // Result of the latest probe
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
 func (x *StatusReport) Reset() {
 	*x = StatusReport{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[21]
+	mi := &file_api_proto_controlplane_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1804,7 +2186,7 @@ func (x *StatusReport) String() string {
 func (*StatusReport) ProtoMessage() {}
 
 func (x *StatusReport) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[21]
+	mi := &file_api_proto_controlplane_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1817,7 +2199,7 @@ func (x *StatusReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusReport.ProtoReflect.Descriptor instead.
 func (*StatusReport) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{21}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *StatusReport) GetNodeId() string {
@@ -1883,19 +2265,53 @@ func (x *StatusReport) GetResourceUsage() *ResourceUsage {
 	return nil
 }
 
+func (x *StatusReport) GetEgressViolations() []*EgressViolation {
+	if x != nil {
+		return x.EgressViolations
+	}
+	return nil
+}
+
+func (x *StatusReport) GetHealthCheckPassed() bool {
+	if x != nil {
+		return x.HealthCheckPassed
+	}
+	return false
+}
+
+func (x *StatusReport) GetHealthCheckFailures() int32 {
+	if x != nil {
+		return x.HealthCheckFailures
+	}
+	return 0
+}
+
+func (x *StatusReport) GetHealthCheckOutput() string {
+	if x != nil {
+		return x.HealthCheckOutput
+	}
+	return ""
+}
+
+// ResourceUsage is a deployment's resource usage summed across its
+// containers. Network byte counters are cumulative since the deployment
+// started.
 type ResourceUsage struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	CpuPercent     float64                `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
 	MemoryBytes    int64                  `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
 	NetworkRxBytes int64                  `protobuf:"varint,3,opt,name=network_rx_bytes,json=networkRxBytes,proto3" json:"network_rx_bytes,omitempty"`
 	NetworkTxBytes int64                  `protobuf:"varint,4,opt,name=network_tx_bytes,json=networkTxBytes,proto3" json:"network_tx_bytes,omitempty"`
+	Requests       int64                  `protobuf:"varint,5,opt,name=requests,proto3" json:"requests,omitempty"`                               // Cumulative HTTP requests served; 0 when the agent does not count them
+	Errors         int64                  `protobuf:"varint,6,opt,name=errors,proto3" json:"errors,omitempty"`                                   // Cumulative 5xx responses among them
+	LatencyMsSum   int64                  `protobuf:"varint,7,opt,name=latency_ms_sum,json=latencyMsSum,proto3" json:"latency_ms_sum,omitempty"` // Cumulative response time of them, in milliseconds
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ResourceUsage) Reset() {
 	*x = ResourceUsage{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[22]
+	mi := &file_api_proto_controlplane_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1907,7 +2323,7 @@ func (x *ResourceUsage) String() string {
 func (*ResourceUsage) ProtoMessage() {}
 
 func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[22]
+	mi := &file_api_proto_controlplane_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1920,7 +2336,7 @@ func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
 func (*ResourceUsage) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{22}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ResourceUsage) GetCpuPercent() float64 {
@@ -1951,6 +2367,104 @@ func (x *ResourceUsage) GetNetworkTxBytes() int64 {
 	return 0
 }
 
+func (x *ResourceUsage) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetErrors() int64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetLatencyMsSum() int64 {
+	if x != nil {
+		return x.LatencyMsSum
+	}
+	return 0
+}
+
+// EgressViolation counts blocked outbound connections to one destination.
+type EgressViolation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Destination   string                 `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"` // Destination IP address
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Count         int64                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"` // Cumulative since the deployment started
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EgressViolation) Reset() {
+	*x = EgressViolation{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EgressViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EgressViolation) ProtoMessage() {}
+
+func (x *EgressViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EgressViolation.ProtoReflect.Descriptor instead.
+func (*EgressViolation) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *EgressViolation) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *EgressViolation) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *EgressViolation) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *EgressViolation) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *EgressViolation) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
 type StatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
@@ -1960,7 +2474,7 @@ type StatusResponse struct {
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[23]
+	mi := &file_api_proto_controlplane_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1972,7 +2486,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[23]
+	mi := &file_api_proto_controlplane_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1985,7 +2499,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{23}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *StatusResponse) GetAcknowledged() bool {
@@ -1996,21 +2510,22 @@ func (x *StatusResponse) GetAcknowledged() bool {
 }
 
 type CPLogEntry struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	DeploymentId  string                 `protobuf:"bytes,1,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
-	StreamId      string                 `protobuf:"bytes,2,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
-	ServiceName   string                 `protobuf:"bytes,3,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Level         CPLogLevel             `protobuf:"varint,5,opt,name=level,proto3,enum=controlplane.CPLogLevel" json:"level,omitempty"`
-	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	DeploymentId string                 `protobuf:"bytes,1,opt,name=deployment_id,json=deploymentId,proto3" json:"deployment_id,omitempty"`
+	StreamId     string                 `protobuf:"bytes,2,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	ServiceName  string                 `protobuf:"bytes,3,opt,name=service_name,json=serviceName,proto3" json:"service_name,omitempty"`
+	Timestamp    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Level        CPLogLevel             `protobuf:"varint,5,opt,name=level,proto3,enum=controlplane.CPLogLevel" json:"level,omitempty"`
+	Message      string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	// "stream" is the output the line was written to, stdout or stderr
+	Metadata      map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CPLogEntry) Reset() {
 	*x = CPLogEntry{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[24]
+	mi := &file_api_proto_controlplane_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2022,7 +2537,7 @@ func (x *CPLogEntry) String() string {
 func (*CPLogEntry) ProtoMessage() {}
 
 func (x *CPLogEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[24]
+	mi := &file_api_proto_controlplane_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2035,7 +2550,7 @@ func (x *CPLogEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CPLogEntry.ProtoReflect.Descriptor instead.
 func (*CPLogEntry) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{24}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *CPLogEntry) GetDeploymentId() string {
@@ -2096,7 +2611,7 @@ type PushLogsResponse struct {
 
 func (x *PushLogsResponse) Reset() {
 	*x = PushLogsResponse{}
-	mi := &file_api_proto_controlplane_proto_msgTypes[25]
+	mi := &file_api_proto_controlplane_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2108,7 +2623,7 @@ func (x *PushLogsResponse) String() string {
 func (*PushLogsResponse) ProtoMessage() {}
 
 func (x *PushLogsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_controlplane_proto_msgTypes[25]
+	mi := &file_api_proto_controlplane_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2121,7 +2636,7 @@ func (x *PushLogsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PushLogsResponse.ProtoReflect.Descriptor instead.
 func (*PushLogsResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{25}
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *PushLogsResponse) GetEntriesReceived() int64 {
@@ -2131,6 +2646,190 @@ func (x *PushLogsResponse) GetEntriesReceived() int64 {
 	return 0
 }
 
+// AgentHeartbeat is sent by an agent on its NodeAgent stream every heartbeat
+// interval, reporting the node's capacity and load.
+type AgentHeartbeat struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	NodeId      string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Resources   *ResourceMetrics       `protobuf:"bytes,2,opt,name=resources,proto3" json:"resources,omitempty"`
+	DiskMetrics *NodeDiskMetrics       `protobuf:"bytes,3,opt,name=disk_metrics,json=diskMetrics,proto3" json:"disk_metrics,omitempty"`
+	// Load averages over the last 1, 5 and 15 minutes
+	Load1             float64                `protobuf:"fixed64,4,opt,name=load1,proto3" json:"load1,omitempty"`
+	Load5             float64                `protobuf:"fixed64,5,opt,name=load5,proto3" json:"load5,omitempty"`
+	Load15            float64                `protobuf:"fixed64,6,opt,name=load15,proto3" json:"load15,omitempty"`
+	ActiveDeployments int32                  `protobuf:"varint,7,opt,name=active_deployments,json=activeDeployments,proto3" json:"active_deployments,omitempty"`
+	Draining          bool                   `protobuf:"varint,8,opt,name=draining,proto3" json:"draining,omitempty"`
+	SentAt            *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	// When the certificate the agent serves with expires, if it uses TLS
+	CertificateExpiresAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=certificate_expires_at,json=certificateExpiresAt,proto3" json:"certificate_expires_at,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AgentHeartbeat) Reset() {
+	*x = AgentHeartbeat{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentHeartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentHeartbeat) ProtoMessage() {}
+
+func (x *AgentHeartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentHeartbeat.ProtoReflect.Descriptor instead.
+func (*AgentHeartbeat) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AgentHeartbeat) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *AgentHeartbeat) GetResources() *ResourceMetrics {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *AgentHeartbeat) GetDiskMetrics() *NodeDiskMetrics {
+	if x != nil {
+		return x.DiskMetrics
+	}
+	return nil
+}
+
+func (x *AgentHeartbeat) GetLoad1() float64 {
+	if x != nil {
+		return x.Load1
+	}
+	return 0
+}
+
+func (x *AgentHeartbeat) GetLoad5() float64 {
+	if x != nil {
+		return x.Load5
+	}
+	return 0
+}
+
+func (x *AgentHeartbeat) GetLoad15() float64 {
+	if x != nil {
+		return x.Load15
+	}
+	return 0
+}
+
+func (x *AgentHeartbeat) GetActiveDeployments() int32 {
+	if x != nil {
+		return x.ActiveDeployments
+	}
+	return 0
+}
+
+func (x *AgentHeartbeat) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *AgentHeartbeat) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+func (x *AgentHeartbeat) GetCertificateExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CertificateExpiresAt
+	}
+	return nil
+}
+
+// AgentHeartbeatAck answers each heartbeat with the node's health as the
+// control plane sees it.
+type AgentHeartbeatAck struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Healthy bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Headroom from 0 (saturated) to 100 (idle)
+	HealthScore int32 `protobuf:"varint,2,opt,name=health_score,json=healthScore,proto3" json:"health_score,omitempty"`
+	// How often the agent should send heartbeats
+	HeartbeatIntervalSeconds int32 `protobuf:"varint,3,opt,name=heartbeat_interval_seconds,json=heartbeatIntervalSeconds,proto3" json:"heartbeat_interval_seconds,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *AgentHeartbeatAck) Reset() {
+	*x = AgentHeartbeatAck{}
+	mi := &file_api_proto_controlplane_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentHeartbeatAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentHeartbeatAck) ProtoMessage() {}
+
+func (x *AgentHeartbeatAck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_controlplane_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentHeartbeatAck.ProtoReflect.Descriptor instead.
+func (*AgentHeartbeatAck) Descriptor() ([]byte, []int) {
+	return file_api_proto_controlplane_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *AgentHeartbeatAck) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *AgentHeartbeatAck) GetHealthScore() int32 {
+	if x != nil {
+		return x.HealthScore
+	}
+	return 0
+}
+
+func (x *AgentHeartbeatAck) GetHeartbeatIntervalSeconds() int32 {
+	if x != nil {
+		return x.HeartbeatIntervalSeconds
+	}
+	return 0
+}
+
 var File_api_proto_controlplane_proto protoreflect.FileDescriptor
 
 const file_api_proto_controlplane_proto_rawDesc = "" +
@@ -2143,7 +2842,7 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\rServingStatus\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x02\"\xe7\x02\n" +
+	"\vNOT_SERVING\x10\x02\"\xdd\x03\n" +
 	"\bNodeInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
 	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x18\n" +
@@ -2153,7 +2852,10 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\fcached_paths\x18\x06 \x03(\tR\vcachedPaths\x12'\n" +
 	"\x0favailable_slots\x18\a \x01(\x05R\x0eavailableSlots\x12-\n" +
 	"\x12active_deployments\x18\b \x01(\x05R\x11activeDeployments\x12@\n" +
-	"\fdisk_metrics\x18\t \x01(\v2\x1d.controlplane.NodeDiskMetricsR\vdiskMetrics\"\xe7\x01\n" +
+	"\fdisk_metrics\x18\t \x01(\v2\x1d.controlplane.NodeDiskMetricsR\vdiskMetrics\x12\"\n" +
+	"\fcapabilities\x18\n" +
+	" \x03(\tR\fcapabilities\x12P\n" +
+	"\x16certificate_expires_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\x14certificateExpiresAt\"\xe7\x01\n" +
 	"\x0fResourceMetrics\x12\x1b\n" +
 	"\tcpu_total\x18\x01 \x01(\x01R\bcpuTotal\x12#\n" +
 	"\rcpu_available\x18\x02 \x01(\x01R\fcpuAvailable\x12!\n" +
@@ -2195,7 +2897,7 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\x14WatchCommandsRequest\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1d\n" +
 	"\n" +
-	"auth_token\x18\x02 \x01(\tR\tauthToken\"\xdd\x03\n" +
+	"auth_token\x18\x02 \x01(\tR\tauthToken\"\xd6\x04\n" +
 	"\x11DeploymentCommand\x12\x1d\n" +
 	"\n" +
 	"command_id\x18\x01 \x01(\tR\tcommandId\x12-\n" +
@@ -2207,7 +2909,10 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\arestart\x18\f \x01(\v2\x1e.controlplane.CPRestartRequestH\x00R\arestart\x12J\n" +
 	"\rupdate_config\x18\r \x01(\v2#.controlplane.CPUpdateConfigRequestH\x00R\fupdateConfig\x12C\n" +
 	"\vstream_logs\x18\x0e \x01(\v2 .controlplane.CPLogStreamRequestH\x00R\n" +
-	"streamLogsB\t\n" +
+	"streamLogs\x124\n" +
+	"\x05debug\x18\x0f \x01(\v2\x1c.controlplane.CPDebugRequestH\x00R\x05debug\x12A\n" +
+	"\n" +
+	"stop_debug\x18\x10 \x01(\v2 .controlplane.CPStopDebugRequestH\x00R\tstopDebugB\t\n" +
 	"\acommand\"\xb5\x02\n" +
 	"\x0fCPDeployRequest\x12#\n" +
 	"\rdeployment_id\x18\x01 \x01(\tR\fdeploymentId\x12\x15\n" +
@@ -2221,7 +2926,7 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\bapp_name\x18\b \x01(\tR\aappName\":\n" +
 	"\x0eCPResourceSpec\x12\x10\n" +
 	"\x03cpu\x18\x01 \x01(\tR\x03cpu\x12\x16\n" +
-	"\x06memory\x18\x02 \x01(\tR\x06memory\"\xeb\x02\n" +
+	"\x06memory\x18\x02 \x01(\tR\x06memory\"\xa8\x04\n" +
 	"\x12CPDeploymentConfig\x12:\n" +
 	"\tresources\x18\x01 \x01(\v2\x1c.controlplane.CPResourceSpecR\tresources\x12\x1a\n" +
 	"\breplicas\x18\x02 \x01(\x05R\breplicas\x12H\n" +
@@ -2229,17 +2934,32 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\n" +
 	"depends_on\x18\x04 \x03(\tR\tdependsOn\x12D\n" +
 	"\fhealth_check\x18\x05 \x01(\v2!.controlplane.CPHealthCheckConfigR\vhealthCheck\x12\x12\n" +
-	"\x04port\x18\x06 \x01(\x05R\x04port\x1a:\n" +
+	"\x04port\x18\x06 \x01(\x05R\x04port\x124\n" +
+	"\x06egress\x18\a \x01(\v2\x1c.controlplane.CPEgressPolicyR\x06egress\x12\x18\n" +
+	"\acommand\x18\b \x03(\tR\acommand\x12\x18\n" +
+	"\aruntime\x18\t \x01(\tR\aruntime\x12,\n" +
+	"\x12canary_baseline_id\x18\n" +
+	" \x01(\tR\x10canaryBaselineId\x12#\n" +
+	"\rcanary_weight\x18\v \x01(\x05R\fcanaryWeight\x1a:\n" +
 	"\fEnvVarsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xef\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"]\n" +
+	"\x0eCPEgressPolicy\x12\x19\n" +
+	"\bdeny_all\x18\x01 \x01(\bR\adenyAll\x120\n" +
+	"\x05allow\x18\x02 \x03(\v2\x1a.controlplane.CPEgressRuleR\x05allow\"h\n" +
+	"\fCPEgressRule\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
+	"\x04cidr\x18\x02 \x01(\tR\x04cidr\x12\x14\n" +
+	"\x05ports\x18\x03 \x03(\x05R\x05ports\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\"\x89\x02\n" +
 	"\x13CPHealthCheckConfig\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12)\n" +
 	"\x10interval_seconds\x18\x03 \x01(\x05R\x0fintervalSeconds\x12'\n" +
 	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\x12+\n" +
 	"\x11healthy_threshold\x18\x05 \x01(\x05R\x10healthyThreshold\x12/\n" +
-	"\x13unhealthy_threshold\x18\x06 \x01(\x05R\x12unhealthyThreshold\"s\n" +
+	"\x13unhealthy_threshold\x18\x06 \x01(\x05R\x12unhealthyThreshold\x12\x18\n" +
+	"\acommand\x18\a \x03(\tR\acommand\"s\n" +
 	"\rCPStopRequest\x12#\n" +
 	"\rdeployment_id\x18\x01 \x01(\tR\fdeploymentId\x12\x14\n" +
 	"\x05force\x18\x02 \x01(\bR\x05force\x12'\n" +
@@ -2256,7 +2976,18 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"tail_lines\x18\x03 \x01(\x05R\ttailLines\x12\x16\n" +
 	"\x06follow\x18\x04 \x01(\bR\x06follow\x12%\n" +
 	"\x0eservice_filter\x18\x05 \x01(\tR\rserviceFilter\x12;\n" +
-	"\flevel_filter\x18\x06 \x01(\x0e2\x18.controlplane.CPLogLevelR\vlevelFilter\"\x87\x03\n" +
+	"\flevel_filter\x18\x06 \x01(\x0e2\x18.controlplane.CPLogLevelR\vlevelFilter\"\xbf\x01\n" +
+	"\x0eCPDebugRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12#\n" +
+	"\rdeployment_id\x18\x02 \x01(\tR\fdeploymentId\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\tR\x05image\x12\x18\n" +
+	"\acommand\x18\x04 \x03(\tR\acommand\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"3\n" +
+	"\x12CPStopDebugRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\xe7\x04\n" +
 	"\fStatusReport\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12#\n" +
 	"\rdeployment_id\x18\x02 \x01(\tR\fdeploymentId\x12\x1d\n" +
@@ -2268,13 +2999,27 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x1b\n" +
 	"\texit_code\x18\a \x01(\x05R\bexitCode\x12#\n" +
 	"\rerror_message\x18\b \x01(\tR\ferrorMessage\x12B\n" +
-	"\x0eresource_usage\x18\t \x01(\v2\x1b.controlplane.ResourceUsageR\rresourceUsage\"\xa7\x01\n" +
+	"\x0eresource_usage\x18\t \x01(\v2\x1b.controlplane.ResourceUsageR\rresourceUsage\x12J\n" +
+	"\x11egress_violations\x18\n" +
+	" \x03(\v2\x1d.controlplane.EgressViolationR\x10egressViolations\x12.\n" +
+	"\x13health_check_passed\x18\v \x01(\bR\x11healthCheckPassed\x122\n" +
+	"\x15health_check_failures\x18\f \x01(\x05R\x13healthCheckFailures\x12.\n" +
+	"\x13health_check_output\x18\r \x01(\tR\x11healthCheckOutput\"\x81\x02\n" +
 	"\rResourceUsage\x12\x1f\n" +
 	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
 	"cpuPercent\x12!\n" +
 	"\fmemory_bytes\x18\x02 \x01(\x03R\vmemoryBytes\x12(\n" +
 	"\x10network_rx_bytes\x18\x03 \x01(\x03R\x0enetworkRxBytes\x12(\n" +
-	"\x10network_tx_bytes\x18\x04 \x01(\x03R\x0enetworkTxBytes\"4\n" +
+	"\x10network_tx_bytes\x18\x04 \x01(\x03R\x0enetworkTxBytes\x12\x1a\n" +
+	"\brequests\x18\x05 \x01(\x03R\brequests\x12\x16\n" +
+	"\x06errors\x18\x06 \x01(\x03R\x06errors\x12$\n" +
+	"\x0elatency_ms_sum\x18\a \x01(\x03R\flatencyMsSum\"\xb2\x01\n" +
+	"\x0fEgressViolation\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x03R\x05count\x127\n" +
+	"\tlast_seen\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\"4\n" +
 	"\x0eStatusResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"\xf6\x02\n" +
 	"\n" +
@@ -2290,14 +3035,32 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"=\n" +
 	"\x10PushLogsResponse\x12)\n" +
-	"\x10entries_received\x18\x01 \x01(\x03R\x0fentriesReceived*\x91\x01\n" +
+	"\x10entries_received\x18\x01 \x01(\x03R\x0fentriesReceived\"\xbe\x03\n" +
+	"\x0eAgentHeartbeat\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12;\n" +
+	"\tresources\x18\x02 \x01(\v2\x1d.controlplane.ResourceMetricsR\tresources\x12@\n" +
+	"\fdisk_metrics\x18\x03 \x01(\v2\x1d.controlplane.NodeDiskMetricsR\vdiskMetrics\x12\x14\n" +
+	"\x05load1\x18\x04 \x01(\x01R\x05load1\x12\x14\n" +
+	"\x05load5\x18\x05 \x01(\x01R\x05load5\x12\x16\n" +
+	"\x06load15\x18\x06 \x01(\x01R\x06load15\x12-\n" +
+	"\x12active_deployments\x18\a \x01(\x05R\x11activeDeployments\x12\x1a\n" +
+	"\bdraining\x18\b \x01(\bR\bdraining\x123\n" +
+	"\asent_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x06sentAt\x12P\n" +
+	"\x16certificate_expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x14certificateExpiresAt\"\x8e\x01\n" +
+	"\x11AgentHeartbeatAck\x12\x18\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12!\n" +
+	"\fhealth_score\x18\x02 \x01(\x05R\vhealthScore\x12<\n" +
+	"\x1aheartbeat_interval_seconds\x18\x03 \x01(\x05R\x18heartbeatIntervalSeconds*\xbc\x01\n" +
 	"\vCommandType\x12\x13\n" +
 	"\x0fCOMMAND_UNKNOWN\x10\x00\x12\x12\n" +
 	"\x0eCOMMAND_DEPLOY\x10\x01\x12\x10\n" +
 	"\fCOMMAND_STOP\x10\x02\x12\x13\n" +
 	"\x0fCOMMAND_RESTART\x10\x03\x12\x19\n" +
 	"\x15COMMAND_UPDATE_CONFIG\x10\x04\x12\x17\n" +
-	"\x13COMMAND_STREAM_LOGS\x10\x05*V\n" +
+	"\x13COMMAND_STREAM_LOGS\x10\x05\x12\x11\n" +
+	"\rCOMMAND_DEBUG\x10\x06\x12\x16\n" +
+	"\x12COMMAND_STOP_DEBUG\x10\a*V\n" +
 	"\vCPBuildType\x12\x19\n" +
 	"\x15CP_BUILD_TYPE_UNKNOWN\x10\x00\x12\x15\n" +
 	"\x11CP_BUILD_TYPE_OCI\x10\x01\x12\x15\n" +
@@ -2317,13 +3080,14 @@ const file_api_proto_controlplane_proto_rawDesc = "" +
 	"\fCP_LOG_DEBUG\x10\x01\x12\x0f\n" +
 	"\vCP_LOG_INFO\x10\x02\x12\x0f\n" +
 	"\vCP_LOG_WARN\x10\x03\x12\x10\n" +
-	"\fCP_LOG_ERROR\x10\x042\x98\x03\n" +
+	"\fCP_LOG_ERROR\x10\x042\xe8\x03\n" +
 	"\x13ControlPlaneService\x12I\n" +
 	"\bRegister\x12\x1d.controlplane.RegisterRequest\x1a\x1e.controlplane.RegisterResponse\x12L\n" +
 	"\tHeartbeat\x12\x1e.controlplane.HeartbeatRequest\x1a\x1f.controlplane.HeartbeatResponse\x12V\n" +
 	"\rWatchCommands\x12\".controlplane.WatchCommandsRequest\x1a\x1f.controlplane.DeploymentCommand0\x01\x12H\n" +
 	"\fReportStatus\x12\x1a.controlplane.StatusReport\x1a\x1c.controlplane.StatusResponse\x12F\n" +
-	"\bPushLogs\x12\x18.controlplane.CPLogEntry\x1a\x1e.controlplane.PushLogsResponse(\x012\xa6\x01\n" +
+	"\bPushLogs\x12\x18.controlplane.CPLogEntry\x1a\x1e.controlplane.PushLogsResponse(\x01\x12N\n" +
+	"\tNodeAgent\x12\x1c.controlplane.AgentHeartbeat\x1a\x1f.controlplane.AgentHeartbeatAck(\x010\x012\xa6\x01\n" +
 	"\x06Health\x12L\n" +
 	"\x05Check\x12 .controlplane.HealthCheckRequest\x1a!.controlplane.HealthCheckResponse\x12N\n" +
 	"\x05Watch\x12 .controlplane.HealthCheckRequest\x1a!.controlplane.HealthCheckResponse0\x01B0Z.github.com/narvanalabs/control-plane/api/protob\x06proto3"
@@ -2341,7 +3105,7 @@ func file_api_proto_controlplane_proto_rawDescGZIP() []byte {
 }
 
 var file_api_proto_controlplane_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_api_proto_controlplane_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_api_proto_controlplane_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_api_proto_controlplane_proto_goTypes = []any{
 	(CommandType)(0),                       // 0: controlplane.CommandType
 	(CPBuildType)(0),                       // 1: controlplane.CPBuildType
@@ -2364,69 +3128,90 @@ var file_api_proto_controlplane_proto_goTypes = []any{
 	(*CPDeployRequest)(nil),                // 18: controlplane.CPDeployRequest
 	(*CPResourceSpec)(nil),                 // 19: controlplane.CPResourceSpec
 	(*CPDeploymentConfig)(nil),             // 20: controlplane.CPDeploymentConfig
-	(*CPHealthCheckConfig)(nil),            // 21: controlplane.CPHealthCheckConfig
-	(*CPStopRequest)(nil),                  // 22: controlplane.CPStopRequest
-	(*CPRestartRequest)(nil),               // 23: controlplane.CPRestartRequest
-	(*CPUpdateConfigRequest)(nil),          // 24: controlplane.CPUpdateConfigRequest
-	(*CPLogStreamRequest)(nil),             // 25: controlplane.CPLogStreamRequest
-	(*StatusReport)(nil),                   // 26: controlplane.StatusReport
-	(*ResourceUsage)(nil),                  // 27: controlplane.ResourceUsage
-	(*StatusResponse)(nil),                 // 28: controlplane.StatusResponse
-	(*CPLogEntry)(nil),                     // 29: controlplane.CPLogEntry
-	(*PushLogsResponse)(nil),               // 30: controlplane.PushLogsResponse
-	nil,                                    // 31: controlplane.CPDeploymentConfig.EnvVarsEntry
-	nil,                                    // 32: controlplane.CPLogEntry.MetadataEntry
-	(*timestamppb.Timestamp)(nil),          // 33: google.protobuf.Timestamp
+	(*CPEgressPolicy)(nil),                 // 21: controlplane.CPEgressPolicy
+	(*CPEgressRule)(nil),                   // 22: controlplane.CPEgressRule
+	(*CPHealthCheckConfig)(nil),            // 23: controlplane.CPHealthCheckConfig
+	(*CPStopRequest)(nil),                  // 24: controlplane.CPStopRequest
+	(*CPRestartRequest)(nil),               // 25: controlplane.CPRestartRequest
+	(*CPUpdateConfigRequest)(nil),          // 26: controlplane.CPUpdateConfigRequest
+	(*CPLogStreamRequest)(nil),             // 27: controlplane.CPLogStreamRequest
+	(*CPDebugRequest)(nil),                 // 28: controlplane.CPDebugRequest
+	(*CPStopDebugRequest)(nil),             // 29: controlplane.CPStopDebugRequest
+	(*StatusReport)(nil),                   // 30: controlplane.StatusReport
+	(*ResourceUsage)(nil),                  // 31: controlplane.ResourceUsage
+	(*EgressViolation)(nil),                // 32: controlplane.EgressViolation
+	(*StatusResponse)(nil),                 // 33: controlplane.StatusResponse
+	(*CPLogEntry)(nil),                     // 34: controlplane.CPLogEntry
+	(*PushLogsResponse)(nil),               // 35: controlplane.PushLogsResponse
+	(*AgentHeartbeat)(nil),                 // 36: controlplane.AgentHeartbeat
+	(*AgentHeartbeatAck)(nil),              // 37: controlplane.AgentHeartbeatAck
+	nil,                                    // 38: controlplane.CPDeploymentConfig.EnvVarsEntry
+	nil,                                    // 39: controlplane.CPLogEntry.MetadataEntry
+	(*timestamppb.Timestamp)(nil),          // 40: google.protobuf.Timestamp
 }
 var file_api_proto_controlplane_proto_depIdxs = []int32{
 	4,  // 0: controlplane.HealthCheckResponse.status:type_name -> controlplane.HealthCheckResponse.ServingStatus
 	8,  // 1: controlplane.NodeInfo.resources:type_name -> controlplane.ResourceMetrics
 	10, // 2: controlplane.NodeInfo.disk_metrics:type_name -> controlplane.NodeDiskMetrics
-	9,  // 3: controlplane.NodeDiskMetrics.nix_store:type_name -> controlplane.DiskStats
-	9,  // 4: controlplane.NodeDiskMetrics.container_storage:type_name -> controlplane.DiskStats
-	7,  // 5: controlplane.RegisterRequest.node_info:type_name -> controlplane.NodeInfo
-	13, // 6: controlplane.RegisterResponse.config:type_name -> controlplane.NodeConfig
-	7,  // 7: controlplane.HeartbeatRequest.node_info:type_name -> controlplane.NodeInfo
-	33, // 8: controlplane.HeartbeatRequest.timestamp:type_name -> google.protobuf.Timestamp
-	0,  // 9: controlplane.DeploymentCommand.type:type_name -> controlplane.CommandType
-	33, // 10: controlplane.DeploymentCommand.deadline:type_name -> google.protobuf.Timestamp
-	18, // 11: controlplane.DeploymentCommand.deploy:type_name -> controlplane.CPDeployRequest
-	22, // 12: controlplane.DeploymentCommand.stop:type_name -> controlplane.CPStopRequest
-	23, // 13: controlplane.DeploymentCommand.restart:type_name -> controlplane.CPRestartRequest
-	24, // 14: controlplane.DeploymentCommand.update_config:type_name -> controlplane.CPUpdateConfigRequest
-	25, // 15: controlplane.DeploymentCommand.stream_logs:type_name -> controlplane.CPLogStreamRequest
-	1,  // 16: controlplane.CPDeployRequest.build_type:type_name -> controlplane.CPBuildType
-	20, // 17: controlplane.CPDeployRequest.config:type_name -> controlplane.CPDeploymentConfig
-	19, // 18: controlplane.CPDeploymentConfig.resources:type_name -> controlplane.CPResourceSpec
-	31, // 19: controlplane.CPDeploymentConfig.env_vars:type_name -> controlplane.CPDeploymentConfig.EnvVarsEntry
-	21, // 20: controlplane.CPDeploymentConfig.health_check:type_name -> controlplane.CPHealthCheckConfig
-	20, // 21: controlplane.CPUpdateConfigRequest.config:type_name -> controlplane.CPDeploymentConfig
-	3,  // 22: controlplane.CPLogStreamRequest.level_filter:type_name -> controlplane.CPLogLevel
-	2,  // 23: controlplane.StatusReport.status:type_name -> controlplane.DeploymentStatus
-	33, // 24: controlplane.StatusReport.started_at:type_name -> google.protobuf.Timestamp
-	27, // 25: controlplane.StatusReport.resource_usage:type_name -> controlplane.ResourceUsage
-	33, // 26: controlplane.CPLogEntry.timestamp:type_name -> google.protobuf.Timestamp
-	3,  // 27: controlplane.CPLogEntry.level:type_name -> controlplane.CPLogLevel
-	32, // 28: controlplane.CPLogEntry.metadata:type_name -> controlplane.CPLogEntry.MetadataEntry
-	11, // 29: controlplane.ControlPlaneService.Register:input_type -> controlplane.RegisterRequest
-	14, // 30: controlplane.ControlPlaneService.Heartbeat:input_type -> controlplane.HeartbeatRequest
-	16, // 31: controlplane.ControlPlaneService.WatchCommands:input_type -> controlplane.WatchCommandsRequest
-	26, // 32: controlplane.ControlPlaneService.ReportStatus:input_type -> controlplane.StatusReport
-	29, // 33: controlplane.ControlPlaneService.PushLogs:input_type -> controlplane.CPLogEntry
-	5,  // 34: controlplane.Health.Check:input_type -> controlplane.HealthCheckRequest
-	5,  // 35: controlplane.Health.Watch:input_type -> controlplane.HealthCheckRequest
-	12, // 36: controlplane.ControlPlaneService.Register:output_type -> controlplane.RegisterResponse
-	15, // 37: controlplane.ControlPlaneService.Heartbeat:output_type -> controlplane.HeartbeatResponse
-	17, // 38: controlplane.ControlPlaneService.WatchCommands:output_type -> controlplane.DeploymentCommand
-	28, // 39: controlplane.ControlPlaneService.ReportStatus:output_type -> controlplane.StatusResponse
-	30, // 40: controlplane.ControlPlaneService.PushLogs:output_type -> controlplane.PushLogsResponse
-	6,  // 41: controlplane.Health.Check:output_type -> controlplane.HealthCheckResponse
-	6,  // 42: controlplane.Health.Watch:output_type -> controlplane.HealthCheckResponse
-	36, // [36:43] is the sub-list for method output_type
-	29, // [29:36] is the sub-list for method input_type
-	29, // [29:29] is the sub-list for extension type_name
-	29, // [29:29] is the sub-list for extension extendee
-	0,  // [0:29] is the sub-list for field type_name
+	40, // 3: controlplane.NodeInfo.certificate_expires_at:type_name -> google.protobuf.Timestamp
+	9,  // 4: controlplane.NodeDiskMetrics.nix_store:type_name -> controlplane.DiskStats
+	9,  // 5: controlplane.NodeDiskMetrics.container_storage:type_name -> controlplane.DiskStats
+	7,  // 6: controlplane.RegisterRequest.node_info:type_name -> controlplane.NodeInfo
+	13, // 7: controlplane.RegisterResponse.config:type_name -> controlplane.NodeConfig
+	7,  // 8: controlplane.HeartbeatRequest.node_info:type_name -> controlplane.NodeInfo
+	40, // 9: controlplane.HeartbeatRequest.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 10: controlplane.DeploymentCommand.type:type_name -> controlplane.CommandType
+	40, // 11: controlplane.DeploymentCommand.deadline:type_name -> google.protobuf.Timestamp
+	18, // 12: controlplane.DeploymentCommand.deploy:type_name -> controlplane.CPDeployRequest
+	24, // 13: controlplane.DeploymentCommand.stop:type_name -> controlplane.CPStopRequest
+	25, // 14: controlplane.DeploymentCommand.restart:type_name -> controlplane.CPRestartRequest
+	26, // 15: controlplane.DeploymentCommand.update_config:type_name -> controlplane.CPUpdateConfigRequest
+	27, // 16: controlplane.DeploymentCommand.stream_logs:type_name -> controlplane.CPLogStreamRequest
+	28, // 17: controlplane.DeploymentCommand.debug:type_name -> controlplane.CPDebugRequest
+	29, // 18: controlplane.DeploymentCommand.stop_debug:type_name -> controlplane.CPStopDebugRequest
+	1,  // 19: controlplane.CPDeployRequest.build_type:type_name -> controlplane.CPBuildType
+	20, // 20: controlplane.CPDeployRequest.config:type_name -> controlplane.CPDeploymentConfig
+	19, // 21: controlplane.CPDeploymentConfig.resources:type_name -> controlplane.CPResourceSpec
+	38, // 22: controlplane.CPDeploymentConfig.env_vars:type_name -> controlplane.CPDeploymentConfig.EnvVarsEntry
+	23, // 23: controlplane.CPDeploymentConfig.health_check:type_name -> controlplane.CPHealthCheckConfig
+	21, // 24: controlplane.CPDeploymentConfig.egress:type_name -> controlplane.CPEgressPolicy
+	22, // 25: controlplane.CPEgressPolicy.allow:type_name -> controlplane.CPEgressRule
+	20, // 26: controlplane.CPUpdateConfigRequest.config:type_name -> controlplane.CPDeploymentConfig
+	3,  // 27: controlplane.CPLogStreamRequest.level_filter:type_name -> controlplane.CPLogLevel
+	40, // 28: controlplane.CPDebugRequest.expires_at:type_name -> google.protobuf.Timestamp
+	2,  // 29: controlplane.StatusReport.status:type_name -> controlplane.DeploymentStatus
+	40, // 30: controlplane.StatusReport.started_at:type_name -> google.protobuf.Timestamp
+	31, // 31: controlplane.StatusReport.resource_usage:type_name -> controlplane.ResourceUsage
+	32, // 32: controlplane.StatusReport.egress_violations:type_name -> controlplane.EgressViolation
+	40, // 33: controlplane.EgressViolation.last_seen:type_name -> google.protobuf.Timestamp
+	40, // 34: controlplane.CPLogEntry.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 35: controlplane.CPLogEntry.level:type_name -> controlplane.CPLogLevel
+	39, // 36: controlplane.CPLogEntry.metadata:type_name -> controlplane.CPLogEntry.MetadataEntry
+	8,  // 37: controlplane.AgentHeartbeat.resources:type_name -> controlplane.ResourceMetrics
+	10, // 38: controlplane.AgentHeartbeat.disk_metrics:type_name -> controlplane.NodeDiskMetrics
+	40, // 39: controlplane.AgentHeartbeat.sent_at:type_name -> google.protobuf.Timestamp
+	40, // 40: controlplane.AgentHeartbeat.certificate_expires_at:type_name -> google.protobuf.Timestamp
+	11, // 41: controlplane.ControlPlaneService.Register:input_type -> controlplane.RegisterRequest
+	14, // 42: controlplane.ControlPlaneService.Heartbeat:input_type -> controlplane.HeartbeatRequest
+	16, // 43: controlplane.ControlPlaneService.WatchCommands:input_type -> controlplane.WatchCommandsRequest
+	30, // 44: controlplane.ControlPlaneService.ReportStatus:input_type -> controlplane.StatusReport
+	34, // 45: controlplane.ControlPlaneService.PushLogs:input_type -> controlplane.CPLogEntry
+	36, // 46: controlplane.ControlPlaneService.NodeAgent:input_type -> controlplane.AgentHeartbeat
+	5,  // 47: controlplane.Health.Check:input_type -> controlplane.HealthCheckRequest
+	5,  // 48: controlplane.Health.Watch:input_type -> controlplane.HealthCheckRequest
+	12, // 49: controlplane.ControlPlaneService.Register:output_type -> controlplane.RegisterResponse
+	15, // 50: controlplane.ControlPlaneService.Heartbeat:output_type -> controlplane.HeartbeatResponse
+	17, // 51: controlplane.ControlPlaneService.WatchCommands:output_type -> controlplane.DeploymentCommand
+	33, // 52: controlplane.ControlPlaneService.ReportStatus:output_type -> controlplane.StatusResponse
+	35, // 53: controlplane.ControlPlaneService.PushLogs:output_type -> controlplane.PushLogsResponse
+	37, // 54: controlplane.ControlPlaneService.NodeAgent:output_type -> controlplane.AgentHeartbeatAck
+	6,  // 55: controlplane.Health.Check:output_type -> controlplane.HealthCheckResponse
+	6,  // 56: controlplane.Health.Watch:output_type -> controlplane.HealthCheckResponse
+	49, // [49:57] is the sub-list for method output_type
+	41, // [41:49] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_controlplane_proto_init() }
@@ -2440,6 +3225,8 @@ func file_api_proto_controlplane_proto_init() {
 		(*DeploymentCommand_Restart)(nil),
 		(*DeploymentCommand_UpdateConfig)(nil),
 		(*DeploymentCommand_StreamLogs)(nil),
+		(*DeploymentCommand_Debug)(nil),
+		(*DeploymentCommand_StopDebug)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -2447,7 +3234,7 @@ func file_api_proto_controlplane_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_controlplane_proto_rawDesc), len(file_api_proto_controlplane_proto_rawDesc)),
 			NumEnums:      5,
-			NumMessages:   28,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
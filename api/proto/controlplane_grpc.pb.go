@@ -24,6 +24,7 @@ const (
 	ControlPlaneService_WatchCommands_FullMethodName = "/controlplane.ControlPlaneService/WatchCommands"
 	ControlPlaneService_ReportStatus_FullMethodName  = "/controlplane.ControlPlaneService/ReportStatus"
 	ControlPlaneService_PushLogs_FullMethodName      = "/controlplane.ControlPlaneService/PushLogs"
+	ControlPlaneService_NodeAgent_FullMethodName     = "/controlplane.ControlPlaneService/NodeAgent"
 )
 
 // ControlPlaneServiceClient is the client API for ControlPlaneService service.
@@ -42,6 +43,8 @@ type ControlPlaneServiceClient interface {
 	ReportStatus(ctx context.Context, in *StatusReport, opts ...grpc.CallOption) (*StatusResponse, error)
 	// Stream logs to control plane (client streaming)
 	PushLogs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CPLogEntry, PushLogsResponse], error)
+	// Stream heartbeats with node capacity and load (bidirectional streaming, persistent)
+	NodeAgent(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AgentHeartbeat, AgentHeartbeatAck], error)
 }
 
 type controlPlaneServiceClient struct {
@@ -114,6 +117,19 @@ func (c *controlPlaneServiceClient) PushLogs(ctx context.Context, opts ...grpc.C
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ControlPlaneService_PushLogsClient = grpc.ClientStreamingClient[CPLogEntry, PushLogsResponse]
 
+func (c *controlPlaneServiceClient) NodeAgent(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AgentHeartbeat, AgentHeartbeatAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ControlPlaneService_ServiceDesc.Streams[2], ControlPlaneService_NodeAgent_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AgentHeartbeat, AgentHeartbeatAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlaneService_NodeAgentClient = grpc.BidiStreamingClient[AgentHeartbeat, AgentHeartbeatAck]
+
 // ControlPlaneServiceServer is the server API for ControlPlaneService service.
 // All implementations must embed UnimplementedControlPlaneServiceServer
 // for forward compatibility.
@@ -130,6 +146,8 @@ type ControlPlaneServiceServer interface {
 	ReportStatus(context.Context, *StatusReport) (*StatusResponse, error)
 	// Stream logs to control plane (client streaming)
 	PushLogs(grpc.ClientStreamingServer[CPLogEntry, PushLogsResponse]) error
+	// Stream heartbeats with node capacity and load (bidirectional streaming, persistent)
+	NodeAgent(grpc.BidiStreamingServer[AgentHeartbeat, AgentHeartbeatAck]) error
 	mustEmbedUnimplementedControlPlaneServiceServer()
 }
 
@@ -155,6 +173,9 @@ func (UnimplementedControlPlaneServiceServer) ReportStatus(context.Context, *Sta
 func (UnimplementedControlPlaneServiceServer) PushLogs(grpc.ClientStreamingServer[CPLogEntry, PushLogsResponse]) error {
 	return status.Error(codes.Unimplemented, "method PushLogs not implemented")
 }
+func (UnimplementedControlPlaneServiceServer) NodeAgent(grpc.BidiStreamingServer[AgentHeartbeat, AgentHeartbeatAck]) error {
+	return status.Error(codes.Unimplemented, "method NodeAgent not implemented")
+}
 func (UnimplementedControlPlaneServiceServer) mustEmbedUnimplementedControlPlaneServiceServer() {}
 func (UnimplementedControlPlaneServiceServer) testEmbeddedByValue()                             {}
 
@@ -248,6 +269,13 @@ func _ControlPlaneService_PushLogs_Handler(srv interface{}, stream grpc.ServerSt
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ControlPlaneService_PushLogsServer = grpc.ClientStreamingServer[CPLogEntry, PushLogsResponse]
 
+func _ControlPlaneService_NodeAgent_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ControlPlaneServiceServer).NodeAgent(&grpc.GenericServerStream[AgentHeartbeat, AgentHeartbeatAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlaneService_NodeAgentServer = grpc.BidiStreamingServer[AgentHeartbeat, AgentHeartbeatAck]
+
 // ControlPlaneService_ServiceDesc is the grpc.ServiceDesc for ControlPlaneService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -279,6 +307,12 @@ var ControlPlaneService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _ControlPlaneService_PushLogs_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "NodeAgent",
+			Handler:       _ControlPlaneService_NodeAgent_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "api/proto/controlplane.proto",
 }
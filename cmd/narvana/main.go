@@ -0,0 +1,181 @@
+// Package main provides the narvana command, which runs the whole control
+// plane on one machine: narvana up starts Postgres and an image registry in
+// Podman, applies the migrations and runs the API server, the build worker,
+// the web UI and a local node in a single process.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/controlplane"
+	"github.com/narvanalabs/control-plane/internal/localnode"
+	"github.com/narvanalabs/control-plane/internal/quickstart"
+	"github.com/narvanalabs/control-plane/internal/shutdown"
+	pgstore "github.com/narvanalabs/control-plane/internal/store/postgres"
+	"github.com/narvanalabs/control-plane/migrations"
+	"github.com/narvanalabs/control-plane/pkg/config"
+	"github.com/narvanalabs/control-plane/pkg/logger"
+	"github.com/narvanalabs/control-plane/web/assets"
+	"github.com/narvanalabs/control-plane/web/server"
+)
+
+const usage = `Usage: narvana up [flags]
+
+Runs the control plane, build worker, web UI and a local node on this machine.
+
+The database is not embedded: unless -database-url is given, Postgres and an
+image registry run as Podman containers named narvana-quickstart-postgres and
+narvana-quickstart-registry, so Podman must be installed. Their data is kept
+in Podman volumes of the same names across runs.
+
+Flags:
+`
+
+func main() {
+	qcfg := quickstart.DefaultConfig()
+	flags := flag.NewFlagSet("up", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&qcfg.DataDir, "data-dir", qcfg.DataDir, "directory for generated secrets, keys and build directories")
+	databaseURL := flags.String("database-url", "", "use this Postgres database instead of starting one in Podman")
+	assetsDir := flags.String("assets-dir", "", "serve the web UI's static assets from this directory instead of the embedded ones")
+	apiPort := flags.Int("api-port", 8080, "port of the API server")
+	webPort := flags.Int("web-port", 8090, "port of the web UI")
+
+	if len(os.Args) < 2 || os.Args[1] != "up" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	flags.Parse(os.Args[2:])
+
+	log := logger.New(slog.LevelInfo, false)
+	if err := up(qcfg, *databaseURL, *assetsDir, *apiPort, *webPort, log); err != nil {
+		log.Error("narvana up failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func up(qcfg quickstart.Config, databaseURL, assetsDir string, apiPort, webPort int, log *logger.Logger) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env, err := quickstart.New(qcfg, log.Logger)
+	if err != nil {
+		return err
+	}
+
+	// Start the database and the registry builds push to
+	if databaseURL == "" {
+		if databaseURL, err = env.StartPostgres(ctx); err != nil {
+			return err
+		}
+	}
+	if err := env.StartRegistry(ctx); err != nil {
+		return err
+	}
+	if err := migrate(ctx, databaseURL, log); err != nil {
+		return err
+	}
+
+	vars, err := env.Env(databaseURL, apiPort)
+	if err != nil {
+		return err
+	}
+	for key, value := range vars {
+		os.Setenv(key, value)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	storeCfg := pgstore.DefaultConfig(cfg.DatabaseDSN)
+	storeCfg.ReplicaDSN = cfg.DatabaseReadDSN
+	storeCfg.ReplicaWait = cfg.DatabaseReadWait
+	store, err := pgstore.NewPostgresStore(storeCfg, log.Logger)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+
+	shutdownTimeout := 30 * time.Second
+	if cfg.ShutdownTimeout > 0 {
+		shutdownTimeout = cfg.ShutdownTimeout
+	}
+	coordinator := shutdown.NewCoordinator(
+		shutdown.WithTimeout(shutdownTimeout),
+		shutdown.WithLogger(log.Logger),
+	)
+
+	// Registered first so they are stopped last, after everything using them
+	coordinator.Register(shutdown.NewFuncComponent("quickstart-containers", env.Stop))
+	coordinator.Register(shutdown.NewCloserComponent("database", store))
+
+	if err := controlplane.StartTracing(ctx, cfg, coordinator, "narvana"); err != nil {
+		return err
+	}
+	node := localnode.NewBackend(store, localnode.DefaultConfig(), log.Logger)
+	if err := controlplane.StartAPI(ctx, cfg, store, coordinator, controlplane.APIOptions{LocalNode: node}, log); err != nil {
+		return fmt.Errorf("starting API server: %w", err)
+	}
+	if err := controlplane.StartWorker(ctx, cfg, store, coordinator, controlplane.WorkerOptions{}, log); err != nil {
+		return fmt.Errorf("starting worker: %w", err)
+	}
+
+	static, err := assets.Open(assetsDir)
+	if err != nil {
+		return err
+	}
+	webServer := &http.Server{
+		Addr:         fmt.Sprintf("127.0.0.1:%d", webPort),
+		Handler:      server.NewRouter(static, cfg.TrustedProxies),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+	coordinator.Register(shutdown.NewHTTPServerComponent("web-server", webServer))
+	go func() {
+		if err := webServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("web server failed to start", "error", err)
+			coordinator.Shutdown()
+		}
+	}()
+
+	fmt.Printf("\nNarvana is running at http://127.0.0.1:%d — open it to register the first account.\n", webPort)
+	fmt.Printf("Data is kept in %s. Press Ctrl+C to stop.\n\n", qcfg.DataDir)
+
+	coordinator.WaitForSignal()
+	coordinator.Wait()
+	cancel()
+
+	if code := coordinator.ExitCode(); code != 0 {
+		return fmt.Errorf("shutdown finished with exit code %d", code)
+	}
+	return nil
+}
+
+// migrate applies the embedded migrations to the database.
+func migrate(ctx context.Context, databaseURL string, log *logger.Logger) error {
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	applied, err := quickstart.Migrate(ctx, db, migrations.Files, log.Logger)
+	if err != nil {
+		return fmt.Errorf("migrating database: %w", err)
+	}
+	if applied > 0 {
+		log.Info("database migrated", "applied", applied)
+	}
+	return nil
+}
@@ -0,0 +1,764 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/web/api"
+)
+
+// actionResult is printed for commands whose API call returns no body.
+type actionResult struct {
+	App     string `json:"app,omitempty"`
+	Service string `json:"service,omitempty"`
+	BuildID string `json:"build_id,omitempty"`
+	Action  string `json:"action"`
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors to stderr.
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// positional parses fs and requires exactly n positional arguments.
+func positional(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() != n {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func (c *cli) login(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("login")
+	email := fs.String("email", "", "Account email")
+	password := fs.String("password", "", "Account password")
+	if _, err := positional(fs, args, 0); err != nil {
+		return err
+	}
+	if *email == "" {
+		return fmt.Errorf("-email is required")
+	}
+
+	if *password == "" {
+		*password = os.Getenv("NARVANA_PASSWORD")
+	}
+	if *password == "" {
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *password == "" {
+		return fmt.Errorf("a password is required")
+	}
+
+	resp, err := c.client.Login(ctx, *email, *password)
+	if err != nil {
+		return err
+	}
+
+	// Persist only the file config plus the new session, never env overrides
+	saved, err := LoadConfig(c.configPath)
+	if err != nil {
+		return err
+	}
+	saved.APIURL = c.cfg.APIURL
+	saved.Token = resp.Token
+	if err := saved.Save(c.configPath); err != nil {
+		return err
+	}
+
+	// The token is written to the config file only, not echoed to stdout
+	result := map[string]string{"user_id": resp.UserID, "api_url": c.cfg.APIURL, "config": c.configPath}
+	return c.out.result(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Logged in as %s (token saved to %s)\n", *email, c.configPath)
+	})
+}
+
+func (c *cli) logout() error {
+	saved, err := LoadConfig(c.configPath)
+	if err != nil {
+		return err
+	}
+	saved.Token = ""
+	if err := saved.Save(c.configPath); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{Action: "logout"}, func(w io.Writer) {
+		fmt.Fprintln(w, "Logged out")
+	})
+}
+
+func (c *cli) appsList(ctx context.Context) error {
+	apps, err := c.client.ListApps(ctx)
+	if err != nil {
+		return err
+	}
+	return c.out.result(apps, func(w io.Writer) {
+		rows := make([][]string, 0, len(apps))
+		for _, app := range apps {
+			rows = append(rows, []string{app.ID, app.Name, fmt.Sprint(len(app.Services)), formatTime(app.CreatedAt)})
+		}
+		c.out.table([]string{"ID", "NAME", "SERVICES", "CREATED"}, rows)
+	})
+}
+
+func (c *cli) appsCreate(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("apps create")
+	name := fs.String("name", "", "Application name")
+	description := fs.String("description", "", "Application description")
+	if _, err := positional(fs, args, 0); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	app, err := c.client.CreateApp(ctx, *name, *description, "")
+	if err != nil {
+		return err
+	}
+	return c.out.result(app, func(w io.Writer) {
+		fmt.Fprintf(w, "Created app %s (%s)\n", app.Name, app.ID)
+	})
+}
+
+func (c *cli) servicesDeploy(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("services deploy")
+	reason := fs.String("freeze-reason", "", "Override an active deploy freeze with this reason (owners only)")
+	pos, err := positional(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	app, service := pos[0], pos[1]
+
+	var deployment *api.Deployment
+	if *reason != "" {
+		deployment, err = c.client.DeployWithFreezeOverride(ctx, app, service, *reason)
+	} else {
+		deployment, err = c.client.Deploy(ctx, app, service)
+	}
+	if err != nil {
+		return err
+	}
+	return c.out.result(deployment, func(w io.Writer) {
+		fmt.Fprintf(w, "Deploying %s v%d (%s)\n", deployment.ServiceName, deployment.Version, deployment.ID)
+	})
+}
+
+func (c *cli) servicesStop(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("services stop"), args, 2)
+	if err != nil {
+		return err
+	}
+	if err := c.client.StopService(ctx, pos[0], pos[1]); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{App: pos[0], Service: pos[1], Action: "stop"}, func(w io.Writer) {
+		fmt.Fprintf(w, "Stopping %s\n", pos[1])
+	})
+}
+
+func (c *cli) servicesStart(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("services start"), args, 2)
+	if err != nil {
+		return err
+	}
+	if err := c.client.StartService(ctx, pos[0], pos[1]); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{App: pos[0], Service: pos[1], Action: "start"}, func(w io.Writer) {
+		fmt.Fprintf(w, "Starting %s\n", pos[1])
+	})
+}
+
+func (c *cli) deploymentsRollback(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("deployments rollback"), args, 1)
+	if err != nil {
+		return err
+	}
+	deployment, err := c.client.RollbackDeployment(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return c.out.result(deployment, func(w io.Writer) {
+		fmt.Fprintf(w, "Rolling back %s as v%d (%s), redeploying %s\n",
+			deployment.ServiceName, deployment.Version, deployment.ID, deployment.RollbackOf)
+	})
+}
+
+// deploymentsPromote promotes a running deployment into the next environment
+// of its app's pipeline, or the one named with -to.
+func (c *cli) deploymentsPromote(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("deployments promote")
+	to := fs.String("to", "", "Environment to promote into (default: the next one)")
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	promotion, err := c.client.PromoteDeployment(ctx, pos[0], *to)
+	if err != nil {
+		return err
+	}
+	return c.out.result(promotion, func(w io.Writer) {
+		switch {
+		case promotion.TargetDeploymentID != "":
+			fmt.Fprintf(w, "Promoted %s as deployment %s\n", promotion.SourceDeploymentID, promotion.TargetDeploymentID)
+		case promotion.Reason != "":
+			fmt.Fprintf(w, "Promotion %s %s: %s\n", promotion.ID, promotion.Status, promotion.Reason)
+		default:
+			fmt.Fprintf(w, "Promotion %s %s\n", promotion.ID, promotion.Status)
+		}
+	})
+}
+
+// promotionsDecide approves or rejects a promotion of one of the app's deployments.
+func (c *cli) promotionsDecide(ctx context.Context, decision string, args []string) error {
+	pos, err := positional(c.newFlagSet("promotions "+decision), args, 2)
+	if err != nil {
+		return err
+	}
+	var promotion *api.Promotion
+	if decision == "approve" {
+		promotion, err = c.client.ApprovePromotion(ctx, pos[0], pos[1])
+	} else {
+		promotion, err = c.client.RejectPromotion(ctx, pos[0], pos[1])
+	}
+	if err != nil {
+		return err
+	}
+	return c.out.result(promotion, func(w io.Writer) {
+		switch {
+		case promotion.TargetDeploymentID != "":
+			fmt.Fprintf(w, "Promoted %s as deployment %s\n", promotion.SourceDeploymentID, promotion.TargetDeploymentID)
+		case promotion.Reason != "":
+			fmt.Fprintf(w, "Promotion %s: %s\n", promotion.Status, promotion.Reason)
+		default:
+			fmt.Fprintf(w, "Promotion %s\n", promotion.Status)
+		}
+	})
+}
+
+func (c *cli) buildsList(ctx context.Context) error {
+	builds, err := c.client.ListBuilds(ctx)
+	if err != nil {
+		return err
+	}
+	if builds == nil {
+		builds = []api.Build{}
+	}
+	return c.out.result(builds, func(w io.Writer) {
+		rows := make([][]string, 0, len(builds))
+		for _, b := range builds {
+			rows = append(rows, []string{b.ID, b.AppID, b.Status, b.Strategy, formatTime(b.CreatedAt)})
+		}
+		c.out.table([]string{"ID", "APP", "STATUS", "STRATEGY", "CREATED"}, rows)
+	})
+}
+
+func (c *cli) buildsRetry(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("builds retry"), args, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.client.RetryBuild(ctx, pos[0]); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{BuildID: pos[0], Action: "retry"}, func(w io.Writer) {
+		fmt.Fprintf(w, "Retrying build %s\n", pos[0])
+	})
+}
+
+func (c *cli) buildsSubmit(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("builds submit")
+	ref := fs.String("ref", "", "Git ref the artifact was built from")
+	commit := fs.String("commit", "", "Commit SHA the artifact was built from")
+	reason := fs.String("freeze-reason", "", "Override an active deploy freeze with this reason (owners only)")
+	var meta stringList
+	fs.Var(&meta, "meta", "CI metadata as key=value (repeatable)")
+	pos, err := positional(fs, args, 3)
+	if err != nil {
+		return err
+	}
+
+	req := api.SubmitBuildRequest{Artifact: pos[2], GitRef: *ref, GitCommit: *commit}
+	for _, kv := range meta {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("-meta must be key=value, got %q", kv)
+		}
+		if req.Metadata == nil {
+			req.Metadata = map[string]string{}
+		}
+		req.Metadata[k] = v
+	}
+	if *reason != "" {
+		req.FreezeOverride = &api.FreezeOverrideRequest{Reason: *reason}
+	}
+
+	resp, err := c.client.SubmitBuild(ctx, pos[0], pos[1], req)
+	if err != nil {
+		return err
+	}
+	return c.out.result(resp, func(w io.Writer) {
+		fmt.Fprintf(w, "Recorded build %s; deploying %s v%d (%s)\n",
+			resp.Build.ID, resp.Deployment.ServiceName, resp.Deployment.Version, resp.Deployment.ID)
+	})
+}
+
+func (c *cli) logs(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("logs")
+	service := fs.String("service", "", "Service name (defaults to the app's latest deployment)")
+	deployment := fs.String("deployment", "", "Deployment ID")
+	source := fs.String("source", "", "Log source: build or runtime")
+	follow := fs.Bool("follow", false, "Stream new log lines until interrupted")
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	opts := api.LogOptions{ServiceName: *service, DeploymentID: *deployment, Source: *source}
+
+	if !*follow {
+		logs, err := c.client.ListLogs(ctx, pos[0], opts)
+		if err != nil {
+			return err
+		}
+		// The API returns newest first; print in chronological order
+		for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+			logs[i], logs[j] = logs[j], logs[i]
+		}
+		return c.out.result(logs, func(w io.Writer) {
+			for _, l := range logs {
+				printLog(w, l)
+			}
+		})
+	}
+
+	return c.client.StreamLogs(ctx, pos[0], opts, func(ev api.LogStreamEvent) error {
+		switch {
+		case ev.Log != nil:
+			return c.out.line(ev.Log, func(w io.Writer) { printLog(w, *ev.Log) })
+		case ev.Event == "new_deployment" || ev.Event == "deployment_status":
+			return c.out.line(map[string]any{"event": ev.Event, "data": ev.Data}, func(w io.Writer) {
+				fmt.Fprintf(w, "--- %s %s\n", ev.Event, ev.Data)
+			})
+		}
+		return nil
+	})
+}
+
+// printLog writes a log entry as a single text line.
+func printLog(w io.Writer, l api.Log) {
+	fmt.Fprintf(w, "%s %-5s %s\n", formatTime(l.Timestamp), strings.ToUpper(l.Level), l.Message)
+}
+
+func (c *cli) apply(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("apply")
+	dryRun := fs.Bool("dry-run", false, "Validate and show the changes without applying them")
+	prune := fs.Bool("prune", false, "Delete services the spec does not list")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 1 || fs.NArg() > 2 {
+		return errUsage
+	}
+	app, file := fs.Arg(0), "narvana.yaml"
+	if fs.NArg() == 2 {
+		file = fs.Arg(1)
+	}
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("reading app spec: %w", err)
+	}
+
+	result, err := c.client.ApplyAppSpec(ctx, app, data, *dryRun, *prune)
+	if err != nil {
+		return err
+	}
+	return c.out.result(result, func(w io.Writer) {
+		if len(result.Changes) == 0 {
+			fmt.Fprintln(w, "No changes")
+			return
+		}
+		printAppSpecChanges(w, result.Changes)
+		if result.DryRun {
+			fmt.Fprintf(w, "%d change(s) planned; run without -dry-run to apply\n", len(result.Changes))
+		} else {
+			fmt.Fprintf(w, "%d change(s) applied\n", len(result.Changes))
+		}
+	})
+}
+
+// printAppSpecChanges prints app spec changes one per line, marked + for
+// additions, - for removals and ~ for updates.
+func printAppSpecChanges(w io.Writer, changes []api.AppSpecChange) {
+	for _, ch := range changes {
+		switch {
+		case ch.Action == "add" && ch.To != "":
+			fmt.Fprintf(w, "+ %s %s: %s\n", ch.Kind, ch.Name, ch.To)
+		case ch.Action == "add":
+			fmt.Fprintf(w, "+ %s %s\n", ch.Kind, ch.Name)
+		case ch.Action == "remove":
+			fmt.Fprintf(w, "- %s %s\n", ch.Kind, ch.Name)
+		case len(ch.Fields) > 0:
+			fmt.Fprintf(w, "~ %s %s: %s\n", ch.Kind, ch.Name, strings.Join(ch.Fields, ", "))
+		case ch.To != "":
+			fmt.Fprintf(w, "~ %s %s: %s -> %s\n", ch.Kind, ch.Name, ch.From, ch.To)
+		default:
+			fmt.Fprintf(w, "~ %s %s\n", ch.Kind, ch.Name)
+		}
+	}
+}
+
+func (c *cli) driftShow(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("drift show"), args, 1)
+	if err != nil {
+		return err
+	}
+	manifest, err := c.client.GetAppDrift(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return c.out.result(manifest, func(w io.Writer) {
+		fmt.Fprintf(w, "App spec applied %s\n", formatTime(manifest.AppliedAt))
+		if len(manifest.Drift) == 0 {
+			fmt.Fprintln(w, "No drift")
+			return
+		}
+		printAppSpecChanges(w, manifest.Drift)
+		fmt.Fprintf(w, "%d change(s) made outside the app spec; run drift revert or drift accept\n", len(manifest.Drift))
+	})
+}
+
+func (c *cli) driftRevert(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("drift revert"), args, 1)
+	if err != nil {
+		return err
+	}
+	result, err := c.client.RevertAppDrift(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return c.out.result(result, func(w io.Writer) {
+		if len(result.Changes) == 0 {
+			fmt.Fprintln(w, "No changes")
+			return
+		}
+		printAppSpecChanges(w, result.Changes)
+		fmt.Fprintf(w, "%d change(s) reverted\n", len(result.Changes))
+	})
+}
+
+func (c *cli) driftAccept(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("drift accept")
+	output := fs.String("o", "", "Write the new app spec to this file instead of stdout")
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	manifest, err := c.client.AcceptAppDrift(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		if err := os.WriteFile(*output, []byte(manifest.Spec), 0o644); err != nil {
+			return fmt.Errorf("writing app spec: %w", err)
+		}
+	}
+	return c.out.result(manifest, func(w io.Writer) {
+		if *output == "" {
+			io.WriteString(w, manifest.Spec)
+			return
+		}
+		fmt.Fprintf(w, "Live state accepted; app spec written to %s\n", *output)
+	})
+}
+
+// requireOrg returns the configured org ID or an error explaining how to set one.
+func (c *cli) requireOrg() (string, error) {
+	if c.cfg.OrgID == "" {
+		return "", fmt.Errorf("an org is required; pass -org or set NARVANA_ORG")
+	}
+	return c.cfg.OrgID, nil
+}
+
+func (c *cli) policyExport(ctx context.Context) error {
+	orgID, err := c.requireOrg()
+	if err != nil {
+		return err
+	}
+
+	if c.out.json {
+		var policy json.RawMessage
+		if err := c.client.Get(ctx, "/v1/orgs/"+orgID+"/policy?format=json", &policy); err != nil {
+			return err
+		}
+		return c.out.result(policy, nil)
+	}
+
+	data, err := c.client.ExportPolicy(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.w.Write(data)
+	return err
+}
+
+func (c *cli) policyApply(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("policy apply")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without applying them")
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	orgID, err := c.requireOrg()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if pos[0] == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(pos[0])
+	}
+	if err != nil {
+		return fmt.Errorf("reading policy: %w", err)
+	}
+
+	result, err := c.client.ApplyPolicy(ctx, orgID, data, *dryRun)
+	if err != nil {
+		return err
+	}
+	return c.out.result(result, func(w io.Writer) {
+		if len(result.Changes) == 0 {
+			fmt.Fprintln(w, "No changes")
+			return
+		}
+		for _, ch := range result.Changes {
+			switch ch.Action {
+			case "add":
+				fmt.Fprintf(w, "+ %s %s: %s\n", ch.Kind, ch.Name, ch.To)
+			case "remove":
+				fmt.Fprintf(w, "- %s %s: %s\n", ch.Kind, ch.Name, ch.From)
+			default:
+				fmt.Fprintf(w, "~ %s %s: %s -> %s\n", ch.Kind, ch.Name, ch.From, ch.To)
+			}
+		}
+		if result.DryRun {
+			fmt.Fprintf(w, "%d change(s) planned; run without -dry-run to apply\n", len(result.Changes))
+		} else {
+			fmt.Fprintf(w, "%d change(s) applied\n", len(result.Changes))
+		}
+	})
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+// formatScopes renders key scopes as "app:action,action" for table output.
+func formatScopes(scopes []api.APIKeyScope) string {
+	if len(scopes) == 0 {
+		return "full access"
+	}
+	parts := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		parts = append(parts, s.AppID+":"+strings.Join(s.Actions, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *cli) keysList(ctx context.Context) error {
+	keys, err := c.client.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		keys = []api.APIKey{}
+	}
+	return c.out.result(keys, func(w io.Writer) {
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, []string{k.ID, k.Name, k.Prefix + "...", formatScopes(k.Scopes), formatTime(k.CreatedAt)})
+		}
+		c.out.table([]string{"ID", "NAME", "PREFIX", "SCOPES", "CREATED"}, rows)
+	})
+}
+
+func (c *cli) keysCreate(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("keys create")
+	name := fs.String("name", "", "Key name")
+	var apps stringList
+	fs.Var(&apps, "app", "Restrict the key to this app (repeatable, * for all apps)")
+	actions := fs.String("actions", "deploy", "Comma-separated actions allowed on -app: read, deploy, write")
+	expires := fs.Int("expires-in-days", 0, "Expire the key after this many days (default never)")
+	if _, err := positional(fs, args, 0); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	req := api.CreateAPIKeyRequest{Name: *name, ExpiresInDays: *expires}
+	for _, app := range apps {
+		req.Scopes = append(req.Scopes, api.APIKeyScope{AppID: app, Actions: strings.Split(*actions, ",")})
+	}
+
+	key, err := c.client.CreateAPIKey(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.out.result(key, func(w io.Writer) {
+		fmt.Fprintf(w, "Created key %s (%s) with %s\n", key.Name, key.ID, formatScopes(key.Scopes))
+		fmt.Fprintf(w, "Key: %s\n", key.Key)
+		fmt.Fprintln(w, "Store it now; it cannot be shown again.")
+	})
+}
+
+func (c *cli) keysRevoke(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("keys revoke"), args, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.client.DeleteAPIKey(ctx, pos[0]); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{Action: "revoke"}, func(w io.Writer) {
+		fmt.Fprintf(w, "Revoked key %s\n", pos[0])
+	})
+}
+
+func (c *cli) sshKeysList(ctx context.Context) error {
+	keys, err := c.client.ListSSHKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		keys = []api.SSHKey{}
+	}
+	return c.out.result(keys, func(w io.Writer) {
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			lastUsed := "never"
+			if k.LastUsedAt != nil {
+				lastUsed = formatTime(*k.LastUsedAt)
+			}
+			rows = append(rows, []string{k.ID, k.Name, k.Fingerprint, formatTime(k.CreatedAt), lastUsed})
+		}
+		c.out.table([]string{"ID", "NAME", "FINGERPRINT", "CREATED", "LAST USED"}, rows)
+	})
+}
+
+func (c *cli) sshKeysAdd(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("ssh-keys add")
+	name := fs.String("name", "", "Key name (default the key's comment)")
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if pos[0] == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(pos[0])
+	}
+	if err != nil {
+		return fmt.Errorf("reading public key: %w", err)
+	}
+
+	key, err := c.client.CreateSSHKey(ctx, *name, string(data))
+	if err != nil {
+		return err
+	}
+	return c.out.result(key, func(w io.Writer) {
+		fmt.Fprintf(w, "Added SSH key %s (%s)\n", key.Name, key.Fingerprint)
+	})
+}
+
+func (c *cli) sshKeysRemove(ctx context.Context, args []string) error {
+	pos, err := positional(c.newFlagSet("ssh-keys remove"), args, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.client.DeleteSSHKey(ctx, pos[0]); err != nil {
+		return err
+	}
+	return c.out.result(actionResult{Action: "remove"}, func(w io.Writer) {
+		fmt.Fprintf(w, "Removed SSH key %s\n", pos[0])
+	})
+}
+
+// errDoctorFailed is returned when a doctor check fails with an error.
+var errDoctorFailed = errors.New("installation has errors")
+
+func (c *cli) doctor(ctx context.Context) error {
+	report, err := c.client.Doctor(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.out.result(report, func(w io.Writer) {
+		for _, f := range report.Findings {
+			fmt.Fprintf(w, "%-8s %-11s %s\n", strings.ToUpper(f.Severity), f.Check, f.Message)
+			if f.Remedy != "" && f.Severity != "ok" {
+				fmt.Fprintf(w, "%-20s %s\n", "", f.Remedy)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	if report.Status == "error" {
+		return errDoctorFailed
+	}
+	return nil
+}
+
+func (c *cli) logLevel(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("log-level")
+	component := fs.String("component", "", "Component to change: api, worker or grpc (default all)")
+	duration := fs.String("for", "", "How long the change lasts, e.g. 30m (default set by the server)")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 1 {
+		return errUsage
+	}
+
+	var levels []api.LogLevel
+	var err error
+	if fs.NArg() == 1 {
+		levels, err = c.client.SetLogLevel(ctx, *component, fs.Arg(0), *duration)
+	} else {
+		levels, err = c.client.GetLogLevels(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return c.out.result(levels, func(w io.Writer) {
+		fmt.Fprintf(w, "%-10s %-8s %s\n", "COMPONENT", "LEVEL", "REVERTS")
+		for _, l := range levels {
+			reverts := "-"
+			if l.RevertAt != nil {
+				reverts = fmt.Sprintf("%s (to %s)", formatTime(*l.RevertAt), l.DefaultLevel)
+			}
+			fmt.Fprintf(w, "%-10s %-8s %s\n", l.Component, l.Level, reverts)
+		}
+	})
+}
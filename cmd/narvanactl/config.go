@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/narvanalabs/control-plane/web/api"
+)
+
+// defaultAPIURL is used when no API URL is configured.
+const defaultAPIURL = "http://127.0.0.1:8080"
+
+// Config is the persisted CLI configuration. Values from the environment and
+// global flags take precedence over the file.
+type Config struct {
+	APIURL string `json:"api_url,omitempty"`
+	Token  string `json:"token,omitempty"`
+	OrgID  string `json:"org_id,omitempty"`
+}
+
+// defaultConfigPath returns $NARVANA_CONFIG or narvana/config.json in the
+// user's config directory.
+func defaultConfigPath() (string, error) {
+	if path := os.Getenv("NARVANA_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "narvana", "config.json"), nil
+}
+
+// LoadConfig reads the config file. A missing file yields an empty config.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config file with owner-only permissions since it holds a token.
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+// applyEnv overrides config values with NARVANA_* environment variables.
+func (c *Config) applyEnv() {
+	c.applyFlags(os.Getenv("NARVANA_API_URL"), os.Getenv("NARVANA_TOKEN"), os.Getenv("NARVANA_ORG"))
+}
+
+// applyFlags overrides config values with any non-empty arguments.
+func (c *Config) applyFlags(apiURL, token, orgID string) {
+	if apiURL != "" {
+		c.APIURL = apiURL
+	}
+	if token != "" {
+		c.Token = token
+	}
+	if orgID != "" {
+		c.OrgID = orgID
+	}
+}
+
+// client builds an API client from the config.
+func (c *Config) client() *api.Client {
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	client := api.NewClient(apiURL).WithClientName("narvanactl")
+	if c.Token != "" {
+		client = client.WithToken(c.Token)
+	}
+	if c.OrgID != "" {
+		client = client.WithOrg(c.OrgID)
+	}
+	return client
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/web/api"
+)
+
+// maskedValue replaces secret values when a dev session is printed.
+const maskedValue = "********"
+
+// devSummary is what dev prints before running the service: its environment
+// with secret values masked and the local addresses of its dependencies.
+type devSummary struct {
+	App     string          `json:"app_id"`
+	Service string          `json:"service"`
+	Command []string        `json:"command,omitempty"`
+	Env     []api.DevEnvVar `json:"env"`
+	Tunnels []devTunnel     `json:"tunnels"`
+}
+
+type devTunnel struct {
+	Service string `json:"service"`
+	Port    int    `json:"port"`
+	Local   string `json:"local"`
+	Running bool   `json:"running"`
+}
+
+// dev runs a service on this machine with the environment its deployments
+// receive. Each port of its dependencies is served on 127.0.0.1, relayed to
+// the running dependency through the control plane, so addresses such as
+// localhost:5432 in database URLs keep working.
+func (c *cli) dev(ctx context.Context, args []string) error {
+	fs := c.newFlagSet("dev")
+	var with stringList
+	fs.Var(&with, "with", "Also tunnel to this service (repeatable)")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return errUsage
+	}
+	app, service, ok := strings.Cut(fs.Arg(0), "/")
+	if !ok || app == "" || service == "" {
+		return errUsage
+	}
+	command := fs.Args()[1:]
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+
+	session, err := c.client.CreateDevSession(ctx, app, service, with)
+	if err != nil {
+		return err
+	}
+	if len(command) == 0 && session.StartCommand != "" {
+		command = []string{"sh", "-c", session.StartCommand}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	summary := devSummary{App: session.AppID, Service: session.Service, Command: command, Tunnels: []devTunnel{}}
+	for _, dep := range session.Dependencies {
+		for _, port := range dep.Ports {
+			l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+			if err != nil {
+				return fmt.Errorf("listening for %s on port %d: %w", dep.Service, port, err)
+			}
+			defer l.Close()
+			go c.serveTunnel(ctx, l, session.AppID, dep.Service, port)
+			summary.Tunnels = append(summary.Tunnels, devTunnel{Service: dep.Service, Port: port, Local: l.Addr().String(), Running: dep.Running})
+		}
+	}
+
+	env := make([]string, 0, len(session.Env))
+	for _, v := range session.Env {
+		env = append(env, v.Key+"="+v.Value)
+		if v.Secret {
+			v.Value = maskedValue
+		}
+		summary.Env = append(summary.Env, v)
+	}
+	if err := c.out.result(summary, func(w io.Writer) { printDevSummary(w, summary) }); err != nil {
+		return err
+	}
+
+	if len(command) == 0 {
+		fmt.Fprintln(c.stderr, "No start command; holding tunnels open until interrupted")
+		<-ctx.Done()
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = c.stdin
+	cmd.Stdout = c.out.w
+	cmd.Stderr = c.stderr
+	return cmd.Run()
+}
+
+func printDevSummary(w io.Writer, s devSummary) {
+	fmt.Fprintf(w, "Running %s/%s locally\n", s.App, s.Service)
+	fmt.Fprintln(w, "Environment:")
+	for _, v := range s.Env {
+		fmt.Fprintf(w, "  %s=%s\n", v.Key, v.Value)
+	}
+	if len(s.Tunnels) > 0 {
+		fmt.Fprintln(w, "Tunnels:")
+	}
+	for _, t := range s.Tunnels {
+		note := ""
+		if !t.Running {
+			note = " (not running)"
+		}
+		fmt.Fprintf(w, "  %s -> %s:%d%s\n", t.Local, t.Service, t.Port, note)
+	}
+}
+
+// serveTunnel relays each connection accepted on l to a port of a service
+// until l is closed.
+func (c *cli) serveTunnel(ctx context.Context, l net.Listener, appID, service string, port int) {
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer local.Close()
+			remote, err := c.client.OpenTunnel(ctx, appID, service, port)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "Tunnel to %s:%d failed: %v\n", service, port, err)
+				return
+			}
+			defer remote.Close()
+
+			done := make(chan struct{}, 2)
+			go func() {
+				io.Copy(remote, local)
+				done <- struct{}{}
+			}()
+			go func() {
+				io.Copy(local, remote)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
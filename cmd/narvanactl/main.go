@@ -0,0 +1,249 @@
+// Package main provides narvanactl, a command-line client for the Narvana
+// control plane API. It is intended for scripts and CI: every command accepts
+// -output json for machine-readable output and exits non-zero on failure.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/narvanalabs/control-plane/web/api"
+)
+
+const usage = `Usage: narvanactl [global flags] <command> [flags] [args]
+
+Commands:
+  login -email <email> [-password <password>]   Log in and save the token
+  logout                                         Remove the saved token
+  apps list                                      List applications
+  apps create -name <name> [-description <d>]    Create an application
+  services deploy [-freeze-reason <r>] <app> <service>
+  services stop <app> <service>
+  services start <app> <service>
+  deployments rollback <deployment-id>          Redeploy a deployment's artifact
+  deployments promote [-to <env>] <deployment-id>
+  promotions approve <app> <promotion-id>        Approve a pending promotion
+  promotions reject <app> <promotion-id>         Reject a pending promotion
+  builds list                                    List builds
+  builds retry <build-id>                        Retry a failed build
+  builds submit [-ref <r>] [-commit <sha>] [-meta k=v] [-freeze-reason <r>] <app> <service> <artifact>
+  logs [-service <s>] [-deployment <id>] [-source build|runtime] [-follow] <app>
+  dev [-with <service>] <app>/<service> [-- <command>...]
+  apply [-dry-run] [-prune] <app> [file|-]       Converge an app to its narvana.yaml
+  drift show <app>                               Show changes made outside the app spec
+  drift revert <app>                             Undo them by applying the app spec again
+  drift accept [-o <file>] <app>                 Make the live state the new baseline
+  policy export                                  Print the org RBAC policy as YAML
+  policy apply [-dry-run] <file|->               Apply a policy document
+  keys list                                      List your API keys
+  keys create -name <n> [-app <app> -actions <a,b>] [-expires-in-days <d>]
+  keys revoke <key-id>                           Revoke an API key
+  ssh-keys list                                  List your SSH keys
+  ssh-keys add [-name <n>] <public-key-file|->   Register an SSH public key
+  ssh-keys remove <key-id>                       Remove an SSH key
+  doctor                                         Check the installation (admins)
+  log-level [-component <c>] [-for <d>] [level]  Show or change log levels (admins)
+
+Global flags:
+  -api <url>        API URL (default from config, $NARVANA_API_URL or http://127.0.0.1:8080)
+  -token <token>    API token (default from config or $NARVANA_TOKEN)
+  -org <id>         Organization ID (default from config or $NARVANA_ORG)
+  -output <format>  Output format: text or json (default text)
+
+Apps may be referenced by ID or name. Policy commands act on the org given
+by -org. Keys created with -app may only perform the given actions (read,
+deploy, write) on that app; repeat -app to scope a key to several apps.
+builds submit deploys an image reference or Nix store path built by your own
+CI, skipping Narvana's builder; repeat -meta to record CI details. The
+password for login is read from $NARVANA_PASSWORD or the first line of stdin
+when -password is omitted. dev runs a service on this machine with its
+deployed environment (secrets are masked when printed), serving each port of
+its dependencies on 127.0.0.1 through the control plane; the command defaults
+to the service's start command. Registered SSH keys let owners reach nodes with
+ssh -J narvana@<control-plane>:2222 root@<node-hostname>. doctor lists
+problems with the installation and how to fix them, and exits non-zero if any
+check fails with an error. apply reads narvana.yaml from the current
+directory unless a file is given; with -prune, services it does not list are
+deleted. drift accept prints the app spec of the app as it is, to commit as
+its narvana.yaml. log-level changes the level (debug, info, warn or error) of
+the api, worker or grpc component, or of all of them, until it reverts.
+deployments promote deploys a running deployment's artifact to the next
+environment of its app's pipeline, or the later one given with -to; approve
+it with promotions approve if that environment requires approval.
+`
+
+// errUsage is returned for invalid invocations; the usage text is printed.
+var errUsage = errors.New("invalid usage")
+
+// cli holds the resolved configuration and I/O for a single invocation.
+type cli struct {
+	cfg        *Config
+	configPath string
+	client     *api.Client
+	out        *printer
+	stdin      io.Reader
+	stderr     io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the process exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("narvanactl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	apiURL := fs.String("api", "", "API URL")
+	token := fs.String("token", "", "API token")
+	orgID := fs.String("org", "", "Organization ID")
+	output := fs.String("output", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintln(stderr, "Error: -output must be text or json")
+		return 2
+	}
+
+	configPath, err := defaultConfigPath()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	cfg.applyEnv()
+	cfg.applyFlags(*apiURL, *token, *orgID)
+
+	c := &cli{
+		cfg:        cfg,
+		configPath: configPath,
+		client:     cfg.client(),
+		out:        &printer{w: stdout, json: *output == "json"},
+		stdin:      stdin,
+		stderr:     stderr,
+	}
+
+	if err := c.dispatch(ctx, fs.Arg(0), fs.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// dispatch routes a command and its arguments to the matching handler.
+func (c *cli) dispatch(ctx context.Context, command string, args []string) error {
+	sub := ""
+	rest := args
+	if len(args) > 0 {
+		sub, rest = args[0], args[1:]
+	}
+
+	switch command {
+	case "login":
+		return c.login(ctx, args)
+	case "logout":
+		return c.logout()
+	case "apps":
+		switch sub {
+		case "list":
+			return c.appsList(ctx)
+		case "create":
+			return c.appsCreate(ctx, rest)
+		}
+	case "services":
+		switch sub {
+		case "deploy":
+			return c.servicesDeploy(ctx, rest)
+		case "stop":
+			return c.servicesStop(ctx, rest)
+		case "start":
+			return c.servicesStart(ctx, rest)
+		}
+	case "deployments":
+		switch sub {
+		case "rollback":
+			return c.deploymentsRollback(ctx, rest)
+		case "promote":
+			return c.deploymentsPromote(ctx, rest)
+		}
+	case "promotions":
+		switch sub {
+		case "approve", "reject":
+			return c.promotionsDecide(ctx, sub, rest)
+		}
+	case "builds":
+		switch sub {
+		case "list":
+			return c.buildsList(ctx)
+		case "retry":
+			return c.buildsRetry(ctx, rest)
+		case "submit":
+			return c.buildsSubmit(ctx, rest)
+		}
+	case "logs":
+		return c.logs(ctx, args)
+	case "dev":
+		return c.dev(ctx, args)
+	case "apply":
+		return c.apply(ctx, args)
+	case "drift":
+		switch sub {
+		case "show":
+			return c.driftShow(ctx, rest)
+		case "revert":
+			return c.driftRevert(ctx, rest)
+		case "accept":
+			return c.driftAccept(ctx, rest)
+		}
+	case "policy":
+		switch sub {
+		case "export":
+			return c.policyExport(ctx)
+		case "apply":
+			return c.policyApply(ctx, rest)
+		}
+	case "keys":
+		switch sub {
+		case "list":
+			return c.keysList(ctx)
+		case "create":
+			return c.keysCreate(ctx, rest)
+		case "revoke":
+			return c.keysRevoke(ctx, rest)
+		}
+	case "ssh-keys":
+		switch sub {
+		case "list":
+			return c.sshKeysList(ctx)
+		case "add":
+			return c.sshKeysAdd(ctx, rest)
+		case "remove":
+			return c.sshKeysRemove(ctx, rest)
+		}
+	case "doctor":
+		return c.doctor(ctx)
+	case "log-level":
+		return c.logLevel(ctx, args)
+	case "help":
+		fmt.Fprint(c.out.w, usage)
+		return nil
+	}
+	return errUsage
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer starts an API stub and points the CLI at an empty config file.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	t.Setenv("NARVANA_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv("NARVANA_API_URL", srv.URL)
+	t.Setenv("NARVANA_TOKEN", "")
+	t.Setenv("NARVANA_ORG", "")
+	t.Setenv("NARVANA_PASSWORD", "")
+	return srv
+}
+
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestAppsListJSON(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/apps" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Org-ID"); got != "org-1" {
+			t.Errorf("X-Org-ID = %q", got)
+		}
+		io.WriteString(w, `[{"id":"app-1","name":"api","services":[{"name":"web"}]}]`)
+	})
+
+	code, stdout, stderr := runCLI("", "-token", "tok", "-org", "org-1", "-output", "json", "apps", "list")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	var apps []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &apps); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(apps) != 1 || apps[0]["name"] != "api" {
+		t.Errorf("unexpected apps: %v", apps)
+	}
+}
+
+func TestServicesDeployWithFreezeReason(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/apps/app-1/services/web/deploy" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if got := body["freeze_override"]["reason"]; got != "hotfix" {
+			t.Errorf("freeze_override.reason = %q, want hotfix", got)
+		}
+		io.WriteString(w, `{"id":"dep-1","service_name":"web","version":3,"status":"pending"}`)
+	})
+
+	code, stdout, stderr := runCLI("", "services", "deploy", "-freeze-reason", "hotfix", "app-1", "web")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "dep-1") {
+		t.Errorf("output missing deployment ID: %s", stdout)
+	}
+}
+
+func TestLoginSavesToken(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["email"] != "ci@example.com" || body["password"] != "s3cret" {
+			t.Errorf("unexpected credentials: %v", body)
+		}
+		io.WriteString(w, `{"token":"jwt-token","user_id":"user-1"}`)
+	})
+
+	code, stdout, stderr := runCLI("s3cret\n", "login", "-email", "ci@example.com")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if strings.Contains(stdout, "jwt-token") {
+		t.Errorf("token must not be printed: %s", stdout)
+	}
+
+	path, _ := defaultConfigPath()
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Token != "jwt-token" {
+		t.Errorf("saved token = %q, want jwt-token", cfg.Token)
+	}
+
+	if code, _, stderr := runCLI("", "logout"); code != 0 {
+		t.Fatalf("logout exit code %d, stderr: %s", code, stderr)
+	}
+	cfg, _ = LoadConfig(path)
+	if cfg.Token != "" {
+		t.Errorf("token not cleared on logout: %q", cfg.Token)
+	}
+}
+
+func TestAPIErrorExitCode(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"code":"deploy_frozen","message":"deployments are frozen"}`)
+	})
+
+	code, _, stderr := runCLI("", "services", "deploy", "app-1", "web")
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr, "deploy_frozen") {
+		t.Errorf("stderr missing API error: %s", stderr)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	tests := [][]string{
+		{},
+		{"apps"},
+		{"unknown"},
+		{"services", "deploy", "app-1"},
+		{"builds", "retry"},
+		{"-output", "yaml", "apps", "list"},
+	}
+	for _, args := range tests {
+		if code, _, _ := runCLI("", args...); code != 2 {
+			t.Errorf("%v: exit code = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestPolicyApplyDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policy := "version: 1\nbindings:\n  - user: alice@example.com\n    role: owner\n"
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v1/orgs/org-1/policy" || r.URL.Query().Get("dry_run") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != policy {
+			t.Errorf("policy not sent verbatim: %q", body)
+		}
+		io.WriteString(w, `{"dry_run":true,"changes":[{"action":"remove","kind":"binding","name":"bob@example.com","from":"member"}]}`)
+	})
+
+	code, stdout, stderr := runCLI("", "-org", "org-1", "policy", "apply", "-dry-run", path)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "- binding bob@example.com: member") {
+		t.Errorf("diff not printed: %s", stdout)
+	}
+
+	if code, _, stderr := runCLI("", "policy", "export"); code != 1 || !strings.Contains(stderr, "org is required") {
+		t.Errorf("export without -org: exit code %d, stderr: %s", code, stderr)
+	}
+}
+
+func TestApplyPrune(t *testing.T) {
+	spec := "version: 1\nservices:\n  - name: web\n    git_repo: github.com/acme/shop\n"
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/apps/shop/apply" || r.URL.Query().Get("prune") != "true" || r.URL.Query().Has("dry_run") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != spec {
+			t.Errorf("spec not sent verbatim: %q", body)
+		}
+		io.WriteString(w, `{"dry_run":false,"changes":[{"action":"update","kind":"service","name":"web","fields":["git_repo","replicas"]},{"action":"remove","kind":"service","name":"worker"}]}`)
+	})
+
+	code, stdout, stderr := runCLI(spec, "apply", "-prune", "shop", "-")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	for _, want := range []string{"~ service web: git_repo, replicas", "- service worker", "2 change(s) applied"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q: %s", want, stdout)
+		}
+	}
+}
+
+func TestDriftAccept(t *testing.T) {
+	spec := "version: 1\napp: shop\nservices: []\n"
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/apps/shop/drift/accept" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"app_id": "app-1", "spec": spec, "drift": []any{}})
+	})
+
+	path := filepath.Join(t.TempDir(), "narvana.yaml")
+	code, stdout, stderr := runCLI("", "drift", "accept", "-o", path, "shop")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != spec {
+		t.Errorf("spec file = %q, %v", data, err)
+	}
+	if !strings.Contains(stdout, "written to "+path) {
+		t.Errorf("output: %s", stdout)
+	}
+}
+
+func TestLogLevelSet(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/v1/server/log-level" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if req["component"] != "worker" || req["level"] != "debug" || req["duration"] != "15m" {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"component": "api", "level": "info", "default_level": "info"},
+			{"component": "worker", "level": "debug", "default_level": "info", "revert_at": "2026-01-02T15:04:05Z"},
+		})
+	})
+
+	code, stdout, stderr := runCLI("", "log-level", "-component", "worker", "-for", "15m", "debug")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "worker     debug") || !strings.Contains(stdout, "(to info)") {
+		t.Errorf("output: %s", stdout)
+	}
+}
+
+func TestKeysCreateScoped(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/user/api-keys" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Name   string `json:"name"`
+			Scopes []struct {
+				AppID   string   `json:"app_id"`
+				Actions []string `json:"actions"`
+			} `json:"scopes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if req.Name != "ci" || len(req.Scopes) != 1 || req.Scopes[0].AppID != "my-app" || strings.Join(req.Scopes[0].Actions, ",") != "deploy,read" {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"key-1","name":"ci","prefix":"nrv_abcdefgh","key":"nrv_secret","scopes":[{"app_id":"app-1","actions":["deploy","read"]}]}`)
+	})
+
+	code, stdout, stderr := runCLI("", "keys", "create", "-name", "ci", "-app", "my-app", "-actions", "deploy,read")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "nrv_secret") || !strings.Contains(stdout, "app-1:deploy,read") {
+		t.Errorf("key not printed: %s", stdout)
+	}
+}
+
+func TestBuildsSubmit(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/apps/my-app/services/web/builds" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Artifact  string            `json:"artifact"`
+			GitCommit string            `json:"git_commit"`
+			Metadata  map[string]string `json:"metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if req.Artifact != "ghcr.io/acme/web@sha256:abc" || req.GitCommit != "abc123" || req.Metadata["ci"] != "github-actions" {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"build":{"id":"build-1","status":"succeeded"},"deployment":{"id":"dep-1","service_name":"web","version":4,"status":"built"}}`)
+	})
+
+	code, stdout, stderr := runCLI("", "builds", "submit", "-commit", "abc123", "-meta", "ci=github-actions",
+		"my-app", "web", "ghcr.io/acme/web@sha256:abc")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "build-1") || !strings.Contains(stdout, "web v4") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+
+	if code, _, _ := runCLI("", "builds", "submit", "-meta", "novalue", "my-app", "web", "img:1"); code == 0 {
+		t.Error("invalid -meta accepted")
+	}
+}
+
+func TestDeploymentsRollback(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/deployments/dep-2/rollback" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"id":"dep-3","service_name":"web","version":3,"status":"built","rollback_of":"dep-1"}`)
+	})
+
+	code, stdout, stderr := runCLI("", "deployments", "rollback", "dep-2")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "v3") || !strings.Contains(stdout, "dep-1") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+}
+
+func TestPromotionsApprove(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/apps/shop/promotions/promo-1/approve" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		io.WriteString(w, `{"id":"promo-1","source_deployment_id":"dep-1","target_deployment_id":"dep-9","status":"promoted"}`)
+	})
+
+	code, stdout, stderr := runCLI("", "promotions", "approve", "shop", "promo-1")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "dep-9") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+}
+
+func TestDeploymentsPromote(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/deployments/dep-1/promote" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Environment string `json:"environment"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Environment != "production" {
+			t.Errorf("unexpected request body: %+v (%v)", req, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"promo-1","environment_id":"env-1","source_deployment_id":"dep-1","status":"awaiting_approval",`+
+			`"reason":"waiting for approval to deploy to production"}`)
+	})
+
+	code, stdout, stderr := runCLI("", "deployments", "promote", "-to", "production", "dep-1")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "promo-1 awaiting_approval") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+}
+
+func TestSSHKeysAdd(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/user/ssh-keys" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req struct {
+			Name      string `json:"name"`
+			PublicKey string `json:"public_key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		if req.Name != "laptop" || !strings.HasPrefix(req.PublicKey, "ssh-ed25519 AAAA") {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"key-1","name":"laptop","fingerprint":"SHA256:abc","key_type":"ssh-ed25519"}`)
+	})
+
+	code, stdout, stderr := runCLI("ssh-ed25519 AAAAC3Nza alice@laptop\n", "ssh-keys", "add", "-name", "laptop", "-")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "SHA256:abc") {
+		t.Errorf("fingerprint not printed: %s", stdout)
+	}
+}
+
+func TestDoctorExitsNonZeroOnErrors(t *testing.T) {
+	status := "warning"
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/server/doctor" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"status":%q,"findings":[
+			{"check":"database","severity":"ok","message":"The database is reachable."},
+			{"check":"workers","severity":%q,"message":"No build worker is running.","remedy":"Start a worker."}
+		]}`, status, status)
+	})
+
+	code, stdout, stderr := runCLI("", "doctor")
+	if code != 0 {
+		t.Fatalf("exit code %d with only warnings, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "WARNING") || !strings.Contains(stdout, "Start a worker.") {
+		t.Errorf("finding or remedy not printed: %s", stdout)
+	}
+
+	status = "error"
+	if code, _, _ := runCLI("", "doctor"); code != 1 {
+		t.Errorf("exit code %d with an error finding, want 1", code)
+	}
+}
+
+// freePort returns a local TCP port that is not in use.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// devServer stubs the dev session and tunnel endpoints. The tunnel echoes
+// what it receives.
+func devServer(t *testing.T, port int) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/apps/shop/services/web/dev":
+			var req struct {
+				With []string `json:"with"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.With) != 1 || req.With[0] != "cache" {
+				t.Errorf("with = %v, want [cache]", req.With)
+			}
+			fmt.Fprintf(w, `{"app_id":"app-1","service":"web","env":[
+				{"key":"API_TOKEN","value":"s3cret","secret":true},
+				{"key":"LOG_LEVEL","value":"debug","secret":false}],
+				"dependencies":[{"service":"db","ports":[%d],"running":true}]}`, port)
+		case "/v1/apps/app-1/services/db/tunnel/ws":
+			conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				_, msg, err := conn.ReadMessage()
+				if err != nil || conn.WriteMessage(websocket.BinaryMessage, msg) != nil {
+					return
+				}
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	})
+}
+
+func TestDevRunsCommandWithEnv(t *testing.T) {
+	devServer(t, freePort(t))
+
+	code, stdout, stderr := runCLI("", "dev", "-with", "cache", "shop/web", "--", "sh", "-c", `echo "token=$API_TOKEN level=$LOG_LEVEL"`)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	summary, output, _ := strings.Cut(stdout, "Tunnels:")
+	if strings.Contains(summary, "s3cret") || !strings.Contains(summary, "API_TOKEN=********") {
+		t.Errorf("secret not masked in summary:\n%s", summary)
+	}
+	if !strings.Contains(output, "token=s3cret level=debug") {
+		t.Errorf("command did not get the environment:\n%s", stdout)
+	}
+}
+
+func TestDevTunnels(t *testing.T) {
+	port := freePort(t)
+	devServer(t, port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan int)
+	var stdout, stderr bytes.Buffer
+	go func() {
+		exited <- run(ctx, []string{"dev", "-with", "cache", "shop/web"}, strings.NewReader(""), &stdout, &stderr)
+	}()
+
+	// Wait for the tunnel's local listener
+	var conn net.Conn
+	var err error
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port))); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Errorf("read %q, %v through the tunnel", buf, err)
+	}
+	conn.Close()
+
+	cancel()
+	if code := <-exited; code != 0 {
+		t.Errorf("exit code %d, stderr: %s", code, stderr.String())
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// printer writes command results as aligned text tables or JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// result prints v as indented JSON in JSON mode; otherwise it calls text.
+func (p *printer) result(v any, text func(w io.Writer)) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(p.w)
+	return nil
+}
+
+// line prints v as a single compact JSON line in JSON mode; otherwise it
+// calls text. Used for streams, which are emitted as newline-delimited JSON.
+func (p *printer) line(v any, text func(w io.Writer)) error {
+	if p.json {
+		return json.NewEncoder(p.w).Encode(v)
+	}
+	text(p.w)
+	return nil
+}
+
+// table prints rows under a header with tab-aligned columns.
+func (p *printer) table(header []string, rows [][]string) {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for i, h := range header {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, h)
+	}
+	fmt.Fprintln(tw)
+	for _, row := range rows {
+		for i, col := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, col)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// formatTime renders a timestamp for text output.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,136 @@
+// Package main provides the release notes tool. It generates release notes from a
+// commit list and publishes them to any combination of a GitHub Release,
+// CHANGELOG.md, and the changelog website content directory.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/narvanalabs/control-plane/scripts"
+)
+
+func main() {
+	version := flag.String("version", "", "Version number (e.g., 1.0.0)")
+	dateStr := flag.String("date", "", "Release date (YYYY-MM-DD, default: today)")
+	notesFile := flag.String("notes", "", "Path to file containing commit messages, one per line")
+	manifestFile := flag.String("manifest", "", "Path to a repos manifest for a combined multi-repo release (replaces -notes)")
+	overrideFile := flag.String("override", "", "Path to override file with custom title/intro/closing (optional)")
+	projectName := flag.String("project", scripts.DefaultProjectName, "Project name for release notes")
+	repo := flag.String("repo", "narvanalabs/control-plane", "GitHub repository (owner/name)")
+	noAI := flag.Bool("no-ai", false, "Disable AI summaries and use the deterministic summary for large groups")
+	cachePath := flag.String("summary-cache", scripts.DefaultSummaryCachePath, "Path to the AI summary cache file")
+
+	changelogPath := flag.String("changelog", "", "Append the release to this CHANGELOG.md file")
+	websiteDir := flag.String("website-dir", "", "Write the release entry into this website content directory")
+	githubRelease := flag.Bool("github-release", false, "Create or update the GitHub Release for the version tag")
+	githubToken := flag.String("github-token", "", "GitHub token (or set GITHUB_TOKEN env var)")
+	dryRun := flag.Bool("dry-run", false, "Print the generated release notes instead of publishing")
+	flag.Parse()
+
+	if *version == "" {
+		fmt.Fprintln(os.Stderr, "Error: -version is required")
+		os.Exit(1)
+	}
+	if *dateStr == "" {
+		*dateStr = time.Now().Format("2006-01-02")
+	}
+
+	var rawNotes string
+	if *notesFile != "" {
+		content, err := os.ReadFile(*notesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read notes file: %v\n", err)
+			os.Exit(1)
+		}
+		rawNotes = string(content)
+	}
+
+	config := scripts.ReleaseNotesConfig{
+		Version:     *version,
+		Date:        *dateStr,
+		ProjectName: *projectName,
+	}
+	if *overrideFile != "" {
+		override, err := scripts.ParseOverrideFile(*overrideFile)
+		if err != nil {
+			// Log warning but continue with defaults
+			fmt.Fprintf(os.Stderr, "Warning: failed to parse override file: %v\n", err)
+		} else {
+			config.Title = override.Title
+			config.Introduction = override.Introduction
+			config.Closing = override.Closing
+		}
+	}
+
+	// AI summaries are used only when a provider is configured via RELEASE_NOTES_AI_KEY
+	var summaryOpts scripts.SummaryOptions
+	if provider := scripts.NewChatCompletionSummarizerFromEnv(); provider != nil && !*noAI {
+		cache, err := scripts.LoadSummaryCache(*cachePath)
+		if err != nil {
+			// Log warning but continue without the cache
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		summaryOpts = scripts.SummaryOptions{Provider: provider, Cache: cache}
+	}
+
+	ctx := context.Background()
+	var doc *scripts.ReleaseDocument
+	var err error
+	if *manifestFile != "" {
+		manifest, loadErr := scripts.LoadReposManifest(*manifestFile)
+		if loadErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", loadErr)
+			os.Exit(1)
+		}
+		doc, err = scripts.NewCombinedReleaseDocument(ctx, manifest, config, *repo, summaryOpts)
+	} else {
+		doc, err = scripts.NewReleaseDocument(ctx, rawNotes, config, *repo, summaryOpts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to generate release notes: %v\n", err)
+		os.Exit(1)
+	}
+	if err := summaryOpts.Cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	var publishers []scripts.Publisher
+	if *changelogPath != "" {
+		publishers = append(publishers, &scripts.ChangelogPublisher{Path: *changelogPath})
+	}
+	if *websiteDir != "" {
+		publishers = append(publishers, &scripts.WebsitePublisher{Dir: *websiteDir})
+	}
+	if *githubRelease {
+		token := *githubToken
+		if token == "" {
+			token = os.Getenv("GITHUB_TOKEN")
+		}
+		if token == "" && !*dryRun {
+			fmt.Fprintln(os.Stderr, "Error: GitHub token required. Use -github-token flag or set GITHUB_TOKEN env var")
+			os.Exit(1)
+		}
+		publishers = append(publishers, &scripts.GitHubReleasePublisher{Repo: *repo, Token: token})
+	}
+
+	if *dryRun || len(publishers) == 0 {
+		fmt.Print(doc.Markdown)
+		for _, p := range publishers {
+			fmt.Fprintf(os.Stderr, "Dry run: skipping %s\n", p.Name())
+		}
+		return
+	}
+
+	if err := scripts.PublishAll(ctx, doc, publishers...); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to publish release notes: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, p := range publishers {
+		fmt.Printf("Published v%s to %s\n", doc.Version, p.Name())
+	}
+}
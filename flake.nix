@@ -36,6 +36,7 @@
             golangci-lint
             postgresql_15
             protobuf
+            buf
             protoc-gen-go
             protoc-gen-go-grpc
             overmind  # Process manager for running multiple services
@@ -0,0 +1,328 @@
+// Package admission evaluates an org's admission policies against deploys:
+// build-stage policies when a deploy is requested and deploy-stage policies
+// when a built deployment is about to be scheduled.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Subject is the deploy a policy is evaluated against. Build is only set
+// at the deploy stage.
+type Subject struct {
+	App        *models.App
+	Service    *models.ServiceConfig
+	Deployment *models.Deployment
+	Build      *BuildFacts
+}
+
+// BuildFacts describes the build of a deployment at the deploy stage.
+type BuildFacts struct {
+	ID       string
+	Artifact string
+	// Attested is true if the build has signed SLSA provenance.
+	Attested bool
+}
+
+// Result is the outcome of one policy for a subject.
+type Result struct {
+	PolicyID    string                      `json:"policy_id,omitempty"`
+	PolicyName  string                      `json:"policy_name"`
+	Enforcement models.AdmissionEnforcement `json:"enforcement"`
+	// Matched is false if the policy's match expression excluded the subject.
+	Matched bool   `json:"matched"`
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message,omitempty"`
+	// Error is set when an expression failed to evaluate; the policy is then
+	// treated as violated.
+	Error string `json:"error,omitempty"`
+}
+
+// Decision is the outcome of all of an org's policies for a stage.
+type Decision struct {
+	Stage   models.AdmissionStage `json:"stage"`
+	Results []Result              `json:"results"`
+}
+
+// Violations returns the results of violated policies.
+func (d *Decision) Violations() []Result {
+	var violations []Result
+	for _, r := range d.Results {
+		if !r.Allowed {
+			violations = append(violations, r)
+		}
+	}
+	return violations
+}
+
+// Denied returns true if a deny policy was violated.
+func (d *Decision) Denied() bool {
+	for _, r := range d.Results {
+		if !r.Allowed && r.Enforcement == models.AdmissionEnforcementDeny {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary joins the messages of violated deny policies.
+func (d *Decision) Summary() string {
+	var messages []string
+	for _, r := range d.Results {
+		if !r.Allowed && r.Enforcement == models.AdmissionEnforcementDeny {
+			messages = append(messages, r.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CompilePolicy checks that a policy's expressions compile.
+func CompilePolicy(p *models.AdmissionPolicy) error {
+	if p.Match != "" {
+		if _, err := Compile(p.Match); err != nil {
+			return fmt.Errorf("match: %w", err)
+		}
+	}
+	if _, err := Compile(p.Expression); err != nil {
+		return fmt.Errorf("expression: %w", err)
+	}
+	return nil
+}
+
+// Evaluate runs the enabled policies of a stage against a subject.
+func Evaluate(policies []*models.AdmissionPolicy, stage models.AdmissionStage, subject Subject) *Decision {
+	decision := &Decision{Stage: stage, Results: []Result{}}
+	vars := Input(subject)
+	for _, p := range policies {
+		if !p.Enabled || p.Stage != stage {
+			continue
+		}
+		decision.Results = append(decision.Results, EvaluatePolicy(p, vars))
+	}
+	return decision
+}
+
+// EvaluatePolicy runs one policy against the variables built by Input,
+// regardless of whether it is enabled.
+func EvaluatePolicy(p *models.AdmissionPolicy, vars map[string]any) Result {
+	result := Result{
+		PolicyID:    p.ID,
+		PolicyName:  p.Name,
+		Enforcement: p.Enforcement,
+		Matched:     true,
+		Allowed:     true,
+	}
+	fail := func(err error) Result {
+		result.Allowed = false
+		result.Error = err.Error()
+		result.Message = p.ViolationMessage() + " (policy error: " + err.Error() + ")"
+		return result
+	}
+
+	if p.Match != "" {
+		match, err := Compile(p.Match)
+		if err != nil {
+			return fail(err)
+		}
+		matched, err := match.EvalBool(vars)
+		if err != nil {
+			return fail(err)
+		}
+		if !matched {
+			result.Matched = false
+			return result
+		}
+	}
+
+	expr, err := Compile(p.Expression)
+	if err != nil {
+		return fail(err)
+	}
+	allowed, err := expr.EvalBool(vars)
+	if err != nil {
+		return fail(err)
+	}
+	if !allowed {
+		result.Allowed = false
+		result.Message = p.ViolationMessage()
+	}
+	return result
+}
+
+// Input builds the variables policy expressions are evaluated against:
+// app, service, deployment and, at the deploy stage, build.
+func Input(s Subject) map[string]any {
+	vars := map[string]any{}
+	if s.App != nil {
+		vars["app"] = map[string]any{
+			"id":     s.App.ID,
+			"name":   s.App.Name,
+			"org_id": s.App.OrgID,
+		}
+	}
+	if s.Service != nil {
+		svc := s.Service
+		ports := make([]any, 0, len(svc.Ports))
+		for _, p := range svc.Ports {
+			ports = append(ports, int64(p.ContainerPort))
+		}
+		env := make([]any, 0, len(svc.EnvVars))
+		for k := range svc.EnvVars {
+			env = append(env, k)
+		}
+		dependsOn := make([]any, 0, len(svc.DependsOn))
+		for _, d := range svc.DependsOn {
+			dependsOn = append(dependsOn, d)
+		}
+		cpu, memory := "", ""
+		if svc.Resources != nil {
+			cpu, memory = svc.Resources.CPU, svc.Resources.Memory
+		}
+		serviceType := string(svc.Type)
+		if serviceType == "" {
+			serviceType = string(models.ServiceTypeService)
+		}
+		vars["service"] = map[string]any{
+			"name":              svc.Name,
+			"type":              serviceType,
+			"source_type":       string(svc.SourceType),
+			"image":             svc.Image,
+			"image_tag":         imageTag(svc.Image),
+			"git_repo":          svc.GitRepo,
+			"git_ref":           svc.GitRef,
+			"flake_uri":         svc.FlakeURI,
+			"replicas":          int64(svc.Replicas),
+			"cpu":               cpu,
+			"memory":            memory,
+			"ports":             ports,
+			"env":               env,
+			"depends_on":        dependsOn,
+			"egress_restricted": svc.Egress.Restricts(),
+		}
+	}
+	if s.Deployment != nil {
+		d := s.Deployment
+		vars["deployment"] = map[string]any{
+			"id":         d.ID,
+			"version":    int64(d.Version),
+			"git_ref":    d.GitRef,
+			"build_type": string(d.BuildType),
+			"artifact":   d.Artifact,
+			"rollback":   d.RollbackOf != "",
+		}
+	}
+	if s.Build != nil {
+		vars["build"] = map[string]any{
+			"id":       s.Build.ID,
+			"artifact": s.Build.Artifact,
+			"attested": s.Build.Attested,
+		}
+	}
+	return vars
+}
+
+// imageTag returns the tag of an image reference: "latest" when it has
+// neither tag nor digest, and "" when it is pinned by digest.
+func imageTag(image string) string {
+	if image == "" || strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
+
+// Controller loads an org's policies, evaluates them and records violations.
+type Controller struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewController creates an admission controller.
+func NewController(st store.Store, logger *slog.Logger) *Controller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Controller{store: st, logger: logger}
+}
+
+// Check evaluates the org's policies for a stage. Apps outside an org have
+// no policies.
+func (c *Controller) Check(ctx context.Context, stage models.AdmissionStage, subject Subject) (*Decision, error) {
+	if subject.App == nil || subject.App.OrgID == "" {
+		return &Decision{Stage: stage, Results: []Result{}}, nil
+	}
+	policies, err := c.store.AdmissionPolicies().List(ctx, subject.App.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("listing admission policies: %w", err)
+	}
+	return Evaluate(policies, stage, subject), nil
+}
+
+// BuildFacts loads the build of a deployment and whether it is attested.
+// It returns nil if no build of the deployment can be found, in which case
+// policies that read build fields are violated.
+func (c *Controller) BuildFacts(ctx context.Context, deploymentID string) (*BuildFacts, error) {
+	build, err := c.store.Builds().GetByDeployment(ctx, deploymentID)
+	if err != nil || build == nil {
+		return nil, nil
+	}
+	attestation, err := c.store.BuildAttestations().Get(ctx, build.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting build attestation: %w", err)
+	}
+	return &BuildFacts{
+		ID:       build.ID,
+		Artifact: build.Artifact,
+		Attested: attestation != nil,
+	}, nil
+}
+
+// RecordViolations stores the decision's violations. Failures are logged
+// and don't affect the deploy.
+func (c *Controller) RecordViolations(ctx context.Context, decision *Decision, subject Subject, userID string) {
+	results := decision.Violations()
+	if len(results) == 0 || subject.App == nil {
+		return
+	}
+
+	violations := make([]*models.AdmissionViolation, 0, len(results))
+	for _, r := range results {
+		v := &models.AdmissionViolation{
+			OrgID:       subject.App.OrgID,
+			PolicyID:    r.PolicyID,
+			PolicyName:  r.PolicyName,
+			Stage:       decision.Stage,
+			Enforcement: r.Enforcement,
+			AppID:       subject.App.ID,
+			UserID:      userID,
+			Message:     r.Message,
+		}
+		if subject.Service != nil {
+			v.ServiceName = subject.Service.Name
+		}
+		if subject.Deployment != nil {
+			v.DeploymentID = subject.Deployment.ID
+		}
+		violations = append(violations, v)
+	}
+
+	if err := c.store.AdmissionPolicies().RecordViolations(ctx, violations); err != nil {
+		c.logger.Error("failed to record admission violations", "error", err, "app_id", subject.App.ID)
+		return
+	}
+	c.logger.Warn("admission policies violated",
+		"app_id", subject.App.ID,
+		"stage", decision.Stage,
+		"violations", len(violations),
+		"denied", decision.Denied(),
+	)
+}
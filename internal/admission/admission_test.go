@@ -0,0 +1,178 @@
+package admission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing admission policies.
+type memStore struct {
+	store.Store
+	policies *memPolicies
+}
+
+func (s *memStore) AdmissionPolicies() store.AdmissionPolicyStore { return s.policies }
+
+type memPolicies struct {
+	store.AdmissionPolicyStore
+	policies   []*models.AdmissionPolicy
+	violations []*models.AdmissionViolation
+}
+
+func (m *memPolicies) List(ctx context.Context, orgID string) ([]*models.AdmissionPolicy, error) {
+	var result []*models.AdmissionPolicy
+	for _, p := range m.policies {
+		if p.OrgID == orgID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *memPolicies) RecordViolations(ctx context.Context, violations []*models.AdmissionViolation) error {
+	m.violations = append(m.violations, violations...)
+	return nil
+}
+
+func testSubject(image string, replicas int) Subject {
+	return Subject{
+		App: &models.App{ID: "app-1", Name: "shop-production", OrgID: "org-1"},
+		Service: &models.ServiceConfig{
+			Name:       "web",
+			SourceType: models.SourceTypeImage,
+			Image:      image,
+			Replicas:   replicas,
+		},
+		Deployment: &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web", BuildType: models.BuildTypeOCI},
+	}
+}
+
+func policy(name, match, expression string, enforcement models.AdmissionEnforcement) *models.AdmissionPolicy {
+	return &models.AdmissionPolicy{
+		ID:          name,
+		OrgID:       "org-1",
+		Name:        name,
+		Stage:       models.AdmissionStageBuild,
+		Match:       match,
+		Expression:  expression,
+		Enforcement: enforcement,
+		Enabled:     true,
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                         "latest",
+		"nginx:1.27":                    "1.27",
+		"nginx:latest":                  "latest",
+		"registry.example.com:5000/a":   "latest",
+		"registry.example.com:5000/a:b": "b",
+		"nginx@sha256:abc":              "",
+		"":                              "",
+	}
+	for image, want := range tests {
+		if got := imageTag(image); got != want {
+			t.Errorf("imageTag(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	noLatest := policy("no-latest", "", `service.image_tag != "latest"`, models.AdmissionEnforcementDeny)
+	prodReplicas := policy("prod-replicas", `app.name.endsWith("-production")`, `service.replicas >= 2`, models.AdmissionEnforcementWarn)
+	prodReplicas.Message = "Production services need 2 replicas"
+	disabled := policy("disabled", "", `false`, models.AdmissionEnforcementDeny)
+	disabled.Enabled = false
+	deployStage := policy("signed", "", `build.attested`, models.AdmissionEnforcementDeny)
+	deployStage.Stage = models.AdmissionStageDeploy
+	policies := []*models.AdmissionPolicy{noLatest, prodReplicas, disabled, deployStage}
+
+	decision := Evaluate(policies, models.AdmissionStageBuild, testSubject("nginx:1.27", 2))
+	if len(decision.Results) != 2 || len(decision.Violations()) != 0 || decision.Denied() {
+		t.Fatalf("compliant deploy: %+v", decision)
+	}
+
+	decision = Evaluate(policies, models.AdmissionStageBuild, testSubject("nginx", 1))
+	if !decision.Denied() || len(decision.Violations()) != 2 {
+		t.Fatalf("violating deploy: %+v", decision)
+	}
+	if decision.Summary() != "no-latest" {
+		t.Errorf("Summary() = %q, want only the deny policy", decision.Summary())
+	}
+	if v := decision.Violations()[1]; v.Message != "Production services need 2 replicas" {
+		t.Errorf("warn message = %q", v.Message)
+	}
+
+	// The match expression limits the policy to production apps
+	subject := testSubject("nginx:1.27", 1)
+	subject.App.Name = "shop-staging"
+	decision = Evaluate(policies, models.AdmissionStageBuild, subject)
+	if len(decision.Violations()) != 0 || decision.Results[1].Matched {
+		t.Fatalf("unmatched policy: %+v", decision)
+	}
+}
+
+func TestEvaluateDeployStage(t *testing.T) {
+	signed := policy("signed", "", `has(build.attested) && build.attested`, models.AdmissionEnforcementDeny)
+	signed.Stage = models.AdmissionStageDeploy
+	policies := []*models.AdmissionPolicy{signed}
+
+	subject := testSubject("nginx:1.27", 1)
+	subject.Build = &BuildFacts{ID: "build-1", Artifact: "nginx:1.27", Attested: true}
+	if decision := Evaluate(policies, models.AdmissionStageDeploy, subject); decision.Denied() {
+		t.Fatalf("attested build denied: %+v", decision)
+	}
+
+	subject.Build.Attested = false
+	if decision := Evaluate(policies, models.AdmissionStageDeploy, subject); !decision.Denied() {
+		t.Fatalf("unattested build admitted: %+v", decision)
+	}
+
+	// Without a build the expression fails and the policy fails closed
+	subject.Build = nil
+	decision := Evaluate(policies, models.AdmissionStageDeploy, subject)
+	if !decision.Denied() || decision.Results[0].Error == "" {
+		t.Fatalf("missing build admitted: %+v", decision)
+	}
+	if !strings.Contains(decision.Summary(), "policy error") {
+		t.Errorf("Summary() = %q, want the policy error", decision.Summary())
+	}
+}
+
+func TestControllerCheck(t *testing.T) {
+	policies := &memPolicies{policies: []*models.AdmissionPolicy{
+		policy("no-latest", "", `service.image_tag != "latest"`, models.AdmissionEnforcementDeny),
+	}}
+	c := NewController(&memStore{policies: policies}, nil)
+	ctx := context.Background()
+
+	subject := testSubject("nginx", 1)
+	decision, err := c.Check(ctx, models.AdmissionStageBuild, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decision.Denied() {
+		t.Fatalf("decision = %+v, want denied", decision)
+	}
+
+	c.RecordViolations(ctx, decision, subject, "user-1")
+	if len(policies.violations) != 1 {
+		t.Fatalf("recorded %d violations, want 1", len(policies.violations))
+	}
+	v := policies.violations[0]
+	if v.OrgID != "org-1" || v.PolicyID != "no-latest" || v.AppID != "app-1" || v.ServiceName != "web" ||
+		v.DeploymentID != "dep-1" || v.UserID != "user-1" || v.Stage != models.AdmissionStageBuild {
+		t.Errorf("violation = %+v", v)
+	}
+
+	// Apps outside an org have no policies
+	subject.App.OrgID = ""
+	decision, err = c.Check(ctx, models.AdmissionStageBuild, subject)
+	if err != nil || len(decision.Results) != 0 {
+		t.Fatalf("personal app: decision = %+v, err = %v", decision, err)
+	}
+}
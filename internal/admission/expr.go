@@ -0,0 +1,1003 @@
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxExpressionLength limits the source length of a policy expression.
+const MaxExpressionLength = 2000
+
+// Expr is a compiled policy expression. The language is a subset of CEL:
+// literals (numbers, strings, true, false, null, lists), field selection,
+// indexing, the operators ! - * / % + < <= > >= == != in && || and ?:, the
+// functions size() and has(), the string methods startsWith, endsWith,
+// contains and matches, and the list macros exists and all.
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression.
+func Compile(source string) (*Expr, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, errors.New("expression is empty")
+	}
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("expression must be %d characters or fewer", MaxExpressionLength)
+	}
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the expression's source.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against the given variables.
+func (e *Expr) Eval(vars map[string]any) (any, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the expression and requires a boolean result.
+func (e *Expr) EvalBool(vars map[string]any) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %s, want bool", typeName(v))
+	}
+	return b, nil
+}
+
+// ============ Lexer ============
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokFloat
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the operator and punctuation tokens, longest first.
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=",
+	"!", "<", ">", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]",
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		case unicode.IsDigit(c):
+			start := i
+			kind := tokInt
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				if src[i] == '.' {
+					// A dot not followed by a digit is a selector, e.g. "1.size()"
+					if kind == tokFloat || i+1 >= len(src) || !unicode.IsDigit(rune(src[i+1])) {
+						break
+					}
+					kind = tokFloat
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, text: src[start:i], pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if rune(src[i]) == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+					switch src[i] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					default:
+						sb.WriteByte(src[i])
+					}
+					i++
+					continue
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// ============ Parser ============
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given operator or keyword.
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return fmt.Errorf("expected %q at end of expression", text)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", text, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &conditional{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		left = &logical{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if p.peek().kind != tokOp || (op != "+" && op != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if p.peek().kind != tokOp || (op != "*" && op != "/" && op != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{operand: operand}, nil
+	}
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negate{operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected field name at position %d", t.pos)
+			}
+			if !p.accept("(") {
+				n = &selectField{operand: n, field: t.text}
+				continue
+			}
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			n, err = newMethod(n, t.text, args)
+			if err != nil {
+				return nil, err
+			}
+		case p.accept("["):
+			index, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexed{operand: n, index: index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// parseArgs parses a call's arguments after the opening parenthesis.
+func (p *parser) parseArgs() ([]node, error) {
+	var args []node
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		v, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return &literal{value: v}, nil
+	case tokFloat:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return &literal{value: v}, nil
+	case tokString:
+		return &literal{value: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null":
+			return &literal{value: nil}, nil
+		}
+		if !p.accept("(") {
+			return &ident{name: t.text}, nil
+		}
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		return newFunction(t.text, args)
+	case tokOp:
+		switch t.text {
+		case "(":
+			n, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			var items []node
+			if p.accept("]") {
+				return &list{items: items}, nil
+			}
+			for {
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if p.accept("]") {
+					return &list{items: items}, nil
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case tokEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// ============ Evaluation ============
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literal struct{ value any }
+
+func (n *literal) eval(map[string]any) (any, error) { return n.value, nil }
+
+type list struct{ items []node }
+
+func (n *list) eval(vars map[string]any) (any, error) {
+	values := make([]any, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+type ident struct{ name string }
+
+func (n *ident) eval(vars map[string]any) (any, error) {
+	v, ok := vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("undeclared reference to %q", n.name)
+	}
+	return v, nil
+}
+
+type selectField struct {
+	operand node
+	field   string
+}
+
+func (n *selectField) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot select %q from %s", n.field, typeName(v))
+	}
+	field, ok := m[n.field]
+	if !ok {
+		return nil, fmt.Errorf("no such key: %s", n.field)
+	}
+	return field, nil
+}
+
+type indexed struct{ operand, index node }
+
+func (n *indexed) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	i, err := n.index.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch c := v.(type) {
+	case []any:
+		idx, ok := i.(int64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be int, got %s", typeName(i))
+		}
+		if idx < 0 || idx >= int64(len(c)) {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		return c[idx], nil
+	case map[string]any:
+		key, ok := i.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be string, got %s", typeName(i))
+		}
+		field, ok := c[key]
+		if !ok {
+			return nil, fmt.Errorf("no such key: %s", key)
+		}
+		return field, nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(v))
+}
+
+type conditional struct{ cond, then, otherwise node }
+
+func (n *conditional) eval(vars map[string]any) (any, error) {
+	c, err := evalBool(n.cond, vars)
+	if err != nil {
+		return nil, err
+	}
+	if c {
+		return n.then.eval(vars)
+	}
+	return n.otherwise.eval(vars)
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n *logical) eval(vars map[string]any) (any, error) {
+	left, err := evalBool(n.left, vars)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" && !left {
+		return false, nil
+	}
+	if n.op == "||" && left {
+		return true, nil
+	}
+	return evalBool(n.right, vars)
+}
+
+type not struct{ operand node }
+
+func (n *not) eval(vars map[string]any) (any, error) {
+	v, err := evalBool(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	return !v, nil
+}
+
+type negate struct{ operand node }
+
+func (n *negate) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case int64:
+		return -x, nil
+	case float64:
+		return -x, nil
+	}
+	return nil, fmt.Errorf("cannot negate %s", typeName(v))
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (n *binary) eval(vars map[string]any) (any, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "in":
+		switch c := r.(type) {
+		case []any:
+			for _, item := range c {
+				if equal(l, item) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]any:
+			key, ok := l.(string)
+			if !ok {
+				return false, nil
+			}
+			_, found := c[key]
+			return found, nil
+		}
+		return nil, fmt.Errorf("cannot test membership in %s", typeName(r))
+	case "<", "<=", ">", ">=":
+		cmp, err := compare(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "+":
+		if ls, ok := l.(string); ok {
+			if rs, ok := r.(string); ok {
+				return ls + rs, nil
+			}
+		}
+		if ll, ok := l.([]any); ok {
+			if rl, ok := r.([]any); ok {
+				return append(append([]any{}, ll...), rl...), nil
+			}
+		}
+	}
+	return arithmetic(n.op, l, r)
+}
+
+// arithmetic applies + - * / % to two numbers of the same type.
+func arithmetic(op string, l, r any) (any, error) {
+	switch a := l.(type) {
+	case int64:
+		b, ok := r.(int64)
+		if !ok {
+			break
+		}
+		switch op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/", "%":
+			if b == 0 {
+				return nil, errors.New("division by zero")
+			}
+			if op == "/" {
+				return a / b, nil
+			}
+			return a % b, nil
+		}
+	case float64:
+		b, ok := r.(float64)
+		if !ok {
+			break
+		}
+		switch op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/":
+			return a / b, nil
+		}
+	}
+	return nil, fmt.Errorf("no such operator: %s %s %s", typeName(l), op, typeName(r))
+}
+
+// equal compares values, treating ints and floats with the same value as equal.
+func equal(l, r any) bool {
+	if lf, lok := toFloat(l); lok {
+		rf, rok := toFloat(r)
+		return rok && lf == rf
+	}
+	switch a := l.(type) {
+	case []any:
+		b, ok := r.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := r.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return l == r
+}
+
+// compare orders two numbers or two strings.
+func compare(l, r any) (int, error) {
+	if lf, ok := toFloat(l); ok {
+		if rf, ok := toFloat(r); ok {
+			switch {
+			case lf < rf:
+				return -1, nil
+			case lf > rf:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			return strings.Compare(ls, rs), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", typeName(l), typeName(r))
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+func evalBool(n node, vars map[string]any) (bool, error) {
+	v, err := n.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected bool, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+// ============ Functions ============
+
+// newFunction builds a call to a global function.
+func newFunction(name string, args []node) (node, error) {
+	switch name {
+	case "size":
+		if len(args) != 1 {
+			return nil, errors.New("size() takes one argument")
+		}
+		return &sizeOf{operand: args[0]}, nil
+	case "has":
+		if len(args) != 1 {
+			return nil, errors.New("has() takes one argument")
+		}
+		sel, ok := args[0].(*selectField)
+		if !ok {
+			return nil, errors.New("has() argument must be a field selection, e.g. has(service.image)")
+		}
+		return &hasField{sel: sel}, nil
+	}
+	return nil, fmt.Errorf("unknown function %q", name)
+}
+
+// newMethod builds a call to a method or list macro on target.
+func newMethod(target node, name string, args []node) (node, error) {
+	switch name {
+	case "size":
+		if len(args) != 0 {
+			return nil, errors.New("size() takes no arguments")
+		}
+		return &sizeOf{operand: target}, nil
+	case "startsWith", "endsWith", "contains", "matches":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() takes one argument", name)
+		}
+		if name == "matches" {
+			if lit, ok := args[0].(*literal); ok {
+				pattern, ok := lit.value.(string)
+				if !ok {
+					return nil, errors.New("matches() pattern must be a string")
+				}
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+				return &stringMethod{name: name, target: target, arg: args[0], re: re}, nil
+			}
+		}
+		return &stringMethod{name: name, target: target, arg: args[0]}, nil
+	case "exists", "all":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s() takes a variable and a predicate", name)
+		}
+		v, ok := args[0].(*ident)
+		if !ok {
+			return nil, fmt.Errorf("%s() first argument must be a variable name", name)
+		}
+		return &comprehension{all: name == "all", target: target, variable: v.name, predicate: args[1]}, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", name)
+}
+
+type sizeOf struct{ operand node }
+
+func (n *sizeOf) eval(vars map[string]any) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case string:
+		return int64(len([]rune(x))), nil
+	case []any:
+		return int64(len(x)), nil
+	case map[string]any:
+		return int64(len(x)), nil
+	}
+	return nil, fmt.Errorf("no size for %s", typeName(v))
+}
+
+type hasField struct{ sel *selectField }
+
+func (n *hasField) eval(vars map[string]any) (any, error) {
+	v, err := n.sel.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("has() requires a map, got %s", typeName(v))
+	}
+	field, ok := m[n.sel.field]
+	return ok && field != nil, nil
+}
+
+type stringMethod struct {
+	name        string
+	target, arg node
+	re          *regexp.Regexp
+}
+
+func (n *stringMethod) eval(vars map[string]any) (any, error) {
+	t, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	a, err := n.arg.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if n.name == "contains" {
+		if l, ok := t.([]any); ok {
+			for _, item := range l {
+				if equal(item, a) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+	s, ok := t.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() requires a string, got %s", n.name, typeName(t))
+	}
+	arg, ok := a.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() argument must be a string, got %s", n.name, typeName(a))
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	case "contains":
+		return strings.Contains(s, arg), nil
+	}
+	re := n.re
+	if re == nil {
+		if re, err = regexp.Compile(arg); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+	}
+	return re.MatchString(s), nil
+}
+
+type comprehension struct {
+	all       bool
+	target    node
+	variable  string
+	predicate node
+}
+
+func (n *comprehension) eval(vars map[string]any) (any, error) {
+	t, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	var items []any
+	switch x := t.(type) {
+	case []any:
+		items = x
+	case map[string]any:
+		for k := range x {
+			items = append(items, k)
+		}
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(t))
+	}
+
+	scope := make(map[string]any, len(vars)+1)
+	for k, v := range vars {
+		scope[k] = v
+	}
+	for _, item := range items {
+		scope[n.variable] = item
+		ok, err := evalBool(n.predicate, scope)
+		if err != nil {
+			return nil, err
+		}
+		if ok != n.all {
+			return ok, nil
+		}
+	}
+	return n.all, nil
+}
+
+// typeName names a value's type for error messages.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
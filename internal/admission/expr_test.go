@@ -0,0 +1,119 @@
+package admission
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]any{
+		"service": map[string]any{
+			"name":      "api",
+			"image":     "nginx:1.27",
+			"image_tag": "1.27",
+			"replicas":  int64(2),
+			"ports":     []any{int64(80), int64(443)},
+			"env":       []any{"DATABASE_URL", "PORT"},
+		},
+		"app": map[string]any{"name": "shop-production"},
+	}
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`1 + 2 * 3`, int64(7)},
+		{`(1 + 2) * 3`, int64(9)},
+		{`7 / 2`, int64(3)},
+		{`7 % 4`, int64(3)},
+		{`1.5 + 1.0`, 2.5},
+		{`-service.replicas`, int64(-2)},
+		{`"a" + "b"`, "ab"},
+		{`service.replicas >= 2`, true},
+		{`service.replicas == 2.0`, true},
+		{`service.image_tag != "latest"`, true},
+		{`service.name in ["api", "web"]`, true},
+		{`"PORT" in service.env`, true},
+		{`service.ports[1]`, int64(443)},
+		{`service["name"]`, "api"},
+		{`size(service.ports)`, int64(2)},
+		{`service.env.size()`, int64(2)},
+		{`app.name.endsWith("-production")`, true},
+		{`app.name.startsWith("shop")`, true},
+		{`service.image.contains(":")`, true},
+		{`service.image.matches("^nginx:[0-9.]+$")`, true},
+		{`has(service.image)`, true},
+		{`has(service.flake_uri)`, false},
+		{`service.ports.exists(p, p == 443)`, true},
+		{`service.ports.all(p, p < 1024)`, true},
+		{`service.env.all(e, e.startsWith("D"))`, false},
+		{`!app.name.endsWith("-production") || service.replicas >= 2`, true},
+		{`service.replicas > 1 ? "ha" : "single"`, "ha"},
+		{`false && missing.field`, false},
+		{`true || missing.field`, true},
+		{`[1, 2] == [1, 2]`, true},
+		{`null == null`, true},
+	}
+	for _, tt := range tests {
+		e, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.expr, err)
+			continue
+		}
+		if !equal(got, tt.want) {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`1 +`,
+		`(1 + 2`,
+		`service.`,
+		`"unterminated`,
+		`a ? b`,
+		`size()`,
+		`has(service)`,
+		`x.unknown()`,
+		`x.exists(1, true)`,
+		`1 $ 2`,
+		strings.Repeat("a", MaxExpressionLength+1),
+	} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) succeeded, want error", src)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]any{"service": map[string]any{"replicas": int64(1), "ports": []any{}}}
+	for _, src := range []string{
+		`missing`,
+		`service.missing`,
+		`service.replicas + "a"`,
+		`service.replicas / 0`,
+		`service.ports[0]`,
+		`"a" < 1`,
+		`!service.replicas`,
+	} {
+		e, err := Compile(src)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", src, err)
+		}
+		if _, err := e.Eval(vars); err == nil {
+			t.Errorf("Eval(%q) succeeded, want error", src)
+		}
+	}
+
+	e, _ := Compile(`service.replicas`)
+	if _, err := e.EvalBool(vars); err == nil {
+		t.Error("EvalBool of an int succeeded, want error")
+	}
+}
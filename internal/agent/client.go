@@ -11,6 +11,7 @@ import (
 
 	pb "github.com/narvanalabs/control-plane/api/proto"
 	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -137,7 +138,7 @@ func (c *GRPCClient) getConnection(ctx context.Context, nodeID string) (*grpc.Cl
 	}
 
 	// Set up dial options
-	var opts []grpc.DialOption
+	opts := []grpc.DialOption{tracing.DialOption()}
 	if c.config.TLSConfig != nil {
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(c.config.TLSConfig)))
 	} else {
@@ -443,6 +444,7 @@ func convertRuntimeConfigToProto(cfg *models.RuntimeConfig) *pb.RuntimeConfig {
 			IntervalSeconds: int32(cfg.HealthCheck.IntervalSeconds),
 			TimeoutSeconds:  int32(cfg.HealthCheck.TimeoutSeconds),
 			Retries:         int32(cfg.HealthCheck.Retries),
+			Command:         cfg.HealthCheck.Command,
 		}
 	}
 
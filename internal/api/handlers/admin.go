@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Settings keys used by the admin console.
+const (
+	// featureFlagPrefix prefixes every feature flag stored in the settings table.
+	featureFlagPrefix = "feature_flag."
+	// SettingLicenseKey holds the license key for the instance.
+	SettingLicenseKey = "license_key"
+	// SettingLicenseExpiresAt holds the RFC 3339 expiry of the license.
+	SettingLicenseExpiresAt = "license_expires_at"
+)
+
+// AdminHandler handles the instance admin console endpoints.
+// All routes are expected to be mounted behind middleware.RequireAdmin.
+type AdminHandler struct {
+	store        store.Store
+	authService  *auth.Service
+	buildTimeout time.Duration
+	logger       *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler.
+// buildTimeout is used to flag running builds that have exceeded their budget.
+func NewAdminHandler(st store.Store, authService *auth.Service, buildTimeout time.Duration, logger *slog.Logger) *AdminHandler {
+	if buildTimeout <= 0 {
+		buildTimeout = 30 * time.Minute
+	}
+	return &AdminHandler{
+		store:        st,
+		authService:  authService,
+		buildTimeout: buildTimeout,
+		logger:       logger,
+	}
+}
+
+// AdminOverview is the summary returned for the admin console landing page.
+type AdminOverview struct {
+	Users               int            `json:"users"`
+	Orgs                int            `json:"orgs"`
+	Apps                int            `json:"apps"`
+	Services            int            `json:"services"`
+	ActiveAnnouncements int            `json:"active_announcements"`
+	Jobs                *JobHealth     `json:"jobs"`
+	License             *LicenseStatus `json:"license"`
+}
+
+// OrgUsage describes resource usage for a single organization.
+type OrgUsage struct {
+	Org                *models.Organization `json:"org"`
+	Members            int                  `json:"members"`
+	Apps               int                  `json:"apps"`
+	Services           int                  `json:"services"`
+	RunningDeployments int                  `json:"running_deployments"`
+}
+
+// JobHealth summarizes the state of the background build queue.
+type JobHealth struct {
+	Healthy            bool  `json:"healthy"`
+	QueuedBuilds       int   `json:"queued_builds"`
+	RunningBuilds      int   `json:"running_builds"`
+	StuckBuilds        int   `json:"stuck_builds"`
+	OldestQueuedSecs   int64 `json:"oldest_queued_seconds"`
+	PendingDeployments int   `json:"pending_deployments"`
+}
+
+// LicenseStatus describes the license attached to the instance.
+type LicenseStatus struct {
+	Edition   string     `json:"edition"`
+	Licensed  bool       `json:"licensed"`
+	Expired   bool       `json:"expired"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ImpersonateRequest is the request body for starting an impersonation session.
+type ImpersonateRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// ImpersonateResponse carries a short-lived token for the impersonated user.
+type ImpersonateResponse struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      *store.User `json:"user"`
+}
+
+// Overview handles GET /v1/admin/overview - returns instance-wide totals.
+func (h *AdminHandler) Overview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	users, err := h.store.Users().List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list users", "error", err)
+		WriteInternalError(w, "Failed to load users")
+		return
+	}
+
+	orgs, err := h.store.Orgs().ListAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to list organizations", "error", err)
+		WriteInternalError(w, "Failed to load organizations")
+		return
+	}
+
+	apps, err := h.store.Apps().ListAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to list apps", "error", err)
+		WriteInternalError(w, "Failed to load apps")
+		return
+	}
+
+	services := 0
+	for _, app := range apps {
+		services += len(app.Services)
+	}
+
+	jobs, err := h.jobHealth(ctx)
+	if err != nil {
+		h.logger.Error("failed to compute job health", "error", err)
+		WriteInternalError(w, "Failed to load job health")
+		return
+	}
+
+	settings, err := h.store.Settings().GetAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to load settings", "error", err)
+		WriteInternalError(w, "Failed to load settings")
+		return
+	}
+
+	announcements, err := h.store.Announcements().ListActive(ctx, time.Now())
+	if err != nil {
+		h.logger.Error("failed to list announcements", "error", err)
+		WriteInternalError(w, "Failed to load announcements")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, &AdminOverview{
+		Users:               len(users),
+		Orgs:                len(orgs),
+		Apps:                len(apps),
+		Services:            services,
+		ActiveAnnouncements: len(announcements),
+		Jobs:                jobs,
+		License:             licenseFromSettings(settings, time.Now()),
+	})
+}
+
+// ListUsers handles GET /v1/admin/users - lists every user on the instance.
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.store.Users().List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list users", "error", err)
+		WriteInternalError(w, "Failed to list users")
+		return
+	}
+	if users == nil {
+		users = []*store.User{}
+	}
+	WriteJSON(w, http.StatusOK, users)
+}
+
+// ListOrgs handles GET /v1/admin/orgs - lists every organization with its usage.
+func (h *AdminHandler) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgs, err := h.store.Orgs().ListAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to list organizations", "error", err)
+		WriteInternalError(w, "Failed to list organizations")
+		return
+	}
+
+	usage := make([]*OrgUsage, 0, len(orgs))
+	for _, org := range orgs {
+		u, err := h.orgUsage(ctx, org)
+		if err != nil {
+			h.logger.Error("failed to compute org usage", "error", err, "org_id", org.ID)
+			WriteInternalError(w, "Failed to compute organization usage")
+			return
+		}
+		usage = append(usage, u)
+	}
+
+	WriteJSON(w, http.StatusOK, usage)
+}
+
+// ListApps handles GET /v1/admin/apps - lists every app across all organizations.
+func (h *AdminHandler) ListApps(w http.ResponseWriter, r *http.Request) {
+	apps, err := h.store.Apps().ListAll(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list apps", "error", err)
+		WriteInternalError(w, "Failed to list apps")
+		return
+	}
+	if apps == nil {
+		apps = []*models.App{}
+	}
+	WriteJSON(w, http.StatusOK, apps)
+}
+
+// JobHealth handles GET /v1/admin/jobs - reports build queue health.
+func (h *AdminHandler) JobHealth(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.jobHealth(r.Context())
+	if err != nil {
+		h.logger.Error("failed to compute job health", "error", err)
+		WriteInternalError(w, "Failed to load job health")
+		return
+	}
+	WriteJSON(w, http.StatusOK, jobs)
+}
+
+// License handles GET /v1/admin/license - reports the instance license status.
+func (h *AdminHandler) License(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.store.Settings().GetAll(r.Context())
+	if err != nil {
+		h.logger.Error("failed to load settings", "error", err)
+		WriteInternalError(w, "Failed to load license")
+		return
+	}
+	WriteJSON(w, http.StatusOK, licenseFromSettings(settings, time.Now()))
+}
+
+// ListFeatureFlags handles GET /v1/admin/feature-flags - returns all feature flags.
+func (h *AdminHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.store.Settings().GetAll(r.Context())
+	if err != nil {
+		h.logger.Error("failed to load settings", "error", err)
+		WriteInternalError(w, "Failed to load feature flags")
+		return
+	}
+	WriteJSON(w, http.StatusOK, featureFlagsFromSettings(settings))
+}
+
+// UpdateFeatureFlags handles PUT /v1/admin/feature-flags - sets one or more flags.
+func (h *AdminHandler) UpdateFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	var req map[string]bool
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	for name, enabled := range req {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			WriteBadRequest(w, "feature flag name is required")
+			return
+		}
+		value := "false"
+		if enabled {
+			value = "true"
+		}
+		if err := h.store.Settings().Set(ctx, featureFlagPrefix+name, value); err != nil {
+			h.logger.Error("failed to set feature flag", "error", err, "flag", name)
+			WriteInternalError(w, "Failed to update feature flags")
+			return
+		}
+	}
+
+	h.logger.Info("feature flags updated", "user_id", middleware.GetUserID(ctx), "count", len(req))
+
+	settings, err := h.store.Settings().GetAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to load settings", "error", err)
+		WriteInternalError(w, "Failed to load feature flags")
+		return
+	}
+	WriteJSON(w, http.StatusOK, featureFlagsFromSettings(settings))
+}
+
+// Impersonate handles POST /v1/admin/impersonate - issues a session token for another user.
+// This is the entry point used by operators to reproduce what a user sees.
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	var req ImpersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.UserID == "" {
+		WriteBadRequest(w, "user_id is required")
+		return
+	}
+
+	ctx := r.Context()
+	adminID := middleware.GetUserID(ctx)
+	if req.UserID == adminID {
+		WriteBadRequest(w, "cannot impersonate yourself")
+		return
+	}
+
+	target, err := h.store.Users().GetByID(ctx, req.UserID)
+	if err != nil {
+		h.logger.Error("failed to get user", "error", err, "user_id", req.UserID)
+		WriteInternalError(w, "Failed to load user")
+		return
+	}
+	if target == nil {
+		WriteNotFound(w, "User not found")
+		return
+	}
+
+	token, expiresAt, err := h.authService.GenerateImpersonationToken(target.ID, target.Email, adminID)
+	if err != nil {
+		h.logger.Error("failed to generate impersonation token", "error", err)
+		WriteInternalError(w, "Failed to start impersonation")
+		return
+	}
+
+	// The token is only handed out once the impersonation is on record
+	entry := &models.AuditEntry{
+		UserID:       adminID,
+		UserEmail:    middleware.GetUserEmail(ctx),
+		Action:       "admin.impersonate",
+		ResourceType: "user",
+		ResourceID:   target.ID,
+		Path:         r.URL.Path,
+		Status:       http.StatusOK,
+		Changes: []models.AuditChange{
+			{Field: "impersonated_user", New: target.Email},
+			{Field: "expires_at", New: expiresAt},
+		},
+		RequestID:  chimiddleware.GetReqID(ctx),
+		RemoteAddr: r.RemoteAddr,
+	}
+	if err := h.store.Audit().Record(ctx, entry); err != nil {
+		h.logger.Error("failed to record impersonation", "error", err, "admin_id", adminID, "target_user_id", target.ID)
+		WriteInternalError(w, "Failed to start impersonation")
+		return
+	}
+
+	h.logger.Warn("admin impersonation started",
+		"admin_id", adminID,
+		"target_user_id", target.ID,
+		"target_email", target.Email,
+		"expires_at", expiresAt,
+	)
+
+	WriteJSON(w, http.StatusOK, &ImpersonateResponse{Token: token, ExpiresAt: expiresAt, User: target})
+}
+
+// orgUsage computes usage figures for a single organization.
+func (h *AdminHandler) orgUsage(ctx context.Context, org *models.Organization) (*OrgUsage, error) {
+	members, err := h.store.Orgs().ListMembers(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := h.store.Apps().ListByOrg(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	services := 0
+	for _, app := range apps {
+		services += len(app.Services)
+	}
+
+	running, err := h.store.Deployments().CountByStatusAndOrg(ctx, models.DeploymentStatusRunning, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrgUsage{
+		Org:                org,
+		Members:            len(members),
+		Apps:               len(apps),
+		Services:           services,
+		RunningDeployments: running,
+	}, nil
+}
+
+// jobHealth inspects the build queue and pending deployments.
+func (h *AdminHandler) jobHealth(ctx context.Context) (*JobHealth, error) {
+	queued, err := h.store.Builds().ListQueued(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := h.store.Builds().ListRunning(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := h.store.Deployments().ListByStatus(ctx, models.DeploymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeJobHealth(queued, running, len(pending), h.buildTimeout, time.Now()), nil
+}
+
+// computeJobHealth derives queue health from the queued and running builds.
+// A running build is stuck when it has been running longer than its timeout.
+func computeJobHealth(queued, running []*models.BuildJob, pendingDeployments int, defaultTimeout time.Duration, now time.Time) *JobHealth {
+	health := &JobHealth{
+		QueuedBuilds:       len(queued),
+		RunningBuilds:      len(running),
+		PendingDeployments: pendingDeployments,
+	}
+
+	for _, build := range queued {
+		age := int64(now.Sub(build.CreatedAt).Seconds())
+		if age > health.OldestQueuedSecs {
+			health.OldestQueuedSecs = age
+		}
+	}
+
+	for _, build := range running {
+		if build.StartedAt == nil {
+			continue
+		}
+		timeout := defaultTimeout
+		if build.TimeoutSeconds > 0 {
+			timeout = time.Duration(build.TimeoutSeconds) * time.Second
+		}
+		if now.Sub(*build.StartedAt) > timeout {
+			health.StuckBuilds++
+		}
+	}
+
+	health.Healthy = health.StuckBuilds == 0
+	return health
+}
+
+// featureFlagsFromSettings extracts feature flags from the settings map.
+func featureFlagsFromSettings(settings map[string]string) map[string]bool {
+	flags := make(map[string]bool)
+	for key, value := range settings {
+		if name, ok := strings.CutPrefix(key, featureFlagPrefix); ok {
+			flags[name] = value == "true"
+		}
+	}
+	return flags
+}
+
+// licenseFromSettings derives the license status from the settings map.
+func licenseFromSettings(settings map[string]string, now time.Time) *LicenseStatus {
+	status := &LicenseStatus{Edition: "community"}
+	if strings.TrimSpace(settings[SettingLicenseKey]) == "" {
+		return status
+	}
+
+	status.Edition = "enterprise"
+	status.Licensed = true
+	if raw := settings[SettingLicenseExpiresAt]; raw != "" {
+		if expiresAt, err := time.Parse(time.RFC3339, raw); err == nil {
+			status.ExpiresAt = &expiresAt
+			if now.After(expiresAt) {
+				status.Expired = true
+				status.Licensed = false
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// **Feature: admin-console, Property 1: Stuck Build Detection**
+// *For any* set of running builds, job health SHALL count exactly those builds that have
+// been running longer than their timeout as stuck, and report unhealthy when any exist.
+
+func TestComputeJobHealthStuckBuilds(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	now := time.Now()
+	defaultTimeout := 30 * time.Minute
+
+	properties.Property("stuck builds are those past their timeout", prop.ForAll(
+		func(numStuck, numHealthy, numQueued int) bool {
+			var running []*models.BuildJob
+			for i := 0; i < numStuck; i++ {
+				started := now.Add(-defaultTimeout - time.Minute)
+				running = append(running, &models.BuildJob{StartedAt: &started})
+			}
+			for i := 0; i < numHealthy; i++ {
+				started := now.Add(-time.Minute)
+				running = append(running, &models.BuildJob{StartedAt: &started})
+			}
+
+			var queued []*models.BuildJob
+			for i := 0; i < numQueued; i++ {
+				queued = append(queued, &models.BuildJob{CreatedAt: now.Add(-time.Duration(i+1) * time.Second)})
+			}
+
+			health := computeJobHealth(queued, running, 0, defaultTimeout, now)
+
+			if health.StuckBuilds != numStuck || health.RunningBuilds != numStuck+numHealthy {
+				return false
+			}
+			if health.QueuedBuilds != numQueued {
+				return false
+			}
+			if numQueued > 0 && health.OldestQueuedSecs != int64(numQueued) {
+				return false
+			}
+			return health.Healthy == (numStuck == 0)
+		},
+		gen.IntRange(0, 5),
+		gen.IntRange(0, 5),
+		gen.IntRange(0, 5),
+	))
+
+	properties.TestingRun(t)
+}
+
+// TestComputeJobHealthPerBuildTimeout verifies a build's own timeout overrides the default.
+func TestComputeJobHealthPerBuildTimeout(t *testing.T) {
+	now := time.Now()
+	started := now.Add(-10 * time.Minute)
+	running := []*models.BuildJob{{StartedAt: &started, TimeoutSeconds: 300}}
+
+	health := computeJobHealth(nil, running, 0, time.Hour, now)
+	if health.StuckBuilds != 1 || health.Healthy {
+		t.Errorf("expected build past its own timeout to be stuck, got %+v", health)
+	}
+}
+
+// **Feature: admin-console, Property 2: License Status Derivation**
+// *For any* license expiry, the license SHALL be reported as expired exactly when the
+// expiry is in the past, and an instance without a key SHALL be unlicensed.
+
+func TestLicenseFromSettings(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	now := time.Now().Truncate(time.Second)
+
+	properties.Property("expiry relative to now determines license validity", prop.ForAll(
+		func(offsetHours int) bool {
+			expiresAt := now.Add(time.Duration(offsetHours) * time.Hour)
+			status := licenseFromSettings(map[string]string{
+				SettingLicenseKey:       "key",
+				SettingLicenseExpiresAt: expiresAt.Format(time.RFC3339),
+			}, now)
+
+			expired := offsetHours < 0
+			return status.Expired == expired && status.Licensed == !expired && status.ExpiresAt != nil
+		},
+		gen.IntRange(-1000, 1000).SuchThat(func(v int) bool { return v != 0 }),
+	))
+
+	properties.Property("missing license key is unlicensed community edition", prop.ForAll(
+		func(expiry string) bool {
+			status := licenseFromSettings(map[string]string{SettingLicenseExpiresAt: expiry}, now)
+			return !status.Licensed && status.Edition == "community"
+		},
+		gen.AlphaString(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// TestFeatureFlagsFromSettings verifies only prefixed settings are treated as flags.
+func TestFeatureFlagsFromSettings(t *testing.T) {
+	flags := featureFlagsFromSettings(map[string]string{
+		featureFlagPrefix + "previews": "true",
+		featureFlagPrefix + "metrics":  "false",
+		"server_domain":                "example.com",
+	})
+
+	if len(flags) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(flags))
+	}
+	if !flags["previews"] || flags["metrics"] {
+		t.Errorf("unexpected flag values: %v", flags)
+	}
+}
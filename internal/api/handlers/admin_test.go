@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// recordingAuditStore keeps the entries recorded.
+type recordingAuditStore struct {
+	store.AuditStore
+	entries []*models.AuditEntry
+}
+
+func (m *recordingAuditStore) Record(ctx context.Context, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// impersonateMockStore provides users and records audit entries.
+type impersonateMockStore struct {
+	store.Store
+	users *roleUserStore
+	audit *recordingAuditStore
+}
+
+func (m *impersonateMockStore) Users() store.UserStore  { return m.users }
+func (m *impersonateMockStore) Audit() store.AuditStore { return m.audit }
+
+func TestImpersonate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	authService := auth.NewService(&auth.Config{JWTSecret: []byte("4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c"), TokenExpiry: 24 * time.Hour}, nil, logger)
+	audit := &recordingAuditStore{}
+	h := NewAdminHandler(&impersonateMockStore{users: &roleUserStore{role: store.RoleMember}, audit: audit}, authService, 0, logger)
+
+	rr := httptest.NewRecorder()
+	h.Impersonate(rr, templateRequest(http.MethodPost, "/v1/admin/impersonate", ImpersonateRequest{UserID: "user-2"}, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp ImpersonateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if d := time.Until(resp.ExpiresAt); d <= 0 || d > auth.ImpersonationTokenExpiry {
+		t.Errorf("token expires in %v, want at most %v", d, auth.ImpersonationTokenExpiry)
+	}
+
+	claims, err := authService.ValidateToken(resp.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.UserID != "user-2" || claims.ImpersonatedBy != "user-1" {
+		t.Errorf("claims = %+v, want user-2 impersonated by user-1", claims)
+	}
+
+	if len(audit.entries) != 1 {
+		t.Fatalf("recorded %d audit entries, want 1", len(audit.entries))
+	}
+	entry := audit.entries[0]
+	if entry.UserID != "user-1" || entry.Action != "admin.impersonate" || entry.ResourceType != "user" || entry.ResourceID != "user-2" {
+		t.Errorf("audit entry = %+v, want user-1 impersonating user-2", entry)
+	}
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrCodeAdmissionDenied is returned when a deploy violates a deny admission policy.
+const ErrCodeAdmissionDenied = "admission_denied"
+
+const (
+	defaultAdmissionViolations = 50
+	maxAdmissionViolations     = 500
+)
+
+// AdmissionPolicyHandler handles org admission policy HTTP requests.
+type AdmissionPolicyHandler struct {
+	store      store.Store
+	controller *admission.Controller
+	logger     *slog.Logger
+}
+
+// NewAdmissionPolicyHandler creates a new admission policy handler.
+func NewAdmissionPolicyHandler(st store.Store, logger *slog.Logger) *AdmissionPolicyHandler {
+	return &AdmissionPolicyHandler{
+		store:      st,
+		controller: admission.NewController(st, logger),
+		logger:     logger,
+	}
+}
+
+// AdmissionPolicyRequest is the request body for creating or updating an admission policy.
+type AdmissionPolicyRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Stage       string `json:"stage,omitempty"`
+	Match       string `json:"match,omitempty"`
+	Expression  string `json:"expression"`
+	Message     string `json:"message,omitempty"`
+	Enforcement string `json:"enforcement,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"` // Defaults to true
+}
+
+// apply copies the request onto a policy.
+func (req *AdmissionPolicyRequest) apply(p *models.AdmissionPolicy) {
+	p.Name = req.Name
+	p.Description = req.Description
+	p.Stage = models.AdmissionStage(req.Stage)
+	p.Match = strings.TrimSpace(req.Match)
+	p.Expression = strings.TrimSpace(req.Expression)
+	p.Message = req.Message
+	p.Enforcement = models.AdmissionEnforcement(req.Enforcement)
+	p.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// EvaluateAdmissionRequest is the request body for a dry-run evaluation.
+type EvaluateAdmissionRequest struct {
+	AppID       string `json:"app_id"`
+	ServiceName string `json:"service_name"`
+	Stage       string `json:"stage,omitempty"` // Defaults to build
+	GitRef      string `json:"git_ref,omitempty"`
+	// DeploymentID evaluates an existing deployment of the service and, at
+	// the deploy stage, its build. Otherwise a new deploy of the service is
+	// evaluated.
+	DeploymentID string `json:"deployment_id,omitempty"`
+	// Policy evaluates an unsaved policy instead of the org's policies.
+	Policy *AdmissionPolicyRequest `json:"policy,omitempty"`
+}
+
+// List handles GET /v1/orgs/{orgID}/admission-policies - lists an org's admission policies.
+func (h *AdmissionPolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	policies, err := h.store.AdmissionPolicies().List(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list admission policies", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list admission policies")
+		return
+	}
+	if policies == nil {
+		policies = []*models.AdmissionPolicy{}
+	}
+	WriteJSON(w, http.StatusOK, policies)
+}
+
+// Get handles GET /v1/orgs/{orgID}/admission-policies/{policyID} - gets an admission policy.
+func (h *AdmissionPolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	policy, ok := h.findPolicy(w, r, orgID)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, policy)
+}
+
+// Create handles POST /v1/orgs/{orgID}/admission-policies - creates an admission policy (owners only).
+func (h *AdmissionPolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	var req AdmissionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	policy := &models.AdmissionPolicy{
+		ID:        uuid.New().String(),
+		OrgID:     orgID,
+		CreatedBy: middleware.GetUserID(ctx),
+	}
+	req.apply(policy)
+	if !validatePolicy(w, policy) || !h.requireUniqueName(w, r, policy) {
+		return
+	}
+
+	if err := h.store.AdmissionPolicies().Create(ctx, policy); err != nil {
+		h.logger.Error("failed to create admission policy", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to create admission policy")
+		return
+	}
+
+	h.logger.Info("admission policy created",
+		"policy_id", policy.ID,
+		"org_id", orgID,
+		"stage", policy.Stage,
+		"enforcement", policy.Enforcement,
+		"user_id", policy.CreatedBy,
+	)
+	WriteJSON(w, http.StatusCreated, policy)
+}
+
+// Update handles PUT /v1/orgs/{orgID}/admission-policies/{policyID} - replaces an admission policy (owners only).
+func (h *AdmissionPolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	policy, ok := h.findPolicy(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	var req AdmissionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.apply(policy)
+	if !validatePolicy(w, policy) || !h.requireUniqueName(w, r, policy) {
+		return
+	}
+
+	if err := h.store.AdmissionPolicies().Update(ctx, policy); err != nil {
+		h.logger.Error("failed to update admission policy", "error", err, "policy_id", policy.ID)
+		WriteInternalError(w, "Failed to update admission policy")
+		return
+	}
+
+	h.logger.Info("admission policy updated", "policy_id", policy.ID, "org_id", orgID)
+	WriteJSON(w, http.StatusOK, policy)
+}
+
+// Delete handles DELETE /v1/orgs/{orgID}/admission-policies/{policyID} - removes an admission policy (owners only).
+func (h *AdmissionPolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	policy, ok := h.findPolicy(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	if err := h.store.AdmissionPolicies().Delete(r.Context(), policy.ID); err != nil {
+		h.logger.Error("failed to delete admission policy", "error", err, "policy_id", policy.ID)
+		WriteInternalError(w, "Failed to delete admission policy")
+		return
+	}
+
+	h.logger.Info("admission policy deleted", "policy_id", policy.ID, "org_id", orgID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Evaluate handles POST /v1/orgs/{orgID}/admission-policies/evaluate - evaluates
+// policies against a service without deploying it or recording violations.
+func (h *AdmissionPolicyHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	var req EvaluateAdmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	stage := models.AdmissionStage(req.Stage)
+	switch stage {
+	case "":
+		stage = models.AdmissionStageBuild
+	case models.AdmissionStageBuild, models.AdmissionStageDeploy:
+	default:
+		WriteBadRequest(w, "stage must be one of: build, deploy")
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, req.AppID)
+	if err != nil || app == nil || app.OrgID != orgID {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == req.ServiceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		WriteNotFound(w, "Service not found")
+		return
+	}
+
+	var subject admission.Subject
+	if req.DeploymentID != "" {
+		deployment, err := h.store.Deployments().Get(ctx, req.DeploymentID)
+		if err != nil || deployment == nil || deployment.AppID != app.ID || deployment.ServiceName != service.Name {
+			WriteNotFound(w, "Deployment not found")
+			return
+		}
+		subject = admission.Subject{App: app, Service: service, Deployment: deployment}
+		if stage == models.AdmissionStageDeploy {
+			subject.Build, err = h.controller.BuildFacts(ctx, deployment.ID)
+			if err != nil {
+				h.logger.Error("failed to load build for admission", "error", err, "deployment_id", deployment.ID)
+				WriteInternalError(w, "Failed to evaluate admission policies")
+				return
+			}
+		}
+	} else {
+		subject = admissionSubject(app, service, req.GitRef)
+	}
+
+	if req.Policy != nil {
+		policy := &models.AdmissionPolicy{OrgID: orgID}
+		req.Policy.apply(policy)
+		if !validatePolicy(w, policy) {
+			return
+		}
+		policy.Stage = stage
+		policy.Enabled = true
+		WriteJSON(w, http.StatusOK, admission.Evaluate([]*models.AdmissionPolicy{policy}, stage, subject))
+		return
+	}
+
+	decision, err := h.controller.Check(ctx, stage, subject)
+	if err != nil {
+		h.logger.Error("failed to evaluate admission policies", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to evaluate admission policies")
+		return
+	}
+	WriteJSON(w, http.StatusOK, decision)
+}
+
+// ListViolations handles GET /v1/orgs/{orgID}/admission-violations - lists recent
+// policy violations, optionally for one app.
+func (h *AdmissionPolicyHandler) ListViolations(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	limit := defaultAdmissionViolations
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAdmissionViolations {
+			WriteBadRequest(w, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	violations, err := h.store.AdmissionPolicies().ListViolations(r.Context(), orgID, r.URL.Query().Get("app_id"), limit)
+	if err != nil {
+		h.logger.Error("failed to list admission violations", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list admission violations")
+		return
+	}
+	if violations == nil {
+		violations = []*models.AdmissionViolation{}
+	}
+	WriteJSON(w, http.StatusOK, violations)
+}
+
+// findPolicy loads the policy in the URL, writing a not found response unless it belongs to the org.
+func (h *AdmissionPolicyHandler) findPolicy(w http.ResponseWriter, r *http.Request, orgID string) (*models.AdmissionPolicy, bool) {
+	policyID := chi.URLParam(r, "policyID")
+	policy, err := h.store.AdmissionPolicies().Get(r.Context(), policyID)
+	if err != nil {
+		h.logger.Error("failed to get admission policy", "error", err, "policy_id", policyID)
+		WriteInternalError(w, "Failed to load admission policy")
+		return nil, false
+	}
+	if policy == nil || policy.OrgID != orgID {
+		WriteNotFound(w, "Admission policy not found")
+		return nil, false
+	}
+	return policy, true
+}
+
+// requireUniqueName writes a conflict response if another of the org's policies has the policy's name.
+func (h *AdmissionPolicyHandler) requireUniqueName(w http.ResponseWriter, r *http.Request, policy *models.AdmissionPolicy) bool {
+	policies, err := h.store.AdmissionPolicies().List(r.Context(), policy.OrgID)
+	if err != nil {
+		h.logger.Error("failed to list admission policies", "error", err, "org_id", policy.OrgID)
+		WriteInternalError(w, "Failed to save admission policy")
+		return false
+	}
+	for _, existing := range policies {
+		if existing.ID != policy.ID && strings.EqualFold(existing.Name, policy.Name) {
+			WriteConflict(w, "An admission policy with this name already exists")
+			return false
+		}
+	}
+	return true
+}
+
+// validatePolicy validates a policy and compiles its expressions, writing a
+// bad request response if either fails.
+func validatePolicy(w http.ResponseWriter, policy *models.AdmissionPolicy) bool {
+	if err := policy.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	if err := admission.CompilePolicy(policy); err != nil {
+		WriteBadRequest(w, "Invalid policy "+err.Error())
+		return false
+	}
+	return true
+}
+
+// requireMember writes a forbidden response unless the current user belongs to the org.
+func (h *AdmissionPolicyHandler) requireMember(w http.ResponseWriter, r *http.Request, orgID string) bool {
+	isMember, err := h.store.Orgs().IsMember(r.Context(), orgID, middleware.GetUserID(r.Context()))
+	if err != nil {
+		h.logger.Error("failed to check org membership", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to verify organization membership")
+		return false
+	}
+	if !isMember {
+		WriteForbidden(w, "Not a member of this organization")
+		return false
+	}
+	return true
+}
+
+// requirePermission writes a forbidden response unless the current user can manage policies.
+func (h *AdmissionPolicyHandler) requirePermission(w http.ResponseWriter, r *http.Request) bool {
+	if err := userHasPermission(r.Context(), h.store, middleware.GetUserID(r.Context()), auth.PermissionManageAdmissionPolicies); err != nil {
+		WriteForbidden(w, "Only owners can manage admission policies")
+		return false
+	}
+	return true
+}
+
+// admissionSubject describes a new deploy of a service for build-stage
+// policies. The deployment has no ID or version yet.
+func admissionSubject(app *models.App, svc *models.ServiceConfig, gitRef string) admission.Subject {
+	if gitRef == "" {
+		gitRef = svc.GitRef
+	}
+	return admission.Subject{
+		App:     app,
+		Service: svc,
+		Deployment: &models.Deployment{
+			AppID:       app.ID,
+			ServiceName: svc.Name,
+			GitRef:      gitRef,
+			BuildType:   determineBuildType(svc),
+		},
+	}
+}
+
+// checkAdmission evaluates the org's build-stage admission policies against
+// new deploys before any deployment is created. It writes an error response
+// and returns false if a deny policy is violated; warn violations are
+// recorded and the deploy goes ahead.
+func (h *DeploymentHandler) checkAdmission(w http.ResponseWriter, r *http.Request, subjects []admission.Subject) bool {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	controller := admission.NewController(h.store, h.logger)
+
+	var violations []admission.Result
+	var denied []string
+	for _, subject := range subjects {
+		decision, err := controller.Check(ctx, models.AdmissionStageBuild, subject)
+		if err != nil {
+			h.logger.Error("failed to check admission policies", "error", err, "app_id", subject.App.ID)
+			WriteInternalError(w, "Failed to check admission policies")
+			return false
+		}
+		controller.RecordViolations(ctx, decision, subject, userID)
+		violations = append(violations, decision.Violations()...)
+		if decision.Denied() {
+			denied = append(denied, subject.Service.Name+": "+decision.Summary())
+		}
+	}
+
+	if len(denied) > 0 {
+		WriteErrorWithDetails(w, http.StatusUnprocessableEntity, ErrCodeAdmissionDenied,
+			"Deploy rejected by admission policies ("+strings.Join(denied, "; ")+")", violations)
+		return false
+	}
+	return true
+}
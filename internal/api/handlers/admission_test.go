@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockAdmissionPolicyStore implements store.AdmissionPolicyStore for testing.
+type mockAdmissionPolicyStore struct {
+	policies   []*models.AdmissionPolicy
+	violations []*models.AdmissionViolation
+}
+
+func (m *mockAdmissionPolicyStore) Create(ctx context.Context, policy *models.AdmissionPolicy) error {
+	m.policies = append(m.policies, policy)
+	return nil
+}
+
+func (m *mockAdmissionPolicyStore) Get(ctx context.Context, id string) (*models.AdmissionPolicy, error) {
+	for _, p := range m.policies {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAdmissionPolicyStore) List(ctx context.Context, orgID string) ([]*models.AdmissionPolicy, error) {
+	var result []*models.AdmissionPolicy
+	for _, p := range m.policies {
+		if p.OrgID == orgID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAdmissionPolicyStore) Update(ctx context.Context, policy *models.AdmissionPolicy) error {
+	return nil
+}
+
+func (m *mockAdmissionPolicyStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockAdmissionPolicyStore) RecordViolations(ctx context.Context, violations []*models.AdmissionViolation) error {
+	m.violations = append(m.violations, violations...)
+	return nil
+}
+
+func (m *mockAdmissionPolicyStore) ListViolations(ctx context.Context, orgID, appID string, limit int) ([]*models.AdmissionViolation, error) {
+	return m.violations, nil
+}
+
+// memberOrgStore reports every user as a member of one org.
+type memberOrgStore struct {
+	store.OrgStore
+	orgID string
+}
+
+func (m *memberOrgStore) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
+	return orgID == m.orgID, nil
+}
+
+// admissionMockStore adds admission policies and orgs to the freeze mock store.
+type admissionMockStore struct {
+	*freezeMockStore
+	admission *mockAdmissionPolicyStore
+	orgs      *memberOrgStore
+}
+
+func (m *admissionMockStore) AdmissionPolicies() store.AdmissionPolicyStore { return m.admission }
+func (m *admissionMockStore) Orgs() store.OrgStore                          { return m.orgs }
+
+func newAdmissionMockStore(role store.Role) *admissionMockStore {
+	st := &admissionMockStore{
+		freezeMockStore: &freezeMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			freezes:             &mockDeployFreezeStore{},
+			users:               &roleUserStore{role: role},
+		},
+		admission: &mockAdmissionPolicyStore{},
+		orgs:      &memberOrgStore{orgID: "org-1"},
+	}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:      "app-1",
+		OrgID:   "org-1",
+		OwnerID: "user-1",
+		Name:    "shop-production",
+		Services: []models.ServiceConfig{{
+			Name:       "web",
+			SourceType: models.SourceTypeImage,
+			Image:      "nginx:latest",
+			Replicas:   1,
+		}},
+	}
+	return st
+}
+
+func TestAdmissionPolicyCreate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	params := map[string]string{"orgID": "org-1"}
+	valid := AdmissionPolicyRequest{Name: "no-latest", Expression: `service.image_tag != "latest"`}
+
+	tests := []struct {
+		name   string
+		role   store.Role
+		orgID  string
+		req    AdmissionPolicyRequest
+		status int
+	}{
+		{"owner creates policy", store.RoleOwner, "org-1", valid, http.StatusCreated},
+		{"members cannot manage policies", store.RoleMember, "org-1", valid, http.StatusForbidden},
+		{"non-members are rejected", store.RoleOwner, "org-2", valid, http.StatusForbidden},
+		{"invalid expression", store.RoleOwner, "org-1", AdmissionPolicyRequest{Name: "bad", Expression: `service.`}, http.StatusBadRequest},
+		{"invalid stage", store.RoleOwner, "org-1", AdmissionPolicyRequest{Name: "bad", Stage: "run", Expression: `true`}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newAdmissionMockStore(tt.role)
+			rr := httptest.NewRecorder()
+			NewAdmissionPolicyHandler(st, logger).Create(rr, templateRequest(http.MethodPost,
+				"/v1/orgs/"+tt.orgID+"/admission-policies", tt.req, map[string]string{"orgID": tt.orgID}))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	// Names are unique within an org
+	st := newAdmissionMockStore(store.RoleOwner)
+	h := NewAdmissionPolicyHandler(st, logger)
+	for _, status := range []int{http.StatusCreated, http.StatusConflict} {
+		rr := httptest.NewRecorder()
+		h.Create(rr, templateRequest(http.MethodPost, "/v1/orgs/org-1/admission-policies", valid, params))
+		if rr.Code != status {
+			t.Fatalf("status = %d, want %d: %s", rr.Code, status, rr.Body.String())
+		}
+	}
+	p := st.admission.policies[0]
+	if p.Stage != models.AdmissionStageBuild || p.Enforcement != models.AdmissionEnforcementDeny || !p.Enabled || p.CreatedBy != "user-1" {
+		t.Errorf("unexpected defaults: %+v", p)
+	}
+}
+
+func TestAdmissionPolicyEvaluate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newAdmissionMockStore(store.RoleMember)
+	st.admission.policies = []*models.AdmissionPolicy{{
+		ID: "p1", OrgID: "org-1", Name: "no-latest", Stage: models.AdmissionStageBuild,
+		Expression: `service.image_tag != "latest"`, Enforcement: models.AdmissionEnforcementDeny, Enabled: true,
+	}}
+	h := NewAdmissionPolicyHandler(st, logger)
+	params := map[string]string{"orgID": "org-1"}
+
+	// The org's policies
+	rr := httptest.NewRecorder()
+	h.Evaluate(rr, templateRequest(http.MethodPost, "/v1/orgs/org-1/admission-policies/evaluate",
+		EvaluateAdmissionRequest{AppID: "app-1", ServiceName: "web"}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var decision admission.Decision
+	json.Unmarshal(rr.Body.Bytes(), &decision)
+	if !decision.Denied() || decision.Results[0].PolicyName != "no-latest" {
+		t.Fatalf("decision = %+v, want no-latest violated", decision)
+	}
+
+	// An unsaved policy
+	rr = httptest.NewRecorder()
+	h.Evaluate(rr, templateRequest(http.MethodPost, "/v1/orgs/org-1/admission-policies/evaluate",
+		EvaluateAdmissionRequest{AppID: "app-1", ServiceName: "web", Policy: &AdmissionPolicyRequest{
+			Name:       "prod-replicas",
+			Match:      `app.name.endsWith("-production")`,
+			Expression: `service.replicas >= 2`,
+		}}, params))
+	decision = admission.Decision{}
+	json.Unmarshal(rr.Body.Bytes(), &decision)
+	if rr.Code != http.StatusOK || len(decision.Results) != 1 || decision.Results[0].Allowed {
+		t.Fatalf("status = %d, decision = %+v", rr.Code, decision)
+	}
+
+	// Dry runs don't record violations
+	if len(st.admission.violations) != 0 {
+		t.Errorf("dry run recorded %d violations", len(st.admission.violations))
+	}
+}
+
+func TestCreateDeployment_Admission(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name        string
+		enforcement models.AdmissionEnforcement
+		status      int
+		deployments int
+	}{
+		{"deny rejects the deploy", models.AdmissionEnforcementDeny, http.StatusUnprocessableEntity, 0},
+		{"warn lets the deploy go ahead", models.AdmissionEnforcementWarn, http.StatusAccepted, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newAdmissionMockStore(store.RoleOwner)
+			st.admission.policies = []*models.AdmissionPolicy{{
+				ID: "p1", OrgID: "org-1", Name: "no-latest", Stage: models.AdmissionStageBuild,
+				Expression: `service.image_tag != "latest"`, Message: "Pin image tags",
+				Enforcement: tt.enforcement, Enabled: true,
+			}}
+
+			rr := httptest.NewRecorder()
+			NewDeploymentHandler(st, newMockQueue(), logger).Create(rr,
+				templateRequest(http.MethodPost, "/v1/apps/app-1/deploy", CreateDeploymentRequest{}, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status == http.StatusUnprocessableEntity {
+				var apiErr APIError
+				json.Unmarshal(rr.Body.Bytes(), &apiErr)
+				if apiErr.Code != ErrCodeAdmissionDenied {
+					t.Errorf("code = %q, want %q", apiErr.Code, ErrCodeAdmissionDenied)
+				}
+			}
+			if got := len(st.deploymentStore.deployments); got != tt.deployments {
+				t.Errorf("created %d deployments, want %d", got, tt.deployments)
+			}
+			if len(st.admission.violations) != 1 {
+				t.Fatalf("recorded %d violations, want 1", len(st.admission.violations))
+			}
+			if v := st.admission.violations[0]; v.Message != "Pin image tags" || v.ServiceName != "web" || v.UserID != "user-1" {
+				t.Errorf("unexpected violation: %+v", v)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// AnnouncementsHandler handles announcement banner HTTP requests.
+type AnnouncementsHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewAnnouncementsHandler creates a new announcements handler.
+func NewAnnouncementsHandler(st store.Store, logger *slog.Logger) *AnnouncementsHandler {
+	return &AnnouncementsHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// AnnouncementRequest is the request body for creating or updating an announcement.
+type AnnouncementRequest struct {
+	Message  string     `json:"message"`
+	Level    string     `json:"level"`
+	Audience string     `json:"audience"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// apply copies the request fields onto an announcement and validates the result.
+func (req *AnnouncementRequest) apply(a *models.Announcement) error {
+	a.Message = req.Message
+	a.Level = models.AnnouncementLevel(req.Level)
+	a.Audience = models.AnnouncementAudience(req.Audience)
+	a.StartsAt = req.StartsAt
+	a.EndsAt = req.EndsAt
+	return a.Validate()
+}
+
+// ListActive handles GET /v1/announcements - returns banners for the current user.
+func (h *AnnouncementsHandler) ListActive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, err := h.store.Users().GetByID(ctx, middleware.GetUserID(ctx))
+	if err != nil {
+		h.logger.Error("failed to get user", "error", err)
+		WriteInternalError(w, "Failed to load announcements")
+		return
+	}
+
+	announcements, err := h.store.Announcements().ListActive(ctx, time.Now())
+	if err != nil {
+		h.logger.Error("failed to list announcements", "error", err)
+		WriteInternalError(w, "Failed to load announcements")
+		return
+	}
+
+	visible := []*models.Announcement{}
+	for _, a := range announcements {
+		if user != nil && a.IsVisibleTo(models.Role(user.Role)) {
+			visible = append(visible, a)
+		}
+	}
+
+	WriteJSON(w, http.StatusOK, visible)
+}
+
+// List handles GET /v1/admin/announcements - returns every announcement (admin only).
+func (h *AnnouncementsHandler) List(w http.ResponseWriter, r *http.Request) {
+	announcements, err := h.store.Announcements().List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list announcements", "error", err)
+		WriteInternalError(w, "Failed to list announcements")
+		return
+	}
+	if announcements == nil {
+		announcements = []*models.Announcement{}
+	}
+
+	WriteJSON(w, http.StatusOK, announcements)
+}
+
+// Create handles POST /v1/admin/announcements - publishes a new announcement (admin only).
+func (h *AnnouncementsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	announcement := &models.Announcement{CreatedBy: middleware.GetUserID(ctx)}
+	if err := req.apply(announcement); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.store.Announcements().Create(ctx, announcement); err != nil {
+		h.logger.Error("failed to create announcement", "error", err)
+		WriteInternalError(w, "Failed to create announcement")
+		return
+	}
+
+	h.logger.Info("announcement created",
+		"announcement_id", announcement.ID,
+		"user_id", announcement.CreatedBy,
+		"level", announcement.Level,
+	)
+	WriteJSON(w, http.StatusCreated, announcement)
+}
+
+// Update handles PUT /v1/admin/announcements/{announcementID} - edits an announcement (admin only).
+func (h *AnnouncementsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	announcementID := chi.URLParam(r, "announcementID")
+
+	announcement, err := h.store.Announcements().Get(ctx, announcementID)
+	if err != nil {
+		h.logger.Error("failed to get announcement", "error", err, "announcement_id", announcementID)
+		WriteInternalError(w, "Failed to load announcement")
+		return
+	}
+	if announcement == nil {
+		WriteNotFound(w, "Announcement not found")
+		return
+	}
+
+	var req AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := req.apply(announcement); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.store.Announcements().Update(ctx, announcement); err != nil {
+		h.logger.Error("failed to update announcement", "error", err, "announcement_id", announcementID)
+		WriteInternalError(w, "Failed to update announcement")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, announcement)
+}
+
+// Delete handles DELETE /v1/admin/announcements/{announcementID} - removes an announcement (admin only).
+func (h *AnnouncementsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	announcementID := chi.URLParam(r, "announcementID")
+
+	announcement, err := h.store.Announcements().Get(ctx, announcementID)
+	if err != nil {
+		h.logger.Error("failed to get announcement", "error", err, "announcement_id", announcementID)
+		WriteInternalError(w, "Failed to load announcement")
+		return
+	}
+	if announcement == nil {
+		WriteNotFound(w, "Announcement not found")
+		return
+	}
+
+	if err := h.store.Announcements().Delete(ctx, announcementID); err != nil {
+		h.logger.Error("failed to delete announcement", "error", err, "announcement_id", announcementID)
+		WriteInternalError(w, "Failed to delete announcement")
+		return
+	}
+
+	h.logger.Info("announcement deleted", "announcement_id", announcementID, "user_id", middleware.GetUserID(ctx))
+	w.WriteHeader(http.StatusNoContent)
+}
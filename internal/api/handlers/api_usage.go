@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/versioning"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+const (
+	// defaultAPIUsageWindow is how far back usage is listed by default.
+	defaultAPIUsageWindow = 30 * 24 * time.Hour
+	defaultAPIUsageLimit  = 100
+	maxAPIUsageLimit      = 1000
+)
+
+// APIUsageHandler handles the API versioning policy and the usage of
+// deprecated routes and versions.
+type APIUsageHandler struct {
+	store      store.Store
+	negotiator *versioning.Negotiator
+	logger     *slog.Logger
+}
+
+// NewAPIUsageHandler creates a new API usage handler.
+func NewAPIUsageHandler(st store.Store, negotiator *versioning.Negotiator, logger *slog.Logger) *APIUsageHandler {
+	return &APIUsageHandler{
+		store:      st,
+		negotiator: negotiator,
+		logger:     logger,
+	}
+}
+
+// APIUsageResponse is the versioning policy with the clients still using
+// deprecated routes and versions.
+type APIUsageResponse struct {
+	CurrentVersion string                   `json:"current_version"`
+	DefaultVersion string                   `json:"default_version"`
+	Versions       []versioning.Version     `json:"versions"`
+	Deprecations   []versioning.Deprecation `json:"deprecations"`
+	Usage          []*models.APIUsage       `json:"usage"`
+}
+
+// Get handles GET /v1/admin/api-usage - returns the supported API versions,
+// the deprecated routes, and the clients that used them within since
+// (default 720h), most recently seen first. The results can be narrowed to
+// one route, e.g. ?route=POST+/v1/nodes/heartbeat.
+func (h *APIUsageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	window := defaultAPIUsageWindow
+	if s := r.URL.Query().Get("since"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			WriteBadRequest(w, "Invalid since: must be a positive duration such as \"24h\"")
+			return
+		}
+		window = d
+	}
+	limit := defaultAPIUsageLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAPIUsageLimit {
+			WriteBadRequest(w, "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+
+	// Include usage counted since the last flush
+	h.negotiator.Flush(r.Context())
+	usage, err := h.store.APIUsage().List(r.Context(), models.APIUsageFilter{
+		Route: r.URL.Query().Get("route"),
+		Since: time.Now().Add(-window),
+		Limit: limit,
+	})
+	if err != nil {
+		h.logger.Error("failed to list API usage", "error", err)
+		WriteInternalError(w, "Failed to list API usage")
+		return
+	}
+	if usage == nil {
+		usage = []*models.APIUsage{}
+	}
+
+	policy := h.negotiator.Policy()
+	deprecations := policy.Deprecations
+	if deprecations == nil {
+		deprecations = []versioning.Deprecation{}
+	}
+	WriteJSON(w, http.StatusOK, APIUsageResponse{
+		CurrentVersion: h.negotiator.Current(),
+		DefaultVersion: policy.Default,
+		Versions:       policy.Versions,
+		Deprecations:   deprecations,
+		Usage:          usage,
+	})
+}
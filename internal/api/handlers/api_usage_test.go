@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/versioning"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// apiUsageMockStore keeps recorded API usage in memory.
+type apiUsageMockStore struct {
+	*opsMockStore
+	usage  []*models.APIUsage
+	filter models.APIUsageFilter
+}
+
+func (m *apiUsageMockStore) APIUsage() store.APIUsageStore { return apiUsageStore{s: m} }
+
+type apiUsageStore struct {
+	store.APIUsageStore
+	s *apiUsageMockStore
+}
+
+func (a apiUsageStore) Record(ctx context.Context, usage []*models.APIUsage) error {
+	a.s.usage = append(a.s.usage, usage...)
+	return nil
+}
+
+func (a apiUsageStore) List(ctx context.Context, filter models.APIUsageFilter) ([]*models.APIUsage, error) {
+	a.s.filter = filter
+	return a.s.usage, nil
+}
+
+func TestAPIUsage(t *testing.T) {
+	st := &apiUsageMockStore{opsMockStore: newOpsMockStore()}
+	negotiator := versioning.NewNegotiator(st, versioning.DefaultPolicy(), versioning.DefaultConfig(), nil)
+	h := NewAPIUsageHandler(st, negotiator, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Count a request to a deprecated route that is not yet flushed
+	router := chi.NewRouter()
+	router.Use(negotiator.Middleware(router))
+	router.Post("/v1/nodes/heartbeat", func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest(http.MethodPost, "/v1/nodes/heartbeat", nil)
+	req.Header.Set(versioning.ClientHeader, "node-agent/0.9.0")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	h.Get(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/api-usage?route=POST+/v1/nodes/heartbeat&limit=10", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp APIUsageResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.CurrentVersion != versioning.Current || resp.DefaultVersion != versioning.V1_0 || len(resp.Versions) != 2 || len(resp.Deprecations) != 1 {
+		t.Errorf("policy = %+v", resp)
+	}
+	if len(resp.Usage) != 1 || resp.Usage[0].Client != "node-agent/0.9.0" || resp.Usage[0].Count != 1 {
+		t.Errorf("usage = %+v, want the unflushed request", resp.Usage)
+	}
+	if st.filter.Route != "POST /v1/nodes/heartbeat" || st.filter.Limit != 10 || st.filter.Since.IsZero() {
+		t.Errorf("filter = %+v", st.filter)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/api-usage?since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid since: status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxAPIKeyExpiryDays is the longest lifetime an API key can be given.
+const maxAPIKeyExpiryDays = 365
+
+// APIKeyHandler handles API key management for the current user.
+type APIKeyHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler.
+func NewAPIKeyHandler(st store.Store, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key.
+// Scopes may name apps by ID or name; without scopes the key has the full
+// access of its user.
+type CreateAPIKeyRequest struct {
+	Name          string               `json:"name"`
+	ExpiresInDays int                  `json:"expires_in_days,omitempty"`
+	Scopes        []models.APIKeyScope `json:"scopes,omitempty"`
+}
+
+// CreateAPIKeyResponse returns a new key. The key is only shown in this response.
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// List handles GET /v1/user/api-keys - lists the current user's API keys.
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	keys, err := h.store.APIKeys().ListByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list api keys", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to list API keys")
+		return
+	}
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+	WriteJSON(w, http.StatusOK, keys)
+}
+
+// Create handles POST /v1/user/api-keys - creates an API key, optionally
+// restricted to actions on specific apps.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		WriteBadRequest(w, models.ErrAPIKeyNameRequired.Error())
+		return
+	}
+	if len(req.Name) > 100 {
+		WriteBadRequest(w, "API key name must be 100 characters or fewer")
+		return
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPIKeyExpiryDays {
+		WriteBadRequest(w, "expires_in_days must be between 0 and 365")
+		return
+	}
+
+	for i := range req.Scopes {
+		scope := &req.Scopes[i]
+		if err := scope.Validate(); err != nil {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		if scope.AppID == models.APIKeyAllApps {
+			continue
+		}
+		appID, err := h.resolveApp(r, userID, scope.AppID)
+		if err != nil {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		scope.AppID = appID
+	}
+
+	raw, err := auth.GenerateAPIKey()
+	if err != nil {
+		h.logger.Error("failed to generate api key", "error", err)
+		WriteInternalError(w, "Failed to create API key")
+		return
+	}
+
+	key := &models.APIKey{
+		UserID:  userID,
+		KeyHash: auth.HashAPIKey(raw),
+		Prefix:  raw[:12],
+		Name:    req.Name,
+		Scopes:  req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		key.ExpiresAt = time.Now().AddDate(0, 0, req.ExpiresInDays)
+	}
+
+	if err := h.store.APIKeys().Create(ctx, key); err != nil {
+		h.logger.Error("failed to create api key", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to create API key")
+		return
+	}
+
+	h.logger.Info("api key created", "key_id", key.ID, "user_id", userID, "scopes", len(key.Scopes))
+	WriteJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+// Delete handles DELETE /v1/user/api-keys/{keyID} - revokes one of the current user's API keys.
+func (h *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	keyID := chi.URLParam(r, "keyID")
+
+	keys, err := h.store.APIKeys().ListByUser(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to list api keys", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to revoke API key")
+		return
+	}
+	found := false
+	for _, k := range keys {
+		if k.ID == keyID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		WriteNotFound(w, "API key not found")
+		return
+	}
+
+	if err := h.store.APIKeys().Delete(ctx, keyID); err != nil {
+		h.logger.Error("failed to delete api key", "error", err, "key_id", keyID)
+		WriteInternalError(w, "Failed to revoke API key")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// errScopeAppNotFound is returned when a scope names an app the user cannot access.
+var errScopeAppNotFound = errors.New("scope app not found")
+
+// resolveApp resolves an app ID or name in a scope to the ID of an app the user can access.
+func (h *APIKeyHandler) resolveApp(r *http.Request, userID, idOrName string) (string, error) {
+	ctx := r.Context()
+	app, err := h.store.Apps().Get(ctx, idOrName)
+	if err != nil {
+		if app, err = h.store.Apps().GetByName(ctx, userID, idOrName); err != nil {
+			return "", fmt.Errorf("%w: %s", errScopeAppNotFound, idOrName)
+		}
+	}
+	if app.OwnerID == userID {
+		return app.ID, nil
+	}
+	if app.OrgID != "" {
+		isMember, err := h.store.Orgs().IsMember(ctx, app.OrgID, userID)
+		if err == nil && isMember {
+			return app.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", errScopeAppNotFound, idOrName)
+}
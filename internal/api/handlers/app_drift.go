@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/drift"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// GetDrift handles GET /v1/apps/{appID}/drift - returns the app's manifest,
+// the app spec last applied to it, with the changes made to the app since.
+func (h *ServiceHandler) GetDrift(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.ownedApp(w, r)
+	if !ok {
+		return
+	}
+	manifest, ok := h.manifest(w, r, app)
+	if !ok {
+		return
+	}
+
+	changes, err := drift.Detect(ctx, h.store, manifest)
+	if err != nil {
+		h.logger.Error("failed to detect app drift", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to detect drift")
+		return
+	}
+	now := time.Now()
+	if err := h.store.AppManifests().UpdateDrift(ctx, app.ID, changes, now); err != nil {
+		h.logger.Error("failed to record app drift", "error", err, "app_id", app.ID)
+	}
+	if changes == nil {
+		changes = []models.AppSpecChange{}
+	}
+	manifest.Drift, manifest.DriftCheckedAt = changes, &now
+	WriteJSON(w, http.StatusOK, manifest)
+}
+
+// RevertDrift handles POST /v1/apps/{appID}/drift/revert - applies the app's
+// manifest again, undoing the changes made to the services, env vars and
+// domains it manages. Secrets are not reverted; changes to them remain until
+// accepted.
+func (h *ServiceHandler) RevertDrift(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.ownedApp(w, r)
+	if !ok {
+		return
+	}
+	manifest, ok := h.manifest(w, r, app)
+	if !ok {
+		return
+	}
+
+	spec, err := appspec.Parse([]byte(manifest.Spec))
+	if err == nil {
+		err = spec.Validate()
+	}
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	// The app may have been renamed since the spec was applied
+	spec.App = ""
+	plan, ok := h.convergeApp(w, r, app, spec, drift.Options(manifest), false)
+	if !ok {
+		return
+	}
+
+	if changes, err := drift.Detect(ctx, h.store, manifest); err == nil {
+		if err := h.store.AppManifests().UpdateDrift(ctx, app.ID, changes, time.Now()); err != nil {
+			h.logger.Error("failed to record app drift", "error", err, "app_id", app.ID)
+		}
+	}
+
+	h.logger.Info("app reverted to its manifest", "app_id", app.ID, "changes", len(plan.Changes))
+	WriteJSON(w, http.StatusOK, ApplySpecResponse{Changes: plan.Changes})
+}
+
+// AcceptDrift handles POST /v1/apps/{appID}/drift/accept - makes the app's
+// live state its new baseline: the manifest is replaced by a spec of the
+// app's current services, env vars and domains, returned for committing as
+// its narvana.yaml, and by its current secrets.
+func (h *ServiceHandler) AcceptDrift(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.ownedApp(w, r)
+	if !ok {
+		return
+	}
+	previous, ok := h.manifest(w, r, app)
+	if !ok {
+		return
+	}
+
+	domains, err := h.store.Domains().List(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to list domains", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to accept drift")
+		return
+	}
+	data, err := appspec.FromApp(app, domains).Encode()
+	if err != nil {
+		h.logger.Error("failed to encode app spec", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to accept drift")
+		return
+	}
+
+	manifest := &models.AppManifest{
+		AppID:     app.ID,
+		Spec:      string(data),
+		Prune:     previous.Prune,
+		Resources: previous.Resources,
+		AppliedBy: middleware.GetUserID(ctx),
+	}
+	if err := drift.Save(ctx, h.store, manifest); err != nil {
+		h.logger.Error("failed to save app manifest", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to accept drift")
+		return
+	}
+	manifest.Drift = []models.AppSpecChange{}
+
+	h.logger.Info("app drift accepted as its manifest", "app_id", app.ID)
+	WriteJSON(w, http.StatusOK, manifest)
+}
+
+// manifest returns the app's manifest, writing a not found response if no
+// app spec has been applied to it.
+func (h *ServiceHandler) manifest(w http.ResponseWriter, r *http.Request, app *models.App) (*models.AppManifest, bool) {
+	manifest, err := h.store.AppManifests().Get(r.Context(), app.ID)
+	if err != nil {
+		h.logger.Error("failed to get app manifest", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to get app manifest")
+		return nil, false
+	}
+	if manifest == nil {
+		WriteNotFound(w, "No app spec has been applied to this application")
+		return nil, false
+	}
+	return manifest, true
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/validation"
+)
+
+// Sources of env vars a service inherits from its app.
+const (
+	EnvSourceApp          = "app"
+	EnvSourceSecret       = "secret"
+	EnvSourceSharedSecret = "shared_secret" // An org secret shared into the app
+)
+
+// ListEnvVars handles GET /v1/apps/{appID}/env - lists the app-level env vars
+// inherited by every service.
+func (h *AppHandler) ListEnvVars(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.loadEnvApp(w, r)
+	if !ok {
+		return
+	}
+
+	variables := make([]EnvVarResponse, 0, len(app.EnvVars))
+	for _, k := range sortedKeys(app.EnvVars) {
+		variables = append(variables, EnvVarResponse{Key: k, Value: app.EnvVars[k]})
+	}
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"variables": variables,
+	})
+}
+
+// AddEnvVar handles POST /v1/apps/{appID}/env - adds an app-level env var.
+func (h *AppHandler) AddEnvVar(w http.ResponseWriter, r *http.Request) {
+	var req AddEnvVarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if !checkEnvVar(w, req.Key, req.Value) {
+		return
+	}
+
+	app, ok := h.loadEnvApp(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := app.EnvVars[req.Key]; exists {
+		WriteError(w, http.StatusConflict, "KEY_EXISTS", "Environment variable with this key already exists")
+		return
+	}
+
+	if app.EnvVars == nil {
+		app.EnvVars = make(map[string]string)
+	}
+	app.EnvVars[req.Key] = req.Value
+	if !h.saveEnvApp(w, r, app, "add") {
+		return
+	}
+
+	h.logger.Info("app env var added", "app_id", app.ID, "key", req.Key)
+	WriteJSON(w, http.StatusCreated, EnvVarResponse{
+		Key:       req.Key,
+		Value:     req.Value,
+		CreatedAt: app.UpdatedAt,
+	})
+}
+
+// UpdateEnvVar handles PUT /v1/apps/{appID}/env/{key} - updates an app-level env var.
+func (h *AppHandler) UpdateEnvVar(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+
+	var req UpdateEnvVarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if !checkEnvVar(w, key, req.Value) {
+		return
+	}
+
+	app, ok := h.loadEnvApp(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := app.EnvVars[key]; !exists {
+		WriteError(w, http.StatusNotFound, "KEY_NOT_FOUND", "Environment variable not found")
+		return
+	}
+
+	app.EnvVars[key] = req.Value
+	if !h.saveEnvApp(w, r, app, "update") {
+		return
+	}
+
+	h.logger.Info("app env var updated", "app_id", app.ID, "key", key)
+	WriteJSON(w, http.StatusOK, EnvVarResponse{
+		Key:       key,
+		Value:     req.Value,
+		CreatedAt: app.UpdatedAt,
+	})
+}
+
+// DeleteEnvVar handles DELETE /v1/apps/{appID}/env/{key} - deletes an app-level env var.
+func (h *AppHandler) DeleteEnvVar(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+
+	app, ok := h.loadEnvApp(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := app.EnvVars[key]; !exists {
+		WriteError(w, http.StatusNotFound, "KEY_NOT_FOUND", "Environment variable not found")
+		return
+	}
+
+	delete(app.EnvVars, key)
+	if !h.saveEnvApp(w, r, app, "delete") {
+		return
+	}
+
+	h.logger.Info("app env var deleted", "app_id", app.ID, "key", key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadEnvApp fetches the app named in the URL, writing an error response if it cannot.
+func (h *AppHandler) loadEnvApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	app, err := h.store.Apps().Get(r.Context(), appIDFromRequest(r))
+	if err != nil || app == nil {
+		WriteError(w, http.StatusNotFound, "APP_NOT_FOUND", "Application not found")
+		return nil, false
+	}
+	return app, true
+}
+
+// saveEnvApp stores an app after a change to its env vars, writing an error response if it cannot.
+func (h *AppHandler) saveEnvApp(w http.ResponseWriter, r *http.Request, app *models.App, action string) bool {
+	app.UpdatedAt = time.Now()
+	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+		h.logger.Error("failed to "+action+" app env var", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to "+action+" environment variable")
+		return false
+	}
+	return true
+}
+
+// checkEnvVar validates an env var key and value, writing an error response if either is invalid.
+func checkEnvVar(w http.ResponseWriter, key, value string) bool {
+	if err := validation.ValidateEnvKey(key); err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			WriteErrorWithDetails(w, http.StatusBadRequest, "INVALID_KEY", validationErr.Message, map[string]string{
+				"field":      "key",
+				"constraint": "pattern",
+				"expected":   "^[A-Za-z_][A-Za-z0-9_]*$",
+			})
+			return false
+		}
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	if err := validation.ValidateEnvValue(value); err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			WriteErrorWithDetails(w, http.StatusBadRequest, "INVALID_VALUE", validationErr.Message, map[string]string{
+				"field":      "value",
+				"constraint": "max_length",
+				"expected":   "32KB",
+			})
+			return false
+		}
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	return true
+}
+
+// sortedKeys returns the keys of an env var map in order.
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
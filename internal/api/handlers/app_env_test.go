@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockSecretKeyStore implements the key listing of store.SecretStore for testing.
+type mockSecretKeyStore struct {
+	store.SecretStore
+	keys []string
+}
+
+func (m *mockSecretKeyStore) List(ctx context.Context, appID string) ([]string, error) {
+	return m.keys, nil
+}
+
+// envMockStore adds secrets to the deployment mock store.
+type envMockStore struct {
+	*deploymentMockStore
+	secrets *mockSecretKeyStore
+}
+
+func (m *envMockStore) Secrets() store.SecretStore {
+	return m.secrets
+}
+
+func TestServiceEnvInheritsAppValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &envMockStore{deploymentMockStore: newDeploymentMockStore(), secrets: &mockSecretKeyStore{keys: []string{"DATABASE_URL", "API_TOKEN"}}}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "shop",
+		Services: []models.ServiceConfig{{Name: "web", EnvVars: map[string]string{"LOG_LEVEL": "debug", "API_TOKEN": "dev"}}}}
+	apps := NewAppHandler(st, logger)
+
+	for _, kv := range [][2]string{{"LOG_LEVEL", "info"}, {"REGION", "eu"}} {
+		rr := httptest.NewRecorder()
+		apps.AddEnvVar(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/env", AddEnvVarRequest{Key: kv[0], Value: kv[1]}, nil))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("add %s: status = %d: %s", kv[0], rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	apps.AddEnvVar(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/env", AddEnvVarRequest{Key: "REGION", Value: "us"}, nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate key: status = %d, want 409", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	apps.AddEnvVar(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/env", AddEnvVarRequest{Key: "1BAD", Value: "x"}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid key: status = %d, want 400", rr.Code)
+	}
+
+	services := NewServiceHandler(st, nil, nil, logger)
+	rr = httptest.NewRecorder()
+	services.ListEnvVars(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/services/web/env", nil,
+		map[string]string{"serviceName": "web"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list service env: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Variables []EnvVarResponse `json:"variables"`
+		Inherited []EnvVarResponse `json:"inherited"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+
+	want := []EnvVarResponse{
+		{Key: "API_TOKEN", Value: "dev", Overrides: EnvSourceSecret},
+		{Key: "LOG_LEVEL", Value: "debug", Overrides: EnvSourceApp},
+	}
+	if len(resp.Variables) != len(want) {
+		t.Fatalf("variables = %+v, want %+v", resp.Variables, want)
+	}
+	for i := range want {
+		if resp.Variables[i] != want[i] {
+			t.Errorf("variables[%d] = %+v, want %+v", i, resp.Variables[i], want[i])
+		}
+	}
+
+	wantInherited := []EnvVarResponse{
+		{Key: "DATABASE_URL", Source: EnvSourceSecret},
+		{Key: "REGION", Value: "eu", Source: EnvSourceApp},
+	}
+	if len(resp.Inherited) != len(wantInherited) {
+		t.Fatalf("inherited = %+v, want %+v", resp.Inherited, wantInherited)
+	}
+	for i := range wantInherited {
+		if resp.Inherited[i] != wantInherited[i] {
+			t.Errorf("inherited[%d] = %+v, want %+v", i, resp.Inherited[i], wantInherited[i])
+		}
+	}
+}
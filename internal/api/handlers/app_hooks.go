@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// defaultHookDeliveries is the number of deliveries listed when no limit is given.
+const defaultHookDeliveries = 20
+
+// AppHooksHandler handles an app's lifecycle hooks.
+type AppHooksHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewAppHooksHandler creates a new app hooks handler.
+func NewAppHooksHandler(st store.Store, logger *slog.Logger) *AppHooksHandler {
+	return &AppHooksHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// AppHookRequest is the request body for creating or updating a hook. A
+// secret left empty on update keeps the stored one.
+type AppHookRequest struct {
+	Name        string                `json:"name"`
+	Enabled     *bool                 `json:"enabled,omitempty"`
+	Events      []models.AppHookEvent `json:"events"`
+	URL         string                `json:"url,omitempty"`
+	ServiceName string                `json:"service_name,omitempty"`
+	Path        string                `json:"path,omitempty"`
+	Secret      string                `json:"secret,omitempty"`
+}
+
+// AppHookResponse is a hook with its secret withheld.
+type AppHookResponse struct {
+	*models.AppHook
+	// SecretSet reports whether payloads are signed.
+	SecretSet bool `json:"secret_set"`
+}
+
+// List handles GET /v1/apps/{appID}/hooks - lists the app's hooks.
+func (h *AppHooksHandler) List(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	hooks, err := h.store.AppHooks().ListByApp(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list app hooks", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list hooks")
+		return
+	}
+
+	resp := make([]AppHookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		resp = append(resp, appHookResponse(hook))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create handles POST /v1/apps/{appID}/hooks - adds a hook.
+func (h *AppHooksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+
+	var req AppHookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	hook := &models.AppHook{
+		AppID:       appID,
+		Name:        strings.TrimSpace(req.Name),
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Events:      req.Events,
+		URL:         req.URL,
+		ServiceName: req.ServiceName,
+		Path:        req.Path,
+		Secret:      req.Secret,
+	}
+	if !h.checkHook(w, r, hook) {
+		return
+	}
+
+	if err := h.store.AppHooks().Create(r.Context(), hook); err != nil {
+		h.logger.Error("failed to create app hook", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create hook")
+		return
+	}
+
+	h.logger.Info("app hook created", "app_id", appID, "hook_id", hook.ID, "events", hook.Events)
+	WriteJSON(w, http.StatusCreated, appHookResponse(hook))
+}
+
+// Update handles PUT /v1/apps/{appID}/hooks/{hookID} - edits a hook.
+func (h *AppHooksHandler) Update(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.loadHook(w, r)
+	if !ok {
+		return
+	}
+
+	var req AppHookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	hook := *existing
+	hook.Name = strings.TrimSpace(req.Name)
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	hook.Events = req.Events
+	hook.URL = req.URL
+	hook.ServiceName = req.ServiceName
+	hook.Path = req.Path
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	if !h.checkHook(w, r, &hook) {
+		return
+	}
+
+	if err := h.store.AppHooks().Update(r.Context(), &hook); err != nil {
+		h.logger.Error("failed to update app hook", "error", err, "hook_id", hook.ID)
+		WriteInternalError(w, "Failed to update hook")
+		return
+	}
+	WriteJSON(w, http.StatusOK, appHookResponse(&hook))
+}
+
+// Delete handles DELETE /v1/apps/{appID}/hooks/{hookID} - removes a hook and
+// drops its queued deliveries.
+func (h *AppHooksHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.loadHook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.AppHooks().Delete(r.Context(), hook.ID); err != nil {
+		h.logger.Error("failed to delete app hook", "error", err, "hook_id", hook.ID)
+		WriteInternalError(w, "Failed to delete hook")
+		return
+	}
+
+	h.logger.Info("app hook deleted", "app_id", hook.AppID, "hook_id", hook.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /v1/apps/{appID}/hooks/{hookID}/deliveries -
+// lists the hook's most recent calls and their outcome.
+func (h *AppHooksHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.loadHook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultHookDeliveries
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.store.AppHooks().ListDeliveries(r.Context(), hook.ID, limit)
+	if err != nil {
+		h.logger.Error("failed to list app hook deliveries", "error", err, "hook_id", hook.ID)
+		WriteInternalError(w, "Failed to list hook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*models.AppHookDelivery{}
+	}
+	WriteJSON(w, http.StatusOK, deliveries)
+}
+
+// checkHook validates a hook and checks that its hook service, if any, is a
+// service of the app, writing an error response if not.
+func (h *AppHooksHandler) checkHook(w http.ResponseWriter, r *http.Request, hook *models.AppHook) bool {
+	if err := hook.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	if hook.ServiceName == "" {
+		return true
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), hook.AppID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "Application not found")
+		return false
+	}
+	if !hasService(app, hook.ServiceName) {
+		WriteBadRequest(w, "Service "+hook.ServiceName+" not found in this app")
+		return false
+	}
+	return true
+}
+
+// loadHook fetches the hook named in the URL, writing an error response if it
+// cannot or the hook belongs to another app.
+func (h *AppHooksHandler) loadHook(w http.ResponseWriter, r *http.Request) (*models.AppHook, bool) {
+	hookID := chi.URLParam(r, "hookID")
+	hook, err := h.store.AppHooks().Get(r.Context(), hookID)
+	if err != nil {
+		h.logger.Error("failed to get app hook", "error", err, "hook_id", hookID)
+		WriteInternalError(w, "Failed to load hook")
+		return nil, false
+	}
+	if hook == nil || hook.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "Hook not found")
+		return nil, false
+	}
+	return hook, true
+}
+
+// appHookResponse withholds a hook's secret.
+func appHookResponse(hook *models.AppHook) AppHookResponse {
+	redacted := *hook
+	redacted.Secret = ""
+	return AppHookResponse{AppHook: &redacted, SecretSet: hook.Secret != ""}
+}
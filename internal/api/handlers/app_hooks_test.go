@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockAppHookStore implements store.AppHookStore for testing.
+type mockAppHookStore struct {
+	store.AppHookStore
+	hooks map[string]*models.AppHook
+}
+
+func (m *mockAppHookStore) Create(ctx context.Context, hook *models.AppHook) error {
+	hook.ID = "hook-" + hook.Name
+	hook.CreatedAt = time.Now()
+	stored := *hook
+	m.hooks[hook.ID] = &stored
+	return nil
+}
+
+func (m *mockAppHookStore) Get(ctx context.Context, id string) (*models.AppHook, error) {
+	if h, ok := m.hooks[id]; ok {
+		copied := *h
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAppHookStore) Update(ctx context.Context, hook *models.AppHook) error {
+	stored := *hook
+	m.hooks[hook.ID] = &stored
+	return nil
+}
+
+func (m *mockAppHookStore) ListDeliveries(ctx context.Context, hookID string, limit int) ([]*models.AppHookDelivery, error) {
+	return nil, nil
+}
+
+// appHookMockStore adds app hooks to the deployment mock store.
+type appHookMockStore struct {
+	*deploymentMockStore
+	appHooks *mockAppHookStore
+}
+
+func (m *appHookMockStore) AppHooks() store.AppHookStore {
+	return m.appHooks
+}
+
+func TestAppHooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &appHookMockStore{deploymentMockStore: newDeploymentMockStore(), appHooks: &mockAppHookStore{hooks: map[string]*models.AppHook{}}}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "shop",
+		Services: []models.ServiceConfig{{Name: "api"}, {Name: "hooks"}}}
+	st.appHooks.hooks["hook-foreign"] = &models.AppHook{ID: "hook-foreign", AppID: "app-2", Name: "foreign", URL: "https://example.com"}
+	h := NewAppHooksHandler(st, logger)
+
+	// Creating with a secret signs payloads but never returns the secret
+	rr := httptest.NewRecorder()
+	h.Create(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/hooks", AppHookRequest{
+		Name:   "purge",
+		Events: []models.AppHookEvent{models.AppHookDeploySuccess},
+		URL:    "https://cdn.example.com/purge",
+		Secret: "s3cret",
+	}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created AppHookResponse
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.Secret != "" || !created.SecretSet || !created.Enabled || created.AppID != "app-1" {
+		t.Errorf("create response = %+v", created)
+	}
+
+	// Hook services must belong to the app
+	rr = httptest.NewRecorder()
+	h.Create(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/hooks",
+		AppHookRequest{Name: "svc", ServiceName: "worker"}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown hook service: status = %d, want 400", rr.Code)
+	}
+
+	// Updating without a secret keeps the stored one
+	rr = httptest.NewRecorder()
+	h.Update(rr, templateRequest(http.MethodPut, "/v1/apps/app-1/hooks/hook-purge",
+		AppHookRequest{Name: "purge", ServiceName: "hooks", Path: "/purge"}, map[string]string{"hookID": "hook-purge"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if stored := st.appHooks.hooks["hook-purge"]; stored.Secret != "s3cret" || stored.URL != "" || stored.ServiceName != "hooks" {
+		t.Errorf("stored after update = %+v", stored)
+	}
+
+	// Hooks of other apps are not found
+	rr = httptest.NewRecorder()
+	h.ListDeliveries(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/hooks/hook-foreign/deliveries", nil,
+		map[string]string{"hookID": "hook-foreign"}))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("foreign hook: status = %d, want 404", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ListDeliveries(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/hooks/hook-purge/deliveries", nil,
+		map[string]string{"hookID": "hook-purge"}))
+	if rr.Code != http.StatusOK || rr.Body.String() != "[]\n" {
+		t.Errorf("deliveries: status = %d, body = %q", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/drift"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxAppSpecSize limits the size of an uploaded app spec.
+const maxAppSpecSize = 1 << 20
+
+// ApplySpecResponse lists the changes an applied app spec makes.
+type ApplySpecResponse struct {
+	DryRun  bool             `json:"dry_run"`
+	Changes []appspec.Change `json:"changes"`
+}
+
+// ApplySpec handles POST /v1/apps/{appID}/apply - converges the app's
+// services, env vars and domains to a YAML or JSON app spec (narvana.yaml),
+// which becomes the manifest drift is detected against. With ?dry_run=true
+// the changes are validated and returned without being applied; with
+// ?prune=true services the spec does not list are deleted.
+func (h *ServiceHandler) ApplySpec(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.ownedApp(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxAppSpecSize+1))
+	if err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if len(data) > maxAppSpecSize {
+		WriteBadRequest(w, "App spec must be 1MB or smaller")
+		return
+	}
+
+	spec, err := appspec.Parse(data)
+	if err == nil {
+		err = spec.Validate()
+	}
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := query.Get("dry_run") == "true"
+	opts := appspec.Options{
+		Prune:     query.Get("prune") == "true",
+		Resources: h.getDefaultResources(ctx),
+	}
+	plan, ok := h.convergeApp(w, r, app, spec, opts, dryRun)
+	if !ok {
+		return
+	}
+	if dryRun {
+		WriteJSON(w, http.StatusOK, ApplySpecResponse{DryRun: true, Changes: plan.Changes})
+		return
+	}
+
+	manifest := &models.AppManifest{
+		AppID:     app.ID,
+		Spec:      string(data),
+		Prune:     opts.Prune,
+		Resources: opts.Resources,
+		AppliedBy: middleware.GetUserID(ctx),
+	}
+	if err := drift.Save(ctx, h.store, manifest); err != nil {
+		h.logger.Error("failed to save app manifest", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "App spec applied, but failed to record it for drift detection")
+		return
+	}
+
+	h.logger.Info("app spec applied", "app_id", app.ID, "changes", len(plan.Changes))
+	WriteJSON(w, http.StatusOK, ApplySpecResponse{Changes: plan.Changes})
+}
+
+// ownedApp returns the app of the request, writing an error response unless
+// the current user owns it.
+func (h *ServiceHandler) ownedApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return nil, false
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		WriteNotFound(w, "Application not found")
+		return nil, false
+	}
+	if app.OwnerID != userID {
+		WriteForbidden(w, "Access denied")
+		return nil, false
+	}
+	return app, true
+}
+
+// convergeApp plans the changes that converge the app to a validated spec
+// and, unless dryRun, applies them. It writes an error response and returns
+// false if the spec's domains are taken or the plan fails.
+func (h *ServiceHandler) convergeApp(w http.ResponseWriter, r *http.Request, app *models.App, spec *appspec.Spec, opts appspec.Options, dryRun bool) (*appspec.Plan, bool) {
+	ctx := r.Context()
+
+	// Domains must be valid and free for this app to use
+	for _, svc := range spec.Services {
+		for _, domain := range svc.Domains {
+			if !ValidateDomain(domain) {
+				WriteBadRequest(w, fmt.Sprintf("Invalid domain format: %s", domain))
+				return nil, false
+			}
+			existing, err := h.store.Domains().GetByDomain(ctx, domain)
+			if err != nil {
+				h.logger.Error("failed to check existing domain", "error", err, "domain", domain)
+				WriteInternalError(w, "Failed to check domain availability")
+				return nil, false
+			}
+			if existing != nil && existing.AppID != app.ID {
+				WriteError(w, http.StatusConflict, ErrCodeConflict, fmt.Sprintf("Domain %s is already in use", domain))
+				return nil, false
+			}
+		}
+	}
+
+	domains, err := h.store.Domains().List(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to list domains", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to apply app spec")
+		return nil, false
+	}
+
+	plan, err := appspec.Converge(app, domains, spec, opts)
+	if err != nil {
+		if errors.Is(err, appspec.ErrInvalidSpec) {
+			WriteBadRequest(w, err.Error())
+			return nil, false
+		}
+		h.logger.Error("failed to plan app spec", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to apply app spec")
+		return nil, false
+	}
+
+	// Check service count limit (Requirements: 24.1, 24.2)
+	maxServices := 50 // Default limit
+	if maxServicesStr, err := h.store.Settings().Get(ctx, "max_services_per_app"); err == nil && maxServicesStr != "" {
+		if n, err := parseIntSetting(maxServicesStr); err == nil && n > 0 {
+			maxServices = n
+		}
+	}
+	if len(plan.Services) > maxServices && len(plan.Services) > len(app.Services) {
+		WriteError(w, http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("App spec declares more than the maximum services per app (%d).", maxServices))
+		return nil, false
+	}
+
+	if dryRun || len(plan.Changes) == 0 {
+		return plan, true
+	}
+	if err := h.applyPlan(ctx, app, domains, plan); err != nil {
+		h.logger.Error("failed to apply app spec", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to apply app spec")
+		return nil, false
+	}
+	return plan, true
+}
+
+// applyPlan converges the app to a planned spec: database credentials are
+// generated for new database services, then the app's services, env vars
+// and domains are updated in one transaction. Removed services are cleaned
+// up as if deleted.
+func (h *ServiceHandler) applyPlan(ctx context.Context, app *models.App, domains []*models.Domain, plan *appspec.Plan) error {
+	previous := make(map[string]models.ServiceConfig, len(app.Services))
+	for _, svc := range app.Services {
+		previous[svc.Name] = svc
+	}
+	planned := make(map[string]bool, len(plan.Services))
+	for _, svc := range plan.Services {
+		planned[svc.Name] = true
+		if svc.SourceType != models.SourceTypeDatabase || svc.Database == nil {
+			continue
+		}
+		if prev, ok := previous[svc.Name]; ok && prev.SourceType == models.SourceTypeDatabase {
+			continue
+		}
+		if err := h.generateDatabaseCredentials(ctx, app.ID, svc.Name, svc.Database.Type); err != nil {
+			return fmt.Errorf("generating database credentials for %s: %w", svc.Name, err)
+		}
+	}
+
+	err := h.store.WithTx(ctx, func(txStore store.Store) error {
+		current := make(map[string]string, len(domains))
+		for _, d := range domains {
+			current[d.Domain] = d.Service
+			if plan.Domains[d.Domain] != d.Service {
+				if err := txStore.Domains().Delete(ctx, d.ID); err != nil {
+					return fmt.Errorf("deleting domain %s: %w", d.Domain, err)
+				}
+			}
+		}
+		for domain, service := range plan.Domains {
+			if current[domain] == service {
+				continue
+			}
+			if err := txStore.Domains().Create(ctx, &models.Domain{
+				AppID:      app.ID,
+				Service:    service,
+				Domain:     domain,
+				IsWildcard: IsWildcardDomain(domain),
+			}); err != nil {
+				return fmt.Errorf("creating domain %s: %w", domain, err)
+			}
+		}
+
+		for _, svc := range app.Services {
+			if !planned[svc.Name] {
+				h.removeServiceResources(ctx, txStore, app.ID, &svc)
+			}
+		}
+
+		app.Services = plan.Services
+		app.EnvVars = plan.Env
+		app.UpdatedAt = time.Now()
+		return txStore.Apps().Update(ctx, app)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, svc := range plan.Services {
+		if prev, ok := previous[svc.Name]; ok && prev.Replicas != svc.Replicas {
+			h.serviceScaled(ctx, app.ID, svc.Name, prev.Replicas, svc.Replicas, models.ScaleEventManual, "app spec applied")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/apptemplates"
+	"github.com/narvanalabs/control-plane/internal/drift"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AppTemplateRequest represents the request body for adding an app template.
+type AppTemplateRequest struct {
+	Name        string                           `json:"name"`
+	Title       string                           `json:"title"`
+	Description string                           `json:"description,omitempty"`
+	Variables   []models.ServiceTemplateVariable `json:"variables,omitempty"`
+	Secrets     []models.AppTemplateSecret       `json:"secrets,omitempty"`
+	Spec        string                           `json:"spec"`
+}
+
+// InstantiateAppTemplateRequest represents the request body for creating an
+// app from a template.
+type InstantiateAppTemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+}
+
+// InstantiateAppTemplateResponse describes an app created from a template:
+// the changes its spec made and the keys of the secrets set. Secret values
+// are never returned.
+type InstantiateAppTemplateResponse struct {
+	DryRun  bool             `json:"dry_run"`
+	App     *models.App      `json:"app"`
+	Changes []appspec.Change `json:"changes"`
+	Secrets []string         `json:"secrets"`
+}
+
+// ListAppTemplates handles GET /v1/templates - lists the bundled app
+// templates and those added by instance admins.
+func (h *ServiceHandler) ListAppTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := apptemplates.List(r.Context(), h.store)
+	if err != nil {
+		h.logger.Error("failed to list app templates", "error", err)
+		WriteInternalError(w, "Failed to list app templates")
+		return
+	}
+	WriteJSON(w, http.StatusOK, templates)
+}
+
+// GetAppTemplate handles GET /v1/templates/{templateName} - retrieves an app template.
+func (h *ServiceHandler) GetAppTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.findAppTemplate(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, t)
+}
+
+// CreateAppTemplate handles POST /v1/templates - adds an app template
+// offered to every user. Instance admins only.
+func (h *ServiceHandler) CreateAppTemplate(w http.ResponseWriter, r *http.Request) {
+	var req AppTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	t := &models.AppTemplate{
+		Name:        req.Name,
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Variables:   req.Variables,
+		Secrets:     req.Secrets,
+		Spec:        req.Spec,
+		CreatedBy:   middleware.GetUserID(r.Context()),
+	}
+	if err := apptemplates.Check(t); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	existing, err := apptemplates.Get(r.Context(), h.store, t.Name)
+	if err != nil {
+		h.logger.Error("failed to check existing app template", "error", err, "template", t.Name)
+		WriteInternalError(w, "Failed to create app template")
+		return
+	}
+	if existing != nil {
+		WriteConflict(w, "An app template with this name already exists")
+		return
+	}
+
+	if err := h.store.AppTemplates().Create(r.Context(), t); err != nil {
+		h.logger.Error("failed to create app template", "error", err, "template", t.Name)
+		WriteInternalError(w, "Failed to create app template")
+		return
+	}
+
+	h.logger.Info("app template created", "template", t.Name, "user_id", t.CreatedBy)
+	WriteJSON(w, http.StatusCreated, t)
+}
+
+// DeleteAppTemplate handles DELETE /v1/templates/{templateName} - removes an
+// app template added by an instance admin. Apps created from it are kept.
+// Instance admins only.
+func (h *ServiceHandler) DeleteAppTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.findAppTemplate(w, r)
+	if !ok {
+		return
+	}
+	if t.Builtin {
+		WriteConflict(w, "Bundled app templates cannot be deleted")
+		return
+	}
+
+	if err := h.store.AppTemplates().Delete(r.Context(), t.Name); err != nil {
+		h.logger.Error("failed to delete app template", "error", err, "template", t.Name)
+		WriteInternalError(w, "Failed to delete app template")
+		return
+	}
+
+	h.logger.Info("app template deleted", "template", t.Name, "user_id", middleware.GetUserID(r.Context()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InstantiateAppTemplate handles POST /v1/templates/{templateName}/instantiate -
+// creates an app from a template in one call: the template is rendered with
+// the given params, the new app is converged to the rendered spec, which
+// becomes its manifest, and the template's secrets are set. Nothing is
+// created unless the whole spec can be applied. With ?dry_run=true the
+// changes are validated and returned without creating the app.
+func (h *ServiceHandler) InstantiateAppTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req InstantiateAppTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := (&CreateAppRequest{Name: req.Name}).Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	t, ok := h.findAppTemplate(w, r)
+	if !ok {
+		return
+	}
+	rendered, err := apptemplates.Render(t, req.Name, req.Params)
+	if err != nil {
+		var verr *models.ValidationError
+		if errors.As(err, &verr) || errors.Is(err, appspec.ErrInvalidSpec) {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to render app template", "error", err, "template", t.Name)
+		WriteInternalError(w, "Failed to render app template")
+		return
+	}
+
+	existing, err := h.store.Apps().GetByName(ctx, userID, req.Name)
+	if err == nil && existing != nil {
+		WriteConflict(w, "An application with this name already exists")
+		return
+	}
+
+	now := time.Now()
+	app := &models.App{
+		ID:          uuid.New().String(),
+		OrgID:       middleware.GetOrgID(ctx),
+		OwnerID:     userID,
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		IconURL:     strings.TrimSpace(req.IconURL),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	secretKeys := make([]string, 0, len(rendered.Secrets))
+	for key := range rendered.Secrets {
+		secretKeys = append(secretKeys, key)
+	}
+	sort.Strings(secretKeys)
+
+	// Plan against the app before creating it, so that taken domains and
+	// service limits are reported without leaving an empty app behind
+	opts := appspec.Options{Resources: h.getDefaultResources(ctx)}
+	plan, ok := h.convergeApp(w, r, app, rendered.Spec, opts, true)
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("dry_run") == "true" {
+		WriteJSON(w, http.StatusOK, InstantiateAppTemplateResponse{DryRun: true, App: app, Changes: plan.Changes, Secrets: secretKeys})
+		return
+	}
+
+	if err := h.store.Apps().Create(ctx, app); err != nil {
+		h.logger.Error("failed to create app", "error", err)
+		WriteInternalError(w, "Failed to create application")
+		return
+	}
+	if plan, ok = h.convergeApp(w, r, app, rendered.Spec, opts, false); !ok {
+		h.discardApp(ctx, app)
+		return
+	}
+	for _, key := range secretKeys {
+		if err := h.setAppSecret(ctx, app.ID, key, rendered.Secrets[key]); err != nil {
+			h.logger.Error("failed to store template secret", "error", err, "app_id", app.ID, "key", key)
+			h.discardApp(ctx, app)
+			WriteInternalError(w, "Failed to store the template's secrets")
+			return
+		}
+	}
+
+	manifest := &models.AppManifest{
+		AppID:     app.ID,
+		Spec:      string(rendered.Data),
+		Resources: opts.Resources,
+		AppliedBy: userID,
+	}
+	if err := drift.Save(ctx, h.store, manifest); err != nil {
+		h.logger.Error("failed to save app manifest", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "App created, but failed to record its spec for drift detection")
+		return
+	}
+
+	h.logger.Info("application created from template", "app_id", app.ID, "name", app.Name, "template", t.Name, "owner_id", userID, "org_id", app.OrgID)
+	WriteJSON(w, http.StatusCreated, InstantiateAppTemplateResponse{App: app, Changes: plan.Changes, Secrets: secretKeys})
+}
+
+// findAppTemplate fetches the app template named in the URL, writing an
+// error response if it cannot or the template does not exist.
+func (h *ServiceHandler) findAppTemplate(w http.ResponseWriter, r *http.Request) (*models.AppTemplate, bool) {
+	name := chi.URLParam(r, "templateName")
+	t, err := apptemplates.Get(r.Context(), h.store, name)
+	if err != nil {
+		h.logger.Error("failed to get app template", "error", err, "template", name)
+		WriteInternalError(w, "Failed to get app template")
+		return nil, false
+	}
+	if t == nil {
+		WriteNotFound(w, "App template not found")
+		return nil, false
+	}
+	return t, true
+}
+
+// setAppSecret encrypts a secret value with SOPS, or keeps it as-is if SOPS
+// is not configured, and stores it.
+func (h *ServiceHandler) setAppSecret(ctx context.Context, appID, key, value string) error {
+	stored := []byte(value)
+	if h.sopsService != nil && h.sopsService.CanEncrypt() {
+		encrypted, err := h.sopsService.Encrypt(ctx, stored)
+		if err != nil {
+			return fmt.Errorf("encrypting secret: %w", err)
+		}
+		stored = encrypted
+	} else {
+		h.logger.Warn("SOPS not configured, storing secret without encryption", "key", key)
+	}
+	return h.store.Secrets().Set(ctx, appID, key, stored)
+}
+
+// discardApp deletes an app whose creation from a template failed part way.
+func (h *ServiceHandler) discardApp(ctx context.Context, app *models.App) {
+	if err := h.store.Apps().Delete(ctx, app.ID); err != nil {
+		h.logger.Error("failed to delete partially created app", "error", err, "app_id", app.ID)
+	}
+}
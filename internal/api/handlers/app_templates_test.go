@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockAppTemplateStore implements store.AppTemplateStore for testing.
+type mockAppTemplateStore struct {
+	store.AppTemplateStore
+	templates map[string]*models.AppTemplate
+}
+
+func (m *mockAppTemplateStore) Get(ctx context.Context, name string) (*models.AppTemplate, error) {
+	return m.templates[name], nil
+}
+
+func (m *mockAppTemplateStore) List(ctx context.Context) ([]*models.AppTemplate, error) {
+	var result []*models.AppTemplate
+	for _, t := range m.templates {
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// takenDomainStore has one domain, used by another app.
+type takenDomainStore struct {
+	store.DomainStore
+}
+
+func (m *takenDomainStore) GetByDomain(ctx context.Context, domain string) (*models.Domain, error) {
+	if domain == "taken.example.com" {
+		return &models.Domain{AppID: "app-other", Domain: domain}, nil
+	}
+	return nil, nil
+}
+
+func (m *takenDomainStore) List(ctx context.Context, appID string) ([]*models.Domain, error) {
+	return nil, nil
+}
+
+// mockManifestStore keeps saved manifests by app.
+type mockManifestStore struct {
+	store.AppManifestStore
+	manifests map[string]*models.AppManifest
+}
+
+func (m *mockManifestStore) Save(ctx context.Context, manifest *models.AppManifest) error {
+	m.manifests[manifest.AppID] = manifest
+	return nil
+}
+
+// appTemplateMockStore adds app templates, secrets, domains and manifests
+// to the service template mock store.
+type appTemplateMockStore struct {
+	*templateMockStore
+	appTemplates *mockAppTemplateStore
+	secrets      *importSecretStore
+	manifests    *mockManifestStore
+}
+
+func (m *appTemplateMockStore) AppTemplates() store.AppTemplateStore { return m.appTemplates }
+func (m *appTemplateMockStore) Secrets() store.SecretStore           { return m.secrets }
+func (m *appTemplateMockStore) Domains() store.DomainStore           { return &takenDomainStore{} }
+func (m *appTemplateMockStore) AppManifests() store.AppManifestStore { return m.manifests }
+
+func (m *appTemplateMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func TestInstantiateAppTemplate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &appTemplateMockStore{
+		templateMockStore: newTemplateMockStore(),
+		appTemplates: &mockAppTemplateStore{templates: map[string]*models.AppTemplate{
+			"site": {Name: "site", Title: "Site", Spec: "version: 1\nservices:\n  - name: web\n    git_repo: github.com/acme/site\n    domains: [taken.example.com]\n"},
+		}},
+		secrets:   &importSecretStore{values: map[string][]byte{}},
+		manifests: &mockManifestStore{manifests: map[string]*models.AppManifest{}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"templateName": "postgres-go-api"}
+
+	// Bundled and stored templates are listed together
+	rr := httptest.NewRecorder()
+	h.ListAppTemplates(rr, templateRequest(http.MethodGet, "/v1/templates", nil, nil))
+	var listed []models.AppTemplate
+	json.NewDecoder(rr.Body).Decode(&listed)
+	var names []string
+	for _, tmpl := range listed {
+		names = append(names, tmpl.Name)
+	}
+	if got := strings.Join(names, " "); got != "nextjs-app postgres-go-api site" {
+		t.Errorf("listed = %s", got)
+	}
+
+	// A dry run creates nothing
+	rr = httptest.NewRecorder()
+	h.InstantiateAppTemplate(rr, templateRequest(http.MethodPost, "/v1/templates/postgres-go-api/instantiate?dry_run=true",
+		InstantiateAppTemplateRequest{Name: "shop", Params: map[string]string{"git_repo": "github.com/acme/shop"}}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("dry run: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(st.appStore.apps) != 1 || len(st.secrets.values) != 0 {
+		t.Fatalf("dry run created an app or secrets")
+	}
+
+	rr = httptest.NewRecorder()
+	h.InstantiateAppTemplate(rr, templateRequest(http.MethodPost, "/v1/templates/postgres-go-api/instantiate",
+		InstantiateAppTemplateRequest{Name: "shop", Params: map[string]string{"git_repo": "github.com/acme/shop"}}, params))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("instantiate: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp InstantiateAppTemplateResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.App == nil || resp.App.OwnerID != "user-1" || len(resp.Changes) == 0 || strings.Join(resp.Secrets, ",") != "SESSION_SECRET" {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	app := st.appStore.apps[resp.App.ID]
+	if app == nil || len(app.Services) != 2 || app.Services[0].GitRepo != "github.com/acme/shop" {
+		t.Fatalf("stored app = %+v", app)
+	}
+	if len(st.secrets.values["SESSION_SECRET"]) != 64 || st.secrets.values["DB_DATABASE_URL"] == nil {
+		t.Errorf("secrets = %v", st.secrets.values)
+	}
+	manifest := st.manifests.manifests[app.ID]
+	if manifest == nil || !strings.Contains(manifest.Spec, "app: shop") || manifest.SecretDigests["SESSION_SECRET"] == "" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	rejected := []struct {
+		name     string
+		template string
+		req      InstantiateAppTemplateRequest
+		status   int
+	}{
+		{"taken name", "postgres-go-api", InstantiateAppTemplateRequest{Name: "shop", Params: map[string]string{"git_repo": "github.com/acme/shop"}}, http.StatusConflict},
+		{"missing param", "postgres-go-api", InstantiateAppTemplateRequest{Name: "shop-2"}, http.StatusBadRequest},
+		{"unknown template", "rails", InstantiateAppTemplateRequest{Name: "shop-2"}, http.StatusNotFound},
+		{"taken domain", "site", InstantiateAppTemplateRequest{Name: "site"}, http.StatusConflict},
+	}
+	for _, tt := range rejected {
+		rr := httptest.NewRecorder()
+		h.InstantiateAppTemplate(rr, templateRequest(http.MethodPost, "/v1/templates/"+tt.template+"/instantiate", tt.req,
+			map[string]string{"templateName": tt.template}))
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rr.Code, tt.status, rr.Body.String())
+		}
+	}
+	if len(st.appStore.apps) != 2 {
+		t.Errorf("rejected instantiations left %d apps, want 2", len(st.appStore.apps))
+	}
+}
+
+func TestAppTemplateAdmin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &appTemplateMockStore{
+		templateMockStore: newTemplateMockStore(),
+		appTemplates:      &mockAppTemplateStore{templates: map[string]*models.AppTemplate{}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+
+	rejected := []struct {
+		name   string
+		req    AppTemplateRequest
+		status int
+	}{
+		{"bundled name", AppTemplateRequest{Name: "nextjs-app", Title: "Next.js", Spec: "version: 1\n"}, http.StatusConflict},
+		{"undeclared variable", AppTemplateRequest{Name: "api", Title: "API", Spec: "version: 1\napp: ${name}\n"}, http.StatusBadRequest},
+	}
+	for _, tt := range rejected {
+		rr := httptest.NewRecorder()
+		h.CreateAppTemplate(rr, templateRequest(http.MethodPost, "/v1/templates", tt.req, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rr.Code, tt.status, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.DeleteAppTemplate(rr, templateRequest(http.MethodDelete, "/v1/templates/nextjs-app", nil,
+		map[string]string{"templateName": "nextjs-app"}))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("delete bundled: status = %d, want 409", rr.Code)
+	}
+}
@@ -205,7 +205,7 @@ func (h *AppHandler) Get(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	WriteJSON(w, http.StatusOK, app)
+	h.writeApp(w, http.StatusOK, app)
 }
 
 // UpdateAppRequest represents the request body for updating an application.
@@ -322,6 +322,156 @@ func (h *AppHandler) Update(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, app)
 }
 
+// PutAppRequest represents the request body for creating or replacing an
+// application by name.
+type PutAppRequest struct {
+	Name        string `json:"name,omitempty"` // Renames the app when set
+	Description string `json:"description"`
+	IconURL     string `json:"icon_url"`
+}
+
+// Put handles PUT /v1/apps/:appID - creates the application named in the
+// path if the user has none by that name or ID, or replaces its description
+// and icon, renaming it if the body names it. Putting the same values again
+// changes nothing, so the request is safe to repeat. The response carries the
+// app's ETag, which If-Match compares; If-None-Match: * only creates.
+func (h *AppHandler) Put(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req PutAppRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	// RequireOwnershipIfExists resolves the app if it exists
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		h.putNew(w, r, userID, req)
+		return
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		h.logger.Debug("failed to get app for put", "error", err, "app_id", appID)
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	if !checkPreconditions(w, r, resourceETag(app)) {
+		return
+	}
+
+	name := app.Name
+	if req.Name != "" {
+		name = strings.TrimSpace(req.Name)
+		if err := (&CreateAppRequest{Name: name}).Validate(); err != nil {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+	}
+	description := strings.TrimSpace(req.Description)
+	iconURL := strings.TrimSpace(req.IconURL)
+	if name == app.Name && description == app.Description && iconURL == app.IconURL {
+		h.writeApp(w, http.StatusOK, app)
+		return
+	}
+
+	if name != app.Name {
+		existing, err := h.store.Apps().GetByName(r.Context(), app.OwnerID, name)
+		if err == nil && existing != nil && existing.ID != app.ID {
+			WriteConflict(w, "An application with this name already exists in this organization")
+			return
+		}
+	}
+	app.Name, app.Description, app.IconURL = name, description, iconURL
+
+	// The version read above makes concurrent writes fail
+	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+		if err.Error() == "resource was modified by another request" {
+			WriteError(w, http.StatusPreconditionFailed, ErrCodePreconditionFailed, "Resource was modified by another request. Please refresh and try again.")
+			return
+		}
+		if err.Error() == "duplicate name" {
+			WriteConflict(w, "An application with this name already exists in this organization")
+			return
+		}
+		h.logger.Error("failed to update app", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to update application")
+		return
+	}
+
+	h.logger.Info("application replaced", "app_id", appID, "name", app.Name, "owner_id", userID)
+	h.writeStoredApp(w, r, http.StatusOK, app.ID)
+}
+
+// putNew creates the application named in the path of a PUT request.
+func (h *AppHandler) putNew(w http.ResponseWriter, r *http.Request, userID string, req PutAppRequest) {
+	if !checkPreconditions(w, r, "") {
+		return
+	}
+	name := chi.URLParam(r, "appID")
+	if _, err := uuid.Parse(name); err == nil {
+		// Apps are created by name; an unknown ID does not name one
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	if req.Name != "" && strings.TrimSpace(req.Name) != name {
+		WriteBadRequest(w, "name must match the application name in the path")
+		return
+	}
+	if err := (&CreateAppRequest{Name: name}).Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	now := time.Now()
+	app := &models.App{
+		ID:          uuid.New().String(),
+		OrgID:       middleware.GetOrgID(r.Context()),
+		OwnerID:     userID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		IconURL:     strings.TrimSpace(req.IconURL),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.store.Apps().Create(r.Context(), app); err != nil {
+		if err.Error() == "duplicate name" {
+			WriteConflict(w, "An application with this name already exists in this organization")
+			return
+		}
+		h.logger.Error("failed to create app", "error", err)
+		WriteInternalError(w, "Failed to create application")
+		return
+	}
+
+	h.logger.Info("application created", "app_id", app.ID, "name", app.Name, "owner_id", userID, "org_id", app.OrgID)
+	w.Header().Set("Location", "/v1/apps/"+app.ID)
+	h.writeStoredApp(w, r, http.StatusCreated, app.ID)
+}
+
+// writeStoredApp writes an app as stored, so its ETag matches the one GET
+// returns.
+func (h *AppHandler) writeStoredApp(w http.ResponseWriter, r *http.Request, status int, appID string) {
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to get app", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to get application")
+		return
+	}
+	h.writeApp(w, status, app)
+}
+
+// writeApp writes an app with its ETag.
+func (h *AppHandler) writeApp(w http.ResponseWriter, status int, app *models.App) {
+	w.Header().Set("ETag", resourceETag(app))
+	WriteJSON(w, status, app)
+}
+
 // Delete handles DELETE /v1/apps/:appID - soft-deletes an application.
 // Requirements: 11.1, 11.2, 11.3, 11.4 - Safe deletion with deployment cleanup.
 func (h *AppHandler) Delete(w http.ResponseWriter, r *http.Request) {
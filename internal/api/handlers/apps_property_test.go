@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -89,6 +90,65 @@ func (m *mockAppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.A
 	return result, nil
 }
 
+func (m *mockAppStore) ListAll(ctx context.Context) ([]*models.App, error) {
+	var result []*models.App
+	for _, app := range m.apps {
+		if app.DeletedAt == nil {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
+// mockServiceStore implements store.ServiceStore on the apps of a mockAppStore.
+type mockServiceStore struct {
+	apps *mockAppStore
+}
+
+func (m *mockServiceStore) Get(ctx context.Context, appID, name string) (*models.ServiceConfig, error) {
+	if app, ok := m.apps.apps[appID]; ok {
+		for _, svc := range app.Services {
+			if svc.Name == name {
+				return &svc, nil
+			}
+		}
+	}
+	return nil, errors.New("resource not found")
+}
+
+func (m *mockServiceStore) Create(ctx context.Context, appID string, service *models.ServiceConfig) error {
+	app, ok := m.apps.apps[appID]
+	if !ok {
+		return errors.New("resource not found")
+	}
+	app.Services = append(app.Services, *service)
+	return nil
+}
+
+func (m *mockServiceStore) Update(ctx context.Context, appID string, service *models.ServiceConfig) error {
+	if app, ok := m.apps.apps[appID]; ok {
+		for i := range app.Services {
+			if app.Services[i].Name == service.Name {
+				app.Services[i] = *service
+				return nil
+			}
+		}
+	}
+	return errors.New("resource not found")
+}
+
+func (m *mockServiceStore) Delete(ctx context.Context, appID, name string) error {
+	if app, ok := m.apps.apps[appID]; ok {
+		for i := range app.Services {
+			if app.Services[i].Name == name {
+				app.Services = append(app.Services[:i], app.Services[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return errors.New("resource not found")
+}
+
 // emptyDeploymentStore implements store.DeploymentStore that returns empty results
 type emptyDeploymentStore struct{}
 
@@ -202,6 +262,186 @@ func (m *mockStore) GitHubAccounts() store.GitHubAccountStore {
 	return nil
 }
 
+func (m *mockStore) Announcements() store.AnnouncementStore {
+	return nil
+}
+
+func (m *mockStore) Releases() store.ReleaseStore {
+	return nil
+}
+
+func (m *mockStore) Stats() store.StatsStore {
+	return nil
+}
+
+func (m *mockStore) EgressViolations() store.EgressViolationStore {
+	return nil
+}
+
+func (m *mockStore) DeployFreezes() store.DeployFreezeStore {
+	return nil
+}
+
+func (m *mockStore) OnCall() store.OnCallStore {
+	return nil
+}
+
+func (m *mockStore) APICatalog() store.APICatalogStore {
+	return nil
+}
+
+func (m *mockStore) SCIM() store.SCIMStore {
+	return nil
+}
+
+func (m *mockStore) APIKeys() store.APIKeyStore {
+	return nil
+}
+
+func (m *mockStore) Notifications() store.NotificationStore {
+	return nil
+}
+
+func (m *mockStore) Promotions() store.PromotionStore {
+	return nil
+}
+
+func (m *mockStore) SSH() store.SSHStore {
+	return nil
+}
+
+func (m *mockStore) BuildWorkers() store.BuildWorkerStore {
+	return nil
+}
+
+func (m *mockStore) ServiceTemplates() store.ServiceTemplateStore {
+	return nil
+}
+
+func (m *mockStore) BuildLogs() store.BuildLogStore {
+	return nil
+}
+
+func (m *mockStore) BuildSnapshots() store.BuildSnapshotStore {
+	return nil
+}
+
+func (m *mockStore) AppHooks() store.AppHookStore {
+	return nil
+}
+
+func (m *mockStore) CDN() store.CDNStore {
+	return nil
+}
+
+func (m *mockStore) BuildAttestations() store.BuildAttestationStore {
+	return nil
+}
+
+func (m *mockStore) CronRuns() store.CronRunStore {
+	return nil
+}
+
+func (m *mockStore) Metrics() store.MetricStore {
+	return nil
+}
+
+func (m *mockStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	return nil
+}
+
+func (m *mockStore) Audit() store.AuditStore {
+	return nil
+}
+
+func (m *mockStore) OrgSecrets() store.OrgSecretStore {
+	return nil
+}
+
+func (m *mockStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	return nil
+}
+
+func (m *mockStore) SmokeTests() store.SmokeTestStore {
+	return nil
+}
+
+func (m *mockStore) LoadTests() store.LoadTestStore {
+	return nil
+}
+
+func (m *mockStore) Archives() store.ArchiveStore {
+	return nil
+}
+
+func (m *mockStore) Operations() store.OperationStore {
+	return nil
+}
+
+func (m *mockStore) DebugSessions() store.DebugSessionStore {
+	return nil
+}
+
+func (m *mockStore) AuthSessions() store.AuthSessionStore {
+	return nil
+}
+
+func (m *mockStore) RateLimits() store.RateLimitStore {
+	return nil
+}
+
+func (m *mockStore) Consistency() store.ConsistencyStore {
+	return nil
+}
+
+func (m *mockStore) DBHealth() store.DBHealthStore {
+	return nil
+}
+
+func (m *mockStore) AppManifests() store.AppManifestStore {
+	return nil
+}
+
+func (m *mockStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return nil
+}
+
+func (m *mockStore) ScaleEvents() store.ScaleEventStore {
+	return nil
+}
+
+func (m *mockStore) Environments() store.EnvironmentStore {
+	return nil
+}
+
+func (m *mockStore) Federation() store.FederationStore {
+	return nil
+}
+
+func (m *mockStore) EvidenceExports() store.EvidenceExportStore {
+	return nil
+}
+
+func (m *mockStore) APIUsage() store.APIUsageStore {
+	return nil
+}
+
+func (m *mockStore) Events() store.EventStore {
+	return nil
+}
+
+func (m *mockStore) Webhooks() store.WebhookStore {
+	return nil
+}
+
+func (m *mockStore) AppTemplates() store.AppTemplateStore {
+	return nil
+}
+
+func (m *mockStore) Services() store.ServiceStore {
+	return &mockServiceStore{apps: m.appStore}
+}
+
 func (m *mockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	return fn(m)
 }
@@ -714,6 +954,186 @@ func (m *appDeletionMockStore) GitHubAccounts() store.GitHubAccountStore {
 	return nil
 }
 
+func (m *appDeletionMockStore) Announcements() store.AnnouncementStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Releases() store.ReleaseStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Stats() store.StatsStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) EgressViolations() store.EgressViolationStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) DeployFreezes() store.DeployFreezeStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) OnCall() store.OnCallStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) APICatalog() store.APICatalogStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) SCIM() store.SCIMStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) APIKeys() store.APIKeyStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Notifications() store.NotificationStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Promotions() store.PromotionStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) SSH() store.SSHStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) BuildWorkers() store.BuildWorkerStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) ServiceTemplates() store.ServiceTemplateStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) BuildLogs() store.BuildLogStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) BuildSnapshots() store.BuildSnapshotStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) AppHooks() store.AppHookStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) CDN() store.CDNStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) BuildAttestations() store.BuildAttestationStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) CronRuns() store.CronRunStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Metrics() store.MetricStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Audit() store.AuditStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) OrgSecrets() store.OrgSecretStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) SmokeTests() store.SmokeTestStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) LoadTests() store.LoadTestStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Archives() store.ArchiveStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Operations() store.OperationStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) DebugSessions() store.DebugSessionStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) AuthSessions() store.AuthSessionStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) RateLimits() store.RateLimitStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Consistency() store.ConsistencyStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) DBHealth() store.DBHealthStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) AppManifests() store.AppManifestStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) ScaleEvents() store.ScaleEventStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Environments() store.EnvironmentStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Federation() store.FederationStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) EvidenceExports() store.EvidenceExportStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) APIUsage() store.APIUsageStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Events() store.EventStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Webhooks() store.WebhookStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) AppTemplates() store.AppTemplateStore {
+	return nil
+}
+
+func (m *appDeletionMockStore) Services() store.ServiceStore {
+	return &mockServiceStore{apps: m.appStore}
+}
+
 func (m *appDeletionMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	return fn(m)
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/archive"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ArchiveHandler handles the admin view of the archive tier.
+// All routes are expected to be mounted behind middleware.RequireAdmin.
+type ArchiveHandler struct {
+	store    store.Store
+	archiver *archive.Archiver
+	logger   *slog.Logger
+}
+
+// NewArchiveHandler creates a new archive handler. archiver is nil when no
+// object storage is configured.
+func NewArchiveHandler(st store.Store, archiver *archive.Archiver, logger *slog.Logger) *ArchiveHandler {
+	return &ArchiveHandler{
+		store:    st,
+		archiver: archiver,
+		logger:   logger,
+	}
+}
+
+// ArchiveOverview is the size of the archive tier and its restore queue.
+type ArchiveOverview struct {
+	Enabled bool `json:"enabled"`
+	models.ArchiveStats
+	RestoreQueue []*models.Archive `json:"restore_queue"`
+}
+
+// Get handles GET /v1/admin/archive - returns the number and size of
+// archived deployments and the archives being or waiting to be restored.
+func (h *ArchiveHandler) Get(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.store.Archives().Stats(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get archive stats", "error", err)
+		WriteInternalError(w, "Failed to get archive stats")
+		return
+	}
+	queue, err := h.store.Archives().ListRestoreQueue(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list restore queue", "error", err)
+		WriteInternalError(w, "Failed to get archive stats")
+		return
+	}
+	if queue == nil {
+		queue = []*models.Archive{}
+	}
+
+	WriteJSON(w, http.StatusOK, ArchiveOverview{
+		Enabled:      h.archiver != nil,
+		ArchiveStats: *stats,
+		RestoreQueue: queue,
+	})
+}
+
+// rehydrate restores a deployment's history if it was archived, so it can be
+// read from the store. It writes an error and returns false if the history
+// is not available yet; clients retry after the Retry-After delay.
+func rehydrate(w http.ResponseWriter, r *http.Request, archiver *archive.Archiver, logger *slog.Logger, deploymentID string) bool {
+	if archiver == nil || deploymentID == "" {
+		return true
+	}
+	err := archiver.Rehydrate(r.Context(), deploymentID)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, archive.ErrRestoring) {
+		w.Header().Set("Retry-After", "30")
+		WriteError(w, http.StatusServiceUnavailable, "archive_restoring", "Deployment history is being restored from the archive, retry shortly")
+		return false
+	}
+	logger.Error("failed to restore archived deployment", "error", err, "deployment_id", deploymentID)
+	WriteInternalError(w, "Failed to restore archived deployment")
+	return false
+}
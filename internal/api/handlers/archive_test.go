@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/archive"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockArchiveStore implements the parts of store.ArchiveStore the handlers
+// and rehydration use.
+type mockArchiveStore struct {
+	store.ArchiveStore
+	archives []*models.Archive
+}
+
+func (m *mockArchiveStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.Archive, error) {
+	for _, a := range m.archives {
+		if a.DeploymentID == deploymentID {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockArchiveStore) ClaimRestore(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	return false, nil
+}
+
+func (m *mockArchiveStore) ListRestoreQueue(ctx context.Context) ([]*models.Archive, error) {
+	var queue []*models.Archive
+	for _, a := range m.archives {
+		if a.Status != models.ArchiveStatusArchived {
+			queue = append(queue, a)
+		}
+	}
+	return queue, nil
+}
+
+func (m *mockArchiveStore) Stats(ctx context.Context) (*models.ArchiveStats, error) {
+	stats := &models.ArchiveStats{Archives: len(m.archives)}
+	for _, a := range m.archives {
+		stats.SizeBytes += a.SizeBytes
+		stats.OriginalBytes += a.OriginalBytes
+	}
+	return stats, nil
+}
+
+// archiveMockStore adds archives to the deployment mock store.
+type archiveMockStore struct {
+	*deploymentMockStore
+	archives *mockArchiveStore
+}
+
+func (m *archiveMockStore) Archives() store.ArchiveStore {
+	return m.archives
+}
+
+func TestArchiveOverview(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	requested := time.Now()
+	st := &archiveMockStore{deploymentMockStore: newDeploymentMockStore(), archives: &mockArchiveStore{archives: []*models.Archive{
+		{ID: "a-1", DeploymentID: "dep-1", Status: models.ArchiveStatusArchived, SizeBytes: 100, OriginalBytes: 900},
+		{ID: "a-2", DeploymentID: "dep-2", Status: models.ArchiveStatusRestoreFailed, SizeBytes: 50, OriginalBytes: 400,
+			Error: "fetching archive: object not found", RestoreRequestedAt: &requested},
+	}}}
+	h := NewArchiveHandler(st, nil, logger)
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, httptest.NewRequest(http.MethodGet, "/v1/admin/archive", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var overview ArchiveOverview
+	if err := json.Unmarshal(rr.Body.Bytes(), &overview); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if overview.Enabled || overview.Archives != 2 || overview.SizeBytes != 150 || overview.OriginalBytes != 1300 {
+		t.Errorf("overview = %+v", overview)
+	}
+	if len(overview.RestoreQueue) != 1 || overview.RestoreQueue[0].DeploymentID != "dep-2" {
+		t.Errorf("restore queue = %+v", overview.RestoreQueue)
+	}
+}
+
+func TestLogsOfRestoringDeployment(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &archiveMockStore{deploymentMockStore: newDeploymentMockStore(), archives: &mockArchiveStore{archives: []*models.Archive{
+		{ID: "a-1", DeploymentID: "dep-1", Status: models.ArchiveStatusRestoring},
+	}}}
+	h := NewLogHandler(st, logger)
+	h.SetArchiver(archive.NewArchiver(st, archive.NewDirStore(t.TempDir()), archive.DefaultConfig(), logger))
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/logs?deployment_id=dep-1", nil, nil))
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("status = %d, Retry-After = %q: %s", rr.Code, rr.Header().Get("Retry-After"), rr.Body.String())
+	}
+}
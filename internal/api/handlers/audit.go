@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Audit log page sizes.
+const (
+	defaultAuditEntries = 50
+	maxAuditEntries     = 500
+)
+
+// AuditHandler serves the audit log of mutating API requests.
+type AuditHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewAuditHandler creates a new audit handler.
+func NewAuditHandler(st store.Store, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// List handles GET /v1/audit - lists audit entries, newest first. Admins see
+// every entry; other users see only their own.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	q := r.URL.Query()
+
+	filter := models.AuditFilter{
+		UserID:       q.Get("user_id"),
+		APIKeyID:     q.Get("api_key_id"),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+		AppID:        q.Get("app_id"),
+		Limit:        defaultAuditEntries,
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				WriteBadRequest(w, name+" must be an RFC 3339 timestamp")
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAuditEntries {
+			WriteBadRequest(w, "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = n
+	}
+
+	if err := userHasPermission(ctx, h.store, userID, auth.PermissionAdminConsole); err != nil {
+		if filter.UserID != "" && filter.UserID != userID {
+			WriteForbidden(w, "Only admins can view other users' audit entries")
+			return
+		}
+		filter.UserID = userID
+	}
+
+	entries, err := h.store.Audit().List(ctx, filter)
+	if err != nil {
+		h.logger.Error("failed to list audit entries", "error", err)
+		WriteInternalError(w, "Failed to list audit entries")
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+	WriteJSON(w, http.StatusOK, entries)
+}
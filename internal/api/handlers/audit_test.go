@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// filterAuditStore records the filter it was listed with.
+type filterAuditStore struct {
+	store.AuditStore
+	filter models.AuditFilter
+}
+
+func (m *filterAuditStore) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	m.filter = filter
+	return nil, nil
+}
+
+// auditMockStore provides users with a fixed role and the audit log.
+type auditMockStore struct {
+	store.Store
+	users *roleUserStore
+	audit *filterAuditStore
+}
+
+func (m *auditMockStore) Users() store.UserStore  { return m.users }
+func (m *auditMockStore) Audit() store.AuditStore { return m.audit }
+
+func TestAuditList(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name   string
+		role   store.Role
+		query  string
+		status int
+		want   models.AuditFilter
+	}{
+		{"admins see every actor", store.RoleOwner, "?resource_type=service&resource_id=app-1/web",
+			http.StatusOK, models.AuditFilter{ResourceType: "service", ResourceID: "app-1/web", Limit: defaultAuditEntries}},
+		{"admins filter by actor", store.RoleOwner, "?user_id=user-2&since=2026-03-01T00:00:00Z&limit=10",
+			http.StatusOK, models.AuditFilter{UserID: "user-2", Since: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Limit: 10}},
+		{"members see their own entries", store.RoleMember, "?app_id=app-1",
+			http.StatusOK, models.AuditFilter{UserID: "user-1", AppID: "app-1", Limit: defaultAuditEntries}},
+		{"members cannot view other actors", store.RoleMember, "?user_id=user-2", http.StatusForbidden, models.AuditFilter{}},
+		{"invalid since", store.RoleOwner, "?since=yesterday", http.StatusBadRequest, models.AuditFilter{}},
+		{"limit too large", store.RoleOwner, "?limit=501", http.StatusBadRequest, models.AuditFilter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &auditMockStore{users: &roleUserStore{role: tt.role}, audit: &filterAuditStore{}}
+			rr := httptest.NewRecorder()
+			NewAuditHandler(st, logger).List(rr, templateRequest(http.MethodGet, "/v1/audit"+tt.query, nil, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if st.audit.filter != tt.want {
+				t.Errorf("filter = %+v, want %+v", st.audit.filter, tt.want)
+			}
+			if tt.status == http.StatusOK && rr.Body.String() != "[]\n" {
+				t.Errorf("body = %q, want an empty list", rr.Body.String())
+			}
+		})
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/api/ratelimit"
 	"github.com/narvanalabs/control-plane/internal/auth"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
@@ -23,6 +25,10 @@ type AuthHandler struct {
 	rbacService *auth.RBACService
 	logger      *slog.Logger
 
+	// Sign-in attempts per account, if limited
+	loginLimiter *ratelimit.Limiter
+	loginRule    ratelimit.Rule
+
 	// Device auth state (in-memory for simplicity)
 	deviceCodes   map[string]*deviceAuthState
 	deviceCodesMu sync.RWMutex
@@ -46,6 +52,14 @@ func NewAuthHandler(st store.Store, authSvc *auth.Service, logger *slog.Logger)
 	}
 }
 
+// SetLoginLimit limits the failed sign-in attempts made for each account,
+// whatever their client, to slow down password guessing. Successful sign-ins
+// are not counted.
+func (h *AuthHandler) SetLoginLimit(limiter *ratelimit.Limiter, rule ratelimit.Rule) {
+	h.loginLimiter = limiter
+	h.loginRule = rule
+}
+
 // SetupCheck returns whether initial setup is complete.
 func (h *AuthHandler) SetupCheck(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -161,21 +175,20 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Generate token
-	token, err := h.authService.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		h.logger.Error("failed to generate token", "error", err)
-		WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate token"})
-		return
-	}
-
-	WriteJSON(w, http.StatusCreated, map[string]interface{}{
+	// Sign the new user in
+	resp := map[string]interface{}{
 		"user_id":  user.ID,
 		"email":    user.Email,
-		"token":    token,
 		"role":     user.Role,
 		"is_admin": user.Role == store.RoleOwner,
-	})
+	}
+	if err := startSession(r, h.authService, user.ID, user.Email, resp); err != nil {
+		h.logger.Error("failed to start session", "error", err)
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate token"})
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 // Login handles user login.
@@ -197,26 +210,40 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	// Verify credentials
+	// Refuse an account with too many failed attempts before checking the
+	// password, so that guessing it from many addresses is slow too. Each
+	// address is already limited by the route's IP limit.
+	account := strings.ToLower(req.Email)
+	if h.loginLimiter != nil {
+		if res := h.loginLimiter.Check(ctx, h.loginRule, account); !res.Allowed {
+			h.logger.Warn("too many failed sign-in attempts", "email", req.Email)
+			ratelimit.WriteLimited(w, res, "Too many sign-in attempts, please retry later")
+			return
+		}
+	}
+
+	// Verify credentials, charging only failed attempts to the account
 	user, err := h.store.Users().Authenticate(ctx, req.Email, req.Password)
 	if err != nil {
+		if h.loginLimiter != nil {
+			h.loginLimiter.Allow(ctx, h.loginRule, account)
+		}
 		WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
 		return
 	}
 
-	// Generate token
-	token, err := h.authService.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		h.logger.Error("failed to generate token", "error", err)
+	// Start a session for the device
+	resp := map[string]interface{}{
+		"user_id": user.ID,
+		"email":   user.Email,
+	}
+	if err := startSession(r, h.authService, user.ID, user.Email, resp); err != nil {
+		h.logger.Error("failed to start session", "error", err)
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate token"})
 		return
 	}
 
-	WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"token":   token,
-	})
+	WriteJSON(w, http.StatusOK, resp)
 }
 
 // DeviceAuthStart initiates device authorization flow (for CLI).
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/ratelimit"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// passwordUserStore authenticates one user with a fixed password.
+type passwordUserStore struct {
+	store.UserStore
+}
+
+func (passwordUserStore) Authenticate(ctx context.Context, email, password string) (*store.User, error) {
+	if !strings.EqualFold(email, "alice@example.com") || password != "correct horse" {
+		return nil, errors.New("invalid credentials")
+	}
+	return &store.User{ID: "user-1", Email: email}, nil
+}
+
+// loginMockStore provides the password user store.
+type loginMockStore struct {
+	store.Store
+}
+
+func (loginMockStore) Users() store.UserStore { return passwordUserStore{} }
+
+func TestLoginLimitChargesFailedAttempts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	authService := auth.NewService(&auth.Config{JWTSecret: []byte("4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c"), TokenExpiry: 24 * time.Hour}, nil, logger)
+	h := NewAuthHandler(loginMockStore{}, authService, logger)
+	h.SetLoginLimit(ratelimit.New(ratelimit.NewMemoryStore(), nil, logger), ratelimit.Rule{Name: "login", PerMinute: 2})
+
+	login := func(password string) int {
+		rr := httptest.NewRecorder()
+		h.Login(rr, templateRequest(http.MethodPost, "/auth/login", map[string]string{"email": "Alice@example.com", "password": password}, nil))
+		return rr.Code
+	}
+
+	for i := 0; i < 5; i++ {
+		if code := login("correct horse"); code != http.StatusOK {
+			t.Fatalf("sign-in %d: status = %d, want 200", i+1, code)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if code := login("wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("failed attempt %d: status = %d, want 401", i+1, code)
+		}
+	}
+	if code := login("correct horse"); code != http.StatusTooManyRequests {
+		t.Errorf("sign-in after the failed attempts: status = %d, want 429", code)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// defaultScaleEvents is how many events ListScaleEvents returns without a limit.
+const defaultScaleEvents = 50
+
+// AutoscalingResponse is a service's autoscaling with the replica count it
+// runs and any manual override holding the autoscaler off.
+type AutoscalingResponse struct {
+	Autoscaling *models.Autoscaling     `json:"autoscaling,omitempty"`
+	Override    *models.ReplicaOverride `json:"override,omitempty"`
+	Replicas    int                     `json:"replicas"`
+}
+
+// GetAutoscaling handles GET /v1/apps/{appID}/services/{serviceName}/autoscaling -
+// returns the service's autoscaling and the replica count it runs.
+func (h *ServiceHandler) GetAutoscaling(w http.ResponseWriter, r *http.Request) {
+	_, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, autoscalingResponse(service, time.Now()))
+}
+
+// SetAutoscaling handles PUT /v1/apps/{appID}/services/{serviceName}/autoscaling -
+// replaces the service's autoscaling. Any manual override is cleared and the
+// replica count is brought within the new bounds; the autoscaler sizes it to
+// the targets from then on.
+func (h *ServiceHandler) SetAutoscaling(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	var autoscaling models.Autoscaling
+	if err := json.NewDecoder(r.Body).Decode(&autoscaling); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := autoscaling.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	if service.ScalingSchedule != nil {
+		WriteConflict(w, "Service has a scaling schedule; remove it before enabling autoscaling")
+		return
+	}
+
+	now := time.Now()
+	previousReplicas := service.Replicas
+	service.Autoscaling = &autoscaling
+	service.ReplicaOverride = nil
+	service.Replicas = autoscaling.Clamp(service.Replicas)
+
+	if err := h.store.Services().Update(r.Context(), app.ID, service); err != nil {
+		h.logger.Error("failed to save autoscaling", "error", err, "app_id", app.ID)
+		writeServiceStoreError(w, err, "Failed to save autoscaling")
+		return
+	}
+
+	h.logger.Info("autoscaling set", "app_id", app.ID, "service_name", service.Name,
+		"min_replicas", autoscaling.MinReplicas, "max_replicas", autoscaling.MaxReplicas)
+	h.serviceScaled(r.Context(), app.ID, service.Name, previousReplicas, service.Replicas, models.ScaleEventAutoscaler, "autoscaling bounds set")
+	WriteJSON(w, http.StatusOK, autoscalingResponse(service, now))
+}
+
+// DeleteAutoscaling handles DELETE /v1/apps/{appID}/services/{serviceName}/autoscaling -
+// turns off the service's autoscaling. The service keeps its current replica
+// count.
+func (h *ServiceHandler) DeleteAutoscaling(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	if service.Autoscaling == nil {
+		WriteNotFound(w, "Service is not autoscaled")
+		return
+	}
+
+	service.Autoscaling = nil
+	service.ReplicaOverride = nil
+
+	if err := h.store.Services().Update(r.Context(), app.ID, service); err != nil {
+		h.logger.Error("failed to remove autoscaling", "error", err, "app_id", app.ID)
+		writeServiceStoreError(w, err, "Failed to remove autoscaling")
+		return
+	}
+
+	h.logger.Info("autoscaling removed", "app_id", app.ID, "service_name", service.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListScaleEvents handles GET /v1/apps/{appID}/services/{serviceName}/scale-events -
+// lists the service's most recent changes of replica count, newest first,
+// whether made by the autoscaler, a scaling schedule or a person.
+func (h *ServiceHandler) ListScaleEvents(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultScaleEvents
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.store.ScaleEvents().List(r.Context(), app.ID, service.Name, limit)
+	if err != nil {
+		h.logger.Error("failed to list scale events", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to list scale events")
+		return
+	}
+	if events == nil {
+		events = []*models.ScaleEvent{}
+	}
+	WriteJSON(w, http.StatusOK, events)
+}
+
+// autoscalingResponse describes the service's autoscaling at the given time.
+func autoscalingResponse(service *models.ServiceConfig, now time.Time) AutoscalingResponse {
+	resp := AutoscalingResponse{
+		Autoscaling: service.Autoscaling,
+		Replicas:    service.DesiredReplicas(now),
+	}
+	if service.Autoscaling != nil && service.ReplicaOverride.ActiveAt(now) {
+		resp.Override = service.ReplicaOverride
+	}
+	return resp
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/deploy"
+)
+
+// BindingsResponse lists the connection strings injected into a service for
+// the database services it depends on.
+type BindingsResponse struct {
+	Service  string           `json:"service"`
+	Bindings []deploy.Binding `json:"bindings"`
+}
+
+// GetBindings handles GET /v1/apps/{appID}/services/{serviceName}/bindings -
+// returns the database bindings the service's next deployment receives, with
+// passwords masked.
+func (h *ServiceHandler) GetBindings(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	bindings, err := deploy.NewEnvMerger(h.store, h.sopsService, h.logger).Bindings(r.Context(), app.ID, service.Name)
+	if err != nil {
+		h.logger.Error("failed to compute bindings", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to compute bindings")
+		return
+	}
+
+	resp := BindingsResponse{Service: service.Name, Bindings: make([]deploy.Binding, 0, len(bindings))}
+	for _, b := range bindings {
+		resp.Bindings = append(resp.Bindings, b.Redacted())
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+)
+
+// BuildAttestationResponse is a build's signed provenance with the result of
+// verifying it: the signature against the worker's key, and the statement
+// against the build it is stored with.
+type BuildAttestationResponse struct {
+	*models.BuildAttestation
+	Statement *provenance.Statement `json:"statement,omitempty"`
+	Verified  bool                  `json:"verified"`
+	// VerificationError explains why the attestation did not verify.
+	VerificationError string `json:"verification_error,omitempty"`
+}
+
+// GetAttestation handles GET /v1/builds/{buildID}/attestation - returns the
+// SLSA provenance the worker signed for a successful build, verified.
+func (h *BuildHandler) GetAttestation(w http.ResponseWriter, r *http.Request) {
+	build, ok := h.ownedBuild(w, r)
+	if !ok {
+		return
+	}
+
+	attestation, err := h.store.BuildAttestations().Get(r.Context(), build.ID)
+	if err != nil {
+		h.logger.Error("failed to get build attestation", "error", err, "build_id", build.ID)
+		WriteInternalError(w, "Failed to get build attestation")
+		return
+	}
+	if attestation == nil {
+		WriteNotFound(w, "Build has no attestation")
+		return
+	}
+
+	resp := BuildAttestationResponse{BuildAttestation: attestation}
+	statement, err := verifyAttestation(attestation, build)
+	if err != nil {
+		resp.VerificationError = err.Error()
+	} else {
+		resp.Statement = statement
+		resp.Verified = true
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// verifyAttestation checks an attestation's signature and that its statement
+// describes the build: the same invocation and artifact.
+func verifyAttestation(attestation *models.BuildAttestation, build *models.BuildJob) (*provenance.Statement, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(attestation.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	if provenance.KeyID(publicKey) != attestation.KeyID {
+		return nil, errors.New("public key does not match key ID")
+	}
+
+	var envelope provenance.Envelope
+	if err := json.Unmarshal(attestation.Envelope, &envelope); err != nil {
+		return nil, errors.New("invalid envelope")
+	}
+	statement, err := provenance.Verify(&envelope, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement.Predicate.RunDetails.Metadata.InvocationID != build.ID {
+		return nil, errors.New("statement describes a different build")
+	}
+	if len(statement.Subject) != 1 || statement.Subject[0].Name != build.Artifact {
+		return nil, errors.New("statement subject does not match the build's artifact")
+	}
+	return statement, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockBuildAttestationStore implements store.BuildAttestationStore for testing.
+type mockBuildAttestationStore struct {
+	store.BuildAttestationStore
+	attestations map[string]*models.BuildAttestation
+}
+
+func (m *mockBuildAttestationStore) Get(ctx context.Context, buildID string) (*models.BuildAttestation, error) {
+	return m.attestations[buildID], nil
+}
+
+// attestationMockStore adds build attestations to the deployment mock store.
+type attestationMockStore struct {
+	*deploymentMockStore
+	attestations *mockBuildAttestationStore
+}
+
+func (m *attestationMockStore) BuildAttestations() store.BuildAttestationStore {
+	return m.attestations
+}
+
+func TestGetAttestation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	_, key, _ := ed25519.GenerateKey(nil)
+	signer := provenance.NewSigner(key)
+
+	build := &models.BuildJob{ID: "build-1", AppID: "app-1", Status: models.BuildStatusSucceeded,
+		GitURL: "https://github.com/acme/shop", GitRef: "main", Artifact: "/nix/store/abc123-web"}
+
+	attestation := func(job models.BuildJob) *models.BuildAttestation {
+		envelope, err := signer.Sign(provenance.Generate(&job, "deadbeef", "worker-a", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(envelope)
+		return &models.BuildAttestation{
+			BuildID:   "build-1",
+			KeyID:     signer.KeyID(),
+			PublicKey: base64.StdEncoding.EncodeToString(signer.PublicKey()),
+			Envelope:  data,
+		}
+	}
+	get := func(a *models.BuildAttestation, userID string) (*httptest.ResponseRecorder, BuildAttestationResponse) {
+		st := &attestationMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			attestations:        &mockBuildAttestationStore{attestations: map[string]*models.BuildAttestation{}},
+		}
+		st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+		st.buildStore.builds["build-1"] = build
+		if a != nil {
+			st.attestations.attestations["build-1"] = a
+		}
+		rr := httptest.NewRecorder()
+		NewBuildHandler(st, nil, nil, logger).GetAttestation(rr, snapshotRequest(http.MethodGet, "/v1/builds/build-1/attestation", userID))
+		var resp BuildAttestationResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return rr, resp
+	}
+
+	t.Run("verified", func(t *testing.T) {
+		rr, resp := get(attestation(*build), "user-1")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		if !resp.Verified || resp.Statement == nil {
+			t.Fatalf("verified = %v (%s)", resp.Verified, resp.VerificationError)
+		}
+		deps := resp.Statement.Predicate.BuildDefinition.ResolvedDependencies
+		if len(deps) != 1 || deps[0].Digest["gitCommit"] != "deadbeef" {
+			t.Errorf("dependencies = %+v", deps)
+		}
+	})
+
+	t.Run("other artifact", func(t *testing.T) {
+		other := *build
+		other.Artifact = "/nix/store/evil-web"
+		rr, resp := get(attestation(other), "user-1")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		if resp.Verified || resp.VerificationError == "" {
+			t.Errorf("verified = %v, error = %q; want unverified", resp.Verified, resp.VerificationError)
+		}
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		a := attestation(*build)
+		_, otherKey, _ := ed25519.GenerateKey(nil)
+		a.PublicKey = base64.StdEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey))
+		a.KeyID = provenance.KeyID(otherKey.Public().(ed25519.PublicKey))
+		_, resp := get(a, "user-1")
+		if resp.Verified {
+			t.Error("attestation verified with another key")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if rr, _ := get(nil, "user-1"); rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rr.Code)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		if rr, _ := get(attestation(*build), "user-2"); rr.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", rr.Code)
+		}
+	})
+}
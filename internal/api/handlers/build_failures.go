@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultBuildFailureDays is how many days of builds GetBuildFailureStats
+// summarizes without a days parameter.
+const defaultBuildFailureDays = 30
+
+// GetBuildFailureStats handles GET /v1/apps/{appID}/services/{serviceName}/build-failures -
+// summarizes how the service's builds of the last days (1 to 90, default 30)
+// failed: how many builds ran, failed and needed retries, and the failed
+// builds by failure category, most frequent first.
+func (h *ServiceHandler) GetBuildFailureStats(w http.ResponseWriter, r *http.Request) {
+	days := defaultBuildFailureDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 90 {
+			WriteBadRequest(w, "days must be between 1 and 90")
+			return
+		}
+		days = n
+	}
+
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	since := time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(days) * 24 * time.Hour)
+	stats, err := h.store.Builds().FailureStats(r.Context(), app.ID, service.Name, since)
+	if err != nil {
+		h.logger.Error("failed to get build failure stats", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to get build failure stats")
+		return
+	}
+	WriteJSON(w, http.StatusOK, stats)
+}
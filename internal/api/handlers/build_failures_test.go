@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestGetBuildFailureStats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newDeploymentMockStore()
+	st.appStore.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx"}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+
+	tests := []struct {
+		name    string
+		service string
+		query   string
+		status  int
+		days    int
+	}{
+		{"default window", "web", "", http.StatusOK, defaultBuildFailureDays},
+		{"custom window", "web", "?days=7", http.StatusOK, 7},
+		{"window too long", "web", "?days=365", http.StatusBadRequest, 0},
+		{"unknown service", "api", "", http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			target := "/v1/apps/app-1/services/" + tt.service + "/build-failures" + tt.query
+			h.GetBuildFailureStats(rr, templateRequest(http.MethodGet, target, nil, map[string]string{"serviceName": tt.service}))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var stats models.BuildFailureStats
+			if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			want := time.Now().Add(-time.Duration(tt.days) * 24 * time.Hour)
+			if stats.ServiceName != "web" || stats.Since.Sub(want).Abs() > time.Hour {
+				t.Errorf("stats = %+v, want web since %s", stats, want)
+			}
+		})
+	}
+}
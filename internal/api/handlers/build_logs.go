@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// buildLogPollInterval is how often the stream checks for new build output.
+const buildLogPollInterval = 500 * time.Millisecond
+
+// buildLogPageSize bounds the chunks read from the store per poll.
+const buildLogPageSize = 200
+
+// StreamLogs handles GET /v1/builds/{buildID}/logs/stream - streams a build's
+// output via Server-Sent Events. Each "log" event carries one chunk and uses
+// its sequence number as the event ID, so reconnecting clients resume through
+// Last-Event-ID; the after query parameter does the same for new streams.
+// The stream ends with a "complete" event once the build finished and all of
+// its output was sent.
+func (h *BuildHandler) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	buildID := chi.URLParam(r, "buildID")
+	if buildID == "" {
+		WriteBadRequest(w, "Build ID is required")
+		return
+	}
+
+	after := 0
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("after")
+	}
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			WriteBadRequest(w, "after must be a non-negative chunk sequence number")
+			return
+		}
+		after = n
+	}
+
+	build, err := h.store.Builds().Get(r.Context(), buildID)
+	if err != nil || build == nil {
+		WriteNotFound(w, "Build not found")
+		return
+	}
+	userID := middleware.GetUserID(r.Context())
+	app, err := h.store.Apps().Get(r.Context(), build.AppID)
+	if err != nil || app.OwnerID != userID {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+	if !rehydrate(w, r, h.archiver, h.logger, build.DeploymentID) {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+	flusher.Flush()
+
+	ctx := r.Context()
+	pollTicker := time.NewTicker(buildLogPollInterval)
+	defer pollTicker.Stop()
+	pingTicker := time.NewTicker(15 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		// Read the status before the output: the worker persists all output
+		// before marking the build finished, so a finished status read here
+		// means the chunks read next are complete
+		status := build.Status
+		if current, err := h.store.Builds().Get(ctx, buildID); err == nil && current != nil {
+			status = current.Status
+		}
+
+		chunks, err := h.store.BuildLogs().List(ctx, buildID, after, buildLogPageSize)
+		if err != nil {
+			h.logger.Error("failed to list build log chunks", "error", err, "build_id", buildID)
+		}
+		for _, chunk := range chunks {
+			writeSSE(w, strconv.Itoa(chunk.Seq), "log", chunk)
+			after = chunk.Seq
+		}
+		if len(chunks) > 0 {
+			flusher.Flush()
+		}
+
+		if err == nil && len(chunks) < buildLogPageSize && models.IsTerminalState(status) {
+			writeSSE(w, "", "complete", map[string]string{"build_id": buildID, "status": string(status)})
+			flusher.Flush()
+			return
+		}
+		if len(chunks) == buildLogPageSize {
+			// More output is waiting; read it without waiting for the ticker
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-pingTicker.C:
+			writeSSE(w, "", "ping", map[string]int64{"time": time.Now().Unix()})
+			flusher.Flush()
+		case <-pollTicker.C:
+		}
+	}
+}
+
+// writeSSE writes a Server-Sent Event with an optional ID.
+func writeSSE(w http.ResponseWriter, id, event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockBuildLogStore implements store.BuildLogStore for testing.
+type mockBuildLogStore struct {
+	chunks []*models.BuildLogChunk
+}
+
+func (m *mockBuildLogStore) Append(ctx context.Context, chunk *models.BuildLogChunk) error {
+	chunk.Seq = len(m.chunks) + 1
+	m.chunks = append(m.chunks, chunk)
+	return nil
+}
+
+func (m *mockBuildLogStore) List(ctx context.Context, buildID string, afterSeq, limit int) ([]*models.BuildLogChunk, error) {
+	var result []*models.BuildLogChunk
+	for _, c := range m.chunks {
+		if c.BuildID == buildID && c.Seq > afterSeq {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBuildLogStore) Restore(ctx context.Context, chunks []*models.BuildLogChunk) error {
+	m.chunks = append(m.chunks, chunks...)
+	return nil
+}
+
+func (m *mockBuildLogStore) DeleteByBuild(ctx context.Context, buildID string) error {
+	kept := m.chunks[:0]
+	for _, c := range m.chunks {
+		if c.BuildID != buildID {
+			kept = append(kept, c)
+		}
+	}
+	m.chunks = kept
+	return nil
+}
+
+// buildLogMockStore adds build log chunks to the deployment mock store.
+type buildLogMockStore struct {
+	*deploymentMockStore
+	buildLogs *mockBuildLogStore
+}
+
+func (m *buildLogMockStore) BuildLogs() store.BuildLogStore {
+	return m.buildLogs
+}
+
+func TestBuildStreamLogs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name        string
+		userID      string
+		query       string
+		lastEventID string
+		status      int
+		want        []string
+		notWant     []string
+	}{
+		{"full log", "user-1", "", "", http.StatusOK,
+			[]string{"id: 1\nevent: log", "cloning", "id: 2\nevent: log", "building", "event: complete", `"status":"succeeded"`}, nil},
+		{"resume with after", "user-1", "?after=1", "", http.StatusOK,
+			[]string{"id: 2\nevent: log", "building", "event: complete"}, []string{"cloning"}},
+		{"resume with Last-Event-ID", "user-1", "", "2", http.StatusOK,
+			[]string{"event: complete"}, []string{"cloning", "building"}},
+		{"invalid cursor", "user-1", "?after=x", "", http.StatusBadRequest, nil, nil},
+		{"other user", "user-2", "", "", http.StatusForbidden, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &buildLogMockStore{deploymentMockStore: newDeploymentMockStore(), buildLogs: &mockBuildLogStore{}}
+			st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+			st.buildStore.builds["build-1"] = &models.BuildJob{ID: "build-1", AppID: "app-1", Status: models.BuildStatusSucceeded}
+			st.buildLogs.Append(context.Background(), &models.BuildLogChunk{BuildID: "build-1", Content: "cloning\n"})
+			st.buildLogs.Append(context.Background(), &models.BuildLogChunk{BuildID: "build-1", Content: "building\n"})
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/builds/build-1/logs/stream"+tt.query, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("buildID", "build-1")
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			NewBuildHandler(st, nil, nil, logger).StreamLogs(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("stream missing %q:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(body, s) {
+					t.Errorf("stream contains %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
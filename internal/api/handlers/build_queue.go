@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// BuildQueueHandler lets instance admins see, reorder and cancel queued
+// builds. All routes are expected to be mounted behind middleware.RequireAdmin.
+type BuildQueueHandler struct {
+	store  store.Store
+	queue  queue.ManagedQueue
+	logger *slog.Logger
+}
+
+// NewBuildQueueHandler creates a new build queue handler.
+func NewBuildQueueHandler(st store.Store, q queue.ManagedQueue, logger *slog.Logger) *BuildQueueHandler {
+	return &BuildQueueHandler{
+		store:  st,
+		queue:  q,
+		logger: logger,
+	}
+}
+
+// ReorderBuildRequest moves a queued build. An empty priority keeps the
+// build's priority; first moves it ahead of the queued builds of its
+// priority.
+type ReorderBuildRequest struct {
+	Priority models.BuildPriority `json:"priority,omitempty"`
+	First    bool                 `json:"first,omitempty"`
+}
+
+// List handles GET /v1/admin/build-queue - lists queued builds in the order
+// workers claim them.
+func (h *BuildQueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.queue.ListPending(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list build queue", "error", err)
+		WriteInternalError(w, "Failed to list build queue")
+		return
+	}
+	if jobs == nil {
+		jobs = []*queue.QueuedJob{}
+	}
+	WriteJSON(w, http.StatusOK, jobs)
+}
+
+// Reorder handles PATCH /v1/admin/build-queue/{buildID} - changes the
+// priority of a queued build or moves it to the front of its priority.
+func (h *BuildQueueHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	var req ReorderBuildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Priority != "" && !req.Priority.IsValid() {
+		WriteBadRequest(w, "priority must be user, webhook or retry")
+		return
+	}
+
+	build, ok := h.loadQueuedBuild(w, r)
+	if !ok {
+		return
+	}
+	if req.Priority == "" {
+		req.Priority = build.Priority
+	}
+
+	if err := h.queue.Reorder(r.Context(), build.ID, req.Priority, req.First); err != nil {
+		h.writeQueueError(w, err, build.ID, "Failed to reorder build")
+		return
+	}
+
+	build.Priority = req.Priority
+	if err := h.store.Builds().Update(r.Context(), build); err != nil {
+		h.logger.Error("failed to save build priority", "error", err, "build_id", build.ID)
+	}
+	if position, err := h.queue.Position(r.Context(), build.ID); err == nil {
+		build.QueuePosition = position
+	}
+
+	h.logger.Info("build reordered", "build_id", build.ID, "priority", build.Priority, "first", req.First)
+	WriteJSON(w, http.StatusOK, build)
+}
+
+// Cancel handles DELETE /v1/admin/build-queue/{buildID} - removes a queued
+// build from the queue and cancels it and its deployment.
+func (h *BuildQueueHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	build, ok := h.loadQueuedBuild(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.queue.Cancel(r.Context(), build.ID); err != nil {
+		h.writeQueueError(w, err, build.ID, "Failed to cancel build")
+		return
+	}
+
+	markBuildCanceled(r.Context(), h.store, build, h.logger)
+
+	h.logger.Info("build cancelled", "build_id", build.ID, "app_id", build.AppID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadQueuedBuild loads the build of the request, writing an error unless it
+// is queued.
+func (h *BuildQueueHandler) loadQueuedBuild(w http.ResponseWriter, r *http.Request) (*models.BuildJob, bool) {
+	build, err := h.store.Builds().Get(r.Context(), chi.URLParam(r, "buildID"))
+	if err != nil {
+		WriteNotFound(w, "Build not found")
+		return nil, false
+	}
+	if build.Status != models.BuildStatusQueued {
+		WriteConflict(w, "Build is not queued")
+		return nil, false
+	}
+	return build, true
+}
+
+// writeQueueError writes the error of a queue change to a build; a build
+// that left the queue in the meantime is no longer queued.
+func (h *BuildQueueHandler) writeQueueError(w http.ResponseWriter, err error, buildID, message string) {
+	if errors.Is(err, queue.ErrJobNotFound) {
+		WriteConflict(w, "Build is not queued")
+		return
+	}
+	h.logger.Error("failed to change build queue", "error", err, "build_id", buildID)
+	WriteInternalError(w, message)
+}
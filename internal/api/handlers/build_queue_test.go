@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/queue"
+)
+
+// managedMockQueue implements queue.ManagedQueue for testing, claiming
+// jobs in the order they were enqueued.
+type managedMockQueue struct {
+	mockQueue
+}
+
+func (m *managedMockQueue) ListPending(ctx context.Context) ([]*queue.QueuedJob, error) {
+	var jobs []*queue.QueuedJob
+	for i, job := range m.jobs {
+		jobs = append(jobs, &queue.QueuedJob{ID: job.ID, AppID: job.AppID, Priority: job.Priority, Position: i + 1})
+	}
+	return jobs, nil
+}
+
+func (m *managedMockQueue) Position(ctx context.Context, jobID string) (int, error) {
+	for i, job := range m.jobs {
+		if job.ID == jobID {
+			return i + 1, nil
+		}
+	}
+	return 0, queue.ErrJobNotFound
+}
+
+func (m *managedMockQueue) Reorder(ctx context.Context, jobID string, priority models.BuildPriority, first bool) error {
+	for i, job := range m.jobs {
+		if job.ID != jobID {
+			continue
+		}
+		job.Priority = priority
+		if first {
+			m.jobs = append([]*models.BuildJob{job}, append(m.jobs[:i:i], m.jobs[i+1:]...)...)
+		}
+		return nil
+	}
+	return queue.ErrJobNotFound
+}
+
+func (m *managedMockQueue) Cancel(ctx context.Context, jobID string) error {
+	for i, job := range m.jobs {
+		if job.ID == jobID {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return queue.ErrJobNotFound
+}
+
+func TestBuildQueue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newDeploymentMockStore()
+	q := &managedMockQueue{}
+	for _, id := range []string{"b1", "b2", "b3"} {
+		build := &models.BuildJob{ID: id, AppID: "app-1", DeploymentID: "d-" + id, Status: models.BuildStatusQueued, Priority: models.BuildPriorityRetry}
+		st.buildStore.builds[id] = build
+		st.deploymentStore.deployments["d-"+id] = &models.Deployment{ID: "d-" + id, AppID: "app-1", Status: models.DeploymentStatusPending}
+		q.Enqueue(context.Background(), build)
+	}
+	st.buildStore.builds["b4"] = &models.BuildJob{ID: "b4", AppID: "app-1", Status: models.BuildStatusRunning}
+	h := NewBuildQueueHandler(st, q, logger)
+
+	t.Run("moves a build to the front with a new priority", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Reorder(rr, templateRequest(http.MethodPatch, "/v1/admin/build-queue/b3",
+			ReorderBuildRequest{Priority: models.BuildPriorityUser, First: true}, map[string]string{"buildID": "b3"}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		var build models.BuildJob
+		if err := json.Unmarshal(rr.Body.Bytes(), &build); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if build.QueuePosition != 1 || build.Priority != models.BuildPriorityUser {
+			t.Errorf("build = position %d priority %q, want position 1 priority user", build.QueuePosition, build.Priority)
+		}
+		if st.buildStore.builds["b3"].Priority != models.BuildPriorityUser {
+			t.Error("new priority was not saved on the build")
+		}
+	})
+
+	t.Run("rejects an unknown priority", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Reorder(rr, templateRequest(http.MethodPatch, "/v1/admin/build-queue/b1",
+			ReorderBuildRequest{Priority: "urgent"}, map[string]string{"buildID": "b1"}))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rr.Code)
+		}
+	})
+
+	t.Run("cancels a queued build", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Cancel(rr, templateRequest(http.MethodDelete, "/v1/admin/build-queue/b1", nil, map[string]string{"buildID": "b1"}))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		if _, err := q.Position(context.Background(), "b1"); err != queue.ErrJobNotFound {
+			t.Error("cancelled build is still queued")
+		}
+		if st.buildStore.builds["b1"].Status != models.BuildStatusCanceled || st.deploymentStore.deployments["d-b1"].Status != models.DeploymentStatusCanceled {
+			t.Error("cancelled build and its deployment were not canceled")
+		}
+
+		rr = httptest.NewRecorder()
+		h.List(rr, templateRequest(http.MethodGet, "/v1/admin/build-queue", nil, nil))
+		var jobs []*queue.QueuedJob
+		if err := json.Unmarshal(rr.Body.Bytes(), &jobs); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if len(jobs) != 2 || jobs[0].ID != "b3" || jobs[1].ID != "b2" {
+			t.Errorf("queue = %+v, want b3, b2", jobs)
+		}
+	})
+
+	t.Run("refuses builds that are not queued", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Cancel(rr, templateRequest(http.MethodDelete, "/v1/admin/build-queue/b4", nil, map[string]string{"buildID": "b4"}))
+		if rr.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rr.Code)
+		}
+	})
+}
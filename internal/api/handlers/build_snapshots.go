@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
+)
+
+// BuildSnapshotResponse is the kept environment of a failed build with the
+// command that starts a shell in it on the worker host.
+type BuildSnapshotResponse struct {
+	*models.BuildSnapshot
+	Command string `json:"command"`
+}
+
+// GetSnapshot handles GET /v1/builds/{buildID}/snapshot - returns the
+// environment kept for debugging a failed build.
+func (h *BuildHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := h.buildSnapshot(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, BuildSnapshotResponse{
+		BuildSnapshot: snapshot,
+		Command:       shellJoin(h.podman.RunInteractive(snapshotContainer(snapshot)).Args),
+	})
+}
+
+// DeleteSnapshot handles DELETE /v1/builds/{buildID}/snapshot - discards the
+// environment kept for a failed build. The snapshot expires immediately and
+// the worker holding it removes its directory the next time it prunes.
+func (h *BuildHandler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := h.buildSnapshot(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot.ExpiresAt = time.Now()
+	if err := h.store.BuildSnapshots().Save(r.Context(), snapshot); err != nil {
+		h.logger.Error("failed to expire build snapshot", "error", err, "build_id", snapshot.BuildID)
+		WriteInternalError(w, "Failed to discard build snapshot")
+		return
+	}
+
+	h.logger.Info("build snapshot discarded", "build_id", snapshot.BuildID, "user_id", middleware.GetUserID(r.Context()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DebugWS handles GET /v1/builds/{buildID}/debug/ws - opens a shell in a new
+// container started from a failed build's snapshot: the build's image and
+// environment with its working directory mounted where the build had it.
+// The shell runs through the local Podman, so it is only available when the
+// snapshot is kept on the API server's host.
+func (h *BuildHandler) DebugWS(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := h.buildSnapshot(w, r)
+	if !ok {
+		return
+	}
+	if hostname, _ := os.Hostname(); snapshot.Hostname != hostname {
+		WriteConflict(w, fmt.Sprintf("The build environment is kept on worker host %s; run the snapshot's command there", snapshot.Hostname))
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("failed to upgrade websocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	h.logger.Info("build debug shell started",
+		"build_id", snapshot.BuildID,
+		"user_id", middleware.GetUserID(r.Context()),
+		"image", snapshot.Image,
+	)
+
+	c := h.podman.RunInteractive(snapshotContainer(snapshot))
+	f, err := pty.Start(c)
+	if err != nil {
+		h.logger.Error("failed to start pty", "error", err, "build_id", snapshot.BuildID)
+		return
+	}
+	defer f.Close()
+
+	// Set initial size
+	_ = pty.Setsize(f, &pty.Winsize{Rows: 24, Cols: 80})
+
+	// Clean up process on exit; the container is started with --rm
+	defer func() {
+		if c.Process != nil {
+			c.Process.Kill()
+		}
+	}()
+
+	// Copy PTY output to WebSocket
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Handle WebSocket input
+	for {
+		mt, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if mt == websocket.TextMessage {
+			var ctrl struct {
+				Type string `json:"type"`
+				Rows uint16 `json:"rows"`
+				Cols uint16 `json:"cols"`
+			}
+			if err := json.Unmarshal(msg, &ctrl); err == nil {
+				if ctrl.Type == "resize" {
+					_ = pty.Setsize(f, &pty.Winsize{Rows: ctrl.Rows, Cols: ctrl.Cols})
+					continue
+				}
+				if ctrl.Type == "terminate" {
+					return
+				}
+			}
+		}
+
+		if mt == websocket.BinaryMessage || mt == websocket.TextMessage {
+			f.Write(msg)
+		}
+	}
+}
+
+// buildSnapshot loads the unexpired snapshot of the build in the URL and
+// checks the build's owner.
+func (h *BuildHandler) buildSnapshot(w http.ResponseWriter, r *http.Request) (*models.BuildSnapshot, bool) {
+	build, ok := h.ownedBuild(w, r)
+	if !ok {
+		return nil, false
+	}
+	buildID := build.ID
+
+	snapshot, err := h.store.BuildSnapshots().Get(r.Context(), buildID)
+	if err != nil {
+		h.logger.Error("failed to get build snapshot", "error", err, "build_id", buildID)
+		WriteInternalError(w, "Failed to get build snapshot")
+		return nil, false
+	}
+	if snapshot == nil || snapshot.Expired(time.Now()) {
+		WriteNotFound(w, "Build has no snapshot")
+		return nil, false
+	}
+	return snapshot, true
+}
+
+// ownedBuild fetches the build named in the URL if it belongs to one of the
+// user's apps, writing an error response if it does not.
+func (h *BuildHandler) ownedBuild(w http.ResponseWriter, r *http.Request) (*models.BuildJob, bool) {
+	buildID := chi.URLParam(r, "buildID")
+	if buildID == "" {
+		WriteBadRequest(w, "Build ID is required")
+		return nil, false
+	}
+
+	build, err := h.store.Builds().Get(r.Context(), buildID)
+	if err != nil || build == nil {
+		WriteNotFound(w, "Build not found")
+		return nil, false
+	}
+	app, err := h.store.Apps().Get(r.Context(), build.AppID)
+	if err != nil || app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return nil, false
+	}
+	return build, true
+}
+
+// snapshotContainer describes a container for debugging a build snapshot,
+// configured like the container the build ran in.
+func snapshotContainer(snapshot *models.BuildSnapshot) *podman.ContainerConfig {
+	shell := snapshot.Shell
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	env := map[string]string{"TERM": "xterm-256color"}
+	for k, v := range snapshot.Env {
+		env[k] = v
+	}
+	return &podman.ContainerConfig{
+		Image:       snapshot.Image,
+		Entrypoint:  []string{shell},
+		WorkDir:     snapshot.WorkDir,
+		User:        "root",
+		Privileged:  true,
+		NetworkMode: "host",
+		Remove:      true,
+		Env:         env,
+		Mounts:      []podman.Mount{{Source: snapshot.Path, Target: "/build"}},
+	}
+}
+
+// shellJoin quotes arguments for a POSIX shell where needed and joins them.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=@%+,") == "" {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockBuildSnapshotStore implements store.BuildSnapshotStore for testing.
+type mockBuildSnapshotStore struct {
+	store.BuildSnapshotStore
+	snapshots map[string]*models.BuildSnapshot
+}
+
+func (m *mockBuildSnapshotStore) Save(ctx context.Context, snapshot *models.BuildSnapshot) error {
+	m.snapshots[snapshot.BuildID] = snapshot
+	return nil
+}
+
+func (m *mockBuildSnapshotStore) Get(ctx context.Context, buildID string) (*models.BuildSnapshot, error) {
+	return m.snapshots[buildID], nil
+}
+
+// snapshotMockStore adds build snapshots to the deployment mock store.
+type snapshotMockStore struct {
+	*deploymentMockStore
+	snapshots *mockBuildSnapshotStore
+}
+
+func (m *snapshotMockStore) BuildSnapshots() store.BuildSnapshotStore {
+	return m.snapshots
+}
+
+func snapshotRequest(method, target, userID string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("buildID", "build-1")
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestBuildSnapshot(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	hostname, _ := os.Hostname()
+
+	newStore := func(snapshot *models.BuildSnapshot) *snapshotMockStore {
+		st := &snapshotMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			snapshots:           &mockBuildSnapshotStore{snapshots: map[string]*models.BuildSnapshot{}},
+		}
+		st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+		st.buildStore.builds["build-1"] = &models.BuildJob{ID: "build-1", AppID: "app-1", Status: models.BuildStatusFailed}
+		if snapshot != nil {
+			st.snapshots.snapshots["build-1"] = snapshot
+		}
+		return st
+	}
+	snapshot := func(host string, expiresIn time.Duration) *models.BuildSnapshot {
+		return &models.BuildSnapshot{
+			BuildID:   "build-1",
+			Hostname:  host,
+			Path:      "/tmp/narvana-builds/build-1",
+			Image:     "docker.io/nixos/nix:latest",
+			Shell:     "/root/.nix-profile/bin/bash",
+			WorkDir:   "/build/src",
+			Env:       map[string]string{"NIX_CONFIG": "experimental-features = nix-command flakes"},
+			ExpiresAt: time.Now().Add(expiresIn),
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		h := NewBuildHandler(newStore(snapshot(hostname, time.Hour)), nil, podman.NewClient("", logger), logger)
+		rr := httptest.NewRecorder()
+		h.GetSnapshot(rr, snapshotRequest(http.MethodGet, "/v1/builds/build-1/snapshot", "user-1"))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp BuildSnapshotResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		for _, want := range []string{
+			"podman run -it",
+			"-v /tmp/narvana-builds/build-1:/build",
+			"--workdir /build/src",
+			"'NIX_CONFIG=experimental-features = nix-command flakes'",
+			"--entrypoint /root/.nix-profile/bin/bash docker.io/nixos/nix:latest",
+		} {
+			if !strings.Contains(resp.Command, want) {
+				t.Errorf("command %q does not contain %q", resp.Command, want)
+			}
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		h := NewBuildHandler(newStore(snapshot(hostname, -time.Minute)), nil, podman.NewClient("", logger), logger)
+		rr := httptest.NewRecorder()
+		h.GetSnapshot(rr, snapshotRequest(http.MethodGet, "/v1/builds/build-1/snapshot", "user-1"))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rr.Code)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		h := NewBuildHandler(newStore(snapshot(hostname, time.Hour)), nil, podman.NewClient("", logger), logger)
+		rr := httptest.NewRecorder()
+		h.GetSnapshot(rr, snapshotRequest(http.MethodGet, "/v1/builds/build-1/snapshot", "user-2"))
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", rr.Code)
+		}
+	})
+
+	t.Run("delete expires the snapshot", func(t *testing.T) {
+		st := newStore(snapshot(hostname, time.Hour))
+		h := NewBuildHandler(st, nil, podman.NewClient("", logger), logger)
+		rr := httptest.NewRecorder()
+		h.DeleteSnapshot(rr, snapshotRequest(http.MethodDelete, "/v1/builds/build-1/snapshot", "user-1"))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		if !st.snapshots.snapshots["build-1"].Expired(time.Now()) {
+			t.Error("discarded snapshot should be expired")
+		}
+	})
+
+	t.Run("debug shell on another host", func(t *testing.T) {
+		h := NewBuildHandler(newStore(snapshot("worker-2", time.Hour)), nil, podman.NewClient("", logger), logger)
+		rr := httptest.NewRecorder()
+		h.DebugWS(rr, snapshotRequest(http.MethodGet, "/v1/builds/build-1/debug/ws", "user-1"))
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "worker-2") {
+			t.Errorf("status = %d, body = %s; want 409 naming the worker host", rr.Code, rr.Body.String())
+		}
+	})
+}
@@ -1,31 +1,46 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/archive"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
 	"github.com/narvanalabs/control-plane/internal/queue"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
 // BuildHandler handles build-related HTTP requests.
 type BuildHandler struct {
-	store  store.Store
-	queue  queue.Queue
-	logger *slog.Logger
+	store    store.Store
+	queue    queue.Queue
+	podman   *podman.Client
+	archiver *archive.Archiver
+	logger   *slog.Logger
 }
 
 // NewBuildHandler creates a new build handler.
-func NewBuildHandler(st store.Store, q queue.Queue, logger *slog.Logger) *BuildHandler {
+func NewBuildHandler(st store.Store, q queue.Queue, pd *podman.Client, logger *slog.Logger) *BuildHandler {
 	return &BuildHandler{
 		store:  st,
 		queue:  q,
+		podman: pd,
 		logger: logger,
 	}
 }
 
+// SetArchiver sets the archiver that restores archived builds before they
+// are read.
+func (h *BuildHandler) SetArchiver(a *archive.Archiver) {
+	h.archiver = a
+}
+
 // List handles GET /v1/builds - lists all builds for the authenticated user.
 func (h *BuildHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
@@ -67,6 +82,25 @@ func (h *BuildHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.archiver != nil {
+		if !rehydrate(w, r, h.archiver, h.logger, build.DeploymentID) {
+			return
+		}
+		if build, err = h.store.Builds().Get(r.Context(), buildID); err != nil {
+			WriteNotFound(w, "Build not found")
+			return
+		}
+	}
+
+	// Queued builds show how many builds are claimed before them
+	if mq, ok := h.queue.(queue.ManagedQueue); ok && build.Status == models.BuildStatusQueued {
+		if position, err := mq.Position(r.Context(), build.ID); err == nil {
+			build.QueuePosition = position
+		} else if !errors.Is(err, queue.ErrJobNotFound) {
+			h.logger.Warn("failed to get queue position", "error", err, "build_id", buildID)
+		}
+	}
+
 	WriteJSON(w, http.StatusOK, build)
 }
 
@@ -92,9 +126,22 @@ func (h *BuildHandler) Retry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Reset build job
+	// Retries reuse the generated flake, which archiving removes
+	if h.archiver != nil {
+		if !rehydrate(w, r, h.archiver, h.logger, build.DeploymentID) {
+			return
+		}
+		if build, err = h.store.Builds().Get(r.Context(), buildID); err != nil {
+			WriteNotFound(w, "Build not found")
+			return
+		}
+	}
+
+	// Reset build job; a retry someone asked for is claimed like a new build
 	build.Status = "queued"
 	build.RetryCount++
+	build.Priority = models.BuildPriorityUser
+	build.TriggeredBy = userID
 
 	if err := h.store.Builds().Update(r.Context(), build); err != nil {
 		h.logger.Error("failed to update build for retry", "error", err, "build_id", buildID)
@@ -110,3 +157,91 @@ func (h *BuildHandler) Retry(w http.ResponseWriter, r *http.Request) {
 
 	WriteJSON(w, http.StatusAccepted, build)
 }
+
+// Cancel handles POST /v1/builds/{buildID}/cancel - cancels a queued or
+// running build and its deployment. A queued build is canceled at once; a
+// running build is stopped by the worker running it, which marks it
+// canceled, so the request is accepted with cancel_requested_at set.
+func (h *BuildHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	buildID := chi.URLParam(r, "buildID")
+	if buildID == "" {
+		WriteBadRequest(w, "Build ID is required")
+		return
+	}
+
+	build, err := h.store.Builds().Get(r.Context(), buildID)
+	if err != nil || build == nil {
+		WriteNotFound(w, "Build not found")
+		return
+	}
+
+	// Verify ownership
+	userID := middleware.GetUserID(r.Context())
+	app, err := h.store.Apps().Get(r.Context(), build.AppID)
+	if err != nil || app.OwnerID != userID {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	if models.IsTerminalState(build.Status) {
+		WriteConflict(w, "Build has already finished")
+		return
+	}
+
+	canceled, err := cancelBuild(r.Context(), h.store, h.queue, build, h.logger)
+	if err != nil {
+		h.logger.Error("failed to cancel build", "error", err, "build_id", buildID)
+		WriteInternalError(w, "Failed to cancel build")
+		return
+	}
+
+	h.logger.Info("build cancel requested", "build_id", buildID, "app_id", build.AppID, "canceled", canceled)
+	if !canceled {
+		WriteJSON(w, http.StatusAccepted, build)
+		return
+	}
+	WriteJSON(w, http.StatusOK, build)
+}
+
+// cancelBuild cancels a queued or running build. A build still waiting in
+// the queue is removed from it and marked canceled, reporting true;
+// otherwise the cancel is requested from the worker that claimed the build.
+func cancelBuild(ctx context.Context, st store.Store, q queue.Queue, build *models.BuildJob, logger *slog.Logger) (bool, error) {
+	if mq, ok := q.(queue.ManagedQueue); ok && build.Status == models.BuildStatusQueued {
+		err := mq.Cancel(ctx, build.ID)
+		if err == nil {
+			markBuildCanceled(ctx, st, build, logger)
+			return true, nil
+		}
+		// A build that left the queue in the meantime is running
+		if !errors.Is(err, queue.ErrJobNotFound) {
+			return false, err
+		}
+	}
+
+	if err := st.Builds().RequestCancel(ctx, build.ID); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	build.CancelRequestedAt = &now
+	return false, nil
+}
+
+// markBuildCanceled marks a build that never ran, and its deployment,
+// canceled.
+func markBuildCanceled(ctx context.Context, st store.Store, build *models.BuildJob, logger *slog.Logger) {
+	now := time.Now()
+	build.Status = models.BuildStatusCanceled
+	build.FinishedAt = &now
+	build.QueuePosition = 0
+	if err := st.Builds().Update(ctx, build); err != nil {
+		logger.Error("failed to mark build canceled", "error", err, "build_id", build.ID)
+	}
+	if deployment, err := st.Deployments().Get(ctx, build.DeploymentID); err == nil && deployment != nil {
+		deployment.Status = models.DeploymentStatusCanceled
+		deployment.UpdatedAt = now
+		if err := st.Deployments().Update(ctx, deployment); err != nil {
+			logger.Error("failed to mark deployment canceled", "error", err, "deployment_id", deployment.ID)
+		}
+	}
+}
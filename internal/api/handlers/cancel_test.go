@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/queue"
+)
+
+func TestCancel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	newStore := func() (*deploymentMockStore, *managedMockQueue) {
+		st := newDeploymentMockStore()
+		st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+		q := &managedMockQueue{}
+		for id, status := range map[string]models.BuildStatus{
+			"queued": models.BuildStatusQueued, "running": models.BuildStatusRunning, "done": models.BuildStatusSucceeded,
+		} {
+			build := &models.BuildJob{ID: id, AppID: "app-1", DeploymentID: "d-" + id, Status: status}
+			st.buildStore.builds[id] = build
+			deploymentStatus := models.DeploymentStatusBuilding
+			if status == models.BuildStatusSucceeded {
+				deploymentStatus = models.DeploymentStatusRunning
+			}
+			st.deploymentStore.deployments["d-"+id] = &models.Deployment{ID: "d-" + id, AppID: "app-1", Status: deploymentStatus}
+			if status == models.BuildStatusQueued {
+				q.Enqueue(context.Background(), build)
+			}
+		}
+		st.deploymentStore.deployments["d-built"] = &models.Deployment{ID: "d-built", AppID: "app-1", Status: models.DeploymentStatusBuilt}
+		return st, q
+	}
+
+	t.Run("cancels a queued build at once", func(t *testing.T) {
+		st, q := newStore()
+		rr := httptest.NewRecorder()
+		NewBuildHandler(st, q, nil, logger).Cancel(rr, templateRequest(http.MethodPost, "/v1/builds/queued/cancel", nil, map[string]string{"buildID": "queued"}))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		if _, err := q.Position(context.Background(), "queued"); err != queue.ErrJobNotFound {
+			t.Error("canceled build is still queued")
+		}
+		if st.buildStore.builds["queued"].Status != models.BuildStatusCanceled || st.deploymentStore.deployments["d-queued"].Status != models.DeploymentStatusCanceled {
+			t.Error("build and its deployment were not canceled")
+		}
+	})
+
+	t.Run("asks the worker to cancel a running build", func(t *testing.T) {
+		st, q := newStore()
+		rr := httptest.NewRecorder()
+		NewBuildHandler(st, q, nil, logger).Cancel(rr, templateRequest(http.MethodPost, "/v1/builds/running/cancel", nil, map[string]string{"buildID": "running"}))
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+		}
+		build := st.buildStore.builds["running"]
+		if build.CancelRequestedAt == nil || build.Status != models.BuildStatusRunning {
+			t.Errorf("build = %s with cancel requested at %v, want a running build with a cancel request", build.Status, build.CancelRequestedAt)
+		}
+	})
+
+	t.Run("refuses finished builds", func(t *testing.T) {
+		st, q := newStore()
+		rr := httptest.NewRecorder()
+		NewBuildHandler(st, q, nil, logger).Cancel(rr, templateRequest(http.MethodPost, "/v1/builds/done/cancel", nil, map[string]string{"buildID": "done"}))
+		if rr.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rr.Code)
+		}
+	})
+
+	t.Run("cancels a deployment through its build", func(t *testing.T) {
+		st, q := newStore()
+		h := NewDeploymentHandler(st, q, logger)
+		rr := httptest.NewRecorder()
+		h.Cancel(rr, templateRequest(http.MethodPost, "/v1/deployments/d-running/cancel", nil, map[string]string{"deploymentID": "d-running"}))
+		if rr.Code != http.StatusAccepted || st.buildStore.builds["running"].CancelRequestedAt == nil {
+			t.Errorf("status = %d, want 202 with a cancel request on the running build", rr.Code)
+		}
+
+		rr = httptest.NewRecorder()
+		h.Cancel(rr, templateRequest(http.MethodPost, "/v1/deployments/d-queued/cancel", nil, map[string]string{"deploymentID": "d-queued"}))
+		if rr.Code != http.StatusOK || st.deploymentStore.deployments["d-queued"].Status != models.DeploymentStatusCanceled {
+			t.Errorf("status = %d, want 200 with the deployment canceled", rr.Code)
+		}
+	})
+
+	t.Run("cancels a built deployment before it is placed", func(t *testing.T) {
+		st, q := newStore()
+		rr := httptest.NewRecorder()
+		NewDeploymentHandler(st, q, logger).Cancel(rr, templateRequest(http.MethodPost, "/v1/deployments/d-built/cancel", nil, map[string]string{"deploymentID": "d-built"}))
+		if rr.Code != http.StatusOK || st.deploymentStore.deployments["d-built"].Status != models.DeploymentStatusCanceled {
+			t.Errorf("status = %d, want 200 with the deployment canceled", rr.Code)
+		}
+	})
+
+	t.Run("refuses started deployments", func(t *testing.T) {
+		st, q := newStore()
+		rr := httptest.NewRecorder()
+		NewDeploymentHandler(st, q, logger).Cancel(rr, templateRequest(http.MethodPost, "/v1/deployments/d-done/cancel", nil, map[string]string{"deploymentID": "d-done"}))
+		if rr.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/catalog"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// CatalogHandler handles the API catalog of an org and the OpenAPI specs of
+// its services.
+type CatalogHandler struct {
+	store   store.Store
+	catalog *catalog.Catalog
+	logger  *slog.Logger
+}
+
+// NewCatalogHandler creates a new catalog handler.
+func NewCatalogHandler(st store.Store, c *catalog.Catalog, logger *slog.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		store:   st,
+		catalog: c,
+		logger:  logger,
+	}
+}
+
+// CatalogResponse lists the APIs of an org's services matching a search.
+type CatalogResponse struct {
+	Query   string                 `json:"query,omitempty"`
+	Entries []*models.CatalogEntry `json:"entries"`
+}
+
+// Search handles GET /v1/catalog - lists the APIs of the org's services,
+// filtered by the q query parameter.
+func (h *CatalogHandler) Search(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgID(r.Context())
+	if orgID == "" {
+		WriteInternalError(w, "Organization context required")
+		return
+	}
+
+	entries, err := h.store.APICatalog().ListByOrg(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list API catalog", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list API catalog")
+		return
+	}
+	if entries == nil {
+		entries = []*models.CatalogEntry{}
+	}
+
+	query := r.URL.Query().Get("q")
+	WriteJSON(w, http.StatusOK, CatalogResponse{Query: query, Entries: catalog.Search(entries, query)})
+}
+
+// GetSpec handles GET /v1/apps/{appID}/services/{serviceName}/openapi -
+// returns the OpenAPI spec last fetched for a service.
+func (h *CatalogHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	serviceName := chi.URLParam(r, "serviceName")
+
+	spec, err := h.store.APICatalog().Get(r.Context(), appID, serviceName)
+	if err != nil {
+		h.logger.Error("failed to get OpenAPI spec", "error", err, "app_id", appID, "service_name", serviceName)
+		WriteInternalError(w, "Failed to get OpenAPI spec")
+		return
+	}
+	if spec == nil {
+		WriteNotFound(w, "No OpenAPI spec has been fetched for this service")
+		return
+	}
+	WriteJSON(w, http.StatusOK, spec)
+}
+
+// RefreshSpec handles POST /v1/apps/{appID}/services/{serviceName}/openapi/refresh -
+// fetches a service's OpenAPI spec now. A failed fetch is reported in the
+// returned spec's error.
+func (h *CatalogHandler) RefreshSpec(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	serviceName := chi.URLParam(r, "serviceName")
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == serviceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		WriteNotFound(w, "Service not found")
+		return
+	}
+	if service.OpenAPIURL == "" {
+		WriteBadRequest(w, "The service has no openapi_url")
+		return
+	}
+
+	spec, err := h.catalog.Refresh(r.Context(), app.ID, service)
+	if err != nil {
+		h.logger.Error("failed to refresh OpenAPI spec", "error", err, "app_id", appID, "service_name", serviceName)
+		WriteInternalError(w, "Failed to refresh OpenAPI spec")
+		return
+	}
+	WriteJSON(w, http.StatusOK, spec)
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/cdn"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// defaultCDNPurges is the number of purges listed when no limit is given.
+const defaultCDNPurges = 20
+
+// CDNHandler handles an app's CDN integrations and cache purges.
+type CDNHandler struct {
+	store  store.Store
+	purger *cdn.Purger
+	logger *slog.Logger
+}
+
+// NewCDNHandler creates a new CDN handler.
+func NewCDNHandler(st store.Store, purger *cdn.Purger, logger *slog.Logger) *CDNHandler {
+	return &CDNHandler{
+		store:  st,
+		purger: purger,
+		logger: logger,
+	}
+}
+
+// CDNIntegrationRequest is the request body for creating or updating an
+// integration. Secret config fields left empty on update keep their stored value.
+type CDNIntegrationRequest struct {
+	Provider models.CDNProviderType `json:"provider"`
+	Name     string                 `json:"name"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+	Config   map[string]string      `json:"config"`
+	Services []string               `json:"services"`
+}
+
+// CDNPurgeRequest is the request body for a manual purge. Without an
+// integration ID every enabled integration is purged; without URLs the whole
+// cache is.
+type CDNPurgeRequest struct {
+	IntegrationID string   `json:"integration_id,omitempty"`
+	URLs          []string `json:"urls,omitempty"`
+}
+
+// ListIntegrations handles GET /v1/apps/{appID}/cdn/integrations - lists the
+// app's integrations with secrets redacted.
+func (h *CDNHandler) ListIntegrations(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	integrations, err := h.store.CDN().ListIntegrations(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list CDN integrations", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list CDN integrations")
+		return
+	}
+
+	redacted := make([]*models.CDNIntegration, 0, len(integrations))
+	for _, i := range integrations {
+		redacted = append(redacted, h.purger.Redact(i))
+	}
+	WriteJSON(w, http.StatusOK, redacted)
+}
+
+// CreateIntegration handles POST /v1/apps/{appID}/cdn/integrations - adds an integration.
+func (h *CDNHandler) CreateIntegration(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+
+	var req CDNIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	integration := &models.CDNIntegration{
+		AppID:    appID,
+		Provider: req.Provider,
+		Name:     strings.TrimSpace(req.Name),
+		Enabled:  req.Enabled == nil || *req.Enabled,
+		Config:   req.Config,
+		Services: req.Services,
+	}
+	if !h.checkIntegration(w, r, integration) {
+		return
+	}
+
+	if err := h.store.CDN().CreateIntegration(r.Context(), integration); err != nil {
+		h.logger.Error("failed to create CDN integration", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create CDN integration")
+		return
+	}
+
+	h.logger.Info("CDN integration created", "app_id", appID, "integration_id", integration.ID, "provider", integration.Provider)
+	WriteJSON(w, http.StatusCreated, h.purger.Redact(integration))
+}
+
+// UpdateIntegration handles PUT /v1/apps/{appID}/cdn/integrations/{integrationID} -
+// edits an integration. The provider cannot be changed.
+func (h *CDNHandler) UpdateIntegration(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.loadIntegration(w, r, chi.URLParam(r, "integrationID"))
+	if !ok {
+		return
+	}
+
+	var req CDNIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	integration := *existing
+	integration.Name = strings.TrimSpace(req.Name)
+	if req.Enabled != nil {
+		integration.Enabled = *req.Enabled
+	}
+	integration.Config = req.Config
+	integration.Services = req.Services
+	h.purger.MergeSecrets(&integration, existing)
+	if !h.checkIntegration(w, r, &integration) {
+		return
+	}
+
+	if err := h.store.CDN().UpdateIntegration(r.Context(), &integration); err != nil {
+		h.logger.Error("failed to update CDN integration", "error", err, "integration_id", integration.ID)
+		WriteInternalError(w, "Failed to update CDN integration")
+		return
+	}
+	WriteJSON(w, http.StatusOK, h.purger.Redact(&integration))
+}
+
+// DeleteIntegration handles DELETE /v1/apps/{appID}/cdn/integrations/{integrationID} -
+// removes an integration and its purge log.
+func (h *CDNHandler) DeleteIntegration(w http.ResponseWriter, r *http.Request) {
+	integration, ok := h.loadIntegration(w, r, chi.URLParam(r, "integrationID"))
+	if !ok {
+		return
+	}
+
+	if err := h.store.CDN().DeleteIntegration(r.Context(), integration.ID); err != nil {
+		h.logger.Error("failed to delete CDN integration", "error", err, "integration_id", integration.ID)
+		WriteInternalError(w, "Failed to delete CDN integration")
+		return
+	}
+
+	h.logger.Info("CDN integration deleted", "app_id", integration.AppID, "integration_id", integration.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Purge handles POST /v1/apps/{appID}/cdn/purge - purges one integration, or
+// every enabled integration of the app, and returns the logged results. It
+// responds 502 when no purge succeeded.
+func (h *CDNHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := appIDFromRequest(r)
+
+	var req CDNPurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	for _, u := range req.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			WriteBadRequest(w, "Invalid URL: "+u)
+			return
+		}
+	}
+
+	var integrations []*models.CDNIntegration
+	if req.IntegrationID != "" {
+		integration, ok := h.loadIntegration(w, r, req.IntegrationID)
+		if !ok {
+			return
+		}
+		integrations = []*models.CDNIntegration{integration}
+	} else {
+		all, err := h.store.CDN().ListIntegrations(ctx, appID)
+		if err != nil {
+			h.logger.Error("failed to list CDN integrations", "error", err, "app_id", appID)
+			WriteInternalError(w, "Failed to list CDN integrations")
+			return
+		}
+		for _, i := range all {
+			if i.Enabled {
+				integrations = append(integrations, i)
+			}
+		}
+		if len(integrations) == 0 {
+			WriteBadRequest(w, "No enabled CDN integrations configured for this app")
+			return
+		}
+	}
+
+	purges := make([]*models.CDNPurge, 0, len(integrations))
+	succeeded := false
+	for _, integration := range integrations {
+		purge := h.purger.Purge(ctx, integration, &models.CDNPurge{
+			Trigger:     models.CDNPurgeTriggerManual,
+			RequestedBy: middleware.GetUserID(ctx),
+			URLs:        req.URLs,
+		})
+		succeeded = succeeded || purge.Succeeded
+		purges = append(purges, purge)
+	}
+
+	if !succeeded {
+		WriteJSON(w, http.StatusBadGateway, purges)
+		return
+	}
+	WriteJSON(w, http.StatusOK, purges)
+}
+
+// ListPurges handles GET /v1/apps/{appID}/cdn/purges - lists the app's most
+// recent purges and their results.
+func (h *CDNHandler) ListPurges(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+
+	limit := defaultCDNPurges
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	purges, err := h.store.CDN().ListPurges(r.Context(), appID, limit)
+	if err != nil {
+		h.logger.Error("failed to list CDN purges", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list CDN purges")
+		return
+	}
+	if purges == nil {
+		purges = []*models.CDNPurge{}
+	}
+	WriteJSON(w, http.StatusOK, purges)
+}
+
+// checkIntegration validates an integration and checks that the services it
+// purges on are services of the app, writing an error response if not.
+func (h *CDNHandler) checkIntegration(w http.ResponseWriter, r *http.Request, integration *models.CDNIntegration) bool {
+	if err := h.purger.Validate(integration); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	if len(integration.Services) == 0 {
+		return true
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), integration.AppID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "Application not found")
+		return false
+	}
+	for _, name := range integration.Services {
+		if !hasService(app, name) {
+			WriteBadRequest(w, "Service "+name+" not found in this app")
+			return false
+		}
+	}
+	return true
+}
+
+// loadIntegration fetches an integration, writing an error response if it
+// cannot or the integration belongs to another app.
+func (h *CDNHandler) loadIntegration(w http.ResponseWriter, r *http.Request, integrationID string) (*models.CDNIntegration, bool) {
+	integration, err := h.store.CDN().GetIntegration(r.Context(), integrationID)
+	if err != nil {
+		h.logger.Error("failed to get CDN integration", "error", err, "integration_id", integrationID)
+		WriteInternalError(w, "Failed to load CDN integration")
+		return nil, false
+	}
+	if integration == nil || integration.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "CDN integration not found")
+		return nil, false
+	}
+	return integration, true
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/cdn"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockCDNStore implements store.CDNStore for testing.
+type mockCDNStore struct {
+	store.CDNStore
+	integrations map[string]*models.CDNIntegration
+	purges       []*models.CDNPurge
+}
+
+func (m *mockCDNStore) CreateIntegration(ctx context.Context, integration *models.CDNIntegration) error {
+	integration.ID = "cdn-" + integration.Name
+	stored := *integration
+	m.integrations[integration.ID] = &stored
+	return nil
+}
+
+func (m *mockCDNStore) GetIntegration(ctx context.Context, id string) (*models.CDNIntegration, error) {
+	if i, ok := m.integrations[id]; ok {
+		copied := *i
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockCDNStore) UpdateIntegration(ctx context.Context, integration *models.CDNIntegration) error {
+	stored := *integration
+	m.integrations[integration.ID] = &stored
+	return nil
+}
+
+func (m *mockCDNStore) ListIntegrations(ctx context.Context, appID string) ([]*models.CDNIntegration, error) {
+	var result []*models.CDNIntegration
+	for _, i := range m.integrations {
+		if i.AppID == appID {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCDNStore) RecordPurge(ctx context.Context, purge *models.CDNPurge) error {
+	m.purges = append(m.purges, purge)
+	return nil
+}
+
+// cdnMockStore adds CDN integrations to the deployment mock store.
+type cdnMockStore struct {
+	*deploymentMockStore
+	cdn *mockCDNStore
+}
+
+func (m *cdnMockStore) CDN() store.CDNStore {
+	return m.cdn
+}
+
+func TestCDNIntegrations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &cdnMockStore{deploymentMockStore: newDeploymentMockStore(), cdn: &mockCDNStore{integrations: map[string]*models.CDNIntegration{}}}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "shop",
+		Services: []models.ServiceConfig{{Name: "web"}}}
+	st.cdn.integrations["cdn-foreign"] = &models.CDNIntegration{ID: "cdn-foreign", AppID: "app-2", Name: "foreign",
+		Provider: models.CDNProviderFastly, Enabled: true}
+	h := NewCDNHandler(st, cdn.NewPurger(st, nil, logger), logger)
+
+	// Credentials are required and never returned
+	rr := httptest.NewRecorder()
+	h.CreateIntegration(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/integrations", CDNIntegrationRequest{
+		Provider: models.CDNProviderCloudflare, Name: "cf", Config: map[string]string{"zone_id": "z1"},
+	}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("missing api_token: status = %d, want 400", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.CreateIntegration(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/integrations", CDNIntegrationRequest{
+		Provider: models.CDNProviderCloudflare, Name: "cf", Services: []string{"web"},
+		Config: map[string]string{"zone_id": "z1", "api_token": "secret"},
+	}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.CDNIntegration
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.Config["api_token"] != "" || !created.Enabled || created.AppID != "app-1" {
+		t.Errorf("create response = %+v", created)
+	}
+
+	// Deploy services must belong to the app
+	rr = httptest.NewRecorder()
+	h.CreateIntegration(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/integrations", CDNIntegrationRequest{
+		Provider: models.CDNProviderBunny, Name: "bunny", Services: []string{"worker"},
+		Config: map[string]string{"pull_zone_id": "1", "api_key": "k"},
+	}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown service: status = %d, want 400", rr.Code)
+	}
+
+	// Updating without the token keeps the stored one
+	rr = httptest.NewRecorder()
+	h.UpdateIntegration(rr, templateRequest(http.MethodPut, "/v1/apps/app-1/cdn/integrations/cdn-cf", CDNIntegrationRequest{
+		Name: "cf", Config: map[string]string{"zone_id": "z2"},
+	}, map[string]string{"integrationID": "cdn-cf"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if stored := st.cdn.integrations["cdn-cf"]; stored.Config["api_token"] != "secret" || stored.Config["zone_id"] != "z2" || len(stored.Services) != 0 {
+		t.Errorf("stored after update = %+v", stored)
+	}
+
+	// Integrations of other apps cannot be purged
+	rr = httptest.NewRecorder()
+	h.Purge(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/purge",
+		CDNPurgeRequest{IntegrationID: "cdn-foreign"}, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("foreign integration: status = %d, want 404", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Purge(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/purge",
+		CDNPurgeRequest{URLs: []string{"not a url"}}, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid url: status = %d, want 400", rr.Code)
+	}
+}
+
+func TestCDNPurgeLogsResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &cdnMockStore{deploymentMockStore: newDeploymentMockStore(), cdn: &mockCDNStore{integrations: map[string]*models.CDNIntegration{
+		// Fastly purges single URLs by sending PURGE to them, so the test server answers
+		"cdn-fastly": {ID: "cdn-fastly", AppID: "app-1", Name: "fastly", Provider: models.CDNProviderFastly, Enabled: true,
+			Config: map[string]string{"service_id": "s1", "api_token": "k"}},
+	}}}
+	h := NewCDNHandler(st, cdn.NewPurger(st, srv.Client(), logger), logger)
+
+	rr := httptest.NewRecorder()
+	h.Purge(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/cdn/purge",
+		CDNPurgeRequest{URLs: []string{srv.URL + "/index.html"}}, nil))
+	if rr.Code != http.StatusBadGateway {
+		t.Fatalf("failed purge: status = %d, want 502: %s", rr.Code, rr.Body.String())
+	}
+	if len(st.cdn.purges) != 1 {
+		t.Fatalf("logged purges = %d, want 1", len(st.cdn.purges))
+	}
+	purge := st.cdn.purges[0]
+	if purge.Succeeded || purge.Error == "" || purge.Trigger != models.CDNPurgeTriggerManual || purge.RequestedBy != "user-1" {
+		t.Errorf("logged purge = %+v", purge)
+	}
+}
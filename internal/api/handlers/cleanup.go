@@ -2,11 +2,16 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 
-	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
 	"github.com/narvanalabs/control-plane/internal/cleanup"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/operations"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
@@ -15,267 +20,141 @@ import (
 type CleanupHandler struct {
 	store          store.Store
 	cleanupService *cleanup.Service
+	operations     *operations.Manager
 	logger         *slog.Logger
 }
 
-// NewCleanupHandler creates a new cleanup handler.
-func NewCleanupHandler(st store.Store, cleanupSvc *cleanup.Service, logger *slog.Logger) *CleanupHandler {
+// NewCleanupHandler creates a new cleanup handler. Cleanups run as
+// operations of ops.
+func NewCleanupHandler(st store.Store, cleanupSvc *cleanup.Service, ops *operations.Manager, logger *slog.Logger) *CleanupHandler {
 	return &CleanupHandler{
 		store:          st,
 		cleanupService: cleanupSvc,
+		operations:     ops,
 		logger:         logger,
 	}
 }
 
-// CleanupJobResponse represents the response for a cleanup job trigger.
-// Requirements: 19.4
-type CleanupJobResponse struct {
-	JobID   string `json:"job_id"`
-	Status  string `json:"status"`
-	Message string `json:"message,omitempty"`
-}
-
-// CleanupContainersResponse represents the response for container cleanup.
-type CleanupContainersResponse struct {
-	JobID        string   `json:"job_id"`
-	Status       string   `json:"status"`
-	ItemsRemoved int      `json:"items_removed"`
-	Errors       []string `json:"errors,omitempty"`
-	Duration     string   `json:"duration"`
-}
-
-// CleanupImagesResponse represents the response for image cleanup.
-type CleanupImagesResponse struct {
-	JobID        string   `json:"job_id"`
-	Status       string   `json:"status"`
-	ItemsRemoved int      `json:"items_removed"`
-	Errors       []string `json:"errors,omitempty"`
-	Duration     string   `json:"duration"`
-}
-
-// NixGCResponse represents the response for Nix garbage collection.
-type NixGCResponse struct {
-	JobID        string `json:"job_id"`
-	Status       string `json:"status"`
-	SpaceFreed   int64  `json:"space_freed_bytes"`
-	PathsRemoved int    `json:"paths_removed"`
-	Duration     string `json:"duration"`
-	Error        string `json:"error,omitempty"`
-}
-
-// ArchiveDeploymentsResponse represents the response for deployment archival.
-type ArchiveDeploymentsResponse struct {
-	JobID               string   `json:"job_id"`
-	Status              string   `json:"status"`
-	DeploymentsArchived int      `json:"deployments_archived"`
-	BuildsArchived      int      `json:"builds_archived"`
-	LogsArchived        int      `json:"logs_archived"`
-	Errors              []string `json:"errors,omitempty"`
-	Duration            string   `json:"duration"`
-}
-
-// CleanupAtticResponse represents the response for Attic cache cleanup.
-type CleanupAtticResponse struct {
-	JobID        string   `json:"job_id"`
-	Status       string   `json:"status"`
-	ItemsRemoved int      `json:"items_removed"`
-	SpaceFreed   int64    `json:"space_freed_bytes"`
-	Errors       []string `json:"errors,omitempty"`
-	Duration     string   `json:"duration"`
-}
-
-// CleanupContainers handles POST /v1/admin/cleanup/containers - triggers immediate container cleanup.
+// CleanupContainers handles POST /v1/admin/cleanup/containers - starts a
+// cleanup of stopped containers and returns its operation.
 // Requirements: 19.1, 19.4
 func (h *CleanupHandler) CleanupContainers(w http.ResponseWriter, r *http.Request) {
-	jobID := uuid.New().String()
-	h.logger.Info("triggering container cleanup", "job_id", jobID)
-
-	result, err := h.cleanupService.CleanupContainers(r.Context())
-	if err != nil {
-		h.logger.Error("container cleanup failed", "error", err, "job_id", jobID)
-		WriteInternalError(w, "Failed to cleanup containers: "+err.Error())
-		return
-	}
-
-	response := &CleanupContainersResponse{
-		JobID:        jobID,
-		Status:       "completed",
-		ItemsRemoved: result.ItemsRemoved,
-		Errors:       result.Errors,
-		Duration:     result.Duration.String(),
-	}
-
-	h.logger.Info("container cleanup completed",
-		"job_id", jobID,
-		"items_removed", result.ItemsRemoved,
-		"errors", len(result.Errors),
-		"duration", result.Duration,
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+	h.start(w, r, models.OperationCleanupContainers, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Removing stopped containers")
+		result, err := h.cleanupService.CleanupContainers(ctx)
+		if err != nil {
+			return fmt.Errorf("cleaning up containers: %w", err)
+		}
+		recordCleanupResult(ctx, p, result)
+		return nil
+	})
 }
 
-// CleanupImages handles POST /v1/admin/cleanup/images - triggers immediate image cleanup.
+// CleanupImages handles POST /v1/admin/cleanup/images - starts a cleanup of
+// unused images and returns its operation.
 // Requirements: 25.4, 19.4
 func (h *CleanupHandler) CleanupImages(w http.ResponseWriter, r *http.Request) {
-	jobID := uuid.New().String()
-	h.logger.Info("triggering image cleanup", "job_id", jobID)
-
-	result, err := h.cleanupService.CleanupImages(r.Context())
-	if err != nil {
-		h.logger.Error("image cleanup failed", "error", err, "job_id", jobID)
-		WriteInternalError(w, "Failed to cleanup images: "+err.Error())
-		return
-	}
-
-	response := &CleanupImagesResponse{
-		JobID:        jobID,
-		Status:       "completed",
-		ItemsRemoved: result.ItemsRemoved,
-		Errors:       result.Errors,
-		Duration:     result.Duration.String(),
-	}
-
-	h.logger.Info("image cleanup completed",
-		"job_id", jobID,
-		"items_removed", result.ItemsRemoved,
-		"errors", len(result.Errors),
-		"duration", result.Duration,
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+	h.start(w, r, models.OperationCleanupImages, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Removing unused images")
+		result, err := h.cleanupService.CleanupImages(ctx)
+		if err != nil {
+			return fmt.Errorf("cleaning up images: %w", err)
+		}
+		recordCleanupResult(ctx, p, result)
+		return nil
+	})
 }
 
-// NixGC handles POST /v1/admin/cleanup/nix-gc - triggers immediate Nix garbage collection.
+// NixGC handles POST /v1/admin/cleanup/nix-gc - starts Nix garbage
+// collection on every node, one node at a time, and returns its operation.
+// A node that fails is recorded and the others are still collected.
 // Requirements: 19.2, 19.4
 func (h *CleanupHandler) NixGC(w http.ResponseWriter, r *http.Request) {
-	jobID := uuid.New().String()
-	h.logger.Info("triggering Nix garbage collection", "job_id", jobID)
-
-	// Get all nodes and trigger GC on each
-	nodes, err := h.store.Nodes().List(r.Context())
-	if err != nil {
-		h.logger.Error("failed to list nodes for Nix GC", "error", err, "job_id", jobID)
-		WriteInternalError(w, "Failed to list nodes: "+err.Error())
-		return
-	}
-
-	// Check if there are any nodes registered
-	if len(nodes) == 0 {
-		h.logger.Warn("no nodes registered for Nix GC", "job_id", jobID)
-		response := &NixGCResponse{
-			JobID:        jobID,
-			Status:       "completed",
-			SpaceFreed:   0,
-			PathsRemoved: 0,
-			Error:        "",
-			Duration:     "0s",
-		}
-		WriteJSON(w, http.StatusOK, response)
-		return
-	}
-
-	var totalSpaceFreed int64
-	var totalPathsRemoved int
-	var lastError string
-
-	for _, node := range nodes {
-		result, err := h.cleanupService.TriggerNixGC(r.Context(), node.ID)
+	h.start(w, r, models.OperationNixGC, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Listing nodes")
+		nodes, err := h.store.Nodes().List(ctx)
 		if err != nil {
-			h.logger.Error("Nix GC failed on node", "error", err, "node_id", node.ID, "job_id", jobID)
-			lastError = err.Error()
-			continue
+			return fmt.Errorf("listing nodes: %w", err)
 		}
-		totalSpaceFreed += result.SpaceFreed
-		totalPathsRemoved += result.PathsRemoved
-		if result.Error != "" {
-			lastError = result.Error
-		}
-	}
-
-	response := &NixGCResponse{
-		JobID:        jobID,
-		Status:       "completed",
-		SpaceFreed:   totalSpaceFreed,
-		PathsRemoved: totalPathsRemoved,
-		Error:        lastError,
-		Duration:     "0s", // Aggregated duration not tracked
-	}
 
-	h.logger.Info("Nix garbage collection completed",
-		"job_id", jobID,
-		"space_freed", totalSpaceFreed,
-		"paths_removed", totalPathsRemoved,
-		"nodes_processed", len(nodes),
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+		var spaceFreed int64
+		var pathsRemoved int
+		for i, node := range nodes {
+			p.Set(ctx, i*100/len(nodes), fmt.Sprintf("Collecting garbage on %s (%d of %d)", node.Hostname, i+1, len(nodes)))
+			result, err := h.cleanupService.TriggerNixGC(ctx, node.ID)
+			if err != nil {
+				p.AddError(ctx, fmt.Errorf("%s: %w", node.Hostname, err))
+				continue
+			}
+			spaceFreed += result.SpaceFreed
+			pathsRemoved += result.PathsRemoved
+			if result.Error != "" {
+				p.AddError(ctx, fmt.Errorf("%s: %s", node.Hostname, result.Error))
+			}
+		}
+		p.SetResult("nodes", len(nodes))
+		p.SetResult("space_freed_bytes", spaceFreed)
+		p.SetResult("paths_removed", pathsRemoved)
+		p.Set(ctx, 100, "Done")
+		return nil
+	})
 }
 
-// ArchiveDeployments handles POST /v1/admin/cleanup/deployments - triggers immediate deployment archival.
+// ArchiveDeployments handles POST /v1/admin/cleanup/deployments - starts
+// archival of old deployments and returns its operation.
 // Requirements: 19.3, 19.4
 func (h *CleanupHandler) ArchiveDeployments(w http.ResponseWriter, r *http.Request) {
-	jobID := uuid.New().String()
-	h.logger.Info("triggering deployment archival", "job_id", jobID)
-
-	result, err := h.cleanupService.ArchiveDeployments(r.Context())
-	if err != nil {
-		h.logger.Error("deployment archival failed", "error", err, "job_id", jobID)
-		WriteInternalError(w, "Failed to archive deployments: "+err.Error())
-		return
-	}
-
-	response := &ArchiveDeploymentsResponse{
-		JobID:               jobID,
-		Status:              "completed",
-		DeploymentsArchived: result.DeploymentsArchived,
-		BuildsArchived:      result.BuildsArchived,
-		LogsArchived:        result.LogsArchived,
-		Errors:              result.Errors,
-		Duration:            result.Duration.String(),
-	}
-
-	h.logger.Info("deployment archival completed",
-		"job_id", jobID,
-		"deployments_archived", result.DeploymentsArchived,
-		"builds_archived", result.BuildsArchived,
-		"logs_archived", result.LogsArchived,
-		"errors", len(result.Errors),
-		"duration", result.Duration,
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+	h.start(w, r, models.OperationArchiveDeployments, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Archiving old deployments")
+		result, err := h.cleanupService.ArchiveDeployments(ctx)
+		if err != nil {
+			return fmt.Errorf("archiving deployments: %w", err)
+		}
+		for _, msg := range result.Errors {
+			p.AddError(ctx, errors.New(msg))
+		}
+		p.SetResult("deployments_archived", result.DeploymentsArchived)
+		p.SetResult("builds_archived", result.BuildsArchived)
+		p.SetResult("logs_archived", result.LogsArchived)
+		p.SetResult("duration", result.Duration.String())
+		p.Set(ctx, 100, "Done")
+		return nil
+	})
 }
 
-// CleanupAttic handles POST /v1/admin/cleanup/attic - triggers immediate Attic cache cleanup.
+// CleanupAttic handles POST /v1/admin/cleanup/attic - starts a cleanup of
+// the Attic binary cache and returns its operation.
 // Requirements: 26.4, 19.4
 func (h *CleanupHandler) CleanupAttic(w http.ResponseWriter, r *http.Request) {
-	jobID := uuid.New().String()
-	h.logger.Info("triggering Attic cache cleanup", "job_id", jobID)
+	h.start(w, r, models.OperationCleanupAttic, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Removing unused cache entries")
+		result, err := h.cleanupService.CleanupAttic(ctx)
+		if err != nil {
+			return fmt.Errorf("cleaning up Attic cache: %w", err)
+		}
+		recordCleanupResult(ctx, p, result)
+		return nil
+	})
+}
 
-	result, err := h.cleanupService.CleanupAttic(r.Context())
-	if err != nil {
-		h.logger.Error("Attic cache cleanup failed", "error", err, "job_id", jobID)
-		WriteInternalError(w, "Failed to cleanup Attic cache: "+err.Error())
+// start starts a cleanup operation and responds with it.
+func (h *CleanupHandler) start(w http.ResponseWriter, r *http.Request, kind models.OperationKind, fn operations.Func) {
+	op := &models.Operation{Kind: kind, CreatedBy: middleware.GetUserID(r.Context())}
+	if err := h.operations.Start(r.Context(), op, fn); err != nil {
+		h.logger.Error("failed to start cleanup", "error", err, "kind", kind)
+		WriteInternalError(w, "Failed to start cleanup")
 		return
 	}
+	WriteOperation(w, op)
+}
 
-	response := &CleanupAtticResponse{
-		JobID:        jobID,
-		Status:       "completed",
-		ItemsRemoved: result.ItemsRemoved,
-		SpaceFreed:   result.SpaceFreed,
-		Errors:       result.Errors,
-		Duration:     result.Duration.String(),
+// recordCleanupResult records the totals and errors of a cleanup.
+func recordCleanupResult(ctx context.Context, p *operations.Progress, result *cleanup.CleanupResult) {
+	for _, msg := range result.Errors {
+		p.AddError(ctx, errors.New(msg))
 	}
-
-	h.logger.Info("Attic cache cleanup completed",
-		"job_id", jobID,
-		"items_removed", result.ItemsRemoved,
-		"space_freed", result.SpaceFreed,
-		"errors", len(result.Errors),
-		"duration", result.Duration,
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+	p.SetResult("items_removed", result.ItemsRemoved)
+	p.SetResult("space_freed_bytes", result.SpaceFreed)
+	p.SetResult("duration", result.Duration.String())
+	p.Set(ctx, 100, "Done")
 }
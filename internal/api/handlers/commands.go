@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Command sections group the command palette's entries.
+const (
+	CommandSectionNavigation = "Navigation"
+	CommandSectionApp        = "App"
+	CommandSectionService    = "Service"
+	CommandSectionAdmin      = "Administration"
+)
+
+// Command is an action the web UI's command palette offers. Path is a web UI
+// path: commands with method GET navigate to it, POST commands submit to it.
+type Command struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Section  string `json:"section"`
+	Shortcut string `json:"shortcut,omitempty"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	// Confirm is the question to ask before running a destructive command
+	Confirm string `json:"confirm,omitempty"`
+}
+
+// commandScope is what a command acts on.
+type commandScope int
+
+const (
+	scopeGlobal commandScope = iota
+	scopeApp
+	scopeService
+)
+
+// commandDef registers a command. Titles and paths may use {app} and
+// {service}. The command is offered when the user has its permission and
+// when, if set, reports true.
+type commandDef struct {
+	Command
+	scope      commandScope
+	permission auth.Permission
+	when       func(c *commandContext) bool
+}
+
+// commandRegistry lists every command, in the order they are offered.
+var commandRegistry = []commandDef{
+	{Command: Command{ID: "nav.dashboard", Title: "Go to Dashboard", Section: CommandSectionNavigation, Shortcut: "g d", Method: http.MethodGet, Path: "/"}},
+	{Command: Command{ID: "nav.apps", Title: "Go to Apps", Section: CommandSectionNavigation, Shortcut: "g a", Method: http.MethodGet, Path: "/apps"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.deployments", Title: "Go to Deployments", Section: CommandSectionNavigation, Shortcut: "g p", Method: http.MethodGet, Path: "/deployments"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.builds", Title: "Go to Builds", Section: CommandSectionNavigation, Shortcut: "g b", Method: http.MethodGet, Path: "/builds"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.catalog", Title: "Go to API Catalog", Section: CommandSectionNavigation, Shortcut: "g c", Method: http.MethodGet, Path: "/catalog"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.domains", Title: "Go to Domains", Section: CommandSectionNavigation, Method: http.MethodGet, Path: "/domains"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.nodes", Title: "Go to Nodes", Section: CommandSectionNavigation, Shortcut: "g n", Method: http.MethodGet, Path: "/nodes"}, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "nav.settings", Title: "Go to Settings", Section: CommandSectionNavigation, Shortcut: "g s", Method: http.MethodGet, Path: "/settings"}},
+	{Command: Command{ID: "nav.profile", Title: "Edit Profile", Section: CommandSectionNavigation, Method: http.MethodGet, Path: "/settings/profile"}},
+	{Command: Command{ID: "nav.ssh_keys", Title: "Manage SSH Keys", Section: CommandSectionNavigation, Method: http.MethodGet, Path: "/settings/ssh-keys"}},
+	{Command: Command{ID: "nav.sessions", Title: "Manage Sessions", Section: CommandSectionNavigation, Method: http.MethodGet, Path: "/settings/sessions"}},
+
+	{Command: Command{ID: "admin.users", Title: "Manage Users", Section: CommandSectionAdmin, Method: http.MethodGet, Path: "/settings/users"}, permission: auth.PermissionViewUsers},
+	{Command: Command{ID: "admin.server", Title: "Server Settings", Section: CommandSectionAdmin, Method: http.MethodGet, Path: "/settings/server"}, permission: auth.PermissionManageSettings},
+	{Command: Command{ID: "admin.console", Title: "Open Admin Console", Section: CommandSectionAdmin, Method: http.MethodGet, Path: "/admin"}, permission: auth.PermissionAdminConsole},
+
+	{Command: Command{ID: "app.open", Title: "Open {app}", Section: CommandSectionApp, Method: http.MethodGet, Path: "/apps/{app}"}, scope: scopeApp, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "app.delete", Title: "Delete {app}", Section: CommandSectionApp, Method: http.MethodPost, Path: "/apps/{app}/delete", Confirm: "Delete the app and all its services?"}, scope: scopeApp, permission: auth.PermissionManageApps},
+
+	{Command: Command{ID: "service.open", Title: "Open {service}", Section: CommandSectionService, Method: http.MethodGet, Path: "/apps/{app}/services/{service}"}, scope: scopeService, permission: auth.PermissionViewApps},
+	{Command: Command{ID: "service.deploy", Title: "Deploy {service}", Section: CommandSectionService, Method: http.MethodPost, Path: "/apps/{app}/services/{service}/deploy"}, scope: scopeService, permission: auth.PermissionDeploy},
+	{Command: Command{ID: "service.reload", Title: "Restart {service}", Section: CommandSectionService, Method: http.MethodPost, Path: "/apps/{app}/services/{service}/reload"}, scope: scopeService, permission: auth.PermissionDeploy,
+		when: func(c *commandContext) bool { return c.running }},
+	{Command: Command{ID: "service.stop", Title: "Stop {service}", Section: CommandSectionService, Method: http.MethodPost, Path: "/apps/{app}/services/{service}/stop", Confirm: "Stop the service?"}, scope: scopeService, permission: auth.PermissionDeploy,
+		when: func(c *commandContext) bool { return c.running }},
+	{Command: Command{ID: "service.start", Title: "Start {service}", Section: CommandSectionService, Method: http.MethodPost, Path: "/apps/{app}/services/{service}/start"}, scope: scopeService, permission: auth.PermissionDeploy,
+		when: func(c *commandContext) bool { return c.stopped }},
+	{Command: Command{ID: "service.delete", Title: "Delete {service}", Section: CommandSectionService, Method: http.MethodPost, Path: "/apps/{app}/services/{service}/delete", Confirm: "Delete the service?"}, scope: scopeService, permission: auth.PermissionManageApps},
+}
+
+// commandContext is what commands are resolved against: the user's
+// permissions and the app and service of the page they are on.
+type commandContext struct {
+	can     func(auth.Permission) bool
+	canApp  func(auth.Permission) bool
+	app     *models.App
+	service *models.ServiceConfig
+	running bool
+	stopped bool
+}
+
+// availableCommands returns the commands of the registry c allows.
+func availableCommands(c *commandContext) []Command {
+	commands := []Command{}
+	for _, def := range commandRegistry {
+		switch def.scope {
+		case scopeApp:
+			if c.app == nil {
+				continue
+			}
+		case scopeService:
+			if c.service == nil {
+				continue
+			}
+		}
+		can := c.can
+		if def.scope != scopeGlobal {
+			can = c.canApp
+		}
+		if def.permission != "" && !can(def.permission) {
+			continue
+		}
+		if def.when != nil && !def.when(c) {
+			continue
+		}
+
+		cmd := def.Command
+		if c.app != nil {
+			cmd.Title = strings.ReplaceAll(cmd.Title, "{app}", c.app.Name)
+			cmd.Path = strings.ReplaceAll(cmd.Path, "{app}", c.app.ID)
+		}
+		if c.service != nil {
+			cmd.Title = strings.ReplaceAll(cmd.Title, "{service}", c.service.Name)
+			cmd.Path = strings.ReplaceAll(cmd.Path, "{service}", c.service.Name)
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+// CommandHandler serves the command palette's registry.
+type CommandHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewCommandHandler creates a new command handler.
+func NewCommandHandler(st store.Store, logger *slog.Logger) *CommandHandler {
+	return &CommandHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// List handles GET /v1/commands - lists the commands the user can run,
+// including those acting on the app and service given by the app_id and
+// service query parameters. Authorization stays on the server: clients
+// show the commands returned and nothing else.
+func (h *CommandHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	user, err := h.store.Users().GetByID(ctx, userID)
+	if err != nil || user == nil {
+		h.logger.Error("failed to get user", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to list commands")
+		return
+	}
+	orgRole := middleware.GetOrgRole(ctx)
+	c := &commandContext{
+		can: func(p auth.Permission) bool {
+			if orgRole != "" && auth.CheckOrgRolePermission(orgRole, p) == nil {
+				return true
+			}
+			return auth.CheckRolePermission(user.Role, p) == nil
+		},
+	}
+
+	appID := r.URL.Query().Get("app_id")
+	if appID == "" {
+		WriteJSON(w, http.StatusOK, availableCommands(c))
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, appID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	// The app's owner may do anything with it; org members what their
+	// role in the app's org allows
+	if app.OwnerID == userID {
+		c.canApp = func(auth.Permission) bool { return true }
+	} else {
+		var role models.Role
+		if app.OrgID != "" {
+			if role, err = h.store.Orgs().GetMemberRole(ctx, app.OrgID, userID); err != nil {
+				h.logger.Error("failed to check org membership", "error", err, "org_id", app.OrgID, "user_id", userID)
+				WriteInternalError(w, "Failed to verify access")
+				return
+			}
+		}
+		if role == "" {
+			WriteNotFound(w, "Application not found")
+			return
+		}
+		c.canApp = func(p auth.Permission) bool { return auth.CheckOrgRolePermission(role, p) == nil }
+	}
+	c.app = app
+
+	if name := r.URL.Query().Get("service"); name != "" {
+		for i := range app.Services {
+			if app.Services[i].Name == name {
+				c.service = &app.Services[i]
+			}
+		}
+		if c.service == nil {
+			WriteError(w, http.StatusNotFound, "SERVICE_NOT_FOUND", "Service not found")
+			return
+		}
+
+		deployments, err := h.store.Deployments().List(ctx, app.ID)
+		if err != nil {
+			h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+			WriteInternalError(w, "Failed to list commands")
+			return
+		}
+		var latest *models.Deployment
+		for _, d := range deployments {
+			if d.ServiceName == name && (latest == nil || d.Version > latest.Version) {
+				latest = d
+			}
+		}
+		c.running = runningDeployment(deployments, name) != nil
+		c.stopped = latest != nil && latest.Status == models.DeploymentStatusStopped
+	}
+
+	WriteJSON(w, http.StatusOK, availableCommands(c))
+}
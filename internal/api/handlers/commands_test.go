@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+func TestAvailableCommands(t *testing.T) {
+	app := &models.App{ID: "app-1", Name: "shop", Services: []models.ServiceConfig{{Name: "web"}}}
+	instanceRole := func(role store.Role) func(auth.Permission) bool {
+		return func(p auth.Permission) bool { return auth.CheckRolePermission(role, p) == nil }
+	}
+	orgRole := func(role models.Role) func(auth.Permission) bool {
+		return func(p auth.Permission) bool { return auth.CheckOrgRolePermission(role, p) == nil }
+	}
+
+	tests := []struct {
+		name    string
+		ctx     *commandContext
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "member outside an app",
+			ctx:     &commandContext{can: instanceRole(store.RoleMember)},
+			want:    []string{"nav.dashboard", "nav.apps", "nav.sessions"},
+			notWant: []string{"admin.console", "admin.users", "app.open", "service.deploy"},
+		},
+		{
+			name:    "owner outside an app",
+			ctx:     &commandContext{can: instanceRole(store.RoleOwner)},
+			want:    []string{"admin.console", "admin.users", "admin.server"},
+			notWant: []string{"app.open"},
+		},
+		{
+			name:    "developer on a running service",
+			ctx:     &commandContext{can: instanceRole(store.RoleMember), canApp: orgRole(models.RoleDeveloper), app: app, service: &app.Services[0], running: true},
+			want:    []string{"app.open", "app.delete", "service.open", "service.deploy", "service.reload", "service.stop", "service.delete"},
+			notWant: []string{"service.start"},
+		},
+		{
+			name:    "developer on a stopped service",
+			ctx:     &commandContext{can: instanceRole(store.RoleMember), canApp: orgRole(models.RoleDeveloper), app: app, service: &app.Services[0], stopped: true},
+			want:    []string{"service.start", "service.deploy"},
+			notWant: []string{"service.stop", "service.reload"},
+		},
+		{
+			name:    "viewer on a service",
+			ctx:     &commandContext{can: instanceRole(store.RoleMember), canApp: orgRole(models.RoleViewer), app: app, service: &app.Services[0], running: true},
+			want:    []string{"app.open", "service.open"},
+			notWant: []string{"app.delete", "service.deploy", "service.stop", "service.delete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]Command{}
+			for _, c := range availableCommands(tt.ctx) {
+				got[c.ID] = c
+			}
+			for _, id := range tt.want {
+				if _, ok := got[id]; !ok {
+					t.Errorf("missing command %s", id)
+				}
+			}
+			for _, id := range tt.notWant {
+				if _, ok := got[id]; ok {
+					t.Errorf("unexpected command %s", id)
+				}
+			}
+		})
+	}
+
+	commands := availableCommands(&commandContext{can: instanceRole(store.RoleOwner), canApp: orgRole(models.RoleOwner), app: app, service: &app.Services[0], running: true})
+	for _, c := range commands {
+		if c.ID == "service.deploy" {
+			if c.Title != "Deploy web" || c.Path != "/apps/app-1/services/web/deploy" || c.Method != "POST" {
+				t.Errorf("service.deploy = %+v", c)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/integrations/github"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// CommitRangeHandler resolves the commits shipped between two deployments of a service.
+type CommitRangeHandler struct {
+	store        store.Store
+	githubClient *github.Client
+	logger       *slog.Logger
+}
+
+// NewCommitRangeHandler creates a new commit range handler.
+func NewCommitRangeHandler(st store.Store, logger *slog.Logger) *CommitRangeHandler {
+	return &CommitRangeHandler{
+		store:        st,
+		githubClient: github.NewClient(),
+		logger:       logger,
+	}
+}
+
+// CommitRangeResponse is the commit range between two deployments.
+type CommitRangeResponse struct {
+	ServiceName      string          `json:"service_name"`
+	Repo             string          `json:"repo"`
+	FromDeploymentID string          `json:"from_deployment_id"`
+	ToDeploymentID   string          `json:"to_deployment_id"`
+	FromCommit       string          `json:"from_commit"`
+	ToCommit         string          `json:"to_commit"`
+	TotalCommits     int             `json:"total_commits"`
+	Commits          []github.Commit `json:"commits"`
+	Changes          ReleaseChanges  `json:"changes"`
+	CompareURL       string          `json:"compare_url,omitempty"`
+	Summary          string          `json:"summary"` // e.g. "12 commits deployed"
+}
+
+// getDeployment loads a deployment of the app, writing a 404 if the app has no
+// such deployment and a 500 if the store fails.
+func (h *CommitRangeHandler) getDeployment(w http.ResponseWriter, r *http.Request, appID, deploymentID string) (*models.Deployment, bool) {
+	deployment, err := h.store.Deployments().Get(r.Context(), deploymentID)
+	if err != nil && err.Error() != "resource not found" {
+		h.logger.Error("failed to get deployment", "error", err, "deployment_id", deploymentID)
+		WriteInternalError(w, "Failed to compare deployments")
+		return nil, false
+	}
+	if err != nil || deployment == nil || deployment.AppID != appID {
+		WriteNotFound(w, "Deployment not found")
+		return nil, false
+	}
+	return deployment, true
+}
+
+// Compare handles GET /v1/apps/{appID}/deployments/compare?from={id}&to={id}.
+// When from is omitted, the previous deployment of the same service is used.
+func (h *CommitRangeHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := middleware.GetResolvedAppID(ctx)
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+
+	toID := r.URL.Query().Get("to")
+	if toID == "" {
+		WriteBadRequest(w, "to is required")
+		return
+	}
+
+	to, ok := h.getDeployment(w, r, appID, toID)
+	if !ok {
+		return
+	}
+
+	var from *models.Deployment
+	if fromID := r.URL.Query().Get("from"); fromID != "" {
+		from, ok = h.getDeployment(w, r, appID, fromID)
+		if !ok {
+			return
+		}
+		if from.ServiceName != to.ServiceName {
+			WriteBadRequest(w, "Deployments belong to different services")
+			return
+		}
+	} else {
+		deployments, err := h.store.Deployments().List(ctx, appID)
+		if err != nil {
+			h.logger.Error("failed to list deployments", "error", err, "app_id", appID)
+			WriteInternalError(w, "Failed to compare deployments")
+			return
+		}
+		from = findPreviousDeployment(deployments, to)
+		if from == nil {
+			WriteError(w, http.StatusUnprocessableEntity, ErrCodeInvalidRequest, "No previous deployment to compare against")
+			return
+		}
+	}
+
+	if from.GitCommit == "" || to.GitCommit == "" {
+		WriteError(w, http.StatusUnprocessableEntity, ErrCodeInvalidRequest, "Both deployments must have a resolved git commit")
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, appID)
+	if err != nil {
+		h.logger.Error("failed to get app", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to compare deployments")
+		return
+	}
+
+	var gitRepo string
+	for _, svc := range app.Services {
+		if svc.Name == to.ServiceName {
+			gitRepo = svc.GitRepo
+			break
+		}
+	}
+	owner, repo, ok := github.ParseRepo(gitRepo)
+	if !ok {
+		WriteError(w, http.StatusUnprocessableEntity, ErrCodeInvalidRequest, "Service is not backed by a GitHub repository")
+		return
+	}
+
+	token := h.resolveToken(ctx, app.OwnerID, owner)
+	comparison, err := h.githubClient.CompareCommits(ctx, token, owner, repo, from.GitCommit, to.GitCommit)
+	if err != nil {
+		h.logger.Error("failed to compare commits", "error", err, "repo", owner+"/"+repo,
+			"from", from.GitCommit, "to", to.GitCommit)
+		WriteError(w, http.StatusBadGateway, ErrCodeInternalError, "Failed to fetch commits from GitHub")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, newCommitRangeResponse(from, to, owner+"/"+repo, comparison))
+}
+
+// resolveToken picks a GitHub token for the repository owner: an App
+// installation on the owner's account if one exists, otherwise the user's
+// OAuth token. It returns an empty string when neither is available.
+func (h *CommitRangeHandler) resolveToken(ctx context.Context, userID, repoOwner string) string {
+	installations, err := h.store.GitHub().ListInstallations(ctx, userID)
+	if err == nil && len(installations) > 0 {
+		config, err := h.store.GitHub().GetConfig(ctx)
+		if err == nil && config != nil && config.ConfigType == "app" && config.AppID != nil && config.PrivateKey != nil {
+			for _, inst := range installations {
+				if !strings.EqualFold(inst.AccountLogin, repoOwner) {
+					continue
+				}
+				token, err := h.githubClient.GenerateInstallationToken(ctx, *config.AppID, *config.PrivateKey, inst.ID)
+				if err != nil {
+					h.logger.Error("failed to generate installation token", "installation_id", inst.ID, "error", err)
+					break
+				}
+				return token
+			}
+		}
+	}
+
+	account, err := h.store.GitHubAccounts().GetByUserID(ctx, userID)
+	if err == nil && account != nil {
+		return account.AccessToken
+	}
+	return ""
+}
+
+// newCommitRangeResponse categorizes the compared commits for display.
+func newCommitRangeResponse(from, to *models.Deployment, repo string, comparison *github.Comparison) CommitRangeResponse {
+	messages := make([]string, 0, len(comparison.Commits))
+	for _, c := range comparison.Commits {
+		// Only the subject line feeds the release notes engine
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		messages = append(messages, subject)
+	}
+
+	return CommitRangeResponse{
+		ServiceName:      to.ServiceName,
+		Repo:             repo,
+		FromDeploymentID: from.ID,
+		ToDeploymentID:   to.ID,
+		FromCommit:       from.GitCommit,
+		ToCommit:         to.GitCommit,
+		TotalCommits:     comparison.TotalCommits,
+		Commits:          comparison.Commits,
+		Changes:          categorizeChanges(messages),
+		CompareURL:       comparison.HTMLURL,
+		Summary:          commitRangeSummary(comparison.TotalCommits),
+	}
+}
+
+// commitRangeSummary renders a short notification line such as "12 commits deployed".
+func commitRangeSummary(total int) string {
+	if total == 1 {
+		return "1 commit deployed"
+	}
+	return fmt.Sprintf("%d commits deployed", total)
+}
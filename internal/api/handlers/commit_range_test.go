@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/integrations/github"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := []struct {
+		gitRepo string
+		owner   string
+		repo    string
+		ok      bool
+	}{
+		{"github.com/narvanalabs/control-plane", "narvanalabs", "control-plane", true},
+		{"https://github.com/narvanalabs/control-plane.git", "narvanalabs", "control-plane", true},
+		{"git@github.com:narvanalabs/control-plane.git", "narvanalabs", "control-plane", true},
+		{"gitlab.com/narvanalabs/control-plane", "", "", false},
+		{"github.com/narvanalabs", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gitRepo, func(t *testing.T) {
+			owner, repo, ok := github.ParseRepo(tt.gitRepo)
+			if ok != tt.ok || owner != tt.owner || repo != tt.repo {
+				t.Errorf("ParseRepo(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.gitRepo, owner, repo, ok, tt.owner, tt.repo, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewCommitRangeResponse(t *testing.T) {
+	from := &models.Deployment{ID: "web-1", ServiceName: "web", GitCommit: "aaa"}
+	to := &models.Deployment{ID: "web-2", ServiceName: "web", GitCommit: "bbb"}
+	comparison := &github.Comparison{
+		TotalCommits: 2,
+		Commits: []github.Commit{
+			{SHA: "111", Message: "feat(api): compare deployments\n\nLonger body text"},
+			{SHA: "222", Message: "fix: handle missing commits"},
+		},
+	}
+
+	resp := newCommitRangeResponse(from, to, "narvanalabs/control-plane", comparison)
+	if resp.Summary != "2 commits deployed" {
+		t.Errorf("unexpected summary %q", resp.Summary)
+	}
+	if resp.FromCommit != "aaa" || resp.ToCommit != "bbb" {
+		t.Errorf("unexpected range %s..%s", resp.FromCommit, resp.ToCommit)
+	}
+	if len(resp.Changes.Features) != 1 || resp.Changes.Features[0].Area != "api" {
+		t.Errorf("expected one api feature, got %+v", resp.Changes.Features)
+	}
+	if len(resp.Changes.BugFixes) != 1 {
+		t.Errorf("expected one bug fix, got %+v", resp.Changes.BugFixes)
+	}
+	if commitRangeSummary(1) != "1 commit deployed" {
+		t.Errorf("unexpected singular summary %q", commitRangeSummary(1))
+	}
+}
+
+// flakyDeploymentStore fails every lookup of one deployment and reports
+// another as not found, the way the postgres store does.
+type flakyDeploymentStore struct {
+	*mockDeploymentStore
+	failID string
+	goneID string
+}
+
+func (m *flakyDeploymentStore) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	switch id {
+	case m.failID:
+		return nil, errors.New("connection refused")
+	case m.goneID:
+		return nil, errors.New("resource not found")
+	}
+	return m.mockDeploymentStore.Get(ctx, id)
+}
+
+// flakyDeploymentMockStore is the deployment mock store with a flaky deployment store.
+type flakyDeploymentMockStore struct {
+	*deploymentMockStore
+	deployments *flakyDeploymentStore
+}
+
+func (m *flakyDeploymentMockStore) Deployments() store.DeploymentStore { return m.deployments }
+
+func TestCompareDeploymentLookup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	base := newDeploymentMockStore()
+	base.deploymentStore.deployments["dep-1"] = &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web"}
+	base.deploymentStore.deployments["dep-other"] = &models.Deployment{ID: "dep-other", AppID: "app-2", ServiceName: "web"}
+	st := &flakyDeploymentMockStore{
+		deploymentMockStore: base,
+		deployments:         &flakyDeploymentStore{mockDeploymentStore: base.deploymentStore, failID: "dep-broken", goneID: "dep-gone"},
+	}
+	h := NewCommitRangeHandler(st, logger)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"missing to", "to=dep-missing", http.StatusNotFound},
+		{"deleted to", "to=dep-gone", http.StatusNotFound},
+		{"to of another app", "to=dep-other", http.StatusNotFound},
+		{"store error on to", "to=dep-broken", http.StatusInternalServerError},
+		{"missing from", "to=dep-1&from=dep-missing", http.StatusNotFound},
+		{"store error on from", "to=dep-1&from=dep-broken", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.Compare(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/deployments/compare?"+tt.query, nil, nil))
+			if rr.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+}
@@ -204,6 +204,11 @@ func (h *ConfigHandler) getStatusMappings() map[string]StatusMapping {
 			Color: "red",
 			Icon:  "x-circle",
 		},
+		"canceled": {
+			Label: "Canceled",
+			Color: "gray",
+			Icon:  "ban",
+		},
 	}
 }
 
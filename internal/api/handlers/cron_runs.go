@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/cronjobs"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// defaultCronRuns is how many runs ListCronRuns returns without a limit.
+const defaultCronRuns = 20
+
+// ListCronRuns handles GET /v1/apps/{appID}/services/{serviceName}/runs -
+// lists the cron service's most recent runs, newest first.
+func (h *ServiceHandler) ListCronRuns(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	if !service.IsCron() {
+		WriteBadRequest(w, "Service is not a cron service")
+		return
+	}
+
+	limit := defaultCronRuns
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.store.CronRuns().List(r.Context(), app.ID, service.Name, limit)
+	if err != nil {
+		h.logger.Error("failed to list cron runs", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to list runs")
+		return
+	}
+	if runs == nil {
+		runs = []*models.CronRun{}
+	}
+	WriteJSON(w, http.StatusOK, runs)
+}
+
+// TriggerCronRun handles POST /v1/apps/{appID}/services/{serviceName}/runs -
+// starts a run of the cron service now, outside its schedule.
+func (h *ServiceHandler) TriggerCronRun(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	if !service.IsCron() {
+		WriteBadRequest(w, "Service is not a cron service")
+		return
+	}
+
+	deployments, err := h.store.Deployments().List(r.Context(), app.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to start run")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	release := cronjobs.LatestRelease(deployments, service.Name)
+	run, err := cronjobs.Start(r.Context(), h.store, service, release, models.CronRunTriggerManual, userID, time.Now())
+	switch {
+	case errors.Is(err, cronjobs.ErrNoRelease), errors.Is(err, cronjobs.ErrRunActive):
+		WriteConflict(w, err.Error())
+		return
+	case err != nil:
+		h.logger.Error("failed to start cron run", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to start run")
+		return
+	}
+
+	h.logger.Info("cron run triggered",
+		"app_id", app.ID,
+		"service_name", service.Name,
+		"run_id", run.ID,
+		"user_id", userID,
+	)
+	WriteJSON(w, http.StatusAccepted, run)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockCronRunStore implements store.CronRunStore for testing.
+type mockCronRunStore struct {
+	store.CronRunStore
+	runs []*models.CronRun
+}
+
+func (m *mockCronRunStore) Create(ctx context.Context, run *models.CronRun) error {
+	run.ID = fmt.Sprintf("run-%d", len(m.runs)+1)
+	m.runs = append(m.runs, run)
+	return nil
+}
+
+func (m *mockCronRunStore) List(ctx context.Context, appID, serviceName string, limit int) ([]*models.CronRun, error) {
+	var result []*models.CronRun
+	for i := len(m.runs) - 1; i >= 0 && len(result) < limit; i-- {
+		if m.runs[i].AppID == appID && m.runs[i].ServiceName == serviceName {
+			result = append(result, m.runs[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCronRunStore) ListActive(ctx context.Context) ([]*models.CronRun, error) {
+	var result []*models.CronRun
+	for _, run := range m.runs {
+		if !run.Status.Finished() {
+			result = append(result, run)
+		}
+	}
+	return result, nil
+}
+
+// cronRunMockStore adds cron runs to the deployment mock store.
+type cronRunMockStore struct {
+	*deploymentMockStore
+	cronRuns *mockCronRunStore
+}
+
+func (m *cronRunMockStore) CronRuns() store.CronRunStore {
+	return m.cronRuns
+}
+
+func (m *cronRunMockStore) Settings() store.SettingsStore {
+	return &emptySettingsStore{}
+}
+
+func (m *cronRunMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func TestCronRuns(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &cronRunMockStore{deploymentMockStore: newDeploymentMockStore(), cronRuns: &mockCronRunStore{}}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 1}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"serviceName": "cleanup"}
+	target := "/v1/apps/app-1/services/cleanup/runs"
+
+	rr := httptest.NewRecorder()
+	h.Create(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/services", CreateServiceRequest{
+		Name:       "cleanup",
+		SourceType: models.SourceTypeGit,
+		GitRepo:    "github.com/acme/jobs",
+		Type:       models.ServiceTypeCron,
+		Cron:       &models.CronConfig{Schedule: "@daily", Command: []string{"./cleanup"}},
+	}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create cron service: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.ServiceConfig
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode service: %v", err)
+	}
+	if !created.IsCron() || created.Cron == nil || len(created.Ports) != 0 {
+		t.Errorf("created service = %+v, want a cron service without ports", created)
+	}
+
+	rr = httptest.NewRecorder()
+	h.TriggerCronRun(rr, templateRequest(http.MethodPost, target, nil, params))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("run before deploying: status = %d, want 409", rr.Code)
+	}
+
+	st.deploymentStore.deployments["release-1"] = &models.Deployment{
+		ID: "release-1", AppID: "app-1", ServiceName: "cleanup", Version: 1,
+		Artifact: "/nix/store/abc-jobs", Status: models.DeploymentStatusStopped, CreatedAt: time.Now(),
+	}
+	rr = httptest.NewRecorder()
+	h.TriggerCronRun(rr, templateRequest(http.MethodPost, target, nil, params))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("trigger run: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var run models.CronRun
+	if err := json.Unmarshal(rr.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if run.Trigger != models.CronRunTriggerManual || run.TriggeredBy != "user-1" || run.DeploymentID == "" {
+		t.Errorf("run = %+v, want a manual run by user-1 with a deployment", run)
+	}
+	if d := st.deploymentStore.deployments[run.DeploymentID]; d == nil || !d.IsCronRun() || d.Version != 2 {
+		t.Errorf("run deployment = %+v, want run version 2", d)
+	}
+
+	rr = httptest.NewRecorder()
+	h.TriggerCronRun(rr, templateRequest(http.MethodPost, target, nil, params))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("run while a run is active: status = %d, want 409", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ListCronRuns(rr, templateRequest(http.MethodGet, target, nil, params))
+	var runs []models.CronRun
+	if err := json.Unmarshal(rr.Body.Bytes(), &runs); err != nil {
+		t.Fatalf("decode runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("runs = %+v, want the triggered run", runs)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ListCronRuns(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/services/web/runs", nil, map[string]string{"serviceName": "web"}))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("runs of a non-cron service: status = %d, want 400", rr.Code)
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// CreateDebugSessionRequest represents the request body for starting a debug
+// session.
+type CreateDebugSessionRequest struct {
+	// DeploymentID is the running deployment to debug; the service's latest
+	// running deployment when empty
+	DeploymentID string   `json:"deployment_id,omitempty"`
+	Image        string   `json:"image,omitempty"`
+	Command      []string `json:"command,omitempty"`
+	TTLSeconds   int      `json:"ttl_seconds,omitempty"`
+}
+
+// DebugSessionResponse is a debug session with the command that opens a
+// shell in its container on the session's node.
+type DebugSessionResponse struct {
+	*models.DebugSession
+	AttachCommand string `json:"attach_command"`
+}
+
+func debugSessionResponse(session *models.DebugSession) DebugSessionResponse {
+	return DebugSessionResponse{
+		DebugSession:  session,
+		AttachCommand: shellJoin([]string{"podman", "exec", "-it", session.ContainerName(), "/bin/sh"}),
+	}
+}
+
+// CreateDebugSession handles POST /v1/apps/{appID}/services/{serviceName}/debug-sessions -
+// starts a debug container from a tools image in the namespaces of a running
+// deployment's container. The session is pending until the debug session
+// manager has started the container on the deployment's node.
+func (h *ServiceHandler) CreateDebugSession(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	if err := userHasPermission(ctx, h.store, userID, auth.PermissionDebugWorkloads); err != nil {
+		WriteForbidden(w, "You do not have permission to debug workloads")
+		return
+	}
+
+	var req CreateDebugSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	session := &models.DebugSession{
+		AppID:       app.ID,
+		ServiceName: service.Name,
+		Image:       req.Image,
+		Command:     req.Command,
+		TTLSeconds:  req.TTLSeconds,
+		Status:      models.DebugSessionPending,
+		CreatedBy:   userID,
+	}
+	session.Normalize()
+	if err := session.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	var deployment *models.Deployment
+	if req.DeploymentID != "" {
+		d, err := h.store.Deployments().Get(ctx, req.DeploymentID)
+		if err == nil && d != nil && d.AppID == app.ID && d.ServiceName == service.Name {
+			deployment = d
+		}
+		if deployment == nil || deployment.Status != models.DeploymentStatusRunning || deployment.NodeID == "" {
+			WriteConflict(w, "The deployment is not running")
+			return
+		}
+	} else {
+		deployments, err := h.store.Deployments().List(ctx, app.ID)
+		if err != nil {
+			h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+			WriteInternalError(w, "Failed to start debug session")
+			return
+		}
+		if deployment = runningDeployment(deployments, service.Name); deployment == nil {
+			WriteConflict(w, "The service has no running deployment")
+			return
+		}
+	}
+	session.DeploymentID = deployment.ID
+	session.NodeID = deployment.NodeID
+	session.CreatedAt = time.Now()
+	session.ExpiresAt = session.CreatedAt.Add(time.Duration(session.TTLSeconds) * time.Second)
+
+	if err := h.store.DebugSessions().Create(ctx, session); err != nil {
+		h.logger.Error("failed to create debug session", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to start debug session")
+		return
+	}
+
+	h.logger.Info("debug session requested",
+		"session_id", session.ID,
+		"app_id", app.ID,
+		"service_name", service.Name,
+		"deployment_id", session.DeploymentID,
+		"node_id", session.NodeID,
+		"image", session.Image,
+		"user_id", userID,
+	)
+	WriteJSON(w, http.StatusCreated, debugSessionResponse(session))
+}
+
+// ListDebugSessions handles GET /v1/apps/{appID}/services/{serviceName}/debug-sessions -
+// lists a service's debug sessions, newest first.
+func (h *ServiceHandler) ListDebugSessions(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	sessions, err := h.store.DebugSessions().ListByService(r.Context(), app.ID, service.Name, limit)
+	if err != nil {
+		h.logger.Error("failed to list debug sessions", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to list debug sessions")
+		return
+	}
+	resp := make([]DebugSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, debugSessionResponse(s))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetDebugSession handles GET /v1/apps/{appID}/services/{serviceName}/debug-sessions/{sessionID}.
+func (h *ServiceHandler) GetDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, debugSessionResponse(session))
+}
+
+// EndDebugSession handles DELETE /v1/apps/{appID}/services/{serviceName}/debug-sessions/{sessionID} -
+// ends a debug session. Its container is removed by the debug session
+// manager.
+func (h *ServiceHandler) EndDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	if err := userHasPermission(ctx, h.store, middleware.GetUserID(ctx), auth.PermissionDebugWorkloads); err != nil {
+		WriteForbidden(w, "You do not have permission to debug workloads")
+		return
+	}
+
+	if !session.Status.Finished() {
+		session.Status = models.DebugSessionTerminating
+		if err := h.store.DebugSessions().Update(ctx, session); err != nil {
+			h.logger.Error("failed to end debug session", "error", err, "session_id", session.ID)
+			WriteInternalError(w, "Failed to end debug session")
+			return
+		}
+		h.logger.Info("debug session ended", "session_id", session.ID, "user_id", middleware.GetUserID(ctx))
+	}
+	WriteJSON(w, http.StatusOK, debugSessionResponse(session))
+}
+
+// AttachDebugSessionWS handles GET /v1/apps/{appID}/services/{serviceName}/debug-sessions/{sessionID}/attach/ws -
+// opens a shell in a running session's debug container. The shell runs
+// through the local Podman, so it is only available for sessions on the
+// local node; on other nodes run the session's attach command over the
+// SSH broker.
+func (h *ServiceHandler) AttachDebugSessionWS(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.debugSession(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	if err := userHasPermission(ctx, h.store, middleware.GetUserID(ctx), auth.PermissionDebugWorkloads); err != nil {
+		WriteForbidden(w, "You do not have permission to debug workloads")
+		return
+	}
+	if session.Status != models.DebugSessionRunning {
+		WriteConflict(w, fmt.Sprintf("The debug session is %s", session.Status))
+		return
+	}
+	node, err := h.store.Nodes().Get(ctx, session.NodeID)
+	if err != nil || node == nil || node.Provider != models.NodeProviderLocal {
+		WriteConflict(w, fmt.Sprintf("The debug container runs on node %s; run the session's attach command there", session.NodeID))
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("failed to upgrade websocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	h.logger.Info("debug session attached", "session_id", session.ID, "user_id", middleware.GetUserID(ctx))
+
+	c := h.podman.Exec(session.ContainerName(), []string{"/bin/sh"})
+	c.Env = append(os.Environ(), "TERM=xterm-256color")
+	f, err := pty.Start(c)
+	if err != nil {
+		h.logger.Error("failed to start pty", "error", err, "session_id", session.ID)
+		return
+	}
+	defer f.Close()
+
+	// Set initial size
+	_ = pty.Setsize(f, &pty.Winsize{Rows: 24, Cols: 80})
+
+	// Clean up process on exit; the debug container keeps running
+	defer func() {
+		if c.Process != nil {
+			c.Process.Kill()
+		}
+	}()
+
+	// Copy PTY output to WebSocket
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Handle WebSocket input
+	for {
+		mt, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if mt == websocket.TextMessage {
+			var ctrl struct {
+				Type string `json:"type"`
+				Rows uint16 `json:"rows"`
+				Cols uint16 `json:"cols"`
+			}
+			if err := json.Unmarshal(msg, &ctrl); err == nil {
+				if ctrl.Type == "resize" {
+					_ = pty.Setsize(f, &pty.Winsize{Rows: ctrl.Rows, Cols: ctrl.Cols})
+					continue
+				}
+				if ctrl.Type == "terminate" {
+					return
+				}
+			}
+		}
+
+		if mt == websocket.BinaryMessage || mt == websocket.TextMessage {
+			f.Write(msg)
+		}
+	}
+}
+
+// debugSession loads the debug session in the URL and checks that it
+// belongs to the service in the URL.
+func (h *ServiceHandler) debugSession(w http.ResponseWriter, r *http.Request) (*models.DebugSession, bool) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return nil, false
+	}
+	sessionID := chi.URLParam(r, "sessionID")
+
+	session, err := h.store.DebugSessions().Get(r.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("failed to get debug session", "error", err, "session_id", sessionID)
+		WriteInternalError(w, "Failed to get debug session")
+		return nil, false
+	}
+	if session == nil || session.AppID != app.ID || session.ServiceName != service.Name {
+		WriteNotFound(w, "Debug session not found")
+		return nil, false
+	}
+	return session, true
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrCodeDeployFrozen is returned when a deploy is blocked by an active freeze window.
+const ErrCodeDeployFrozen = "deploy_frozen"
+
+// recentFreezeOverrides is the number of overrides returned alongside freeze windows.
+const recentFreezeOverrides = 20
+
+// DeployFreezeHandler handles org deploy freeze window HTTP requests.
+type DeployFreezeHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewDeployFreezeHandler creates a new deploy freeze handler.
+func NewDeployFreezeHandler(st store.Store, logger *slog.Logger) *DeployFreezeHandler {
+	return &DeployFreezeHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// FreezeWindowRequest is the request body for creating a freeze window.
+type FreezeWindowRequest struct {
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	StartDay  string     `json:"start_day,omitempty"`
+	StartTime string     `json:"start_time,omitempty"`
+	EndDay    string     `json:"end_day,omitempty"`
+	EndTime   string     `json:"end_time,omitempty"`
+	Timezone  string     `json:"timezone,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+// FreezeWindowsResponse lists an org's freeze windows, the one currently in
+// effect (if any) and recent overrides.
+type FreezeWindowsResponse struct {
+	Windows   []*models.FreezeWindow   `json:"windows"`
+	Active    *models.FreezeWindow     `json:"active,omitempty"`
+	Overrides []*models.FreezeOverride `json:"overrides"`
+}
+
+// FreezeOverrideRequest lets an owner deploy during an active freeze window.
+type FreezeOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// List handles GET /v1/orgs/{orgID}/freeze-windows - lists freeze windows for an org.
+func (h *DeployFreezeHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	windows, err := h.store.DeployFreezes().ListWindows(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list freeze windows", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list freeze windows")
+		return
+	}
+	overrides, err := h.store.DeployFreezes().ListOverrides(ctx, orgID, recentFreezeOverrides)
+	if err != nil {
+		h.logger.Error("failed to list freeze overrides", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list freeze windows")
+		return
+	}
+
+	if windows == nil {
+		windows = []*models.FreezeWindow{}
+	}
+	if overrides == nil {
+		overrides = []*models.FreezeOverride{}
+	}
+
+	WriteJSON(w, http.StatusOK, FreezeWindowsResponse{
+		Windows:   windows,
+		Active:    models.ActiveFreezeWindow(windows, time.Now()),
+		Overrides: overrides,
+	})
+}
+
+// Create handles POST /v1/orgs/{orgID}/freeze-windows - creates a freeze window (owners only).
+func (h *DeployFreezeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r, auth.PermissionManageFreezes) {
+		return
+	}
+
+	var req FreezeWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	window := &models.FreezeWindow{
+		OrgID:     orgID,
+		Name:      req.Name,
+		Kind:      models.FreezeWindowKind(req.Kind),
+		StartDay:  req.StartDay,
+		StartTime: req.StartTime,
+		EndDay:    req.EndDay,
+		EndTime:   req.EndTime,
+		Timezone:  req.Timezone,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: middleware.GetUserID(ctx),
+	}
+	if err := window.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.store.DeployFreezes().CreateWindow(ctx, window); err != nil {
+		h.logger.Error("failed to create freeze window", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to create freeze window")
+		return
+	}
+
+	h.logger.Info("freeze window created",
+		"window_id", window.ID,
+		"org_id", orgID,
+		"kind", window.Kind,
+		"user_id", window.CreatedBy,
+	)
+	WriteJSON(w, http.StatusCreated, window)
+}
+
+// Delete handles DELETE /v1/orgs/{orgID}/freeze-windows/{windowID} - removes a freeze window (owners only).
+func (h *DeployFreezeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	windowID := chi.URLParam(r, "windowID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r, auth.PermissionManageFreezes) {
+		return
+	}
+
+	window, err := h.store.DeployFreezes().GetWindow(ctx, windowID)
+	if err != nil {
+		h.logger.Error("failed to get freeze window", "error", err, "window_id", windowID)
+		WriteInternalError(w, "Failed to load freeze window")
+		return
+	}
+	if window == nil || window.OrgID != orgID {
+		WriteNotFound(w, "Freeze window not found")
+		return
+	}
+
+	if err := h.store.DeployFreezes().DeleteWindow(ctx, windowID); err != nil {
+		h.logger.Error("failed to delete freeze window", "error", err, "window_id", windowID)
+		WriteInternalError(w, "Failed to delete freeze window")
+		return
+	}
+
+	h.logger.Info("freeze window deleted", "window_id", windowID, "org_id", orgID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireMember writes a forbidden response unless the current user belongs to the org.
+func (h *DeployFreezeHandler) requireMember(w http.ResponseWriter, r *http.Request, orgID string) bool {
+	isMember, err := h.store.Orgs().IsMember(r.Context(), orgID, middleware.GetUserID(r.Context()))
+	if err != nil {
+		h.logger.Error("failed to check org membership", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to verify organization membership")
+		return false
+	}
+	if !isMember {
+		WriteForbidden(w, "Not a member of this organization")
+		return false
+	}
+	return true
+}
+
+// requirePermission writes a forbidden response unless the current user's role grants permission.
+func (h *DeployFreezeHandler) requirePermission(w http.ResponseWriter, r *http.Request, permission auth.Permission) bool {
+	if err := userHasPermission(r.Context(), h.store, middleware.GetUserID(r.Context()), permission); err != nil {
+		WriteForbidden(w, "Only owners can manage freeze windows")
+		return false
+	}
+	return true
+}
+
+// userHasPermission checks a permission against the user's role in the
+// request's org, when resolved, and then their instance role.
+func userHasPermission(ctx context.Context, st store.Store, userID string, permission auth.Permission) error {
+	// The org role resolved by the org middleware grants permissions within that org
+	if role := middleware.GetOrgRole(ctx); role != "" && auth.CheckOrgRolePermission(role, permission) == nil {
+		return nil
+	}
+	user, err := st.Users().GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return auth.ErrUserNotFound
+	}
+	return auth.CheckRolePermission(user.Role, permission)
+}
+
+// checkDeployFreeze enforces the app's org freeze windows. It returns the
+// active window when a deploy goes ahead under an override, nil when no
+// window is active, and false after writing an error response when the
+// deploy is blocked. Overrides need a reason and the override_freeze permission.
+func (h *DeploymentHandler) checkDeployFreeze(w http.ResponseWriter, r *http.Request, app *models.App, override *FreezeOverrideRequest) (*models.FreezeWindow, bool) {
+	if app.OrgID == "" {
+		return nil, true
+	}
+
+	ctx := r.Context()
+	windows, err := h.store.DeployFreezes().ListWindows(ctx, app.OrgID)
+	if err != nil {
+		h.logger.Error("failed to list freeze windows", "error", err, "org_id", app.OrgID)
+		WriteInternalError(w, "Failed to check deploy freeze windows")
+		return nil, false
+	}
+
+	active := models.ActiveFreezeWindow(windows, time.Now())
+	if active == nil {
+		return nil, true
+	}
+
+	if override == nil {
+		WriteErrorWithDetails(w, http.StatusConflict, ErrCodeDeployFrozen,
+			"Deploys are frozen by \""+active.Name+"\"; an owner can override with a reason", active)
+		return nil, false
+	}
+
+	override.Reason = strings.TrimSpace(override.Reason)
+	if override.Reason == "" {
+		WriteBadRequest(w, "A reason is required to override a deploy freeze")
+		return nil, false
+	}
+	if len(override.Reason) > models.MaxFreezeOverrideReasonLength {
+		WriteBadRequest(w, "Override reason must be 500 characters or fewer")
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(ctx)
+	if err := userHasPermission(ctx, h.store, userID, auth.PermissionOverrideFreeze); err != nil {
+		WriteForbidden(w, "Only owners can override a deploy freeze")
+		return nil, false
+	}
+
+	return active, true
+}
+
+// recordFreezeOverride stores an audit record for a deploy made during a freeze.
+// Failures are logged but do not fail the deploy, which has already been created.
+func (h *DeploymentHandler) recordFreezeOverride(ctx context.Context, window *models.FreezeWindow, deployment *models.Deployment, reason string) {
+	override := &models.FreezeOverride{
+		OrgID:        window.OrgID,
+		WindowID:     window.ID,
+		DeploymentID: deployment.ID,
+		AppID:        deployment.AppID,
+		ServiceName:  deployment.ServiceName,
+		UserID:       middleware.GetUserID(ctx),
+		Reason:       reason,
+	}
+	if err := h.store.DeployFreezes().RecordOverride(ctx, override); err != nil {
+		h.logger.Error("failed to record freeze override", "error", err, "deployment_id", deployment.ID)
+		return
+	}
+
+	h.logger.Warn("deploy freeze overridden",
+		"window_id", window.ID,
+		"deployment_id", deployment.ID,
+		"user_id", override.UserID,
+		"reason", reason,
+	)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockDeployFreezeStore implements store.DeployFreezeStore for testing.
+type mockDeployFreezeStore struct {
+	windows   []*models.FreezeWindow
+	overrides []*models.FreezeOverride
+}
+
+func (m *mockDeployFreezeStore) CreateWindow(ctx context.Context, window *models.FreezeWindow) error {
+	m.windows = append(m.windows, window)
+	return nil
+}
+
+func (m *mockDeployFreezeStore) GetWindow(ctx context.Context, id string) (*models.FreezeWindow, error) {
+	for _, w := range m.windows {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockDeployFreezeStore) ListWindows(ctx context.Context, orgID string) ([]*models.FreezeWindow, error) {
+	var result []*models.FreezeWindow
+	for _, w := range m.windows {
+		if w.OrgID == orgID {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDeployFreezeStore) DeleteWindow(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockDeployFreezeStore) RecordOverride(ctx context.Context, override *models.FreezeOverride) error {
+	m.overrides = append(m.overrides, override)
+	return nil
+}
+
+func (m *mockDeployFreezeStore) ListOverrides(ctx context.Context, orgID string, limit int) ([]*models.FreezeOverride, error) {
+	return m.overrides, nil
+}
+
+// roleUserStore returns users with a fixed role.
+type roleUserStore struct {
+	store.UserStore
+	role store.Role
+}
+
+func (m *roleUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return &store.User{ID: id, Role: m.role}, nil
+}
+
+// freezeMockStore adds freeze windows and users to the deployment mock store.
+type freezeMockStore struct {
+	*deploymentMockStore
+	freezes *mockDeployFreezeStore
+	users   *roleUserStore
+}
+
+func (m *freezeMockStore) DeployFreezes() store.DeployFreezeStore {
+	return m.freezes
+}
+
+func (m *freezeMockStore) Users() store.UserStore {
+	return m.users
+}
+
+func TestCreateDeployment_Freeze(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	startsAt := time.Now().Add(-time.Hour)
+	endsAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		role      store.Role
+		override  *FreezeOverrideRequest
+		status    int
+		overrides int
+	}{
+		{"blocked without override", store.RoleOwner, nil, http.StatusConflict, 0},
+		{"override requires reason", store.RoleOwner, &FreezeOverrideRequest{Reason: "  "}, http.StatusBadRequest, 0},
+		{"members cannot override", store.RoleMember, &FreezeOverrideRequest{Reason: "hotfix"}, http.StatusForbidden, 0},
+		{"owner override is recorded", store.RoleOwner, &FreezeOverrideRequest{Reason: "hotfix for outage"}, http.StatusAccepted, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &freezeMockStore{
+				deploymentMockStore: newDeploymentMockStore(),
+				freezes: &mockDeployFreezeStore{windows: []*models.FreezeWindow{{
+					ID:       "window-1",
+					OrgID:    "org-1",
+					Name:     "Holidays",
+					Kind:     models.FreezeWindowRange,
+					StartsAt: &startsAt,
+					EndsAt:   &endsAt,
+				}}},
+				users: &roleUserStore{role: tt.role},
+			}
+			app := &models.App{
+				ID:      "app-1",
+				OrgID:   "org-1",
+				OwnerID: "user-1",
+				Name:    "app",
+				Services: []models.ServiceConfig{{
+					Name:       "web",
+					SourceType: models.SourceTypeImage,
+					Image:      "nginx:latest",
+				}},
+			}
+			st.appStore.apps[app.ID] = app
+
+			body, _ := json.Marshal(CreateDeploymentRequest{FreezeOverride: tt.override})
+			req := httptest.NewRequest(http.MethodPost, "/v1/apps/app-1/deploy", bytes.NewReader(body))
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("appID", app.ID)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			NewDeploymentHandler(st, newMockQueue(), logger).Create(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status == http.StatusConflict {
+				var apiErr APIError
+				json.Unmarshal(rr.Body.Bytes(), &apiErr)
+				if apiErr.Code != ErrCodeDeployFrozen {
+					t.Errorf("code = %q, want %q", apiErr.Code, ErrCodeDeployFrozen)
+				}
+			}
+			if len(st.freezes.overrides) != tt.overrides {
+				t.Fatalf("recorded %d overrides, want %d", len(st.freezes.overrides), tt.overrides)
+			}
+			if tt.overrides > 0 {
+				o := st.freezes.overrides[0]
+				if o.WindowID != "window-1" || o.Reason != "hotfix for outage" || o.UserID != "user-1" || o.ServiceName != "web" {
+					t.Errorf("unexpected override: %+v", o)
+				}
+			}
+		})
+	}
+}
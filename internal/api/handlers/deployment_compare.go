@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// compareLogSample caps the runtime log lines read per deployment when
+// computing comparison metrics.
+const compareLogSample = 10000
+
+// DeploymentComparison describes what changed between deployment A (the
+// baseline) and deployment B, and how each behaved while it was active.
+type DeploymentComparison struct {
+	A       *models.Deployment `json:"a"`
+	B       *models.Deployment `json:"b"`
+	Changes []FieldChange      `json:"changes"`
+	Env     []EnvChange        `json:"env"`
+	Routes  []RouteChange      `json:"routes"`
+	Metrics ComparisonMetrics  `json:"metrics"`
+	// Regressions lists the ways B behaved worse than A, e.g. a higher error rate.
+	Regressions []string `json:"regressions"`
+}
+
+// FieldChange is a deployment setting whose value differs between A and B.
+type FieldChange struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// EnvChange is an environment variable added, removed or changed in B.
+type EnvChange struct {
+	Key    string `json:"key"`
+	Change string `json:"change"` // added, removed or changed
+	A      string `json:"a,omitempty"`
+	B      string `json:"b,omitempty"`
+}
+
+// RouteChange is a port exposed by only one of the deployments. Domains route
+// to a service's ports, so these are the routes that changed.
+type RouteChange struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Change   string `json:"change"` // added or removed
+}
+
+// ComparisonMetrics holds the runtime metrics of both deployments side by side.
+type ComparisonMetrics struct {
+	A RuntimeMetrics `json:"a"`
+	B RuntimeMetrics `json:"b"`
+}
+
+// RuntimeMetrics summarizes a deployment's behavior over its active period,
+// from when it started until it finished or now if it is still running.
+type RuntimeMetrics struct {
+	ActiveFrom    *time.Time `json:"active_from,omitempty"`
+	ActiveUntil   *time.Time `json:"active_until,omitempty"`
+	ActiveSeconds int64      `json:"active_seconds"`
+	LogLines      int        `json:"log_lines"`
+	ErrorLines    int        `json:"error_lines"`
+	ErrorRate     float64    `json:"error_rate"`
+	// Sampled is set when only the most recent log lines were counted.
+	Sampled bool `json:"sampled,omitempty"`
+	// BlockedEgress counts outbound connections blocked by the egress policy.
+	BlockedEgress int64 `json:"blocked_egress"`
+	// LoadTest is the result of the deployment's latest completed load test.
+	LoadTest *models.LoadTestResult `json:"load_test,omitempty"`
+}
+
+// Compare handles GET /v1/deployments/compare?a={id}&b={id}. The deployments
+// may belong to different services or apps, e.g. staging and production, as
+// long as the caller owns both.
+func (h *DeploymentHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	aID, bID := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+	if aID == "" || bID == "" {
+		WriteBadRequest(w, "a and b deployment IDs are required")
+		return
+	}
+
+	a, ok := h.loadOwnedDeployment(w, r, aID)
+	if !ok {
+		return
+	}
+	b, ok := h.loadOwnedDeployment(w, r, bID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	metricsA, err := h.runtimeMetrics(ctx, a, now)
+	if err != nil {
+		h.logger.Error("failed to load deployment metrics", "error", err, "deployment_id", a.ID)
+		WriteInternalError(w, "Failed to load deployment metrics")
+		return
+	}
+	metricsB, err := h.runtimeMetrics(ctx, b, now)
+	if err != nil {
+		h.logger.Error("failed to load deployment metrics", "error", err, "deployment_id", b.ID)
+		WriteInternalError(w, "Failed to load deployment metrics")
+		return
+	}
+
+	comparison := compareDeployments(a, b)
+	comparison.Metrics = ComparisonMetrics{A: metricsA, B: metricsB}
+	comparison.Regressions = regressions(a, b, metricsA, metricsB)
+	WriteJSON(w, http.StatusOK, comparison)
+}
+
+// loadOwnedDeployment fetches a deployment owned by the caller, writing an
+// error response if it cannot.
+func (h *DeploymentHandler) loadOwnedDeployment(w http.ResponseWriter, r *http.Request, id string) (*models.Deployment, bool) {
+	deployment, err := h.store.Deployments().Get(r.Context(), id)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment "+id+" not found")
+		return nil, false
+	}
+	app, err := h.store.Apps().Get(r.Context(), deployment.AppID)
+	if err != nil || app == nil || app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return nil, false
+	}
+	return deployment, true
+}
+
+// runtimeMetrics computes a deployment's metrics over its active period.
+func (h *DeploymentHandler) runtimeMetrics(ctx context.Context, d *models.Deployment, now time.Time) (RuntimeMetrics, error) {
+	var m RuntimeMetrics
+	if d.StartedAt == nil {
+		return m, nil
+	}
+	until := now
+	if d.FinishedAt != nil {
+		until = *d.FinishedAt
+	}
+	m.ActiveFrom, m.ActiveUntil = d.StartedAt, &until
+	m.ActiveSeconds = int64(until.Sub(*d.StartedAt).Seconds())
+
+	entries, err := h.store.Logs().ListBySource(ctx, d.ID, "runtime", compareLogSample)
+	if err != nil {
+		return m, fmt.Errorf("listing runtime logs: %w", err)
+	}
+	m.ErrorRate, m.LogLines = models.ErrorRate(entries, *d.StartedAt)
+	m.ErrorLines = int(m.ErrorRate*float64(m.LogLines) + 0.5)
+	m.Sampled = len(entries) == compareLogSample
+
+	violations, err := h.store.EgressViolations().ListByService(ctx, d.AppID, d.ServiceName, *d.StartedAt)
+	if err != nil {
+		return m, fmt.Errorf("listing egress violations: %w", err)
+	}
+	for _, v := range violations {
+		if v.DeploymentID == d.ID {
+			m.BlockedEgress += v.Count
+		}
+	}
+
+	tests, err := h.store.LoadTests().ListByDeployment(ctx, d.ID)
+	if err != nil {
+		return m, fmt.Errorf("listing load tests: %w", err)
+	}
+	for _, t := range tests {
+		if t.Status == models.LoadTestCompleted && t.Result != nil {
+			m.LoadTest = t.Result
+			break
+		}
+	}
+	return m, nil
+}
+
+// compareDeployments diffs the artifact and runtime configuration of two deployments.
+func compareDeployments(a, b *models.Deployment) *DeploymentComparison {
+	c := &DeploymentComparison{
+		A:           a,
+		B:           b,
+		Changes:     []FieldChange{},
+		Env:         []EnvChange{},
+		Routes:      []RouteChange{},
+		Regressions: []string{},
+	}
+
+	ca, cb := runtimeConfig(a), runtimeConfig(b)
+	resA, resB := deploymentResources(a), deploymentResources(b)
+	fields := []FieldChange{
+		{"service_name", a.ServiceName, b.ServiceName},
+		{"artifact", a.Artifact, b.Artifact},
+		{"build_type", string(a.BuildType), string(b.BuildType)},
+		{"git_ref", a.GitRef, b.GitRef},
+		{"git_commit", a.GitCommit, b.GitCommit},
+		{"resources.cpu", resA.CPU, resB.CPU},
+		{"resources.memory", resA.Memory, resB.Memory},
+		{"health_check", formatHealthCheck(ca.HealthCheck), formatHealthCheck(cb.HealthCheck)},
+		{"egress", formatEgress(ca.Egress), formatEgress(cb.Egress)},
+		{"node_id", a.NodeID, b.NodeID},
+	}
+	for _, f := range fields {
+		if f.A != f.B {
+			c.Changes = append(c.Changes, f)
+		}
+	}
+
+	for key, va := range ca.EnvVars {
+		vb, ok := cb.EnvVars[key]
+		switch {
+		case !ok:
+			c.Env = append(c.Env, EnvChange{Key: key, Change: "removed", A: va})
+		case va != vb:
+			c.Env = append(c.Env, EnvChange{Key: key, Change: "changed", A: va, B: vb})
+		}
+	}
+	for key, vb := range cb.EnvVars {
+		if _, ok := ca.EnvVars[key]; !ok {
+			c.Env = append(c.Env, EnvChange{Key: key, Change: "added", B: vb})
+		}
+	}
+	sort.Slice(c.Env, func(i, j int) bool { return c.Env[i].Key < c.Env[j].Key })
+
+	portsA, portsB := portSet(ca.Ports), portSet(cb.Ports)
+	for p := range portsA {
+		if !portsB[p] {
+			c.Routes = append(c.Routes, RouteChange{Port: p.ContainerPort, Protocol: p.Protocol, Change: "removed"})
+		}
+	}
+	for p := range portsB {
+		if !portsA[p] {
+			c.Routes = append(c.Routes, RouteChange{Port: p.ContainerPort, Protocol: p.Protocol, Change: "added"})
+		}
+	}
+	sort.Slice(c.Routes, func(i, j int) bool {
+		if c.Routes[i].Port != c.Routes[j].Port {
+			return c.Routes[i].Port < c.Routes[j].Port
+		}
+		return c.Routes[i].Protocol < c.Routes[j].Protocol
+	})
+
+	return c
+}
+
+// regressions describes how B behaved worse than A.
+func regressions(a, b *models.Deployment, ma, mb RuntimeMetrics) []string {
+	out := []string{}
+	if b.Status == models.DeploymentStatusFailed && a.Status != models.DeploymentStatusFailed {
+		out = append(out, "B failed")
+	}
+	if ma.LogLines > 0 && mb.LogLines > 0 && mb.ErrorRate > ma.ErrorRate {
+		out = append(out, fmt.Sprintf("error rate rose from %.2f%% to %.2f%%", ma.ErrorRate*100, mb.ErrorRate*100))
+	}
+	if mb.BlockedEgress > ma.BlockedEgress {
+		out = append(out, fmt.Sprintf("blocked outbound connections rose from %d to %d", ma.BlockedEgress, mb.BlockedEgress))
+	}
+	if la, lb := ma.LoadTest, mb.LoadTest; la != nil && lb != nil {
+		// Latencies vary between runs, so only a rise of over 10% counts
+		if lb.LatencyP99 > la.LatencyP99*1.1 {
+			out = append(out, fmt.Sprintf("load test p99 latency rose from %.1fms to %.1fms", la.LatencyP99, lb.LatencyP99))
+		}
+		if lb.ErrorRate > la.ErrorRate {
+			out = append(out, fmt.Sprintf("load test error rate rose from %.2f%% to %.2f%%", la.ErrorRate*100, lb.ErrorRate*100))
+		}
+	}
+	return out
+}
+
+// runtimeConfig returns the deployment's runtime configuration, never nil.
+func runtimeConfig(d *models.Deployment) *models.RuntimeConfig {
+	if d.Config == nil {
+		return &models.RuntimeConfig{}
+	}
+	return d.Config
+}
+
+// deploymentResources returns the resources the deployment runs with, never nil.
+func deploymentResources(d *models.Deployment) models.ResourceSpec {
+	switch {
+	case d.Config != nil && d.Config.Resources != nil:
+		return *d.Config.Resources
+	case d.Resources != nil:
+		return *d.Resources
+	}
+	return models.ResourceSpec{}
+}
+
+// portSet returns the exposed ports with the protocol defaulted to tcp.
+func portSet(ports []models.PortMapping) map[models.PortMapping]bool {
+	set := make(map[models.PortMapping]bool, len(ports))
+	for _, p := range ports {
+		if p.Protocol == "" {
+			p.Protocol = "tcp"
+		}
+		set[p] = true
+	}
+	return set
+}
+
+func formatHealthCheck(hc *models.HealthCheckConfig) string {
+	if hc == nil {
+		return ""
+	}
+	if len(hc.Command) > 0 {
+		return fmt.Sprintf("%s every %ds", strings.Join(hc.Command, " "), hc.IntervalSeconds)
+	}
+	return fmt.Sprintf("%s:%d every %ds", hc.Path, hc.Port, hc.IntervalSeconds)
+}
+
+func formatEgress(p *models.EgressPolicy) string {
+	if !p.Restricts() {
+		return "allow all"
+	}
+	allow := make([]string, 0, len(p.Allow))
+	for _, rule := range p.Allow {
+		dest := rule.Host
+		if dest == "" {
+			dest = rule.CIDR
+		}
+		if len(rule.Ports) > 0 {
+			ports := make([]string, 0, len(rule.Ports))
+			for _, port := range rule.Ports {
+				ports = append(ports, strconv.Itoa(port))
+			}
+			dest += ":" + strings.Join(ports, "/")
+		}
+		allow = append(allow, dest)
+	}
+	if len(allow) == 0 {
+		return "deny all"
+	}
+	return "deny all except " + strings.Join(allow, ", ")
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// compareMockStore adds runtime logs, egress violations and load tests to
+// the deployment mock store.
+type compareMockStore struct {
+	*deploymentMockStore
+	logs       map[string][]*models.LogEntry
+	violations []*models.EgressViolation
+	loadTests  map[string][]*models.LoadTest
+}
+
+func (m *compareMockStore) Logs() store.LogStore {
+	return compareLogs{m: m}
+}
+
+func (m *compareMockStore) EgressViolations() store.EgressViolationStore {
+	return compareViolations{m: m}
+}
+
+func (m *compareMockStore) LoadTests() store.LoadTestStore {
+	return compareLoadTests{m: m}
+}
+
+type compareLogs struct {
+	store.LogStore
+	m *compareMockStore
+}
+
+func (l compareLogs) ListBySource(ctx context.Context, deploymentID, source string, limit int) ([]*models.LogEntry, error) {
+	return l.m.logs[deploymentID], nil
+}
+
+type compareViolations struct {
+	store.EgressViolationStore
+	m *compareMockStore
+}
+
+func (v compareViolations) ListByService(ctx context.Context, appID, serviceName string, since time.Time) ([]*models.EgressViolation, error) {
+	return v.m.violations, nil
+}
+
+type compareLoadTests struct {
+	store.LoadTestStore
+	m *compareMockStore
+}
+
+func (l compareLoadTests) ListByDeployment(ctx context.Context, deploymentID string) ([]*models.LoadTest, error) {
+	return l.m.loadTests[deploymentID], nil
+}
+
+func TestCompareDeployments(t *testing.T) {
+	a := &models.Deployment{
+		ServiceName: "web",
+		Artifact:    "/nix/store/aaa-web",
+		GitCommit:   "aaa",
+		Config: &models.RuntimeConfig{
+			Resources: &models.ResourceSpec{CPU: "0.5", Memory: "512Mi"},
+			EnvVars:   map[string]string{"LOG_LEVEL": "info", "OLD": "1", "SAME": "x"},
+			Ports:     []models.PortMapping{{ContainerPort: 8080}},
+		},
+	}
+	b := &models.Deployment{
+		ServiceName: "web",
+		Artifact:    "/nix/store/bbb-web",
+		GitCommit:   "bbb",
+		Config: &models.RuntimeConfig{
+			Resources: &models.ResourceSpec{CPU: "0.5", Memory: "1Gi"},
+			EnvVars:   map[string]string{"LOG_LEVEL": "debug", "NEW": "2", "SAME": "x"},
+			Ports:     []models.PortMapping{{ContainerPort: 8080, Protocol: "tcp"}, {ContainerPort: 9090}},
+			Egress:    &models.EgressPolicy{DenyAll: true, Allow: []models.EgressRule{{Host: "api.stripe.com", Ports: []int{443}}}},
+		},
+	}
+
+	c := compareDeployments(a, b)
+
+	changed := map[string]FieldChange{}
+	for _, f := range c.Changes {
+		changed[f.Field] = f
+	}
+	for _, field := range []string{"artifact", "git_commit", "resources.memory", "egress"} {
+		if _, ok := changed[field]; !ok {
+			t.Errorf("expected %s to be reported as changed", field)
+		}
+	}
+	if len(changed) != 4 {
+		t.Errorf("changes = %+v, want 4", c.Changes)
+	}
+	if got := changed["egress"].B; got != "deny all except api.stripe.com:443" {
+		t.Errorf("egress B = %q", got)
+	}
+
+	wantEnv := []EnvChange{
+		{Key: "LOG_LEVEL", Change: "changed", A: "info", B: "debug"},
+		{Key: "NEW", Change: "added", B: "2"},
+		{Key: "OLD", Change: "removed", A: "1"},
+	}
+	if len(c.Env) != len(wantEnv) {
+		t.Fatalf("env = %+v, want %+v", c.Env, wantEnv)
+	}
+	for i := range wantEnv {
+		if c.Env[i] != wantEnv[i] {
+			t.Errorf("env[%d] = %+v, want %+v", i, c.Env[i], wantEnv[i])
+		}
+	}
+
+	if len(c.Routes) != 1 || c.Routes[0] != (RouteChange{Port: 9090, Protocol: "tcp", Change: "added"}) {
+		t.Errorf("routes = %+v, want port 9090 added", c.Routes)
+	}
+}
+
+func TestCompareHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	startedA := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	finishedA := startedA.Add(time.Hour)
+	startedB := finishedA
+
+	st := &compareMockStore{deploymentMockStore: newDeploymentMockStore(), logs: map[string][]*models.LogEntry{}}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1"}
+	st.appStore.apps["app-2"] = &models.App{ID: "app-2", OwnerID: "user-2"}
+	st.deploymentStore.deployments["dep-a"] = &models.Deployment{
+		ID: "dep-a", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusStopped,
+		StartedAt: &startedA, FinishedAt: &finishedA,
+	}
+	st.deploymentStore.deployments["dep-b"] = &models.Deployment{
+		ID: "dep-b", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusRunning, StartedAt: &startedB,
+	}
+	st.deploymentStore.deployments["dep-other"] = &models.Deployment{ID: "dep-other", AppID: "app-2", ServiceName: "web"}
+	st.logs["dep-a"] = []*models.LogEntry{
+		{Level: "info", Timestamp: startedA.Add(time.Minute)},
+		{Level: "info", Timestamp: startedA.Add(2 * time.Minute)},
+		{Level: "info", Timestamp: startedA.Add(3 * time.Minute)},
+		{Level: "error", Timestamp: startedA.Add(4 * time.Minute)},
+	}
+	st.logs["dep-b"] = []*models.LogEntry{
+		{Level: "info", Timestamp: startedB.Add(time.Minute)},
+		{Level: "error", Timestamp: startedB.Add(2 * time.Minute)},
+	}
+	st.violations = []*models.EgressViolation{{DeploymentID: "dep-b", Count: 3}}
+	st.loadTests = map[string][]*models.LoadTest{
+		"dep-a": {{Status: models.LoadTestCompleted, Result: &models.LoadTestResult{Requests: 100, LatencyP99: 40}}},
+		"dep-b": {
+			// The latest test failed, so the one before it is compared
+			{Status: models.LoadTestFailed, Error: "deployment is not running (stopped)"},
+			{Status: models.LoadTestCompleted, Result: &models.LoadTestResult{Requests: 100, LatencyP99: 60}},
+		},
+	}
+
+	h := NewDeploymentHandler(st, newMockQueue(), logger)
+	compare := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/deployments/compare"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "user-1"))
+		rr := httptest.NewRecorder()
+		h.Compare(rr, req)
+		return rr
+	}
+
+	if rr := compare("?a=dep-a"); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing b: status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	if rr := compare("?a=dep-a&b=missing"); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown deployment: status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if rr := compare("?a=dep-a&b=dep-other"); rr.Code != http.StatusForbidden {
+		t.Errorf("someone else's deployment: status = %d, want %d", rr.Code, http.StatusForbidden)
+	}
+
+	rr := compare("?a=dep-a&b=dep-b")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var got DeploymentComparison
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got.Metrics.A.ActiveSeconds != 3600 || got.Metrics.A.LogLines != 4 || got.Metrics.A.ErrorLines != 1 {
+		t.Errorf("unexpected metrics for A: %+v", got.Metrics.A)
+	}
+	if got.Metrics.B.ErrorRate != 0.5 || got.Metrics.B.BlockedEgress != 3 || got.Metrics.B.ActiveUntil == nil {
+		t.Errorf("unexpected metrics for B: %+v", got.Metrics.B)
+	}
+	if got.Metrics.A.LoadTest == nil || got.Metrics.B.LoadTest == nil || got.Metrics.B.LoadTest.LatencyP99 != 60 {
+		t.Errorf("load tests = %+v, %+v", got.Metrics.A.LoadTest, got.Metrics.B.LoadTest)
+	}
+	if len(got.Regressions) != 3 || got.Regressions[2] != "load test p99 latency rose from 40.0ms to 60.0ms" {
+		t.Errorf("regressions = %v, want error rate, egress and load test latency", got.Regressions)
+	}
+}
@@ -3,15 +3,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/admission"
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
 	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/oncall"
 	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
@@ -19,6 +23,7 @@ import (
 type DeploymentHandler struct {
 	store  store.Store
 	queue  queue.Queue
+	onCall *oncall.Checker
 	logger *slog.Logger
 }
 
@@ -27,6 +32,7 @@ func NewDeploymentHandler(st store.Store, q queue.Queue, logger *slog.Logger) *D
 	return &DeploymentHandler{
 		store:  st,
 		queue:  q,
+		onCall: oncall.NewChecker(st, nil, logger),
 		logger: logger,
 	}
 }
@@ -35,6 +41,13 @@ func NewDeploymentHandler(st store.Store, q queue.Queue, logger *slog.Logger) *D
 type CreateDeploymentRequest struct {
 	GitRef      string `json:"git_ref,omitempty"`      // Optional, uses service's git_ref if not specified
 	ServiceName string `json:"service_name,omitempty"` // Optional for app-level deploy, required for per-service deploy
+
+	// FreezeOverride lets an owner deploy during an active freeze window
+	FreezeOverride *FreezeOverrideRequest `json:"freeze_override,omitempty"`
+
+	// OnCallAckID is an approved on-call acknowledgement letting the user
+	// deploy outside business hours
+	OnCallAckID string `json:"on_call_ack_id,omitempty"`
 }
 
 // Validate validates the create deployment request.
@@ -46,6 +59,13 @@ func (r *CreateDeploymentRequest) Validate() error {
 // ServiceDeployRequest represents the request body for deploying a specific service.
 type ServiceDeployRequest struct {
 	GitRef string `json:"git_ref,omitempty"` // Optional, overrides service's git_ref if specified
+
+	// FreezeOverride lets an owner deploy during an active freeze window
+	FreezeOverride *FreezeOverrideRequest `json:"freeze_override,omitempty"`
+
+	// OnCallAckID is an approved on-call acknowledgement letting the user
+	// deploy outside business hours
+	OnCallAckID string `json:"on_call_ack_id,omitempty"`
 }
 
 // Validate validates the service deploy request.
@@ -88,6 +108,15 @@ func (h *DeploymentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Block deploys during an org freeze window unless an owner overrides it
+	freeze, ok := h.checkDeployFreeze(w, r, app, req.FreezeOverride)
+	if !ok {
+		return
+	}
+	if !h.checkOnCall(w, r, app, req.OnCallAckID) {
+		return
+	}
+
 	// Determine which services to deploy
 	servicesToDeploy := app.Services
 	if req.ServiceName != "" {
@@ -116,6 +145,15 @@ func (h *DeploymentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Sort services by dependency order (services with no dependencies first)
 	sortedServices := sortServicesByDependency(servicesToDeploy)
 
+	// Reject the whole deploy if any service violates a deny admission policy
+	subjects := make([]admission.Subject, 0, len(sortedServices))
+	for i := range sortedServices {
+		subjects = append(subjects, admissionSubject(app, &sortedServices[i], req.GitRef))
+	}
+	if !h.checkAdmission(w, r, subjects) {
+		return
+	}
+
 	now := time.Now()
 	var deployments []*models.Deployment
 
@@ -153,6 +191,7 @@ func (h *DeploymentHandler) Create(w http.ResponseWriter, r *http.Request) {
 				EnvVars:     svc.EnvVars,
 				Ports:       svc.Ports,
 				HealthCheck: svc.HealthCheck,
+				Egress:      svc.Egress,
 			},
 			DependsOn: svc.DependsOn, // Track service dependencies
 			CreatedAt: now,
@@ -164,6 +203,9 @@ func (h *DeploymentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			WriteInternalError(w, "Failed to create deployment")
 			return
 		}
+		if freeze != nil {
+			h.recordFreezeOverride(r.Context(), freeze, deployment, req.FreezeOverride.Reason)
+		}
 
 		// Create and enqueue build job based on source type
 		buildJob := h.createBuildJobForService(r.Context(), deployment.ID, appID, &svc, gitRef, buildType, now)
@@ -337,6 +379,96 @@ func (h *DeploymentHandler) Get(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, deployment)
 }
 
+// Cancel handles POST /v1/deployments/{deploymentID}/cancel - cancels a
+// deployment that has not been started on a node. The build of a pending or
+// building deployment is canceled with it; when the build is already
+// running, the request is accepted and the worker running it cancels both.
+// Deployments that are starting or running are stopped or rolled back
+// instead.
+func (h *DeploymentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deploymentID := chi.URLParam(r, "deploymentID")
+
+	deployment, err := h.store.Deployments().Get(ctx, deploymentID)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+	app, err := h.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil || app.OwnerID != middleware.GetUserID(ctx) {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	switch deployment.Status {
+	case models.DeploymentStatusPending, models.DeploymentStatusBuilding:
+		build, err := h.store.Builds().GetByDeployment(ctx, deployment.ID)
+		if err == nil && build != nil && !models.IsTerminalState(build.Status) {
+			canceled, err := cancelBuild(ctx, h.store, h.queue, build, h.logger)
+			if err != nil {
+				h.logger.Error("failed to cancel build", "error", err, "build_id", build.ID)
+				WriteInternalError(w, "Failed to cancel deployment")
+				return
+			}
+			h.logger.Info("deployment cancel requested", "deployment_id", deployment.ID, "build_id", build.ID, "canceled", canceled)
+			if !canceled {
+				WriteJSON(w, http.StatusAccepted, deployment)
+				return
+			}
+			deployment.Status = models.DeploymentStatusCanceled
+			WriteJSON(w, http.StatusOK, deployment)
+			return
+		}
+	case models.DeploymentStatusBuilt, models.DeploymentStatusScheduled:
+	case models.DeploymentStatusStarting, models.DeploymentStatusRunning:
+		WriteConflict(w, "Deployment has already started; stop or roll it back instead")
+		return
+	default:
+		WriteConflict(w, "Deployment has already finished")
+		return
+	}
+
+	deployment.Status = models.DeploymentStatusCanceled
+	deployment.UpdatedAt = time.Now()
+	if err := h.store.Deployments().Update(ctx, deployment); err != nil {
+		h.logger.Error("failed to cancel deployment", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to cancel deployment")
+		return
+	}
+	h.logger.Info("deployment canceled", "deployment_id", deployment.ID, "app_id", deployment.AppID)
+	WriteJSON(w, http.StatusOK, deployment)
+}
+
+// SmokeTests handles GET /v1/deployments/{deploymentID}/smoke-tests - returns
+// the results of a deployment's smoke tests.
+func (h *DeploymentHandler) SmokeTests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deploymentID := chi.URLParam(r, "deploymentID")
+
+	deployment, err := h.store.Deployments().Get(ctx, deploymentID)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+	app, err := h.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil || app.OwnerID != middleware.GetUserID(ctx) {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	run, err := h.store.SmokeTests().GetByDeployment(ctx, deployment.ID)
+	if err != nil {
+		h.logger.Error("failed to get smoke test run", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to get smoke tests")
+		return
+	}
+	if run == nil {
+		WriteNotFound(w, "Deployment was not smoke tested")
+		return
+	}
+	WriteJSON(w, http.StatusOK, run)
+}
+
 // CreateForService handles POST /v1/apps/{appID}/services/{serviceName}/deploy - deploys a specific service.
 func (h *DeploymentHandler) CreateForService(w http.ResponseWriter, r *http.Request) {
 	// Use resolved app ID from middleware (handles both UUID and name lookup)
@@ -394,6 +526,19 @@ func (h *DeploymentHandler) CreateForService(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Block deploys during an org freeze window unless an owner overrides it
+	freeze, ok := h.checkDeployFreeze(w, r, app, req.FreezeOverride)
+	if !ok {
+		return
+	}
+	if !h.checkOnCall(w, r, app, req.OnCallAckID) {
+		return
+	}
+
+	if !h.checkAdmission(w, r, []admission.Subject{admissionSubject(app, service, req.GitRef)}) {
+		return
+	}
+
 	// Determine git_ref: use request override or service's configured git_ref
 	gitRef := req.GitRef
 	if gitRef == "" {
@@ -449,6 +594,7 @@ func (h *DeploymentHandler) CreateForService(w http.ResponseWriter, r *http.Requ
 			EnvVars:     service.EnvVars,
 			Ports:       service.Ports,
 			HealthCheck: service.HealthCheck,
+			Egress:      service.Egress,
 		},
 		DependsOn: service.DependsOn,
 		CreatedAt: now,
@@ -460,6 +606,9 @@ func (h *DeploymentHandler) CreateForService(w http.ResponseWriter, r *http.Requ
 		WriteInternalError(w, "Failed to create deployment")
 		return
 	}
+	if freeze != nil {
+		h.recordFreezeOverride(r.Context(), freeze, deployment, req.FreezeOverride.Reason)
+	}
 
 	// Create and enqueue build job based on source type
 	buildJob := h.createBuildJobForService(r.Context(), deployment.ID, appID, service, gitRef, buildType, now)
@@ -517,6 +666,7 @@ func (h *DeploymentHandler) createBuildJobForService(ctx context.Context, deploy
 			GitURL:        service.GitRepo,
 			GitRef:        gitRef,
 			FlakeOutput:   service.FlakeOutput,
+			BuildPath:     service.BuildPath,
 			BuildType:     buildType,
 			BuildStrategy: service.BuildStrategy,
 			BuildConfig:   service.BuildConfig,
@@ -539,6 +689,7 @@ func (h *DeploymentHandler) createBuildJobForService(ctx context.Context, deploy
 			buildConfig.DatabaseOptions = &models.DatabaseOptions{
 				Type:    service.Database.Type,
 				Version: service.Database.Version,
+				Options: service.Database.Options,
 			}
 		}
 
@@ -576,6 +727,10 @@ func (h *DeploymentHandler) createBuildJobForService(ctx context.Context, deploy
 
 	// Create the build record in the database
 	if buildJob != nil {
+		// Builds people start are claimed ahead of webhook builds and retries
+		buildJob.Priority = models.BuildPriorityUser
+		buildJob.TriggeredBy = middleware.GetUserID(ctx)
+
 		if err := h.store.Builds().Create(ctx, buildJob); err != nil {
 			h.logger.Error("failed to create build record",
 				"error", err,
@@ -607,6 +762,10 @@ func (h *DeploymentHandler) createBuildJobForService(ctx context.Context, deploy
 }
 
 // Rollback handles POST /v1/deployments/:deploymentID/rollback - rolls back to a previous deployment.
+// The new deployment redeploys the target's artifact if the target ran
+// successfully, or else the artifact of the last earlier deployment of the
+// same service that did, and links to it through rollback_of.
+// Rollbacks restore a known-good artifact and are not blocked by deploy freeze windows.
 func (h *DeploymentHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 	deploymentID := chi.URLParam(r, "deploymentID")
 	if deploymentID == "" {
@@ -616,7 +775,7 @@ func (h *DeploymentHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 
 	// Get the deployment to rollback to
 	targetDeployment, err := h.store.Deployments().Get(r.Context(), deploymentID)
-	if err != nil {
+	if err != nil || targetDeployment == nil {
 		WriteNotFound(w, "Deployment not found")
 		return
 	}
@@ -629,39 +788,38 @@ func (h *DeploymentHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify the target deployment has an artifact
-	if targetDeployment.Artifact == "" {
+	history, err := h.store.Deployments().List(r.Context(), targetDeployment.AppID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", targetDeployment.AppID)
+		WriteInternalError(w, "Failed to load deployment history")
+		return
+	}
+	source, err := scheduler.RollbackSource(targetDeployment, history)
+	switch {
+	case errors.Is(err, scheduler.ErrNoArtifact):
 		WriteBadRequest(w, "Cannot rollback to a deployment without an artifact")
 		return
+	case errors.Is(err, scheduler.ErrNoPreviousDeployment):
+		WriteBadRequest(w, "No earlier successful deployment of this service to roll back to")
+		return
+	case err != nil:
+		h.logger.Error("failed to choose rollback source", "error", err, "deployment_id", deploymentID)
+		WriteInternalError(w, "Failed to create rollback deployment")
+		return
 	}
 
 	// Get next version for this service
 	// **Validates: Requirements 9.1, 9.2**
-	version, err := h.store.Deployments().GetNextVersion(r.Context(), targetDeployment.AppID, targetDeployment.ServiceName)
+	version, err := h.store.Deployments().GetNextVersion(r.Context(), source.AppID, source.ServiceName)
 	if err != nil {
-		h.logger.Error("failed to get next version", "error", err, "service", targetDeployment.ServiceName)
+		h.logger.Error("failed to get next version", "error", err, "service", source.ServiceName)
 		WriteInternalError(w, "Failed to determine deployment version")
 		return
 	}
 
-	// Create a new deployment using the artifact from the target
-	now := time.Now()
-	newDeployment := &models.Deployment{
-		ID:          uuid.New().String(),
-		AppID:       targetDeployment.AppID,
-		ServiceName: targetDeployment.ServiceName,
-		Version:     version,
-		GitRef:      targetDeployment.GitRef,
-		GitCommit:   targetDeployment.GitCommit,
-		BuildType:   targetDeployment.BuildType,
-		Artifact:    targetDeployment.Artifact,    // Use the same artifact
-		Status:      models.DeploymentStatusBuilt, // Skip build phase
-		Resources:   targetDeployment.Resources,
-		Config:      targetDeployment.Config,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
+	// Create a new deployment using the source's artifact; it starts as built
+	// so the scheduler places it without a build
+	newDeployment := scheduler.CreateRollbackDeployment(source, version, uuid.New().String())
 	if err := h.store.Deployments().Create(r.Context(), newDeployment); err != nil {
 		h.logger.Error("failed to create rollback deployment", "error", err)
 		WriteInternalError(w, "Failed to create rollback deployment")
@@ -671,6 +829,7 @@ func (h *DeploymentHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("rollback deployment created",
 		"new_deployment_id", newDeployment.ID,
 		"target_deployment_id", deploymentID,
+		"rollback_of", newDeployment.RollbackOf,
 		"artifact", newDeployment.Artifact,
 	)
 
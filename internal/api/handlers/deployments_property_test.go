@@ -59,7 +59,13 @@ func (m *mockDeploymentStore) List(ctx context.Context, appID string) ([]*models
 }
 
 func (m *mockDeploymentStore) ListByNode(ctx context.Context, nodeID string) ([]*models.Deployment, error) {
-	return nil, nil
+	var result []*models.Deployment
+	for _, d := range m.deployments {
+		if d.NodeID == nodeID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
 }
 
 func (m *mockDeploymentStore) ListByStatus(ctx context.Context, status models.DeploymentStatus) ([]*models.Deployment, error) {
@@ -219,6 +225,22 @@ func (m *mockBuildStore) ListQueued(ctx context.Context) ([]*models.BuildJob, er
 	return result, nil
 }
 
+func (m *mockBuildStore) FindByContentHash(ctx context.Context, contentHash string) (*models.BuildJob, error) {
+	return nil, nil
+}
+
+func (m *mockBuildStore) FailureStats(ctx context.Context, appID, serviceName string, since time.Time) (*models.BuildFailureStats, error) {
+	return &models.BuildFailureStats{ServiceName: serviceName, Since: since, Classes: []*models.BuildFailureClassStats{}}, nil
+}
+
+func (m *mockBuildStore) RequestCancel(ctx context.Context, id string) error {
+	if b, ok := m.builds[id]; ok && b.CancelRequestedAt == nil {
+		now := time.Now()
+		b.CancelRequestedAt = &now
+	}
+	return nil
+}
+
 func (m *mockBuildStore) List(ctx context.Context, appID string) ([]*models.BuildJob, error) {
 	var result []*models.BuildJob
 	for _, b := range m.builds {
@@ -238,6 +260,7 @@ type deploymentMockStore struct {
 	appStore        *mockAppStore
 	deploymentStore *mockDeploymentStore
 	buildStore      *mockBuildStore
+	scaleEvents     *mockScaleEventStore
 }
 
 func newDeploymentMockStore() *deploymentMockStore {
@@ -245,6 +268,7 @@ func newDeploymentMockStore() *deploymentMockStore {
 		appStore:        newMockAppStore(),
 		deploymentStore: newMockDeploymentStore(),
 		buildStore:      newMockBuildStore(),
+		scaleEvents:     &mockScaleEventStore{},
 	}
 }
 
@@ -300,6 +324,186 @@ func (m *deploymentMockStore) Orgs() store.OrgStore {
 	return nil
 }
 
+func (m *deploymentMockStore) Announcements() store.AnnouncementStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Releases() store.ReleaseStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Stats() store.StatsStore {
+	return nil
+}
+
+func (m *deploymentMockStore) EgressViolations() store.EgressViolationStore {
+	return nil
+}
+
+func (m *deploymentMockStore) DeployFreezes() store.DeployFreezeStore {
+	return nil
+}
+
+func (m *deploymentMockStore) OnCall() store.OnCallStore {
+	return &mockOnCallStore{}
+}
+
+func (m *deploymentMockStore) APICatalog() store.APICatalogStore {
+	return nil
+}
+
+func (m *deploymentMockStore) SCIM() store.SCIMStore {
+	return nil
+}
+
+func (m *deploymentMockStore) APIKeys() store.APIKeyStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Notifications() store.NotificationStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Promotions() store.PromotionStore {
+	return nil
+}
+
+func (m *deploymentMockStore) SSH() store.SSHStore {
+	return nil
+}
+
+func (m *deploymentMockStore) BuildWorkers() store.BuildWorkerStore {
+	return nil
+}
+
+func (m *deploymentMockStore) ServiceTemplates() store.ServiceTemplateStore {
+	return nil
+}
+
+func (m *deploymentMockStore) BuildLogs() store.BuildLogStore {
+	return nil
+}
+
+func (m *deploymentMockStore) BuildSnapshots() store.BuildSnapshotStore {
+	return nil
+}
+
+func (m *deploymentMockStore) AppHooks() store.AppHookStore {
+	return nil
+}
+
+func (m *deploymentMockStore) CDN() store.CDNStore {
+	return nil
+}
+
+func (m *deploymentMockStore) BuildAttestations() store.BuildAttestationStore {
+	return nil
+}
+
+func (m *deploymentMockStore) CronRuns() store.CronRunStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Metrics() store.MetricStore {
+	return nil
+}
+
+func (m *deploymentMockStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	return &mockAdmissionPolicyStore{}
+}
+
+func (m *deploymentMockStore) Audit() store.AuditStore {
+	return nil
+}
+
+func (m *deploymentMockStore) OrgSecrets() store.OrgSecretStore {
+	return nil
+}
+
+func (m *deploymentMockStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	return nil
+}
+
+func (m *deploymentMockStore) SmokeTests() store.SmokeTestStore {
+	return nil
+}
+
+func (m *deploymentMockStore) LoadTests() store.LoadTestStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Archives() store.ArchiveStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Operations() store.OperationStore {
+	return nil
+}
+
+func (m *deploymentMockStore) DebugSessions() store.DebugSessionStore {
+	return nil
+}
+
+func (m *deploymentMockStore) AuthSessions() store.AuthSessionStore {
+	return nil
+}
+
+func (m *deploymentMockStore) RateLimits() store.RateLimitStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Consistency() store.ConsistencyStore {
+	return nil
+}
+
+func (m *deploymentMockStore) DBHealth() store.DBHealthStore {
+	return nil
+}
+
+func (m *deploymentMockStore) AppManifests() store.AppManifestStore {
+	return nil
+}
+
+func (m *deploymentMockStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return nil
+}
+
+func (m *deploymentMockStore) ScaleEvents() store.ScaleEventStore {
+	return m.scaleEvents
+}
+
+func (m *deploymentMockStore) Environments() store.EnvironmentStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Federation() store.FederationStore {
+	return nil
+}
+
+func (m *deploymentMockStore) EvidenceExports() store.EvidenceExportStore {
+	return nil
+}
+
+func (m *deploymentMockStore) APIUsage() store.APIUsageStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Events() store.EventStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Webhooks() store.WebhookStore {
+	return nil
+}
+
+func (m *deploymentMockStore) AppTemplates() store.AppTemplateStore {
+	return nil
+}
+
+func (m *deploymentMockStore) Services() store.ServiceStore {
+	return &mockServiceStore{apps: m.appStore}
+}
+
 func (m *deploymentMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	return fn(m)
 }
@@ -472,6 +676,48 @@ func TestRollbackUsesPreviousArtifact(t *testing.T) {
 	properties.TestingRun(t)
 }
 
+func TestRollbackFailedDeploymentRestoresLastSuccess(t *testing.T) {
+	st := newDeploymentMockStore()
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+	started := time.Now().Add(-time.Hour)
+	st.deploymentStore.deployments["good"] = &models.Deployment{
+		ID: "good", AppID: "app-1", ServiceName: "web", Version: 1,
+		Artifact: "ghcr.io/acme/web:1", BuildType: models.BuildTypeOCI,
+		Status: models.DeploymentStatusStopped, StartedAt: &started,
+	}
+	st.deploymentStore.deployments["bad"] = &models.Deployment{
+		ID: "bad", AppID: "app-1", ServiceName: "web", Version: 2,
+		Artifact: "ghcr.io/acme/web:2", BuildType: models.BuildTypeOCI,
+		Status: models.DeploymentStatusFailed,
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/deployments/bad/rollback", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("deploymentID", "bad")
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	NewDeploymentHandler(st, newMockQueue(), slog.Default()).Rollback(rr, req)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rr.Code, rr.Body.String())
+	}
+
+	var rollback models.Deployment
+	if err := json.NewDecoder(rr.Body).Decode(&rollback); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if rollback.RollbackOf != "good" || rollback.Artifact != "ghcr.io/acme/web:1" {
+		t.Errorf("rollback_of = %q, artifact = %q; want good, ghcr.io/acme/web:1", rollback.RollbackOf, rollback.Artifact)
+	}
+	if rollback.Version != 3 || rollback.Status != models.DeploymentStatusBuilt {
+		t.Errorf("version = %d, status = %s; want 3, built", rollback.Version, rollback.Status)
+	}
+	if st.deploymentStore.deployments[rollback.ID] == nil {
+		t.Error("rollback deployment was not stored")
+	}
+}
+
 // **Feature: control-plane, Property 21: Multi-service deployment creation**
 // *For any* application with N services, triggering a deployment should create
 // exactly N deployment records (one per service).
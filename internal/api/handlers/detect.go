@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/builder/clone"
 	"github.com/narvanalabs/control-plane/internal/builder/detector"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
@@ -20,29 +21,32 @@ import (
 // DetectHandler handles build strategy detection HTTP requests.
 type DetectHandler struct {
 	detector detector.Detector
-	logger   *slog.Logger
+	// clone shallow-clones a repository into destDir and returns the
+	// branch it checked out; replaced in tests
+	clone  func(ctx context.Context, gitURL, gitRef, destDir string) (string, error)
+	logger *slog.Logger
 }
 
 // NewDetectHandler creates a new detect handler.
 func NewDetectHandler(logger *slog.Logger) *DetectHandler {
-	return &DetectHandler{
-		detector: detector.NewDetector(),
-		logger:   logger,
-	}
+	return NewDetectHandlerWithDetector(detector.NewDetector(), logger)
 }
 
 // NewDetectHandlerWithDetector creates a new detect handler with a custom detector.
 func NewDetectHandlerWithDetector(d detector.Detector, logger *slog.Logger) *DetectHandler {
-	return &DetectHandler{
+	h := &DetectHandler{
 		detector: d,
 		logger:   logger,
 	}
+	h.clone = h.cloneRepository
+	return h
 }
 
 // DetectRequest represents the request body for detecting build strategy.
 type DetectRequest struct {
-	GitURL string `json:"git_url"`
-	GitRef string `json:"git_ref,omitempty"`
+	GitURL    string `json:"git_url"`
+	GitRef    string `json:"git_ref,omitempty"`
+	BuildPath string `json:"build_path,omitempty"` // Directory to detect, default the repository root
 }
 
 // DetectResponse represents the response for build strategy detection.
@@ -56,6 +60,9 @@ type DetectResponse struct {
 	Confidence           float64                `json:"confidence"`
 	Warnings             []string               `json:"warnings,omitempty"`
 	DefaultBranch        string                 `json:"default_branch,omitempty"`
+	// Projects are the directories of the repository that can each be
+	// built as a service, found down to detector.DefaultProjectDepth levels
+	Projects []detector.Project `json:"projects,omitempty"`
 }
 
 // DetectErrorResponse represents an error response for detection failures.
@@ -63,6 +70,9 @@ type DetectErrorResponse struct {
 	Error       string   `json:"error"`
 	Code        string   `json:"code"`
 	Suggestions []string `json:"suggestions,omitempty"`
+	// Projects are set when the repository root is not a project but
+	// subdirectories are
+	Projects []detector.Project `json:"projects,omitempty"`
 }
 
 // Detect handles POST /v1/detect - detects build strategy for a repository.
@@ -73,34 +83,48 @@ func (h *DetectHandler) Detect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response, ok := h.detectRepository(r.Context(), w, req)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// detectRepository validates a detection request, shallow-clones the
+// repository and detects its build path and the projects within it. It
+// writes an error response and returns false if detection is not possible.
+func (h *DetectHandler) detectRepository(ctx context.Context, w http.ResponseWriter, req DetectRequest) (*DetectResponse, bool) {
 	// Validate git URL
 	if req.GitURL == "" {
 		WriteBadRequest(w, "git_url is required")
-		return
+		return nil, false
 	}
 
 	if !isValidGitURL(req.GitURL) {
 		WriteBadRequest(w, "Invalid git_url format")
-		return
+		return nil, false
+	}
+	buildPath, err := models.CleanBuildPath(req.BuildPath)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return nil, false
 	}
 
-	// Default git ref to empty to detect default branch
-	gitRef := req.GitRef
-
-	// Create temporary directory for cloning
+	// Create temporary directory for cloning; an empty git ref clones the
+	// default branch
 	tempDir, err := os.MkdirTemp("", "detect-*")
 	if err != nil {
 		h.logger.Error("failed to create temp directory", "error", err)
 		WriteInternalError(w, "Failed to create temporary directory")
-		return
+		return nil, false
 	}
 	defer os.RemoveAll(tempDir)
 
 	// Clone the repository
-	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	clonedBranch, err := h.cloneRepository(ctx, req.GitURL, gitRef, tempDir)
+	clonedBranch, err := h.clone(ctx, req.GitURL, req.GitRef, tempDir)
 	if err != nil {
 		h.logger.Error("failed to clone repository", "error", err, "url", req.GitURL)
 		h.writeDetectionError(w, "Failed to clone repository", "clone_failed", []string{
@@ -108,19 +132,38 @@ func (h *DetectHandler) Detect(w http.ResponseWriter, r *http.Request) {
 			"Ensure the repository is publicly accessible or provide authentication",
 			"Check that the specified branch/ref exists",
 		})
-		return
+		return nil, false
+	}
+
+	// List every project in the repository, so a monorepo can back a
+	// service for each
+	projects, err := detector.FindProjects(ctx, h.detector, tempDir, detector.DefaultProjectDepth)
+	if err != nil {
+		h.logger.Warn("failed to find projects in repository", "error", err, "url", req.GitURL)
 	}
 
 	// Run detection
-	result, err := h.detector.Detect(ctx, tempDir)
+	detectPath, err := clone.Subdir(tempDir, buildPath)
+	if err != nil {
+		h.writeDetectionError(w, err.Error(), "build_path_not_found", []string{
+			"Check that the directory exists on the branch being detected",
+			"Leave build_path empty to detect the repository root",
+		})
+		return nil, false
+	}
+	result, err := h.detector.Detect(ctx, detectPath)
 	if err != nil {
-		h.logger.Info("detection failed", "error", err, "url", req.GitURL)
+		h.logger.Info("detection failed", "error", err, "url", req.GitURL, "build_path", buildPath)
+		if buildPath == "" && len(projects) > 0 {
+			h.writeSubdirectoryProjects(w, projects)
+			return nil, false
+		}
 		h.handleDetectionError(w, err)
-		return
+		return nil, false
 	}
 
 	// Build response
-	response := DetectResponse{
+	response := &DetectResponse{
 		Strategy:             result.Strategy,
 		Framework:            result.Framework,
 		Version:              result.Version,
@@ -130,16 +173,17 @@ func (h *DetectHandler) Detect(w http.ResponseWriter, r *http.Request) {
 		Confidence:           result.Confidence,
 		Warnings:             result.Warnings,
 		DefaultBranch:        clonedBranch,
+		Projects:             projects,
 	}
 
 	h.logger.Info("detection completed",
 		"url", req.GitURL,
+		"build_path", buildPath,
 		"strategy", result.Strategy,
 		"framework", result.Framework,
 		"default_branch", clonedBranch,
 	)
-
-	WriteJSON(w, http.StatusOK, response)
+	return response, true
 }
 
 // cloneRepository clones a git repository to the specified directory.
@@ -225,6 +269,21 @@ func (h *DetectHandler) handleDetectionError(w http.ResponseWriter, err error) {
 	}
 }
 
+// writeSubdirectoryProjects writes the error for a repository whose root is
+// not a project, suggesting the build paths of the projects within it.
+func (h *DetectHandler) writeSubdirectoryProjects(w http.ResponseWriter, projects []detector.Project) {
+	suggestions := make([]string, 0, len(projects))
+	for _, p := range projects {
+		suggestions = append(suggestions, fmt.Sprintf("Set build_path to %q to build its %s project", p.BuildPath, p.Detection.Strategy))
+	}
+	WriteJSON(w, http.StatusUnprocessableEntity, DetectErrorResponse{
+		Error:       "No application found at the repository root",
+		Code:        "projects_in_subdirectories",
+		Suggestions: suggestions,
+		Projects:    projects,
+	})
+}
+
 // writeDetectionError writes a detection error response.
 func (h *DetectHandler) writeDetectionError(w http.ResponseWriter, message, code string, suggestions []string) {
 	response := DetectErrorResponse{
@@ -264,9 +323,10 @@ func normalizeGitURL(url string) string {
 	return "https://" + url
 }
 
-// CloneAndDetect is a helper function that clones a repository and runs detection.
+// CloneAndDetect is a helper function that clones a repository and runs
+// detection on its build path, the repository root when empty.
 // This is useful for testing and can be called directly.
-func (h *DetectHandler) CloneAndDetect(ctx context.Context, gitURL, gitRef string) (*models.DetectionResult, error) {
+func (h *DetectHandler) CloneAndDetect(ctx context.Context, gitURL, gitRef, buildPath string) (*models.DetectionResult, error) {
 	// Create temporary directory for cloning
 	tempDir, err := os.MkdirTemp("", "detect-*")
 	if err != nil {
@@ -275,13 +335,17 @@ func (h *DetectHandler) CloneAndDetect(ctx context.Context, gitURL, gitRef strin
 	defer os.RemoveAll(tempDir)
 
 	// Clone the repository
-	_, err = h.cloneRepository(ctx, gitURL, gitRef, tempDir)
+	_, err = h.clone(ctx, gitURL, gitRef, tempDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	// Run detection
-	return h.detector.Detect(ctx, tempDir)
+	detectPath, err := clone.Subdir(tempDir, buildPath)
+	if err != nil {
+		return nil, err
+	}
+	return h.detector.Detect(ctx, detectPath)
 }
 
 // DetectFromPath runs detection on a local path (useful for testing).
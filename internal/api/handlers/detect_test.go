@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// cloneTestdata stands in for cloning by copying a repository from testdata.
+func cloneTestdata(repo string) func(ctx context.Context, gitURL, gitRef, destDir string) (string, error) {
+	return func(ctx context.Context, gitURL, gitRef, destDir string) (string, error) {
+		if err := os.CopyFS(destDir, os.DirFS("../../../testdata/repos/"+repo)); err != nil {
+			return "", err
+		}
+		return "main", nil
+	}
+}
+
+func TestDetectForService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	h := NewServiceHandler(newDeploymentMockStore(), nil, nil, logger)
+	h.detect.clone = cloneTestdata("mixed-languages")
+
+	detect := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.DetectForService(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/detect", json.RawMessage(body), nil))
+		return rr
+	}
+
+	rr := detect(`{"git_url": "github.com/acme/mono"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp ServiceDetectResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Strategy != models.BuildStrategyAutoGo || resp.BuildType != models.BuildTypePureNix || resp.Confidence == 0 || resp.DefaultBranch != "main" {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.Projects) < 3 {
+		t.Errorf("projects = %+v, want the root, frontend and scripts", resp.Projects)
+	}
+
+	rr = detect(`{"git_url": "github.com/acme/mono", "git_ref": "main", "build_path": "frontend"}`)
+	resp = ServiceDetectResponse{}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Strategy != models.BuildStrategyAutoNode {
+		t.Errorf("frontend: status = %d, response = %s", rr.Code, rr.Body.String())
+	}
+
+	for body, want := range map[string]int{
+		`{}`: http.StatusBadRequest,
+		`{"git_url": "github.com/acme/mono", "build_path": "../other"}`:    http.StatusBadRequest,
+		`{"git_url": "github.com/acme/mono", "build_path": "backend/api"}`: http.StatusUnprocessableEntity,
+	} {
+		if rr := detect(body); rr.Code != want {
+			t.Errorf("%s: status = %d, want %d: %s", body, rr.Code, want, rr.Body.String())
+		}
+	}
+
+	h.detect.clone = func(ctx context.Context, gitURL, gitRef, destDir string) (string, error) {
+		return "", errors.New("repository not found")
+	}
+	rr = detect(`{"git_url": "github.com/acme/missing"}`)
+	var failure DetectErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &failure)
+	if rr.Code != http.StatusUnprocessableEntity || failure.Code != "clone_failed" {
+		t.Errorf("clone failure: status = %d: %s", rr.Code, rr.Body.String())
+	}
+}
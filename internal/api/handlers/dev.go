@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
+	"github.com/narvanalabs/control-plane/internal/deploy"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DevSessionRequest is the request body for starting a local dev session.
+type DevSessionRequest struct {
+	// With names services to tunnel to in addition to the service's
+	// depends_on.
+	With []string `json:"with,omitempty"`
+}
+
+// DevEnvVar is a variable of a service's resolved environment.
+type DevEnvVar struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+// DevDependency is a service a dev session reaches through tunnels.
+type DevDependency struct {
+	Service string `json:"service"`
+	Ports   []int  `json:"ports"`
+	Running bool   `json:"running"`
+}
+
+// DevSessionResponse is everything needed to run a service locally: its
+// environment as deployments receive it and the dependencies to tunnel to.
+type DevSessionResponse struct {
+	AppID        string          `json:"app_id"`
+	Service      string          `json:"service"`
+	StartCommand string          `json:"start_command,omitempty"`
+	Env          []DevEnvVar     `json:"env"`
+	Dependencies []DevDependency `json:"dependencies"`
+}
+
+// CreateDevSession handles POST /v1/apps/{appID}/services/{serviceName}/dev -
+// resolves the service's environment, including decrypted secrets, and the
+// ports of its dependencies so it can be run on a developer's machine. The
+// request is audited like any other change since it discloses secrets.
+func (h *ServiceHandler) CreateDevSession(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	var req DevSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	deployments, err := h.store.Deployments().List(r.Context(), app.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to start dev session")
+		return
+	}
+
+	resp := DevSessionResponse{AppID: app.ID, Service: service.Name, Env: []DevEnvVar{}, Dependencies: []DevDependency{}}
+	if service.BuildConfig != nil {
+		resp.StartCommand = service.BuildConfig.StartCommand
+	}
+
+	seen := map[string]bool{service.Name: true}
+	for _, name := range append(append([]string{}, service.DependsOn...), req.With...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		var dep *models.ServiceConfig
+		for i := range app.Services {
+			if app.Services[i].Name == name {
+				dep = &app.Services[i]
+			}
+		}
+		if dep == nil {
+			WriteBadRequest(w, "Unknown service: "+name)
+			return
+		}
+		resp.Dependencies = append(resp.Dependencies, DevDependency{
+			Service: name,
+			Ports:   tunnelPorts(dep),
+			Running: runningDeployment(deployments, name) != nil,
+		})
+	}
+
+	env, err := deploy.NewEnvMerger(h.store, h.sopsService, h.logger).Resolve(r.Context(), app.ID, service.Name, service.EnvVars)
+	if err != nil {
+		h.logger.Error("failed to resolve environment", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to resolve environment")
+		return
+	}
+	for key, value := range env.Vars {
+		resp.Env = append(resp.Env, DevEnvVar{Key: key, Value: value, Secret: env.Secrets[key]})
+	}
+	sort.Slice(resp.Env, func(i, j int) bool { return resp.Env[i].Key < resp.Env[j].Key })
+
+	h.logger.Info("dev session started",
+		"app_id", app.ID,
+		"service_name", service.Name,
+		"user_id", middleware.GetUserID(r.Context()),
+		"dependencies", len(resp.Dependencies),
+	)
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// TunnelWS handles GET /v1/apps/{appID}/services/{serviceName}/tunnel/ws?port=N -
+// relays a TCP connection to a port of the service's running deployment over
+// a WebSocket, as binary messages in both directions.
+func (h *ServiceHandler) TunnelWS(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	port, err := strconv.Atoi(r.URL.Query().Get("port"))
+	if err != nil || !containsPort(tunnelPorts(service), port) {
+		WriteBadRequest(w, "port must be one of the service's ports")
+		return
+	}
+
+	deployments, err := h.store.Deployments().List(r.Context(), app.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to open tunnel")
+		return
+	}
+	running := runningDeployment(deployments, service.Name)
+	if running == nil {
+		WriteConflict(w, "Service "+service.Name+" is not running")
+		return
+	}
+	node, err := h.store.Nodes().Get(r.Context(), running.NodeID)
+	if err != nil || node == nil {
+		WriteConflict(w, "Service "+service.Name+" is not placed on a node")
+		return
+	}
+
+	// Dial before upgrading so an unreachable service is an HTTP error
+	upstream, err := h.dial(r.Context(), "tcp", net.JoinHostPort(node.Address, strconv.Itoa(port)))
+	if err != nil {
+		h.logger.Warn("failed to reach service for tunnel", "error", err, "app_id", app.ID, "service_name", service.Name, "node_id", node.ID)
+		WriteError(w, http.StatusBadGateway, "TUNNEL_FAILED", "Could not reach service "+service.Name)
+		return
+	}
+	defer upstream.Close()
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("failed to upgrade websocket", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	logger := h.logger.With("app_id", app.ID, "service_name", service.Name, "port", port, "user_id", middleware.GetUserID(r.Context()))
+	logger.Info("tunnel opened", "deployment_id", running.ID, "node_id", node.ID)
+
+	var in, out atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := upstream.Read(buf)
+			if n > 0 {
+				if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					return
+				}
+				out.Add(int64(n))
+			}
+			if err != nil {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+				return
+			}
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if _, err := upstream.Write(msg); err != nil {
+			break
+		}
+		in.Add(int64(len(msg)))
+	}
+	upstream.Close()
+	<-done
+	logger.Info("tunnel closed", "bytes_in", in.Load(), "bytes_out", out.Load())
+}
+
+// tunnelPorts returns the TCP ports a service can be tunneled to: its
+// declared ports, or its database's default port.
+func tunnelPorts(service *models.ServiceConfig) []int {
+	var ports []int
+	for _, p := range service.Ports {
+		if p.Protocol == "" || p.Protocol == "tcp" {
+			ports = append(ports, p.ContainerPort)
+		}
+	}
+	if len(ports) == 0 && service.Database != nil {
+		if port := databases.GetDefaultPort(databases.DatabaseType(service.Database.Type)); port > 0 {
+			ports = append(ports, port)
+		}
+	}
+	if ports == nil {
+		ports = []int{}
+	}
+	return ports
+}
+
+// runningDeployment returns the service's latest running deployment that is
+// placed on a node, or nil.
+func runningDeployment(deployments []*models.Deployment, serviceName string) *models.Deployment {
+	var running *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName != serviceName || d.Status != models.DeploymentStatusRunning || d.NodeID == "" {
+			continue
+		}
+		if running == nil || d.Version > running.Version {
+			running = d
+		}
+	}
+	return running
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
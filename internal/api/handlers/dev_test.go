@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type devSecretStore struct {
+	store.SecretStore
+	values map[string][]byte
+}
+
+func (s *devSecretStore) GetAll(ctx context.Context, appID string) (map[string][]byte, error) {
+	return s.values, nil
+}
+
+type devNodeStore struct {
+	store.NodeStore
+	node *models.Node
+}
+
+func (s *devNodeStore) Get(ctx context.Context, id string) (*models.Node, error) {
+	if s.node == nil || s.node.ID != id {
+		return nil, nil
+	}
+	return s.node, nil
+}
+
+// devMockStore adds secrets and nodes to the deployment mock store.
+type devMockStore struct {
+	*deploymentMockStore
+	secrets *devSecretStore
+	nodes   *devNodeStore
+}
+
+func (m *devMockStore) Secrets() store.SecretStore { return m.secrets }
+func (m *devMockStore) Nodes() store.NodeStore     { return m.nodes }
+
+func newDevMockStore() *devMockStore {
+	st := &devMockStore{
+		deploymentMockStore: newDeploymentMockStore(),
+		secrets:             &devSecretStore{values: map[string][]byte{"DB_DATABASE_URL": []byte("postgres://u:p@localhost:5432/shop")}},
+		nodes:               &devNodeStore{node: &models.Node{ID: "node-1", Address: "10.0.0.5"}},
+	}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "shop",
+		EnvVars: map[string]string{"REGION": "eu"},
+		Services: []models.ServiceConfig{
+			{Name: "web", EnvVars: map[string]string{"LOG_LEVEL": "debug"}, DependsOn: []string{"db"},
+				BuildConfig: &models.BuildConfig{StartCommand: "./web --port 8080"}},
+			{Name: "db", SourceType: models.SourceTypeDatabase, Database: &models.DatabaseConfig{Type: "postgres", Version: "16"}},
+			{Name: "cache", Ports: []models.PortMapping{{ContainerPort: 6379}}},
+		}}
+	st.deploymentStore.deployments["dep-1"] = &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "db",
+		Version: 1, Status: models.DeploymentStatusRunning, NodeID: "node-1"}
+	return st
+}
+
+func TestCreateDevSession(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	h := NewServiceHandler(newDevMockStore(), nil, nil, logger)
+	params := map[string]string{"serviceName": "web"}
+
+	rr := httptest.NewRecorder()
+	h.CreateDevSession(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/services/web/dev", DevSessionRequest{With: []string{"cache"}}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body)
+	}
+	var resp DevSessionResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+
+	if resp.StartCommand != "./web --port 8080" {
+		t.Errorf("start command = %q", resp.StartCommand)
+	}
+	want := []DevEnvVar{
+		{Key: "DB_DATABASE_URL", Value: "postgres://u:p@localhost:5432/shop", Secret: true},
+		{Key: "LOG_LEVEL", Value: "debug"},
+		{Key: "REGION", Value: "eu"},
+	}
+	if len(resp.Env) != len(want) {
+		t.Fatalf("env = %+v, want %+v", resp.Env, want)
+	}
+	for i := range want {
+		if resp.Env[i] != want[i] {
+			t.Errorf("env[%d] = %+v, want %+v", i, resp.Env[i], want[i])
+		}
+	}
+
+	if len(resp.Dependencies) != 2 {
+		t.Fatalf("dependencies = %+v", resp.Dependencies)
+	}
+	if db := resp.Dependencies[0]; db.Service != "db" || len(db.Ports) != 1 || db.Ports[0] != 5432 || !db.Running {
+		t.Errorf("db dependency = %+v", db)
+	}
+	if cache := resp.Dependencies[1]; cache.Service != "cache" || cache.Ports[0] != 6379 || cache.Running {
+		t.Errorf("cache dependency = %+v", cache)
+	}
+
+	rr = httptest.NewRecorder()
+	h.CreateDevSession(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/services/web/dev", DevSessionRequest{With: []string{"queue"}}, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown dependency status = %d, want 400", rr.Code)
+	}
+}
+
+func TestTunnelWS(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	h := NewServiceHandler(newDevMockStore(), nil, nil, logger)
+
+	var dialed string
+	h.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = address
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			io.Copy(server, server) // Echo
+		}()
+		return client, nil
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.UserIDKey, "user-1")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("appID", "app-1")
+		rctx.URLParams.Add("serviceName", strings.Split(r.URL.Path, "/")[1])
+		h.TunnelWS(w, r.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx)))
+	}))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/db?port=5432", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if dialed != "10.0.0.5:5432" {
+		t.Errorf("dialed %q, want the db's node and port", dialed)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "ping" {
+		t.Errorf("echo = %q, %v", msg, err)
+	}
+
+	for path, want := range map[string]int{
+		"/db?port=22":      http.StatusBadRequest, // Not one of the service's ports
+		"/cache?port=6379": http.StatusConflict,   // Not running
+	} {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+path, nil)
+		if err == nil || resp == nil || resp.StatusCode != want {
+			t.Errorf("%s: response = %v, %v; want %d", path, resp, err, want)
+		}
+	}
+}
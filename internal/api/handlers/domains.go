@@ -143,6 +143,104 @@ func (h *DomainHandler) Create(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusCreated, domain)
 }
 
+// PutDomainRequest represents the request body for routing a domain by name.
+type PutDomainRequest struct {
+	Service string `json:"service"`
+}
+
+// Put handles PUT /v1/apps/:appID/domains/:domain - routes a custom domain
+// to a service, adding it or changing its service while keeping its ID.
+// Putting the same service again changes nothing, so the request is safe to
+// repeat. The response carries the domain's ETag, which If-Match compares;
+// If-None-Match: * only creates.
+func (h *DomainHandler) Put(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return
+	}
+
+	var body PutDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req := CreateDomainRequest{Service: body.Service, Domain: chi.URLParam(r, "domain")}
+	if err := req.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	existing, err := h.store.Domains().GetByDomain(r.Context(), req.Domain)
+	if err != nil {
+		h.logger.Error("failed to check existing domain", "error", err)
+		WriteInternalError(w, "Failed to check domain availability")
+		return
+	}
+	if existing != nil && existing.AppID != appID {
+		WriteError(w, http.StatusConflict, ErrCodeConflict, "Domain is already in use")
+		return
+	}
+	etag := ""
+	if existing != nil {
+		etag = resourceETag(existing)
+	}
+	if !checkPreconditions(w, r, etag) {
+		return
+	}
+	if existing != nil && existing.Service == req.Service {
+		w.Header().Set("ETag", etag)
+		WriteJSON(w, http.StatusOK, existing)
+		return
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to get app", "error", err)
+		WriteInternalError(w, "Failed to verify application")
+		return
+	}
+	if _, ok := findService(app, req.Service); !ok {
+		WriteBadRequest(w, "Service not found in application")
+		return
+	}
+
+	status := http.StatusOK
+	domain := existing
+	if domain == nil {
+		status = http.StatusCreated
+		domain = &models.Domain{
+			AppID:      appID,
+			Service:    req.Service,
+			Domain:     req.Domain,
+			IsWildcard: IsWildcardDomain(req.Domain),
+		}
+		err = h.store.Domains().Create(r.Context(), domain)
+	} else {
+		domain.Service = req.Service
+		err = h.store.Domains().Update(r.Context(), domain)
+	}
+	if err != nil {
+		h.logger.Error("failed to put domain", "error", err)
+		WriteInternalError(w, "Failed to put domain")
+		return
+	}
+
+	// Read the domain back as stored, so its ETag is stable
+	if stored, err := h.store.Domains().Get(r.Context(), domain.ID); err == nil {
+		domain = stored
+	}
+	h.logger.Info("domain put", "app_id", appID, "domain", req.Domain, "service", req.Service)
+	if status == http.StatusCreated {
+		w.Header().Set("Location", "/v1/apps/"+appID+"/domains/"+domain.Domain)
+	}
+	w.Header().Set("ETag", resourceETag(domain))
+	WriteJSON(w, status, domain)
+}
+
 // List handles GET /v1/apps/:appID/domains - lists domains for an app.
 func (h *DomainHandler) List(w http.ResponseWriter, r *http.Request) {
 	appID := middleware.GetResolvedAppID(r.Context())
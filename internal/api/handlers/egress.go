@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// defaultEgressViolationWindow is how far back violations are listed when the
+// request doesn't specify a window.
+const defaultEgressViolationWindow = 7 * 24 * time.Hour
+
+// EgressResponse is a service's egress policy with recent violations.
+type EgressResponse struct {
+	Policy     *models.EgressPolicy      `json:"policy,omitempty"`
+	Violations []*models.EgressViolation `json:"violations"`
+	// TotalBlocked sums the violation counts in the window.
+	TotalBlocked int64     `json:"total_blocked"`
+	Since        time.Time `json:"since"`
+}
+
+// GetEgress handles GET /v1/apps/{appID}/services/{serviceName}/egress - returns
+// the service's egress policy and the outbound connections it blocked. The
+// optional since parameter is a duration such as "24h" (default 7 days).
+func (h *ServiceHandler) GetEgress(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	serviceName := chi.URLParam(r, "serviceName")
+
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return
+	}
+	if serviceName == "" {
+		WriteBadRequest(w, "Service name is required")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return
+	}
+
+	window := defaultEgressViolationWindow
+	if s := r.URL.Query().Get("since"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			WriteBadRequest(w, "Invalid since: must be a positive duration such as \"24h\"")
+			return
+		}
+		window = d
+	}
+
+	// Get the app
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "APP_NOT_FOUND", "Application not found")
+		return
+	}
+
+	// Verify ownership
+	if app.OwnerID != userID {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	// Find the service
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == serviceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+
+	if service == nil {
+		WriteError(w, http.StatusNotFound, "SERVICE_NOT_FOUND", "Service not found")
+		return
+	}
+
+	since := time.Now().Add(-window)
+	violations, err := h.store.EgressViolations().ListByService(r.Context(), app.ID, serviceName, since)
+	if err != nil {
+		h.logger.Error("failed to list egress violations", "app_id", app.ID, "service_name", serviceName, "error", err)
+		WriteInternalError(w, "Failed to list egress violations")
+		return
+	}
+
+	resp := EgressResponse{
+		Policy:     service.Egress,
+		Violations: violations,
+		Since:      since,
+	}
+	if resp.Violations == nil {
+		resp.Violations = []*models.EgressViolation{}
+	}
+	for _, v := range violations {
+		resp.TotalBlocked += v.Count
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
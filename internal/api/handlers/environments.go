@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// EnvironmentRequest is the request body for adding or updating an
+// environment. AppID cannot be changed on update, and Position defaults to
+// after the last environment.
+type EnvironmentRequest struct {
+	Name            string `json:"name"`
+	AppID           string `json:"app_id"`
+	Position        *int   `json:"position,omitempty"`
+	RequireApproval bool   `json:"require_approval"`
+}
+
+// PromoteRequest is the optional request body for promoting a deployment.
+type PromoteRequest struct {
+	// Environment names the environment to promote into; it defaults to the
+	// one after the deployment's own.
+	Environment string `json:"environment,omitempty"`
+}
+
+// ListEnvironments handles GET /v1/apps/{appID}/environments - lists the
+// environments of the app's pipeline in promotion order.
+func (h *PromotionsHandler) ListEnvironments(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	envs, err := h.store.Environments().List(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list environments", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list environments")
+		return
+	}
+	if envs == nil {
+		envs = []*models.Environment{}
+	}
+	WriteJSON(w, http.StatusOK, envs)
+}
+
+// CreateEnvironment handles POST /v1/apps/{appID}/environments - adds an
+// environment to the app's pipeline, backed by an app the caller owns. The
+// backing app may be this app itself.
+func (h *PromotionsHandler) CreateEnvironment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := appIDFromRequest(r)
+
+	var req EnvironmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	existing, err := h.store.Environments().List(ctx, appID)
+	if err != nil {
+		h.logger.Error("failed to list environments", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create environment")
+		return
+	}
+	env := &models.Environment{
+		AppID:           appID,
+		Name:            req.Name,
+		EnvAppID:        req.AppID,
+		RequireApproval: req.RequireApproval,
+	}
+	if req.Position != nil {
+		env.Position = *req.Position
+	} else if len(existing) > 0 {
+		env.Position = existing[len(existing)-1].Position + 1
+	} else {
+		env.Position = 1
+	}
+	if !h.checkEnvironment(w, env, existing) {
+		return
+	}
+
+	backed, err := h.store.Environments().GetByEnvApp(ctx, env.EnvAppID)
+	if err != nil {
+		h.logger.Error("failed to get environment", "error", err, "env_app_id", env.EnvAppID)
+		WriteInternalError(w, "Failed to create environment")
+		return
+	}
+	if backed != nil {
+		WriteConflict(w, "App already backs the "+backed.Name+" environment")
+		return
+	}
+	envApp, err := h.store.Apps().Get(ctx, env.EnvAppID)
+	if err != nil || envApp == nil || envApp.OwnerID != middleware.GetUserID(ctx) {
+		WriteBadRequest(w, "App backing the environment not found")
+		return
+	}
+
+	if err := h.store.Environments().Create(ctx, env); err != nil {
+		h.logger.Error("failed to create environment", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create environment")
+		return
+	}
+
+	h.logger.Info("environment created",
+		"environment_id", env.ID,
+		"app_id", appID,
+		"name", env.Name,
+		"env_app_id", env.EnvAppID,
+	)
+	WriteJSON(w, http.StatusCreated, env)
+}
+
+// UpdateEnvironment handles PUT /v1/apps/{appID}/environments/{environmentID} -
+// renames, reorders or changes the approval requirement of an environment.
+// Promotions already awaiting approval keep waiting for it.
+func (h *PromotionsHandler) UpdateEnvironment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	existing, ok := h.loadEnvironment(w, r)
+	if !ok {
+		return
+	}
+
+	var req EnvironmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.AppID != "" && req.AppID != existing.EnvAppID {
+		WriteBadRequest(w, "The app backing an environment cannot be changed")
+		return
+	}
+
+	others, err := h.store.Environments().List(ctx, existing.AppID)
+	if err != nil {
+		h.logger.Error("failed to list environments", "error", err, "app_id", existing.AppID)
+		WriteInternalError(w, "Failed to update environment")
+		return
+	}
+	env := *existing
+	env.Name = req.Name
+	env.RequireApproval = req.RequireApproval
+	if req.Position != nil {
+		env.Position = *req.Position
+	}
+	if !h.checkEnvironment(w, &env, others) {
+		return
+	}
+
+	if err := h.store.Environments().Update(ctx, &env); err != nil {
+		h.logger.Error("failed to update environment", "error", err, "environment_id", env.ID)
+		WriteInternalError(w, "Failed to update environment")
+		return
+	}
+	WriteJSON(w, http.StatusOK, &env)
+}
+
+// DeleteEnvironment handles DELETE /v1/apps/{appID}/environments/{environmentID} -
+// removes an environment from the pipeline with its promotion history. The
+// app backing it is left untouched.
+func (h *PromotionsHandler) DeleteEnvironment(w http.ResponseWriter, r *http.Request) {
+	env, ok := h.loadEnvironment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Environments().Delete(r.Context(), env.ID); err != nil {
+		h.logger.Error("failed to delete environment", "error", err, "environment_id", env.ID)
+		WriteInternalError(w, "Failed to delete environment")
+		return
+	}
+
+	h.logger.Info("environment deleted", "environment_id", env.ID, "app_id", env.AppID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Promote handles POST /v1/deployments/{deploymentID}/promote - deploys the
+// artifact of a running deployment to the next environment of its app's
+// pipeline, or to the later environment named in the body, without
+// rebuilding it. Promotion into an environment that requires approval waits
+// for an owner to approve it.
+func (h *PromotionsHandler) Promote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deploymentID := chi.URLParam(r, "deploymentID")
+
+	var req PromoteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	deployment, err := h.store.Deployments().Get(ctx, deploymentID)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+	app, err := h.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil || app.OwnerID != middleware.GetUserID(ctx) {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+	if deployment.Status != models.DeploymentStatusRunning || deployment.Artifact == "" || deployment.IsCronRun() {
+		WriteConflict(w, "Only running deployments of a service can be promoted")
+		return
+	}
+
+	from, err := h.store.Environments().GetByEnvApp(ctx, deployment.AppID)
+	if err != nil {
+		h.logger.Error("failed to get environment", "error", err, "app_id", deployment.AppID)
+		WriteInternalError(w, "Failed to promote deployment")
+		return
+	}
+	if from == nil {
+		WriteConflict(w, "The deployment's app is not an environment of a pipeline")
+		return
+	}
+	envs, err := h.store.Environments().List(ctx, from.AppID)
+	if err != nil {
+		h.logger.Error("failed to list environments", "error", err, "app_id", from.AppID)
+		WriteInternalError(w, "Failed to promote deployment")
+		return
+	}
+
+	to := models.NextEnvironment(envs, from.Position)
+	if req.Environment != "" {
+		to = nil
+		for _, e := range envs {
+			if e.Name == req.Environment {
+				to = e
+				break
+			}
+		}
+		if to == nil {
+			WriteBadRequest(w, "Environment "+req.Environment+" not found")
+			return
+		}
+		if to.Position <= from.Position {
+			WriteBadRequest(w, "Deployments can only be promoted to a later environment than "+from.Name)
+			return
+		}
+	}
+	if to == nil {
+		WriteConflict(w, from.Name+" is the last environment of the pipeline")
+		return
+	}
+
+	target, err := h.store.Apps().Get(ctx, to.EnvAppID)
+	if err != nil || target == nil {
+		WriteNotFound(w, "App backing the "+to.Name+" environment not found")
+		return
+	}
+	if !hasService(target, deployment.ServiceName) {
+		WriteBadRequest(w, "Service "+deployment.ServiceName+" not found in the "+to.Name+" environment")
+		return
+	}
+	if !h.checkTargetScope(w, r, target.ID) {
+		return
+	}
+
+	p, err := h.evaluator.Promote(ctx, deployment, to, middleware.GetUserID(ctx))
+	if err != nil {
+		h.logger.Error("failed to promote deployment", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to promote deployment")
+		return
+	}
+	WriteJSON(w, http.StatusCreated, p)
+}
+
+// checkEnvironment validates an environment and checks that its name and
+// position are not taken by another environment of the pipeline.
+func (h *PromotionsHandler) checkEnvironment(w http.ResponseWriter, env *models.Environment, others []*models.Environment) bool {
+	if err := env.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	for _, o := range others {
+		if o.ID == env.ID {
+			continue
+		}
+		if o.Name == env.Name {
+			WriteConflict(w, "The pipeline already has an environment named "+env.Name)
+			return false
+		}
+		if o.Position == env.Position {
+			WriteConflict(w, "Environment "+o.Name+" already has that position")
+			return false
+		}
+	}
+	return true
+}
+
+// loadEnvironment fetches the environment named in the URL, writing an error
+// response if it cannot or if the environment belongs to another app.
+func (h *PromotionsHandler) loadEnvironment(w http.ResponseWriter, r *http.Request) (*models.Environment, bool) {
+	environmentID := chi.URLParam(r, "environmentID")
+	env, err := h.store.Environments().Get(r.Context(), environmentID)
+	if err != nil {
+		h.logger.Error("failed to get environment", "error", err, "environment_id", environmentID)
+		WriteInternalError(w, "Failed to load environment")
+		return nil, false
+	}
+	if env == nil || env.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "Environment not found")
+		return nil, false
+	}
+	return env, true
+}
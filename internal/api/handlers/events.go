@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// EventsHandler streams changes of apps, services, deployments and builds.
+type EventsHandler struct {
+	store  store.Store
+	hub    *events.Hub
+	logger *slog.Logger
+}
+
+// NewEventsHandler creates a new events handler.
+func NewEventsHandler(s store.Store, hub *events.Hub, logger *slog.Logger) *EventsHandler {
+	return &EventsHandler{
+		store:  s,
+		hub:    hub,
+		logger: logger,
+	}
+}
+
+// Stream handles GET /v1/events - streams changes of the apps the user can
+// access, and for instance admins of nodes, via Server-Sent Events. Event
+// types are "<kind>.<action>", e.g. "deployment.updated", and IDs are
+// sequence numbers, so reconnecting clients resume through Last-Event-ID;
+// the after query parameter does the same for new streams. A "reset" event
+// tells the client events were missed and the state it shows should be
+// reloaded. The app_id and kind query parameters, the latter a
+// comma-separated list, narrow the stream.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	after := int64(-1)
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = query.Get("after")
+	}
+	if cursor != "" {
+		n, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || n < 0 {
+			WriteBadRequest(w, "after must be a non-negative event sequence number")
+			return
+		}
+		after = n
+	}
+
+	var kinds map[models.EventKind]bool
+	if k := query.Get("kind"); k != "" {
+		kinds = make(map[models.EventKind]bool)
+		for _, name := range strings.Split(k, ",") {
+			kind := models.EventKind(strings.TrimSpace(name))
+			if !kind.IsValid() || kind == models.EventKindReset {
+				WriteBadRequest(w, "kind must be a comma-separated list of app, service, deployment, build and node")
+				return
+			}
+			kinds[kind] = true
+		}
+	}
+
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	access := &eventAccess{store: h.store, userID: userID, apps: make(map[string]bool)}
+
+	appID := query.Get("app_id")
+	if appID != "" {
+		app, err := h.store.Apps().Get(ctx, appID)
+		if err != nil || app == nil {
+			WriteNotFound(w, "Application not found")
+			return
+		}
+		allowed, err := access.allowed(ctx, app.ID, app.OwnerID, app.OrgID)
+		if err != nil {
+			h.logger.Error("failed to check org membership", "error", err, "org_id", app.OrgID, "user_id", userID)
+			WriteInternalError(w, "Failed to verify access")
+			return
+		}
+		if !allowed {
+			WriteNotFound(w, "Application not found")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, missed, resumed := h.hub.Subscribe(after)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+	if !resumed {
+		writeSSE(w, "", string(models.EventKindReset), map[string]string{"reason": "events were missed"})
+	}
+
+	send := func(e *models.Event) {
+		if e.Kind != models.EventKindReset {
+			if kinds != nil && !kinds[e.Kind] {
+				return
+			}
+			if appID != "" && e.AppID != appID {
+				return
+			}
+			allowed, err := access.event(ctx, e)
+			if err != nil {
+				h.logger.Warn("failed to check access to event", "error", err, "app_id", e.AppID, "user_id", userID)
+			}
+			if !allowed {
+				return
+			}
+		}
+		writeSSE(w, strconv.FormatInt(e.Seq, 10), e.Type(), e)
+	}
+	for _, e := range missed {
+		send(e)
+	}
+	flusher.Flush()
+
+	pingTicker := time.NewTicker(15 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, open := <-sub.C:
+			if !open {
+				// The stream fell too far behind; the client reconnects
+				// and resumes from the events the hub kept
+				return
+			}
+			send(e)
+			flusher.Flush()
+		case <-pingTicker.C:
+			writeSSE(w, "", "ping", map[string]int64{"time": time.Now().Unix()})
+			flusher.Flush()
+		}
+	}
+}
+
+// eventAccess decides which events a stream's user may see, remembering
+// the apps it checked.
+type eventAccess struct {
+	store  store.Store
+	userID string
+	apps   map[string]bool
+	// admin is whether the user is an instance admin, once checked.
+	admin *bool
+}
+
+// event reports whether the user can access the app e is about, or for
+// node events whether the user is an instance admin. App events carry the
+// app's owner and org, so access changes as soon as they do.
+func (a *eventAccess) event(ctx context.Context, e *models.Event) (bool, error) {
+	if e.Kind == models.EventKindNode {
+		if a.admin == nil {
+			user, err := a.store.Users().GetByID(ctx, a.userID)
+			if err != nil {
+				return false, err
+			}
+			admin := user != nil && auth.CheckRolePermission(user.Role, auth.PermissionAdminConsole) == nil
+			a.admin = &admin
+		}
+		return *a.admin, nil
+	}
+	if e.AppID == "" {
+		return false, nil
+	}
+	if e.Kind == models.EventKindApp {
+		return a.allowed(ctx, e.AppID, e.OwnerID, e.OrgID)
+	}
+	if allowed, ok := a.apps[e.AppID]; ok {
+		return allowed, nil
+	}
+	app, err := a.store.Apps().Get(ctx, e.AppID)
+	if err != nil || app == nil {
+		// Events of apps deleted since are not worth a lookup each
+		a.apps[e.AppID] = false
+		return false, nil
+	}
+	return a.allowed(ctx, app.ID, app.OwnerID, app.OrgID)
+}
+
+// allowed reports whether the user owns the app or is a member of its org.
+func (a *eventAccess) allowed(ctx context.Context, appID, ownerID, orgID string) (bool, error) {
+	allowed := ownerID == a.userID
+	if !allowed && orgID != "" {
+		role, err := a.store.Orgs().GetMemberRole(ctx, orgID, a.userID)
+		if err != nil {
+			return false, err
+		}
+		allowed = role != ""
+	}
+	a.apps[appID] = allowed
+	return allowed, nil
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// roleOrgStore gives every user one role in one org.
+type roleOrgStore struct {
+	store.OrgStore
+	orgID string
+	role  models.Role
+}
+
+func (m *roleOrgStore) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	if orgID != m.orgID {
+		return "", nil
+	}
+	return m.role, nil
+}
+
+// eventsMockStore adds orgs and users to the deployment mock store.
+type eventsMockStore struct {
+	*deploymentMockStore
+	orgs  *roleOrgStore
+	users *adminUserStore
+}
+
+func (m *eventsMockStore) Orgs() store.OrgStore   { return m.orgs }
+func (m *eventsMockStore) Users() store.UserStore { return m.users }
+
+// adminUserStore makes one user the instance owner and others members.
+type adminUserStore struct {
+	store.UserStore
+	adminID string
+}
+
+func (m *adminUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	if id == m.adminID {
+		return &store.User{ID: id, Role: store.RoleOwner}, nil
+	}
+	return &store.User{ID: id, Role: store.RoleMember}, nil
+}
+
+func TestEventsStream(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name        string
+		userID      string
+		query       string
+		lastEventID string
+		status      int
+		want        []string
+		notWant     []string
+	}{
+		{"own and org apps", "user-1", "?after=0", "", http.StatusOK,
+			[]string{"id: 1\nevent: app.created", "id: 2\nevent: deployment.updated", `"status":"running"`, "id: 3\nevent: build.updated", "id: 5\nevent: node.updated"},
+			[]string{"id: 4\n"}},
+		{"org member", "user-2", "?after=0", "", http.StatusOK,
+			[]string{"id: 3\nevent: build.updated"}, []string{"id: 1\n", "id: 2\n", "id: 4\n", "id: 5\n"}},
+		{"filtered by kind", "user-1", "?after=0&kind=build", "", http.StatusOK,
+			[]string{"id: 3\nevent: build.updated"}, []string{"id: 1\n", "id: 2\n"}},
+		{"filtered by app", "user-1", "?after=0&app_id=app-1", "", http.StatusOK,
+			[]string{"id: 1\n", "id: 2\n"}, []string{"id: 3\n"}},
+		{"resume with Last-Event-ID", "user-1", "", "2", http.StatusOK,
+			[]string{"id: 3\n"}, []string{"id: 1\n", "id: 2\n"}},
+		{"events from before restart", "user-1", "?after=99", "", http.StatusOK,
+			[]string{"event: reset"}, []string{"id: 1\n"}},
+		{"no replay", "user-1", "", "", http.StatusOK, nil, []string{"id: 1\n", "event: reset"}},
+		{"invalid cursor", "user-1", "?after=x", "", http.StatusBadRequest, nil, nil},
+		{"invalid kind", "user-1", "?kind=pod", "", http.StatusBadRequest, nil, nil},
+		{"inaccessible app", "user-2", "?app_id=app-1", "", http.StatusNotFound, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &eventsMockStore{
+				deploymentMockStore: newDeploymentMockStore(),
+				orgs:                &roleOrgStore{orgID: "org-1", role: models.RoleMember},
+				users:               &adminUserStore{adminID: "user-1"},
+			}
+			st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+			st.appStore.apps["app-2"] = &models.App{ID: "app-2", OwnerID: "user-3", OrgID: "org-1", Name: "shared"}
+			st.appStore.apps["app-3"] = &models.App{ID: "app-3", OwnerID: "user-3", OrgID: "org-2", Name: "other"}
+
+			hub := events.NewHub(st, events.DefaultConfig(), logger)
+			hub.Publish(&models.Event{Kind: models.EventKindApp, Action: models.EventActionCreated, ID: "app-1", AppID: "app-1", OwnerID: "user-1"})
+			hub.Publish(&models.Event{Kind: models.EventKindDeployment, Action: models.EventActionUpdated, ID: "dep-1", AppID: "app-1", Status: "running"})
+			hub.Publish(&models.Event{Kind: models.EventKindBuild, Action: models.EventActionUpdated, ID: "build-1", AppID: "app-2", Status: "succeeded"})
+			hub.Publish(&models.Event{Kind: models.EventKindBuild, Action: models.EventActionUpdated, ID: "build-2", AppID: "app-3", Status: "failed"})
+			hub.Publish(&models.Event{Kind: models.EventKindNode, Action: models.EventActionUpdated, ID: "node-1", Status: "warning"})
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/events"+tt.query, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			ctx, cancel := context.WithTimeout(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID), 50*time.Millisecond)
+			defer cancel()
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			NewEventsHandler(st, hub, logger).Stream(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("stream missing %q:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(body, s) {
+					t.Errorf("stream contains %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
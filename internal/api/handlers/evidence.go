@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/evidence"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/operations"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrCodeEvidenceUnavailable is returned when no evidence signing key is
+// configured.
+const ErrCodeEvidenceUnavailable = "evidence_unavailable"
+
+// EvidenceHandler handles evidence packages compiled for compliance audits.
+type EvidenceHandler struct {
+	store      store.Store
+	compiler   *evidence.Compiler
+	operations *operations.Manager
+	logger     *slog.Logger
+}
+
+// NewEvidenceHandler creates a new evidence handler. Packages are compiled
+// as operations of ops; a nil compiler means no signing key is configured
+// and exports are unavailable.
+func NewEvidenceHandler(st store.Store, compiler *evidence.Compiler, ops *operations.Manager, logger *slog.Logger) *EvidenceHandler {
+	return &EvidenceHandler{
+		store:      st,
+		compiler:   compiler,
+		operations: ops,
+		logger:     logger,
+	}
+}
+
+// CreateEvidenceExportRequest is the request body for exporting an evidence
+// package.
+type CreateEvidenceExportRequest struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// EvidenceSigningKeyResponse is the key evidence packages are verified with.
+type EvidenceSigningKeyResponse struct {
+	KeyID string `json:"key_id"`
+	// PublicKey is the base64-encoded ed25519 public key
+	PublicKey   string `json:"public_key"`
+	PayloadType string `json:"payload_type"`
+}
+
+// Create handles POST /v1/apps/{appID}/evidence-exports - starts compiling
+// an evidence package of the app over a date range and returns its
+// operation. The export's ID is in the operation's export_id result.
+func (h *EvidenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	if !h.authorize(w, r) {
+		return
+	}
+	if h.compiler == nil {
+		WriteError(w, http.StatusServiceUnavailable, ErrCodeEvidenceUnavailable, "Evidence exports are not configured")
+		return
+	}
+
+	var req CreateEvidenceExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := models.ValidateEvidenceRange(req.Since, req.Until); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	appID := appIDFromRequest(r)
+	app, err := h.store.Apps().Get(ctx, appID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "App not found")
+		return
+	}
+
+	since, until := req.Since.UTC(), req.Until.UTC()
+	op := &models.Operation{Kind: models.OperationEvidenceExport, TargetID: app.ID, CreatedBy: userID}
+	err = h.operations.Start(ctx, op, func(ctx context.Context, p *operations.Progress) error {
+		p.Set(ctx, 0, "Collecting evidence")
+		pkg, err := h.compiler.Compile(ctx, app, since, until, userID)
+		if err != nil {
+			return fmt.Errorf("compiling evidence package: %w", err)
+		}
+
+		p.Set(ctx, 80, "Storing package")
+		export := &models.EvidenceExport{
+			AppID:       app.ID,
+			Since:       since,
+			Until:       until,
+			OperationID: op.ID,
+			Counts:      pkg.Counts,
+			SHA256:      pkg.SHA256,
+			SizeBytes:   int64(len(pkg.Archive)),
+			KeyID:       pkg.Manifest.KeyID,
+			CreatedBy:   userID,
+		}
+		if err := h.store.EvidenceExports().Create(ctx, export, pkg.Archive); err != nil {
+			return fmt.Errorf("storing evidence package: %w", err)
+		}
+		p.SetResult("export_id", export.ID)
+		for kind, n := range pkg.Counts {
+			p.SetResult(kind, n)
+		}
+		p.Set(ctx, 100, "Done")
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("failed to start evidence export", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to start evidence export")
+		return
+	}
+	WriteOperation(w, op)
+}
+
+// List handles GET /v1/apps/{appID}/evidence-exports - lists the app's
+// evidence packages, newest first.
+func (h *EvidenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	appID := appIDFromRequest(r)
+	exports, err := h.store.EvidenceExports().ListByApp(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list evidence exports", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list evidence exports")
+		return
+	}
+	if exports == nil {
+		exports = []*models.EvidenceExport{}
+	}
+	WriteJSON(w, http.StatusOK, exports)
+}
+
+// Get handles GET /v1/apps/{appID}/evidence-exports/{exportID} - returns an
+// evidence package's details.
+func (h *EvidenceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	export, ok := h.export(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, export)
+}
+
+// Download handles GET /v1/apps/{appID}/evidence-exports/{exportID}/download -
+// returns an evidence package's archive.
+func (h *EvidenceHandler) Download(w http.ResponseWriter, r *http.Request) {
+	export, ok := h.export(w, r)
+	if !ok {
+		return
+	}
+	archive, err := h.store.EvidenceExports().GetArchive(r.Context(), export.ID)
+	if err != nil {
+		h.logger.Error("failed to get evidence archive", "error", err, "export_id", export.ID)
+		WriteInternalError(w, "Failed to load evidence package")
+		return
+	}
+	if archive == nil {
+		WriteNotFound(w, "Evidence export not found")
+		return
+	}
+
+	filename := fmt.Sprintf("evidence_%s_%s.tar.gz", export.AppID, export.CreatedAt.UTC().Format("20060102_150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	http.ServeContent(w, r, filename, export.CreatedAt, bytes.NewReader(archive))
+}
+
+// Delete handles DELETE /v1/apps/{appID}/evidence-exports/{exportID} -
+// deletes an evidence package.
+func (h *EvidenceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	export, ok := h.export(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.EvidenceExports().Delete(r.Context(), export.ID); err != nil {
+		h.logger.Error("failed to delete evidence export", "error", err, "export_id", export.ID)
+		WriteInternalError(w, "Failed to delete evidence export")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SigningKey handles GET /v1/evidence/signing-key - returns the public key
+// that evidence package manifests are signed with.
+func (h *EvidenceHandler) SigningKey(w http.ResponseWriter, r *http.Request) {
+	if h.compiler == nil {
+		WriteNotFound(w, "Evidence exports are not configured")
+		return
+	}
+	WriteJSON(w, http.StatusOK, EvidenceSigningKeyResponse{
+		KeyID:       h.compiler.KeyID(),
+		PublicKey:   base64.StdEncoding.EncodeToString(h.compiler.PublicKey()),
+		PayloadType: evidence.PayloadType,
+	})
+}
+
+// authorize checks the caller may export the app's evidence, writing a
+// forbidden response if not.
+func (h *EvidenceHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	ctx := r.Context()
+	if err := userHasPermission(ctx, h.store, middleware.GetUserID(ctx), auth.PermissionExportEvidence); err != nil {
+		WriteForbidden(w, "You do not have permission to export evidence")
+		return false
+	}
+	return true
+}
+
+// export loads the export named in the request after checking the caller's
+// permission, writing an error response if it cannot.
+func (h *EvidenceHandler) export(w http.ResponseWriter, r *http.Request) (*models.EvidenceExport, bool) {
+	if !h.authorize(w, r) {
+		return nil, false
+	}
+	exportID := chi.URLParam(r, "exportID")
+	export, err := h.store.EvidenceExports().Get(r.Context(), exportID)
+	if err != nil {
+		h.logger.Error("failed to get evidence export", "error", err, "export_id", exportID)
+		WriteInternalError(w, "Failed to load evidence export")
+		return nil, false
+	}
+	if export == nil || export.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "Evidence export not found")
+		return nil, false
+	}
+	return export, true
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/evidence"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/operations"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// evidenceMockStore adds apps and evidence exports to the operations mock
+// store. The app has no deployments or audit entries.
+type evidenceMockStore struct {
+	*opsMockStore
+	apps     map[string]*models.App
+	exports  map[string]*models.EvidenceExport
+	archives map[string][]byte
+}
+
+func newEvidenceMockStore() *evidenceMockStore {
+	return &evidenceMockStore{
+		opsMockStore: newOpsMockStore(),
+		apps:         map[string]*models.App{"app-1": {ID: "app-1", Name: "shop"}},
+		exports:      make(map[string]*models.EvidenceExport),
+		archives:     make(map[string][]byte),
+	}
+}
+
+func (m *evidenceMockStore) Apps() store.AppStore { return evidenceAppStore{s: m} }
+func (m *evidenceMockStore) Deployments() store.DeploymentStore {
+	return evidenceDeploymentStore{}
+}
+func (m *evidenceMockStore) Audit() store.AuditStore { return evidenceAuditStore{} }
+func (m *evidenceMockStore) EvidenceExports() store.EvidenceExportStore {
+	return evidenceExportStore{s: m}
+}
+
+type evidenceAppStore struct {
+	store.AppStore
+	s *evidenceMockStore
+}
+
+func (a evidenceAppStore) Get(ctx context.Context, id string) (*models.App, error) {
+	return a.s.apps[id], nil
+}
+
+type evidenceDeploymentStore struct{ store.DeploymentStore }
+
+func (evidenceDeploymentStore) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	return nil, nil
+}
+
+type evidenceAuditStore struct{ store.AuditStore }
+
+func (evidenceAuditStore) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	return nil, nil
+}
+
+type evidenceExportStore struct {
+	store.EvidenceExportStore
+	s *evidenceMockStore
+}
+
+func (e evidenceExportStore) Create(ctx context.Context, export *models.EvidenceExport, archive []byte) error {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	export.ID = fmt.Sprintf("export-%d", len(e.s.exports)+1)
+	export.CreatedAt = time.Now()
+	e.s.exports[export.ID] = export
+	e.s.archives[export.ID] = archive
+	return nil
+}
+
+func (e evidenceExportStore) Get(ctx context.Context, id string) (*models.EvidenceExport, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return e.s.exports[id], nil
+}
+
+func (e evidenceExportStore) GetArchive(ctx context.Context, id string) ([]byte, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return e.s.archives[id], nil
+}
+
+func evidenceRequest(method, target, role string, body any, params map[string]string) *http.Request {
+	req := templateRequest(method, target, body, params)
+	ctx := context.WithValue(req.Context(), middleware.OrgRoleKey, models.Role(role))
+	return req.WithContext(ctx)
+}
+
+func TestEvidenceExport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newEvidenceMockStore()
+	st.users["user-1"] = &store.User{ID: "user-1", Role: store.RoleMember}
+	_, key, _ := ed25519.GenerateKey(nil)
+	signer := provenance.NewSigner(key)
+	manager := operations.NewManager(st, operations.DefaultConfig(), logger)
+	h := NewEvidenceHandler(st, evidence.NewCompiler(st, signer), manager, logger)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	body := CreateEvidenceExportRequest{Since: since, Until: since.AddDate(0, 3, 0)}
+
+	// Developers and viewers cannot export evidence
+	for _, role := range []string{"developer", "viewer"} {
+		rec := httptest.NewRecorder()
+		h.Create(rec, evidenceRequest(http.MethodPost, "/v1/apps/app-1/evidence-exports", role, body, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want 403", role, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, evidenceRequest(http.MethodPost, "/v1/apps/app-1/evidence-exports", "admin",
+		CreateEvidenceExportRequest{Since: since, Until: since.AddDate(2, 0, 0)}, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("two-year range: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Create(rec, evidenceRequest(http.MethodPost, "/v1/apps/app-1/evidence-exports", "admin", body, nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	manager.Wait()
+	op, _ := st.Operations().Get(context.Background(), "op-1")
+	if op.Kind != models.OperationEvidenceExport || op.Status != models.OperationSucceeded || op.Result["export_id"] != "export-1" {
+		t.Fatalf("operation = %+v, want succeeded with export-1", op)
+	}
+	export := st.exports["export-1"]
+	if export.AppID != "app-1" || export.KeyID != signer.KeyID() || export.CreatedBy != "user-1" || !export.Since.Equal(since) {
+		t.Errorf("export = %+v", export)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Download(rec, evidenceRequest(http.MethodGet, "/v1/apps/app-1/evidence-exports/export-1/download", "owner", nil,
+		map[string]string{"exportID": "export-1"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("download: status = %d, want 200", rec.Code)
+	}
+	manifest, err := evidence.Verify(rec.Body.Bytes(), signer.PublicKey())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if manifest.AppID != "app-1" || manifest.GeneratedBy != "user-1" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	// Exports of other apps are not found
+	st.exports["export-2"] = &models.EvidenceExport{ID: "export-2", AppID: "app-2"}
+	rec = httptest.NewRecorder()
+	h.Get(rec, evidenceRequest(http.MethodGet, "/v1/apps/app-1/evidence-exports/export-2", "owner", nil,
+		map[string]string{"exportID": "export-2"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other app's export: status = %d, want 404", rec.Code)
+	}
+
+	var signingKey EvidenceSigningKeyResponse
+	rec = httptest.NewRecorder()
+	h.SigningKey(rec, httptest.NewRequest(http.MethodGet, "/v1/evidence/signing-key", nil))
+	if err := json.NewDecoder(rec.Body).Decode(&signingKey); err != nil || signingKey.KeyID != signer.KeyID() {
+		t.Errorf("signing key = %+v, %v", signingKey, err)
+	}
+}
+
+func TestEvidenceExportUnconfigured(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newEvidenceMockStore()
+	h := NewEvidenceHandler(st, nil, operations.NewManager(st, operations.DefaultConfig(), logger), logger)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := httptest.NewRecorder()
+	h.Create(rec, evidenceRequest(http.MethodPost, "/v1/apps/app-1/evidence-exports", "owner",
+		CreateEvidenceExportRequest{Since: since, Until: since.AddDate(0, 1, 0)}, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
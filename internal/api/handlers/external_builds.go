@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Limits on the CI metadata attached to an external build.
+const (
+	maxExternalMetadataKeys     = 32
+	maxExternalMetadataKeyLen   = 64
+	maxExternalMetadataValueLen = 1024
+)
+
+// ExternalBuildRequest is the request body for submitting an artifact built
+// outside Narvana, e.g. by a GitHub Actions workflow, for deployment only.
+type ExternalBuildRequest struct {
+	// Artifact is an OCI image reference, preferably pinned by digest
+	// (registry.example.com/app@sha256:...), or a Nix store path available
+	// from a configured binary cache.
+	Artifact  string `json:"artifact"`
+	GitRef    string `json:"git_ref,omitempty"`
+	GitCommit string `json:"git_commit,omitempty"`
+
+	// Metadata describes where the artifact came from, e.g. the CI provider,
+	// workflow and run URL. It is stored with the build record.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// FreezeOverride lets an owner deploy during an active freeze window
+	FreezeOverride *FreezeOverrideRequest `json:"freeze_override,omitempty"`
+
+	// OnCallAckID is an approved on-call acknowledgement letting the user
+	// deploy outside business hours
+	OnCallAckID string `json:"on_call_ack_id,omitempty"`
+}
+
+// Validate validates the external build request.
+func (r *ExternalBuildRequest) Validate() error {
+	r.Artifact = strings.TrimSpace(r.Artifact)
+	if r.Artifact == "" {
+		return errors.New("artifact is required")
+	}
+	if _, ok := models.BuildTypeForArtifact(r.Artifact); !ok {
+		return errors.New("artifact must be an OCI image reference or a Nix store path")
+	}
+	if len(r.Metadata) > maxExternalMetadataKeys {
+		return fmt.Errorf("metadata can have at most %d keys", maxExternalMetadataKeys)
+	}
+	for k, v := range r.Metadata {
+		if k == "" || len(k) > maxExternalMetadataKeyLen {
+			return fmt.Errorf("metadata keys must be 1-%d characters", maxExternalMetadataKeyLen)
+		}
+		if len(v) > maxExternalMetadataValueLen {
+			return fmt.Errorf("metadata value for %q exceeds %d characters", k, maxExternalMetadataValueLen)
+		}
+	}
+	return nil
+}
+
+// ExternalBuildResponse is returned after an external build is accepted.
+type ExternalBuildResponse struct {
+	Build      *models.BuildJob   `json:"build"`
+	Deployment *models.Deployment `json:"deployment"`
+}
+
+// CreateExternalBuild handles POST /v1/apps/{appID}/services/{serviceName}/builds.
+// It records a succeeded build for an artifact produced by an external CI
+// system and creates a deployment for it that skips the internal builder.
+func (h *DeploymentHandler) CreateExternalBuild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := middleware.GetResolvedAppID(ctx)
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	serviceName := chi.URLParam(r, "serviceName")
+
+	userID := middleware.GetUserID(ctx)
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req ExternalBuildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, appID)
+	if err != nil {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	if app.OwnerID != userID {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == serviceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		WriteNotFound(w, "Service not found")
+		return
+	}
+
+	freeze, ok := h.checkDeployFreeze(w, r, app, req.FreezeOverride)
+	if !ok {
+		return
+	}
+	if !h.checkOnCall(w, r, app, req.OnCallAckID) {
+		return
+	}
+
+	buildType, _ := models.BuildTypeForArtifact(req.Artifact)
+	subject := admissionSubject(app, service, req.GitRef)
+	subject.Deployment.BuildType = buildType
+	subject.Deployment.Artifact = req.Artifact
+	if !h.checkAdmission(w, r, []admission.Subject{subject}) {
+		return
+	}
+
+	gitRef := req.GitRef
+	if gitRef == "" {
+		gitRef = service.GitRef
+	}
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	version, err := h.store.Deployments().GetNextVersion(ctx, appID, service.Name)
+	if err != nil {
+		h.logger.Error("failed to get next version", "error", err, "service", service.Name)
+		WriteInternalError(w, "Failed to determine deployment version")
+		return
+	}
+
+	// The deployment starts as built so the scheduler picks it up directly
+	now := time.Now()
+	deployment := &models.Deployment{
+		ID:          uuid.New().String(),
+		AppID:       appID,
+		ServiceName: service.Name,
+		Version:     version,
+		GitRef:      gitRef,
+		GitCommit:   req.GitCommit,
+		BuildType:   buildType,
+		Artifact:    req.Artifact,
+		Status:      models.DeploymentStatusBuilt,
+		Resources:   service.Resources,
+		Config: &models.RuntimeConfig{
+			Resources:   service.Resources,
+			EnvVars:     service.EnvVars,
+			Ports:       service.Ports,
+			HealthCheck: service.HealthCheck,
+			Egress:      service.Egress,
+		},
+		DependsOn: service.DependsOn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	build := &models.BuildJob{
+		ID:               uuid.New().String(),
+		DeploymentID:     deployment.ID,
+		AppID:            appID,
+		ServiceName:      service.Name,
+		GitURL:           service.GitRepo,
+		GitRef:           gitRef,
+		BuildPath:        service.BuildPath,
+		BuildType:        buildType,
+		Status:           models.BuildStatusSucceeded,
+		CreatedAt:        now,
+		StartedAt:        &now,
+		FinishedAt:       &now,
+		Artifact:         req.Artifact,
+		ExternalMetadata: metadata,
+	}
+
+	// Record the deployment and its build together so the scheduler never
+	// sees a deployment without the build that produced its artifact
+	err = h.store.WithTx(ctx, func(txStore store.Store) error {
+		if err := txStore.Deployments().Create(ctx, deployment); err != nil {
+			return err
+		}
+		return txStore.Builds().Create(ctx, build)
+	})
+	if err != nil {
+		h.logger.Error("failed to record external build", "error", err, "service", service.Name)
+		WriteInternalError(w, "Failed to record external build")
+		return
+	}
+	if freeze != nil {
+		h.recordFreezeOverride(ctx, freeze, deployment, req.FreezeOverride.Reason)
+	}
+
+	h.logger.Info("external build accepted",
+		"app_id", appID,
+		"service_name", service.Name,
+		"build_id", build.ID,
+		"deployment_id", deployment.ID,
+		"artifact", build.Artifact,
+	)
+
+	WriteJSON(w, http.StatusAccepted, ExternalBuildResponse{Build: build, Deployment: deployment})
+}
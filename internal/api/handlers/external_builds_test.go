@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestCreateExternalBuild(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	digest := "ghcr.io/acme/web@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name      string
+		body      ExternalBuildRequest
+		service   string
+		status    int
+		buildType models.BuildType
+	}{
+		{"image digest", ExternalBuildRequest{Artifact: digest, GitCommit: "abc123", Metadata: map[string]string{"ci": "github-actions"}}, "web", http.StatusAccepted, models.BuildTypeOCI},
+		{"nix store path", ExternalBuildRequest{Artifact: "/nix/store/0123456789abcdfghijklmnpqrsvwxyz-web-1.0"}, "web", http.StatusAccepted, models.BuildTypePureNix},
+		{"missing artifact", ExternalBuildRequest{}, "web", http.StatusBadRequest, ""},
+		{"invalid artifact", ExternalBuildRequest{Artifact: "not an artifact"}, "web", http.StatusBadRequest, ""},
+		{"unknown service", ExternalBuildRequest{Artifact: digest}, "api", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newDeploymentMockStore()
+			st.appStore.apps["app-1"] = &models.App{
+				ID:      "app-1",
+				OwnerID: "user-1",
+				Name:    "app",
+				Services: []models.ServiceConfig{{
+					Name:       "web",
+					SourceType: models.SourceTypeGit,
+					GitRepo:    "github.com/acme/web",
+					GitRef:     "main",
+				}},
+			}
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/v1/apps/app-1/services/"+tt.service+"/builds", bytes.NewReader(body))
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("appID", "app-1")
+			rctx.URLParams.Add("serviceName", tt.service)
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			q := newMockQueue()
+			rr := httptest.NewRecorder()
+			NewDeploymentHandler(st, q, logger).CreateExternalBuild(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status != http.StatusAccepted {
+				if len(st.buildStore.builds) != 0 || len(st.deploymentStore.deployments) != 0 {
+					t.Fatal("rejected request created records")
+				}
+				return
+			}
+
+			var resp ExternalBuildResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			d := st.deploymentStore.deployments[resp.Deployment.ID]
+			if d == nil || d.Status != models.DeploymentStatusBuilt || d.Artifact != tt.body.Artifact || d.BuildType != tt.buildType {
+				t.Errorf("unexpected deployment: %+v", d)
+			}
+			if d != nil && (d.GitRef != "main" || d.GitCommit != tt.body.GitCommit) {
+				t.Errorf("deployment git ref/commit = %q/%q", d.GitRef, d.GitCommit)
+			}
+			b := st.buildStore.builds[resp.Build.ID]
+			if b == nil || b.Status != models.BuildStatusSucceeded || b.DeploymentID != resp.Deployment.ID || b.Artifact != tt.body.Artifact {
+				t.Errorf("unexpected build: %+v", b)
+			}
+			if b != nil && b.ExternalMetadata == nil {
+				t.Error("external build has no metadata")
+			}
+			if len(q.jobs) != 0 {
+				t.Error("external build was queued for the builder")
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/federation"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// FederationHandler handles federation peers and the combined view of this
+// instance and its peers.
+type FederationHandler struct {
+	store      store.Store
+	federation *federation.Service
+	logger     *slog.Logger
+}
+
+// NewFederationHandler creates a new federation handler.
+func NewFederationHandler(st store.Store, svc *federation.Service, logger *slog.Logger) *FederationHandler {
+	return &FederationHandler{
+		store:      st,
+		federation: svc,
+		logger:     logger,
+	}
+}
+
+// FederationPeerRequest is the request body for registering a peer.
+type FederationPeerRequest struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	WebURL string `json:"web_url,omitempty"`
+	APIKey string `json:"api_key"`
+}
+
+// ListPeers handles GET /v1/admin/federation/peers - lists the registered
+// peers without their API keys.
+func (h *FederationHandler) ListPeers(w http.ResponseWriter, r *http.Request) {
+	peers, err := h.store.Federation().ListPeers(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list federation peers", "error", err)
+		WriteInternalError(w, "Failed to list federation peers")
+		return
+	}
+
+	redacted := make([]*models.FederationPeer, 0, len(peers))
+	for _, p := range peers {
+		redacted = append(redacted, redactPeer(p))
+	}
+	WriteJSON(w, http.StatusOK, redacted)
+}
+
+// CreatePeer handles POST /v1/admin/federation/peers - registers a peer after
+// checking that its summary can be fetched with the given API key.
+func (h *FederationHandler) CreatePeer(w http.ResponseWriter, r *http.Request) {
+	var req FederationPeerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	peer := &models.FederationPeer{
+		Name:      strings.TrimSpace(req.Name),
+		URL:       strings.TrimRight(strings.TrimSpace(req.URL), "/"),
+		WebURL:    strings.TrimRight(strings.TrimSpace(req.WebURL), "/"),
+		APIKey:    strings.TrimSpace(req.APIKey),
+		CreatedBy: middleware.GetUserID(r.Context()),
+	}
+	if err := peer.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	peers, err := h.store.Federation().ListPeers(ctx)
+	if err != nil {
+		h.logger.Error("failed to list federation peers", "error", err)
+		WriteInternalError(w, "Failed to create federation peer")
+		return
+	}
+	for _, p := range peers {
+		if strings.EqualFold(p.Name, peer.Name) {
+			WriteConflict(w, "A peer with this name already exists")
+			return
+		}
+	}
+
+	if _, err := h.federation.Fetch(ctx, peer); err != nil {
+		WriteBadRequest(w, "Could not fetch the peer's summary: "+err.Error())
+		return
+	}
+
+	if err := h.store.Federation().CreatePeer(ctx, peer); err != nil {
+		h.logger.Error("failed to create federation peer", "error", err, "name", peer.Name)
+		WriteInternalError(w, "Failed to create federation peer")
+		return
+	}
+
+	h.logger.Info("federation peer registered", "peer_id", peer.ID, "name", peer.Name, "url", peer.URL)
+	WriteJSON(w, http.StatusCreated, redactPeer(peer))
+}
+
+// DeletePeer handles DELETE /v1/admin/federation/peers/{peerID} - removes a peer.
+func (h *FederationHandler) DeletePeer(w http.ResponseWriter, r *http.Request) {
+	peerID := chi.URLParam(r, "peerID")
+	peer, err := h.store.Federation().GetPeer(r.Context(), peerID)
+	if err != nil {
+		h.logger.Error("failed to get federation peer", "error", err, "peer_id", peerID)
+		WriteInternalError(w, "Failed to delete federation peer")
+		return
+	}
+	if peer == nil {
+		WriteNotFound(w, "Federation peer not found")
+		return
+	}
+
+	if err := h.store.Federation().DeletePeer(r.Context(), peerID); err != nil {
+		h.logger.Error("failed to delete federation peer", "error", err, "peer_id", peerID)
+		WriteInternalError(w, "Failed to delete federation peer")
+		return
+	}
+
+	h.logger.Info("federation peer removed", "peer_id", peerID, "name", peer.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Summary handles GET /v1/admin/federation/summary - lists this instance's
+// apps, nodes and unfinished deployments for the instances it is a peer of.
+func (h *FederationHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.federation.Summary(r.Context())
+	if err != nil {
+		h.logger.Error("failed to build federation summary", "error", err)
+		WriteInternalError(w, "Failed to build federation summary")
+		return
+	}
+	WriteJSON(w, http.StatusOK, summary)
+}
+
+// View handles GET /v1/admin/federation - combines this instance's summary
+// with its peers'. Rows link to the owning instance, where changes are made.
+func (h *FederationHandler) View(w http.ResponseWriter, r *http.Request) {
+	view, err := h.federation.View(r.Context())
+	if err != nil {
+		h.logger.Error("failed to build federation view", "error", err)
+		WriteInternalError(w, "Failed to build federation view")
+		return
+	}
+	WriteJSON(w, http.StatusOK, view)
+}
+
+// redactPeer returns a copy of a peer without its API key, for API responses.
+func redactPeer(peer *models.FederationPeer) *models.FederationPeer {
+	redacted := *peer
+	redacted.APIKey = ""
+	return &redacted
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/federation"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockFederationStore implements store.FederationStore for testing.
+type mockFederationStore struct {
+	store.FederationStore
+	peers []*models.FederationPeer
+}
+
+func (m *mockFederationStore) CreatePeer(ctx context.Context, peer *models.FederationPeer) error {
+	peer.ID = "peer-" + peer.Name
+	stored := *peer
+	m.peers = append(m.peers, &stored)
+	return nil
+}
+
+func (m *mockFederationStore) ListPeers(ctx context.Context) ([]*models.FederationPeer, error) {
+	return m.peers, nil
+}
+
+// federationMockStore adds federation peers to the deployment mock store.
+type federationMockStore struct {
+	*deploymentMockStore
+	federation *mockFederationStore
+}
+
+func (m *federationMockStore) Federation() store.FederationStore {
+	return m.federation
+}
+
+func TestFederationPeers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer nrv_good" {
+			WriteUnauthorized(w, "Invalid API key")
+			return
+		}
+		WriteJSON(w, http.StatusOK, models.FederationSummary{})
+	}))
+	defer peer.Close()
+
+	st := &federationMockStore{deploymentMockStore: newDeploymentMockStore(), federation: &mockFederationStore{}}
+	h := NewFederationHandler(st, federation.NewService(st, peer.Client(), logger), logger)
+	create := func(req FederationPeerRequest) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CreatePeer(rr, templateRequest(http.MethodPost, "/v1/admin/federation/peers", req, nil))
+		return rr
+	}
+
+	// A key the peer refuses is reported rather than stored
+	if rr := create(FederationPeerRequest{Name: "eu", URL: peer.URL, APIKey: "nrv_bad"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad key: status = %d, want 400", rr.Code)
+	}
+	if rr := create(FederationPeerRequest{Name: "eu", URL: "eu.example.com", APIKey: "nrv_good"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("relative URL: status = %d, want 400", rr.Code)
+	}
+
+	rr := create(FederationPeerRequest{Name: "eu", URL: peer.URL + "/", APIKey: "nrv_good"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.FederationPeer
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.APIKey != "" || created.URL != peer.URL || created.CreatedBy != "user-1" {
+		t.Errorf("created = %+v, want the key redacted and the URL trimmed", created)
+	}
+	if st.federation.peers[0].APIKey != "nrv_good" {
+		t.Error("API key was not stored")
+	}
+
+	if rr := create(FederationPeerRequest{Name: "EU", URL: peer.URL, APIKey: "nrv_good"}); rr.Code != http.StatusConflict {
+		t.Errorf("duplicate name: status = %d, want 409", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ListPeers(rr, templateRequest(http.MethodGet, "/v1/admin/federation/peers", nil, nil))
+	var peers []models.FederationPeer
+	json.NewDecoder(rr.Body).Decode(&peers)
+	if len(peers) != 1 || peers[0].APIKey != "" {
+		t.Errorf("peers = %+v, want one peer without its key", peers)
+	}
+}
@@ -185,20 +185,19 @@ func (h *InvitationsHandler) Accept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate token for the new user
-	token, err := h.authService.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		h.logger.Error("failed to generate token", "error", err)
+	// Sign the new user in
+	resp := map[string]interface{}{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"role":    user.Role,
+	}
+	if err := startSession(r, h.authService, user.ID, user.Email, resp); err != nil {
+		h.logger.Error("failed to start session", "error", err)
 		WriteInternalError(w, "failed to generate token")
 		return
 	}
 
-	WriteJSON(w, http.StatusCreated, map[string]interface{}{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"token":   token,
-		"role":    user.Role,
-	})
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 // GetByToken handles GET /auth/invite/{token} - gets invitation details (public).
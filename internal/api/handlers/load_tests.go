@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// defaultLoadTests is how many tests ListLoadTests returns without a limit.
+const defaultLoadTests = 20
+
+// CreateLoadTestRequest starts a load test of a service.
+type CreateLoadTestRequest struct {
+	// DeploymentID is the deployment to test, e.g. a canary; the service's
+	// latest running deployment when empty
+	DeploymentID string `json:"deployment_id,omitempty"`
+	// WorkerID is the build worker to run the test from; any worker running
+	// load tests when empty
+	WorkerID        string `json:"worker_id,omitempty"`
+	Method          string `json:"method,omitempty"`
+	Path            string `json:"path,omitempty"`
+	RPS             int    `json:"rps"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"`
+}
+
+// ListLoadTests handles GET /v1/apps/{appID}/services/{serviceName}/load-tests -
+// lists the load tests of the service's deployments, newest first.
+func (h *ServiceHandler) ListLoadTests(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultLoadTests
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	tests, err := h.store.LoadTests().ListByService(r.Context(), app.ID, service.Name, limit)
+	if err != nil {
+		h.logger.Error("failed to list load tests", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to list load tests")
+		return
+	}
+	if tests == nil {
+		tests = []*models.LoadTest{}
+	}
+	WriteJSON(w, http.StatusOK, tests)
+}
+
+// GetLoadTest handles GET /v1/apps/{appID}/services/{serviceName}/load-tests/{loadTestID} -
+// returns a load test and, once it finished, its result.
+func (h *ServiceHandler) GetLoadTest(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	test, err := h.store.LoadTests().Get(r.Context(), chi.URLParam(r, "loadTestID"))
+	if err != nil {
+		h.logger.Error("failed to get load test", "error", err)
+		WriteInternalError(w, "Failed to get load test")
+		return
+	}
+	if test == nil || test.AppID != app.ID || test.ServiceName != service.Name {
+		WriteNotFound(w, "Load test not found")
+		return
+	}
+	WriteJSON(w, http.StatusOK, test)
+}
+
+// CreateLoadTest handles POST /v1/apps/{appID}/services/{serviceName}/load-tests -
+// queues a load test of one of the service's running deployments. A build
+// worker claims it and records the result on the test.
+func (h *ServiceHandler) CreateLoadTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateLoadTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	test := &models.LoadTest{
+		AppID:           app.ID,
+		ServiceName:     service.Name,
+		WorkerID:        req.WorkerID,
+		Method:          req.Method,
+		Path:            req.Path,
+		RPS:             req.RPS,
+		DurationSeconds: req.DurationSeconds,
+		TimeoutSeconds:  req.TimeoutSeconds,
+		Status:          models.LoadTestPending,
+	}
+	test.Normalize()
+	if err := test.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	deployments, err := h.store.Deployments().List(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to start load test")
+		return
+	}
+	var target *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName != service.Name || d.IsCronRun() {
+			continue
+		}
+		if req.DeploymentID != "" {
+			if d.ID == req.DeploymentID {
+				target = d
+				break
+			}
+			continue
+		}
+		if d.Status == models.DeploymentStatusRunning && (target == nil || d.Version > target.Version) {
+			target = d
+		}
+	}
+	switch {
+	case target == nil && req.DeploymentID != "":
+		WriteNotFound(w, "Deployment not found for this service")
+		return
+	case target == nil:
+		WriteConflict(w, "Service has no running deployment")
+		return
+	case target.Status != models.DeploymentStatusRunning:
+		WriteConflict(w, "Deployment is not running")
+		return
+	}
+	test.DeploymentID = target.ID
+
+	if test.WorkerID != "" {
+		workers, err := h.store.BuildWorkers().List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list build workers", "error", err)
+			WriteInternalError(w, "Failed to start load test")
+			return
+		}
+		active := false
+		for _, worker := range workers {
+			if worker.ID == test.WorkerID && worker.StateAt(time.Now()) == models.BuildWorkerStateActive {
+				active = true
+				break
+			}
+		}
+		if !active {
+			WriteBadRequest(w, "worker_id must name an active build worker")
+			return
+		}
+	}
+
+	if err := h.store.LoadTests().Create(ctx, test); err != nil {
+		h.logger.Error("failed to create load test", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to start load test")
+		return
+	}
+
+	h.logger.Info("load test queued",
+		"app_id", app.ID,
+		"service_name", service.Name,
+		"deployment_id", test.DeploymentID,
+		"load_test_id", test.ID,
+		"rps", test.RPS,
+		"duration_seconds", test.DurationSeconds,
+		"user_id", middleware.GetUserID(ctx),
+	)
+	WriteJSON(w, http.StatusAccepted, test)
+}
+
+// LoadTests handles GET /v1/deployments/{deploymentID}/load-tests - lists
+// the load tests of a deployment, newest first.
+func (h *DeploymentHandler) LoadTests(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := h.loadOwnedDeployment(w, r, chi.URLParam(r, "deploymentID"))
+	if !ok {
+		return
+	}
+
+	tests, err := h.store.LoadTests().ListByDeployment(r.Context(), deployment.ID)
+	if err != nil {
+		h.logger.Error("failed to list load tests", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to list load tests")
+		return
+	}
+	if tests == nil {
+		tests = []*models.LoadTest{}
+	}
+	WriteJSON(w, http.StatusOK, tests)
+}
@@ -4,17 +4,21 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/archive"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
 // LogHandler handles log-related HTTP requests.
 type LogHandler struct {
-	store  store.Store
-	logger *slog.Logger
+	store    store.Store
+	archiver *archive.Archiver
+	logger   *slog.Logger
 }
 
 // NewLogHandler creates a new log handler.
@@ -25,7 +29,24 @@ func NewLogHandler(st store.Store, logger *slog.Logger) *LogHandler {
 	}
 }
 
-// Get handles GET /v1/apps/:appID/logs - retrieves logs for the most recent deployment.
+// SetArchiver sets the archiver that restores the logs of archived
+// deployments before they are read.
+func (h *LogHandler) SetArchiver(a *archive.Archiver) {
+	h.archiver = a
+}
+
+// logSearchParams are the query parameters that make a logs request a search.
+var logSearchParams = []string{"q", "level", "stream", "since", "until", "cursor"}
+
+// LogSearchResponse is a page of log search results. NextCursor continues
+// the search with older entries; it is empty on the last page.
+type LogSearchResponse struct {
+	Logs       []*models.LogEntry `json:"logs"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// Get handles GET /v1/apps/:appID/logs - retrieves logs for the most recent
+// deployment, or searches the app's logs when given search parameters.
 func (h *LogHandler) Get(w http.ResponseWriter, r *http.Request) {
 	// Use resolved app ID from middleware (handles both UUID and name lookup)
 	appID := middleware.GetResolvedAppID(r.Context())
@@ -45,6 +66,13 @@ func (h *LogHandler) Get(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, param := range logSearchParams {
+		if r.URL.Query().Has(param) {
+			h.search(w, r, appID, limit)
+			return
+		}
+	}
+
 	source := r.URL.Query().Get("source") // "build" or "runtime"
 	deploymentID := r.URL.Query().Get("deployment_id")
 
@@ -80,6 +108,10 @@ func (h *LogHandler) Get(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !rehydrate(w, r, h.archiver, h.logger, deploymentID) {
+		return
+	}
+
 	// Get logs
 	var logs []*models.LogEntry
 	var err error
@@ -105,3 +137,56 @@ func (h *LogHandler) Get(w http.ResponseWriter, r *http.Request) {
 		"logs":          logs,
 	})
 }
+
+// search searches an app's logs across its deployments, newest first, a page
+// at a time. Logs of archived deployments are not searched.
+func (h *LogHandler) search(w http.ResponseWriter, r *http.Request, appID string, limit int) {
+	q := r.URL.Query()
+	filter := models.LogFilter{
+		AppID:        appID,
+		ServiceName:  q.Get("service_name"),
+		DeploymentID: q.Get("deployment_id"),
+		Query:        strings.TrimSpace(q.Get("q")),
+		Source:       q.Get("source"),
+		Stream:       q.Get("stream"),
+		Limit:        limit + 1, // One more to tell whether there is a next page
+	}
+	if levels := q.Get("level"); levels != "" {
+		filter.Levels = strings.Split(levels, ",")
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				WriteBadRequest(w, name+" must be an RFC 3339 timestamp")
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := models.ParseLogCursor(v)
+		if err != nil {
+			WriteBadRequest(w, "Invalid cursor")
+			return
+		}
+		filter.Before = cursor
+	}
+
+	logs, err := h.store.Logs().Search(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to search logs", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to search logs")
+		return
+	}
+
+	resp := LogSearchResponse{Logs: logs}
+	if len(logs) > limit {
+		resp.Logs = logs[:limit]
+		resp.NextCursor = models.CursorOf(logs[limit-1]).String()
+	}
+	if resp.Logs == nil {
+		resp.Logs = []*models.LogEntry{}
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -266,7 +266,8 @@ func isTerminalStatus(status models.DeploymentStatus) bool {
 	switch status {
 	case models.DeploymentStatusRunning,
 		models.DeploymentStatusStopped,
-		models.DeploymentStatusFailed:
+		models.DeploymentStatusFailed,
+		models.DeploymentStatusCanceled:
 		return true
 	default:
 		return false
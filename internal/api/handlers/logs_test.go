@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// searchMockStore serves log searches from a list of entries, newest first.
+type searchMockStore struct {
+	*deploymentMockStore
+	entries []*models.LogEntry
+	filter  models.LogFilter // The last search
+}
+
+func (m *searchMockStore) Logs() store.LogStore { return searchLogs{m: m} }
+
+type searchLogs struct {
+	store.LogStore
+	m *searchMockStore
+}
+
+func (l searchLogs) Search(ctx context.Context, filter models.LogFilter) ([]*models.LogEntry, error) {
+	l.m.filter = filter
+	var result []*models.LogEntry
+	for _, e := range l.m.entries {
+		if len(filter.Levels) > 0 && !slices.Contains(filter.Levels, e.Level) {
+			continue
+		}
+		if filter.Before != nil && !e.Timestamp.Before(filter.Before.Timestamp) {
+			continue
+		}
+		result = append(result, e)
+	}
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func TestLogSearchPages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	st := &searchMockStore{deploymentMockStore: newDeploymentMockStore()}
+	for i, level := range []string{"error", "info", "error", "error"} {
+		st.entries = append(st.entries, &models.LogEntry{
+			ID:        string(rune('a' + i)),
+			Level:     level,
+			Message:   "request timed out",
+			Timestamp: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	h := NewLogHandler(st, logger)
+
+	search := func(query string) (int, LogSearchResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/apps/app-1/logs?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("appID", "app-1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rec := httptest.NewRecorder()
+		h.Get(rec, req)
+		var resp LogSearchResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	code, page := search("q=timed+out&level=error&service_name=api&since=2026-10-01T00:00:00Z&limit=2")
+	if code != http.StatusOK || len(page.Logs) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page: status %d, %d logs, cursor %q; want 2 logs and a cursor", code, len(page.Logs), page.NextCursor)
+	}
+	if f := st.filter; f.AppID != "app-1" || f.ServiceName != "api" || f.Query != "timed out" || !f.Since.Equal(now.Truncate(24*time.Hour)) {
+		t.Errorf("filter = %+v", f)
+	}
+
+	code, page = search("level=error&limit=2&cursor=" + page.NextCursor)
+	if code != http.StatusOK || len(page.Logs) != 1 || page.Logs[0].ID != "d" || page.NextCursor != "" {
+		t.Errorf("last page: status %d, logs %+v, cursor %q; want only d", code, page.Logs, page.NextCursor)
+	}
+
+	for _, query := range []string{"since=yesterday", "cursor=not-a-cursor"} {
+		if code, _ := search(query); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// defaultMetricsRange is the window served when the request doesn't name one.
+const defaultMetricsRange = "1h"
+
+// ServiceMetricsResponse is a service's resource usage series.
+type ServiceMetricsResponse struct {
+	Range       string               `json:"range"`
+	StepSeconds int                  `json:"step_seconds"`
+	From        time.Time            `json:"from"`
+	To          time.Time            `json:"to"`
+	Points      []models.MetricPoint `json:"points"`
+}
+
+// GetMetrics handles GET /v1/apps/{appID}/services/{serviceName}/metrics -
+// returns the CPU, memory and network usage of the service's deployments
+// over the range (15m, 1h, 6h, 24h or 7d; default 1h), downsampled to the
+// range's step. Steps in which no deployment reported are omitted.
+func (h *ServiceHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("range")
+	if name == "" {
+		name = defaultMetricsRange
+	}
+	window, err := models.ParseMetricsRange(name)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	// Align steps so consecutive requests return the same points, and
+	// include the step before the range to seed network rates
+	to := time.Now().UTC().Truncate(window.Step).Add(window.Step)
+	from := to.Add(-window.Duration)
+	rollups, err := h.store.Metrics().ListByService(r.Context(), app.ID, service.Name, from.Add(-window.Step), to)
+	if err != nil {
+		h.logger.Error("failed to list metrics", "error", err, "app_id", app.ID, "service_name", service.Name)
+		WriteInternalError(w, "Failed to get metrics")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, ServiceMetricsResponse{
+		Range:       window.Name,
+		StepSeconds: int(window.Step.Seconds()),
+		From:        from,
+		To:          to,
+		Points:      models.DownsampleMetrics(rollups, from, window.Step),
+	})
+}
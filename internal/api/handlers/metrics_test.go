@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockMetricStore implements store.MetricStore for testing.
+type mockMetricStore struct {
+	store.MetricStore
+	rollups  []*models.MetricRollup
+	from, to time.Time
+}
+
+func (m *mockMetricStore) ListByService(ctx context.Context, appID, serviceName string, from, to time.Time) ([]*models.MetricRollup, error) {
+	m.from, m.to = from, to
+	var result []*models.MetricRollup
+	for _, r := range m.rollups {
+		if r.AppID == appID && r.ServiceName == serviceName && !r.Bucket.Before(from) && r.Bucket.Before(to) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// metricsMockStore adds metrics to the deployment mock store.
+type metricsMockStore struct {
+	*deploymentMockStore
+	metrics *mockMetricStore
+}
+
+func (m *metricsMockStore) Metrics() store.MetricStore {
+	return m.metrics
+}
+
+func TestGetMetrics(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &metricsMockStore{deploymentMockStore: newDeploymentMockStore(), metrics: &mockMetricStore{}}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 2}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"serviceName": "web"}
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	for _, id := range []string{"deploy-1", "deploy-2"} {
+		for i := 3; i >= 1; i-- {
+			st.metrics.rollups = append(st.metrics.rollups, &models.MetricRollup{
+				DeploymentID: id, AppID: "app-1", ServiceName: "web",
+				Bucket:  now.Add(-time.Duration(i) * time.Minute),
+				Samples: 4, CPUPercentSum: 100, CPUPercentMax: 40,
+				MemoryBytesSum: 4 << 20, MemoryBytesMax: 2 << 20,
+				NetworkRxBytes: int64(3-i) * 6000,
+			})
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.GetMetrics(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/services/web/metrics?range=15m", nil, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("get metrics: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp ServiceMetricsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode metrics: %v", err)
+	}
+	if resp.Range != "15m" || resp.StepSeconds != 60 || resp.To.Sub(resp.From) != 15*time.Minute {
+		t.Errorf("response window = %s/%ds %s-%s, want 15m at 60s steps", resp.Range, resp.StepSeconds, resp.From, resp.To)
+	}
+	if !st.metrics.from.Equal(resp.From.Add(-time.Minute)) {
+		t.Errorf("queried from %s, want one step before %s", st.metrics.from, resp.From)
+	}
+	if len(resp.Points) != 3 {
+		t.Fatalf("points = %+v, want 3", resp.Points)
+	}
+	last := resp.Points[2]
+	if last.Deployments != 2 || last.CPUPercent != 50 || last.CPUPercentMax != 80 || last.MemoryBytes != 2<<20 {
+		t.Errorf("last point = %+v, want both deployments summed", last)
+	}
+	if last.NetworkRxRate != 200 {
+		t.Errorf("last point rx rate = %v, want 200 bytes/s", last.NetworkRxRate)
+	}
+
+	rr = httptest.NewRecorder()
+	h.GetMetrics(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/services/web/metrics?range=2h", nil, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown range: status = %d, want 400", rr.Code)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// nodeJoinTokenPrefix marks node join tokens.
+const nodeJoinTokenPrefix = "njt_"
+
+// maxNodeJoinTokenExpiryDays is the longest lifetime a join token can be given.
+const maxNodeJoinTokenExpiryDays = 365
+
+// NodeJoinHandler handles node join tokens and the registration of nodes
+// that join with them.
+type NodeJoinHandler struct {
+	store  store.Store
+	auth   *auth.Service
+	logger *slog.Logger
+}
+
+// NewNodeJoinHandler creates a new node join handler.
+func NewNodeJoinHandler(st store.Store, authSvc *auth.Service, logger *slog.Logger) *NodeJoinHandler {
+	return &NodeJoinHandler{
+		store:  st,
+		auth:   authSvc,
+		logger: logger,
+	}
+}
+
+// CreateNodeJoinTokenRequest represents the request body for creating a join token.
+type CreateNodeJoinTokenRequest struct {
+	Description   string `json:"description,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+// CreateNodeJoinTokenResponse returns a new join token. The token is only
+// shown in this response.
+type CreateNodeJoinTokenResponse struct {
+	*models.NodeJoinToken
+	Token string `json:"token"`
+}
+
+// ListTokens handles GET /v1/settings/node-join-tokens - lists join tokens.
+func (h *NodeJoinHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.store.NodeJoinTokens().List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list node join tokens", "error", err)
+		WriteInternalError(w, "Failed to list join tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []*models.NodeJoinToken{}
+	}
+	WriteJSON(w, http.StatusOK, tokens)
+}
+
+// CreateToken handles POST /v1/settings/node-join-tokens - generates a token
+// new nodes can register with.
+func (h *NodeJoinHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	var req CreateNodeJoinTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if len(req.Description) > 100 {
+		WriteBadRequest(w, "description must be 100 characters or fewer")
+		return
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxNodeJoinTokenExpiryDays {
+		WriteBadRequest(w, "expires_in_days must be between 0 and 365")
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		h.logger.Error("failed to generate node join token", "error", err)
+		WriteInternalError(w, "Failed to create join token")
+		return
+	}
+	raw := nodeJoinTokenPrefix + hex.EncodeToString(b)
+
+	token := &models.NodeJoinToken{
+		Description: req.Description,
+		TokenPrefix: raw[:len(nodeJoinTokenPrefix)+6],
+		CreatedBy:   userID,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := h.store.NodeJoinTokens().Create(ctx, token, auth.HashAPIKey(raw)); err != nil {
+		h.logger.Error("failed to create node join token", "error", err)
+		WriteInternalError(w, "Failed to create join token")
+		return
+	}
+
+	h.logger.Info("node join token created", "token_id", token.ID, "user_id", userID)
+	WriteJSON(w, http.StatusCreated, CreateNodeJoinTokenResponse{NodeJoinToken: token, Token: raw})
+}
+
+// DeleteToken handles DELETE /v1/settings/node-join-tokens/{tokenID} -
+// revokes a join token. Nodes that already joined with it are unaffected.
+func (h *NodeJoinHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	tokenID := chi.URLParam(r, "tokenID")
+
+	tokens, err := h.store.NodeJoinTokens().List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list node join tokens", "error", err)
+		WriteInternalError(w, "Failed to revoke join token")
+		return
+	}
+	found := false
+	for _, t := range tokens {
+		if t.ID == tokenID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		WriteNotFound(w, "Join token not found")
+		return
+	}
+
+	if err := h.store.NodeJoinTokens().Delete(r.Context(), tokenID); err != nil {
+		h.logger.Error("failed to delete node join token", "error", err, "token_id", tokenID)
+		WriteInternalError(w, "Failed to revoke join token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JoinRequest represents the request body a node joins with.
+type JoinRequest struct {
+	Token    string    `json:"token"`
+	NodeInfo *NodeInfo `json:"node_info"`
+}
+
+// JoinResponse returns the joined node's ID and the token its agent
+// authenticates with once the node is approved.
+type JoinResponse struct {
+	NodeID     string            `json:"node_id"`
+	Status     models.NodeStatus `json:"status"`
+	AgentToken string            `json:"agent_token"`
+}
+
+// Join handles POST /v1/nodes/join - registers a node that presents a join
+// token. The node waits in pending until an admin approves it; its agent is
+// refused until then.
+func (h *NodeJoinHandler) Join(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req JoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if !strings.HasPrefix(req.Token, nodeJoinTokenPrefix) {
+		WriteUnauthorized(w, "Invalid join token")
+		return
+	}
+	info := req.NodeInfo
+	if info == nil || info.Hostname == "" || info.Address == "" {
+		WriteBadRequest(w, "node_info.hostname and node_info.address are required")
+		return
+	}
+	if info.GRPCPort <= 0 || info.GRPCPort > 65535 {
+		WriteBadRequest(w, "node_info.grpc_port must be between 1 and 65535")
+		return
+	}
+
+	joinToken, err := h.store.NodeJoinTokens().Authenticate(ctx, auth.HashAPIKey(req.Token))
+	if err != nil {
+		h.logger.Error("failed to authenticate node join token", "error", err)
+		WriteInternalError(w, "Failed to register node")
+		return
+	}
+	if joinToken == nil {
+		WriteUnauthorized(w, "Invalid join token")
+		return
+	}
+
+	// Node IDs are UUIDs; derive one from other identifiers like the agent API does
+	nodeID := info.ID
+	if nodeID == "" {
+		nodeID = uuid.New().String()
+	} else if _, err := uuid.Parse(nodeID); err != nil {
+		nodeID = uuid.NewSHA1(uuid.NameSpaceOID, []byte(info.ID)).String()
+	}
+
+	// A join token only admits new nodes, so it can't be used to take over
+	// one that is already registered
+	if existing, err := h.store.Nodes().Get(ctx, nodeID); err == nil && existing != nil {
+		switch existing.Status {
+		case models.NodeStatusPending:
+			// Joining again refreshes the pending node's details
+		case models.NodeStatusRejected:
+			WriteForbidden(w, "Node was rejected")
+			return
+		default:
+			WriteConflict(w, "Node is already registered")
+			return
+		}
+	}
+
+	node := &models.Node{
+		ID:          nodeID,
+		Hostname:    info.Hostname,
+		Address:     info.Address,
+		GRPCPort:    info.GRPCPort,
+		Status:      models.NodeStatusPending,
+		Resources:   info.Resources,
+		DiskMetrics: info.DiskMetrics,
+	}
+	if err := h.store.Nodes().Register(ctx, node); err != nil {
+		h.logger.Error("failed to register node", "error", err, "node_id", nodeID)
+		WriteInternalError(w, "Failed to register node")
+		return
+	}
+
+	agentToken, err := h.auth.GenerateToken(nodeID, "")
+	if err != nil {
+		h.logger.Error("failed to issue agent token", "error", err, "node_id", nodeID)
+		WriteInternalError(w, "Failed to register node")
+		return
+	}
+
+	h.logger.Info("node joined", "node_id", nodeID, "hostname", info.Hostname, "join_token_id", joinToken.ID)
+	WriteJSON(w, http.StatusAccepted, JoinResponse{NodeID: nodeID, Status: node.Status, AgentToken: agentToken})
+}
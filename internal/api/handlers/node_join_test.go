@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type lifecycleNodeStore struct {
+	store.NodeStore
+	nodes map[string]*models.Node
+}
+
+func (s *lifecycleNodeStore) Register(ctx context.Context, node *models.Node) error {
+	if existing, ok := s.nodes[node.ID]; ok {
+		node.Status = existing.Status
+	}
+	s.nodes[node.ID] = node
+	return nil
+}
+
+func (s *lifecycleNodeStore) Get(ctx context.Context, id string) (*models.Node, error) {
+	return s.nodes[id], nil
+}
+
+func (s *lifecycleNodeStore) UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error {
+	s.nodes[id].Status = status
+	return nil
+}
+
+func (s *lifecycleNodeStore) Delete(ctx context.Context, id string) error {
+	delete(s.nodes, id)
+	return nil
+}
+
+type joinTokenStore struct {
+	store.NodeJoinTokenStore
+	tokens map[string]*models.NodeJoinToken // By hash
+}
+
+func (s *joinTokenStore) Create(ctx context.Context, token *models.NodeJoinToken, tokenHash string) error {
+	token.ID = "token-1"
+	s.tokens[tokenHash] = token
+	return nil
+}
+
+func (s *joinTokenStore) Authenticate(ctx context.Context, tokenHash string) (*models.NodeJoinToken, error) {
+	return s.tokens[tokenHash], nil
+}
+
+// nodeMockStore adds nodes and join tokens to the deployment mock store.
+type nodeMockStore struct {
+	*deploymentMockStore
+	nodes  *lifecycleNodeStore
+	tokens *joinTokenStore
+}
+
+func (m *nodeMockStore) Nodes() store.NodeStore                   { return m.nodes }
+func (m *nodeMockStore) NodeJoinTokens() store.NodeJoinTokenStore { return m.tokens }
+
+func newNodeMockStore() *nodeMockStore {
+	return &nodeMockStore{
+		deploymentMockStore: newDeploymentMockStore(),
+		nodes:               &lifecycleNodeStore{nodes: make(map[string]*models.Node)},
+		tokens:              &joinTokenStore{tokens: make(map[string]*models.NodeJoinToken)},
+	}
+}
+
+func TestNodeJoin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	authSvc := auth.NewService(&auth.Config{JWTSecret: []byte("0123456789abcdef0123456789abcdef"), TokenExpiry: time.Hour}, nil, logger)
+	st := newNodeMockStore()
+	h := NewNodeJoinHandler(st, authSvc, logger)
+	nodes := NewNodeHandler(st, logger)
+
+	rr := httptest.NewRecorder()
+	h.CreateToken(rr, templateRequest(http.MethodPost, "/v1/settings/node-join-tokens", CreateNodeJoinTokenRequest{Description: "rack 4"}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create token status = %d: %s", rr.Code, rr.Body)
+	}
+	var created CreateNodeJoinTokenResponse
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.TokenPrefix != created.Token[:10] || created.CreatedBy != "user-1" {
+		t.Errorf("token = %+v", created)
+	}
+
+	nodeID := "7f4c1a52-3b7e-4a8e-9a51-2f0c9d6b1e10"
+	join := func(token string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.Join(rr, templateRequest(http.MethodPost, "/v1/nodes/join", JoinRequest{
+			Token:    token,
+			NodeInfo: &NodeInfo{ID: nodeID, Hostname: "node-4", Address: "10.0.0.4", GRPCPort: 9090},
+		}, nil))
+		return rr
+	}
+
+	if rr := join("njt_unknown"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token status = %d, want 401", rr.Code)
+	}
+
+	rr = join(created.Token)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("join status = %d: %s", rr.Code, rr.Body)
+	}
+	var joined JoinResponse
+	json.NewDecoder(rr.Body).Decode(&joined)
+	if joined.NodeID != nodeID || joined.Status != models.NodeStatusPending {
+		t.Errorf("join response = %+v", joined)
+	}
+	if claims, err := authSvc.ValidateToken(joined.AgentToken); err != nil || claims.UserID != nodeID {
+		t.Errorf("agent token claims = %+v, %v", claims, err)
+	}
+	if st.nodes.nodes[nodeID].Schedulable() {
+		t.Error("a joined node must not receive deployments before approval")
+	}
+
+	// Pending nodes may join again; approved ones can't be taken over
+	if rr := join(created.Token); rr.Code != http.StatusAccepted {
+		t.Errorf("rejoin while pending status = %d, want 202", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	nodes.Approve(rr, templateRequest(http.MethodPost, "/v1/nodes/"+nodeID+"/approve", nil, map[string]string{"nodeID": nodeID}))
+	if rr.Code != http.StatusOK || st.nodes.nodes[nodeID].Status != models.NodeStatusActive {
+		t.Fatalf("approve status = %d: %s", rr.Code, rr.Body)
+	}
+	if rr := join(created.Token); rr.Code != http.StatusConflict {
+		t.Errorf("join as an active node status = %d, want 409", rr.Code)
+	}
+}
+
+func TestNodeLifecycle(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newNodeMockStore()
+	st.nodes.nodes["node-1"] = &models.Node{ID: "node-1", Status: models.NodeStatusActive}
+	st.deploymentStore.deployments["dep-1"] = &models.Deployment{ID: "dep-1", NodeID: "node-1", Status: models.DeploymentStatusRunning}
+	h := NewNodeHandler(st, logger)
+	params := map[string]string{"nodeID": "node-1"}
+
+	for _, step := range []struct {
+		action string
+		do     func(http.ResponseWriter, *http.Request)
+		code   int
+		status models.NodeStatus
+	}{
+		{"approve", h.Approve, http.StatusConflict, models.NodeStatusActive}, // Already active
+		{"cordon", h.Cordon, http.StatusOK, models.NodeStatusCordoned},
+		{"drain", h.Drain, http.StatusOK, models.NodeStatusDraining},
+		{"reject", h.Reject, http.StatusConflict, models.NodeStatusDraining}, // Only pending nodes
+		{"uncordon", h.Uncordon, http.StatusOK, models.NodeStatusActive},
+	} {
+		rr := httptest.NewRecorder()
+		step.do(rr, templateRequest(http.MethodPost, "/v1/nodes/node-1/"+step.action, nil, params))
+		if rr.Code != step.code || st.nodes.nodes["node-1"].Status != step.status {
+			t.Errorf("%s: status = %d, node %s; want %d, node %s", step.action, rr.Code, st.nodes.nodes["node-1"].Status, step.code, step.status)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.Delete(rr, templateRequest(http.MethodDelete, "/v1/nodes/node-1", nil, params))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("delete with a running deployment status = %d, want 409", rr.Code)
+	}
+
+	st.deploymentStore.deployments["dep-1"].Status = models.DeploymentStatusStopped
+	rr = httptest.NewRecorder()
+	h.Delete(rr, templateRequest(http.MethodDelete, "/v1/nodes/node-1", nil, params))
+	if rr.Code != http.StatusNoContent || st.nodes.nodes["node-1"] != nil {
+		t.Errorf("delete status = %d, want 204", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Cordon(rr, templateRequest(http.MethodPost, "/v1/nodes/node-1/cordon", nil, params))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("cordon a deleted node status = %d, want 404", rr.Code)
+	}
+}
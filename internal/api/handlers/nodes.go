@@ -3,22 +3,39 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
 	"github.com/narvanalabs/control-plane/internal/cleanup"
 	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/operations"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
+// drainPollInterval is how often a drain operation checks the node's
+// deployments. Tests shorten it.
+var drainPollInterval = 5 * time.Second
+
 // NodeHandler handles node-related HTTP requests.
 type NodeHandler struct {
 	store          store.Store
 	cleanupService *cleanup.Service
+	operations     *operations.Manager
 	logger         *slog.Logger
 }
 
+// SetOperations sets the manager drains are followed by. Without one, Drain
+// responds with the node instead of a drain operation.
+func (h *NodeHandler) SetOperations(m *operations.Manager) {
+	h.operations = m
+}
+
 // NewNodeHandler creates a new node handler.
 func NewNodeHandler(st store.Store, logger *slog.Logger) *NodeHandler {
 	return &NodeHandler{
@@ -175,6 +192,32 @@ func (h *NodeHandler) List(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, nodes)
 }
 
+// HealthEvents handles GET /v1/nodes/health-events - lists nodes becoming
+// healthy or unhealthy, newest first, optionally for one node_id.
+func (h *NodeHandler) HealthEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.store.Nodes().ListHealthEvents(r.Context(), r.URL.Query().Get("node_id"), limit)
+	if err != nil {
+		h.logger.Error("failed to list node health events", "error", err)
+		WriteInternalError(w, "Failed to list node health events")
+		return
+	}
+	if events == nil {
+		events = []*models.NodeHealthEvent{}
+	}
+
+	WriteJSON(w, http.StatusOK, events)
+}
+
 // Heartbeat handles POST /v1/nodes/heartbeat - updates node health status.
 func (h *NodeHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 	var req HeartbeatRequest
@@ -466,3 +509,206 @@ func getDiskStatus(usagePercent float64) string {
 	}
 	return "healthy"
 }
+
+// Approve handles POST /v1/nodes/{nodeID}/approve - admits a pending node so
+// its agent may connect and it receives deployments.
+func (h *NodeHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.NodeStatusActive, models.NodeStatusPending, models.NodeStatusRejected)
+}
+
+// Reject handles POST /v1/nodes/{nodeID}/reject - refuses a pending node.
+func (h *NodeHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.NodeStatusRejected, models.NodeStatusPending)
+}
+
+// Cordon handles POST /v1/nodes/{nodeID}/cordon - stops placing new
+// deployments on a node. Its deployments keep running; draining stops.
+func (h *NodeHandler) Cordon(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.NodeStatusCordoned, models.NodeStatusActive, models.NodeStatusDraining)
+}
+
+// Uncordon handles POST /v1/nodes/{nodeID}/uncordon - lets a cordoned or
+// draining node receive deployments again.
+func (h *NodeHandler) Uncordon(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, models.NodeStatusActive, models.NodeStatusCordoned, models.NodeStatusDraining)
+}
+
+// Drain handles POST /v1/nodes/{nodeID}/drain - cordons a node and has the
+// scheduler move its deployments to other nodes. The node becomes cordoned
+// once none are left. It returns the operation following the drain.
+func (h *NodeHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	node, ok := h.setStatus(w, r, models.NodeStatusDraining, models.NodeStatusActive, models.NodeStatusCordoned)
+	if !ok {
+		return
+	}
+	if h.operations == nil {
+		WriteJSON(w, http.StatusOK, node)
+		return
+	}
+
+	// Count the deployments to move before the scheduler starts moving them
+	deployments, err := h.store.Deployments().ListByNode(r.Context(), node.ID)
+	if err != nil {
+		h.logger.Error("failed to list node deployments", "error", err, "node_id", node.ID)
+		WriteInternalError(w, "Node is draining, but its progress cannot be followed")
+		return
+	}
+
+	op := &models.Operation{
+		Kind:      models.OperationNodeDrain,
+		TargetID:  node.ID,
+		CreatedBy: middleware.GetUserID(r.Context()),
+	}
+	if err := h.operations.Start(r.Context(), op, h.followDrain(node.ID, countActive(deployments))); err != nil {
+		h.logger.Error("failed to start drain operation", "error", err, "node_id", node.ID)
+		WriteInternalError(w, "Node is draining, but its progress cannot be followed")
+		return
+	}
+	WriteOperation(w, op)
+}
+
+// followDrain reports the progress of a node's drain as the scheduler moves
+// its total deployments away. It succeeds once the scheduler cordons the
+// emptied node and fails if the node leaves the draining status otherwise,
+// e.g. when it is uncordoned.
+func (h *NodeHandler) followDrain(nodeID string, total int) operations.Func {
+	return func(ctx context.Context, p *operations.Progress) error {
+		ticker := time.NewTicker(drainPollInterval)
+		defer ticker.Stop()
+
+		lastMoved := -1
+		for {
+			node, err := h.store.Nodes().Get(ctx, nodeID)
+			if err != nil {
+				return fmt.Errorf("getting node: %w", err)
+			}
+			if node == nil {
+				return errors.New("node was deleted")
+			}
+			deployments, err := h.store.Deployments().ListByNode(ctx, nodeID)
+			if err != nil {
+				return fmt.Errorf("listing node deployments: %w", err)
+			}
+			remaining := countActive(deployments)
+			total = max(total, remaining)
+
+			switch node.Status {
+			case models.NodeStatusCordoned:
+				p.SetResult("deployments_moved", total-remaining)
+				p.Set(ctx, 100, "Node drained and cordoned")
+				return nil
+			case models.NodeStatusDraining:
+			default:
+				return fmt.Errorf("drain stopped: node is %s", node.Status)
+			}
+
+			if moved := total - remaining; moved != lastMoved {
+				lastMoved = moved
+				percent := 99
+				if total > 0 {
+					percent = min(moved*100/total, 99)
+				}
+				p.Set(ctx, percent, fmt.Sprintf("Moving deployments to other nodes (%d of %d moved)", moved, total))
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// countActive counts the deployments placed on a node that still run there.
+func countActive(deployments []*models.Deployment) int {
+	n := 0
+	for _, d := range deployments {
+		switch d.Status {
+		case models.DeploymentStatusScheduled, models.DeploymentStatusStarting, models.DeploymentStatusRunning:
+			n++
+		}
+	}
+	return n
+}
+
+// transition moves a node to a status if it is in one of the given statuses.
+func (h *NodeHandler) transition(w http.ResponseWriter, r *http.Request, to models.NodeStatus, from ...models.NodeStatus) {
+	if node, ok := h.setStatus(w, r, to, from...); ok {
+		WriteJSON(w, http.StatusOK, node)
+	}
+}
+
+// setStatus moves a node to a status if it is in one of the given statuses
+// and returns it. It writes an error response and returns false otherwise.
+func (h *NodeHandler) setStatus(w http.ResponseWriter, r *http.Request, to models.NodeStatus, from ...models.NodeStatus) (*models.Node, bool) {
+	nodeID := chi.URLParam(r, "nodeID")
+	node, err := h.store.Nodes().Get(r.Context(), nodeID)
+	if err != nil || node == nil {
+		WriteNotFound(w, "Node not found")
+		return nil, false
+	}
+
+	current := node.Status
+	if current == "" {
+		current = models.NodeStatusActive
+	}
+	allowed := false
+	for _, s := range from {
+		if current == s {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		WriteConflict(w, "Node is "+string(current)+" and cannot become "+string(to))
+		return nil, false
+	}
+
+	if err := h.store.Nodes().UpdateStatus(r.Context(), nodeID, to); err != nil {
+		h.logger.Error("failed to update node status", "error", err, "node_id", nodeID)
+		WriteInternalError(w, "Failed to update node")
+		return nil, false
+	}
+	node.Status = to
+
+	h.logger.Info("node status changed",
+		"node_id", nodeID,
+		"from", current,
+		"to", to,
+		"user_id", middleware.GetUserID(r.Context()),
+	)
+	return node, true
+}
+
+// Delete handles DELETE /v1/nodes/{nodeID} - removes a node. Nodes still
+// running deployments must be drained first.
+func (h *NodeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	nodeID := chi.URLParam(r, "nodeID")
+	node, err := h.store.Nodes().Get(ctx, nodeID)
+	if err != nil || node == nil {
+		WriteNotFound(w, "Node not found")
+		return
+	}
+
+	deployments, err := h.store.Deployments().ListByNode(ctx, nodeID)
+	if err != nil {
+		h.logger.Error("failed to list node deployments", "error", err, "node_id", nodeID)
+		WriteInternalError(w, "Failed to delete node")
+		return
+	}
+	if countActive(deployments) > 0 {
+		WriteConflict(w, "Node still runs deployments; drain it first")
+		return
+	}
+
+	if err := h.store.Nodes().Delete(ctx, nodeID); err != nil {
+		h.logger.Error("failed to delete node", "error", err, "node_id", nodeID)
+		WriteInternalError(w, "Failed to delete node")
+		return
+	}
+
+	h.logger.Info("node deleted", "node_id", nodeID, "user_id", middleware.GetUserID(ctx))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// NotificationsHandler handles notification provider management (admin only).
+type NotificationsHandler struct {
+	store      store.Store
+	dispatcher *notifications.Dispatcher
+	logger     *slog.Logger
+}
+
+// NewNotificationsHandler creates a new notifications handler.
+func NewNotificationsHandler(st store.Store, dispatcher *notifications.Dispatcher, logger *slog.Logger) *NotificationsHandler {
+	return &NotificationsHandler{
+		store:      st,
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+// NotificationProviderRequest is the request body for creating or updating a
+// provider. Secret config fields left empty on update keep their stored value.
+type NotificationProviderRequest struct {
+	Type    models.NotificationProviderType `json:"type"`
+	Name    string                          `json:"name"`
+	Enabled *bool                           `json:"enabled,omitempty"`
+	Config  map[string]string               `json:"config"`
+	Events  []models.NotificationEventType  `json:"events"`
+}
+
+// TestNotificationResponse reports the outcome of a test send.
+type TestNotificationResponse struct {
+	Delivered bool   `json:"delivered"`
+	Error     string `json:"error,omitempty"`
+}
+
+// List handles GET /v1/notifications/providers - lists providers with secrets redacted.
+func (h *NotificationsHandler) List(w http.ResponseWriter, r *http.Request) {
+	providers, err := h.store.Notifications().ListProviders(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list notification providers", "error", err)
+		WriteInternalError(w, "Failed to list notification providers")
+		return
+	}
+
+	redacted := make([]*models.NotificationProvider, 0, len(providers))
+	for _, p := range providers {
+		redacted = append(redacted, h.dispatcher.Redact(p))
+	}
+	WriteJSON(w, http.StatusOK, redacted)
+}
+
+// Create handles POST /v1/notifications/providers - adds a provider.
+func (h *NotificationsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req NotificationProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	provider := &models.NotificationProvider{
+		Type:    req.Type,
+		Name:    strings.TrimSpace(req.Name),
+		Enabled: req.Enabled == nil || *req.Enabled,
+		Config:  req.Config,
+		Events:  req.Events,
+	}
+	if err := h.dispatcher.Validate(provider); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.store.Notifications().CreateProvider(r.Context(), provider); err != nil {
+		h.logger.Error("failed to create notification provider", "error", err)
+		WriteInternalError(w, "Failed to create notification provider")
+		return
+	}
+
+	h.logger.Info("notification provider created", "provider_id", provider.ID, "type", provider.Type)
+	WriteJSON(w, http.StatusCreated, h.dispatcher.Redact(provider))
+}
+
+// Update handles PUT /v1/notifications/providers/{providerID} - edits a provider.
+// The provider type cannot be changed.
+func (h *NotificationsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	existing, ok := h.loadProvider(w, r)
+	if !ok {
+		return
+	}
+
+	var req NotificationProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	provider := *existing
+	provider.Name = strings.TrimSpace(req.Name)
+	if req.Enabled != nil {
+		provider.Enabled = *req.Enabled
+	}
+	provider.Config = req.Config
+	provider.Events = req.Events
+	h.dispatcher.MergeSecrets(&provider, existing)
+	if err := h.dispatcher.Validate(&provider); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.store.Notifications().UpdateProvider(ctx, &provider); err != nil {
+		h.logger.Error("failed to update notification provider", "error", err, "provider_id", provider.ID)
+		WriteInternalError(w, "Failed to update notification provider")
+		return
+	}
+	WriteJSON(w, http.StatusOK, h.dispatcher.Redact(&provider))
+}
+
+// Delete handles DELETE /v1/notifications/providers/{providerID} - removes a
+// provider and drops its queued deliveries.
+func (h *NotificationsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.loadProvider(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Notifications().DeleteProvider(r.Context(), provider.ID); err != nil {
+		h.logger.Error("failed to delete notification provider", "error", err, "provider_id", provider.ID)
+		WriteInternalError(w, "Failed to delete notification provider")
+		return
+	}
+
+	h.logger.Info("notification provider deleted", "provider_id", provider.ID, "type", provider.Type)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Test handles POST /v1/notifications/providers/{providerID}/test - sends a
+// test notification immediately, without retries, and reports the result.
+func (h *NotificationsHandler) Test(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	provider, ok := h.loadProvider(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	event := &models.NotificationEvent{
+		Type:       models.NotificationTest,
+		Message:    "Notifications from Narvana are working.",
+		OccurredAt: now,
+	}
+
+	resp := TestNotificationResponse{Delivered: true}
+	if err := h.dispatcher.Send(ctx, provider, event); err != nil {
+		resp = TestNotificationResponse{Error: err.Error()}
+	}
+	if err := h.store.Notifications().RecordProviderResult(ctx, provider.ID, now, resp.Error); err != nil {
+		h.logger.Error("failed to record notification provider result", "error", err, "provider_id", provider.ID)
+	}
+
+	if !resp.Delivered {
+		WriteJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// loadProvider fetches the provider named in the URL, writing an error response if it cannot.
+func (h *NotificationsHandler) loadProvider(w http.ResponseWriter, r *http.Request) (*models.NotificationProvider, bool) {
+	providerID := chi.URLParam(r, "providerID")
+	provider, err := h.store.Notifications().GetProvider(r.Context(), providerID)
+	if err != nil {
+		h.logger.Error("failed to get notification provider", "error", err, "provider_id", providerID)
+		WriteInternalError(w, "Failed to load notification provider")
+		return nil, false
+	}
+	if provider == nil {
+		WriteNotFound(w, "Notification provider not found")
+		return nil, false
+	}
+	return provider, true
+}
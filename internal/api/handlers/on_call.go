@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/oncall"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrCodeOnCallRequired is returned when a deploy outside business hours is
+// blocked by an on-call gate.
+const ErrCodeOnCallRequired = "on_call_required"
+
+const (
+	defaultOnCallAcks = 50
+	maxOnCallAcks     = 500
+)
+
+// OnCallHandler handles org on-call gate and acknowledgement HTTP requests.
+type OnCallHandler struct {
+	store   store.Store
+	checker *oncall.Checker
+	logger  *slog.Logger
+}
+
+// NewOnCallHandler creates a new on-call handler.
+func NewOnCallHandler(st store.Store, logger *slog.Logger) *OnCallHandler {
+	return &OnCallHandler{
+		store:   st,
+		checker: oncall.NewChecker(st, nil, logger),
+		logger:  logger,
+	}
+}
+
+// OnCallGateRequest is the request body for creating or updating an on-call gate.
+type OnCallGateRequest struct {
+	Name     string `json:"name"`
+	Match    string `json:"match,omitempty"`
+	Provider string `json:"provider"`
+	// ScheduleID is the PagerDuty schedule ID or Opsgenie schedule ID.
+	ScheduleID string `json:"schedule_id"`
+	// APIToken is a PagerDuty REST API key or Opsgenie API key able to read
+	// the schedule. It is never returned; leave it empty in an update to keep
+	// the current one.
+	APIToken      string   `json:"api_token,omitempty"`
+	Requirement   string   `json:"requirement,omitempty"`
+	BusinessDays  []string `json:"business_days,omitempty"`
+	BusinessStart string   `json:"business_start,omitempty"`
+	BusinessEnd   string   `json:"business_end,omitempty"`
+	Timezone      string   `json:"timezone,omitempty"`
+	Enabled       *bool    `json:"enabled,omitempty"` // Defaults to true
+}
+
+// apply copies the request onto a gate.
+func (req *OnCallGateRequest) apply(g *models.OnCallGate) {
+	g.Name = req.Name
+	g.Match = strings.TrimSpace(req.Match)
+	g.Provider = models.OnCallProvider(req.Provider)
+	g.ScheduleID = req.ScheduleID
+	if req.APIToken != "" {
+		g.APIToken = req.APIToken
+	}
+	g.Requirement = models.OnCallRequirement(req.Requirement)
+	g.BusinessDays = req.BusinessDays
+	g.BusinessStart = req.BusinessStart
+	g.BusinessEnd = req.BusinessEnd
+	g.Timezone = req.Timezone
+	g.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// OnCallAckRequest is the request body for asking whoever is on call to
+// acknowledge a deploy outside business hours.
+type OnCallAckRequest struct {
+	AppID  string `json:"app_id"`
+	Reason string `json:"reason"`
+}
+
+// OnCallAckResponse is an acknowledgement request and who can answer it.
+type OnCallAckResponse struct {
+	*models.OnCallAck
+	OnCall []string `json:"on_call"`
+}
+
+// ListGates handles GET /v1/orgs/{orgID}/on-call-gates - lists an org's on-call gates.
+func (h *OnCallHandler) ListGates(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	gates, err := h.store.OnCall().ListGates(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list on-call gates", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list on-call gates")
+		return
+	}
+	if gates == nil {
+		gates = []*models.OnCallGate{}
+	}
+	WriteJSON(w, http.StatusOK, gates)
+}
+
+// CreateGate handles POST /v1/orgs/{orgID}/on-call-gates - creates an on-call gate (owners only).
+func (h *OnCallHandler) CreateGate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requirePermission(w, r) {
+		return
+	}
+
+	var req OnCallGateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	gate := &models.OnCallGate{OrgID: orgID, CreatedBy: middleware.GetUserID(ctx)}
+	req.apply(gate)
+	if !validateOnCallGate(w, gate) {
+		return
+	}
+
+	if err := h.store.OnCall().CreateGate(ctx, gate); err != nil {
+		h.logger.Error("failed to create on-call gate", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to create on-call gate")
+		return
+	}
+
+	h.logger.Info("on-call gate created",
+		"gate_id", gate.ID,
+		"org_id", orgID,
+		"provider", gate.Provider,
+		"requirement", gate.Requirement,
+		"user_id", gate.CreatedBy,
+	)
+	WriteJSON(w, http.StatusCreated, gate)
+}
+
+// UpdateGate handles PUT /v1/orgs/{orgID}/on-call-gates/{gateID} - replaces an on-call gate (owners only).
+func (h *OnCallHandler) UpdateGate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requirePermission(w, r) {
+		return
+	}
+
+	gate, ok := h.findGate(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	var req OnCallGateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.apply(gate)
+	if !validateOnCallGate(w, gate) {
+		return
+	}
+
+	if err := h.store.OnCall().UpdateGate(ctx, gate); err != nil {
+		h.logger.Error("failed to update on-call gate", "error", err, "gate_id", gate.ID)
+		WriteInternalError(w, "Failed to update on-call gate")
+		return
+	}
+
+	h.logger.Info("on-call gate updated", "gate_id", gate.ID, "org_id", orgID)
+	WriteJSON(w, http.StatusOK, gate)
+}
+
+// DeleteGate handles DELETE /v1/orgs/{orgID}/on-call-gates/{gateID} - removes an on-call gate (owners only).
+func (h *OnCallHandler) DeleteGate(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requirePermission(w, r) {
+		return
+	}
+
+	gate, ok := h.findGate(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	if err := h.store.OnCall().DeleteGate(r.Context(), gate.ID); err != nil {
+		h.logger.Error("failed to delete on-call gate", "error", err, "gate_id", gate.ID)
+		WriteInternalError(w, "Failed to delete on-call gate")
+		return
+	}
+
+	h.logger.Info("on-call gate deleted", "gate_id", gate.ID, "org_id", orgID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestAck handles POST /v1/orgs/{orgID}/on-call-gates/{gateID}/acks - asks
+// whoever is on call to acknowledge a deploy of an app the gate covers.
+func (h *OnCallHandler) RequestAck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+
+	gate, ok := h.findGate(w, r, orgID)
+	if !ok {
+		return
+	}
+	if gate.Requirement != models.OnCallRequireAck {
+		WriteBadRequest(w, "This gate only lets whoever is on call deploy")
+		return
+	}
+
+	var req OnCallAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		WriteBadRequest(w, "A reason is required")
+		return
+	}
+	if len(req.Reason) > models.MaxOnCallAckReasonLength {
+		WriteBadRequest(w, "Reason must be 500 characters or fewer")
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, req.AppID)
+	if err != nil || app == nil || app.OrgID != orgID {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	if matched, _ := oncall.Matches(gate, app); !matched {
+		WriteBadRequest(w, "The gate does not cover this application")
+		return
+	}
+
+	onCall, err := h.checker.OnCall(ctx, gate)
+	if err != nil {
+		h.logger.Warn("failed to read on-call schedule", "error", err, "gate_id", gate.ID)
+		WriteError(w, http.StatusBadGateway, ErrCodeOnCallRequired, "Failed to read the on-call schedule: "+err.Error())
+		return
+	}
+
+	ack := &models.OnCallAck{
+		OrgID:       orgID,
+		GateID:      gate.ID,
+		AppID:       app.ID,
+		RequestedBy: middleware.GetUserID(ctx),
+		Reason:      req.Reason,
+		Status:      models.OnCallAckPending,
+		ExpiresAt:   time.Now().Add(models.OnCallAckTTL),
+	}
+	if err := h.store.OnCall().CreateAck(ctx, ack); err != nil {
+		h.logger.Error("failed to create on-call ack", "error", err, "gate_id", gate.ID)
+		WriteInternalError(w, "Failed to request acknowledgement")
+		return
+	}
+
+	h.logger.Info("on-call acknowledgement requested",
+		"ack_id", ack.ID,
+		"gate_id", gate.ID,
+		"app_id", app.ID,
+		"user_id", ack.RequestedBy,
+	)
+	WriteJSON(w, http.StatusCreated, OnCallAckResponse{OnCallAck: ack, OnCall: onCall})
+}
+
+// ListAcks handles GET /v1/orgs/{orgID}/on-call-acks - lists recent acknowledgement requests.
+func (h *OnCallHandler) ListAcks(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+
+	limit := defaultOnCallAcks
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxOnCallAcks {
+			WriteBadRequest(w, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	acks, err := h.store.OnCall().ListAcks(r.Context(), orgID, limit)
+	if err != nil {
+		h.logger.Error("failed to list on-call acks", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list acknowledgement requests")
+		return
+	}
+	if acks == nil {
+		acks = []*models.OnCallAck{}
+	}
+	WriteJSON(w, http.StatusOK, acks)
+}
+
+// ApproveAck handles POST /v1/orgs/{orgID}/on-call-acks/{ackID}/approve - lets
+// the requester deploy for the next hour. Only whoever is on call can approve.
+func (h *OnCallHandler) ApproveAck(w http.ResponseWriter, r *http.Request) {
+	h.decideAck(w, r, models.OnCallAckApproved)
+}
+
+// RejectAck handles POST /v1/orgs/{orgID}/on-call-acks/{ackID}/reject - turns
+// down an acknowledgement request. Only whoever is on call can reject.
+func (h *OnCallHandler) RejectAck(w http.ResponseWriter, r *http.Request) {
+	h.decideAck(w, r, models.OnCallAckRejected)
+}
+
+// decideAck answers a pending acknowledgement request on behalf of the
+// current user, who must be on call in the gate's schedule.
+func (h *OnCallHandler) decideAck(w http.ResponseWriter, r *http.Request, status models.OnCallAckStatus) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	ackID := chi.URLParam(r, "ackID")
+	userID := middleware.GetUserID(ctx)
+
+	ack, err := h.store.OnCall().GetAck(ctx, ackID)
+	if err != nil {
+		h.logger.Error("failed to get on-call ack", "error", err, "ack_id", ackID)
+		WriteInternalError(w, "Failed to load acknowledgement request")
+		return
+	}
+	if ack == nil || ack.OrgID != orgID {
+		WriteNotFound(w, "Acknowledgement request not found")
+		return
+	}
+	now := time.Now()
+	if ack.Status != models.OnCallAckPending || ack.Expired(now) {
+		WriteConflict(w, "Acknowledgement request has already been answered or has expired")
+		return
+	}
+	if ack.RequestedBy == userID {
+		WriteForbidden(w, "You cannot acknowledge your own request")
+		return
+	}
+
+	gate, err := h.store.OnCall().GetGate(ctx, ack.GateID)
+	if err != nil || gate == nil {
+		h.logger.Error("failed to get on-call gate", "error", err, "gate_id", ack.GateID)
+		WriteInternalError(w, "Failed to load on-call gate")
+		return
+	}
+	user, err := h.store.Users().GetByID(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get user", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to load user")
+		return
+	}
+	onCall, _, err := h.checker.IsOnCall(ctx, gate, user.Email)
+	if err != nil {
+		h.logger.Warn("failed to read on-call schedule", "error", err, "gate_id", gate.ID)
+		WriteError(w, http.StatusBadGateway, ErrCodeOnCallRequired, "Failed to read the on-call schedule: "+err.Error())
+		return
+	}
+	if !onCall {
+		WriteForbidden(w, "Only whoever is on call can answer acknowledgement requests")
+		return
+	}
+
+	ack.Status = status
+	ack.DecidedBy = userID
+	ack.DecidedAt = &now
+	if status == models.OnCallAckApproved {
+		ack.ExpiresAt = now.Add(models.OnCallAckTTL)
+	}
+	if err := h.store.OnCall().DecideAck(ctx, ack); err != nil {
+		h.logger.Error("failed to decide on-call ack", "error", err, "ack_id", ack.ID)
+		WriteInternalError(w, "Failed to answer acknowledgement request")
+		return
+	}
+
+	h.logger.Info("on-call acknowledgement answered",
+		"ack_id", ack.ID,
+		"gate_id", gate.ID,
+		"status", status,
+		"user_id", userID,
+	)
+	WriteJSON(w, http.StatusOK, ack)
+}
+
+// findGate loads the gate in the URL, writing a not found response unless it belongs to the org.
+func (h *OnCallHandler) findGate(w http.ResponseWriter, r *http.Request, orgID string) (*models.OnCallGate, bool) {
+	gateID := chi.URLParam(r, "gateID")
+	gate, err := h.store.OnCall().GetGate(r.Context(), gateID)
+	if err != nil {
+		h.logger.Error("failed to get on-call gate", "error", err, "gate_id", gateID)
+		WriteInternalError(w, "Failed to load on-call gate")
+		return nil, false
+	}
+	if gate == nil || gate.OrgID != orgID {
+		WriteNotFound(w, "On-call gate not found")
+		return nil, false
+	}
+	return gate, true
+}
+
+// requirePermission writes a forbidden response unless the current user can
+// manage the org's deploy policies, which on-call gates are part of.
+func (h *OnCallHandler) requirePermission(w http.ResponseWriter, r *http.Request) bool {
+	if err := userHasPermission(r.Context(), h.store, middleware.GetUserID(r.Context()), auth.PermissionManageAdmissionPolicies); err != nil {
+		WriteForbidden(w, "Only owners can manage on-call gates")
+		return false
+	}
+	return true
+}
+
+// validateOnCallGate validates a gate and compiles its match expression,
+// writing a bad request response if either fails.
+func validateOnCallGate(w http.ResponseWriter, gate *models.OnCallGate) bool {
+	if err := gate.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	if gate.Match != "" {
+		if _, err := admission.Compile(gate.Match); err != nil {
+			WriteBadRequest(w, "Invalid match expression: "+err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+// checkOnCall enforces the app's org on-call gates on deploys outside
+// business hours. It writes an error response and returns false if a gate
+// blocks the current user; ackID names an approved acknowledgement.
+func (h *DeploymentHandler) checkOnCall(w http.ResponseWriter, r *http.Request, app *models.App, ackID string) bool {
+	ctx := r.Context()
+	block, err := h.onCall.Check(ctx, app, middleware.GetUserID(ctx), ackID)
+	if err != nil {
+		h.logger.Error("failed to check on-call gates", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to check on-call gates")
+		return false
+	}
+	if block == nil {
+		return true
+	}
+
+	message := "Deploys outside business hours are limited by \"" + block.Gate.Name + "\" to whoever is on call"
+	switch {
+	case block.Error != "":
+		message = "Could not read the on-call schedule for \"" + block.Gate.Name + "\", so deploys outside business hours are blocked"
+	case block.Requirement == models.OnCallRequireAck:
+		message += "; request their acknowledgement and deploy with on_call_ack_id"
+	}
+	WriteErrorWithDetails(w, http.StatusConflict, ErrCodeOnCallRequired, message, block)
+	return false
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/oncall"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockOnCallStore keeps on-call gates and acknowledgements in memory.
+type mockOnCallStore struct {
+	store.OnCallStore
+	gates []*models.OnCallGate
+	acks  map[string]*models.OnCallAck
+}
+
+func (m *mockOnCallStore) ListGates(ctx context.Context, orgID string) ([]*models.OnCallGate, error) {
+	var result []*models.OnCallGate
+	for _, g := range m.gates {
+		if g.OrgID == orgID {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOnCallStore) GetGate(ctx context.Context, id string) (*models.OnCallGate, error) {
+	for _, g := range m.gates {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOnCallStore) GetAck(ctx context.Context, id string) (*models.OnCallAck, error) {
+	return m.acks[id], nil
+}
+
+func (m *mockOnCallStore) DecideAck(ctx context.Context, ack *models.OnCallAck) error {
+	m.acks[ack.ID] = ack
+	return nil
+}
+
+// emailUserStore returns owners with the given emails.
+type emailUserStore struct {
+	store.UserStore
+	emails map[string]string
+}
+
+func (m *emailUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return &store.User{ID: id, Email: m.emails[id], Role: store.RoleOwner}, nil
+}
+
+// onCallMockStore adds on-call gates and users with emails to the freeze mock store.
+type onCallMockStore struct {
+	*freezeMockStore
+	onCall *mockOnCallStore
+	emails *emailUserStore
+}
+
+func (m *onCallMockStore) OnCall() store.OnCallStore { return m.onCall }
+func (m *onCallMockStore) Users() store.UserStore    { return m.emails }
+
+// rewriteTransport sends every request to a test server.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newOnCallMockStore creates a store with app-1 covered by an on_call_ack
+// gate whose business days exclude today and tomorrow, so deploys made
+// during the test are always outside business hours.
+func newOnCallMockStore() *onCallMockStore {
+	now := time.Now().UTC()
+	var days []string
+	for _, day := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+		if day != strings.ToLower(now.Weekday().String()) && day != strings.ToLower(now.AddDate(0, 0, 1).Weekday().String()) {
+			days = append(days, day)
+		}
+	}
+	st := &onCallMockStore{
+		freezeMockStore: &freezeMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			freezes:             &mockDeployFreezeStore{},
+		},
+		onCall: &mockOnCallStore{
+			gates: []*models.OnCallGate{{
+				ID: "gate-1", OrgID: "org-1", Name: "Production", Match: `app.name.endsWith("-production")`,
+				Provider: models.OnCallProviderPagerDuty, ScheduleID: "P1", APIToken: "t",
+				Requirement: models.OnCallRequireAck, BusinessDays: days,
+				BusinessStart: "09:00", BusinessEnd: "17:00", Enabled: true,
+			}},
+			acks: map[string]*models.OnCallAck{},
+		},
+		emails: &emailUserStore{emails: map[string]string{
+			"user-1": "dev@example.com",
+			"user-2": "oncall@example.com",
+			"user-3": "other@example.com",
+		}},
+	}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:      "app-1",
+		OrgID:   "org-1",
+		OwnerID: "user-1",
+		Name:    "shop-production",
+		Services: []models.ServiceConfig{{
+			Name:       "web",
+			SourceType: models.SourceTypeImage,
+			Image:      "nginx:1.27",
+		}},
+	}
+	return st
+}
+
+// newPagerDutyServer serves a schedule with oncall@example.com on call and
+// returns a checker reading it.
+func newPagerDutyServer(t *testing.T, st store.Store, logger *slog.Logger) *oncall.Checker {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"oncalls":[{"user":{"email":"oncall@example.com"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return oncall.NewChecker(st, &http.Client{Transport: rewriteTransport{target: target}}, logger)
+}
+
+func withUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+}
+
+func TestCreateDeployment_OnCall(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name   string
+		userID string
+		ackID  string
+		status int
+	}{
+		{"on call deploys", "user-2", "", http.StatusAccepted},
+		{"others are blocked", "user-1", "", http.StatusConflict},
+		{"approved ack lets others deploy", "user-1", "ack-1", http.StatusAccepted},
+		{"ack of another user is ignored", "user-3", "ack-1", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newOnCallMockStore()
+			st.onCall.acks["ack-1"] = &models.OnCallAck{
+				ID: "ack-1", OrgID: "org-1", GateID: "gate-1", AppID: "app-1", RequestedBy: "user-1",
+				Status: models.OnCallAckApproved, ExpiresAt: time.Now().Add(time.Hour),
+			}
+			h := NewDeploymentHandler(st, newMockQueue(), logger)
+			h.onCall = newPagerDutyServer(t, st, logger)
+
+			rr := httptest.NewRecorder()
+			h.Create(rr, withUser(templateRequest(http.MethodPost, "/v1/apps/app-1/deploy",
+				CreateDeploymentRequest{OnCallAckID: tt.ackID}, nil), tt.userID))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status == http.StatusConflict {
+				var apiErr APIError
+				json.Unmarshal(rr.Body.Bytes(), &apiErr)
+				if apiErr.Code != ErrCodeOnCallRequired {
+					t.Errorf("code = %q, want %q", apiErr.Code, ErrCodeOnCallRequired)
+				}
+			}
+		})
+	}
+}
+
+func TestDecideOnCallAck(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name   string
+		userID string
+		status int
+	}{
+		{"requester cannot approve", "user-1", http.StatusForbidden},
+		{"only on call can approve", "user-3", http.StatusForbidden},
+		{"on call approves", "user-2", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newOnCallMockStore()
+			st.onCall.acks["ack-1"] = &models.OnCallAck{
+				ID: "ack-1", OrgID: "org-1", GateID: "gate-1", AppID: "app-1", RequestedBy: "user-1",
+				Reason: "hotfix", Status: models.OnCallAckPending, ExpiresAt: time.Now().Add(time.Hour),
+			}
+			h := NewOnCallHandler(st, logger)
+			h.checker = newPagerDutyServer(t, st, logger)
+
+			rr := httptest.NewRecorder()
+			req := templateRequest(http.MethodPost, "/v1/orgs/org-1/on-call-acks/ack-1/approve", nil,
+				map[string]string{"orgID": "org-1", "ackID": "ack-1"})
+			h.ApproveAck(rr, withUser(req, tt.userID))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+
+			ack := st.onCall.acks["ack-1"]
+			if tt.status == http.StatusOK {
+				if ack.Status != models.OnCallAckApproved || ack.DecidedBy != "user-2" || ack.DecidedAt == nil {
+					t.Errorf("unexpected ack: %+v", ack)
+				}
+			} else if ack.Status != models.OnCallAckPending {
+				t.Errorf("status = %q, want pending", ack.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// operationPollInterval is how often the progress stream checks for updates.
+const operationPollInterval = time.Second
+
+// OperationHandler serves the progress of long-running operations.
+type OperationHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewOperationHandler creates a new operation handler.
+func NewOperationHandler(st store.Store, logger *slog.Logger) *OperationHandler {
+	return &OperationHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// WriteOperation responds to a request that started an operation with 202
+// Accepted, the operation and its location.
+func WriteOperation(w http.ResponseWriter, op *models.Operation) {
+	w.Header().Set("Location", "/v1/operations/"+op.ID)
+	WriteJSON(w, http.StatusAccepted, op)
+}
+
+// Get handles GET /v1/operations/{operationID} - returns an operation's
+// status and progress.
+func (h *OperationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	op, ok := h.operation(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, op)
+}
+
+// Stream handles GET /v1/operations/{operationID}/stream - streams an
+// operation's progress via Server-Sent Events. A "progress" event carries the
+// operation each time it changes and a final "complete" event carries it
+// once finished, after which the stream ends.
+func (h *OperationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	op, ok := h.operation(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+
+	ctx := r.Context()
+	pollTicker := time.NewTicker(operationPollInterval)
+	defer pollTicker.Stop()
+	pingTicker := time.NewTicker(15 * time.Second)
+	defer pingTicker.Stop()
+
+	var sent time.Time
+	for {
+		if op.Status.Finished() {
+			writeSSE(w, "", "complete", op)
+			flusher.Flush()
+			return
+		}
+		if !op.UpdatedAt.Equal(sent) {
+			writeSSE(w, "", "progress", op)
+			flusher.Flush()
+			sent = op.UpdatedAt
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-pingTicker.C:
+			writeSSE(w, "", "ping", map[string]int64{"time": time.Now().Unix()})
+			flusher.Flush()
+		case <-pollTicker.C:
+		}
+
+		current, err := h.store.Operations().Get(ctx, op.ID)
+		if err != nil {
+			h.logger.Error("failed to get operation", "error", err, "operation_id", op.ID)
+			continue
+		}
+		if current == nil {
+			return
+		}
+		op = current
+	}
+}
+
+// operation loads the requested operation. Operations are visible to the
+// user who started them and to instance admins.
+func (h *OperationHandler) operation(w http.ResponseWriter, r *http.Request) (*models.Operation, bool) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	if userID == "" {
+		WriteUnauthorized(w, "Authentication required")
+		return nil, false
+	}
+
+	op, err := h.store.Operations().Get(ctx, chi.URLParam(r, "operationID"))
+	if err != nil {
+		h.logger.Error("failed to get operation", "error", err)
+		WriteInternalError(w, "Failed to get operation")
+		return nil, false
+	}
+	if op == nil {
+		WriteNotFound(w, "Operation not found")
+		return nil, false
+	}
+
+	if op.CreatedBy != userID {
+		user, err := h.store.Users().GetByID(ctx, userID)
+		if err != nil || user == nil || auth.CheckRolePermission(user.Role, auth.PermissionAdminConsole) != nil {
+			// Don't reveal other users' operations
+			WriteNotFound(w, "Operation not found")
+			return nil, false
+		}
+	}
+	return op, true
+}
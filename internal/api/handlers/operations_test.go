@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/operations"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// opsMockStore adds operations and users to the node mock store. Its nodes
+// and deployments are guarded by mu, as drain operations read them in the
+// background.
+type opsMockStore struct {
+	*nodeMockStore
+	mu    sync.Mutex
+	ops   map[string]*models.Operation
+	users map[string]*store.User
+}
+
+func newOpsMockStore() *opsMockStore {
+	return &opsMockStore{
+		nodeMockStore: newNodeMockStore(),
+		ops:           make(map[string]*models.Operation),
+		users:         make(map[string]*store.User),
+	}
+}
+
+func (m *opsMockStore) Nodes() store.NodeStore             { return opsNodeStore{s: m} }
+func (m *opsMockStore) Deployments() store.DeploymentStore { return opsDeploymentStore{s: m} }
+func (m *opsMockStore) Operations() store.OperationStore   { return opsOperationStore{s: m} }
+func (m *opsMockStore) Users() store.UserStore             { return opsUserStore{s: m} }
+
+// update changes the nodes and deployments while no operation reads them.
+func (m *opsMockStore) update(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn()
+}
+
+type opsNodeStore struct {
+	store.NodeStore
+	s *opsMockStore
+}
+
+func (n opsNodeStore) Get(ctx context.Context, id string) (*models.Node, error) {
+	n.s.mu.Lock()
+	defer n.s.mu.Unlock()
+	if node := n.s.nodes.nodes[id]; node != nil {
+		copied := *node
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (n opsNodeStore) UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error {
+	n.s.mu.Lock()
+	defer n.s.mu.Unlock()
+	return n.s.nodes.UpdateStatus(ctx, id, status)
+}
+
+type opsDeploymentStore struct {
+	store.DeploymentStore
+	s *opsMockStore
+}
+
+func (d opsDeploymentStore) ListByNode(ctx context.Context, nodeID string) ([]*models.Deployment, error) {
+	d.s.mu.Lock()
+	defer d.s.mu.Unlock()
+	deployments, _ := d.s.nodeMockStore.Deployments().ListByNode(ctx, nodeID)
+	copies := make([]*models.Deployment, len(deployments))
+	for i, dep := range deployments {
+		copied := *dep
+		copies[i] = &copied
+	}
+	return copies, nil
+}
+
+type opsOperationStore struct {
+	store.OperationStore
+	s *opsMockStore
+}
+
+func (o opsOperationStore) Create(ctx context.Context, op *models.Operation) error {
+	o.s.mu.Lock()
+	defer o.s.mu.Unlock()
+	op.ID = "op-1"
+	op.CreatedAt, op.UpdatedAt = time.Now(), time.Now()
+	saved := *op
+	o.s.ops[op.ID] = &saved
+	return nil
+}
+
+func (o opsOperationStore) Update(ctx context.Context, op *models.Operation) error {
+	o.s.mu.Lock()
+	defer o.s.mu.Unlock()
+	op.UpdatedAt = time.Now()
+	saved := *op
+	saved.Errors = append([]string(nil), op.Errors...)
+	o.s.ops[op.ID] = &saved
+	return nil
+}
+
+func (o opsOperationStore) Get(ctx context.Context, id string) (*models.Operation, error) {
+	o.s.mu.Lock()
+	defer o.s.mu.Unlock()
+	if op := o.s.ops[id]; op != nil {
+		copied := *op
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+type opsUserStore struct {
+	store.UserStore
+	s *opsMockStore
+}
+
+func (u opsUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return u.s.users[id], nil
+}
+
+func operationRequest(method, target, userID string, params map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+	return req.WithContext(ctx)
+}
+
+func TestDrainReportsProgressUntilCordoned(t *testing.T) {
+	defer func(interval time.Duration) { drainPollInterval = interval }(drainPollInterval)
+	drainPollInterval = 5 * time.Millisecond
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newOpsMockStore()
+	st.nodes.nodes["node-1"] = &models.Node{ID: "node-1", Status: models.NodeStatusActive}
+	for _, id := range []string{"dep-1", "dep-2"} {
+		st.deploymentStore.deployments[id] = &models.Deployment{ID: id, NodeID: "node-1", Status: models.DeploymentStatusRunning}
+	}
+
+	manager := operations.NewManager(st, operations.DefaultConfig(), logger)
+	h := NewNodeHandler(st, logger)
+	h.SetOperations(manager)
+
+	rec := httptest.NewRecorder()
+	h.Drain(rec, operationRequest(http.MethodPost, "/v1/nodes/node-1/drain", "admin-1", map[string]string{"nodeID": "node-1"}))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+	var op models.Operation
+	if err := json.NewDecoder(rec.Body).Decode(&op); err != nil {
+		t.Fatalf("decoding operation: %v", err)
+	}
+	if op.Kind != models.OperationNodeDrain || op.TargetID != "node-1" || rec.Header().Get("Location") != "/v1/operations/"+op.ID {
+		t.Fatalf("operation = %+v, location = %q", op, rec.Header().Get("Location"))
+	}
+
+	waitFor := func(cond func(*models.Operation) bool) *models.Operation {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			current, _ := st.Operations().Get(context.Background(), op.ID)
+			if cond(current) {
+				return current
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("operation = %+v, condition not met", current)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The scheduler moves one deployment away
+	st.update(func() { st.deploymentStore.deployments["dep-1"].NodeID = "node-2" })
+	waitFor(func(op *models.Operation) bool { return op.Progress == 50 })
+
+	// Then the other, and cordons the emptied node
+	st.update(func() {
+		st.deploymentStore.deployments["dep-2"].NodeID = "node-2"
+		st.nodes.nodes["node-1"].Status = models.NodeStatusCordoned
+	})
+	manager.Wait()
+	final := waitFor(func(op *models.Operation) bool { return op.Status.Finished() })
+	if final.Status != models.OperationSucceeded || final.Progress != 100 || final.Result["deployments_moved"] != 2 {
+		t.Errorf("final = %+v, want succeeded having moved 2 deployments", final)
+	}
+}
+
+func TestOperationVisibility(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newOpsMockStore()
+	st.users["owner-1"] = &store.User{ID: "owner-1", Role: store.RoleOwner}
+	st.users["dev-1"] = &store.User{ID: "dev-1", Role: store.RoleMember}
+	finished := time.Now()
+	st.ops["op-1"] = &models.Operation{ID: "op-1", Kind: models.OperationNixGC, Status: models.OperationSucceeded, Progress: 100, CreatedBy: "admin-1", FinishedAt: &finished}
+	h := NewOperationHandler(st, logger)
+
+	tests := []struct {
+		user string
+		want int
+	}{
+		{"admin-1", http.StatusOK}, // The user who started it
+		{"owner-1", http.StatusOK}, // An instance admin
+		{"dev-1", http.StatusNotFound},
+		{"", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.Get(rec, operationRequest(http.MethodGet, "/v1/operations/op-1", tt.user, map[string]string{"operationID": "op-1"}))
+		if rec.Code != tt.want {
+			t.Errorf("user %q: status = %d, want %d", tt.user, rec.Code, tt.want)
+		}
+	}
+
+	// The stream of a finished operation ends with its completion
+	rec := httptest.NewRecorder()
+	h.Stream(rec, operationRequest(http.MethodGet, "/v1/operations/op-1/stream", "admin-1", map[string]string{"operationID": "op-1"}))
+	if body := rec.Body.String(); !bytes.Contains([]byte(body), []byte("event: complete")) {
+		t.Errorf("stream = %q, want a complete event", body)
+	}
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// OrgMembersHandler manages organization members, their roles and
+// invitations to join. Routes are wrapped in middleware.RequireOrgRole, which
+// resolves the caller's org role.
+type OrgMembersHandler struct {
+	store       store.Store
+	rbacService *auth.RBACService
+	logger      *slog.Logger
+}
+
+// NewOrgMembersHandler creates a new org members handler.
+func NewOrgMembersHandler(st store.Store, logger *slog.Logger) *OrgMembersHandler {
+	return &OrgMembersHandler{
+		store:       st,
+		rbacService: auth.NewRBACService(st, logger),
+		logger:      logger,
+	}
+}
+
+// OrgMemberResponse is an org member with their user details.
+type OrgMemberResponse struct {
+	UserID    string      `json:"user_id"`
+	Email     string      `json:"email"`
+	Name      string      `json:"name,omitempty"`
+	Role      models.Role `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// OrgMembershipResponse is a user's role in an org.
+type OrgMembershipResponse struct {
+	OrgID  string      `json:"org_id"`
+	UserID string      `json:"user_id"`
+	Role   models.Role `json:"role"`
+}
+
+// UpdateOrgMemberRequest is the request body for changing a member's role.
+type UpdateOrgMemberRequest struct {
+	Role models.Role `json:"role"`
+}
+
+// CreateOrgInvitationRequest is the request body for inviting someone to an org.
+type CreateOrgInvitationRequest struct {
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"` // Defaults to developer
+}
+
+// AcceptOrgInvitationRequest is the request body for accepting an org invitation.
+type AcceptOrgInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// ListMembers handles GET /v1/orgs/{orgID}/members - lists the org's members.
+func (h *OrgMembersHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+
+	members, err := h.store.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list org members", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list members")
+		return
+	}
+
+	resp := make([]OrgMemberResponse, 0, len(members))
+	for _, m := range members {
+		member := OrgMemberResponse{UserID: m.UserID, Role: m.Role, CreatedAt: m.CreatedAt}
+		user, err := h.store.Users().GetByID(ctx, m.UserID)
+		if err != nil {
+			h.logger.Error("failed to get org member", "error", err, "user_id", m.UserID)
+			WriteInternalError(w, "Failed to list members")
+			return
+		}
+		if user != nil {
+			member.Email = user.Email
+			member.Name = user.Name
+		}
+		resp = append(resp, member)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetMembership handles GET /v1/orgs/{orgID}/membership - returns the
+// current user's role in the org, so clients can tailor what they offer.
+func (h *OrgMembersHandler) GetMembership(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	WriteJSON(w, http.StatusOK, OrgMembershipResponse{
+		OrgID:  chi.URLParam(r, "orgID"),
+		UserID: middleware.GetUserID(ctx),
+		Role:   middleware.GetOrgRole(ctx),
+	})
+}
+
+// UpdateMember handles PUT /v1/orgs/{orgID}/members/{userID} - changes a
+// member's role. Only owners can appoint or demote owners, and the org's
+// last owner cannot be demoted.
+func (h *OrgMembersHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	userID := chi.URLParam(r, "userID")
+
+	var req UpdateOrgMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if !req.Role.Valid() {
+		WriteBadRequest(w, models.ErrInvalidOrgRole.Error())
+		return
+	}
+
+	members, err := h.store.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list org members", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to update member")
+		return
+	}
+	current := memberRole(members, userID)
+	if current == "" {
+		WriteNotFound(w, "Member not found")
+		return
+	}
+	if (current == models.RoleOwner || req.Role == models.RoleOwner) && middleware.GetOrgRole(ctx) != models.RoleOwner {
+		WriteForbidden(w, "Only owners can appoint or demote owners")
+		return
+	}
+	if current == models.RoleOwner && req.Role != models.RoleOwner && countOrgOwners(members) == 1 {
+		WriteConflict(w, "Cannot demote the organization's only owner")
+		return
+	}
+
+	if err := h.store.Orgs().AddMember(ctx, orgID, userID, req.Role); err != nil {
+		h.logger.Error("failed to update org member", "error", err, "org_id", orgID, "user_id", userID)
+		WriteInternalError(w, "Failed to update member")
+		return
+	}
+
+	h.logger.Info("org member role changed", "org_id", orgID, "user_id", userID, "from", current, "to", req.Role)
+	WriteJSON(w, http.StatusOK, OrgMembershipResponse{OrgID: orgID, UserID: userID, Role: req.Role})
+}
+
+// RemoveMember handles DELETE /v1/orgs/{orgID}/members/{userID} - removes a
+// member. Any member may remove themselves; removing others needs the
+// manage_org_members permission, and only owners can remove owners.
+func (h *OrgMembersHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	userID := chi.URLParam(r, "userID")
+	callerID := middleware.GetUserID(ctx)
+
+	if userID != callerID {
+		if err := userHasPermission(ctx, h.store, callerID, auth.PermissionManageOrgMembers); err != nil {
+			WriteForbidden(w, "Only admins can remove other members")
+			return
+		}
+	}
+
+	members, err := h.store.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list org members", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to remove member")
+		return
+	}
+	current := memberRole(members, userID)
+	if current == "" {
+		WriteNotFound(w, "Member not found")
+		return
+	}
+	if current == models.RoleOwner && userID != callerID && middleware.GetOrgRole(ctx) != models.RoleOwner {
+		WriteForbidden(w, "Only owners can remove owners")
+		return
+	}
+	if current == models.RoleOwner && countOrgOwners(members) == 1 {
+		WriteConflict(w, "Cannot remove the organization's only owner")
+		return
+	}
+
+	if err := h.store.Orgs().RemoveMember(ctx, orgID, userID); err != nil {
+		h.logger.Error("failed to remove org member", "error", err, "org_id", orgID, "user_id", userID)
+		WriteInternalError(w, "Failed to remove member")
+		return
+	}
+
+	h.logger.Info("org member removed", "org_id", orgID, "user_id", userID, "removed_by", callerID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateInvitation handles POST /v1/orgs/{orgID}/invitations - invites
+// someone to join the org with a role. Only owners can invite owners.
+func (h *OrgMembersHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+
+	var req CreateOrgInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		WriteBadRequest(w, "email is required")
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleDeveloper
+	}
+	if !req.Role.Valid() {
+		WriteBadRequest(w, models.ErrInvalidOrgRole.Error())
+		return
+	}
+	if req.Role == models.RoleOwner && middleware.GetOrgRole(ctx) != models.RoleOwner {
+		WriteForbidden(w, "Only owners can invite owners")
+		return
+	}
+
+	invitation, err := h.rbacService.CreateOrgInvitation(ctx, orgID, req.Email, middleware.GetUserID(ctx), req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrEmailAlreadyInvited):
+			WriteConflict(w, "Email has already been invited to this organization")
+		case errors.Is(err, auth.ErrAlreadyMember):
+			WriteConflict(w, "User is already a member of this organization")
+		default:
+			h.logger.Error("failed to create org invitation", "error", err, "org_id", orgID)
+			WriteInternalError(w, "Failed to create invitation")
+		}
+		return
+	}
+
+	h.logger.Info("org invitation created", "org_id", orgID, "invitation_id", invitation.ID, "role", invitation.OrgRole)
+	WriteJSON(w, http.StatusCreated, invitation)
+}
+
+// ListInvitations handles GET /v1/orgs/{orgID}/invitations - lists
+// invitations to join the org, newest first.
+func (h *OrgMembersHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+
+	invitations, err := h.store.Invitations().ListByOrg(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list org invitations", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list invitations")
+		return
+	}
+	if invitations == nil {
+		invitations = []*models.Invitation{}
+	}
+	WriteJSON(w, http.StatusOK, invitations)
+}
+
+// RevokeInvitation handles DELETE /v1/orgs/{orgID}/invitations/{invitationID}
+// - revokes a pending invitation to join the org.
+func (h *OrgMembersHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	invitationID := chi.URLParam(r, "invitationID")
+
+	invitation, err := h.store.Invitations().Get(ctx, invitationID)
+	if err != nil {
+		h.logger.Error("failed to get invitation", "error", err, "invitation_id", invitationID)
+		WriteInternalError(w, "Failed to revoke invitation")
+		return
+	}
+	if invitation == nil || invitation.OrgID != orgID {
+		WriteNotFound(w, "Invitation not found")
+		return
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		WriteConflict(w, "Only pending invitations can be revoked")
+		return
+	}
+
+	if err := h.rbacService.RevokeInvitation(ctx, invitationID); err != nil {
+		h.logger.Error("failed to revoke invitation", "error", err, "invitation_id", invitationID)
+		WriteInternalError(w, "Failed to revoke invitation")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvitation handles POST /v1/invitations/accept - adds the signed-in
+// user to the org they were invited to. New users accept through
+// /auth/invite/accept instead, which also creates their account.
+func (h *OrgMembersHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	var req AcceptOrgInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Token == "" {
+		WriteBadRequest(w, "token is required")
+		return
+	}
+
+	user, err := h.store.Users().GetByID(ctx, userID)
+	if err != nil || user == nil {
+		h.logger.Error("failed to get user", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to accept invitation")
+		return
+	}
+
+	invitation, err := h.rbacService.AcceptOrgInvitation(ctx, req.Token, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvitationNotFound):
+			WriteNotFound(w, "Invitation not found")
+		case errors.Is(err, auth.ErrInvitationExpired):
+			WriteError(w, http.StatusGone, "invitation_expired", "Invitation has expired")
+		case errors.Is(err, auth.ErrInvitationUsed):
+			WriteConflict(w, "Invitation already used")
+		case errors.Is(err, auth.ErrInvitationEmail):
+			WriteForbidden(w, "Invitation was sent to a different email address")
+		default:
+			h.logger.Error("failed to accept org invitation", "error", err, "user_id", userID)
+			WriteInternalError(w, "Failed to accept invitation")
+		}
+		return
+	}
+
+	h.logger.Info("org invitation accepted", "org_id", invitation.OrgID, "user_id", userID, "role", invitation.OrgRole)
+	WriteJSON(w, http.StatusOK, OrgMembershipResponse{OrgID: invitation.OrgID, UserID: userID, Role: invitation.OrgRole})
+}
+
+// memberRole returns the user's role among members, or an empty role if
+// they are not a member.
+func memberRole(members []*models.OrgMembership, userID string) models.Role {
+	for _, m := range members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// countOrgOwners returns the number of owners among members.
+func countOrgOwners(members []*models.OrgMembership) int {
+	n := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// membersOrgStore keeps the members of a single org.
+type membersOrgStore struct {
+	store.OrgStore
+	members map[string]models.Role
+}
+
+func (s *membersOrgStore) ListMembers(ctx context.Context, orgID string) ([]*models.OrgMembership, error) {
+	var members []*models.OrgMembership
+	for userID, role := range s.members {
+		members = append(members, &models.OrgMembership{OrgID: orgID, UserID: userID, Role: role})
+	}
+	return members, nil
+}
+
+func (s *membersOrgStore) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
+	_, ok := s.members[userID]
+	return ok, nil
+}
+
+func (s *membersOrgStore) AddMember(ctx context.Context, orgID, userID string, role models.Role) error {
+	s.members[userID] = role
+	return nil
+}
+
+func (s *membersOrgStore) RemoveMember(ctx context.Context, orgID, userID string) error {
+	delete(s.members, userID)
+	return nil
+}
+
+// membersUserStore serves users whose email is their ID at example.com.
+type membersUserStore struct {
+	store.UserStore
+}
+
+func (s *membersUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return &store.User{ID: id, Email: id + "@example.com", Role: store.RoleMember}, nil
+}
+
+func (s *membersUserStore) GetByEmail(ctx context.Context, email string) (*store.User, error) {
+	return nil, nil
+}
+
+// membersInvitationStore keeps invitations in memory.
+type membersInvitationStore struct {
+	store.InvitationStore
+	invitations []*models.Invitation
+}
+
+func (s *membersInvitationStore) Create(ctx context.Context, inv *models.Invitation) error {
+	inv.ID = inv.Email
+	s.invitations = append(s.invitations, inv)
+	return nil
+}
+
+func (s *membersInvitationStore) ListByOrg(ctx context.Context, orgID string) ([]*models.Invitation, error) {
+	var out []*models.Invitation
+	for _, inv := range s.invitations {
+		if inv.OrgID == orgID {
+			out = append(out, inv)
+		}
+	}
+	return out, nil
+}
+
+func (s *membersInvitationStore) GetByToken(ctx context.Context, token string) (*models.Invitation, error) {
+	for _, inv := range s.invitations {
+		if inv.Token == token {
+			return inv, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *membersInvitationStore) Update(ctx context.Context, inv *models.Invitation) error {
+	return nil
+}
+
+type membersMockStore struct {
+	store.Store
+	orgs        *membersOrgStore
+	invitations *membersInvitationStore
+}
+
+func (s *membersMockStore) Orgs() store.OrgStore               { return s.orgs }
+func (s *membersMockStore) Users() store.UserStore             { return &membersUserStore{} }
+func (s *membersMockStore) Invitations() store.InvitationStore { return s.invitations }
+
+func newMembersMockStore() *membersMockStore {
+	return &membersMockStore{
+		orgs: &membersOrgStore{members: map[string]models.Role{
+			"alice": models.RoleOwner,
+			"bob":   models.RoleAdmin,
+			"carol": models.RoleViewer,
+		}},
+		invitations: &membersInvitationStore{},
+	}
+}
+
+// memberRequest builds a request from userID, whose org role in org-1 has
+// been resolved as role.
+func memberRequest(method, target string, body any, userID string, role models.Role, params map[string]string) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.OrgRoleKey, role)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestOrgMembersUpdateMember(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newMembersMockStore()
+	h := NewOrgMembersHandler(st, logger)
+
+	update := func(caller string, role models.Role, target string, newRole models.Role) int {
+		rr := httptest.NewRecorder()
+		h.UpdateMember(rr, memberRequest(http.MethodPut, "/v1/orgs/org-1/members/"+target,
+			UpdateOrgMemberRequest{Role: newRole}, caller, role, map[string]string{"userID": target}))
+		return rr.Code
+	}
+
+	if code := update("bob", models.RoleAdmin, "carol", "superuser"); code != http.StatusBadRequest {
+		t.Errorf("invalid role = %d, want 400", code)
+	}
+	if code := update("bob", models.RoleAdmin, "carol", models.RoleOwner); code != http.StatusForbidden {
+		t.Errorf("admin appointing owner = %d, want 403", code)
+	}
+	if code := update("bob", models.RoleAdmin, "alice", models.RoleViewer); code != http.StatusForbidden {
+		t.Errorf("admin demoting owner = %d, want 403", code)
+	}
+	if code := update("alice", models.RoleOwner, "alice", models.RoleAdmin); code != http.StatusConflict {
+		t.Errorf("demoting the only owner = %d, want 409", code)
+	}
+	if code := update("bob", models.RoleAdmin, "dave", models.RoleViewer); code != http.StatusNotFound {
+		t.Errorf("unknown member = %d, want 404", code)
+	}
+	if code := update("bob", models.RoleAdmin, "carol", models.RoleDeveloper); code != http.StatusOK {
+		t.Fatalf("admin promoting viewer = %d, want 200", code)
+	}
+	if got := st.orgs.members["carol"]; got != models.RoleDeveloper {
+		t.Errorf("carol role = %s, want developer", got)
+	}
+}
+
+func TestOrgMembersRemoveMember(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newMembersMockStore()
+	h := NewOrgMembersHandler(st, logger)
+
+	remove := func(caller string, role models.Role, target string) int {
+		rr := httptest.NewRecorder()
+		h.RemoveMember(rr, memberRequest(http.MethodDelete, "/v1/orgs/org-1/members/"+target,
+			nil, caller, role, map[string]string{"userID": target}))
+		return rr.Code
+	}
+
+	if code := remove("carol", models.RoleViewer, "bob"); code != http.StatusForbidden {
+		t.Errorf("viewer removing admin = %d, want 403", code)
+	}
+	if code := remove("bob", models.RoleAdmin, "alice"); code != http.StatusForbidden {
+		t.Errorf("admin removing owner = %d, want 403", code)
+	}
+	if code := remove("alice", models.RoleOwner, "alice"); code != http.StatusConflict {
+		t.Errorf("only owner leaving = %d, want 409", code)
+	}
+	if code := remove("carol", models.RoleViewer, "carol"); code != http.StatusNoContent {
+		t.Errorf("viewer leaving = %d, want 204", code)
+	}
+	if code := remove("bob", models.RoleAdmin, "carol"); code != http.StatusNotFound {
+		t.Errorf("removing former member = %d, want 404", code)
+	}
+}
+
+func TestOrgMembersInvitations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newMembersMockStore()
+	h := NewOrgMembersHandler(st, logger)
+
+	invite := func(role models.Role, email string, invitedRole models.Role) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CreateInvitation(rr, memberRequest(http.MethodPost, "/v1/orgs/org-1/invitations",
+			CreateOrgInvitationRequest{Email: email, Role: invitedRole}, "bob", role, nil))
+		return rr
+	}
+
+	if rr := invite(models.RoleAdmin, "erin@example.com", models.RoleOwner); rr.Code != http.StatusForbidden {
+		t.Errorf("admin inviting owner = %d, want 403", rr.Code)
+	}
+	rr := invite(models.RoleAdmin, "erin@example.com", "")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("invite = %d, want 201: %s", rr.Code, rr.Body.String())
+	}
+	var invitation models.Invitation
+	if err := json.Unmarshal(rr.Body.Bytes(), &invitation); err != nil {
+		t.Fatal(err)
+	}
+	if invitation.OrgID != "org-1" || invitation.OrgRole != models.RoleDeveloper || invitation.Token == "" {
+		t.Errorf("invitation = %+v, want a developer invitation to org-1", invitation)
+	}
+	if rr := invite(models.RoleAdmin, "ERIN@example.com", models.RoleViewer); rr.Code != http.StatusConflict {
+		t.Errorf("duplicate invite = %d, want 409", rr.Code)
+	}
+
+	accept := func(userID string) int {
+		rr := httptest.NewRecorder()
+		h.AcceptInvitation(rr, memberRequest(http.MethodPost, "/v1/invitations/accept",
+			AcceptOrgInvitationRequest{Token: invitation.Token}, userID, "", nil))
+		return rr.Code
+	}
+	if code := accept("mallory"); code != http.StatusForbidden {
+		t.Errorf("accept with another email = %d, want 403", code)
+	}
+	if code := accept("erin"); code != http.StatusOK {
+		t.Fatalf("accept = %d, want 200", code)
+	}
+	if got := st.orgs.members["erin"]; got != models.RoleDeveloper {
+		t.Errorf("erin role = %s, want developer", got)
+	}
+}
@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/secrets"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Consumer statuses of a shared secret in an app.
+const (
+	// SharedSecretInherited means the app's services receive the shared value.
+	SharedSecretInherited = "inherited"
+	// SharedSecretOverridden means an app secret with the same key replaces it.
+	SharedSecretOverridden = "overridden"
+)
+
+// SharedSecretHandler handles org-level secrets shared into apps.
+type SharedSecretHandler struct {
+	store       store.Store
+	sopsService *secrets.SOPSService
+	hooks       *hooks.Trigger
+	logger      *slog.Logger
+}
+
+// NewSharedSecretHandler creates a new shared secret handler.
+func NewSharedSecretHandler(st store.Store, sopsService *secrets.SOPSService, logger *slog.Logger) *SharedSecretHandler {
+	return &SharedSecretHandler{
+		store:       st,
+		sopsService: sopsService,
+		logger:      logger,
+	}
+}
+
+// SetHooks sets the trigger told when a shared secret changes in an app.
+func (h *SharedSecretHandler) SetHooks(t *hooks.Trigger) {
+	h.hooks = t
+}
+
+// SharedSecretRequest is the request body for creating or updating a shared
+// secret. A value left empty on update keeps the stored one.
+type SharedSecretRequest struct {
+	Key         string   `json:"key"`
+	Value       string   `json:"value,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Scope       string   `json:"scope,omitempty"` // Defaults to selected
+	AppIDs      []string `json:"app_ids,omitempty"`
+}
+
+// SharedSecretResponse is a shared secret with its value masked.
+type SharedSecretResponse struct {
+	*models.OrgSecret
+	MaskedValue string `json:"masked_value"`
+}
+
+// SharedSecretConsumer is an app a shared secret is shared into.
+type SharedSecretConsumer struct {
+	AppID   string `json:"app_id"`
+	AppName string `json:"app_name"`
+	// Status is SharedSecretInherited or SharedSecretOverridden.
+	Status string `json:"status"`
+	// Services are the app's services the secret was injected into, with the
+	// time of their last deployment that received it.
+	Services []*models.OrgSecretUsage `json:"services"`
+}
+
+// List handles GET /v1/orgs/{orgID}/shared-secrets - lists an org's shared secrets with masked values.
+func (h *SharedSecretHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	orgSecrets, err := h.store.OrgSecrets().List(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list shared secrets", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list shared secrets")
+		return
+	}
+
+	resp := make([]SharedSecretResponse, 0, len(orgSecrets))
+	for _, secret := range orgSecrets {
+		resp = append(resp, h.response(r.Context(), secret))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create handles POST /v1/orgs/{orgID}/shared-secrets - creates a shared secret (owners only).
+func (h *SharedSecretHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	var req SharedSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Value == "" {
+		WriteBadRequest(w, "value is required")
+		return
+	}
+
+	secret := &models.OrgSecret{
+		ID:          uuid.New().String(),
+		OrgID:       orgID,
+		Key:         req.Key,
+		Description: req.Description,
+		Scope:       models.OrgSecretScope(req.Scope),
+		AppIDs:      req.AppIDs,
+		CreatedBy:   middleware.GetUserID(ctx),
+	}
+	if !h.validate(w, r, secret) {
+		return
+	}
+
+	existing, err := h.store.OrgSecrets().Get(ctx, orgID, secret.Key)
+	if err != nil {
+		h.logger.Error("failed to get shared secret", "error", err, "org_id", orgID, "key", secret.Key)
+		WriteInternalError(w, "Failed to create shared secret")
+		return
+	}
+	if existing != nil {
+		WriteConflict(w, "A shared secret with this key already exists")
+		return
+	}
+
+	if secret.EncryptedValue, err = h.encrypt(ctx, req.Value); err != nil {
+		h.logger.Error("failed to encrypt shared secret", "error", err)
+		WriteInternalError(w, "Failed to encrypt secret")
+		return
+	}
+	if err := h.store.OrgSecrets().Create(ctx, secret); err != nil {
+		h.logger.Error("failed to create shared secret", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to create shared secret")
+		return
+	}
+
+	h.logger.Info("shared secret created",
+		"org_id", orgID,
+		"key", secret.Key,
+		"scope", secret.Scope,
+		"user_id", secret.CreatedBy,
+	)
+	h.propagate(ctx, nil, secret, "set")
+	WriteJSON(w, http.StatusCreated, h.response(ctx, secret))
+}
+
+// Update handles PUT /v1/orgs/{orgID}/shared-secrets/{key} - replaces a shared
+// secret's value, description and scope (owners only).
+func (h *SharedSecretHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	secret, ok := h.findSecret(w, r, orgID)
+	if !ok {
+		return
+	}
+	previous := *secret
+
+	var req SharedSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	secret.Description = req.Description
+	secret.Scope = models.OrgSecretScope(req.Scope)
+	secret.AppIDs = req.AppIDs
+	if !h.validate(w, r, secret) {
+		return
+	}
+	if req.Value != "" {
+		encrypted, err := h.encrypt(ctx, req.Value)
+		if err != nil {
+			h.logger.Error("failed to encrypt shared secret", "error", err)
+			WriteInternalError(w, "Failed to encrypt secret")
+			return
+		}
+		secret.EncryptedValue = encrypted
+	}
+
+	if err := h.store.OrgSecrets().Update(ctx, secret); err != nil {
+		h.logger.Error("failed to update shared secret", "error", err, "org_id", orgID, "key", secret.Key)
+		WriteInternalError(w, "Failed to update shared secret")
+		return
+	}
+
+	h.logger.Info("shared secret updated", "org_id", orgID, "key", secret.Key, "scope", secret.Scope)
+	h.propagate(ctx, &previous, secret, "set")
+	WriteJSON(w, http.StatusOK, h.response(ctx, secret))
+}
+
+// Delete handles DELETE /v1/orgs/{orgID}/shared-secrets/{key} - removes a shared secret (owners only).
+func (h *SharedSecretHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) || !h.requirePermission(w, r) {
+		return
+	}
+
+	secret, ok := h.findSecret(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	if err := h.store.OrgSecrets().Delete(r.Context(), secret.ID); err != nil {
+		h.logger.Error("failed to delete shared secret", "error", err, "org_id", orgID, "key", secret.Key)
+		WriteInternalError(w, "Failed to delete shared secret")
+		return
+	}
+
+	h.logger.Info("shared secret deleted", "org_id", orgID, "key", secret.Key)
+	h.propagate(r.Context(), secret, nil, "deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Consumers handles GET /v1/orgs/{orgID}/shared-secrets/{key}/consumers - lists
+// the apps a shared secret is shared into, whether each overrides it, and the
+// services it was injected into.
+func (h *SharedSecretHandler) Consumers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireMember(w, r, orgID) {
+		return
+	}
+
+	secret, ok := h.findSecret(w, r, orgID)
+	if !ok {
+		return
+	}
+
+	apps, err := h.store.Apps().ListByOrg(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to list org apps", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to list shared secret consumers")
+		return
+	}
+	usages, err := h.store.OrgSecrets().ListUsage(ctx, secret.ID)
+	if err != nil {
+		h.logger.Error("failed to list shared secret usage", "error", err, "secret_id", secret.ID)
+		WriteInternalError(w, "Failed to list shared secret consumers")
+		return
+	}
+	usageByApp := make(map[string][]*models.OrgSecretUsage)
+	for _, u := range usages {
+		usageByApp[u.AppID] = append(usageByApp[u.AppID], u)
+	}
+
+	consumers := make([]SharedSecretConsumer, 0)
+	for _, app := range apps {
+		if !secret.SharedWith(app.ID) {
+			continue
+		}
+		overridden, err := h.appOverrides(ctx, app.ID, secret.Key)
+		if err != nil {
+			h.logger.Error("failed to list app secrets", "error", err, "app_id", app.ID)
+			WriteInternalError(w, "Failed to list shared secret consumers")
+			return
+		}
+		consumer := SharedSecretConsumer{
+			AppID:    app.ID,
+			AppName:  app.Name,
+			Status:   SharedSecretInherited,
+			Services: usageByApp[app.ID],
+		}
+		if overridden {
+			consumer.Status = SharedSecretOverridden
+		}
+		if consumer.Services == nil {
+			consumer.Services = []*models.OrgSecretUsage{}
+		}
+		consumers = append(consumers, consumer)
+	}
+	WriteJSON(w, http.StatusOK, consumers)
+}
+
+// validate validates a shared secret and checks that the apps it is shared
+// into belong to its org, writing a bad request response if not.
+func (h *SharedSecretHandler) validate(w http.ResponseWriter, r *http.Request, secret *models.OrgSecret) bool {
+	if err := secret.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	for _, appID := range secret.AppIDs {
+		app, err := h.store.Apps().Get(r.Context(), appID)
+		if err != nil || app == nil || app.OrgID != secret.OrgID {
+			WriteBadRequest(w, "app "+appID+" is not in this organization")
+			return false
+		}
+	}
+	return true
+}
+
+// propagate fires on_secret_change for every app whose services see a
+// different value after a change: apps the secret was or is now shared into,
+// except those that override it with an app secret.
+func (h *SharedSecretHandler) propagate(ctx context.Context, before, after *models.OrgSecret, action string) {
+	if h.hooks == nil {
+		return
+	}
+	secret := after
+	if secret == nil {
+		secret = before
+	}
+	apps, err := h.store.Apps().ListByOrg(ctx, secret.OrgID)
+	if err != nil {
+		h.logger.Warn("failed to list org apps for shared secret hooks", "error", err, "org_id", secret.OrgID)
+		return
+	}
+	for _, app := range apps {
+		was := before != nil && before.SharedWith(app.ID)
+		is := after != nil && after.SharedWith(app.ID)
+		if !was && !is {
+			continue
+		}
+		if overridden, err := h.appOverrides(ctx, app.ID, secret.Key); err != nil || overridden {
+			continue
+		}
+		appAction := action
+		if was && !is {
+			appAction = "deleted"
+		}
+		h.hooks.SecretChanged(ctx, app.ID, secret.Key, appAction)
+	}
+}
+
+// appOverrides reports whether an app has its own secret with the key.
+func (h *SharedSecretHandler) appOverrides(ctx context.Context, appID, key string) (bool, error) {
+	keys, err := h.store.Secrets().List(ctx, appID)
+	if err != nil {
+		return false, err
+	}
+	for _, k := range keys {
+		if k == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findSecret loads the secret named in the URL, writing a not found response
+// if it does not exist.
+func (h *SharedSecretHandler) findSecret(w http.ResponseWriter, r *http.Request, orgID string) (*models.OrgSecret, bool) {
+	key := strings.ToUpper(chi.URLParam(r, "key"))
+	secret, err := h.store.OrgSecrets().Get(r.Context(), orgID, key)
+	if err != nil {
+		h.logger.Error("failed to get shared secret", "error", err, "org_id", orgID, "key", key)
+		WriteInternalError(w, "Failed to get shared secret")
+		return nil, false
+	}
+	if secret == nil {
+		WriteNotFound(w, "Shared secret not found")
+		return nil, false
+	}
+	return secret, true
+}
+
+// encrypt encrypts a value with SOPS, storing it as is when SOPS is not configured.
+func (h *SharedSecretHandler) encrypt(ctx context.Context, value string) ([]byte, error) {
+	if h.sopsService != nil && h.sopsService.CanEncrypt() {
+		return h.sopsService.Encrypt(ctx, []byte(value))
+	}
+	h.logger.Warn("SOPS not configured, storing secret without encryption")
+	return []byte(value), nil
+}
+
+// response masks a shared secret's value for display.
+func (h *SharedSecretHandler) response(ctx context.Context, secret *models.OrgSecret) SharedSecretResponse {
+	value := string(secret.EncryptedValue)
+	if h.sopsService != nil && h.sopsService.CanDecrypt() {
+		decrypted, err := h.sopsService.Decrypt(ctx, secret.EncryptedValue)
+		if err != nil {
+			// Never show ciphertext fragments
+			return SharedSecretResponse{OrgSecret: secret, MaskedValue: maskSecretValue("")}
+		}
+		value = string(decrypted)
+	}
+	return SharedSecretResponse{OrgSecret: secret, MaskedValue: maskSecretValue(value)}
+}
+
+// maskSecretValue hides a secret value, keeping the last four characters of
+// long values so owners can tell credentials apart.
+func maskSecretValue(value string) string {
+	if len(value) < 12 {
+		return "********"
+	}
+	return "********" + value[len(value)-4:]
+}
+
+// requireMember writes a forbidden response unless the current user belongs to the org.
+func (h *SharedSecretHandler) requireMember(w http.ResponseWriter, r *http.Request, orgID string) bool {
+	isMember, err := h.store.Orgs().IsMember(r.Context(), orgID, middleware.GetUserID(r.Context()))
+	if err != nil {
+		h.logger.Error("failed to check org membership", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to verify organization membership")
+		return false
+	}
+	if !isMember {
+		WriteForbidden(w, "Not a member of this organization")
+		return false
+	}
+	return true
+}
+
+// requirePermission writes a forbidden response unless the current user can manage shared secrets.
+func (h *SharedSecretHandler) requirePermission(w http.ResponseWriter, r *http.Request) bool {
+	if err := userHasPermission(r.Context(), h.store, middleware.GetUserID(r.Context()), auth.PermissionManageSharedSecrets); err != nil {
+		WriteForbidden(w, "Only owners can manage shared secrets")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockOrgSecretStore implements store.OrgSecretStore for testing.
+type mockOrgSecretStore struct {
+	secrets []*models.OrgSecret
+	usages  []*models.OrgSecretUsage
+}
+
+func (m *mockOrgSecretStore) Create(ctx context.Context, secret *models.OrgSecret) error {
+	m.secrets = append(m.secrets, secret)
+	return nil
+}
+
+func (m *mockOrgSecretStore) Get(ctx context.Context, orgID, key string) (*models.OrgSecret, error) {
+	for _, s := range m.secrets {
+		if s.OrgID == orgID && s.Key == key {
+			copy := *s
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOrgSecretStore) List(ctx context.Context, orgID string) ([]*models.OrgSecret, error) {
+	var result []*models.OrgSecret
+	for _, s := range m.secrets {
+		if s.OrgID == orgID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOrgSecretStore) Update(ctx context.Context, secret *models.OrgSecret) error {
+	for i, s := range m.secrets {
+		if s.ID == secret.ID {
+			m.secrets[i] = secret
+		}
+	}
+	return nil
+}
+
+func (m *mockOrgSecretStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockOrgSecretStore) RecordUsage(ctx context.Context, usages []*models.OrgSecretUsage) error {
+	m.usages = append(m.usages, usages...)
+	return nil
+}
+
+func (m *mockOrgSecretStore) ListUsage(ctx context.Context, secretID string) ([]*models.OrgSecretUsage, error) {
+	var result []*models.OrgSecretUsage
+	for _, u := range m.usages {
+		if u.SecretID == secretID {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+// sharedSecretMockStore adds shared secrets and app secret keys to the admission mock store.
+type sharedSecretMockStore struct {
+	*admissionMockStore
+	orgSecrets *mockOrgSecretStore
+	secrets    *mockSecretKeyStore
+}
+
+func (m *sharedSecretMockStore) OrgSecrets() store.OrgSecretStore { return m.orgSecrets }
+func (m *sharedSecretMockStore) Secrets() store.SecretStore       { return m.secrets }
+
+func newSharedSecretMockStore(role store.Role) *sharedSecretMockStore {
+	st := &sharedSecretMockStore{
+		admissionMockStore: newAdmissionMockStore(role),
+		orgSecrets:         &mockOrgSecretStore{},
+		secrets:            &mockSecretKeyStore{},
+	}
+	st.appStore.apps["app-2"] = &models.App{ID: "app-2", OrgID: "org-1", OwnerID: "user-1", Name: "shop-staging"}
+	st.appStore.apps["app-3"] = &models.App{ID: "app-3", OrgID: "org-2", OwnerID: "user-2", Name: "elsewhere"}
+	return st
+}
+
+func TestSharedSecretCreate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	valid := SharedSecretRequest{Key: "apm_key", Value: "apm-0123456789abcd", AppIDs: []string{"app-1"}}
+
+	tests := []struct {
+		name   string
+		role   store.Role
+		orgID  string
+		req    SharedSecretRequest
+		status int
+	}{
+		{"owner creates secret", store.RoleOwner, "org-1", valid, http.StatusCreated},
+		{"members cannot manage secrets", store.RoleMember, "org-1", valid, http.StatusForbidden},
+		{"non-members are rejected", store.RoleOwner, "org-2", valid, http.StatusForbidden},
+		{"value is required", store.RoleOwner, "org-1", SharedSecretRequest{Key: "APM_KEY"}, http.StatusBadRequest},
+		{"invalid key", store.RoleOwner, "org-1", SharedSecretRequest{Key: "APM-KEY", Value: "x"}, http.StatusBadRequest},
+		{"invalid scope", store.RoleOwner, "org-1", SharedSecretRequest{Key: "APM_KEY", Value: "x", Scope: "some"}, http.StatusBadRequest},
+		{"apps outside the org", store.RoleOwner, "org-1", SharedSecretRequest{Key: "APM_KEY", Value: "x", AppIDs: []string{"app-3"}}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newSharedSecretMockStore(tt.role)
+			rr := httptest.NewRecorder()
+			NewSharedSecretHandler(st, nil, logger).Create(rr, templateRequest(http.MethodPost,
+				"/v1/orgs/"+tt.orgID+"/shared-secrets", tt.req, map[string]string{"orgID": tt.orgID}))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	// Keys are unique within an org, and values are never returned
+	st := newSharedSecretMockStore(store.RoleOwner)
+	h := NewSharedSecretHandler(st, nil, logger)
+	params := map[string]string{"orgID": "org-1"}
+	for _, status := range []int{http.StatusCreated, http.StatusConflict} {
+		rr := httptest.NewRecorder()
+		h.Create(rr, templateRequest(http.MethodPost, "/v1/orgs/org-1/shared-secrets", valid, params))
+		if rr.Code != status {
+			t.Fatalf("status = %d, want %d: %s", rr.Code, status, rr.Body.String())
+		}
+		if bytes.Contains(rr.Body.Bytes(), []byte("apm-0123456789")) {
+			t.Errorf("response leaks the value: %s", rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.List(rr, templateRequest(http.MethodGet, "/v1/orgs/org-1/shared-secrets", nil, params))
+	var listed []SharedSecretResponse
+	json.Unmarshal(rr.Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0].Key != "APM_KEY" || listed[0].MaskedValue != "********abcd" || listed[0].Scope != models.OrgSecretScopeSelected {
+		t.Errorf("listed = %s", rr.Body.String())
+	}
+}
+
+func TestSharedSecretConsumers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newSharedSecretMockStore(store.RoleMember)
+	st.orgSecrets.secrets = []*models.OrgSecret{{
+		ID: "s1", OrgID: "org-1", Key: "REGISTRY_TOKEN", EncryptedValue: []byte("token"), Scope: models.OrgSecretScopeAll,
+	}}
+	st.orgSecrets.usages = []*models.OrgSecretUsage{{SecretID: "s1", AppID: "app-1", ServiceName: "web"}}
+	// Both apps have their own REGISTRY_TOKEN secret
+	st.secrets.keys = []string{"REGISTRY_TOKEN"}
+
+	rr := httptest.NewRecorder()
+	NewSharedSecretHandler(st, nil, logger).Consumers(rr, templateRequest(http.MethodGet,
+		"/v1/orgs/org-1/shared-secrets/registry_token/consumers", nil, map[string]string{"orgID": "org-1", "key": "registry_token"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var consumers []SharedSecretConsumer
+	json.Unmarshal(rr.Body.Bytes(), &consumers)
+	byApp := make(map[string]SharedSecretConsumer)
+	for _, c := range consumers {
+		byApp[c.AppID] = c
+	}
+	if len(consumers) != 2 || byApp["app-3"].AppID != "" {
+		t.Fatalf("consumers = %+v, want the two org-1 apps", consumers)
+	}
+	if c := byApp["app-1"]; c.Status != SharedSecretOverridden || len(c.Services) != 1 || c.Services[0].ServiceName != "web" {
+		t.Errorf("app-1 = %+v", c)
+	}
+	if c := byApp["app-2"]; c.Status != SharedSecretOverridden || len(c.Services) != 0 {
+		t.Errorf("app-2 = %+v", c)
+	}
+
+	// Without app secrets the apps inherit the shared value
+	st.secrets.keys = nil
+	rr = httptest.NewRecorder()
+	NewSharedSecretHandler(st, nil, logger).Consumers(rr, templateRequest(http.MethodGet,
+		"/v1/orgs/org-1/shared-secrets/REGISTRY_TOKEN/consumers", nil, map[string]string{"orgID": "org-1", "key": "REGISTRY_TOKEN"}))
+	consumers = nil
+	json.Unmarshal(rr.Body.Bytes(), &consumers)
+	for _, c := range consumers {
+		if c.Status != SharedSecretInherited {
+			t.Errorf("%s status = %q, want inherited", c.AppID, c.Status)
+		}
+	}
+}
+
+func TestServiceEnvInheritsSharedSecrets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newSharedSecretMockStore(store.RoleMember)
+	st.secrets.keys = []string{"REGISTRY_TOKEN"}
+	st.orgSecrets.secrets = []*models.OrgSecret{
+		{ID: "s1", OrgID: "org-1", Key: "APM_KEY", Scope: models.OrgSecretScopeSelected, AppIDs: []string{"app-1"}},
+		{ID: "s2", OrgID: "org-1", Key: "REGISTRY_TOKEN", Scope: models.OrgSecretScopeAll},
+		{ID: "s3", OrgID: "org-1", Key: "STAGING_ONLY", Scope: models.OrgSecretScopeSelected, AppIDs: []string{"app-2"}},
+	}
+
+	rr := httptest.NewRecorder()
+	NewServiceHandler(st, nil, nil, logger).ListEnvVars(rr, templateRequest(http.MethodGet,
+		"/v1/apps/app-1/services/web/env", nil, map[string]string{"serviceName": "web"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Inherited []EnvVarResponse `json:"inherited"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	want := []EnvVarResponse{
+		{Key: "APM_KEY", Source: EnvSourceSharedSecret},
+		{Key: "REGISTRY_TOKEN", Source: EnvSourceSecret}, // the app secret overrides the shared one
+	}
+	if len(resp.Inherited) != len(want) {
+		t.Fatalf("inherited = %+v, want %+v", resp.Inherited, want)
+	}
+	for i := range want {
+		if resp.Inherited[i] != want[i] {
+			t.Errorf("inherited[%d] = %+v, want %+v", i, resp.Inherited[i], want[i])
+		}
+	}
+}
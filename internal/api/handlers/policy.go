@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxPolicySize limits the size of an uploaded policy document.
+const maxPolicySize = 1 << 20
+
+// PolicyHandler handles org RBAC policy export and import.
+type PolicyHandler struct {
+	store   store.Store
+	service *auth.PolicyService
+	logger  *slog.Logger
+}
+
+// NewPolicyHandler creates a new policy handler.
+func NewPolicyHandler(st store.Store, logger *slog.Logger) *PolicyHandler {
+	return &PolicyHandler{
+		store:   st,
+		service: auth.NewPolicyService(st, logger),
+		logger:  logger,
+	}
+}
+
+// ApplyPolicyResponse lists the changes an imported policy makes.
+type ApplyPolicyResponse struct {
+	DryRun  bool                `json:"dry_run"`
+	Changes []auth.PolicyChange `json:"changes"`
+}
+
+// Export handles GET /v1/orgs/{orgID}/policy - returns the org policy as YAML,
+// or JSON with ?format=json (owners only).
+func (h *PolicyHandler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID, auth.PermissionViewUsers) {
+		return
+	}
+
+	policy, err := h.service.Export(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to export policy", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to export policy")
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		WriteJSON(w, http.StatusOK, policy)
+		return
+	}
+
+	data, err := policy.Encode()
+	if err != nil {
+		h.logger.Error("failed to encode policy", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to export policy")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Apply handles PUT /v1/orgs/{orgID}/policy - applies a YAML or JSON policy
+// document. With ?dry_run=true the changes are returned without being applied (owners only).
+func (h *PolicyHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID, auth.PermissionManageUsers, auth.PermissionManageFreezes) {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxPolicySize+1))
+	if err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if len(data) > maxPolicySize {
+		WriteBadRequest(w, "Policy document must be 1MB or smaller")
+		return
+	}
+
+	policy, err := auth.ParsePolicy(data)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	changes, err := h.service.Apply(ctx, orgID, middleware.GetUserID(ctx), policy, dryRun)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidPolicy) || errors.Is(err, auth.ErrPolicyNoOwner) {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to apply policy", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to apply policy")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, ApplyPolicyResponse{DryRun: dryRun, Changes: changes})
+}
+
+// requireOwner writes a forbidden response unless the current user belongs to
+// the org and their role grants every permission.
+func (h *PolicyHandler) requireOwner(w http.ResponseWriter, r *http.Request, orgID string, permissions ...auth.Permission) bool {
+	return requireOrgPermissions(w, r, h.store, h.logger, orgID, "Only owners can manage the organization policy", permissions...)
+}
+
+// requireOrgPermissions writes a forbidden response unless the current user
+// belongs to the org and their role grants every permission.
+func requireOrgPermissions(w http.ResponseWriter, r *http.Request, st store.Store, logger *slog.Logger, orgID, forbidden string, permissions ...auth.Permission) bool {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	isMember, err := st.Orgs().IsMember(ctx, orgID, userID)
+	if err != nil {
+		logger.Error("failed to check org membership", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to verify organization membership")
+		return false
+	}
+	if !isMember {
+		WriteForbidden(w, "Not a member of this organization")
+		return false
+	}
+	for _, permission := range permissions {
+		if err := userHasPermission(ctx, st, userID, permission); err != nil {
+			WriteForbidden(w, forbidden)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ErrCodePreconditionFailed is returned when an If-Match or If-None-Match
+// header does not hold for the resource a request writes.
+const ErrCodePreconditionFailed = "precondition_failed"
+
+// resourceETag returns the strong ETag of a resource, a digest of its JSON
+// encoding. PUT endpoints compare it to If-Match so clients only replace a
+// resource they have seen.
+func resourceETag(v any) string {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// checkPreconditions evaluates a write's If-Match and If-None-Match headers
+// against the ETag of the resource it writes, "" if the resource does not
+// exist. If-Match fails unless the resource is unchanged since the client
+// read it; If-None-Match: * fails if the resource exists, making a PUT
+// create-only. It writes a 412 response and returns false if either fails.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if etag == "" {
+			WriteError(w, http.StatusPreconditionFailed, ErrCodePreconditionFailed, "Resource does not exist")
+			return false
+		}
+		if !strongETagMatches(ifMatch, etag) {
+			WriteError(w, http.StatusPreconditionFailed, ErrCodePreconditionFailed, "Resource was modified since it was read. Please refresh and try again.")
+			return false
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etag != "" && etagMatches(ifNoneMatch, etag) {
+		WriteError(w, http.StatusPreconditionFailed, ErrCodePreconditionFailed, "Resource already exists")
+		return false
+	}
+	return true
+}
+
+// strongETagMatches reports whether an If-Match header matches etag, using
+// strong comparison as required for writes: weak ETags never match.
+func strongETagMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	etag := resourceETag(map[string]string{"name": "web"})
+	if etag != resourceETag(map[string]string{"name": "web"}) {
+		t.Fatal("resourceETag is not stable")
+	}
+	if etag == resourceETag(map[string]string{"name": "api"}) {
+		t.Fatal("resourceETag does not change with the resource")
+	}
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		ifNoneMatch string
+		current     string
+		want        bool
+	}{
+		{name: "no headers", current: etag, want: true},
+		{name: "no headers creating", want: true},
+		{name: "if-match current", ifMatch: etag, current: etag, want: true},
+		{name: "if-match one of", ifMatch: `"other", ` + etag, current: etag, want: true},
+		{name: "if-match stale", ifMatch: `"other"`, current: etag, want: false},
+		{name: "if-match weak", ifMatch: "W/" + etag, current: etag, want: false},
+		{name: "if-match any", ifMatch: "*", current: etag, want: true},
+		{name: "if-match any creating", ifMatch: "*", want: false},
+		{name: "if-none-match any creating", ifNoneMatch: "*", want: true},
+		{name: "if-none-match any existing", ifNoneMatch: "*", current: etag, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/v1/apps/web", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			if got := checkPreconditions(w, r, tt.current); got != tt.want {
+				t.Fatalf("checkPreconditions = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusPreconditionFailed {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
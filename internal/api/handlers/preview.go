@@ -411,6 +411,12 @@ func (h *PreviewHandler) mergeConfigs(base, override *models.BuildConfig) models
 	if override.BuildTimeout > 0 {
 		result.BuildTimeout = override.BuildTimeout
 	}
+	if override.BuildCPU != "" {
+		result.BuildCPU = override.BuildCPU
+	}
+	if override.BuildMemory != "" {
+		result.BuildMemory = override.BuildMemory
+	}
 	if override.GoVersion != "" {
 		result.GoVersion = override.GoVersion
 	}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/promotion"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxListedPromotions caps the promotion history returned for an app.
+const maxListedPromotions = 50
+
+// PromotionsHandler handles promotion policies and their evaluations.
+type PromotionsHandler struct {
+	store     store.Store
+	evaluator *promotion.Evaluator
+	logger    *slog.Logger
+}
+
+// NewPromotionsHandler creates a new promotions handler.
+func NewPromotionsHandler(st store.Store, evaluator *promotion.Evaluator, logger *slog.Logger) *PromotionsHandler {
+	return &PromotionsHandler{
+		store:     st,
+		evaluator: evaluator,
+		logger:    logger,
+	}
+}
+
+// PromotionPolicyRequest is the request body for creating or updating a
+// promotion policy. ServiceName cannot be changed on update.
+type PromotionPolicyRequest struct {
+	ServiceName       string  `json:"service_name"`
+	TargetAppID       string  `json:"target_app_id"`
+	TargetServiceName string  `json:"target_service_name,omitempty"`
+	WindowMinutes     int     `json:"window_minutes"`
+	MaxErrorRate      float64 `json:"max_error_rate"`
+	RequireApproval   bool    `json:"require_approval"`
+	Enabled           *bool   `json:"enabled,omitempty"`
+}
+
+// RejectPromotionRequest is the optional request body for rejecting a promotion.
+type RejectPromotionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ListPolicies handles GET /v1/apps/{appID}/promotion-policies - lists the
+// policies that promote this app's services.
+func (h *PromotionsHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	policies, err := h.store.Promotions().ListPolicies(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list promotion policies", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list promotion policies")
+		return
+	}
+	if policies == nil {
+		policies = []*models.PromotionPolicy{}
+	}
+	WriteJSON(w, http.StatusOK, policies)
+}
+
+// CreatePolicy handles POST /v1/apps/{appID}/promotion-policies - adds a
+// policy promoting one of this app's services into another app.
+func (h *PromotionsHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := appIDFromRequest(r)
+
+	var req PromotionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	policy := &models.PromotionPolicy{
+		AppID:             appID,
+		ServiceName:       req.ServiceName,
+		TargetAppID:       req.TargetAppID,
+		TargetServiceName: req.TargetServiceName,
+		WindowMinutes:     req.WindowMinutes,
+		MaxErrorRate:      req.MaxErrorRate,
+		RequireApproval:   req.RequireApproval,
+		Enabled:           req.Enabled == nil || *req.Enabled,
+		CreatedBy:         middleware.GetUserID(ctx),
+	}
+	if !h.checkPolicy(w, r, policy) {
+		return
+	}
+
+	existing, err := h.store.Promotions().ListPolicies(ctx, appID)
+	if err != nil {
+		h.logger.Error("failed to list promotion policies", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create promotion policy")
+		return
+	}
+	for _, p := range existing {
+		if p.ServiceName == policy.ServiceName && p.TargetAppID == policy.TargetAppID && p.TargetServiceName == policy.TargetServiceName {
+			WriteError(w, http.StatusConflict, ErrCodeConflict, "A policy already promotes this service to that target")
+			return
+		}
+	}
+
+	if err := h.store.Promotions().CreatePolicy(ctx, policy); err != nil {
+		h.logger.Error("failed to create promotion policy", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to create promotion policy")
+		return
+	}
+
+	h.logger.Info("promotion policy created",
+		"policy_id", policy.ID,
+		"app_id", appID,
+		"service_name", policy.ServiceName,
+		"target_app_id", policy.TargetAppID,
+	)
+	WriteJSON(w, http.StatusCreated, policy)
+}
+
+// UpdatePolicy handles PUT /v1/apps/{appID}/promotion-policies/{policyID} -
+// changes a policy's target and criteria. Evaluations already in progress
+// keep the criteria they started with.
+func (h *PromotionsHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.loadPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	var req PromotionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	policy := *existing
+	policy.TargetAppID = req.TargetAppID
+	policy.TargetServiceName = req.TargetServiceName
+	policy.WindowMinutes = req.WindowMinutes
+	policy.MaxErrorRate = req.MaxErrorRate
+	policy.RequireApproval = req.RequireApproval
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if !h.checkPolicy(w, r, &policy) {
+		return
+	}
+
+	if err := h.store.Promotions().UpdatePolicy(r.Context(), &policy); err != nil {
+		h.logger.Error("failed to update promotion policy", "error", err, "policy_id", policy.ID)
+		WriteInternalError(w, "Failed to update promotion policy")
+		return
+	}
+	WriteJSON(w, http.StatusOK, &policy)
+}
+
+// DeletePolicy handles DELETE /v1/apps/{appID}/promotion-policies/{policyID} -
+// removes a policy and its promotion history.
+func (h *PromotionsHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := h.loadPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Promotions().DeletePolicy(r.Context(), policy.ID); err != nil {
+		h.logger.Error("failed to delete promotion policy", "error", err, "policy_id", policy.ID)
+		WriteInternalError(w, "Failed to delete promotion policy")
+		return
+	}
+
+	h.logger.Info("promotion policy deleted", "policy_id", policy.ID, "app_id", policy.AppID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List handles GET /v1/apps/{appID}/promotions - lists recent promotion
+// evaluations of this app's deployments, newest first.
+func (h *PromotionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	appID := appIDFromRequest(r)
+	promotions, err := h.store.Promotions().ListByApp(r.Context(), appID, maxListedPromotions)
+	if err != nil {
+		h.logger.Error("failed to list promotions", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list promotions")
+		return
+	}
+	if promotions == nil {
+		promotions = []*models.Promotion{}
+	}
+	WriteJSON(w, http.StatusOK, promotions)
+}
+
+// Approve handles POST /v1/apps/{appID}/promotions/{promotionID}/approve -
+// approves a promotion that met its criteria, or was requested into an
+// environment requiring approval, and deploys the artifact to the target. The
+// approver is recorded in decided_by. The promotion stays blocked while the
+// target is in a deploy freeze.
+func (h *PromotionsHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, targetAppID, ok := h.loadPromotion(w, r)
+	if !ok {
+		return
+	}
+	if !h.checkTargetScope(w, r, targetAppID) {
+		return
+	}
+
+	err := h.evaluator.Approve(ctx, p, middleware.GetUserID(ctx))
+	if errors.Is(err, promotion.ErrNotAwaitingApproval) {
+		WriteError(w, http.StatusConflict, ErrCodeConflict, "Promotion is "+string(p.Status)+", not awaiting approval")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to approve promotion", "error", err, "promotion_id", p.ID)
+		WriteInternalError(w, "Failed to approve promotion")
+		return
+	}
+	WriteJSON(w, http.StatusOK, p)
+}
+
+// Reject handles POST /v1/apps/{appID}/promotions/{promotionID}/reject -
+// declines a promotion awaiting approval.
+func (h *PromotionsHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, _, ok := h.loadPromotion(w, r)
+	if !ok {
+		return
+	}
+	if p.Status != models.PromotionAwaitingApproval && p.Status != models.PromotionBlocked {
+		WriteError(w, http.StatusConflict, ErrCodeConflict, "Promotion is "+string(p.Status)+" and can no longer be rejected")
+		return
+	}
+
+	var req RejectPromotionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteBadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	p.Status = models.PromotionRejected
+	p.DecidedBy = middleware.GetUserID(ctx)
+	p.Reason = req.Reason
+	if p.Reason == "" {
+		p.Reason = "rejected by an owner"
+	}
+	if err := h.store.Promotions().Update(ctx, p); err != nil {
+		h.logger.Error("failed to reject promotion", "error", err, "promotion_id", p.ID)
+		WriteInternalError(w, "Failed to reject promotion")
+		return
+	}
+
+	h.logger.Info("promotion rejected", "promotion_id", p.ID, "user_id", p.DecidedBy)
+	WriteJSON(w, http.StatusOK, p)
+}
+
+// ListForDeployment handles GET /v1/deployments/{deploymentID}/promotions -
+// lists the promotions a deployment was evaluated for or created by.
+func (h *PromotionsHandler) ListForDeployment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deploymentID := chi.URLParam(r, "deploymentID")
+
+	deployment, err := h.store.Deployments().Get(ctx, deploymentID)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+	app, err := h.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil || app.OwnerID != middleware.GetUserID(ctx) {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	promotions, err := h.store.Promotions().ListByDeployment(ctx, deployment.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployment promotions", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to list promotions")
+		return
+	}
+	if promotions == nil {
+		promotions = []*models.Promotion{}
+	}
+	WriteJSON(w, http.StatusOK, promotions)
+}
+
+// checkPolicy validates a policy and checks that its source service exists and
+// that the caller owns, and may deploy to, the target app and service.
+func (h *PromotionsHandler) checkPolicy(w http.ResponseWriter, r *http.Request, policy *models.PromotionPolicy) bool {
+	ctx := r.Context()
+	if err := policy.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+
+	source, err := h.store.Apps().Get(ctx, policy.AppID)
+	if err != nil || source == nil {
+		WriteNotFound(w, "Application not found")
+		return false
+	}
+	if !hasService(source, policy.ServiceName) {
+		WriteBadRequest(w, "Service "+policy.ServiceName+" not found in this app")
+		return false
+	}
+
+	target, err := h.store.Apps().Get(ctx, policy.TargetAppID)
+	if err != nil || target == nil || target.OwnerID != middleware.GetUserID(ctx) {
+		WriteBadRequest(w, "Target app not found")
+		return false
+	}
+	if !hasService(target, policy.TargetServiceName) {
+		WriteBadRequest(w, "Service "+policy.TargetServiceName+" not found in the target app")
+		return false
+	}
+	return h.checkTargetScope(w, r, target.ID)
+}
+
+// checkTargetScope rejects scoped API keys that cannot deploy to the target
+// app, since a promotion deploys there on the caller's behalf.
+func (h *PromotionsHandler) checkTargetScope(w http.ResponseWriter, r *http.Request, targetAppID string) bool {
+	scopes := middleware.GetAPIKeyScopes(r.Context())
+	if scopes != nil && !models.AllowsApp(scopes, targetAppID, models.APIKeyActionDeploy) {
+		WriteForbidden(w, "API key cannot deploy to the target app")
+		return false
+	}
+	return true
+}
+
+// loadPolicy fetches the policy named in the URL, writing an error response if
+// it cannot or if the policy belongs to another app.
+func (h *PromotionsHandler) loadPolicy(w http.ResponseWriter, r *http.Request) (*models.PromotionPolicy, bool) {
+	policyID := chi.URLParam(r, "policyID")
+	policy, err := h.store.Promotions().GetPolicy(r.Context(), policyID)
+	if err != nil {
+		h.logger.Error("failed to get promotion policy", "error", err, "policy_id", policyID)
+		WriteInternalError(w, "Failed to load promotion policy")
+		return nil, false
+	}
+	if policy == nil || policy.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "Promotion policy not found")
+		return nil, false
+	}
+	return policy, true
+}
+
+// loadPromotion fetches the promotion named in the URL and the app it
+// deploys to, writing an error response if its policy or environment cannot
+// be loaded or belongs to another app.
+func (h *PromotionsHandler) loadPromotion(w http.ResponseWriter, r *http.Request) (*models.Promotion, string, bool) {
+	ctx := r.Context()
+	promotionID := chi.URLParam(r, "promotionID")
+	p, err := h.store.Promotions().Get(ctx, promotionID)
+	if err != nil {
+		h.logger.Error("failed to get promotion", "error", err, "promotion_id", promotionID)
+		WriteInternalError(w, "Failed to load promotion")
+		return nil, "", false
+	}
+	if p == nil {
+		WriteNotFound(w, "Promotion not found")
+		return nil, "", false
+	}
+
+	if p.EnvironmentID != "" {
+		env, err := h.store.Environments().Get(ctx, p.EnvironmentID)
+		if err != nil {
+			h.logger.Error("failed to get environment", "error", err, "environment_id", p.EnvironmentID)
+			WriteInternalError(w, "Failed to load promotion")
+			return nil, "", false
+		}
+		if env == nil || env.AppID != appIDFromRequest(r) {
+			WriteNotFound(w, "Promotion not found")
+			return nil, "", false
+		}
+		return p, env.EnvAppID, true
+	}
+
+	policy, err := h.store.Promotions().GetPolicy(ctx, p.PolicyID)
+	if err != nil {
+		h.logger.Error("failed to get promotion policy", "error", err, "policy_id", p.PolicyID)
+		WriteInternalError(w, "Failed to load promotion")
+		return nil, "", false
+	}
+	if policy == nil || policy.AppID != appIDFromRequest(r) {
+		WriteNotFound(w, "Promotion not found")
+		return nil, "", false
+	}
+	return p, policy.TargetAppID, true
+}
+
+// appIDFromRequest returns the app ID resolved by RequireOwnership, falling
+// back to the URL parameter.
+func appIDFromRequest(r *http.Request) string {
+	if appID := middleware.GetResolvedAppID(r.Context()); appID != "" {
+		return appID
+	}
+	return chi.URLParam(r, "appID")
+}
+
+// hasService reports whether the app has a service with the given name.
+func hasService(app *models.App, name string) bool {
+	for _, s := range app.Services {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
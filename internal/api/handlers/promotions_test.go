@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/promotion"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockPromotionStore implements the parts of store.PromotionStore the handler uses.
+type mockPromotionStore struct {
+	store.PromotionStore
+	policies   []*models.PromotionPolicy
+	promotions []*models.Promotion
+}
+
+func (m *mockPromotionStore) CreatePolicy(ctx context.Context, p *models.PromotionPolicy) error {
+	p.ID = "policy-new"
+	m.policies = append(m.policies, p)
+	return nil
+}
+
+func (m *mockPromotionStore) GetPolicy(ctx context.Context, id string) (*models.PromotionPolicy, error) {
+	for _, p := range m.policies {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPromotionStore) ListPolicies(ctx context.Context, appID string) ([]*models.PromotionPolicy, error) {
+	var out []*models.PromotionPolicy
+	for _, p := range m.policies {
+		if p.AppID == appID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockPromotionStore) Get(ctx context.Context, id string) (*models.Promotion, error) {
+	for _, p := range m.promotions {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPromotionStore) Create(ctx context.Context, p *models.Promotion) error {
+	p.ID = "promotion-new"
+	m.promotions = append(m.promotions, p)
+	return nil
+}
+
+func (m *mockPromotionStore) Update(ctx context.Context, p *models.Promotion) error { return nil }
+
+// mockEnvironmentStore implements the parts of store.EnvironmentStore the handler uses.
+type mockEnvironmentStore struct {
+	store.EnvironmentStore
+	envs []*models.Environment
+}
+
+func (m *mockEnvironmentStore) Create(ctx context.Context, env *models.Environment) error {
+	env.ID = "env-" + env.Name
+	m.envs = append(m.envs, env)
+	return nil
+}
+
+func (m *mockEnvironmentStore) Get(ctx context.Context, id string) (*models.Environment, error) {
+	for _, e := range m.envs {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockEnvironmentStore) GetByEnvApp(ctx context.Context, envAppID string) (*models.Environment, error) {
+	for _, e := range m.envs {
+		if e.EnvAppID == envAppID {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockEnvironmentStore) List(ctx context.Context, appID string) ([]*models.Environment, error) {
+	var out []*models.Environment
+	for _, e := range m.envs {
+		if e.AppID == appID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// promotionMockStore adds promotions to the deployment mock store.
+type promotionMockStore struct {
+	*deploymentMockStore
+	promotions   *mockPromotionStore
+	environments *mockEnvironmentStore
+}
+
+func (m *promotionMockStore) Promotions() store.PromotionStore {
+	return m.promotions
+}
+
+func (m *promotionMockStore) Environments() store.EnvironmentStore {
+	return m.environments
+}
+
+func (m *promotionMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func newPromotionMockStore() *promotionMockStore {
+	st := &promotionMockStore{
+		deploymentMockStore: newDeploymentMockStore(),
+		promotions:          &mockPromotionStore{},
+		environments:        &mockEnvironmentStore{},
+	}
+	st.appStore.apps["staging"] = &models.App{ID: "staging", OwnerID: "user-1", Services: []models.ServiceConfig{{Name: "web"}}}
+	st.appStore.apps["production"] = &models.App{ID: "production", OwnerID: "user-1", Services: []models.ServiceConfig{{Name: "web"}}}
+	st.appStore.apps["other"] = &models.App{ID: "other", OwnerID: "user-2", Services: []models.ServiceConfig{{Name: "web"}}}
+	return st
+}
+
+func promotionRequest(method, path string, body any, params map[string]string) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("appID", "staging")
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestCreatePromotionPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name   string
+		body   PromotionPolicyRequest
+		status int
+	}{
+		{"valid", PromotionPolicyRequest{ServiceName: "web", TargetAppID: "production", WindowMinutes: 30, MaxErrorRate: 0.01}, http.StatusCreated},
+		{"unknown service", PromotionPolicyRequest{ServiceName: "api", TargetAppID: "production", WindowMinutes: 30}, http.StatusBadRequest},
+		{"target owned by someone else", PromotionPolicyRequest{ServiceName: "web", TargetAppID: "other", WindowMinutes: 30}, http.StatusBadRequest},
+		{"promote into itself", PromotionPolicyRequest{ServiceName: "web", TargetAppID: "staging", WindowMinutes: 30}, http.StatusBadRequest},
+		{"window too short", PromotionPolicyRequest{ServiceName: "web", TargetAppID: "production"}, http.StatusBadRequest},
+		{"error rate out of range", PromotionPolicyRequest{ServiceName: "web", TargetAppID: "production", WindowMinutes: 30, MaxErrorRate: 2}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newPromotionMockStore()
+			h := NewPromotionsHandler(st, promotion.NewEvaluator(st, promotion.DefaultConfig(), logger), logger)
+
+			rr := httptest.NewRecorder()
+			h.CreatePolicy(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/promotion-policies", tt.body, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+
+			p := st.promotions.policies[0]
+			if p.TargetServiceName != "web" || !p.Enabled || p.CreatedBy != "user-1" {
+				t.Errorf("unexpected policy: %+v", p)
+			}
+
+			rr = httptest.NewRecorder()
+			h.CreatePolicy(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/promotion-policies", tt.body, nil))
+			if rr.Code != http.StatusConflict {
+				t.Errorf("duplicate policy status = %d, want %d", rr.Code, http.StatusConflict)
+			}
+		})
+	}
+}
+
+func TestRejectPromotion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newPromotionMockStore()
+	st.promotions.policies = []*models.PromotionPolicy{{ID: "policy-1", AppID: "staging", TargetAppID: "production"}}
+	st.promotions.promotions = []*models.Promotion{
+		{ID: "waiting", PolicyID: "policy-1", Status: models.PromotionAwaitingApproval},
+		{ID: "done", PolicyID: "policy-1", Status: models.PromotionPromoted},
+	}
+	h := NewPromotionsHandler(st, promotion.NewEvaluator(st, promotion.DefaultConfig(), logger), logger)
+
+	rr := httptest.NewRecorder()
+	h.Reject(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/promotions/waiting/reject",
+		RejectPromotionRequest{Reason: "not this week"}, map[string]string{"promotionID": "waiting"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	p := st.promotions.promotions[0]
+	if p.Status != models.PromotionRejected || p.DecidedBy != "user-1" || p.Reason != "not this week" {
+		t.Errorf("unexpected promotion: %+v", p)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Reject(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/promotions/done/reject", nil, map[string]string{"promotionID": "done"}))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("rejecting a promoted promotion: status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestCreateEnvironment(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	first := 1
+
+	tests := []struct {
+		name   string
+		body   EnvironmentRequest
+		status int
+	}{
+		{"valid", EnvironmentRequest{Name: "production", AppID: "production"}, http.StatusCreated},
+		{"invalid name", EnvironmentRequest{Name: "Production!", AppID: "production"}, http.StatusBadRequest},
+		{"name taken", EnvironmentRequest{Name: "staging", AppID: "production"}, http.StatusConflict},
+		{"position taken", EnvironmentRequest{Name: "production", AppID: "production", Position: &first}, http.StatusConflict},
+		{"app backs another environment", EnvironmentRequest{Name: "qa", AppID: "staging"}, http.StatusConflict},
+		{"app owned by someone else", EnvironmentRequest{Name: "production", AppID: "other"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newPromotionMockStore()
+			st.environments.envs = []*models.Environment{{ID: "env-staging", AppID: "staging", Name: "staging", Position: 1, EnvAppID: "staging"}}
+			h := NewPromotionsHandler(st, promotion.NewEvaluator(st, promotion.DefaultConfig(), logger), logger)
+
+			rr := httptest.NewRecorder()
+			h.CreateEnvironment(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/environments", tt.body, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			env := st.environments.envs[1]
+			if env.AppID != "staging" || env.EnvAppID != "production" || env.Position != 2 {
+				t.Errorf("unexpected environment: %+v", env)
+			}
+		})
+	}
+}
+
+func TestPromoteDeployment(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+
+	tests := []struct {
+		name            string
+		deploymentID    string
+		body            any
+		requireApproval bool
+		status          int
+		want            models.PromotionStatus
+	}{
+		{"promotes to the next environment", "staging-dep", nil, false, http.StatusCreated, models.PromotionPromoted},
+		{"waits for approval", "staging-dep", nil, true, http.StatusCreated, models.PromotionAwaitingApproval},
+		{"names the environment", "staging-dep", PromoteRequest{Environment: "production"}, false, http.StatusCreated, models.PromotionPromoted},
+		{"cannot promote backwards", "production-dep", PromoteRequest{Environment: "staging"}, false, http.StatusBadRequest, ""},
+		{"last environment", "production-dep", nil, false, http.StatusConflict, ""},
+		{"deployment not running", "failed-dep", nil, false, http.StatusConflict, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newPromotionMockStore()
+			st.environments.envs = []*models.Environment{
+				{ID: "env-staging", AppID: "staging", Name: "staging", Position: 1, EnvAppID: "staging"},
+				{ID: "env-production", AppID: "staging", Name: "production", Position: 2, EnvAppID: "production", RequireApproval: tt.requireApproval},
+			}
+			for _, d := range []*models.Deployment{
+				{ID: "staging-dep", AppID: "staging", ServiceName: "web", Artifact: "/nix/store/abc-web", Status: models.DeploymentStatusRunning},
+				{ID: "production-dep", AppID: "production", ServiceName: "web", Artifact: "/nix/store/abc-web", Status: models.DeploymentStatusRunning},
+				{ID: "failed-dep", AppID: "staging", ServiceName: "web", Artifact: "/nix/store/def-web", Status: models.DeploymentStatusFailed},
+			} {
+				st.deploymentStore.deployments[d.ID] = d
+			}
+			h := NewPromotionsHandler(st, promotion.NewEvaluator(st, promotion.DefaultConfig(), logger), logger)
+
+			params := map[string]string{"deploymentID": tt.deploymentID}
+			rr := httptest.NewRecorder()
+			h.Promote(rr, promotionRequest(http.MethodPost, "/v1/deployments/"+tt.deploymentID+"/promote", tt.body, params))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+
+			p := st.promotions.promotions[0]
+			if p.Status != tt.want || p.EnvironmentID != "env-production" || p.RequestedBy != "user-1" {
+				t.Fatalf("promotion = %+v, want %s into production requested by user-1", p, tt.want)
+			}
+			if tt.want == models.PromotionAwaitingApproval {
+				rr = httptest.NewRecorder()
+				h.Approve(rr, promotionRequest(http.MethodPost, "/v1/apps/staging/promotions/"+p.ID+"/approve", nil,
+					map[string]string{"promotionID": p.ID}))
+				if rr.Code != http.StatusOK {
+					t.Fatalf("approve status = %d: %s", rr.Code, rr.Body.String())
+				}
+				if p.Status != models.PromotionPromoted || p.DecidedBy != "user-1" {
+					t.Fatalf("promotion after approval = %+v", p)
+				}
+			}
+			target := st.deploymentStore.deployments[p.TargetDeploymentID]
+			if target == nil || target.AppID != "production" || target.Artifact != "/nix/store/abc-web" {
+				t.Errorf("unexpected target deployment: %+v", target)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/metrics"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// RecommendationsResponse lists the resource recommendations of an app's
+// services.
+type RecommendationsResponse struct {
+	From            time.Time                        `json:"from"`
+	To              time.Time                        `json:"to"`
+	Recommendations []*models.ResourceRecommendation `json:"recommendations"`
+	// MonthlySavings is the estimated saving of applying every recommendation
+	MonthlySavings float64 `json:"monthly_savings"`
+}
+
+// ApplyRecommendationRequest applies a service's resource recommendation.
+type ApplyRecommendationRequest struct {
+	// FreezeOverride lets an owner redeploy during an active freeze window
+	FreezeOverride *FreezeOverrideRequest `json:"freeze_override,omitempty"`
+
+	// OnCallAckID is an approved on-call acknowledgement letting the user
+	// deploy outside business hours
+	OnCallAckID string `json:"on_call_ack_id,omitempty"`
+}
+
+// ApplyRecommendationResponse is the applied recommendation and the
+// redeploy with the new resources.
+type ApplyRecommendationResponse struct {
+	Recommendation *models.ResourceRecommendation `json:"recommendation"`
+	Service        *models.ServiceConfig          `json:"service"`
+	// Deployment is nil if the service had no running deployment; the new
+	// resources apply from its next deploy
+	Deployment *models.Deployment `json:"deployment,omitempty"`
+}
+
+// Recommendations handles GET /v1/apps/{appID}/recommendations - right-sizes
+// the app's services from their usage over the last week. Only services
+// with a day of usage whose resources don't fit it are listed.
+func (h *DeploymentHandler) Recommendations(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.loadOwnedApp(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	resp := RecommendationsResponse{
+		From:            now.Add(-metrics.RecommendationWindow),
+		To:              now,
+		Recommendations: []*models.ResourceRecommendation{},
+	}
+	for i := range app.Services {
+		service := &app.Services[i]
+		if service.IsCron() {
+			continue
+		}
+		rec, err := h.recommend(r.Context(), app, service, now)
+		if err != nil {
+			h.logger.Error("failed to recommend resources", "error", err, "app_id", app.ID, "service_name", service.Name)
+			WriteInternalError(w, "Failed to get recommendations")
+			return
+		}
+		if rec != nil {
+			resp.Recommendations = append(resp.Recommendations, rec)
+			resp.MonthlySavings += rec.Savings.MonthlyCost
+		}
+	}
+	resp.MonthlySavings = math.Round(resp.MonthlySavings*100) / 100
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// ApplyRecommendation handles POST /v1/apps/{appID}/recommendations/{serviceName}/apply -
+// sets the service's resources to the current recommendation and redeploys
+// its running artifact with them, without a build.
+func (h *DeploymentHandler) ApplyRecommendation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.loadOwnedApp(w, r)
+	if !ok {
+		return
+	}
+	serviceName := chi.URLParam(r, "serviceName")
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == serviceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		WriteError(w, http.StatusNotFound, "SERVICE_NOT_FOUND", "Service not found")
+		return
+	}
+
+	var req ApplyRecommendationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	rec, err := h.recommend(ctx, app, service, time.Now().UTC())
+	if err != nil {
+		h.logger.Error("failed to recommend resources", "error", err, "app_id", app.ID, "service_name", serviceName)
+		WriteInternalError(w, "Failed to apply recommendation")
+		return
+	}
+	if rec == nil || service.IsCron() {
+		WriteConflict(w, "Service has no resource recommendation")
+		return
+	}
+
+	deployments, err := h.store.Deployments().List(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to list deployments", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to apply recommendation")
+		return
+	}
+	var running *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName == serviceName && d.Status == models.DeploymentStatusRunning && !d.IsCronRun() &&
+			(running == nil || d.Version > running.Version) {
+			running = d
+		}
+	}
+
+	resources := rec.Recommended
+	service.Resources = &resources
+	var freeze *models.FreezeWindow
+	if running != nil {
+		if freeze, ok = h.checkDeployFreeze(w, r, app, req.FreezeOverride); !ok {
+			return
+		}
+		if !h.checkOnCall(w, r, app, req.OnCallAckID) {
+			return
+		}
+		if !h.checkAdmission(w, r, []admission.Subject{admissionSubject(app, service, running.GitRef)}) {
+			return
+		}
+	}
+
+	var deployment *models.Deployment
+	app.UpdatedAt = time.Now()
+	err = h.store.WithTx(ctx, func(txStore store.Store) error {
+		if err := txStore.Apps().Update(ctx, app); err != nil {
+			return fmt.Errorf("updating service: %w", err)
+		}
+		if running == nil {
+			return nil
+		}
+		version, err := txStore.Deployments().GetNextVersion(ctx, app.ID, serviceName)
+		if err != nil {
+			return fmt.Errorf("getting next version: %w", err)
+		}
+		deployment = resizedDeployment(running, &resources, version)
+		return txStore.Deployments().Create(ctx, deployment)
+	})
+	if err != nil {
+		h.logger.Error("failed to apply recommendation", "error", err, "app_id", app.ID, "service_name", serviceName)
+		WriteInternalError(w, "Failed to apply recommendation")
+		return
+	}
+	if freeze != nil {
+		h.recordFreezeOverride(ctx, freeze, deployment, req.FreezeOverride.Reason)
+	}
+
+	attrs := []any{
+		"app_id", app.ID,
+		"service_name", serviceName,
+		"cpu", resources.CPU,
+		"memory", resources.Memory,
+		"monthly_savings", rec.Savings.MonthlyCost,
+		"user_id", middleware.GetUserID(ctx),
+	}
+	if deployment != nil {
+		attrs = append(attrs, "deployment_id", deployment.ID)
+	}
+	h.logger.Info("resource recommendation applied", attrs...)
+
+	WriteJSON(w, http.StatusOK, ApplyRecommendationResponse{
+		Recommendation: rec,
+		Service:        service,
+		Deployment:     deployment,
+	})
+}
+
+// loadOwnedApp returns the request's app, writing an error response if it
+// doesn't exist or the user doesn't own it.
+func (h *DeploymentHandler) loadOwnedApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil || app == nil {
+		WriteError(w, http.StatusNotFound, "APP_NOT_FOUND", "Application not found")
+		return nil, false
+	}
+	if app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return nil, false
+	}
+	return app, true
+}
+
+// recommend right-sizes a service from its usage over the recommendation
+// window ending now.
+func (h *DeploymentHandler) recommend(ctx context.Context, app *models.App, service *models.ServiceConfig, now time.Time) (*models.ResourceRecommendation, error) {
+	rollups, err := h.store.Metrics().ListByService(ctx, app.ID, service.Name, now.Add(-metrics.RecommendationWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("listing metrics: %w", err)
+	}
+	current := models.DefaultResourceSpec()
+	if service.Resources != nil {
+		current = service.Resources
+	}
+	return metrics.Recommend(service, *current, rollups, h.pricing(ctx)), nil
+}
+
+// pricing returns the resource prices from settings, or the defaults.
+func (h *DeploymentHandler) pricing(ctx context.Context) metrics.Pricing {
+	pricing := metrics.Pricing{
+		CPUCoreMonth:   models.DefaultCostPerCPUCoreMonth,
+		MemoryGiBMonth: models.DefaultCostPerMemoryGiBMonth,
+	}
+	if v, err := h.store.Settings().Get(ctx, "cost_cpu_core_month"); err == nil && v != "" {
+		if price, err := strconv.ParseFloat(v, 64); err == nil && price >= 0 {
+			pricing.CPUCoreMonth = price
+		}
+	}
+	if v, err := h.store.Settings().Get(ctx, "cost_memory_gib_month"); err == nil && v != "" {
+		if price, err := strconv.ParseFloat(v, 64); err == nil && price >= 0 {
+			pricing.MemoryGiBMonth = price
+		}
+	}
+	return pricing
+}
+
+// resizedDeployment redeploys a running deployment's artifact with new
+// resources. It starts as built so the scheduler places it without a build.
+func resizedDeployment(running *models.Deployment, resources *models.ResourceSpec, version int) *models.Deployment {
+	config := &models.RuntimeConfig{}
+	if running.Config != nil {
+		*config = *running.Config
+	}
+	config.Resources = resources
+
+	now := time.Now()
+	return &models.Deployment{
+		ID:          uuid.New().String(),
+		AppID:       running.AppID,
+		ServiceName: running.ServiceName,
+		Version:     version,
+		GitRef:      running.GitRef,
+		GitCommit:   running.GitCommit,
+		BuildType:   running.BuildType,
+		Artifact:    running.Artifact,
+		Status:      models.DeploymentStatusBuilt,
+		Resources:   resources,
+		Config:      config,
+		DependsOn:   running.DependsOn,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/metrics"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// recommendMockStore adds metrics and default settings to the deployment
+// mock store.
+type recommendMockStore struct {
+	*metricsMockStore
+}
+
+func (m *recommendMockStore) Settings() store.SettingsStore {
+	return &emptySettingsStore{}
+}
+
+func TestRecommendations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &recommendMockStore{&metricsMockStore{deploymentMockStore: newDeploymentMockStore(), metrics: &mockMetricStore{}}}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:      "app-1",
+		OwnerID: "user-1",
+		Services: []models.ServiceConfig{
+			{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 1, Resources: &models.ResourceSpec{CPU: "1", Memory: "1Gi"}},
+			{Name: "api", SourceType: models.SourceTypeImage, Image: "api", Replicas: 1, Resources: &models.ResourceSpec{CPU: "0.2", Memory: "256Mi"}},
+		},
+	}
+	st.deploymentStore.deployments["dep-1"] = &models.Deployment{
+		ID: "dep-1", AppID: "app-1", ServiceName: "web", Version: 3, Status: models.DeploymentStatusRunning,
+		Artifact: "nginx", BuildType: models.BuildTypeOCI, Resources: &models.ResourceSpec{CPU: "1", Memory: "1Gi"},
+		Config: &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: 80}}},
+	}
+
+	// A day of the same usage on both services
+	now := time.Now().UTC().Truncate(time.Minute)
+	for _, service := range []string{"web", "api"} {
+		for i := 1; i <= metrics.MinRecommendationSamples; i++ {
+			st.metrics.rollups = append(st.metrics.rollups, &models.MetricRollup{
+				DeploymentID: "dep-" + service, AppID: "app-1", ServiceName: service,
+				Bucket:  now.Add(-time.Duration(i) * time.Minute),
+				Samples: 1, CPUPercentSum: 10, CPUPercentMax: 10,
+				MemoryBytesSum: 180 << 20, MemoryBytesMax: 180 << 20,
+			})
+		}
+	}
+	h := NewDeploymentHandler(st, newMockQueue(), logger)
+
+	rr := httptest.NewRecorder()
+	h.Recommendations(rr, templateRequest(http.MethodGet, "/v1/apps/app-1/recommendations", nil, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var list RecommendationsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(list.Recommendations) != 1 || list.Recommendations[0].ServiceName != "web" {
+		t.Fatalf("recommendations = %+v, want only web", list.Recommendations)
+	}
+	want := models.ResourceSpec{CPU: "0.2", Memory: "256Mi"}
+	if got := list.Recommendations[0].Recommended; got != want {
+		t.Errorf("recommended = %+v, want %+v", got, want)
+	}
+	if list.MonthlySavings != list.Recommendations[0].Savings.MonthlyCost || list.MonthlySavings <= 0 {
+		t.Errorf("monthly savings = %v", list.MonthlySavings)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ApplyRecommendation(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/recommendations/api/apply", nil, map[string]string{"serviceName": "api"}))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("applying without a recommendation: status = %d, want 409", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ApplyRecommendation(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/recommendations/web/apply", nil, map[string]string{"serviceName": "web"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var applied ApplyRecommendationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &applied); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got := st.appStore.apps["app-1"].Services[0].Resources; got == nil || *got != want {
+		t.Errorf("service resources = %+v, want %+v", got, want)
+	}
+	d := st.deploymentStore.deployments[applied.Deployment.ID]
+	if d == nil || d.Status != models.DeploymentStatusBuilt || d.Artifact != "nginx" || d.Version != 4 {
+		t.Fatalf("redeploy = %+v", d)
+	}
+	if *d.Resources != want || *d.Config.Resources != want || len(d.Config.Ports) != 1 {
+		t.Errorf("redeploy resources = %+v, config = %+v", d.Resources, d.Config)
+	}
+	if st.deploymentStore.deployments["dep-1"].Config.Resources != nil {
+		t.Error("applying changed the running deployment's config")
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ReleasesHandler handles release notes linked to deployments.
+type ReleasesHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewReleasesHandler creates a new releases handler.
+func NewReleasesHandler(st store.Store, logger *slog.Logger) *ReleasesHandler {
+	return &ReleasesHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// ReleaseResponse is a release with its commits categorized into sections.
+type ReleaseResponse struct {
+	*models.Release
+	Changes ReleaseChanges `json:"changes"`
+}
+
+// newReleaseResponse categorizes the release's commits for display.
+func newReleaseResponse(release *models.Release) ReleaseResponse {
+	return ReleaseResponse{Release: release, Changes: categorizeChanges(release.Commits)}
+}
+
+// CreateReleaseRequest is the request body for attaching release notes to a deployment.
+type CreateReleaseRequest struct {
+	DeploymentID string   `json:"deployment_id"`
+	Commits      []string `json:"commits,omitempty"`     // Commit messages, newest first
+	CommitLog    string   `json:"commit_log,omitempty"`  // Raw output of git log, one commit per line
+	Notes        string   `json:"notes,omitempty"`       // Hand-written markdown notes
+	FromCommit   string   `json:"from_commit,omitempty"` // Defaults to the previous deployment's commit
+	ToCommit     string   `json:"to_commit,omitempty"`   // Defaults to this deployment's commit
+}
+
+// List handles GET /v1/apps/{appID}/releases - lists release notes for an app, newest first.
+func (h *ReleasesHandler) List(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+
+	releases, err := h.store.Releases().ListByApp(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list releases", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list releases")
+		return
+	}
+
+	response := make([]ReleaseResponse, 0, len(releases))
+	for _, release := range releases {
+		response = append(response, newReleaseResponse(release))
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+// Get handles GET /v1/apps/{appID}/releases/{deploymentID} - returns the release notes for a deployment.
+func (h *ReleasesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	deploymentID := chi.URLParam(r, "deploymentID")
+
+	release, err := h.store.Releases().GetByDeployment(r.Context(), deploymentID)
+	if err != nil {
+		h.logger.Error("failed to get release", "error", err, "deployment_id", deploymentID)
+		WriteInternalError(w, "Failed to load release")
+		return
+	}
+	if release == nil || release.AppID != appID {
+		WriteNotFound(w, "Release not found")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, newReleaseResponse(release))
+}
+
+// Create handles POST /v1/apps/{appID}/releases - attaches release notes to a deployment.
+// Posting again for the same deployment replaces its notes.
+func (h *ReleasesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appID := middleware.GetResolvedAppID(ctx)
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+
+	var req CreateReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.DeploymentID == "" {
+		WriteBadRequest(w, "deployment_id is required")
+		return
+	}
+
+	deployment, err := h.store.Deployments().Get(ctx, req.DeploymentID)
+	if err != nil || deployment.AppID != appID {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+
+	release := &models.Release{
+		AppID:        appID,
+		DeploymentID: deployment.ID,
+		ServiceName:  deployment.ServiceName,
+		Version:      deployment.Version,
+		FromCommit:   req.FromCommit,
+		ToCommit:     req.ToCommit,
+		Commits:      append(req.Commits, releasenotes.ParseRawCommits(req.CommitLog)...),
+		Notes:        req.Notes,
+		CreatedBy:    middleware.GetUserID(ctx),
+	}
+	if err := release.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if release.ToCommit == "" {
+		release.ToCommit = deployment.GitCommit
+	}
+	if release.FromCommit == "" {
+		previous, err := h.previousDeployment(r, deployment)
+		if err != nil {
+			h.logger.Error("failed to list deployments", "error", err, "app_id", appID)
+			WriteInternalError(w, "Failed to create release")
+			return
+		}
+		if previous != nil {
+			release.FromCommit = previous.GitCommit
+		}
+	}
+
+	if err := h.store.Releases().Upsert(ctx, release); err != nil {
+		h.logger.Error("failed to save release", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to create release")
+		return
+	}
+
+	h.logger.Info("release notes attached",
+		"app_id", appID,
+		"deployment_id", deployment.ID,
+		"commits", len(release.Commits),
+		"source", release.Source,
+	)
+	WriteJSON(w, http.StatusCreated, newReleaseResponse(release))
+}
+
+// previousDeployment returns the latest earlier deployment of the same service, if any.
+func (h *ReleasesHandler) previousDeployment(r *http.Request, deployment *models.Deployment) (*models.Deployment, error) {
+	deployments, err := h.store.Deployments().List(r.Context(), deployment.AppID)
+	if err != nil {
+		return nil, err
+	}
+	return findPreviousDeployment(deployments, deployment), nil
+}
+
+// findPreviousDeployment picks the highest-versioned deployment of the same
+// service older than the given one.
+func findPreviousDeployment(deployments []*models.Deployment, deployment *models.Deployment) *models.Deployment {
+	var previous *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName != deployment.ServiceName || d.Version >= deployment.Version {
+			continue
+		}
+		if previous == nil || d.Version > previous.Version {
+			previous = d
+		}
+	}
+	return previous
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestFindPreviousDeployment(t *testing.T) {
+	deployments := []*models.Deployment{
+		{ID: "web-3", ServiceName: "web", Version: 3, GitCommit: "ccc"},
+		{ID: "web-1", ServiceName: "web", Version: 1, GitCommit: "aaa"},
+		{ID: "worker-2", ServiceName: "worker", Version: 2, GitCommit: "zzz"},
+		{ID: "web-2", ServiceName: "web", Version: 2, GitCommit: "bbb"},
+	}
+
+	tests := []struct {
+		name       string
+		deployment *models.Deployment
+		expectID   string
+	}{
+		{"latest earlier version of same service", deployments[0], "web-2"},
+		{"first deployment has no previous", deployments[1], ""},
+		{"other services are ignored", deployments[2], ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := findPreviousDeployment(deployments, tt.deployment)
+			if tt.expectID == "" {
+				if previous != nil {
+					t.Errorf("expected no previous deployment, got %s", previous.ID)
+				}
+				return
+			}
+			if previous == nil || previous.ID != tt.expectID {
+				t.Errorf("expected %s, got %+v", tt.expectID, previous)
+			}
+		})
+	}
+}
+
+func TestNewReleaseResponse(t *testing.T) {
+	release := &models.Release{
+		Commits: []string{
+			"feat(api): add release notes endpoint",
+			"fix: handle empty commit ranges (08b7b4d)",
+			"update readme",
+		},
+	}
+	if err := release.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if release.Source != models.ReleaseNotesSourceGenerated {
+		t.Errorf("expected generated source, got %q", release.Source)
+	}
+
+	changes := newReleaseResponse(release).Changes
+	if len(changes.Features) != 1 || changes.Features[0].Area != "api" {
+		t.Errorf("expected one api feature, got %+v", changes.Features)
+	}
+	if len(changes.BugFixes) != 1 || len(changes.Other) != 1 {
+		t.Errorf("expected one fix and one other change, got %+v", changes)
+	}
+}
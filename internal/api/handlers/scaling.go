@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ScalingScheduleResponse is a service's scaling schedule with the replica
+// count it currently runs and what sets it.
+type ScalingScheduleResponse struct {
+	Schedule *models.ScalingSchedule `json:"schedule,omitempty"`
+	Override *models.ReplicaOverride `json:"override,omitempty"`
+	Replicas int                     `json:"replicas"`
+	// ActiveProfile names the profile setting the replica count; empty when
+	// the default count or a manual override applies.
+	ActiveProfile string     `json:"active_profile,omitempty"`
+	NextChange    *time.Time `json:"next_change,omitempty"`
+}
+
+// GetScalingSchedule handles GET /v1/apps/{appID}/services/{serviceName}/scaling-schedule -
+// returns the service's scaling schedule and the replica count it sets now.
+func (h *ServiceHandler) GetScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	_, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, scalingScheduleResponse(service, time.Now()))
+}
+
+// SetScalingSchedule handles PUT /v1/apps/{appID}/services/{serviceName}/scaling-schedule -
+// replaces the service's scaling schedule. Any manual override is cleared and
+// the service is scaled to the count the new schedule sets now.
+func (h *ServiceHandler) SetScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+
+	var schedule models.ScalingSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	if service.Autoscaling != nil {
+		WriteConflict(w, "Service is autoscaled; remove its autoscaling before setting a scaling schedule")
+		return
+	}
+
+	now := time.Now()
+	previousReplicas := service.Replicas
+	service.ScalingSchedule = &schedule
+	service.ReplicaOverride = nil
+	service.Replicas, _ = schedule.ReplicasAt(now)
+
+	if err := h.store.Services().Update(r.Context(), app.ID, service); err != nil {
+		h.logger.Error("failed to save scaling schedule", "error", err, "app_id", app.ID)
+		writeServiceStoreError(w, err, "Failed to save scaling schedule")
+		return
+	}
+
+	h.logger.Info("scaling schedule set", "app_id", app.ID, "service_name", service.Name, "profiles", len(schedule.Profiles))
+	h.serviceScaled(r.Context(), app.ID, service.Name, previousReplicas, service.Replicas, models.ScaleEventSchedule, "scaling schedule set")
+	WriteJSON(w, http.StatusOK, scalingScheduleResponse(service, now))
+}
+
+// DeleteScalingSchedule handles DELETE /v1/apps/{appID}/services/{serviceName}/scaling-schedule -
+// removes the service's scaling schedule. The service keeps its current
+// replica count.
+func (h *ServiceHandler) DeleteScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	app, service, ok := h.scalingService(w, r)
+	if !ok {
+		return
+	}
+	if service.ScalingSchedule == nil {
+		WriteNotFound(w, "Service has no scaling schedule")
+		return
+	}
+
+	service.ScalingSchedule = nil
+	service.ReplicaOverride = nil
+
+	if err := h.store.Services().Update(r.Context(), app.ID, service); err != nil {
+		h.logger.Error("failed to remove scaling schedule", "error", err, "app_id", app.ID)
+		writeServiceStoreError(w, err, "Failed to remove scaling schedule")
+		return
+	}
+
+	h.logger.Info("scaling schedule removed", "app_id", app.ID, "service_name", service.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scalingService loads the service of a scaling schedule or cron run request
+// and checks the app's owner.
+func (h *ServiceHandler) scalingService(w http.ResponseWriter, r *http.Request) (*models.App, *models.ServiceConfig, bool) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	serviceName := chi.URLParam(r, "serviceName")
+
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return nil, nil, false
+	}
+	if serviceName == "" {
+		WriteBadRequest(w, "Service name is required")
+		return nil, nil, false
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "APP_NOT_FOUND", "Application not found")
+		return nil, nil, false
+	}
+	if app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return nil, nil, false
+	}
+
+	for i := range app.Services {
+		if app.Services[i].Name == serviceName {
+			return app, &app.Services[i], true
+		}
+	}
+	WriteError(w, http.StatusNotFound, "SERVICE_NOT_FOUND", "Service not found")
+	return nil, nil, false
+}
+
+// scalingScheduleResponse describes the service's scaling at the given time.
+func scalingScheduleResponse(service *models.ServiceConfig, now time.Time) ScalingScheduleResponse {
+	resp := ScalingScheduleResponse{
+		Schedule: service.ScalingSchedule,
+		Replicas: service.Replicas,
+	}
+	if service.ScalingSchedule == nil {
+		return resp
+	}
+
+	resp.Replicas = service.DesiredReplicas(now)
+	if service.ReplicaOverride.ActiveAt(now) {
+		resp.Override = service.ReplicaOverride
+		resp.NextChange = service.ReplicaOverride.Until
+		return resp
+	}
+	if _, profile := service.ScalingSchedule.ReplicasAt(now); profile != nil {
+		resp.ActiveProfile = profile.Name
+	}
+	resp.NextChange = service.ScalingSchedule.NextChange(now)
+	return resp
+}
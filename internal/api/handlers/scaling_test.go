@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockScaleEventStore records the scale events the handlers create.
+type mockScaleEventStore struct {
+	store.ScaleEventStore
+	events []*models.ScaleEvent
+}
+
+func (m *mockScaleEventStore) Create(ctx context.Context, event *models.ScaleEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func TestScalingSchedule(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newDeploymentMockStore()
+	st.appStore.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 1}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"serviceName": "web"}
+	target := "/v1/apps/app-1/services/web/scaling-schedule"
+
+	// Every day, so the assertions hold whenever the test runs
+	schedule := models.ScalingSchedule{
+		DefaultReplicas: 2,
+		Profiles:        []models.ScalingProfile{{Name: "always", StartTime: "00:00", EndTime: "00:00", Replicas: 6}},
+	}
+
+	rr := httptest.NewRecorder()
+	h.SetScalingSchedule(rr, templateRequest(http.MethodPut, target, models.ScalingSchedule{DefaultReplicas: 2}, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("schedule without profiles: status = %d, want 400", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.SetScalingSchedule(rr, templateRequest(http.MethodPut, target, schedule, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("set schedule: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp ScalingScheduleResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Replicas != 6 || resp.ActiveProfile != "always" {
+		t.Errorf("replicas = %d, profile = %q, want 6 from \"always\"", resp.Replicas, resp.ActiveProfile)
+	}
+	if got := st.appStore.apps["app-1"].Services[0].Replicas; got != 6 {
+		t.Errorf("service replicas = %d, want 6", got)
+	}
+
+	// Scaling the service by hand overrides the schedule
+	replicas := 3
+	rr = httptest.NewRecorder()
+	h.Update(rr, templateRequest(http.MethodPatch, "/v1/apps/app-1/services/web", UpdateServiceRequest{Replicas: &replicas}, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("scale service: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	override := st.appStore.apps["app-1"].Services[0].ReplicaOverride
+	if override == nil || override.Replicas != 3 || override.SetBy != "user-1" {
+		t.Fatalf("override = %+v, want 3 replicas set by user-1", override)
+	}
+	events := st.scaleEvents.events
+	if len(events) != 2 || events[1].Source != models.ScaleEventManual || events[1].UserID != "user-1" {
+		t.Errorf("scale events = %+v, want the schedule's then a manual one by user-1", events)
+	}
+
+	rr = httptest.NewRecorder()
+	h.GetScalingSchedule(rr, templateRequest(http.MethodGet, target, nil, params))
+	resp = ScalingScheduleResponse{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Replicas != 3 || resp.Override == nil || resp.ActiveProfile != "" {
+		t.Errorf("get schedule = %+v, want 3 replicas from the override", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	h.DeleteScalingSchedule(rr, templateRequest(http.MethodDelete, target, nil, params))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("delete schedule: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	svc := st.appStore.apps["app-1"].Services[0]
+	if svc.ScalingSchedule != nil || svc.ReplicaOverride != nil || svc.Replicas != 3 {
+		t.Errorf("after delete: schedule = %v, override = %v, replicas = %d", svc.ScalingSchedule, svc.ReplicaOverride, svc.Replicas)
+	}
+}
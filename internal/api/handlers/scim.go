@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scim"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxSCIMRequestSize limits the size of a SCIM request body.
+const maxSCIMRequestSize = 1 << 20
+
+// defaultSCIMCount is the page size when a list request has no count.
+const defaultSCIMCount = 100
+
+// SCIMHandler serves the SCIM 2.0 provisioning API used by identity
+// providers, and the org endpoints owners use to configure it.
+type SCIMHandler struct {
+	store   store.Store
+	service *scim.Service
+	logger  *slog.Logger
+}
+
+// NewSCIMHandler creates a new SCIM handler.
+func NewSCIMHandler(st store.Store, service *scim.Service, logger *slog.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		store:   st,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ============================================================================
+// SCIM protocol endpoints (/scim/v2, SCIM token auth)
+// ============================================================================
+
+// ServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig.
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	scim.WriteJSON(w, http.StatusOK, scim.ServiceProviderConfig())
+}
+
+// ResourceTypes handles GET /scim/v2/ResourceTypes.
+func (h *SCIMHandler) ResourceTypes(w http.ResponseWriter, r *http.Request) {
+	scim.WriteJSON(w, http.StatusOK, scim.ResourceTypes())
+}
+
+// ListUsers handles GET /scim/v2/Users.
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, startIndex, count, ok := h.listParams(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.ListUsers(r.Context(), middleware.GetSCIMOrgID(r.Context()), filter, startIndex, count)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetUser handles GET /scim/v2/Users/{id}.
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetUser(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// CreateUser handles POST /scim/v2/Users.
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var in scim.User
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.CreateUser(r.Context(), middleware.GetSCIMOrgID(r.Context()), &in)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusCreated, res)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/{id}.
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var in scim.User
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.ReplaceUser(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"), &in)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/{id}.
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var in scim.PatchRequest
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.PatchUser(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"), in.Operations)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/{id}.
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteUser(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListGroups handles GET /scim/v2/Groups.
+func (h *SCIMHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	filter, startIndex, count, ok := h.listParams(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.ListGroups(r.Context(), middleware.GetSCIMOrgID(r.Context()), filter, startIndex, count)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetGroup handles GET /scim/v2/Groups/{id}.
+func (h *SCIMHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.GetGroup(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// CreateGroup handles POST /scim/v2/Groups.
+func (h *SCIMHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var in scim.Group
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.CreateGroup(r.Context(), middleware.GetSCIMOrgID(r.Context()), &in)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusCreated, res)
+}
+
+// ReplaceGroup handles PUT /scim/v2/Groups/{id}.
+func (h *SCIMHandler) ReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	var in scim.Group
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.ReplaceGroup(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"), &in)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// PatchGroup handles PATCH /scim/v2/Groups/{id}.
+func (h *SCIMHandler) PatchGroup(w http.ResponseWriter, r *http.Request) {
+	var in scim.PatchRequest
+	if !h.decode(w, r, &in) {
+		return
+	}
+	res, err := h.service.PatchGroup(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id"), in.Operations)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	scim.WriteJSON(w, http.StatusOK, res)
+}
+
+// DeleteGroup handles DELETE /scim/v2/Groups/{id}.
+func (h *SCIMHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteGroup(r.Context(), middleware.GetSCIMOrgID(r.Context()), chi.URLParam(r, "id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listParams parses the filter, startIndex and count query parameters.
+func (h *SCIMHandler) listParams(w http.ResponseWriter, r *http.Request) (*scim.Filter, int, int, bool) {
+	q := r.URL.Query()
+	filter, err := scim.ParseFilter(q.Get("filter"))
+	if err != nil {
+		h.writeError(w, err)
+		return nil, 0, 0, false
+	}
+
+	startIndex, count := 1, defaultSCIMCount
+	if v := q.Get("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			scim.WriteError(w, &scim.Error{Status: http.StatusBadRequest, SCIMType: "invalidValue", Detail: "startIndex must be an integer"})
+			return nil, 0, 0, false
+		}
+	}
+	if v := q.Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			scim.WriteError(w, &scim.Error{Status: http.StatusBadRequest, SCIMType: "invalidValue", Detail: "count must be an integer"})
+			return nil, 0, 0, false
+		}
+	}
+	return filter, startIndex, count, true
+}
+
+// decode reads a JSON request body into v.
+func (h *SCIMHandler) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSCIMRequestSize)).Decode(v); err != nil {
+		scim.WriteError(w, &scim.Error{Status: http.StatusBadRequest, SCIMType: "invalidSyntax", Detail: "Invalid request body"})
+		return false
+	}
+	return true
+}
+
+// writeError writes err as a SCIM error, hiding internal failures.
+func (h *SCIMHandler) writeError(w http.ResponseWriter, err error) {
+	var scimErr *scim.Error
+	if errors.As(err, &scimErr) {
+		scim.WriteError(w, scimErr)
+		return
+	}
+	h.logger.Error("scim request failed", "error", err)
+	scim.WriteError(w, &scim.Error{Status: http.StatusInternalServerError, Detail: "Internal server error"})
+}
+
+// ============================================================================
+// Org SCIM configuration (/v1/orgs/{orgID}/scim, owners only)
+// ============================================================================
+
+// SCIMTokenResponse is returned once when a SCIM token is generated.
+type SCIMTokenResponse struct {
+	Token       string `json:"token"`
+	TokenPrefix string `json:"token_prefix"`
+	Endpoint    string `json:"endpoint"`
+}
+
+// SetSCIMMappingsRequest replaces an org's group to role mappings.
+type SetSCIMMappingsRequest struct {
+	Mappings []models.SCIMGroupMapping `json:"mappings"`
+}
+
+// Status handles GET /v1/orgs/{orgID}/scim - returns the SCIM configuration
+// and recent sync events.
+func (h *SCIMHandler) Status(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID) {
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get scim status", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to get SCIM status")
+		return
+	}
+	WriteJSON(w, http.StatusOK, status)
+}
+
+// GenerateToken handles POST /v1/orgs/{orgID}/scim/token - creates or rotates
+// the org's SCIM token. The token is only shown in this response.
+func (h *SCIMHandler) GenerateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID) {
+		return
+	}
+
+	token, err := h.service.GenerateToken(ctx, orgID, middleware.GetUserID(ctx))
+	if err != nil {
+		h.logger.Error("failed to generate scim token", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to generate SCIM token")
+		return
+	}
+
+	status, err := h.service.Status(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to get scim status", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to generate SCIM token")
+		return
+	}
+	WriteJSON(w, http.StatusCreated, SCIMTokenResponse{
+		Token:       token,
+		TokenPrefix: status.Token.TokenPrefix,
+		Endpoint:    "/scim/v2",
+	})
+}
+
+// RevokeToken handles DELETE /v1/orgs/{orgID}/scim/token - disables SCIM
+// provisioning. Provisioned users and memberships are kept.
+func (h *SCIMHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID) {
+		return
+	}
+
+	if err := h.service.RevokeToken(r.Context(), orgID); err != nil {
+		h.logger.Error("failed to revoke scim token", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to disable SCIM")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetMappings handles PUT /v1/orgs/{orgID}/scim/mappings - replaces the group
+// to role mappings and re-syncs roles.
+func (h *SCIMHandler) SetMappings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := chi.URLParam(r, "orgID")
+	if !h.requireOwner(w, r, orgID) {
+		return
+	}
+
+	var req SetSCIMMappingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if err := h.service.SetMappings(ctx, orgID, req.Mappings); err != nil {
+		var scimErr *scim.Error
+		if errors.As(err, &scimErr) {
+			WriteBadRequest(w, scimErr.Detail)
+			return
+		}
+		h.logger.Error("failed to set scim mappings", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to update group mappings")
+		return
+	}
+
+	status, err := h.service.Status(ctx, orgID)
+	if err != nil {
+		h.logger.Error("failed to get scim status", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to get SCIM status")
+		return
+	}
+	WriteJSON(w, http.StatusOK, status)
+}
+
+// requireOwner writes a forbidden response unless the current user is an
+// org member allowed to manage users.
+func (h *SCIMHandler) requireOwner(w http.ResponseWriter, r *http.Request, orgID string) bool {
+	return requireOrgPermissions(w, r, h.store, h.logger, orgID, "Only owners can manage SCIM provisioning", auth.PermissionManageUsers)
+}
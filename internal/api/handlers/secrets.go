@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/hooks"
 	"github.com/narvanalabs/control-plane/internal/secrets"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
@@ -16,6 +20,7 @@ import (
 type SecretHandler struct {
 	store       store.Store
 	sopsService *secrets.SOPSService
+	hooks       *hooks.Trigger
 	logger      *slog.Logger
 }
 
@@ -28,6 +33,11 @@ func NewSecretHandler(st store.Store, sopsService *secrets.SOPSService, logger *
 	}
 }
 
+// SetHooks sets the trigger told when a secret is set or deleted.
+func (h *SecretHandler) SetHooks(t *hooks.Trigger) {
+	h.hooks = t
+}
+
 // CreateSecretRequest represents the request body for creating a secret.
 type CreateSecretRequest struct {
 	Key   string `json:"key"`
@@ -42,13 +52,21 @@ func (r *CreateSecretRequest) Validate() error {
 	if r.Value == "" {
 		return &APIError{Code: ErrCodeInvalidRequest, Message: "value is required"}
 	}
-	// Validate key format (alphanumeric and underscores only)
-	for _, c := range r.Key {
+	if !validSecretKey(r.Key) {
+		return &APIError{Code: ErrCodeInvalidRequest, Message: "key must contain only alphanumeric characters and underscores"}
+	}
+	return nil
+}
+
+// validSecretKey reports whether a key contains only alphanumeric characters
+// and underscores.
+func validSecretKey(key string) bool {
+	for _, c := range key {
 		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
-			return &APIError{Code: ErrCodeInvalidRequest, Message: "key must contain only alphanumeric characters and underscores"}
+			return false
 		}
 	}
-	return nil
+	return true
 }
 
 // Create handles POST /v1/apps/:appID/secrets - creates or updates a secret.
@@ -78,20 +96,11 @@ func (h *SecretHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Encrypt the value using SOPS
-	var encryptedValue []byte
-	var err error
-	if h.sopsService != nil && h.sopsService.CanEncrypt() {
-		encryptedValue, err = h.sopsService.Encrypt(r.Context(), []byte(req.Value))
-		if err != nil {
-			h.logger.Error("failed to encrypt secret with SOPS", "error", err)
-			WriteInternalError(w, "Failed to encrypt secret")
-			return
-		}
-	} else {
-		// Fallback: store plaintext if SOPS is not configured (for testing/development)
-		h.logger.Warn("SOPS not configured, storing secret without encryption")
-		encryptedValue = []byte(req.Value)
+	encryptedValue, err := h.encrypt(r.Context(), req.Value)
+	if err != nil {
+		h.logger.Error("failed to encrypt secret with SOPS", "error", err)
+		WriteInternalError(w, "Failed to encrypt secret")
+		return
 	}
 
 	// Store the secret
@@ -102,12 +111,97 @@ func (h *SecretHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.logger.Info("secret created", "app_id", appID, "key", req.Key)
+	if h.hooks != nil {
+		h.hooks.SecretChanged(r.Context(), appID, strings.ToUpper(req.Key), "set")
+	}
 	WriteJSON(w, http.StatusCreated, map[string]string{
 		"key":    strings.ToUpper(req.Key),
 		"status": "created",
 	})
 }
 
+// PutSecretRequest represents the request body for setting a secret by key.
+type PutSecretRequest struct {
+	Value string `json:"value"`
+}
+
+// PutSecretResponse describes a secret set by key. Its ETag digests the
+// stored value, so clients can tell whether it changed without reading it.
+type PutSecretResponse struct {
+	Key    string `json:"key"`
+	Status string `json:"status"` // created, updated or unchanged
+	ETag   string `json:"etag"`
+}
+
+// Put handles PUT /v1/apps/:appID/secrets/:key - sets a secret. Setting a
+// secret to its current value stores nothing, so the request is safe to
+// repeat. If-Match compares the secret's ETag; If-None-Match: * only creates.
+func (h *SecretHandler) Put(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return
+	}
+
+	var req PutSecretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	create := CreateSecretRequest{Key: chi.URLParam(r, "key"), Value: req.Value}
+	if err := create.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	key := strings.ToUpper(create.Key)
+
+	current, err := h.store.Secrets().GetAll(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to get secrets", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to get secret")
+		return
+	}
+	stored, exists := current[key]
+	etag := ""
+	if exists {
+		etag = resourceETag(stored)
+	}
+	if !checkPreconditions(w, r, etag) {
+		return
+	}
+	if exists && h.decrypt(r.Context(), key, stored) == req.Value {
+		w.Header().Set("ETag", etag)
+		WriteJSON(w, http.StatusOK, PutSecretResponse{Key: key, Status: "unchanged", ETag: etag})
+		return
+	}
+
+	encryptedValue, err := h.encrypt(r.Context(), req.Value)
+	if err != nil {
+		h.logger.Error("failed to encrypt secret with SOPS", "error", err)
+		WriteInternalError(w, "Failed to encrypt secret")
+		return
+	}
+	if err := h.store.Secrets().Set(r.Context(), appID, key, encryptedValue); err != nil {
+		h.logger.Error("failed to store secret", "error", err)
+		WriteInternalError(w, "Failed to store secret")
+		return
+	}
+
+	status, resp := http.StatusOK, PutSecretResponse{Key: key, Status: "updated", ETag: resourceETag(encryptedValue)}
+	if !exists {
+		status, resp.Status = http.StatusCreated, "created"
+	}
+	h.logger.Info("secret "+resp.Status, "app_id", appID, "key", key)
+	if h.hooks != nil {
+		h.hooks.SecretChanged(r.Context(), appID, key, "set")
+	}
+	w.Header().Set("ETag", resp.ETag)
+	WriteJSON(w, status, resp)
+}
+
 // SecretResponse represents a secret in the API response.
 type SecretResponse struct {
 	Key   string `json:"key"`
@@ -137,26 +231,39 @@ func (h *SecretHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	secrets := make([]SecretResponse, 0, len(encryptedSecrets))
 	for key, encryptedValue := range encryptedSecrets {
-		var value string
-		// Decrypt the value if SOPS is configured and can decrypt
-		if h.sopsService != nil && h.sopsService.CanDecrypt() {
-			decrypted, err := h.sopsService.Decrypt(r.Context(), encryptedValue)
-			if err != nil {
-				h.logger.Warn("failed to decrypt secret, returning as-is", "key", key, "error", err)
-				value = string(encryptedValue)
-			} else {
-				value = string(decrypted)
-			}
-		} else {
-			// No encryption configured or no private key, value is stored as plaintext
-			value = string(encryptedValue)
-		}
-		secrets = append(secrets, SecretResponse{Key: key, Value: value})
+		secrets = append(secrets, SecretResponse{Key: key, Value: h.decrypt(r.Context(), key, encryptedValue)})
 	}
 
 	WriteJSON(w, http.StatusOK, map[string][]SecretResponse{"secrets": secrets})
 }
 
+// encrypt encrypts a secret value with SOPS, or returns it as-is if SOPS is
+// not configured.
+func (h *SecretHandler) encrypt(ctx context.Context, value string) ([]byte, error) {
+	if h.sopsService != nil && h.sopsService.CanEncrypt() {
+		return h.sopsService.Encrypt(ctx, []byte(value))
+	}
+	// Fallback: store plaintext if SOPS is not configured (for testing/development)
+	h.logger.Warn("SOPS not configured, storing secret without encryption")
+	return []byte(value), nil
+}
+
+// decrypt decrypts a stored secret value. Values that cannot be decrypted,
+// or are stored as plaintext, are returned as-is.
+func (h *SecretHandler) decrypt(ctx context.Context, key string, encryptedValue []byte) string {
+	// Decrypt the value if SOPS is configured and can decrypt
+	if h.sopsService != nil && h.sopsService.CanDecrypt() {
+		decrypted, err := h.sopsService.Decrypt(ctx, encryptedValue)
+		if err != nil {
+			h.logger.Warn("failed to decrypt secret, returning as-is", "key", key, "error", err)
+			return string(encryptedValue)
+		}
+		return string(decrypted)
+	}
+	// No encryption configured or no private key, value is stored as plaintext
+	return string(encryptedValue)
+}
+
 // Delete handles DELETE /v1/apps/:appID/secrets/:key - deletes a secret.
 func (h *SecretHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Use resolved app ID from middleware (handles both UUID and name lookup)
@@ -182,5 +289,237 @@ func (h *SecretHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.logger.Info("secret deleted", "app_id", appID, "key", key)
+	if h.hooks != nil {
+		h.hooks.SecretChanged(r.Context(), appID, strings.ToUpper(key), "deleted")
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// maxSecretImportSize limits the size of an imported dotenv or JSON file.
+const maxSecretImportSize = 1 << 20
+
+// Collision strategies for keys an import sets that the app already has.
+const (
+	secretConflictFail      = "fail"
+	secretConflictSkip      = "skip"
+	secretConflictOverwrite = "overwrite"
+)
+
+// SecretImportResponse reports which keys an import created, updated and skipped.
+type SecretImportResponse struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Skipped []string `json:"skipped"`
+}
+
+// Import handles POST /v1/apps/:appID/secrets/import - sets many secrets at
+// once from a dotenv file or a JSON object of string values. The format is
+// taken from the format query parameter, or else the Content-Type. The
+// on_conflict parameter decides what happens to keys the app already has:
+// fail (the default) rejects the import, skip keeps the existing values and
+// overwrite replaces them. Either every secret is set or none are.
+func (h *SecretHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	// Use resolved app ID from middleware (handles both UUID and name lookup)
+	appID := middleware.GetResolvedAppID(ctx)
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return
+	}
+
+	onConflict := r.URL.Query().Get("on_conflict")
+	switch onConflict {
+	case "":
+		onConflict = secretConflictFail
+	case secretConflictFail, secretConflictSkip, secretConflictOverwrite:
+	default:
+		WriteBadRequest(w, "on_conflict must be fail, skip or overwrite")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "dotenv"
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			format = "json"
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSecretImportSize+1))
+	if err != nil {
+		WriteBadRequest(w, "Failed to read request body")
+		return
+	}
+	if len(body) > maxSecretImportSize {
+		WriteError(w, http.StatusRequestEntityTooLarge, ErrCodeInvalidRequest, "Import is larger than 1 MB")
+		return
+	}
+
+	var parsed map[string]string
+	switch format {
+	case "dotenv":
+		if parsed, err = secrets.ParseDotenv(string(body)); err != nil {
+			WriteBadRequest(w, "Invalid dotenv file: "+err.Error())
+			return
+		}
+	case "json":
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			WriteBadRequest(w, "Invalid JSON: expected an object of string values")
+			return
+		}
+	default:
+		WriteBadRequest(w, "format must be dotenv or json")
+		return
+	}
+	if len(parsed) == 0 {
+		WriteBadRequest(w, "No secrets to import")
+		return
+	}
+
+	// Validate everything before setting anything
+	values := make(map[string]string, len(parsed))
+	var problems []string
+	for key, value := range parsed {
+		upper := strings.ToUpper(key)
+		switch {
+		case !validSecretKey(key):
+			problems = append(problems, key+": key must contain only alphanumeric characters and underscores")
+		case value == "":
+			problems = append(problems, key+": value is required")
+		case strings.HasPrefix(value, "********"):
+			problems = append(problems, key+": value is masked, export with reveal=true to migrate secrets")
+		default:
+			values[upper] = value
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		WriteErrorWithDetails(w, http.StatusBadRequest, ErrCodeInvalidRequest, "Invalid secrets", problems)
+		return
+	}
+
+	existingKeys, err := h.store.Secrets().List(ctx, appID)
+	if err != nil {
+		h.logger.Error("failed to list secrets", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list secrets")
+		return
+	}
+	existing := make(map[string]bool, len(existingKeys))
+	for _, key := range existingKeys {
+		existing[key] = true
+	}
+
+	resp := SecretImportResponse{Created: []string{}, Updated: []string{}, Skipped: []string{}}
+	var conflicts []string
+	for key := range values {
+		switch {
+		case !existing[key]:
+			resp.Created = append(resp.Created, key)
+		case onConflict == secretConflictOverwrite:
+			resp.Updated = append(resp.Updated, key)
+		case onConflict == secretConflictSkip:
+			resp.Skipped = append(resp.Skipped, key)
+		default:
+			conflicts = append(conflicts, key)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		WriteErrorWithDetails(w, http.StatusConflict, ErrCodeConflict,
+			"Secrets already exist: "+strings.Join(conflicts, ", ")+" (use on_conflict=skip or on_conflict=overwrite)", conflicts)
+		return
+	}
+	sort.Strings(resp.Created)
+	sort.Strings(resp.Updated)
+	sort.Strings(resp.Skipped)
+
+	toSet := append(append([]string(nil), resp.Created...), resp.Updated...)
+	encrypted := make(map[string][]byte, len(toSet))
+	for _, key := range toSet {
+		if encrypted[key], err = h.encrypt(ctx, values[key]); err != nil {
+			h.logger.Error("failed to encrypt secret with SOPS", "error", err)
+			WriteInternalError(w, "Failed to encrypt secret")
+			return
+		}
+	}
+
+	err = h.store.WithTx(ctx, func(tx store.Store) error {
+		for _, key := range toSet {
+			if err := tx.Secrets().Set(ctx, appID, key, encrypted[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error("failed to import secrets", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to store secrets")
+		return
+	}
+
+	h.logger.Info("secrets imported", "app_id", appID,
+		"created", len(resp.Created), "updated", len(resp.Updated), "skipped", len(resp.Skipped))
+	if h.hooks != nil {
+		for _, key := range toSet {
+			h.hooks.SecretChanged(ctx, appID, key, "set")
+		}
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// Export handles GET /v1/apps/:appID/secrets/export - downloads an app's
+// secrets as a dotenv file or, with format=json, a JSON object. Values are
+// masked unless reveal=true is given, so the export can be shared safely;
+// revealed exports can be imported into another app or environment.
+func (h *SecretHandler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	// Use resolved app ID from middleware (handles both UUID and name lookup)
+	appID := middleware.GetResolvedAppID(ctx)
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "dotenv"
+	}
+	if format != "dotenv" && format != "json" {
+		WriteBadRequest(w, "format must be dotenv or json")
+		return
+	}
+	reveal := r.URL.Query().Get("reveal") == "true"
+
+	encryptedSecrets, err := h.store.Secrets().GetAll(ctx, appID)
+	if err != nil {
+		h.logger.Error("failed to list secrets", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list secrets")
+		return
+	}
+
+	vars := make(map[string]string, len(encryptedSecrets))
+	for key, encryptedValue := range encryptedSecrets {
+		value := h.decrypt(ctx, key, encryptedValue)
+		if !reveal {
+			value = maskSecretValue(value)
+		}
+		vars[key] = value
+	}
+	if reveal {
+		h.logger.Info("secrets exported with values", "app_id", appID, "user_id", middleware.GetUserID(ctx), "count", len(vars))
+	}
+
+	if format == "json" {
+		WriteJSON(w, http.StatusOK, vars)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, secrets.FormatDotenv(vars))
+}
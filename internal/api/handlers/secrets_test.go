@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// importSecretStore keeps an app's secrets in a map.
+type importSecretStore struct {
+	store.SecretStore
+	values map[string][]byte
+}
+
+func (s *importSecretStore) Set(ctx context.Context, appID, key string, value []byte) error {
+	s.values[key] = value
+	return nil
+}
+
+func (s *importSecretStore) List(ctx context.Context, appID string) ([]string, error) {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s *importSecretStore) GetAll(ctx context.Context, appID string) (map[string][]byte, error) {
+	return s.values, nil
+}
+
+// importMockStore adds secrets to the deployment mock store.
+type importMockStore struct {
+	*deploymentMockStore
+	secrets *importSecretStore
+}
+
+func (m *importMockStore) Secrets() store.SecretStore { return m.secrets }
+
+func (m *importMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func secretRequest(method, target, contentType, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("appID", "app-1")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestImportSecretsConflictStrategies(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	dotenv := "# copied from staging\nDATABASE_URL=postgres://db/app\nexport api_key=new\n"
+
+	tests := []struct {
+		name       string
+		onConflict string
+		wantCode   int
+		wantResp   SecretImportResponse
+		wantAPIKey string
+	}{
+		{"fail by default", "", http.StatusConflict, SecretImportResponse{}, "old"},
+		{"skip", "skip", http.StatusOK, SecretImportResponse{Created: []string{"DATABASE_URL"}, Updated: []string{}, Skipped: []string{"API_KEY"}}, "old"},
+		{"overwrite", "overwrite", http.StatusOK, SecretImportResponse{Created: []string{"DATABASE_URL"}, Updated: []string{"API_KEY"}, Skipped: []string{}}, "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets := &importSecretStore{values: map[string][]byte{"API_KEY": []byte("old")}}
+			h := NewSecretHandler(&importMockStore{deploymentMockStore: newDeploymentMockStore(), secrets: secrets}, nil, logger)
+
+			rec := httptest.NewRecorder()
+			h.Import(rec, secretRequest(http.MethodPost, "/v1/apps/app-1/secrets/import?on_conflict="+tt.onConflict, "text/plain", dotenv))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				var resp SecretImportResponse
+				json.NewDecoder(rec.Body).Decode(&resp)
+				if !slices.Equal(resp.Created, tt.wantResp.Created) || !slices.Equal(resp.Updated, tt.wantResp.Updated) || !slices.Equal(resp.Skipped, tt.wantResp.Skipped) {
+					t.Errorf("response = %+v, want %+v", resp, tt.wantResp)
+				}
+			} else if _, ok := secrets.values["DATABASE_URL"]; ok {
+				t.Error("a failed import set DATABASE_URL")
+			}
+			if got := string(secrets.values["API_KEY"]); got != tt.wantAPIKey {
+				t.Errorf("API_KEY = %q, want %q", got, tt.wantAPIKey)
+			}
+		})
+	}
+}
+
+func TestImportSecretsRejectsInvalidInput(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+	}{
+		{"invalid key", "/v1/apps/app-1/secrets/import", "text/plain", "BAD-KEY=value\n"},
+		{"masked value", "/v1/apps/app-1/secrets/import", "application/json", `{"API_KEY": "********"}`},
+		{"non-string JSON value", "/v1/apps/app-1/secrets/import", "application/json", `{"PORT": 8080}`},
+		{"unknown strategy", "/v1/apps/app-1/secrets/import?on_conflict=merge", "text/plain", "A=b\n"},
+		{"empty", "/v1/apps/app-1/secrets/import", "text/plain", "# nothing here\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets := &importSecretStore{values: map[string][]byte{}}
+			h := NewSecretHandler(&importMockStore{deploymentMockStore: newDeploymentMockStore(), secrets: secrets}, nil, logger)
+			rec := httptest.NewRecorder()
+			h.Import(rec, secretRequest(http.MethodPost, tt.target, tt.contentType, tt.body))
+			if rec.Code != http.StatusBadRequest || len(secrets.values) != 0 {
+				t.Errorf("status = %d with %d secrets set, want 400 and none", rec.Code, len(secrets.values))
+			}
+		})
+	}
+}
+
+func TestExportSecretsMasksValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	secrets := &importSecretStore{values: map[string][]byte{
+		"API_KEY":      []byte("sk_live_0123456789"),
+		"DATABASE_URL": []byte("postgres://db/app"),
+	}}
+	h := NewSecretHandler(&importMockStore{deploymentMockStore: newDeploymentMockStore(), secrets: secrets}, nil, logger)
+
+	rec := httptest.NewRecorder()
+	h.Export(rec, secretRequest(http.MethodGet, "/v1/apps/app-1/secrets/export", "", ""))
+	if want := "API_KEY=********6789\nDATABASE_URL=********/app\n"; rec.Body.String() != want {
+		t.Errorf("masked export = %q, want %q", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Export(rec, secretRequest(http.MethodGet, "/v1/apps/app-1/secrets/export?format=json&reveal=true", "", ""))
+	var vars map[string]string
+	json.NewDecoder(rec.Body).Decode(&vars)
+	if vars["API_KEY"] != "sk_live_0123456789" || vars["DATABASE_URL"] != "postgres://db/app" {
+		t.Errorf("revealed export = %v", vars)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/dbhealth"
+)
+
+// ServerDBHealthHandler reports the size, growth and bloat of the control
+// plane's database.
+type ServerDBHealthHandler struct {
+	maintainer *dbhealth.Maintainer
+	logger     *slog.Logger
+}
+
+// NewServerDBHealthHandler creates a new database health handler.
+func NewServerDBHealthHandler(m *dbhealth.Maintainer, logger *slog.Logger) *ServerDBHealthHandler {
+	return &ServerDBHealthHandler{maintainer: m, logger: logger}
+}
+
+// Get handles GET /v1/server/db-health - returns the database's size, the
+// size, daily growth and dead rows of every table with VACUUM or pg_repack
+// advice for bloated ones, the orphaned queue jobs and log rows awaiting
+// cleanup, and the outcome of the last maintenance run.
+func (h *ServerDBHealthHandler) Get(w http.ResponseWriter, r *http.Request) {
+	report, err := h.maintainer.Report(r.Context())
+	if err != nil {
+		h.logger.Error("failed to report database health", "error", err)
+		WriteInternalError(w, "Failed to report database health")
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
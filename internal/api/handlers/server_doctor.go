@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/doctor"
+)
+
+// ServerDoctorHandler reports installation problems and how to fix them.
+type ServerDoctorHandler struct {
+	doctor *doctor.Doctor
+}
+
+// NewServerDoctorHandler creates a new server doctor handler.
+func NewServerDoctorHandler(d *doctor.Doctor) *ServerDoctorHandler {
+	return &ServerDoctorHandler{doctor: d}
+}
+
+// Get handles GET /v1/server/doctor - checks the database, migrations,
+// registry, Attic cache, build workers' Podman, node clocks and the JWT
+// secret, returning a finding with a severity for each.
+func (h *ServerDoctorHandler) Get(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, h.doctor.Run(r.Context()))
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/loglevel"
+)
+
+// ServerLogLevelHandler changes the log levels of the API server, the gRPC
+// server and the build workers at runtime.
+type ServerLogLevelHandler struct {
+	controller *loglevel.Controller
+	logger     *slog.Logger
+}
+
+// NewServerLogLevelHandler creates a new log level handler.
+func NewServerLogLevelHandler(c *loglevel.Controller, logger *slog.Logger) *ServerLogLevelHandler {
+	return &ServerLogLevelHandler{controller: c, logger: logger}
+}
+
+// SetLogLevelRequest changes the log level of a component, or of every
+// component if Component is empty, for Duration (e.g. "30m").
+type SetLogLevelRequest struct {
+	Component string `json:"component,omitempty"`
+	Level     string `json:"level"`
+	Duration  string `json:"duration,omitempty"`
+}
+
+// Get handles GET /v1/server/log-level - returns the log level of each
+// component and when changed levels revert.
+func (h *ServerLogLevelHandler) Get(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.controller.Status(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get log levels", "error", err)
+		WriteInternalError(w, "Failed to get log levels")
+		return
+	}
+	WriteJSON(w, http.StatusOK, statuses)
+}
+
+// Update handles PATCH /v1/server/log-level - changes the log level of the
+// api, worker or grpc component, or of all of them, without a restart. The
+// change reverts after the given duration or LOG_LEVEL_REVERT_AFTER.
+func (h *ServerLogLevelHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		WriteBadRequest(w, "level must be debug, info, warn or error")
+		return
+	}
+	var d time.Duration
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			WriteBadRequest(w, "duration must be a duration such as 30m")
+			return
+		}
+	}
+
+	statuses, err := h.controller.Set(r.Context(), req.Component, level, d)
+	if err != nil {
+		if errors.Is(err, loglevel.ErrUnknownComponent) || errors.Is(err, loglevel.ErrInvalidDuration) {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to set log level", "error", err)
+		WriteInternalError(w, "Failed to set log level")
+		return
+	}
+
+	h.logger.Info("log level set", "component", req.Component, "level", level.String(),
+		"duration", req.Duration, "user_id", middleware.GetUserID(r.Context()))
+	WriteJSON(w, http.StatusOK, statuses)
+}
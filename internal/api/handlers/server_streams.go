@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/api/streams"
+)
+
+// ServerStreamsHandler reports open SSE and WebSocket connections.
+type ServerStreamsHandler struct {
+	registry *streams.Registry
+}
+
+// NewServerStreamsHandler creates a new server streams handler.
+func NewServerStreamsHandler(registry *streams.Registry) *ServerStreamsHandler {
+	return &ServerStreamsHandler{registry: registry}
+}
+
+// Get handles GET /v1/server/streams - returns open stream counts by kind,
+// route, user and app, along with the configured limits.
+func (h *ServerStreamsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, h.registry.Snapshot())
+}
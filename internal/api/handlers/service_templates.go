@@ -0,0 +1,553 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/internal/validation"
+)
+
+// ServiceTemplateRequest represents the request body for creating or
+// replacing a service template. Name is ignored when replacing.
+type ServiceTemplateRequest struct {
+	Name        string                           `json:"name"`
+	Description string                           `json:"description,omitempty"`
+	Variables   []models.ServiceTemplateVariable `json:"variables,omitempty"`
+	Service     models.ServiceConfig             `json:"service"`
+}
+
+// ServiceTemplateResponse represents a service template with the names of
+// the services instantiated from it.
+type ServiceTemplateResponse struct {
+	*models.ServiceTemplate
+	Instances []string `json:"instances"`
+}
+
+// TemplateInstanceRequest represents the request body for instantiating a
+// template or changing an instance's parameters. Instance is ignored when
+// changing parameters.
+type TemplateInstanceRequest struct {
+	Instance string            `json:"instance"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// ListTemplates handles GET /v1/apps/{appID}/service-templates - lists an app's service templates.
+func (h *ServiceHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.store.ServiceTemplates().List(r.Context(), app.ID)
+	if err != nil {
+		h.logger.Error("failed to list service templates", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to list service templates")
+		return
+	}
+
+	result := make([]ServiceTemplateResponse, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, templateResponse(app, t))
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+// GetTemplate handles GET /v1/apps/{appID}/service-templates/{templateName} - retrieves a service template.
+func (h *ServiceHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.findTemplate(w, r, app)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, templateResponse(app, t))
+}
+
+// CreateTemplate handles POST /v1/apps/{appID}/service-templates - creates a service template.
+func (h *ServiceHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+
+	var req ServiceTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if err := validation.ValidateServiceName(req.Name); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	t := &models.ServiceTemplate{
+		AppID:       app.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Variables:   req.Variables,
+		Service:     req.Service,
+	}
+	if err := h.prepareTemplate(r, t); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	existing, err := h.store.ServiceTemplates().Get(r.Context(), app.ID, t.Name)
+	if err != nil {
+		h.logger.Error("failed to check existing service template", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to create service template")
+		return
+	}
+	if existing != nil {
+		WriteConflict(w, "A service template with this name already exists")
+		return
+	}
+
+	if err := h.store.ServiceTemplates().Create(r.Context(), t); err != nil {
+		h.logger.Error("failed to create service template", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to create service template")
+		return
+	}
+
+	h.logger.Info("service template created", "app_id", app.ID, "template", t.Name)
+	WriteJSON(w, http.StatusCreated, templateResponse(app, t))
+}
+
+// UpdateTemplate handles PUT /v1/apps/{appID}/service-templates/{templateName} -
+// replaces a service template and re-renders all of its instances with their
+// parameters.
+func (h *ServiceHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.findTemplate(w, r, app)
+	if !ok {
+		return
+	}
+
+	var req ServiceTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	t.Description = req.Description
+	t.Variables = req.Variables
+	t.Service = req.Service
+	if err := h.prepareTemplate(r, t); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	// Render every instance before saving anything, so a parameter set that
+	// no longer fits the template rejects the whole update
+	for i := range app.Services {
+		ref := app.Services[i].Template
+		if ref == nil || ref.Name != t.Name {
+			continue
+		}
+		svc, err := h.renderInstance(app, t, ref.Instance, ref.Params)
+		if err != nil {
+			WriteBadRequest(w, fmt.Sprintf("Instance %s: %v", ref.Instance, err))
+			return
+		}
+		app.Services[i] = svc
+	}
+
+	err := h.store.WithTx(r.Context(), func(tx store.Store) error {
+		if err := tx.ServiceTemplates().Update(r.Context(), t); err != nil {
+			return err
+		}
+		app.UpdatedAt = time.Now()
+		return tx.Apps().Update(r.Context(), app)
+	})
+	if err != nil {
+		h.logger.Error("failed to update service template", "error", err, "app_id", app.ID, "template", t.Name)
+		WriteInternalError(w, "Failed to update service template")
+		return
+	}
+
+	resp := templateResponse(app, t)
+	h.logger.Info("service template updated", "app_id", app.ID, "template", t.Name, "instances", len(resp.Instances))
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// DeleteTemplate handles DELETE /v1/apps/{appID}/service-templates/{templateName} -
+// deletes a service template that has no instances left.
+func (h *ServiceHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.findTemplate(w, r, app)
+	if !ok {
+		return
+	}
+
+	if instances := templateInstances(app, t.Name); len(instances) > 0 {
+		WriteConflict(w, "Delete the template's instances first: "+formatDependents(instances))
+		return
+	}
+
+	if err := h.store.ServiceTemplates().Delete(r.Context(), app.ID, t.Name); err != nil {
+		h.logger.Error("failed to delete service template", "error", err, "app_id", app.ID, "template", t.Name)
+		WriteInternalError(w, "Failed to delete service template")
+		return
+	}
+
+	h.logger.Info("service template deleted", "app_id", app.ID, "template", t.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateInstance handles POST /v1/apps/{appID}/service-templates/{templateName}/instances -
+// adds a service named <template>-<instance> rendered with the given parameters.
+func (h *ServiceHandler) CreateInstance(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.findTemplate(w, r, app)
+	if !ok {
+		return
+	}
+
+	var req TemplateInstanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Instance == "" {
+		WriteBadRequest(w, "instance is required")
+		return
+	}
+
+	svc, err := h.renderInstance(app, t, req.Instance, req.Params)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	maxServices := 50 // Default limit
+	if maxServicesStr, err := h.store.Settings().Get(r.Context(), "max_services_per_app"); err == nil && maxServicesStr != "" {
+		if n, err := parseIntSetting(maxServicesStr); err == nil && n > 0 {
+			maxServices = n
+		}
+	}
+	if len(app.Services) >= maxServices {
+		WriteBadRequest(w, fmt.Sprintf("Maximum services per app (%d) reached. Delete unused services or contact administrator.", maxServices))
+		return
+	}
+	for _, existing := range app.Services {
+		if existing.Name == svc.Name {
+			WriteConflict(w, "A service with this name already exists")
+			return
+		}
+	}
+
+	if err := h.store.Services().Create(r.Context(), app.ID, &svc); err != nil {
+		h.logger.Error("failed to add template instance", "error", err, "app_id", app.ID, "service_name", svc.Name)
+		writeServiceStoreError(w, err, "Failed to create service")
+		return
+	}
+
+	h.logger.Info("template instance created", "app_id", app.ID, "template", t.Name, "service_name", svc.Name)
+	WriteJSON(w, http.StatusCreated, svc)
+}
+
+// UpdateInstance handles PUT /v1/apps/{appID}/service-templates/{templateName}/instances/{instance} -
+// re-renders an instance with new parameters.
+func (h *ServiceHandler) UpdateInstance(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.templateApp(w, r)
+	if !ok {
+		return
+	}
+	t, ok := h.findTemplate(w, r, app)
+	if !ok {
+		return
+	}
+	instance := chi.URLParam(r, "instance")
+
+	var req TemplateInstanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	index := -1
+	for i, svc := range app.Services {
+		if svc.Template != nil && svc.Template.Name == t.Name && svc.Template.Instance == instance {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		WriteNotFound(w, "Template instance not found")
+		return
+	}
+
+	svc, err := h.renderInstance(app, t, instance, req.Params)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	svc.Version = app.Services[index].Version
+	if err := h.store.Services().Update(r.Context(), app.ID, &svc); err != nil {
+		h.logger.Error("failed to update template instance", "error", err, "app_id", app.ID, "service_name", svc.Name)
+		writeServiceStoreError(w, err, "Failed to update service")
+		return
+	}
+
+	h.logger.Info("template instance updated", "app_id", app.ID, "template", t.Name, "service_name", svc.Name)
+	WriteJSON(w, http.StatusOK, svc)
+}
+
+// templateApp loads the app of a template request and checks its owner.
+func (h *ServiceHandler) templateApp(w http.ResponseWriter, r *http.Request) (*models.App, bool) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	if appID == "" {
+		WriteBadRequest(w, "Application ID is required")
+		return nil, false
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		WriteNotFound(w, "Application not found")
+		return nil, false
+	}
+	if app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return nil, false
+	}
+	return app, true
+}
+
+// findTemplate loads the template named in the URL.
+func (h *ServiceHandler) findTemplate(w http.ResponseWriter, r *http.Request, app *models.App) (*models.ServiceTemplate, bool) {
+	name := chi.URLParam(r, "templateName")
+	t, err := h.store.ServiceTemplates().Get(r.Context(), app.ID, name)
+	if err != nil {
+		h.logger.Error("failed to get service template", "error", err, "app_id", app.ID, "template", name)
+		WriteInternalError(w, "Failed to get service template")
+		return nil, false
+	}
+	if t == nil {
+		WriteNotFound(w, "Service template not found")
+		return nil, false
+	}
+	return t, true
+}
+
+// prepareTemplate applies the defaults of new services to a template's
+// service and validates the template.
+func (h *ServiceHandler) prepareTemplate(r *http.Request, t *models.ServiceTemplate) error {
+	svc := &t.Service
+	svc.Name = t.Name
+	svc.Template = nil
+	if svc.Resources == nil {
+		svc.Resources = h.getDefaultResources(r.Context())
+	} else if err := validation.ValidateResourceSpec(svc.Resources); err != nil {
+		return err
+	}
+	if err := validation.ValidateEgressPolicy(svc.Egress); err != nil {
+		return err
+	}
+	if svc.Replicas <= 0 {
+		svc.Replicas = 1
+	}
+	if len(svc.Ports) == 0 {
+		svc.Ports = []models.PortMapping{{ContainerPort: 8080, Protocol: "tcp"}}
+	}
+	if svc.BuildStrategy == "" {
+		svc.BuildStrategy = models.BuildStrategyFlake
+	}
+	if !svc.BuildStrategy.IsValid() {
+		return fmt.Errorf("invalid build_strategy %q", svc.BuildStrategy)
+	}
+	return t.Validate()
+}
+
+// renderInstance renders a template instance and validates it like a new
+// service, including its dependencies on the app's other services.
+func (h *ServiceHandler) renderInstance(app *models.App, t *models.ServiceTemplate, instance string, params map[string]string) (models.ServiceConfig, error) {
+	svc, err := t.Render(instance, params)
+	if err != nil {
+		return svc, err
+	}
+	if err := validation.ValidateServiceName(svc.Name); err != nil {
+		return svc, err
+	}
+	if err := svc.Validate(); err != nil {
+		return svc, err
+	}
+
+	if len(svc.DependsOn) > 0 {
+		if err := h.dependencyValidator.ValidateDependencies(app.Services, svc.Name, svc.DependsOn); err != nil {
+			return svc, err
+		}
+		existing := make(map[string]bool, len(app.Services))
+		for _, s := range app.Services {
+			existing[s.Name] = true
+		}
+		for _, dep := range svc.DependsOn {
+			if !existing[dep] {
+				return svc, fmt.Errorf("dependency '%s' not found in app", dep)
+			}
+		}
+	}
+	return svc, nil
+}
+
+// templateInstances returns the names of the services instantiated from a template.
+func templateInstances(app *models.App, template string) []string {
+	instances := []string{}
+	for _, svc := range app.Services {
+		if svc.Template != nil && svc.Template.Name == template {
+			instances = append(instances, svc.Name)
+		}
+	}
+	return instances
+}
+
+func templateResponse(app *models.App, t *models.ServiceTemplate) ServiceTemplateResponse {
+	return ServiceTemplateResponse{ServiceTemplate: t, Instances: templateInstances(app, t.Name)}
+}
+
+// DeployTemplate handles POST /v1/apps/{appID}/service-templates/{templateName}/deploy -
+// deploys every instance of a service template.
+func (h *DeploymentHandler) DeployTemplate(w http.ResponseWriter, r *http.Request) {
+	appID := middleware.GetResolvedAppID(r.Context())
+	if appID == "" {
+		appID = chi.URLParam(r, "appID")
+	}
+	templateName := chi.URLParam(r, "templateName")
+
+	var req ServiceDeployRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	app, err := h.store.Apps().Get(r.Context(), appID)
+	if err != nil {
+		WriteNotFound(w, "Application not found")
+		return
+	}
+	if app.OwnerID != middleware.GetUserID(r.Context()) {
+		WriteForbidden(w, "Access denied")
+		return
+	}
+
+	t, err := h.store.ServiceTemplates().Get(r.Context(), app.ID, templateName)
+	if err != nil {
+		h.logger.Error("failed to get service template", "error", err, "app_id", app.ID, "template", templateName)
+		WriteInternalError(w, "Failed to get service template")
+		return
+	}
+	if t == nil {
+		WriteNotFound(w, "Service template not found")
+		return
+	}
+
+	var instances []models.ServiceConfig
+	for _, svc := range app.Services {
+		if svc.Template != nil && svc.Template.Name == t.Name {
+			instances = append(instances, svc)
+		}
+	}
+	if len(instances) == 0 {
+		WriteBadRequest(w, "Service template has no instances")
+		return
+	}
+
+	// Block deploys during an org freeze window unless an owner overrides it
+	freeze, ok := h.checkDeployFreeze(w, r, app, req.FreezeOverride)
+	if !ok {
+		return
+	}
+	if !h.checkOnCall(w, r, app, req.OnCallAckID) {
+		return
+	}
+
+	subjects := make([]admission.Subject, 0, len(instances))
+	for i := range instances {
+		subjects = append(subjects, admissionSubject(app, &instances[i], req.GitRef))
+	}
+	if !h.checkAdmission(w, r, subjects) {
+		return
+	}
+
+	now := time.Now()
+	deployments := make([]*models.Deployment, 0, len(instances))
+	for _, svc := range sortServicesByDependency(instances) {
+		gitRef := req.GitRef
+		if gitRef == "" {
+			gitRef = svc.GitRef
+		}
+		buildType := determineBuildType(&svc)
+
+		version, err := h.store.Deployments().GetNextVersion(r.Context(), app.ID, svc.Name)
+		if err != nil {
+			h.logger.Error("failed to get next version", "error", err, "service", svc.Name)
+			WriteInternalError(w, "Failed to determine deployment version")
+			return
+		}
+
+		deployment := &models.Deployment{
+			ID:          uuid.New().String(),
+			AppID:       app.ID,
+			ServiceName: svc.Name,
+			Version:     version,
+			GitRef:      gitRef,
+			BuildType:   buildType,
+			Status:      models.DeploymentStatusPending,
+			Resources:   svc.Resources,
+			Config: &models.RuntimeConfig{
+				Resources:   svc.Resources,
+				EnvVars:     svc.EnvVars,
+				Ports:       svc.Ports,
+				HealthCheck: svc.HealthCheck,
+				Egress:      svc.Egress,
+			},
+			DependsOn: svc.DependsOn,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := h.store.Deployments().Create(r.Context(), deployment); err != nil {
+			h.logger.Error("failed to create deployment", "error", err)
+			WriteInternalError(w, "Failed to create deployment")
+			return
+		}
+		if freeze != nil {
+			h.recordFreezeOverride(r.Context(), freeze, deployment, req.FreezeOverride.Reason)
+		}
+
+		buildJob := h.createBuildJobForService(r.Context(), deployment.ID, app.ID, &svc, gitRef, buildType, now)
+		if buildJob != nil && h.queue != nil {
+			if err := h.queue.Enqueue(r.Context(), buildJob); err != nil {
+				h.logger.Error("failed to enqueue build job", "error", err, "deployment_id", deployment.ID)
+				// Don't fail the request, the deployment is created
+			}
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	h.logger.Info("template deployment triggered",
+		"app_id", app.ID,
+		"template", t.Name,
+		"deployment_count", len(deployments),
+	)
+	WriteJSON(w, http.StatusAccepted, deployments)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockServiceTemplateStore implements the parts of store.ServiceTemplateStore the handlers use.
+type mockServiceTemplateStore struct {
+	store.ServiceTemplateStore
+	templates map[string]*models.ServiceTemplate
+}
+
+func (m *mockServiceTemplateStore) Get(ctx context.Context, appID, name string) (*models.ServiceTemplate, error) {
+	return m.templates[name], nil
+}
+
+func (m *mockServiceTemplateStore) Update(ctx context.Context, template *models.ServiceTemplate) error {
+	m.templates[template.Name] = template
+	return nil
+}
+
+// emptySettingsStore has no settings, so handlers use their defaults.
+type emptySettingsStore struct {
+	store.SettingsStore
+}
+
+func (m *emptySettingsStore) Get(ctx context.Context, key string) (string, error) {
+	return "", nil
+}
+
+// templateMockStore adds service templates to the deployment mock store.
+type templateMockStore struct {
+	*deploymentMockStore
+	templates *mockServiceTemplateStore
+}
+
+func (m *templateMockStore) ServiceTemplates() store.ServiceTemplateStore {
+	return m.templates
+}
+
+func (m *templateMockStore) Settings() store.SettingsStore {
+	return &emptySettingsStore{}
+}
+
+func (m *templateMockStore) DeployFreezes() store.DeployFreezeStore {
+	return &mockDeployFreezeStore{}
+}
+
+func (m *templateMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func newTemplateMockStore() *templateMockStore {
+	st := &templateMockStore{
+		deploymentMockStore: newDeploymentMockStore(),
+		templates: &mockServiceTemplateStore{templates: map[string]*models.ServiceTemplate{
+			"worker": {
+				ID:        "tmpl-1",
+				AppID:     "app-1",
+				Name:      "worker",
+				Variables: []models.ServiceTemplateVariable{{Name: "tenant", Required: true}},
+				Service: models.ServiceConfig{
+					Name:          "worker",
+					SourceType:    models.SourceTypeGit,
+					GitRepo:       "github.com/acme/worker",
+					GitRef:        "main",
+					BuildStrategy: models.BuildStrategyFlake,
+					Replicas:      1,
+					EnvVars:       map[string]string{"TENANT": "${tenant}"},
+				},
+			},
+		}},
+	}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "app"}
+	return st
+}
+
+// templateRequest builds a request by user-1 with the given URL params.
+func templateRequest(method, target string, body any, params map[string]string) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("appID", "app-1")
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestServiceTemplateInstances(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := newTemplateMockStore()
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"templateName": "worker"}
+
+	for _, tenant := range []string{"acme", "globex"} {
+		rr := httptest.NewRecorder()
+		h.CreateInstance(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/service-templates/worker/instances",
+			TemplateInstanceRequest{Instance: tenant, Params: map[string]string{"tenant": tenant}}, params))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("create instance %s: status = %d: %s", tenant, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.CreateInstance(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/service-templates/worker/instances",
+		TemplateInstanceRequest{Instance: "acme", Params: map[string]string{"tenant": "acme"}}, params))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate instance status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	rr = httptest.NewRecorder()
+	h.CreateInstance(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/service-templates/worker/instances",
+		TemplateInstanceRequest{Instance: "initech"}, params))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("missing required variable status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	// Updating the template re-renders every instance with its own parameters
+	update := ServiceTemplateRequest{
+		Variables: []models.ServiceTemplateVariable{{Name: "tenant", Required: true}},
+		Service: models.ServiceConfig{
+			SourceType: models.SourceTypeGit,
+			GitRepo:    "github.com/acme/worker",
+			GitRef:     "v2",
+			EnvVars:    map[string]string{"TENANT": "${tenant}", "QUEUE": "jobs-${instance}"},
+		},
+	}
+	rr = httptest.NewRecorder()
+	h.UpdateTemplate(rr, templateRequest(http.MethodPut, "/v1/apps/app-1/service-templates/worker", update, params))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update template: status = %d: %s", rr.Code, rr.Body.String())
+	}
+
+	app := st.appStore.apps["app-1"]
+	if len(app.Services) != 2 {
+		t.Fatalf("services = %d, want 2", len(app.Services))
+	}
+	for _, svc := range app.Services {
+		tenant := svc.Template.Instance
+		if svc.Name != "worker-"+tenant || svc.GitRef != "v2" ||
+			svc.EnvVars["TENANT"] != tenant || svc.EnvVars["QUEUE"] != "jobs-"+tenant {
+			t.Errorf("instance %s not re-rendered: %+v", tenant, svc)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.DeleteTemplate(rr, templateRequest(http.MethodDelete, "/v1/apps/app-1/service-templates/worker", nil, params))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("delete template with instances status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	// Deploying the template deploys each instance
+	rr = httptest.NewRecorder()
+	NewDeploymentHandler(st, newMockQueue(), logger).DeployTemplate(rr,
+		templateRequest(http.MethodPost, "/v1/apps/app-1/service-templates/worker/deploy", ServiceDeployRequest{}, params))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("deploy template: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var deployments []models.Deployment
+	json.Unmarshal(rr.Body.Bytes(), &deployments)
+	if len(deployments) != 2 {
+		t.Errorf("deployments = %d, want 2", len(deployments))
+	}
+}
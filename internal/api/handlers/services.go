@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
 	"github.com/narvanalabs/control-plane/internal/builder/detector"
 	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
+	"github.com/narvanalabs/control-plane/internal/hooks"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/podman"
 	"github.com/narvanalabs/control-plane/internal/secrets"
@@ -28,9 +30,11 @@ import (
 type ServiceHandler struct {
 	store               store.Store
 	podman              *podman.Client
-	detector            detector.Detector
+	detect              *DetectHandler
 	sopsService         *secrets.SOPSService
 	dependencyValidator *validation.DependencyValidator
+	hooks               *hooks.Trigger
+	dial                func(ctx context.Context, network, address string) (net.Conn, error)
 	logger              *slog.Logger
 }
 
@@ -39,19 +43,50 @@ func NewServiceHandler(st store.Store, pd *podman.Client, sopsService *secrets.S
 	return &ServiceHandler{
 		store:               st,
 		podman:              pd,
-		detector:            detector.NewDetector(),
+		detect:              NewDetectHandler(logger),
 		sopsService:         sopsService,
 		dependencyValidator: validation.NewDependencyValidator(logger),
+		dial:                (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
 		logger:              logger,
 	}
 }
 
+// SetHooks sets the trigger told when a service's replica count changes.
+func (h *ServiceHandler) SetHooks(t *hooks.Trigger) {
+	h.hooks = t
+}
+
+// serviceScaled records a change of a service's replica count in its scale
+// events and fires the app's on_scale hooks, if hooks are set.
+func (h *ServiceHandler) serviceScaled(ctx context.Context, appID, serviceName string, previous, replicas int, source models.ScaleEventSource, reason string) {
+	if previous == replicas {
+		return
+	}
+	event := &models.ScaleEvent{
+		AppID:        appID,
+		ServiceName:  serviceName,
+		FromReplicas: previous,
+		ToReplicas:   replicas,
+		Source:       source,
+		Reason:       reason,
+	}
+	if source == models.ScaleEventManual {
+		event.UserID = middleware.GetUserID(ctx)
+	}
+	if err := h.store.ScaleEvents().Create(ctx, event); err != nil {
+		h.logger.Error("failed to record scale event", "error", err, "app_id", appID, "service_name", serviceName)
+	}
+	if h.hooks != nil {
+		h.hooks.Scaled(ctx, appID, serviceName, previous, replicas)
+	}
+}
+
 // NewServiceHandlerWithDetector creates a new service handler with a custom detector.
 func NewServiceHandlerWithDetector(st store.Store, pd *podman.Client, det detector.Detector, sopsService *secrets.SOPSService, logger *slog.Logger) *ServiceHandler {
 	return &ServiceHandler{
 		store:               st,
 		podman:              pd,
-		detector:            det,
+		detect:              NewDetectHandlerWithDetector(det, logger),
 		sopsService:         sopsService,
 		dependencyValidator: validation.NewDependencyValidator(logger),
 		logger:              logger,
@@ -65,6 +100,7 @@ type CreateServiceRequest struct {
 	GitRepo     string                 `json:"git_repo,omitempty"`
 	GitRef      string                 `json:"git_ref,omitempty"`      // Default: "main"
 	FlakeOutput string                 `json:"flake_output,omitempty"` // Default: "packages.x86_64-linux.default"
+	BuildPath   string                 `json:"build_path,omitempty"`   // Default: the repository root
 	FlakeURI    string                 `json:"flake_uri,omitempty"`
 	Image       string                 `json:"image,omitempty"`
 	Database    *models.DatabaseConfig `json:"database,omitempty"`
@@ -85,6 +121,16 @@ type CreateServiceRequest struct {
 	HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
 	DependsOn   []string                  `json:"depends_on,omitempty"`
 	EnvVars     map[string]string         `json:"env_vars,omitempty"`
+	Egress      *models.EgressPolicy      `json:"egress,omitempty"`
+	NodePool    string                    `json:"node_pool,omitempty"` // Default: the agent node pool
+	Runtime     models.ServiceRuntime     `json:"runtime,omitempty"`   // Pure-nix only; default: "container"
+	SmokeTests  []models.SmokeTest        `json:"smoke_tests,omitempty"`
+	Canary      *models.CanaryConfig      `json:"canary,omitempty"`      // Deploy new releases as canaries
+	OpenAPIURL  string                    `json:"openapi_url,omitempty"` // Spec listed in the API catalog
+
+	// Cron services run Cron.Command on Cron.Schedule instead of continuously
+	Type models.ServiceType `json:"type,omitempty"` // Default: "service"
+	Cron *models.CronConfig `json:"cron,omitempty"`
 }
 
 // UpdateServiceRequest represents the request body for updating a service.
@@ -93,6 +139,7 @@ type UpdateServiceRequest struct {
 	GitRepo     *string                `json:"git_repo,omitempty"`
 	GitRef      *string                `json:"git_ref,omitempty"`
 	FlakeOutput *string                `json:"flake_output,omitempty"`
+	BuildPath   *string                `json:"build_path,omitempty"`
 	FlakeURI    *string                `json:"flake_uri,omitempty"`
 	Image       *string                `json:"image,omitempty"`
 	Database    *models.DatabaseConfig `json:"database,omitempty"`
@@ -108,6 +155,13 @@ type UpdateServiceRequest struct {
 	HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
 	DependsOn   []string                  `json:"depends_on,omitempty"`
 	EnvVars     map[string]string         `json:"env_vars,omitempty"`
+	Egress      *models.EgressPolicy      `json:"egress,omitempty"`
+	NodePool    *string                   `json:"node_pool,omitempty"`   // Empty moves the service to the agent node pool
+	Runtime     *models.ServiceRuntime    `json:"runtime,omitempty"`     // Takes effect on the next deployment
+	SmokeTests  []models.SmokeTest        `json:"smoke_tests,omitempty"` // An empty list removes the service's smoke tests
+	Canary      *models.CanaryConfig      `json:"canary,omitempty"`      // An empty object turns canary deployments off
+	OpenAPIURL  *string                   `json:"openapi_url,omitempty"` // Empty removes the service from the API catalog
+	Cron        *models.CronConfig        `json:"cron,omitempty"`        // Cron services only
 }
 
 // ServiceResponse represents a service in API responses with inherited env vars.
@@ -207,6 +261,22 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Validate build timeout and build container limits if provided
+	if err := validation.ValidateBuildLimits(req.BuildConfig); err != nil {
+		WriteError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate egress policy if provided
+	if err := validation.ValidateEgressPolicy(req.Egress); err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			WriteError(w, http.StatusBadRequest, ErrCodeInvalidRequest, validationErr.Error())
+			return
+		}
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
 	// Validate database configuration (Requirements: 29.1, 29.2)
 	if sourceType == models.SourceTypeDatabase && req.Database != nil {
 		if err := validation.ValidateDatabaseConfig(req.Database); err != nil {
@@ -226,6 +296,7 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		GitRepo:       req.GitRepo,
 		GitRef:        req.GitRef,
 		FlakeOutput:   req.FlakeOutput,
+		BuildPath:     req.BuildPath,
 		FlakeURI:      req.FlakeURI,
 		Database:      req.Database,
 		BuildStrategy: req.BuildStrategy,
@@ -235,6 +306,14 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		HealthCheck:   req.HealthCheck,
 		DependsOn:     req.DependsOn,
 		EnvVars:       req.EnvVars,
+		Egress:        req.Egress,
+		NodePool:      req.NodePool,
+		Runtime:       req.Runtime,
+		SmokeTests:    req.SmokeTests,
+		Canary:        req.Canary,
+		OpenAPIURL:    req.OpenAPIURL,
+		Type:          req.Type,
+		Cron:          req.Cron,
 	}
 
 	// Apply default resources if not specified (Requirements: 12.3, 30.2, 30.3)
@@ -248,8 +327,22 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		service.Replicas = 1
 	}
 
+	// Database services default to their engine's version, port and health check
+	if sourceType == models.SourceTypeDatabase && service.Database != nil {
+		dbType := databases.DatabaseType(service.Database.Type)
+		if service.Database.Version == "" {
+			service.Database.Version = validation.GetDefaultVersion(service.Database.Type)
+		}
+		if port := databases.GetDefaultPort(dbType); port > 0 && len(service.Ports) == 0 {
+			service.Ports = []models.PortMapping{{ContainerPort: port, Protocol: "tcp"}}
+		}
+		if service.HealthCheck == nil {
+			service.HealthCheck = databases.HealthCheck(dbType)
+		}
+	}
+
 	// Default to port 8080 if no ports specified (common for web services)
-	if len(service.Ports) == 0 {
+	if len(service.Ports) == 0 && !service.IsCron() {
 		service.Ports = []models.PortMapping{{ContainerPort: 8080, Protocol: "tcp"}}
 	}
 
@@ -326,12 +419,9 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add service to app
-	app.Services = append(app.Services, service)
-	app.UpdatedAt = time.Now()
-
-	if err := h.store.Apps().Update(r.Context(), app); err != nil {
-		h.logger.Error("failed to update app with new service", "error", err)
-		WriteInternalError(w, "Failed to create service")
+	if err := h.store.Services().Create(r.Context(), appID, &service); err != nil {
+		h.logger.Error("failed to create service", "error", err)
+		writeServiceStoreError(w, err, "Failed to create service")
 		return
 	}
 
@@ -419,6 +509,7 @@ func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
 	// Find the service
 	for _, svc := range app.Services {
 		if svc.Name == serviceName {
+			w.Header().Set("ETag", resourceETag(svc))
 			WriteJSON(w, http.StatusOK, svc)
 			return
 		}
@@ -479,6 +570,22 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Validate build timeout and build container limits if provided
+	if err := validation.ValidateBuildLimits(req.BuildConfig); err != nil {
+		WriteError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Validate egress policy if provided
+	if err := validation.ValidateEgressPolicy(req.Egress); err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			WriteError(w, http.StatusBadRequest, ErrCodeInvalidRequest, validationErr.Error())
+			return
+		}
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
 	// Get the app
 	app, err := h.store.Apps().Get(r.Context(), appID)
 	if err != nil {
@@ -507,6 +614,7 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	service := &app.Services[serviceIndex]
+	previousReplicas := service.Replicas
 
 	// Apply updates (preserve unspecified fields)
 	if req.SourceType != nil {
@@ -524,9 +632,13 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.FlakeOutput != nil {
 		service.FlakeOutput = *req.FlakeOutput
 	}
+	if req.BuildPath != nil {
+		service.BuildPath = *req.BuildPath
+	}
 	if req.FlakeURI != nil {
 		service.FlakeURI = *req.FlakeURI
 		service.GitRepo = ""
+		service.BuildPath = ""
 		service.Image = ""
 		service.Database = nil
 	}
@@ -562,6 +674,27 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	if req.Replicas != nil {
 		service.Replicas = *req.Replicas
+		// Manually scaling a scheduled service overrides the schedule until
+		// its next change of replica count, and an autoscaled service holds
+		// the autoscaler off for a while
+		now := time.Now()
+		switch {
+		case service.ScalingSchedule != nil:
+			service.ReplicaOverride = &models.ReplicaOverride{
+				Replicas: *req.Replicas,
+				Until:    service.ScalingSchedule.NextChange(now),
+				SetBy:    userID,
+				SetAt:    now,
+			}
+		case service.Autoscaling != nil:
+			until := now.Add(models.AutoscalingOverrideDuration)
+			service.ReplicaOverride = &models.ReplicaOverride{
+				Replicas: *req.Replicas,
+				Until:    &until,
+				SetBy:    userID,
+				SetAt:    now,
+			}
+		}
 	}
 	if req.Ports != nil {
 		service.Ports = req.Ports
@@ -575,6 +708,30 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.EnvVars != nil {
 		service.EnvVars = req.EnvVars
 	}
+	if req.Egress != nil {
+		service.Egress = req.Egress
+	}
+	if req.NodePool != nil {
+		service.NodePool = *req.NodePool
+	}
+	if req.Runtime != nil {
+		service.Runtime = *req.Runtime
+	}
+	if req.SmokeTests != nil {
+		service.SmokeTests = req.SmokeTests
+	}
+	if req.Canary != nil {
+		service.Canary = req.Canary
+		if *req.Canary == (models.CanaryConfig{}) {
+			service.Canary = nil
+		}
+	}
+	if req.OpenAPIURL != nil {
+		service.OpenAPIURL = *req.OpenAPIURL
+	}
+	if req.Cron != nil {
+		service.Cron = req.Cron
+	}
 
 	// Re-validate after updates
 	if err := service.Validate(); err != nil {
@@ -611,16 +768,14 @@ func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Update app's updated_at timestamp
-	app.UpdatedAt = time.Now()
-
-	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+	if err := h.store.Services().Update(r.Context(), appID, service); err != nil {
 		h.logger.Error("failed to update service", "error", err)
-		WriteInternalError(w, "Failed to update service")
+		writeServiceStoreError(w, err, "Failed to update service")
 		return
 	}
 
 	h.logger.Info("service updated", "app_id", appID, "service_name", serviceName)
+	h.serviceScaled(r.Context(), app.ID, serviceName, previousReplicas, service.Replicas, models.ScaleEventManual, "replicas updated")
 	WriteJSON(w, http.StatusOK, service)
 }
 
@@ -699,77 +854,96 @@ func (h *ServiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	// Use transaction for atomicity (Requirements: 21.1, 21.2, 21.3, 21.4)
 	err = h.store.WithTx(r.Context(), func(txStore store.Store) error {
-		// Delete associated secrets (database credentials) (Requirements: 21.1)
-		if serviceToDelete.SourceType == models.SourceTypeDatabase {
-			secretKeys, err := txStore.Secrets().List(r.Context(), appID)
-			if err == nil {
-				// Delete secrets that match this service's naming pattern
-				servicePrefix := strings.ToUpper(serviceName)
-				for _, key := range secretKeys {
-					if strings.HasPrefix(key, servicePrefix+"_") {
-						if err := txStore.Secrets().Delete(r.Context(), appID, key); err != nil {
-							h.logger.Error("failed to delete secret", "error", err, "key", key)
-						}
+		h.removeServiceResources(r.Context(), txStore, appID, serviceToDelete)
+
+		// Remove the service from the app
+		return txStore.Services().Delete(r.Context(), appID, serviceName)
+	})
+
+	if err != nil {
+		h.logger.Error("failed to delete service", "error", err)
+		WriteInternalError(w, "Failed to delete service")
+		return
+	}
+
+	h.logger.Info("service deleted", "app_id", appID, "service_name", serviceName)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeServiceResources deletes the database credentials and domain mappings
+// of a service being removed from an app, and fails its pending builds and
+// active deployments. Failures are logged rather than returned so that the
+// service is removed regardless.
+func (h *ServiceHandler) removeServiceResources(ctx context.Context, txStore store.Store, appID string, svc *models.ServiceConfig) {
+	// Delete associated secrets (database credentials) (Requirements: 21.1)
+	if svc.SourceType == models.SourceTypeDatabase {
+		secretKeys, err := txStore.Secrets().List(ctx, appID)
+		if err == nil {
+			// Delete secrets that match this service's naming pattern
+			servicePrefix := strings.ToUpper(svc.Name)
+			for _, key := range secretKeys {
+				if strings.HasPrefix(key, servicePrefix+"_") {
+					if err := txStore.Secrets().Delete(ctx, appID, key); err != nil {
+						h.logger.Error("failed to delete secret", "error", err, "key", key)
 					}
 				}
 			}
 		}
+	}
 
-		// Remove domain mappings for this service (Requirements: 21.2)
-		domains, err := txStore.Domains().List(r.Context(), appID)
-		if err == nil {
-			for _, domain := range domains {
-				if domain.Service == serviceName {
-					if err := txStore.Domains().Delete(r.Context(), domain.ID); err != nil {
-						h.logger.Error("failed to delete domain mapping", "error", err, "domain_id", domain.ID)
-					}
+	// Remove domain mappings for this service (Requirements: 21.2)
+	domains, err := txStore.Domains().List(ctx, appID)
+	if err == nil {
+		for _, domain := range domains {
+			if domain.Service == svc.Name {
+				if err := txStore.Domains().Delete(ctx, domain.ID); err != nil {
+					h.logger.Error("failed to delete domain mapping", "error", err, "domain_id", domain.ID)
 				}
 			}
 		}
+	}
 
-		// Cancel pending builds for this service (Requirements: 21.3)
-		builds, err := txStore.Builds().List(r.Context(), appID)
-		if err == nil {
-			for _, build := range builds {
-				if build.ServiceName == serviceName && (build.Status == models.BuildStatusQueued || build.Status == models.BuildStatusRunning) {
-					build.Status = models.BuildStatusFailed
-					build.FinishedAt = timePtr(time.Now())
-					if err := txStore.Builds().Update(r.Context(), build); err != nil {
-						h.logger.Error("failed to cancel build", "error", err, "build_id", build.ID)
-					}
+	// Cancel pending builds for this service (Requirements: 21.3)
+	builds, err := txStore.Builds().List(ctx, appID)
+	if err == nil {
+		for _, build := range builds {
+			if build.ServiceName == svc.Name && (build.Status == models.BuildStatusQueued || build.Status == models.BuildStatusRunning) {
+				build.Status = models.BuildStatusFailed
+				build.FinishedAt = timePtr(time.Now())
+				if err := txStore.Builds().Update(ctx, build); err != nil {
+					h.logger.Error("failed to cancel build", "error", err, "build_id", build.ID)
 				}
 			}
 		}
+	}
 
-		// Stop running deployments and schedule container cleanup (Requirements: 21.4)
-		deployments, err := txStore.Deployments().List(r.Context(), appID)
-		if err == nil {
-			for _, d := range deployments {
-				if d.ServiceName == serviceName && isActiveDeployment(d.Status) {
-					d.Status = models.DeploymentStatusFailed
-					d.UpdatedAt = time.Now()
-					if err := txStore.Deployments().Update(r.Context(), d); err != nil {
-						h.logger.Error("failed to update deployment status", "error", err, "deployment_id", d.ID)
-					}
+	// Stop running deployments and schedule container cleanup (Requirements: 21.4)
+	deployments, err := txStore.Deployments().List(ctx, appID)
+	if err == nil {
+		for _, d := range deployments {
+			if d.ServiceName == svc.Name && isActiveDeployment(d.Status) {
+				d.Status = models.DeploymentStatusFailed
+				d.UpdatedAt = time.Now()
+				if err := txStore.Deployments().Update(ctx, d); err != nil {
+					h.logger.Error("failed to update deployment status", "error", err, "deployment_id", d.ID)
 				}
 			}
 		}
-
-		// Remove the service from the app
-		app.Services = append(app.Services[:serviceIndex], app.Services[serviceIndex+1:]...)
-		app.UpdatedAt = time.Now()
-
-		return txStore.Apps().Update(r.Context(), app)
-	})
-
-	if err != nil {
-		h.logger.Error("failed to delete service", "error", err)
-		WriteInternalError(w, "Failed to delete service")
-		return
 	}
+}
 
-	h.logger.Info("service deleted", "app_id", appID, "service_name", serviceName)
-	w.WriteHeader(http.StatusNoContent)
+// writeServiceStoreError writes the response for a failed service write: a
+// conflict when the service was changed by another request since it was read
+// or its name is taken, otherwise an internal error with message.
+func writeServiceStoreError(w http.ResponseWriter, err error, message string) {
+	switch err.Error() {
+	case "resource was modified by another request":
+		WriteConflict(w, "Service was modified by another request. Please refresh and try again.")
+	case "duplicate name":
+		WriteConflict(w, "A service with this name already exists")
+	default:
+		WriteInternalError(w, message)
+	}
 }
 
 // formatDependents formats a list of dependent service names for error messages.
@@ -928,8 +1102,9 @@ func (h *ServiceHandler) TerminalWS(w http.ResponseWriter, r *http.Request) {
 
 // ServiceDetectRequest represents the request body for detecting service configuration.
 type ServiceDetectRequest struct {
-	GitURL string `json:"git_url"`
-	GitRef string `json:"git_ref,omitempty"`
+	GitURL    string `json:"git_url"`
+	GitRef    string `json:"git_ref,omitempty"` // Default: the repository's default branch
+	BuildPath string `json:"build_path,omitempty"`
 }
 
 // ServiceDetectResponse represents the response for service detection.
@@ -946,10 +1121,14 @@ type ServiceDetectResponse struct {
 	SuggestedConfig map[string]interface{} `json:"suggested_config,omitempty"`
 	Confidence      float64                `json:"confidence"`
 	Warnings        []string               `json:"warnings,omitempty"`
+	DefaultBranch   string                 `json:"default_branch,omitempty"`
+	Projects        []detector.Project     `json:"projects,omitempty"`
 }
 
-// DetectForService handles POST /v1/apps/{appID}/services/detect - detects service configuration from git URL.
+// DetectForService handles POST /v1/apps/{appID}/detect - detects service configuration from git URL.
 // This endpoint is called when a user enters a git URL during service creation to auto-populate fields.
+// It shallow-clones the repository and returns the recommended strategy and build type with the
+// detected framework and the detection's confidence, so the form can preselect them.
 // **Validates: Requirements 4.3, 4.4, 4.5**
 func (h *ServiceHandler) DetectForService(w http.ResponseWriter, r *http.Request) {
 	var req ServiceDetectRequest
@@ -958,79 +1137,51 @@ func (h *ServiceHandler) DetectForService(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Validate git URL
-	if req.GitURL == "" {
-		WriteBadRequest(w, "git_url is required")
-		return
-	}
-
-	// Default git ref to main
-	gitRef := req.GitRef
-	if gitRef == "" {
-		gitRef = "main"
-	}
-
-	// Create a detect handler to leverage existing detection logic
-	detectHandler := NewDetectHandler(h.logger)
-
-	// Use a timeout context for detection
-	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
-	defer cancel()
-
-	// Clone and detect
-	result, err := detectHandler.CloneAndDetect(ctx, req.GitURL, gitRef)
-	if err != nil {
-		h.logger.Error("detection failed", "error", err, "url", req.GitURL)
-		WriteError(w, http.StatusUnprocessableEntity, "detection_failed", "Failed to detect repository: "+err.Error())
+	detected, ok := h.detect.detectRepository(r.Context(), w, DetectRequest{
+		GitURL:    req.GitURL,
+		GitRef:    req.GitRef,
+		BuildPath: req.BuildPath,
+	})
+	if !ok {
 		return
 	}
 
-	// Determine build type based on strategy
-	// **Validates: Requirements 4.7, 4.8**
-	buildType := detector.DetermineBuildType(result.Strategy)
-
 	// Extract entry point and build command from suggested config
 	var entryPoint, buildCommand, startCommand string
-	if result.SuggestedConfig != nil {
-		if ep, ok := result.SuggestedConfig["entry_point"].(string); ok {
+	if detected.SuggestedConfig != nil {
+		if ep, ok := detected.SuggestedConfig["entry_point"].(string); ok {
 			entryPoint = ep
 		}
-		if bc, ok := result.SuggestedConfig["build_command"].(string); ok {
+		if bc, ok := detected.SuggestedConfig["build_command"].(string); ok {
 			buildCommand = bc
 		}
-		if sc, ok := result.SuggestedConfig["start_command"].(string); ok {
+		if sc, ok := detected.SuggestedConfig["start_command"].(string); ok {
 			startCommand = sc
 		}
 	}
 
 	// If entry points are detected, use the first one as the default entry point
-	if entryPoint == "" && len(result.EntryPoints) > 0 {
-		entryPoint = result.EntryPoints[0]
+	if entryPoint == "" && len(detected.EntryPoints) > 0 {
+		entryPoint = detected.EntryPoints[0]
 	}
 
-	// Build response
-	response := ServiceDetectResponse{
-		Strategy:        result.Strategy,
-		BuildType:       buildType,
-		Framework:       result.Framework,
-		Version:         result.Version,
+	// Determine build type based on strategy
+	// **Validates: Requirements 4.7, 4.8**
+	WriteJSON(w, http.StatusOK, ServiceDetectResponse{
+		Strategy:        detected.Strategy,
+		BuildType:       detector.DetermineBuildType(detected.Strategy),
+		Framework:       detected.Framework,
+		Version:         detected.Version,
 		EntryPoint:      entryPoint,
-		EntryPoints:     result.EntryPoints,
+		EntryPoints:     detected.EntryPoints,
 		BuildCommand:    buildCommand,
 		StartCommand:    startCommand,
-		SuggestedConfig: result.SuggestedConfig,
-		Confidence:      result.Confidence,
-		Warnings:        result.Warnings,
-	}
-
-	h.logger.Info("service detection completed",
-		"url", req.GitURL,
-		"strategy", result.Strategy,
-		"build_type", buildType,
-		"framework", result.Framework,
-	)
-
-	WriteJSON(w, http.StatusOK, response)
+		SuggestedConfig: detected.SuggestedConfig,
+		Confidence:      detected.Confidence,
+		Warnings:        detected.Warnings,
+		DefaultBranch:   detected.DefaultBranch,
+		Projects:        detected.Projects,
+	})
 }
 
 // StopService handles POST /v1/apps/{appID}/services/{serviceName}/stop - stops a running service.
@@ -1489,6 +1640,11 @@ func (h *ServiceHandler) Rename(w http.ResponseWriter, r *http.Request) {
 			return &APIError{Code: ErrCodeNotFound, Message: "Service not found"}
 		}
 
+		// Template instances are named <template>-<instance> by their template
+		if app.Services[serviceIndex].Template != nil {
+			return &APIError{Code: ErrCodeConflict, Message: "Template instances cannot be renamed"}
+		}
+
 		// Check new name doesn't exist (Requirements: 23.1)
 		for _, svc := range app.Services {
 			if svc.Name == req.NewName {
@@ -1641,6 +1797,12 @@ type EnvVarResponse struct {
 	Key       string    `json:"key"`
 	Value     string    `json:"value,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Source is where an inherited variable comes from: EnvSourceApp,
+	// EnvSourceSharedSecret or EnvSourceSecret. Values of inherited secrets
+	// are not returned.
+	Source string `json:"source,omitempty"`
+	// Overrides is the source of the app-level value a service variable replaces.
+	Overrides string `json:"overrides,omitempty"`
 }
 
 // AddEnvVar handles POST /v1/apps/{appID}/services/{serviceName}/env - adds an environment variable.
@@ -1743,9 +1905,9 @@ func (h *ServiceHandler) AddEnvVar(w http.ResponseWriter, r *http.Request) {
 	app.Services[serviceIndex].EnvVars[req.Key] = req.Value
 	app.UpdatedAt = time.Now()
 
-	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+	if err := h.store.Services().Update(r.Context(), appID, &app.Services[serviceIndex]); err != nil {
 		h.logger.Error("failed to add env var", "error", err)
-		WriteInternalError(w, "Failed to add environment variable")
+		writeServiceStoreError(w, err, "Failed to add environment variable")
 		return
 	}
 
@@ -1826,11 +1988,10 @@ func (h *ServiceHandler) DeleteEnvVar(w http.ResponseWriter, r *http.Request) {
 
 	// Delete the env var
 	delete(app.Services[serviceIndex].EnvVars, key)
-	app.UpdatedAt = time.Now()
 
-	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+	if err := h.store.Services().Update(r.Context(), appID, &app.Services[serviceIndex]); err != nil {
 		h.logger.Error("failed to delete env var", "error", err)
-		WriteInternalError(w, "Failed to delete environment variable")
+		writeServiceStoreError(w, err, "Failed to delete environment variable")
 		return
 	}
 
@@ -1934,9 +2095,9 @@ func (h *ServiceHandler) UpdateEnvVar(w http.ResponseWriter, r *http.Request) {
 	app.Services[serviceIndex].EnvVars[key] = req.Value
 	app.UpdatedAt = time.Now()
 
-	if err := h.store.Apps().Update(r.Context(), app); err != nil {
+	if err := h.store.Services().Update(r.Context(), appID, &app.Services[serviceIndex]); err != nil {
 		h.logger.Error("failed to update env var", "error", err)
-		WriteInternalError(w, "Failed to update environment variable")
+		writeServiceStoreError(w, err, "Failed to update environment variable")
 		return
 	}
 
@@ -1948,7 +2109,8 @@ func (h *ServiceHandler) UpdateEnvVar(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// ListEnvVars handles GET /v1/apps/{appID}/services/{serviceName}/env - lists all environment variables.
+// ListEnvVars handles GET /v1/apps/{appID}/services/{serviceName}/env - lists the service's
+// environment variables and those it inherits from the app.
 // **Validates: Requirements 5.4**
 func (h *ServiceHandler) ListEnvVars(w http.ResponseWriter, r *http.Request) {
 	appID := middleware.GetResolvedAppID(r.Context())
@@ -1999,18 +2161,60 @@ func (h *ServiceHandler) ListEnvVars(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Org shared secrets override app env vars, app secrets override both and
+	// service env vars override all three
+	secretKeys, err := h.store.Secrets().List(r.Context(), appID)
+	if err != nil {
+		h.logger.Error("failed to list secrets", "error", err, "app_id", appID)
+		WriteInternalError(w, "Failed to list environment variables")
+		return
+	}
+	var sharedSecrets []*models.OrgSecret
+	if app.OrgID != "" {
+		sharedSecrets, err = h.store.OrgSecrets().List(r.Context(), app.OrgID)
+		if err != nil {
+			h.logger.Error("failed to list shared secrets", "error", err, "org_id", app.OrgID)
+			WriteInternalError(w, "Failed to list environment variables")
+			return
+		}
+	}
+	appSources := make(map[string]string, len(app.EnvVars)+len(secretKeys))
+	for k := range app.EnvVars {
+		appSources[k] = EnvSourceApp
+	}
+	for _, secret := range sharedSecrets {
+		if secret.SharedWith(app.ID) {
+			appSources[secret.Key] = EnvSourceSharedSecret
+		}
+	}
+	for _, k := range secretKeys {
+		appSources[k] = EnvSourceSecret
+	}
+
 	// Build response
-	variables := make([]EnvVarResponse, 0)
-	if service.EnvVars != nil {
-		for k, v := range service.EnvVars {
-			variables = append(variables, EnvVarResponse{
-				Key:   k,
-				Value: v,
-			})
+	variables := make([]EnvVarResponse, 0, len(service.EnvVars))
+	for _, k := range sortedKeys(service.EnvVars) {
+		variables = append(variables, EnvVarResponse{
+			Key:       k,
+			Value:     service.EnvVars[k],
+			Overrides: appSources[k],
+		})
+	}
+
+	inherited := make([]EnvVarResponse, 0)
+	for _, k := range sortedKeys(appSources) {
+		if _, overridden := service.EnvVars[k]; overridden {
+			continue
+		}
+		v := EnvVarResponse{Key: k, Source: appSources[k]}
+		if v.Source == EnvSourceApp {
+			v.Value = app.EnvVars[k]
 		}
+		inherited = append(inherited, v)
 	}
 
 	WriteJSON(w, http.StatusOK, map[string]interface{}{
 		"variables": variables,
+		"inherited": inherited,
 	})
 }
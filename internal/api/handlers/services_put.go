@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Put handles PUT /v1/apps/{appID}/services/{serviceName} - creates the
+// service or replaces its configuration with the body, a service as declared
+// in an app spec. Omitted settings take the defaults of new services; the
+// service keeps its domains, which are put separately. Putting the same
+// configuration again changes nothing, so the request is safe to repeat. The
+// response carries the service's ETag, which If-Match compares;
+// If-None-Match: * only creates.
+func (h *ServiceHandler) Put(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	app, ok := h.ownedApp(w, r)
+	if !ok {
+		return
+	}
+	serviceName := chi.URLParam(r, "serviceName")
+
+	var svc appspec.Service
+	if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if svc.Name != "" && svc.Name != serviceName {
+		WriteBadRequest(w, "name must match the service name in the path")
+		return
+	}
+	if len(svc.Domains) > 0 {
+		WriteBadRequest(w, "domains are put with PUT /v1/apps/{appID}/domains/{domain}")
+		return
+	}
+	svc.Name = serviceName
+
+	current, exists := findService(app, serviceName)
+	etag := ""
+	if exists {
+		etag = resourceETag(current)
+	}
+	if !checkPreconditions(w, r, etag) {
+		return
+	}
+
+	domains, err := h.store.Domains().List(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to list domains", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to put service")
+		return
+	}
+	for _, d := range domains {
+		if d.Service == serviceName {
+			svc.Domains = append(svc.Domains, d.Domain)
+		}
+	}
+
+	// A spec of the one service, keeping the app's env vars and other services
+	spec := &appspec.Spec{
+		Version:  appspec.Version,
+		Env:      maps.Clone(appspec.Env(app.EnvVars)),
+		Services: []appspec.Service{svc},
+	}
+	if err := spec.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	opts := appspec.Options{Resources: h.getDefaultResources(ctx)}
+	plan, ok := h.convergeApp(w, r, app, spec, opts, false)
+	if !ok {
+		return
+	}
+
+	// Read the service back as stored, so its ETag matches the one GET returns
+	updated, err := h.store.Apps().Get(ctx, app.ID)
+	if err != nil {
+		h.logger.Error("failed to get app", "error", err, "app_id", app.ID)
+		WriteInternalError(w, "Failed to get service")
+		return
+	}
+	stored, _ := findService(updated, serviceName)
+
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+		w.Header().Set("Location", "/v1/apps/"+app.ID+"/services/"+serviceName)
+	}
+	if len(plan.Changes) > 0 {
+		h.logger.Info("service put", "app_id", app.ID, "service", serviceName, "changes", len(plan.Changes))
+	}
+	w.Header().Set("ETag", resourceETag(stored))
+	WriteJSON(w, status, stored)
+}
+
+// findService returns an app's service by name.
+func findService(app *models.App, name string) (models.ServiceConfig, bool) {
+	for _, svc := range app.Services {
+		if svc.Name == name {
+			return svc, true
+		}
+	}
+	return models.ServiceConfig{}, false
+}
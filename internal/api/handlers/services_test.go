@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// staleServiceStore rejects every service update, as if the service changed
+// since it was read.
+type staleServiceStore struct {
+	store.ServiceStore
+}
+
+func (staleServiceStore) Update(ctx context.Context, appID string, service *models.ServiceConfig) error {
+	return errors.New("resource was modified by another request")
+}
+
+// staleServiceMockStore has the deployment mock store's apps, whose services
+// can't be updated.
+type staleServiceMockStore struct {
+	*deploymentMockStore
+}
+
+func (m *staleServiceMockStore) Services() store.ServiceStore { return staleServiceStore{} }
+
+func TestServiceUpdateConflict(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &staleServiceMockStore{deploymentMockStore: newDeploymentMockStore()}
+	st.appStore.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 1, Version: 1}},
+	}
+	h := NewServiceHandler(st, nil, nil, logger)
+	params := map[string]string{"serviceName": "web"}
+
+	replicas := 2
+	rr := httptest.NewRecorder()
+	h.Update(rr, templateRequest(http.MethodPatch, "/v1/apps/app-1/services/web", UpdateServiceRequest{Replicas: &replicas}, params))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("update: status = %d, want 409: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.AddEnvVar(rr, templateRequest(http.MethodPost, "/v1/apps/app-1/services/web/env", AddEnvVarRequest{Key: "PORT", Value: "8080"}, params))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("add env var: status = %d, want 409: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// SessionResponse is a sign-in session of the user.
+type SessionResponse struct {
+	*models.AuthSession
+	// Current is true for the session of the request's access token
+	Current bool `json:"current"`
+}
+
+// startSession signs a user in on the device making the request and adds
+// the session's tokens to resp.
+func startSession(r *http.Request, authService *auth.Service, userID, email string, resp map[string]interface{}) error {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	pair, err := authService.StartSession(r.Context(), userID, email, r.UserAgent(), ip)
+	if err != nil {
+		return err
+	}
+	resp["token"] = pair.AccessToken
+	resp["expires_at"] = pair.AccessExpiresAt
+	if pair.RefreshToken != "" {
+		resp["session_id"] = pair.SessionID
+		resp["refresh_token"] = pair.RefreshToken
+		resp["refresh_expires_at"] = pair.RefreshExpiresAt
+	}
+	return nil
+}
+
+// Refresh handles POST /auth/refresh - exchanges a session's refresh token
+// for a new access token and a new refresh token. The refresh token sent
+// stops working.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		WriteBadRequest(w, "refresh_token is required")
+		return
+	}
+
+	pair, err := h.authService.RefreshSession(r.Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		WriteUnauthorized(w, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to refresh session", "error", err)
+		WriteInternalError(w, "Failed to refresh session")
+		return
+	}
+	WriteJSON(w, http.StatusOK, pair)
+}
+
+// Logout handles POST /v1/auth/logout - revokes the session of the
+// request's access token.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sessionID := middleware.GetSessionID(ctx); sessionID != "" {
+		if err := h.store.AuthSessions().Revoke(ctx, sessionID); err != nil {
+			h.logger.Error("failed to revoke session", "error", err, "session_id", sessionID)
+			WriteInternalError(w, "Failed to log out")
+			return
+		}
+		h.logger.Info("session logged out", "session_id", sessionID, "user_id", middleware.GetUserID(ctx))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions handles GET /v1/auth/sessions - lists the user's active
+// sign-in sessions, most recently used first.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sessions, err := h.store.AuthSessions().ListActiveByUser(ctx, middleware.GetUserID(ctx))
+	if err != nil {
+		h.logger.Error("failed to list sessions", "error", err)
+		WriteInternalError(w, "Failed to list sessions")
+		return
+	}
+
+	current := middleware.GetSessionID(ctx)
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, SessionResponse{AuthSession: s, Current: s.ID == current})
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// RevokeSession handles DELETE /v1/auth/sessions/{sessionID} - signs one of
+// the user's devices out. Its access token stops working immediately.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	sessionID := chi.URLParam(r, "sessionID")
+
+	session, err := h.store.AuthSessions().Get(ctx, sessionID)
+	if err != nil {
+		h.logger.Error("failed to get session", "error", err, "session_id", sessionID)
+		WriteInternalError(w, "Failed to revoke session")
+		return
+	}
+	if session == nil || session.UserID != userID {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	if err := h.store.AuthSessions().Revoke(ctx, session.ID); err != nil {
+		h.logger.Error("failed to revoke session", "error", err, "session_id", session.ID)
+		WriteInternalError(w, "Failed to revoke session")
+		return
+	}
+	h.logger.Info("session revoked", "session_id", session.ID, "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions handles DELETE /v1/auth/sessions - signs every device
+// of the user out but the one making the request.
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	n, err := h.store.AuthSessions().RevokeAllByUser(ctx, userID, middleware.GetSessionID(ctx))
+	if err != nil {
+		h.logger.Error("failed to revoke sessions", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to revoke sessions")
+		return
+	}
+	h.logger.Info("other sessions revoked", "user_id", userID, "count", n)
+	WriteJSON(w, http.StatusOK, map[string]int64{"revoked": n})
+}
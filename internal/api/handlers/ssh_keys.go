@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxSSHSessionsListed caps the SSH sessions returned by ListSessions.
+const maxSSHSessionsListed = 500
+
+// SSHKeyHandler handles the current user's SSH keys and the audit trail of
+// SSH sessions brokered to nodes.
+type SSHKeyHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewSSHKeyHandler creates a new SSH key handler.
+func NewSSHKeyHandler(st store.Store, logger *slog.Logger) *SSHKeyHandler {
+	return &SSHKeyHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// CreateSSHKeyRequest represents the request body for registering an SSH key.
+// The key's comment is used as its name when name is empty.
+type CreateSSHKeyRequest struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+// List handles GET /v1/user/ssh-keys - lists the current user's SSH keys.
+func (h *SSHKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	keys, err := h.store.SSH().ListKeys(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list ssh keys", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to list SSH keys")
+		return
+	}
+	if keys == nil {
+		keys = []*models.SSHKey{}
+	}
+	WriteJSON(w, http.StatusOK, keys)
+}
+
+// Create handles POST /v1/user/ssh-keys - registers a public key for the current user.
+func (h *SSHKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+
+	var req CreateSSHKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	key, err := models.ParseSSHKey(req.Name, req.PublicKey)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	if len(key.Name) > 100 {
+		WriteBadRequest(w, "SSH key name must be 100 characters or fewer")
+		return
+	}
+	key.UserID = userID
+
+	// Keys identify their user to the SSH broker, so each may only be registered once
+	existing, err := h.store.SSH().GetKeyByFingerprint(ctx, key.Fingerprint)
+	if err != nil {
+		h.logger.Error("failed to check existing ssh key", "error", err)
+		WriteInternalError(w, "Failed to add SSH key")
+		return
+	}
+	if existing != nil {
+		WriteError(w, http.StatusConflict, ErrCodeConflict, "This SSH key is already registered")
+		return
+	}
+
+	if err := h.store.SSH().CreateKey(ctx, key); err != nil {
+		h.logger.Error("failed to create ssh key", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to add SSH key")
+		return
+	}
+
+	h.logger.Info("ssh key added", "key_id", key.ID, "user_id", userID, "fingerprint", key.Fingerprint)
+	WriteJSON(w, http.StatusCreated, key)
+}
+
+// Delete handles DELETE /v1/user/ssh-keys/{keyID} - removes one of the current user's SSH keys.
+func (h *SSHKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetUserID(ctx)
+	keyID := chi.URLParam(r, "keyID")
+
+	keys, err := h.store.SSH().ListKeys(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to list ssh keys", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to remove SSH key")
+		return
+	}
+	found := false
+	for _, k := range keys {
+		if k.ID == keyID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		WriteNotFound(w, "SSH key not found")
+		return
+	}
+
+	if err := h.store.SSH().DeleteKey(ctx, userID, keyID); err != nil {
+		h.logger.Error("failed to delete ssh key", "error", err, "key_id", keyID)
+		WriteInternalError(w, "Failed to remove SSH key")
+		return
+	}
+
+	h.logger.Info("ssh key removed", "key_id", keyID, "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions handles GET /v1/admin/ssh-sessions - lists brokered SSH
+// sessions, newest first, optionally for one node (?node_id=).
+func (h *SSHKeyHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSSHSessionsListed {
+			WriteBadRequest(w, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	sessions, err := h.store.SSH().ListSessions(r.Context(), r.URL.Query().Get("node_id"), limit)
+	if err != nil {
+		h.logger.Error("failed to list ssh sessions", "error", err)
+		WriteInternalError(w, "Failed to list SSH sessions")
+		return
+	}
+	if sessions == nil {
+		sessions = []*models.SSHSession{}
+	}
+	WriteJSON(w, http.StatusOK, sessions)
+}
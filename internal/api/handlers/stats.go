@@ -3,8 +3,12 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
 	"github.com/narvanalabs/control-plane/internal/models"
@@ -121,3 +125,60 @@ func (h *StatsHandler) calculateNodeHealth(ctx context.Context) (*NodeHealthSumm
 
 	return summary, nil
 }
+
+// GetHealthSummary handles GET /v1/health/summary - returns compact health
+// rollups for dashboard polling. Responses carry an ETag; clients that send it
+// back in If-None-Match get 304 Not Modified while nothing has changed.
+func (h *StatsHandler) GetHealthSummary(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgID(r.Context())
+	if orgID == "" {
+		h.logger.Error("no organization context found")
+		WriteInternalError(w, "Organization context required")
+		return
+	}
+
+	summary, err := h.store.Stats().HealthSummary(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to load health summary", "error", err, "org_id", orgID)
+		WriteInternalError(w, "Failed to load health summary")
+		return
+	}
+
+	writeJSONWithETag(w, r, summary)
+}
+
+// writeJSONWithETag writes data as JSON with a content-derived ETag, or 304
+// when the request's If-None-Match already matches it.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		WriteInternalError(w, "Failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using weak
+// comparison as required for GET requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -53,6 +53,51 @@ func (m *statsMockStore) GitHubAccounts() store.GitHubAccountStore
 func (m *statsMockStore) Settings() store.SettingsStore                                { return nil }
 func (m *statsMockStore) Domains() store.DomainStore                                   { return nil }
 func (m *statsMockStore) Invitations() store.InvitationStore                           { return nil }
+func (m *statsMockStore) Announcements() store.AnnouncementStore                       { return nil }
+func (m *statsMockStore) Releases() store.ReleaseStore                                 { return nil }
+func (m *statsMockStore) Stats() store.StatsStore                                      { return nil }
+func (m *statsMockStore) EgressViolations() store.EgressViolationStore                 { return nil }
+func (m *statsMockStore) DeployFreezes() store.DeployFreezeStore                       { return nil }
+func (m *statsMockStore) OnCall() store.OnCallStore                                    { return nil }
+func (m *statsMockStore) APICatalog() store.APICatalogStore                            { return nil }
+func (m *statsMockStore) SCIM() store.SCIMStore                                        { return nil }
+func (m *statsMockStore) APIKeys() store.APIKeyStore                                   { return nil }
+func (m *statsMockStore) Notifications() store.NotificationStore                       { return nil }
+func (m *statsMockStore) Promotions() store.PromotionStore                             { return nil }
+func (m *statsMockStore) SSH() store.SSHStore                                          { return nil }
+func (m *statsMockStore) BuildWorkers() store.BuildWorkerStore                         { return nil }
+func (m *statsMockStore) ServiceTemplates() store.ServiceTemplateStore                 { return nil }
+func (m *statsMockStore) BuildLogs() store.BuildLogStore                               { return nil }
+func (m *statsMockStore) BuildSnapshots() store.BuildSnapshotStore                     { return nil }
+func (m *statsMockStore) AppHooks() store.AppHookStore                                 { return nil }
+func (m *statsMockStore) CDN() store.CDNStore                                          { return nil }
+func (m *statsMockStore) BuildAttestations() store.BuildAttestationStore               { return nil }
+func (m *statsMockStore) CronRuns() store.CronRunStore                                 { return nil }
+func (m *statsMockStore) Metrics() store.MetricStore                                   { return nil }
+func (m *statsMockStore) AdmissionPolicies() store.AdmissionPolicyStore                { return nil }
+func (m *statsMockStore) Audit() store.AuditStore                                      { return nil }
+func (m *statsMockStore) OrgSecrets() store.OrgSecretStore                             { return nil }
+func (m *statsMockStore) NodeJoinTokens() store.NodeJoinTokenStore                     { return nil }
+func (m *statsMockStore) SmokeTests() store.SmokeTestStore                             { return nil }
+func (m *statsMockStore) LoadTests() store.LoadTestStore                               { return nil }
+func (m *statsMockStore) Archives() store.ArchiveStore                                 { return nil }
+func (m *statsMockStore) Operations() store.OperationStore                             { return nil }
+func (m *statsMockStore) DebugSessions() store.DebugSessionStore                       { return nil }
+func (m *statsMockStore) AuthSessions() store.AuthSessionStore                         { return nil }
+func (m *statsMockStore) RateLimits() store.RateLimitStore                             { return nil }
+func (m *statsMockStore) Consistency() store.ConsistencyStore                          { return nil }
+func (m *statsMockStore) DBHealth() store.DBHealthStore                                { return nil }
+func (m *statsMockStore) AppManifests() store.AppManifestStore                         { return nil }
+func (m *statsMockStore) IdempotencyKeys() store.IdempotencyKeyStore                   { return nil }
+func (m *statsMockStore) ScaleEvents() store.ScaleEventStore                           { return nil }
+func (m *statsMockStore) Environments() store.EnvironmentStore                         { return nil }
+func (m *statsMockStore) Federation() store.FederationStore                            { return nil }
+func (m *statsMockStore) EvidenceExports() store.EvidenceExportStore                   { return nil }
+func (m *statsMockStore) APIUsage() store.APIUsageStore                                { return nil }
+func (m *statsMockStore) Events() store.EventStore                                     { return nil }
+func (m *statsMockStore) Webhooks() store.WebhookStore                                 { return nil }
+func (m *statsMockStore) AppTemplates() store.AppTemplateStore                         { return nil }
+func (m *statsMockStore) Services() store.ServiceStore                                 { return nil }
 func (m *statsMockStore) WithTx(ctx context.Context, fn func(store.Store) error) error { return fn(m) }
 func (m *statsMockStore) Close() error                                                 { return nil }
 func (m *statsMockStore) Ping(ctx context.Context) error                               { return nil }
@@ -104,6 +149,16 @@ func (m *statsAppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.
 	return apps, nil
 }
 
+func (m *statsAppStore) ListAll(ctx context.Context) ([]*models.App, error) {
+	var result []*models.App
+	for _, app := range m.apps {
+		if app.DeletedAt == nil {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
 func (m *statsAppStore) Update(ctx context.Context, app *models.App) error {
 	m.apps[app.ID] = app
 	return nil
@@ -259,6 +314,30 @@ func (m *statsNodeStore) ListWithClosure(ctx context.Context, storePath string)
 	return nil, nil
 }
 
+func (m *statsNodeStore) UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error {
+	return nil
+}
+
+func (m *statsNodeStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *statsNodeStore) UpdateHealthScore(ctx context.Context, id string, score int, load *models.NodeLoad) error {
+	return nil
+}
+
+func (m *statsNodeStore) UpdateClock(ctx context.Context, id string, skew time.Duration, certificateExpiresAt *time.Time) error {
+	return nil
+}
+
+func (m *statsNodeStore) CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error {
+	return nil
+}
+
+func (m *statsNodeStore) ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error) {
+	return nil, nil
+}
+
 // genOrgID generates valid organization IDs
 func genOrgID() gopter.Gen {
 	return gen.RegexMatch("[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}")
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestWriteJSONWithETag(t *testing.T) {
+	summary := models.NewHealthSummary()
+	summary.Nodes.Add(models.NodeStateHealthy, 2)
+
+	first := httptest.NewRecorder()
+	writeJSONWithETag(first, httptest.NewRequest(http.MethodGet, "/v1/health/summary", nil), summary)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/health/summary", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	cached := httptest.NewRecorder()
+	writeJSONWithETag(cached, req, summary)
+	if cached.Code != http.StatusNotModified || cached.Body.Len() != 0 {
+		t.Errorf("expected empty 304, got %d with %d bytes", cached.Code, cached.Body.Len())
+	}
+
+	summary.Nodes.Add(models.NodeStateUnhealthy, 1)
+	changed := httptest.NewRecorder()
+	writeJSONWithETag(changed, req, summary)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("expected a new ETag after the summary changed, got %d %q", changed.Code, changed.Header().Get("ETag"))
+	}
+}
+
+func TestRollupAppHealth(t *testing.T) {
+	tests := []struct {
+		states []models.ServiceState
+		want   models.AppHealthState
+	}{
+		{nil, models.AppHealthIdle},
+		{[]models.ServiceState{models.ServiceStateNew, models.ServiceStateStopped}, models.AppHealthIdle},
+		{[]models.ServiceState{models.ServiceStateRunning, models.ServiceStateStopped}, models.AppHealthRunning},
+		{[]models.ServiceState{models.ServiceStateRunning, models.ServiceStateDeploying}, models.AppHealthDeploying},
+		{[]models.ServiceState{models.ServiceStateDeploying, models.ServiceStateFailed}, models.AppHealthFailed},
+	}
+
+	for _, tt := range tests {
+		if got := models.RollupAppHealth(tt.states); got != tt.want {
+			t.Errorf("RollupAppHealth(%v) = %q, want %q", tt.states, got, tt.want)
+		}
+	}
+}
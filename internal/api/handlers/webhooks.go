@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// WebhooksHandler handles the user's webhooks and their delivery log.
+type WebhooksHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewWebhooksHandler creates a new webhooks handler.
+func NewWebhooksHandler(st store.Store, logger *slog.Logger) *WebhooksHandler {
+	return &WebhooksHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// WebhookRequest is the request body for creating or updating a webhook. A
+// secret left empty on update keeps the stored one.
+type WebhookRequest struct {
+	Name    string                `json:"name"`
+	URL     string                `json:"url"`
+	Enabled *bool                 `json:"enabled,omitempty"`
+	Events  []models.WebhookEvent `json:"events"`
+	AppID   string                `json:"app_id,omitempty"`
+	Secret  string                `json:"secret,omitempty"`
+}
+
+// WebhookResponse is a webhook with its secret withheld.
+type WebhookResponse struct {
+	*models.Webhook
+	// SecretSet reports whether payloads are signed.
+	SecretSet bool `json:"secret_set"`
+}
+
+// List handles GET /v1/webhooks - lists the user's webhooks.
+func (h *WebhooksHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	webhooks, err := h.store.Webhooks().ListByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list webhooks", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to list webhooks")
+		return
+	}
+
+	resp := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, webhookResponse(webhook))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// Create handles POST /v1/webhooks - adds a webhook.
+func (h *WebhooksHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	webhook := &models.Webhook{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		URL:     req.URL,
+		Enabled: req.Enabled == nil || *req.Enabled,
+		Events:  req.Events,
+		AppID:   req.AppID,
+		Secret:  req.Secret,
+	}
+	if !h.checkWebhook(w, r, webhook) {
+		return
+	}
+
+	if err := h.store.Webhooks().Create(r.Context(), webhook); err != nil {
+		h.logger.Error("failed to create webhook", "error", err, "user_id", userID)
+		WriteInternalError(w, "Failed to create webhook")
+		return
+	}
+
+	h.logger.Info("webhook created", "user_id", userID, "webhook_id", webhook.ID, "events", webhook.Events)
+	WriteJSON(w, http.StatusCreated, webhookResponse(webhook))
+}
+
+// Get handles GET /v1/webhooks/{webhookID} - returns a webhook.
+func (h *WebhooksHandler) Get(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, webhookResponse(webhook))
+}
+
+// Update handles PUT /v1/webhooks/{webhookID} - edits a webhook.
+func (h *WebhooksHandler) Update(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	webhook := *existing
+	webhook.Name = strings.TrimSpace(req.Name)
+	webhook.URL = req.URL
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	webhook.Events = req.Events
+	webhook.AppID = req.AppID
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if !h.checkWebhook(w, r, &webhook) {
+		return
+	}
+
+	if err := h.store.Webhooks().Update(r.Context(), &webhook); err != nil {
+		h.logger.Error("failed to update webhook", "error", err, "webhook_id", webhook.ID)
+		WriteInternalError(w, "Failed to update webhook")
+		return
+	}
+	WriteJSON(w, http.StatusOK, webhookResponse(&webhook))
+}
+
+// Delete handles DELETE /v1/webhooks/{webhookID} - removes a webhook and its
+// delivery log.
+func (h *WebhooksHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Webhooks().Delete(r.Context(), webhook.ID); err != nil {
+		h.logger.Error("failed to delete webhook", "error", err, "webhook_id", webhook.ID)
+		WriteInternalError(w, "Failed to delete webhook")
+		return
+	}
+
+	h.logger.Info("webhook deleted", "user_id", webhook.UserID, "webhook_id", webhook.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /v1/webhooks/{webhookID}/deliveries - lists the
+// webhook's most recent deliveries with the payload sent and the response.
+func (h *WebhooksHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultHookDeliveries
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			WriteBadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.store.Webhooks().ListDeliveries(r.Context(), webhook.ID, limit)
+	if err != nil {
+		h.logger.Error("failed to list webhook deliveries", "error", err, "webhook_id", webhook.ID)
+		WriteInternalError(w, "Failed to list webhook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+	WriteJSON(w, http.StatusOK, deliveries)
+}
+
+// Redeliver handles POST /v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver -
+// queues the payload of a delivery again, as a new delivery sent with the
+// next batch. The payload keeps its ID, so receivers can tell it is the
+// same event.
+func (h *WebhooksHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID := chi.URLParam(r, "deliveryID")
+	original, err := h.store.Webhooks().GetDelivery(r.Context(), deliveryID)
+	if err != nil {
+		h.logger.Error("failed to get webhook delivery", "error", err, "delivery_id", deliveryID)
+		WriteInternalError(w, "Failed to load delivery")
+		return
+	}
+	if original == nil || original.WebhookID != webhook.ID {
+		WriteNotFound(w, "Delivery not found")
+		return
+	}
+	if !webhook.Enabled {
+		WriteConflict(w, "Webhook is disabled")
+		return
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID:    webhook.ID,
+		Payload:      original.Payload,
+		RedeliveryOf: original.ID,
+	}
+	if err := h.store.Webhooks().EnqueueDelivery(r.Context(), delivery); err != nil {
+		h.logger.Error("failed to queue webhook redelivery", "error", err, "delivery_id", original.ID)
+		WriteInternalError(w, "Failed to queue redelivery")
+		return
+	}
+
+	h.logger.Info("webhook redelivery queued", "webhook_id", webhook.ID, "delivery_id", delivery.ID, "redelivery_of", original.ID)
+	WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+// checkWebhook validates a webhook and checks that its user can access the
+// app it is limited to and, if it subscribes to node events, is an instance
+// admin, writing an error response if not.
+func (h *WebhooksHandler) checkWebhook(w http.ResponseWriter, r *http.Request, webhook *models.Webhook) bool {
+	if err := webhook.Validate(); err != nil {
+		WriteBadRequest(w, err.Error())
+		return false
+	}
+	ctx := r.Context()
+
+	if webhook.AppID != "" {
+		app, err := h.store.Apps().Get(ctx, webhook.AppID)
+		if err != nil || app == nil {
+			WriteNotFound(w, "Application not found")
+			return false
+		}
+		access := &eventAccess{store: h.store, userID: webhook.UserID, apps: make(map[string]bool)}
+		allowed, err := access.allowed(ctx, app.ID, app.OwnerID, app.OrgID)
+		if err != nil {
+			h.logger.Error("failed to check org membership", "error", err, "org_id", app.OrgID, "user_id", webhook.UserID)
+			WriteInternalError(w, "Failed to verify access")
+			return false
+		}
+		if !allowed {
+			WriteNotFound(w, "Application not found")
+			return false
+		}
+	}
+
+	// Webhooks of other users subscribed to all events get app events only
+	if slices.ContainsFunc(webhook.Events, models.WebhookEvent.IsNode) {
+		user, err := h.store.Users().GetByID(ctx, webhook.UserID)
+		if err != nil {
+			h.logger.Error("failed to get user", "error", err, "user_id", webhook.UserID)
+			WriteInternalError(w, "Failed to verify access")
+			return false
+		}
+		if user == nil || auth.CheckRolePermission(user.Role, auth.PermissionAdminConsole) != nil {
+			WriteForbidden(w, "Only instance admins can subscribe to node events")
+			return false
+		}
+	}
+	return true
+}
+
+// loadWebhook fetches the webhook named in the URL, writing an error
+// response if it cannot or the webhook belongs to another user.
+func (h *WebhooksHandler) loadWebhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	webhookID := chi.URLParam(r, "webhookID")
+	webhook, err := h.store.Webhooks().Get(r.Context(), webhookID)
+	if err != nil {
+		h.logger.Error("failed to get webhook", "error", err, "webhook_id", webhookID)
+		WriteInternalError(w, "Failed to load webhook")
+		return nil, false
+	}
+	if webhook == nil || webhook.UserID != middleware.GetUserID(r.Context()) {
+		WriteNotFound(w, "Webhook not found")
+		return nil, false
+	}
+	return webhook, true
+}
+
+// webhookResponse withholds a webhook's secret.
+func webhookResponse(webhook *models.Webhook) WebhookResponse {
+	redacted := *webhook
+	redacted.Secret = ""
+	return WebhookResponse{Webhook: &redacted, SecretSet: webhook.Secret != ""}
+}
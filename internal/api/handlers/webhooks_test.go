@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockWebhookStore implements store.WebhookStore for testing.
+type mockWebhookStore struct {
+	store.WebhookStore
+	webhooks   map[string]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (m *mockWebhookStore) Create(ctx context.Context, webhook *models.Webhook) error {
+	webhook.ID = "webhook-" + webhook.Name
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookStore) Get(ctx context.Context, id string) (*models.Webhook, error) {
+	if w, ok := m.webhooks[id]; ok {
+		copied := *w
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockWebhookStore) Update(ctx context.Context, webhook *models.Webhook) error {
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookStore) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockWebhookStore) EnqueueDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	d.ID = "redelivery"
+	d.Status = models.WebhookDeliveryPending
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+// webhookMockStore adds webhooks to the events mock store.
+type webhookMockStore struct {
+	*eventsMockStore
+	webhooks *mockWebhookStore
+}
+
+func (m *webhookMockStore) Webhooks() store.WebhookStore {
+	return m.webhooks
+}
+
+func TestWebhooks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	st := &webhookMockStore{
+		eventsMockStore: &eventsMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			orgs:                &roleOrgStore{orgID: "org-1", role: models.RoleMember},
+			users:               &adminUserStore{adminID: "admin"},
+		},
+		webhooks: &mockWebhookStore{webhooks: map[string]*models.Webhook{}},
+	}
+	st.appStore.apps["app-1"] = &models.App{ID: "app-1", OwnerID: "user-1", Name: "shop"}
+	st.appStore.apps["app-3"] = &models.App{ID: "app-3", OwnerID: "user-3", OrgID: "org-2", Name: "other"}
+	st.webhooks.webhooks["webhook-foreign"] = &models.Webhook{ID: "webhook-foreign", UserID: "user-2", Name: "foreign", URL: "https://example.com", Enabled: true}
+	st.webhooks.deliveries = []*models.WebhookDelivery{
+		{ID: "d1", WebhookID: "webhook-deploys", Status: models.WebhookDeliveryFailed,
+			Payload: models.WebhookPayload{ID: "event-1", Event: models.WebhookDeploymentFailed, AppID: "app-1"}},
+	}
+	h := NewWebhooksHandler(st, logger)
+
+	// Creating with a secret signs payloads but never returns the secret
+	rr := httptest.NewRecorder()
+	h.Create(rr, templateRequest(http.MethodPost, "/v1/webhooks", WebhookRequest{
+		Name:   "deploys",
+		URL:    "https://ci.example.com/narvana",
+		Events: []models.WebhookEvent{models.WebhookDeploymentFailed},
+		AppID:  "app-1",
+		Secret: "s3cret",
+	}, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created WebhookResponse
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.Secret != "" || !created.SecretSet || !created.Enabled || created.UserID != "user-1" {
+		t.Errorf("created = %+v", created)
+	}
+
+	rejected := []struct {
+		name   string
+		req    WebhookRequest
+		status int
+	}{
+		{"bad url", WebhookRequest{Name: "x", URL: "ci.example.com"}, http.StatusBadRequest},
+		{"unknown event", WebhookRequest{Name: "x", URL: "https://example.com", Events: []models.WebhookEvent{"app.deleted"}}, http.StatusBadRequest},
+		{"inaccessible app", WebhookRequest{Name: "x", URL: "https://example.com", AppID: "app-3"}, http.StatusNotFound},
+		{"node events", WebhookRequest{Name: "x", URL: "https://example.com", Events: []models.WebhookEvent{models.WebhookNodeUnhealthy}}, http.StatusForbidden},
+	}
+	for _, tt := range rejected {
+		rr := httptest.NewRecorder()
+		h.Create(rr, templateRequest(http.MethodPost, "/v1/webhooks", tt.req, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rr.Code, tt.status, rr.Body.String())
+		}
+	}
+
+	// Updating without a secret keeps the stored one
+	rr = httptest.NewRecorder()
+	h.Update(rr, templateRequest(http.MethodPut, "/v1/webhooks/webhook-deploys", WebhookRequest{
+		Name: "deploys", URL: "https://ci.example.com/v2",
+	}, map[string]string{"webhookID": "webhook-deploys"}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if stored := st.webhooks.webhooks["webhook-deploys"]; stored.Secret != "s3cret" || stored.URL != "https://ci.example.com/v2" {
+		t.Errorf("stored after update = %+v", stored)
+	}
+
+	// Redelivering queues the same payload again
+	rr = httptest.NewRecorder()
+	h.Redeliver(rr, templateRequest(http.MethodPost, "/v1/webhooks/webhook-deploys/deliveries/d1/redeliver", nil,
+		map[string]string{"webhookID": "webhook-deploys", "deliveryID": "d1"}))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("redeliver: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := st.webhooks.deliveries[1]; got.RedeliveryOf != "d1" || got.Payload.ID != "event-1" || got.WebhookID != "webhook-deploys" {
+		t.Errorf("redelivery = %+v", got)
+	}
+
+	// Other users' webhooks and deliveries are not found
+	rr = httptest.NewRecorder()
+	h.Redeliver(rr, templateRequest(http.MethodPost, "/v1/webhooks/webhook-foreign/deliveries/d1/redeliver", nil,
+		map[string]string{"webhookID": "webhook-foreign", "deliveryID": "d1"}))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("foreign webhook: status = %d, want 404", rr.Code)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
+
+// defaultWhatsNewLimit is the number of releases returned when no limit is given.
+const defaultWhatsNewLimit = 10
+
+// WhatsNewHandler serves the in-app "what's new" feed built from CHANGELOG.md.
+// The changelog is written by the release pipeline in scripts/, so the feed
+// always matches the published release notes.
+type WhatsNewHandler struct {
+	changelogPath string
+	logger        *slog.Logger
+}
+
+// NewWhatsNewHandler creates a new what's new handler reading the given changelog file.
+func NewWhatsNewHandler(changelogPath string, logger *slog.Logger) *WhatsNewHandler {
+	return &WhatsNewHandler{
+		changelogPath: changelogPath,
+		logger:        logger,
+	}
+}
+
+// WhatsNewRelease is a single release in the what's new feed.
+type WhatsNewRelease struct {
+	Version    string    `json:"version"`
+	Date       time.Time `json:"date"`
+	ReleaseURL string    `json:"release_url,omitempty"`
+	ReleaseChanges
+}
+
+// ReleaseChanges is a list of commits categorized into release note sections.
+type ReleaseChanges struct {
+	Features        []WhatsNewItem `json:"features"`
+	Improvements    []WhatsNewItem `json:"improvements"`
+	BugFixes        []WhatsNewItem `json:"bug_fixes"`
+	BreakingChanges []WhatsNewItem `json:"breaking_changes"`
+	Other           []WhatsNewItem `json:"other"`
+}
+
+// WhatsNewItem is a single change within a release.
+type WhatsNewItem struct {
+	Area        string `json:"area,omitempty"`
+	Description string `json:"description"`
+}
+
+// changelogHashSuffix matches the trailing short commit hash on changelog lines.
+var changelogHashSuffix = regexp.MustCompile(`\s*\([0-9a-f]{7,40}\)$`)
+
+// List handles GET /v1/whats-new - returns recent releases, newest first.
+// Accepts an optional ?limit= query parameter.
+func (h *WhatsNewHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultWhatsNewLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			WriteBadRequest(w, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	data, err := os.ReadFile(h.changelogPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Instances installed without a changelog simply have an empty feed
+		WriteJSON(w, http.StatusOK, []WhatsNewRelease{})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to read changelog", "error", err, "path", h.changelogPath)
+		WriteInternalError(w, "Failed to load release notes")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, buildWhatsNewFeed(string(data), limit))
+}
+
+// buildWhatsNewFeed parses the changelog and categorizes each release's changes.
+func buildWhatsNewFeed(changelog string, limit int) []WhatsNewRelease {
+	entries := releasenotes.ParseChangelog(changelog)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	releases := make([]WhatsNewRelease, 0, len(entries))
+	for _, entry := range entries {
+		var lines []string
+		for _, line := range strings.Split(entry.ReleaseNotes, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "- ") {
+				lines = append(lines, strings.TrimPrefix(line, "- "))
+			}
+		}
+
+		releases = append(releases, WhatsNewRelease{
+			Version:        entry.Version,
+			Date:           entry.Date,
+			ReleaseURL:     entry.ReleaseURL,
+			ReleaseChanges: categorizeChanges(lines),
+		})
+	}
+	return releases
+}
+
+// categorizeChanges runs commit messages through the release notes engine
+// shared with the release pipeline and groups them into sections.
+func categorizeChanges(commitMessages []string) ReleaseChanges {
+	commits := make([]releasenotes.ParsedCommit, 0, len(commitMessages))
+	for _, msg := range commitMessages {
+		msg = changelogHashSuffix.ReplaceAllString(strings.TrimSpace(msg), "")
+		commits = append(commits, releasenotes.ParseCommitWithFallback(msg))
+	}
+
+	content := releasenotes.CategorizeCommitsWithFallback(releasenotes.GroupCommitsWithFallback(commits))
+	return ReleaseChanges{
+		Features:        whatsNewItems(content.Features),
+		Improvements:    whatsNewItems(content.Improvements),
+		BugFixes:        whatsNewItems(content.BugFixes),
+		BreakingChanges: whatsNewItems(content.BreakingChanges),
+		Other:           whatsNewItems(content.Other),
+	}
+}
+
+// whatsNewItems flattens commit groups into display items.
+func whatsNewItems(groups []releasenotes.CommitGroup) []WhatsNewItem {
+	items := []WhatsNewItem{}
+	for _, group := range groups {
+		area := group.Name
+		if area == string(releasenotes.FeatureAreaOther) {
+			area = ""
+		}
+		for _, commit := range group.Commits {
+			desc := releasenotes.CleanDescription(commit.Description)
+			if desc == "" {
+				continue
+			}
+			items = append(items, WhatsNewItem{Area: area, Description: desc})
+		}
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildWhatsNewFeed(t *testing.T) {
+	changelog := `# Changelog
+
+All notable changes to Narvana are documented here.
+
+## [0.0.2] - 2026-01-25
+
+- feat(github): Add repository selection (f2b5c31)
+- fix: correct container paths (08b7b4d)
+
+## [0.0.1] - 2026-01-08
+
+- chore(scripts): Refactor install.sh (00f59a3)
+
+[0.0.2]: https://github.com/narvanalabs/control-plane/releases/tag/v0.0.2
+[0.0.1]: https://github.com/narvanalabs/control-plane/releases/tag/v0.0.1
+`
+
+	feed := buildWhatsNewFeed(changelog, 10)
+	if len(feed) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(feed))
+	}
+
+	latest := feed[0]
+	if latest.Version != "0.0.2" || latest.ReleaseURL == "" {
+		t.Errorf("unexpected latest release: %+v", latest)
+	}
+	if len(latest.Features) != 1 || len(latest.BugFixes) != 1 {
+		t.Fatalf("expected one feature and one fix, got %+v", latest)
+	}
+	if strings.Contains(latest.Features[0].Description, "f2b5c31") {
+		t.Errorf("expected commit hash to be stripped, got %q", latest.Features[0].Description)
+	}
+	if len(feed[1].Other) != 1 {
+		t.Errorf("expected chore to be listed under other changes, got %+v", feed[1])
+	}
+
+	if limited := buildWhatsNewFeed(changelog, 1); len(limited) != 1 || limited[0].Version != "0.0.2" {
+		t.Errorf("expected limit to keep only the newest release, got %+v", limited)
+	}
+}
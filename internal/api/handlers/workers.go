@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// WorkerHandler handles requests about the build workers sharing the queue.
+type WorkerHandler struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewWorkerHandler creates a new build worker handler.
+func NewWorkerHandler(st store.Store, logger *slog.Logger) *WorkerHandler {
+	return &WorkerHandler{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// List handles GET /v1/workers - lists registered build workers with the
+// jobs they hold, optionally only those in one state (?state=).
+func (h *WorkerHandler) List(w http.ResponseWriter, r *http.Request) {
+	state := models.BuildWorkerState(r.URL.Query().Get("state"))
+	switch state {
+	case "", models.BuildWorkerStateActive, models.BuildWorkerStateUnresponsive, models.BuildWorkerStateStopped:
+	default:
+		WriteBadRequest(w, "state must be one of active, unresponsive, stopped")
+		return
+	}
+
+	workers, err := h.store.BuildWorkers().List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list build workers", "error", err)
+		WriteInternalError(w, "Failed to list build workers")
+		return
+	}
+
+	now := time.Now()
+	result := make([]*models.BuildWorker, 0, len(workers))
+	for _, worker := range workers {
+		worker.State = worker.StateAt(now)
+		if state != "" && worker.State != state {
+			continue
+		}
+		result = append(result, worker)
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// mockBuildWorkerStore lists a fixed set of build workers.
+type mockBuildWorkerStore struct {
+	store.BuildWorkerStore
+	workers []*models.BuildWorker
+}
+
+func (m *mockBuildWorkerStore) List(ctx context.Context) ([]*models.BuildWorker, error) {
+	return m.workers, nil
+}
+
+// workerMockStore adds build workers to the deployment mock store.
+type workerMockStore struct {
+	*deploymentMockStore
+	workers *mockBuildWorkerStore
+}
+
+func (m *workerMockStore) BuildWorkers() store.BuildWorkerStore {
+	return m.workers
+}
+
+func TestListWorkers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	now := time.Now()
+	stoppedAt := now.Add(-time.Hour)
+
+	newStore := func() *workerMockStore {
+		return &workerMockStore{
+			deploymentMockStore: newDeploymentMockStore(),
+			workers: &mockBuildWorkerStore{workers: []*models.BuildWorker{
+				{ID: "live", LeaseSeconds: 120, LastHeartbeatAt: now.Add(-10 * time.Second), ClaimedJobs: []string{"job-1"}},
+				{ID: "dead", LeaseSeconds: 120, LastHeartbeatAt: now.Add(-5 * time.Minute), ClaimedJobs: []string{"job-2"}},
+				{ID: "gone", LeaseSeconds: 120, LastHeartbeatAt: stoppedAt, StoppedAt: &stoppedAt, ClaimedJobs: []string{}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   map[string]models.BuildWorkerState
+	}{
+		{"all workers", "", http.StatusOK, map[string]models.BuildWorkerState{
+			"live": models.BuildWorkerStateActive,
+			"dead": models.BuildWorkerStateUnresponsive,
+			"gone": models.BuildWorkerStateStopped,
+		}},
+		{"unresponsive only", "?state=unresponsive", http.StatusOK, map[string]models.BuildWorkerState{
+			"dead": models.BuildWorkerStateUnresponsive,
+		}},
+		{"invalid state", "?state=sleeping", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewWorkerHandler(newStore(), logger)
+			rr := httptest.NewRecorder()
+			h.List(rr, httptest.NewRequest(http.MethodGet, "/v1/workers"+tt.query, nil))
+
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.status, rr.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+
+			var workers []models.BuildWorker
+			if err := json.Unmarshal(rr.Body.Bytes(), &workers); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			got := make(map[string]models.BuildWorkerState)
+			for _, w := range workers {
+				got[w.ID] = w.State
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("workers = %v, want %v", got, tt.want)
+			}
+			for id, state := range tt.want {
+				if got[id] != state {
+					t.Errorf("worker %s state = %q, want %q", id, got[id], state)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// WorkloadIdentityHandler issues workload identity tokens and publishes the
+// keys that verify them.
+type WorkloadIdentityHandler struct {
+	store  store.Store
+	issuer *identity.Issuer
+	logger *slog.Logger
+}
+
+// NewWorkloadIdentityHandler creates a new workload identity handler.
+func NewWorkloadIdentityHandler(st store.Store, issuer *identity.Issuer, logger *slog.Logger) *WorkloadIdentityHandler {
+	return &WorkloadIdentityHandler{
+		store:  st,
+		issuer: issuer,
+		logger: logger,
+	}
+}
+
+// WorkloadTokenRequest is the request body for issuing a workload token. An
+// empty audience issues a token for the control plane API.
+type WorkloadTokenRequest struct {
+	DeploymentID string   `json:"deployment_id,omitempty"`
+	Audience     []string `json:"audience,omitempty"`
+}
+
+// WorkloadTokenResponse is an issued workload token.
+type WorkloadTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Subject   string    `json:"subject"`
+	Audience  []string  `json:"audience"`
+}
+
+// JWKS handles GET /.well-known/jwks.json.
+func (h *WorkloadIdentityHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	WriteJSON(w, http.StatusOK, h.issuer.JWKS())
+}
+
+// Discovery handles GET /.well-known/openid-configuration.
+func (h *WorkloadIdentityHandler) Discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	WriteJSON(w, http.StatusOK, h.issuer.Discovery())
+}
+
+// IssueForNode handles POST /v1/nodes/{nodeID}/workload-tokens. Node agents
+// request tokens for the deployments they run and write them where the
+// workload can read them.
+func (h *WorkloadIdentityHandler) IssueForNode(w http.ResponseWriter, r *http.Request) {
+	nodeID := chi.URLParam(r, "nodeID")
+
+	// Node agents authenticate with a token whose subject is their node ID
+	if callerID := middleware.GetUserID(r.Context()); callerID != nodeID {
+		user, err := h.store.Users().GetByID(r.Context(), callerID)
+		if err != nil {
+			h.logger.Error("failed to look up caller", "error", err, "user_id", callerID)
+			WriteInternalError(w, "Failed to authorize request")
+			return
+		}
+		if user == nil || user.Role != store.RoleOwner {
+			WriteForbidden(w, "Only the node's agent can request workload tokens")
+			return
+		}
+	}
+
+	var req WorkloadTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.DeploymentID == "" {
+		WriteBadRequest(w, "deployment_id is required")
+		return
+	}
+
+	h.issue(w, r, req.DeploymentID, nodeID, req.Audience)
+}
+
+// Exchange handles POST /v1/workload-identity/token. A workload trades its
+// control plane token for one scoped to the audiences of other services.
+func (h *WorkloadIdentityHandler) Exchange(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetWorkloadClaims(r.Context())
+	if claims == nil {
+		WriteForbidden(w, "Only workloads can exchange workload identity tokens")
+		return
+	}
+
+	var req WorkloadTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+	if len(req.Audience) == 0 {
+		WriteBadRequest(w, "audience is required")
+		return
+	}
+
+	h.issue(w, r, claims.DeploymentID, claims.NodeID, req.Audience)
+}
+
+// issue signs a token for a deployment that is running on nodeID.
+func (h *WorkloadIdentityHandler) issue(w http.ResponseWriter, r *http.Request, deploymentID, nodeID string, audience []string) {
+	ctx := r.Context()
+	deployment, err := h.store.Deployments().Get(ctx, deploymentID)
+	if err != nil || deployment == nil {
+		WriteNotFound(w, "Deployment not found")
+		return
+	}
+	if deployment.NodeID != nodeID {
+		WriteForbidden(w, "Deployment is not scheduled on this node")
+		return
+	}
+	switch deployment.Status {
+	case models.DeploymentStatusScheduled, models.DeploymentStatusStarting, models.DeploymentStatusRunning:
+	default:
+		WriteConflict(w, "Deployment is "+string(deployment.Status)+"; tokens are only issued to active deployments")
+		return
+	}
+
+	app, err := h.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil {
+		WriteNotFound(w, "App not found")
+		return
+	}
+
+	token, expiresAt, err := h.issuer.Issue(app, deployment, audience)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidAudience) {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+		h.logger.Error("failed to issue workload token", "error", err, "deployment_id", deployment.ID)
+		WriteInternalError(w, "Failed to issue workload token")
+		return
+	}
+	if len(audience) == 0 {
+		audience = []string{h.issuer.Issuer()}
+	}
+
+	WriteJSON(w, http.StatusOK, WorkloadTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Subject:   identity.Subject(app.ID, deployment.ServiceName),
+		Audience:  audience,
+	})
+}
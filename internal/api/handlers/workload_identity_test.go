@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type workloadDeploymentStore struct {
+	store.DeploymentStore
+	deployments map[string]*models.Deployment
+}
+
+func (s *workloadDeploymentStore) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	if d, ok := s.deployments[id]; ok {
+		return d, nil
+	}
+	return nil, errors.New("deployment not found")
+}
+
+type workloadAppStore struct {
+	store.AppStore
+}
+
+func (s *workloadAppStore) Get(ctx context.Context, id string) (*models.App, error) {
+	return &models.App{ID: id, OrgID: "org-1", OwnerID: "alice"}, nil
+}
+
+type workloadUserStore struct {
+	store.UserStore
+}
+
+func (s *workloadUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	if id == "alice" {
+		return &store.User{ID: id, Role: store.RoleMember}, nil
+	}
+	return nil, nil
+}
+
+type workloadMockStore struct {
+	store.Store
+	deployments *workloadDeploymentStore
+}
+
+func (s *workloadMockStore) Deployments() store.DeploymentStore { return s.deployments }
+func (s *workloadMockStore) Apps() store.AppStore               { return &workloadAppStore{} }
+func (s *workloadMockStore) Users() store.UserStore             { return &workloadUserStore{} }
+
+func newWorkloadHandler(t *testing.T) *WorkloadIdentityHandler {
+	t.Helper()
+	key, err := identity.LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &workloadMockStore{deployments: &workloadDeploymentStore{deployments: map[string]*models.Deployment{
+		"dep-running": {ID: "dep-running", AppID: "app-1", ServiceName: "web", NodeID: "node-1", Status: models.DeploymentStatusRunning},
+		"dep-stopped": {ID: "dep-stopped", AppID: "app-1", ServiceName: "web", NodeID: "node-1", Status: models.DeploymentStatusStopped},
+	}}}
+	issuer := identity.NewIssuer(identity.Config{Issuer: "https://cp.example.com", TokenTTL: time.Minute}, key)
+	return NewWorkloadIdentityHandler(st, issuer, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestWorkloadIdentityIssueForNode(t *testing.T) {
+	h := newWorkloadHandler(t)
+
+	issue := func(caller, nodeID, deploymentID string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(WorkloadTokenRequest{DeploymentID: deploymentID})
+		req := httptest.NewRequest(http.MethodPost, "/v1/nodes/"+nodeID+"/workload-tokens", bytes.NewReader(data))
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, caller)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("nodeID", nodeID)
+		rec := httptest.NewRecorder()
+		h.IssueForNode(rec, req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx)))
+		return rec
+	}
+
+	if rec := issue("alice", "node-1", "dep-running"); rec.Code != http.StatusForbidden {
+		t.Errorf("user token = %d, want 403", rec.Code)
+	}
+	if rec := issue("node-2", "node-2", "dep-running"); rec.Code != http.StatusForbidden {
+		t.Errorf("deployment on another node = %d, want 403", rec.Code)
+	}
+	if rec := issue("node-1", "node-1", "dep-stopped"); rec.Code != http.StatusConflict {
+		t.Errorf("stopped deployment = %d, want 409", rec.Code)
+	}
+	if rec := issue("node-1", "node-1", "dep-missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown deployment = %d, want 404", rec.Code)
+	}
+
+	rec := issue("node-1", "node-1", "dep-running")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("issue = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp WorkloadTokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := h.issuer.Verify(resp.Token, "https://cp.example.com")
+	if err != nil {
+		t.Fatalf("issued token does not verify for the control plane: %v", err)
+	}
+	if claims.Subject != "app:app-1:service:web" || claims.NodeID != "node-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestWorkloadIdentityExchange(t *testing.T) {
+	h := newWorkloadHandler(t)
+
+	exchange := func(claims *identity.Claims, audience []string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(WorkloadTokenRequest{Audience: audience})
+		req := httptest.NewRequest(http.MethodPost, "/v1/workload-identity/token", bytes.NewReader(data))
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, "alice")
+		if claims != nil {
+			ctx = context.WithValue(ctx, middleware.WorkloadClaimsKey, claims)
+		}
+		rec := httptest.NewRecorder()
+		h.Exchange(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	if rec := exchange(nil, []string{"billing"}); rec.Code != http.StatusForbidden {
+		t.Errorf("user exchange = %d, want 403", rec.Code)
+	}
+	workload := &identity.Claims{AppID: "app-1", Service: "web", DeploymentID: "dep-running", NodeID: "node-1"}
+	if rec := exchange(workload, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("no audience = %d, want 400", rec.Code)
+	}
+
+	rec := exchange(workload, []string{"billing"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("exchange = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp WorkloadTokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.issuer.Verify(resp.Token, "billing"); err != nil {
+		t.Errorf("exchanged token does not verify for billing: %v", err)
+	}
+	if _, err := h.issuer.Verify(resp.Token, "https://cp.example.com"); err == nil {
+		t.Error("exchanged token verifies for the control plane")
+	}
+}
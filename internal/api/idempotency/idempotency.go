@@ -0,0 +1,273 @@
+// Package idempotency makes retrying mutating API requests safe. A request
+// sent with an Idempotency-Key header is served once; retries with the same
+// key get the recorded response instead of running again, so clients such as
+// infrastructure-as-code tools can retry after timeouts without creating
+// duplicates.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+const (
+	// Header carries the client's key for a request.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set to "true" on recorded responses.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// maxKeyLength is the longest key accepted.
+	maxKeyLength = 255
+)
+
+// replayedHeaders are the response headers recorded with the body.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Config controls how long keys are kept and how much is recorded.
+type Config struct {
+	// TTL is how long a key's response is replayed.
+	TTL time.Duration
+	// LockTimeout is how long a request may stay in progress before a retry
+	// may take over its key, for requests whose server stopped.
+	LockTimeout time.Duration
+	// MaxRequestSize is the largest request body sent with a key.
+	MaxRequestSize int64
+	// MaxResponseSize is the largest response body recorded. Larger
+	// responses are replayed with their status and an empty body.
+	MaxResponseSize int
+	// Interval is how often expired keys are deleted.
+	Interval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		TTL:             24 * time.Hour,
+		LockTimeout:     2 * time.Minute,
+		MaxRequestSize:  8 << 20,
+		MaxResponseSize: 1 << 20,
+		Interval:        time.Hour,
+	}
+}
+
+// Keys is HTTP middleware that records and replays the responses of
+// requests sent with idempotency keys.
+type Keys struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewKeys creates the idempotency key middleware.
+func NewKeys(st store.Store, cfg Config, logger *slog.Logger) *Keys {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Keys{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Middleware serves a POST, PUT, PATCH or DELETE request sent with a key the
+// user has not used before and records its response; a retry with the same
+// key gets that response. Keys are scoped to the user, so it must run after
+// authentication. Reusing a key for a different request is rejected with a
+// 422, and retrying while the request is in progress with a 409. Responses
+// with a 5xx status are not recorded, so those requests can be retried.
+func (k *Keys) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		userID := middleware.GetUserID(r.Context())
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			key = ""
+		}
+		if key == "" || userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Idempotency-Key must be 255 characters or less")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, k.config.MaxRequestSize+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+			return
+		}
+		if int64(len(body)) > k.config.MaxRequestSize {
+			writeError(w, http.StatusRequestEntityTooLarge, "invalid_request", "Request body is too large to send with an Idempotency-Key")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		record := &models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RequestHash: requestHash(r, body),
+			CreatedAt:   k.now(),
+		}
+		existing, err := k.store.IdempotencyKeys().Begin(ctx, record)
+		if err == nil && existing != nil && existing.Status == 0 && k.now().Sub(existing.CreatedAt) > k.config.LockTimeout {
+			// The server serving the request stopped; serve it again
+			if err = k.store.IdempotencyKeys().Delete(ctx, userID, key); err == nil {
+				existing, err = k.store.IdempotencyKeys().Begin(ctx, record)
+			}
+		}
+		if err != nil {
+			k.logger.Error("failed to record idempotency key", "error", err, "user_id", userID)
+			writeError(w, http.StatusInternalServerError, "internal_error", "Failed to record idempotency key")
+			return
+		}
+
+		switch {
+		case existing == nil:
+			k.serve(w, r, next, record)
+		case existing.RequestHash != record.RequestHash:
+			writeError(w, http.StatusUnprocessableEntity, "idempotency_key_reused",
+				"Idempotency-Key was already used for a different request")
+		case existing.Status == 0:
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusConflict, "idempotency_key_in_progress",
+				"A request with this Idempotency-Key is in progress")
+		default:
+			replay(w, existing)
+		}
+	})
+}
+
+// serve runs the request and records its response under the key.
+func (k *Keys) serve(w http.ResponseWriter, r *http.Request, next http.Handler, record *models.IdempotencyKey) {
+	ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	body := &cappedBuffer{limit: k.config.MaxResponseSize}
+	ww.Tee(body)
+	next.ServeHTTP(ww, r)
+
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	// The request context may already be cancelled by a client disconnect
+	ctx := context.WithoutCancel(r.Context())
+	if status >= http.StatusInternalServerError {
+		if err := k.store.IdempotencyKeys().Delete(ctx, record.UserID, record.Key); err != nil {
+			k.logger.Error("failed to release idempotency key", "error", err, "user_id", record.UserID)
+		}
+		return
+	}
+
+	headers := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if value := ww.Header().Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	response := body.Bytes()
+	if body.truncated {
+		delete(headers, "Content-Type")
+		response = nil
+	}
+	if err := k.store.IdempotencyKeys().Complete(ctx, record.UserID, record.Key, status, headers, response); err != nil {
+		k.logger.Error("failed to record idempotent response", "error", err, "user_id", record.UserID)
+	}
+}
+
+// Run deletes expired keys every interval until ctx is cancelled.
+func (k *Keys) Run(ctx context.Context) {
+	ticker := time.NewTicker(k.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes the keys older than the TTL.
+func (k *Keys) RunOnce(ctx context.Context) {
+	deleted, err := k.store.IdempotencyKeys().DeleteBefore(ctx, k.now().Add(-k.config.TTL))
+	if err != nil {
+		k.logger.Error("failed to delete expired idempotency keys", "error", err)
+		return
+	}
+	if deleted > 0 {
+		k.logger.Info("deleted expired idempotency keys", "count", deleted)
+	}
+}
+
+// requestHash identifies a request by its method, URL and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay writes a recorded response.
+func replay(w http.ResponseWriter, k *models.IdempotencyKey) {
+	for name, value := range k.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(k.Status)
+	w.Write(k.Response)
+}
+
+// writeError writes an error in the API's error format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
+
+// cappedBuffer keeps the first limit bytes written to it.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the kept bytes.
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
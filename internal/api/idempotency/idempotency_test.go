@@ -0,0 +1,218 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type memKeys struct {
+	store.IdempotencyKeyStore
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func (m *memKeys) Begin(_ context.Context, k *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.keys[k.UserID+"/"+k.Key]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+	copied := *k
+	m.keys[k.UserID+"/"+k.Key] = &copied
+	return nil, nil
+}
+
+func (m *memKeys) Complete(_ context.Context, userID, key string, status int, headers map[string]string, response []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := m.keys[userID+"/"+key]
+	k.Status, k.Headers, k.Response = status, headers, response
+	return nil
+}
+
+func (m *memKeys) Delete(_ context.Context, userID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, userID+"/"+key)
+	return nil
+}
+
+func (m *memKeys) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for id, k := range m.keys {
+		if k.CreatedAt.Before(before) {
+			delete(m.keys, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type memStore struct {
+	store.Store
+	keys *memKeys
+}
+
+func (s *memStore) IdempotencyKeys() store.IdempotencyKeyStore { return s.keys }
+
+func newTestKeys() (*Keys, *memKeys, *time.Time) {
+	keys := &memKeys{keys: map[string]*models.IdempotencyKey{}}
+	k := NewKeys(&memStore{keys: keys}, DefaultConfig(), nil)
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	k.now = func() time.Time { return now }
+	return k, keys, &now
+}
+
+func request(method, userID, key, body string) *http.Request {
+	r := httptest.NewRequest(method, "/v1/apps", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(Header, key)
+	}
+	return r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID))
+}
+
+func TestMiddlewareReplays(t *testing.T) {
+	k, _, _ := newTestKeys()
+	calls := 0
+	handler := k.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Request-Only", "yes")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"app-%d","name":%q}`, calls, body)
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, request(http.MethodPost, "alice", "create-web", "web"))
+	retry := httptest.NewRecorder()
+	handler.ServeHTTP(retry, request(http.MethodPost, "alice", "create-web", "web"))
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if got := retry.Header().Get(ReplayedHeader); got != "true" {
+		t.Errorf("%s = %q, want true", ReplayedHeader, got)
+	}
+	if got := retry.Header().Get("ETag"); got != `"v1"` {
+		t.Errorf("ETag = %q, want recorded", got)
+	}
+	if got := retry.Header().Get("X-Request-Only"); got != "" {
+		t.Errorf("X-Request-Only = %q, want not replayed", got)
+	}
+
+	// Keys are scoped to the user
+	other := httptest.NewRecorder()
+	handler.ServeHTTP(other, request(http.MethodPost, "bob", "create-web", "web"))
+	if calls != 2 || other.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("another user's request with the same key was replayed")
+	}
+
+	// Without a key, or for reads, every request is served
+	handler.ServeHTTP(httptest.NewRecorder(), request(http.MethodPost, "alice", "", "web"))
+	handler.ServeHTTP(httptest.NewRecorder(), request(http.MethodGet, "alice", "create-web", ""))
+	if calls != 4 {
+		t.Errorf("handler called %d times, want 4", calls)
+	}
+}
+
+func TestMiddlewareRejectsReusedKey(t *testing.T) {
+	k, _, _ := newTestKeys()
+	handler := k.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), request(http.MethodPost, "alice", "k1", "web"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodPost, "alice", "k1", "api"))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodPost, "alice", strings.Repeat("k", maxKeyLength+1), "web"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("long key status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	k, keys, now := newTestKeys()
+	calls := 0
+	handler := k.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// A request still being served holds its key
+	pending := request(http.MethodPost, "alice", "k1", "web")
+	if _, err := keys.Begin(context.Background(), &models.IdempotencyKey{
+		UserID: "alice", Key: "k1", RequestHash: requestHash(pending, []byte("web")), CreatedAt: *now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodPost, "alice", "k1", "web"))
+	if w.Code != http.StatusConflict || calls != 0 {
+		t.Errorf("in progress status = %d after %d calls, want %d", w.Code, calls, http.StatusConflict)
+	}
+
+	// Until its server is presumed stopped
+	*now = now.Add(DefaultConfig().LockTimeout + time.Second)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodPost, "alice", "k1", "web"))
+	if w.Code != http.StatusOK || calls != 1 {
+		t.Errorf("stale status = %d after %d calls, want %d after 1", w.Code, calls, http.StatusOK)
+	}
+}
+
+func TestMiddlewareRetriesServerErrors(t *testing.T) {
+	k, _, _ := newTestKeys()
+	status := http.StatusInternalServerError
+	calls := 0
+	handler := k.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), request(http.MethodPost, "alice", "k1", "web"))
+	status = http.StatusCreated
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request(http.MethodPost, "alice", "k1", "web"))
+	if w.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after 5xx = %d after %d calls, want %d after 2", w.Code, calls, http.StatusCreated)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	k, keys, now := newTestKeys()
+	ctx := context.Background()
+	keys.Begin(ctx, &models.IdempotencyKey{UserID: "alice", Key: "old", CreatedAt: now.Add(-25 * time.Hour)})
+	keys.Begin(ctx, &models.IdempotencyKey{UserID: "alice", Key: "new", CreatedAt: now.Add(-time.Hour)})
+
+	k.RunOnce(ctx)
+	if _, ok := keys.keys["alice/old"]; ok {
+		t.Error("expired key was kept")
+	}
+	if _, ok := keys.keys["alice/new"]; !ok {
+		t.Error("unexpired key was deleted")
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// RequireAdmin returns a middleware that only lets instance admins through.
+// An instance admin is a user whose platform role grants the admin console
+// permission (currently the owner role).
+func RequireAdmin(st store.Store, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := GetUserID(r.Context())
+			if userID == "" {
+				writeUnauthorized(w, "Authentication required")
+				return
+			}
+
+			user, err := st.Users().GetByID(r.Context(), userID)
+			if err != nil {
+				logger.Error("failed to load user for admin check", "error", err, "user_id", userID)
+				writeInternalError(w, "Failed to verify access")
+				return
+			}
+			if user == nil {
+				writeUnauthorized(w, "User not found")
+				return
+			}
+
+			if err := auth.CheckRolePermission(user.Role, auth.PermissionAdminConsole); err != nil {
+				logger.Debug("admin check failed",
+					"user_id", userID,
+					"role", user.Role,
+					"action", r.Method+" "+r.URL.Path,
+				)
+				writeForbidden(w, "Instance admin access required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
@@ -19,6 +21,10 @@ const (
 	UserIDKey contextKey = "user_id"
 	// UserEmailKey is the context key for the authenticated user email.
 	UserEmailKey contextKey = "user_email"
+	// APIKeyIDKey is the context key for the ID of the API key used to authenticate.
+	APIKeyIDKey contextKey = "api_key_id"
+	// SessionIDKey is the context key for the sign-in session of the access token used to authenticate.
+	SessionIDKey contextKey = "session_id"
 )
 
 // GetUserID extracts the user ID from the request context.
@@ -37,11 +43,32 @@ func GetUserEmail(ctx context.Context) string {
 	return ""
 }
 
+// GetAPIKeyID extracts the ID of the API key used to authenticate the request.
+// It returns an empty string for requests authenticated with a JWT.
+func GetAPIKeyID(ctx context.Context) string {
+	if v := ctx.Value(APIKeyIDKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// GetSessionID extracts the sign-in session of the access token used to
+// authenticate the request. It returns an empty string for requests
+// authenticated otherwise.
+func GetSessionID(ctx context.Context) string {
+	if v := ctx.Value(SessionIDKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
 // AuthMiddleware handles JWT and API key authentication.
 type AuthMiddleware struct {
 	authService  *auth.Service
 	apiKeyHeader string
 	logger       *slog.Logger
+	workloads    *identity.Issuer
+	store        store.Store
 }
 
 // NewAuthMiddleware creates a new authentication middleware.
@@ -59,55 +86,104 @@ func NewAuthMiddleware(authService *auth.Service, apiKeyHeader string, logger *s
 // Authenticate is a middleware that validates JWT tokens or API keys.
 // It supports authentication via:
 // - X-API-Key header
-// - Authorization: Bearer <token> header
+// - Authorization: Bearer <token> header, where the token is a JWT or an API key
 // - ?token=<jwt> query parameter (for SSE endpoints that can't set headers)
+//
+// The scopes of a scoped API key are stored in the request context. If
+// workload identity is set, bearer tokens may also be workload identity tokens.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var userID, email string
+		var userID, email, apiKeyID, sessionID string
+		var scopes []models.APIKeyScope
+		var workload *identity.Claims
 
 		// Try API key first
 		apiKey := r.Header.Get(m.apiKeyHeader)
+		token := ""
+		if apiKey == "" {
+			// Try JWT token from Authorization header
+			authHeader := r.Header.Get("Authorization")
+			token = auth.ExtractBearerToken(authHeader)
+
+			// Fall back to query param token (for SSE/EventSource)
+			if token == "" {
+				token = r.URL.Query().Get("token")
+			}
+
+			// API keys may also be sent as bearer tokens
+			if auth.IsAPIKey(token) {
+				apiKey, token = token, ""
+			}
+		}
+
 		if apiKey != "" {
 			user, err := m.authService.ValidateAPIKey(r.Context(), apiKey)
 			if err != nil {
 				m.logger.Debug("API key validation failed", "error", err)
+				if err == auth.ErrExpiredToken {
+					writeUnauthorized(w, "API key has expired")
+					return
+				}
 				writeUnauthorized(w, "Invalid API key")
 				return
 			}
 			userID = user.ID
 			email = user.Email
+			scopes = user.Scopes
+			apiKeyID = user.APIKeyID
 		} else {
-			// Try JWT token from Authorization header
-			authHeader := r.Header.Get("Authorization")
-			token := auth.ExtractBearerToken(authHeader)
-
-			// Fall back to query param token (for SSE/EventSource)
-			if token == "" {
-				token = r.URL.Query().Get("token")
-			}
-
 			if token == "" {
 				writeUnauthorized(w, "Missing authentication")
 				return
 			}
 
-			claims, err := m.authService.ValidateToken(token)
-			if err != nil {
-				m.logger.Debug("JWT validation failed", "error", err)
-				if err == auth.ErrExpiredToken {
-					writeUnauthorized(w, "Token has expired")
+			if m.workloads != nil && m.workloads.IsWorkloadToken(token) {
+				claims, ownerID, err := m.authenticateWorkload(r.Context(), token)
+				if err != nil {
+					m.logger.Debug("workload token validation failed", "error", err)
+					writeUnauthorized(w, "Invalid workload identity token")
 					return
 				}
-				writeUnauthorized(w, "Invalid token")
-				return
+				userID = ownerID
+				scopes = workloadScopes(claims)
+				workload = claims
+			} else {
+				claims, err := m.authService.ValidateToken(token)
+				if err != nil {
+					m.logger.Debug("JWT validation failed", "error", err)
+					if err == auth.ErrExpiredToken {
+						writeUnauthorized(w, "Token has expired")
+						return
+					}
+					writeUnauthorized(w, "Invalid token")
+					return
+				}
+				if err := m.authService.CheckSession(r.Context(), claims); err != nil {
+					m.logger.Debug("session check failed", "error", err, "session_id", claims.SessionID)
+					writeUnauthorized(w, "Session has been revoked")
+					return
+				}
+				userID = claims.UserID
+				email = claims.Email
+				sessionID = claims.SessionID
 			}
-			userID = claims.UserID
-			email = claims.Email
 		}
 
 		// Add user info to context
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
 		ctx = context.WithValue(ctx, UserEmailKey, email)
+		if len(scopes) > 0 {
+			ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
+		}
+		if apiKeyID != "" {
+			ctx = context.WithValue(ctx, APIKeyIDKey, apiKeyID)
+		}
+		if sessionID != "" {
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
+		}
+		if workload != nil {
+			ctx = context.WithValue(ctx, WorkloadClaimsKey, workload)
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -155,15 +231,20 @@ func RequireOwnership(st store.Store, logger *slog.Logger) func(http.Handler) ht
 
 			// Check if user is a member of the app's organization
 			if app.OrgID != "" {
-				isMember, err := st.Orgs().IsMember(r.Context(), app.OrgID, userID)
+				role, err := st.Orgs().GetMemberRole(r.Context(), app.OrgID, userID)
 				if err != nil {
 					logger.Error("failed to check org membership", "error", err, "org_id", app.OrgID, "user_id", userID)
 					writeInternalError(w, "Failed to verify access")
 					return
 				}
-				if isMember {
-					// Store the resolved app ID in context for handlers to use
+				if role != "" {
+					if !role.AtLeast(models.RoleDeveloper) && !isReadOnly(r.Method) {
+						writeForbidden(w, "Viewers have read-only access")
+						return
+					}
+					// Store the resolved app ID and org role in context for handlers to use
 					ctx := context.WithValue(r.Context(), appIDKey, app.ID)
+					ctx = context.WithValue(ctx, OrgRoleKey, role)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -182,6 +263,44 @@ func RequireOwnership(st store.Store, logger *slog.Logger) func(http.Handler) ht
 	}
 }
 
+// RequireOwnershipIfExists returns a middleware like RequireOwnership for
+// routes that create the app when it does not exist, such as PUT
+// /v1/apps/{appID}: a request for an app the user has no app by that ID or
+// name continues without a resolved app ID. Scoped API keys cannot create
+// apps.
+func RequireOwnershipIfExists(st store.Store, logger *slog.Logger) func(http.Handler) http.Handler {
+	requireOwnership := RequireOwnership(st, logger)
+	return func(next http.Handler) http.Handler {
+		owned := requireOwnership(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := GetUserID(r.Context())
+			appIDOrName := chi.URLParam(r, "appID")
+			if userID == "" || appIDOrName == "" {
+				owned.ServeHTTP(w, r)
+				return
+			}
+			if _, err := st.Apps().Get(r.Context(), appIDOrName); err == nil {
+				owned.ServeHTTP(w, r)
+				return
+			}
+			if _, err := st.Apps().GetByName(r.Context(), userID, appIDOrName); err == nil {
+				owned.ServeHTTP(w, r)
+				return
+			}
+			if GetAPIKeyScopes(r.Context()) != nil {
+				writeInsufficientScope(w, "This API key is restricted to app scopes and cannot create apps; use an unrestricted key", "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isReadOnly reports whether an HTTP method only reads state.
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 func writeInternalError(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusInternalServerError)
@@ -70,6 +70,16 @@ func (m *mockAppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.A
 	return result, nil
 }
 
+func (m *mockAppStore) ListAll(ctx context.Context) ([]*models.App, error) {
+	var result []*models.App
+	for _, app := range m.apps {
+		if app.DeletedAt == nil {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
 // mockStore implements store.Store for testing
 type mockStore struct {
 	appStore *mockAppStore
@@ -117,6 +127,186 @@ func (m *mockStore) GitHubAccounts() store.GitHubAccountStore {
 	return nil
 }
 
+func (m *mockStore) Announcements() store.AnnouncementStore {
+	return nil
+}
+
+func (m *mockStore) Releases() store.ReleaseStore {
+	return nil
+}
+
+func (m *mockStore) Stats() store.StatsStore {
+	return nil
+}
+
+func (m *mockStore) EgressViolations() store.EgressViolationStore {
+	return nil
+}
+
+func (m *mockStore) DeployFreezes() store.DeployFreezeStore {
+	return nil
+}
+
+func (m *mockStore) OnCall() store.OnCallStore {
+	return nil
+}
+
+func (m *mockStore) APICatalog() store.APICatalogStore {
+	return nil
+}
+
+func (m *mockStore) SCIM() store.SCIMStore {
+	return nil
+}
+
+func (m *mockStore) APIKeys() store.APIKeyStore {
+	return nil
+}
+
+func (m *mockStore) Notifications() store.NotificationStore {
+	return nil
+}
+
+func (m *mockStore) Promotions() store.PromotionStore {
+	return nil
+}
+
+func (m *mockStore) SSH() store.SSHStore {
+	return nil
+}
+
+func (m *mockStore) BuildWorkers() store.BuildWorkerStore {
+	return nil
+}
+
+func (m *mockStore) ServiceTemplates() store.ServiceTemplateStore {
+	return nil
+}
+
+func (m *mockStore) BuildLogs() store.BuildLogStore {
+	return nil
+}
+
+func (m *mockStore) BuildSnapshots() store.BuildSnapshotStore {
+	return nil
+}
+
+func (m *mockStore) AppHooks() store.AppHookStore {
+	return nil
+}
+
+func (m *mockStore) CDN() store.CDNStore {
+	return nil
+}
+
+func (m *mockStore) BuildAttestations() store.BuildAttestationStore {
+	return nil
+}
+
+func (m *mockStore) CronRuns() store.CronRunStore {
+	return nil
+}
+
+func (m *mockStore) Metrics() store.MetricStore {
+	return nil
+}
+
+func (m *mockStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	return nil
+}
+
+func (m *mockStore) Audit() store.AuditStore {
+	return nil
+}
+
+func (m *mockStore) OrgSecrets() store.OrgSecretStore {
+	return nil
+}
+
+func (m *mockStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	return nil
+}
+
+func (m *mockStore) SmokeTests() store.SmokeTestStore {
+	return nil
+}
+
+func (m *mockStore) LoadTests() store.LoadTestStore {
+	return nil
+}
+
+func (m *mockStore) Archives() store.ArchiveStore {
+	return nil
+}
+
+func (m *mockStore) Operations() store.OperationStore {
+	return nil
+}
+
+func (m *mockStore) DebugSessions() store.DebugSessionStore {
+	return nil
+}
+
+func (m *mockStore) AuthSessions() store.AuthSessionStore {
+	return nil
+}
+
+func (m *mockStore) RateLimits() store.RateLimitStore {
+	return nil
+}
+
+func (m *mockStore) Consistency() store.ConsistencyStore {
+	return nil
+}
+
+func (m *mockStore) DBHealth() store.DBHealthStore {
+	return nil
+}
+
+func (m *mockStore) AppManifests() store.AppManifestStore {
+	return nil
+}
+
+func (m *mockStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return nil
+}
+
+func (m *mockStore) ScaleEvents() store.ScaleEventStore {
+	return nil
+}
+
+func (m *mockStore) Environments() store.EnvironmentStore {
+	return nil
+}
+
+func (m *mockStore) Federation() store.FederationStore {
+	return nil
+}
+
+func (m *mockStore) EvidenceExports() store.EvidenceExportStore {
+	return nil
+}
+
+func (m *mockStore) APIUsage() store.APIUsageStore {
+	return nil
+}
+
+func (m *mockStore) Events() store.EventStore {
+	return nil
+}
+
+func (m *mockStore) Webhooks() store.WebhookStore {
+	return nil
+}
+
+func (m *mockStore) AppTemplates() store.AppTemplateStore {
+	return nil
+}
+
+func (m *mockStore) Services() store.ServiceStore {
+	return nil
+}
+
 func (m *mockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	return fn(m)
 }
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ConsistencyHeader carries consistency tokens. Responses to successful
+// writes set it; reads sending it back see at least the state after the
+// writes.
+const ConsistencyHeader = "X-Consistency-Token"
+
+// Consistency lets reads be served by a read replica without clients seeing
+// the state from before their own writes. Successful writes return a
+// consistency token, and reads sending one wait for the replica to catch up
+// with it or are served by the primary. Reads without a token may be served
+// by a lagging replica. Without a replica tokens are empty and not sent.
+func Consistency(st store.Store, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				ctx := store.ReadAfter(r.Context(), r.Header.Get(ConsistencyHeader))
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			tokens := st.Consistency()
+			if tokens == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&consistencyWriter{ResponseWriter: w, r: r, tokens: tokens, logger: logger}, r)
+		})
+	}
+}
+
+// consistencyWriter sets the consistency token of a write's response before
+// its headers are sent, once the handler has committed its changes.
+type consistencyWriter struct {
+	http.ResponseWriter
+	r      *http.Request
+	tokens store.ConsistencyStore
+	logger *slog.Logger
+	sent   bool
+}
+
+func (w *consistencyWriter) setToken(status int) {
+	if w.sent {
+		return
+	}
+	w.sent = true
+	if status >= http.StatusBadRequest {
+		return
+	}
+	token, err := w.tokens.Token(w.r.Context())
+	if err != nil {
+		w.logger.Warn("failed to get consistency token", "error", err)
+		return
+	}
+	if token != "" {
+		w.Header().Set(ConsistencyHeader, token)
+	}
+}
+
+func (w *consistencyWriter) WriteHeader(status int) {
+	w.setToken(status)
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *consistencyWriter) Write(b []byte) (int, error) {
+	w.setToken(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *consistencyWriter) Flush() {
+	w.setToken(http.StatusOK)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *consistencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// tokenStore issues a fixed consistency token.
+type tokenStore struct {
+	store.Store
+	token string
+	err   error
+}
+
+func (s *tokenStore) Consistency() store.ConsistencyStore { return s }
+
+func (s *tokenStore) Token(ctx context.Context) (string, error) { return s.token, s.err }
+
+func TestConsistency(t *testing.T) {
+	serve := func(st store.Store, method string, header string, status int) (*httptest.ResponseRecorder, string, bool) {
+		var readAfter string
+		var marked bool
+		h := Consistency(st, slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readAfter, marked = store.ReadAfterToken(r.Context())
+			w.WriteHeader(status)
+		}))
+		r := httptest.NewRequest(method, "/v1/apps", nil)
+		if header != "" {
+			r.Header.Set(ConsistencyHeader, header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w, readAfter, marked
+	}
+
+	t.Run("write returns a token", func(t *testing.T) {
+		w, _, marked := serve(&tokenStore{token: "0/16B3748"}, http.MethodPost, "", http.StatusCreated)
+		if got := w.Header().Get(ConsistencyHeader); got != "0/16B3748" {
+			t.Errorf("token = %q", got)
+		}
+		if marked {
+			t.Error("write allowed to read from a replica")
+		}
+	})
+
+	t.Run("failed write returns no token", func(t *testing.T) {
+		w, _, _ := serve(&tokenStore{token: "0/16B3748"}, http.MethodPost, "", http.StatusBadRequest)
+		if got := w.Header().Get(ConsistencyHeader); got != "" {
+			t.Errorf("token = %q", got)
+		}
+	})
+
+	t.Run("no token without a replica", func(t *testing.T) {
+		w, _, _ := serve(&tokenStore{}, http.MethodDelete, "", http.StatusNoContent)
+		if _, ok := w.Header()[ConsistencyHeader]; ok {
+			t.Error("empty token sent")
+		}
+		w, _, _ = serve(&tokenStore{err: errors.New("connection refused")}, http.MethodDelete, "", http.StatusNoContent)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("status %d", w.Code)
+		}
+	})
+
+	t.Run("read honors the token", func(t *testing.T) {
+		_, readAfter, marked := serve(&tokenStore{}, http.MethodGet, "0/16B3748", http.StatusOK)
+		if !marked || readAfter != "0/16B3748" {
+			t.Errorf("ReadAfterToken = %q, %v", readAfter, marked)
+		}
+	})
+}
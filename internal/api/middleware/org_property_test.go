@@ -92,6 +92,13 @@ func (m *mockOrgStore) IsMember(ctx context.Context, orgID, userID string) (bool
 	return false, nil
 }
 
+func (m *mockOrgStore) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	if m.memberships[orgID] != nil && m.memberships[orgID][userID] {
+		return models.RoleMember, nil
+	}
+	return "", nil
+}
+
 func (m *mockOrgStore) GetDefault(ctx context.Context) (*models.Organization, error) {
 	return nil, nil
 }
@@ -111,6 +118,14 @@ func (m *mockOrgStore) ListMembers(ctx context.Context, orgID string) ([]*models
 	return nil, nil
 }
 
+func (m *mockOrgStore) ListAll(ctx context.Context) ([]*models.Organization, error) {
+	var result []*models.Organization
+	for _, org := range m.orgs {
+		result = append(result, org)
+	}
+	return result, nil
+}
+
 // orgTestStore implements store.Store for organization testing
 type orgTestStore struct {
 	appStore *mockAppStore
@@ -137,6 +152,51 @@ func (m *orgTestStore) GitHubAccounts() store.GitHubAccountStore
 func (m *orgTestStore) Settings() store.SettingsStore                                { return nil }
 func (m *orgTestStore) Domains() store.DomainStore                                   { return nil }
 func (m *orgTestStore) Invitations() store.InvitationStore                           { return nil }
+func (m *orgTestStore) Announcements() store.AnnouncementStore                       { return nil }
+func (m *orgTestStore) Releases() store.ReleaseStore                                 { return nil }
+func (m *orgTestStore) Stats() store.StatsStore                                      { return nil }
+func (m *orgTestStore) EgressViolations() store.EgressViolationStore                 { return nil }
+func (m *orgTestStore) DeployFreezes() store.DeployFreezeStore                       { return nil }
+func (m *orgTestStore) OnCall() store.OnCallStore                                    { return nil }
+func (m *orgTestStore) APICatalog() store.APICatalogStore                            { return nil }
+func (m *orgTestStore) SCIM() store.SCIMStore                                        { return nil }
+func (m *orgTestStore) APIKeys() store.APIKeyStore                                   { return nil }
+func (m *orgTestStore) Notifications() store.NotificationStore                       { return nil }
+func (m *orgTestStore) Promotions() store.PromotionStore                             { return nil }
+func (m *orgTestStore) SSH() store.SSHStore                                          { return nil }
+func (m *orgTestStore) BuildWorkers() store.BuildWorkerStore                         { return nil }
+func (m *orgTestStore) ServiceTemplates() store.ServiceTemplateStore                 { return nil }
+func (m *orgTestStore) BuildLogs() store.BuildLogStore                               { return nil }
+func (m *orgTestStore) BuildSnapshots() store.BuildSnapshotStore                     { return nil }
+func (m *orgTestStore) AppHooks() store.AppHookStore                                 { return nil }
+func (m *orgTestStore) CDN() store.CDNStore                                          { return nil }
+func (m *orgTestStore) BuildAttestations() store.BuildAttestationStore               { return nil }
+func (m *orgTestStore) CronRuns() store.CronRunStore                                 { return nil }
+func (m *orgTestStore) Metrics() store.MetricStore                                   { return nil }
+func (m *orgTestStore) AdmissionPolicies() store.AdmissionPolicyStore                { return nil }
+func (m *orgTestStore) Audit() store.AuditStore                                      { return nil }
+func (m *orgTestStore) OrgSecrets() store.OrgSecretStore                             { return nil }
+func (m *orgTestStore) NodeJoinTokens() store.NodeJoinTokenStore                     { return nil }
+func (m *orgTestStore) SmokeTests() store.SmokeTestStore                             { return nil }
+func (m *orgTestStore) LoadTests() store.LoadTestStore                               { return nil }
+func (m *orgTestStore) Archives() store.ArchiveStore                                 { return nil }
+func (m *orgTestStore) Operations() store.OperationStore                             { return nil }
+func (m *orgTestStore) DebugSessions() store.DebugSessionStore                       { return nil }
+func (m *orgTestStore) AuthSessions() store.AuthSessionStore                         { return nil }
+func (m *orgTestStore) RateLimits() store.RateLimitStore                             { return nil }
+func (m *orgTestStore) Consistency() store.ConsistencyStore                          { return nil }
+func (m *orgTestStore) DBHealth() store.DBHealthStore                                { return nil }
+func (m *orgTestStore) AppManifests() store.AppManifestStore                         { return nil }
+func (m *orgTestStore) IdempotencyKeys() store.IdempotencyKeyStore                   { return nil }
+func (m *orgTestStore) ScaleEvents() store.ScaleEventStore                           { return nil }
+func (m *orgTestStore) Environments() store.EnvironmentStore                         { return nil }
+func (m *orgTestStore) Federation() store.FederationStore                            { return nil }
+func (m *orgTestStore) EvidenceExports() store.EvidenceExportStore                   { return nil }
+func (m *orgTestStore) APIUsage() store.APIUsageStore                                { return nil }
+func (m *orgTestStore) Events() store.EventStore                                     { return nil }
+func (m *orgTestStore) Webhooks() store.WebhookStore                                 { return nil }
+func (m *orgTestStore) AppTemplates() store.AppTemplateStore                         { return nil }
+func (m *orgTestStore) Services() store.ServiceStore                                 { return nil }
 func (m *orgTestStore) WithTx(ctx context.Context, fn func(store.Store) error) error { return fn(m) }
 func (m *orgTestStore) Close() error                                                 { return nil }
 func (m *orgTestStore) Ping(ctx context.Context) error                               { return nil }
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// roleOrgStore returns fixed org roles keyed by user ID.
+type roleOrgStore struct {
+	store.OrgStore
+	roles map[string]models.Role
+}
+
+func (s *roleOrgStore) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	return s.roles[userID], nil
+}
+
+// roleUserStore returns users with fixed instance roles.
+type roleUserStore struct {
+	store.UserStore
+	roles map[string]store.Role
+}
+
+func (s *roleUserStore) GetByID(ctx context.Context, id string) (*store.User, error) {
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, nil
+	}
+	return &store.User{ID: id, Role: role}, nil
+}
+
+// roleAppStore serves a single app in org-1.
+type roleAppStore struct {
+	store.AppStore
+}
+
+func (s *roleAppStore) Get(ctx context.Context, id string) (*models.App, error) {
+	return &models.App{ID: id, OrgID: "org-1", OwnerID: "creator"}, nil
+}
+
+type roleTestStore struct {
+	store.Store
+	orgs  *roleOrgStore
+	users *roleUserStore
+}
+
+func (s *roleTestStore) Orgs() store.OrgStore   { return s.orgs }
+func (s *roleTestStore) Users() store.UserStore { return s.users }
+func (s *roleTestStore) Apps() store.AppStore   { return &roleAppStore{} }
+
+func newRoleTestStore() *roleTestStore {
+	return &roleTestStore{
+		orgs: &roleOrgStore{roles: map[string]models.Role{
+			"owner":  models.RoleOwner,
+			"admin":  models.RoleAdmin,
+			"dev":    models.RoleDeveloper,
+			"viewer": models.RoleViewer,
+		}},
+		users: &roleUserStore{roles: map[string]store.Role{
+			"dev":      store.RoleMember,
+			"viewer":   store.RoleMember,
+			"platform": store.RoleOwner,
+		}},
+	}
+}
+
+func TestRequireOrgRole(t *testing.T) {
+	st := newRoleTestStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var gotRole models.Role
+	r := chi.NewRouter()
+	r.Route("/orgs/{orgID}", func(r chi.Router) {
+		r.Use(RequireOrgRole(st, logger, models.RoleViewer))
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			gotRole = GetOrgRole(r.Context())
+		}
+		r.Get("/", handler)
+		r.With(RequireOrgRole(st, logger, models.RoleAdmin)).Patch("/", handler)
+	})
+
+	tests := []struct {
+		user     string
+		method   string
+		wantCode int
+		wantRole models.Role
+	}{
+		{"viewer", http.MethodGet, http.StatusOK, models.RoleViewer},
+		{"viewer", http.MethodPatch, http.StatusForbidden, ""},
+		{"dev", http.MethodPatch, http.StatusForbidden, ""},
+		{"admin", http.MethodPatch, http.StatusOK, models.RoleAdmin},
+		{"owner", http.MethodPatch, http.StatusOK, models.RoleOwner},
+		{"platform", http.MethodPatch, http.StatusOK, models.RoleOwner},
+		{"stranger", http.MethodGet, http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		gotRole = ""
+		ctx := context.WithValue(context.Background(), UserIDKey, tt.user)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tt.method, "/orgs/org-1/", nil).WithContext(ctx))
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s %s = %d, want %d", tt.user, tt.method, rec.Code, tt.wantCode)
+		}
+		if gotRole != tt.wantRole {
+			t.Errorf("%s %s role = %q, want %q", tt.user, tt.method, gotRole, tt.wantRole)
+		}
+	}
+}
+
+func TestRequireOwnershipViewerReadOnly(t *testing.T) {
+	st := newRoleTestStore()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r := chi.NewRouter()
+	r.Route("/apps/{appID}", func(r chi.Router) {
+		r.Use(RequireOwnership(st, logger))
+		r.HandleFunc("/*", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	serve := func(user, method string) int {
+		ctx := context.WithValue(context.Background(), UserIDKey, user)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, "/apps/app-1/deploy", nil).WithContext(ctx))
+		return rec.Code
+	}
+
+	if code := serve("viewer", http.MethodGet); code != http.StatusOK {
+		t.Errorf("viewer GET = %d, want 200", code)
+	}
+	if code := serve("viewer", http.MethodPost); code != http.StatusForbidden {
+		t.Errorf("viewer POST = %d, want 403", code)
+	}
+	if code := serve("dev", http.MethodPost); code != http.StatusOK {
+		t.Errorf("developer POST = %d, want 200", code)
+	}
+}
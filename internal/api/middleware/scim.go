@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/scim"
+)
+
+// SCIMOrgIDKey is the context key for the org authenticated by a SCIM token.
+const SCIMOrgIDKey contextKey = "scim_org_id"
+
+// GetSCIMOrgID extracts the SCIM org ID from the request context.
+func GetSCIMOrgID(ctx context.Context) string {
+	if v := ctx.Value(SCIMOrgIDKey); v != nil {
+		return v.(string)
+	}
+	return ""
+}
+
+// SCIMAuth returns a middleware that authenticates identity providers by
+// their org SCIM bearer token. Errors use the SCIM error format.
+func SCIMAuth(service *scim.Service, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := auth.ExtractBearerToken(r.Header.Get("Authorization"))
+			if token == "" {
+				scim.WriteError(w, &scim.Error{Status: http.StatusUnauthorized, Detail: "Missing bearer token"})
+				return
+			}
+
+			orgID, err := service.Authenticate(r.Context(), token)
+			if err != nil {
+				logger.Error("failed to authenticate scim token", "error", err)
+				scim.WriteError(w, &scim.Error{Status: http.StatusInternalServerError, Detail: "Failed to authenticate"})
+				return
+			}
+			if orgID == "" {
+				scim.WriteError(w, &scim.Error{Status: http.StatusUnauthorized, Detail: "Invalid SCIM token"})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), SCIMOrgIDKey, orgID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// APIKeyScopesKey is the context key for the scopes of a scoped API key.
+const APIKeyScopesKey contextKey = "api_key_scopes"
+
+// GetAPIKeyScopes returns the scopes of the API key used for the request, or
+// nil if the request was made with a session token or an unrestricted key.
+func GetAPIKeyScopes(ctx context.Context) []models.APIKeyScope {
+	if v := ctx.Value(APIKeyScopesKey); v != nil {
+		return v.([]models.APIKeyScope)
+	}
+	return nil
+}
+
+// LimitScopedKeys returns a middleware that rejects scoped API keys outside
+// app routes. App routes check the key's scopes with RequireAppScope. Workloads,
+// which are scoped to their own app, may also exchange their identity tokens.
+func LimitScopedKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAPIKeyScopes(r.Context()) == nil || r.URL.Path == "/v1/auth/validate" || r.URL.Path == "/v1/workload-identity/token" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 4); len(parts) >= 3 && parts[0] == "v1" && parts[1] == "apps" && parts[2] != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeInsufficientScope(w, "This API key is restricted to app scopes and cannot access "+r.URL.Path+"; use an unrestricted key", "")
+	})
+}
+
+// RequireAppScope returns a middleware that checks a scoped API key grants the
+// action a request performs on the app. It must run after RequireOwnership.
+func RequireAppScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scopes := GetAPIKeyScopes(r.Context())
+		appID := GetResolvedAppID(r.Context())
+		if scopes == nil || appID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		action := AppScopeAction(r.Method, appSubPath(r.URL.Path))
+		if !models.AllowsApp(scopes, appID, action) {
+			required := models.ScopeName(appID, action)
+			writeInsufficientScope(w, "API key is missing the "+string(action)+" scope for app "+chi.URLParam(r, "appID")+" (requires "+required+")", required)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AppScopeAction returns the scope action needed for a request to an app
+// route. subPath is the path below /v1/apps/{appID}, e.g. "/services/web/deploy".
+//
+// Reads need read, except the interactive terminal and tunnels to a service's
+// ports which need write. Deploys, externally built artifact submissions,
+// promotion approvals and service start, stop, reload and retry need deploy.
+// Build previews only read. Everything else changes the app and needs write.
+func AppScopeAction(method, subPath string) models.APIKeyAction {
+	subPath = strings.TrimSuffix(subPath, "/")
+	parts := strings.Split(strings.TrimPrefix(subPath, "/"), "/")
+
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		if strings.HasSuffix(subPath, "/terminal/ws") || strings.HasSuffix(subPath, "/tunnel/ws") {
+			return models.APIKeyActionWrite
+		}
+		return models.APIKeyActionRead
+	case http.MethodPost:
+		if subPath == "/deploy" {
+			return models.APIKeyActionDeploy
+		}
+		if len(parts) == 3 && parts[0] == "services" {
+			switch parts[2] {
+			case "deploy", "builds", "stop", "start", "reload", "retry":
+				return models.APIKeyActionDeploy
+			case "preview":
+				return models.APIKeyActionRead
+			}
+		}
+		if len(parts) == 3 && parts[0] == "promotions" && parts[2] == "approve" {
+			return models.APIKeyActionDeploy
+		}
+	}
+	return models.APIKeyActionWrite
+}
+
+// appSubPath returns the part of an app route path after /v1/apps/{appID}.
+func appSubPath(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 4)
+	if len(parts) < 4 {
+		return ""
+	}
+	return "/" + parts[3]
+}
+
+func writeInsufficientScope(w http.ResponseWriter, message, requiredScope string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	body := `{"code":"insufficient_scope","message":"` + escapeJSON(message) + `"`
+	if requiredScope != "" {
+		body += `,"details":{"required_scope":"` + escapeJSON(requiredScope) + `"}`
+	}
+	w.Write([]byte(body + "}"))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestAppScopeAction(t *testing.T) {
+	tests := []struct {
+		method  string
+		subPath string
+		want    models.APIKeyAction
+	}{
+		{http.MethodGet, "", models.APIKeyActionRead},
+		{http.MethodGet, "/services/web/logs", models.APIKeyActionRead},
+		{http.MethodGet, "/services/web/terminal/ws", models.APIKeyActionWrite},
+		{http.MethodGet, "/services/db/tunnel/ws", models.APIKeyActionWrite},
+		{http.MethodPost, "/services/web/dev", models.APIKeyActionWrite},
+		{http.MethodPost, "/deploy", models.APIKeyActionDeploy},
+		{http.MethodPost, "/services/web/deploy", models.APIKeyActionDeploy},
+		{http.MethodPost, "/services/web/stop", models.APIKeyActionDeploy},
+		{http.MethodPost, "/services/web/builds", models.APIKeyActionDeploy},
+		{http.MethodPost, "/promotions/abc/approve", models.APIKeyActionDeploy},
+		{http.MethodPost, "/promotions/abc/reject", models.APIKeyActionWrite},
+		{http.MethodPost, "/services/web/preview", models.APIKeyActionRead},
+		{http.MethodPost, "/services/", models.APIKeyActionWrite},
+		{http.MethodPost, "/secrets", models.APIKeyActionWrite},
+		{http.MethodPatch, "/services/web", models.APIKeyActionWrite},
+		{http.MethodDelete, "", models.APIKeyActionWrite},
+	}
+	for _, tt := range tests {
+		if got := AppScopeAction(tt.method, tt.subPath); got != tt.want {
+			t.Errorf("AppScopeAction(%s, %q) = %s, want %s", tt.method, tt.subPath, got, tt.want)
+		}
+	}
+}
+
+func TestRequireAppScope(t *testing.T) {
+	scopes := []models.APIKeyScope{{AppID: "app-1", Actions: []models.APIKeyAction{models.APIKeyActionDeploy}}}
+	handler := RequireAppScope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method, path, appID string, scopes []models.APIKeyScope) *httptest.ResponseRecorder {
+		ctx := context.WithValue(context.Background(), appIDKey, appID)
+		if scopes != nil {
+			ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil).WithContext(ctx))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/v1/apps/app-1/services/web/deploy", "app-1", scopes); rec.Code != http.StatusOK {
+		t.Errorf("scoped deploy = %d, want 200", rec.Code)
+	}
+	if rec := serve(http.MethodDelete, "/v1/apps/app-1", "app-1", nil); rec.Code != http.StatusOK {
+		t.Errorf("unscoped delete = %d, want 200", rec.Code)
+	}
+
+	rec := serve(http.MethodPost, "/v1/apps/app-2/deploy", "app-2", scopes)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("deploy to other app = %d, want 403", rec.Code)
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Details struct {
+			RequiredScope string `json:"required_scope"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid error body: %v", err)
+	}
+	if body.Code != "insufficient_scope" || body.Details.RequiredScope != "app:app-2:deploy" {
+		t.Errorf("error body = %s", rec.Body.String())
+	}
+
+	if rec := serve(http.MethodGet, "/v1/apps/app-1/services", "app-1", scopes); rec.Code != http.StatusForbidden {
+		t.Errorf("read with deploy-only key = %d, want 403", rec.Code)
+	}
+}
+
+func TestLimitScopedKeys(t *testing.T) {
+	scopes := []models.APIKeyScope{{AppID: models.APIKeyAllApps, Actions: []models.APIKeyAction{models.APIKeyActionRead}}}
+	handler := LimitScopedKeys(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path   string
+		scoped bool
+		want   int
+	}{
+		{"/v1/apps/app-1/services", true, http.StatusOK},
+		{"/v1/auth/validate", true, http.StatusOK},
+		{"/v1/apps", true, http.StatusForbidden},
+		{"/v1/user/api-keys", true, http.StatusForbidden},
+		{"/v1/user/api-keys", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.scoped {
+			ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil).WithContext(ctx))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s (scoped=%v) = %d, want %d", tt.path, tt.scoped, rec.Code, tt.want)
+		}
+	}
+}
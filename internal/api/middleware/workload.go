@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// WorkloadClaimsKey is the context key for the claims of the workload
+// identity token used to authenticate.
+const WorkloadClaimsKey contextKey = "workload_claims"
+
+// GetWorkloadClaims returns the claims of the workload identity token used for
+// the request, or nil if the request was not made by a workload.
+func GetWorkloadClaims(ctx context.Context) *identity.Claims {
+	if v := ctx.Value(WorkloadClaimsKey); v != nil {
+		return v.(*identity.Claims)
+	}
+	return nil
+}
+
+// SetWorkloadIdentity lets workloads authenticate with identity tokens issued
+// for the control plane. A workload acts as its app's owner, restricted to
+// reading its own app.
+func (m *AuthMiddleware) SetWorkloadIdentity(issuer *identity.Issuer, st store.Store) {
+	m.workloads = issuer
+	m.store = st
+}
+
+// authenticateWorkload verifies a workload identity token and returns its
+// claims and the user the workload acts as.
+func (m *AuthMiddleware) authenticateWorkload(ctx context.Context, token string) (*identity.Claims, string, error) {
+	claims, err := m.workloads.Verify(token, m.workloads.Issuer())
+	if err != nil {
+		return nil, "", err
+	}
+	app, err := m.store.Apps().Get(ctx, claims.AppID)
+	if err != nil || app == nil {
+		return nil, "", identity.ErrInvalidToken
+	}
+	return claims, app.OwnerID, nil
+}
+
+// workloadScopes are the scopes of a workload: read access to its own app.
+func workloadScopes(claims *identity.Claims) []models.APIKeyScope {
+	return []models.APIKeyScope{{AppID: claims.AppID, Actions: []models.APIKeyAction{models.APIKeyActionRead}}}
+}
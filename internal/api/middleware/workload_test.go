@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestAuthenticateWorkloadToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	key, err := identity.LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := identity.NewIssuer(identity.Config{Issuer: "https://cp.example.com", TokenTTL: time.Minute}, key)
+
+	authSvc := auth.NewService(&auth.Config{JWTSecret: []byte("test-secret-key-at-least-32-chars!!"), TokenExpiry: time.Hour}, nil, logger)
+	m := NewAuthMiddleware(authSvc, "", logger)
+	m.SetWorkloadIdentity(issuer, newRoleTestStore())
+
+	var userID string
+	var scopes []models.APIKeyScope
+	var workload *identity.Claims
+	handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID = GetUserID(r.Context())
+		scopes = GetAPIKeyScopes(r.Context())
+		workload = GetWorkloadClaims(r.Context())
+	}))
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/apps/app-1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	app := &models.App{ID: "app-1"}
+	deployment := &models.Deployment{ID: "dep-1", ServiceName: "web", NodeID: "node-1"}
+	token, _, err := issuer.Issue(app, deployment, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := serve(token); code != http.StatusOK {
+		t.Fatalf("workload token = %d, want 200", code)
+	}
+	if userID != "creator" || workload == nil || workload.Service != "web" {
+		t.Errorf("user = %q, workload = %+v; want the app owner acting as web", userID, workload)
+	}
+	if !models.AllowsApp(scopes, "app-1", models.APIKeyActionRead) || models.AllowsApp(scopes, "app-1", models.APIKeyActionDeploy) || models.AllowsApp(scopes, "app-2", models.APIKeyActionRead) {
+		t.Errorf("scopes = %+v, want read on app-1 only", scopes)
+	}
+
+	// Tokens for other services are not accepted by the API
+	other, _, err := issuer.Issue(app, deployment, []string{"billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := serve(other); code != http.StatusUnauthorized {
+		t.Errorf("token for another audience = %d, want 401", code)
+	}
+}
@@ -0,0 +1,121 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errQueueTimeout is returned when a request waited too long for its turn.
+var errQueueTimeout = errors.New("timed out waiting for a free request slot")
+
+// fairQueue limits the requests served at once. When all places are taken,
+// waiting requests queue per tenant and freed places go to the tenants in
+// turn, so a tenant with a hundred queued requests delays another tenant's
+// request by at most one of its own.
+type fairQueue struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	waiting  map[string][]chan struct{} // Waiting requests of each tenant, oldest first
+	order    []string                   // Tenants with waiting requests, next to be served first
+}
+
+func newFairQueue(limit int) *fairQueue {
+	return &fairQueue{
+		limit:   limit,
+		waiting: make(map[string][]chan struct{}),
+	}
+}
+
+// acquire waits for a place for a request of the tenant and returns the
+// function that frees it. It gives up after timeout, if positive, or when ctx
+// is done.
+func (q *fairQueue) acquire(ctx context.Context, tenant string, timeout time.Duration) (func(), error) {
+	q.mu.Lock()
+	if q.inFlight < q.limit && len(q.order) == 0 {
+		q.inFlight++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	ready := make(chan struct{})
+	if _, ok := q.waiting[tenant]; !ok {
+		q.order = append(q.order, tenant)
+	}
+	q.waiting[tenant] = append(q.waiting[tenant], ready)
+	q.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var err error
+	select {
+	case <-ready:
+		return q.release, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-expired:
+		err = errQueueTimeout
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.remove(tenant, ready) {
+		// Handed a place just as we gave up; pass it on
+		q.releaseLocked()
+	}
+	return nil, err
+}
+
+// release frees a place, handing it to the next tenant's oldest request.
+func (q *fairQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked()
+}
+
+func (q *fairQueue) releaseLocked() {
+	if len(q.order) == 0 {
+		q.inFlight--
+		return
+	}
+	tenant := q.order[0]
+	waiters := q.waiting[tenant]
+	close(waiters[0])
+	if len(waiters) == 1 {
+		delete(q.waiting, tenant)
+		q.order = q.order[1:]
+	} else {
+		q.waiting[tenant] = waiters[1:]
+		q.order = append(q.order[1:], tenant)
+	}
+}
+
+// remove takes a waiting request out of the queue, reporting whether it was
+// still waiting.
+func (q *fairQueue) remove(tenant string, ready chan struct{}) bool {
+	waiters := q.waiting[tenant]
+	for i, w := range waiters {
+		if w != ready {
+			continue
+		}
+		if len(waiters) > 1 {
+			q.waiting[tenant] = append(waiters[:i:i], waiters[i+1:]...)
+			return true
+		}
+		delete(q.waiting, tenant)
+		for j, t := range q.order {
+			if t == tenant {
+				q.order = append(q.order[:j:j], q.order[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+// Package quota enforces per-organization API request quotas and shares the
+// API server fairly between organizations, so that one tenant's heavy polling
+// or misbehaving CI cannot starve the others.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+const (
+	// tenantTTL is how long a request's organization is remembered.
+	tenantTTL = time.Minute
+	// sweepInterval is how often idle buckets and tenants are forgotten.
+	sweepInterval = time.Minute
+)
+
+// Config holds the quota settings. A zero PerMinute disables quotas and a
+// zero MaxInFlight disables fair queuing.
+type Config struct {
+	PerMinute    int            // Requests an organization may make per minute
+	MaxInFlight  int            // Requests served at once
+	QueueTimeout time.Duration  // How long a request waits for its turn
+	Overrides    map[string]int // Quotas of specific orgs by ID or slug; 0 for none
+}
+
+// ParseOverrides parses quotas of specific organizations given as
+// "org=perMinute,org=perMinute", where org is an ID or slug.
+func ParseOverrides(s string) (map[string]int, error) {
+	overrides := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		org, limit, ok := strings.Cut(pair, "=")
+		org = strings.TrimSpace(org)
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || org == "" || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid quota override %q: expected org=perMinute", pair)
+		}
+		overrides[org] = n
+	}
+	return overrides, nil
+}
+
+// Usage is an organization's use of its quota, as reported in the
+// X-RateLimit-* response headers.
+type Usage struct {
+	Limit      int           // Requests allowed per minute
+	Remaining  int           // Requests that can be made right now
+	Reset      time.Duration // Until the quota is fully replenished
+	RetryAfter time.Duration // Until the next request is allowed, when over quota
+}
+
+// tenant is the organization a request is accounted to.
+type tenant struct {
+	ID   string
+	Slug string
+}
+
+type tenantEntry struct {
+	tenant  tenant
+	expires time.Time
+}
+
+// bucket is a token bucket holding up to a minute's worth of requests.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter accounts API requests to organizations.
+type Limiter struct {
+	config Config
+	store  store.Store
+	logger *slog.Logger
+	queue  *fairQueue
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	tenants   map[string]tenantEntry
+	lastSweep time.Time
+}
+
+// New creates a limiter.
+func New(cfg Config, st store.Store, logger *slog.Logger) *Limiter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &Limiter{
+		config:  cfg,
+		store:   st,
+		logger:  logger,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+		tenants: make(map[string]tenantEntry),
+	}
+	if cfg.MaxInFlight > 0 {
+		l.queue = newFairQueue(cfg.MaxInFlight)
+	}
+	return l
+}
+
+// Middleware charges each authenticated request to its organization's quota,
+// rejecting it with 429 once the quota is used up, and then waits for its
+// turn among the requests served at once. It must run after authentication.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.GetUserID(r.Context())
+		if userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		t := l.tenant(r, userID)
+
+		if limit := l.limitFor(t); limit > 0 {
+			usage, ok := l.take(t.ID, limit)
+			setUsageHeaders(w.Header(), usage)
+			if !ok {
+				l.logger.Warn("API quota exceeded",
+					"org_id", t.ID,
+					"user_id", userID,
+					"limit", usage.Limit,
+				)
+				writeError(w, http.StatusTooManyRequests, usage.RetryAfter, "quota_exceeded",
+					fmt.Sprintf("API quota of %d requests per minute exceeded for this organization", usage.Limit))
+				return
+			}
+		}
+
+		if l.queue == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		release, err := l.queue.acquire(r.Context(), t.ID, l.config.QueueTimeout)
+		if err != nil {
+			l.logger.Warn("API request not served in time", "org_id", t.ID, "error", err)
+			writeError(w, http.StatusServiceUnavailable, time.Second, "server_busy",
+				"The server is busy, please retry")
+			return
+		}
+		s := &slot{release: release}
+		defer s.free()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), slotKey{}, s)))
+	})
+}
+
+// slot is a request's place among the requests served at once.
+type slot struct {
+	once    sync.Once
+	release func()
+}
+
+func (s *slot) free() { s.once.Do(s.release) }
+
+type slotKey struct{}
+
+// Release gives up the request's place among the requests served at once.
+// Long-lived streams call it so they do not hold a place while open.
+func Release(ctx context.Context) {
+	if s, ok := ctx.Value(slotKey{}).(*slot); ok {
+		s.free()
+	}
+}
+
+// limitFor returns the per-minute quota of a tenant, 0 for none.
+func (l *Limiter) limitFor(t tenant) int {
+	if n, ok := l.config.Overrides[t.ID]; ok {
+		return n
+	}
+	if n, ok := l.config.Overrides[t.Slug]; ok && t.Slug != "" {
+		return n
+	}
+	return l.config.PerMinute
+}
+
+// take charges one request to a tenant's bucket.
+func (l *Limiter) take(id string, limit int) (Usage, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweepLocked()
+
+	b := l.refill(id, limit)
+	ok := b.tokens >= 1
+	if ok {
+		b.tokens--
+	}
+	return b.usage(limit), ok
+}
+
+// refill returns a tenant's bucket with the tokens earned since it was last
+// used added.
+func (l *Limiter) refill(id string, limit int) *bucket {
+	now := l.now()
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &bucket{tokens: float64(limit), updated: now}
+		l.buckets[id] = b
+	}
+	rate := float64(limit) / 60
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	return b
+}
+
+// usage reports the use of a bucket holding up to limit tokens.
+func (b *bucket) usage(limit int) Usage {
+	rate := float64(limit) / 60
+	usage := Usage{
+		Limit:     limit,
+		Remaining: int(b.tokens),
+		Reset:     time.Duration((float64(limit) - b.tokens) / rate * float64(time.Second)),
+	}
+	if b.tokens < 1 {
+		usage.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	return usage
+}
+
+// sweepLocked forgets buckets that have refilled and expired tenants.
+func (l *Limiter) sweepLocked() {
+	now := l.now()
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for id, b := range l.buckets {
+		if now.Sub(b.updated) > time.Minute {
+			delete(l.buckets, id)
+		}
+	}
+	for key, e := range l.tenants {
+		if now.After(e.expires) {
+			delete(l.tenants, key)
+		}
+	}
+}
+
+// tenant returns the organization a request is accounted to: the one it
+// targets with the X-Org-ID or X-Org-Slug header or current_org cookie, as
+// OrgContext resolves it, or else the user's default organization. Requests
+// whose organization cannot be resolved, e.g. naming an organization the user
+// is not a member of, are accounted to the user, so they cannot use up another
+// organization's quota.
+func (l *Limiter) tenant(r *http.Request, userID string) tenant {
+	orgID := r.Header.Get("X-Org-ID")
+	orgSlug := r.Header.Get("X-Org-Slug")
+	if orgID == "" && orgSlug == "" {
+		if cookie, err := r.Cookie("current_org"); err == nil {
+			orgSlug = cookie.Value
+		}
+	}
+
+	key := userID + "\x00" + orgID + "\x00" + orgSlug
+	l.mu.Lock()
+	e, ok := l.tenants[key]
+	l.mu.Unlock()
+	if ok && l.now().Before(e.expires) {
+		return e.tenant
+	}
+
+	t := tenant{ID: "user:" + userID}
+	if org, err := l.resolveOrg(r.Context(), userID, orgID, orgSlug); err != nil {
+		l.logger.Debug("could not resolve organization of request", "user_id", userID, "error", err)
+	} else {
+		t = tenant{ID: org.ID, Slug: org.Slug}
+	}
+
+	l.mu.Lock()
+	l.tenants[key] = tenantEntry{tenant: t, expires: l.now().Add(tenantTTL)}
+	l.mu.Unlock()
+	return t
+}
+
+func (l *Limiter) resolveOrg(ctx context.Context, userID, orgID, orgSlug string) (*models.Organization, error) {
+	var org *models.Organization
+	var err error
+	switch {
+	case orgID != "":
+		org, err = l.store.Orgs().Get(ctx, orgID)
+	case orgSlug != "":
+		org, err = l.store.Orgs().GetBySlug(ctx, orgSlug)
+	default:
+		org, err = l.store.Orgs().GetDefaultForUser(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, fmt.Errorf("user has no organization")
+	}
+	if orgID != "" || orgSlug != "" {
+		member, err := l.store.Orgs().IsMember(ctx, org.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, fmt.Errorf("user is not a member of organization %s", org.ID)
+		}
+	}
+	return org, nil
+}
+
+// setUsageHeaders reports quota usage in the X-RateLimit-* headers.
+func setUsageHeaders(h http.Header, usage Usage) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(usage.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(usage.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(seconds(usage.Reset)))
+}
+
+// writeError writes an error in the API's error format with a Retry-After
+// header.
+func writeError(w http.ResponseWriter, status int, retryAfter time.Duration, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds(retryAfter), 1)))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
+
+// seconds rounds a duration up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
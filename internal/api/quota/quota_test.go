@@ -0,0 +1,209 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type quotaOrgs struct {
+	store.OrgStore
+	orgs    map[string]*models.Organization
+	members map[string]string // user ID to org ID
+}
+
+func (s *quotaOrgs) Get(ctx context.Context, id string) (*models.Organization, error) {
+	if org, ok := s.orgs[id]; ok {
+		return org, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *quotaOrgs) GetBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	for _, org := range s.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *quotaOrgs) GetDefaultForUser(ctx context.Context, userID string) (*models.Organization, error) {
+	return s.orgs[s.members[userID]], nil
+}
+
+func (s *quotaOrgs) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
+	return s.members[userID] == orgID, nil
+}
+
+type quotaStore struct {
+	store.Store
+	orgs *quotaOrgs
+}
+
+func (s *quotaStore) Orgs() store.OrgStore { return s.orgs }
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	st := &quotaStore{orgs: &quotaOrgs{
+		orgs: map[string]*models.Organization{
+			"org-a": {ID: "org-a", Slug: "acme"},
+			"org-b": {ID: "org-b", Slug: "ci"},
+		},
+		members: map[string]string{"alice": "org-a", "bob": "org-b"},
+	}}
+	l := New(cfg, st, nil)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func request(userID string, headers ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/v1/apps", nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	return r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID))
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestMiddlewareEnforcesQuota(t *testing.T) {
+	l, now := newTestLimiter(Config{PerMinute: 2, Overrides: map[string]int{"ci": 0}})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, remaining := range []string{"1", "0"} {
+		rec := serve(h, request("alice"))
+		if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != remaining {
+			t.Fatalf("status = %d, remaining = %q; want 200 with %s remaining",
+				rec.Code, rec.Header().Get("X-RateLimit-Remaining"), remaining)
+		}
+	}
+	rec := serve(h, request("alice"))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("over quota: status = %d, Retry-After = %q; want 429 after 30s", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	*now = now.Add(30 * time.Second)
+	if rec := serve(h, request("alice")); rec.Code != http.StatusOK {
+		t.Errorf("after refill: status = %d, want 200", rec.Code)
+	}
+
+	// Overridden organizations have no quota
+	for i := 0; i < 5; i++ {
+		rec := serve(h, request("bob"))
+		if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("overridden org: status = %d, limit header = %q", rec.Code, rec.Header().Get("X-RateLimit-Limit"))
+		}
+	}
+}
+
+func TestMiddlewareChargesForeignOrgToUser(t *testing.T) {
+	l, _ := newTestLimiter(Config{PerMinute: 1})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// bob names alice's organization, which he is not a member of
+	if rec := serve(h, request("bob", "X-Org-Slug", "acme")); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec := serve(h, request("alice", "X-Org-ID", "org-a")); rec.Code != http.StatusOK {
+		t.Errorf("alice's quota was used by another user's request: status = %d", rec.Code)
+	}
+}
+
+func TestFairQueueServesTenantsInTurn(t *testing.T) {
+	q := newFairQueue(1)
+	release, err := q.acquire(context.Background(), "busy", 0)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var served []string
+	var wg sync.WaitGroup
+	enqueue := func(tenant string) {
+		waiting := func() int {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			n := 0
+			for _, w := range q.waiting {
+				n += len(w)
+			}
+			return n
+		}
+		before := waiting()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := q.acquire(context.Background(), tenant, 0)
+			if err != nil {
+				t.Errorf("acquire(%s) error = %v", tenant, err)
+				return
+			}
+			mu.Lock()
+			served = append(served, tenant)
+			mu.Unlock()
+			done()
+		}()
+		for waiting() == before {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, tenant := range []string{"busy", "busy", "busy", "quiet"} {
+		enqueue(tenant)
+	}
+	release()
+	wg.Wait()
+
+	want := []string{"busy", "quiet", "busy", "busy"}
+	for i := range want {
+		if i >= len(served) || served[i] != want[i] {
+			t.Fatalf("served %v, want %v", served, want)
+		}
+	}
+}
+
+func TestFairQueueTimesOut(t *testing.T) {
+	q := newFairQueue(1)
+	release, _ := q.acquire(context.Background(), "a", 0)
+	if _, err := q.acquire(context.Background(), "b", 10*time.Millisecond); !errors.Is(err, errQueueTimeout) {
+		t.Fatalf("acquire() error = %v, want timeout", err)
+	}
+	release()
+	if q.inFlight != 0 || len(q.order) != 0 {
+		t.Errorf("queue not empty after timeout: inFlight = %d, order = %v", q.inFlight, q.order)
+	}
+}
+
+func TestReleaseFreesPlaceOfStream(t *testing.T) {
+	l, _ := newTestLimiter(Config{MaxInFlight: 1, QueueTimeout: time.Second})
+	streaming := make(chan struct{})
+	stop := make(chan struct{})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/stream" {
+			Release(r.Context())
+			close(streaming)
+			<-stop
+		}
+	}))
+
+	go serve(h, httptest.NewRequest(http.MethodGet, "/v1/stream", nil).WithContext(
+		context.WithValue(context.Background(), middleware.UserIDKey, "alice")))
+	<-streaming
+	defer close(stop)
+
+	if rec := serve(h, request("bob")); rec.Code != http.StatusOK {
+		t.Errorf("status = %d while a stream is open, want 200", rec.Code)
+	}
+}
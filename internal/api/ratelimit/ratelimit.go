@@ -0,0 +1,293 @@
+// Package ratelimit protects the API from request floods and password
+// guessing. Requests are charged to token buckets keyed by client IP, by the
+// user or API key making them, or by the account a login names. Unlike the
+// per-organization quotas of package quota, limits apply before a request is
+// authenticated.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/telemetry"
+)
+
+const (
+	// sweepInterval is how often buckets that have refilled are forgotten.
+	sweepInterval = time.Minute
+)
+
+// Rule is a limit of requests per minute. Up to Burst requests may be made
+// at once; Burst defaults to PerMinute. A zero PerMinute disables the rule.
+type Rule struct {
+	Name      string
+	PerMinute int
+	Burst     int
+}
+
+func (r Rule) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.PerMinute
+}
+
+// Result is the outcome of charging a request to a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int           // Requests allowed per minute
+	Remaining  int           // Requests that can be made right now
+	RetryAfter time.Duration // Until the next request is allowed, when limited
+}
+
+// Store holds token buckets. A memory store limits the requests of one API
+// server; a Postgres store shares buckets between every API server.
+type Store interface {
+	// Take refills a bucket holding up to burst tokens at rate tokens per
+	// second and removes a token if it holds one. It returns the tokens left
+	// and whether a token was removed.
+	Take(ctx context.Context, key string, rate float64, burst int) (float64, bool, error)
+	// Peek returns the tokens a bucket would hold after refilling, without
+	// changing it.
+	Peek(ctx context.Context, key string, rate float64, burst int) (float64, error)
+	// DeleteIdle removes buckets not used since before.
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Limiter charges requests to the buckets of a store.
+type Limiter struct {
+	store  Store
+	logger *slog.Logger
+	now    func() time.Time
+
+	limited *telemetry.CounterVec
+}
+
+// New creates a limiter keeping buckets in st. Metrics of limited requests
+// are registered with metrics, if not nil.
+func New(st Store, metrics *telemetry.Registry, logger *slog.Logger) *Limiter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &Limiter{store: st, logger: logger, now: time.Now}
+	if metrics != nil {
+		l.limited = metrics.Counter("narvana_rate_limited_requests_total",
+			"Requests rejected by a rate limit, by rule.", "rule")
+	}
+	return l
+}
+
+// Allow charges a request to key's bucket of rule. The limiter fails open:
+// when the store cannot be reached the request is allowed.
+func (l *Limiter) Allow(ctx context.Context, rule Rule, key string) Result {
+	if rule.PerMinute <= 0 {
+		return Result{Allowed: true}
+	}
+	rate := float64(rule.PerMinute) / 60
+	tokens, ok, err := l.store.Take(ctx, rule.Name+":"+key, rate, rule.burst())
+	if err != nil {
+		l.logger.Error("rate limit store failed, allowing request", "rule", rule.Name, "error", err)
+		return Result{Allowed: true, Limit: rule.PerMinute, Remaining: rule.burst()}
+	}
+
+	res := Result{Allowed: ok, Limit: rule.PerMinute, Remaining: int(math.Max(tokens, 0))}
+	if !ok {
+		res.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+		if l.limited != nil {
+			l.limited.With(rule.Name).Inc()
+		}
+	}
+	return res
+}
+
+// Check reports whether a request would be allowed by key's bucket of rule,
+// without charging it. Like Allow, it fails open.
+func (l *Limiter) Check(ctx context.Context, rule Rule, key string) Result {
+	if rule.PerMinute <= 0 {
+		return Result{Allowed: true}
+	}
+	rate := float64(rule.PerMinute) / 60
+	tokens, err := l.store.Peek(ctx, rule.Name+":"+key, rate, rule.burst())
+	if err != nil {
+		l.logger.Error("rate limit store failed, allowing request", "rule", rule.Name, "error", err)
+		return Result{Allowed: true, Limit: rule.PerMinute, Remaining: rule.burst()}
+	}
+
+	res := Result{Allowed: tokens >= 1, Limit: rule.PerMinute, Remaining: int(math.Max(tokens, 0))}
+	if !res.Allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+		if l.limited != nil {
+			l.limited.With(rule.Name).Inc()
+		}
+	}
+	return res
+}
+
+// ByIP limits requests by client IP, as resolved by the realip middleware.
+func (l *Limiter) ByIP(rule Rule) func(http.Handler) http.Handler {
+	return l.middleware(rule, func(r *http.Request) string {
+		return ClientIP(r)
+	})
+}
+
+// ByCaller limits requests by the API key or, for other tokens, the user
+// making them. It must run after authentication; unauthenticated requests
+// are not limited.
+func (l *Limiter) ByCaller(rule Rule) func(http.Handler) http.Handler {
+	return l.middleware(rule, func(r *http.Request) string {
+		if keyID := middleware.GetAPIKeyID(r.Context()); keyID != "" {
+			return "key:" + keyID
+		}
+		if userID := middleware.GetUserID(r.Context()); userID != "" {
+			return "user:" + userID
+		}
+		return ""
+	})
+}
+
+func (l *Limiter) middleware(rule Rule, keyOf func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if rule.PerMinute <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyOf(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if res := l.Allow(r.Context(), rule, key); !res.Allowed {
+				l.logger.Warn("rate limit exceeded", "rule", rule.Name, "key", key, "path", r.URL.Path)
+				WriteLimited(w, res, "Too many requests, please retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Run forgets idle buckets until ctx is done.
+func (l *Limiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Every bucket refills within an hour of its last use
+			if _, err := l.store.DeleteIdle(ctx, l.now().Add(-time.Hour)); err != nil {
+				l.logger.Error("failed to delete idle rate limit buckets", "error", err)
+			}
+		}
+	}
+}
+
+// WriteLimited writes a 429 response in the API's error format with a
+// Retry-After header.
+func WriteLimited(w http.ResponseWriter, res Result, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(res.RetryAfter.Seconds())), 1)))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    "rate_limited",
+		"message": message,
+	})
+}
+
+// ClientIP returns the IP address of a request's client.
+func ClientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// bucket is a token bucket of a memory store.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryStore keeps buckets in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take refills a bucket and removes a token if it holds one.
+func (s *MemoryStore) Take(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return b.tokens, false, nil
+	}
+	b.tokens--
+	return b.tokens, true, nil
+}
+
+// Peek returns the tokens a bucket would hold after refilling.
+func (s *MemoryStore) Peek(ctx context.Context, key string, rate float64, burst int) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		return float64(burst), nil
+	}
+	return math.Min(float64(burst), b.tokens+s.now().Sub(b.updated).Seconds()*rate), nil
+}
+
+// DeleteIdle removes buckets not used since before.
+func (s *MemoryStore) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for key, b := range s.buckets {
+		if b.updated.Before(before) {
+			delete(s.buckets, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// NewStore returns the store named by kind: "memory", or "postgres" using
+// buckets, which must be set then.
+func NewStore(kind string, buckets Store) (Store, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		if buckets == nil {
+			return nil, fmt.Errorf("postgres rate limit store is not available")
+		}
+		return buckets, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", kind)
+	}
+}
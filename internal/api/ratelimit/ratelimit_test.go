@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, ok, _ := s.Take(ctx, "k", 1, 3); !ok {
+			t.Fatalf("request %d limited within the burst", i+1)
+		}
+	}
+	if tokens, ok, _ := s.Take(ctx, "k", 1, 3); ok || tokens >= 1 {
+		t.Fatalf("request past the burst allowed (tokens %g)", tokens)
+	}
+	if _, ok, _ := s.Take(ctx, "other", 1, 3); !ok {
+		t.Fatal("another key limited")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok, _ := s.Take(ctx, "k", 1, 3); !ok {
+		t.Fatal("request limited after the bucket refilled a token")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n, _ := s.DeleteIdle(ctx, now.Add(-time.Hour)); n != 2 {
+		t.Errorf("DeleteIdle removed %d buckets, want 2", n)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, float64, int) (float64, bool, error) {
+	return 0, false, errors.New("database unavailable")
+}
+
+func (failingStore) Peek(context.Context, string, float64, int) (float64, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func (failingStore) DeleteIdle(context.Context, time.Time) (int64, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	l := New(NewMemoryStore(), nil, nil)
+	rule := Rule{Name: "login", PerMinute: 2}
+
+	for i := 0; i < 2; i++ {
+		if res := l.Allow(ctx, rule, "alice@example.com"); !res.Allowed {
+			t.Fatalf("attempt %d limited", i+1)
+		}
+	}
+	res := l.Allow(ctx, rule, "alice@example.com")
+	if res.Allowed {
+		t.Fatal("third attempt allowed")
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > 30*time.Second {
+		t.Errorf("RetryAfter = %s, want up to 30s at 2 per minute", res.RetryAfter)
+	}
+	if !l.Allow(ctx, Rule{Name: "other", PerMinute: 2}, "alice@example.com").Allowed {
+		t.Error("rules share buckets")
+	}
+	if !l.Allow(ctx, Rule{Name: "off"}, "alice@example.com").Allowed {
+		t.Error("disabled rule limited a request")
+	}
+
+	if !New(failingStore{}, nil, nil).Allow(ctx, rule, "alice@example.com").Allowed {
+		t.Error("store failure limited a request")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	l := New(NewMemoryStore(), nil, nil)
+	rule := Rule{Name: "login", PerMinute: 1}
+
+	for i := 0; i < 3; i++ {
+		if !l.Check(ctx, rule, "alice@example.com").Allowed {
+			t.Fatalf("check %d limited an unused bucket", i+1)
+		}
+	}
+	l.Allow(ctx, rule, "alice@example.com")
+	if res := l.Check(ctx, rule, "alice@example.com"); res.Allowed || res.RetryAfter <= 0 {
+		t.Errorf("Check = %+v after the bucket was used up, want limited", res)
+	}
+
+	if !New(failingStore{}, nil, nil).Check(ctx, rule, "alice@example.com").Allowed {
+		t.Error("store failure limited a request")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(NewMemoryStore(), nil, nil)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("by IP", func(t *testing.T) {
+		h := l.ByIP(Rule{Name: "ip", PerMinute: 1})(ok)
+		serve := func(addr string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+			r.RemoteAddr = addr
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			return w
+		}
+		if w := serve("192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("first request: status %d", w.Code)
+		}
+		w := serve("192.0.2.1:5678")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("second request: status %d, want 429", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("429 without Retry-After")
+		}
+		if w := serve("192.0.2.2:1234"); w.Code != http.StatusOK {
+			t.Errorf("other client: status %d", w.Code)
+		}
+	})
+
+	t.Run("by caller", func(t *testing.T) {
+		h := l.ByCaller(Rule{Name: "caller", PerMinute: 1})(ok)
+		serve := func(userID, keyID string) int {
+			r := httptest.NewRequest(http.MethodGet, "/v1/apps", nil)
+			ctx := r.Context()
+			if userID != "" {
+				ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+			}
+			if keyID != "" {
+				ctx = context.WithValue(ctx, middleware.APIKeyIDKey, keyID)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return w.Code
+		}
+		if code := serve("user-1", ""); code != http.StatusOK {
+			t.Fatalf("first request: status %d", code)
+		}
+		if code := serve("user-1", ""); code != http.StatusTooManyRequests {
+			t.Errorf("second request: status %d, want 429", code)
+		}
+		// An API key has its own bucket
+		if code := serve("user-1", "key-1"); code != http.StatusOK {
+			t.Errorf("API key request: status %d", code)
+		}
+		if code := serve("", ""); code != http.StatusOK {
+			t.Errorf("unauthenticated request: status %d", code)
+		}
+	})
+}
@@ -12,13 +12,39 @@ import (
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/narvanalabs/control-plane/internal/api/handlers"
 	"github.com/narvanalabs/control-plane/internal/api/health"
+	"github.com/narvanalabs/control-plane/internal/api/idempotency"
 	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/api/quota"
+	"github.com/narvanalabs/control-plane/internal/api/ratelimit"
+	"github.com/narvanalabs/control-plane/internal/api/streams"
+	"github.com/narvanalabs/control-plane/internal/api/versioning"
+	"github.com/narvanalabs/control-plane/internal/archive"
+	"github.com/narvanalabs/control-plane/internal/audit"
 	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/catalog"
+	"github.com/narvanalabs/control-plane/internal/cdn"
 	"github.com/narvanalabs/control-plane/internal/cleanup"
+	"github.com/narvanalabs/control-plane/internal/dbhealth"
+	"github.com/narvanalabs/control-plane/internal/doctor"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/evidence"
+	"github.com/narvanalabs/control-plane/internal/federation"
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/loglevel"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/operations"
 	"github.com/narvanalabs/control-plane/internal/podman"
+	"github.com/narvanalabs/control-plane/internal/promotion"
+	"github.com/narvanalabs/control-plane/internal/provenance"
 	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/realip"
+	"github.com/narvanalabs/control-plane/internal/scim"
 	"github.com/narvanalabs/control-plane/internal/secrets"
 	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/internal/telemetry"
+	"github.com/narvanalabs/control-plane/internal/tracing"
 	"github.com/narvanalabs/control-plane/internal/updater"
 	"github.com/narvanalabs/control-plane/pkg/config"
 )
@@ -38,6 +64,21 @@ type Server struct {
 	config        *config.Config
 	logger        *slog.Logger
 	healthChecker *health.Checker
+	streams       *streams.Registry
+	quotas        *quota.Limiter
+	rateLimits    *ratelimit.Limiter
+	hooks         *hooks.Trigger
+	workloads     *identity.Issuer
+	archiver      *archive.Archiver
+	doctor        *doctor.Doctor
+	dbHealth      *dbhealth.Maintainer
+	logLevels     *loglevel.Controller
+	idempotency   *idempotency.Keys
+	operations    *operations.Manager
+	events        *events.Hub
+	evidence      *evidence.Compiler
+	versions      *versioning.Negotiator
+	telemetry     *telemetry.Registry
 }
 
 // NewServer creates a new API server with the given dependencies.
@@ -54,9 +95,31 @@ func NewServer(cfg *config.Config, st store.Store, q queue.Queue, authSvc *auth.
 		logger: logger,
 	}
 
+	// Queue app lifecycle hooks fired by API changes
+	s.hooks = hooks.NewTrigger(st, logger)
+
 	// Initialize health checker
 	s.healthChecker = health.NewChecker(st, Version)
 
+	// Track SSE and WebSocket streams so they can be limited and reaped
+	s.streams = streams.NewRegistry(streams.Config{
+		MaxPerUser:  cfg.Streams.MaxPerUser,
+		MaxPerApp:   cfg.Streams.MaxPerApp,
+		IdleTimeout: cfg.Streams.IdleTimeout,
+	}, logger)
+
+	// Limit each organization's requests and share the server fairly
+	overrides, err := quota.ParseOverrides(cfg.Quota.Overrides)
+	if err != nil {
+		logger.Error("ignoring API quota overrides", "error", err)
+	}
+	s.quotas = quota.New(quota.Config{
+		PerMinute:    cfg.Quota.PerMinute,
+		MaxInFlight:  cfg.Quota.MaxInFlight,
+		QueueTimeout: cfg.Quota.QueueTimeout,
+		Overrides:    overrides,
+	}, st, logger)
+
 	// Initialize SOPS service if configured
 	if cfg.SOPS.AgePublicKey != "" || cfg.SOPS.AgePrivateKey != "" {
 		sopsService, err := secrets.NewSOPSService(&secrets.Config{
@@ -73,24 +136,129 @@ func NewServer(cfg *config.Config, st store.Store, q queue.Queue, authSvc *auth.
 		logger.Warn("SOPS not configured, secrets will be stored without encryption")
 	}
 
+	// Issue identity tokens to workloads if enabled
+	if cfg.WorkloadIdentity.Enabled {
+		key, err := identity.LoadOrCreateSigningKey(cfg.WorkloadIdentity.KeyPath)
+		if err != nil {
+			logger.Error("failed to load workload identity signing key", "error", err)
+		} else {
+			s.workloads = identity.NewIssuer(identity.Config{
+				Issuer:   cfg.WorkloadIdentity.Issuer,
+				TokenTTL: cfg.WorkloadIdentity.TokenTTL,
+			}, key)
+			logger.Info("workload identity enabled", "issuer", s.workloads.Issuer())
+		}
+	}
+
+	// Move the history of old deployments to object storage if configured
+	if objects := archiveObjectStore(cfg.Archive); objects != nil {
+		archiveCfg := archive.DefaultConfig()
+		archiveCfg.After = cfg.Archive.After
+		archiveCfg.KeepPerService = cfg.Archive.KeepPerService
+		archiveCfg.Interval = cfg.Archive.Interval
+		s.archiver = archive.NewArchiver(st, objects, archiveCfg, logger)
+	}
+
+	// Check the installation for misconfigurations on request
+	s.doctor = doctor.New(st, cfg, logger)
+
+	// Clean up orphaned rows, vacuum bloated tables and track table growth
+	dbHealthCfg := dbhealth.DefaultConfig()
+	dbHealthCfg.Interval = cfg.DBMaintenance.Interval
+	dbHealthCfg.OrphanGrace = cfg.DBMaintenance.OrphanGrace
+	dbHealthCfg.Vacuum = cfg.DBMaintenance.Vacuum
+	s.dbHealth = dbhealth.NewMaintainer(st, dbHealthCfg, logger)
+
+	// Change components' log levels at runtime, reverting after a while
+	logLevelCfg := loglevel.DefaultConfig()
+	logLevelCfg.RevertAfter = cfg.Logging.LevelRevertAfter
+	s.logLevels = loglevel.NewController(st, logLevelCfg, logger)
+
+	// Replay the responses of retried requests sent with an Idempotency-Key
+	idempotencyCfg := idempotency.DefaultConfig()
+	idempotencyCfg.TTL = cfg.Idempotency.KeyTTL
+	s.idempotency = idempotency.NewKeys(st, idempotencyCfg, logger)
+
+	// Run long operations in the background and record their progress
+	s.operations = operations.NewManager(st, operations.DefaultConfig(), logger)
+
+	// Stream changes of apps, services, deployments and builds
+	s.events = events.NewHub(st, events.DefaultConfig(), logger)
+
+	// Sign evidence packages exported for compliance audits
+	if cfg.Audit.EvidenceKeyPath != "" {
+		signer, err := provenance.LoadOrCreateSigner(cfg.Audit.EvidenceKeyPath)
+		if err != nil {
+			logger.Error("failed to load evidence signing key, evidence exports are disabled", "error", err)
+		} else {
+			s.evidence = evidence.NewCompiler(st, signer)
+		}
+	}
+
+	// Negotiate API versions, shim older ones and count usage of deprecated
+	// routes
+	s.versions = versioning.NewNegotiator(st, versioning.DefaultPolicy(), versioning.DefaultConfig(), logger)
+
+	// Expose metrics about the control plane itself
+	s.telemetry = telemetry.NewRegistry()
+	telemetry.CollectDeployments(s.telemetry, st)
+	telemetry.CollectBuildQueue(s.telemetry, st)
+
+	// Limit requests by client IP, caller and login account. Buckets kept in
+	// Postgres are shared between API servers.
+	var buckets ratelimit.Store
+	if cfg.RateLimit.Store == "postgres" {
+		buckets = st.RateLimits()
+	}
+	limitStore, err := ratelimit.NewStore(cfg.RateLimit.Store, buckets)
+	if err != nil {
+		logger.Error("falling back to in-memory rate limits", "error", err)
+		limitStore = ratelimit.NewMemoryStore()
+	}
+	s.rateLimits = ratelimit.New(limitStore, s.telemetry, logger)
+
 	s.setupRouter()
 	return s
 }
 
+// archiveObjectStore returns the configured object store of archives, or nil
+// if archiving is not configured.
+func archiveObjectStore(cfg config.ArchiveConfig) archive.ObjectStore {
+	switch {
+	case cfg.S3Bucket != "":
+		return archive.NewS3Store(archive.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		}, nil)
+	case cfg.Dir != "":
+		return archive.NewDirStore(cfg.Dir)
+	default:
+		return nil
+	}
+}
+
 // setupRouter configures the router with middleware and routes.
 func (s *Server) setupRouter() {
 	r := chi.NewRouter()
 
 	// Global middleware
 	r.Use(chimiddleware.RequestID)
-	r.Use(chimiddleware.RealIP)
+	r.Use(realip.New(s.config.TrustedProxies).Middleware)
+	r.Use(tracing.Middleware)
 	r.Use(middleware.RequestLogger(s.logger))
 	r.Use(middleware.Recovery(s.logger))
 	r.Use(chimiddleware.Timeout(60 * time.Second))
+	r.Use(telemetry.NewHTTPMetrics(s.telemetry).Middleware)
 
 	// Health check endpoint (no auth required)
 	r.Get("/health", s.healthChecker.Handler())
 
+	// Prometheus metrics, protected by the metrics token if one is set
+	r.Method(http.MethodGet, "/metrics", s.telemetry.Handler(s.config.MetricsToken, s.logger))
+
 	// API Documentation endpoints (no auth required)
 	// Requirements: 9.1
 	docsHandler := handlers.NewDocsHandler(s.logger)
@@ -99,18 +267,21 @@ func (s *Server) setupRouter() {
 
 	// Auth routes (no auth required)
 	authHandler := handlers.NewAuthHandler(s.store, s.auth, s.logger)
+	authHandler.SetLoginLimit(s.rateLimits, ratelimit.Rule{Name: "login", PerMinute: s.config.RateLimit.LoginPerMinute})
 	invitationsPublicHandler := handlers.NewInvitationsHandler(s.store, s.auth, s.logger)
+	limitAuth := s.rateLimits.ByIP(ratelimit.Rule{Name: "auth", PerMinute: s.config.RateLimit.AuthPerMinute})
 	r.Route("/auth", func(r chi.Router) {
 		r.Get("/setup", authHandler.SetupCheck)
 		r.Get("/can-register", authHandler.CanRegister)
-		r.Post("/register", authHandler.Register)
-		r.Post("/login", authHandler.Login)
+		r.With(limitAuth).Post("/register", authHandler.Register)
+		r.With(limitAuth).Post("/login", authHandler.Login)
+		r.With(limitAuth).Post("/refresh", authHandler.Refresh)
 		r.Post("/device/start", authHandler.DeviceAuthStart)
 		r.Get("/device/poll", authHandler.DeviceAuthPoll)
 		r.Post("/device/approve", authHandler.DeviceAuthApprove)
 		// Invitation acceptance (public)
 		r.Get("/invite/{token}", invitationsPublicHandler.GetByToken)
-		r.Post("/invite/accept", invitationsPublicHandler.Accept)
+		r.With(limitAuth).Post("/invite/accept", invitationsPublicHandler.Accept)
 	})
 
 	// GitHub callbacks (public)
@@ -120,11 +291,58 @@ func (s *Server) setupRouter() {
 	r.Get("/github/post-install", githubHandler.PostInstallation)
 	r.Post("/github/webhook", githubHandler.Webhook)
 
-	// API v1 routes
+	// SCIM 2.0 provisioning (org SCIM token auth)
+	scimService := scim.NewService(s.store, s.logger)
+	scimHandler := handlers.NewSCIMHandler(s.store, scimService, s.logger)
+	r.Route("/scim/v2", func(r chi.Router) {
+		r.Use(middleware.SCIMAuth(scimService, s.logger))
+		r.Get("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+		r.Get("/ResourceTypes", scimHandler.ResourceTypes)
+		r.Get("/Users", scimHandler.ListUsers)
+		r.Post("/Users", scimHandler.CreateUser)
+		r.Get("/Users/{id}", scimHandler.GetUser)
+		r.Put("/Users/{id}", scimHandler.ReplaceUser)
+		r.Patch("/Users/{id}", scimHandler.PatchUser)
+		r.Delete("/Users/{id}", scimHandler.DeleteUser)
+		r.Get("/Groups", scimHandler.ListGroups)
+		r.Post("/Groups", scimHandler.CreateGroup)
+		r.Get("/Groups/{id}", scimHandler.GetGroup)
+		r.Put("/Groups/{id}", scimHandler.ReplaceGroup)
+		r.Patch("/Groups/{id}", scimHandler.PatchGroup)
+		r.Delete("/Groups/{id}", scimHandler.DeleteGroup)
+	})
+
+	// Workload identity discovery (public)
+	var workloadHandler *handlers.WorkloadIdentityHandler
+	if s.workloads != nil {
+		workloadHandler = handlers.NewWorkloadIdentityHandler(s.store, s.workloads, s.logger)
+		r.Get(identity.JWKSPath, workloadHandler.JWKS)
+		r.Get("/.well-known/openid-configuration", workloadHandler.Discovery)
+	}
+
+	// Node join (join token auth). Registered outside the v1 group, whose
+	// middleware requires a user or agent token.
+	nodeJoinHandler := handlers.NewNodeJoinHandler(s.store, s.auth, s.logger)
+	r.Post("/v1/nodes/join", nodeJoinHandler.Join)
+
+	// API v1 routes. The audit recorder resolves routes against the root router.
+	auditRecorder := audit.NewRecorder(s.store, r, s.logger)
+	negotiateVersion := s.versions.Middleware(r)
 	r.Route("/v1", func(r chi.Router) {
 		// Auth middleware for all v1 routes
 		authMiddleware := middleware.NewAuthMiddleware(s.auth, s.config.APIKeyHeader, s.logger)
+		if s.workloads != nil {
+			authMiddleware.SetWorkloadIdentity(s.workloads, s.store)
+		}
+		r.Use(s.rateLimits.ByIP(ratelimit.Rule{Name: "ip", PerMinute: s.config.RateLimit.IPPerMinute}))
 		r.Use(authMiddleware.Authenticate)
+		r.Use(negotiateVersion)
+		r.Use(middleware.LimitScopedKeys)
+		r.Use(s.rateLimits.ByCaller(ratelimit.Rule{Name: "caller", PerMinute: s.config.RateLimit.UserPerMinute}))
+		r.Use(s.quotas.Middleware)
+		r.Use(auditRecorder.Middleware)
+		r.Use(middleware.Consistency(s.store, s.logger))
+		r.Use(s.idempotency.Middleware)
 
 		// Auth validation endpoint (returns OK if token is valid - middleware already validated it)
 		r.Get("/auth/validate", func(w http.ResponseWriter, r *http.Request) {
@@ -134,6 +352,12 @@ func (s *Server) setupRouter() {
 			w.Write([]byte(`{"status":"ok","user_id":"` + userID + `"}`))
 		})
 
+		// Sign-in sessions of the user: logout and remote revocation
+		r.Post("/auth/logout", authHandler.Logout)
+		r.Get("/auth/sessions", authHandler.ListSessions)
+		r.Delete("/auth/sessions", authHandler.RevokeOtherSessions)
+		r.Delete("/auth/sessions/{sessionID}", authHandler.RevokeSession)
+
 		// Platform configuration endpoint
 		// Requirements: 2.1
 		configHandler := handlers.NewConfigHandler(s.store, s.logger)
@@ -147,6 +371,24 @@ func (s *Server) setupRouter() {
 			r.Use(middleware.OrgContext(s.store, s.logger))
 			r.Get("/stats", statsHandler.GetDashboardStats)
 		})
+		r.Route("/health", func(r chi.Router) {
+			r.Use(middleware.OrgContext(s.store, s.logger))
+			r.Get("/summary", statsHandler.GetHealthSummary)
+		})
+
+		// Searchable catalog of the APIs the org's services publish
+		catalogHandler := handlers.NewCatalogHandler(s.store, catalog.NewCatalog(s.store, nil, catalog.DefaultConfig(), s.logger), s.logger)
+		r.Route("/catalog", func(r chi.Router) {
+			r.Use(middleware.OrgContext(s.store, s.logger))
+			r.Get("/", catalogHandler.Search)
+		})
+
+		// Command palette registry: the commands the user can run on a page
+		commandHandler := handlers.NewCommandHandler(s.store, s.logger)
+		r.Route("/commands", func(r chi.Router) {
+			r.Use(middleware.OrgContext(s.store, s.logger))
+			r.Get("/", commandHandler.List)
+		})
 
 		// Detection endpoint
 		detectHandler := handlers.NewDetectHandler(s.logger)
@@ -155,11 +397,13 @@ func (s *Server) setupRouter() {
 		// App routes
 		podmanClient := podman.NewClient(s.config.Worker.PodmanSocket, s.logger)
 		appHandler := handlers.NewAppHandler(s.store, s.logger)
+		promotionsHandler := handlers.NewPromotionsHandler(s.store, promotion.NewEvaluator(s.store, promotion.DefaultConfig(), s.logger), s.logger)
 		r.Route("/apps", func(r chi.Router) {
 			r.Post("/", appHandler.Create)
 			r.Get("/", appHandler.List)
 			r.Route("/{appID}", func(r chi.Router) {
 				r.Use(middleware.RequireOwnership(s.store, s.logger))
+				r.Use(middleware.RequireAppScope)
 				r.Get("/", appHandler.Get)
 				r.Patch("/", appHandler.Update)
 				r.Delete("/", appHandler.Delete)
@@ -168,16 +412,43 @@ func (s *Server) setupRouter() {
 				deploymentHandler := handlers.NewDeploymentHandler(s.store, s.queue, s.logger)
 				r.Post("/deploy", deploymentHandler.Create)
 				r.Get("/deployments", deploymentHandler.List)
+				commitRangeHandler := handlers.NewCommitRangeHandler(s.store, s.logger)
+				r.Get("/deployments/compare", commitRangeHandler.Compare)
+
+				// Right-sizing of service resources from observed usage
+				r.Get("/recommendations", deploymentHandler.Recommendations)
+				r.Post("/recommendations/{serviceName}/apply", deploymentHandler.ApplyRecommendation)
+
+				// Release notes linked to deployments
+				releasesHandler := handlers.NewReleasesHandler(s.store, s.logger)
+				r.Route("/releases", func(r chi.Router) {
+					r.Get("/", releasesHandler.List)
+					r.Post("/", releasesHandler.Create)
+					r.Get("/{deploymentID}", releasesHandler.Get)
+				})
+
+				// Signed evidence packages for compliance audits
+				evidenceHandler := handlers.NewEvidenceHandler(s.store, s.evidence, s.operations, s.logger)
+				r.Route("/evidence-exports", func(r chi.Router) {
+					r.Get("/", evidenceHandler.List)
+					r.Post("/", evidenceHandler.Create)
+					r.Get("/{exportID}", evidenceHandler.Get)
+					r.Get("/{exportID}/download", evidenceHandler.Download)
+					r.Delete("/{exportID}", evidenceHandler.Delete)
+				})
 
 				// Service routes nested under apps
 				serviceHandler := handlers.NewServiceHandler(s.store, podmanClient, s.sopsService, s.logger)
+				serviceHandler.SetHooks(s.hooks)
 				r.Route("/services", func(r chi.Router) {
 					r.Post("/", serviceHandler.Create)
 					r.Get("/", serviceHandler.List)
 					r.Get("/{serviceName}", serviceHandler.Get)
+					r.Put("/{serviceName}", serviceHandler.Put)
 					r.Patch("/{serviceName}", serviceHandler.Update)
 					r.Delete("/{serviceName}", serviceHandler.Delete)
 					r.Post("/{serviceName}/deploy", deploymentHandler.CreateForService)
+					r.Post("/{serviceName}/builds", deploymentHandler.CreateExternalBuild)
 
 					// Service lifecycle actions
 					r.Post("/{serviceName}/stop", serviceHandler.StopService)
@@ -191,6 +462,42 @@ func (s *Server) setupRouter() {
 					r.Put("/{serviceName}/env/{key}", serviceHandler.UpdateEnvVar)
 					r.Delete("/{serviceName}/env/{key}", serviceHandler.DeleteEnvVar)
 
+					// Egress policy and blocked outbound connections
+					r.Get("/{serviceName}/egress", serviceHandler.GetEgress)
+
+					// Connection strings of the databases the service depends on
+					r.Get("/{serviceName}/bindings", serviceHandler.GetBindings)
+
+					// Time-based replica profiles applied by the scaling cron
+					r.Get("/{serviceName}/scaling-schedule", serviceHandler.GetScalingSchedule)
+					r.Put("/{serviceName}/scaling-schedule", serviceHandler.SetScalingSchedule)
+					r.Delete("/{serviceName}/scaling-schedule", serviceHandler.DeleteScalingSchedule)
+
+					// Metric-based replica bounds and targets applied by the autoscaler
+					r.Get("/{serviceName}/autoscaling", serviceHandler.GetAutoscaling)
+					r.Put("/{serviceName}/autoscaling", serviceHandler.SetAutoscaling)
+					r.Delete("/{serviceName}/autoscaling", serviceHandler.DeleteAutoscaling)
+					r.Get("/{serviceName}/scale-events", serviceHandler.ListScaleEvents)
+
+					// Run history and manual runs of cron services
+					r.Get("/{serviceName}/runs", serviceHandler.ListCronRuns)
+					r.Post("/{serviceName}/runs", serviceHandler.TriggerCronRun)
+
+					// OpenAPI spec fetched from the service's openapi_url
+					r.Get("/{serviceName}/openapi", catalogHandler.GetSpec)
+					r.Post("/{serviceName}/openapi/refresh", catalogHandler.RefreshSpec)
+
+					// Resource usage time series reported by node agents
+					r.Get("/{serviceName}/metrics", serviceHandler.GetMetrics)
+
+					// Failed builds by diagnosed failure class
+					r.Get("/{serviceName}/build-failures", serviceHandler.GetBuildFailureStats)
+
+					// On-demand load tests run from build workers
+					r.Get("/{serviceName}/load-tests", serviceHandler.ListLoadTests)
+					r.Post("/{serviceName}/load-tests", serviceHandler.CreateLoadTest)
+					r.Get("/{serviceName}/load-tests/{loadTestID}", serviceHandler.GetLoadTest)
+
 					// Preview endpoint for build preview
 					previewHandler, err := handlers.NewPreviewHandler(s.store, s.logger)
 					if err != nil {
@@ -199,42 +506,159 @@ func (s *Server) setupRouter() {
 						r.Post("/{serviceName}/preview", previewHandler.Preview)
 					}
 
-					r.Get("/{serviceName}/terminal/ws", serviceHandler.TerminalWS)
+					r.With(s.streams.Track(streams.KindWebSocket)).Get("/{serviceName}/terminal/ws", serviceHandler.TerminalWS)
+
+					// Ephemeral debug containers in the namespaces of running deployments
+					r.Get("/{serviceName}/debug-sessions", serviceHandler.ListDebugSessions)
+					r.Post("/{serviceName}/debug-sessions", serviceHandler.CreateDebugSession)
+					r.Get("/{serviceName}/debug-sessions/{sessionID}", serviceHandler.GetDebugSession)
+					r.Delete("/{serviceName}/debug-sessions/{sessionID}", serviceHandler.EndDebugSession)
+					r.With(s.streams.Track(streams.KindWebSocket)).Get("/{serviceName}/debug-sessions/{sessionID}/attach/ws", serviceHandler.AttachDebugSessionWS)
+
+					// Local development: resolved env and tunnels to dependencies
+					r.Post("/{serviceName}/dev", serviceHandler.CreateDevSession)
+					r.With(s.streams.Track(streams.KindWebSocket)).Get("/{serviceName}/tunnel/ws", serviceHandler.TunnelWS)
+				})
+
+				// Build strategy detection for the create-service form
+				r.Post("/detect", serviceHandler.DetectForService)
+
+				// Declarative app specs (narvana.yaml), with dry runs for CI
+				r.Post("/apply", serviceHandler.ApplySpec)
+
+				// Drift of the app from the app spec last applied to it
+				r.Get("/drift", serviceHandler.GetDrift)
+				r.Post("/drift/revert", serviceHandler.RevertDrift)
+				r.Post("/drift/accept", serviceHandler.AcceptDrift)
+
+				// Service templates instantiated as groups of services
+				r.Route("/service-templates", func(r chi.Router) {
+					r.Get("/", serviceHandler.ListTemplates)
+					r.Post("/", serviceHandler.CreateTemplate)
+					r.Get("/{templateName}", serviceHandler.GetTemplate)
+					r.Put("/{templateName}", serviceHandler.UpdateTemplate)
+					r.Delete("/{templateName}", serviceHandler.DeleteTemplate)
+					r.Post("/{templateName}/instances", serviceHandler.CreateInstance)
+					r.Put("/{templateName}/instances/{instance}", serviceHandler.UpdateInstance)
+					r.Post("/{templateName}/deploy", deploymentHandler.DeployTemplate)
 				})
 
 				// Log routes nested under apps
 				logHandler := handlers.NewLogHandler(s.store, s.logger)
+				logHandler.SetArchiver(s.archiver)
 				r.Get("/logs", logHandler.Get)
 
 				// Real-time log streaming via SSE
 				logStreamHandler := handlers.NewLogStreamHandler(s.store, s.logger)
-				r.Get("/logs/stream", logStreamHandler.Stream)
+				r.With(s.streams.Track(streams.KindSSE)).Get("/logs/stream", logStreamHandler.Stream)
+
+				// App-level env vars inherited by every service
+				r.Route("/env", func(r chi.Router) {
+					r.Get("/", appHandler.ListEnvVars)
+					r.Post("/", appHandler.AddEnvVar)
+					r.Put("/{key}", appHandler.UpdateEnvVar)
+					r.Delete("/{key}", appHandler.DeleteEnvVar)
+				})
 
 				// Secret routes nested under apps
 				secretHandler := handlers.NewSecretHandler(s.store, s.sopsService, s.logger)
+				secretHandler.SetHooks(s.hooks)
 				r.Route("/secrets", func(r chi.Router) {
 					r.Post("/", secretHandler.Create)
 					r.Get("/", secretHandler.List)
+					r.Post("/import", secretHandler.Import)
+					r.Get("/export", secretHandler.Export)
+					r.Put("/{key}", secretHandler.Put)
 					r.Delete("/{key}", secretHandler.Delete)
 				})
 
+				// Lifecycle hooks calling URLs or a hook service of the app
+				appHooksHandler := handlers.NewAppHooksHandler(s.store, s.logger)
+				r.Route("/hooks", func(r chi.Router) {
+					r.Get("/", appHooksHandler.List)
+					r.Post("/", appHooksHandler.Create)
+					r.Put("/{hookID}", appHooksHandler.Update)
+					r.Delete("/{hookID}", appHooksHandler.Delete)
+					r.Get("/{hookID}/deliveries", appHooksHandler.ListDeliveries)
+				})
+
+				// CDN cache purge integrations and the purge log
+				cdnHandler := handlers.NewCDNHandler(s.store, cdn.NewPurger(s.store, nil, s.logger), s.logger)
+				r.Route("/cdn", func(r chi.Router) {
+					r.Get("/integrations", cdnHandler.ListIntegrations)
+					r.Post("/integrations", cdnHandler.CreateIntegration)
+					r.Put("/integrations/{integrationID}", cdnHandler.UpdateIntegration)
+					r.Delete("/integrations/{integrationID}", cdnHandler.DeleteIntegration)
+					r.Post("/purge", cdnHandler.Purge)
+					r.Get("/purges", cdnHandler.ListPurges)
+				})
+
+				// Health-gated promotion into other apps
+				r.Route("/promotion-policies", func(r chi.Router) {
+					r.Get("/", promotionsHandler.ListPolicies)
+					r.Post("/", promotionsHandler.CreatePolicy)
+					r.Put("/{policyID}", promotionsHandler.UpdatePolicy)
+					r.Delete("/{policyID}", promotionsHandler.DeletePolicy)
+				})
+				// Environments of the app's release pipeline, promoted through in order
+				r.Route("/environments", func(r chi.Router) {
+					r.Get("/", promotionsHandler.ListEnvironments)
+					r.Post("/", promotionsHandler.CreateEnvironment)
+					r.Put("/{environmentID}", promotionsHandler.UpdateEnvironment)
+					r.Delete("/{environmentID}", promotionsHandler.DeleteEnvironment)
+				})
+				r.Route("/promotions", func(r chi.Router) {
+					r.Get("/", promotionsHandler.List)
+					r.Post("/{promotionID}/approve", promotionsHandler.Approve)
+					r.Post("/{promotionID}/reject", promotionsHandler.Reject)
+				})
+
 				// Domain routes nested under apps
 				domainHandler := handlers.NewDomainHandler(s.store, s.logger)
 				r.Route("/domains", func(r chi.Router) {
 					r.Post("/", domainHandler.Create)
 					r.Get("/", domainHandler.List)
+					r.Put("/{domain}", domainHandler.Put)
 					r.Delete("/{domainID}", domainHandler.Delete)
 				})
 			})
+
+			// Create or replace an app by name, for infrastructure-as-code tools
+			r.With(middleware.RequireOwnershipIfExists(s.store, s.logger), middleware.RequireAppScope).Put("/{appID}", appHandler.Put)
 		})
 
 		// Deployment routes
 		deploymentHandler := handlers.NewDeploymentHandler(s.store, s.queue, s.logger)
 		r.Route("/deployments", func(r chi.Router) {
 			r.Get("/", deploymentHandler.ListAll)
+			r.Get("/compare", deploymentHandler.Compare)
 			r.Route("/{deploymentID}", func(r chi.Router) {
 				r.Get("/", deploymentHandler.Get)
 				r.Post("/rollback", deploymentHandler.Rollback)
+				r.Post("/cancel", deploymentHandler.Cancel)
+				r.Get("/promotions", promotionsHandler.ListForDeployment)
+				r.Post("/promote", promotionsHandler.Promote)
+				r.Get("/smoke-tests", deploymentHandler.SmokeTests)
+				r.Get("/load-tests", deploymentHandler.LoadTests)
+			})
+		})
+
+		// Live changes of apps, services, deployments and builds
+		eventsHandler := handlers.NewEventsHandler(s.store, s.events, s.logger)
+		r.With(s.streams.Track(streams.KindSSE)).Get("/events", eventsHandler.Stream)
+
+		// App templates: starters creating an app, its services and secrets in one call
+		templateHandler := handlers.NewServiceHandler(s.store, podmanClient, s.sopsService, s.logger)
+		r.Route("/templates", func(r chi.Router) {
+			r.Get("/", templateHandler.ListAppTemplates)
+			r.Get("/{templateName}", templateHandler.GetAppTemplate)
+			r.Post("/{templateName}/instantiate", templateHandler.InstantiateAppTemplate)
+
+			// Templates offered to every user (instance admins only)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(s.store, s.logger))
+				r.Post("/", templateHandler.CreateAppTemplate)
+				r.Delete("/{templateName}", templateHandler.DeleteAppTemplate)
 			})
 		})
 
@@ -248,8 +672,10 @@ func (s *Server) setupRouter() {
 
 		// Node routes
 		nodeHandler := handlers.NewNodeHandler(s.store, s.logger)
+		nodeHandler.SetOperations(s.operations)
 		r.Route("/nodes", func(r chi.Router) {
 			r.Get("/", nodeHandler.List)
+			r.Get("/health-events", nodeHandler.HealthEvents)
 			r.Post("/register", nodeHandler.Register)
 			r.Post("/heartbeat", nodeHandler.Heartbeat)
 			r.Route("/{nodeID}", func(r chi.Router) {
@@ -265,9 +691,32 @@ func (s *Server) setupRouter() {
 					nodeID := chi.URLParam(req, "nodeID")
 					nodeHandler.HeartbeatByID(w, req, nodeID)
 				})
+				if workloadHandler != nil {
+					r.Post("/workload-tokens", workloadHandler.IssueForNode)
+				}
+
+				// Lifecycle (instance admins only)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireAdmin(s.store, s.logger))
+					r.Post("/approve", nodeHandler.Approve)
+					r.Post("/reject", nodeHandler.Reject)
+					r.Post("/cordon", nodeHandler.Cordon)
+					r.Post("/uncordon", nodeHandler.Uncordon)
+					r.Post("/drain", nodeHandler.Drain)
+					r.Delete("/", nodeHandler.Delete)
+				})
 			})
 		})
 
+		// Workload identity token exchange
+		if workloadHandler != nil {
+			r.Post("/workload-identity/token", workloadHandler.Exchange)
+		}
+
+		// Build worker routes (admin only: workers are shared by every org)
+		workerHandler := handlers.NewWorkerHandler(s.store, s.logger)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Get("/workers", workerHandler.List)
+
 		// GitHub routes (under /v1 for consistency)
 		r.Route("/github", func(r chi.Router) {
 			r.Get("/setup", githubHandler.ManifestStart)
@@ -285,35 +734,150 @@ func (s *Server) setupRouter() {
 		r.Route("/settings", func(r chi.Router) {
 			r.Get("/", settingsHandler.Get)
 			r.Patch("/", settingsHandler.Update)
+
+			// Tokens new nodes join with (instance admins only)
+			r.Route("/node-join-tokens", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(s.store, s.logger))
+				r.Get("/", nodeJoinHandler.ListTokens)
+				r.Post("/", nodeJoinHandler.CreateToken)
+				r.Delete("/{tokenID}", nodeJoinHandler.DeleteToken)
+			})
+		})
+
+		// Notification provider routes (instance admins only)
+		notificationsHandler := handlers.NewNotificationsHandler(s.store, notifications.NewDispatcher(nil), s.logger)
+		r.Route("/notifications/providers", func(r chi.Router) {
+			r.Use(middleware.RequireAdmin(s.store, s.logger))
+			r.Get("/", notificationsHandler.List)
+			r.Post("/", notificationsHandler.Create)
+			r.Put("/{providerID}", notificationsHandler.Update)
+			r.Delete("/{providerID}", notificationsHandler.Delete)
+			r.Post("/{providerID}/test", notificationsHandler.Test)
+		})
+
+		// Users' webhooks receiving signed lifecycle events, and their delivery log
+		webhooksHandler := handlers.NewWebhooksHandler(s.store, s.logger)
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", webhooksHandler.List)
+			r.Post("/", webhooksHandler.Create)
+			r.Get("/{webhookID}", webhooksHandler.Get)
+			r.Put("/{webhookID}", webhooksHandler.Update)
+			r.Delete("/{webhookID}", webhooksHandler.Delete)
+			r.Get("/{webhookID}/deliveries", webhooksHandler.ListDeliveries)
+			r.Post("/{webhookID}/deliveries/{deliveryID}/redeliver", webhooksHandler.Redeliver)
 		})
 
 		// Build routes
-		buildHandler := handlers.NewBuildHandler(s.store, s.queue, s.logger)
+		buildHandler := handlers.NewBuildHandler(s.store, s.queue, podmanClient, s.logger)
+		buildHandler.SetArchiver(s.archiver)
 		r.Route("/builds", func(r chi.Router) {
 			r.Get("/", buildHandler.List)
 			r.Route("/{buildID}", func(r chi.Router) {
 				r.Get("/", buildHandler.Get)
 				r.Post("/retry", buildHandler.Retry)
+				r.Post("/cancel", buildHandler.Cancel)
+				r.With(s.streams.Track(streams.KindSSE)).Get("/logs/stream", buildHandler.StreamLogs)
+
+				// Signed SLSA provenance of successful builds
+				r.Get("/attestation", buildHandler.GetAttestation)
+				// Environments of failed builds kept for debugging
+				r.Get("/snapshot", buildHandler.GetSnapshot)
+				r.Delete("/snapshot", buildHandler.DeleteSnapshot)
+				r.With(s.streams.Track(streams.KindWebSocket)).Get("/debug/ws", buildHandler.DebugWS)
 			})
 		})
 
 		// User routes
 		userHandler := handlers.NewUserHandler(s.store, s.logger)
+		sshKeyHandler := handlers.NewSSHKeyHandler(s.store, s.logger)
 		r.Route("/user", func(r chi.Router) {
 			r.Get("/profile", userHandler.GetProfile)
 			r.Patch("/profile", userHandler.UpdateProfile)
+
+			// API keys, optionally scoped to actions on specific apps
+			apiKeyHandler := handlers.NewAPIKeyHandler(s.store, s.logger)
+			r.Get("/api-keys", apiKeyHandler.List)
+			r.Post("/api-keys", apiKeyHandler.Create)
+			r.Delete("/api-keys/{keyID}", apiKeyHandler.Delete)
+
+			// SSH keys for opening node sessions through the SSH broker
+			r.Get("/ssh-keys", sshKeyHandler.List)
+			r.Post("/ssh-keys", sshKeyHandler.Create)
+			r.Delete("/ssh-keys/{keyID}", sshKeyHandler.Delete)
 		})
 
 		// Organization routes
 		orgHandler := handlers.NewOrgHandler(s.store, s.logger)
+		deployFreezeHandler := handlers.NewDeployFreezeHandler(s.store, s.logger)
+		policyHandler := handlers.NewPolicyHandler(s.store, s.logger)
+		admissionHandler := handlers.NewAdmissionPolicyHandler(s.store, s.logger)
+		onCallHandler := handlers.NewOnCallHandler(s.store, s.logger)
+		sharedSecretHandler := handlers.NewSharedSecretHandler(s.store, s.sopsService, s.logger)
+		sharedSecretHandler.SetHooks(s.hooks)
+		orgMembersHandler := handlers.NewOrgMembersHandler(s.store, s.logger)
 		r.Route("/orgs", func(r chi.Router) {
 			r.Post("/", orgHandler.Create)
 			r.Get("/", orgHandler.List)
 			r.Get("/slug/{slug}", orgHandler.GetBySlug)
 			r.Route("/{orgID}", func(r chi.Router) {
+				// Every org route needs membership; changes need admin or owner
+				r.Use(middleware.RequireOrgRole(s.store, s.logger, models.RoleViewer))
+				admin := middleware.RequireOrgRole(s.store, s.logger, models.RoleAdmin)
+				owner := middleware.RequireOrgRole(s.store, s.logger, models.RoleOwner)
+
 				r.Get("/", orgHandler.Get)
-				r.Patch("/", orgHandler.Update)
-				r.Delete("/", orgHandler.Delete)
+				r.With(admin).Patch("/", orgHandler.Update)
+				r.With(owner).Delete("/", orgHandler.Delete)
+
+				// Members, their roles and invitations to join
+				r.Get("/membership", orgMembersHandler.GetMembership)
+				r.Get("/members", orgMembersHandler.ListMembers)
+				r.With(admin).Put("/members/{userID}", orgMembersHandler.UpdateMember)
+				r.Delete("/members/{userID}", orgMembersHandler.RemoveMember)
+				r.With(admin).Get("/invitations", orgMembersHandler.ListInvitations)
+				r.With(admin).Post("/invitations", orgMembersHandler.CreateInvitation)
+				r.With(admin).Delete("/invitations/{invitationID}", orgMembersHandler.RevokeInvitation)
+
+				// Deploy freeze windows
+				r.Get("/freeze-windows", deployFreezeHandler.List)
+				r.With(admin).Post("/freeze-windows", deployFreezeHandler.Create)
+				r.With(admin).Delete("/freeze-windows/{windowID}", deployFreezeHandler.Delete)
+
+				// Declarative RBAC policy
+				r.Get("/policy", policyHandler.Export)
+				r.With(admin).Put("/policy", policyHandler.Apply)
+
+				// Deploy admission policies and their violations
+				r.Get("/admission-policies", admissionHandler.List)
+				r.With(admin).Post("/admission-policies", admissionHandler.Create)
+				r.Post("/admission-policies/evaluate", admissionHandler.Evaluate)
+				r.Get("/admission-policies/{policyID}", admissionHandler.Get)
+				r.With(admin).Put("/admission-policies/{policyID}", admissionHandler.Update)
+				r.With(admin).Delete("/admission-policies/{policyID}", admissionHandler.Delete)
+				r.Get("/admission-violations", admissionHandler.ListViolations)
+
+				// On-call gates on deploys outside business hours and acknowledgements
+				r.Get("/on-call-gates", onCallHandler.ListGates)
+				r.With(admin).Post("/on-call-gates", onCallHandler.CreateGate)
+				r.With(admin).Put("/on-call-gates/{gateID}", onCallHandler.UpdateGate)
+				r.With(admin).Delete("/on-call-gates/{gateID}", onCallHandler.DeleteGate)
+				r.Post("/on-call-gates/{gateID}/acks", onCallHandler.RequestAck)
+				r.Get("/on-call-acks", onCallHandler.ListAcks)
+				r.Post("/on-call-acks/{ackID}/approve", onCallHandler.ApproveAck)
+				r.Post("/on-call-acks/{ackID}/reject", onCallHandler.RejectAck)
+
+				// Secrets shared into some or all of the org's apps
+				r.Get("/shared-secrets", sharedSecretHandler.List)
+				r.With(admin).Post("/shared-secrets", sharedSecretHandler.Create)
+				r.With(admin).Put("/shared-secrets/{key}", sharedSecretHandler.Update)
+				r.With(admin).Delete("/shared-secrets/{key}", sharedSecretHandler.Delete)
+				r.Get("/shared-secrets/{key}/consumers", sharedSecretHandler.Consumers)
+
+				// SCIM provisioning configuration and sync status
+				r.Get("/scim", scimHandler.Status)
+				r.With(admin).Post("/scim/token", scimHandler.GenerateToken)
+				r.With(admin).Delete("/scim/token", scimHandler.RevokeToken)
+				r.With(admin).Put("/scim/mappings", scimHandler.SetMappings)
 			})
 		})
 
@@ -330,18 +894,41 @@ func (s *Server) setupRouter() {
 			r.Post("/", invitationsHandler.Create)
 			r.Get("/", invitationsHandler.List)
 			r.Delete("/{invitationID}", invitationsHandler.Revoke)
+			// Existing users accept org invitations while signed in
+			r.Post("/accept", orgMembersHandler.AcceptInvitation)
 		})
 
 		// Server management routes
 		serverLogsHandler := handlers.NewServerLogsHandler(s.logger)
-		r.Get("/server/logs/stream", serverLogsHandler.Stream)
+		r.With(s.streams.Track(streams.KindSSE)).Get("/server/logs/stream", serverLogsHandler.Stream)
 		r.Get("/server/logs/download", serverLogsHandler.Download)
 		r.Post("/server/restart", serverLogsHandler.Restart)
-		r.Get("/server/console/ws", serverLogsHandler.TerminalWS)
+		r.With(s.streams.Track(streams.KindWebSocket)).Get("/server/console/ws", serverLogsHandler.TerminalWS)
 
 		serverStatsHandler := handlers.NewServerStatsHandler(s.logger, Version)
 		r.Get("/server/stats", serverStatsHandler.Get)
-		r.Get("/server/stats/stream", serverStatsHandler.Stream)
+		r.With(s.streams.Track(streams.KindSSE)).Get("/server/stats/stream", serverStatsHandler.Stream)
+
+		// Open streaming connections (instance admins only)
+		serverStreamsHandler := handlers.NewServerStreamsHandler(s.streams)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Get("/server/streams", serverStreamsHandler.Get)
+
+		// Installation checks with remedies (instance admins only)
+		serverDoctorHandler := handlers.NewServerDoctorHandler(s.doctor)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Get("/server/doctor", serverDoctorHandler.Get)
+		serverDBHealthHandler := handlers.NewServerDBHealthHandler(s.dbHealth, s.logger)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Get("/server/db-health", serverDBHealthHandler.Get)
+		serverLogLevelHandler := handlers.NewServerLogLevelHandler(s.logLevels, s.logger)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Get("/server/log-level", serverLogLevelHandler.Get)
+		r.With(middleware.RequireAdmin(s.store, s.logger)).Patch("/server/log-level", serverLogLevelHandler.Update)
+
+		// Audit log of mutating requests (admins see every actor)
+		auditHandler := handlers.NewAuditHandler(s.store, s.logger)
+		r.Get("/audit", auditHandler.List)
+
+		// Public key of signed evidence packages
+		evidenceKeyHandler := handlers.NewEvidenceHandler(s.store, s.evidence, s.operations, s.logger)
+		r.Get("/evidence/signing-key", evidenceKeyHandler.SigningKey)
 
 		// Update routes
 		updaterService := updater.NewService(Version, "narvanalabs/control-plane", s.logger)
@@ -349,17 +936,73 @@ func (s *Server) setupRouter() {
 		r.Get("/updates/check", updatesHandler.CheckForUpdates)
 		r.Post("/updates/apply", updatesHandler.TriggerUpdate)
 
+		// Announcement banners and release notes feed (readable by every authenticated user)
+		announcementsHandler := handlers.NewAnnouncementsHandler(s.store, s.logger)
+		r.Get("/announcements", announcementsHandler.ListActive)
+		whatsNewHandler := handlers.NewWhatsNewHandler(s.config.ChangelogPath, s.logger)
+		r.Get("/whats-new", whatsNewHandler.List)
+
+		// Progress of long-running operations such as drains and cleanups
+		operationHandler := handlers.NewOperationHandler(s.store, s.logger)
+		r.Get("/operations/{operationID}", operationHandler.Get)
+		r.With(s.streams.Track(streams.KindSSE)).Get("/operations/{operationID}/stream", operationHandler.Stream)
+
 		// Admin cleanup routes
 		// Requirements: 19.1, 19.2, 19.3, 19.4, 25.4, 26.4
 		podmanClientForCleanup := podman.NewClient(s.config.Worker.PodmanSocket, s.logger)
 		cleanupService := cleanup.NewService(s.store, podmanClientForCleanup, s.logger)
-		cleanupHandler := handlers.NewCleanupHandler(s.store, cleanupService, s.logger)
-		r.Route("/admin/cleanup", func(r chi.Router) {
-			r.Post("/containers", cleanupHandler.CleanupContainers)
-			r.Post("/images", cleanupHandler.CleanupImages)
-			r.Post("/nix-gc", cleanupHandler.NixGC)
-			r.Post("/deployments", cleanupHandler.ArchiveDeployments)
-			r.Post("/attic", cleanupHandler.CleanupAttic)
+		cleanupService.SetArchiver(s.archiver)
+		cleanupHandler := handlers.NewCleanupHandler(s.store, cleanupService, s.operations, s.logger)
+		r.Route("/admin", func(r chi.Router) {
+			r.Route("/cleanup", func(r chi.Router) {
+				r.Post("/containers", cleanupHandler.CleanupContainers)
+				r.Post("/images", cleanupHandler.CleanupImages)
+				r.Post("/nix-gc", cleanupHandler.NixGC)
+				r.Post("/deployments", cleanupHandler.ArchiveDeployments)
+				r.Post("/attic", cleanupHandler.CleanupAttic)
+			})
+
+			// Instance admin console (instance admins only)
+			adminHandler := handlers.NewAdminHandler(s.store, s.auth, s.config.Worker.BuildTimeout, s.logger)
+			archiveHandler := handlers.NewArchiveHandler(s.store, s.archiver, s.logger)
+			apiUsageHandler := handlers.NewAPIUsageHandler(s.store, s.versions, s.logger)
+			federationHandler := handlers.NewFederationHandler(s.store, federation.NewService(s.store, nil, s.logger), s.logger)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(s.store, s.logger))
+				r.Get("/overview", adminHandler.Overview)
+				r.Get("/users", adminHandler.ListUsers)
+				r.Get("/orgs", adminHandler.ListOrgs)
+				r.Get("/apps", adminHandler.ListApps)
+				r.Get("/jobs", adminHandler.JobHealth)
+				r.Get("/license", adminHandler.License)
+				r.Get("/feature-flags", adminHandler.ListFeatureFlags)
+				r.Put("/feature-flags", adminHandler.UpdateFeatureFlags)
+				r.Post("/impersonate", adminHandler.Impersonate)
+				r.Get("/announcements", announcementsHandler.List)
+				r.Post("/announcements", announcementsHandler.Create)
+				r.Put("/announcements/{announcementID}", announcementsHandler.Update)
+				r.Delete("/announcements/{announcementID}", announcementsHandler.Delete)
+				r.Get("/ssh-sessions", sshKeyHandler.ListSessions)
+				r.Get("/archive", archiveHandler.Get)
+
+				// API versions, deprecated routes and the clients still using them
+				r.Get("/api-usage", apiUsageHandler.Get)
+
+				// Read-only view of this instance and the other installs registered as peers
+				r.Get("/federation", federationHandler.View)
+				r.Get("/federation/summary", federationHandler.Summary)
+				r.Get("/federation/peers", federationHandler.ListPeers)
+				r.Post("/federation/peers", federationHandler.CreatePeer)
+				r.Delete("/federation/peers/{peerID}", federationHandler.DeletePeer)
+
+				// Queued builds, in the order workers claim them
+				if mq, ok := s.queue.(queue.ManagedQueue); ok {
+					buildQueueHandler := handlers.NewBuildQueueHandler(s.store, mq, s.logger)
+					r.Get("/build-queue", buildQueueHandler.List)
+					r.Patch("/build-queue/{buildID}", buildQueueHandler.Reorder)
+					r.Delete("/build-queue/{buildID}", buildQueueHandler.Cancel)
+				}
+			})
 		})
 	})
 
@@ -384,6 +1027,8 @@ func (s *Server) Start(ctx context.Context) error {
 
 	s.logger.Info("starting API server", "addr", addr)
 
+	go s.streams.Run(ctx)
+
 	errCh := make(chan error, 1)
 	go func() {
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -408,6 +1053,76 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(shutdownCtx)
 }
 
+// Streams returns the registry of open streaming connections. Callers that
+// serve Router directly should run its idle reaper with Streams().Run.
+func (s *Server) Streams() *streams.Registry {
+	return s.streams
+}
+
+// RateLimits returns the limiter of requests by client IP, caller and login
+// account. Callers should run its sweeper of idle buckets with
+// RateLimits().Run.
+func (s *Server) RateLimits() *ratelimit.Limiter {
+	return s.rateLimits
+}
+
+// Archiver returns the archiver of old deployments, or nil if archiving is
+// not configured. Callers should run it with Archiver().Run.
+func (s *Server) Archiver() *archive.Archiver {
+	return s.archiver
+}
+
+// Operations returns the manager of long-running operations. Callers should
+// run its sweeper of stale and expired operations with Operations().Run.
+func (s *Server) Operations() *operations.Manager {
+	return s.operations
+}
+
+// Events returns the hub behind /v1/events. Callers should feed it the
+// store's changes with Events().Run.
+func (s *Server) Events() *events.Hub {
+	return s.events
+}
+
+// Doctor returns the installation checker behind /v1/server/doctor. Callers
+// that own the database and node connections add its schema and clock skew
+// checks with SetSchema and SetClockSkews.
+func (s *Server) Doctor() *doctor.Doctor {
+	return s.doctor
+}
+
+// DBHealth returns the database maintainer behind /v1/server/db-health.
+// Callers should run its scheduled maintenance with DBHealth().Run.
+func (s *Server) DBHealth() *dbhealth.Maintainer {
+	return s.dbHealth
+}
+
+// LogLevels returns the controller of components' log levels behind
+// /v1/server/log-level. Callers should attach the process's levels with
+// LogLevels().Manage and apply changes made elsewhere with LogLevels().Run.
+func (s *Server) LogLevels() *loglevel.Controller {
+	return s.logLevels
+}
+
+// Idempotency returns the middleware replaying the responses of requests
+// sent with an Idempotency-Key. Callers should delete expired keys with
+// Idempotency().Run.
+func (s *Server) Idempotency() *idempotency.Keys {
+	return s.idempotency
+}
+
+// Versions returns the middleware negotiating API versions. Callers should
+// record the usage of deprecated routes it counts with Versions().Run.
+func (s *Server) Versions() *versioning.Negotiator {
+	return s.versions
+}
+
+// Telemetry returns the metrics registry served at /metrics, so the other
+// components of the API server process can add their metrics to it.
+func (s *Server) Telemetry() *telemetry.Registry {
+	return s.telemetry
+}
+
 // Router returns the chi router for testing purposes.
 func (s *Server) Router() chi.Router {
 	return s.router
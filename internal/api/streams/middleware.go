@@ -0,0 +1,143 @@
+package streams
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/api/quota"
+)
+
+// Track returns a middleware that registers each request as a stream of the
+// given kind. Requests over a limit get 429. Admitted streams give up their
+// place among the requests the API server serves at once. The stream is
+// released when the handler returns, and closing it (e.g. when idle) cancels
+// the request context and closes the underlying connection if it was hijacked
+// for a WebSocket.
+func (r *Registry) Track(kind string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, cancel := context.WithCancel(req.Context())
+			defer cancel()
+
+			appID := middleware.GetResolvedAppID(req.Context())
+			if appID == "" {
+				appID = chi.URLParam(req, "appID")
+			}
+			route := req.URL.Path
+			if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			conn, err := r.Open(kind, route, middleware.GetUserID(req.Context()), appID, cancel)
+			if err != nil {
+				r.logger.Warn("stream rejected",
+					"kind", kind,
+					"route", route,
+					"user_id", middleware.GetUserID(req.Context()),
+					"app_id", appID,
+					"reason", err.Error(),
+				)
+				writeTooManyStreams(w, err)
+				return
+			}
+			defer r.Release(conn)
+
+			// Streams are limited here, not by the requests served at once
+			quota.Release(req.Context())
+
+			next.ServeHTTP(&trackingWriter{ResponseWriter: w, conn: conn}, req.WithContext(ctx))
+		})
+	}
+}
+
+// writeTooManyStreams writes a 429 in the API's error format.
+func writeTooManyStreams(w http.ResponseWriter, err error) {
+	message := "Too many open streams for this user"
+	if errors.Is(err, ErrAppLimit) {
+		message = "Too many open streams for this app"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "30")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    "too_many_streams",
+		"message": message,
+	})
+}
+
+// trackingWriter records writes as stream activity. It keeps the Flusher and
+// Hijacker behaviour of the wrapped writer that SSE and WebSocket handlers
+// rely on.
+type trackingWriter struct {
+	http.ResponseWriter
+	conn *Conn
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.conn.Touch()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trackingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands over the connection for a WebSocket upgrade. The returned
+// connection records reads and writes as activity, and closing the stream
+// closes it.
+func (w *trackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	netConn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.conn.onClose(func() { netConn.Close() })
+
+	tracked := &trackingConn{Conn: netConn, stream: w.conn}
+	if rw.Reader.Buffered() > 0 {
+		// Keep bytes the server already read; the upgrader rejects this case
+		return tracked, rw, nil
+	}
+	// Rebuild the buffers over the tracked connection so reads through them
+	// count as activity too
+	rw = bufio.NewReadWriter(
+		bufio.NewReaderSize(tracked, rw.Reader.Size()),
+		bufio.NewWriterSize(tracked, rw.Writer.Size()),
+	)
+	return tracked, rw, nil
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// trackingConn records traffic on a hijacked connection as stream activity.
+type trackingConn struct {
+	net.Conn
+	stream *Conn
+}
+
+func (c *trackingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.stream.Touch()
+	}
+	return n, err
+}
+
+func (c *trackingConn) Write(b []byte) (int, error) {
+	c.stream.Touch()
+	return c.Conn.Write(b)
+}
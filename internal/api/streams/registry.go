@@ -0,0 +1,316 @@
+// Package streams tracks long-lived SSE and WebSocket connections held open by
+// the API server, enforcing per-user and per-app limits and closing streams
+// that have gone idle.
+package streams
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of stream tracked by the registry.
+const (
+	KindSSE       = "sse"
+	KindWebSocket = "websocket"
+)
+
+var (
+	// ErrUserLimit is returned when a user already holds the maximum number of streams.
+	ErrUserLimit = errors.New("too many open streams for user")
+	// ErrAppLimit is returned when an app already has the maximum number of streams.
+	ErrAppLimit = errors.New("too many open streams for app")
+)
+
+// Config holds the registry limits. A zero limit disables that check.
+type Config struct {
+	MaxPerUser  int           // Open streams allowed per user
+	MaxPerApp   int           // Open streams allowed per app, across all users
+	IdleTimeout time.Duration // Streams with no traffic for this long are closed
+}
+
+// Conn is a registered stream.
+type Conn struct {
+	ID        string
+	Kind      string
+	Route     string
+	UserID    string
+	AppID     string
+	StartedAt time.Time
+
+	lastActive atomic.Int64 // unix nanoseconds
+
+	mu      sync.Mutex
+	closed  bool
+	closers []func()
+}
+
+// Touch records activity on the stream, resetting its idle timer.
+func (c *Conn) Touch() {
+	c.lastActive.Store(time.Now().UnixNano())
+}
+
+// LastActive returns when the stream last sent or received data.
+func (c *Conn) LastActive() time.Time {
+	return time.Unix(0, c.lastActive.Load())
+}
+
+// Close terminates the stream. It is safe to call more than once.
+func (c *Conn) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	closers := c.closers
+	c.mu.Unlock()
+
+	for _, fn := range closers {
+		fn()
+	}
+}
+
+// onClose adds a function run when the stream is closed, e.g. closing a
+// hijacked WebSocket connection that context cancellation does not reach. If
+// the stream is already closed, fn runs immediately.
+func (c *Conn) onClose(fn func()) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		fn()
+		return
+	}
+	c.closers = append(c.closers, fn)
+	c.mu.Unlock()
+}
+
+// Registry tracks open streams.
+type Registry struct {
+	config Config
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	conns  map[string]*Conn
+	byUser map[string]int
+	byApp  map[string]int
+
+	rejected    atomic.Int64
+	idleClosed  atomic.Int64
+	totalOpened atomic.Int64
+}
+
+// NewRegistry creates a stream registry.
+func NewRegistry(config Config, logger *slog.Logger) *Registry {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Registry{
+		config: config,
+		logger: logger,
+		conns:  make(map[string]*Conn),
+		byUser: make(map[string]int),
+		byApp:  make(map[string]int),
+	}
+}
+
+// Open registers a stream, enforcing the per-user and per-app limits. close is
+// called if the registry needs to terminate the stream. The caller must call
+// Release when the stream ends.
+func (r *Registry) Open(kind, route, userID, appID string, close func()) (*Conn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if userID != "" && r.config.MaxPerUser > 0 && r.byUser[userID] >= r.config.MaxPerUser {
+		r.rejected.Add(1)
+		return nil, ErrUserLimit
+	}
+	if appID != "" && r.config.MaxPerApp > 0 && r.byApp[appID] >= r.config.MaxPerApp {
+		r.rejected.Add(1)
+		return nil, ErrAppLimit
+	}
+
+	conn := &Conn{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Route:     route,
+		UserID:    userID,
+		AppID:     appID,
+		StartedAt: time.Now(),
+	}
+	conn.Touch()
+	if close != nil {
+		conn.closers = []func(){close}
+	}
+
+	r.conns[conn.ID] = conn
+	if userID != "" {
+		r.byUser[userID]++
+	}
+	if appID != "" {
+		r.byApp[appID]++
+	}
+	r.totalOpened.Add(1)
+
+	return conn, nil
+}
+
+// Release removes a stream from the registry.
+func (r *Registry) Release(conn *Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.conns[conn.ID]; !ok {
+		return
+	}
+	delete(r.conns, conn.ID)
+	decrement(r.byUser, conn.UserID)
+	decrement(r.byApp, conn.AppID)
+}
+
+func decrement(counts map[string]int, key string) {
+	if key == "" {
+		return
+	}
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
+
+// Run closes idle streams until ctx is done. It does nothing when no idle
+// timeout is configured.
+func (r *Registry) Run(ctx context.Context) {
+	if r.config.IdleTimeout <= 0 {
+		return
+	}
+
+	interval := r.config.IdleTimeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.closeIdle(time.Now())
+		}
+	}
+}
+
+// closeIdle closes streams that have been inactive for longer than the idle
+// timeout and returns how many were closed.
+func (r *Registry) closeIdle(now time.Time) int {
+	r.mu.Lock()
+	var idle []*Conn
+	for _, conn := range r.conns {
+		if now.Sub(conn.LastActive()) > r.config.IdleTimeout {
+			idle = append(idle, conn)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, conn := range idle {
+		r.logger.Info("closing idle stream",
+			"stream_id", conn.ID,
+			"kind", conn.Kind,
+			"route", conn.Route,
+			"user_id", conn.UserID,
+			"app_id", conn.AppID,
+			"idle_for", now.Sub(conn.LastActive()).Round(time.Second).String(),
+		)
+		conn.Close()
+		r.idleClosed.Add(1)
+	}
+	return len(idle)
+}
+
+// Snapshot summarizes the streams currently open.
+type Snapshot struct {
+	Open        int            `json:"open"`
+	ByKind      map[string]int `json:"by_kind"`
+	ByRoute     map[string]int `json:"by_route"`
+	ByUser      map[string]int `json:"by_user"`
+	ByApp       map[string]int `json:"by_app"`
+	TotalOpened int64          `json:"total_opened"`
+	Rejected    int64          `json:"rejected"`
+	IdleClosed  int64          `json:"idle_closed"`
+	Limits      Limits         `json:"limits"`
+	Streams     []StreamInfo   `json:"streams"`
+}
+
+// Limits reports the configured limits.
+type Limits struct {
+	MaxPerUser         int `json:"max_per_user"`
+	MaxPerApp          int `json:"max_per_app"`
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"`
+}
+
+// StreamInfo describes one open stream.
+type StreamInfo struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Route      string    `json:"route"`
+	UserID     string    `json:"user_id,omitempty"`
+	AppID      string    `json:"app_id,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	LastActive time.Time `json:"last_active"`
+}
+
+// Snapshot returns the current stream counts, oldest streams first.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap := Snapshot{
+		Open:        len(r.conns),
+		ByKind:      make(map[string]int),
+		ByRoute:     make(map[string]int),
+		ByUser:      make(map[string]int, len(r.byUser)),
+		ByApp:       make(map[string]int, len(r.byApp)),
+		TotalOpened: r.totalOpened.Load(),
+		Rejected:    r.rejected.Load(),
+		IdleClosed:  r.idleClosed.Load(),
+		Limits: Limits{
+			MaxPerUser:         r.config.MaxPerUser,
+			MaxPerApp:          r.config.MaxPerApp,
+			IdleTimeoutSeconds: int(r.config.IdleTimeout / time.Second),
+		},
+		Streams: make([]StreamInfo, 0, len(r.conns)),
+	}
+	for user, n := range r.byUser {
+		snap.ByUser[user] = n
+	}
+	for app, n := range r.byApp {
+		snap.ByApp[app] = n
+	}
+	for _, conn := range r.conns {
+		snap.ByKind[conn.Kind]++
+		snap.ByRoute[conn.Route]++
+		snap.Streams = append(snap.Streams, StreamInfo{
+			ID:         conn.ID,
+			Kind:       conn.Kind,
+			Route:      conn.Route,
+			UserID:     conn.UserID,
+			AppID:      conn.AppID,
+			StartedAt:  conn.StartedAt,
+			LastActive: conn.LastActive(),
+		})
+	}
+	sort.Slice(snap.Streams, func(i, j int) bool {
+		return snap.Streams[i].StartedAt.Before(snap.Streams[j].StartedAt)
+	})
+
+	return snap
+}
@@ -0,0 +1,156 @@
+package streams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRegistry_Limits(t *testing.T) {
+	reg := NewRegistry(Config{MaxPerUser: 2, MaxPerApp: 3}, nil)
+
+	a, err := reg.Open(KindSSE, "/logs/stream", "user-1", "app-1", nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := reg.Open(KindSSE, "/logs/stream", "user-1", "app-1", nil); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := reg.Open(KindSSE, "/logs/stream", "user-1", "app-1", nil); !errors.Is(err, ErrUserLimit) {
+		t.Fatalf("expected ErrUserLimit, got %v", err)
+	}
+
+	// Another user can still open a stream until the app limit is reached
+	if _, err := reg.Open(KindSSE, "/logs/stream", "user-2", "app-1", nil); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := reg.Open(KindSSE, "/logs/stream", "user-3", "app-1", nil); !errors.Is(err, ErrAppLimit) {
+		t.Fatalf("expected ErrAppLimit, got %v", err)
+	}
+
+	// Releasing frees a slot for the user
+	reg.Release(a)
+	reg.Release(a)
+	if _, err := reg.Open(KindWebSocket, "/terminal/ws", "user-1", "", nil); err != nil {
+		t.Fatalf("Open after release: %v", err)
+	}
+
+	snap := reg.Snapshot()
+	if snap.Open != 3 {
+		t.Errorf("open = %d, want 3", snap.Open)
+	}
+	if snap.ByUser["user-1"] != 2 || snap.ByUser["user-2"] != 1 {
+		t.Errorf("unexpected per-user counts: %v", snap.ByUser)
+	}
+	if snap.ByApp["app-1"] != 2 {
+		t.Errorf("app-1 count = %d, want 2", snap.ByApp["app-1"])
+	}
+	if snap.ByKind[KindSSE] != 2 || snap.ByKind[KindWebSocket] != 1 {
+		t.Errorf("unexpected per-kind counts: %v", snap.ByKind)
+	}
+	if snap.Rejected != 2 || snap.TotalOpened != 4 {
+		t.Errorf("rejected = %d, total = %d, want 2 and 4", snap.Rejected, snap.TotalOpened)
+	}
+}
+
+func TestRegistry_CloseIdle(t *testing.T) {
+	reg := NewRegistry(Config{IdleTimeout: time.Minute}, nil)
+
+	closed := make(map[string]bool)
+	idle, _ := reg.Open(KindSSE, "/a", "user-1", "", func() { closed["idle"] = true })
+	active, _ := reg.Open(KindSSE, "/b", "user-1", "", func() { closed["active"] = true })
+
+	idle.lastActive.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	active.Touch()
+
+	if n := reg.closeIdle(time.Now()); n != 1 {
+		t.Fatalf("closed %d streams, want 1", n)
+	}
+	if !closed["idle"] || closed["active"] {
+		t.Errorf("unexpected closes: %v", closed)
+	}
+	if got := reg.Snapshot().IdleClosed; got != 1 {
+		t.Errorf("idle_closed = %d, want 1", got)
+	}
+}
+
+func TestTrack_RejectsOverLimitAndReleases(t *testing.T) {
+	reg := NewRegistry(Config{MaxPerApp: 1}, nil)
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handler := reg.Track(KindSSE)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("tracked writer should implement http.Flusher")
+		}
+		close(started)
+		select {
+		case <-finish:
+		case <-r.Context().Done():
+		}
+	}))
+
+	// The first stream holds the app's only slot
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodGet, "/v1/apps/app-1/logs/stream", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), withAppID(req, "app-1"))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/apps/app-1/logs/stream", nil)
+	handler.ServeHTTP(rec, withAppID(req, "app-1"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+
+	close(finish)
+	<-done
+	if open := reg.Snapshot().Open; open != 0 {
+		t.Errorf("open = %d after handler returned, want 0", open)
+	}
+}
+
+func TestTrack_CloseCancelsRequest(t *testing.T) {
+	reg := NewRegistry(Config{}, nil)
+
+	started := make(chan struct{})
+	handler := reg.Track(KindSSE)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/server/stats/stream", nil))
+	}()
+	<-started
+
+	for _, info := range reg.Snapshot().Streams {
+		reg.mu.Lock()
+		conn := reg.conns[info.ID]
+		reg.mu.Unlock()
+		conn.Close()
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("closing the stream did not end the handler")
+	}
+}
+
+// withAppID sets the chi appID URL parameter on req.
+func withAppID(req *http.Request, appID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("appID", appID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,66 @@
+package versioning
+
+import (
+	"math"
+	"time"
+)
+
+// healthCheckDurationShims accept the health checks of version 1.0, which
+// documented their interval and timeout as duration strings such as "10s",
+// on the routes that create and update services.
+func healthCheckDurationShims() []Shim {
+	var shims []Shim
+	for _, route := range []string{
+		"POST /v1/apps/{appID}/services",
+		"PATCH /v1/apps/{appID}/services/{serviceName}",
+	} {
+		shims = append(shims, Shim{
+			Name:    "health-check-durations",
+			Route:   route,
+			Until:   V1_1,
+			Request: healthCheckDurations,
+		})
+	}
+	return shims
+}
+
+// healthCheckDurations moves a health check's interval and timeout into
+// interval_seconds and timeout_seconds, unless those are set.
+func healthCheckDurations(body map[string]any) {
+	check, ok := body["health_check"].(map[string]any)
+	if !ok {
+		return
+	}
+	for old, field := range map[string]string{"interval": "interval_seconds", "timeout": "timeout_seconds"} {
+		value, ok := check[old]
+		if !ok {
+			continue
+		}
+		delete(check, old)
+		if _, set := check[field]; set {
+			continue
+		}
+		if seconds, ok := durationSeconds(value); ok {
+			check[field] = seconds
+		}
+	}
+}
+
+// durationSeconds converts a duration string, or a number of seconds, into
+// whole seconds, rounding up.
+func durationSeconds(value any) (int, bool) {
+	switch v := value.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, false
+		}
+		return int(math.Ceil(d.Seconds())), true
+	case float64:
+		if v <= 0 {
+			return 0, false
+		}
+		return int(math.Ceil(v)), true
+	}
+	return 0, false
+}
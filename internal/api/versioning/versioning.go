@@ -0,0 +1,492 @@
+// Package versioning lets the /v1 API change without breaking the CLI, the
+// SDK and scripts written against its earlier behaviour. Clients pin an API
+// version with the Narvana-API-Version header; requests without one are
+// served as the default version, the oldest still supported. Shims adapt the
+// requests and responses of older versions so handlers only implement the
+// current one. Versions and routes being retired are marked with Deprecation
+// and Sunset headers, requests still using them are counted per client, and
+// once sunset they are refused with 410 Gone.
+package versioning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+const (
+	// Header carries the API version a client pins, and the version a
+	// response was served with.
+	Header = "Narvana-API-Version"
+	// ClientHeader names the client making a request, e.g.
+	// "narvanactl/1.8.0". Usage of deprecated routes is counted by it, or by
+	// the User-Agent of requests without it.
+	ClientHeader = "Narvana-Client"
+	// DeprecationHeader is set on responses to deprecated routes and
+	// versions to when they were deprecated (RFC 9745).
+	DeprecationHeader = "Deprecation"
+	// SunsetHeader is set to when a deprecated route or version stops being
+	// served (RFC 8594).
+	SunsetHeader = "Sunset"
+
+	// maxClientLength is the longest client name recorded.
+	maxClientLength = 200
+)
+
+// Error codes of requests that cannot be served.
+const (
+	ErrCodeUnsupportedVersion = "unsupported_api_version"
+	ErrCodeSunset             = "api_sunset"
+)
+
+// Versions of the /v1 API, oldest first.
+const (
+	// V1_0 is the /v1 API as first released.
+	V1_0 = "1.0"
+	// V1_1 takes health checks' interval and timeout only as
+	// interval_seconds and timeout_seconds.
+	V1_1 = "1.1"
+
+	// Current is the latest version.
+	Current = V1_1
+)
+
+// Version is a version of the /v1 API.
+type Version struct {
+	Name string `json:"name"`
+	// Changes summarizes how the version differs from the one before it
+	Changes string `json:"changes,omitempty"`
+	// Deprecated is when the version was deprecated, if it is
+	Deprecated *time.Time `json:"deprecated,omitempty"`
+	// Sunset is when requests pinning the version stop being served, if
+	// planned
+	Sunset *time.Time `json:"sunset,omitempty"`
+}
+
+// Deprecation retires a route of every version.
+type Deprecation struct {
+	// Route is the method and route pattern, e.g. "POST /v1/nodes/heartbeat"
+	Route string    `json:"route"`
+	Since time.Time `json:"since"`
+	// Sunset is when the route stops being served, if planned
+	Sunset *time.Time `json:"sunset,omitempty"`
+	// Successor is the route replacing it, if any
+	Successor string `json:"successor,omitempty"`
+}
+
+// Shim adapts a route's requests and responses of the versions before Until
+// to its current handler. Request and response bodies are JSON; nil hooks
+// leave them alone.
+type Shim struct {
+	Name  string
+	Route string
+	// Until is the first version the shim does not apply to
+	Until string
+	// Request rewrites a request body, e.g. renaming fields the version
+	// sent under other names
+	Request func(body map[string]any)
+	// Response rewrites a successful response's body into what the version
+	// returned
+	Response func(body any) any
+}
+
+// Policy is the versions the API serves and the routes being retired.
+type Policy struct {
+	// Versions are the supported versions, oldest first; the last is the
+	// current version
+	Versions []Version
+	// Default is the version of requests without a version header
+	Default      string
+	Deprecations []Deprecation
+	Shims        []Shim
+}
+
+// DefaultPolicy returns the versions and deprecations of this release.
+func DefaultPolicy() Policy {
+	deprecated := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	heartbeatSunset := time.Date(2027, 4, 17, 0, 0, 0, 0, time.UTC)
+	return Policy{
+		Versions: []Version{
+			{Name: V1_0},
+			{Name: V1_1, Changes: "Health checks take interval_seconds and timeout_seconds; interval and timeout durations are ignored"},
+		},
+		Default: V1_0,
+		Deprecations: []Deprecation{
+			{
+				Route:     "POST /v1/nodes/heartbeat",
+				Since:     deprecated,
+				Sunset:    &heartbeatSunset,
+				Successor: "POST /v1/nodes/{nodeID}/heartbeat",
+			},
+		},
+		Shims: healthCheckDurationShims(),
+	}
+}
+
+// version returns the supported version named name.
+func (p *Policy) version(name string) (Version, int, bool) {
+	for i, v := range p.Versions {
+		if v.Name == name {
+			return v, i, true
+		}
+	}
+	return Version{}, -1, false
+}
+
+// deprecation returns the deprecation of route, if it is deprecated.
+func (p *Policy) deprecation(route string) (Deprecation, bool) {
+	for _, d := range p.Deprecations {
+		if d.Route == route {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// shims returns the shims of route that apply to the version at index.
+func (p *Policy) shims(route string, index int) []Shim {
+	var shims []Shim
+	for _, s := range p.Shims {
+		if s.Route != route {
+			continue
+		}
+		if _, until, ok := p.version(s.Until); ok && index >= until {
+			continue
+		}
+		shims = append(shims, s)
+	}
+	return shims
+}
+
+// Config controls how usage of deprecated routes and versions is recorded.
+type Config struct {
+	// FlushInterval is how often counted usage is written to the store.
+	FlushInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{FlushInterval: time.Minute}
+}
+
+// usageKey identifies a client's usage of a route with a version.
+type usageKey struct {
+	route, version, userID, apiKeyID, client string
+}
+
+// Negotiator is HTTP middleware that negotiates the API version of
+// requests, applies shims and deprecations, and counts usage of deprecated
+// routes and versions.
+type Negotiator struct {
+	store  store.Store
+	policy Policy
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[usageKey]*models.APIUsage
+}
+
+// NewNegotiator creates the middleware serving the versions of policy.
+func NewNegotiator(st store.Store, policy Policy, cfg Config, logger *slog.Logger) *Negotiator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if policy.Default == "" && len(policy.Versions) > 0 {
+		policy.Default = policy.Versions[0].Name
+	}
+	return &Negotiator{
+		store:   st,
+		policy:  policy,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+		pending: make(map[usageKey]*models.APIUsage),
+	}
+}
+
+// Policy returns the versions served and the routes being retired.
+func (n *Negotiator) Policy() Policy {
+	return n.policy
+}
+
+type contextKey struct{}
+
+// FromContext returns the API version a request is served with, or "" if it
+// was not negotiated.
+func FromContext(ctx context.Context) string {
+	version, _ := ctx.Value(contextKey{}).(string)
+	return version
+}
+
+// Middleware returns the middleware. The router must be the root router
+// serving the requests so that route patterns are resolved before the
+// handler runs. It must run after authentication so usage is counted by
+// caller.
+func (n *Negotiator) Middleware(router chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := strings.TrimSpace(r.Header.Get(Header))
+			if name == "" {
+				name = n.policy.Default
+			}
+			version, index, ok := n.policy.version(name)
+			if !ok {
+				writeError(w, http.StatusBadRequest, ErrCodeUnsupportedVersion,
+					fmt.Sprintf("API version %q is not supported; supported versions are %s", name, strings.Join(n.names(), ", ")))
+				return
+			}
+			now := n.now()
+			if version.Sunset != nil && !now.Before(*version.Sunset) {
+				writeError(w, http.StatusGone, ErrCodeSunset,
+					fmt.Sprintf("API version %s was sunset on %s; use version %s", name, version.Sunset.Format(time.DateOnly), n.Current()))
+				return
+			}
+
+			pattern := strings.TrimSuffix(router.Find(chi.NewRouteContext(), r.Method, r.URL.Path), "/")
+			route := r.Method + " " + pattern
+			deprecation, routeDeprecated := n.policy.deprecation(route)
+			if routeDeprecated && deprecation.Sunset != nil && !now.Before(*deprecation.Sunset) {
+				msg := fmt.Sprintf("%s was sunset on %s", route, deprecation.Sunset.Format(time.DateOnly))
+				if deprecation.Successor != "" {
+					msg += "; use " + deprecation.Successor
+				}
+				writeError(w, http.StatusGone, ErrCodeSunset, msg)
+				return
+			}
+
+			h := w.Header()
+			h.Set(Header, name)
+			switch {
+			case routeDeprecated:
+				setDeprecation(h, deprecation.Since, deprecation.Sunset)
+				if deprecation.Successor != "" {
+					if _, path, ok := strings.Cut(deprecation.Successor, " "); ok {
+						h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", path))
+					}
+				}
+			case version.Deprecated != nil:
+				setDeprecation(h, *version.Deprecated, version.Sunset)
+			}
+			if pattern != "" && (routeDeprecated || version.Deprecated != nil) {
+				n.count(r, route, name, now)
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, name))
+			shims := n.policy.shims(route, index)
+			if pattern == "" || len(shims) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			n.serveShimmed(w, r, next, shims)
+		})
+	}
+}
+
+// setDeprecation sets the deprecation headers of a route or version.
+func setDeprecation(h http.Header, since time.Time, sunset *time.Time) {
+	h.Set(DeprecationHeader, "@"+strconv.FormatInt(since.Unix(), 10))
+	if sunset != nil {
+		h.Set(SunsetHeader, sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// serveShimmed serves a request through the shims of its route and version.
+func (n *Negotiator) serveShimmed(w http.ResponseWriter, r *http.Request, next http.Handler, shims []Shim) {
+	if ct := r.Header.Get("Content-Type"); ct == "" || strings.HasPrefix(ct, "application/json") {
+		if err := shimRequest(r, shims); err != nil {
+			// Leave malformed bodies for the handler to reject
+			n.logger.Debug("not shimming request body", "path", r.URL.Path, "error", err)
+		}
+	}
+
+	var responseShims []Shim
+	for _, s := range shims {
+		if s.Response != nil {
+			responseShims = append(responseShims, s)
+		}
+	}
+	if len(responseShims) == 0 {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	rec := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+	next.ServeHTTP(rec, r)
+	body := rec.body.Bytes()
+	if rec.status < http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err == nil {
+			for _, s := range responseShims {
+				decoded = s.Response(decoded)
+			}
+			if encoded, err := json.Marshal(decoded); err == nil {
+				body = append(encoded, '\n')
+			}
+		}
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(rec.status)
+	w.Write(body)
+}
+
+// shimRequest rewrites a JSON request body with the request hooks of shims.
+func shimRequest(r *http.Request, shims []Shim) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	data, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	for _, s := range shims {
+		if s.Request != nil {
+			s.Request(body)
+		}
+	}
+	data, err = json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+	return nil
+}
+
+// bufferedResponse holds a response until the shims have rewritten it.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// count adds a request to the usage of a deprecated route or version.
+func (n *Negotiator) count(r *http.Request, route, version string, at time.Time) {
+	client := r.Header.Get(ClientHeader)
+	if client == "" {
+		client = r.UserAgent()
+	}
+	if len(client) > maxClientLength {
+		client = client[:maxClientLength]
+	}
+	ctx := r.Context()
+	key := usageKey{
+		route:    route,
+		version:  version,
+		userID:   middleware.GetUserID(ctx),
+		apiKeyID: middleware.GetAPIKeyID(ctx),
+		client:   client,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	u, ok := n.pending[key]
+	if !ok {
+		u = &models.APIUsage{
+			Route:     route,
+			Version:   version,
+			UserID:    key.userID,
+			APIKeyID:  key.apiKeyID,
+			Client:    client,
+			FirstSeen: at,
+		}
+		n.pending[key] = u
+	}
+	u.Count++
+	u.LastSeen = at
+}
+
+// Run writes counted usage to the store every flush interval until ctx is
+// cancelled, and once more before returning.
+func (n *Negotiator) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.Flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			n.Flush(ctx)
+		}
+	}
+}
+
+// Flush writes the usage counted since the last flush to the store. Usage
+// that cannot be written is counted again with the next flush.
+func (n *Negotiator) Flush(ctx context.Context) {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = make(map[usageKey]*models.APIUsage)
+	n.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	usage := make([]*models.APIUsage, 0, len(pending))
+	for _, u := range pending {
+		usage = append(usage, u)
+	}
+	if err := n.store.APIUsage().Record(ctx, usage); err != nil {
+		n.logger.Error("failed to record deprecated API usage", "error", err)
+		n.mu.Lock()
+		for key, u := range pending {
+			if newer, ok := n.pending[key]; ok {
+				newer.Count += u.Count
+				newer.FirstSeen = u.FirstSeen
+			} else {
+				n.pending[key] = u
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+// names returns the names of the supported versions.
+func (n *Negotiator) names() []string {
+	names := make([]string, 0, len(n.policy.Versions))
+	for _, v := range n.policy.Versions {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// Current returns the name of the latest version served.
+func (n *Negotiator) Current() string {
+	if len(n.policy.Versions) == 0 {
+		return ""
+	}
+	return n.policy.Versions[len(n.policy.Versions)-1].Name
+}
+
+// writeError writes an error response in the API's error format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"code": code, "message": message})
+}
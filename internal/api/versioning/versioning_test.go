@@ -0,0 +1,186 @@
+package versioning
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// usageStore is an in-memory store of recorded API usage.
+type usageStore struct {
+	store.Store
+	usage []*models.APIUsage
+}
+
+func (s *usageStore) APIUsage() store.APIUsageStore { return memUsage{s: s} }
+
+type memUsage struct {
+	store.APIUsageStore
+	s *usageStore
+}
+
+func (m memUsage) Record(ctx context.Context, usage []*models.APIUsage) error {
+	m.s.usage = append(m.s.usage, usage...)
+	return nil
+}
+
+// newTestRouter serves the policy's routes with handlers echoing the
+// request body and the negotiated version.
+func newTestRouter(n *Negotiator) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	})
+	r.Use(n.Middleware(r))
+	echo := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Version", FromContext(req.Context()))
+		io.Copy(w, req.Body)
+	}
+	r.Post("/v1/apps/{appID}/services", echo)
+	r.Post("/v1/nodes/heartbeat", echo)
+	r.Get("/v1/apps/{appID}", echo)
+	return r
+}
+
+func serve(r http.Handler, method, target, version, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(ClientHeader, "narvanactl/1.8.0")
+	if version != "" {
+		req.Header.Set(Header, version)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNegotiation(t *testing.T) {
+	n := NewNegotiator(&usageStore{}, DefaultPolicy(), DefaultConfig(), nil)
+	r := newTestRouter(n)
+	body := `{"name":"web","health_check":{"path":"/health","interval":"1m","timeout":"2500ms"}}`
+
+	// Requests without a version are served as 1.0 and shimmed
+	rec := serve(r, http.MethodPost, "/v1/apps/app-1/services", "", body)
+	if rec.Header().Get(Header) != V1_0 || rec.Header().Get("X-Version") != V1_0 {
+		t.Errorf("version = %q, want %s", rec.Header().Get(Header), V1_0)
+	}
+	var got struct {
+		HealthCheck models.HealthCheckConfig `json:"health_check"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding shimmed body: %v", err)
+	}
+	if got.HealthCheck.IntervalSeconds != 60 || got.HealthCheck.TimeoutSeconds != 3 || got.HealthCheck.Path != "/health" {
+		t.Errorf("shimmed health check = %+v, want 60s interval and 3s timeout", got.HealthCheck)
+	}
+
+	// The current version's requests reach the handler as sent
+	rec = serve(r, http.MethodPost, "/v1/apps/app-1/services", Current, body)
+	if rec.Header().Get(Header) != Current || rec.Body.String() != body {
+		t.Errorf("version %s: body = %s, want it unchanged", rec.Header().Get(Header), rec.Body.String())
+	}
+
+	rec = serve(r, http.MethodGet, "/v1/apps/app-1", "2.0", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), ErrCodeUnsupportedVersion) {
+		t.Errorf("unsupported version: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeprecatedRoutes(t *testing.T) {
+	st := &usageStore{}
+	n := NewNegotiator(st, DefaultPolicy(), DefaultConfig(), nil)
+	now := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	r := newTestRouter(n)
+
+	for range 3 {
+		rec := serve(r, http.MethodPost, "/v1/nodes/heartbeat", Current, `{}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 before the sunset", rec.Code)
+		}
+		if rec.Header().Get(DeprecationHeader) != "@1792195200" || rec.Header().Get(SunsetHeader) != "Sat, 17 Apr 2027 00:00:00 GMT" {
+			t.Errorf("deprecation headers = %v", rec.Header())
+		}
+		if link := rec.Header().Get("Link"); link != `</v1/nodes/{nodeID}/heartbeat>; rel="successor-version"` {
+			t.Errorf("Link = %q", link)
+		}
+	}
+	if rec := serve(r, http.MethodGet, "/v1/apps/app-1", Current, ""); rec.Header().Get(DeprecationHeader) != "" {
+		t.Errorf("a current route is marked deprecated: %v", rec.Header())
+	}
+
+	n.Flush(context.Background())
+	if len(st.usage) != 1 {
+		t.Fatalf("usage = %+v, want one client", st.usage)
+	}
+	u := st.usage[0]
+	if u.Route != "POST /v1/nodes/heartbeat" || u.Version != Current || u.UserID != "user-1" || u.Client != "narvanactl/1.8.0" || u.Count != 3 {
+		t.Errorf("usage = %+v", u)
+	}
+	n.Flush(context.Background())
+	if len(st.usage) != 1 {
+		t.Errorf("usage was recorded twice: %+v", st.usage)
+	}
+
+	now = time.Date(2027, 4, 17, 0, 0, 0, 0, time.UTC)
+	rec := serve(r, http.MethodPost, "/v1/nodes/heartbeat", "", `{}`)
+	if rec.Code != http.StatusGone || !strings.Contains(rec.Body.String(), "POST /v1/nodes/{nodeID}/heartbeat") {
+		t.Errorf("after the sunset: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeprecatedVersions(t *testing.T) {
+	deprecated := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sunset := deprecated.AddDate(0, 6, 0)
+	policy := Policy{
+		Versions: []Version{{Name: "1.0", Deprecated: &deprecated, Sunset: &sunset}, {Name: "1.1"}},
+		Shims: []Shim{{
+			Route: "GET /v1/apps/{appID}",
+			Until: "1.1",
+			Response: func(body any) any {
+				return map[string]any{"app": body}
+			},
+		}},
+	}
+	st := &usageStore{}
+	n := NewNegotiator(st, policy, DefaultConfig(), nil)
+	now := deprecated.AddDate(0, 1, 0)
+	n.now = func() time.Time { return now }
+	r := newTestRouter(n)
+
+	rec := serve(r, http.MethodGet, "/v1/apps/app-1", "", `{"id":"app-1"}`)
+	if rec.Header().Get(DeprecationHeader) == "" || rec.Header().Get(SunsetHeader) == "" {
+		t.Errorf("deprecated version headers = %v", rec.Header())
+	}
+	if strings.TrimSpace(rec.Body.String()) != `{"app":{"id":"app-1"}}` {
+		t.Errorf("shimmed response = %s", rec.Body.String())
+	}
+	rec = serve(r, http.MethodGet, "/v1/apps/app-1", "1.1", `{"id":"app-1"}`)
+	if rec.Header().Get(DeprecationHeader) != "" || rec.Body.String() != `{"id":"app-1"}` {
+		t.Errorf("current version: headers = %v, body = %s", rec.Header(), rec.Body.String())
+	}
+
+	n.Flush(context.Background())
+	if len(st.usage) != 1 || st.usage[0].Version != "1.0" || st.usage[0].Route != "GET /v1/apps/{appID}" {
+		t.Errorf("usage = %+v, want the deprecated version's request", st.usage)
+	}
+
+	now = sunset
+	if rec := serve(r, http.MethodGet, "/v1/apps/app-1", "1.0", ""); rec.Code != http.StatusGone {
+		t.Errorf("sunset version: status = %d, want 410", rec.Code)
+	}
+}
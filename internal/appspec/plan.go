@@ -0,0 +1,255 @@
+package appspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/validation"
+)
+
+// Change actions and kinds.
+const (
+	ActionAdd    = "add"
+	ActionUpdate = "update"
+	ActionRemove = "remove"
+
+	KindService = "service"
+	KindEnv     = "env"
+	KindDomain  = "domain"
+	KindSecret  = "secret"
+)
+
+// Change is a single difference between an app and its spec.
+type Change = models.AppSpecChange
+
+// Options control how an app is converged to a spec.
+type Options struct {
+	// Prune removes the services the spec does not list. Services
+	// instantiated from service templates are never removed.
+	Prune bool
+	// Resources are given to services that declare none.
+	Resources *models.ResourceSpec
+}
+
+// Plan is an app converged to a spec: its services, env vars and domains
+// once the changes are applied.
+type Plan struct {
+	Services []models.ServiceConfig
+	Env      map[string]string
+	Domains  map[string]string // Domain to the service it routes to
+	Changes  []Change          // Services, then env vars, then domains, each sorted by name
+}
+
+// Converge plans the changes that turn app, with its domains, into the
+// validated spec. Services keep their order; new ones are added in the
+// order the spec lists them. Settings managed outside the spec, the scaling
+// schedule and replica override of services, are kept.
+func Converge(app *models.App, domains []*models.Domain, spec *Spec, opts Options) (*Plan, error) {
+	if spec.App != "" && spec.App != app.Name {
+		return nil, fmt.Errorf("%w: spec is for app %q, not %q", ErrInvalidSpec, spec.App, app.Name)
+	}
+
+	declared := make(map[string]*Service, len(spec.Services))
+	for i := range spec.Services {
+		declared[spec.Services[i].Name] = &spec.Services[i]
+	}
+
+	plan := &Plan{Domains: make(map[string]string)}
+	kept := make(map[string]bool, len(app.Services))
+	for _, current := range app.Services {
+		svc, ok := declared[current.Name]
+		switch {
+		case ok && current.Template != nil:
+			return nil, fmt.Errorf("%w: service %s is an instance of template %s and cannot be declared", ErrInvalidSpec, current.Name, current.Template.Name)
+		case ok:
+			cfg, err := svc.Config(opts.Resources)
+			if err != nil {
+				return nil, fmt.Errorf("%w: service %s: %v", ErrInvalidSpec, svc.Name, err)
+			}
+			cfg.ScalingSchedule = current.ScalingSchedule
+			cfg.ReplicaOverride = current.ReplicaOverride
+			cfg.Autoscaling = current.Autoscaling
+			if current.ScalingSchedule != nil || current.Autoscaling != nil {
+				// The scaling cron and the autoscaler set the replicas of
+				// scheduled and autoscaled services
+				cfg.Replicas = current.Replicas
+			}
+			plan.Services = append(plan.Services, cfg)
+		case opts.Prune && current.Template == nil:
+			continue
+		default:
+			plan.Services = append(plan.Services, current)
+		}
+		kept[current.Name] = true
+	}
+	for i := range spec.Services {
+		svc := &spec.Services[i]
+		if kept[svc.Name] {
+			continue
+		}
+		cfg, err := svc.Config(opts.Resources)
+		if err != nil {
+			return nil, fmt.Errorf("%w: service %s: %v", ErrInvalidSpec, svc.Name, err)
+		}
+		plan.Services = append(plan.Services, cfg)
+	}
+	if err := validateDependencies(plan.Services); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+
+	if len(spec.Env) > 0 {
+		plan.Env = maps.Clone(map[string]string(spec.Env))
+	}
+
+	// Services the spec declares own their domains; others keep theirs
+	current := make(map[string]string, len(domains))
+	for _, d := range domains {
+		current[d.Domain] = d.Service
+		if _, ok := declared[d.Service]; !ok && kept[d.Service] {
+			plan.Domains[d.Domain] = d.Service
+		}
+	}
+	for _, svc := range spec.Services {
+		for _, domain := range svc.Domains {
+			if other, ok := plan.Domains[domain]; ok {
+				return nil, fmt.Errorf("%w: domain %s already routes to service %s", ErrInvalidSpec, domain, other)
+			}
+			plan.Domains[domain] = svc.Name
+		}
+	}
+
+	plan.Changes = append(plan.Changes, diffServices(app.Services, plan.Services)...)
+	plan.Changes = append(plan.Changes, diffEnv(app.EnvVars, plan.Env)...)
+	plan.Changes = append(plan.Changes, diffDomains(current, plan.Domains)...)
+	return plan, nil
+}
+
+// validateDependencies checks that every dependency exists and that the
+// services' dependencies have no cycles.
+func validateDependencies(services []models.ServiceConfig) error {
+	names := make(map[string]bool, len(services))
+	for _, svc := range services {
+		names[svc.Name] = true
+	}
+	validator := validation.NewDependencyValidator(slog.Default())
+	for _, svc := range services {
+		for _, dep := range svc.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("service %s: dependency '%s' not found in app", svc.Name, dep)
+			}
+		}
+		if err := validator.ValidateDependencies(services, svc.Name, svc.DependsOn); err != nil {
+			return fmt.Errorf("service %s: %v", svc.Name, err)
+		}
+	}
+	return nil
+}
+
+// diffServices compares services by name, listing the changed settings of
+// services in both.
+func diffServices(current, desired []models.ServiceConfig) []Change {
+	from := make(map[string]models.ServiceConfig, len(current))
+	for _, svc := range current {
+		from[svc.Name] = svc
+	}
+	to := make(map[string]models.ServiceConfig, len(desired))
+	for _, svc := range desired {
+		to[svc.Name] = svc
+	}
+
+	var changes []Change
+	for _, name := range sortedKeys(from, to) {
+		a, hadA := from[name]
+		b, hasB := to[name]
+		switch {
+		case !hadA:
+			changes = append(changes, Change{Action: ActionAdd, Kind: KindService, Name: name})
+		case !hasB:
+			changes = append(changes, Change{Action: ActionRemove, Kind: KindService, Name: name})
+		default:
+			if fields := changedFields(a, b); len(fields) > 0 {
+				changes = append(changes, Change{Action: ActionUpdate, Kind: KindService, Name: name, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// changedFields returns the JSON names of the settings that differ between
+// two services, sorted.
+func changedFields(a, b models.ServiceConfig) []string {
+	fieldsA, fieldsB := jsonFields(a), jsonFields(b)
+	var fields []string
+	for _, name := range sortedKeys(fieldsA, fieldsB) {
+		if !bytes.Equal(fieldsA[name], fieldsB[name]) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// jsonFields encodes a service's settings by their JSON names. The stored
+// version is not a setting and is left out.
+func jsonFields(svc models.ServiceConfig) map[string]json.RawMessage {
+	svc.Version = 0
+	var fields map[string]json.RawMessage
+	data, _ := json.Marshal(svc)
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// diffEnv compares env vars by name.
+func diffEnv(current, desired map[string]string) []Change {
+	var changes []Change
+	for _, key := range sortedKeys(current, desired) {
+		a, hadA := current[key]
+		b, hasB := desired[key]
+		switch {
+		case !hadA:
+			changes = append(changes, Change{Action: ActionAdd, Kind: KindEnv, Name: key})
+		case !hasB:
+			changes = append(changes, Change{Action: ActionRemove, Kind: KindEnv, Name: key})
+		case a != b:
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindEnv, Name: key})
+		}
+	}
+	return changes
+}
+
+// diffDomains compares domains and the services they route to.
+func diffDomains(current, desired map[string]string) []Change {
+	var changes []Change
+	for _, domain := range sortedKeys(current, desired) {
+		a, hadA := current[domain]
+		b, hasB := desired[domain]
+		switch {
+		case !hadA:
+			changes = append(changes, Change{Action: ActionAdd, Kind: KindDomain, Name: domain, To: b})
+		case !hasB:
+			changes = append(changes, Change{Action: ActionRemove, Kind: KindDomain, Name: domain, From: a})
+		case a != b:
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindDomain, Name: domain, From: a, To: b})
+		}
+	}
+	return changes
+}
+
+// sortedKeys returns the union of the maps' keys in sorted order.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
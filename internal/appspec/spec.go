@@ -0,0 +1,376 @@
+// Package appspec reads app specs: declarative descriptions of an app's
+// services, env vars and domains, kept as narvana.yaml at the root of its
+// repository. It plans the changes that converge an app to its spec, which
+// the API applies or, for dry runs in CI, only reports.
+package appspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/validation"
+)
+
+// Version is the current version of the app spec format.
+const Version = 1
+
+// FileName is where an app's spec is kept in its repository.
+const FileName = "narvana.yaml"
+
+// ErrInvalidSpec is returned for specs that cannot be parsed or applied.
+var ErrInvalidSpec = errors.New("invalid app spec")
+
+// Spec is an app's declared configuration. Env vars and the services it
+// lists are converged exactly: omitted settings take the defaults of new
+// services, and omitted env vars and domains are removed.
+type Spec struct {
+	Version  int       `json:"version"`
+	App      string    `json:"app,omitempty"` // App name, checked on apply when set
+	Env      Env       `json:"env,omitempty"` // Inherited by every service
+	Services []Service `json:"services"`
+}
+
+// Service is a service declared in a spec. Its fields are those of the
+// service API, with env and domains in place of env_vars and the domain API.
+type Service struct {
+	Name string             `json:"name"`
+	Type models.ServiceType `json:"type,omitempty"`
+	Cron *models.CronConfig `json:"cron,omitempty"`
+
+	// Source: one of git_repo, flake_uri or database
+	GitRepo     string                 `json:"git_repo,omitempty"`
+	GitRef      string                 `json:"git_ref,omitempty"`
+	FlakeOutput string                 `json:"flake_output,omitempty"`
+	BuildPath   string                 `json:"build_path,omitempty"`
+	FlakeURI    string                 `json:"flake_uri,omitempty"`
+	Database    *models.DatabaseConfig `json:"database,omitempty"`
+
+	BuildStrategy models.BuildStrategy `json:"build_strategy,omitempty"`
+	BuildConfig   *models.BuildConfig  `json:"build_config,omitempty"`
+
+	Resources   *models.ResourceSpec      `json:"resources,omitempty"`
+	Replicas    int                       `json:"replicas,omitempty"`
+	Ports       []models.PortMapping      `json:"ports,omitempty"`
+	HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
+	Env         Env                       `json:"env,omitempty"`
+	DependsOn   []string                  `json:"depends_on,omitempty"`
+	Egress      *models.EgressPolicy      `json:"egress,omitempty"`
+	NodePool    string                    `json:"node_pool,omitempty"`
+	Runtime     models.ServiceRuntime     `json:"runtime,omitempty"`
+	SmokeTests  []models.SmokeTest        `json:"smoke_tests,omitempty"`
+	Canary      *models.CanaryConfig      `json:"canary,omitempty"`
+	OpenAPIURL  string                    `json:"openapi_url,omitempty"`
+
+	// Domains route to the service
+	Domains []string `json:"domains,omitempty"`
+}
+
+// Env is a set of env vars. Numbers and booleans are accepted as values, so
+// that "PORT: 8080" needs no quotes.
+type Env map[string]string
+
+// UnmarshalJSON decodes env vars with scalar values.
+func (e *Env) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return fmt.Errorf("env must map names to values")
+	}
+	env := make(Env, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			env[key] = v
+		case json.Number:
+			env[key] = v.String()
+		case bool:
+			env[key] = fmt.Sprint(v)
+		case nil:
+			env[key] = ""
+		default:
+			return fmt.Errorf("env var %s must be a string, number or boolean", key)
+		}
+	}
+	*e = env
+	return nil
+}
+
+// Parse decodes a YAML (or JSON) spec. Unknown fields are rejected so that
+// typos do not silently drop configuration.
+func Parse(data []byte) (*Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("%w: spec is empty", ErrInvalidSpec)
+	}
+
+	// Decode through JSON to reuse the JSON field names of the API's models
+	encoded, err := json.Marshal(stringKeys(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	return &s, nil
+}
+
+// stringKeys converts the maps YAML decodes with non-string keys, such as
+// numeric env var names, to maps with string keys that JSON can encode.
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			v[k] = stringKeys(val)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = stringKeys(val)
+		}
+		return m
+	case []any:
+		for i, val := range v {
+			v[i] = stringKeys(val)
+		}
+		return v
+	}
+	return v
+}
+
+// FromApp returns the spec of an app's current services, env vars and
+// domains, which the app converges to without changes. Services instantiated
+// from service templates are left out.
+func FromApp(app *models.App, domains []*models.Domain) *Spec {
+	spec := &Spec{Version: Version, App: app.Name, Services: []Service{}}
+	if len(app.EnvVars) > 0 {
+		spec.Env = Env(maps.Clone(app.EnvVars))
+	}
+
+	routed := make(map[string][]string)
+	for _, d := range domains {
+		routed[d.Service] = append(routed[d.Service], d.Domain)
+	}
+	for _, svc := range app.Services {
+		if svc.Template != nil {
+			continue
+		}
+		s := Service{
+			Name:          svc.Name,
+			Type:          svc.Type,
+			Cron:          svc.Cron,
+			GitRepo:       svc.GitRepo,
+			GitRef:        svc.GitRef,
+			FlakeOutput:   svc.FlakeOutput,
+			BuildPath:     svc.BuildPath,
+			FlakeURI:      svc.FlakeURI,
+			Database:      svc.Database,
+			BuildStrategy: svc.BuildStrategy,
+			BuildConfig:   svc.BuildConfig,
+			Resources:     svc.Resources,
+			Replicas:      svc.Replicas,
+			Ports:         svc.Ports,
+			HealthCheck:   svc.HealthCheck,
+			DependsOn:     svc.DependsOn,
+			Egress:        svc.Egress,
+			NodePool:      svc.NodePool,
+			Runtime:       svc.Runtime,
+			SmokeTests:    svc.SmokeTests,
+			Canary:        svc.Canary,
+			OpenAPIURL:    svc.OpenAPIURL,
+			Domains:       routed[svc.Name],
+		}
+		if len(svc.EnvVars) > 0 {
+			s.Env = Env(maps.Clone(svc.EnvVars))
+		}
+		sort.Strings(s.Domains)
+		spec.Services = append(spec.Services, s)
+	}
+	return spec
+}
+
+// Encode returns the spec as YAML, with fields in the order they are declared.
+func (s *Spec) Encode() ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML in flow style; decoding it as a node keeps the field
+	// order, and clearing the styles encodes it in block style
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the style of a node and its children.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// Validate checks the spec's version, names, env vars and domains, and
+// normalizes domains to lowercase. Service settings are checked when they
+// are planned, with the defaults of new services applied.
+func (s *Spec) Validate() error {
+	if s.Version != Version {
+		return fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidSpec, s.Version, Version)
+	}
+	if err := validateEnv(s.Env); err != nil {
+		return fmt.Errorf("%w: env: %v", ErrInvalidSpec, err)
+	}
+
+	names := make(map[string]bool, len(s.Services))
+	domains := make(map[string]string)
+	for i := range s.Services {
+		svc := &s.Services[i]
+		if err := validation.ValidateServiceName(svc.Name); err != nil {
+			return fmt.Errorf("%w: service %d: %v", ErrInvalidSpec, i+1, err)
+		}
+		if names[svc.Name] {
+			return fmt.Errorf("%w: duplicate service %q", ErrInvalidSpec, svc.Name)
+		}
+		names[svc.Name] = true
+		if err := validateEnv(svc.Env); err != nil {
+			return fmt.Errorf("%w: service %s: env: %v", ErrInvalidSpec, svc.Name, err)
+		}
+		for j, domain := range svc.Domains {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if other, ok := domains[domain]; ok {
+				return fmt.Errorf("%w: domain %s is declared by both %s and %s", ErrInvalidSpec, domain, other, svc.Name)
+			}
+			domains[domain] = svc.Name
+			svc.Domains[j] = domain
+		}
+	}
+	return nil
+}
+
+// validateEnv checks env var names and values.
+func validateEnv(env Env) error {
+	for key, value := range env {
+		if err := validation.ValidateEnvKey(key); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if err := validation.ValidateEnvValue(value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Config returns the service's configuration with the defaults of a new
+// service applied, using resources when none are declared, and validates it.
+func (s *Service) Config(resources *models.ResourceSpec) (models.ServiceConfig, error) {
+	cfg := models.ServiceConfig{
+		Name:          s.Name,
+		Type:          s.Type,
+		Cron:          s.Cron,
+		GitRepo:       s.GitRepo,
+		GitRef:        s.GitRef,
+		FlakeOutput:   s.FlakeOutput,
+		BuildPath:     s.BuildPath,
+		FlakeURI:      s.FlakeURI,
+		Database:      s.Database,
+		BuildStrategy: s.BuildStrategy,
+		BuildConfig:   s.BuildConfig,
+		Resources:     s.Resources,
+		Replicas:      s.Replicas,
+		Ports:         s.Ports,
+		HealthCheck:   s.HealthCheck,
+		DependsOn:     s.DependsOn,
+		Egress:        s.Egress,
+		NodePool:      s.NodePool,
+		Runtime:       s.Runtime,
+		SmokeTests:    s.SmokeTests,
+		Canary:        s.Canary,
+		OpenAPIURL:    s.OpenAPIURL,
+	}
+	if len(s.Env) > 0 {
+		cfg.EnvVars = map[string]string(s.Env)
+	}
+
+	switch {
+	case cfg.Database != nil:
+		cfg.SourceType = models.SourceTypeDatabase
+	case cfg.FlakeURI != "":
+		cfg.SourceType = models.SourceTypeFlake
+	default:
+		cfg.SourceType = models.SourceTypeGit
+	}
+
+	if cfg.Resources == nil {
+		res := *resources
+		cfg.Resources = &res
+	} else if err := validation.ValidateResourceSpec(cfg.Resources); err != nil {
+		return cfg, err
+	}
+	if err := validation.ValidateEgressPolicy(cfg.Egress); err != nil {
+		return cfg, err
+	}
+	if cfg.Replicas <= 0 {
+		cfg.Replicas = 1
+	}
+
+	if cfg.SourceType == models.SourceTypeDatabase {
+		if err := validation.ValidateDatabaseConfig(cfg.Database); err != nil {
+			return cfg, err
+		}
+		dbType := databases.DatabaseType(cfg.Database.Type)
+		if cfg.Database.Version == "" {
+			cfg.Database.Version = validation.GetDefaultVersion(cfg.Database.Type)
+		}
+		if port := databases.GetDefaultPort(dbType); port > 0 && len(cfg.Ports) == 0 {
+			cfg.Ports = []models.PortMapping{{ContainerPort: port, Protocol: "tcp"}}
+		}
+		if cfg.HealthCheck == nil {
+			cfg.HealthCheck = databases.HealthCheck(dbType)
+		}
+	}
+	if len(cfg.Ports) == 0 && !cfg.IsCron() {
+		cfg.Ports = []models.PortMapping{{ContainerPort: 8080, Protocol: "tcp"}}
+	}
+
+	if cfg.BuildStrategy == "" {
+		if cfg.SourceType == models.SourceTypeDatabase {
+			cfg.BuildStrategy = models.BuildStrategyAutoDatabase
+		} else {
+			cfg.BuildStrategy = models.BuildStrategyFlake
+		}
+	}
+	if !cfg.BuildStrategy.IsValid() {
+		return cfg, fmt.Errorf("invalid build_strategy %q", cfg.BuildStrategy)
+	}
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,209 @@
+package appspec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+const testSpec = `
+version: 1
+app: shop
+env:
+  LOG_LEVEL: info
+  WORKERS: 4
+services:
+  - name: web
+    git_repo: github.com/acme/shop
+    replicas: 2
+    ports:
+      - container_port: 3000
+    env:
+      DEBUG: false
+    depends_on: [db]
+    domains: [Shop.Example.com]
+  - name: db
+    database:
+      type: postgres
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Env["WORKERS"] != "4" || spec.Services[0].Env["DEBUG"] != "false" {
+		t.Errorf("scalar env values = %v, %v", spec.Env, spec.Services[0].Env)
+	}
+	if spec.Services[0].Domains[0] != "shop.example.com" {
+		t.Errorf("domain = %q, want it lowercased", spec.Services[0].Domains[0])
+	}
+
+	for name, data := range map[string]string{
+		"empty":         "",
+		"unknown field": "version: 1\nservices:\n  - name: web\n    replica: 2\n",
+		"not a map":     "- web\n",
+	} {
+		if _, err := Parse([]byte(data)); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("%s: err = %v, want ErrInvalidSpec", name, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for name, spec := range map[string]*Spec{
+		"version":   {Version: 2},
+		"name":      {Version: 1, Services: []Service{{Name: "Web"}}},
+		"duplicate": {Version: 1, Services: []Service{{Name: "web"}, {Name: "web"}}},
+		"env":       {Version: 1, Env: Env{"1BAD": "x"}},
+		"domain": {Version: 1, Services: []Service{
+			{Name: "web", Domains: []string{"a.example.com"}},
+			{Name: "api", Domains: []string{"A.example.com"}},
+		}},
+	} {
+		if err := spec.Validate(); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("%s: err = %v, want ErrInvalidSpec", name, err)
+		}
+	}
+}
+
+func TestConverge(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Resources: models.DefaultResourceSpec()}
+
+	web, err := spec.Services[0].Config(opts.Resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web.Replicas = 1
+	web.ScalingSchedule = &models.ScalingSchedule{}
+	web.ReplicaOverride = nil
+	worker := models.ServiceConfig{Name: "worker", GitRepo: "github.com/acme/shop"}
+	cache := models.ServiceConfig{Name: "cache", Template: &models.ServiceTemplateRef{Name: "redis", Instance: "cache"}}
+	app := &models.App{
+		Name:     "shop",
+		Services: []models.ServiceConfig{worker, web, cache},
+		EnvVars:  map[string]string{"LOG_LEVEL": "debug", "OLD": "1"},
+	}
+	domains := []*models.Domain{
+		{Service: "web", Domain: "old.example.com"},
+		{Service: "worker", Domain: "worker.example.com"},
+	}
+
+	plan, err := Converge(app, domains, spec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, svc := range plan.Services {
+		names = append(names, svc.Name)
+	}
+	if want := []string{"worker", "web", "cache", "db"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("services = %v, want %v", names, want)
+	}
+	if got := plan.Services[1]; got.Replicas != 1 || got.ScalingSchedule == nil {
+		t.Errorf("web replicas = %d, schedule = %v; want the scheduled replicas kept", got.Replicas, got.ScalingSchedule)
+	}
+	if got := plan.Services[3]; got.Ports[0].ContainerPort != 5432 || got.BuildStrategy != models.BuildStrategyAutoDatabase {
+		t.Errorf("db = %+v, want database defaults", got)
+	}
+
+	want := []Change{
+		{Action: ActionAdd, Kind: KindService, Name: "db"},
+		{Action: ActionUpdate, Kind: KindEnv, Name: "LOG_LEVEL"},
+		{Action: ActionRemove, Kind: KindEnv, Name: "OLD"},
+		{Action: ActionAdd, Kind: KindEnv, Name: "WORKERS"},
+		{Action: ActionRemove, Kind: KindDomain, Name: "old.example.com", From: "web"},
+		{Action: ActionAdd, Kind: KindDomain, Name: "shop.example.com", To: "web"},
+	}
+	if !reflect.DeepEqual(plan.Changes, want) {
+		t.Errorf("changes = %+v\nwant %+v", plan.Changes, want)
+	}
+
+	// Pruning removes undeclared services and their domains, but not
+	// template instances
+	opts.Prune = true
+	if plan, err = Converge(app, domains, spec, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Services) != 3 || !reflect.DeepEqual(plan.Changes[1], Change{Action: ActionRemove, Kind: KindService, Name: "worker"}) {
+		t.Errorf("pruned changes = %+v", plan.Changes)
+	}
+	if _, ok := plan.Domains["worker.example.com"]; ok {
+		t.Error("domain of a pruned service kept")
+	}
+}
+
+func TestConvergeErrors(t *testing.T) {
+	opts := Options{Resources: models.DefaultResourceSpec()}
+	app := &models.App{
+		Name: "shop",
+		Services: []models.ServiceConfig{
+			{Name: "cache", Template: &models.ServiceTemplateRef{Name: "redis", Instance: "cache"}},
+		},
+	}
+	for name, spec := range map[string]*Spec{
+		"other app":   {Version: 1, App: "blog"},
+		"template":    {Version: 1, Services: []Service{{Name: "cache", GitRepo: "github.com/acme/cache"}}},
+		"missing dep": {Version: 1, Services: []Service{{Name: "web", GitRepo: "github.com/acme/shop", DependsOn: []string{"api"}}}},
+		"cycle": {Version: 1, Services: []Service{
+			{Name: "web", GitRepo: "github.com/acme/shop", DependsOn: []string{"api"}},
+			{Name: "api", GitRepo: "github.com/acme/shop", DependsOn: []string{"web"}},
+		}},
+		"invalid service": {Version: 1, Services: []Service{{Name: "web"}}},
+	} {
+		if _, err := Converge(app, nil, spec, opts); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("%s: err = %v, want ErrInvalidSpec", name, err)
+		}
+	}
+}
+
+func TestFromAppRoundTrip(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Resources: models.DefaultResourceSpec()}
+	plan, err := Converge(&models.App{Name: "shop"}, nil, spec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &models.App{Name: "shop", Services: plan.Services, EnvVars: plan.Env}
+	var domains []*models.Domain
+	for domain, service := range plan.Domains {
+		domains = append(domains, &models.Domain{Domain: domain, Service: service})
+	}
+
+	data, err := FromApp(app, domains).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := Parse(data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if err := exported.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = Converge(app, domains, exported, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("exported spec changes the app: %+v\n%s", plan.Changes, data)
+	}
+}
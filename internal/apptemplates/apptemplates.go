@@ -0,0 +1,197 @@
+// Package apptemplates is the catalog of app templates: starters for new
+// apps, such as a Go API backed by Postgres, bundled with the control plane
+// or added by instance admins. A template renders into the app spec and the
+// secrets of a new app.
+package apptemplates
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+//go:embed bundled/*.yaml
+var bundledFiles embed.FS
+
+// bundled holds the bundled templates by name.
+var bundled = mustLoadBundled()
+
+// mustLoadBundled decodes and checks the bundled templates, panicking if one
+// is invalid.
+func mustLoadBundled() map[string]*models.AppTemplate {
+	paths, err := fs.Glob(bundledFiles, "bundled/*.yaml")
+	if err != nil {
+		panic(err)
+	}
+	templates := make(map[string]*models.AppTemplate, len(paths))
+	for _, path := range paths {
+		data, err := bundledFiles.ReadFile(path)
+		if err != nil {
+			panic(err)
+		}
+		var t models.AppTemplate
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&t); err != nil {
+			panic(fmt.Sprintf("apptemplates: decoding %s: %v", path, err))
+		}
+		if err := Check(&t); err != nil {
+			panic(fmt.Sprintf("apptemplates: %s: %v", path, err))
+		}
+		t.Builtin = true
+		templates[t.Name] = &t
+	}
+	return templates
+}
+
+// Builtin returns a copy of the bundled template with the given name, or nil
+// if there is none.
+func Builtin(name string) *models.AppTemplate {
+	t, ok := bundled[name]
+	if !ok {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// List returns the bundled templates and those in the store, ordered by name.
+func List(ctx context.Context, st store.Store) ([]*models.AppTemplate, error) {
+	stored, err := st.AppTemplates().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing app templates: %w", err)
+	}
+	templates := make([]*models.AppTemplate, 0, len(bundled)+len(stored))
+	for name := range bundled {
+		templates = append(templates, Builtin(name))
+	}
+	templates = append(templates, stored...)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Get returns the bundled or stored template with the given name, or nil if
+// there is none.
+func Get(ctx context.Context, st store.Store, name string) (*models.AppTemplate, error) {
+	if t := Builtin(name); t != nil {
+		return t, nil
+	}
+	t, err := st.AppTemplates().Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting app template: %w", err)
+	}
+	return t, nil
+}
+
+// Check validates a template and that its spec is a YAML mapping.
+func Check(t *models.AppTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(t.Spec), &doc); err != nil {
+		return &models.ValidationError{Field: "spec", Message: fmt.Sprintf("spec is not valid YAML: %v", err)}
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return &models.ValidationError{Field: "spec", Message: "spec must be a YAML mapping"}
+	}
+	return nil
+}
+
+// Rendered is a template rendered for a new app.
+type Rendered struct {
+	// Spec is the validated app spec.
+	Spec *appspec.Spec
+	// Data is the rendered narvana.yaml, kept as the app's manifest.
+	Data []byte
+	// Secrets are the secret values by key.
+	Secrets map[string]string
+}
+
+// Render renders a template for an app named app. Params set the template's
+// variables; unset variables take their default. Variables are substituted
+// into the string values of the spec, so a value need not be quoted, and
+// one that reads as a number, such as replicas, may set a number field.
+// Errors for invalid params are *models.ValidationError; errors for specs
+// that do not render valid wrap appspec.ErrInvalidSpec.
+func Render(t *models.AppTemplate, app string, params map[string]string) (*Rendered, error) {
+	values, err := t.Values(app, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(t.Spec), &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", appspec.ErrInvalidSpec, err)
+	}
+	expand(&doc, values)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", appspec.ErrInvalidSpec, err)
+	}
+	data := buf.Bytes()
+
+	spec, err := appspec.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string, len(t.Secrets))
+	for _, secret := range t.Secrets {
+		if secret.Value == "" {
+			value, err := randomSecret()
+			if err != nil {
+				return nil, fmt.Errorf("generating secret %s: %w", secret.Key, err)
+			}
+			secrets[secret.Key] = value
+			continue
+		}
+		secrets[secret.Key] = models.ExpandTemplateReferences(secret.Value, values)
+	}
+	return &Rendered{Spec: spec, Data: data, Secrets: secrets}, nil
+}
+
+// expand substitutes variables into the scalars of a YAML tree. Unquoted
+// scalars that reference variables have their type resolved again from the
+// substituted value, unless it is empty.
+func expand(node *yaml.Node, values map[string]string) {
+	if node.Kind == yaml.ScalarNode {
+		expanded := models.ExpandTemplateReferences(node.Value, values)
+		if expanded == node.Value {
+			return
+		}
+		node.Value = expanded
+		if node.Style == 0 && expanded != "" {
+			node.Tag = ""
+		}
+		return
+	}
+	for _, child := range node.Content {
+		expand(child, values)
+	}
+}
+
+// randomSecret returns 32 random bytes, hex encoded.
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,130 @@
+package apptemplates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestBundledTemplatesRender(t *testing.T) {
+	if len(bundled) == 0 {
+		t.Fatal("no bundled templates")
+	}
+	for name, tmpl := range bundled {
+		if _, err := Render(tmpl, "shop", map[string]string{"git_repo": "github.com/acme/shop"}); err != nil {
+			t.Errorf("%s: Render() = %v", name, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := Builtin("postgres-go-api")
+	if tmpl == nil {
+		t.Fatal("postgres-go-api is not bundled")
+	}
+
+	r, err := Render(tmpl, "shop", map[string]string{"git_repo": "github.com/acme/shop", "replicas": "3"})
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	if r.Spec.App != "shop" || len(r.Spec.Services) != 2 {
+		t.Fatalf("spec = %+v", r.Spec)
+	}
+	api := r.Spec.Services[0]
+	if api.GitRepo != "github.com/acme/shop" || api.GitRef != "main" || api.Replicas != 3 {
+		t.Errorf("api = %+v", api)
+	}
+	if !strings.Contains(string(r.Data), "git_repo: github.com/acme/shop") {
+		t.Errorf("rendered spec:\n%s", r.Data)
+	}
+	if len(r.Secrets["SESSION_SECRET"]) != 64 {
+		t.Errorf("generated secret = %q", r.Secrets["SESSION_SECRET"])
+	}
+
+	// Each app gets its own generated secrets
+	again, _ := Render(tmpl, "shop", map[string]string{"git_repo": "github.com/acme/shop"})
+	if again.Secrets["SESSION_SECRET"] == r.Secrets["SESSION_SECRET"] {
+		t.Error("generated secret repeated")
+	}
+
+	var verr *models.ValidationError
+	if _, err := Render(tmpl, "shop", nil); !errors.As(err, &verr) {
+		t.Errorf("missing required param: err = %v", err)
+	}
+	if _, err := Render(tmpl, "shop", map[string]string{"git_repo": "x", "region": "eu"}); !errors.As(err, &verr) {
+		t.Errorf("unknown param: err = %v", err)
+	}
+	if _, err := Render(tmpl, "shop", map[string]string{"git_repo": "github.com/acme/shop", "replicas": "many"}); !errors.Is(err, appspec.ErrInvalidSpec) {
+		t.Errorf("non-numeric replicas: err = %v", err)
+	}
+}
+
+func TestRenderQuotesSubstitutedValues(t *testing.T) {
+	tmpl := &models.AppTemplate{
+		Name:      "worker",
+		Title:     "Worker",
+		Variables: []models.ServiceTemplateVariable{{Name: "greeting"}, {Name: "token"}},
+		Secrets:   []models.AppTemplateSecret{{Key: "API_TOKEN", Value: "tok-${token}"}},
+		Spec: `version: 1
+services:
+  - name: worker
+    git_repo: github.com/acme/worker
+    env:
+      GREETING: ${greeting}
+      QUOTED: "${greeting}"
+`,
+	}
+	if err := Check(tmpl); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+
+	r, err := Render(tmpl, "shop", map[string]string{"greeting": "hello: world # not a comment", "token": "abc"})
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	env := r.Spec.Services[0].Env
+	if env["GREETING"] != "hello: world # not a comment" || env["QUOTED"] != env["GREETING"] {
+		t.Errorf("env = %v", env)
+	}
+	if r.Secrets["API_TOKEN"] != "tok-abc" {
+		t.Errorf("secret = %q", r.Secrets["API_TOKEN"])
+	}
+
+	// An empty value stays a string
+	r, err = Render(tmpl, "shop", map[string]string{"token": "abc"})
+	if err != nil {
+		t.Fatalf("Render() with empty value = %v", err)
+	}
+	if v, ok := r.Spec.Services[0].Env["GREETING"]; !ok || v != "" {
+		t.Errorf("empty value = %q, %v", v, ok)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	valid := models.AppTemplate{Name: "api", Title: "API", Spec: "version: 1\nservices: []\n"}
+	tests := []struct {
+		name   string
+		modify func(*models.AppTemplate)
+		ok     bool
+	}{
+		{"valid", func(*models.AppTemplate) {}, true},
+		{"bad name", func(t *models.AppTemplate) { t.Name = "My API" }, false},
+		{"no title", func(t *models.AppTemplate) { t.Title = "" }, false},
+		{"undeclared variable", func(t *models.AppTemplate) { t.Spec = "app: ${name}\n" }, false},
+		{"app variable redeclared", func(t *models.AppTemplate) { t.Variables = []models.ServiceTemplateVariable{{Name: "app"}} }, false},
+		{"bad secret key", func(t *models.AppTemplate) { t.Secrets = []models.AppTemplateSecret{{Key: "api-key"}} }, false},
+		{"duplicate secret", func(t *models.AppTemplate) { t.Secrets = []models.AppTemplateSecret{{Key: "A"}, {Key: "A"}} }, false},
+		{"not a mapping", func(t *models.AppTemplate) { t.Spec = "- api\n" }, false},
+		{"invalid yaml", func(t *models.AppTemplate) { t.Spec = "services: [\n" }, false},
+	}
+	for _, tt := range tests {
+		tmpl := valid
+		tt.modify(&tmpl)
+		if err := Check(&tmpl); (err == nil) != tt.ok {
+			t.Errorf("%s: Check() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Package archive moves the history of old deployments to object storage.
+//
+// A deployment's build output, generated flake and logs make up most of the
+// database. Once a deployment has stopped and been superseded for long
+// enough, the Archiver writes that history to object storage as compressed
+// JSON and removes it from the database, keeping the deployment and build
+// rows as stubs so listings, versions and rollbacks are unaffected. Reading
+// an archived deployment's logs restores its history first.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrRestoring is returned when an archived deployment's history cannot be
+// read yet because its restore is in progress or failed and will be retried.
+var ErrRestoring = errors.New("deployment history is being restored from the archive")
+
+// ErrNotArchivable is returned when archiving a deployment that is still
+// active or already archived.
+var ErrNotArchivable = errors.New("deployment cannot be archived")
+
+// Config controls which deployments are archived and how often.
+type Config struct {
+	// After is how long a stopped, failed or canceled deployment stays in the
+	// database. Zero disables archiving; archives are still restored.
+	After time.Duration
+	// KeepPerService is the number of newest deployments of each service
+	// that are never archived.
+	KeepPerService int
+	// Interval is how often deployments are archived and failed restores retried.
+	Interval time.Duration
+	// BatchSize is the most deployments archived per interval.
+	BatchSize int
+	// RestoreTimeout is how long a restore may run before it is assumed
+	// abandoned and may be claimed again.
+	RestoreTimeout time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		After:          30 * 24 * time.Hour,
+		KeepPerService: 5,
+		Interval:       time.Hour,
+		BatchSize:      100,
+		RestoreTimeout: 10 * time.Minute,
+	}
+}
+
+// Archiver archives old deployments and restores them.
+type Archiver struct {
+	store   store.Store
+	objects ObjectStore
+	config  Config
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewArchiver creates an archiver keeping archives in the object store.
+func NewArchiver(st store.Store, objects ObjectStore, cfg Config, logger *slog.Logger) *Archiver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Archiver{
+		store:   st,
+		objects: objects,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Run archives deployments and retries failed restores every interval until
+// ctx is cancelled.
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		a.RetryRestores(ctx)
+		a.ArchiveOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveOnce archives a batch of the deployments due under the policy and
+// returns how many were archived.
+func (a *Archiver) ArchiveOnce(ctx context.Context) int {
+	if a.config.After <= 0 {
+		return 0
+	}
+	ids, err := a.store.Archives().ListCandidates(ctx, a.now().Add(-a.config.After), a.config.KeepPerService, a.config.BatchSize)
+	if err != nil {
+		a.logger.Error("failed to list deployments to archive", "error", err)
+		return 0
+	}
+
+	archived := 0
+	for _, id := range ids {
+		if _, err := a.Archive(ctx, id); err != nil {
+			a.logger.Error("failed to archive deployment", "deployment_id", id, "error", err)
+			continue
+		}
+		archived++
+	}
+	if archived > 0 {
+		a.logger.Info("archived deployments", "archived", archived)
+	}
+	return archived
+}
+
+// Archive moves a finished deployment's history to object storage and
+// removes it from the database.
+func (a *Archiver) Archive(ctx context.Context, deploymentID string) (*models.Archive, error) {
+	deployment, err := a.store.Deployments().Get(ctx, deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deployment: %w", err)
+	}
+	switch deployment.Status {
+	case models.DeploymentStatusStopped, models.DeploymentStatusFailed, models.DeploymentStatusCanceled:
+	default:
+		return nil, fmt.Errorf("%w: deployment is %s", ErrNotArchivable, deployment.Status)
+	}
+	existing, err := a.store.Archives().GetByDeployment(ctx, deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting archive: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: deployment is already archived", ErrNotArchivable)
+	}
+
+	bundle := &models.ArchiveBundle{Deployment: deployment}
+	if build, err := a.store.Builds().GetByDeployment(ctx, deploymentID); err == nil && build != nil {
+		bundle.Build = build
+		if bundle.BuildLogs, err = a.store.BuildLogs().List(ctx, build.ID, 0, 0); err != nil {
+			return nil, fmt.Errorf("listing build logs: %w", err)
+		}
+	}
+	if bundle.Logs, err = a.store.Logs().List(ctx, deploymentID, math.MaxInt32); err != nil {
+		return nil, fmt.Errorf("listing logs: %w", err)
+	}
+
+	data, original, err := encodeBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	// Each attempt writes its own object, so a concurrent attempt that
+	// fails never deletes the object of the one that succeeded
+	id := uuid.New().String()
+	archive := &models.Archive{
+		ID:            id,
+		DeploymentID:  deploymentID,
+		AppID:         deployment.AppID,
+		ObjectKey:     ObjectKey(deployment, id),
+		SizeBytes:     int64(len(data)),
+		OriginalBytes: original,
+		Status:        models.ArchiveStatusArchived,
+		ArchivedAt:    a.now(),
+	}
+	if bundle.Build != nil {
+		archive.BuildID = bundle.Build.ID
+	}
+	if err := a.objects.Put(ctx, archive.ObjectKey, data); err != nil {
+		return nil, fmt.Errorf("storing archive: %w", err)
+	}
+
+	err = a.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.Archives().Create(ctx, archive); err != nil {
+			return err
+		}
+		if err := tx.Logs().DeleteByDeployment(ctx, deploymentID); err != nil {
+			return err
+		}
+		if bundle.Build == nil {
+			return nil
+		}
+		if err := tx.BuildLogs().DeleteByBuild(ctx, bundle.Build.ID); err != nil {
+			return err
+		}
+		stub := *bundle.Build
+		stub.GeneratedFlake = ""
+		stub.FlakeLock = ""
+		stub.DetectionResult = nil
+		return tx.Builds().Update(ctx, &stub)
+	})
+	if err != nil {
+		// The deployment is unchanged, so the object is not needed
+		if delErr := a.objects.Delete(ctx, archive.ObjectKey); delErr != nil {
+			a.logger.Warn("failed to delete unused archive object", "key", archive.ObjectKey, "error", delErr)
+		}
+		return nil, fmt.Errorf("stubbing archived deployment: %w", err)
+	}
+
+	a.logger.Info("archived deployment",
+		"deployment_id", deploymentID,
+		"key", archive.ObjectKey,
+		"size_bytes", archive.SizeBytes,
+		"original_bytes", archive.OriginalBytes,
+	)
+	return archive, nil
+}
+
+// Rehydrate restores a deployment's history if it is archived, so it can be
+// read from the database. It returns ErrRestoring if another restore of the
+// deployment is in progress or the restore failed; failed restores are
+// retried by Run.
+func (a *Archiver) Rehydrate(ctx context.Context, deploymentID string) error {
+	archive, err := a.store.Archives().GetByDeployment(ctx, deploymentID)
+	if err != nil {
+		return fmt.Errorf("getting archive: %w", err)
+	}
+	if archive == nil {
+		return nil
+	}
+	return a.restore(ctx, archive)
+}
+
+// RetryRestores restores archives whose restore failed or was abandoned.
+func (a *Archiver) RetryRestores(ctx context.Context) {
+	queue, err := a.store.Archives().ListRestoreQueue(ctx)
+	if err != nil {
+		a.logger.Error("failed to list restore queue", "error", err)
+		return
+	}
+	for _, archive := range queue {
+		if err := a.restore(ctx, archive); err != nil && !errors.Is(err, ErrRestoring) {
+			a.logger.Error("failed to restore archive", "deployment_id", archive.DeploymentID, "error", err)
+		}
+	}
+}
+
+// restore claims an archive and copies its history back into the database.
+func (a *Archiver) restore(ctx context.Context, archive *models.Archive) error {
+	claimed, err := a.store.Archives().ClaimRestore(ctx, archive.ID, a.now().Add(-a.config.RestoreTimeout))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrRestoring
+	}
+
+	if err := a.copyBack(ctx, archive); err != nil {
+		a.logger.Error("failed to restore archived deployment", "deployment_id", archive.DeploymentID, "error", err)
+		if failErr := a.store.Archives().FailRestore(context.WithoutCancel(ctx), archive.ID, err.Error()); failErr != nil {
+			a.logger.Error("failed to record restore failure", "deployment_id", archive.DeploymentID, "error", failErr)
+		}
+		return fmt.Errorf("%w: %v", ErrRestoring, err)
+	}
+
+	if err := a.objects.Delete(ctx, archive.ObjectKey); err != nil {
+		a.logger.Warn("failed to delete restored archive object", "key", archive.ObjectKey, "error", err)
+	}
+	a.logger.Info("restored archived deployment", "deployment_id", archive.DeploymentID)
+	return nil
+}
+
+// copyBack reinserts an archive's logs, build output and generated flake
+// and removes the archive record in one transaction.
+func (a *Archiver) copyBack(ctx context.Context, archive *models.Archive) error {
+	data, err := a.objects.Get(ctx, archive.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("fetching archive: %w", err)
+	}
+	bundle, err := decodeBundle(data)
+	if err != nil {
+		return err
+	}
+
+	return a.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.Logs().CreateBatch(ctx, bundle.Logs); err != nil {
+			return err
+		}
+		if bundle.Build != nil {
+			if err := tx.BuildLogs().Restore(ctx, bundle.BuildLogs); err != nil {
+				return err
+			}
+			// Keep the stub's current state and put back what was removed
+			build, err := tx.Builds().Get(ctx, bundle.Build.ID)
+			if err == nil && build != nil {
+				build.GeneratedFlake = bundle.Build.GeneratedFlake
+				build.FlakeLock = bundle.Build.FlakeLock
+				build.DetectionResult = bundle.Build.DetectionResult
+				if err := tx.Builds().Update(ctx, build); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Archives().Delete(ctx, archive.ID)
+	})
+}
+
+// ObjectKey returns the key an archive of a deployment is stored under.
+func ObjectKey(deployment *models.Deployment, archiveID string) string {
+	return fmt.Sprintf("deployments/%s/%s/%s.json.gz", deployment.AppID, deployment.ID, archiveID)
+}
+
+// encodeBundle returns the gzipped JSON of a bundle and its uncompressed size.
+func encodeBundle(bundle *models.ArchiveBundle) ([]byte, int64, error) {
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encoding archive: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, 0, fmt.Errorf("compressing archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, fmt.Errorf("compressing archive: %w", err)
+	}
+	return buf.Bytes(), int64(len(raw)), nil
+}
+
+// decodeBundle reads a bundle written by encodeBundle.
+func decodeBundle(data []byte) (*models.ArchiveBundle, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing archive: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing archive: %w", err)
+	}
+	var bundle models.ArchiveBundle
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, fmt.Errorf("decoding archive: %w", err)
+	}
+	return &bundle, nil
+}
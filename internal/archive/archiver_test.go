@@ -0,0 +1,290 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the archiver uses.
+type memStore struct {
+	store.Store
+	deployments map[string]*models.Deployment
+	builds      map[string]*models.BuildJob
+	buildLogs   map[string][]*models.BuildLogChunk
+	logs        map[string][]*models.LogEntry
+	archives    map[string]*models.Archive
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		deployments: map[string]*models.Deployment{},
+		builds:      map[string]*models.BuildJob{},
+		buildLogs:   map[string][]*models.BuildLogChunk{},
+		logs:        map[string][]*models.LogEntry{},
+		archives:    map[string]*models.Archive{},
+	}
+}
+
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) Builds() store.BuildStore           { return memBuilds{s: s} }
+func (s *memStore) BuildLogs() store.BuildLogStore     { return memBuildLogs{s: s} }
+func (s *memStore) Logs() store.LogStore               { return memLogs{s: s} }
+func (s *memStore) Archives() store.ArchiveStore       { return memArchives{s: s} }
+
+func (s *memStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(s)
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	if d, ok := m.s.deployments[id]; ok {
+		return d, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type memBuilds struct {
+	store.BuildStore
+	s *memStore
+}
+
+func (m memBuilds) Get(ctx context.Context, id string) (*models.BuildJob, error) {
+	if b, ok := m.s.builds[id]; ok {
+		copied := *b
+		return &copied, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (m memBuilds) GetByDeployment(ctx context.Context, deploymentID string) (*models.BuildJob, error) {
+	for _, b := range m.s.builds {
+		if b.DeploymentID == deploymentID {
+			return m.Get(ctx, b.ID)
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m memBuilds) Update(ctx context.Context, build *models.BuildJob) error {
+	copied := *build
+	m.s.builds[build.ID] = &copied
+	return nil
+}
+
+type memBuildLogs struct {
+	store.BuildLogStore
+	s *memStore
+}
+
+func (m memBuildLogs) List(ctx context.Context, buildID string, afterSeq, limit int) ([]*models.BuildLogChunk, error) {
+	return m.s.buildLogs[buildID], nil
+}
+
+func (m memBuildLogs) Restore(ctx context.Context, chunks []*models.BuildLogChunk) error {
+	for _, c := range chunks {
+		m.s.buildLogs[c.BuildID] = append(m.s.buildLogs[c.BuildID], c)
+	}
+	return nil
+}
+
+func (m memBuildLogs) DeleteByBuild(ctx context.Context, buildID string) error {
+	delete(m.s.buildLogs, buildID)
+	return nil
+}
+
+type memLogs struct {
+	store.LogStore
+	s *memStore
+}
+
+func (m memLogs) List(ctx context.Context, deploymentID string, limit int) ([]*models.LogEntry, error) {
+	return m.s.logs[deploymentID], nil
+}
+
+func (m memLogs) CreateBatch(ctx context.Context, entries []*models.LogEntry) error {
+	for _, e := range entries {
+		m.s.logs[e.DeploymentID] = append(m.s.logs[e.DeploymentID], e)
+	}
+	return nil
+}
+
+func (m memLogs) DeleteByDeployment(ctx context.Context, deploymentID string) error {
+	delete(m.s.logs, deploymentID)
+	return nil
+}
+
+type memArchives struct {
+	store.ArchiveStore
+	s *memStore
+}
+
+func (m memArchives) Create(ctx context.Context, archive *models.Archive) error {
+	m.s.archives[archive.DeploymentID] = archive
+	return nil
+}
+
+func (m memArchives) GetByDeployment(ctx context.Context, deploymentID string) (*models.Archive, error) {
+	return m.s.archives[deploymentID], nil
+}
+
+func (m memArchives) ClaimRestore(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	for _, a := range m.s.archives {
+		if a.ID == id && a.Status != models.ArchiveStatusRestoring {
+			a.Status = models.ArchiveStatusRestoring
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m memArchives) FailRestore(ctx context.Context, id, message string) error {
+	for _, a := range m.s.archives {
+		if a.ID == id {
+			a.Status, a.Error = models.ArchiveStatusRestoreFailed, message
+		}
+	}
+	return nil
+}
+
+func (m memArchives) ListRestoreQueue(ctx context.Context) ([]*models.Archive, error) {
+	var queue []*models.Archive
+	for _, a := range m.s.archives {
+		if a.Status != models.ArchiveStatusArchived {
+			queue = append(queue, a)
+		}
+	}
+	return queue, nil
+}
+
+func (m memArchives) Delete(ctx context.Context, id string) error {
+	for deploymentID, a := range m.s.archives {
+		if a.ID == id {
+			delete(m.s.archives, deploymentID)
+		}
+	}
+	return nil
+}
+
+// setup returns a store with a stopped deployment with build output and
+// logs, and an archiver keeping archives in a temporary directory.
+func setup(t *testing.T) (*memStore, *Archiver, string) {
+	t.Helper()
+	st := newMemStore()
+	st.deployments["dep-1"] = &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusStopped}
+	st.deployments["dep-2"] = &models.Deployment{ID: "dep-2", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusRunning}
+	st.builds["build-1"] = &models.BuildJob{
+		ID: "build-1", DeploymentID: "dep-1", AppID: "app-1", Status: models.BuildStatusSucceeded,
+		GeneratedFlake: "{ outputs = _: {}; }", FlakeLock: `{"nodes":{}}`,
+		DetectionResult: &models.DetectionResult{Framework: "nextjs"},
+	}
+	created := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	st.buildLogs["build-1"] = []*models.BuildLogChunk{
+		{BuildID: "build-1", Seq: 1, Content: "building\n", CreatedAt: created},
+		{BuildID: "build-1", Seq: 2, Content: "done\n", CreatedAt: created},
+	}
+	st.logs["dep-1"] = []*models.LogEntry{
+		{ID: "log-1", DeploymentID: "dep-1", Source: "runtime", Level: "info", Message: "listening on :3000", Timestamp: created},
+	}
+
+	dir := t.TempDir()
+	return st, NewArchiver(st, NewDirStore(dir), DefaultConfig(), nil), dir
+}
+
+func TestArchiveAndRehydrate(t *testing.T) {
+	ctx := context.Background()
+	st, a, dir := setup(t)
+
+	archive, err := a.Archive(ctx, "dep-1")
+	if err != nil {
+		t.Fatalf("Archive() = %v", err)
+	}
+	if archive.BuildID != "build-1" || archive.SizeBytes == 0 || archive.OriginalBytes <= archive.SizeBytes {
+		t.Errorf("archive = %+v", archive)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archive.ObjectKey)); err != nil {
+		t.Fatalf("archive object: %v", err)
+	}
+	build := st.builds["build-1"]
+	if len(st.logs["dep-1"]) != 0 || len(st.buildLogs["build-1"]) != 0 || build.GeneratedFlake != "" || build.DetectionResult != nil {
+		t.Fatalf("history left in the store: logs %d, build logs %d, build %+v", len(st.logs["dep-1"]), len(st.buildLogs["build-1"]), build)
+	}
+	if build.Status != models.BuildStatusSucceeded {
+		t.Errorf("stub build status = %s", build.Status)
+	}
+
+	if _, err := a.Archive(ctx, "dep-1"); !errors.Is(err, ErrNotArchivable) {
+		t.Errorf("archiving again = %v, want %v", err, ErrNotArchivable)
+	}
+	if _, err := a.Archive(ctx, "dep-2"); !errors.Is(err, ErrNotArchivable) {
+		t.Errorf("archiving a running deployment = %v, want %v", err, ErrNotArchivable)
+	}
+
+	if err := a.Rehydrate(ctx, "dep-1"); err != nil {
+		t.Fatalf("Rehydrate() = %v", err)
+	}
+	if len(st.logs["dep-1"]) != 1 || st.logs["dep-1"][0].Message != "listening on :3000" {
+		t.Errorf("restored logs = %+v", st.logs["dep-1"])
+	}
+	chunks := st.buildLogs["build-1"]
+	if len(chunks) != 2 || chunks[1].Seq != 2 || !chunks[1].CreatedAt.Equal(chunks[0].CreatedAt) {
+		t.Errorf("restored build logs = %+v", chunks)
+	}
+	build = st.builds["build-1"]
+	if build.GeneratedFlake == "" || build.FlakeLock == "" || build.DetectionResult == nil || build.DetectionResult.Framework != "nextjs" {
+		t.Errorf("restored build = %+v", build)
+	}
+	if st.archives["dep-1"] != nil {
+		t.Error("archive record kept after restoring")
+	}
+	if _, err := os.Stat(filepath.Join(dir, archive.ObjectKey)); !os.IsNotExist(err) {
+		t.Error("archive object kept after restoring")
+	}
+
+	// Deployments that were never archived need no restore
+	if err := a.Rehydrate(ctx, "dep-2"); err != nil {
+		t.Errorf("Rehydrate() of an unarchived deployment = %v", err)
+	}
+}
+
+func TestRehydrateQueuesFailedRestores(t *testing.T) {
+	ctx := context.Background()
+	st, a, dir := setup(t)
+
+	archive, err := a.Archive(ctx, "dep-1")
+	if err != nil {
+		t.Fatalf("Archive() = %v", err)
+	}
+	object := filepath.Join(dir, archive.ObjectKey)
+	data, err := os.ReadFile(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(object)
+
+	if err := a.Rehydrate(ctx, "dep-1"); !errors.Is(err, ErrRestoring) {
+		t.Fatalf("Rehydrate() without the object = %v, want %v", err, ErrRestoring)
+	}
+	if got := st.archives["dep-1"]; got == nil || got.Status != models.ArchiveStatusRestoreFailed || got.Error == "" {
+		t.Fatalf("archive after failed restore = %+v", got)
+	}
+
+	// The object comes back and the queued restore is retried
+	if err := os.WriteFile(object, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	a.RetryRestores(ctx)
+	if st.archives["dep-1"] != nil || len(st.logs["dep-1"]) != 1 {
+		t.Errorf("retry did not restore: archive %+v, logs %d", st.archives["dep-1"], len(st.logs["dep-1"]))
+	}
+}
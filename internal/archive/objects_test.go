@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 serves a path-style bucket from memory and rejects unsigned requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20260310/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") ||
+		r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) || r.Header.Get("X-Amz-Date") != "20260310T120000Z" {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Code>SignatureDoesNotMatch</Code>")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s := NewS3Store(S3Config{
+		Endpoint:        srv.URL + "/",
+		Bucket:          "archives",
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	}, srv.Client())
+	s.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	key := "deployments/app-1/dep-1/archive-1.json.gz"
+	if err := s.Put(ctx, key, []byte("history")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	if _, ok := fake.objects["/archives/"+key]; !ok {
+		t.Fatalf("objects = %v, want the key under the bucket", fake.objects)
+	}
+	data, err := s.Get(ctx, key)
+	if err != nil || string(data) != "history" {
+		t.Fatalf("Get() = %q, %v", data, err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() = %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() after Delete() = %v, want %v", err, ErrObjectNotFound)
+	}
+
+	s.config.AccessKeyID = "OTHER"
+	if err := s.Put(ctx, key, []byte("history")); err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put() with a rejected signature = %v", err)
+	}
+}
+
+func TestDirStoreRejectsEscapingKeys(t *testing.T) {
+	s := NewDirStore(t.TempDir())
+	if err := s.Put(context.Background(), "../../etc/passwd", []byte("x")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	if _, err := s.Get(context.Background(), "etc/passwd"); err != nil {
+		t.Errorf("key was not kept within the directory: %v", err)
+	}
+	if err := s.Put(context.Background(), "..", []byte("x")); err == nil {
+		t.Error("Put() of an empty key succeeded")
+	}
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Redacted replaces sensitive values in recorded changes.
+const Redacted = "[redacted]"
+
+// sensitiveFields are field names whose values are never recorded.
+var sensitiveFields = map[string]bool{
+	"value":         true,
+	"password":      true,
+	"token":         true,
+	"secret":        true,
+	"private_key":   true,
+	"api_key":       true,
+	"client_secret": true,
+	"webhook_url":   true,
+}
+
+// redactedParents are objects whose members' values are never recorded. Env
+// vars often hold credentials even though they are not stored as secrets.
+var redactedParents = map[string]bool{
+	"env_vars": true,
+	"headers":  true,
+}
+
+// Diff returns the fields that differ between two JSON snapshots of a
+// resource. Objects are compared field by field using dotted paths; arrays
+// are compared as a whole. A nil snapshot means the resource did not exist.
+func Diff(before, after []byte) []models.AuditChange {
+	oldFields := flatten(decode(before))
+	newFields := flatten(decode(after))
+
+	var changes []models.AuditChange
+	for field, oldValue := range oldFields {
+		newValue, ok := newFields[field]
+		if !ok {
+			changes = append(changes, change(field, oldValue, nil))
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, change(field, oldValue, newValue))
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, change(field, nil, newValue))
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// decode parses a snapshot, returning nil for missing or invalid JSON.
+func decode(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// flatten maps each leaf of a decoded JSON document to its dotted path.
+// Null fields are dropped so that a field set to null and an absent field
+// compare equal.
+func flatten(v any) map[string]any {
+	fields := make(map[string]any)
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			if v != nil && prefix != "" {
+				fields[prefix] = v
+			}
+			return
+		}
+		for k, child := range obj {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			walk(path, child)
+		}
+	}
+	walk("", v)
+	return fields
+}
+
+// change builds a change, redacting the values of sensitive fields. The
+// change itself is still recorded so the log shows that the field changed.
+func change(field string, oldValue, newValue any) models.AuditChange {
+	if sensitive(field) {
+		if oldValue != nil {
+			oldValue = Redacted
+		}
+		if newValue != nil {
+			newValue = Redacted
+		}
+	}
+	return models.AuditChange{Field: field, Old: oldValue, New: newValue}
+}
+
+// sensitive reports whether a dotted field path holds a value that must not
+// be recorded.
+func sensitive(field string) bool {
+	parts := strings.Split(field, ".")
+	for _, p := range parts[:len(parts)-1] {
+		if redactedParents[p] {
+			return true
+		}
+	}
+	return sensitiveFields[parts[len(parts)-1]]
+}
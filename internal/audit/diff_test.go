@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	before := []byte(`{"name":"web","replicas":1,"ports":[80],"env_vars":{"DATABASE_URL":"postgres://a","PORT":"80"},"health":{"path":"/"}}`)
+	after := []byte(`{"name":"web","replicas":2,"ports":[80,443],"env_vars":{"DATABASE_URL":"postgres://b","PORT":"80","DEBUG":"1"},"health":null}`)
+
+	want := []models.AuditChange{
+		{Field: "env_vars.DATABASE_URL", Old: Redacted, New: Redacted},
+		{Field: "env_vars.DEBUG", New: Redacted},
+		{Field: "health.path", Old: "/"},
+		{Field: "ports", Old: []any{float64(80)}, New: []any{float64(80), float64(443)}},
+		{Field: "replicas", Old: float64(1), New: float64(2)},
+	}
+	if got := Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	// Creating and deleting list every field
+	if got := Diff(nil, []byte(`{"key":"TOKEN","value":"abc"}`)); len(got) != 2 || got[1].New != Redacted {
+		t.Errorf("Diff(nil, secret) = %+v", got)
+	}
+	if got := Diff([]byte(`{"id":"app-1"}`), nil); len(got) != 1 || got[0].Old != "app-1" || got[0].New != nil {
+		t.Errorf("Diff(app, nil) = %+v", got)
+	}
+	if got := Diff(before, before); len(got) != 0 {
+		t.Errorf("Diff of identical snapshots = %+v", got)
+	}
+}
+
+func TestResolveResource(t *testing.T) {
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		want    resource
+	}{
+		{"/v1/apps", nil, resource{Type: "app"}},
+		{"/v1/apps/{appID}", map[string]string{"appID": "shop"}, resource{Type: "app", ID: "shop", appRef: "shop"}},
+		{"/v1/apps/{appID}/deploy", map[string]string{"appID": "shop"}, resource{Type: "app", ID: "shop", appRef: "shop"}},
+		{"/v1/apps/{appID}/services/{serviceName}/env/{key}", map[string]string{"appID": "shop", "serviceName": "web", "key": "PORT"},
+			resource{Type: "service", Name: "web", appRef: "shop"}},
+		{"/v1/apps/{appID}/secrets", map[string]string{"appID": "shop"}, resource{Type: "secret", appRef: "shop"}},
+		{"/v1/apps/{appID}/services/{serviceName}/builds", map[string]string{"appID": "shop", "serviceName": "web"},
+			resource{Type: "build", appRef: "shop"}},
+		{"/v1/deployments/{deploymentID}/rollback", map[string]string{"deploymentID": "dep-1"}, resource{Type: "deployment", ID: "dep-1"}},
+		{"/v1/orgs/{orgID}/admission-policies/{policyID}", map[string]string{"orgID": "org-1", "policyID": "p1"},
+			resource{Type: "admission-policy", ID: "p1", OrgID: "org-1"}},
+		{"/v1/orgs/{orgID}/shared-secrets/{key}", map[string]string{"orgID": "org-1", "key": "apm_key"},
+			resource{Type: "shared-secret", OrgID: "org-1", Name: "APM_KEY"}},
+		{"/v1/nodes/{nodeID}/drain", map[string]string{"nodeID": "n1"}, resource{Type: "node", ID: "n1"}},
+	}
+	for _, tt := range tests {
+		var params chi.RouteParams
+		for k, v := range tt.params {
+			params.Add(k, v)
+		}
+		if got := resolveResource(tt.pattern, params); got != tt.want {
+			t.Errorf("resolveResource(%q) = %+v, want %+v", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how long audit entries are kept.
+type Config struct {
+	// Retention is how long entries are kept. Zero keeps them forever.
+	Retention time.Duration
+	// PruneInterval is how often expired entries are deleted.
+	PruneInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Retention:     90 * 24 * time.Hour,
+		PruneInterval: time.Hour,
+	}
+}
+
+// Pruner deletes audit entries older than the retention period.
+type Pruner struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewPruner creates an audit log pruner.
+func NewPruner(st store.Store, cfg Config, logger *slog.Logger) *Pruner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Pruner{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run prunes expired entries every prune interval until ctx is cancelled.
+// It returns immediately when retention is disabled.
+func (p *Pruner) Run(ctx context.Context) {
+	if p.config.Retention <= 0 {
+		return
+	}
+	p.PruneOnce(ctx)
+
+	ticker := time.NewTicker(p.config.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PruneOnce(ctx)
+		}
+	}
+}
+
+// PruneOnce deletes entries older than the retention period.
+func (p *Pruner) PruneOnce(ctx context.Context) {
+	if p.config.Retention <= 0 {
+		return
+	}
+	deleted, err := p.store.Audit().DeleteBefore(ctx, p.now().Add(-p.config.Retention))
+	if err != nil {
+		p.logger.Error("failed to prune audit log", "error", err)
+		return
+	}
+	if deleted > 0 {
+		p.logger.Info("pruned audit log", "deleted", deleted)
+	}
+}
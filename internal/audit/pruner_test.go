@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestPruneOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	st := newMemStore()
+	st.audit.entries = []*models.AuditEntry{
+		{ID: "old", CreatedAt: now.Add(-91 * 24 * time.Hour)},
+		{ID: "recent", CreatedAt: now.Add(-time.Hour)},
+	}
+	p := NewPruner(st, DefaultConfig(), nil)
+	p.now = func() time.Time { return now }
+
+	p.PruneOnce(context.Background())
+	if len(st.audit.entries) != 1 || st.audit.entries[0].ID != "recent" {
+		t.Errorf("entries after pruning = %+v, want recent", st.audit.entries)
+	}
+
+	// Zero retention keeps everything
+	p = NewPruner(st, Config{}, nil)
+	p.now = func() time.Time { return now.Add(365 * 24 * time.Hour) }
+	p.PruneOnce(context.Background())
+	if len(st.audit.entries) != 1 {
+		t.Errorf("pruned with retention disabled: %+v", st.audit.entries)
+	}
+}
@@ -0,0 +1,364 @@
+// Package audit records who changed what through the API. Every mutating
+// request under /v1 is logged with its actor, route, target resource and
+// outcome, along with a field-level diff for apps, services, app and shared
+// secrets, deployments and builds. The status transitions of builds,
+// deployments and nodes that follow are logged too.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxCapturedBody caps how much of a response is kept to find the ID of a
+// created resource.
+const maxCapturedBody = 64 << 10
+
+// skippedRoutes are mutating routes that change nothing worth auditing:
+// agent heartbeats, workload token refreshes and side-effect free evaluations.
+var skippedRoutes = map[string]bool{
+	"POST /v1/nodes/register":                              true,
+	"POST /v1/nodes/heartbeat":                             true,
+	"POST /v1/nodes/{nodeID}/heartbeat":                    true,
+	"POST /v1/nodes/{nodeID}/workload-tokens":              true,
+	"POST /v1/workload-identity/token":                     true,
+	"POST /v1/detect":                                      true,
+	"POST /v1/orgs/{orgID}/admission-policies/evaluate":    true,
+	"POST /v1/apps/{appID}/services/{serviceName}/preview": true,
+}
+
+// parentScoped are collections whose members are recorded as changes to
+// the parent resource, e.g. a service's env vars.
+var parentScoped = map[string]bool{
+	"env": true,
+}
+
+// Recorder is HTTP middleware that writes an audit entry for every
+// mutating request.
+type Recorder struct {
+	store  store.Store
+	router chi.Routes
+	logger *slog.Logger
+}
+
+// NewRecorder creates a recorder. The router must be the root router serving
+// the requests so that route patterns and URL params can be resolved before
+// the handler runs.
+func NewRecorder(st store.Store, router chi.Routes, logger *slog.Logger) *Recorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Recorder{
+		store:  st,
+		router: router,
+		logger: logger,
+	}
+}
+
+// resource identifies the target of a request.
+type resource struct {
+	Type  string
+	ID    string
+	AppID string
+	OrgID string
+	// Name is the service or secret name within its app, or the shared
+	// secret key within its org.
+	Name string
+	// appRef is the app ID or name from the URL, resolved into AppID.
+	appRef string
+}
+
+// Middleware records the request after the handler has run. It must run
+// after authentication so the actor is known.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rctx := chi.NewRouteContext()
+		pattern := strings.TrimSuffix(rec.router.Find(rctx, r.Method, r.URL.Path), "/")
+		action := r.Method + " " + pattern
+		if pattern == "" || skippedRoutes[action] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		userID := middleware.GetUserID(ctx)
+		res := resolveResource(pattern, rctx.URLParams)
+		rec.resolveApp(ctx, &res, userID)
+		before := rec.snapshot(ctx, res)
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		body := &cappedBuffer{limit: maxCapturedBody}
+		ww.Tee(body)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		entry := &models.AuditEntry{
+			UserID:       userID,
+			UserEmail:    middleware.GetUserEmail(ctx),
+			APIKeyID:     middleware.GetAPIKeyID(ctx),
+			Action:       action,
+			ResourceType: res.Type,
+			AppID:        res.AppID,
+			Path:         r.URL.Path,
+			Status:       status,
+			RequestID:    chimiddleware.GetReqID(ctx),
+			RemoteAddr:   r.RemoteAddr,
+		}
+		if status < http.StatusBadRequest {
+			if res.Name == "" && res.ID == "" {
+				rec.fillCreated(&res, body.Bytes())
+			}
+			entry.Changes = Diff(before, rec.snapshot(ctx, res))
+		}
+		entry.ResourceID = res.resourceID()
+
+		// The request context may already be cancelled by a client disconnect
+		if err := rec.store.Audit().Record(context.WithoutCancel(ctx), entry); err != nil {
+			rec.logger.Error("failed to record audit entry", "error", err, "action", action, "path", r.URL.Path)
+		}
+	})
+}
+
+// resolveResource derives the target resource from a route pattern. A static
+// segment followed by a param, or a plural segment, names a collection; other
+// trailing segments are actions on the preceding resource.
+func resolveResource(pattern string, params chi.RouteParams) resource {
+	values := make(map[string]string, len(params.Keys))
+	for i, k := range params.Keys {
+		values[k] = params.Values[i]
+	}
+
+	var res resource
+	var collection string
+	segments := strings.Split(strings.TrimPrefix(pattern, "/v1/"), "/")
+	for i, seg := range segments {
+		if isParam(seg) || parentScoped[seg] {
+			continue
+		}
+		var id string
+		if i+1 < len(segments) && isParam(segments[i+1]) {
+			id = values[strings.Trim(segments[i+1], "{}")]
+		} else if !strings.HasSuffix(seg, "s") {
+			// An action such as /deploy or /stop
+			continue
+		}
+		collection, res.ID = seg, id
+		switch seg {
+		case "apps":
+			res.appRef = id
+		case "orgs":
+			res.OrgID = id
+		}
+	}
+	if collection == "" {
+		collection = segments[0]
+	}
+
+	res.Type = singular(collection)
+	switch res.Type {
+	case "service", "secret":
+		res.Name, res.ID = res.ID, ""
+	case "shared-secret":
+		res.Name, res.ID = strings.ToUpper(res.ID), ""
+	}
+	return res
+}
+
+// isParam reports whether a route pattern segment is a URL param.
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, "{")
+}
+
+// singular turns a collection segment into a resource type, e.g.
+// "admission-policies" into "admission-policy".
+func singular(collection string) string {
+	switch {
+	case strings.HasSuffix(collection, "ies"):
+		return strings.TrimSuffix(collection, "ies") + "y"
+	case strings.HasSuffix(collection, "s"):
+		return strings.TrimSuffix(collection, "s")
+	default:
+		return collection
+	}
+}
+
+// resourceID returns the ID recorded for the resource. Services and secrets
+// are named within their app, shared secrets within their org.
+func (res resource) resourceID() string {
+	switch {
+	case res.Name == "":
+		return res.ID
+	case res.Type == "shared-secret":
+		return res.OrgID + "/" + res.Name
+	default:
+		return res.AppID + "/" + res.Name
+	}
+}
+
+// resolveApp resolves the app from the URL, which may be an ID or a name.
+func (rec *Recorder) resolveApp(ctx context.Context, res *resource, userID string) {
+	if res.appRef == "" {
+		return
+	}
+	app, err := rec.store.Apps().Get(ctx, res.appRef)
+	if err != nil || app == nil {
+		app, err = rec.store.Apps().GetByName(ctx, userID, res.appRef)
+		if err != nil || app == nil {
+			res.AppID = res.appRef
+			return
+		}
+	}
+	res.AppID = app.ID
+	if res.Type == "app" {
+		res.ID = app.ID
+	}
+}
+
+// fillCreated takes the ID of a created resource from the response body.
+func (rec *Recorder) fillCreated(res *resource, body []byte) {
+	var created struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return
+	}
+	switch res.Type {
+	case "service":
+		res.Name = created.Name
+	case "secret", "shared-secret":
+		res.Name = created.Key
+	case "app":
+		res.ID, res.AppID = created.ID, created.ID
+	default:
+		res.ID = created.ID
+	}
+}
+
+// snapshot returns the current state of a tracked resource as JSON, or nil
+// if the resource is untracked or does not exist.
+func (rec *Recorder) snapshot(ctx context.Context, res resource) []byte {
+	var v any
+	switch res.Type {
+	case "app":
+		if res.ID == "" {
+			return nil
+		}
+		app, err := rec.store.Apps().Get(ctx, res.ID)
+		if err != nil || app == nil {
+			return nil
+		}
+		// Services are recorded as resources of their own
+		clone := *app
+		clone.Services = nil
+		v = clone
+	case "service":
+		if res.AppID == "" || res.Name == "" {
+			return nil
+		}
+		app, err := rec.store.Apps().Get(ctx, res.AppID)
+		if err != nil || app == nil {
+			return nil
+		}
+		for i := range app.Services {
+			if app.Services[i].Name == res.Name {
+				v = app.Services[i]
+				break
+			}
+		}
+	case "secret":
+		if res.AppID == "" || res.Name == "" {
+			return nil
+		}
+		value, err := rec.store.Secrets().Get(ctx, res.AppID, res.Name)
+		if err != nil {
+			return nil
+		}
+		// The value is redacted in the diff; hashing it shows when it changed
+		sum := sha256.Sum256(value)
+		v = map[string]string{"key": res.Name, "value": hex.EncodeToString(sum[:])}
+	case "shared-secret":
+		if res.OrgID == "" || res.Name == "" {
+			return nil
+		}
+		secret, err := rec.store.OrgSecrets().Get(ctx, res.OrgID, res.Name)
+		if err != nil || secret == nil {
+			return nil
+		}
+		sum := sha256.Sum256(secret.EncryptedValue)
+		v = map[string]any{
+			"key":         secret.Key,
+			"value":       hex.EncodeToString(sum[:]),
+			"description": secret.Description,
+			"scope":       secret.Scope,
+			"app_ids":     secret.AppIDs,
+		}
+	case "deployment":
+		if res.ID == "" {
+			return nil
+		}
+		deployment, err := rec.store.Deployments().Get(ctx, res.ID)
+		if err != nil {
+			return nil
+		}
+		v = deployment
+	case "build":
+		if res.ID == "" {
+			return nil
+		}
+		build, err := rec.store.Builds().Get(ctx, res.ID)
+		if err != nil {
+			return nil
+		}
+		v = build
+	}
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// cappedBuffer keeps the first limit bytes written to it.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/narvanalabs/control-plane/internal/api/middleware"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+var errNotFound = errors.New("not found")
+
+// memStore is an in-memory store providing apps, secrets and the audit log.
+type memStore struct {
+	store.Store
+	apps    *memApps
+	secrets *memSecrets
+	audit   *memAudit
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		apps:    &memApps{apps: map[string]*models.App{}},
+		secrets: &memSecrets{values: map[string][]byte{}},
+		audit:   &memAudit{},
+	}
+}
+
+func (s *memStore) Apps() store.AppStore       { return s.apps }
+func (s *memStore) Secrets() store.SecretStore { return s.secrets }
+func (s *memStore) Audit() store.AuditStore    { return s.audit }
+
+type memApps struct {
+	store.AppStore
+	apps map[string]*models.App
+}
+
+func (m *memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	if app, ok := m.apps[id]; ok {
+		return app, nil
+	}
+	return nil, errNotFound
+}
+
+func (m *memApps) GetByName(ctx context.Context, ownerID, name string) (*models.App, error) {
+	for _, app := range m.apps {
+		if app.OwnerID == ownerID && app.Name == name {
+			return app, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+type memSecrets struct {
+	store.SecretStore
+	values map[string][]byte
+}
+
+func (m *memSecrets) Get(ctx context.Context, appID, key string) ([]byte, error) {
+	if v, ok := m.values[appID+"/"+key]; ok {
+		return v, nil
+	}
+	return nil, errNotFound
+}
+
+type memAudit struct {
+	entries []*models.AuditEntry
+}
+
+func (m *memAudit) Record(ctx context.Context, entry *models.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memAudit) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	return m.entries, nil
+}
+
+func (m *memAudit) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var kept []*models.AuditEntry
+	for _, e := range m.entries {
+		if !e.CreatedAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(m.entries) - len(kept))
+	m.entries = kept
+	return deleted, nil
+}
+
+// newTestRouter serves a few routes behind the recorder the way the API
+// server does, authenticating every request as user-1.
+func newTestRouter(st *memStore) http.Handler {
+	r := chi.NewRouter()
+	rec := NewRecorder(st, r, nil)
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, "user-1")
+				ctx = context.WithValue(ctx, middleware.APIKeyIDKey, "key-1")
+				next.ServeHTTP(w, req.WithContext(ctx))
+			})
+		})
+		r.Use(rec.Middleware)
+		r.Patch("/apps/{appID}/services/{serviceName}", func(w http.ResponseWriter, req *http.Request) {
+			svc := &st.apps.apps["app-1"].Services[0]
+			svc.Replicas = 3
+			svc.EnvVars = map[string]string{"DATABASE_URL": "postgres://new"}
+			w.WriteHeader(http.StatusOK)
+		})
+		r.Post("/apps/{appID}/secrets", func(w http.ResponseWriter, req *http.Request) {
+			st.secrets.values["app-1/API_TOKEN"] = []byte("encrypted")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"key":"API_TOKEN","status":"created"}`))
+		})
+		r.Delete("/apps/{appID}", func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		})
+		r.Post("/nodes/heartbeat", func(w http.ResponseWriter, req *http.Request) {})
+		r.Get("/apps/{appID}", func(w http.ResponseWriter, req *http.Request) {})
+	})
+	return r
+}
+
+func TestRecorder(t *testing.T) {
+	st := newMemStore()
+	st.apps.apps["app-1"] = &models.App{
+		ID:       "app-1",
+		Name:     "shop",
+		OwnerID:  "user-1",
+		Services: []models.ServiceConfig{{Name: "web", Replicas: 1, EnvVars: map[string]string{"DATABASE_URL": "postgres://old"}}},
+	}
+	router := newTestRouter(st)
+
+	do := func(method, path string) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader("{}")))
+	}
+	do(http.MethodPatch, "/v1/apps/shop/services/web")
+	do(http.MethodPost, "/v1/apps/app-1/secrets")
+	do(http.MethodDelete, "/v1/apps/app-1")
+	// Reads and heartbeats are not audited
+	do(http.MethodGet, "/v1/apps/app-1")
+	do(http.MethodPost, "/v1/nodes/heartbeat")
+
+	entries := st.audit.entries
+	if len(entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(entries))
+	}
+
+	update := entries[0]
+	if update.Action != "PATCH /v1/apps/{appID}/services/{serviceName}" || update.ResourceType != "service" ||
+		update.ResourceID != "app-1/web" || update.AppID != "app-1" || update.UserID != "user-1" ||
+		update.APIKeyID != "key-1" || update.Status != http.StatusOK {
+		t.Errorf("update entry = %+v", update)
+	}
+	changes, _ := json.Marshal(update.Changes)
+	if want := `[{"field":"env_vars.DATABASE_URL","old":"[redacted]","new":"[redacted]"},{"field":"replicas","old":1,"new":3}]`; string(changes) != want {
+		t.Errorf("update changes = %s, want %s", changes, want)
+	}
+
+	created := entries[1]
+	if created.ResourceType != "secret" || created.ResourceID != "app-1/API_TOKEN" || len(created.Changes) != 2 {
+		t.Errorf("secret entry = %+v", created)
+	}
+	if strings.Contains(string(mustJSON(t, created)), "encrypted") {
+		t.Error("secret entry leaks the value")
+	}
+
+	// Failed requests are recorded without a diff
+	denied := entries[2]
+	if denied.ResourceType != "app" || denied.ResourceID != "app-1" || denied.Status != http.StatusForbidden || len(denied.Changes) != 0 {
+		t.Errorf("denied entry = %+v", denied)
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
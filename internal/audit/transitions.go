@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// TransitionRecorder writes an audit entry for every build, deployment and
+// node transition published on the event bus, so the audit log shows what
+// the platform did alongside what users asked it to. Its entries have no
+// user and the transition kind, e.g. "deployment.status", as their action.
+type TransitionRecorder struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewTransitionRecorder creates a transition recorder.
+func NewTransitionRecorder(st store.Store, logger *slog.Logger) *TransitionRecorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TransitionRecorder{store: st, logger: logger}
+}
+
+// HandleTransition records a transition.
+func (r *TransitionRecorder) HandleTransition(ctx context.Context, t *events.Transition) error {
+	entry := transitionEntry(t)
+	if entry == nil {
+		return nil
+	}
+	if err := r.store.Audit().Record(ctx, entry); err != nil {
+		return fmt.Errorf("recording %s transition: %w", t.Kind, err)
+	}
+	return nil
+}
+
+// transitionEntry returns the audit entry for a transition, or nil if it
+// changed nothing worth recording.
+func transitionEntry(t *events.Transition) *models.AuditEntry {
+	entry := &models.AuditEntry{Action: string(t.Kind), CreatedAt: t.Time}
+	switch {
+	case t.Kind == events.TransitionBuildFinished && t.Build != nil:
+		entry.ResourceType = "build"
+		entry.ResourceID = t.Build.ID
+		entry.AppID = t.Build.AppID
+		entry.Changes = []models.AuditChange{{Field: "status", New: t.Build.Status}}
+	case t.Kind == events.TransitionDeploymentStatus && t.Deployment != nil:
+		entry.ResourceType = "deployment"
+		entry.ResourceID = t.Deployment.ID
+		entry.AppID = t.Deployment.AppID
+		entry.Changes = []models.AuditChange{{Field: "status", Old: t.PreviousStatus, New: t.Deployment.Status}}
+	case t.Kind == events.TransitionNodeHealth && t.Node != nil:
+		entry.ResourceType = "node"
+		entry.ResourceID = t.Node.ID
+		if e := t.HealthEvent; e != nil {
+			entry.Changes = []models.AuditChange{{Field: "healthy", Old: !e.Healthy, New: e.Healthy}}
+		}
+		if p := t.PreviousNode; p != nil {
+			if p.ClockStatus != t.Node.ClockStatus {
+				entry.Changes = append(entry.Changes, models.AuditChange{Field: "clock_status", Old: p.ClockStatus, New: t.Node.ClockStatus})
+			}
+			if p.CertificateStatus != t.Node.CertificateStatus {
+				entry.Changes = append(entry.Changes, models.AuditChange{Field: "certificate_status", Old: p.CertificateStatus, New: t.Node.CertificateStatus})
+			}
+		}
+		if len(entry.Changes) == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return entry
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestTransitionRecorder(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		transition *events.Transition
+		wantType   string
+		wantID     string
+		wantFields []string
+	}{
+		{"build finished", &events.Transition{Kind: events.TransitionBuildFinished, Time: now,
+			Build: &models.BuildJob{ID: "b1", AppID: "app-1", Status: models.BuildStatusFailed}},
+			"build", "b1", []string{"status"}},
+		{"deployment status", &events.Transition{Kind: events.TransitionDeploymentStatus, Time: now,
+			Deployment: &models.Deployment{ID: "d1", AppID: "app-1", Status: models.DeploymentStatusRunning}, PreviousStatus: models.DeploymentStatusStarting},
+			"deployment", "d1", []string{"status"}},
+		{"node lapsed", &events.Transition{Kind: events.TransitionNodeHealth, Time: now,
+			Node: &models.Node{ID: "n1"}, HealthEvent: &models.NodeHealthEvent{NodeID: "n1"}},
+			"node", "n1", []string{"healthy"}},
+		{"node conditions", &events.Transition{Kind: events.TransitionNodeHealth, Time: now,
+			Node:         &models.Node{ID: "n1", ClockStatus: models.NodeConditionCritical, CertificateStatus: models.NodeConditionOK},
+			PreviousNode: &models.Node{ID: "n1", ClockStatus: models.NodeConditionOK, CertificateStatus: models.NodeConditionOK}},
+			"node", "n1", []string{"clock_status"}},
+		{"node unchanged", &events.Transition{Kind: events.TransitionNodeHealth, Time: now,
+			Node: &models.Node{ID: "n1"}, PreviousNode: &models.Node{ID: "n1"}},
+			"", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newMemStore()
+			if err := NewTransitionRecorder(st, nil).HandleTransition(context.Background(), tt.transition); err != nil {
+				t.Fatalf("HandleTransition() = %v", err)
+			}
+			if tt.wantType == "" {
+				if len(st.audit.entries) != 0 {
+					t.Errorf("recorded %+v, want nothing", st.audit.entries)
+				}
+				return
+			}
+			if len(st.audit.entries) != 1 {
+				t.Fatalf("recorded %d entries, want 1", len(st.audit.entries))
+			}
+			e := st.audit.entries[0]
+			if e.Action != string(tt.transition.Kind) || e.ResourceType != tt.wantType || e.ResourceID != tt.wantID ||
+				e.UserID != "" || !e.CreatedAt.Equal(now) {
+				t.Errorf("entry = %+v", e)
+			}
+			if len(e.Changes) != len(tt.wantFields) {
+				t.Fatalf("changes = %+v, want %v", e.Changes, tt.wantFields)
+			}
+			for i, f := range tt.wantFields {
+				if e.Changes[i].Field != f {
+					t.Errorf("changes[%d] = %s, want %s", i, e.Changes[i].Field, f)
+				}
+			}
+		})
+	}
+}
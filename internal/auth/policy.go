@@ -0,0 +1,445 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyVersion is the current version of the org policy document format.
+const PolicyVersion = 1
+
+// Policy errors.
+var (
+	ErrInvalidPolicy = errors.New("invalid policy")
+	ErrPolicyNoOwner = errors.New("policy must bind at least one owner")
+)
+
+// Policy is an org's declarative access configuration: built-in roles and
+// their permissions, member role bindings and deploy freeze windows. It is
+// exported as YAML so it can be kept in git and applied back through the API.
+// Roles are fixed and informational; an imported policy may omit them, but
+// any roles it lists must match the built-in definitions.
+type Policy struct {
+	Version       int                          `yaml:"version" json:"version"`
+	Org           string                       `yaml:"org,omitempty" json:"org,omitempty"` // Org slug, checked on import when set
+	Roles         map[models.Role][]Permission `yaml:"roles,omitempty" json:"roles,omitempty"`
+	Bindings      []PolicyBinding              `yaml:"bindings" json:"bindings"`
+	FreezeWindows []PolicyFreezeWindow         `yaml:"freeze_windows" json:"freeze_windows"`
+}
+
+// PolicyBinding grants a user, identified by email, a role in the org.
+type PolicyBinding struct {
+	User string      `yaml:"user" json:"user"`
+	Role models.Role `yaml:"role" json:"role"`
+}
+
+// PolicyFreezeWindow is a deploy freeze window identified by name.
+type PolicyFreezeWindow struct {
+	Name      string                  `yaml:"name" json:"name"`
+	Kind      models.FreezeWindowKind `yaml:"kind" json:"kind"`
+	StartDay  string                  `yaml:"start_day,omitempty" json:"start_day,omitempty"`
+	StartTime string                  `yaml:"start_time,omitempty" json:"start_time,omitempty"`
+	EndDay    string                  `yaml:"end_day,omitempty" json:"end_day,omitempty"`
+	EndTime   string                  `yaml:"end_time,omitempty" json:"end_time,omitempty"`
+	Timezone  string                  `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	StartsAt  *time.Time              `yaml:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt    *time.Time              `yaml:"ends_at,omitempty" json:"ends_at,omitempty"`
+}
+
+// Policy change actions and kinds.
+const (
+	PolicyActionAdd    = "add"
+	PolicyActionUpdate = "update"
+	PolicyActionRemove = "remove"
+
+	PolicyKindBinding      = "binding"
+	PolicyKindFreezeWindow = "freeze_window"
+)
+
+// PolicyChange is a single difference between the current and desired policy.
+// From and To summarize the old and new values for display in a diff.
+type PolicyChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// ParsePolicy decodes a YAML (or JSON) policy document. Unknown fields are
+// rejected so that typos do not silently drop configuration.
+func ParsePolicy(data []byte) (*Policy, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	return &p, nil
+}
+
+// Encode renders the policy as YAML.
+func (p *Policy) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Validate checks the policy document and normalizes freeze windows.
+func (p *Policy) Validate() error {
+	if p.Version != PolicyVersion {
+		return fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidPolicy, p.Version, PolicyVersion)
+	}
+
+	for role, perms := range p.Roles {
+		builtin, ok := orgRolePermissions[role]
+		if !ok {
+			return fmt.Errorf("%w: unknown role %q; custom roles are not supported", ErrInvalidPolicy, role)
+		}
+		if !samePermissions(builtin, perms) {
+			return fmt.Errorf("%w: permissions for role %q do not match the built-in role", ErrInvalidPolicy, role)
+		}
+	}
+
+	seenUsers := make(map[string]bool, len(p.Bindings))
+	hasOwner := false
+	for i := range p.Bindings {
+		b := &p.Bindings[i]
+		b.User = strings.TrimSpace(b.User)
+		if b.User == "" {
+			return fmt.Errorf("%w: binding %d: user is required", ErrInvalidPolicy, i+1)
+		}
+		if !b.Role.Valid() {
+			return fmt.Errorf("%w: binding for %s: role must be one of: owner, admin, developer, viewer, member", ErrInvalidPolicy, b.User)
+		}
+		if seenUsers[b.User] {
+			return fmt.Errorf("%w: duplicate binding for %s", ErrInvalidPolicy, b.User)
+		}
+		seenUsers[b.User] = true
+		hasOwner = hasOwner || b.Role == models.RoleOwner
+	}
+	if !hasOwner {
+		return ErrPolicyNoOwner
+	}
+
+	seenWindows := make(map[string]bool, len(p.FreezeWindows))
+	for i := range p.FreezeWindows {
+		window := p.FreezeWindows[i].model()
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("%w: freeze window %d: %v", ErrInvalidPolicy, i+1, err)
+		}
+		if seenWindows[window.Name] {
+			return fmt.Errorf("%w: duplicate freeze window %q", ErrInvalidPolicy, window.Name)
+		}
+		seenWindows[window.Name] = true
+		p.FreezeWindows[i] = policyFreezeWindow(window)
+	}
+	return nil
+}
+
+// samePermissions reports whether two permission lists hold the same set.
+func samePermissions(a, b []Permission) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[Permission]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// model converts the policy window to a freeze window model.
+func (w PolicyFreezeWindow) model() *models.FreezeWindow {
+	return &models.FreezeWindow{
+		Name:      w.Name,
+		Kind:      w.Kind,
+		StartDay:  w.StartDay,
+		StartTime: w.StartTime,
+		EndDay:    w.EndDay,
+		EndTime:   w.EndTime,
+		Timezone:  w.Timezone,
+		StartsAt:  w.StartsAt,
+		EndsAt:    w.EndsAt,
+	}
+}
+
+// policyFreezeWindow converts a freeze window model to its policy form.
+func policyFreezeWindow(w *models.FreezeWindow) PolicyFreezeWindow {
+	pw := PolicyFreezeWindow{
+		Name:      w.Name,
+		Kind:      w.Kind,
+		StartDay:  w.StartDay,
+		StartTime: w.StartTime,
+		EndDay:    w.EndDay,
+		EndTime:   w.EndTime,
+		Timezone:  w.Timezone,
+	}
+	if w.StartsAt != nil {
+		t := w.StartsAt.UTC()
+		pw.StartsAt = &t
+	}
+	if w.EndsAt != nil {
+		t := w.EndsAt.UTC()
+		pw.EndsAt = &t
+	}
+	return pw
+}
+
+// summary describes the window's schedule on one line.
+func (w PolicyFreezeWindow) summary() string {
+	if w.Kind == models.FreezeWindowRange && w.StartsAt != nil && w.EndsAt != nil {
+		return fmt.Sprintf("range %s to %s", w.StartsAt.UTC().Format(time.RFC3339), w.EndsAt.UTC().Format(time.RFC3339))
+	}
+	tz := w.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+	return fmt.Sprintf("recurring %s %s to %s %s %s", w.StartDay, w.StartTime, w.EndDay, w.EndTime, tz)
+}
+
+// DiffPolicy returns the changes needed to turn current into desired, with
+// bindings before freeze windows and each group sorted by name. Both policies
+// must have been validated.
+func DiffPolicy(current, desired *Policy) []PolicyChange {
+	changes := []PolicyChange{}
+
+	currentRoles := make(map[string]models.Role, len(current.Bindings))
+	for _, b := range current.Bindings {
+		currentRoles[b.User] = b.Role
+	}
+	desiredRoles := make(map[string]models.Role, len(desired.Bindings))
+	for _, b := range desired.Bindings {
+		desiredRoles[b.User] = b.Role
+	}
+	for _, user := range sortedKeys(currentRoles, desiredRoles) {
+		from, hadFrom := currentRoles[user]
+		to, hasTo := desiredRoles[user]
+		switch {
+		case !hadFrom:
+			changes = append(changes, PolicyChange{Action: PolicyActionAdd, Kind: PolicyKindBinding, Name: user, To: string(to)})
+		case !hasTo:
+			changes = append(changes, PolicyChange{Action: PolicyActionRemove, Kind: PolicyKindBinding, Name: user, From: string(from)})
+		case from != to:
+			changes = append(changes, PolicyChange{Action: PolicyActionUpdate, Kind: PolicyKindBinding, Name: user, From: string(from), To: string(to)})
+		}
+	}
+
+	currentWindows := make(map[string]string, len(current.FreezeWindows))
+	for _, w := range current.FreezeWindows {
+		currentWindows[w.Name] = w.summary()
+	}
+	desiredWindows := make(map[string]string, len(desired.FreezeWindows))
+	for _, w := range desired.FreezeWindows {
+		desiredWindows[w.Name] = w.summary()
+	}
+	for _, name := range sortedKeys(currentWindows, desiredWindows) {
+		from, hadFrom := currentWindows[name]
+		to, hasTo := desiredWindows[name]
+		switch {
+		case !hadFrom:
+			changes = append(changes, PolicyChange{Action: PolicyActionAdd, Kind: PolicyKindFreezeWindow, Name: name, To: to})
+		case !hasTo:
+			changes = append(changes, PolicyChange{Action: PolicyActionRemove, Kind: PolicyKindFreezeWindow, Name: name, From: from})
+		case from != to:
+			changes = append(changes, PolicyChange{Action: PolicyActionUpdate, Kind: PolicyKindFreezeWindow, Name: name, From: from, To: to})
+		}
+	}
+
+	return changes
+}
+
+// sortedKeys returns the union of the maps' keys in sorted order.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PolicyService exports and applies org policies.
+type PolicyService struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewPolicyService creates a new policy service.
+func NewPolicyService(st store.Store, logger *slog.Logger) *PolicyService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PolicyService{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// Export builds the org's current policy.
+func (s *PolicyService) Export(ctx context.Context, orgID string) (*Policy, error) {
+	policy, _, err := s.export(ctx, orgID)
+	return policy, err
+}
+
+// export builds the org's current policy along with the user ID of each
+// bound email.
+func (s *PolicyService) export(ctx context.Context, orgID string) (*Policy, map[string]string, error) {
+	org, err := s.store.Orgs().Get(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policy := &Policy{
+		Version:       PolicyVersion,
+		Org:           org.Slug,
+		Roles:         make(map[models.Role][]Permission, len(orgRolePermissions)),
+		Bindings:      []PolicyBinding{},
+		FreezeWindows: []PolicyFreezeWindow{},
+	}
+	for role, perms := range orgRolePermissions {
+		policy.Roles[role] = perms
+	}
+
+	members, err := s.store.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	userIDs := make(map[string]string, len(members))
+	for _, m := range members {
+		user, err := s.store.Users().GetByID(ctx, m.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if user == nil {
+			s.logger.Warn("skipping membership for missing user", "org_id", orgID, "user_id", m.UserID)
+			continue
+		}
+		userIDs[user.Email] = user.ID
+		policy.Bindings = append(policy.Bindings, PolicyBinding{User: user.Email, Role: m.Role})
+	}
+	sort.Slice(policy.Bindings, func(i, j int) bool { return policy.Bindings[i].User < policy.Bindings[j].User })
+
+	windows, err := s.store.DeployFreezes().ListWindows(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, w := range windows {
+		policy.FreezeWindows = append(policy.FreezeWindows, policyFreezeWindow(w))
+	}
+	sort.SliceStable(policy.FreezeWindows, func(i, j int) bool { return policy.FreezeWindows[i].Name < policy.FreezeWindows[j].Name })
+
+	return policy, userIDs, nil
+}
+
+// Apply validates the desired policy and returns the changes it makes to the
+// org. When dryRun is true nothing is written. Changes are applied in a
+// single transaction. Every bound user must already have an account.
+func (s *PolicyService) Apply(ctx context.Context, orgID, userID string, desired *Policy, dryRun bool) ([]PolicyChange, error) {
+	if err := desired.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, userIDs, err := s.export(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if desired.Org != "" && desired.Org != current.Org {
+		return nil, fmt.Errorf("%w: policy is for org %q, not %q", ErrInvalidPolicy, desired.Org, current.Org)
+	}
+
+	for _, b := range desired.Bindings {
+		if _, ok := userIDs[b.User]; ok {
+			continue
+		}
+		user, err := s.store.Users().GetByEmail(ctx, b.User)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("%w: no user with email %s", ErrInvalidPolicy, b.User)
+		}
+		userIDs[b.User] = user.ID
+	}
+
+	changes := DiffPolicy(current, desired)
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	desiredWindows := make(map[string]PolicyFreezeWindow, len(desired.FreezeWindows))
+	for _, w := range desired.FreezeWindows {
+		desiredWindows[w.Name] = w
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		existing, err := tx.DeployFreezes().ListWindows(ctx, orgID)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			switch c.Kind {
+			case PolicyKindBinding:
+				if c.Action == PolicyActionRemove {
+					err = tx.Orgs().RemoveMember(ctx, orgID, userIDs[c.Name])
+				} else {
+					err = tx.Orgs().AddMember(ctx, orgID, userIDs[c.Name], models.Role(c.To))
+				}
+			case PolicyKindFreezeWindow:
+				// Windows have no update; replace any with the same name
+				for _, w := range existing {
+					if w.Name == c.Name {
+						if err := tx.DeployFreezes().DeleteWindow(ctx, w.ID); err != nil {
+							return err
+						}
+					}
+				}
+				if c.Action != PolicyActionRemove {
+					window := desiredWindows[c.Name].model()
+					window.OrgID = orgID
+					window.CreatedBy = userID
+					err = tx.DeployFreezes().CreateWindow(ctx, window)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("applying %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("org policy applied", "org_id", orgID, "user_id", userID, "changes", len(changes))
+	return changes, nil
+}
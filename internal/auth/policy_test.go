@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+const testPolicy = `
+version: 1
+org: acme
+bindings:
+  - user: alice@example.com
+    role: owner
+  - user: bob@example.com
+    role: member
+freeze_windows:
+  - name: Weekend
+    kind: recurring
+    start_day: Friday
+    start_time: "16:00"
+    end_day: monday
+    end_time: "08:00"
+    timezone: Europe/Berlin
+  - name: Holidays
+    kind: range
+    starts_at: 2026-12-24T00:00:00Z
+    ends_at: 2026-12-27T00:00:00Z
+`
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(p.Bindings) != 2 || len(p.FreezeWindows) != 2 {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+	if p.FreezeWindows[0].StartDay != "friday" {
+		t.Errorf("start_day not normalized: %q", p.FreezeWindows[0].StartDay)
+	}
+
+	// Encoding and parsing again yields the same policy
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	again, err := ParsePolicy(data)
+	if err != nil {
+		t.Fatalf("ParsePolicy(Encode): %v\n%s", err, data)
+	}
+	if len(DiffPolicy(p, again)) != 0 {
+		t.Errorf("round trip changed the policy:\n%s", data)
+	}
+}
+
+func TestParsePolicy_UnknownField(t *testing.T) {
+	_, err := ParsePolicy([]byte("version: 1\nbindngs: []\n"))
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expected ErrInvalidPolicy for a misspelled field, got %v", err)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	owner := PolicyBinding{User: "alice@example.com", Role: models.RoleOwner}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		err    error
+	}{
+		{"wrong version", Policy{Version: 2, Bindings: []PolicyBinding{owner}}, ErrInvalidPolicy},
+		{"no owner", Policy{Version: 1, Bindings: []PolicyBinding{{User: "bob@example.com", Role: models.RoleMember}}}, ErrPolicyNoOwner},
+		{"invalid role", Policy{Version: 1, Bindings: []PolicyBinding{owner, {User: "bob@example.com", Role: "superuser"}}}, ErrInvalidPolicy},
+		{"duplicate user", Policy{Version: 1, Bindings: []PolicyBinding{owner, owner}}, ErrInvalidPolicy},
+		{"custom role", Policy{Version: 1, Roles: map[models.Role][]Permission{"deployer": {PermissionDeploy}}, Bindings: []PolicyBinding{owner}}, ErrInvalidPolicy},
+		{"modified role", Policy{Version: 1, Roles: map[models.Role][]Permission{models.RoleMember: {PermissionDeploy}}, Bindings: []PolicyBinding{owner}}, ErrInvalidPolicy},
+		{"invalid window", Policy{Version: 1, Bindings: []PolicyBinding{owner}, FreezeWindows: []PolicyFreezeWindow{{Name: "x", Kind: "daily"}}}, ErrInvalidPolicy},
+		{"built-in roles", Policy{Version: 1, Roles: map[models.Role][]Permission{models.RoleMember: {PermissionDeploy, PermissionManageApps, PermissionViewApps}}, Bindings: []PolicyBinding{owner}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Validate() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDiffPolicy(t *testing.T) {
+	current := &Policy{
+		Version: 1,
+		Bindings: []PolicyBinding{
+			{User: "alice@example.com", Role: models.RoleOwner},
+			{User: "bob@example.com", Role: models.RoleOwner},
+			{User: "carol@example.com", Role: models.RoleMember},
+		},
+		FreezeWindows: []PolicyFreezeWindow{
+			{Name: "Weekend", Kind: models.FreezeWindowRecurring, StartDay: "friday", StartTime: "16:00", EndDay: "monday", EndTime: "08:00"},
+			{Name: "Launch", Kind: models.FreezeWindowRecurring, StartDay: "monday", StartTime: "00:00", EndDay: "tuesday", EndTime: "00:00"},
+		},
+	}
+	desired, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	if err := desired.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	var got []string
+	for _, c := range DiffPolicy(current, desired) {
+		got = append(got, strings.Join([]string{c.Action, c.Kind, c.Name}, " "))
+	}
+	want := []string{
+		"update binding bob@example.com",
+		"remove binding carol@example.com",
+		"add freeze_window Holidays",
+		"remove freeze_window Launch",
+		"update freeze_window Weekend",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffPolicy() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := DiffPolicy(desired, desired); len(changes) != 0 {
+		t.Errorf("identical policies produced changes: %+v", changes)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/narvanalabs/control-plane/internal/models"
@@ -24,6 +25,8 @@ var (
 	ErrInvitationExpired   = errors.New("invitation has expired")
 	ErrInvitationUsed      = errors.New("invitation has already been used")
 	ErrEmailAlreadyInvited = errors.New("email has already been invited")
+	ErrAlreadyMember       = errors.New("user is already a member of the organization")
+	ErrInvitationEmail     = errors.New("invitation was sent to a different email address")
 )
 
 // Permission represents an action that can be performed.
@@ -42,6 +45,24 @@ const (
 	PermissionViewApps Permission = "view_apps"
 	// PermissionDeploy allows deploying services.
 	PermissionDeploy Permission = "deploy"
+	// PermissionAdminConsole allows access to the instance admin console.
+	PermissionAdminConsole Permission = "admin_console"
+	// PermissionManageFreezes allows creating and deleting deploy freeze windows.
+	PermissionManageFreezes Permission = "manage_freezes"
+	// PermissionOverrideFreeze allows deploying during an active freeze window.
+	PermissionOverrideFreeze Permission = "override_freeze"
+	// PermissionNodeSSH allows opening SSH sessions to nodes through the SSH broker.
+	PermissionNodeSSH Permission = "node_ssh"
+	// PermissionManageAdmissionPolicies allows creating, changing and deleting deploy admission policies.
+	PermissionManageAdmissionPolicies Permission = "manage_admission_policies"
+	// PermissionManageSharedSecrets allows creating, changing and deleting org secrets shared into apps.
+	PermissionManageSharedSecrets Permission = "manage_shared_secrets"
+	// PermissionManageOrgMembers allows inviting, removing and changing the roles of org members.
+	PermissionManageOrgMembers Permission = "manage_org_members"
+	// PermissionDebugWorkloads allows starting debug containers next to running deployments.
+	PermissionDebugWorkloads Permission = "debug_workloads"
+	// PermissionExportEvidence allows compiling and downloading evidence packages for compliance audits.
+	PermissionExportEvidence Permission = "export_evidence"
 )
 
 // InvitationExpiry is the default duration for invitation validity.
@@ -56,6 +77,15 @@ var rolePermissions = map[store.Role][]Permission{
 		PermissionManageApps,
 		PermissionViewApps,
 		PermissionDeploy,
+		PermissionAdminConsole,
+		PermissionManageFreezes,
+		PermissionOverrideFreeze,
+		PermissionNodeSSH,
+		PermissionManageAdmissionPolicies,
+		PermissionManageSharedSecrets,
+		PermissionManageOrgMembers,
+		PermissionDebugWorkloads,
+		PermissionExportEvidence,
 	},
 	store.RoleMember: {
 		PermissionViewApps,
@@ -63,6 +93,49 @@ var rolePermissions = map[store.Role][]Permission{
 	},
 }
 
+// orgRolePermissions defines which permissions each org role grants within
+// its organization. Deleting the org and appointing owners are reserved for
+// owners and checked against the role directly.
+var orgRolePermissions = map[models.Role][]Permission{
+	models.RoleOwner: {
+		PermissionViewApps,
+		PermissionManageApps,
+		PermissionDeploy,
+		PermissionManageFreezes,
+		PermissionOverrideFreeze,
+		PermissionManageAdmissionPolicies,
+		PermissionManageSharedSecrets,
+		PermissionManageOrgMembers,
+		PermissionDebugWorkloads,
+		PermissionExportEvidence,
+	},
+	models.RoleAdmin: {
+		PermissionViewApps,
+		PermissionManageApps,
+		PermissionDeploy,
+		PermissionManageFreezes,
+		PermissionOverrideFreeze,
+		PermissionManageAdmissionPolicies,
+		PermissionManageSharedSecrets,
+		PermissionManageOrgMembers,
+		PermissionDebugWorkloads,
+		PermissionExportEvidence,
+	},
+	models.RoleDeveloper: {
+		PermissionViewApps,
+		PermissionManageApps,
+		PermissionDeploy,
+	},
+	models.RoleMember: {
+		PermissionViewApps,
+		PermissionManageApps,
+		PermissionDeploy,
+	},
+	models.RoleViewer: {
+		PermissionViewApps,
+	},
+}
+
 // RBACService provides role-based access control functionality.
 type RBACService struct {
 	store  store.Store
@@ -116,6 +189,16 @@ func CheckRolePermission(role store.Role, permission Permission) error {
 	return ErrPermissionDenied
 }
 
+// CheckOrgRolePermission checks if an org role grants a permission.
+func CheckOrgRolePermission(role models.Role, permission Permission) error {
+	for _, p := range orgRolePermissions[role] {
+		if p == permission {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
 // CreateInvitation creates an invitation for a new user.
 func (s *RBACService) CreateInvitation(ctx context.Context, email string, invitedBy string, role store.Role) (*models.Invitation, error) {
 	// Check if email is already invited
@@ -130,12 +213,10 @@ func (s *RBACService) CreateInvitation(ctx context.Context, email string, invite
 		return nil, errors.New("user with this email already exists")
 	}
 
-	// Generate token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
+	token, err := newInvitationToken()
+	if err != nil {
 		return nil, err
 	}
-	token := hex.EncodeToString(tokenBytes)
 
 	invitation := &models.Invitation{
 		Email:     email,
@@ -175,6 +256,11 @@ func (s *RBACService) AcceptInvitation(ctx context.Context, token, password stri
 	if err != nil {
 		return nil, err
 	}
+	if invitation.OrgID != "" {
+		if err := s.store.Orgs().AddMember(ctx, invitation.OrgID, user.ID, invitation.OrgRole); err != nil {
+			s.logger.Error("failed to add invited user to organization", "error", err, "org_id", invitation.OrgID)
+		}
+	}
 
 	// Mark invitation as accepted
 	now := time.Now()
@@ -187,6 +273,106 @@ func (s *RBACService) AcceptInvitation(ctx context.Context, token, password stri
 	return user, nil
 }
 
+// CreateOrgInvitation invites someone to join an organization with a role.
+// Invitees without an account create one when accepting; existing users
+// accept while signed in with the invited email.
+func (s *RBACService) CreateOrgInvitation(ctx context.Context, orgID, email, invitedBy string, role models.Role) (*models.Invitation, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	invitations, err := s.store.Invitations().ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, inv := range invitations {
+		if strings.EqualFold(inv.Email, email) && inv.IsValid() {
+			return nil, ErrEmailAlreadyInvited
+		}
+	}
+
+	user, err := s.store.Users().GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		isMember, err := s.store.Orgs().IsMember(ctx, orgID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		Token:     token,
+		InvitedBy: invitedBy,
+		Role:      models.Role(store.RoleMember),
+		OrgID:     orgID,
+		OrgRole:   role,
+		Status:    models.InvitationStatusPending,
+		ExpiresAt: time.Now().Add(InvitationExpiry),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.Invitations().Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptOrgInvitation adds an existing user to the organization an
+// invitation was sent for. The user's email must match the invitation.
+func (s *RBACService) AcceptOrgInvitation(ctx context.Context, token string, user *store.User) (*models.Invitation, error) {
+	invitation, err := s.store.Invitations().GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	// Invitations without an org create accounts and are accepted publicly
+	if invitation == nil || invitation.OrgID == "" {
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, ErrInvitationUsed
+	}
+	if invitation.IsExpired() {
+		return nil, ErrInvitationExpired
+	}
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, ErrInvitationEmail
+	}
+
+	if err := s.store.Orgs().AddMember(ctx, invitation.OrgID, user.ID, invitation.OrgRole); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.Status = models.InvitationStatusAccepted
+	invitation.AcceptedAt = &now
+	if err := s.store.Invitations().Update(ctx, invitation); err != nil {
+		s.logger.Error("failed to update invitation status", "error", err)
+	}
+
+	return invitation, nil
+}
+
+// newInvitationToken generates a random invitation token.
+func newInvitationToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
 // RevokeInvitation revokes a pending invitation.
 func (s *RBACService) RevokeInvitation(ctx context.Context, invitationID string) error {
 	invitation, err := s.store.Invitations().Get(ctx, invitationID)
@@ -98,6 +98,51 @@ func (m *mockStoreRBAC) GitHubAccounts() store.GitHubAccountStore
 func (m *mockStoreRBAC) Settings() store.SettingsStore                                { return nil }
 func (m *mockStoreRBAC) Domains() store.DomainStore                                   { return nil }
 func (m *mockStoreRBAC) Invitations() store.InvitationStore                           { return nil }
+func (m *mockStoreRBAC) Announcements() store.AnnouncementStore                       { return nil }
+func (m *mockStoreRBAC) Releases() store.ReleaseStore                                 { return nil }
+func (m *mockStoreRBAC) Stats() store.StatsStore                                      { return nil }
+func (m *mockStoreRBAC) EgressViolations() store.EgressViolationStore                 { return nil }
+func (m *mockStoreRBAC) DeployFreezes() store.DeployFreezeStore                       { return nil }
+func (m *mockStoreRBAC) OnCall() store.OnCallStore                                    { return nil }
+func (m *mockStoreRBAC) APICatalog() store.APICatalogStore                            { return nil }
+func (m *mockStoreRBAC) SCIM() store.SCIMStore                                        { return nil }
+func (m *mockStoreRBAC) APIKeys() store.APIKeyStore                                   { return nil }
+func (m *mockStoreRBAC) Notifications() store.NotificationStore                       { return nil }
+func (m *mockStoreRBAC) Promotions() store.PromotionStore                             { return nil }
+func (m *mockStoreRBAC) SSH() store.SSHStore                                          { return nil }
+func (m *mockStoreRBAC) BuildWorkers() store.BuildWorkerStore                         { return nil }
+func (m *mockStoreRBAC) ServiceTemplates() store.ServiceTemplateStore                 { return nil }
+func (m *mockStoreRBAC) BuildLogs() store.BuildLogStore                               { return nil }
+func (m *mockStoreRBAC) BuildSnapshots() store.BuildSnapshotStore                     { return nil }
+func (m *mockStoreRBAC) AppHooks() store.AppHookStore                                 { return nil }
+func (m *mockStoreRBAC) CDN() store.CDNStore                                          { return nil }
+func (m *mockStoreRBAC) BuildAttestations() store.BuildAttestationStore               { return nil }
+func (m *mockStoreRBAC) CronRuns() store.CronRunStore                                 { return nil }
+func (m *mockStoreRBAC) Metrics() store.MetricStore                                   { return nil }
+func (m *mockStoreRBAC) AdmissionPolicies() store.AdmissionPolicyStore                { return nil }
+func (m *mockStoreRBAC) Audit() store.AuditStore                                      { return nil }
+func (m *mockStoreRBAC) OrgSecrets() store.OrgSecretStore                             { return nil }
+func (m *mockStoreRBAC) NodeJoinTokens() store.NodeJoinTokenStore                     { return nil }
+func (m *mockStoreRBAC) SmokeTests() store.SmokeTestStore                             { return nil }
+func (m *mockStoreRBAC) LoadTests() store.LoadTestStore                               { return nil }
+func (m *mockStoreRBAC) Archives() store.ArchiveStore                                 { return nil }
+func (m *mockStoreRBAC) Operations() store.OperationStore                             { return nil }
+func (m *mockStoreRBAC) DebugSessions() store.DebugSessionStore                       { return nil }
+func (m *mockStoreRBAC) AuthSessions() store.AuthSessionStore                         { return nil }
+func (m *mockStoreRBAC) RateLimits() store.RateLimitStore                             { return nil }
+func (m *mockStoreRBAC) Consistency() store.ConsistencyStore                          { return nil }
+func (m *mockStoreRBAC) DBHealth() store.DBHealthStore                                { return nil }
+func (m *mockStoreRBAC) AppManifests() store.AppManifestStore                         { return nil }
+func (m *mockStoreRBAC) IdempotencyKeys() store.IdempotencyKeyStore                   { return nil }
+func (m *mockStoreRBAC) ScaleEvents() store.ScaleEventStore                           { return nil }
+func (m *mockStoreRBAC) Environments() store.EnvironmentStore                         { return nil }
+func (m *mockStoreRBAC) Federation() store.FederationStore                            { return nil }
+func (m *mockStoreRBAC) EvidenceExports() store.EvidenceExportStore                   { return nil }
+func (m *mockStoreRBAC) APIUsage() store.APIUsageStore                                { return nil }
+func (m *mockStoreRBAC) Events() store.EventStore                                     { return nil }
+func (m *mockStoreRBAC) Webhooks() store.WebhookStore                                 { return nil }
+func (m *mockStoreRBAC) AppTemplates() store.AppTemplateStore                         { return nil }
+func (m *mockStoreRBAC) Services() store.ServiceStore                                 { return nil }
 func (m *mockStoreRBAC) WithTx(ctx context.Context, fn func(store.Store) error) error { return nil }
 func (m *mockStoreRBAC) Close() error                                                 { return nil }
 func (m *mockStoreRBAC) Ping(ctx context.Context) error                               { return nil }
@@ -278,6 +323,15 @@ func genAdminPermission() gopter.Gen {
 		PermissionManageUsers,
 		PermissionManageSettings,
 		PermissionViewUsers,
+		PermissionAdminConsole,
+		PermissionManageFreezes,
+		PermissionOverrideFreeze,
+		PermissionNodeSSH,
+		PermissionManageAdmissionPolicies,
+		PermissionManageSharedSecrets,
+		PermissionManageOrgMembers,
+		PermissionDebugWorkloads,
+		PermissionExportEvidence,
 	)
 }
 
@@ -298,6 +352,15 @@ func genAnyPermission() gopter.Gen {
 		PermissionManageApps,
 		PermissionViewApps,
 		PermissionDeploy,
+		PermissionAdminConsole,
+		PermissionManageFreezes,
+		PermissionOverrideFreeze,
+		PermissionNodeSSH,
+		PermissionManageAdmissionPolicies,
+		PermissionManageSharedSecrets,
+		PermissionManageOrgMembers,
+		PermissionDebugWorkloads,
+		PermissionExportEvidence,
 	)
 }
 
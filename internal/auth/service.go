@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
 )
 
 // Common errors returned by the auth service.
@@ -26,10 +28,13 @@ var (
 	ErrInvalidSignature = errors.New("invalid token signature")
 )
 
-// User represents an authenticated user.
+// User represents an authenticated user. Requests made with a scoped API key
+// carry the key's scopes.
 type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
+	ID       string               `json:"id"`
+	Email    string               `json:"email"`
+	APIKeyID string               `json:"api_key_id,omitempty"`
+	Scopes   []models.APIKeyScope `json:"scopes,omitempty"`
 }
 
 // Claims represents the JWT claims structure.
@@ -37,17 +42,16 @@ type Claims struct {
 	UserID string    `json:"user_id"`
 	Email  string    `json:"email"`
 	Exp    time.Time `json:"exp"`
+	// SessionID is the sign-in session an access token was issued for;
+	// empty for tokens issued outside of a session
+	SessionID string `json:"session_id,omitempty"`
+	// ImpersonatedBy is the admin a token was issued to while impersonating
+	// the user; empty for the user's own tokens
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 }
 
 // APIKey represents a stored API key.
-type APIKey struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	KeyHash   string    `json:"-"` // SHA256 hash of the key
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at,omitempty"`
-}
+type APIKey = models.APIKey
 
 // APIKeyStore defines the interface for API key storage.
 type APIKeyStore interface {
@@ -59,20 +63,30 @@ type APIKeyStore interface {
 	Delete(ctx context.Context, id string) error
 	// ListByUser retrieves all API keys for a user.
 	ListByUser(ctx context.Context, userID string) ([]*APIKey, error)
+	// MarkUsed records that an API key was used.
+	MarkUsed(ctx context.Context, id string) error
 }
 
 // Config holds authentication configuration.
 type Config struct {
 	JWTSecret   []byte
 	TokenExpiry time.Duration
+	// AccessTokenExpiry and RefreshTokenExpiry are the lifetimes of the
+	// tokens of sign-in sessions; zero uses the defaults
+	AccessTokenExpiry  time.Duration
+	RefreshTokenExpiry time.Duration
 }
 
 // Service provides authentication and authorization functionality.
 type Service struct {
-	jwtSecret   []byte
-	tokenExpiry time.Duration
-	apiKeyStore APIKeyStore
-	logger      *slog.Logger
+	jwtSecret          []byte
+	tokenExpiry        time.Duration
+	accessTokenExpiry  time.Duration
+	refreshTokenExpiry time.Duration
+	apiKeyStore        APIKeyStore
+	sessions           store.AuthSessionStore
+	users              store.UserStore
+	logger             *slog.Logger
 }
 
 // NewService creates a new authentication service.
@@ -80,12 +94,21 @@ func NewService(cfg *Config, apiKeyStore APIKeyStore, logger *slog.Logger) *Serv
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Service{
-		jwtSecret:   cfg.JWTSecret,
-		tokenExpiry: cfg.TokenExpiry,
-		apiKeyStore: apiKeyStore,
-		logger:      logger,
+	s := &Service{
+		jwtSecret:          cfg.JWTSecret,
+		tokenExpiry:        cfg.TokenExpiry,
+		accessTokenExpiry:  cfg.AccessTokenExpiry,
+		refreshTokenExpiry: cfg.RefreshTokenExpiry,
+		apiKeyStore:        apiKeyStore,
+		logger:             logger,
+	}
+	if s.accessTokenExpiry <= 0 {
+		s.accessTokenExpiry = DefaultAccessTokenExpiry
+	}
+	if s.refreshTokenExpiry <= 0 {
+		s.refreshTokenExpiry = DefaultRefreshTokenExpiry
 	}
+	return s
 }
 
 // GenerateToken creates a new JWT token for the given user.
@@ -115,6 +138,39 @@ func (s *Service) GenerateToken(userID, email string) (string, error) {
 	return signedToken, nil
 }
 
+// ImpersonationTokenExpiry is the lifetime of the tokens issued to admins
+// impersonating a user. They are not tied to a session and can't be
+// refreshed, so they are kept short.
+const ImpersonationTokenExpiry = 30 * time.Minute
+
+// GenerateImpersonationToken creates a short-lived JWT token for the given
+// user on behalf of an admin, who is named in its impersonated_by claim.
+// It returns the token and when it expires.
+func (s *Service) GenerateImpersonationToken(userID, email, adminID string) (string, time.Time, error) {
+	if userID == "" || adminID == "" {
+		return "", time.Time{}, ErrMissingClaims
+	}
+
+	now := time.Now()
+	exp := now.Add(ImpersonationTokenExpiry)
+
+	claims := jwt.MapClaims{
+		"sub":             userID,
+		"email":           email,
+		"impersonated_by": adminID,
+		"iat":             now.Unix(),
+		"exp":             exp.Unix(),
+		"nbf":             now.Unix(),
+	}
+
+	signedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		s.logger.Error("failed to sign token", "error", err)
+		return "", time.Time{}, fmt.Errorf("signing token: %w", err)
+	}
+	return signedToken, exp, nil
+}
+
 // ValidateToken validates a JWT token and returns the claims.
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 	if tokenString == "" {
@@ -166,10 +222,16 @@ func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 	}
 	exp := time.Unix(int64(expFloat), 0)
 
+	// Extract the session and impersonating admin (optional)
+	sessionID, _ := mapClaims["sid"].(string)
+	impersonatedBy, _ := mapClaims["impersonated_by"].(string)
+
 	return &Claims{
-		UserID: userID,
-		Email:  email,
-		Exp:    exp,
+		UserID:         userID,
+		Email:          email,
+		Exp:            exp,
+		SessionID:      sessionID,
+		ImpersonatedBy: impersonatedBy,
 	}, nil
 }
 
@@ -202,11 +264,25 @@ func (s *Service) ValidateAPIKey(ctx context.Context, apiKey string) (*User, err
 		return nil, ErrExpiredToken
 	}
 
+	if err := s.apiKeyStore.MarkUsed(ctx, storedKey.ID); err != nil {
+		s.logger.Warn("failed to record API key use", "error", err, "key_id", storedKey.ID)
+	}
+
 	return &User{
-		ID: storedKey.UserID,
+		ID:       storedKey.UserID,
+		APIKeyID: storedKey.ID,
+		Scopes:   storedKey.Scopes,
 	}, nil
 }
 
+// IsAPIKey reports whether token looks like an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// apiKeyPrefix starts every API key so they can be told apart from JWTs.
+const apiKeyPrefix = "nrv_"
+
 // GenerateAPIKey generates a new API key and returns the raw key.
 // The raw key should be shown to the user once and never stored.
 func GenerateAPIKey() (string, error) {
@@ -217,7 +293,7 @@ func GenerateAPIKey() (string, error) {
 	}
 
 	// Encode as base64 with a prefix for identification
-	key := "nrv_" + base64.RawURLEncoding.EncodeToString(bytes)
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(bytes)
 	return key, nil
 }
 
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Default lifetimes of the tokens of sign-in sessions.
+const (
+	DefaultAccessTokenExpiry  = 15 * time.Minute
+	DefaultRefreshTokenExpiry = 30 * 24 * time.Hour
+)
+
+// refreshTokenPrefix identifies refresh tokens.
+const refreshTokenPrefix = "nrt_"
+
+// Session errors returned by the auth service.
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// TokenPair is the tokens a sign-in session hands to its device: a
+// short-lived access token and the refresh token that renews it.
+type TokenPair struct {
+	SessionID        string    `json:"session_id,omitempty"`
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token,omitempty"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at,omitempty"`
+}
+
+// SetSessionStore makes sign-ins start sessions kept in sessions, whose
+// users are looked up in users when their tokens are refreshed. Without it
+// sign-ins get a plain access token that cannot be refreshed or revoked.
+func (s *Service) SetSessionStore(sessions store.AuthSessionStore, users store.UserStore) {
+	s.sessions = sessions
+	s.users = users
+}
+
+// StartSession signs a user in on a device, identified by its user agent
+// and IP address, and returns the session's tokens.
+func (s *Service) StartSession(ctx context.Context, userID, email, userAgent, ipAddress string) (*TokenPair, error) {
+	if s.sessions == nil {
+		token, err := s.GenerateToken(userID, email)
+		if err != nil {
+			return nil, err
+		}
+		return &TokenPair{AccessToken: token, AccessExpiresAt: time.Now().Add(s.tokenExpiry)}, nil
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &models.AuthSession{
+		UserID:           userID,
+		RefreshTokenHash: HashAPIKey(refreshToken),
+		UserAgent:        truncate(userAgent, 512),
+		IPAddress:        ipAddress,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(s.refreshTokenExpiry),
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("creating session: %w", err)
+	}
+	return s.sessionTokens(session, email, refreshToken, now)
+}
+
+// RefreshSession exchanges a refresh token for new tokens of its session.
+// The refresh token is rotated: the one exchanged stops working.
+func (s *Service) RefreshSession(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if s.sessions == nil || !strings.HasPrefix(refreshToken, refreshTokenPrefix) {
+		return nil, ErrInvalidRefreshToken
+	}
+	hash := HashAPIKey(refreshToken)
+	session, err := s.sessions.GetByRefreshHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting session: %w", err)
+	}
+	now := time.Now()
+	if session == nil || !session.Active(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTokenExpiry)
+	rotated, err := s.sessions.Rotate(ctx, session.ID, hash, HashAPIKey(next), session.LastUsedAt, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.sessionTokens(session, user.Email, next, now)
+}
+
+// CheckSession returns ErrSessionRevoked if the access token's session has
+// been revoked or has expired. Tokens not issued for a session pass.
+func (s *Service) CheckSession(ctx context.Context, claims *Claims) error {
+	if claims.SessionID == "" || s.sessions == nil {
+		return nil
+	}
+	session, err := s.sessions.Get(ctx, claims.SessionID)
+	if err != nil {
+		return fmt.Errorf("getting session: %w", err)
+	}
+	if session == nil || session.UserID != claims.UserID || !session.Active(time.Now()) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// sessionTokens issues an access token naming the session.
+func (s *Service) sessionTokens(session *models.AuthSession, email, refreshToken string, now time.Time) (*TokenPair, error) {
+	exp := now.Add(s.accessTokenExpiry)
+	claims := jwt.MapClaims{
+		"sub":   session.UserID,
+		"email": email,
+		"sid":   session.ID,
+		"iat":   now.Unix(),
+		"exp":   exp.Unix(),
+		"nbf":   now.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		s.logger.Error("failed to sign token", "error", err)
+		return nil, fmt.Errorf("signing token: %w", err)
+	}
+	return &TokenPair{
+		SessionID:        session.ID,
+		AccessToken:      token,
+		AccessExpiresAt:  exp,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// generateRefreshToken generates a new random refresh token.
+func generateRefreshToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("generating refresh token: %w", err)
+	}
+	return refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memSessions is an in-memory session store.
+type memSessions struct {
+	store.AuthSessionStore
+	sessions map[string]*models.AuthSession
+}
+
+func (m *memSessions) Create(ctx context.Context, s *models.AuthSession) error {
+	s.ID = "session-1"
+	copied := *s
+	m.sessions[s.ID] = &copied
+	return nil
+}
+
+func (m *memSessions) Get(ctx context.Context, id string) (*models.AuthSession, error) {
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (m *memSessions) GetByRefreshHash(ctx context.Context, hash string) (*models.AuthSession, error) {
+	for _, s := range m.sessions {
+		if s.RefreshTokenHash == hash {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memSessions) Rotate(ctx context.Context, id, oldHash, newHash string, usedAt, expiresAt time.Time) (bool, error) {
+	s := m.sessions[id]
+	if s == nil || s.RefreshTokenHash != oldHash || s.RevokedAt != nil {
+		return false, nil
+	}
+	s.RefreshTokenHash, s.LastUsedAt, s.ExpiresAt = newHash, usedAt, expiresAt
+	return true, nil
+}
+
+func (m *memSessions) Revoke(ctx context.Context, id string) error {
+	now := time.Now()
+	m.sessions[id].RevokedAt = &now
+	return nil
+}
+
+type memUsers struct {
+	store.UserStore
+}
+
+func (memUsers) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return &store.User{ID: id, Email: "ada@example.com"}, nil
+}
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	sessions := &memSessions{sessions: map[string]*models.AuthSession{}}
+	svc := NewService(&Config{JWTSecret: []byte("4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c"), TokenExpiry: time.Hour}, nil, nil)
+	svc.SetSessionStore(sessions, memUsers{})
+
+	pair, err := svc.StartSession(ctx, "user-1", "ada@example.com", "curl/8.0", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	if until := time.Until(pair.AccessExpiresAt); until > DefaultAccessTokenExpiry || until < DefaultAccessTokenExpiry-time.Minute {
+		t.Errorf("access token expires in %v, want %v", until, DefaultAccessTokenExpiry)
+	}
+	claims, err := svc.ValidateToken(pair.AccessToken)
+	if err != nil || claims.SessionID != "session-1" || claims.UserID != "user-1" {
+		t.Fatalf("ValidateToken() = %+v, %v", claims, err)
+	}
+	if err := svc.CheckSession(ctx, claims); err != nil {
+		t.Errorf("CheckSession() of an active session = %v", err)
+	}
+
+	// Refreshing rotates the refresh token
+	next, err := svc.RefreshSession(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if next.RefreshToken == pair.RefreshToken || next.SessionID != pair.SessionID {
+		t.Errorf("refreshed tokens = %+v, want a new refresh token for the same session", next)
+	}
+	if _, err := svc.RefreshSession(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() with a used refresh token = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Revoking the session stops its access and refresh tokens
+	sessions.Revoke(ctx, "session-1")
+	if err := svc.CheckSession(ctx, claims); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("CheckSession() of a revoked session = %v, want ErrSessionRevoked", err)
+	}
+	if _, err := svc.RefreshSession(ctx, next.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() of a revoked session = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Tokens issued outside of a session are not checked
+	token, _ := svc.GenerateToken("user-1", "ada@example.com")
+	legacy, _ := svc.ValidateToken(token)
+	if err := svc.CheckSession(ctx, legacy); err != nil {
+		t.Errorf("CheckSession() of a token without a session = %v", err)
+	}
+}
+
+func TestImpersonationToken(t *testing.T) {
+	svc := NewService(&Config{JWTSecret: []byte("4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c"), TokenExpiry: 24 * time.Hour}, nil, nil)
+
+	token, expiresAt, err := svc.GenerateImpersonationToken("user-1", "ada@example.com", "admin-1")
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken: %v", err)
+	}
+	if d := time.Until(expiresAt); d <= 0 || d > ImpersonationTokenExpiry {
+		t.Errorf("token expires in %v, want at most %v", d, ImpersonationTokenExpiry)
+	}
+
+	claims, err := svc.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.ImpersonatedBy != "admin-1" || claims.SessionID != "" {
+		t.Errorf("claims = %+v, want user-1 impersonated by admin-1", claims)
+	}
+
+	// The user's own tokens name no impersonator
+	own, _ := svc.GenerateToken("user-1", "ada@example.com")
+	if claims, _ := svc.ValidateToken(own); claims == nil || claims.ImpersonatedBy != "" {
+		t.Errorf("own token claims = %+v", claims)
+	}
+
+	if _, _, err := svc.GenerateImpersonationToken("user-1", "ada@example.com", ""); !errors.Is(err, ErrMissingClaims) {
+		t.Errorf("without admin: err = %v, want ErrMissingClaims", err)
+	}
+}
@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+)
+
+// SetSigner sets the key the worker signs the provenance of successful
+// builds with. Without one no attestations are produced.
+func (w *Worker) SetSigner(s *provenance.Signer) {
+	w.signer = s
+}
+
+// attest signs the SLSA provenance of a successful build and stores it with
+// the build. commit is the source commit the build's deployment resolved.
+// Failures are logged and do not fail the build.
+func (w *Worker) attest(ctx context.Context, job *models.BuildJob, commit string, logCallback func(string)) {
+	if w.signer == nil {
+		return
+	}
+
+	workerID, _ := os.Hostname()
+	if w.coordinator != nil {
+		workerID = w.coordinator.WorkerID()
+	}
+
+	envelope, err := w.signer.Sign(provenance.Generate(job, commit, workerID, WorkerVersion))
+	if err != nil {
+		w.logger.Error("failed to sign build provenance", "job_id", job.ID, "error", err)
+		return
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		w.logger.Error("failed to encode build provenance", "job_id", job.ID, "error", err)
+		return
+	}
+
+	attestation := &models.BuildAttestation{
+		BuildID:   job.ID,
+		WorkerID:  workerID,
+		KeyID:     w.signer.KeyID(),
+		PublicKey: base64.StdEncoding.EncodeToString(w.signer.PublicKey()),
+		Envelope:  data,
+	}
+	if err := w.store.BuildAttestations().Save(ctx, attestation); err != nil {
+		w.logger.Error("failed to save build attestation", "job_id", job.ID, "error", err)
+		return
+	}
+	logCallback("=== Provenance attestation signed with key " + attestation.KeyID + " ===")
+}
@@ -9,8 +9,12 @@ import (
 	"log/slog"
 	"net/http"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
 )
 
 // AtticClient provides methods for interacting with an Attic binary cache.
@@ -56,6 +60,13 @@ func NewAtticClient(cfg *AtticConfig, logger *slog.Logger) *AtticClient {
 	}
 }
 
+// WithCache returns a client for another cache on the same server.
+func (c *AtticClient) WithCache(cacheName string) *AtticClient {
+	clone := *c
+	clone.cacheName = cacheName
+	return &clone
+}
+
 // PushResult holds the result of pushing a closure to Attic.
 type PushResult struct {
 	StorePath string        // The store path that was pushed
@@ -278,3 +289,160 @@ func (c *AtticClient) SignClosure(ctx context.Context, storePath string) error {
 
 	return nil
 }
+
+// cacheNamePattern matches the Attic cache names Narvana creates.
+var cacheNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// CacheMapping maps apps to the Attic caches their builds pull from and push
+// to. Every build also pulls from the shared cache, and its closure is pushed
+// there too so nodes find it; Attic deduplicates NARs across caches, so the
+// second push only uploads metadata.
+type CacheMapping struct {
+	Shared string            // Cache shared by all apps
+	PerApp bool              // Give each app its own cache, named "<shared>-<appID>"
+	Apps   map[string]string // Explicit cache names by app ID
+}
+
+// CacheFor returns the cache builds of an app use.
+func (m CacheMapping) CacheFor(appID string) string {
+	if name, ok := m.Apps[appID]; ok {
+		return name
+	}
+	if m.PerApp && appID != "" {
+		return strings.ToLower(m.Shared + "-" + appID)
+	}
+	return m.Shared
+}
+
+// ParseCacheMapping parses explicit app caches given as
+// "appID=cache,appID=cache".
+func ParseCacheMapping(s string) (map[string]string, error) {
+	apps := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		appID, cache, ok := strings.Cut(pair, "=")
+		appID, cache = strings.TrimSpace(appID), strings.TrimSpace(cache)
+		if !ok || appID == "" {
+			return nil, fmt.Errorf("invalid app cache %q: expected appID=cache", pair)
+		}
+		if !cacheNamePattern.MatchString(cache) {
+			return nil, fmt.Errorf("invalid cache name %q for app %s", cache, appID)
+		}
+		apps[appID] = cache
+	}
+	return apps, nil
+}
+
+// Marker printed by atticPullScript and read back by parseCacheStats.
+const cacheStatsMarker = "=== Cache Stats: "
+
+// atticPullScript configures the Attic client inside the build container
+// and adds the app's cache and the shared cache as substituters, then counts
+// what the build will fetch and what it will build with a dry run. Pulling is
+// best effort: a build never fails because the cache is unreachable.
+func atticPullScript(endpoint, token, cache, shared string) string {
+	caches := cache
+	if shared != "" && shared != cache {
+		caches += " " + shared
+	}
+	return fmt.Sprintf(`
+# Pull from the Attic binary cache
+echo "=== Configuring Attic cache ==="
+echo "Attic URL: %s"
+echo "Caches: %s"
+
+# Install attic-client
+nix profile install nixpkgs#attic-client
+
+# Configure Attic client directly (write config file)
+mkdir -p /root/.config/attic
+cat > /root/.config/attic/config.toml << 'ATTIC_CONFIG'
+default-server = "narvana"
+
+[servers.narvana]
+endpoint = "%s"
+token = "%s"
+ATTIC_CONFIG
+
+for CACHE in %s; do
+  # Create cache if it doesn't exist (ignore error if already exists)
+  attic cache create "$CACHE" 2>/dev/null || true
+  attic use "narvana:$CACHE" || echo "WARNING: could not use cache $CACHE, building without it"
+done
+
+# Count cache hits and misses before building
+DRY_RUN_OUTPUT=$(mktemp)
+nix build '.#default' --dry-run --impure --option sandbox false --option filter-syscalls false > "$DRY_RUN_OUTPUT" 2>&1 || true
+CACHE_MISSES=$(sed -n -e 's/^this derivation will be built:$/1/p' -e 's/^these \([0-9]*\) derivations will be built:$/\1/p' "$DRY_RUN_OUTPUT" | head -1)
+CACHE_HITS=$(sed -n -e 's/^this path will be fetched.*/1/p' -e 's/^these \([0-9]*\) paths will be fetched.*/\1/p' "$DRY_RUN_OUTPUT" | head -1)
+rm -f "$DRY_RUN_OUTPUT"
+echo "=== Cache Stats: hits=${CACHE_HITS:-0} misses=${CACHE_MISSES:-0} ==="
+echo ""
+`, endpoint, caches, endpoint, token, caches)
+}
+
+// atticPushScript pushes the build output's closure to each of the caches.
+func atticPushScript(cache, shared string) string {
+	caches := cache
+	if shared != "" && shared != cache {
+		caches += " " + shared
+	}
+	return fmt.Sprintf(`
+# Push to Attic binary cache
+echo "=== Pushing to Attic cache ==="
+for CACHE in %s; do
+  # Push the closure with all dependencies
+  attic push "$CACHE" "$STORE_PATH"
+done
+
+echo ""
+echo "=== Pushed to Attic successfully ==="
+echo ""
+`, caches)
+}
+
+// parseCacheStats reads the cache hits and misses counted before the build.
+// It returns nil if the build output holds no stats.
+func parseCacheStats(output, cache string) *models.BuildCacheStats {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, cacheStatsMarker) || !strings.HasSuffix(line, markerSuffix) {
+			continue
+		}
+		stats := &models.BuildCacheStats{Cache: cache}
+		for _, field := range strings.Fields(strings.TrimSuffix(strings.TrimPrefix(line, cacheStatsMarker), markerSuffix)) {
+			key, value, _ := strings.Cut(field, "=")
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil
+			}
+			switch key {
+			case "hits":
+				stats.Hits = n
+			case "misses":
+				stats.Misses = n
+			}
+		}
+		return stats
+	}
+	return nil
+}
+
+// reportCacheStats writes a successful build's cache usage to its logs.
+func (w *Worker) reportCacheStats(ctx context.Context, job *models.BuildJob) {
+	stats := job.CacheStats
+	if stats == nil {
+		return
+	}
+	w.logger.Info("build cache usage",
+		"job_id", job.ID,
+		"cache", stats.Cache,
+		"hits", stats.Hits,
+		"misses", stats.Misses,
+	)
+	w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Cache %s: %d paths pulled, %d built (%.0f%% hit rate)",
+		stats.Cache, stats.Hits, stats.Misses, stats.HitRate()*100))
+}
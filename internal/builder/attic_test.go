@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCacheMappingCacheFor(t *testing.T) {
+	shared := CacheMapping{Shared: "narvana"}
+	if got := shared.CacheFor("app-1"); got != "narvana" {
+		t.Errorf("shared cache = %q, want narvana", got)
+	}
+
+	perApp := CacheMapping{Shared: "narvana", PerApp: true, Apps: map[string]string{"app-2": "billing"}}
+	if got := perApp.CacheFor("App-1"); got != "narvana-app-1" {
+		t.Errorf("per-app cache = %q, want narvana-app-1", got)
+	}
+	if got := perApp.CacheFor("app-2"); got != "billing" {
+		t.Errorf("mapped cache = %q, want billing", got)
+	}
+	if got := perApp.CacheFor(""); got != "narvana" {
+		t.Errorf("cache without an app = %q, want narvana", got)
+	}
+}
+
+func TestParseCacheMapping(t *testing.T) {
+	apps, err := ParseCacheMapping(" app-1=billing, app-2 = search ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 2 || apps["app-1"] != "billing" || apps["app-2"] != "search" {
+		t.Errorf("apps = %v", apps)
+	}
+
+	for _, bad := range []string{"app-1", "=billing", "app-1=Billing Cache", "app-1="} {
+		if _, err := ParseCacheMapping(bad); err == nil {
+			t.Errorf("ParseCacheMapping(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestParseCacheStats(t *testing.T) {
+	output := "=== Configuring Attic cache ===\n=== Cache Stats: hits=12 misses=3 ===\n/nix/store/abc-app\n"
+	stats := parseCacheStats(output, "narvana-app-1")
+	if stats == nil || stats.Cache != "narvana-app-1" || stats.Hits != 12 || stats.Misses != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if rate := stats.HitRate(); rate != 0.8 {
+		t.Errorf("hit rate = %v, want 0.8", rate)
+	}
+
+	if stats := parseCacheStats("=== Build Complete ===\n", "narvana"); stats != nil {
+		t.Errorf("stats without marker = %+v, want nil", stats)
+	}
+}
+
+func TestAtticScriptsUseAppAndSharedCaches(t *testing.T) {
+	pull := atticPullScript("http://attic:5000", "token", "narvana-app-1", "narvana")
+	if !strings.Contains(pull, "for CACHE in narvana-app-1 narvana; do") {
+		t.Errorf("pull script does not use both caches:\n%s", pull)
+	}
+	if !strings.Contains(pull, cacheStatsMarker) {
+		t.Error("pull script does not print cache stats")
+	}
+
+	push := atticPushScript("narvana", "narvana")
+	if !strings.Contains(push, "for CACHE in narvana; do") {
+		t.Errorf("push script pushes to the shared cache twice:\n%s", push)
+	}
+}
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// buildLogFlushInterval is how often buffered build output is persisted, and
+// so roughly how far clients tailing a build lag behind it.
+const buildLogFlushInterval = time.Second
+
+// buildLogChunkSize is the buffered output size that triggers a flush before
+// the interval elapses.
+const buildLogChunkSize = 64 * 1024
+
+// buildLogWriter persists a build's output in chunks while the build runs.
+// Lines are buffered and appended as one chunk every flush interval, or
+// sooner when the buffer grows past buildLogChunkSize.
+type buildLogWriter struct {
+	ctx     context.Context
+	store   store.BuildLogStore
+	buildID string
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	buf    strings.Builder
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newBuildLogWriter starts a writer for the given build. Close must be called
+// to persist the remaining output and stop the flush loop.
+func newBuildLogWriter(ctx context.Context, st store.BuildLogStore, buildID string, logger *slog.Logger) *buildLogWriter {
+	b := &buildLogWriter{
+		ctx:     ctx,
+		store:   st,
+		buildID: buildID,
+		logger:  logger,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.run(buildLogFlushInterval)
+	return b
+}
+
+func (b *buildLogWriter) run(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			b.flushLocked()
+			b.mu.Unlock()
+		}
+	}
+}
+
+// WriteLine buffers a line of output. Lines written after Close are dropped.
+func (b *buildLogWriter) WriteLine(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.buf.WriteString(line)
+	b.buf.WriteByte('\n')
+	if b.buf.Len() >= buildLogChunkSize {
+		b.flushLocked()
+	}
+}
+
+// Close persists the buffered output and stops the flush loop. It is safe to
+// call more than once.
+func (b *buildLogWriter) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.flushLocked()
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+}
+
+// flushLocked appends the buffered output as the next chunk. The lock is held
+// across the write so chunks are numbered in the order they were buffered.
+func (b *buildLogWriter) flushLocked() {
+	if b.buf.Len() == 0 {
+		return
+	}
+	chunk := &models.BuildLogChunk{BuildID: b.buildID, Content: b.buf.String()}
+	b.buf.Reset()
+	if err := b.store.Append(b.ctx, chunk); err != nil {
+		b.logger.Error("failed to persist build log chunk", "build_id", b.buildID, "error", err)
+	}
+}
@@ -0,0 +1,32 @@
+package builder
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestBuildLogWriter(t *testing.T) {
+	st := NewMockBuildLogStore()
+	w := newBuildLogWriter(context.Background(), st, "build-1", slog.Default())
+
+	w.WriteLine("cloning")
+	w.WriteLine("building")
+	// A line past the chunk size flushes immediately
+	w.WriteLine(strings.Repeat("x", buildLogChunkSize))
+	chunks, _ := st.List(context.Background(), "build-1", 0, 0)
+	if len(chunks) != 1 || !strings.HasPrefix(chunks[0].Content, "cloning\nbuilding\n") {
+		t.Fatalf("expected one flushed chunk, got %d", len(chunks))
+	}
+
+	w.WriteLine("done")
+	w.Close()
+	w.Close()
+	w.WriteLine("after close")
+
+	chunks, _ = st.List(context.Background(), "build-1", 1, 0)
+	if len(chunks) != 1 || chunks[0].Seq != 2 || chunks[0].Content != "done\n" {
+		t.Fatalf("unexpected chunks after close: %+v", chunks)
+	}
+}
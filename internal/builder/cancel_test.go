@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/builder/executor"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestWatchCancel(t *testing.T) {
+	st := NewMockStore()
+	w := &Worker{store: st, logger: slog.Default()}
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	job.Status = models.BuildStatusRunning
+	st.Builds().Create(context.Background(), job)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	go w.watchCancel(ctx, job.ID, cancel)
+
+	if err := st.Builds().RequestCancel(context.Background(), job.ID); err != nil {
+		t.Fatalf("RequestCancel: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(3 * cancelPollInterval):
+		t.Fatal("build context was not canceled")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrBuildCanceled) {
+		t.Errorf("cause = %v, want ErrBuildCanceled", cause)
+	}
+}
+
+func TestProcessJobCanceledBeforeStart(t *testing.T) {
+	ctx := context.Background()
+	st := NewMockStore()
+	w := &Worker{store: st, logger: slog.Default()}
+
+	st.Deployments().Create(ctx, &models.Deployment{ID: "d1", AppID: "test-app-id", Status: models.DeploymentStatusPending})
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	st.Builds().Create(ctx, job)
+	st.Builds().RequestCancel(ctx, job.ID)
+
+	if err := w.processJob(ctx, job); err != nil {
+		t.Fatalf("processJob: %v", err)
+	}
+	build, _ := st.Builds().Get(ctx, job.ID)
+	deployment, _ := st.Deployments().Get(ctx, "d1")
+	if build.Status != models.BuildStatusCanceled || build.FinishedAt == nil {
+		t.Errorf("build = %s, want canceled with a finish time", build.Status)
+	}
+	if deployment.Status != models.DeploymentStatusCanceled {
+		t.Errorf("deployment = %s, want canceled", deployment.Status)
+	}
+
+	// A canceled build that is dequeued again is acknowledged untouched
+	if err := w.processJob(ctx, job); err != nil {
+		t.Errorf("processJob of a canceled build: %v", err)
+	}
+}
+
+// hangingExecutor streams a line and runs until its context ends, returning
+// the logs so far.
+type hangingExecutor struct {
+	executor.StrategyExecutor
+}
+
+func (hangingExecutor) Supports(strategy models.BuildStrategy) bool { return true }
+
+func (hangingExecutor) ExecuteWithLogs(ctx context.Context, job *models.BuildJob, logCallback executor.LogCallback) (*executor.BuildResult, error) {
+	logCallback("compiling")
+	<-ctx.Done()
+	return &executor.BuildResult{Logs: "compiling\n"}, ctx.Err()
+}
+
+func TestExecuteWithStrategyTimeoutKeepsLogs(t *testing.T) {
+	registry := executor.NewExecutorRegistry()
+	registry.Register(hangingExecutor{})
+	w := &Worker{
+		store:            NewMockStore(),
+		executorRegistry: registry,
+		progressTracker:  NewDefaultProgressTracker(slog.Default()),
+		logger:           slog.Default(),
+	}
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyDockerfile)
+	job.TimeoutSeconds = 1
+
+	var streamed []string
+	_, logs, err := w.executeWithStrategy(context.Background(), job, func(line string) {
+		streamed = append(streamed, line)
+	})
+	if !errors.Is(err, ErrBuildTimeout) || !strings.Contains(err.Error(), "1s timeout") {
+		t.Errorf("err = %v, want ErrBuildTimeout naming the limit", err)
+	}
+	if logs != "compiling\n" {
+		t.Errorf("logs = %q, want the executor's partial logs", logs)
+	}
+	if last := streamed[len(streamed)-1]; last != "=== Build timeout exceeded (1s) ===" {
+		t.Errorf("last streamed line = %q", last)
+	}
+}
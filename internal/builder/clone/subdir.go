@@ -0,0 +1,36 @@
+package clone
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Subdir returns the directory buildPath names within a cloned repository,
+// or repoPath itself when buildPath is empty. It fails if the directory does
+// not exist or, following symlinks, lies outside the repository.
+func Subdir(repoPath, buildPath string) (string, error) {
+	if buildPath == "" {
+		return repoPath, nil
+	}
+	root, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("resolving repository path: %w", err)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(buildPath)))
+	if err != nil {
+		return "", fmt.Errorf("build path %q not found in repository", buildPath)
+	}
+	if dir != root && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("build path %q is outside the repository", buildPath)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("build path %q: %w", buildPath, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("build path %q is not a directory", buildPath)
+	}
+	return dir, nil
+}
@@ -0,0 +1,176 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// DefaultHeartbeatInterval is how often a worker reports liveness and renews
+// the leases on its jobs.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// staleWorkerRetention is how long workers that stopped heartbeating stay in
+// the registry before they are removed.
+const staleWorkerRetention = 24 * time.Hour
+
+// NewWorkerID returns a registry ID for a worker process. The random suffix
+// keeps a restarted process from inheriting the leases of its predecessor.
+func NewWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
+
+// Coordinator lets several worker processes share one build queue. It
+// registers its worker in the build worker registry, heartbeats while the
+// worker runs, renews the leases on the worker's jobs and returns jobs whose
+// worker stopped heartbeating to the queue.
+type Coordinator struct {
+	store    store.Store
+	queue    queue.LeasedQueue
+	worker   models.BuildWorker
+	interval time.Duration
+	logger   *slog.Logger
+	// podmanCheck reports whether the worker can reach its Podman service;
+	// nil skips the check.
+	podmanCheck func(ctx context.Context) error
+}
+
+// NewCoordinator creates a coordinator for the worker process with the given
+// registry ID.
+func NewCoordinator(s store.Store, q queue.LeasedQueue, workerID string, concurrency int, interval time.Duration, logger *slog.Logger) *Coordinator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	hostname, _ := os.Hostname()
+	return &Coordinator{
+		store: s,
+		queue: q,
+		worker: models.BuildWorker{
+			ID:           workerID,
+			Hostname:     hostname,
+			Version:      WorkerVersion,
+			Concurrency:  concurrency,
+			LeaseSeconds: int(q.LeaseDuration() / time.Second),
+		},
+		interval: interval,
+		logger:   logger.With("build_worker_id", workerID),
+	}
+}
+
+// SetPodmanCheck sets the check run on registration and every heartbeat to
+// report whether the worker can reach its Podman service.
+func (c *Coordinator) SetPodmanCheck(check func(ctx context.Context) error) {
+	c.podmanCheck = check
+}
+
+// WorkerID returns the registry ID of the coordinated worker.
+func (c *Coordinator) WorkerID() string {
+	return c.worker.ID
+}
+
+// Register adds the worker to the registry and removes workers that have
+// not heartbeated for a day.
+func (c *Coordinator) Register(ctx context.Context) error {
+	c.checkPodman(ctx)
+	if err := c.store.BuildWorkers().Register(ctx, &c.worker); err != nil {
+		return fmt.Errorf("registering build worker: %w", err)
+	}
+	if n, err := c.store.BuildWorkers().DeleteStale(ctx, time.Now().Add(-staleWorkerRetention)); err != nil {
+		c.logger.Warn("failed to remove stale build workers", "error", err)
+	} else if n > 0 {
+		c.logger.Info("removed stale build workers", "count", n)
+	}
+	c.logger.Info("registered build worker", "lease_seconds", c.worker.LeaseSeconds)
+	return nil
+}
+
+// Run heartbeats every interval until stop is closed or ctx is cancelled.
+// activeJobs reports how many jobs the worker is running.
+func (c *Coordinator) Run(ctx context.Context, stop <-chan struct{}, activeJobs func() int) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.Heartbeat(ctx, activeJobs())
+		}
+	}
+}
+
+// Heartbeat renews the worker's leases, records its liveness and requeues
+// jobs whose lease expired.
+func (c *Coordinator) Heartbeat(ctx context.Context, activeJobs int) {
+	if _, err := c.queue.RenewLeases(ctx); err != nil {
+		c.logger.Error("failed to renew job leases", "error", err)
+	}
+
+	// A change in Podman reachability is recorded by registering again
+	podmanError := c.worker.PodmanError
+	if c.checkPodman(ctx) != podmanError {
+		c.worker.ActiveJobs = activeJobs
+		if err := c.store.BuildWorkers().Register(ctx, &c.worker); err != nil {
+			c.logger.Error("failed to register build worker", "error", err)
+		}
+	} else if err := c.store.BuildWorkers().Heartbeat(ctx, c.worker.ID, activeJobs); err != nil {
+		// The registration may have been removed as stale after a long pause
+		c.logger.Warn("failed to record heartbeat, registering again", "error", err)
+		c.worker.ActiveJobs = activeJobs
+		if err := c.store.BuildWorkers().Register(ctx, &c.worker); err != nil {
+			c.logger.Error("failed to register build worker", "error", err)
+		}
+	}
+
+	requeued, err := c.queue.RequeueExpired(ctx)
+	if err != nil {
+		c.logger.Error("failed to requeue jobs with expired leases", "error", err)
+		return
+	}
+	if len(requeued) > 0 {
+		c.logger.Warn("requeued jobs of unresponsive workers", "job_ids", requeued)
+	}
+}
+
+// checkPodman runs the Podman check, records its error on the worker and
+// returns it.
+func (c *Coordinator) checkPodman(ctx context.Context) string {
+	if c.podmanCheck == nil {
+		return ""
+	}
+	previous := c.worker.PodmanError
+	c.worker.PodmanError = ""
+	if err := c.podmanCheck(ctx); err != nil {
+		c.worker.PodmanError = err.Error()
+		if previous == "" {
+			c.logger.Warn("cannot reach podman", "error", err)
+		}
+	}
+	return c.worker.PodmanError
+}
+
+// Deregister marks the worker as stopped.
+func (c *Coordinator) Deregister(ctx context.Context) {
+	if err := c.store.BuildWorkers().MarkStopped(ctx, c.worker.ID); err != nil {
+		c.logger.Error("failed to mark build worker stopped", "error", err)
+		return
+	}
+	c.logger.Info("deregistered build worker")
+}
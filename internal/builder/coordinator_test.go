@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// leasedQueueStub records lease operations.
+type leasedQueueStub struct {
+	queue.Queue
+	renewed  int
+	expired  []string
+	requeued []string
+}
+
+func (q *leasedQueueStub) LeaseDuration() time.Duration { return 2 * time.Minute }
+
+func (q *leasedQueueStub) RenewLeases(ctx context.Context) (int, error) {
+	q.renewed++
+	return 1, nil
+}
+
+func (q *leasedQueueStub) RequeueExpired(ctx context.Context) ([]string, error) {
+	ids := q.expired
+	q.requeued = append(q.requeued, ids...)
+	q.expired = nil
+	return ids, nil
+}
+
+func (q *leasedQueueStub) ListClaimed(ctx context.Context) ([]string, error) { return nil, nil }
+
+// workerRegistryStub is an in-memory build worker registry.
+type workerRegistryStub struct {
+	store.BuildWorkerStore
+	workers map[string]models.BuildWorker
+}
+
+func (r *workerRegistryStub) Register(ctx context.Context, w *models.BuildWorker) error {
+	w.LastHeartbeatAt = time.Now()
+	r.workers[w.ID] = *w
+	return nil
+}
+
+func (r *workerRegistryStub) Heartbeat(ctx context.Context, id string, activeJobs int) error {
+	w, ok := r.workers[id]
+	if !ok {
+		return errors.New("resource not found")
+	}
+	w.ActiveJobs = activeJobs
+	w.LastHeartbeatAt = time.Now()
+	r.workers[id] = w
+	return nil
+}
+
+func (r *workerRegistryStub) MarkStopped(ctx context.Context, id string) error {
+	w := r.workers[id]
+	now := time.Now()
+	w.StoppedAt = &now
+	r.workers[id] = w
+	return nil
+}
+
+func (r *workerRegistryStub) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+type registryStore struct {
+	store.Store
+	registry *workerRegistryStub
+}
+
+func (s *registryStore) BuildWorkers() store.BuildWorkerStore { return s.registry }
+
+func TestCoordinatorHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	registry := &workerRegistryStub{workers: map[string]models.BuildWorker{}}
+	q := &leasedQueueStub{expired: []string{"job-of-dead-worker"}}
+	c := NewCoordinator(&registryStore{registry: registry}, q, "worker-a", 4, time.Second, nil)
+
+	if err := c.Register(ctx); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if w := registry.workers["worker-a"]; w.Concurrency != 4 || w.LeaseSeconds != 120 {
+		t.Errorf("registered worker = %+v, want concurrency 4 and 120s lease", w)
+	}
+
+	c.Heartbeat(ctx, 2)
+	if q.renewed != 1 {
+		t.Errorf("leases renewed %d times, want 1", q.renewed)
+	}
+	if len(q.requeued) != 1 || q.requeued[0] != "job-of-dead-worker" {
+		t.Errorf("requeued = %v, want the expired job", q.requeued)
+	}
+	if w := registry.workers["worker-a"]; w.ActiveJobs != 2 {
+		t.Errorf("active jobs = %d, want 2", w.ActiveJobs)
+	}
+
+	// A registration removed while the worker was paused is recreated
+	delete(registry.workers, "worker-a")
+	c.Heartbeat(ctx, 1)
+	if w, ok := registry.workers["worker-a"]; !ok || w.ActiveJobs != 1 {
+		t.Errorf("worker not registered again after heartbeat: %+v", w)
+	}
+
+	c.Deregister(ctx)
+	if w := registry.workers["worker-a"]; w.StateAt(time.Now()) != models.BuildWorkerStateStopped {
+		t.Errorf("state after deregistering = %q, want stopped", w.StateAt(time.Now()))
+	}
+}
+
+func TestBuildWorkerStateAt(t *testing.T) {
+	now := time.Now()
+	w := &models.BuildWorker{LeaseSeconds: 60, LastHeartbeatAt: now.Add(-30 * time.Second)}
+	if got := w.StateAt(now); got != models.BuildWorkerStateActive {
+		t.Errorf("state = %q, want active", got)
+	}
+	if got := w.StateAt(now.Add(time.Minute)); got != models.BuildWorkerStateUnresponsive {
+		t.Errorf("state after lease = %q, want unresponsive", got)
+	}
+}
+
+func TestCoordinatorReportsPodmanReachability(t *testing.T) {
+	ctx := context.Background()
+	registry := &workerRegistryStub{workers: map[string]models.BuildWorker{}}
+	c := NewCoordinator(&registryStore{registry: registry}, &leasedQueueStub{}, "worker-a", 1, time.Second, nil)
+	var podmanErr error
+	c.SetPodmanCheck(func(ctx context.Context) error { return podmanErr })
+
+	if err := c.Register(ctx); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if w := registry.workers["worker-a"]; w.PodmanError != "" {
+		t.Errorf("podman error = %q, want none", w.PodmanError)
+	}
+
+	podmanErr = errors.New("connection refused")
+	c.Heartbeat(ctx, 0)
+	if w := registry.workers["worker-a"]; w.PodmanError != "connection refused" {
+		t.Errorf("podman error after heartbeat = %q, want it recorded", w.PodmanError)
+	}
+
+	podmanErr = nil
+	c.Heartbeat(ctx, 0)
+	if w := registry.workers["worker-a"]; w.PodmanError != "" {
+		t.Errorf("podman error after recovery = %q, want none", w.PodmanError)
+	}
+}
@@ -0,0 +1,143 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// treeHashTimeout bounds how long resolving a source tree may take before the
+// build proceeds without deduplication.
+const treeHashTimeout = 30 * time.Second
+
+// contentHashInputs are the build inputs that determine the artifact. The
+// source is identified by its git tree hash rather than the commit or branch,
+// so the same tree built from different branches or commits produces the same
+// hash. App and service identity are deliberately excluded.
+type contentHashInputs struct {
+	TreeHash       string               `json:"tree_hash"`
+	Strategy       models.BuildStrategy `json:"strategy"`
+	BuildType      models.BuildType     `json:"build_type"`
+	FlakeOutput    string               `json:"flake_output"`
+	BuildPath      string               `json:"build_path,omitempty"`
+	BuildConfig    *models.BuildConfig  `json:"build_config,omitempty"`
+	GeneratedFlake string               `json:"generated_flake,omitempty"`
+	FlakeLock      string               `json:"flake_lock,omitempty"`
+	VendorHash     string               `json:"vendor_hash,omitempty"`
+}
+
+// ContentHash returns the content-addressed key for a build of the given
+// source tree. Builds with equal keys produce interchangeable artifacts.
+func ContentHash(job *models.BuildJob, treeHash string) (string, error) {
+	data, err := json.Marshal(contentHashInputs{
+		TreeHash:       treeHash,
+		Strategy:       job.BuildStrategy,
+		BuildType:      job.BuildType,
+		FlakeOutput:    job.FlakeOutput,
+		BuildPath:      job.BuildPath,
+		BuildConfig:    job.BuildConfig,
+		GeneratedFlake: job.GeneratedFlake,
+		FlakeLock:      job.FlakeLock,
+		VendorHash:     job.VendorHash,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshaling content hash inputs: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ResolveTreeHash returns the git tree hash that gitRef points to. It fetches
+// only the commit object where the server supports partial clone, so it is far
+// cheaper than cloning. An empty gitRef resolves the default branch.
+func ResolveTreeHash(ctx context.Context, gitURL, gitRef string) (string, error) {
+	dir, err := os.MkdirTemp("", "narvana-tree-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	if _, err := git("init", "--quiet"); err != nil {
+		return "", err
+	}
+
+	ref := gitRef
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := git("fetch", "--quiet", "--depth", "1", "--filter=tree:0", gitURL, ref); err != nil {
+		// Servers without partial clone support need the full snapshot
+		if _, err := git("fetch", "--quiet", "--depth", "1", gitURL, ref); err != nil {
+			return "", err
+		}
+	}
+
+	return git("rev-parse", "FETCH_HEAD^{tree}")
+}
+
+// findDuplicateBuild computes the job's content hash and looks for an earlier
+// successful build with the same hash. The hash is recorded on the job either
+// way so later builds can match it. Only git sources are deduplicated; any
+// failure along the way means the job is simply built.
+func (w *Worker) findDuplicateBuild(ctx context.Context, job *models.BuildJob) (*models.BuildJob, bool) {
+	if w.resolveTreeHash == nil || job.GitURL == "" {
+		return nil, false
+	}
+	if job.SourceType != "" && job.SourceType != models.SourceTypeGit {
+		return nil, false
+	}
+
+	resolveCtx, cancel := context.WithTimeout(ctx, treeHashTimeout)
+	defer cancel()
+
+	treeHash, err := w.resolveTreeHash(resolveCtx, job.GitURL, job.GitRef)
+	if err != nil {
+		w.logger.Warn("could not resolve source tree, skipping build deduplication",
+			"job_id", job.ID,
+			"git_url", job.GitURL,
+			"git_ref", job.GitRef,
+			"error", err,
+		)
+		return nil, false
+	}
+
+	contentHash, err := ContentHash(job, treeHash)
+	if err != nil {
+		w.logger.Warn("could not compute content hash", "job_id", job.ID, "error", err)
+		return nil, false
+	}
+	job.ContentHash = contentHash
+
+	source, err := w.store.Builds().FindByContentHash(ctx, contentHash)
+	if err != nil || source == nil || source.ID == job.ID || source.Artifact == "" {
+		return nil, false
+	}
+
+	w.logger.Info("build inputs match an earlier build, reusing artifact",
+		"job_id", job.ID,
+		"deduplicated_from", source.ID,
+		"content_hash", contentHash,
+		"artifact", source.Artifact,
+	)
+	return source, true
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestContentHash(t *testing.T) {
+	base := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	hash, err := ContentHash(base, "tree-a")
+	if err != nil {
+		t.Fatalf("ContentHash: %v", err)
+	}
+	if len(hash) != 64 {
+		t.Fatalf("expected a hex sha256, got %q", hash)
+	}
+
+	// Identity and source ref don't affect the hash, only the inputs do
+	other := NewTestBuildJob("b2", "d2", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	other.AppID = "another-app"
+	other.ServiceName = "another-service"
+	other.GitRef = "feature-branch"
+	if h, _ := ContentHash(other, "tree-a"); h != hash {
+		t.Error("builds of the same tree with the same inputs should share a hash")
+	}
+
+	changes := map[string]func(j *models.BuildJob) string{
+		"tree":      func(j *models.BuildJob) string { return "tree-b" },
+		"strategy":  func(j *models.BuildJob) string { j.BuildStrategy = models.BuildStrategyFlake; return "tree-a" },
+		"buildType": func(j *models.BuildJob) string { j.BuildType = models.BuildTypeOCI; return "tree-a" },
+		"config": func(j *models.BuildJob) string {
+			j.BuildConfig = &models.BuildConfig{BuildCommand: "make"}
+			return "tree-a"
+		},
+		"flakeLock": func(j *models.BuildJob) string { j.FlakeLock = "{}"; return "tree-a" },
+		"buildPath": func(j *models.BuildJob) string { j.BuildPath = "services/api"; return "tree-a" },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			job := NewTestBuildJob("b3", "d3", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+			tree := change(job)
+			if h, _ := ContentHash(job, tree); h == hash {
+				t.Errorf("changing %s should change the hash", name)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateBuild(t *testing.T) {
+	ctx := context.Background()
+	st := NewMockStore()
+	w := &Worker{
+		store:  st,
+		logger: slog.Default(),
+		resolveTreeHash: func(ctx context.Context, gitURL, gitRef string) (string, error) {
+			return "tree-a", nil
+		},
+	}
+
+	previous := NewTestBuildJob("previous", "d1", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	previous.SourceType = models.SourceTypeGit
+	if _, ok := w.findDuplicateBuild(ctx, previous); ok {
+		t.Fatal("no earlier build exists yet")
+	}
+	if previous.ContentHash == "" {
+		t.Fatal("content hash should be recorded even without a match")
+	}
+
+	finished := time.Now()
+	previous.Status = models.BuildStatusSucceeded
+	previous.FinishedAt = &finished
+	previous.Artifact = "/nix/store/abc-app"
+	st.Builds().Create(ctx, previous)
+
+	job := NewTestBuildJob("current", "d2", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	job.SourceType = models.SourceTypeGit
+	job.GitRef = "other-branch"
+	source, ok := w.findDuplicateBuild(ctx, job)
+	if !ok {
+		t.Fatal("expected the earlier build to match")
+	}
+	if source.ID != "previous" || source.Artifact != "/nix/store/abc-app" {
+		t.Errorf("unexpected source build: %+v", source)
+	}
+
+	// Different inputs don't match
+	oci := NewTestBuildJob("oci", "d3", models.BuildTypeOCI, models.BuildStrategyAutoGo)
+	if _, ok := w.findDuplicateBuild(ctx, oci); ok {
+		t.Error("a build with a different build type should not match")
+	}
+
+	// Non-git sources and unresolvable trees are built normally
+	flake := NewTestBuildJob("flake", "d4", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	flake.SourceType = models.SourceTypeFlake
+	if _, ok := w.findDuplicateBuild(ctx, flake); ok {
+		t.Error("flake sources should not be deduplicated")
+	}
+
+	w.resolveTreeHash = func(ctx context.Context, gitURL, gitRef string) (string, error) {
+		return "", errors.New("unreachable")
+	}
+	unresolved := NewTestBuildJob("unresolved", "d5", models.BuildTypePureNix, models.BuildStrategyAutoGo)
+	if _, ok := w.findDuplicateBuild(ctx, unresolved); ok || unresolved.ContentHash != "" {
+		t.Error("builds whose tree cannot be resolved should not be deduplicated")
+	}
+}
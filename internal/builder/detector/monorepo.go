@@ -0,0 +1,82 @@
+package detector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DefaultProjectDepth is how many directory levels below the repository root
+// FindProjects searches.
+const DefaultProjectDepth = 2
+
+// Project is a directory of a repository that can be built as a service.
+type Project struct {
+	// BuildPath is the directory relative to the repository root, with
+	// forward slashes; empty for the root itself.
+	BuildPath string                  `json:"build_path"`
+	Detection *models.DetectionResult `json:"detection"`
+}
+
+// skippedProjectDirs are directories of dependencies and tooling that never
+// hold a project of their own.
+var skippedProjectDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"testdata":     true,
+	"__pycache__":  true,
+}
+
+// FindProjects runs detection on the repository root and every directory up
+// to maxDepth levels below it, returning each directory that was detected as
+// a project, root first and then by path. One repository can back a service
+// for each project.
+func FindProjects(ctx context.Context, d Detector, repoPath string, maxDepth int) ([]Project, error) {
+	var projects []Project
+	err := filepath.WalkDir(repoPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		} else {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || skippedProjectDirs[name] {
+				return filepath.SkipDir
+			}
+			if strings.Count(rel, "/")+1 > maxDepth {
+				return filepath.SkipDir
+			}
+		}
+
+		if result, err := d.Detect(ctx, path); err == nil && result != nil {
+			projects = append(projects, Project{BuildPath: rel, Detection: result})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].BuildPath < projects[j].BuildPath
+	})
+	return projects, nil
+}
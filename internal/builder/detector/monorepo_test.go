@@ -0,0 +1,43 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestFindProjectsInMonorepo(t *testing.T) {
+	projects, err := FindProjects(context.Background(), NewDetector(), "../../../testdata/repos/mixed-languages", DefaultProjectDepth)
+	if err != nil {
+		t.Fatalf("FindProjects() = %v", err)
+	}
+
+	got := map[string]models.BuildStrategy{}
+	for _, p := range projects {
+		got[p.BuildPath] = p.Detection.Strategy
+	}
+	want := map[string]models.BuildStrategy{
+		"":         models.BuildStrategyAutoGo,
+		"frontend": models.BuildStrategyAutoNode,
+		"scripts":  models.BuildStrategyAutoPython,
+	}
+	for path, strategy := range want {
+		if got[path] != strategy {
+			t.Errorf("project %q strategy = %q, want %q (all: %v)", path, got[path], strategy, got)
+		}
+	}
+	if projects[0].BuildPath != "" {
+		t.Errorf("first project = %q, want the root", projects[0].BuildPath)
+	}
+}
+
+func TestFindProjectsRespectsDepth(t *testing.T) {
+	projects, err := FindProjects(context.Background(), NewDetector(), "../../../testdata/repos/mixed-languages", 0)
+	if err != nil {
+		t.Fatalf("FindProjects() = %v", err)
+	}
+	if len(projects) != 1 || projects[0].BuildPath != "" {
+		t.Errorf("projects = %+v, want only the root", projects)
+	}
+}
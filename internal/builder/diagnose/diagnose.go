@@ -0,0 +1,199 @@
+// Package diagnose classifies failed builds from their logs into known
+// failure classes, with the lines that show the failure and suggested fixes,
+// and tells transient failures worth retrying from ones the build's inputs
+// cause.
+package diagnose
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+const (
+	// excerptLines is how many log lines a build failure quotes.
+	excerptLines = 20
+	// lineLength truncates long quoted log lines.
+	lineLength = 300
+	// tailLines is how many streamed log lines are kept to classify builds
+	// that fail without returning their logs, such as timeouts.
+	tailLines = 200
+)
+
+// rule recognizes a category of build failure in its log lines.
+type rule struct {
+	category    models.BuildFailureCategory
+	summary     string
+	pattern     *regexp.Regexp
+	suggestions []string
+}
+
+// rules are checked in order; the first matching rule classifies the
+// failure. Resource exhaustion is checked first as it often surfaces as a
+// compile or fetch error, and dependencies that do not exist before the
+// network errors that retrying them produces.
+var rules = []rule{
+	{
+		category: models.BuildFailureOutOfMemory,
+		summary:  "The build ran out of memory.",
+		pattern:  regexp.MustCompile(`(?i)out of memory|cannot allocate memory|oomkilled|heap limit allocation failed|exit (code|status) 137|signal: killed`),
+		suggestions: []string{
+			"Raise the service's memory in its resources, or run builds on a worker with more memory.",
+			"Limit build parallelism, e.g. NODE_OPTIONS=--max-old-space-size or GOMAXPROCS.",
+		},
+	},
+	{
+		category: models.BuildFailureDiskFull,
+		summary:  "The build worker ran out of disk space.",
+		pattern:  regexp.MustCompile(`(?i)no space left on device|disk quota exceeded`),
+		suggestions: []string{
+			"Free space on the build worker, e.g. with nix-collect-garbage, or give it a larger disk.",
+			"Large build outputs and caches count too; trim what the build writes.",
+		},
+	},
+	{
+		category: models.BuildFailureInfrastructure,
+		summary:  "The build worker's container runtime or Nix daemon failed.",
+		pattern: regexp.MustCompile(`(?i)cannot connect to the (docker|podman) daemon|error response from daemon|` +
+			`cannot connect to socket at '/nix/var/nix/daemon-socket|nix-daemon.*(died|lost)|` +
+			`oci runtime (create|exec) failed|error creating overlay mount`),
+		suggestions: []string{
+			"The build is retried automatically; check the worker's container runtime and Nix daemon if it keeps failing.",
+		},
+	},
+	{
+		category: models.BuildFailureDependencyFetch,
+		summary:  "A dependency could not be found.",
+		pattern: regexp.MustCompile(`(?i)http error 40[134]|npm err! 404|err_pnpm_fetch_40[134]|` +
+			`no matching distribution found|could not find a version that satisfies|unknown revision|` +
+			`hash mismatch in fixed-output derivation|failed to get .* as a dependency|could not read username|` +
+			`repository not found|manifest unknown`),
+		suggestions: []string{
+			"Check that every dependency and version exists and that private registries or repositories are reachable with the configured credentials.",
+			"If a vendor hash mismatched, clear vendor_hash so it is recalculated, or update it to the hash in the log.",
+		},
+	},
+	{
+		category: models.BuildFailureNetwork,
+		summary:  "A download failed on a network error.",
+		pattern: regexp.MustCompile(`(?i)unable to download|could not resolve host|temporary failure in name resolution|failed to fetch|` +
+			`connection (refused|reset|timed out)|econnreset|etimedout|eai_again|enotfound|npm err! network|` +
+			`tls handshake timeout|i/o timeout|unexpected eof|http error 5\d\d|too many requests|429 too many`),
+		suggestions: []string{
+			"The build is retried automatically; network errors usually pass on retry.",
+			"If it keeps failing, check that registries and repositories are reachable from the build worker.",
+		},
+	},
+	{
+		category: models.BuildFailureCompile,
+		summary:  "The code failed to compile.",
+		pattern: regexp.MustCompile(`(?i)\.go:\d+:\d+: |error\[e\d+\]|error ts\d+|syntaxerror|compilation failed|could not compile|` +
+			`cannot find symbol|undefined reference|undefined: |build failed because of webpack errors|failed to compile|` +
+			`\berror: .*\.(rs|c|cpp|ts|tsx|java|kt|swift):\d+`),
+		suggestions: []string{
+			"Fix the errors quoted from the log; the same commit should fail to build locally.",
+			"Check that the build uses the language version the code expects.",
+		},
+	},
+}
+
+// timeoutRule classifies builds stopped by their timeout, recognized by
+// their error rather than their logs.
+var timeoutRule = rule{
+	category: models.BuildFailureTimeout,
+	summary:  "The build exceeded its timeout.",
+	suggestions: []string{
+		"Raise the build timeout in the service's build config.",
+		"Enable a binary cache so dependencies are not rebuilt every time.",
+	},
+}
+
+// noiseLine matches log lines that say nothing about a failure: progress of
+// fetches and builds, and the worker's own banners.
+var noiseLine = regexp.MustCompile(`^(===|copying path |building '/nix/store|these \d+ (paths|derivations) will be|` +
+	`/nix/store/\S+$|downloading '|querying info|unpacking |evaluating |\[\d+/\d+)`)
+
+// ansiEscape matches terminal color and cursor codes.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Classify explains a failed build from its error and log lines. timedOut
+// reports whether the build was stopped by its timeout.
+func Classify(buildErr error, lines []string, timedOut bool) *models.BuildFailure {
+	excerpt := Excerpt(lines)
+	text := strings.Join(excerpt, "\n")
+	if buildErr != nil {
+		text += "\n" + buildErr.Error()
+	}
+
+	matched := &rule{
+		category: models.BuildFailureUnknown,
+		summary:  "The build failed.",
+		suggestions: []string{
+			"Read the lines quoted from the log, or open the build logs for the full output.",
+		},
+	}
+	if timedOut {
+		matched = &timeoutRule
+	} else {
+		for i := range rules {
+			if rules[i].pattern.MatchString(text) {
+				matched = &rules[i]
+				break
+			}
+		}
+	}
+
+	return &models.BuildFailure{
+		Category:    matched.category,
+		Summary:     matched.summary,
+		Excerpt:     excerpt,
+		Suggestions: matched.suggestions,
+		Retryable:   matched.category.IsTransient(),
+	}
+}
+
+// Excerpt returns the last meaningful log lines: without blank lines,
+// terminal codes and progress noise, truncated to a readable length.
+func Excerpt(lines []string) []string {
+	var excerpt []string
+	for i := len(lines) - 1; i >= 0 && len(excerpt) < excerptLines; i-- {
+		line := strings.TrimRight(ansiEscape.ReplaceAllString(lines[i], ""), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || noiseLine.MatchString(trimmed) {
+			continue
+		}
+		if len(line) > lineLength {
+			line = line[:lineLength] + "..."
+		}
+		excerpt = append(excerpt, line)
+	}
+	slices.Reverse(excerpt)
+	return excerpt
+}
+
+// Tail keeps the last lines streamed from a build. The zero value is ready
+// to use.
+type Tail struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// Add records a streamed line.
+func (t *Tail) Add(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = append(t.lines, strings.Split(line, "\n")...)
+	if over := len(t.lines) - tailLines; over > 0 {
+		t.lines = t.lines[over:]
+	}
+}
+
+// Lines returns the recorded lines, oldest first.
+func (t *Tail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.lines)
+}
@@ -0,0 +1,133 @@
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		lines    []string
+		timedOut bool
+		want     models.BuildFailureCategory
+	}{
+		{
+			name:  "out of memory",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"> next build", "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory"},
+			want:  models.BuildFailureOutOfMemory,
+		},
+		{
+			name:  "dependency fetch",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"go: downloading github.com/acme/lib v1.2.3", "go: github.com/acme/lib@v1.2.3: unknown revision v1.2.3"},
+			want:  models.BuildFailureDependencyFetch,
+		},
+		{
+			name:  "network",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"npm ERR! code ECONNRESET", "npm ERR! network aborted"},
+			want:  models.BuildFailureNetwork,
+		},
+		{
+			name:  "missing dependency despite retried downloads",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"warning: unable to download 'https://example.com/lib.tar.gz': HTTP error 404; retrying"},
+			want:  models.BuildFailureDependencyFetch,
+		},
+		{
+			name:  "infrastructure",
+			err:   fmt.Errorf("podman build: exit status 125"),
+			lines: []string{"Error: OCI runtime create failed: container_linux.go:380"},
+			want:  models.BuildFailureInfrastructure,
+		},
+		{
+			name:  "disk full",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"error: writing to file: No space left on device"},
+			want:  models.BuildFailureDiskFull,
+		},
+		{
+			name:  "compile error",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"# example.com/api", "./main.go:12:2: undefined: handler"},
+			want:  models.BuildFailureCompile,
+		},
+		{
+			name:     "timeout",
+			err:      fmt.Errorf("build exceeded 30m0s timeout"),
+			lines:    []string{"[3/10 built] building api", "error: connection timed out"},
+			timedOut: true,
+			want:     models.BuildFailureTimeout,
+		},
+		{
+			name:  "unknown",
+			err:   fmt.Errorf("nix build failed: exit status 1"),
+			lines: []string{"something went wrong"},
+			want:  models.BuildFailureUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Classify(tt.err, tt.lines, tt.timedOut)
+			if f.Category != tt.want {
+				t.Errorf("category = %q, want %q", f.Category, tt.want)
+			}
+			if f.Retryable != tt.want.IsTransient() {
+				t.Errorf("retryable = %v for %q", f.Retryable, f.Category)
+			}
+			if f.Summary == "" || len(f.Suggestions) == 0 {
+				t.Errorf("failure %+v lacks a summary or suggestions", f)
+			}
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	lines := []string{
+		"=== Building api ===",
+		"copying path '/nix/store/abc-source' from 'https://cache.nixos.org'",
+		"",
+		"\x1b[31merror:\x1b[0m builder failed",
+		strings.Repeat("x", lineLength+10),
+	}
+	got := Excerpt(lines)
+	if len(got) != 2 {
+		t.Fatalf("excerpt = %q, want the 2 meaningful lines", got)
+	}
+	if got[0] != "error: builder failed" {
+		t.Errorf("excerpt[0] = %q, want terminal codes stripped", got[0])
+	}
+	if len(got[1]) != lineLength+3 {
+		t.Errorf("excerpt[1] has length %d, want truncated", len(got[1]))
+	}
+
+	var many []string
+	for i := range excerptLines + 5 {
+		many = append(many, fmt.Sprintf("line %d", i))
+	}
+	got = Excerpt(many)
+	if len(got) != excerptLines || got[len(got)-1] != many[len(many)-1] {
+		t.Errorf("excerpt = %q, want the last %d lines in order", got, excerptLines)
+	}
+}
+
+func TestTail(t *testing.T) {
+	tail := &Tail{}
+	for i := range tailLines {
+		tail.Add(fmt.Sprintf("line %d", i))
+	}
+	tail.Add("last\nlines")
+	got := tail.Lines()
+	if len(got) != tailLines {
+		t.Fatalf("tail kept %d lines, want %d", len(got), tailLines)
+	}
+	if got[0] != "line 2" || got[len(got)-1] != "lines" {
+		t.Errorf("tail = [%q ... %q], want the newest lines", got[0], got[len(got)-1])
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/narvanalabs/control-plane/internal/builder/detector"
 	"github.com/narvanalabs/control-plane/internal/builder/templates"
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
@@ -70,6 +71,24 @@ func (e *AutoDatabaseStrategyExecutor) GenerateFlake(ctx context.Context, detect
 		}
 	}
 
+	// MySQL, MariaDB, MongoDB and Redis have templates of their own;
+	// PostgreSQL and SQLite share the generic one
+	if dbType := databases.DatabaseType(data.DatabaseType); dbType.IsValid() && dbType != databases.DatabaseTypePostgres {
+		serviceName, _ := detection.SuggestedConfig["service_name"].(string)
+		if serviceName == "" {
+			serviceName = data.DatabaseType
+		}
+		dbData := databases.NewDatabaseTemplateData(data.AppName, serviceName, dbType, data.DatabaseVersion)
+		if config.DatabaseOptions != nil {
+			dbData.WithConfig(config.DatabaseOptions.Options)
+		}
+		flakeContent, err := databases.Render(dbData)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrTemplateRenderFailed, err)
+		}
+		return flakeContent, nil
+	}
+
 	// Render the template
 	flakeContent, err := e.templateEngine.Render(ctx, "database.nix", data)
 	if err != nil {
@@ -105,15 +124,16 @@ func (e *AutoDatabaseStrategyExecutor) ExecuteWithLogs(ctx context.Context, job
 
 		// Use config from job if available
 		dbType := "postgres" // Default to PostgreSQL
-		dbVersion := "15"    // Default PostgreSQL version
+		dbVersion := ""
 		if job.BuildConfig != nil && job.BuildConfig.DatabaseOptions != nil {
 			if job.BuildConfig.DatabaseOptions.Type != "" {
 				dbType = job.BuildConfig.DatabaseOptions.Type
 			}
-			// Set default version if not provided
-			if job.BuildConfig.DatabaseOptions.Version != "" {
-				dbVersion = job.BuildConfig.DatabaseOptions.Version
-			}
+			dbVersion = job.BuildConfig.DatabaseOptions.Version
+		}
+		// Set default version if not provided
+		if dbVersion == "" {
+			dbVersion = databases.GetDefaultVersion(databases.DatabaseType(dbType))
 		}
 
 		detection := &models.DetectionResult{
@@ -121,6 +141,7 @@ func (e *AutoDatabaseStrategyExecutor) ExecuteWithLogs(ctx context.Context, job
 			SuggestedConfig: map[string]interface{}{
 				"database_type":    dbType,
 				"database_version": dbVersion,
+				"service_name":     job.ServiceName,
 			},
 		}
 
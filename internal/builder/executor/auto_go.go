@@ -359,7 +359,7 @@ func (e *AutoGoStrategyExecutor) PreBuild(ctx context.Context, job *models.Build
 	// First, try to get a cached detection result if we have a commit SHA
 	// **Validates: Requirements 4.3** - Cache detection results by commit SHA
 	if e.detectionCache != nil && job.GitRef != "" {
-		cachedResult, found := e.detectionCache.Get(ctx, job.GitURL, job.GitRef)
+		cachedResult, found := e.detectionCache.Get(ctx, detectionSource(job), job.GitRef)
 		if found {
 			if e.logger != nil {
 				e.logger.Info("detection cache hit, skipping clone for detection",
@@ -425,8 +425,16 @@ func (e *AutoGoStrategyExecutor) PreBuild(ctx context.Context, job *models.Build
 		)
 	}
 
+	// Only the build path is detected, so each service of a monorepo is
+	// detected on its own
+	detectPath, err := clone.Subdir(result.RepoPath, job.BuildPath)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, fmt.Errorf("%w: %v", ErrDetectionFailed, err)
+	}
+
 	detectionStart := time.Now()
-	detection, err := e.detector.DetectGo(ctx, result.RepoPath)
+	detection, err := e.detector.DetectGo(ctx, detectPath)
 	result.DetectionDuration = time.Since(detectionStart)
 
 	if err != nil {
@@ -446,7 +454,7 @@ func (e *AutoGoStrategyExecutor) PreBuild(ctx context.Context, job *models.Build
 	// Store detection result in cache for future builds
 	// **Validates: Requirements 4.3** - Cache detection results by commit SHA
 	if e.detectionCache != nil && result.CommitSHA != "" {
-		if err := e.detectionCache.Set(ctx, job.GitURL, result.CommitSHA, detection); err != nil {
+		if err := e.detectionCache.Set(ctx, detectionSource(job), result.CommitSHA, detection); err != nil {
 			// Log warning but don't fail the build
 			if e.logger != nil {
 				e.logger.Warn("failed to cache detection result",
@@ -533,6 +541,16 @@ func (e *AutoGoStrategyExecutor) buildOCI(ctx context.Context, job *models.Build
 	}, nil
 }
 
+// detectionSource returns the key a job's detection results are cached
+// under: its repository and, for services built from a subdirectory, the
+// build path within it.
+func detectionSource(job *models.BuildJob) string {
+	if job.BuildPath == "" {
+		return job.GitURL
+	}
+	return job.GitURL + "//" + job.BuildPath
+}
+
 // getAppName extracts the application name from detection result.
 func getAppName(detection *models.DetectionResult) string {
 	if detection == nil {
@@ -307,6 +307,12 @@ func copyBuildConfig(config *models.BuildConfig) *models.BuildConfig {
 			copied.EnvironmentVars[k] = v
 		}
 	}
+	if config.BuildArgs != nil {
+		copied.BuildArgs = make(map[string]string)
+		for k, v := range config.BuildArgs {
+			copied.BuildArgs[k] = v
+		}
+	}
 
 	return &copied
 }
@@ -1,37 +1,61 @@
 package executor
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 
-	"github.com/narvanalabs/control-plane/internal/builder/templates"
+	"github.com/narvanalabs/control-plane/internal/builder/clone"
 	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
 )
 
+// DefaultDockerfilePath is the Dockerfile built when the build config does
+// not name one.
+const DefaultDockerfilePath = "Dockerfile"
+
+// DockerfileExecutorConfig holds configuration for the Dockerfile strategy.
+type DockerfileExecutorConfig struct {
+	PodmanSocket string // Podman service the image is built on (e.g., "unix:///run/podman/podman.sock")
+	Registry     string // Registry URL the image is pushed to (e.g., "localhost:5000")
+
+	// BuildLimits are the build container's limits for services that do not
+	// set their own; podman.DefaultBuildLimits when zero
+	BuildLimits podman.ResourceLimits
+}
+
 // DockerfileStrategyExecutor executes builds using an existing Dockerfile.
-// This strategy always produces OCI images.
+// The image is built by Podman from the cloned repository and pushed to the
+// registry, so this strategy always produces OCI images.
 type DockerfileStrategyExecutor struct {
-	templateEngine templates.TemplateEngine
-	ociBuilder     OCIBuilder
-	logger         *slog.Logger
+	podman       string // Podman binary, replaced in tests
+	podmanSocket string
+	registry     string
+	limits       podman.ResourceLimits
+	logger       *slog.Logger
 }
 
 // NewDockerfileStrategyExecutor creates a new DockerfileStrategyExecutor.
-func NewDockerfileStrategyExecutor(
-	tmplEngine templates.TemplateEngine,
-	ociBuilder OCIBuilder,
-	logger *slog.Logger,
-) *DockerfileStrategyExecutor {
+func NewDockerfileStrategyExecutor(cfg *DockerfileExecutorConfig, logger *slog.Logger) *DockerfileStrategyExecutor {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	limits := cfg.BuildLimits
+	if limits == (podman.ResourceLimits{}) {
+		limits = podman.DefaultBuildLimits
+	}
 	return &DockerfileStrategyExecutor{
-		templateEngine: tmplEngine,
-		ociBuilder:     ociBuilder,
-		logger:         logger,
+		podman:       "podman",
+		podmanSocket: cfg.PodmanSocket,
+		registry:     cfg.Registry,
+		limits:       limits,
+		logger:       logger,
 	}
 }
 
@@ -40,29 +64,19 @@ func (e *DockerfileStrategyExecutor) Supports(strategy models.BuildStrategy) boo
 	return strategy == models.BuildStrategyDockerfile
 }
 
-// GenerateFlake generates a flake.nix wrapper for Dockerfile builds.
-// This uses nix2container to build OCI images from the Dockerfile.
+// GenerateFlake returns an empty string as the Dockerfile is built as is.
 func (e *DockerfileStrategyExecutor) GenerateFlake(ctx context.Context, detection *models.DetectionResult, config models.BuildConfig) (string, error) {
-	// Prepare template data
-	data := templates.TemplateData{
-		AppName:         getDockerAppName(detection),
-		StartCommand:    config.StartCommand,
-		Config:          config,
-		DetectionResult: detection,
-	}
-
-	// Render the template
-	flakeContent, err := e.templateEngine.Render(ctx, "dockerfile.nix", data)
-	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTemplateRenderFailed, err)
-	}
-
-	return flakeContent, nil
+	return "", nil
 }
 
 // Execute runs the build using the Dockerfile.
-// Dockerfile strategy always produces OCI images.
 func (e *DockerfileStrategyExecutor) Execute(ctx context.Context, job *models.BuildJob) (*BuildResult, error) {
+	return e.ExecuteWithLogs(ctx, job, nil)
+}
+
+// ExecuteWithLogs builds the repository's Dockerfile with Podman and pushes
+// the image to the registry, streaming the build output to the callback.
+func (e *DockerfileStrategyExecutor) ExecuteWithLogs(ctx context.Context, job *models.BuildJob, externalCallback LogCallback) (*BuildResult, error) {
 	e.logger.Info("executing dockerfile strategy",
 		"job_id", job.ID,
 	)
@@ -78,33 +92,66 @@ func (e *DockerfileStrategyExecutor) Execute(ctx context.Context, job *models.Bu
 	var logs string
 	logCallback := func(line string) {
 		logs += line + "\n"
+		if externalCallback != nil {
+			externalCallback(line)
+		}
 	}
 
 	logCallback("=== Building from Dockerfile ===")
 	logCallback("Build type: OCI (enforced for dockerfile strategy)")
 
-	// If we don't have a generated flake yet, generate one
-	if job.GeneratedFlake == "" {
-		logCallback("=== Generating flake.nix wrapper ===")
-
-		detection := &models.DetectionResult{
-			Strategy:             models.BuildStrategyDockerfile,
-			Framework:            models.FrameworkGeneric,
-			RecommendedBuildType: models.BuildTypeOCI,
+	repoPath := job.PreClonedRepoPath
+	if repoPath == "" {
+		logCallback("=== Cloning repository ===")
+		tempDir, err := os.MkdirTemp("", "dockerfile-*")
+		if err != nil {
+			return &BuildResult{Logs: logs}, fmt.Errorf("%w: creating build directory: %v", ErrBuildFailed, err)
 		}
+		defer os.RemoveAll(tempDir)
 
-		config := e.getConfigFromJob(job)
-		flakeContent, err := e.GenerateFlake(ctx, detection, config)
+		repoPath = filepath.Join(tempDir, "repo")
+		cloneResult, err := clone.Repository(ctx, job.GitURL, job.GitRef, repoPath)
 		if err != nil {
-			return &BuildResult{Logs: logs}, err
+			logCallback(fmt.Sprintf("Clone failed: %v", err))
+			return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrBuildFailed, err)
 		}
+		logCallback(fmt.Sprintf("Commit SHA: %s", cloneResult.CommitSHA))
+	}
+
+	// The build path is the build context; the Dockerfile is relative to it
+	contextDir, err := clone.Subdir(repoPath, job.BuildPath)
+	if err != nil {
+		logCallback(err.Error())
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrBuildFailed, err)
+	}
+	if job.BuildPath != "" {
+		logCallback(fmt.Sprintf("Build path: %s", job.BuildPath))
+	}
+
+	config := e.getConfigFromJob(job)
+	dockerfile, err := dockerfilePath(contextDir, config.DockerfilePath)
+	if err != nil {
+		logCallback(err.Error())
+		return &BuildResult{Logs: logs}, err
+	}
+
+	imageTag := e.imageTag(job)
+	logCallback(fmt.Sprintf("=== Building image %s ===", imageTag))
+	if err := e.runPodman(ctx, logCallback, e.buildArgs(contextDir, dockerfile, imageTag, config)...); err != nil {
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: podman build: %v", ErrBuildFailed, err)
+	}
 
-		job.GeneratedFlake = flakeContent
-		logCallback("Generated flake.nix wrapper successfully")
+	logCallback("=== Pushing image to registry ===")
+	if err := e.runPodman(ctx, logCallback, "push", imageTag); err != nil {
+		return &BuildResult{ImageTag: imageTag, Logs: logs}, fmt.Errorf("%w: pushing image %s: %v", ErrBuildFailed, imageTag, err)
 	}
+	logCallback(fmt.Sprintf("Successfully pushed: %s", imageTag))
 
-	// Execute the OCI build
-	return e.buildOCI(ctx, job, logCallback, &logs)
+	return &BuildResult{
+		Artifact: imageTag,
+		ImageTag: imageTag,
+		Logs:     logs,
+	}, nil
 }
 
 // getConfigFromJob extracts build config from the job.
@@ -115,40 +162,96 @@ func (e *DockerfileStrategyExecutor) getConfigFromJob(job *models.BuildJob) mode
 	return models.BuildConfig{}
 }
 
-// buildOCI executes an OCI build.
-func (e *DockerfileStrategyExecutor) buildOCI(ctx context.Context, job *models.BuildJob, logCallback func(string), logs *string) (*BuildResult, error) {
-	result, err := e.ociBuilder.BuildWithLogCallback(ctx, job, logCallback)
+// imageTag returns the registry reference the image is pushed as.
+// Format: registry/app-id:deployment-id
+func (e *DockerfileStrategyExecutor) imageTag(job *models.BuildJob) string {
+	tag := job.DeploymentID
+	if tag == "" {
+		tag = job.ID
+	}
+	return fmt.Sprintf("%s/%s:%s", e.registry, sanitizeImageName(job.AppID), tag)
+}
+
+// buildArgs returns the podman build arguments for the Dockerfile, limited
+// to the service's build CPU and memory. Build arguments are sorted so the
+// same config always yields the same command.
+func (e *DockerfileStrategyExecutor) buildArgs(repoPath, dockerfile, imageTag string, config models.BuildConfig) []string {
+	args := []string{"build", "-f", dockerfile, "-t", imageTag}
+
+	// podman build has no pids limit; only CPU and memory apply
+	limits := podman.BuildLimits(&config, e.limits)
+	limits.PidsLimit = 0
+	args = append(args, limits.Args()...)
+
+	keys := make([]string, 0, len(config.BuildArgs))
+	for key := range config.BuildArgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", key, config.BuildArgs[key]))
+	}
+
+	if config.Target != "" {
+		args = append(args, "--target", config.Target)
+	}
+	return append(args, repoPath)
+}
+
+// runPodman runs a podman command against the configured Podman service,
+// passing each line of its output to the callback.
+func (e *DockerfileStrategyExecutor) runPodman(ctx context.Context, logCallback func(string), args ...string) error {
+	if e.podmanSocket != "" {
+		args = append([]string{"--url", e.podmanSocket}, args...)
+	}
+	return runLogged(ctx, logCallback, e.podman, args...)
+}
+
+// runLogged runs a command, passing each line of its combined output to the
+// callback.
+func runLogged(ctx context.Context, logCallback func(string), name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return &BuildResult{
-			Logs: *logs,
-		}, fmt.Errorf("%w: %v", ErrBuildFailed, err)
+		return err
 	}
+	cmd.Stderr = cmd.Stdout
 
-	return &BuildResult{
-		Artifact:  result.ImageTag,
-		ImageTag:  result.ImageTag,
-		StorePath: result.StorePath,
-		Logs:      *logs,
-	}, nil
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		logCallback(scanner.Text())
+	}
+	// Keep draining after an overlong line so the command does not block
+	io.Copy(io.Discard, stdout)
+
+	return cmd.Wait()
 }
 
-// getDockerAppName extracts the application name from detection result.
-func getDockerAppName(detection *models.DetectionResult) string {
-	if detection == nil {
-		return "app"
+// dockerfilePath resolves the configured Dockerfile within the build context
+// and checks that it exists.
+func dockerfilePath(repoPath, configured string) (string, error) {
+	if configured == "" {
+		configured = DefaultDockerfilePath
 	}
-	// Check suggested config for name
-	if detection.SuggestedConfig != nil {
-		if name, ok := detection.SuggestedConfig["name"].(string); ok && name != "" {
-			return name
-		}
+	if filepath.IsAbs(configured) || !filepath.IsLocal(configured) {
+		return "", fmt.Errorf("%w: dockerfile path %q must be inside the repository", ErrBuildFailed, configured)
+	}
+
+	path := filepath.Join(repoPath, configured)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", ErrDockerfileNotFound, configured)
 	}
-	return "app"
+	return path, nil
 }
 
 // ValidateDockerfileExists checks if a Dockerfile exists in the given repository path.
 func ValidateDockerfileExists(repoPath string) error {
-	dockerfilePath := filepath.Join(repoPath, "Dockerfile")
+	dockerfilePath := filepath.Join(repoPath, DefaultDockerfilePath)
 	if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
 		return ErrDockerfileNotFound
 	}
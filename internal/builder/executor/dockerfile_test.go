@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// fakePodman writes a podman stand-in that echoes its arguments, failing
+// when they contain fail.
+func fakePodman(t *testing.T, fail string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "podman")
+	script := "#!/bin/sh\necho \"podman $*\"\necho step >&2\n"
+	if fail != "" {
+		script += "case \"$*\" in *" + fail + "*) exit 1;; esac\n"
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDockerfileExecutorBuildsAndPushes(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "deploy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "deploy", "Dockerfile.prod"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewDockerfileStrategyExecutor(&DockerfileExecutorConfig{
+		PodmanSocket: "unix:///run/podman/podman.sock",
+		Registry:     "registry.local:5000",
+	}, nil)
+	e.podman = fakePodman(t, "")
+
+	job := &models.BuildJob{
+		ID:                "build-1",
+		AppID:             "My App",
+		DeploymentID:      "dep-1",
+		BuildType:         models.BuildTypePureNix,
+		PreClonedRepoPath: repo,
+		BuildConfig: &models.BuildConfig{
+			DockerfilePath: "deploy/Dockerfile.prod",
+			BuildArgs:      map[string]string{"VERSION": "1.2", "ENV": "prod"},
+			Target:         "runtime",
+			BuildCPU:       "4",
+			BuildMemory:    "8Gi",
+		},
+	}
+
+	var streamed []string
+	result, err := e.ExecuteWithLogs(context.Background(), job, func(line string) {
+		streamed = append(streamed, line)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWithLogs() = %v\n%s", err, result.Logs)
+	}
+
+	wantTag := "registry.local:5000/my-app:dep-1"
+	if result.Artifact != wantTag || result.ImageTag != wantTag {
+		t.Errorf("artifact = %q, want %q", result.Artifact, wantTag)
+	}
+	if job.BuildType != models.BuildTypeOCI {
+		t.Errorf("build type = %s, want oci", job.BuildType)
+	}
+
+	wantBuild := "podman --url unix:///run/podman/podman.sock build -f " + filepath.Join(repo, "deploy", "Dockerfile.prod") +
+		" -t " + wantTag + " --cpu-period 100000 --cpu-quota 400000 --memory 8192m --build-arg ENV=prod --build-arg VERSION=1.2 --target runtime " + repo
+	wantPush := "podman --url unix:///run/podman/podman.sock push " + wantTag
+	logs := strings.Join(streamed, "\n")
+	if !strings.Contains(logs, wantBuild) || !strings.Contains(logs, wantPush) || !strings.Contains(logs, "step") {
+		t.Errorf("streamed logs missing podman output:\n%s", logs)
+	}
+	if result.Logs != logs+"\n" {
+		t.Errorf("result logs differ from the streamed ones")
+	}
+}
+
+func TestDockerfileExecutorFailures(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		dockerfile string
+		fail       string
+		want       error
+	}{
+		{"missing dockerfile", "Containerfile", "", ErrDockerfileNotFound},
+		{"outside repository", "../Dockerfile", "", ErrBuildFailed},
+		{"build fails", "", "build", ErrBuildFailed},
+		{"push fails", "", "push", ErrBuildFailed},
+	} {
+		e := NewDockerfileStrategyExecutor(&DockerfileExecutorConfig{Registry: "localhost:5000"}, nil)
+		e.podman = fakePodman(t, tc.fail)
+		job := &models.BuildJob{
+			ID:                "build-1",
+			AppID:             "app",
+			PreClonedRepoPath: repo,
+			BuildConfig:       &models.BuildConfig{DockerfilePath: tc.dockerfile},
+		}
+
+		result, err := e.Execute(context.Background(), job)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: Execute() = %v, want %v", tc.name, err, tc.want)
+			continue
+		}
+		if result.Artifact != "" {
+			t.Errorf("%s: artifact = %q after a failure", tc.name, result.Artifact)
+		}
+	}
+}
+
+func TestDockerfileExecutorBuildsFromBuildPath(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "services", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "services", "api", "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(t.TempDir(), filepath.Join(repo, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewDockerfileStrategyExecutor(&DockerfileExecutorConfig{Registry: "localhost:5000"}, nil)
+	e.podman = fakePodman(t, "")
+	job := &models.BuildJob{ID: "build-1", AppID: "app", PreClonedRepoPath: repo, BuildPath: "services/api"}
+
+	result, err := e.Execute(context.Background(), job)
+	if err != nil {
+		t.Fatalf("Execute() = %v\n%s", err, result.Logs)
+	}
+	contextDir, _ := filepath.EvalSymlinks(filepath.Join(repo, "services", "api"))
+	if !strings.Contains(result.Logs, "build -f "+filepath.Join(contextDir, "Dockerfile")+" -t localhost:5000/app:build-1 --cpu-period 100000 --cpu-quota 200000 --memory 4096m "+contextDir+"\n") {
+		t.Errorf("image not built from the build path:\n%s", result.Logs)
+	}
+
+	for _, buildPath := range []string{"services/web", "escape"} {
+		job.BuildPath = buildPath
+		if _, err := e.Execute(context.Background(), job); !errors.Is(err, ErrBuildFailed) {
+			t.Errorf("build path %q: Execute() = %v, want %v", buildPath, err, ErrBuildFailed)
+		}
+	}
+}
@@ -1,34 +1,44 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/narvanalabs/control-plane/internal/builder/clone"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
-// NixpacksStrategyExecutor executes builds using Nixpacks.
-// This strategy always produces OCI images.
+// nixpacksDockerfile is where nixpacks writes the Dockerfile of its build
+// plan within the output directory.
+const nixpacksDockerfile = ".nixpacks/Dockerfile"
+
+// NixpacksStrategyExecutor executes builds using Nixpacks. Nixpacks detects
+// the build plan and writes it out as a Dockerfile, which is built by Podman
+// and pushed to the registry like the Dockerfile strategy, so this strategy
+// always produces OCI images.
 type NixpacksStrategyExecutor struct {
-	ociBuilder OCIBuilder
-	logger     *slog.Logger
+	nixpacks string // Nixpacks binary, replaced in tests
+	image    *DockerfileStrategyExecutor
+	logger   *slog.Logger
 }
 
-// NewNixpacksStrategyExecutor creates a new NixpacksStrategyExecutor.
-func NewNixpacksStrategyExecutor(
-	ociBuilder OCIBuilder,
-	logger *slog.Logger,
-) *NixpacksStrategyExecutor {
+// NewNixpacksStrategyExecutor creates a new NixpacksStrategyExecutor that
+// builds images on the same Podman service and registry as the Dockerfile
+// strategy.
+func NewNixpacksStrategyExecutor(cfg *DockerfileExecutorConfig, logger *slog.Logger) *NixpacksStrategyExecutor {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	return &NixpacksStrategyExecutor{
-		ociBuilder: ociBuilder,
-		logger:     logger,
+		nixpacks: "nixpacks",
+		image:    NewDockerfileStrategyExecutor(cfg, logger),
+		logger:   logger,
 	}
 }
 
@@ -45,8 +55,14 @@ func (e *NixpacksStrategyExecutor) GenerateFlake(ctx context.Context, detection
 }
 
 // Execute runs the build using Nixpacks.
-// Nixpacks strategy always produces OCI images.
 func (e *NixpacksStrategyExecutor) Execute(ctx context.Context, job *models.BuildJob) (*BuildResult, error) {
+	return e.ExecuteWithLogs(ctx, job, nil)
+}
+
+// ExecuteWithLogs generates the Nixpacks build plan of the repository,
+// builds it with Podman and pushes the image to the registry, streaming the
+// output to the callback.
+func (e *NixpacksStrategyExecutor) ExecuteWithLogs(ctx context.Context, job *models.BuildJob, externalCallback LogCallback) (*BuildResult, error) {
 	e.logger.Info("executing nixpacks strategy",
 		"job_id", job.ID,
 	)
@@ -62,30 +78,74 @@ func (e *NixpacksStrategyExecutor) Execute(ctx context.Context, job *models.Buil
 	var logs string
 	logCallback := func(line string) {
 		logs += line + "\n"
+		if externalCallback != nil {
+			externalCallback(line)
+		}
 	}
 
 	logCallback("=== Building with Nixpacks ===")
 	logCallback("Build type: OCI (enforced for nixpacks strategy)")
 
-	// Generate the build plan first
-	logCallback("=== Generating Nixpacks build plan ===")
-	plan, err := e.generateBuildPlan(ctx, job)
-	if err != nil {
-		logCallback(fmt.Sprintf("Failed to generate build plan: %v", err))
-		return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrNixpacksFailed, err)
+	if _, err := exec.LookPath(e.nixpacks); err != nil {
+		logCallback("nixpacks is not installed on this build worker")
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: nixpacks not found: %v", ErrNixpacksFailed, err)
 	}
-	logCallback(fmt.Sprintf("Build plan generated:\n%s", plan))
-
-	// Execute the Nixpacks build
-	logCallback("=== Executing Nixpacks build ===")
-	imageTag, buildLogs, err := e.runNixpacksBuild(ctx, job)
-	logs += buildLogs
 
+	tempDir, err := os.MkdirTemp("", "nixpacks-*")
 	if err != nil {
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: creating build directory: %v", ErrBuildFailed, err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	repoPath := job.PreClonedRepoPath
+	if repoPath == "" {
+		logCallback("=== Cloning repository ===")
+		repoPath = filepath.Join(tempDir, "repo")
+		cloneResult, err := clone.Repository(ctx, job.GitURL, job.GitRef, repoPath)
+		if err != nil {
+			logCallback(fmt.Sprintf("Clone failed: %v", err))
+			return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrBuildFailed, err)
+		}
+		logCallback(fmt.Sprintf("Commit SHA: %s", cloneResult.CommitSHA))
+	}
+	contextDir, err := clone.Subdir(repoPath, job.BuildPath)
+	if err != nil {
+		logCallback(err.Error())
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrBuildFailed, err)
+	}
+	if job.BuildPath != "" {
+		logCallback(fmt.Sprintf("Build path: %s", job.BuildPath))
+	}
+
+	// Write the plan to a separate directory so the checkout stays clean
+	config := e.image.getConfigFromJob(job)
+	outDir := filepath.Join(tempDir, "out")
+	logCallback("=== Generating Nixpacks build plan ===")
+	if err := runLogged(ctx, logCallback, e.nixpacks, e.planArgs(contextDir, outDir, config)...); err != nil {
 		return &BuildResult{Logs: logs}, fmt.Errorf("%w: %v", ErrNixpacksFailed, err)
 	}
+	dockerfile := filepath.Join(outDir, nixpacksDockerfile)
+	if _, err := os.Stat(dockerfile); err != nil {
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: nixpacks wrote no Dockerfile: %v", ErrNixpacksFailed, err)
+	}
 
-	logCallback(fmt.Sprintf("Successfully built image: %s", imageTag))
+	// The plan declares the environment as build arguments
+	imageConfig := models.BuildConfig{
+		BuildArgs:   nixpacksEnv(config),
+		BuildCPU:    config.BuildCPU,
+		BuildMemory: config.BuildMemory,
+	}
+	imageTag := e.image.imageTag(job)
+	logCallback(fmt.Sprintf("=== Building image %s ===", imageTag))
+	if err := e.image.runPodman(ctx, logCallback, e.image.buildArgs(outDir, dockerfile, imageTag, imageConfig)...); err != nil {
+		return &BuildResult{Logs: logs}, fmt.Errorf("%w: podman build: %v", ErrNixpacksFailed, err)
+	}
+
+	logCallback("=== Pushing image to registry ===")
+	if err := e.image.runPodman(ctx, logCallback, "push", imageTag); err != nil {
+		return &BuildResult{ImageTag: imageTag, Logs: logs}, fmt.Errorf("%w: pushing image %s: %v", ErrBuildFailed, imageTag, err)
+	}
+	logCallback(fmt.Sprintf("Successfully pushed: %s", imageTag))
 
 	return &BuildResult{
 		Artifact: imageTag,
@@ -94,71 +154,47 @@ func (e *NixpacksStrategyExecutor) Execute(ctx context.Context, job *models.Buil
 	}, nil
 }
 
-// generateBuildPlan generates a Nixpacks build plan for the repository.
-func (e *NixpacksStrategyExecutor) generateBuildPlan(ctx context.Context, job *models.BuildJob) (string, error) {
-	// Build the nixpacks plan command
-	args := []string{"plan", job.GitURL}
-
-	// Add any custom configuration from build config
-	if job.BuildConfig != nil {
-		if job.BuildConfig.BuildCommand != "" {
-			args = append(args, "--build-cmd", job.BuildConfig.BuildCommand)
-		}
-		if job.BuildConfig.StartCommand != "" {
-			args = append(args, "--start-cmd", job.BuildConfig.StartCommand)
-		}
+// planArgs returns the nixpacks arguments that write the repository's build
+// plan to outDir, applying the build config's commands, packages and
+// environment.
+func (e *NixpacksStrategyExecutor) planArgs(repoPath, outDir string, config models.BuildConfig) []string {
+	args := []string{"build", repoPath, "--out", outDir}
+	if config.BuildCommand != "" {
+		args = append(args, "--build-cmd", config.BuildCommand)
 	}
-
-	cmd := exec.CommandContext(ctx, "nixpacks", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("nixpacks plan failed: %s: %w", stderr.String(), err)
+	if config.StartCommand != "" {
+		args = append(args, "--start-cmd", config.StartCommand)
+	}
+	if len(config.ExtraNixPackages) > 0 {
+		args = append(args, "--pkgs", strings.Join(config.ExtraNixPackages, " "))
 	}
 
-	return stdout.String(), nil
+	env := nixpacksEnv(config)
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", key, env[key]))
+	}
+	return args
 }
 
-// runNixpacksBuild executes the Nixpacks build and returns the image tag.
-func (e *NixpacksStrategyExecutor) runNixpacksBuild(ctx context.Context, job *models.BuildJob) (string, string, error) {
-	// Generate image name
-	imageName := sanitizeImageName(job.AppID)
-	imageTag := fmt.Sprintf("%s:%s", imageName, job.DeploymentID)
-	if job.DeploymentID == "" {
-		imageTag = fmt.Sprintf("%s:%s", imageName, job.ID)
+// nixpacksEnv returns the build environment: the configured variables plus
+// the Nixpacks settings for the configured language versions.
+func nixpacksEnv(config models.BuildConfig) map[string]string {
+	env := make(map[string]string, len(config.EnvironmentVars)+2)
+	for key, value := range config.EnvironmentVars {
+		env[key] = value
 	}
-
-	// Build the nixpacks build command
-	args := []string{"build", job.GitURL, "--name", imageTag}
-
-	// Add any custom configuration from build config
-	if job.BuildConfig != nil {
-		if job.BuildConfig.BuildCommand != "" {
-			args = append(args, "--build-cmd", job.BuildConfig.BuildCommand)
-		}
-		if job.BuildConfig.StartCommand != "" {
-			args = append(args, "--start-cmd", job.BuildConfig.StartCommand)
-		}
-		// Add environment variables
-		for key, value := range job.BuildConfig.EnvironmentVars {
-			args = append(args, "--env", fmt.Sprintf("%s=%s", key, value))
-		}
+	if config.NodeVersion != "" {
+		env["NIXPACKS_NODE_VERSION"] = config.NodeVersion
 	}
-
-	cmd := exec.CommandContext(ctx, "nixpacks", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		logs := stdout.String() + "\n" + stderr.String()
-		return "", logs, fmt.Errorf("nixpacks build failed: %w", err)
+	if config.PythonVersion != "" {
+		env["NIXPACKS_PYTHON_VERSION"] = config.PythonVersion
 	}
-
-	logs := stdout.String() + "\n" + stderr.String()
-	return imageTag, logs, nil
+	return env
 }
 
 // sanitizeImageName converts a string to a valid Docker image name.
@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// fakeNixpacks writes a nixpacks stand-in that echoes its arguments and
+// writes a Dockerfile to the --out directory, or exits 1 if fail is set.
+func fakeNixpacks(t *testing.T, fail bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "nixpacks")
+	script := "#!/bin/sh\necho \"nixpacks $*\"\n"
+	if fail {
+		script += "echo 'unable to generate a build plan' >&2\nexit 1\n"
+	}
+	script += "while [ $# -gt 0 ]; do if [ \"$1\" = --out ]; then out=$2; fi; shift; done\n" +
+		"mkdir -p \"$out/.nixpacks\" && echo 'FROM scratch' > \"$out/.nixpacks/Dockerfile\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestNixpacksExecutor(t *testing.T, failPlan bool, failPodman string) *NixpacksStrategyExecutor {
+	t.Helper()
+	e := NewNixpacksStrategyExecutor(&DockerfileExecutorConfig{Registry: "registry.local:5000"}, nil)
+	e.nixpacks = fakeNixpacks(t, failPlan)
+	e.image.podman = fakePodman(t, failPodman)
+	return e
+}
+
+func TestNixpacksExecutorBuildsAndPushes(t *testing.T) {
+	repo := t.TempDir()
+	e := newTestNixpacksExecutor(t, false, "")
+	job := &models.BuildJob{
+		ID:                "build-1",
+		AppID:             "app-1",
+		DeploymentID:      "dep-1",
+		BuildType:         models.BuildTypePureNix,
+		BuildStrategy:     models.BuildStrategyNixpacks,
+		PreClonedRepoPath: repo,
+		BuildConfig: &models.BuildConfig{
+			BuildCommand:     "npm run build",
+			StartCommand:     "npm start",
+			NodeVersion:      "20",
+			ExtraNixPackages: []string{"ffmpeg", "imagemagick"},
+			EnvironmentVars:  map[string]string{"API_URL": "https://api.example.com"},
+		},
+	}
+
+	var streamed []string
+	result, err := e.ExecuteWithLogs(context.Background(), job, func(line string) {
+		streamed = append(streamed, line)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWithLogs() = %v\n%s", err, result.Logs)
+	}
+
+	wantTag := "registry.local:5000/app-1:dep-1"
+	if result.Artifact != wantTag || job.BuildType != models.BuildTypeOCI {
+		t.Errorf("artifact = %q, build type = %s", result.Artifact, job.BuildType)
+	}
+
+	logs := strings.Join(streamed, "\n")
+	wantPlan := "nixpacks build " + repo + " --out "
+	wantFlags := " --build-cmd npm run build --start-cmd npm start --pkgs ffmpeg imagemagick" +
+		" --env API_URL=https://api.example.com --env NIXPACKS_NODE_VERSION=20"
+	wantBuild := " --build-arg API_URL=https://api.example.com --build-arg NIXPACKS_NODE_VERSION=20"
+	for _, want := range []string{wantPlan, wantFlags, "podman build -f ", wantBuild, "podman push " + wantTag} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs missing %q:\n%s", want, logs)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".nixpacks")); !os.IsNotExist(err) {
+		t.Error("the build plan was written into the checkout")
+	}
+}
+
+func TestNixpacksExecutorFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		failPlan   bool
+		failPodman string
+		want       error
+		wantLog    string
+	}{
+		{"plan fails", true, "", ErrNixpacksFailed, "unable to generate a build plan"},
+		{"image build fails", false, "build", ErrNixpacksFailed, "podman build"},
+		{"push fails", false, "push", ErrBuildFailed, "podman push"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestNixpacksExecutor(t, tt.failPlan, tt.failPodman)
+			job := &models.BuildJob{ID: "build-1", AppID: "app-1", DeploymentID: "dep-1", PreClonedRepoPath: t.TempDir()}
+
+			result, err := e.Execute(context.Background(), job)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Execute() = %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(result.Logs, tt.wantLog) {
+				t.Errorf("logs missing %q:\n%s", tt.wantLog, result.Logs)
+			}
+		})
+	}
+
+	t.Run("nixpacks missing", func(t *testing.T) {
+		e := newTestNixpacksExecutor(t, false, "")
+		e.nixpacks = filepath.Join(t.TempDir(), "nixpacks")
+		_, err := e.Execute(context.Background(), &models.BuildJob{ID: "build-1", PreClonedRepoPath: t.TempDir()})
+		if !errors.Is(err, ErrNixpacksFailed) {
+			t.Errorf("Execute() = %v, want %v", err, ErrNixpacksFailed)
+		}
+	})
+}
@@ -223,6 +223,8 @@ type MockStore struct {
 	builds         *MockBuildStore
 	secrets        *MockSecretStore
 	logs           *LifecycleMockLogStore
+	buildLogs      *MockBuildLogStore
+	snapshots      *MockBuildSnapshotStore
 	users          *MockUserStore
 	github         *MockGitHubStore
 	githubAccounts *MockGitHubAccountStore
@@ -240,6 +242,8 @@ func NewMockStore() *MockStore {
 		builds:         NewMockBuildStore(),
 		secrets:        NewMockSecretStore(),
 		logs:           NewLifecycleMockLogStore(),
+		buildLogs:      NewMockBuildLogStore(),
+		snapshots:      NewMockBuildSnapshotStore(),
 		users:          NewMockUserStore(),
 		github:         NewMockGitHubStore(),
 		githubAccounts: NewMockGitHubAccountStore(),
@@ -261,6 +265,51 @@ func (m *MockStore) GitHubAccounts() store.GitHubAccountStore
 func (m *MockStore) Settings() store.SettingsStore                                { return m.settings }
 func (m *MockStore) Domains() store.DomainStore                                   { return m.domains }
 func (m *MockStore) Invitations() store.InvitationStore                           { return nil }
+func (m *MockStore) Announcements() store.AnnouncementStore                       { return nil }
+func (m *MockStore) Releases() store.ReleaseStore                                 { return nil }
+func (m *MockStore) Stats() store.StatsStore                                      { return nil }
+func (m *MockStore) EgressViolations() store.EgressViolationStore                 { return nil }
+func (m *MockStore) DeployFreezes() store.DeployFreezeStore                       { return nil }
+func (m *MockStore) OnCall() store.OnCallStore                                    { return nil }
+func (m *MockStore) APICatalog() store.APICatalogStore                            { return nil }
+func (m *MockStore) SCIM() store.SCIMStore                                        { return nil }
+func (m *MockStore) APIKeys() store.APIKeyStore                                   { return nil }
+func (m *MockStore) Notifications() store.NotificationStore                       { return nil }
+func (m *MockStore) Promotions() store.PromotionStore                             { return nil }
+func (m *MockStore) SSH() store.SSHStore                                          { return nil }
+func (m *MockStore) BuildWorkers() store.BuildWorkerStore                         { return nil }
+func (m *MockStore) ServiceTemplates() store.ServiceTemplateStore                 { return nil }
+func (m *MockStore) BuildLogs() store.BuildLogStore                               { return m.buildLogs }
+func (m *MockStore) BuildSnapshots() store.BuildSnapshotStore                     { return m.snapshots }
+func (m *MockStore) AppHooks() store.AppHookStore                                 { return nil }
+func (m *MockStore) CDN() store.CDNStore                                          { return nil }
+func (m *MockStore) BuildAttestations() store.BuildAttestationStore               { return nil }
+func (m *MockStore) CronRuns() store.CronRunStore                                 { return nil }
+func (m *MockStore) Metrics() store.MetricStore                                   { return nil }
+func (m *MockStore) AdmissionPolicies() store.AdmissionPolicyStore                { return nil }
+func (m *MockStore) Audit() store.AuditStore                                      { return nil }
+func (m *MockStore) OrgSecrets() store.OrgSecretStore                             { return nil }
+func (m *MockStore) NodeJoinTokens() store.NodeJoinTokenStore                     { return nil }
+func (m *MockStore) SmokeTests() store.SmokeTestStore                             { return nil }
+func (m *MockStore) LoadTests() store.LoadTestStore                               { return nil }
+func (m *MockStore) Archives() store.ArchiveStore                                 { return nil }
+func (m *MockStore) Operations() store.OperationStore                             { return nil }
+func (m *MockStore) DebugSessions() store.DebugSessionStore                       { return nil }
+func (m *MockStore) AuthSessions() store.AuthSessionStore                         { return nil }
+func (m *MockStore) RateLimits() store.RateLimitStore                             { return nil }
+func (m *MockStore) Consistency() store.ConsistencyStore                          { return nil }
+func (m *MockStore) DBHealth() store.DBHealthStore                                { return nil }
+func (m *MockStore) AppManifests() store.AppManifestStore                         { return nil }
+func (m *MockStore) IdempotencyKeys() store.IdempotencyKeyStore                   { return nil }
+func (m *MockStore) ScaleEvents() store.ScaleEventStore                           { return nil }
+func (m *MockStore) Environments() store.EnvironmentStore                         { return nil }
+func (m *MockStore) Federation() store.FederationStore                            { return nil }
+func (m *MockStore) EvidenceExports() store.EvidenceExportStore                   { return nil }
+func (m *MockStore) APIUsage() store.APIUsageStore                                { return nil }
+func (m *MockStore) Events() store.EventStore                                     { return nil }
+func (m *MockStore) Webhooks() store.WebhookStore                                 { return nil }
+func (m *MockStore) AppTemplates() store.AppTemplateStore                         { return nil }
+func (m *MockStore) Services() store.ServiceStore                                 { return nil }
 func (m *MockStore) WithTx(ctx context.Context, fn func(store.Store) error) error { return fn(m) }
 func (m *MockStore) Close() error                                                 { return nil }
 func (m *MockStore) Ping(ctx context.Context) error                               { return nil }
@@ -356,10 +405,24 @@ func (m *MockOrgStore) ListMembers(ctx context.Context, orgID string) ([]*models
 	return nil, nil
 }
 
+func (m *MockOrgStore) ListAll(ctx context.Context) ([]*models.Organization, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*models.Organization
+	for _, org := range m.orgs {
+		result = append(result, org)
+	}
+	return result, nil
+}
+
 func (m *MockOrgStore) IsMember(ctx context.Context, orgID, userID string) (bool, error) {
 	return true, nil
 }
 
+func (m *MockOrgStore) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	return models.RoleOwner, nil
+}
+
 func (m *MockOrgStore) GetDefaultForUser(ctx context.Context, userID string) (*models.Organization, error) {
 	return m.GetDefault(ctx)
 }
@@ -475,6 +538,45 @@ func (m *MockBuildStore) ListQueued(ctx context.Context) ([]*models.BuildJob, er
 	return queued, nil
 }
 
+// FindByContentHash returns the most recently finished successful build with
+// the given content hash and an artifact.
+func (m *MockBuildStore) FindByContentHash(ctx context.Context, contentHash string) (*models.BuildJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found *models.BuildJob
+	for _, build := range m.builds {
+		if build.ContentHash != contentHash || build.Status != models.BuildStatusSucceeded || build.Artifact == "" {
+			continue
+		}
+		if found == nil || (build.FinishedAt != nil && found.FinishedAt != nil && build.FinishedAt.After(*found.FinishedAt)) {
+			found = build
+		}
+	}
+	if found == nil {
+		return nil, errors.New("build not found")
+	}
+	return found, nil
+}
+
+// FailureStats is not needed by the lifecycle tests.
+func (m *MockBuildStore) FailureStats(ctx context.Context, appID, serviceName string, since time.Time) (*models.BuildFailureStats, error) {
+	return &models.BuildFailureStats{ServiceName: serviceName, Since: since}, nil
+}
+
+func (m *MockBuildStore) RequestCancel(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	build, ok := m.builds[id]
+	if !ok {
+		return errors.New("build not found")
+	}
+	if build.CancelRequestedAt == nil {
+		now := time.Now()
+		build.CancelRequestedAt = &now
+	}
+	return nil
+}
+
 func (m *MockBuildStore) List(ctx context.Context, appID string) ([]*models.BuildJob, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -800,6 +902,18 @@ func (m *MockAppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.A
 	return result, nil
 }
 
+func (m *MockAppStore) ListAll(ctx context.Context) ([]*models.App, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*models.App
+	for _, app := range m.apps {
+		if app.DeletedAt == nil {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
 // MockNodeStore is a mock implementation of NodeStore for testing.
 type MockNodeStore struct {
 	mu    sync.Mutex
@@ -856,6 +970,52 @@ func (m *MockNodeStore) ListWithClosure(ctx context.Context, storePath string) (
 	return m.List(ctx)
 }
 
+func (m *MockNodeStore) UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if node, ok := m.nodes[id]; ok {
+		node.Status = status
+	}
+	return nil
+}
+
+func (m *MockNodeStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nodes, id)
+	return nil
+}
+
+func (m *MockNodeStore) UpdateHealthScore(ctx context.Context, id string, score int, load *models.NodeLoad) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if node, ok := m.nodes[id]; ok {
+		node.HealthScore = score
+		node.Load = load
+	}
+	return nil
+}
+
+func (m *MockNodeStore) UpdateClock(ctx context.Context, id string, skew time.Duration, certificateExpiresAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if node, ok := m.nodes[id]; ok {
+		node.ClockSkewMs = skew.Milliseconds()
+		if certificateExpiresAt != nil {
+			node.CertificateExpiresAt = certificateExpiresAt
+		}
+	}
+	return nil
+}
+
+func (m *MockNodeStore) CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error {
+	return nil
+}
+
+func (m *MockNodeStore) ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error) {
+	return nil, nil
+}
+
 // MockSecretStore is a mock implementation of SecretStore for testing.
 type MockSecretStore struct {
 	mu      sync.Mutex
@@ -939,6 +1099,15 @@ func (m *LifecycleMockLogStore) Create(ctx context.Context, entry *models.LogEnt
 	return nil
 }
 
+func (m *LifecycleMockLogStore) CreateBatch(ctx context.Context, entries []*models.LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, entry := range entries {
+		m.logs[entry.DeploymentID] = append(m.logs[entry.DeploymentID], entry)
+	}
+	return nil
+}
+
 func (m *LifecycleMockLogStore) List(ctx context.Context, deploymentID string, limit int) ([]*models.LogEntry, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -964,10 +1133,115 @@ func (m *LifecycleMockLogStore) ListBySource(ctx context.Context, deploymentID,
 	return result, nil
 }
 
+func (m *LifecycleMockLogStore) Search(ctx context.Context, filter models.LogFilter) ([]*models.LogEntry, error) {
+	return nil, nil
+}
+
 func (m *LifecycleMockLogStore) DeleteOlderThan(ctx context.Context, deploymentID string, before int64) error {
 	return nil
 }
 
+func (m *LifecycleMockLogStore) DeleteByDeployment(ctx context.Context, deploymentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.logs, deploymentID)
+	return nil
+}
+
+// MockBuildLogStore is a mock implementation of BuildLogStore for testing.
+type MockBuildLogStore struct {
+	mu     sync.Mutex
+	chunks map[string][]*models.BuildLogChunk
+}
+
+// NewMockBuildLogStore creates a new MockBuildLogStore.
+func NewMockBuildLogStore() *MockBuildLogStore {
+	return &MockBuildLogStore{chunks: make(map[string][]*models.BuildLogChunk)}
+}
+
+func (m *MockBuildLogStore) Append(ctx context.Context, chunk *models.BuildLogChunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chunk.Seq = len(m.chunks[chunk.BuildID]) + 1
+	chunk.CreatedAt = time.Now()
+	m.chunks[chunk.BuildID] = append(m.chunks[chunk.BuildID], chunk)
+	return nil
+}
+
+func (m *MockBuildLogStore) List(ctx context.Context, buildID string, afterSeq, limit int) ([]*models.BuildLogChunk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*models.BuildLogChunk
+	for _, chunk := range m.chunks[buildID] {
+		if chunk.Seq > afterSeq {
+			result = append(result, chunk)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		return result[:limit], nil
+	}
+	return result, nil
+}
+
+func (m *MockBuildLogStore) Restore(ctx context.Context, chunks []*models.BuildLogChunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, chunk := range chunks {
+		m.chunks[chunk.BuildID] = append(m.chunks[chunk.BuildID], chunk)
+	}
+	return nil
+}
+
+func (m *MockBuildLogStore) DeleteByBuild(ctx context.Context, buildID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.chunks, buildID)
+	return nil
+}
+
+// MockBuildSnapshotStore is a mock implementation of BuildSnapshotStore for testing.
+type MockBuildSnapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*models.BuildSnapshot
+}
+
+// NewMockBuildSnapshotStore creates a new MockBuildSnapshotStore.
+func NewMockBuildSnapshotStore() *MockBuildSnapshotStore {
+	return &MockBuildSnapshotStore{snapshots: make(map[string]*models.BuildSnapshot)}
+}
+
+func (m *MockBuildSnapshotStore) Save(ctx context.Context, snapshot *models.BuildSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[snapshot.BuildID] = snapshot
+	return nil
+}
+
+func (m *MockBuildSnapshotStore) Get(ctx context.Context, buildID string) (*models.BuildSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshots[buildID], nil
+}
+
+func (m *MockBuildSnapshotStore) ListExpired(ctx context.Context, hostname string, before time.Time) ([]*models.BuildSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*models.BuildSnapshot
+	for _, s := range m.snapshots {
+		if s.Hostname == hostname && !s.ExpiresAt.After(before) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockBuildSnapshotStore) Delete(ctx context.Context, buildID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.snapshots, buildID)
+	return nil
+}
+
 // MockUserStore is a mock implementation of UserStore for testing.
 type MockUserStore struct {
 	mu    sync.Mutex
@@ -1654,6 +1928,16 @@ func (m *MockDomainStore) List(ctx context.Context, appID string) ([]*models.Dom
 	return result, nil
 }
 
+func (m *MockDomainStore) Update(ctx context.Context, domain *models.Domain) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.domains[domain.ID]; !ok {
+		return errors.New("domain not found")
+	}
+	m.domains[domain.ID] = domain
+	return nil
+}
+
 func (m *MockDomainStore) Delete(ctx context.Context, id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
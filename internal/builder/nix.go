@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/builder/clone"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/podman"
 )
@@ -21,9 +22,10 @@ type NixBuilder struct {
 	podmanClient *podman.Client
 	workDir      string
 	nixImage     string
-	atticURL     string // Attic binary cache URL
-	atticCache   string // Attic cache name
-	atticToken   string // Attic JWT token
+	atticURL     string       // Attic binary cache URL
+	caches       CacheMapping // Attic caches builds pull from and push to
+	atticToken   string       // Attic JWT token
+	limits       podman.ResourceLimits
 	logger       *slog.Logger
 }
 
@@ -33,8 +35,17 @@ type NixBuilderConfig struct {
 	PodmanSocket string
 	NixImage     string // Docker image with Nix installed
 	AtticURL     string // Attic binary cache URL (e.g., "http://localhost:5000")
-	AtticCache   string // Attic cache name (e.g., "narvana")
+	AtticCache   string // Attic cache shared by all apps (e.g., "narvana")
 	AtticToken   string // Attic JWT token for authentication
+
+	// AtticPerAppCache gives each app its own cache, named "<AtticCache>-<appID>"
+	AtticPerAppCache bool
+	// AtticAppCaches names the caches of specific apps by app ID
+	AtticAppCaches map[string]string
+
+	// BuildLimits are the build container's limits for services that do not
+	// set their own; podman.DefaultBuildLimits when zero
+	BuildLimits podman.ResourceLimits
 }
 
 // DefaultNixBuilderConfig returns a NixBuilderConfig with sensible defaults.
@@ -65,14 +76,24 @@ func NewNixBuilder(cfg *NixBuilderConfig, logger *slog.Logger) (*NixBuilder, err
 
 	podmanClient := podman.NewClient(cfg.PodmanSocket, logger)
 
+	limits := cfg.BuildLimits
+	if limits == (podman.ResourceLimits{}) {
+		limits = podman.DefaultBuildLimits
+	}
+
 	return &NixBuilder{
 		podmanClient: podmanClient,
 		workDir:      cfg.WorkDir,
 		nixImage:     cfg.NixImage,
 		atticURL:     cfg.AtticURL,
-		atticCache:   cfg.AtticCache,
-		atticToken:   cfg.AtticToken,
-		logger:       logger,
+		caches: CacheMapping{
+			Shared: cfg.AtticCache,
+			PerApp: cfg.AtticPerAppCache,
+			Apps:   cfg.AtticAppCaches,
+		},
+		atticToken: cfg.AtticToken,
+		limits:     limits,
+		logger:     logger,
 	}, nil
 }
 
@@ -95,12 +116,16 @@ func (b *NixBuilder) Build(ctx context.Context, job *models.BuildJob) (*NixBuild
 		"has_generated_flake", job.GeneratedFlake != "",
 	)
 
-	// Create a unique build directory
+	// Create a unique build directory, dropping a snapshot kept by an earlier
+	// attempt of the build
 	buildDir := filepath.Join(b.workDir, job.ID)
+	job.Snapshot = nil
+	job.CacheStats = nil
+	os.RemoveAll(buildDir)
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating build directory: %w", err)
 	}
-	defer os.RemoveAll(buildDir) // Clean up after build
+	defer b.cleanupBuildDir(job, buildDir) // Clean up after build
 
 	var flakeRef string
 
@@ -122,13 +147,7 @@ func (b *NixBuilder) Build(ctx context.Context, job *models.BuildJob) (*NixBuild
 		}
 	} else {
 		// Build from the git URL
-		flakeRef = job.GitURL
-		if job.GitRef != "" {
-			flakeRef = fmt.Sprintf("%s?ref=%s", job.GitURL, job.GitRef)
-		}
-		if job.FlakeOutput != "" {
-			flakeRef = fmt.Sprintf("%s#%s", flakeRef, job.FlakeOutput)
-		}
+		flakeRef = gitFlakeRef(job)
 	}
 
 	// Create a log buffer to capture output
@@ -148,6 +167,26 @@ func (b *NixBuilder) Build(ctx context.Context, job *models.BuildJob) (*NixBuild
 	return result, nil
 }
 
+// gitFlakeRef returns the reference of a job's flake in its git repository,
+// in the build path's directory when the job has one.
+func gitFlakeRef(job *models.BuildJob) string {
+	var params []string
+	if job.GitRef != "" {
+		params = append(params, "ref="+job.GitRef)
+	}
+	if job.BuildPath != "" {
+		params = append(params, "dir="+job.BuildPath)
+	}
+	flakeRef := job.GitURL
+	if len(params) > 0 {
+		flakeRef += "?" + strings.Join(params, "&")
+	}
+	if job.FlakeOutput != "" {
+		flakeRef += "#" + job.FlakeOutput
+	}
+	return flakeRef
+}
+
 // buildInContainer executes the nix build inside a Podman container.
 // **Validates: Requirements 4.2** - Reuses pre-cloned repo when available
 func (b *NixBuilder) buildInContainer(ctx context.Context, job *models.BuildJob, buildDir, flakeRef string, logWriter io.Writer) (*NixBuildResult, error) {
@@ -177,8 +216,8 @@ git clone --depth 1 --branch %s %s /build/repo 2>&1 || git clone --depth 1 %s /b
 echo "=== Creating clean source directory ==="
 mkdir -p /build/src
 
-# Copy all files except vendor and .git to clean directory
-cd /build/repo
+# Copy all files of the build path except vendor and .git to clean directory
+cd /build/repo/%s
 for item in *; do
   if [ "$item" != "vendor" ]; then
     cp -r "$item" /build/src/
@@ -205,7 +244,7 @@ git add -A
 git commit -m "narvana: clean source for build"
 
 echo "Clean source directory created without vendor/"
-`, gitRef, job.GitURL, job.GitURL)
+`, gitRef, job.GitURL, job.GitURL, job.BuildPath)
 		// Update flakeRef to point to the cloned directory
 		flakeRef = "/build/src"
 		if job.FlakeOutput != "" {
@@ -263,6 +302,15 @@ fi
 		}
 	}
 
+	// Pull from and push to the app's Attic cache
+	cache := b.caches.CacheFor(job.AppID)
+
+	// Rebuild the output to check it is deterministic when requested
+	var verifyScript string
+	if shouldVerifyReproducibility(job) {
+		verifyScript = reproducibilityScript
+	}
+
 	// Build script that will run inside the container
 	// Note: We use single-user nix mode (build-users-group =) to work with rootless podman
 	// With vendorHash = null, we don't need two-phase hash calculation
@@ -287,6 +335,7 @@ echo ""
 # Try to change to /build/src (cloned repo) or stay in /build (generated/direct flake)
 cd /build/src 2>/dev/null || cd /build
 
+%s
 # Run the actual build
 echo "=== Running nix build ==="
 echo "Building from: $(pwd)"
@@ -317,41 +366,14 @@ fi
 echo ""
 echo "=== Build Output: $STORE_PATH ==="
 echo ""
-
-# Push to Attic binary cache
-echo "=== Pushing to Attic cache ==="
-echo "Attic URL: %s"
-echo "Cache: %s"
-
-# Install attic-client
-nix profile install nixpkgs#attic-client
-
-# Configure Attic client directly (write config file)
-mkdir -p /root/.config/attic
-cat > /root/.config/attic/config.toml << 'ATTIC_CONFIG'
-default-server = "narvana"
-
-[servers.narvana]
-endpoint = "%s"
-token = "%s"
-ATTIC_CONFIG
-
-# Create cache if it doesn't exist (ignore error if already exists)
-attic cache create %s 2>/dev/null || true
-
-# Push the closure with all dependencies
-attic push %s "$STORE_PATH"
-
-echo ""
-echo "=== Pushed to Attic successfully ==="
-echo ""
-
+%s
+%s
 # Print the store path as the final line for parsing
 echo "$STORE_PATH"
 
 echo ""
 echo "=== Build Complete ==="
-`, flakeRef, job.BuildType, cloneScript, b.atticURL, b.atticCache, b.atticURL, b.atticToken, b.atticCache, b.atticCache)
+`, flakeRef, job.BuildType, cloneScript, atticPullScript(b.atticURL, b.atticToken, cache, b.caches.Shared), verifyScript, atticPushScript(cache, b.caches.Shared))
 
 	containerName := fmt.Sprintf("narvana-build-%s", job.ID)
 
@@ -363,10 +385,14 @@ echo "=== Build Complete ==="
 		// then push to Attic binary cache for distribution to nodes.
 	}
 
-	// Add pre-cloned repo mount if available
+	// Add pre-cloned repo mount if available, mounting only the build path
 	if hasPreClonedRepo {
+		source, err := clone.Subdir(job.PreClonedRepoPath, job.BuildPath)
+		if err != nil {
+			return nil, err
+		}
 		mounts = append(mounts, podman.Mount{
-			Source:   job.PreClonedRepoPath,
+			Source:   source,
 			Target:   "/build/precloned",
 			ReadOnly: true, // Read-only since we copy to /build/src
 		})
@@ -377,19 +403,15 @@ echo "=== Build Complete ==="
 	}
 
 	cfg := &podman.ContainerConfig{
-		Name:       containerName,
-		Image:      b.nixImage,
-		Entrypoint: []string{"/root/.nix-profile/bin/bash", "-c"},
-		Command:    []string{buildScript},
-		WorkDir:    "/build",
-		User:       "root", // Run as root
-		Privileged: true,   // Privileged mode for nix builds
-		Mounts:     mounts,
-		Limits: &podman.ResourceLimits{
-			CPUQuota:  2.0,  // Allow 2 CPUs for builds
-			MemoryMB:  4096, // 4GB for builds
-			PidsLimit: 1000,
-		},
+		Name:        containerName,
+		Image:       b.nixImage,
+		Entrypoint:  []string{"/root/.nix-profile/bin/bash", "-c"},
+		Command:     []string{buildScript},
+		WorkDir:     "/build",
+		User:        "root", // Run as root
+		Privileged:  true,   // Privileged mode for nix builds
+		Mounts:      mounts,
+		Limits:      podman.BuildLimits(job.BuildConfig, b.limits),
 		Remove:      true,
 		NetworkMode: "host", // Allow network access for fetching dependencies and pushing to Attic
 		Env: map[string]string{
@@ -420,16 +442,27 @@ echo "=== Build Complete ==="
 	}
 
 	if containerResult.ExitCode != 0 {
+		b.keepSnapshot(job, cfg, buildDir, flakeRef)
 		return result, fmt.Errorf("build failed with exit code %d", containerResult.ExitCode)
 	}
 
 	// Parse the store path from the output
 	storePath := b.parseStorePath(stdout.String())
 	if storePath == "" {
+		b.keepSnapshot(job, cfg, buildDir, flakeRef)
 		return result, fmt.Errorf("could not parse store path from build output")
 	}
 
 	result.StorePath = storePath
+	job.CacheStats = parseCacheStats(stdout.String(), cache)
+	if verifyScript != "" {
+		job.Reproducibility, job.OutputHash = parseReproducibility(stdout.String())
+		b.logger.Info("reproducibility verification finished",
+			"job_id", job.ID,
+			"reproducibility", job.Reproducibility,
+			"output_hash", job.OutputHash,
+		)
+	}
 	b.logger.Info("nix build completed",
 		"job_id", job.ID,
 		"store_path", storePath,
@@ -439,6 +472,44 @@ echo "=== Build Complete ==="
 	return result, nil
 }
 
+// keepSnapshot records the environment of a failed build whose job asked for
+// a debug snapshot, which keeps its build directory from being removed.
+func (b *NixBuilder) keepSnapshot(job *models.BuildJob, cfg *podman.ContainerConfig, buildDir, flakeRef string) {
+	if job.BuildConfig == nil || !job.BuildConfig.DebugSnapshot {
+		return
+	}
+	path, err := filepath.Abs(buildDir)
+	if err != nil {
+		path = buildDir
+	}
+	// The build script runs nix build from the clean source directory when it
+	// prepared one, and from the build directory otherwise
+	workDir := cfg.WorkDir
+	if strings.HasPrefix(flakeRef, "/build/src") {
+		workDir = "/build/src"
+	}
+	env := make(map[string]string, len(cfg.Env))
+	for k, v := range cfg.Env {
+		env[k] = v
+	}
+	job.Snapshot = &models.BuildSnapshot{
+		BuildID:  job.ID,
+		Path:     path,
+		Image:    cfg.Image,
+		Shell:    cfg.Entrypoint[0],
+		WorkDir:  workDir,
+		FlakeRef: flakeRef,
+		Env:      env,
+	}
+}
+
+// cleanupBuildDir removes a build's directory unless it was kept as a snapshot.
+func (b *NixBuilder) cleanupBuildDir(job *models.BuildJob, buildDir string) {
+	if job.Snapshot == nil {
+		os.RemoveAll(buildDir)
+	}
+}
+
 // parseStorePath extracts the Nix store path from build output.
 // The store path is printed by --print-out-paths and looks like:
 // /nix/store/abc123-name
@@ -467,12 +538,16 @@ func (b *NixBuilder) BuildWithLogCallback(ctx context.Context, job *models.Build
 		"has_generated_flake", job.GeneratedFlake != "",
 	)
 
-	// Create a unique build directory
+	// Create a unique build directory, dropping a snapshot kept by an earlier
+	// attempt of the build
 	buildDir := filepath.Join(b.workDir, job.ID)
+	job.Snapshot = nil
+	job.CacheStats = nil
+	os.RemoveAll(buildDir)
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating build directory: %w", err)
 	}
-	defer os.RemoveAll(buildDir)
+	defer b.cleanupBuildDir(job, buildDir) // Clean up after build
 
 	var flakeRef string
 
@@ -495,13 +570,7 @@ func (b *NixBuilder) BuildWithLogCallback(ctx context.Context, job *models.Build
 		}
 	} else {
 		// Build from the git URL
-		flakeRef = job.GitURL
-		if job.GitRef != "" {
-			flakeRef = fmt.Sprintf("%s?ref=%s", job.GitURL, job.GitRef)
-		}
-		if job.FlakeOutput != "" {
-			flakeRef = fmt.Sprintf("%s#%s", flakeRef, job.FlakeOutput)
-		}
+		flakeRef = gitFlakeRef(job)
 	}
 
 	// Create a writer that calls the callback for each line
@@ -90,8 +90,9 @@ func (r *RecoveryService) RecoverOnStartup(ctx context.Context) (*RecoveryResult
 	return result, nil
 }
 
-// markInterruptedBuildsAsFailed marks all builds with status "running" as failed.
-// These builds were interrupted by a server restart.
+// markInterruptedBuildsAsFailed marks builds with status "running" as failed
+// unless their queue job is still claimed by a worker. These builds were
+// interrupted by a server restart.
 // **Validates: Requirements 15.2**
 func (r *RecoveryService) markInterruptedBuildsAsFailed(ctx context.Context) (int, error) {
 	// Get all running builds
@@ -104,10 +105,27 @@ func (r *RecoveryService) markInterruptedBuildsAsFailed(ctx context.Context) (in
 		return 0, nil
 	}
 
+	// Builds still claimed in a shared queue belong to another worker, or are
+	// requeued once that worker's lease expires
+	claimed := make(map[string]bool)
+	if lq, ok := r.queue.(queue.LeasedQueue); ok {
+		ids, err := lq.ListClaimed(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("listing claimed jobs: %w", err)
+		}
+		for _, id := range ids {
+			claimed[id] = true
+		}
+	}
+
 	count := 0
 	now := time.Now()
 
 	for _, build := range runningBuilds {
+		if claimed[build.ID] {
+			continue
+		}
+
 		r.logger.Info("marking interrupted build as failed",
 			"build_id", build.ID,
 			"deployment_id", build.DeploymentID,
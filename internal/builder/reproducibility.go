@@ -0,0 +1,94 @@
+package builder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Markers printed by reproducibilityScript and read back by parseReproducibility.
+const (
+	outputHashMarker      = "=== Output Hash: "
+	reproducibilityMarker = "=== Reproducibility: "
+	markerSuffix          = " ==="
+)
+
+// reproducibilityScript runs after the first build inside the build container.
+// It records the output's NAR hash, then rebuilds the derivation with --rebuild,
+// which makes nix fail if the new output differs from the one in the store.
+// A failed verification never fails the build.
+const reproducibilityScript = `
+# Verify the build is reproducible by rebuilding and comparing outputs
+echo "=== Verifying reproducibility ==="
+OUTPUT_HASH=$(nix path-info --json "$STORE_PATH" 2>/dev/null | grep -o '"narHash":"[^"]*"' | head -1 | cut -d'"' -f4 || true)
+echo "=== Output Hash: $OUTPUT_HASH ==="
+
+VERIFY_OUTPUT=$(mktemp)
+if nix build '.#default' --rebuild --no-link --impure --option sandbox false --option filter-syscalls false > "$VERIFY_OUTPUT" 2>&1; then
+  cat "$VERIFY_OUTPUT"
+  echo "=== Reproducibility: verified ==="
+elif grep -q "may not be deterministic" "$VERIFY_OUTPUT"; then
+  cat "$VERIFY_OUTPUT"
+  echo "WARNING: rebuilding produced a different output"
+  echo "=== Reproducibility: mismatch ==="
+else
+  cat "$VERIFY_OUTPUT"
+  echo "WARNING: could not rebuild to verify reproducibility"
+  echo "=== Reproducibility: error ==="
+fi
+rm -f "$VERIFY_OUTPUT"
+echo ""
+`
+
+// shouldVerifyReproducibility reports whether the job asked for double-build
+// verification. Only pure-nix builds produce a store path that can be rebuilt.
+func shouldVerifyReproducibility(job *models.BuildJob) bool {
+	return job.BuildType == models.BuildTypePureNix &&
+		job.BuildConfig != nil && job.BuildConfig.VerifyReproducibility
+}
+
+// parseReproducibility reads the verification result and output hash from the
+// build output. A missing or unrecognized result is reported as an error.
+func parseReproducibility(output string) (models.ReproducibilityStatus, string) {
+	status := models.ReproducibilityError
+	var outputHash string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasSuffix(line, markerSuffix) {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, outputHashMarker):
+			outputHash = strings.TrimSuffix(strings.TrimPrefix(line, outputHashMarker), markerSuffix)
+		case strings.HasPrefix(line, reproducibilityMarker):
+			switch s := models.ReproducibilityStatus(strings.TrimSuffix(strings.TrimPrefix(line, reproducibilityMarker), markerSuffix)); s {
+			case models.ReproducibilityVerified, models.ReproducibilityMismatch:
+				status = s
+			}
+		}
+	}
+	return status, outputHash
+}
+
+// reportReproducibility surfaces the verification result of a successful
+// build. A mismatch is logged as an error and written to the build logs so it
+// shows up alongside the build; it also counts toward the health summary's
+// active alerts.
+func (w *Worker) reportReproducibility(ctx context.Context, job *models.BuildJob) {
+	switch job.Reproducibility {
+	case models.ReproducibilityVerified:
+		w.streamLog(ctx, job.DeploymentID, "Reproducibility verified: rebuilding produced identical output")
+	case models.ReproducibilityMismatch:
+		w.logger.Error("build is not reproducible",
+			"job_id", job.ID,
+			"app_id", job.AppID,
+			"service_name", job.ServiceName,
+			"output_hash", job.OutputHash,
+		)
+		w.streamLog(ctx, job.DeploymentID, "WARNING: build is not reproducible, rebuilding produced a different output")
+	case models.ReproducibilityError:
+		w.logger.Warn("could not verify build reproducibility", "job_id", job.ID)
+		w.streamLog(ctx, job.DeploymentID, "WARNING: could not verify build reproducibility")
+	}
+}
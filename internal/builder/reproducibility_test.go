@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestShouldVerifyReproducibility(t *testing.T) {
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyFlake)
+	if shouldVerifyReproducibility(job) {
+		t.Error("verification should be opt-in")
+	}
+
+	job.BuildConfig = &models.BuildConfig{VerifyReproducibility: true}
+	if !shouldVerifyReproducibility(job) {
+		t.Error("pure-nix builds that opt in should be verified")
+	}
+
+	job.BuildType = models.BuildTypeOCI
+	if shouldVerifyReproducibility(job) {
+		t.Error("OCI builds should not be verified")
+	}
+}
+
+func TestParseReproducibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		status  models.ReproducibilityStatus
+		narHash string
+	}{
+		{
+			name:    "verified",
+			output:  "building...\n=== Output Hash: sha256-abc= ===\n=== Reproducibility: verified ===\n/nix/store/abc-app\n",
+			status:  models.ReproducibilityVerified,
+			narHash: "sha256-abc=",
+		},
+		{
+			name:    "mismatch",
+			output:  "=== Output Hash: sha256-abc= ===\nerror: derivation may not be deterministic\n=== Reproducibility: mismatch ===\n",
+			status:  models.ReproducibilityMismatch,
+			narHash: "sha256-abc=",
+		},
+		{
+			name:    "rebuild failed",
+			output:  "=== Output Hash:  ===\n=== Reproducibility: error ===\n",
+			status:  models.ReproducibilityError,
+			narHash: "",
+		},
+		{
+			name:   "no markers",
+			output: "/nix/store/abc-app\n",
+			status: models.ReproducibilityError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, narHash := parseReproducibility(tt.output)
+			if status != tt.status {
+				t.Errorf("status = %q, want %q", status, tt.status)
+			}
+			if narHash != tt.narHash {
+				t.Errorf("output hash = %q, want %q", narHash, tt.narHash)
+			}
+		})
+	}
+}
+
+func TestReportReproducibility_Mismatch(t *testing.T) {
+	st := NewMockStore()
+	w := &Worker{store: st, logger: slog.Default()}
+
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyFlake)
+	job.Reproducibility = models.ReproducibilityMismatch
+	w.reportReproducibility(context.Background(), job)
+
+	logs, _ := st.Logs().List(context.Background(), "d1", 0)
+	if len(logs) != 1 || !strings.Contains(logs[0].Message, "not reproducible") {
+		t.Errorf("expected a warning in the build logs, got %+v", logs)
+	}
+}
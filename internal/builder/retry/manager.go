@@ -134,13 +134,7 @@ func (m *Manager) ShouldRetry(ctx context.Context, job *models.BuildJob, err err
 	}
 
 	// **Validates: Requirements 15.5** - Check if we've exceeded max attempts
-	attempts := m.GetAttempts(job.ID)
-	if len(attempts) >= m.strategy.MaxAttempts {
-		return false
-	}
-
-	// Also check the job's retry count against MaxRetries constant
-	if job.RetryCount >= MaxRetries {
+	if !m.hasRetriesLeft(job) {
 		return false
 	}
 
@@ -162,6 +156,26 @@ func (m *Manager) ShouldRetry(ctx context.Context, job *models.BuildJob, err err
 	return false
 }
 
+// ShouldRetryFailure determines if a failed build should be retried like
+// ShouldRetry, and also retries failures diagnosed as transient from the
+// build's logs while the build has retries left. Builds stopped by their
+// timeout are never retried, as they would run into the same limit.
+func (m *Manager) ShouldRetryFailure(ctx context.Context, job *models.BuildJob, err error, failure *models.BuildFailure) bool {
+	if failure != nil && failure.Category == models.BuildFailureTimeout {
+		return false
+	}
+	if err != nil && failure != nil && failure.Retryable && !IsValidationError(err) && m.hasRetriesLeft(job) {
+		return true
+	}
+	return m.ShouldRetry(ctx, job, err)
+}
+
+// hasRetriesLeft reports whether the job has attempts left under both the
+// strategy and MaxRetries.
+func (m *Manager) hasRetriesLeft(job *models.BuildJob) bool {
+	return len(m.GetAttempts(job.ID)) < m.strategy.MaxAttempts && job.RetryCount < MaxRetries
+}
+
 // shouldFallbackToOCI checks if the error indicates we should try OCI instead.
 func (m *Manager) shouldFallbackToOCI(errStr string) bool {
 	for _, pattern := range ociFallbackErrorPatterns {
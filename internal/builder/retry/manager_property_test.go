@@ -1264,3 +1264,33 @@ func TestOCIFallbackOnRetry(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestShouldRetryFailure(t *testing.T) {
+	buildErr := errors.New("nix build failed: exit status 1")
+	transient := &models.BuildFailure{Category: models.BuildFailureNetwork, Retryable: true}
+	compile := &models.BuildFailure{Category: models.BuildFailureCompile}
+
+	tests := []struct {
+		name       string
+		err        error
+		failure    *models.BuildFailure
+		retryCount int
+		want       bool
+	}{
+		{"transient failure", buildErr, transient, 0, true},
+		{"transient failure without retries left", buildErr, transient, MaxRetries, false},
+		{"validation error", fmt.Errorf("%w: missing go.mod", ErrValidationFailed), transient, 0, false},
+		{"failure caused by the inputs", buildErr, compile, 0, false},
+		{"retryable error without a diagnosis", errors.New("connection refused"), nil, 0, true},
+		{"build timeout", errors.New("build exceeded timeout limit: build exceeded 30m0s timeout"),
+			&models.BuildFailure{Category: models.BuildFailureTimeout}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &models.BuildJob{ID: "build-1", BuildType: models.BuildTypeOCI, RetryCount: tt.retryCount}
+			if got := NewManager().ShouldRetryFailure(context.Background(), job, tt.err, tt.failure); got != tt.want {
+				t.Errorf("ShouldRetryFailure = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DefaultSnapshotTTL is how long the environment of a failed build is kept
+// for debugging when the worker is not configured otherwise.
+const DefaultSnapshotTTL = 24 * time.Hour
+
+// snapshotPruneInterval is how often a worker removes the expired snapshots
+// kept on its host.
+const snapshotPruneInterval = 10 * time.Minute
+
+// saveSnapshot records the snapshot the builder kept for a failed build so it
+// can be debugged until it expires. Without a record the kept directory would
+// never be pruned, so it is removed when the record cannot be saved.
+func (w *Worker) saveSnapshot(ctx context.Context, job *models.BuildJob, logCallback func(string)) {
+	snapshot := job.Snapshot
+	if snapshot == nil {
+		return
+	}
+
+	ttl := w.snapshotTTL
+	if ttl <= 0 {
+		ttl = DefaultSnapshotTTL
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	snapshot.Hostname = hostname
+	snapshot.CreatedAt = now
+	snapshot.ExpiresAt = now.Add(ttl)
+	if w.coordinator != nil {
+		snapshot.WorkerID = w.coordinator.WorkerID()
+	}
+
+	if err := w.store.BuildSnapshots().Save(ctx, snapshot); err != nil {
+		w.logger.Error("failed to save build snapshot", "job_id", job.ID, "error", err)
+		w.discardSnapshot(job)
+		return
+	}
+
+	w.logger.Info("kept failed build environment for debugging",
+		"job_id", job.ID,
+		"path", snapshot.Path,
+		"expires_at", snapshot.ExpiresAt,
+	)
+	logCallback(fmt.Sprintf("=== Build environment kept for debugging on %s until %s ===",
+		hostname, snapshot.ExpiresAt.UTC().Format(time.RFC3339)))
+}
+
+// discardSnapshot removes the directory the builder kept for a failed build,
+// e.g. because the build is retried.
+func (w *Worker) discardSnapshot(job *models.BuildJob) {
+	if job.Snapshot == nil {
+		return
+	}
+	if err := os.RemoveAll(job.Snapshot.Path); err != nil {
+		w.logger.Warn("failed to remove build snapshot", "job_id", job.ID, "path", job.Snapshot.Path, "error", err)
+	}
+	job.Snapshot = nil
+}
+
+// snapshotLoop periodically prunes the expired snapshots kept on this host.
+func (w *Worker) snapshotLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	w.pruneSnapshots(ctx)
+
+	ticker := time.NewTicker(snapshotPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.pruneSnapshots(ctx)
+		}
+	}
+}
+
+// pruneSnapshots removes the directories and records of the expired
+// snapshots kept on this host. Snapshots are matched by hostname rather than
+// worker ID, so those of an earlier worker process on the host are pruned too.
+func (w *Worker) pruneSnapshots(ctx context.Context) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		w.logger.Error("failed to get hostname for snapshot pruning", "error", err)
+		return
+	}
+
+	snapshots, err := w.store.BuildSnapshots().ListExpired(ctx, hostname, time.Now())
+	if err != nil {
+		w.logger.Error("failed to list expired build snapshots", "error", err)
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		if err := os.RemoveAll(snapshot.Path); err != nil {
+			w.logger.Warn("failed to remove build snapshot", "build_id", snapshot.BuildID, "path", snapshot.Path, "error", err)
+			continue
+		}
+		if err := w.store.BuildSnapshots().Delete(ctx, snapshot.BuildID); err != nil {
+			w.logger.Error("failed to delete build snapshot", "build_id", snapshot.BuildID, "error", err)
+			continue
+		}
+		w.logger.Info("pruned expired build snapshot", "build_id", snapshot.BuildID)
+	}
+}
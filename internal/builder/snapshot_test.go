@@ -0,0 +1,107 @@
+package builder
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
+)
+
+func TestKeepSnapshot(t *testing.T) {
+	b := &NixBuilder{logger: slog.Default()}
+	cfg := &podman.ContainerConfig{
+		Image:      "docker.io/nixos/nix:latest",
+		Entrypoint: []string{"/root/.nix-profile/bin/bash", "-c"},
+		WorkDir:    "/build",
+		Env:        map[string]string{"HOME": "/root"},
+	}
+
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyFlake)
+	dir := t.TempDir()
+	b.keepSnapshot(job, cfg, dir, "/build/src#default")
+	if job.Snapshot != nil {
+		t.Fatal("snapshot kept for a build that did not ask for one")
+	}
+	b.cleanupBuildDir(job, dir)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("build directory should be removed, stat error = %v", err)
+	}
+
+	job.BuildConfig = &models.BuildConfig{DebugSnapshot: true}
+	dir = t.TempDir()
+	b.keepSnapshot(job, cfg, dir, "/build/src#default")
+	if job.Snapshot == nil {
+		t.Fatal("snapshot not kept for a build that asked for one")
+	}
+	if job.Snapshot.WorkDir != "/build/src" || job.Snapshot.Shell != "/root/.nix-profile/bin/bash" || job.Snapshot.Env["HOME"] != "/root" {
+		t.Errorf("snapshot = %+v", job.Snapshot)
+	}
+	b.cleanupBuildDir(job, dir)
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("kept build directory should remain: %v", err)
+	}
+}
+
+func TestSaveAndPruneSnapshots(t *testing.T) {
+	st := NewMockStore()
+	w := &Worker{store: st, logger: slog.Default(), snapshotTTL: time.Hour}
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "b1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyFlake)
+	job.Snapshot = &models.BuildSnapshot{BuildID: "b1", Path: dir}
+
+	var lines []string
+	w.saveSnapshot(ctx, job, func(line string) { lines = append(lines, line) })
+
+	saved, _ := st.BuildSnapshots().Get(ctx, "b1")
+	hostname, _ := os.Hostname()
+	if saved == nil || saved.Hostname != hostname {
+		t.Fatalf("saved snapshot = %+v, want one on host %q", saved, hostname)
+	}
+	if until := time.Until(saved.ExpiresAt); until <= 0 || until > time.Hour {
+		t.Errorf("snapshot expires in %s, want within the TTL", until)
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], "kept for debugging") {
+		t.Errorf("build log lines = %q", lines)
+	}
+
+	// Unexpired snapshots are left alone
+	w.pruneSnapshots(ctx)
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("unexpired snapshot removed: %v", err)
+	}
+
+	saved.ExpiresAt = time.Now().Add(-time.Minute)
+	w.pruneSnapshots(ctx)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expired snapshot directory should be removed, stat error = %v", err)
+	}
+	if s, _ := st.BuildSnapshots().Get(ctx, "b1"); s != nil {
+		t.Error("expired snapshot record should be deleted")
+	}
+}
+
+func TestDiscardSnapshot(t *testing.T) {
+	w := &Worker{store: NewMockStore(), logger: slog.Default()}
+	dir := t.TempDir()
+	job := NewTestBuildJob("b1", "d1", models.BuildTypePureNix, models.BuildStrategyFlake)
+	job.Snapshot = &models.BuildSnapshot{BuildID: "b1", Path: dir}
+
+	w.discardSnapshot(job)
+	if job.Snapshot != nil {
+		t.Error("job should no longer reference the snapshot")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("discarded snapshot directory should be removed, stat error = %v", err)
+	}
+}
@@ -2,12 +2,19 @@
 package databases
 
 import (
+	"bytes"
 	cryptoRand "crypto/rand"
+	"embed"
 	"fmt"
+	"strconv"
+	"text/template"
 
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
+//go:embed *.nix.tmpl
+var templateFS embed.FS
+
 // DatabaseType represents a supported database type.
 type DatabaseType string
 
@@ -253,6 +260,78 @@ func (d *DatabaseTemplateData) WithConfig(config map[string]string) *DatabaseTem
 	return d
 }
 
+// Render renders the flake of a database service from its type's template.
+func Render(data *DatabaseTemplateData) (string, error) {
+	name := GetTemplateName(data.DatabaseType)
+	if name == "" {
+		return "", fmt.Errorf("unknown database type: %s", data.DatabaseType)
+	}
+	content, err := templateFS.ReadFile(name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("reading template %s: %w", name, err)
+	}
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// HealthCheck returns the health check of a database type: the healthcheck.sh
+// script its flake installs, which asks the engine itself whether it accepts
+// connections (pg_isready, mysqladmin ping, a MongoDB ping command or redis-cli
+// ping) rather than only probing the port. Retries allow for the first start,
+// which initializes the data directory.
+func HealthCheck(dbType DatabaseType) *models.HealthCheckConfig {
+	engine, err := GetTemplate(dbType)
+	if err != nil {
+		return nil
+	}
+	return &models.HealthCheckConfig{
+		Port:            engine.DefaultPort,
+		Command:         []string{"healthcheck.sh"},
+		IntervalSeconds: 10,
+		TimeoutSeconds:  5,
+		Retries:         12,
+	}
+}
+
+// rootPasswordVars names the variable each engine's flake reads its root
+// password from.
+var rootPasswordVars = map[DatabaseType]string{
+	DatabaseTypeMySQL:   "MYSQL_ROOT_PASSWORD",
+	DatabaseTypeMariaDB: "MARIADB_ROOT_PASSWORD",
+	DatabaseTypeMongoDB: "MONGO_ROOT_PASSWORD",
+}
+
+// EngineEnv returns the variables a database service's engine is configured
+// from, taken from the service's generated credentials in env (see
+// GetSecretKeys): DB_NAME, DB_USER and DB_PASSWORD, the root password under
+// the engine's own name, and REDIS_PASSWORD for Redis.
+func EngineEnv(dbType DatabaseType, serviceName string, env map[string]string) map[string]string {
+	prefix := sanitizeEnvVarName(serviceName) + "_"
+	vars := make(map[string]string)
+	copyVar := func(to, from string) {
+		if value, ok := env[prefix+from]; ok {
+			vars[to] = value
+		}
+	}
+	copyVar("DB_NAME", "DB_NAME")
+	copyVar("DB_USER", "DB_USER")
+	copyVar("DB_PASSWORD", "DB_PASSWORD")
+	if name, ok := rootPasswordVars[dbType]; ok {
+		copyVar(name, "DB_ROOT_PASSWORD")
+	}
+	if dbType == DatabaseTypeRedis {
+		copyVar("REDIS_PASSWORD", "DB_PASSWORD")
+	}
+	return vars
+}
+
 // DatabaseCredentials contains generated credentials for a database service.
 type DatabaseCredentials struct {
 	Username     string `json:"username"`
@@ -342,6 +421,54 @@ func (c *DatabaseCredentials) GetSecretKeys(dbType DatabaseType, serviceName str
 	return secrets
 }
 
+// CredentialsFromSecrets rebuilds a database service's credentials from its
+// secrets as stored by GetSecretKeys, so that credentials changed since they
+// were generated are picked up. It reports false when the user or password
+// secret is missing. A missing or invalid port secret falls back to the
+// engine's default port.
+func CredentialsFromSecrets(dbType DatabaseType, serviceName string, secrets map[string]string) (*DatabaseCredentials, bool) {
+	prefix := sanitizeEnvVarName(serviceName) + "_"
+	creds := &DatabaseCredentials{
+		Username:     secrets[prefix+"DB_USER"],
+		Password:     secrets[prefix+"DB_PASSWORD"],
+		DatabaseName: secrets[prefix+"DB_NAME"],
+		Host:         secrets[prefix+"DB_HOST"],
+		RootPassword: secrets[prefix+"DB_ROOT_PASSWORD"],
+	}
+	if creds.Password == "" || (creds.Username == "" && dbType != DatabaseTypeRedis) {
+		return nil, false
+	}
+	if creds.Host == "" {
+		creds.Host = "localhost"
+	}
+	if creds.DatabaseName == "" {
+		creds.DatabaseName = sanitizeDatabaseName(serviceName)
+	}
+	port, err := strconv.Atoi(secrets[prefix+"DB_PORT"])
+	if err != nil || port < 1 || port > 65535 {
+		port = GetDefaultPort(dbType)
+	}
+	creds.Port = port
+	return creds, true
+}
+
+// BindingVariable returns the variable a database's connection URL is
+// injected as into the services that depend on it: DATABASE_URL for SQL
+// databases, MONGODB_URL for MongoDB and REDIS_URL for Redis. It is empty for
+// types that have no connection URL.
+func BindingVariable(dbType DatabaseType) string {
+	switch dbType {
+	case DatabaseTypePostgres, DatabaseTypeMySQL, DatabaseTypeMariaDB:
+		return "DATABASE_URL"
+	case DatabaseTypeMongoDB:
+		return "MONGODB_URL"
+	case DatabaseTypeRedis:
+		return "REDIS_URL"
+	default:
+		return ""
+	}
+}
+
 // generateSecurePassword generates a cryptographically secure random password.
 func generateSecurePassword(length int) (string, error) {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -0,0 +1,67 @@
+package databases
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEveryType(t *testing.T) {
+	for _, dbType := range ValidDatabaseTypes() {
+		data := NewDatabaseTemplateData("shop", "cache", dbType, "")
+		out, err := Render(data)
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", dbType, err)
+		}
+		for _, want := range []string{"shop/cache", `dbVersion = "` + GetDefaultVersion(dbType) + `"`, "healthcheck.sh", "HEALTHEOF"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s flake does not contain %q", dbType, want)
+			}
+		}
+		if strings.Contains(out, "<no value>") {
+			t.Errorf("%s flake has unset template values", dbType)
+		}
+	}
+}
+
+func TestRenderAppliesOptions(t *testing.T) {
+	data := NewDatabaseTemplateData("shop", "db", DatabaseTypeMySQL, "8.4").
+		WithConfig(map[string]string{"max_connections": "500"})
+	out, err := Render(data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, `maxConnections = "500"`) || !strings.Contains(out, `innodbBufferPoolSize = "128M"`) {
+		t.Errorf("flake does not use the options and defaults:\n%s", out)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	hc := HealthCheck(DatabaseTypeMongoDB)
+	if hc == nil || hc.Port != 27017 || len(hc.Command) != 1 || hc.Command[0] != "healthcheck.sh" {
+		t.Errorf("HealthCheck(mongodb) = %+v", hc)
+	}
+	if HealthCheck("oracle") != nil {
+		t.Error("HealthCheck of an unknown type is not nil")
+	}
+}
+
+func TestEngineEnv(t *testing.T) {
+	creds, err := GenerateCredentials(DatabaseTypeMySQL, "orders-db")
+	if err != nil {
+		t.Fatalf("GenerateCredentials() error = %v", err)
+	}
+	env := creds.GetSecretKeys(DatabaseTypeMySQL, "orders-db")
+
+	vars := EngineEnv(DatabaseTypeMySQL, "orders-db", env)
+	if vars["DB_PASSWORD"] != creds.Password || vars["DB_USER"] != creds.Username || vars["MYSQL_ROOT_PASSWORD"] != creds.RootPassword {
+		t.Errorf("EngineEnv(mysql) = %v", vars)
+	}
+
+	vars = EngineEnv(DatabaseTypeRedis, "orders-db", env)
+	if vars["REDIS_PASSWORD"] != creds.Password {
+		t.Errorf("EngineEnv(redis) = %v", vars)
+	}
+	if len(EngineEnv(DatabaseTypeRedis, "sessions", env)) != 0 {
+		t.Error("EngineEnv used the credentials of another service")
+	}
+}
@@ -5,24 +5,37 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/narvanalabs/control-plane/internal/builder/detector"
+	"github.com/narvanalabs/control-plane/internal/builder/diagnose"
 	"github.com/narvanalabs/control-plane/internal/builder/executor"
 	"github.com/narvanalabs/control-plane/internal/builder/hash"
 	"github.com/narvanalabs/control-plane/internal/builder/retry"
 	"github.com/narvanalabs/control-plane/internal/builder/templates"
+	"github.com/narvanalabs/control-plane/internal/events"
 	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
 	"github.com/narvanalabs/control-plane/internal/queue"
 	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/internal/telemetry"
+	"github.com/narvanalabs/control-plane/internal/tracing"
+	"github.com/narvanalabs/control-plane/internal/validation"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Build timeout errors.
 var (
 	// ErrBuildTimeout is returned when a build exceeds its configured timeout.
 	ErrBuildTimeout = errors.New("build exceeded timeout limit")
+	// ErrBuildCanceled is returned when a build is stopped because someone
+	// canceled it.
+	ErrBuildCanceled = errors.New("build canceled")
 	// ErrValidationFailed is returned when build validation fails.
 	ErrValidationFailed = errors.New("build validation failed")
 	// ErrInvalidStateTransition is returned when an invalid state transition is attempted.
@@ -32,6 +45,14 @@ var (
 // DefaultBuildTimeout is the default build timeout in seconds (30 minutes).
 const DefaultBuildTimeout = 1800
 
+// cancelPollInterval is how often a running build checks whether it was
+// canceled, and cancelGracePeriod how long a canceled or timed-out build's
+// executor is given to stop its processes and remove what it built so far.
+const (
+	cancelPollInterval = 2 * time.Second
+	cancelGracePeriod  = 30 * time.Second
+)
+
 // Validation error codes.
 // These codes are used to categorize validation errors for programmatic handling.
 // **Validates: Requirements 3.4, 3.5, 3.6, 3.7, 3.8, 3.9**
@@ -220,6 +241,15 @@ func (v *DefaultBuildValidator) validateBuildConfig(config *models.BuildConfig,
 		}
 	}
 
+	// Validate the Dockerfile stays within the repository
+	if config.DockerfilePath != "" && !filepath.IsLocal(config.DockerfilePath) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "build_config.dockerfile_path",
+			Message: "dockerfile path must be relative to the repository root",
+			Code:    ValidationCodeInvalidValue,
+		})
+	}
+
 	// Validate build timeout - negative values are not allowed
 	if config.BuildTimeout < 0 {
 		result.Errors = append(result.Errors, ValidationError{
@@ -228,6 +258,22 @@ func (v *DefaultBuildValidator) validateBuildConfig(config *models.BuildConfig,
 			Code:    ValidationCodeNegativeValue,
 		})
 	}
+
+	// Validate the build container limits, which are passed to Podman
+	if validation.ValidateCPU(config.BuildCPU) != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "build_config.build_cpu",
+			Message: "build CPU must be a decimal number of cores",
+			Code:    ValidationCodeInvalidValue,
+		})
+	}
+	if validation.ValidateMemory(config.BuildMemory) != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "build_config.build_memory",
+			Message: "build memory must be a size with unit suffix",
+			Code:    ValidationCodeInvalidValue,
+		})
+	}
 }
 
 // isValidVersionFormat checks if a version string has a valid format.
@@ -486,12 +532,35 @@ type Worker struct {
 	progressTracker  BuildProgressTracker
 	validator        BuildValidator
 	scheduler        SchedulerInterface
+	events           events.Publisher
+	coordinator      *Coordinator
 	logger           *slog.Logger
 
+	// resolveTreeHash identifies a build's source for deduplication; nil
+	// disables deduplication.
+	resolveTreeHash func(ctx context.Context, gitURL, gitRef string) (string, error)
+
 	concurrency    int
 	defaultTimeout int // Default build timeout in seconds
 	stopCh         chan struct{}
 	wg             sync.WaitGroup
+
+	// snapshotTTL is how long the environments of failed builds that asked
+	// for a debug snapshot are kept.
+	snapshotTTL time.Duration
+
+	// signer signs the provenance of successful builds; nil disables attestations.
+	signer *provenance.Signer
+
+	// buildDurations records how long finished builds took; nil records none.
+	buildDurations *telemetry.HistogramVec
+
+	// activeJobs counts the jobs being processed, reported in heartbeats.
+	activeJobs atomic.Int32
+	// heartbeatStop ends the coordinator loop once in-flight jobs finish, so
+	// their leases are renewed until they are acknowledged.
+	heartbeatStop chan struct{}
+	heartbeatDone chan struct{}
 }
 
 // WorkerConfig holds configuration for the build worker.
@@ -501,6 +570,14 @@ type WorkerConfig struct {
 	OCIConfig      *OCIBuilderConfig
 	AtticConfig    *AtticConfig
 	DefaultTimeout int // Default build timeout in seconds (default: 1800 = 30 minutes)
+
+	// DisableDeduplication always rebuilds, even when an earlier build had
+	// identical inputs.
+	DisableDeduplication bool
+
+	// SnapshotTTL is how long the environment of a failed build is kept when
+	// the build asked for a debug snapshot (default: DefaultSnapshotTTL).
+	SnapshotTTL time.Duration
 }
 
 // DefaultWorkerConfig returns a WorkerConfig with sensible defaults.
@@ -511,6 +588,7 @@ func DefaultWorkerConfig() *WorkerConfig {
 		OCIConfig:      DefaultOCIBuilderConfig(),
 		AtticConfig:    DefaultAtticConfig(),
 		DefaultTimeout: 1800, // 30 minutes
+		SnapshotTTL:    DefaultSnapshotTTL,
 	}
 }
 
@@ -543,6 +621,19 @@ func NewWorker(cfg *WorkerConfig, s store.Store, q queue.Queue, logger *slog.Log
 	flakeExecutor := executor.NewFlakeStrategyExecutor(nixBuilderAdapter, ociBuilderAdapter, logger)
 	registry.Register(flakeExecutor)
 
+	// Register dockerfile and nixpacks strategy executors, building on the
+	// same Podman service and registry as OCI builds
+	imageConfig := &executor.DockerfileExecutorConfig{
+		PodmanSocket: cfg.OCIConfig.PodmanSocket,
+		Registry:     cfg.OCIConfig.Registry,
+		BuildLimits:  cfg.NixConfig.BuildLimits,
+	}
+	registry.Register(executor.NewDockerfileStrategyExecutor(imageConfig, logger))
+	registry.Register(executor.NewNixpacksStrategyExecutor(imageConfig, logger))
+	if !executor.IsNixpacksAvailable() {
+		logger.Warn("nixpacks not found, builds with the nixpacks strategy will fail on this worker")
+	}
+
 	// Create shared dependencies for auto-* executors
 	det := detector.NewDetector()
 	tmplEngine, tmplErr := templates.NewTemplateEngine()
@@ -605,6 +696,11 @@ func NewWorker(cfg *WorkerConfig, s store.Store, q queue.Queue, logger *slog.Log
 		"required_strategies", executor.RequiredStrategies,
 	)
 
+	var resolveTreeHash func(ctx context.Context, gitURL, gitRef string) (string, error)
+	if !cfg.DisableDeduplication {
+		resolveTreeHash = ResolveTreeHash
+	}
+
 	return &Worker{
 		store:            s,
 		queue:            q,
@@ -618,7 +714,11 @@ func NewWorker(cfg *WorkerConfig, s store.Store, q queue.Queue, logger *slog.Log
 		logger:           logger,
 		concurrency:      cfg.Concurrency,
 		defaultTimeout:   cfg.DefaultTimeout,
+		snapshotTTL:      cfg.SnapshotTTL,
+		resolveTreeHash:  resolveTreeHash,
 		stopCh:           make(chan struct{}),
+		heartbeatStop:    make(chan struct{}),
+		heartbeatDone:    make(chan struct{}),
 	}, nil
 }
 
@@ -672,9 +772,38 @@ func (w *Worker) SetScheduler(s SchedulerInterface) {
 	w.scheduler = s
 }
 
+// SetEvents sets where finished builds are published.
+func (w *Worker) SetEvents(p events.Publisher) {
+	w.events = p
+}
+
+// SetMetrics registers the durations of finished builds, by strategy and
+// status, in reg.
+func (w *Worker) SetMetrics(reg *telemetry.Registry) {
+	w.buildDurations = reg.Histogram("narvana_build_duration_seconds",
+		"Time taken by finished builds, by build strategy and status.",
+		[]float64{30, 60, 120, 300, 600, 900, 1200, 1800, 3600}, "strategy", "status")
+}
+
+// SetCoordinator sets the coordinator that registers the worker and keeps
+// the leases on its jobs alive, for queues shared by several workers.
+func (w *Worker) SetCoordinator(c *Coordinator) {
+	w.coordinator = c
+}
+
 func (w *Worker) Start(ctx context.Context) error {
 	w.logger.Info("starting build worker", "concurrency", w.concurrency)
 
+	if w.coordinator != nil {
+		if err := w.coordinator.Register(ctx); err != nil {
+			return err
+		}
+		go func() {
+			defer close(w.heartbeatDone)
+			w.coordinator.Run(ctx, w.heartbeatStop, func() int { return int(w.activeJobs.Load()) })
+		}()
+	}
+
 	for i := 0; i < w.concurrency; i++ {
 		w.wg.Add(1)
 		go w.workerLoop(ctx, i)
@@ -686,6 +815,10 @@ func (w *Worker) Start(ctx context.Context) error {
 		go w.schedulerLoop(ctx)
 	}
 
+	// Remove the environments of failed builds once they expire
+	w.wg.Add(1)
+	go w.snapshotLoop(ctx)
+
 	return nil
 }
 
@@ -748,6 +881,14 @@ func (w *Worker) Stop() {
 	w.logger.Info("stopping build worker")
 	close(w.stopCh)
 	w.wg.Wait()
+
+	if w.coordinator != nil {
+		close(w.heartbeatStop)
+		<-w.heartbeatDone
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		w.coordinator.Deregister(ctx)
+		cancel()
+	}
 	w.logger.Info("build worker stopped")
 }
 
@@ -780,8 +921,11 @@ func (w *Worker) workerLoop(ctx context.Context, workerID int) {
 				continue
 			}
 
-			// Process the job
-			if err := w.processJob(ctx, job); err != nil {
+			// Process the job, continuing the trace of the request that queued it
+			w.activeJobs.Add(1)
+			err = w.processJob(tracing.Extract(ctx, job.TraceContext), job)
+			w.activeJobs.Add(-1)
+			if err != nil {
 				logger.Error("failed to process job",
 					"job_id", job.ID,
 					"error", err,
@@ -802,7 +946,14 @@ func (w *Worker) workerLoop(ctx context.Context, workerID int) {
 }
 
 // processJob executes a single build job.
-func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
+func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) (err error) {
+	ctx, span := tracing.Start(ctx, "build",
+		attribute.String("build.id", job.ID),
+		attribute.String("deployment.id", job.DeploymentID),
+		attribute.String("app.id", job.AppID),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	w.logger.Info("processing build job",
 		"job_id", job.ID,
 		"deployment_id", job.DeploymentID,
@@ -826,6 +977,26 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 	// Use the existing job from the database (it has the correct state)
 	job = existingJob
 
+	// Builds canceled before they started are acknowledged without running
+	if models.IsTerminalState(job.Status) {
+		w.logger.Info("skipping finished build", "job_id", job.ID, "status", job.Status)
+		return nil
+	}
+	if job.CancelRequestedAt != nil {
+		w.cancelBeforeStart(ctx, job)
+		return nil
+	}
+
+	// A running build was requeued after its worker stopped heartbeating;
+	// start it over
+	if job.Status == models.BuildStatusRunning {
+		w.logger.Warn("resuming build abandoned by another worker", "job_id", job.ID)
+		if err := transitionJobStatus(job, models.BuildStatusQueued, true); err != nil {
+			return fmt.Errorf("requeuing abandoned build: %w", err)
+		}
+		w.streamLog(ctx, job.DeploymentID, "=== Restarting build: previous worker stopped responding ===")
+	}
+
 	// Validate the build job configuration before starting
 	validationResult, err := w.validator.Validate(ctx, job)
 	if err != nil {
@@ -858,7 +1029,16 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 			)
 		}
 		job.FinishedAt = &now
+		job.Failure = &models.BuildFailure{
+			Category:    models.BuildFailureInvalidConfig,
+			Summary:     "The build configuration is invalid.",
+			Suggestions: []string{"Correct the build settings quoted above and deploy again."},
+		}
+		for _, e := range validationResult.Errors {
+			job.Failure.Excerpt = append(job.Failure.Excerpt, e.Field+": "+e.Message)
+		}
 		w.store.Builds().Update(ctx, job)
+		w.notifyBuildFinished(ctx, job)
 		return fmt.Errorf("%w: %v", ErrValidationFailed, validationResult.Errors)
 	}
 
@@ -929,19 +1109,55 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 	var buildLogs string
 	var buildErr error
 
-	// Create a log callback to stream logs to the database
+	// Persist the build's output in chunks as it runs so clients can tail it
+	buildLog := newBuildLogWriter(ctx, w.store.BuildLogs(), job.ID, w.logger)
+	defer buildLog.Close()
+
+	// Create a log callback to stream logs to the database, keeping the last
+	// lines to explain a failure with
+	tail := &diagnose.Tail{}
 	logCallback := func(line string) {
 		w.streamLog(ctx, job.DeploymentID, line)
+		buildLog.WriteLine(line)
+		tail.Add(line)
 	}
 
-	// Route to appropriate strategy executor or fall back to legacy build
-	artifact, buildLogs, buildErr = w.executeWithStrategy(ctx, job, logCallback)
+	// Reuse the artifact of an earlier build with identical inputs, otherwise
+	// route to appropriate strategy executor or fall back to legacy build
+	if source, ok := w.findDuplicateBuild(ctx, job); ok {
+		job.DeduplicatedFrom = source.ID
+		artifact = source.Artifact
+		logCallback(fmt.Sprintf("=== Build inputs match build %s, reusing its artifact ===", source.ID))
+		logCallback(fmt.Sprintf("Artifact: %s", artifact))
+	} else {
+		// Stop the build when someone cancels it
+		buildCtx, cancelBuild := context.WithCancelCause(ctx)
+		go w.watchCancel(buildCtx, job.ID, cancelBuild)
+		artifact, buildLogs, buildErr = w.executeWithStrategy(buildCtx, job, logCallback)
+		cancelBuild(nil)
+	}
 
 	// Update job and deployment status based on result
 	finishedAt := time.Now()
 	job.FinishedAt = &finishedAt
 
-	if buildErr != nil {
+	if errors.Is(buildErr, ErrBuildCanceled) {
+		w.logger.Info("build canceled", "job_id", job.ID)
+
+		w.progressTracker.ReportStage(ctx, job.ID, StageFailed)
+		if err := transitionJobStatus(job, models.BuildStatusCanceled, false); err != nil {
+			w.logger.Error("failed to transition job status to canceled",
+				"job_id", job.ID,
+				"error", err,
+			)
+		}
+		deployment.Status = models.DeploymentStatusCanceled
+		logCallback("=== Build canceled ===")
+
+		// Nothing of a canceled build is kept
+		w.discardSnapshot(job)
+		w.storeBuildLogs(ctx, job.DeploymentID, buildLogs)
+	} else if buildErr != nil {
 		// Include detection info in build failure log
 		// **Validates: Requirements 2.2** - Include detection information in error messages
 		logFields := []any{
@@ -963,11 +1179,21 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 		}
 		w.logger.Error("build failed", logFields...)
 
+		// Explain the failure on the build and in its notification, and retry
+		// failures that are likely transient
+		logLines := tail.Lines()
+		if buildLogs != "" {
+			logLines = strings.Split(buildLogs, "\n")
+		}
+		timedOut := errors.Is(buildErr, ErrBuildTimeout) || errors.Is(buildErr, context.DeadlineExceeded)
+		job.Failure = diagnose.Classify(buildErr, logLines, timedOut)
+
 		// Check if we should retry
-		if w.retryManager.ShouldRetry(ctx, job, buildErr) {
+		if w.retryManager.ShouldRetryFailure(ctx, job, buildErr, job.Failure) {
 			w.logger.Info("preparing build retry",
 				"job_id", job.ID,
 				"retry_count", job.RetryCount,
+				"failure_category", job.Failure.Category,
 			)
 
 			// Record the failed attempt
@@ -984,10 +1210,14 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 			// Prepare retry job
 			retryJob, retryErr := w.retryManager.PrepareRetry(ctx, job)
 			if retryErr == nil {
+				// Only the final attempt's environment is worth debugging
+				w.discardSnapshot(job)
+
 				// Update job for retry - use isRetry=true to allow running → queued transition
 				job.RetryCount = retryJob.RetryCount
 				job.BuildType = retryJob.BuildType
 				job.RetryAsOCI = retryJob.RetryAsOCI
+				job.Priority = models.BuildPriorityRetry
 				if err := transitionJobStatus(job, models.BuildStatusQueued, true); err != nil {
 					w.logger.Error("failed to transition job status for retry",
 						"job_id", job.ID,
@@ -1028,22 +1258,29 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 		// Stream detection info to build logs on failure
 		// **Validates: Requirements 2.2** - Include detection information in error messages
 		if job.DetectionResult != nil {
-			w.streamLog(ctx, job.DeploymentID, "=== Detection Results ===")
-			w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Strategy: %s", job.DetectionResult.Strategy))
-			w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Framework: %s", job.DetectionResult.Framework))
-			w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Version: %s", job.DetectionResult.Version))
+			logCallback("=== Detection Results ===")
+			logCallback(fmt.Sprintf("Strategy: %s", job.DetectionResult.Strategy))
+			logCallback(fmt.Sprintf("Framework: %s", job.DetectionResult.Framework))
+			logCallback(fmt.Sprintf("Version: %s", job.DetectionResult.Version))
 			if cgoEnabled, ok := job.DetectionResult.SuggestedConfig["cgo_enabled"].(bool); ok {
-				w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("CGO Enabled: %v", cgoEnabled))
+				logCallback(fmt.Sprintf("CGO Enabled: %v", cgoEnabled))
 			}
 			if len(job.DetectionResult.EntryPoints) > 0 {
-				w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Entry Points: %v", job.DetectionResult.EntryPoints))
+				logCallback(fmt.Sprintf("Entry Points: %v", job.DetectionResult.EntryPoints))
 			}
 			if len(job.DetectionResult.Warnings) > 0 {
-				w.streamLog(ctx, job.DeploymentID, fmt.Sprintf("Warnings: %v", job.DetectionResult.Warnings))
+				logCallback(fmt.Sprintf("Warnings: %v", job.DetectionResult.Warnings))
 			}
 		}
 
-		// Store the build logs even on failure
+		// Keep the build's environment for debugging if it asked for it
+		w.saveSnapshot(ctx, job, logCallback)
+
+		// Store the build logs even on failure; a timed-out build whose
+		// executor did not return its logs keeps the last lines streamed
+		if buildLogs == "" && timedOut {
+			buildLogs = strings.Join(logLines, "\n")
+		}
 		w.storeBuildLogs(ctx, job.DeploymentID, buildLogs)
 	} else {
 		w.logger.Info("build succeeded",
@@ -1058,12 +1295,21 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 				"error", err,
 			)
 		}
+		job.Artifact = artifact
+		job.Failure = nil
+		w.reportReproducibility(ctx, job)
+		w.reportCacheStats(ctx, job)
+		w.attest(ctx, job, deployment.GitCommit, logCallback)
 		deployment.Status = models.DeploymentStatusBuilt
 		deployment.Artifact = artifact
 	}
 
 	deployment.UpdatedAt = finishedAt
 
+	// Persist the remaining output before the build turns terminal, so
+	// clients that stop tailing on the final status have all of it
+	buildLog.Close()
+
 	// Update the job
 	if err := w.store.Builds().Update(ctx, job); err != nil {
 		w.logger.Error("failed to update job status", "job_id", job.ID, "error", err)
@@ -1074,11 +1320,75 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) error {
 		w.logger.Error("failed to update deployment status", "deployment_id", deployment.ID, "error", err)
 	}
 
+	w.notifyBuildFinished(ctx, job)
+
 	// Return nil to acknowledge the job - build failures are recorded in the database
 	// and should not be retried via the queue
 	return nil
 }
 
+// notifyBuildFinished records the build's duration and publishes that the
+// build has finished.
+func (w *Worker) notifyBuildFinished(ctx context.Context, job *models.BuildJob) {
+	if w.buildDurations != nil && job.StartedAt != nil && job.FinishedAt != nil {
+		w.buildDurations.With(string(job.BuildStrategy), string(job.Status)).
+			Observe(job.FinishedAt.Sub(*job.StartedAt).Seconds())
+	}
+	if w.events != nil {
+		w.events.Publish(&events.Transition{Kind: events.TransitionBuildFinished, Build: job})
+	}
+}
+
+// cancelBeforeStart marks a build that was canceled before this worker
+// started it, and its deployment, canceled.
+func (w *Worker) cancelBeforeStart(ctx context.Context, job *models.BuildJob) {
+	w.logger.Info("build canceled before it started", "job_id", job.ID)
+	if err := transitionJobStatus(job, models.BuildStatusCanceled, false); err != nil {
+		w.logger.Error("failed to transition job status to canceled", "job_id", job.ID, "error", err)
+		return
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+	if err := w.store.Builds().Update(ctx, job); err != nil {
+		w.logger.Error("failed to update job status", "job_id", job.ID, "error", err)
+	}
+	if deployment, err := w.store.Deployments().Get(ctx, job.DeploymentID); err == nil && deployment != nil {
+		deployment.Status = models.DeploymentStatusCanceled
+		deployment.UpdatedAt = now
+		if err := w.store.Deployments().Update(ctx, deployment); err != nil {
+			w.logger.Error("failed to update deployment status", "deployment_id", deployment.ID, "error", err)
+		}
+	}
+	w.notifyBuildFinished(ctx, job)
+}
+
+// watchCancel cancels a running build's context with ErrBuildCanceled once
+// someone asks to cancel it, until the context is done.
+func (w *Worker) watchCancel(ctx context.Context, jobID string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job, err := w.store.Builds().Get(ctx, jobID)
+			if err != nil {
+				if ctx.Err() == nil {
+					w.logger.Warn("failed to check for build cancel", "job_id", jobID, "error", err)
+				}
+				continue
+			}
+			if job.CancelRequestedAt != nil {
+				w.logger.Info("canceling build", "job_id", jobID)
+				cancel(ErrBuildCanceled)
+				return
+			}
+		}
+	}
+}
+
 // executeWithStrategy routes the build to the appropriate strategy executor.
 func (w *Worker) executeWithStrategy(ctx context.Context, job *models.BuildJob, logCallback func(string)) (string, string, error) {
 	// Determine the timeout for this build
@@ -1096,9 +1406,22 @@ func (w *Worker) executeWithStrategy(ctx context.Context, job *models.BuildJob,
 	}
 	resultCh := make(chan buildResult, 1)
 
+	// The executor and the timeout below both write to the build's logs
+	var logMu sync.Mutex
+	logLine := func(line string) {
+		logMu.Lock()
+		defer logMu.Unlock()
+		logCallback(line)
+	}
+
 	// Execute the build in a goroutine
 	go func() {
-		artifact, logs, err := w.executeBuild(buildCtx, job, logCallback)
+		execCtx, span := tracing.Start(buildCtx, "build.execute",
+			attribute.String("build.strategy", string(job.BuildStrategy)),
+			attribute.String("build.type", string(job.BuildType)),
+		)
+		artifact, logs, err := w.executeBuild(execCtx, job, logLine)
+		tracing.End(span, err)
 		resultCh <- buildResult{artifact, logs, err}
 	}()
 
@@ -1107,9 +1430,29 @@ func (w *Worker) executeWithStrategy(ctx context.Context, job *models.BuildJob,
 	case result := <-resultCh:
 		return result.artifact, result.logs, result.err
 	case <-buildCtx.Done():
+		if errors.Is(context.Cause(buildCtx), ErrBuildCanceled) {
+			// Let the executor kill its processes and remove its partial
+			// artifacts before the build is marked canceled
+			select {
+			case <-resultCh:
+			case <-time.After(cancelGracePeriod):
+				w.logger.Warn("build executor did not stop after cancel", "job_id", job.ID)
+			}
+			return "", "", ErrBuildCanceled
+		}
 		if buildCtx.Err() == context.DeadlineExceeded {
-			logCallback(fmt.Sprintf("=== Build timeout exceeded (%v) ===", timeout))
-			return "", "", fmt.Errorf("%w: build exceeded %v timeout", ErrBuildTimeout, timeout)
+			logLine(fmt.Sprintf("=== Build timeout exceeded (%v) ===", timeout))
+			timeoutErr := fmt.Errorf("%w: build exceeded %v timeout", ErrBuildTimeout, timeout)
+
+			// Keep the logs of the build so far, once the executor has
+			// stopped its processes
+			select {
+			case result := <-resultCh:
+				return "", result.logs, timeoutErr
+			case <-time.After(cancelGracePeriod):
+				w.logger.Warn("build executor did not stop after timeout", "job_id", job.ID)
+			}
+			return "", "", timeoutErr
 		}
 		return "", "", buildCtx.Err()
 	}
@@ -1240,9 +1583,13 @@ func (w *Worker) buildPureNix(ctx context.Context, job *models.BuildJob, logCall
 	w.progressTracker.ReportStage(ctx, job.ID, StagePushing)
 	w.progressTracker.ReportProgress(ctx, job.ID, 80, "Pushing to cache")
 
-	// Push the closure to Attic
+	// Push the closure to the app's Attic cache
 	logCallback("=== Pushing closure to Attic ===")
-	pushResult, err := w.atticClient.PushWithDependencies(ctx, result.StorePath)
+	attic := w.atticClient
+	if job.CacheStats != nil && job.CacheStats.Cache != "" {
+		attic = attic.WithCache(job.CacheStats.Cache)
+	}
+	pushResult, err := attic.PushWithDependencies(ctx, result.StorePath)
 	if err != nil {
 		return "", result.Logs, fmt.Errorf("pushing to Attic: %w", err)
 	}
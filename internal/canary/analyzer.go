@@ -0,0 +1,223 @@
+// Package canary analyzes canary deployments: once a canary has served
+// beside its baseline for the evaluation window, their error rates and
+// latencies, as counted by node agents, are compared and the verdict is
+// recorded on the canary. A canary that passes, or that had too little
+// traffic to judge, is promoted by stopping its baseline; one that fails is
+// rolled back by stopping it, leaving the baseline serving.
+package canary
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how often canaries are analyzed.
+type Config struct {
+	// PollInterval is how often pending analyses are checked.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 15 * time.Second,
+	}
+}
+
+// Stopper stops a deployment's containers on the node running it.
+type Stopper interface {
+	Stop(ctx context.Context, nodeID string, deploymentID string) error
+}
+
+// Analyzer finishes the pending analyses of running canaries whose window
+// has passed, and fails those of canaries that stop running before it, as
+// a deployment notifier of the gRPC server.
+type Analyzer struct {
+	store   store.Store
+	stopper Stopper
+	config  Config
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewAnalyzer creates a canary analyzer. stopper stops the deployment that
+// loses the analysis; it may be nil, in which case it is only marked
+// stopped or failed.
+func NewAnalyzer(st store.Store, stopper Stopper, cfg Config, logger *slog.Logger) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Analyzer{
+		store:   st,
+		stopper: stopper,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// DeploymentStatusChanged fails the pending analysis of a canary that
+// stopped running before its window passed.
+func (a *Analyzer) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if deployment.Canary == nil || deployment.Canary.Status != models.CanaryPending {
+		return
+	}
+	if deployment.Status != models.DeploymentStatusFailed && deployment.Status != models.DeploymentStatusStopped {
+		return
+	}
+	reason := fmt.Sprintf("canary stopped running (%s) before its analysis finished", deployment.Status)
+	if err := a.finish(ctx, deployment, models.CanaryFailed, []string{reason}); err != nil {
+		a.logger.Error("failed to record canary verdict", "error", err, "deployment_id", deployment.ID)
+	}
+}
+
+// Run analyzes canaries every poll interval until ctx is cancelled.
+func (a *Analyzer) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce analyzes every running canary whose window has passed.
+func (a *Analyzer) RunOnce(ctx context.Context) {
+	deployments, err := a.store.Deployments().ListByStatus(ctx, models.DeploymentStatusRunning)
+	if err != nil {
+		a.logger.Error("failed to list running deployments", "error", err)
+		return
+	}
+	for _, d := range deployments {
+		if d.Canary == nil || d.Canary.Status != models.CanaryPending || d.StartedAt == nil {
+			continue
+		}
+		if err := a.analyze(ctx, d); err != nil {
+			a.logger.Error("failed to analyze canary", "error", err, "deployment_id", d.ID)
+		}
+	}
+}
+
+// analyze compares a canary with its baseline once its window has passed,
+// records the verdict and promotes or rolls back the canary.
+func (a *Analyzer) analyze(ctx context.Context, canary *models.Deployment) error {
+	analysis := canary.Canary
+	from := *canary.StartedAt
+	to := from.Add(analysis.Config.Window())
+	analysis.StartedAt = &from
+
+	baseline, err := a.store.Deployments().Get(ctx, analysis.BaselineID)
+	if err != nil || baseline == nil || baseline.Status != models.DeploymentStatusRunning {
+		return a.finish(ctx, canary, models.CanaryInconclusive,
+			[]string{"baseline stopped running before the analysis finished"})
+	}
+	if a.now().Before(to) {
+		return nil
+	}
+
+	rollups, err := a.store.Metrics().ListByService(ctx, canary.AppID, canary.ServiceName,
+		from.Truncate(models.MetricsResolution), to)
+	if err != nil {
+		return fmt.Errorf("listing metrics: %w", err)
+	}
+	canaryMetrics := windowMetrics(rollups, canary.ID, true)
+	baselineMetrics := windowMetrics(rollups, baseline.ID, false)
+	analysis.Canary = &canaryMetrics
+	analysis.Baseline = &baselineMetrics
+
+	status, reasons := analysis.Config.Evaluate(canaryMetrics, baselineMetrics)
+	now := a.now()
+	loser := baseline
+	if status == models.CanaryFailed {
+		// Saved with the verdict
+		loser = canary
+		canary.Status = models.DeploymentStatusFailed
+		canary.FinishedAt = &now
+	} else {
+		baseline.Status = models.DeploymentStatusStopped
+		baseline.FinishedAt = &now
+		baseline.UpdatedAt = now
+		if err := a.store.Deployments().Update(ctx, baseline); err != nil {
+			return fmt.Errorf("stopping baseline: %w", err)
+		}
+	}
+	if err := a.finish(ctx, canary, status, reasons); err != nil {
+		return err
+	}
+	a.stop(ctx, loser)
+
+	a.logger.Info("canary analyzed",
+		"app_id", canary.AppID,
+		"service_name", canary.ServiceName,
+		"deployment_id", canary.ID,
+		"baseline_id", baseline.ID,
+		"verdict", status,
+	)
+	return nil
+}
+
+// windowMetrics sums what a deployment served over the rollups of a window.
+// Counters grow from the deployment's first rollup in the window, or from
+// zero for a deployment that started in it; a counter that went backwards
+// (the container restarted) counts from zero.
+func windowMetrics(rollups []*models.MetricRollup, deploymentID string, fromZero bool) models.CanaryMetrics {
+	var requests, errors, latency int64
+	var last *models.MetricRollup
+	for _, r := range rollups {
+		if r.DeploymentID != deploymentID || r.Samples == 0 {
+			continue
+		}
+		switch {
+		case last != nil:
+			requests += counterIncrease(last.Requests, r.Requests)
+			errors += counterIncrease(last.Errors, r.Errors)
+			latency += counterIncrease(last.LatencyMsSum, r.LatencyMsSum)
+		case fromZero:
+			requests, errors, latency = r.Requests, r.Errors, r.LatencyMsSum
+		}
+		last = r
+	}
+	return models.NewCanaryMetrics(requests, errors, latency)
+}
+
+// counterIncrease returns how much a cumulative counter grew from prev to
+// cur, treating a decrease as a reset to zero.
+func counterIncrease(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// stop stops a deployment on its node, if it was placed on one.
+func (a *Analyzer) stop(ctx context.Context, deployment *models.Deployment) {
+	if deployment.NodeID == "" || a.stopper == nil {
+		return
+	}
+	if err := a.stopper.Stop(ctx, deployment.NodeID, deployment.ID); err != nil {
+		a.logger.Error("failed to stop deployment", "error", err, "deployment_id", deployment.ID, "node_id", deployment.NodeID)
+	}
+}
+
+// finish records a canary's verdict and the reasons for it.
+func (a *Analyzer) finish(ctx context.Context, canary *models.Deployment, status models.CanaryStatus, reasons []string) error {
+	now := a.now()
+	canary.UpdatedAt = now
+	canary.Canary.Status = status
+	canary.Canary.Reasons = reasons
+	canary.Canary.FinishedAt = &now
+	if err := a.store.Deployments().Update(ctx, canary); err != nil {
+		return fmt.Errorf("updating canary deployment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package canary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the analyzer uses.
+type memStore struct {
+	store.Store
+	deployments map[string]*models.Deployment
+	rollups     []*models.MetricRollup
+}
+
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) Metrics() store.MetricStore         { return memMetrics{s: s} }
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	return m.s.deployments[id], nil
+}
+
+func (m memDeployments) Update(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments[d.ID] = d
+	return nil
+}
+
+func (m memDeployments) ListByStatus(ctx context.Context, status models.DeploymentStatus) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.Status == status {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+type memMetrics struct {
+	store.MetricStore
+	s *memStore
+}
+
+func (m memMetrics) ListByService(ctx context.Context, appID, serviceName string, from, to time.Time) ([]*models.MetricRollup, error) {
+	var result []*models.MetricRollup
+	for _, r := range m.s.rollups {
+		if r.AppID == appID && r.ServiceName == serviceName && !r.Bucket.Before(from) && r.Bucket.Before(to) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+type recordingStopper struct {
+	stopped []string
+}
+
+func (s *recordingStopper) Stop(ctx context.Context, nodeID, deploymentID string) error {
+	s.stopped = append(s.stopped, deploymentID)
+	return nil
+}
+
+// newCanaryStore returns a store with a running baseline and a canary of it
+// that started at started, each having served requests at the given
+// cumulative counters one and four minutes in.
+func newCanaryStore(started time.Time, canary, baseline [2][3]int64) *memStore {
+	st := &memStore{deployments: map[string]*models.Deployment{
+		"base": {ID: "base", AppID: "app", ServiceName: "web", Version: 1, NodeID: "n1", Status: models.DeploymentStatusRunning},
+		"canary": {ID: "canary", AppID: "app", ServiceName: "web", Version: 2, NodeID: "n1", Status: models.DeploymentStatusRunning,
+			StartedAt: &started,
+			Canary:    &models.CanaryAnalysis{Status: models.CanaryPending, BaselineID: "base", Config: models.CanaryConfig{}.WithDefaults()}},
+	}}
+	for i, minute := range []time.Duration{time.Minute, 4 * time.Minute} {
+		for id, c := range map[string][3]int64{"canary": canary[i], "base": baseline[i]} {
+			st.rollups = append(st.rollups, &models.MetricRollup{
+				DeploymentID: id, AppID: "app", ServiceName: "web", Bucket: started.Add(minute), Samples: 1,
+				Requests: c[0], Errors: c[1], LatencyMsSum: c[2],
+			})
+		}
+	}
+	return st
+}
+
+func TestAnalyzer(t *testing.T) {
+	started := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	after := started.Add(models.DefaultCanaryWindow + time.Minute)
+
+	t.Run("waits for the window", func(t *testing.T) {
+		st := newCanaryStore(started, [2][3]int64{{10, 0, 1000}, {200, 0, 20000}}, [2][3]int64{{5000, 5, 500000}, {7000, 7, 700000}})
+		a := NewAnalyzer(st, &recordingStopper{}, DefaultConfig(), nil)
+		a.now = func() time.Time { return started.Add(time.Minute) }
+		a.RunOnce(context.Background())
+		if st.deployments["canary"].Canary.Status != models.CanaryPending {
+			t.Errorf("verdict = %s before the window passed", st.deployments["canary"].Canary.Status)
+		}
+	})
+
+	t.Run("promotes a healthy canary", func(t *testing.T) {
+		// Canary: 200 requests, none failed, 100ms; baseline: 2000 more, 2 failed, 100ms
+		st := newCanaryStore(started, [2][3]int64{{10, 0, 1000}, {200, 0, 20000}}, [2][3]int64{{5000, 5, 500000}, {7000, 7, 700000}})
+		stopper := &recordingStopper{}
+		a := NewAnalyzer(st, stopper, DefaultConfig(), nil)
+		a.now = func() time.Time { return after }
+		a.RunOnce(context.Background())
+
+		analysis := st.deployments["canary"].Canary
+		if analysis.Status != models.CanaryPassed {
+			t.Fatalf("verdict = %s %q, want passed", analysis.Status, analysis.Reasons)
+		}
+		if analysis.Canary.Requests != 200 || analysis.Baseline.Requests != 2000 || analysis.Baseline.Errors != 2 {
+			t.Errorf("metrics = canary %+v baseline %+v", analysis.Canary, analysis.Baseline)
+		}
+		if st.deployments["base"].Status != models.DeploymentStatusStopped || st.deployments["canary"].Status != models.DeploymentStatusRunning {
+			t.Error("baseline was not stopped in favour of the canary")
+		}
+		if len(stopper.stopped) != 1 || stopper.stopped[0] != "base" {
+			t.Errorf("stopped = %v, want [base]", stopper.stopped)
+		}
+	})
+
+	t.Run("rolls back a canary with more errors", func(t *testing.T) {
+		// Canary: 10% of 200 requests failed against 0.1% of the baseline's
+		st := newCanaryStore(started, [2][3]int64{{10, 1, 1000}, {200, 20, 20000}}, [2][3]int64{{5000, 5, 500000}, {7000, 7, 700000}})
+		stopper := &recordingStopper{}
+		a := NewAnalyzer(st, stopper, DefaultConfig(), nil)
+		a.now = func() time.Time { return after }
+		a.RunOnce(context.Background())
+
+		analysis := st.deployments["canary"].Canary
+		if analysis.Status != models.CanaryFailed || len(analysis.Reasons) != 1 {
+			t.Fatalf("verdict = %s %q, want failed on the error rate", analysis.Status, analysis.Reasons)
+		}
+		if st.deployments["canary"].Status != models.DeploymentStatusFailed || st.deployments["base"].Status != models.DeploymentStatusRunning {
+			t.Error("canary was not failed in favour of the baseline")
+		}
+		if len(stopper.stopped) != 1 || stopper.stopped[0] != "canary" {
+			t.Errorf("stopped = %v, want [canary]", stopper.stopped)
+		}
+	})
+
+	t.Run("fails the analysis of a canary that stopped", func(t *testing.T) {
+		st := newCanaryStore(started, [2][3]int64{}, [2][3]int64{})
+		a := NewAnalyzer(st, nil, DefaultConfig(), nil)
+		canary := st.deployments["canary"]
+		canary.Status = models.DeploymentStatusFailed
+		a.DeploymentStatusChanged(context.Background(), canary, models.DeploymentStatusRunning)
+		if canary.Canary.Status != models.CanaryFailed {
+			t.Errorf("verdict = %s, want failed", canary.Canary.Status)
+		}
+	})
+}
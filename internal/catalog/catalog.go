@@ -0,0 +1,260 @@
+// Package catalog keeps the API catalog of each organization: the OpenAPI
+// specs services publish at their openapi_url, fetched after each deploy and
+// periodically, so teams can discover the APIs of each other's services.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how often specs are refetched.
+type Config struct {
+	// RefreshInterval is how often the specs of every service are
+	// refetched, catching changes made without a deploy.
+	RefreshInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		RefreshInterval: 6 * time.Hour,
+	}
+}
+
+// Catalog fetches services' OpenAPI specs into the store.
+type Catalog struct {
+	store  store.Store
+	client *http.Client
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewCatalog creates a catalog that fetches specs with client. A nil client
+// uses one with a 30 second timeout.
+func NewCatalog(st store.Store, client *http.Client, cfg Config, logger *slog.Logger) *Catalog {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Catalog{
+		store:  st,
+		client: client,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Refresh fetches the spec of a service and stores it. A failed fetch keeps
+// the spec fetched before and is reported in the returned spec's Error
+// rather than returned. Services without an openapi_url have their spec
+// removed, and nil is returned.
+func (c *Catalog) Refresh(ctx context.Context, appID string, service *models.ServiceConfig) (*models.ServiceAPISpec, error) {
+	if service.OpenAPIURL == "" {
+		return nil, c.store.APICatalog().Delete(ctx, appID, service.Name)
+	}
+
+	spec, err := c.store.APICatalog().Get(ctx, appID, service.Name)
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil || spec.URL != service.OpenAPIURL {
+		// A spec fetched from another URL describes something else
+		spec = &models.ServiceAPISpec{AppID: appID, ServiceName: service.Name, URL: service.OpenAPIURL}
+	}
+	spec.CheckedAt = c.now()
+	spec.Error = ""
+
+	doc, err := c.fetch(ctx, service.OpenAPIURL)
+	if err != nil {
+		spec.Error = err.Error()
+		c.logger.Warn("failed to fetch OpenAPI spec",
+			"app_id", appID,
+			"service_name", service.Name,
+			"url", service.OpenAPIURL,
+			"error", err,
+		)
+	} else {
+		spec.Title = doc.Title
+		spec.Version = doc.Version
+		spec.Description = doc.Description
+		spec.Operations = doc.Operations
+		spec.Spec = doc.JSON
+		fetchedAt := spec.CheckedAt
+		spec.FetchedAt = &fetchedAt
+	}
+	if spec.Operations == nil {
+		spec.Operations = []models.APIOperation{}
+	}
+
+	if err := c.store.APICatalog().Upsert(ctx, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// fetch downloads and parses a spec.
+func (c *Catalog) fetch(ctx context.Context, url string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, models.MaxOpenAPISpecSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := data
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, fmt.Errorf("spec URL returned %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	if len(data) > models.MaxOpenAPISpecSize {
+		return nil, fmt.Errorf("spec is larger than %d MiB", models.MaxOpenAPISpecSize>>20)
+	}
+	return Parse(data)
+}
+
+// DeploymentStatusChanged refetches, in the background, the spec of a
+// service with an openapi_url when a deployment of it starts running, so the
+// catalog describes the version just deployed. Cron runs are skipped.
+func (c *Catalog) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if deployment.Status != models.DeploymentStatusRunning || previous == models.DeploymentStatusRunning || deployment.IsCronRun() {
+		return
+	}
+	go func() {
+		ctx := context.WithoutCancel(ctx)
+		app, err := c.store.Apps().Get(ctx, deployment.AppID)
+		if err != nil || app == nil {
+			return
+		}
+		for i := range app.Services {
+			if app.Services[i].Name == deployment.ServiceName && app.Services[i].OpenAPIURL != "" {
+				if _, err := c.Refresh(ctx, app.ID, &app.Services[i]); err != nil {
+					c.logger.Error("failed to refresh OpenAPI spec", "error", err, "app_id", app.ID)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Run refetches every spec each refresh interval until ctx is cancelled.
+func (c *Catalog) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce refetches the specs of every service with an openapi_url and
+// removes those of services that no longer have one.
+func (c *Catalog) RunOnce(ctx context.Context) {
+	apps, err := c.store.Apps().ListAll(ctx)
+	if err != nil {
+		c.logger.Error("failed to list apps", "error", err)
+		return
+	}
+	for _, app := range apps {
+		published := make(map[string]bool)
+		for i := range app.Services {
+			if app.Services[i].OpenAPIURL == "" {
+				continue
+			}
+			published[app.Services[i].Name] = true
+			if _, err := c.Refresh(ctx, app.ID, &app.Services[i]); err != nil {
+				c.logger.Error("failed to refresh OpenAPI spec", "error", err, "app_id", app.ID)
+			}
+		}
+
+		specs, err := c.store.APICatalog().ListByApp(ctx, app.ID)
+		if err != nil {
+			c.logger.Error("failed to list OpenAPI specs", "error", err, "app_id", app.ID)
+			continue
+		}
+		for _, spec := range specs {
+			if !published[spec.ServiceName] {
+				if err := c.store.APICatalog().Delete(ctx, app.ID, spec.ServiceName); err != nil {
+					c.logger.Error("failed to delete OpenAPI spec", "error", err, "app_id", app.ID)
+				}
+			}
+		}
+	}
+}
+
+// Search returns the entries matching a query, each with only the
+// operations matching it unless the service itself does. Every word of the
+// query must appear, case-insensitively, in the app or service name, the
+// API's title or description, or an operation's method, path, summary,
+// operation ID or tags. An empty query matches everything.
+func Search(entries []*models.CatalogEntry, query string) []*models.CatalogEntry {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return entries
+	}
+
+	result := []*models.CatalogEntry{}
+	for _, entry := range entries {
+		service := strings.ToLower(strings.Join([]string{
+			entry.AppName, entry.ServiceName, entry.Title, entry.Description,
+		}, "\n"))
+		if containsAll(service, words) {
+			result = append(result, entry)
+			continue
+		}
+
+		var operations []models.APIOperation
+		for _, op := range entry.Operations {
+			text := strings.ToLower(strings.Join(append([]string{
+				service, op.Method, op.Path, op.Summary, op.OperationID,
+			}, op.Tags...), "\n"))
+			if containsAll(text, words) {
+				operations = append(operations, op)
+			}
+		}
+		if len(operations) > 0 {
+			matched := *entry
+			matched.Operations = operations
+			result = append(result, &matched)
+		}
+	}
+	return result
+}
+
+func containsAll(text string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
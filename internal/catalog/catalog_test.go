@@ -0,0 +1,175 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing the API catalog store.
+type memStore struct {
+	store.Store
+	catalog *memCatalog
+}
+
+func (s *memStore) APICatalog() store.APICatalogStore { return s.catalog }
+
+type memCatalog struct {
+	store.APICatalogStore
+	specs map[string]*models.ServiceAPISpec
+}
+
+func (m *memCatalog) Upsert(ctx context.Context, spec *models.ServiceAPISpec) error {
+	stored := *spec
+	m.specs[spec.AppID+"/"+spec.ServiceName] = &stored
+	return nil
+}
+
+func (m *memCatalog) Get(ctx context.Context, appID, serviceName string) (*models.ServiceAPISpec, error) {
+	spec, ok := m.specs[appID+"/"+serviceName]
+	if !ok {
+		return nil, nil
+	}
+	copied := *spec
+	return &copied, nil
+}
+
+func (m *memCatalog) Delete(ctx context.Context, appID, serviceName string) error {
+	delete(m.specs, appID+"/"+serviceName)
+	return nil
+}
+
+const petstoreYAML = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.2
+paths:
+  /pets/{id}:
+    delete:
+      summary: Delete a pet
+      deprecated: true
+    get:
+      operationId: getPet
+      tags: [pets]
+      responses:
+        200:
+          description: A pet
+  /pets:
+    post:
+      summary: Create a pet
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(petstoreYAML))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if doc.Title != "Petstore" || doc.Version != "1.2" {
+		t.Errorf("info = %q %q, want Petstore 1.2", doc.Title, doc.Version)
+	}
+	want := []models.APIOperation{
+		{Method: "POST", Path: "/pets", Summary: "Create a pet"},
+		{Method: "GET", Path: "/pets/{id}", OperationID: "getPet", Tags: []string{"pets"}},
+		{Method: "DELETE", Path: "/pets/{id}", Summary: "Delete a pet", Deprecated: true},
+	}
+	got, _ := json.Marshal(doc.Operations)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Errorf("operations = %s, want %s", got, wantJSON)
+	}
+	if !json.Valid(doc.JSON) {
+		t.Errorf("spec is not valid JSON: %s", doc.JSON)
+	}
+
+	if _, err := Parse([]byte(`{"swagger": "2.0", "info": {"title": "Old"}}`)); err != nil {
+		t.Errorf("Parse(swagger 2): %v", err)
+	}
+	if _, err := Parse([]byte(`{"name": "package.json"}`)); !errors.Is(err, ErrNotOpenAPI) {
+		t.Errorf("Parse(non-spec) error = %v, want ErrNotOpenAPI", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(petstoreYAML))
+	}))
+	defer srv.Close()
+
+	mem := &memCatalog{specs: map[string]*models.ServiceAPISpec{}}
+	c := NewCatalog(&memStore{catalog: mem}, srv.Client(), DefaultConfig(), nil)
+	service := &models.ServiceConfig{Name: "api", OpenAPIURL: srv.URL + "/openapi.yaml"}
+
+	spec, err := c.Refresh(context.Background(), "app-1", service)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if spec.Error != "" || spec.Title != "Petstore" || len(spec.Operations) != 3 || spec.FetchedAt == nil {
+		t.Fatalf("spec = %+v, want the fetched Petstore spec", spec)
+	}
+
+	// A failed fetch keeps the spec fetched before
+	status = http.StatusInternalServerError
+	spec, err = c.Refresh(context.Background(), "app-1", service)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if spec.Error == "" || spec.Title != "Petstore" || len(spec.Operations) != 3 {
+		t.Errorf("spec = %+v, want the previous spec with an error", spec)
+	}
+
+	// Removing the URL removes the spec
+	service.OpenAPIURL = ""
+	if _, err := c.Refresh(context.Background(), "app-1", service); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(mem.specs) != 0 {
+		t.Errorf("specs = %v, want none", mem.specs)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := []*models.CatalogEntry{
+		{AppName: "shop", ServiceAPISpec: models.ServiceAPISpec{ServiceName: "orders", Title: "Orders API",
+			Operations: []models.APIOperation{{Method: "GET", Path: "/orders"}, {Method: "POST", Path: "/refunds"}}}},
+		{AppName: "shop", ServiceAPISpec: models.ServiceAPISpec{ServiceName: "users",
+			Operations: []models.APIOperation{{Method: "GET", Path: "/users", Tags: []string{"accounts"}}}}},
+	}
+
+	tests := []struct {
+		query string
+		want  map[string]int // Operations matched per service
+	}{
+		{"", map[string]int{"orders": 2, "users": 1}},
+		{"ORDERS", map[string]int{"orders": 2}},
+		{"post refunds", map[string]int{"orders": 1}},
+		{"shop accounts", map[string]int{"users": 1}},
+		{"payments", map[string]int{}},
+	}
+	for _, tt := range tests {
+		got := map[string]int{}
+		for _, e := range Search(entries, tt.query) {
+			got[e.ServiceName] = len(e.Operations)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for name, n := range tt.want {
+			if got[name] != n {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+	}
+	if len(entries[0].Operations) != 2 {
+		t.Error("Search modified the entries it was given")
+	}
+}
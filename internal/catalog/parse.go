@@ -0,0 +1,133 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ErrNotOpenAPI is returned for documents that are neither OpenAPI 3 nor
+// Swagger 2 specs.
+var ErrNotOpenAPI = errors.New("document is not an OpenAPI or Swagger spec")
+
+// httpMethods lists the operations of an OpenAPI path item in display order.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is an OpenAPI spec read by Parse.
+type Document struct {
+	Title       string
+	Version     string
+	Description string
+	Operations  []models.APIOperation
+	// JSON is the spec as JSON, converted if it was YAML.
+	JSON json.RawMessage
+}
+
+// Parse reads an OpenAPI 3 or Swagger 2 spec written as JSON or YAML.
+func Parse(data []byte) (*Document, error) {
+	var raw any
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("decoding JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("decoding YAML: %w", err)
+		}
+		raw = stringKeys(raw)
+	}
+
+	root, ok := raw.(map[string]any)
+	if !ok || (root["openapi"] == nil && root["swagger"] == nil) {
+		return nil, ErrNotOpenAPI
+	}
+	doc := &Document{}
+	if info, ok := root["info"].(map[string]any); ok {
+		doc.Title = str(info["title"])
+		doc.Version = str(info["version"])
+		doc.Description = str(info["description"])
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	names := make([]string, 0, len(paths))
+	for path := range paths {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	for _, path := range names {
+		item, _ := paths[path].(map[string]any)
+		for _, method := range httpMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			operation := models.APIOperation{
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				OperationID: str(op["operationId"]),
+				Summary:     str(op["summary"]),
+			}
+			if deprecated, ok := op["deprecated"].(bool); ok {
+				operation.Deprecated = deprecated
+			}
+			if tags, ok := op["tags"].([]any); ok {
+				for _, tag := range tags {
+					operation.Tags = append(operation.Tags, str(tag))
+				}
+			}
+			doc.Operations = append(doc.Operations, operation)
+		}
+	}
+
+	var err error
+	if doc.JSON, err = json.Marshal(root); err != nil {
+		return nil, fmt.Errorf("encoding spec as JSON: %w", err)
+	}
+	return doc, nil
+}
+
+// stringKeys converts the maps decoded from YAML to map[string]any, so the
+// document can be encoded as JSON. YAML allows keys such as the status codes
+// of responses to be numbers.
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = stringKeys(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = stringKeys(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// str returns a scalar as a string, e.g. a version written as a YAML
+// number, and "" for anything else.
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil, map[string]any, []any:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
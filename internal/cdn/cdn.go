@@ -0,0 +1,158 @@
+// Package cdn purges apps' content from CDNs (Cloudflare, Fastly and
+// BunnyCDN), on request or after a successful deploy of a service an
+// integration selects. Every purge is logged with its result.
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Purger purges integrations through the provider for their CDN and records
+// the results.
+type Purger struct {
+	store     store.Store
+	providers map[models.CDNProviderType]Provider
+	logger    *slog.Logger
+}
+
+// NewPurger creates a purger that makes HTTP requests with client. A nil
+// client uses one with a 30 second timeout.
+func NewPurger(st store.Store, client *http.Client, logger *slog.Logger) *Purger {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Purger{
+		store: st,
+		providers: map[models.CDNProviderType]Provider{
+			models.CDNProviderCloudflare: &cloudflareProvider{client: client, baseURL: "https://api.cloudflare.com/client/v4"},
+			models.CDNProviderFastly:     &fastlyProvider{client: client, baseURL: "https://api.fastly.com"},
+			models.CDNProviderBunny:      &bunnyProvider{client: client, baseURL: "https://api.bunny.net"},
+		},
+		logger: logger,
+	}
+}
+
+// Validate checks an integration's settings and the config fields its CDN requires.
+func (p *Purger) Validate(integration *models.CDNIntegration) error {
+	if err := integration.Validate(); err != nil {
+		return err
+	}
+	return p.providers[integration.Provider].Validate(integration.Config)
+}
+
+// Redact returns a copy of an integration with secret config fields blanked, for API responses.
+func (p *Purger) Redact(integration *models.CDNIntegration) *models.CDNIntegration {
+	redacted := *integration
+	redacted.Config = make(map[string]string, len(integration.Config))
+	for k, v := range integration.Config {
+		redacted.Config[k] = v
+	}
+	if provider, ok := p.providers[integration.Provider]; ok {
+		for _, f := range provider.SecretFields() {
+			if redacted.Config[f] != "" {
+				redacted.Config[f] = ""
+			}
+		}
+	}
+	return &redacted
+}
+
+// MergeSecrets copies secret fields left blank in an update from the stored
+// config, so clients can edit an integration without re-entering its credentials.
+func (p *Purger) MergeSecrets(update, existing *models.CDNIntegration) {
+	provider, ok := p.providers[update.Provider]
+	if !ok {
+		return
+	}
+	if update.Config == nil {
+		update.Config = map[string]string{}
+	}
+	for _, f := range provider.SecretFields() {
+		if update.Config[f] == "" {
+			update.Config[f] = existing.Config[f]
+		}
+	}
+}
+
+// Purge purges an integration's cache, or only purge.URLs when set, and
+// logs the result. The purge is filled in with the integration, outcome and
+// duration; a failed purge is reported in it rather than returned.
+func (p *Purger) Purge(ctx context.Context, integration *models.CDNIntegration, purge *models.CDNPurge) *models.CDNPurge {
+	purge.AppID = integration.AppID
+	purge.IntegrationID = integration.ID
+	purge.Provider = integration.Provider
+	purge.CreatedAt = time.Now()
+
+	var err error
+	if provider, ok := p.providers[integration.Provider]; ok {
+		err = provider.Purge(ctx, integration.Config, purge.URLs)
+	} else {
+		err = fmt.Errorf("%w: %q", models.ErrCDNProviderType, integration.Provider)
+	}
+	purge.DurationMs = time.Since(purge.CreatedAt).Milliseconds()
+	purge.Succeeded = err == nil
+	purge.Error = ""
+	if err != nil {
+		purge.Error = err.Error()
+		p.logger.Warn("CDN purge failed",
+			"app_id", integration.AppID,
+			"integration_id", integration.ID,
+			"provider", integration.Provider,
+			"trigger", purge.Trigger,
+			"error", err,
+		)
+	} else {
+		p.logger.Info("CDN purged",
+			"app_id", integration.AppID,
+			"integration_id", integration.ID,
+			"provider", integration.Provider,
+			"trigger", purge.Trigger,
+			"urls", len(purge.URLs),
+		)
+	}
+
+	if err := p.store.CDN().RecordPurge(ctx, purge); err != nil {
+		p.logger.Error("failed to record CDN purge", "error", err, "integration_id", integration.ID)
+	}
+	return purge
+}
+
+// DeploymentStatusChanged purges, in the background, the caches of
+// integrations selecting a service when a deployment of it starts running.
+// Cron runs are not deploys and are skipped.
+func (p *Purger) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if deployment.Status != models.DeploymentStatusRunning || previous == models.DeploymentStatusRunning || deployment.IsCronRun() {
+		return
+	}
+	go p.purgeDeployment(context.WithoutCancel(ctx), deployment)
+}
+
+// purgeDeployment purges every integration of the deployment's app that
+// purges on deploys of its service.
+func (p *Purger) purgeDeployment(ctx context.Context, deployment *models.Deployment) {
+	integrations, err := p.store.CDN().ListIntegrations(ctx, deployment.AppID)
+	if err != nil {
+		p.logger.Error("failed to list CDN integrations", "error", err, "app_id", deployment.AppID)
+		return
+	}
+	for _, integration := range integrations {
+		if !integration.PurgesOnDeploy(deployment.ServiceName) {
+			continue
+		}
+		p.Purge(ctx, integration, &models.CDNPurge{
+			Trigger:      models.CDNPurgeTriggerDeploy,
+			DeploymentID: deployment.ID,
+			ServiceName:  deployment.ServiceName,
+		})
+	}
+}
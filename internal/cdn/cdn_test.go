@@ -0,0 +1,179 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing the CDN store.
+type memStore struct {
+	store.Store
+	cdn *memCDN
+}
+
+func (s *memStore) CDN() store.CDNStore { return s.cdn }
+
+type memCDN struct {
+	store.CDNStore
+	integrations []*models.CDNIntegration
+	purges       []*models.CDNPurge
+}
+
+func (m *memCDN) ListIntegrations(ctx context.Context, appID string) ([]*models.CDNIntegration, error) {
+	var result []*models.CDNIntegration
+	for _, i := range m.integrations {
+		if i.AppID == appID {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+func (m *memCDN) RecordPurge(ctx context.Context, purge *models.CDNPurge) error {
+	m.purges = append(m.purges, purge)
+	return nil
+}
+
+// newTestPurger points every provider's API at srv.
+func newTestPurger(st store.Store, srv *httptest.Server) *Purger {
+	p := NewPurger(st, srv.Client(), nil)
+	p.providers[models.CDNProviderCloudflare].(*cloudflareProvider).baseURL = srv.URL
+	p.providers[models.CDNProviderFastly].(*fastlyProvider).baseURL = srv.URL
+	p.providers[models.CDNProviderBunny].(*bunnyProvider).baseURL = srv.URL
+	return p
+}
+
+func TestProviderRequests(t *testing.T) {
+	var got []string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.RequestURI()+" "+
+			r.Header.Get("Authorization")+r.Header.Get("Fastly-Key")+r.Header.Get("AccessKey"))
+		if strings.HasPrefix(r.URL.Path, "/zones/") {
+			body = nil
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer srv.Close()
+	p := newTestPurger(&memStore{cdn: &memCDN{}}, srv)
+
+	tests := []struct {
+		provider models.CDNProviderType
+		config   map[string]string
+		urls     []string
+		want     []string
+	}{
+		{models.CDNProviderCloudflare, map[string]string{"zone_id": "z1", "api_token": "t"}, nil,
+			[]string{"POST /zones/z1/purge_cache Bearer t"}},
+		{models.CDNProviderFastly, map[string]string{"service_id": "s1", "api_token": "k"}, nil,
+			[]string{"POST /service/s1/purge_all k"}},
+		{models.CDNProviderFastly, map[string]string{"service_id": "s1", "api_token": "k"}, []string{srv.URL + "/a.css"},
+			[]string{"PURGE /a.css k"}},
+		{models.CDNProviderBunny, map[string]string{"pull_zone_id": "42", "api_key": "b"}, nil,
+			[]string{"POST /pullzone/42/purgeCache b"}},
+		{models.CDNProviderBunny, map[string]string{"pull_zone_id": "42", "api_key": "b"}, []string{"https://cdn.example.com/a b"},
+			[]string{"POST /purge?url=https%3A%2F%2Fcdn.example.com%2Fa+b b"}},
+	}
+	for _, tt := range tests {
+		got = nil
+		if err := p.providers[tt.provider].Purge(context.Background(), tt.config, tt.urls); err != nil {
+			t.Errorf("%s purge %v: %v", tt.provider, tt.urls, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s purge %v: requests = %q, want %q", tt.provider, tt.urls, got, tt.want)
+		}
+	}
+
+	// Cloudflare purges files when URLs are given
+	if err := p.providers[models.CDNProviderCloudflare].Purge(context.Background(),
+		map[string]string{"zone_id": "z1", "api_token": "t"}, []string{"https://example.com/a"}); err != nil {
+		t.Fatal(err)
+	}
+	if files, ok := body["files"].([]any); !ok || len(files) != 1 || body["purge_everything"] != nil {
+		t.Errorf("cloudflare body = %v", body)
+	}
+}
+
+func TestCloudflareReportsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"errors":[{"message":"zone not found"}]}`))
+	}))
+	defer srv.Close()
+	p := newTestPurger(&memStore{cdn: &memCDN{}}, srv)
+
+	err := p.providers[models.CDNProviderCloudflare].Purge(context.Background(),
+		map[string]string{"zone_id": "z1", "api_token": "t"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "zone not found") {
+		t.Errorf("error = %v, want zone not found", err)
+	}
+}
+
+func TestValidateAndSecrets(t *testing.T) {
+	p := NewPurger(&memStore{cdn: &memCDN{}}, nil, nil)
+
+	if err := p.Validate(&models.CDNIntegration{Provider: "akamai", Name: "x"}); err == nil {
+		t.Error("unknown provider validated")
+	}
+	if err := p.Validate(&models.CDNIntegration{Provider: models.CDNProviderBunny, Name: "x",
+		Config: map[string]string{"pull_zone_id": "1"}}); err == nil {
+		t.Error("bunny without api_key validated")
+	}
+
+	existing := &models.CDNIntegration{Provider: models.CDNProviderCloudflare, Name: "cf",
+		Config: map[string]string{"zone_id": "z1", "api_token": "secret"}}
+	if redacted := p.Redact(existing); redacted.Config["api_token"] != "" || redacted.Config["zone_id"] != "z1" {
+		t.Errorf("redacted config = %v", redacted.Config)
+	}
+	if existing.Config["api_token"] != "secret" {
+		t.Error("Redact modified the stored integration")
+	}
+
+	update := &models.CDNIntegration{Provider: models.CDNProviderCloudflare, Name: "cf",
+		Config: map[string]string{"zone_id": "z2"}}
+	p.MergeSecrets(update, existing)
+	if update.Config["api_token"] != "secret" || update.Config["zone_id"] != "z2" {
+		t.Errorf("merged config = %v", update.Config)
+	}
+}
+
+func TestPurgeDeploymentPurgesSelectedServices(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("bad key"))
+	}))
+	defer srv.Close()
+
+	mem := &memCDN{integrations: []*models.CDNIntegration{
+		{ID: "web", AppID: "app-1", Provider: models.CDNProviderBunny, Enabled: true, Services: []string{"web"},
+			Config: map[string]string{"pull_zone_id": "1", "api_key": "k"}},
+		{ID: "api", AppID: "app-1", Provider: models.CDNProviderBunny, Enabled: true, Services: []string{"api"},
+			Config: map[string]string{"pull_zone_id": "2", "api_key": "k"}},
+		{ID: "off", AppID: "app-1", Provider: models.CDNProviderBunny, Enabled: false, Services: []string{"web"},
+			Config: map[string]string{"pull_zone_id": "3", "api_key": "k"}},
+	}}
+	p := newTestPurger(&memStore{cdn: mem}, srv)
+
+	p.purgeDeployment(context.Background(), &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web"})
+
+	if calls != 1 || len(mem.purges) != 1 {
+		t.Fatalf("calls = %d, purges = %d, want 1 each", calls, len(mem.purges))
+	}
+	purge := mem.purges[0]
+	if purge.IntegrationID != "web" || purge.Trigger != models.CDNPurgeTriggerDeploy || purge.DeploymentID != "dep-1" {
+		t.Errorf("purge = %+v", purge)
+	}
+	if purge.Succeeded || !strings.Contains(purge.Error, "401") {
+		t.Errorf("purge result = succeeded %v, error %q; want the 401 logged", purge.Succeeded, purge.Error)
+	}
+}
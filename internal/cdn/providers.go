@@ -0,0 +1,172 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Provider purges cached content from one kind of CDN.
+type Provider interface {
+	// Validate checks that config holds every field the provider needs.
+	Validate(config map[string]string) error
+	// Purge removes the given URLs from the cache, or everything when urls is empty.
+	Purge(ctx context.Context, config map[string]string, urls []string) error
+	// SecretFields lists config fields that are never returned by the API.
+	SecretFields() []string
+}
+
+// requireFields returns an error naming the first missing config field.
+func requireFields(config map[string]string, fields ...string) error {
+	for _, f := range fields {
+		if strings.TrimSpace(config[f]) == "" {
+			return fmt.Errorf("%w: %s is required", models.ErrCDNConfigMissing, f)
+		}
+	}
+	return nil
+}
+
+// do sends a request with the given headers and treats any non-2xx response
+// as an error. A non-nil body is sent as JSON.
+func do(ctx context.Context, client *http.Client, method, target string, body any, headers map[string]string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := data
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, fmt.Errorf("CDN returned %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return data, nil
+}
+
+// cloudflareProvider purges a zone through the Cloudflare API with an API
+// token allowed to purge its cache.
+type cloudflareProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (p *cloudflareProvider) Validate(config map[string]string) error {
+	return requireFields(config, "zone_id", "api_token")
+}
+
+func (p *cloudflareProvider) Purge(ctx context.Context, config map[string]string, urls []string) error {
+	body := map[string]any{"purge_everything": true}
+	if len(urls) > 0 {
+		body = map[string]any{"files": urls}
+	}
+	data, err := do(ctx, p.client, http.MethodPost,
+		p.baseURL+"/zones/"+url.PathEscape(config["zone_id"])+"/purge_cache", body,
+		map[string]string{"Authorization": "Bearer " + config["api_token"]})
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("decoding Cloudflare response: %w", err)
+	}
+	if !result.Success {
+		msgs := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("cloudflare purge failed: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func (p *cloudflareProvider) SecretFields() []string { return []string{"api_token"} }
+
+// fastlyProvider purges a Fastly service. Single URLs are purged with a
+// PURGE request to the URL itself.
+type fastlyProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (p *fastlyProvider) Validate(config map[string]string) error {
+	return requireFields(config, "service_id", "api_token")
+}
+
+func (p *fastlyProvider) Purge(ctx context.Context, config map[string]string, urls []string) error {
+	headers := map[string]string{"Fastly-Key": config["api_token"], "Accept": "application/json"}
+	if len(urls) == 0 {
+		_, err := do(ctx, p.client, http.MethodPost,
+			p.baseURL+"/service/"+url.PathEscape(config["service_id"])+"/purge_all", nil, headers)
+		return err
+	}
+	for _, u := range urls {
+		if _, err := do(ctx, p.client, "PURGE", u, nil, headers); err != nil {
+			return fmt.Errorf("purging %s: %w", u, err)
+		}
+	}
+	return nil
+}
+
+func (p *fastlyProvider) SecretFields() []string { return []string{"api_token"} }
+
+// bunnyProvider purges a BunnyCDN pull zone with the account API key.
+type bunnyProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (p *bunnyProvider) Validate(config map[string]string) error {
+	return requireFields(config, "pull_zone_id", "api_key")
+}
+
+func (p *bunnyProvider) Purge(ctx context.Context, config map[string]string, urls []string) error {
+	headers := map[string]string{"AccessKey": config["api_key"]}
+	if len(urls) == 0 {
+		_, err := do(ctx, p.client, http.MethodPost,
+			p.baseURL+"/pullzone/"+url.PathEscape(config["pull_zone_id"])+"/purgeCache", nil, headers)
+		return err
+	}
+	for _, u := range urls {
+		if _, err := do(ctx, p.client, http.MethodPost, p.baseURL+"/purge?url="+url.QueryEscape(u), nil, headers); err != nil {
+			return fmt.Errorf("purging %s: %w", u, err)
+		}
+	}
+	return nil
+}
+
+func (p *bunnyProvider) SecretFields() []string { return []string{"api_key"} }
@@ -4,11 +4,13 @@ package cleanup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/archive"
 	"github.com/narvanalabs/control-plane/internal/podman"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
@@ -72,6 +74,7 @@ func (s *Settings) Validate() error {
 type Service struct {
 	store    store.Store
 	podman   *podman.Client
+	archiver *archive.Archiver
 	logger   *slog.Logger
 	settings *Settings
 }
@@ -88,6 +91,12 @@ func NewService(store store.Store, podman *podman.Client, logger *slog.Logger) *
 	}
 }
 
+// SetArchiver sets the archiver that moves the history of archived
+// deployments to object storage.
+func (s *Service) SetArchiver(a *archive.Archiver) {
+	s.archiver = a
+}
+
 // LoadSettings loads cleanup settings from the store, applying defaults if not configured.
 // **Validates: Requirements 15.1, 15.3, 15.4**
 func (s *Service) LoadSettings(ctx context.Context) error {
@@ -517,6 +526,9 @@ func (s *Service) ArchiveDeployments(ctx context.Context) (*ArchiveResult, error
 	for _, deploymentID := range deploymentsToArchive {
 		// Archive the deployment and its associated records
 		if err := s.archiveDeployment(ctx, deploymentID, result); err != nil {
+			if errors.Is(err, archive.ErrNotArchivable) {
+				continue
+			}
 			s.logger.Error("failed to archive deployment",
 				"deployment_id", deploymentID,
 				"error", err,
@@ -606,7 +618,21 @@ func (s *Service) getDeploymentsToArchive(ctx context.Context, cutoff time.Time)
 }
 
 // archiveDeployment archives a single deployment and its associated records.
+// With an archiver set, the deployment's build output and logs are moved to
+// object storage.
 func (s *Service) archiveDeployment(ctx context.Context, deploymentID string, result *ArchiveResult) error {
+	if s.archiver != nil {
+		archive, err := s.archiver.Archive(ctx, deploymentID)
+		if err != nil {
+			return err
+		}
+		if archive.BuildID != "" {
+			result.BuildsArchived++
+		}
+		result.LogsArchived++
+		return nil
+	}
+
 	// Get the deployment
 	deployment, err := s.store.Deployments().Get(ctx, deploymentID)
 	if err != nil {
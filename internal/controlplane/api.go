@@ -0,0 +1,444 @@
+// Package controlplane starts the API server and the build worker of the
+// control plane. The api and worker binaries each start one; the all-in-one
+// narvana binary starts both in a single process.
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/api"
+	"github.com/narvanalabs/control-plane/internal/audit"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/canary"
+	"github.com/narvanalabs/control-plane/internal/catalog"
+	"github.com/narvanalabs/control-plane/internal/cdn"
+	"github.com/narvanalabs/control-plane/internal/cronjobs"
+	"github.com/narvanalabs/control-plane/internal/debugsession"
+	"github.com/narvanalabs/control-plane/internal/deploy"
+	"github.com/narvanalabs/control-plane/internal/drift"
+	"github.com/narvanalabs/control-plane/internal/events"
+	grpcserver "github.com/narvanalabs/control-plane/internal/grpc"
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/kubernetes"
+	"github.com/narvanalabs/control-plane/internal/localnode"
+	"github.com/narvanalabs/control-plane/internal/loglevel"
+	"github.com/narvanalabs/control-plane/internal/logs"
+	"github.com/narvanalabs/control-plane/internal/metrics"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/promotion"
+	pgqueue "github.com/narvanalabs/control-plane/internal/queue/postgres"
+	"github.com/narvanalabs/control-plane/internal/scaling"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/secrets"
+	"github.com/narvanalabs/control-plane/internal/shutdown"
+	"github.com/narvanalabs/control-plane/internal/smoketest"
+	"github.com/narvanalabs/control-plane/internal/sshbroker"
+	pgstore "github.com/narvanalabs/control-plane/internal/store/postgres"
+	"github.com/narvanalabs/control-plane/internal/webhooks"
+	"github.com/narvanalabs/control-plane/migrations"
+	"github.com/narvanalabs/control-plane/pkg/config"
+	"github.com/narvanalabs/control-plane/pkg/logger"
+)
+
+// APIOptions holds the parts of the API server not set by its configuration.
+type APIOptions struct {
+	// LocalNode runs the deployments placed on the control plane's own host.
+	LocalNode *localnode.Backend
+}
+
+// StartAPI starts the HTTP and gRPC servers and the background loops of the
+// API server and registers them with the shutdown coordinator. The loops run
+// until ctx is cancelled; a server that fails shuts the coordinator down.
+func StartAPI(ctx context.Context, cfg *config.Config, store *pgstore.PostgresStore, coordinator *shutdown.Coordinator, opts APIOptions, log *logger.Logger) error {
+	// Log the gRPC server and the rest of the API server at levels that can
+	// be changed at runtime
+	levels := log.Levels()
+	grpcLog := log.ForComponent(loglevel.ComponentGRPC)
+	log = log.ForComponent(loglevel.ComponentAPI)
+
+	// Initialize build queue
+	queue := pgqueue.NewPostgresQueue(store.DB(), log.Logger)
+
+	// Initialize auth service
+	authCfg := &auth.Config{
+		JWTSecret:          []byte(cfg.JWTSecret),
+		TokenExpiry:        cfg.JWTExpiry,
+		AccessTokenExpiry:  cfg.Sessions.AccessTokenExpiry,
+		RefreshTokenExpiry: cfg.Sessions.RefreshTokenExpiry,
+	}
+	authService := auth.NewService(authCfg, store.APIKeys(), log.Logger)
+	authService.SetSessionStore(store.AuthSessions(), store.Users())
+
+	// Create and start the API server
+	server := api.NewServer(cfg, store, queue, authService, log.Logger)
+
+	// Create gRPC server configuration
+	grpcCfg := &grpcserver.Config{
+		Port:                 cfg.GRPCPort,
+		MaxConcurrentStreams: 1000,
+		KeepaliveTime:        30 * time.Second,
+		KeepaliveTimeout:     10 * time.Second,
+		MaxRecvMsgSize:       16 * 1024 * 1024, // 16MB
+	}
+
+	// Create gRPC server (shares store and auth service with HTTP server)
+	grpcServer, err := grpcserver.NewServer(grpcCfg, store, authService, grpcLog.Logger)
+	if err != nil {
+		return fmt.Errorf("creating gRPC server: %w", err)
+	}
+	grpcServer.SetMetrics(server.Telemetry())
+
+	// Serve the public gRPC API for automation with the HTTP API's routes
+	grpcServer.SetPublicAPI(grpcserver.NewPublicAPI(server.Router(), store, grpcLog.Logger))
+
+	// Create scheduler with gRPC agent client, routing deployments placed on
+	// a Kubernetes cluster or the local node to its backend
+	var agentClient scheduler.AgentClient = scheduler.NewGRPCAgentClient(grpcServer.NodeManager(), nil)
+	var backends []scheduler.NodeBackend
+	var clusterBackend *kubernetes.Backend
+	if cfg.Kubernetes.Enabled {
+		k8sCfg := kubernetes.Config{
+			NodeID:       cfg.Kubernetes.NodeID,
+			Pool:         cfg.Kubernetes.Pool,
+			APIServer:    cfg.Kubernetes.APIServer,
+			TokenFile:    cfg.Kubernetes.TokenFile,
+			CAFile:       cfg.Kubernetes.CAFile,
+			Namespace:    cfg.Kubernetes.Namespace,
+			IngressClass: cfg.Kubernetes.IngressClass,
+			SyncInterval: cfg.Kubernetes.SyncInterval,
+		}
+		client, err := kubernetes.NewClient(k8sCfg)
+		if err != nil {
+			return fmt.Errorf("creating kubernetes client: %w", err)
+		}
+		clusterBackend = kubernetes.NewBackend(client, store, k8sCfg, log.Logger)
+		backends = append(backends, clusterBackend)
+	}
+	if opts.LocalNode != nil {
+		backends = append(backends, opts.LocalNode)
+	}
+	if len(backends) > 0 {
+		agentClient = scheduler.NewRouter(agentClient, backends...)
+	}
+	sched := scheduler.NewScheduler(store, agentClient, &config.SchedulerConfig{
+		HealthThreshold: cfg.Scheduler.HealthThreshold,
+		MaxRetries:      cfg.Scheduler.MaxRetries,
+		RetryBackoff:    cfg.Scheduler.RetryBackoff,
+	}, log.Logger)
+
+	// Initialize SOPS service for secret decryption (if configured)
+	// **Validates: Requirements 6.1, 6.2, 6.3**
+	var sopsService *secrets.SOPSService
+	if cfg.SOPS.AgePublicKey != "" || cfg.SOPS.AgePrivateKey != "" {
+		var err error
+		sopsService, err = secrets.NewSOPSService(&secrets.Config{
+			AgePublicKey:  cfg.SOPS.AgePublicKey,
+			AgePrivateKey: cfg.SOPS.AgePrivateKey,
+		}, log.Logger)
+		if err != nil {
+			log.Warn("failed to initialize SOPS service, secrets will not be decrypted", "error", err)
+		}
+	}
+
+	// Initialize EnvMerger for merging app-level secrets with service-level env vars
+	// **Validates: Requirements 6.1, 6.2, 6.3**
+	envMerger := deploy.NewEnvMerger(store, sopsService, log.Logger)
+	sched.SetEnvMerger(envMerger)
+
+	// Evaluate org deploy-stage admission policies before deployments are placed
+	sched.SetAdmission(admission.NewController(store, log.Logger))
+
+	// Batch log inserts from node agents; registered after the database so
+	// buffered entries are flushed before the connection closes
+	logIngester := logs.NewIngester(store.Logs(), logs.DefaultIngesterConfig(), log.Logger)
+	logIngester.Start()
+	grpcServer.SetLogIngester(logIngester)
+	coordinator.Register(shutdown.NewFuncComponent("log-ingester", logIngester.Stop))
+
+	// Queue notifications and app hooks and purge CDN caches for deployment
+	// status changes reported by agents
+	hookTrigger := hooks.NewTrigger(store, log.Logger)
+
+	// Start cron service runs on schedule and record their outcome
+	cronRunner := cronjobs.NewRunner(store, agentClient, cronjobs.DefaultConfig(), log.Logger)
+
+	// Smoke test new deployments and roll back the ones that fail
+	smokeRunner := smoketest.NewRunner(store, agentClient, smoketest.DefaultConfig(), log.Logger)
+
+	// Compare canaries with their baseline, then promote or roll them back
+	canaryAnalyzer := canary.NewAnalyzer(store, agentClient, canary.DefaultConfig(), log.Logger)
+
+	// Fetch the OpenAPI specs of services into the API catalog
+	apiCatalog := catalog.NewCatalog(store, nil, catalog.DefaultConfig(), log.Logger)
+
+	// Deployment status changes and node health transitions go onto the event
+	// bus, which delivers each to every subscriber until it is handled
+	notifier := notifications.NewNotifier(store, log.Logger)
+	bus := events.NewBus(events.DefaultBusConfig(), log.Logger)
+	bus.Subscribe("notifications", notifier)
+	bus.Subscribe("hooks", hookTrigger, events.TransitionDeploymentStatus)
+	bus.Subscribe("webhooks", webhooks.NewDispatcher(store, log.Logger))
+	bus.Subscribe("audit", audit.NewTransitionRecorder(store, log.Logger))
+	bus.Subscribe("events", server.Events(), events.TransitionNodeHealth)
+	for name, n := range map[string]events.DeploymentNotifier{
+		"cdn":      cdn.NewPurger(store, nil, log.Logger),
+		"cronjobs": cronRunner,
+		"smoke":    smokeRunner,
+		"canary":   canaryAnalyzer,
+		"catalog":  apiCatalog,
+	} {
+		bus.Subscribe(name, events.OnDeploymentStatus(n), events.TransitionDeploymentStatus)
+	}
+	grpcServer.SetEvents(bus)
+	if clusterBackend != nil {
+		clusterBackend.SetEvents(bus)
+	}
+	if opts.LocalNode != nil {
+		opts.LocalNode.SetEvents(bus)
+	}
+	go bus.Run(ctx)
+
+	// Let the doctor check the schema and the clocks of connected nodes
+	server.Doctor().SetSchema(store.DB(), migrations.Files)
+	server.Doctor().SetClockSkews(grpcServer.NodeManager().ClockSkews)
+
+	// Create HTTP server for API
+	addr := fmt.Sprintf("%s:%d", cfg.APIHost, cfg.APIPort)
+	httpServer := &http.Server{
+		Addr:         addr,
+		Handler:      server.Router(),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	// Register HTTP server for graceful shutdown
+	coordinator.Register(shutdown.NewHTTPServerComponent("api-http-server", httpServer))
+
+	// Register gRPC server for graceful shutdown
+	coordinator.Register(shutdown.NewFuncComponent("grpc-server", func(ctx context.Context) error {
+		return grpcServer.Stop(ctx)
+	}))
+
+	// Start the gRPC server in a goroutine
+	grpcErrCh := make(chan error, 1)
+	go func() {
+		log.Info("starting gRPC server",
+			"host", cfg.APIHost,
+			"port", cfg.GRPCPort,
+		)
+		if err := grpcServer.Start(context.Background()); err != nil {
+			grpcErrCh <- err
+		}
+		close(grpcErrCh)
+	}()
+
+	// Start the scheduler loop in a goroutine
+	go runSchedulerLoop(ctx, store, sched, log)
+
+	// Mark nodes whose heartbeats lapse unhealthy and move their deployments;
+	// the scheduler loop places deployments waiting for a node
+	healthMonitor := scheduler.NewHealthMonitor(store, sched, cfg.Scheduler.HealthThreshold, 10*time.Second, log.Logger)
+	healthMonitor.SetSchedulePending(false)
+	healthMonitor.SetEvents(bus)
+	go healthMonitor.Start(ctx)
+
+	// Heartbeat the Kubernetes cluster's node and sync its workloads
+	if clusterBackend != nil {
+		go clusterBackend.Run(ctx)
+	}
+
+	// Heartbeat the local node and sync its containers
+	if opts.LocalNode != nil {
+		go opts.LocalNode.Run(ctx)
+	}
+
+	// Close streaming connections that have gone idle
+	go server.Streams().Run(ctx)
+
+	// Forget idle rate limit buckets
+	go server.RateLimits().Run(ctx)
+
+	// Deliver queued build and deployment notifications
+	notificationWorker := notifications.NewWorker(store, notifications.NewDispatcher(nil), notifications.DefaultWorkerConfig(), log.Logger)
+	go notificationWorker.Run(ctx)
+
+	// Call queued app lifecycle hooks
+	hookWorker := hooks.NewWorker(store, hooks.NewSender(store, nil), notifications.DefaultWorkerConfig(), log.Logger)
+	go hookWorker.Run(ctx)
+
+	// Call queued webhooks, including those of builds queued by build workers
+	webhookWorker := webhooks.NewWorker(store, webhooks.NewSender(nil), notifications.DefaultWorkerConfig(), log.Logger)
+	go webhookWorker.Run(ctx)
+
+	// Promote deployments that stay healthy under a promotion policy
+	promotionEvaluator := promotion.NewEvaluator(store, promotion.DefaultConfig(), log.Logger)
+	go promotionEvaluator.Run(ctx)
+
+	// Apply scheduled replica profiles to services
+	scalingCron := scaling.NewCron(store, scaling.DefaultConfig(), log.Logger)
+	scalingCron.SetHooks(hookTrigger)
+	go scalingCron.Run(ctx)
+
+	// Size autoscaled services to their CPU, memory and request rate
+	autoscaler := scaling.NewAutoscaler(store, scaling.DefaultAutoscalerConfig(), log.Logger)
+	autoscaler.SetHooks(hookTrigger)
+	go autoscaler.Run(ctx)
+
+	// Record the drift of apps from their applied app specs
+	driftDetector := drift.NewDetector(store, drift.DefaultConfig(), log.Logger)
+	driftDetector.SetNotifier(notifier)
+	go driftDetector.Run(ctx)
+
+	go cronRunner.Run(ctx)
+	go smokeRunner.Run(ctx)
+	go canaryAnalyzer.Run(ctx)
+	go apiCatalog.Run(ctx)
+
+	// Start and remove the debug containers of debug sessions
+	if debugger, ok := agentClient.(scheduler.Debugger); ok {
+		debugSessions := debugsession.NewManager(store, debugger, debugsession.DefaultConfig(), log.Logger)
+		go debugSessions.Run(ctx)
+	}
+
+	// Drop resource usage rollups past their retention
+	metricsPruner := metrics.NewPruner(store, metrics.DefaultConfig(), log.Logger)
+	go metricsPruner.Run(ctx)
+
+	// Drop audit log entries past their retention
+	auditCfg := audit.DefaultConfig()
+	auditCfg.Retention = cfg.Audit.Retention
+	auditPruner := audit.NewPruner(store, auditCfg, log.Logger)
+	go auditPruner.Run(ctx)
+
+	// Delete orphaned queue jobs and logs, vacuum bloated tables and record
+	// table sizes
+	go server.DBHealth().Run(ctx)
+
+	// Fail operations whose server stopped and drop old finished ones
+	go server.Operations().Run(ctx)
+
+	// Stream changes made by any process to /v1/events subscribers
+	go server.Events().Run(ctx)
+
+	// Apply log levels changed through any API server, reverting them on time
+	if levels != nil {
+		server.LogLevels().Manage(levels)
+	}
+	go server.LogLevels().Run(ctx)
+
+	// Delete idempotency keys whose responses are no longer replayed
+	go server.Idempotency().Run(ctx)
+
+	// Record which clients still use deprecated API routes and versions
+	go server.Versions().Run(ctx)
+
+	// Move the history of old deployments to object storage and retry
+	// failed restores
+	if archiver := server.Archiver(); archiver != nil {
+		go archiver.Run(ctx)
+	}
+
+	// Broker users' SSH sessions to nodes
+	if cfg.SSHBroker.Enabled {
+		hostKey, err := sshbroker.LoadOrCreateHostKey(cfg.SSHBroker.HostKeyPath)
+		if err != nil {
+			return fmt.Errorf("loading ssh broker host key: %w", err)
+		}
+		brokerCfg := sshbroker.DefaultConfig()
+		brokerCfg.Addr = cfg.SSHBroker.Addr
+		brokerCfg.NodePort = cfg.SSHBroker.NodePort
+		broker := sshbroker.NewBroker(store, brokerCfg, hostKey, log.Logger)
+		coordinator.Register(shutdown.NewFuncComponent("ssh-broker", broker.Stop))
+		go func() {
+			log.Info("starting ssh broker", "addr", brokerCfg.Addr)
+			if err := broker.ListenAndServe(); err != nil {
+				log.Error("ssh broker error", "error", err)
+			}
+		}()
+	}
+
+	// Start the HTTP API server in a goroutine
+	httpErrCh := make(chan error, 1)
+	go func() {
+		log.Info("starting API server",
+			"host", cfg.APIHost,
+			"port", cfg.APIPort,
+		)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			httpErrCh <- err
+		}
+		close(httpErrCh)
+	}()
+
+	// Wait for either server to error or shutdown signal
+	go func() {
+		select {
+		case err := <-grpcErrCh:
+			if err != nil {
+				log.Error("gRPC server error", "error", err)
+				coordinator.Shutdown()
+			}
+		case err := <-httpErrCh:
+			if err != nil {
+				log.Error("HTTP server error", "error", err)
+				coordinator.Shutdown()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// runSchedulerLoop periodically checks for built deployments and schedules them.
+func runSchedulerLoop(ctx context.Context, store *pgstore.PostgresStore, sched *scheduler.Scheduler, log *logger.Logger) {
+	log.Info("starting scheduler loop")
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("scheduler loop stopped")
+			return
+		case <-ticker.C:
+			// Find deployments that are built but not yet scheduled
+			deployments, err := store.Deployments().ListByStatus(ctx, models.DeploymentStatusBuilt)
+			if err != nil {
+				log.Error("failed to list built deployments", "error", err)
+				continue
+			}
+
+			for _, deployment := range deployments {
+				log.Info("scheduling deployment",
+					"deployment_id", deployment.ID,
+					"service_name", deployment.ServiceName,
+					"app_id", deployment.AppID,
+				)
+
+				if err := sched.ScheduleAndAssign(ctx, deployment); err != nil {
+					log.Error("failed to schedule deployment",
+						"deployment_id", deployment.ID,
+						"error", err,
+					)
+					continue
+				}
+
+				log.Info("deployment scheduled successfully",
+					"deployment_id", deployment.ID,
+					"node_id", deployment.NodeID,
+				)
+			}
+
+			// Move deployments off nodes an admin is draining
+			if err := sched.DrainNodes(ctx); err != nil {
+				log.Error("failed to drain nodes", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/narvanalabs/control-plane/internal/shutdown"
+	"github.com/narvanalabs/control-plane/internal/tracing"
+	"github.com/narvanalabs/control-plane/pkg/config"
+)
+
+// StartTracing sets up the OpenTelemetry tracing of the process, naming its
+// spans after service, and registers the flushing of the spans left to
+// export with the shutdown coordinator. Call it before StartAPI and
+// StartWorker so the spans of their components are flushed.
+func StartTracing(ctx context.Context, cfg *config.Config, coordinator *shutdown.Coordinator, service string) error {
+	flush, err := tracing.Setup(ctx, cfg.Tracing, service)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	coordinator.Register(shutdown.NewFuncComponent("tracing", flush))
+	return nil
+}
@@ -0,0 +1,221 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/narvanalabs/control-plane/internal/audit"
+	"github.com/narvanalabs/control-plane/internal/builder"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/loadtest"
+	"github.com/narvanalabs/control-plane/internal/loglevel"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/podman"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+	postgresqueue "github.com/narvanalabs/control-plane/internal/queue/postgres"
+	"github.com/narvanalabs/control-plane/internal/shutdown"
+	"github.com/narvanalabs/control-plane/internal/store/postgres"
+	"github.com/narvanalabs/control-plane/internal/telemetry"
+	"github.com/narvanalabs/control-plane/internal/webhooks"
+	"github.com/narvanalabs/control-plane/pkg/config"
+	"github.com/narvanalabs/control-plane/pkg/logger"
+)
+
+// WorkerOptions holds the parts of the build worker not set by its
+// configuration.
+type WorkerOptions struct {
+	// HealthAddr is where the worker health check is served; empty serves none.
+	HealthAddr string
+}
+
+// StartWorker recovers interrupted builds and starts the build worker,
+// registering it with the shutdown coordinator, which waits for the builds
+// in progress. The worker claims jobs until ctx is cancelled.
+func StartWorker(ctx context.Context, cfg *config.Config, store *postgres.PostgresStore, coordinator *shutdown.Coordinator, opts WorkerOptions, log *logger.Logger) error {
+	// Log at a level that can be changed at runtime through the API server
+	levels := log.Levels()
+	log = log.ForComponent(loglevel.ComponentWorker)
+
+	// Initialize queue; jobs are leased to this worker so several workers
+	// can share the queue, within the per-app and per-user build caps
+	workerID := builder.NewWorkerID()
+	queue := postgresqueue.NewPostgresQueue(store.DB(), log.Logger)
+	queue.SetWorker(workerID, cfg.Worker.LeaseDuration)
+	queue.SetLimits(cfg.Worker.MaxBuildsPerApp, cfg.Worker.MaxBuildsPerUser)
+
+	// Perform startup recovery for pending and interrupted builds
+	// **Validates: Requirements 15.1, 15.2**
+	recoveryService := builder.NewRecoveryService(store, queue, log.Logger)
+	recoveryResult, err := recoveryService.RecoverOnStartup(ctx)
+	if err != nil {
+		log.Error("failed to perform startup recovery", "error", err)
+		// Continue anyway - recovery errors shouldn't prevent worker from starting
+	} else {
+		log.Info("startup recovery completed",
+			"interrupted_builds", recoveryResult.InterruptedBuilds,
+			"resumed_builds", recoveryResult.ResumedBuilds,
+		)
+	}
+
+	// Get Attic token from config, fall back to default dev token if not set
+	atticToken := cfg.AtticToken
+	if atticToken == "" {
+		defaultNixCfg := builder.DefaultNixBuilderConfig()
+		atticToken = defaultNixCfg.AtticToken
+	}
+
+	appCaches, err := builder.ParseCacheMapping(cfg.AtticAppCaches)
+	if err != nil {
+		return fmt.Errorf("invalid ATTIC_APP_CACHES: %w", err)
+	}
+
+	// Limit build containers of services that do not set their own limits
+	buildLimits := podman.ResourceLimits{
+		CPUQuota:  cfg.Worker.BuildCPU,
+		MemoryMB:  int64(cfg.Worker.BuildMemoryMB),
+		PidsLimit: podman.DefaultBuildLimits.PidsLimit,
+	}
+
+	// Configure the worker
+	workerCfg := &builder.WorkerConfig{
+		Concurrency:    cfg.Worker.MaxConcurrency,
+		DefaultTimeout: int(cfg.Worker.BuildTimeout.Seconds()),
+		NixConfig: &builder.NixBuilderConfig{
+			WorkDir:          cfg.Worker.WorkDir,
+			PodmanSocket:     cfg.Worker.PodmanSocket,
+			NixImage:         "docker.io/nixos/nix:latest",
+			AtticURL:         cfg.AtticEndpoint,
+			AtticCache:       cfg.AtticCache,
+			AtticToken:       atticToken,
+			AtticPerAppCache: cfg.AtticPerAppCache,
+			AtticAppCaches:   appCaches,
+			BuildLimits:      buildLimits,
+		},
+		OCIConfig: &builder.OCIBuilderConfig{
+			NixBuilderConfig: &builder.NixBuilderConfig{
+				WorkDir:          cfg.Worker.WorkDir,
+				PodmanSocket:     cfg.Worker.PodmanSocket,
+				NixImage:         "docker.io/nixos/nix:latest",
+				AtticURL:         cfg.AtticEndpoint,
+				AtticCache:       cfg.AtticCache,
+				AtticToken:       atticToken,
+				AtticPerAppCache: cfg.AtticPerAppCache,
+				AtticAppCaches:   appCaches,
+				BuildLimits:      buildLimits,
+			},
+			Registry:     cfg.RegistryURL,
+			PodmanSocket: cfg.Worker.PodmanSocket,
+		},
+		AtticConfig: &builder.AtticConfig{
+			Endpoint:  cfg.AtticEndpoint,
+			CacheName: cfg.AtticCache,
+			Timeout:   cfg.Worker.BuildTimeout,
+		},
+		DisableDeduplication: cfg.Worker.DisableBuildDedup,
+		SnapshotTTL:          cfg.Worker.SnapshotTTL,
+	}
+
+	// Create the worker
+	worker, err := builder.NewWorker(workerCfg, store, queue, log.Logger)
+	if err != nil {
+		return fmt.Errorf("creating worker: %w", err)
+	}
+
+	// Publish finished builds on the worker's event bus, which queues their
+	// notifications and webhooks, delivered by the API server, and audits them
+	bus := events.NewBus(events.DefaultBusConfig(), log.Logger)
+	bus.Subscribe("notifications", notifications.NewNotifier(store, log.Logger))
+	bus.Subscribe("webhooks", webhooks.NewDispatcher(store, log.Logger))
+	bus.Subscribe("audit", audit.NewTransitionRecorder(store, log.Logger))
+	worker.SetEvents(bus)
+	go bus.Run(ctx)
+
+	// Record build durations and the queue depth for the metrics endpoint
+	metrics := telemetry.NewRegistry()
+	worker.SetMetrics(metrics)
+	telemetry.CollectBuildQueue(metrics, store)
+
+	// Sign the provenance of successful builds
+	if cfg.Worker.AttestationKeyPath != "" {
+		signer, err := provenance.LoadOrCreateSigner(cfg.Worker.AttestationKeyPath)
+		if err != nil {
+			log.Error("failed to load attestation key, builds will not be attested", "error", err)
+		} else {
+			worker.SetSigner(signer)
+			log.Info("signing build provenance", "key_id", signer.KeyID())
+		}
+	}
+
+	// Register in the build worker registry and keep job leases alive,
+	// reporting whether the worker can reach Podman
+	workerCoordinator := builder.NewCoordinator(store, queue, workerID,
+		cfg.Worker.MaxConcurrency, cfg.Worker.HeartbeatInterval, log.Logger)
+	workerCoordinator.SetPodmanCheck(podman.NewClient(cfg.Worker.PodmanSocket, log.Logger).Ping)
+	worker.SetCoordinator(workerCoordinator)
+
+	// Serve the worker health check and metrics
+	if opts.HealthAddr != "" {
+		healthChecker := builder.NewWorkerHealthChecker(store.DB(), builder.WorkerVersion)
+		healthMux := http.NewServeMux()
+		healthMux.HandleFunc("/health", healthChecker.Handler())
+		healthMux.Handle("GET /metrics", metrics.Handler(cfg.MetricsToken, log.Logger))
+		healthServer := &http.Server{
+			Addr:    opts.HealthAddr,
+			Handler: healthMux,
+		}
+		coordinator.Register(shutdown.NewHTTPServerComponent("worker-health-server", healthServer))
+		go func() {
+			log.Info("starting worker health check server", "addr", opts.HealthAddr)
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("health check server error", "error", err)
+			}
+		}()
+	}
+
+	// Register worker for graceful shutdown (waits for in-progress builds)
+	coordinator.Register(shutdown.NewWorkerComponent("build-worker", worker))
+
+	// Run load tests of deployments from this worker; a test still running
+	// at shutdown is recorded as failed
+	if cfg.Worker.LoadTests {
+		loadTestCtx, stopLoadTests := context.WithCancel(ctx)
+		loadTestsDone := make(chan struct{})
+		go func() {
+			defer close(loadTestsDone)
+			loadtest.NewRunner(store, workerID, loadtest.DefaultConfig(), log.Logger).Run(loadTestCtx)
+		}()
+		coordinator.Register(shutdown.NewFuncComponent("load-test-runner", func(ctx context.Context) error {
+			stopLoadTests()
+			select {
+			case <-loadTestsDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}))
+		log.Info("running load tests", "build_worker_id", workerID)
+	}
+
+	// Apply the worker's log level when changed through the API server
+	if levels != nil {
+		logLevelCfg := loglevel.DefaultConfig()
+		logLevelCfg.RevertAfter = cfg.Logging.LevelRevertAfter
+		logLevels := loglevel.NewController(store, logLevelCfg, log.Logger)
+		logLevels.Manage(levels)
+		go logLevels.Run(ctx)
+	}
+
+	// Start the worker
+	log.Info("starting build worker",
+		"build_worker_id", workerID,
+		"concurrency", cfg.Worker.MaxConcurrency,
+		"work_dir", cfg.Worker.WorkDir,
+	)
+
+	if err := worker.Start(ctx); err != nil {
+		return fmt.Errorf("starting worker: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+// Package cronjobs runs cron services: it starts a one-off run of each cron
+// service when its schedule fires, stops runs that exceed their timeout and
+// records the outcome of every run.
+package cronjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how often schedules are checked.
+type Config struct {
+	// PollInterval is how often every cron service's schedule is checked.
+	// Runs start within one interval of their scheduled time.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 15 * time.Second,
+	}
+}
+
+// Stopper stops a deployment's containers on the node running it.
+type Stopper interface {
+	Stop(ctx context.Context, nodeID string, deploymentID string) error
+}
+
+// Runner starts the runs of cron services on schedule and tracks them until
+// they finish. It is told about the status of run deployments as a
+// deployment notifier of the gRPC server.
+type Runner struct {
+	store   store.Store
+	stopper Stopper
+	config  Config
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewRunner creates a cron runner. stopper stops runs that time out on their
+// node; it may be nil, in which case timed out runs are only recorded.
+func NewRunner(st store.Store, stopper Stopper, cfg Config, logger *slog.Logger) *Runner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Runner{
+		store:   st,
+		stopper: stopper,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Run starts due runs and enforces timeouts every poll interval until ctx
+// is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	r.RunOnce(ctx)
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce starts the runs that are due and stops the ones that timed out.
+func (r *Runner) RunOnce(ctx context.Context) {
+	now := r.now()
+	r.startDue(ctx, now)
+	r.enforceTimeouts(ctx, now)
+}
+
+// startDue starts a run of every deployed cron service whose schedule fired
+// since its last scheduled run. Times missed while the control plane was
+// down are collapsed into one run at the latest of them.
+func (r *Runner) startDue(ctx context.Context, now time.Time) {
+	apps, err := r.store.Apps().ListAll(ctx)
+	if err != nil {
+		r.logger.Error("failed to list apps for cron services", "error", err)
+		return
+	}
+
+	for _, app := range apps {
+		var deployments []*models.Deployment
+		for i := range app.Services {
+			svc := &app.Services[i]
+			if !svc.IsCron() || svc.Cron == nil {
+				continue
+			}
+			if deployments == nil {
+				if deployments, err = r.store.Deployments().List(ctx, app.ID); err != nil {
+					r.logger.Error("failed to list deployments for cron services", "error", err, "app_id", app.ID)
+					break
+				}
+			}
+			release := LatestRelease(deployments, svc.Name)
+			if release == nil {
+				continue
+			}
+
+			since := release.CreatedAt
+			last, err := r.store.CronRuns().GetLatest(ctx, app.ID, svc.Name, models.CronRunTriggerSchedule)
+			if err != nil {
+				r.logger.Error("failed to get last cron run", "error", err, "app_id", app.ID, "service_name", svc.Name)
+				continue
+			}
+			if last != nil && last.ScheduledAt.After(since) {
+				since = last.ScheduledAt
+			}
+			due := latestDue(svc.Cron, since, now)
+			if due.IsZero() {
+				continue
+			}
+
+			run, err := Start(ctx, r.store, svc, release, models.CronRunTriggerSchedule, "", due)
+			switch {
+			case errors.Is(err, ErrRunActive):
+				r.logger.Warn("cron run skipped, previous run still active",
+					"app_id", app.ID, "service_name", svc.Name, "scheduled_at", due)
+			case err != nil:
+				r.logger.Error("failed to start cron run", "error", err, "app_id", app.ID, "service_name", svc.Name)
+			default:
+				r.logger.Info("cron run started",
+					"app_id", app.ID,
+					"service_name", svc.Name,
+					"run_id", run.ID,
+					"deployment_id", run.DeploymentID,
+					"scheduled_at", due,
+				)
+			}
+		}
+	}
+}
+
+// latestDue returns the latest time after since and not after now that the
+// schedule fires, or the zero time if it does not fire in between.
+func latestDue(cron *models.CronConfig, since, now time.Time) time.Time {
+	var due time.Time
+	for next := cron.Next(since); !next.IsZero() && !next.After(now); next = cron.Next(next) {
+		due = next
+	}
+	return due
+}
+
+// enforceTimeouts stops active runs that have taken longer than their
+// service's timeout, counted from when they started or, if they never did,
+// from when they were created.
+func (r *Runner) enforceTimeouts(ctx context.Context, now time.Time) {
+	runs, err := r.store.CronRuns().ListActive(ctx)
+	if err != nil {
+		r.logger.Error("failed to list active cron runs", "error", err)
+		return
+	}
+
+	timeouts := make(map[string]time.Duration)
+	for _, run := range runs {
+		key := run.AppID + "/" + run.ServiceName
+		timeout, ok := timeouts[key]
+		if !ok {
+			timeout = r.timeout(ctx, run)
+			timeouts[key] = timeout
+		}
+
+		start := run.CreatedAt
+		if run.StartedAt != nil {
+			start = *run.StartedAt
+		}
+		if now.Sub(start) <= timeout {
+			continue
+		}
+
+		r.stopRun(ctx, run)
+		run.Status = models.CronRunStatusTimedOut
+		run.FinishedAt = &now
+		run.Error = fmt.Sprintf("exceeded timeout of %s", timeout)
+		if err := r.store.CronRuns().Update(ctx, run); err != nil {
+			r.logger.Error("failed to record cron run timeout", "error", err, "run_id", run.ID)
+			continue
+		}
+		r.logger.Warn("cron run timed out",
+			"app_id", run.AppID,
+			"service_name", run.ServiceName,
+			"run_id", run.ID,
+			"timeout", timeout,
+		)
+	}
+}
+
+// timeout returns how long a run of the service may take.
+func (r *Runner) timeout(ctx context.Context, run *models.CronRun) time.Duration {
+	app, err := r.store.Apps().Get(ctx, run.AppID)
+	if err == nil && app != nil {
+		for i := range app.Services {
+			if app.Services[i].Name == run.ServiceName && app.Services[i].Cron != nil {
+				return app.Services[i].Cron.Timeout()
+			}
+		}
+	}
+	return models.DefaultCronTimeoutSeconds * time.Second
+}
+
+// stopRun stops a run's deployment: on its node if it was placed on one,
+// otherwise by failing it so the scheduler does not start it.
+func (r *Runner) stopRun(ctx context.Context, run *models.CronRun) {
+	if run.DeploymentID == "" {
+		return
+	}
+	deployment, err := r.store.Deployments().Get(ctx, run.DeploymentID)
+	if err != nil || deployment == nil {
+		return
+	}
+
+	if deployment.NodeID != "" && r.stopper != nil {
+		if err := r.stopper.Stop(ctx, deployment.NodeID, deployment.ID); err != nil {
+			r.logger.Error("failed to stop timed out cron run", "error", err, "run_id", run.ID, "node_id", deployment.NodeID)
+		}
+		return
+	}
+	if deployment.Status == models.DeploymentStatusBuilt {
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.UpdatedAt = r.now()
+		if err := r.store.Deployments().Update(ctx, deployment); err != nil {
+			r.logger.Error("failed to cancel timed out cron run", "error", err, "run_id", run.ID)
+		}
+	}
+}
+
+// DeploymentStatusChanged records the progress of a run when an agent
+// reports the status of its deployment: running, then succeeded when the
+// command exited 0 and the container stopped, or failed.
+func (r *Runner) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if !deployment.IsCronRun() || deployment.Status == previous {
+		return
+	}
+	run, err := r.store.CronRuns().GetByDeployment(ctx, deployment.ID)
+	if err != nil {
+		r.logger.Error("failed to get cron run", "error", err, "deployment_id", deployment.ID)
+		return
+	}
+	if run == nil || run.Status.Finished() {
+		return
+	}
+
+	now := r.now()
+	switch deployment.Status {
+	case models.DeploymentStatusStarting, models.DeploymentStatusRunning:
+		if run.Status == models.CronRunStatusRunning {
+			return
+		}
+		run.Status = models.CronRunStatusRunning
+		run.StartedAt = deployment.StartedAt
+		if run.StartedAt == nil {
+			run.StartedAt = &now
+		}
+	case models.DeploymentStatusStopped:
+		run.Status = models.CronRunStatusSucceeded
+		run.FinishedAt = &now
+	case models.DeploymentStatusFailed:
+		run.Status = models.CronRunStatusFailed
+		run.FinishedAt = &now
+		run.Error = "command exited with an error; see the run's deployment logs"
+	default:
+		return
+	}
+	if run.StartedAt == nil && run.FinishedAt != nil {
+		run.StartedAt = deployment.StartedAt
+	}
+
+	if err := r.store.CronRuns().Update(ctx, run); err != nil {
+		r.logger.Error("failed to update cron run", "error", err, "run_id", run.ID)
+		return
+	}
+	r.logger.Info("cron run status changed",
+		"app_id", run.AppID,
+		"service_name", run.ServiceName,
+		"run_id", run.ID,
+		"status", run.Status,
+	)
+}
@@ -0,0 +1,273 @@
+package cronjobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the runner uses.
+type memStore struct {
+	store.Store
+	apps        []*models.App
+	deployments map[string]*models.Deployment
+	runs        []*models.CronRun
+}
+
+func newMemStore(apps ...*models.App) *memStore {
+	return &memStore{apps: apps, deployments: make(map[string]*models.Deployment)}
+}
+
+func (s *memStore) Apps() store.AppStore               { return memApps{s: s} }
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) CronRuns() store.CronRunStore       { return memRuns{s: s} }
+
+func (s *memStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(s)
+}
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (m memApps) ListAll(ctx context.Context) ([]*models.App, error) { return m.s.apps, nil }
+
+func (m memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	for _, app := range m.s.apps {
+		if app.ID == id {
+			return app, nil
+		}
+	}
+	return nil, nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Create(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments[d.ID] = d
+	return nil
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	return m.s.deployments[id], nil
+}
+
+func (m memDeployments) Update(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments[d.ID] = d
+	return nil
+}
+
+func (m memDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.AppID == appID {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+	return result, nil
+}
+
+func (m memDeployments) GetNextVersion(ctx context.Context, appID, serviceName string) (int, error) {
+	next := 1
+	for _, d := range m.s.deployments {
+		if d.AppID == appID && d.ServiceName == serviceName && d.Version >= next {
+			next = d.Version + 1
+		}
+	}
+	return next, nil
+}
+
+type memRuns struct {
+	store.CronRunStore
+	s *memStore
+}
+
+func (m memRuns) Create(ctx context.Context, run *models.CronRun) error {
+	run.ID = fmt.Sprintf("run-%d", len(m.s.runs)+1)
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+	m.s.runs = append(m.s.runs, run)
+	return nil
+}
+
+func (m memRuns) Update(ctx context.Context, run *models.CronRun) error { return nil }
+
+func (m memRuns) GetByDeployment(ctx context.Context, deploymentID string) (*models.CronRun, error) {
+	for _, run := range m.s.runs {
+		if run.DeploymentID == deploymentID {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m memRuns) GetLatest(ctx context.Context, appID, serviceName string, trigger models.CronRunTrigger) (*models.CronRun, error) {
+	var latest *models.CronRun
+	for _, run := range m.s.runs {
+		if run.AppID == appID && run.ServiceName == serviceName && run.Trigger == trigger &&
+			(latest == nil || run.ScheduledAt.After(latest.ScheduledAt)) {
+			latest = run
+		}
+	}
+	return latest, nil
+}
+
+func (m memRuns) ListActive(ctx context.Context) ([]*models.CronRun, error) {
+	var result []*models.CronRun
+	for _, run := range m.s.runs {
+		if !run.Status.Finished() {
+			result = append(result, run)
+		}
+	}
+	return result, nil
+}
+
+type stopCall struct{ nodeID, deploymentID string }
+
+type recordingStopper struct{ calls []stopCall }
+
+func (s *recordingStopper) Stop(ctx context.Context, nodeID, deploymentID string) error {
+	s.calls = append(s.calls, stopCall{nodeID, deploymentID})
+	return nil
+}
+
+// at returns 2026-01-05 00:mm UTC.
+func at(minute int) time.Time {
+	return time.Date(2026, 1, 5, 0, minute, 0, 0, time.UTC)
+}
+
+func cronApp(timeoutSeconds int) *models.App {
+	return &models.App{
+		ID: "app-1",
+		Services: []models.ServiceConfig{
+			{Name: "web", Replicas: 1},
+			{Name: "cleanup", Type: models.ServiceTypeCron, Cron: &models.CronConfig{
+				Schedule:       "*/10 * * * *",
+				Command:        []string{"./cleanup", "--older-than", "7d"},
+				TimeoutSeconds: timeoutSeconds,
+			}},
+		},
+	}
+}
+
+func release() *models.Deployment {
+	return &models.Deployment{
+		ID: "release-1", AppID: "app-1", ServiceName: "cleanup", Version: 1,
+		Artifact: "/nix/store/abc-cleanup", Status: models.DeploymentStatusStopped,
+		Config:    &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: 8080}}},
+		CreatedAt: at(3),
+	}
+}
+
+func TestRunnerStartsDueRuns(t *testing.T) {
+	st := newMemStore(cronApp(0))
+	st.deployments["release-1"] = release()
+	r := NewRunner(st, nil, DefaultConfig(), nil)
+	ctx := context.Background()
+
+	// 00:10 and 00:20 were missed; only the latest starts
+	r.now = func() time.Time { return at(25) }
+	r.RunOnce(ctx)
+	if len(st.runs) != 1 {
+		t.Fatalf("runs = %d, want 1", len(st.runs))
+	}
+	run := st.runs[0]
+	if !run.ScheduledAt.Equal(at(20)) || run.Status != models.CronRunStatusPending || run.Trigger != models.CronRunTriggerSchedule {
+		t.Errorf("run = %+v, want pending scheduled run at 00:20", run)
+	}
+	d := st.deployments[run.DeploymentID]
+	if d == nil || d.Status != models.DeploymentStatusBuilt || d.Version != 2 || !d.IsCronRun() {
+		t.Fatalf("run deployment = %+v, want built run version 2", d)
+	}
+	if d.Artifact != "/nix/store/abc-cleanup" || len(d.Config.Ports) != 0 || d.Config.Command[0] != "./cleanup" {
+		t.Errorf("run deployment config = %+v, want release artifact, command and no ports", d.Config)
+	}
+
+	// Nothing is due before 00:30
+	r.now = func() time.Time { return at(26) }
+	r.RunOnce(ctx)
+	if len(st.runs) != 1 {
+		t.Fatalf("runs = %d after 00:26, want 1", len(st.runs))
+	}
+
+	// The first run is still active at 00:30, so that run is skipped
+	r.now = func() time.Time { return at(31) }
+	r.RunOnce(ctx)
+	if len(st.runs) != 2 || st.runs[1].Status != models.CronRunStatusSkipped || st.runs[1].DeploymentID != "" {
+		t.Fatalf("runs = %+v, want a skipped run at 00:30", st.runs)
+	}
+
+	// The agent reports the run's deployment starting and exiting
+	d.Status = models.DeploymentStatusRunning
+	r.DeploymentStatusChanged(ctx, d, models.DeploymentStatusScheduled)
+	if run.Status != models.CronRunStatusRunning || run.StartedAt == nil {
+		t.Errorf("run = %+v, want running", run)
+	}
+	d.Status = models.DeploymentStatusStopped
+	r.DeploymentStatusChanged(ctx, d, models.DeploymentStatusRunning)
+	if run.Status != models.CronRunStatusSucceeded || run.FinishedAt == nil {
+		t.Errorf("run = %+v, want succeeded", run)
+	}
+
+	// With no run active, the next scheduled time starts a run
+	r.now = func() time.Time { return at(40) }
+	r.RunOnce(ctx)
+	if len(st.runs) != 3 || st.runs[2].Status != models.CronRunStatusPending {
+		t.Fatalf("runs = %+v, want a pending run at 00:40", st.runs)
+	}
+}
+
+func TestRunnerEnforcesTimeouts(t *testing.T) {
+	st := newMemStore(cronApp(300))
+	st.deployments["release-1"] = release()
+	stopper := &recordingStopper{}
+	r := NewRunner(st, stopper, DefaultConfig(), nil)
+	ctx := context.Background()
+
+	r.now = func() time.Time { return at(10) }
+	r.RunOnce(ctx)
+	if len(st.runs) != 1 {
+		t.Fatalf("runs = %d, want 1", len(st.runs))
+	}
+	run := st.runs[0]
+	d := st.deployments[run.DeploymentID]
+	d.NodeID = "node-1"
+	d.Status = models.DeploymentStatusRunning
+	r.DeploymentStatusChanged(ctx, d, models.DeploymentStatusStarting)
+	started := at(10)
+	run.StartedAt = &started
+
+	// Within the 5 minute timeout
+	r.now = func() time.Time { return at(15) }
+	r.enforceTimeouts(ctx, r.now())
+	if run.Status != models.CronRunStatusRunning || len(stopper.calls) != 0 {
+		t.Fatalf("run = %+v, stops = %v, want still running", run, stopper.calls)
+	}
+
+	r.now = func() time.Time { return at(16) }
+	r.enforceTimeouts(ctx, r.now())
+	if run.Status != models.CronRunStatusTimedOut || run.FinishedAt == nil {
+		t.Errorf("run = %+v, want timed out", run)
+	}
+	if len(stopper.calls) != 1 || stopper.calls[0] != (stopCall{"node-1", d.ID}) {
+		t.Errorf("stops = %v, want the run's deployment stopped on node-1", stopper.calls)
+	}
+
+	// The agent then reports the container stopped; the timeout stands
+	d.Status = models.DeploymentStatusStopped
+	r.DeploymentStatusChanged(ctx, d, models.DeploymentStatusRunning)
+	if run.Status != models.CronRunStatusTimedOut {
+		t.Errorf("run status = %s after stop, want timed_out", run.Status)
+	}
+}
@@ -0,0 +1,142 @@
+package cronjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Errors returned when a run cannot be started.
+var (
+	ErrNotCron   = errors.New("service is not a cron service")
+	ErrNoRelease = errors.New("service has no deployed artifact to run; deploy it first")
+	ErrRunActive = errors.New("an earlier run of the service is still active")
+)
+
+// LatestRelease returns the most recent deployment of a service whose
+// artifact its runs start from: a successful build or rollback rather than a
+// run. deployments are ordered newest first, as DeploymentStore.List returns
+// them. It returns nil if the service has not been deployed.
+func LatestRelease(deployments []*models.Deployment, serviceName string) *models.Deployment {
+	for _, d := range deployments {
+		if d.ServiceName != serviceName || d.Artifact == "" || d.IsCronRun() {
+			continue
+		}
+		if d.Status == models.DeploymentStatusFailed {
+			continue
+		}
+		return d
+	}
+	return nil
+}
+
+// Start starts a run of a cron service from its release: a deployment of
+// the release's artifact that executes the service's command, placed on a
+// node by the scheduler like any other built deployment. If an earlier run
+// is still active no run starts and ErrRunActive is returned; scheduled runs
+// are then recorded as skipped so the history shows the missed time.
+func Start(ctx context.Context, st store.Store, svc *models.ServiceConfig, release *models.Deployment,
+	trigger models.CronRunTrigger, triggeredBy string, scheduledAt time.Time) (*models.CronRun, error) {
+	if !svc.IsCron() || svc.Cron == nil {
+		return nil, ErrNotCron
+	}
+	if release == nil {
+		return nil, ErrNoRelease
+	}
+
+	run := &models.CronRun{
+		AppID:       release.AppID,
+		ServiceName: svc.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      models.CronRunStatusPending,
+		ScheduledAt: scheduledAt,
+	}
+
+	active, err := activeRun(ctx, st, release.AppID, svc.Name)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		if trigger != models.CronRunTriggerSchedule {
+			return nil, ErrRunActive
+		}
+		now := time.Now()
+		run.Status = models.CronRunStatusSkipped
+		run.FinishedAt = &now
+		run.Error = fmt.Sprintf("run %s was still active", active.ID)
+		if err := st.CronRuns().Create(ctx, run); err != nil {
+			return nil, fmt.Errorf("recording skipped run: %w", err)
+		}
+		return run, ErrRunActive
+	}
+
+	err = st.WithTx(ctx, func(tx store.Store) error {
+		version, err := tx.Deployments().GetNextVersion(ctx, release.AppID, svc.Name)
+		if err != nil {
+			return fmt.Errorf("getting next version: %w", err)
+		}
+		deployment := runDeployment(release, svc, version)
+		if err := tx.Deployments().Create(ctx, deployment); err != nil {
+			return fmt.Errorf("creating run deployment: %w", err)
+		}
+		run.DeploymentID = deployment.ID
+		if err := tx.CronRuns().Create(ctx, run); err != nil {
+			return fmt.Errorf("creating run: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// runDeployment describes a run: the release with the service's command.
+func runDeployment(release *models.Deployment, svc *models.ServiceConfig, version int) *models.Deployment {
+	config := &models.RuntimeConfig{}
+	if release.Config != nil {
+		*config = *release.Config
+	}
+	config.Command = append([]string(nil), svc.Cron.Command...)
+	// Runs exit when the command completes; nothing probes or routes to them
+	config.HealthCheck = nil
+	config.Ports = nil
+
+	now := time.Now()
+	return &models.Deployment{
+		ID:          uuid.New().String(),
+		AppID:       release.AppID,
+		ServiceName: release.ServiceName,
+		Version:     version,
+		GitRef:      release.GitRef,
+		GitCommit:   release.GitCommit,
+		BuildType:   release.BuildType,
+		Artifact:    release.Artifact,
+		Status:      models.DeploymentStatusBuilt,
+		Resources:   release.Resources,
+		Config:      config,
+		DependsOn:   release.DependsOn,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// activeRun returns the service's pending or running run, if any.
+func activeRun(ctx context.Context, st store.Store, appID, serviceName string) (*models.CronRun, error) {
+	runs, err := st.CronRuns().ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing active runs: %w", err)
+	}
+	for _, run := range runs {
+		if run.AppID == appID && run.ServiceName == serviceName {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
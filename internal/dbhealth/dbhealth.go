@@ -0,0 +1,253 @@
+// Package dbhealth keeps the control plane's own database healthy. It
+// deletes build queue jobs and log rows nothing refers to any more, vacuums
+// bloated tables, records table sizes and reports size, growth and bloat with
+// advice on reclaiming space.
+package dbhealth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+const (
+	// bloatDeadRatio is the share of dead rows above which a table is bloated.
+	bloatDeadRatio = 0.2
+	// bloatMinDeadRows keeps small tables from being reported as bloated.
+	bloatMinDeadRows = 10000
+	// repackDeadRatio is the share of dead rows above which vacuuming alone
+	// leaves too much unused space, and rebuilding the table is advised.
+	repackDeadRatio = 0.5
+	// logDeleteBatch is how many orphaned log rows are deleted per statement.
+	logDeleteBatch = 5000
+)
+
+// Config controls database maintenance.
+type Config struct {
+	// Interval is how often maintenance runs. Zero disables it.
+	Interval time.Duration
+	// OrphanGrace is how long rows must have been orphaned, or have existed
+	// for queue jobs, before they are deleted.
+	OrphanGrace time.Duration
+	// Vacuum runs VACUUM (ANALYZE) on bloated tables during maintenance.
+	Vacuum bool
+	// TrendWindow is the period table growth is averaged over.
+	TrendWindow time.Duration
+	// SnapshotRetention is how long table size snapshots are kept.
+	SnapshotRetention time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Interval:          6 * time.Hour,
+		OrphanGrace:       24 * time.Hour,
+		Vacuum:            true,
+		TrendWindow:       7 * 24 * time.Hour,
+		SnapshotRetention: 90 * 24 * time.Hour,
+	}
+}
+
+// Maintainer runs database maintenance and reports the database's health.
+type Maintainer struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	lastRun *models.DBMaintenanceRun
+}
+
+// NewMaintainer creates a database maintainer.
+func NewMaintainer(st store.Store, cfg Config, logger *slog.Logger) *Maintainer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Maintainer{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run runs maintenance every interval until ctx is cancelled. It returns
+// immediately when maintenance is disabled.
+func (m *Maintainer) Run(ctx context.Context) {
+	if m.config.Interval <= 0 {
+		return
+	}
+	m.RunOnce(ctx)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes orphaned queue jobs and log rows, records table sizes and
+// vacuums bloated tables. Failed steps are logged and reported in the run;
+// later steps still run.
+func (m *Maintainer) RunOnce(ctx context.Context) *models.DBMaintenanceRun {
+	db := m.store.DBHealth()
+	run := &models.DBMaintenanceRun{StartedAt: m.now()}
+	fail := func(step string, err error) {
+		m.logger.Error("database maintenance step failed", "step", step, "error", err)
+		run.Errors = append(run.Errors, fmt.Sprintf("%s: %v", step, err))
+	}
+	before := run.StartedAt.Add(-m.config.OrphanGrace)
+
+	if n, err := db.DeleteOrphanedQueueJobs(ctx, before); err != nil {
+		fail("queue jobs", err)
+	} else {
+		run.DeletedQueueJobs = n
+	}
+
+	for ctx.Err() == nil {
+		n, err := db.DeleteOrphanedLogs(ctx, before, logDeleteBatch)
+		if err != nil {
+			fail("logs", err)
+			break
+		}
+		run.DeletedLogs += n
+		if n < logDeleteBatch {
+			break
+		}
+	}
+
+	tables, err := db.TableStats(ctx)
+	if err != nil {
+		fail("table statistics", err)
+	} else {
+		if err := db.RecordSizes(ctx, tables, run.StartedAt); err != nil {
+			fail("table sizes", err)
+		}
+		if m.config.SnapshotRetention > 0 {
+			if _, err := db.DeleteSizesBefore(ctx, run.StartedAt.Add(-m.config.SnapshotRetention)); err != nil {
+				fail("table size snapshots", err)
+			}
+		}
+		if m.config.Vacuum {
+			for _, t := range tables {
+				if !bloated(t) {
+					continue
+				}
+				if err := db.Vacuum(ctx, t.Table); err != nil {
+					fail("vacuum", err)
+					continue
+				}
+				run.VacuumedTables = append(run.VacuumedTables, t.Table)
+			}
+		}
+	}
+
+	run.FinishedAt = m.now()
+	m.mu.Lock()
+	m.lastRun = run
+	m.mu.Unlock()
+
+	if run.DeletedQueueJobs > 0 || run.DeletedLogs > 0 || len(run.VacuumedTables) > 0 {
+		m.logger.Info("database maintenance done",
+			"deleted_queue_jobs", run.DeletedQueueJobs,
+			"deleted_logs", run.DeletedLogs,
+			"vacuumed_tables", run.VacuumedTables,
+		)
+	}
+	return run
+}
+
+// Report returns the database's size, the size, growth and bloat of every
+// table, and the orphaned rows awaiting the next maintenance run.
+func (m *Maintainer) Report(ctx context.Context) (*models.DBHealthReport, error) {
+	db := m.store.DBHealth()
+	now := m.now()
+
+	size, err := db.DatabaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := db.TableStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := db.SizesSince(ctx, now.Add(-m.config.TrendWindow))
+	if err != nil {
+		return nil, err
+	}
+	orphans, err := db.CountOrphans(ctx, now.Add(-m.config.OrphanGrace))
+	if err != nil {
+		return nil, err
+	}
+
+	// Samples are oldest first, so the first of each table is its oldest
+	oldest := make(map[string]*models.TableSizeSample)
+	for _, s := range samples {
+		if _, ok := oldest[s.Table]; !ok {
+			oldest[s.Table] = s
+		}
+	}
+
+	report := &models.DBHealthReport{
+		DatabaseBytes: size,
+		Tables:        make([]models.TableHealth, 0, len(tables)),
+		Orphans:       *orphans,
+		TrendWindow:   m.config.TrendWindow.String(),
+		CheckedAt:     now,
+	}
+	for _, t := range tables {
+		h := models.TableHealth{
+			TableStats: *t,
+			DeadRatio:  t.DeadRatio(),
+			Bloated:    bloated(t),
+			Remedy:     m.remedy(t),
+		}
+		if s, ok := oldest[t.Table]; ok {
+			// Growth over less than an hour says little about a day
+			if elapsed := now.Sub(s.RecordedAt); elapsed >= time.Hour {
+				perDay := int64(float64(t.TotalBytes-s.TotalBytes) / elapsed.Hours() * 24)
+				h.GrowthBytesPerDay = &perDay
+			}
+		}
+		report.Tables = append(report.Tables, h)
+	}
+
+	m.mu.Lock()
+	report.LastMaintenance = m.lastRun
+	m.mu.Unlock()
+	return report, nil
+}
+
+// remedy says how to reclaim the space of a bloated table, or nothing for a
+// healthy one.
+func (m *Maintainer) remedy(t *models.TableStats) string {
+	if !bloated(t) {
+		return ""
+	}
+	if t.DeadRatio() >= repackDeadRatio {
+		return fmt.Sprintf("VACUUM makes dead rows' space reusable but does not return it to the operating system. "+
+			"Run pg_repack --table=%s to rebuild the table without blocking writes, "+
+			"or VACUUM FULL %s during a maintenance window, which locks the table.", t.Table, t.Table)
+	}
+	if m.config.Vacuum && m.config.Interval > 0 {
+		return "The next maintenance run vacuums this table."
+	}
+	return fmt.Sprintf("Run VACUUM (ANALYZE) %s, and check that autovacuum is enabled.", t.Table)
+}
+
+// bloated reports whether many of a table's rows are dead.
+func bloated(t *models.TableStats) bool {
+	return t.DeadRows >= bloatMinDeadRows && t.DeadRatio() >= bloatDeadRatio
+}
@@ -0,0 +1,159 @@
+package dbhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type memStore struct {
+	store.Store
+	db *memDB
+}
+
+func (s *memStore) DBHealth() store.DBHealthStore { return s.db }
+
+type memDB struct {
+	tables      []*models.TableStats
+	samples     []*models.TableSizeSample
+	orphanLogs  int64
+	orphanJobs  int64
+	vacuumed    []string
+	vacuumErr   error
+	orphansSeen time.Time
+}
+
+func (m *memDB) DatabaseSize(context.Context) (int64, error) { return 1 << 30, nil }
+
+func (m *memDB) TableStats(context.Context) ([]*models.TableStats, error) { return m.tables, nil }
+
+func (m *memDB) RecordSizes(_ context.Context, tables []*models.TableStats, at time.Time) error {
+	for _, t := range tables {
+		m.samples = append(m.samples, &models.TableSizeSample{Table: t.Table, TotalBytes: t.TotalBytes, RecordedAt: at})
+	}
+	return nil
+}
+
+func (m *memDB) SizesSince(_ context.Context, since time.Time) ([]*models.TableSizeSample, error) {
+	var out []*models.TableSizeSample
+	for _, s := range m.samples {
+		if !s.RecordedAt.Before(since) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memDB) DeleteSizesBefore(_ context.Context, before time.Time) (int64, error) {
+	var kept []*models.TableSizeSample
+	for _, s := range m.samples {
+		if !s.RecordedAt.Before(before) {
+			kept = append(kept, s)
+		}
+	}
+	n := int64(len(m.samples) - len(kept))
+	m.samples = kept
+	return n, nil
+}
+
+func (m *memDB) CountOrphans(_ context.Context, before time.Time) (*models.DBOrphans, error) {
+	m.orphansSeen = before
+	return &models.DBOrphans{QueueJobs: m.orphanJobs, Logs: m.orphanLogs}, nil
+}
+
+func (m *memDB) DeleteOrphanedQueueJobs(context.Context, time.Time) (int64, error) {
+	n := m.orphanJobs
+	m.orphanJobs = 0
+	return n, nil
+}
+
+func (m *memDB) DeleteOrphanedLogs(_ context.Context, _ time.Time, limit int) (int64, error) {
+	n := min(m.orphanLogs, int64(limit))
+	m.orphanLogs -= n
+	return n, nil
+}
+
+func (m *memDB) Vacuum(_ context.Context, table string) error {
+	if m.vacuumErr != nil {
+		return m.vacuumErr
+	}
+	m.vacuumed = append(m.vacuumed, table)
+	return nil
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	db := &memDB{
+		tables: []*models.TableStats{
+			{Table: "logs", TotalBytes: 1 << 20, LiveRows: 50000, DeadRows: 40000},
+			{Table: "apps", TotalBytes: 1 << 10, LiveRows: 10, DeadRows: 9},
+		},
+		samples:    []*models.TableSizeSample{{Table: "logs", RecordedAt: now.Add(-100 * 24 * time.Hour)}},
+		orphanJobs: 3,
+		orphanLogs: 2*logDeleteBatch + 7,
+	}
+	m := NewMaintainer(&memStore{db: db}, DefaultConfig(), nil)
+	m.now = func() time.Time { return now }
+
+	run := m.RunOnce(context.Background())
+	if run.DeletedQueueJobs != 3 || run.DeletedLogs != 2*logDeleteBatch+7 {
+		t.Errorf("deleted %d jobs and %d logs, want 3 and %d", run.DeletedQueueJobs, run.DeletedLogs, 2*logDeleteBatch+7)
+	}
+	// Small tables are not bloated however many of their rows are dead
+	if len(run.VacuumedTables) != 1 || run.VacuumedTables[0] != "logs" {
+		t.Errorf("vacuumed %v, want logs", run.VacuumedTables)
+	}
+	if len(db.samples) != 2 {
+		t.Errorf("%d size snapshots kept, want this run's 2", len(db.samples))
+	}
+
+	db.vacuumErr = errors.New("permission denied")
+	if run := m.RunOnce(context.Background()); len(run.Errors) != 1 {
+		t.Errorf("errors = %v, want the failed vacuum", run.Errors)
+	}
+}
+
+func TestReport(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	db := &memDB{
+		tables: []*models.TableStats{
+			{Table: "logs", TotalBytes: 3 << 20, LiveRows: 10000, DeadRows: 30000},
+			{Table: "builds", TotalBytes: 1 << 20, LiveRows: 90000, DeadRows: 30000},
+			{Table: "apps", TotalBytes: 1 << 10, LiveRows: 10},
+		},
+		samples: []*models.TableSizeSample{
+			{Table: "logs", TotalBytes: 1 << 20, RecordedAt: now.Add(-2 * 24 * time.Hour)},
+			{Table: "logs", TotalBytes: 2 << 20, RecordedAt: now.Add(-24 * time.Hour)},
+			{Table: "apps", TotalBytes: 1 << 10, RecordedAt: now.Add(-time.Minute)},
+		},
+		orphanLogs: 12,
+	}
+	m := NewMaintainer(&memStore{db: db}, DefaultConfig(), nil)
+	m.now = func() time.Time { return now }
+
+	report, err := m.Report(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Orphans.Logs != 12 || !db.orphansSeen.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("orphans = %+v counted before %s", report.Orphans, db.orphansSeen)
+	}
+
+	logs, builds, apps := report.Tables[0], report.Tables[1], report.Tables[2]
+	if logs.GrowthBytesPerDay == nil || *logs.GrowthBytesPerDay != 1<<20 {
+		t.Errorf("logs growth = %v, want 1 MiB per day", logs.GrowthBytesPerDay)
+	}
+	if apps.GrowthBytesPerDay != nil {
+		t.Errorf("growth reported from a snapshot a minute old: %d", *apps.GrowthBytesPerDay)
+	}
+	if !logs.Bloated || logs.Remedy == "" || !builds.Bloated || apps.Bloated || apps.Remedy != "" {
+		t.Errorf("bloat = logs %v, builds %v, apps %v", logs.Bloated, builds.Bloated, apps.Bloated)
+	}
+	if logs.Remedy == builds.Remedy {
+		t.Errorf("heavily bloated table advised like a lightly bloated one: %q", logs.Remedy)
+	}
+}
@@ -0,0 +1,155 @@
+// Package debugsession runs debug sessions: it starts the debug container of
+// each new session on the node of its deployment and removes it when the
+// session is ended, expires or its deployment stops running.
+package debugsession
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how often sessions are synced.
+type Config struct {
+	// PollInterval is how often active sessions are synced. Sessions start
+	// and end within one interval of being created, ended or expiring.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 5 * time.Second,
+	}
+}
+
+// Manager syncs the debug containers of active sessions with their status.
+type Manager struct {
+	store    store.Store
+	debugger scheduler.Debugger
+	config   Config
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewManager creates a debug session manager.
+func NewManager(st store.Store, debugger scheduler.Debugger, cfg Config, logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{
+		store:    st,
+		debugger: debugger,
+		config:   cfg,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Run syncs active sessions every poll interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	m.RunOnce(ctx)
+
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce starts pending sessions and ends the ones that should no longer
+// run.
+func (m *Manager) RunOnce(ctx context.Context) {
+	sessions, err := m.store.DebugSessions().ListActive(ctx)
+	if err != nil {
+		m.logger.Error("failed to list active debug sessions", "error", err)
+		return
+	}
+
+	now := m.now()
+	for _, s := range sessions {
+		reason := m.endReason(ctx, s, now)
+		switch {
+		case s.Status == models.DebugSessionPending && reason == "":
+			m.start(ctx, s, now)
+		case s.Status == models.DebugSessionPending:
+			// Nothing was started on the node
+			m.finish(ctx, s, models.DebugSessionTerminated, reason, now)
+		case reason != "" || s.Status == models.DebugSessionTerminating:
+			m.stop(ctx, s, reason, now)
+		}
+	}
+}
+
+// endReason returns why a session must end on its own, or "" if it may
+// keep running.
+func (m *Manager) endReason(ctx context.Context, s *models.DebugSession, now time.Time) string {
+	if !now.Before(s.ExpiresAt) {
+		return "session expired"
+	}
+	deployment, err := m.store.Deployments().Get(ctx, s.DeploymentID)
+	if err != nil {
+		m.logger.Error("failed to get debug session deployment", "error", err, "session_id", s.ID)
+		return ""
+	}
+	if deployment == nil || deployment.Status != models.DeploymentStatusRunning || deployment.NodeID != s.NodeID {
+		return "deployment is no longer running"
+	}
+	return ""
+}
+
+// start starts a pending session's debug container.
+func (m *Manager) start(ctx context.Context, s *models.DebugSession, now time.Time) {
+	if err := m.debugger.StartDebug(ctx, s.NodeID, s); err != nil {
+		m.logger.Error("failed to start debug session", "error", err, "session_id", s.ID, "node_id", s.NodeID)
+		m.finish(ctx, s, models.DebugSessionFailed, err.Error(), now)
+		return
+	}
+
+	s.Status = models.DebugSessionRunning
+	s.StartedAt = &now
+	if err := m.store.DebugSessions().Update(ctx, s); err != nil {
+		m.logger.Error("failed to update debug session", "error", err, "session_id", s.ID)
+		return
+	}
+	m.logger.Info("debug session started",
+		"session_id", s.ID,
+		"app_id", s.AppID,
+		"service_name", s.ServiceName,
+		"deployment_id", s.DeploymentID,
+		"node_id", s.NodeID,
+	)
+}
+
+// stop removes a session's debug container. A session whose container
+// cannot be removed fails; node agents remove it when it expires.
+func (m *Manager) stop(ctx context.Context, s *models.DebugSession, reason string, now time.Time) {
+	if err := m.debugger.StopDebug(ctx, s.NodeID, s.ID); err != nil {
+		m.logger.Error("failed to stop debug session", "error", err, "session_id", s.ID, "node_id", s.NodeID)
+		m.finish(ctx, s, models.DebugSessionFailed, err.Error(), now)
+		return
+	}
+	m.finish(ctx, s, models.DebugSessionTerminated, reason, now)
+}
+
+// finish records that a session ended.
+func (m *Manager) finish(ctx context.Context, s *models.DebugSession, status models.DebugSessionStatus, reason string, now time.Time) {
+	s.Status = status
+	s.Error = reason
+	s.EndedAt = &now
+	if err := m.store.DebugSessions().Update(ctx, s); err != nil {
+		m.logger.Error("failed to update debug session", "error", err, "session_id", s.ID)
+		return
+	}
+	m.logger.Info("debug session ended", "session_id", s.ID, "status", status, "reason", reason)
+}
@@ -0,0 +1,142 @@
+package debugsession
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the manager uses.
+type memStore struct {
+	store.Store
+	deployments map[string]*models.Deployment
+	sessions    []*models.DebugSession
+}
+
+func (s *memStore) Deployments() store.DeploymentStore     { return memDeployments{s: s} }
+func (s *memStore) DebugSessions() store.DebugSessionStore { return memSessions{s: s} }
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	return m.s.deployments[id], nil
+}
+
+type memSessions struct {
+	store.DebugSessionStore
+	s *memStore
+}
+
+func (m memSessions) ListActive(ctx context.Context) ([]*models.DebugSession, error) {
+	var active []*models.DebugSession
+	for _, s := range m.s.sessions {
+		if !s.Status.Finished() {
+			copied := *s
+			active = append(active, &copied)
+		}
+	}
+	return active, nil
+}
+
+func (m memSessions) Update(ctx context.Context, session *models.DebugSession) error {
+	for i, s := range m.s.sessions {
+		if s.ID == session.ID {
+			copied := *session
+			m.s.sessions[i] = &copied
+		}
+	}
+	return nil
+}
+
+// fakeDebugger records the sessions started and stopped through it.
+type fakeDebugger struct {
+	started  []string
+	stopped  []string
+	startErr error
+}
+
+func (f *fakeDebugger) StartDebug(ctx context.Context, nodeID string, session *models.DebugSession) error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.started = append(f.started, session.ID)
+	return nil
+}
+
+func (f *fakeDebugger) StopDebug(ctx context.Context, nodeID string, sessionID string) error {
+	f.stopped = append(f.stopped, sessionID)
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	st := &memStore{deployments: map[string]*models.Deployment{
+		"dep-1": {ID: "dep-1", NodeID: "node-1", Status: models.DeploymentStatusRunning},
+	}}
+	session := func(id string, status models.DebugSessionStatus) *models.DebugSession {
+		return &models.DebugSession{ID: id, DeploymentID: "dep-1", NodeID: "node-1", Status: status, ExpiresAt: now.Add(time.Hour)}
+	}
+	st.sessions = []*models.DebugSession{
+		session("new", models.DebugSessionPending),
+		session("ended", models.DebugSessionTerminating),
+		session("expired", models.DebugSessionRunning),
+		session("live", models.DebugSessionRunning),
+	}
+	st.sessions[2].ExpiresAt = now
+
+	debugger := &fakeDebugger{}
+	m := NewManager(st, debugger, DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.now = func() time.Time { return now }
+	m.RunOnce(context.Background())
+
+	want := map[string]models.DebugSessionStatus{
+		"new":     models.DebugSessionRunning,
+		"ended":   models.DebugSessionTerminated,
+		"expired": models.DebugSessionTerminated,
+		"live":    models.DebugSessionRunning,
+	}
+	for _, s := range st.sessions {
+		if s.Status != want[s.ID] {
+			t.Errorf("session %s status = %s, want %s", s.ID, s.Status, want[s.ID])
+		}
+	}
+	if len(debugger.started) != 1 || len(debugger.stopped) != 2 {
+		t.Errorf("started %v, stopped %v; want new started and ended and expired stopped", debugger.started, debugger.stopped)
+	}
+
+	// Sessions end with their deployment
+	st.deployments["dep-1"].Status = models.DeploymentStatusStopped
+	m.RunOnce(context.Background())
+	for _, s := range st.sessions {
+		if s.Status != models.DebugSessionTerminated {
+			t.Errorf("session %s status = %s after its deployment stopped", s.ID, s.Status)
+		}
+	}
+}
+
+func TestManagerFailsSessionsThatCannotStart(t *testing.T) {
+	now := time.Now()
+	st := &memStore{
+		deployments: map[string]*models.Deployment{
+			"dep-1": {ID: "dep-1", NodeID: "node-1", Status: models.DeploymentStatusRunning},
+		},
+		sessions: []*models.DebugSession{
+			{ID: "s1", DeploymentID: "dep-1", NodeID: "node-1", Status: models.DebugSessionPending, ExpiresAt: now.Add(time.Hour)},
+		},
+	}
+	debugger := &fakeDebugger{startErr: errors.New("image not found")}
+	NewManager(st, debugger, DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil))).RunOnce(context.Background())
+
+	if s := st.sessions[0]; s.Status != models.DebugSessionFailed || s.Error != "image not found" || s.EndedAt == nil {
+		t.Errorf("session = %+v, want failed with the start error", s)
+	}
+}
@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Binding is a connection string injected into a service's environment for
+// a database service it depends on.
+type Binding struct {
+	Variable string `json:"variable"` // e.g. DATABASE_URL or REDIS_URL
+	Service  string `json:"service"`  // The database service
+	Type     string `json:"type"`     // The database type, e.g. postgres
+	URL      string `json:"url"`
+}
+
+// Redacted returns the binding with the password in its URL masked.
+func (b Binding) Redacted() Binding {
+	if u, err := url.Parse(b.URL); err == nil {
+		b.URL = u.Redacted()
+	}
+	return b
+}
+
+// DatabaseBindings returns the connection strings of the database services a
+// service depends on, in depends_on order. They are built from the databases'
+// current credential secrets, so rotated credentials reach the dependent
+// services with their next deployment. When several databases would bind
+// the same variable, e.g. two Postgres services both binding DATABASE_URL,
+// only the first is bound; the others remain available under their prefixed
+// secrets such as ORDERS_DATABASE_URL.
+func DatabaseBindings(app *models.App, serviceName string, secrets map[string]string) []Binding {
+	service := findService(app, serviceName)
+	if service == nil {
+		return nil
+	}
+
+	var bindings []Binding
+	bound := make(map[string]bool)
+	for _, name := range service.DependsOn {
+		dep := findService(app, name)
+		if dep == nil || dep.SourceType != models.SourceTypeDatabase || dep.Database == nil {
+			continue
+		}
+		dbType := databases.DatabaseType(dep.Database.Type)
+		variable := databases.BindingVariable(dbType)
+		if variable == "" || bound[variable] {
+			continue
+		}
+		creds, ok := databases.CredentialsFromSecrets(dbType, dep.Name, secrets)
+		if !ok {
+			continue
+		}
+		bound[variable] = true
+		bindings = append(bindings, Binding{
+			Variable: variable,
+			Service:  dep.Name,
+			Type:     dep.Database.Type,
+			URL:      creds.GetConnectionURL(dbType),
+		})
+	}
+	return bindings
+}
+
+// Bindings returns the database bindings of a service of an app, with the
+// credentials decrypted.
+func (m *EnvMerger) Bindings(ctx context.Context, appID, serviceName string) ([]Binding, error) {
+	sources, err := m.loadSources(ctx, appID, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return sources.bindings, nil
+}
+
+// bindingVars returns bindings as environment variables.
+func bindingVars(bindings []Binding) map[string]string {
+	vars := make(map[string]string, len(bindings))
+	for _, b := range bindings {
+		vars[b.Variable] = b.URL
+	}
+	return vars
+}
+
+func findService(app *models.App, name string) *models.ServiceConfig {
+	for i := range app.Services {
+		if app.Services[i].Name == name {
+			return &app.Services[i]
+		}
+	}
+	return nil
+}
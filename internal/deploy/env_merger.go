@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/secrets"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
 
-// EnvMerger merges app-level secrets with service-level environment variables.
-// Service-level variables take precedence over app-level secrets when both have the same key.
+// EnvMerger merges app-level env vars and secrets, org shared secrets and the connection
+// strings of the databases a service depends on with service-level environment variables.
+// Service-level variables take precedence over app-level secrets, which take precedence over
+// database bindings, then org shared secrets and finally app-level env vars, when more than
+// one has the same key.
 // **Validates: Requirements 3.2, 6.1, 6.3**
 type EnvMerger struct {
 	store       store.Store
@@ -31,8 +35,10 @@ func NewEnvMerger(st store.Store, sopsService *secrets.SOPSService, logger *slog
 	}
 }
 
-// MergeForDeployment fetches app-level secrets (decrypted) and service-level env vars,
-// then merges them with service-level taking precedence.
+// MergeForDeployment fetches app-level env vars, org shared secrets, app secrets (decrypted)
+// and the service's database bindings and merges them with service-level env vars, the
+// service level taking precedence. Shared secrets that reach the service are recorded as
+// used by it.
 // **Validates: Requirements 6.1, 6.3**
 func (m *EnvMerger) MergeForDeployment(ctx context.Context, appID, serviceName string, serviceEnvVars map[string]string) (map[string]string, error) {
 	m.logger.Debug("merging environment variables for deployment",
@@ -40,19 +46,22 @@ func (m *EnvMerger) MergeForDeployment(ctx context.Context, appID, serviceName s
 		"service_name", serviceName,
 	)
 
-	// Start with app-level secrets (decrypted)
-	appSecrets, err := m.getDecryptedAppSecrets(ctx, appID)
+	sources, err := m.loadSources(ctx, appID, serviceName)
 	if err != nil {
-		return nil, fmt.Errorf("getting app secrets: %w", err)
+		return nil, err
 	}
 
 	// Merge with service-level env vars taking precedence
-	merged := MergeEnvVars(appSecrets, serviceEnvVars)
+	merged := sources.merge(serviceEnvVars)
+	m.recordSharedUsage(ctx, appID, serviceName, sources.sharedSecrets, sources.appSecrets, serviceEnvVars)
 
 	m.logger.Debug("environment variables merged",
 		"app_id", appID,
 		"service_name", serviceName,
-		"app_secrets_count", len(appSecrets),
+		"app_env_vars_count", len(sources.app.EnvVars),
+		"shared_secrets_count", len(sources.shared),
+		"app_secrets_count", len(sources.appSecrets),
+		"bindings_count", len(sources.bindings),
 		"service_env_vars_count", len(serviceEnvVars),
 		"merged_count", len(merged),
 	)
@@ -60,6 +69,73 @@ func (m *EnvMerger) MergeForDeployment(ctx context.Context, appID, serviceName s
 	return merged, nil
 }
 
+// ResolvedEnv is a service's merged environment.
+type ResolvedEnv struct {
+	Vars map[string]string
+	// Secrets holds the keys whose values come from app or shared secrets.
+	Secrets map[string]bool
+}
+
+// Resolve merges a service's environment the way MergeForDeployment does,
+// without recording shared secret usage, and reports which keys hold secret
+// values. It is used to run a service outside the platform.
+func (m *EnvMerger) Resolve(ctx context.Context, appID, serviceName string, serviceEnvVars map[string]string) (*ResolvedEnv, error) {
+	sources, err := m.loadSources(ctx, appID, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedEnv{Vars: sources.merge(serviceEnvVars), Secrets: make(map[string]bool)}
+	for _, values := range []map[string]string{sources.shared, bindingVars(sources.bindings), sources.appSecrets} {
+		for key := range values {
+			if _, ok := serviceEnvVars[key]; !ok {
+				resolved.Secrets[key] = true
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// envSources are the app-level values merged into a service's environment.
+type envSources struct {
+	app           *models.App
+	shared        map[string]string
+	sharedSecrets []*models.OrgSecret
+	appSecrets    map[string]string
+	bindings      []Binding
+}
+
+// loadSources fetches an app's env vars, org shared secrets and app secrets
+// (decrypted) and computes a service's database bindings from the secrets.
+func (m *EnvMerger) loadSources(ctx context.Context, appID, serviceName string) (*envSources, error) {
+	app, err := m.store.Apps().Get(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("getting app: %w", err)
+	}
+	shared, sharedSecrets, err := m.getDecryptedSharedSecrets(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("getting shared secrets: %w", err)
+	}
+	appSecrets, err := m.getDecryptedAppSecrets(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("getting app secrets: %w", err)
+	}
+	return &envSources{
+		app:           app,
+		shared:        shared,
+		sharedSecrets: sharedSecrets,
+		appSecrets:    appSecrets,
+		bindings:      DatabaseBindings(app, serviceName, appSecrets),
+	}, nil
+}
+
+// merge starts with app-level env vars, overridden by org shared secrets,
+// database bindings, app-level secrets and then the service's env vars.
+func (s *envSources) merge(serviceEnvVars map[string]string) map[string]string {
+	merged := MergeEnvVars(MergeEnvVars(s.app.EnvVars, s.shared), bindingVars(s.bindings))
+	return MergeEnvVars(MergeEnvVars(merged, s.appSecrets), serviceEnvVars)
+}
+
 // getDecryptedAppSecrets fetches and decrypts all app-level secrets.
 func (m *EnvMerger) getDecryptedAppSecrets(ctx context.Context, appID string) (map[string]string, error) {
 	// Get all encrypted secrets for the app
@@ -69,31 +145,78 @@ func (m *EnvMerger) getDecryptedAppSecrets(ctx context.Context, appID string) (m
 	}
 
 	decrypted := make(map[string]string, len(encryptedSecrets))
-
 	for key, encryptedValue := range encryptedSecrets {
-		var value string
-
-		// Decrypt the value if SOPS is configured and can decrypt
-		if m.sopsService != nil && m.sopsService.CanDecrypt() {
-			decryptedBytes, err := m.sopsService.Decrypt(ctx, encryptedValue)
-			if err != nil {
-				m.logger.Warn("failed to decrypt secret, using as-is",
-					"key", key,
-					"error", err,
-				)
-				value = string(encryptedValue)
-			} else {
-				value = string(decryptedBytes)
-			}
-		} else {
-			// No encryption configured or no private key, value is stored as plaintext
-			value = string(encryptedValue)
+		decrypted[key] = m.decrypt(ctx, key, encryptedValue)
+	}
+
+	return decrypted, nil
+}
+
+// getDecryptedSharedSecrets fetches and decrypts the org secrets shared into
+// an app, returning the values by key along with the secrets themselves.
+func (m *EnvMerger) getDecryptedSharedSecrets(ctx context.Context, app *models.App) (map[string]string, []*models.OrgSecret, error) {
+	if app.OrgID == "" {
+		return nil, nil, nil
+	}
+	orgSecrets, err := m.store.OrgSecrets().List(ctx, app.OrgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching org secrets: %w", err)
+	}
+
+	var shared []*models.OrgSecret
+	decrypted := make(map[string]string)
+	for _, secret := range orgSecrets {
+		if !secret.SharedWith(app.ID) {
+			continue
 		}
+		shared = append(shared, secret)
+		decrypted[secret.Key] = m.decrypt(ctx, secret.Key, secret.EncryptedValue)
+	}
+	return decrypted, shared, nil
+}
 
-		decrypted[key] = value
+// recordSharedUsage records which shared secrets reach a service, i.e. those
+// not overridden by an app secret or service env var. Failures are logged
+// and do not block the deployment.
+func (m *EnvMerger) recordSharedUsage(ctx context.Context, appID, serviceName string, shared []*models.OrgSecret, appSecrets, serviceEnvVars map[string]string) {
+	var usages []*models.OrgSecretUsage
+	for _, secret := range shared {
+		if _, ok := appSecrets[secret.Key]; ok {
+			continue
+		}
+		if _, ok := serviceEnvVars[secret.Key]; ok {
+			continue
+		}
+		usages = append(usages, &models.OrgSecretUsage{
+			SecretID:    secret.ID,
+			AppID:       appID,
+			ServiceName: serviceName,
+		})
+	}
+	if len(usages) == 0 {
+		return
 	}
+	if err := m.store.OrgSecrets().RecordUsage(ctx, usages); err != nil {
+		m.logger.Warn("failed to record shared secret usage", "app_id", appID, "service_name", serviceName, "error", err)
+	}
+}
 
-	return decrypted, nil
+// decrypt decrypts a secret value, falling back to the stored value when
+// SOPS is not configured or decryption fails.
+func (m *EnvMerger) decrypt(ctx context.Context, key string, encryptedValue []byte) string {
+	// No encryption configured or no private key, value is stored as plaintext
+	if m.sopsService == nil || !m.sopsService.CanDecrypt() {
+		return string(encryptedValue)
+	}
+	decryptedBytes, err := m.sopsService.Decrypt(ctx, encryptedValue)
+	if err != nil {
+		m.logger.Warn("failed to decrypt secret, using as-is",
+			"key", key,
+			"error", err,
+		)
+		return string(encryptedValue)
+	}
+	return string(decryptedBytes)
 }
 
 // MergeEnvVars merges two maps of environment variables.
@@ -0,0 +1,379 @@
+// Package doctor checks an installation for missing prerequisites and common
+// misconfigurations, and says how to fix each problem it finds.
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/pkg/config"
+)
+
+// Thresholds of the checks.
+const (
+	// checkTimeout bounds each network check.
+	checkTimeout = 5 * time.Second
+	// minSecretChars is the number of distinct characters below which a JWT
+	// secret is guessable whatever its length.
+	minSecretChars = 10
+)
+
+// placeholderSecrets are JWT secrets from examples and development defaults.
+var placeholderSecrets = map[string]bool{
+	"development-secret-key-min-32-chars":        true,
+	"your-secret-key-minimum-32-characters-long": true,
+}
+
+// Doctor runs the installation checks.
+type Doctor struct {
+	store  store.Store
+	config *config.Config
+	client *http.Client
+	logger *slog.Logger
+
+	// db and migrations let the doctor check the schema; nil skips the check
+	db         *sql.DB
+	migrations fs.FS
+	// clockSkews reports how far each node's clock is behind; nil skips the check
+	clockSkews func() map[string]time.Duration
+}
+
+// New creates a doctor for the installation configured by cfg.
+func New(st store.Store, cfg *config.Config, logger *slog.Logger) *Doctor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Doctor{
+		store:  st,
+		config: cfg,
+		client: &http.Client{Timeout: checkTimeout},
+		logger: logger,
+	}
+}
+
+// SetSchema lets the doctor check that the tables created by the migrations
+// exist in db.
+func (d *Doctor) SetSchema(db *sql.DB, migrations fs.FS) {
+	d.db = db
+	d.migrations = migrations
+}
+
+// SetClockSkews sets the source of the nodes' clock skews.
+func (d *Doctor) SetClockSkews(skews func() map[string]time.Duration) {
+	d.clockSkews = skews
+}
+
+// Run runs every check and returns their findings.
+func (d *Doctor) Run(ctx context.Context) *models.DoctorReport {
+	report := &models.DoctorReport{Status: models.DoctorSeverityOK, CheckedAt: time.Now().UTC()}
+	if !d.checkDatabase(ctx, report) {
+		return report
+	}
+	d.checkMigrations(ctx, report)
+	d.checkRegistry(ctx, report)
+	d.checkAttic(ctx, report)
+	d.checkWorkers(ctx, report)
+	d.checkClockSkew(ctx, report)
+	d.checkJWTSecret(report)
+	return report
+}
+
+// checkDatabase reports whether the database answers. The other checks need
+// it, so Run stops when it fails.
+func (d *Doctor) checkDatabase(ctx context.Context, report *models.DoctorReport) bool {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := d.store.Ping(ctx); err != nil {
+		report.Add(models.DoctorFinding{
+			Check: "database", Severity: models.DoctorSeverityError,
+			Message: fmt.Sprintf("Cannot reach the database: %v", err),
+			Remedy:  "Check that PostgreSQL is running and that DATABASE_URL points to it.",
+		})
+		return false
+	}
+	report.Add(models.DoctorFinding{Check: "database", Severity: models.DoctorSeverityOK, Message: "The database is reachable."})
+	return true
+}
+
+// createTable matches the tables created by a migration.
+var createTable = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?"?(\w+)"?`)
+
+// checkMigrations reports migrations whose tables are missing. Migrations
+// are applied with psql and not recorded, so a migration counts as applied
+// when every table it creates exists.
+func (d *Doctor) checkMigrations(ctx context.Context, report *models.DoctorReport) {
+	if d.db == nil {
+		return
+	}
+	tables, err := d.tables(ctx)
+	if err != nil {
+		report.Add(models.DoctorFinding{
+			Check: "migrations", Severity: models.DoctorSeverityWarning,
+			Message: fmt.Sprintf("Cannot list the database tables: %v", err),
+		})
+		return
+	}
+
+	names, err := fs.Glob(d.migrations, "*.sql")
+	if err != nil {
+		return
+	}
+	sort.Strings(names)
+	var missing []string
+	for _, name := range names {
+		script, err := fs.ReadFile(d.migrations, name)
+		if err != nil {
+			continue
+		}
+		for _, m := range createTable.FindAllStringSubmatch(string(script), -1) {
+			if !tables[strings.ToLower(m[1])] {
+				missing = append(missing, fmt.Sprintf("%s (table %s)", name, m[1]))
+				break
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		report.Add(models.DoctorFinding{
+			Check: "migrations", Severity: models.DoctorSeverityError,
+			Message: "Migrations are not applied: " + strings.Join(missing, ", "),
+			Remedy:  "Run make migrate-up, or apply the listed files from migrations/ with psql in order.",
+		})
+		return
+	}
+	report.Add(models.DoctorFinding{
+		Check: "migrations", Severity: models.DoctorSeverityOK,
+		Message: fmt.Sprintf("All %d migrations are applied.", len(names)),
+	})
+}
+
+// tables returns the tables of the database's current schema.
+func (d *Doctor) tables(ctx context.Context) (map[string]bool, error) {
+	rows, err := d.db.QueryContext(ctx,
+		`SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables[strings.ToLower(name)] = true
+	}
+	return tables, rows.Err()
+}
+
+// checkRegistry reports whether the registry builds push to answers the
+// registry API, over HTTPS or plain HTTP.
+func (d *Doctor) checkRegistry(ctx context.Context, report *models.DoctorReport) {
+	host, _, _ := strings.Cut(d.config.RegistryURL, "/")
+	if host == "" {
+		report.Add(models.DoctorFinding{
+			Check: "registry", Severity: models.DoctorSeverityError,
+			Message: "No image registry is configured, so OCI builds cannot be pushed.",
+			Remedy:  "Set REGISTRY_URL to the registry builds push to and nodes pull from.",
+		})
+		return
+	}
+
+	var lastErr error
+	for _, scheme := range []string{"https", "http"} {
+		status, err := d.get(ctx, scheme+"://"+host+"/v2/")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		// Registries that require credentials answer 401
+		if status == http.StatusOK || status == http.StatusUnauthorized {
+			report.Add(models.DoctorFinding{
+				Check: "registry", Severity: models.DoctorSeverityOK,
+				Message: fmt.Sprintf("The registry %s is reachable over %s.", host, strings.ToUpper(scheme)),
+			})
+			return
+		}
+		lastErr = fmt.Errorf("%s://%s/v2/ answered %d", scheme, host, status)
+	}
+	report.Add(models.DoctorFinding{
+		Check: "registry", Severity: models.DoctorSeverityError,
+		Message: fmt.Sprintf("The registry %s is not reachable: %v", host, lastErr),
+		Remedy:  "Start the registry, or set REGISTRY_URL to one the control plane and workers can reach.",
+	})
+}
+
+// checkAttic reports whether the Attic binary cache answers. Without it
+// pure-nix builds are not cached and nodes cannot fetch their closures.
+func (d *Doctor) checkAttic(ctx context.Context, report *models.DoctorReport) {
+	if d.config.AtticEndpoint == "" {
+		report.Add(models.DoctorFinding{
+			Check: "attic", Severity: models.DoctorSeverityInfo,
+			Message: "No Attic cache is configured; pure-nix builds will not be cached.",
+			Remedy:  "Set ATTIC_ENDPOINT to deploy pure-nix services.",
+		})
+		return
+	}
+	if _, err := d.get(ctx, d.config.AtticEndpoint); err != nil {
+		report.Add(models.DoctorFinding{
+			Check: "attic", Severity: models.DoctorSeverityWarning,
+			Message: fmt.Sprintf("The Attic cache at %s is not reachable: %v", d.config.AtticEndpoint, err),
+			Remedy:  "Start the Attic server or correct ATTIC_ENDPOINT; pure-nix deployments fail until it is reachable.",
+		})
+		return
+	}
+	report.Add(models.DoctorFinding{
+		Check: "attic", Severity: models.DoctorSeverityOK,
+		Message: fmt.Sprintf("The Attic cache at %s is reachable.", d.config.AtticEndpoint),
+	})
+}
+
+// checkWorkers reports whether a build worker is running and whether the
+// running workers can reach their Podman service.
+func (d *Doctor) checkWorkers(ctx context.Context, report *models.DoctorReport) {
+	workers, err := d.store.BuildWorkers().List(ctx)
+	if err != nil {
+		d.logger.Error("doctor failed to list build workers", "error", err)
+		return
+	}
+
+	now := time.Now()
+	active := 0
+	for _, w := range workers {
+		if w.StateAt(now) != models.BuildWorkerStateActive {
+			continue
+		}
+		active++
+		if w.PodmanError != "" {
+			report.Add(models.DoctorFinding{
+				Check: "podman", Severity: models.DoctorSeverityError,
+				Message: fmt.Sprintf("Build worker %s on %s cannot reach Podman: %s", w.ID, w.Hostname, w.PodmanError),
+				Remedy:  "Start the Podman socket on the worker (systemctl --user enable --now podman.socket) or set PODMAN_SOCKET to it.",
+			})
+		}
+	}
+
+	if active == 0 {
+		report.Add(models.DoctorFinding{
+			Check: "workers", Severity: models.DoctorSeverityWarning,
+			Message: "No build worker is running, so builds stay queued.",
+			Remedy:  "Start a worker with make dev-worker or bin/worker.",
+		})
+		return
+	}
+	report.Add(models.DoctorFinding{
+		Check: "workers", Severity: models.DoctorSeverityOK,
+		Message: fmt.Sprintf("%d build worker(s) running.", active),
+	})
+}
+
+// checkClockSkew reports nodes whose clocks drift from the control plane's.
+func (d *Doctor) checkClockSkew(ctx context.Context, report *models.DoctorReport) {
+	if d.clockSkews == nil {
+		return
+	}
+	skews := d.clockSkews()
+	if len(skews) == 0 {
+		return
+	}
+
+	hostnames := make(map[string]string)
+	if nodes, err := d.store.Nodes().List(ctx); err == nil {
+		for _, n := range nodes {
+			hostnames[n.ID] = n.Hostname
+		}
+	}
+
+	ids := make([]string, 0, len(skews))
+	for id := range skews {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	drifting := 0
+	for _, id := range ids {
+		skew := skews[id].Abs()
+		if skew < models.ClockSkewWarning {
+			continue
+		}
+		drifting++
+		severity := models.DoctorSeverityWarning
+		if skew >= models.ClockSkewCritical {
+			severity = models.DoctorSeverityError
+		}
+		name := id
+		if hostname := hostnames[id]; hostname != "" {
+			name = fmt.Sprintf("%s (%s)", hostname, id)
+		}
+		report.Add(models.DoctorFinding{
+			Check: "clock_skew", Severity: severity,
+			Message: fmt.Sprintf("The clock of node %s is off by %s.", name, skew.Round(time.Second)),
+			Remedy:  "Enable time synchronization on the node, e.g. timedatectl set-ntp true.",
+		})
+	}
+	if drifting == 0 {
+		report.Add(models.DoctorFinding{
+			Check: "clock_skew", Severity: models.DoctorSeverityOK,
+			Message: fmt.Sprintf("The clocks of %d node(s) agree with the control plane.", len(skews)),
+		})
+	}
+}
+
+// checkJWTSecret reports JWT secrets that are placeholders or guessable.
+func (d *Doctor) checkJWTSecret(report *models.DoctorReport) {
+	secret := d.config.JWTSecret
+	const remedy = "Set JWT_SECRET to a random value, e.g. the output of openssl rand -hex 32. Existing sessions are signed out."
+
+	chars := make(map[rune]bool)
+	for _, c := range secret {
+		chars[c] = true
+	}
+	switch {
+	case placeholderSecrets[secret]:
+		report.Add(models.DoctorFinding{
+			Check: "jwt_secret", Severity: models.DoctorSeverityError,
+			Message: "JWT_SECRET is an example value, so anyone can sign session tokens.",
+			Remedy:  remedy,
+		})
+	case len(secret) < 32:
+		report.Add(models.DoctorFinding{
+			Check: "jwt_secret", Severity: models.DoctorSeverityError,
+			Message: "JWT_SECRET is shorter than 32 characters.",
+			Remedy:  remedy,
+		})
+	case len(chars) < minSecretChars:
+		report.Add(models.DoctorFinding{
+			Check: "jwt_secret", Severity: models.DoctorSeverityWarning,
+			Message: fmt.Sprintf("JWT_SECRET uses only %d distinct characters and may be guessable.", len(chars)),
+			Remedy:  remedy,
+		})
+	default:
+		report.Add(models.DoctorFinding{Check: "jwt_secret", Severity: models.DoctorSeverityOK, Message: "JWT_SECRET is strong."})
+	}
+}
+
+// get requests url and returns the response status.
+func (d *Doctor) get(ctx context.Context, url string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/pkg/config"
+)
+
+type doctorWorkers struct {
+	store.BuildWorkerStore
+	workers []*models.BuildWorker
+}
+
+func (s *doctorWorkers) List(ctx context.Context) ([]*models.BuildWorker, error) {
+	return s.workers, nil
+}
+
+type doctorNodes struct {
+	store.NodeStore
+	nodes []*models.Node
+}
+
+func (s *doctorNodes) List(ctx context.Context) ([]*models.Node, error) { return s.nodes, nil }
+
+type doctorStore struct {
+	store.Store
+	pingErr error
+	workers *doctorWorkers
+	nodes   *doctorNodes
+}
+
+func (s *doctorStore) Ping(ctx context.Context) error       { return s.pingErr }
+func (s *doctorStore) BuildWorkers() store.BuildWorkerStore { return s.workers }
+func (s *doctorStore) Nodes() store.NodeStore               { return s.nodes }
+
+// findings returns the severity of each check in the report, keyed by check
+// and, for repeated checks, the last one.
+func findings(report *models.DoctorReport) map[string]models.DoctorSeverity {
+	result := make(map[string]models.DoctorSeverity)
+	for _, f := range report.Findings {
+		result[f.Check] = f.Severity
+	}
+	return result
+}
+
+func newTestDoctor(t *testing.T, st *doctorStore) *Doctor {
+	t.Helper()
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(registry.Close)
+
+	cfg := &config.Config{
+		RegistryURL: strings.TrimPrefix(registry.URL, "http://") + "/narvana",
+		JWTSecret:   "4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c",
+	}
+	return New(st, cfg, nil)
+}
+
+func TestDoctorHealthyInstallation(t *testing.T) {
+	st := &doctorStore{
+		workers: &doctorWorkers{workers: []*models.BuildWorker{
+			{ID: "w1", LeaseSeconds: 60, LastHeartbeatAt: time.Now()},
+		}},
+		nodes: &doctorNodes{},
+	}
+	d := newTestDoctor(t, st)
+	d.SetClockSkews(func() map[string]time.Duration { return map[string]time.Duration{"node-1": 2 * time.Second} })
+
+	report := d.Run(context.Background())
+	got := findings(report)
+	for _, check := range []string{"database", "registry", "workers", "clock_skew", "jwt_secret"} {
+		if got[check] != models.DoctorSeverityOK {
+			t.Errorf("%s = %q, want ok", check, got[check])
+		}
+	}
+	// No Attic endpoint only disables pure-nix caching
+	if got["attic"] != models.DoctorSeverityInfo || report.Status != models.DoctorSeverityInfo {
+		t.Errorf("attic = %q, status = %q; want info", got["attic"], report.Status)
+	}
+}
+
+func TestDoctorFindsMisconfigurations(t *testing.T) {
+	st := &doctorStore{
+		workers: &doctorWorkers{workers: []*models.BuildWorker{
+			{ID: "w1", Hostname: "builder", LeaseSeconds: 60, LastHeartbeatAt: time.Now(), PodmanError: "connection refused"},
+		}},
+		nodes: &doctorNodes{nodes: []*models.Node{{ID: "node-1", Hostname: "edge-1"}}},
+	}
+	d := newTestDoctor(t, st)
+	d.config.RegistryURL = "127.0.0.1:1"
+	d.config.JWTSecret = "development-secret-key-min-32-chars"
+	d.SetClockSkews(func() map[string]time.Duration { return map[string]time.Duration{"node-1": -10 * time.Minute} })
+
+	report := d.Run(context.Background())
+	got := findings(report)
+	for _, check := range []string{"registry", "podman", "clock_skew", "jwt_secret"} {
+		if got[check] != models.DoctorSeverityError {
+			t.Errorf("%s = %q, want error", check, got[check])
+		}
+	}
+	if report.Status != models.DoctorSeverityError {
+		t.Errorf("status = %q, want error", report.Status)
+	}
+	for _, f := range report.Findings {
+		if f.Severity == models.DoctorSeverityError && f.Remedy == "" {
+			t.Errorf("%s finding has no remedy", f.Check)
+		}
+		if f.Check == "clock_skew" && !strings.Contains(f.Message, "edge-1") {
+			t.Errorf("clock skew finding %q does not name the node", f.Message)
+		}
+	}
+}
+
+func TestDoctorStopsWithoutDatabase(t *testing.T) {
+	d := newTestDoctor(t, &doctorStore{pingErr: errors.New("connection refused")})
+	report := d.Run(context.Background())
+	if len(report.Findings) != 1 || report.Findings[0].Check != "database" || report.Status != models.DoctorSeverityError {
+		t.Errorf("report = %+v, want only the database error", report)
+	}
+}
@@ -0,0 +1,246 @@
+// Package drift detects changes made to apps outside their app spec, such as
+// manual scaling or edited secrets, by comparing each app's live state to the
+// manifest last applied to it.
+package drift
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxSummarized is how many changes a drift notification names.
+const maxSummarized = 5
+
+// pastTense describes the change actions as done.
+var pastTense = map[string]string{
+	appspec.ActionAdd:    "added",
+	appspec.ActionUpdate: "updated",
+	appspec.ActionRemove: "removed",
+}
+
+// Notifier queues notification events.
+type Notifier interface {
+	Notify(ctx context.Context, event *models.NotificationEvent)
+}
+
+// Config controls how often drift is checked.
+type Config struct {
+	// Interval is how often every manifest is compared to its app.
+	Interval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{Interval: 10 * time.Minute}
+}
+
+// Detector periodically records the drift of every app with a manifest and
+// notifies when an app starts drifting.
+type Detector struct {
+	store    store.Store
+	config   Config
+	notifier Notifier
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewDetector creates a drift detector.
+func NewDetector(st store.Store, cfg Config, logger *slog.Logger) *Detector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Detector{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetNotifier sets where app.drift events are sent.
+func (d *Detector) SetNotifier(n Notifier) {
+	d.notifier = n
+}
+
+// Run checks for drift every interval until ctx is cancelled.
+func (d *Detector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce records the drift of every app with a manifest.
+func (d *Detector) RunOnce(ctx context.Context) {
+	manifests, err := d.store.AppManifests().List(ctx)
+	if err != nil {
+		d.logger.Error("failed to list app manifests", "error", err)
+		return
+	}
+	for _, m := range manifests {
+		if err := d.check(ctx, m); err != nil {
+			d.logger.Error("failed to check app drift", "error", err, "app_id", m.AppID)
+		}
+	}
+}
+
+// check records an app's drift, notifying if the app had none before.
+func (d *Detector) check(ctx context.Context, m *models.AppManifest) error {
+	changes, err := Detect(ctx, d.store, m)
+	if err != nil {
+		return err
+	}
+	if err := d.store.AppManifests().UpdateDrift(ctx, m.AppID, changes, d.now()); err != nil {
+		return err
+	}
+	if len(m.Drift) == 0 && len(changes) > 0 && d.notifier != nil {
+		d.notifier.Notify(ctx, &models.NotificationEvent{
+			Type:    models.NotificationAppDrift,
+			AppID:   m.AppID,
+			Message: fmt.Sprintf("Changed outside its app spec: %s. Revert to the manifest or accept the changes as its new baseline.", Summarize(changes)),
+		})
+	}
+	return nil
+}
+
+// Detect returns the changes made to an app since its manifest was applied:
+// settings of the services, env vars and domains the spec manages, and the
+// app's secrets.
+func Detect(ctx context.Context, st store.Store, m *models.AppManifest) ([]models.AppSpecChange, error) {
+	app, err := st.Apps().Get(ctx, m.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("getting app: %w", err)
+	}
+	domains, err := st.Domains().List(ctx, m.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("listing domains: %w", err)
+	}
+	spec, err := appspec.Parse([]byte(m.Spec))
+	if err == nil {
+		err = spec.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The app may have been renamed since the spec was applied
+	spec.App = ""
+	plan, err := appspec.Converge(app, domains, spec, Options(m))
+	if err != nil {
+		return nil, err
+	}
+
+	// The plan reverts the changes; drift describes them as they were made
+	changes := make([]models.AppSpecChange, 0, len(plan.Changes))
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case appspec.ActionAdd:
+			c.Action = appspec.ActionRemove
+		case appspec.ActionRemove:
+			c.Action = appspec.ActionAdd
+		}
+		c.From, c.To = c.To, c.From
+		changes = append(changes, c)
+	}
+
+	secrets, err := st.Secrets().GetAll(ctx, m.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("listing secrets: %w", err)
+	}
+	return append(changes, diffSecrets(m.SecretDigests, SecretDigests(secrets))...), nil
+}
+
+// Options returns the options the manifest's spec was applied with.
+func Options(m *models.AppManifest) appspec.Options {
+	resources := m.Resources
+	if resources == nil {
+		resources = models.DefaultResourceSpec()
+	}
+	return appspec.Options{Prune: m.Prune, Resources: resources}
+}
+
+// Save records a manifest as its app's baseline, with digests of the app's
+// current secrets.
+func Save(ctx context.Context, st store.Store, m *models.AppManifest) error {
+	secrets, err := st.Secrets().GetAll(ctx, m.AppID)
+	if err != nil {
+		return fmt.Errorf("listing secrets: %w", err)
+	}
+	m.SecretDigests = SecretDigests(secrets)
+	return st.AppManifests().Save(ctx, m)
+}
+
+// SecretDigests returns the SHA-256 digest of each stored secret value, by
+// key. Values are compared as stored, so setting a secret again to the same
+// value is a change when it is encrypted anew.
+func SecretDigests(secrets map[string][]byte) map[string]string {
+	digests := make(map[string]string, len(secrets))
+	for key, value := range secrets {
+		sum := sha256.Sum256(value)
+		digests[key] = hex.EncodeToString(sum[:])
+	}
+	return digests
+}
+
+// diffSecrets compares secret digests by key.
+func diffSecrets(baseline, current map[string]string) []models.AppSpecChange {
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range baseline {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []models.AppSpecChange
+	for _, key := range keys {
+		was, had := baseline[key]
+		is, has := current[key]
+		switch {
+		case !had:
+			changes = append(changes, models.AppSpecChange{Action: appspec.ActionAdd, Kind: appspec.KindSecret, Name: key})
+		case !has:
+			changes = append(changes, models.AppSpecChange{Action: appspec.ActionRemove, Kind: appspec.KindSecret, Name: key})
+		case was != is:
+			changes = append(changes, models.AppSpecChange{Action: appspec.ActionUpdate, Kind: appspec.KindSecret, Name: key})
+		}
+	}
+	return changes
+}
+
+// Summarize describes changes in a line, e.g. "service web replicas
+// updated, secret API_KEY added".
+func Summarize(changes []models.AppSpecChange) string {
+	parts := make([]string, 0, maxSummarized+1)
+	for i, c := range changes {
+		if i == maxSummarized {
+			parts = append(parts, fmt.Sprintf("and %d more", len(changes)-i))
+			break
+		}
+		what := c.Kind + " " + c.Name
+		if len(c.Fields) > 0 {
+			what += " " + strings.Join(c.Fields, ", ")
+		}
+		parts = append(parts, what+" "+pastTense[c.Action])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,131 @@
+package drift
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/appspec"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type memStore struct {
+	store.Store
+	app       *models.App
+	secrets   map[string][]byte
+	manifests *memManifests
+}
+
+func (s *memStore) Apps() store.AppStore                 { return memApps{s: s} }
+func (s *memStore) Domains() store.DomainStore           { return memDomains{} }
+func (s *memStore) Secrets() store.SecretStore           { return memSecrets{s: s} }
+func (s *memStore) AppManifests() store.AppManifestStore { return s.manifests }
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (a memApps) Get(context.Context, string) (*models.App, error) { return a.s.app, nil }
+
+type memDomains struct{ store.DomainStore }
+
+func (memDomains) List(context.Context, string) ([]*models.Domain, error) { return nil, nil }
+
+type memSecrets struct {
+	store.SecretStore
+	s *memStore
+}
+
+func (m memSecrets) GetAll(context.Context, string) (map[string][]byte, error) {
+	return m.s.secrets, nil
+}
+
+type memManifests struct {
+	manifest *models.AppManifest
+}
+
+func (m *memManifests) Get(context.Context, string) (*models.AppManifest, error) {
+	return m.manifest, nil
+}
+
+func (m *memManifests) List(context.Context) ([]*models.AppManifest, error) {
+	copied := *m.manifest
+	return []*models.AppManifest{&copied}, nil
+}
+
+func (m *memManifests) Save(_ context.Context, manifest *models.AppManifest) error {
+	m.manifest = manifest
+	return nil
+}
+
+func (m *memManifests) UpdateDrift(_ context.Context, _ string, drift []models.AppSpecChange, at time.Time) error {
+	m.manifest.Drift, m.manifest.DriftCheckedAt = drift, &at
+	return nil
+}
+
+type notifier struct{ events []*models.NotificationEvent }
+
+func (n *notifier) Notify(_ context.Context, event *models.NotificationEvent) {
+	n.events = append(n.events, event)
+}
+
+func TestDetector(t *testing.T) {
+	const doc = "version: 1\nenv:\n  MODE: prod\nservices:\n  - name: web\n    git_repo: github.com/acme/shop\n"
+	spec, err := appspec.Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &models.AppManifest{AppID: "app-1", Spec: doc}
+	plan, err := appspec.Converge(&models.App{Name: "shop"}, nil, spec, Options(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &memStore{
+		app:       &models.App{ID: "app-1", Name: "shop", Services: plan.Services, EnvVars: plan.Env},
+		secrets:   map[string][]byte{"API_KEY": []byte("a"), "OLD": []byte("b")},
+		manifests: &memManifests{},
+	}
+	if err := Save(context.Background(), st, manifest); err != nil {
+		t.Fatal(err)
+	}
+	n := &notifier{}
+	d := NewDetector(st, DefaultConfig(), nil)
+	d.SetNotifier(n)
+
+	d.RunOnce(context.Background())
+	if drift := st.manifests.manifest.Drift; len(drift) != 0 || len(n.events) != 0 {
+		t.Fatalf("drift right after applying: %+v", drift)
+	}
+
+	// Scale by hand and edit secrets
+	st.app.Services[0].Replicas = 3
+	st.app.EnvVars = nil
+	st.secrets = map[string][]byte{"API_KEY": []byte("c"), "NEW": []byte("d")}
+	d.RunOnce(context.Background())
+	want := []models.AppSpecChange{
+		{Action: "update", Kind: "service", Name: "web", Fields: []string{"replicas"}},
+		{Action: "remove", Kind: "env", Name: "MODE"},
+		{Action: "update", Kind: "secret", Name: "API_KEY"},
+		{Action: "add", Kind: "secret", Name: "NEW"},
+		{Action: "remove", Kind: "secret", Name: "OLD"},
+	}
+	if drift := st.manifests.manifest.Drift; !reflect.DeepEqual(drift, want) {
+		t.Errorf("drift = %+v\nwant %+v", drift, want)
+	}
+	if len(n.events) != 1 || n.events[0].Type != models.NotificationAppDrift {
+		t.Fatalf("events = %+v, want one app.drift event", n.events)
+	}
+	if msg := n.events[0].Message; msg != "Changed outside its app spec: service web replicas updated, env MODE removed, secret API_KEY updated, secret NEW added, secret OLD removed. Revert to the manifest or accept the changes as its new baseline." {
+		t.Errorf("message = %q", msg)
+	}
+
+	// Known drift is not notified again
+	d.RunOnce(context.Background())
+	if len(n.events) != 1 {
+		t.Errorf("%d events after checking known drift, want 1", len(n.events))
+	}
+}
@@ -0,0 +1,299 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// TransitionKind is the kind of state a Transition changed.
+type TransitionKind string
+
+const (
+	// TransitionBuildFinished is published when a build reaches a
+	// terminal status.
+	TransitionBuildFinished TransitionKind = "build.finished"
+	// TransitionDeploymentStatus is published when a deployment's status
+	// changes as reported by a node agent or a backend.
+	TransitionDeploymentStatus TransitionKind = "deployment.status"
+	// TransitionNodeHealth is published when a node's heartbeats lapse or
+	// resume, and when its clock or agent certificate condition changes.
+	TransitionNodeHealth TransitionKind = "node.health"
+)
+
+// Transition is a change of state published on a Bus. Subscribers get a
+// copy of the objects as they were when it was published and must not
+// modify them.
+type Transition struct {
+	Kind TransitionKind
+	// Build is set on build transitions.
+	Build *models.BuildJob
+	// Deployment and PreviousStatus are set on deployment transitions.
+	Deployment     *models.Deployment
+	PreviousStatus models.DeploymentStatus
+	// Node is set on node transitions. HealthEvent is set when the node's
+	// heartbeats lapsed or resumed, PreviousNode when its conditions
+	// changed, with the conditions of both nodes updated.
+	Node         *models.Node
+	PreviousNode *models.Node
+	HealthEvent  *models.NodeHealthEvent
+	Time         time.Time
+}
+
+// Publisher publishes transitions. Bus implements it; components take a
+// Publisher so tests can record what they publish.
+type Publisher interface {
+	Publish(t *Transition)
+}
+
+// Handler handles the transitions it was subscribed to. A transition it
+// returns an error for is delivered again, so handling one must be
+// idempotent or tolerate duplicates.
+type Handler interface {
+	HandleTransition(ctx context.Context, t *Transition) error
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ctx context.Context, t *Transition) error
+
+// HandleTransition calls f.
+func (f HandlerFunc) HandleTransition(ctx context.Context, t *Transition) error {
+	return f(ctx, t)
+}
+
+// DeploymentNotifier is told about deployment status changes. Its problems
+// are its own to log, so deliveries to it are never retried.
+type DeploymentNotifier interface {
+	DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus)
+}
+
+// OnDeploymentStatus adapts a DeploymentNotifier to a Handler of deployment
+// transitions.
+func OnDeploymentStatus(n DeploymentNotifier) Handler {
+	return HandlerFunc(func(ctx context.Context, t *Transition) error {
+		if t.Kind == TransitionDeploymentStatus {
+			n.DeploymentStatusChanged(ctx, t.Deployment, t.PreviousStatus)
+		}
+		return nil
+	})
+}
+
+// BusConfig controls how transitions are delivered.
+type BusConfig struct {
+	// MaxPending is how many transitions a subscriber may have waiting.
+	// Beyond that its oldest are dropped, so a subscriber that keeps
+	// failing cannot exhaust memory.
+	MaxPending int
+	// BaseBackoff is the wait before delivering a transition again after
+	// the first failure. It doubles with each further failure, up to
+	// MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultBusConfig returns a BusConfig with sensible defaults.
+func DefaultBusConfig() BusConfig {
+	return BusConfig{
+		MaxPending:  10000,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+	}
+}
+
+// Bus delivers the state transitions of builds, deployments and nodes to
+// the subscribers that act on them, such as notifications, app hooks, the
+// audit log and /v1/events. Each subscriber gets every transition it
+// subscribed to at least once, in the order they were published, from a
+// goroutine of its own, so a slow or failing subscriber holds up neither
+// the publisher nor other subscribers. Transitions not yet delivered when
+// the bus stops are lost.
+type Bus struct {
+	config BusConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu            sync.Mutex
+	subscriptions []*subscription
+}
+
+// subscription is a handler and the transitions waiting for it.
+type subscription struct {
+	name    string
+	handler Handler
+	kinds   map[TransitionKind]bool
+
+	mu      sync.Mutex
+	pending []*Transition
+	wake    chan struct{}
+}
+
+// NewBus creates a bus.
+func NewBus(cfg BusConfig, logger *slog.Logger) *Bus {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Bus{config: cfg, logger: logger, now: time.Now}
+}
+
+// Subscribe delivers the transitions of the given kinds, or of every kind
+// if none are given, to h. The name identifies the subscriber in logs.
+// Subscribers must be added before the bus is run.
+func (b *Bus) Subscribe(name string, h Handler, kinds ...TransitionKind) {
+	sub := &subscription{name: name, handler: h, wake: make(chan struct{}, 1)}
+	if len(kinds) > 0 {
+		sub.kinds = make(map[TransitionKind]bool, len(kinds))
+		for _, k := range kinds {
+			sub.kinds[k] = true
+		}
+	}
+	b.mu.Lock()
+	b.subscriptions = append(b.subscriptions, sub)
+	b.mu.Unlock()
+}
+
+// Publish queues a transition for its subscribers. It never blocks. The
+// objects of the transition are copied, so the publisher may go on
+// changing them.
+func (b *Bus) Publish(t *Transition) {
+	published := *t
+	if published.Time.IsZero() {
+		published.Time = b.now()
+	}
+	if t.Build != nil {
+		build := *t.Build
+		published.Build = &build
+	}
+	if t.Deployment != nil {
+		deployment := *t.Deployment
+		published.Deployment = &deployment
+	}
+	if t.Node != nil {
+		node := *t.Node
+		published.Node = &node
+	}
+	if t.PreviousNode != nil {
+		node := *t.PreviousNode
+		published.PreviousNode = &node
+	}
+	if t.HealthEvent != nil {
+		event := *t.HealthEvent
+		published.HealthEvent = &event
+	}
+
+	b.mu.Lock()
+	subscriptions := b.subscriptions
+	b.mu.Unlock()
+	for _, sub := range subscriptions {
+		if sub.kinds != nil && !sub.kinds[published.Kind] {
+			continue
+		}
+		sub.mu.Lock()
+		if len(sub.pending) >= b.config.MaxPending {
+			b.logger.Error("dropping transition for subscriber that fell behind",
+				"subscriber", sub.name, "kind", sub.pending[0].Kind)
+			sub.pending = sub.pending[1:]
+		}
+		sub.pending = append(sub.pending, &published)
+		sub.mu.Unlock()
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run delivers transitions to the subscribers until ctx is done.
+func (b *Bus) Run(ctx context.Context) {
+	b.mu.Lock()
+	subscriptions := b.subscriptions
+	b.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, sub := range subscriptions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.deliver(ctx, sub)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver hands a subscriber its transitions one at a time, retrying each
+// until the subscriber handles it.
+func (b *Bus) deliver(ctx context.Context, sub *subscription) {
+	for {
+		sub.mu.Lock()
+		var next *Transition
+		if len(sub.pending) > 0 {
+			next = sub.pending[0]
+		}
+		sub.mu.Unlock()
+
+		if next == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.wake:
+				continue
+			}
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := handle(ctx, sub.handler, next)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				sub.mu.Lock()
+				pending := len(sub.pending)
+				sub.mu.Unlock()
+				b.logger.Warn("stopped with transitions undelivered", "subscriber", sub.name, "pending", pending)
+				return
+			}
+			delay := b.backoff(attempt)
+			b.logger.Warn("subscriber failed to handle transition, retrying",
+				"subscriber", sub.name, "kind", next.Kind, "attempt", attempt, "retry_in", delay, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		sub.mu.Lock()
+		// The transition may have been dropped while it was handled
+		if len(sub.pending) > 0 && sub.pending[0] == next {
+			sub.pending = sub.pending[1:]
+		}
+		sub.mu.Unlock()
+	}
+}
+
+// handle calls a handler, turning a panic into an error so the
+// transition is delivered again rather than taking the process down.
+func handle(ctx context.Context, h Handler, t *Transition) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.HandleTransition(ctx, t)
+}
+
+// backoff returns the wait before a transition's next delivery after it
+// failed attempt times.
+func (b *Bus) backoff(attempt int) time.Duration {
+	delay := b.config.BaseBackoff
+	for i := 1; i < attempt && delay < b.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > b.config.MaxBackoff {
+		delay = b.config.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,163 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// recorder records the transitions it handles, failing the first failures
+// deliveries.
+type recorder struct {
+	mu       sync.Mutex
+	handled  []*Transition
+	attempts int
+	failures int
+	panics   bool
+}
+
+func (r *recorder) HandleTransition(ctx context.Context, t *Transition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.attempts <= r.failures {
+		if r.panics {
+			panic("subscriber bug")
+		}
+		return errors.New("store unavailable")
+	}
+	r.handled = append(r.handled, t)
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.handled)
+}
+
+func testBusConfig() BusConfig {
+	return BusConfig{MaxPending: 10, BaseBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+}
+
+// runBus runs b until the test ends.
+func runBus(t *testing.T, b *Bus) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitFor(t *testing.T, r *recorder, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for r.count() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("handled %d transitions, want %d", r.count(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func deploymentTransition(status models.DeploymentStatus) *Transition {
+	return &Transition{Kind: TransitionDeploymentStatus, Deployment: &models.Deployment{ID: "dep-1", Status: status}}
+}
+
+func TestBusDeliversInOrderByKind(t *testing.T) {
+	b := NewBus(testBusConfig(), nil)
+	all, builds := &recorder{}, &recorder{}
+	b.Subscribe("all", all)
+	b.Subscribe("builds", builds, TransitionBuildFinished)
+	runBus(t, b)
+
+	b.Publish(deploymentTransition(models.DeploymentStatusStarting))
+	b.Publish(&Transition{Kind: TransitionBuildFinished, Build: &models.BuildJob{ID: "build-1"}})
+	b.Publish(deploymentTransition(models.DeploymentStatusRunning))
+
+	waitFor(t, all, 3)
+	waitFor(t, builds, 1)
+	if got := all.handled[2].Deployment.Status; got != models.DeploymentStatusRunning || all.handled[0].Deployment.Status != models.DeploymentStatusStarting {
+		t.Errorf("delivered out of order: %s last", got)
+	}
+	if builds.handled[0].Kind != TransitionBuildFinished || builds.count() != 1 {
+		t.Errorf("builds subscriber got %+v", builds.handled)
+	}
+	if all.handled[0].Time.IsZero() {
+		t.Error("transition has no time")
+	}
+}
+
+func TestBusRetriesFailedDeliveries(t *testing.T) {
+	for _, panics := range []bool{false, true} {
+		b := NewBus(testBusConfig(), nil)
+		flaky, healthy := &recorder{failures: 3, panics: panics}, &recorder{}
+		b.Subscribe("flaky", flaky)
+		b.Subscribe("healthy", healthy)
+		runBus(t, b)
+
+		b.Publish(deploymentTransition(models.DeploymentStatusRunning))
+		b.Publish(deploymentTransition(models.DeploymentStatusStopped))
+
+		waitFor(t, healthy, 2)
+		waitFor(t, flaky, 2)
+		if flaky.attempts != 5 {
+			t.Errorf("panics %v: %d attempts, want 5", panics, flaky.attempts)
+		}
+		if flaky.handled[0].Deployment.Status != models.DeploymentStatusRunning {
+			t.Errorf("panics %v: a later transition was delivered before a failed one", panics)
+		}
+	}
+}
+
+func TestBusPublishCopies(t *testing.T) {
+	b := NewBus(testBusConfig(), nil)
+	r := &recorder{}
+	b.Subscribe("r", r)
+
+	d := &models.Deployment{ID: "dep-1", Status: models.DeploymentStatusStarting}
+	b.Publish(&Transition{Kind: TransitionDeploymentStatus, Deployment: d})
+	d.Status = models.DeploymentStatusRunning
+	runBus(t, b)
+
+	waitFor(t, r, 1)
+	if got := r.handled[0].Deployment.Status; got != models.DeploymentStatusStarting {
+		t.Errorf("delivered status %s, want the one published", got)
+	}
+}
+
+func TestBusDropsOldestBeyondMaxPending(t *testing.T) {
+	b := NewBus(BusConfig{MaxPending: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, nil)
+	r := &recorder{}
+	b.Subscribe("r", r)
+
+	for _, s := range []models.DeploymentStatus{models.DeploymentStatusStarting, models.DeploymentStatusRunning, models.DeploymentStatusStopped} {
+		b.Publish(deploymentTransition(s))
+	}
+	runBus(t, b)
+
+	waitFor(t, r, 2)
+	if r.handled[0].Deployment.Status != models.DeploymentStatusRunning {
+		t.Errorf("first delivered %s, want the oldest dropped", r.handled[0].Deployment.Status)
+	}
+}
+
+func TestBusBackoff(t *testing.T) {
+	b := NewBus(BusConfig{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}, nil)
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := b.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
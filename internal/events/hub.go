@@ -0,0 +1,201 @@
+// Package events carries state changes to the components acting on them.
+//
+// A Bus delivers the transitions of builds, deployments and nodes, as they
+// are made, to in-process subscribers such as notifications, app hooks and
+// the audit log. A Hub streams the changes of apps, services, deployments
+// and builds to the subscribers of /v1/events, so pages update without
+// being refreshed. Those changes arrive as Postgres notifications, whichever
+// process made them, and the latest are kept so clients reconnecting with
+// the last event they saw miss none.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how many events are kept and how subscribers are served.
+type Config struct {
+	// Retained is how many of the latest events are kept for subscribers
+	// resuming a stream.
+	Retained int
+	// SubscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped; it then resumes from the retained events.
+	SubscriberBuffer int
+	// RetryInterval is how long to wait before listening again after the
+	// database connection is lost.
+	RetryInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Retained:         1000,
+		SubscriberBuffer: 100,
+		RetryInterval:    5 * time.Second,
+	}
+}
+
+// Hub fans events out to subscribers.
+type Hub struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	seq         int64
+	retained    []*models.Event
+	subscribers map[*Subscriber]struct{}
+}
+
+// Subscriber receives events on C until it is unsubscribed or falls too
+// far behind, when C is closed.
+type Subscriber struct {
+	C  <-chan *models.Event
+	ch chan *models.Event
+}
+
+// NewHub creates a hub publishing the events of st.
+func NewHub(st store.Store, cfg Config, logger *slog.Logger) *Hub {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Hub{
+		store:       st,
+		config:      cfg,
+		logger:      logger,
+		now:         time.Now,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Run publishes the store's events until ctx is done, listening again when
+// the connection delivering them is lost. Subscribers are sent a reset
+// event once events may have been missed.
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		err := h.store.Events().Listen(ctx, h.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Warn("lost event notifications, listening again", "error", err, "retry_in", h.config.RetryInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.config.RetryInterval):
+		}
+		h.Publish(&models.Event{Kind: models.EventKindReset})
+	}
+}
+
+// Publish numbers an event, keeps it for resuming subscribers and sends it
+// to every subscriber. Subscribers too far behind to take it are dropped.
+func (h *Hub) Publish(event *models.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event.Seq = h.seq
+	if event.Time.IsZero() {
+		event.Time = h.now()
+	}
+	h.retained = append(h.retained, event)
+	if over := len(h.retained) - h.config.Retained; over > 0 {
+		h.retained = append(h.retained[:0:0], h.retained[over:]...)
+	}
+
+	for sub := range h.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			h.logger.Debug("dropping event subscriber that fell behind", "seq", event.Seq)
+			delete(h.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe subscribes to events published from now on. A subscriber
+// resuming a stream passes the sequence number of the last event it saw as
+// after, or -1 for none, and is also returned the events it missed. ok is
+// false if some of those are no longer kept, or after is from before the
+// hub started, in which case the subscriber should reload its state.
+func (h *Hub) Subscribe(after int64) (sub *Subscriber, missed []*models.Event, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan *models.Event, h.config.SubscriberBuffer)
+	sub = &Subscriber{C: ch, ch: ch}
+	h.subscribers[sub] = struct{}{}
+
+	if after < 0 || after == h.seq {
+		return sub, nil, true
+	}
+	if after > h.seq || len(h.retained) == 0 || h.retained[0].Seq > after+1 {
+		return sub, nil, false
+	}
+	for _, event := range h.retained {
+		if event.Seq > after {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed, true
+}
+
+// HandleTransition streams the health transitions of nodes, which are not
+// announced by Postgres notifications, as node events.
+func (h *Hub) HandleTransition(ctx context.Context, t *Transition) error {
+	if t.Kind != TransitionNodeHealth || t.Node == nil {
+		return nil
+	}
+	event := &models.Event{
+		Kind:   models.EventKindNode,
+		Action: models.EventActionUpdated,
+		ID:     t.Node.ID,
+		Name:   t.Node.Hostname,
+		Time:   t.Time,
+	}
+	switch {
+	case t.HealthEvent != nil:
+		event.Status, event.PreviousStatus = "unhealthy", "healthy"
+		if t.HealthEvent.Healthy {
+			event.Status, event.PreviousStatus = "healthy", "unhealthy"
+		}
+	case t.PreviousNode != nil:
+		event.Status = string(nodeCondition(t.Node))
+		event.PreviousStatus = string(nodeCondition(t.PreviousNode))
+		if event.Status == event.PreviousStatus {
+			return nil
+		}
+	default:
+		return nil
+	}
+	h.Publish(event)
+	return nil
+}
+
+// nodeCondition returns the worse of a node's clock and certificate
+// conditions.
+func nodeCondition(n *models.Node) models.NodeCondition {
+	if n.CertificateStatus.Worse(n.ClockStatus) {
+		return n.CertificateStatus
+	}
+	return n.ClockStatus
+}
+
+// Unsubscribe stops sending events to sub.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// listenStore is a store providing only events. Each call to Listen
+// delivers the next batch of events and then fails, or blocks until ctx is
+// done once no batches are left.
+type listenStore struct {
+	store.Store
+	batches [][]*models.Event
+	calls   int
+}
+
+func (s *listenStore) Events() store.EventStore { return s }
+
+func (s *listenStore) Listen(ctx context.Context, fn func(*models.Event)) error {
+	s.calls++
+	if len(s.batches) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	for _, e := range batch {
+		fn(e)
+	}
+	return errors.New("connection lost")
+}
+
+func testConfig() Config {
+	return Config{Retained: 3, SubscriberBuffer: 2, RetryInterval: time.Millisecond}
+}
+
+func deployment(status string) *models.Event {
+	return &models.Event{Kind: models.EventKindDeployment, Action: models.EventActionUpdated, ID: "dep-1", Status: status}
+}
+
+func TestPublishNumbersAndDelivers(t *testing.T) {
+	h := NewHub(nil, testConfig(), nil)
+	sub, missed, ok := h.Subscribe(-1)
+	if !ok || len(missed) != 0 {
+		t.Fatalf("Subscribe(-1) = %d missed, ok %v; want none, true", len(missed), ok)
+	}
+
+	h.Publish(deployment("building"))
+	h.Publish(deployment("running"))
+
+	for want := int64(1); want <= 2; want++ {
+		e := <-sub.C
+		if e.Seq != want {
+			t.Errorf("Seq = %d, want %d", e.Seq, want)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %d has no time", e.Seq)
+		}
+	}
+}
+
+func TestSubscribeResumes(t *testing.T) {
+	h := NewHub(nil, testConfig(), nil)
+	for _, status := range []string{"pending", "building", "built", "deploying", "running"} {
+		h.Publish(deployment(status))
+	}
+
+	tests := []struct {
+		name     string
+		after    int64
+		wantSeqs []int64
+		wantOK   bool
+	}{
+		{"no replay", -1, nil, true},
+		{"up to date", 5, nil, true},
+		{"missed retained events", 2, []int64{3, 4, 5}, true},
+		{"missed dropped events", 1, nil, false},
+		{"from before restart", 9, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, ok := h.Subscribe(tt.after)
+			defer h.Unsubscribe(sub)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(missed) != len(tt.wantSeqs) {
+				t.Fatalf("missed %d events, want %d", len(missed), len(tt.wantSeqs))
+			}
+			for i, e := range missed {
+				if e.Seq != tt.wantSeqs[i] {
+					t.Errorf("missed[%d].Seq = %d, want %d", i, e.Seq, tt.wantSeqs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h := NewHub(nil, testConfig(), nil)
+	slow, _, _ := h.Subscribe(-1)
+	fast, _, _ := h.Subscribe(-1)
+
+	for i := 0; i < 3; i++ {
+		h.Publish(deployment("running"))
+		<-fast.C
+	}
+
+	var got int
+	for range slow.C {
+		got++
+	}
+	if got != 2 {
+		t.Errorf("slow subscriber got %d events before being dropped, want 2", got)
+	}
+	if _, ok := h.subscribers[fast]; !ok {
+		t.Error("subscriber keeping up was dropped")
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	h := NewHub(nil, testConfig(), nil)
+	sub, _, _ := h.Subscribe(-1)
+	h.Unsubscribe(sub)
+	h.Unsubscribe(sub)
+
+	if _, open := <-sub.C; open {
+		t.Error("channel still open after Unsubscribe")
+	}
+	h.Publish(deployment("running"))
+}
+
+func TestRunResetsAfterReconnecting(t *testing.T) {
+	st := &listenStore{batches: [][]*models.Event{{deployment("building")}}}
+	h := NewHub(st, testConfig(), nil)
+	sub, _, _ := h.Subscribe(-1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+
+	if e := <-sub.C; e.Kind != models.EventKindDeployment {
+		t.Errorf("first event kind = %s, want deployment", e.Kind)
+	}
+	if e := <-sub.C; e.Kind != models.EventKindReset {
+		t.Errorf("event after reconnecting kind = %s, want reset", e.Kind)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	if st.calls != 2 {
+		t.Errorf("Listen called %d times, want 2", st.calls)
+	}
+}
+
+func TestHandleTransitionStreamsNodeHealth(t *testing.T) {
+	h := NewHub(nil, testConfig(), nil)
+	sub, _, _ := h.Subscribe(-1)
+	ctx := context.Background()
+
+	node := &models.Node{ID: "node-1", Hostname: "worker-1"}
+	transitions := []*Transition{
+		{Kind: TransitionNodeHealth, Node: node, HealthEvent: &models.NodeHealthEvent{NodeID: "node-1"}},
+		// Only the clock got worse, which the certificate already was
+		{Kind: TransitionNodeHealth,
+			Node:         &models.Node{ID: "node-1", ClockStatus: models.NodeConditionWarning, CertificateStatus: models.NodeConditionCritical},
+			PreviousNode: &models.Node{ID: "node-1", ClockStatus: models.NodeConditionOK, CertificateStatus: models.NodeConditionCritical}},
+		{Kind: TransitionDeploymentStatus, Deployment: &models.Deployment{ID: "dep-1"}},
+	}
+	for _, tr := range transitions {
+		if err := h.HandleTransition(ctx, tr); err != nil {
+			t.Fatalf("HandleTransition() = %v", err)
+		}
+	}
+
+	e := <-sub.C
+	if e.Kind != models.EventKindNode || e.ID != "node-1" || e.Name != "worker-1" || e.Status != "unhealthy" || e.PreviousStatus != "healthy" {
+		t.Errorf("event = %+v, want node-1 unhealthy", e)
+	}
+	select {
+	case e := <-sub.C:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,464 @@
+// Package evidence compiles evidence packages for compliance audits such as
+// SOC 2: the deployments of an app over a date range with who started and
+// approved them, its audit log, the provenance of its builds and access to
+// its workloads. A package is a gzipped tar archive whose manifest lists the
+// SHA-256 of every file and is signed by the control plane in a DSSE
+// envelope, so auditors can check nothing was changed after export.
+package evidence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// PayloadType is the DSSE payload type of signed package manifests.
+const PayloadType = "application/vnd.narvana.evidence-manifest+json"
+
+// Files of a package.
+const (
+	ManifestFile    = "manifest.json"
+	SignatureFile   = "manifest.sig.json"
+	DeploymentsFile = "deployments.json"
+	AuditFile       = "audit_entries.json"
+	ProvenanceFile  = "provenance.json"
+	AccessFile      = "access.json"
+)
+
+// maxRecords caps the records of each kind read for a package.
+const maxRecords = 10000
+
+// Verification errors.
+var (
+	ErrMissingFile  = errors.New("package is missing a file")
+	ErrFileModified = errors.New("file does not match the manifest")
+)
+
+// Manifest describes a package and the files in it.
+type Manifest struct {
+	AppID       string    `json:"app_id"`
+	AppName     string    `json:"app_name"`
+	OrgID       string    `json:"org_id,omitempty"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy string    `json:"generated_by,omitempty"`
+	KeyID       string    `json:"key_id"`
+	Files       []File    `json:"files"`
+}
+
+// File is a file of a package with its digest.
+type File struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Deployment is a deployment with who started it and who approved it.
+type Deployment struct {
+	*models.Deployment
+	BuildID     string     `json:"build_id,omitempty"`
+	TriggeredBy string     `json:"triggered_by,omitempty"`
+	Approvals   []Approval `json:"approvals"`
+}
+
+// Approval kinds.
+const (
+	ApprovalPromotion      = "promotion"
+	ApprovalFreezeOverride = "freeze_override"
+	ApprovalOnCall         = "on_call"
+)
+
+// Approval records someone allowing a deployment: approving the promotion
+// that created it, overriding a deploy freeze for it, or acknowledging it as
+// on call outside business hours.
+type Approval struct {
+	Kind        string    `json:"kind"`
+	ID          string    `json:"id"`
+	ApprovedBy  string    `json:"approved_by"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Provenance is the signed provenance of a deployment's build.
+type Provenance struct {
+	DeploymentID string `json:"deployment_id"`
+	*models.BuildAttestation
+}
+
+// Access kinds.
+const (
+	AccessDebugSession = "debug_session"
+	AccessNodeSSH      = "node_ssh"
+)
+
+// Access records someone reaching the app's workloads: a debug container
+// started next to a deployment, or an SSH session to a node running one.
+type Access struct {
+	Kind         string     `json:"kind"`
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	ServiceName  string     `json:"service_name,omitempty"`
+	DeploymentID string     `json:"deployment_id,omitempty"`
+	NodeID       string     `json:"node_id"`
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	// Detail is the debug image or the SSH client's address
+	Detail string `json:"detail,omitempty"`
+}
+
+// Package is a compiled evidence package.
+type Package struct {
+	Manifest Manifest
+	Archive  []byte
+	SHA256   string
+	// Counts is the number of records of each kind
+	Counts map[string]int
+}
+
+// Compiler compiles evidence packages from the store.
+type Compiler struct {
+	store  store.Store
+	signer *provenance.Signer
+	now    func() time.Time
+}
+
+// NewCompiler creates a compiler signing manifests with signer.
+func NewCompiler(st store.Store, signer *provenance.Signer) *Compiler {
+	return &Compiler{store: st, signer: signer, now: time.Now}
+}
+
+// KeyID returns the ID of the key packages are signed with.
+func (c *Compiler) KeyID() string {
+	return c.signer.KeyID()
+}
+
+// PublicKey returns the key packages are verified with.
+func (c *Compiler) PublicKey() ed25519.PublicKey {
+	return c.signer.PublicKey()
+}
+
+// Compile gathers the evidence of an app between since and until and packs
+// it into a signed archive. Records of each kind are listed newest first.
+func (c *Compiler) Compile(ctx context.Context, app *models.App, since, until time.Time, generatedBy string) (*Package, error) {
+	deployments, err := c.deployments(ctx, app, since, until)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := c.store.Audit().List(ctx, models.AuditFilter{AppID: app.ID, Since: since, Until: until, Limit: maxRecords})
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+	if audit == nil {
+		audit = []*models.AuditEntry{}
+	}
+	provenance, err := c.provenance(ctx, deployments)
+	if err != nil {
+		return nil, err
+	}
+	access, err := c.access(ctx, app, deployments, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := Manifest{
+		AppID:       app.ID,
+		AppName:     app.Name,
+		OrgID:       app.OrgID,
+		Since:       since,
+		Until:       until,
+		GeneratedAt: c.now().UTC(),
+		GeneratedBy: generatedBy,
+		KeyID:       c.signer.KeyID(),
+	}
+	contents := []struct {
+		name string
+		v    any
+	}{
+		{DeploymentsFile, deployments},
+		{AuditFile, audit},
+		{ProvenanceFile, provenance},
+		{AccessFile, access},
+	}
+	files := make(map[string][]byte, len(contents)+2)
+	for _, f := range contents {
+		data, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, File{Name: f.name, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+		files[f.name] = data
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	signature, err := json.MarshalIndent(c.signer.SignPayload(PayloadType, manifestData), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding signature: %w", err)
+	}
+	files[ManifestFile] = manifestData
+	files[SignatureFile] = signature
+
+	order := []string{ManifestFile, SignatureFile, DeploymentsFile, AuditFile, ProvenanceFile, AccessFile}
+	archive, err := writeArchive(files, order, manifest.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(archive)
+	return &Package{
+		Manifest: manifest,
+		Archive:  archive,
+		SHA256:   hex.EncodeToString(sum[:]),
+		Counts: map[string]int{
+			"deployments":   len(deployments),
+			"audit_entries": len(audit),
+			"attestations":  len(provenance),
+			"access":        len(access),
+		},
+	}, nil
+}
+
+// deployments lists the app's deployments created in the range with who
+// started and approved them.
+func (c *Compiler) deployments(ctx context.Context, app *models.App, since, until time.Time) ([]Deployment, error) {
+	all, err := c.store.Deployments().List(ctx, app.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+
+	var overrides []*models.FreezeOverride
+	var acks []*models.OnCallAck
+	if app.OrgID != "" {
+		if overrides, err = c.store.DeployFreezes().ListOverrides(ctx, app.OrgID, maxRecords); err != nil {
+			return nil, fmt.Errorf("listing freeze overrides: %w", err)
+		}
+		if acks, err = c.store.OnCall().ListAcks(ctx, app.OrgID, maxRecords); err != nil {
+			return nil, fmt.Errorf("listing on-call acknowledgements: %w", err)
+		}
+	}
+
+	result := []Deployment{}
+	for _, d := range all {
+		if !inRange(d.CreatedAt, since, until) {
+			continue
+		}
+		entry := Deployment{Deployment: d, Approvals: []Approval{}}
+		build, err := c.store.Builds().GetByDeployment(ctx, d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting build of deployment %s: %w", d.ID, err)
+		}
+		if build != nil {
+			entry.BuildID = build.ID
+			entry.TriggeredBy = build.TriggeredBy
+		}
+
+		promotions, err := c.store.Promotions().ListByDeployment(ctx, d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing promotions of deployment %s: %w", d.ID, err)
+		}
+		for _, p := range promotions {
+			if p.TargetDeploymentID == d.ID && p.DecidedBy != "" {
+				entry.Approvals = append(entry.Approvals, Approval{
+					Kind: ApprovalPromotion, ID: p.ID, ApprovedBy: p.DecidedBy, RequestedBy: p.RequestedBy, At: p.UpdatedAt,
+				})
+			}
+		}
+		for _, o := range overrides {
+			if o.DeploymentID == d.ID {
+				entry.Approvals = append(entry.Approvals, Approval{
+					Kind: ApprovalFreezeOverride, ID: o.ID, ApprovedBy: o.UserID, Reason: o.Reason, At: o.CreatedAt,
+				})
+			}
+		}
+		for _, a := range acks {
+			if ackCovers(a, app.ID, entry.TriggeredBy, d.CreatedAt) {
+				entry.Approvals = append(entry.Approvals, Approval{
+					Kind: ApprovalOnCall, ID: a.ID, ApprovedBy: a.DecidedBy, RequestedBy: a.RequestedBy, Reason: a.Reason, At: *a.DecidedAt,
+				})
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// ackCovers reports whether an approved on-call acknowledgement let the user
+// deploy the app at the given time.
+func ackCovers(a *models.OnCallAck, appID, userID string, at time.Time) bool {
+	return a.Status == models.OnCallAckApproved && a.DecidedAt != nil && userID != "" &&
+		a.AppID == appID && a.RequestedBy == userID &&
+		!at.Before(*a.DecidedAt) && at.Before(a.ExpiresAt)
+}
+
+// provenance lists the signed provenance of the deployments' builds.
+func (c *Compiler) provenance(ctx context.Context, deployments []Deployment) ([]Provenance, error) {
+	result := []Provenance{}
+	for _, d := range deployments {
+		if d.BuildID == "" {
+			continue
+		}
+		attestation, err := c.store.BuildAttestations().Get(ctx, d.BuildID)
+		if err != nil {
+			return nil, fmt.Errorf("getting attestation of build %s: %w", d.BuildID, err)
+		}
+		if attestation != nil {
+			result = append(result, Provenance{DeploymentID: d.ID, BuildAttestation: attestation})
+		}
+	}
+	return result, nil
+}
+
+// access lists the debug sessions of the app's services and the SSH
+// sessions to the nodes its deployments ran on that started in the range.
+func (c *Compiler) access(ctx context.Context, app *models.App, deployments []Deployment, since, until time.Time) ([]Access, error) {
+	result := []Access{}
+	for _, svc := range app.Services {
+		sessions, err := c.store.DebugSessions().ListByService(ctx, app.ID, svc.Name, maxRecords)
+		if err != nil {
+			return nil, fmt.Errorf("listing debug sessions of %s: %w", svc.Name, err)
+		}
+		for _, s := range sessions {
+			if !inRange(s.CreatedAt, since, until) {
+				continue
+			}
+			result = append(result, Access{
+				Kind: AccessDebugSession, ID: s.ID, UserID: s.CreatedBy, ServiceName: s.ServiceName,
+				DeploymentID: s.DeploymentID, NodeID: s.NodeID, StartedAt: s.CreatedAt, EndedAt: s.EndedAt, Detail: s.Image,
+			})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range deployments {
+		if d.NodeID == "" || seen[d.NodeID] {
+			continue
+		}
+		seen[d.NodeID] = true
+		sessions, err := c.store.SSH().ListSessions(ctx, d.NodeID, maxRecords)
+		if err != nil {
+			return nil, fmt.Errorf("listing SSH sessions of node %s: %w", d.NodeID, err)
+		}
+		for _, s := range sessions {
+			if !inRange(s.StartedAt, since, until) {
+				continue
+			}
+			result = append(result, Access{
+				Kind: AccessNodeSSH, ID: s.ID, UserID: s.UserID, NodeID: s.NodeID,
+				StartedAt: s.StartedAt, EndedAt: s.EndedAt, Detail: s.RemoteAddr,
+			})
+		}
+	}
+	return result, nil
+}
+
+// inRange reports whether t is in [since, until).
+func inRange(t, since, until time.Time) bool {
+	return !t.Before(since) && t.Before(until)
+}
+
+// writeArchive packs the files into a gzipped tar archive in the given order.
+func writeArchive(files map[string][]byte, order []string, modTime time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range order {
+		data := files[name]
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("writing archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("writing archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("writing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("writing archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Verify checks that a package's manifest is signed by the public key and
+// that every file it lists is unchanged, and returns the manifest.
+func Verify(archive []byte, publicKey ed25519.PublicKey) (*Manifest, error) {
+	files, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	signature, ok := files[SignatureFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingFile, SignatureFile)
+	}
+	var envelope provenance.Envelope
+	if err := json.Unmarshal(signature, &envelope); err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+	payload, err := provenance.VerifyPayload(&envelope, PayloadType, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if manifestData, ok := files[ManifestFile]; ok && !bytes.Equal(manifestData, payload) {
+		return nil, fmt.Errorf("%w: %s", ErrFileModified, ManifestFile)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	for _, f := range manifest.Files {
+		data, ok := files[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingFile, f.Name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("%w: %s", ErrFileModified, f.Name)
+		}
+	}
+	return &manifest, nil
+}
+
+// readArchive unpacks a gzipped tar archive into its files by name.
+func readArchive(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+}
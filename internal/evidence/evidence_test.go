@@ -0,0 +1,264 @@
+package evidence
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/provenance"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing the records of one app.
+type memStore struct {
+	store.Store
+	deployments  []*models.Deployment
+	builds       map[string]*models.BuildJob
+	promotions   []*models.Promotion
+	overrides    []*models.FreezeOverride
+	acks         []*models.OnCallAck
+	audit        []*models.AuditEntry
+	attestations map[string]*models.BuildAttestation
+	debug        []*models.DebugSession
+	ssh          []*models.SSHSession
+}
+
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) Builds() store.BuildStore           { return memBuilds{s: s} }
+func (s *memStore) Promotions() store.PromotionStore   { return memPromotions{s: s} }
+func (s *memStore) DeployFreezes() store.DeployFreezeStore {
+	return memFreezes{s: s}
+}
+func (s *memStore) OnCall() store.OnCallStore { return memOnCall{s: s} }
+func (s *memStore) Audit() store.AuditStore   { return memAudit{s: s} }
+func (s *memStore) BuildAttestations() store.BuildAttestationStore {
+	return memAttestations{s: s}
+}
+func (s *memStore) DebugSessions() store.DebugSessionStore { return memDebug{s: s} }
+func (s *memStore) SSH() store.SSHStore                    { return memSSH{s: s} }
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	return m.s.deployments, nil
+}
+
+type memBuilds struct {
+	store.BuildStore
+	s *memStore
+}
+
+func (m memBuilds) GetByDeployment(ctx context.Context, deploymentID string) (*models.BuildJob, error) {
+	return m.s.builds[deploymentID], nil
+}
+
+type memPromotions struct {
+	store.PromotionStore
+	s *memStore
+}
+
+func (m memPromotions) ListByDeployment(ctx context.Context, deploymentID string) ([]*models.Promotion, error) {
+	var result []*models.Promotion
+	for _, p := range m.s.promotions {
+		if p.TargetDeploymentID == deploymentID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+type memFreezes struct {
+	store.DeployFreezeStore
+	s *memStore
+}
+
+func (m memFreezes) ListOverrides(ctx context.Context, orgID string, limit int) ([]*models.FreezeOverride, error) {
+	return m.s.overrides, nil
+}
+
+type memOnCall struct {
+	store.OnCallStore
+	s *memStore
+}
+
+func (m memOnCall) ListAcks(ctx context.Context, orgID string, limit int) ([]*models.OnCallAck, error) {
+	return m.s.acks, nil
+}
+
+type memAudit struct {
+	store.AuditStore
+	s *memStore
+}
+
+func (m memAudit) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	return m.s.audit, nil
+}
+
+type memAttestations struct {
+	store.BuildAttestationStore
+	s *memStore
+}
+
+func (m memAttestations) Get(ctx context.Context, buildID string) (*models.BuildAttestation, error) {
+	return m.s.attestations[buildID], nil
+}
+
+type memDebug struct {
+	store.DebugSessionStore
+	s *memStore
+}
+
+func (m memDebug) ListByService(ctx context.Context, appID, serviceName string, limit int) ([]*models.DebugSession, error) {
+	return m.s.debug, nil
+}
+
+type memSSH struct {
+	store.SSHStore
+	s *memStore
+}
+
+func (m memSSH) ListSessions(ctx context.Context, nodeID string, limit int) ([]*models.SSHSession, error) {
+	var result []*models.SSHSession
+	for _, s := range m.s.ssh {
+		if s.NodeID == nodeID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func newTestStore(since time.Time) *memStore {
+	at := since.Add(48 * time.Hour)
+	decided := at.Add(-time.Hour)
+	return &memStore{
+		deployments: []*models.Deployment{
+			{ID: "d2", AppID: "app-1", NodeID: "node-2", CreatedAt: since.Add(-time.Hour)},
+			{ID: "d1", AppID: "app-1", NodeID: "node-1", CreatedAt: at},
+		},
+		builds: map[string]*models.BuildJob{
+			"d1": {ID: "b1", DeploymentID: "d1", TriggeredBy: "alice"},
+			"d2": {ID: "b2", DeploymentID: "d2", TriggeredBy: "alice"},
+		},
+		promotions: []*models.Promotion{
+			{ID: "p1", TargetDeploymentID: "d1", RequestedBy: "alice", DecidedBy: "bob", UpdatedAt: at},
+		},
+		overrides: []*models.FreezeOverride{
+			{ID: "o1", DeploymentID: "d1", UserID: "carol", Reason: "hotfix", CreatedAt: at},
+			{ID: "o2", DeploymentID: "d9", UserID: "carol", CreatedAt: at},
+		},
+		acks: []*models.OnCallAck{
+			{ID: "a1", AppID: "app-1", RequestedBy: "alice", Status: models.OnCallAckApproved, DecidedBy: "dave",
+				DecidedAt: &decided, ExpiresAt: at.Add(time.Hour)},
+			{ID: "a2", AppID: "app-1", RequestedBy: "erin", Status: models.OnCallAckApproved, DecidedBy: "dave",
+				DecidedAt: &decided, ExpiresAt: at.Add(time.Hour)},
+		},
+		audit: []*models.AuditEntry{{ID: "e1", AppID: "app-1", CreatedAt: at}},
+		attestations: map[string]*models.BuildAttestation{
+			"b1": {BuildID: "b1", KeyID: "worker-key", Envelope: json.RawMessage(`{}`)},
+		},
+		debug: []*models.DebugSession{
+			{ID: "s1", AppID: "app-1", ServiceName: "web", DeploymentID: "d1", NodeID: "node-1", CreatedBy: "bob", CreatedAt: at},
+			{ID: "s2", AppID: "app-1", ServiceName: "web", NodeID: "node-1", CreatedBy: "bob", CreatedAt: since.Add(-time.Hour)},
+		},
+		ssh: []*models.SSHSession{
+			{ID: "h1", NodeID: "node-1", UserID: "frank", RemoteAddr: "10.0.0.5", StartedAt: at},
+			{ID: "h2", NodeID: "node-2", UserID: "frank", StartedAt: at},
+		},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 3, 0)
+	_, key, _ := ed25519.GenerateKey(nil)
+	signer := provenance.NewSigner(key)
+	c := NewCompiler(newTestStore(since), signer)
+	app := &models.App{ID: "app-1", OrgID: "org-1", Name: "shop", Services: []models.ServiceConfig{{Name: "web"}}}
+
+	pkg, err := c.Compile(context.Background(), app, since, until, "auditor")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := map[string]int{"deployments": 1, "audit_entries": 1, "attestations": 1, "access": 2}
+	for kind, n := range want {
+		if pkg.Counts[kind] != n {
+			t.Errorf("Counts[%s] = %d, want %d", kind, pkg.Counts[kind], n)
+		}
+	}
+
+	files, err := readArchive(pkg.Archive)
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	var deployments []Deployment
+	if err := json.Unmarshal(files[DeploymentsFile], &deployments); err != nil {
+		t.Fatalf("decoding deployments: %v", err)
+	}
+	if len(deployments) != 1 || deployments[0].TriggeredBy != "alice" || deployments[0].BuildID != "b1" {
+		t.Fatalf("deployments = %+v, want d1 triggered by alice", deployments)
+	}
+	var approvers []string
+	for _, a := range deployments[0].Approvals {
+		approvers = append(approvers, a.Kind+":"+a.ApprovedBy)
+	}
+	wantApprovers := []string{"promotion:bob", "freeze_override:carol", "on_call:dave"}
+	if !slices.Equal(approvers, wantApprovers) {
+		t.Errorf("approvals = %v, want %v", approvers, wantApprovers)
+	}
+
+	manifest, err := Verify(pkg.Archive, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if manifest.AppID != "app-1" || manifest.KeyID != signer.KeyID() || len(manifest.Files) != 4 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+}
+
+func TestVerifyRejectsChanges(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, key, _ := ed25519.GenerateKey(nil)
+	signer := provenance.NewSigner(key)
+	c := NewCompiler(newTestStore(since), signer)
+	app := &models.App{ID: "app-1", OrgID: "org-1", Name: "shop"}
+	pkg, err := c.Compile(context.Background(), app, since, since.AddDate(0, 1, 0), "auditor")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	if _, err := Verify(pkg.Archive, otherKey); err == nil {
+		t.Error("Verify() with another key succeeded")
+	}
+
+	files, err := readArchive(pkg.Archive)
+	if err != nil {
+		t.Fatalf("readArchive() error = %v", err)
+	}
+	files[AuditFile] = []byte("[]")
+	order := []string{ManifestFile, SignatureFile, DeploymentsFile, AuditFile, ProvenanceFile, AccessFile}
+	tampered, err := writeArchive(files, order, pkg.Manifest.GeneratedAt)
+	if err != nil {
+		t.Fatalf("writeArchive() error = %v", err)
+	}
+	if _, err := Verify(tampered, signer.PublicKey()); !errors.Is(err, ErrFileModified) {
+		t.Errorf("Verify() of a changed file error = %v, want %v", err, ErrFileModified)
+	}
+
+	delete(files, AuditFile)
+	missing, err := writeArchive(files, order[:3], pkg.Manifest.GeneratedAt)
+	if err != nil {
+		t.Fatalf("writeArchive() error = %v", err)
+	}
+	if _, err := Verify(missing, signer.PublicKey()); !errors.Is(err, ErrMissingFile) {
+		t.Errorf("Verify() of a missing file error = %v, want %v", err, ErrMissingFile)
+	}
+}
@@ -0,0 +1,207 @@
+// Package federation combines the apps, nodes and deployments of several
+// Narvana installs into one read-only view. An instance registers its peers
+// with an admin API key of each and fetches their summaries when the view is
+// requested; changes are made on the owning instance, which the view links to.
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// SummaryPath is the API path peers serve their summary on.
+const SummaryPath = "/v1/admin/federation/summary"
+
+// LocalName is the name of this instance in the view.
+const LocalName = "This instance"
+
+// peerTimeout bounds how long the view waits for a peer.
+const peerTimeout = 10 * time.Second
+
+// activeStatuses are the deployment statuses included in a summary.
+var activeStatuses = []models.DeploymentStatus{
+	models.DeploymentStatusPending,
+	models.DeploymentStatusBuilding,
+	models.DeploymentStatusBuilt,
+	models.DeploymentStatusScheduled,
+	models.DeploymentStatusStarting,
+	models.DeploymentStatusRunning,
+	models.DeploymentStatusStopping,
+}
+
+// Service builds this instance's summary and the combined view of it and its peers.
+type Service struct {
+	store  store.Store
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewService creates a federation service that fetches peers with client. A
+// nil client uses one with a timeout of peerTimeout.
+func NewService(st store.Store, client *http.Client, logger *slog.Logger) *Service {
+	if client == nil {
+		client = &http.Client{Timeout: peerTimeout}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{store: st, client: client, logger: logger}
+}
+
+// Summary lists this instance's apps, nodes and unfinished deployments.
+func (s *Service) Summary(ctx context.Context) (*models.FederationSummary, error) {
+	apps, err := s.store.Apps().ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing apps: %w", err)
+	}
+	nodes, err := s.store.Nodes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	summary := &models.FederationSummary{
+		Apps:        make([]models.FederatedApp, 0, len(apps)),
+		Nodes:       make([]models.FederatedNode, 0, len(nodes)),
+		Deployments: []models.FederatedDeployment{},
+		GeneratedAt: time.Now(),
+	}
+	appNames := make(map[string]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ID] = app.Name
+		summary.Apps = append(summary.Apps, models.FederatedApp{
+			ID:        app.ID,
+			Name:      app.Name,
+			OrgID:     app.OrgID,
+			Services:  len(app.Services),
+			CreatedAt: app.CreatedAt,
+		})
+	}
+	for _, node := range nodes {
+		summary.Nodes = append(summary.Nodes, models.FederatedNode{
+			ID:            node.ID,
+			Hostname:      node.Hostname,
+			Status:        node.Status,
+			Healthy:       node.Healthy,
+			LastHeartbeat: node.LastHeartbeat,
+		})
+	}
+	for _, status := range activeStatuses {
+		deployments, err := s.store.Deployments().ListByStatus(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s deployments: %w", status, err)
+		}
+		for _, d := range deployments {
+			summary.Deployments = append(summary.Deployments, models.FederatedDeployment{
+				ID:          d.ID,
+				AppID:       d.AppID,
+				AppName:     appNames[d.AppID],
+				ServiceName: d.ServiceName,
+				Version:     d.Version,
+				Status:      d.Status,
+				NodeID:      d.NodeID,
+				UpdatedAt:   d.UpdatedAt,
+			})
+		}
+	}
+	sort.Slice(summary.Deployments, func(i, j int) bool {
+		return summary.Deployments[i].UpdatedAt.After(summary.Deployments[j].UpdatedAt)
+	})
+	return summary, nil
+}
+
+// View combines this instance's summary with those of every peer, fetched
+// concurrently. A peer that cannot be reached is listed with its error so the
+// rest of the view still loads.
+func (s *Service) View(ctx context.Context) (*models.FederationView, error) {
+	local, err := s.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	peers, err := s.store.Federation().ListPeers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing peers: %w", err)
+	}
+
+	instances := make([]models.FederatedInstance, len(peers)+1)
+	instances[0] = models.FederatedInstance{Name: LocalName, Summary: *local}
+	addLinks(&instances[0].Summary, "")
+
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer *models.FederationPeer) {
+			defer wg.Done()
+			instance := models.FederatedInstance{PeerID: peer.ID, Name: peer.Name, WebURL: peer.LinkBase()}
+			summary, err := s.Fetch(ctx, peer)
+			if err != nil {
+				s.logger.Warn("failed to fetch federation peer", "peer", peer.Name, "error", err)
+				instance.Error = err.Error()
+				instance.Summary = models.FederationSummary{
+					Apps: []models.FederatedApp{}, Nodes: []models.FederatedNode{}, Deployments: []models.FederatedDeployment{},
+				}
+			} else {
+				instance.Summary = *summary
+				addLinks(&instance.Summary, peer.LinkBase())
+			}
+			instances[i+1] = instance
+		}(i, peer)
+	}
+	wg.Wait()
+
+	return &models.FederationView{Instances: instances}, nil
+}
+
+// Fetch retrieves a peer's summary with its API key.
+func (s *Service) Fetch(ctx context.Context, peer *models.FederationPeer) (*models.FederationSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, peerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(peer.URL, "/")+SummaryPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+peer.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("peer returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	var summary models.FederationSummary
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("decoding peer summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// addLinks points every row of a summary at its page in the web UI at base.
+// The local instance uses an empty base so links stay relative.
+func addLinks(summary *models.FederationSummary, base string) {
+	base = strings.TrimRight(base, "/")
+	for i := range summary.Apps {
+		summary.Apps[i].Link = base + "/apps/" + summary.Apps[i].ID
+	}
+	for i := range summary.Nodes {
+		summary.Nodes[i].Link = base + "/nodes"
+	}
+	for i := range summary.Deployments {
+		summary.Deployments[i].Link = base + "/deployments/" + summary.Deployments[i].ID
+	}
+}
@@ -0,0 +1,130 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing what a summary reads and the peers.
+type memStore struct {
+	store.Store
+	apps        []*models.App
+	nodes       []*models.Node
+	deployments []*models.Deployment
+	peers       []*models.FederationPeer
+}
+
+func (s *memStore) Apps() store.AppStore               { return memApps{s: s} }
+func (s *memStore) Nodes() store.NodeStore             { return memNodes{s: s} }
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) Federation() store.FederationStore  { return memFederation{s: s} }
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (m memApps) ListAll(ctx context.Context) ([]*models.App, error) { return m.s.apps, nil }
+
+type memNodes struct {
+	store.NodeStore
+	s *memStore
+}
+
+func (m memNodes) List(ctx context.Context) ([]*models.Node, error) { return m.s.nodes, nil }
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) ListByStatus(ctx context.Context, status models.DeploymentStatus) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.Status == status {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+type memFederation struct {
+	store.FederationStore
+	s *memStore
+}
+
+func (m memFederation) ListPeers(ctx context.Context) ([]*models.FederationPeer, error) {
+	return m.s.peers, nil
+}
+
+func TestSummaryListsActiveDeployments(t *testing.T) {
+	st := &memStore{
+		apps:  []*models.App{{ID: "a1", Name: "shop", Services: []models.ServiceConfig{{Name: "web"}, {Name: "worker"}}}},
+		nodes: []*models.Node{{ID: "n1", Hostname: "node-1", Healthy: true, Status: models.NodeStatusActive}},
+		deployments: []*models.Deployment{
+			{ID: "d1", AppID: "a1", ServiceName: "web", Status: models.DeploymentStatusRunning},
+			{ID: "d2", AppID: "a1", ServiceName: "web", Status: models.DeploymentStatusStopped},
+		},
+	}
+
+	summary, err := NewService(st, nil, nil).Summary(context.Background())
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if len(summary.Apps) != 1 || summary.Apps[0].Services != 2 || len(summary.Nodes) != 1 {
+		t.Errorf("apps = %+v, nodes = %+v", summary.Apps, summary.Nodes)
+	}
+	if len(summary.Deployments) != 1 || summary.Deployments[0].ID != "d1" || summary.Deployments[0].AppName != "shop" {
+		t.Errorf("deployments = %+v, want only the running one, named after its app", summary.Deployments)
+	}
+}
+
+func TestViewCombinesPeersAndLinksToThem(t *testing.T) {
+	peerSummary := models.FederationSummary{
+		Apps:        []models.FederatedApp{{ID: "remote-app", Name: "blog"}},
+		Nodes:       []models.FederatedNode{},
+		Deployments: []models.FederatedDeployment{{ID: "remote-dep", AppID: "remote-app"}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != SummaryPath || r.Header.Get("Authorization") != "Bearer nrv_peer" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(peerSummary)
+	}))
+	defer srv.Close()
+
+	st := &memStore{
+		apps: []*models.App{{ID: "a1", Name: "shop"}},
+		peers: []*models.FederationPeer{
+			{ID: "p1", Name: "eu", URL: srv.URL, WebURL: "https://eu.example.com/", APIKey: "nrv_peer"},
+			{ID: "p2", Name: "us", URL: srv.URL, APIKey: "nrv_wrong"},
+		},
+	}
+	view, err := NewService(st, srv.Client(), nil).View(context.Background())
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	if len(view.Instances) != 3 {
+		t.Fatalf("instances = %d, want the local instance and two peers", len(view.Instances))
+	}
+
+	local, eu, us := view.Instances[0], view.Instances[1], view.Instances[2]
+	if local.Name != LocalName || local.Summary.Apps[0].Link != "/apps/a1" {
+		t.Errorf("local = %+v", local)
+	}
+	if eu.Error != "" || eu.Summary.Apps[0].Link != "https://eu.example.com/apps/remote-app" ||
+		eu.Summary.Deployments[0].Link != "https://eu.example.com/deployments/remote-dep" {
+		t.Errorf("eu = %+v, want links to its web UI", eu)
+	}
+	if !strings.Contains(us.Error, "401") || len(us.Summary.Apps) != 0 {
+		t.Errorf("us = %+v, want the unreachable peer listed with its error", us)
+	}
+}
@@ -3,13 +3,16 @@ package grpc
 import (
 	"context"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/narvanalabs/control-plane/api/proto"
+	"github.com/narvanalabs/control-plane/internal/events"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
@@ -111,6 +114,9 @@ func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.H
 		return nil, status.Error(codes.Internal, "failed to update heartbeat")
 	}
 
+	s.recordHealth(ctx, node, resources, nil)
+	s.recordClock(ctx, node, req.Timestamp, req.GetNodeInfo().GetCertificateExpiresAt())
+
 	// Update in-memory NodeManager connection status (keeps command stream healthy)
 	if s.nodeManager != nil {
 		s.nodeManager.UpdateHeartbeat(req.NodeId, req.NodeInfo)
@@ -166,6 +172,7 @@ func (s *Server) ReportStatus(ctx context.Context, req *pb.StatusReport) (*pb.St
 
 	// Map proto status to model status
 	statusStr := mapProtoStatusToModel(req.Status)
+	previousStatus := deployment.Status
 	deployment.Status = models.DeploymentStatus(statusStr)
 
 	// Update started_at timestamp when deployment starts running (Requirement 5.3)
@@ -188,6 +195,11 @@ func (s *Server) ReportStatus(ctx context.Context, req *pb.StatusReport) (*pb.St
 		}
 	}
 
+	recordHealthCheck(deployment, req, previousStatus)
+	if req.Status == pb.DeploymentStatus_STATUS_FAILED && req.ErrorMessage != "" {
+		deployment.Error = req.ErrorMessage
+	}
+
 	// Log container ID if provided (Requirement 5.3)
 	if req.ContainerId != "" {
 		s.logger.Debug("container ID reported",
@@ -213,6 +225,19 @@ func (s *Server) ReportStatus(ctx context.Context, req *pb.StatusReport) (*pb.St
 		return nil, status.Error(codes.Internal, "failed to update deployment status")
 	}
 
+	s.recordEgressViolations(ctx, deployment, req.EgressViolations)
+	if req.Status == pb.DeploymentStatus_STATUS_RUNNING {
+		s.recordResourceUsage(ctx, deployment, req.ResourceUsage)
+	}
+
+	if s.events != nil && deployment.Status != previousStatus {
+		s.events.Publish(&events.Transition{
+			Kind:           events.TransitionDeploymentStatus,
+			Deployment:     deployment,
+			PreviousStatus: previousStatus,
+		})
+	}
+
 	s.logger.Info("deployment status updated",
 		"deployment_id", req.DeploymentId,
 		"node_id", req.NodeId,
@@ -223,6 +248,102 @@ func (s *Server) ReportStatus(ctx context.Context, req *pb.StatusReport) (*pb.St
 	}, nil
 }
 
+// recordHealthCheck records the readiness probe results reported for a
+// deployment with a health check. Probing starts when the deployment enters
+// starting, and a running report means a probe has passed.
+func recordHealthCheck(deployment *models.Deployment, req *pb.StatusReport, previousStatus models.DeploymentStatus) {
+	if deployment.HealthCheck() == nil {
+		return
+	}
+	now := time.Now()
+	if deployment.Status == models.DeploymentStatusStarting && (previousStatus != models.DeploymentStatusStarting || deployment.Health == nil) {
+		deployment.Health = &models.DeploymentHealth{StartedAt: now}
+	}
+	if deployment.Health == nil {
+		return
+	}
+	switch {
+	case req.Status == pb.DeploymentStatus_STATUS_RUNNING:
+		if !deployment.Health.Passed {
+			deployment.Health.RecordProbe(true, req.HealthCheckOutput, now)
+		}
+	case req.HealthCheckOutput != "" || req.HealthCheckFailures > 0 || req.HealthCheckPassed:
+		deployment.Health.RecordProbe(req.HealthCheckPassed, req.HealthCheckOutput, now)
+		// The agent's count survives reports lost in transit
+		if req.HealthCheckFailures > 0 {
+			deployment.Health.Failures = int(req.HealthCheckFailures)
+		}
+	}
+}
+
+// recordEgressViolations stores the blocked connection counters reported with a
+// status update. Failures are logged and don't fail the status report.
+func (s *Server) recordEgressViolations(ctx context.Context, deployment *models.Deployment, reported []*pb.EgressViolation) {
+	if len(reported) == 0 {
+		return
+	}
+
+	violations := make([]*models.EgressViolation, 0, len(reported))
+	for _, v := range reported {
+		if v.Destination == "" {
+			continue
+		}
+		violation := &models.EgressViolation{
+			DeploymentID: deployment.ID,
+			AppID:        deployment.AppID,
+			ServiceName:  deployment.ServiceName,
+			Destination:  v.Destination,
+			Port:         int(v.Port),
+			Protocol:     v.Protocol,
+			Count:        v.Count,
+		}
+		if v.LastSeen != nil && v.LastSeen.IsValid() {
+			violation.LastSeenAt = v.LastSeen.AsTime()
+		}
+		violations = append(violations, violation)
+	}
+
+	if err := s.store.EgressViolations().Record(ctx, violations); err != nil {
+		s.logger.Error("failed to record egress violations",
+			"deployment_id", deployment.ID,
+			"error", err)
+		return
+	}
+
+	s.logger.Warn("egress policy blocked outbound connections",
+		"deployment_id", deployment.ID,
+		"app_id", deployment.AppID,
+		"service_name", deployment.ServiceName,
+		"destinations", len(violations))
+}
+
+// recordResourceUsage stores the resource usage reported with a status update
+// as a metrics sample. Failures are logged and don't fail the status report.
+func (s *Server) recordResourceUsage(ctx context.Context, deployment *models.Deployment, usage *pb.ResourceUsage) {
+	if usage == nil {
+		return
+	}
+
+	sample := &models.MetricSample{
+		DeploymentID:   deployment.ID,
+		AppID:          deployment.AppID,
+		ServiceName:    deployment.ServiceName,
+		CPUPercent:     usage.CpuPercent,
+		MemoryBytes:    usage.MemoryBytes,
+		NetworkRxBytes: usage.NetworkRxBytes,
+		NetworkTxBytes: usage.NetworkTxBytes,
+		Requests:       usage.Requests,
+		Errors:         usage.Errors,
+		LatencyMsSum:   usage.LatencyMsSum,
+		SampledAt:      time.Now(),
+	}
+	if err := s.store.Metrics().Record(ctx, sample); err != nil {
+		s.logger.Error("failed to record resource usage",
+			"deployment_id", deployment.ID,
+			"error", err)
+	}
+}
+
 // isValidDeploymentStatus checks if the status is a valid deployment status.
 // Supports: PENDING, PULLING, STARTING, RUNNING, STOPPING, STOPPED, FAILED, UNKNOWN
 // (Requirement 5.4)
@@ -242,10 +363,15 @@ func isValidDeploymentStatus(status pb.DeploymentStatus) bool {
 	}
 }
 
+// logsDroppedTrailer is the trailer key PushLogs uses to report how many
+// entries the ingester discarded.
+const logsDroppedTrailer = "x-narvana-logs-dropped"
+
 // PushLogs handles log streaming from nodes.
 // Requirements: 4.2, 4.4
 func (s *Server) PushLogs(stream pb.ControlPlaneService_PushLogsServer) error {
 	var entriesReceived int64
+	var entriesDropped int64
 	var lastDeploymentID string
 	var lastStreamID string
 
@@ -296,10 +422,22 @@ func (s *Server) PushLogs(stream pb.ControlPlaneService_PushLogsServer) error {
 			DeploymentID: entry.DeploymentId,
 			Source:       "runtime",
 			Level:        level,
+			Stream:       entry.Metadata["stream"],
 			Message:      entry.Message,
 			Timestamp:    timestamp,
 		}
 
+		// Hand off to the ingester when configured; it batches inserts and
+		// blocks while its buffer is full, slowing this stream as backpressure
+		if s.logIngester != nil {
+			if err := s.logIngester.Enqueue(stream.Context(), logEntry); err != nil {
+				entriesDropped++
+				continue
+			}
+			entriesReceived++
+			continue
+		}
+
 		// Store the log entry in the database (Requirement 4.4)
 		if err := s.store.Logs().Create(stream.Context(), logEntry); err != nil {
 			s.logger.Error("failed to store log entry",
@@ -324,6 +462,14 @@ func (s *Server) PushLogs(stream pb.ControlPlaneService_PushLogsServer) error {
 		"entries_received", entriesReceived,
 		"deployment_id", lastDeploymentID)
 
+	// Report dropped lines so agents can tell the control plane is shedding load
+	if entriesDropped > 0 {
+		s.logger.Warn("log entries dropped by ingester",
+			"entries_dropped", entriesDropped,
+			"deployment_id", lastDeploymentID)
+		stream.SetTrailer(metadata.Pairs(logsDroppedTrailer, strconv.FormatInt(entriesDropped, 10)))
+	}
+
 	return stream.SendAndClose(&pb.PushLogsResponse{
 		EntriesReceived: entriesReceived,
 	})
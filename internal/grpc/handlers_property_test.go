@@ -8,6 +8,7 @@ import (
 	"github.com/leanovate/gopter/prop"
 
 	pb "github.com/narvanalabs/control-plane/api/proto"
+	"github.com/narvanalabs/control-plane/internal/models"
 )
 
 // **Feature: grpc-node-communication, Property 5: Status Update Completeness**
@@ -255,3 +256,30 @@ func TestFailedStatusRequiresErrorDetails(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestRecordHealthCheck(t *testing.T) {
+	d := &models.Deployment{
+		Status: models.DeploymentStatusStarting,
+		Config: &models.RuntimeConfig{HealthCheck: &models.HealthCheckConfig{Path: "/health", Port: 8080}},
+	}
+
+	recordHealthCheck(d, &pb.StatusReport{Status: pb.DeploymentStatus_STATUS_STARTING}, models.DeploymentStatusScheduled)
+	if d.Health == nil || d.Health.StartedAt.IsZero() || d.Health.CheckedAt != nil {
+		t.Fatalf("health = %+v, want probing started", d.Health)
+	}
+
+	recordHealthCheck(d, &pb.StatusReport{
+		Status:              pb.DeploymentStatus_STATUS_STARTING,
+		HealthCheckFailures: 2,
+		HealthCheckOutput:   "HTTP 503",
+	}, models.DeploymentStatusStarting)
+	if d.Health.Passed || d.Health.Failures != 2 || d.Health.Output != "HTTP 503" {
+		t.Errorf("health = %+v, want 2 failures", d.Health)
+	}
+
+	d.Status = models.DeploymentStatusRunning
+	recordHealthCheck(d, &pb.StatusReport{Status: pb.DeploymentStatus_STATUS_RUNNING, HealthCheckPassed: true}, models.DeploymentStatusStarting)
+	if !d.Health.Passed || d.Health.Failures != 0 {
+		t.Errorf("health = %+v, want passed", d.Health)
+	}
+}
@@ -2,11 +2,17 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
 )
 
 // healthCheckMethods contains the methods that should skip authentication.
@@ -15,6 +21,10 @@ var healthCheckMethods = map[string]bool{
 	"/controlplane.Health/Watch": true,
 }
 
+// publicMethodPrefix is the prefix of the public API's methods, which
+// authenticate with API keys instead of agent tokens.
+const publicMethodPrefix = "/narvana.v1."
+
 // authInterceptor returns a unary server interceptor that validates auth tokens.
 func (s *Server) authInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -22,6 +32,13 @@ func (s *Server) authInterceptor() grpc.UnaryServerInterceptor {
 		if healthCheckMethods[info.FullMethod] {
 			return handler(ctx, req)
 		}
+		if strings.HasPrefix(info.FullMethod, publicMethodPrefix) {
+			ctx, err := s.authenticateAPIKey(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
 
 		token, err := extractToken(ctx)
 		if err != nil {
@@ -38,6 +55,10 @@ func (s *Server) authInterceptor() grpc.UnaryServerInterceptor {
 			return nil, status.Error(codes.Unauthenticated, "invalid auth token")
 		}
 
+		if err := s.authorizeAgent(ctx, claims.UserID); err != nil {
+			return nil, err
+		}
+
 		// Add the user ID (which we use as node ID for node agents) to context
 		ctx = context.WithValue(ctx, nodeIDKey, claims.UserID)
 		return handler(ctx, req)
@@ -51,6 +72,13 @@ func (s *Server) streamAuthInterceptor() grpc.StreamServerInterceptor {
 		if healthCheckMethods[info.FullMethod] {
 			return handler(srv, ss)
 		}
+		if strings.HasPrefix(info.FullMethod, publicMethodPrefix) {
+			ctx, err := s.authenticateAPIKey(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authenticatedServerStream{ServerStream: ss, ctx: ctx})
+		}
 
 		ctx := ss.Context()
 		token, err := extractToken(ctx)
@@ -68,6 +96,10 @@ func (s *Server) streamAuthInterceptor() grpc.StreamServerInterceptor {
 			return status.Error(codes.Unauthenticated, "invalid auth token")
 		}
 
+		if err := s.authorizeAgent(ctx, claims.UserID); err != nil {
+			return err
+		}
+
 		// Wrap the stream with authenticated context
 		wrappedStream := &authenticatedServerStream{
 			ServerStream: ss,
@@ -77,6 +109,57 @@ func (s *Server) streamAuthInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
+// authenticateAPIKey validates the API key of a public API call and adds it
+// to the context, to be sent with the HTTP API requests serving the call.
+func (s *Server) authenticateAPIKey(ctx context.Context) (context.Context, error) {
+	key := extractAPIKey(ctx)
+	if key == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing api key")
+	}
+	if _, err := s.authService.ValidateAPIKey(ctx, key); err != nil {
+		s.logger.Debug("api key validation failed", "error", err)
+		if errors.Is(err, auth.ErrExpiredToken) {
+			return nil, status.Error(codes.Unauthenticated, "api key has expired")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+	return context.WithValue(ctx, apiKeyKey, key), nil
+}
+
+// authorizeAgent rejects tokens issued to platform users, who could
+// otherwise impersonate a node and report on every org's deployments. Only
+// instance owners may use the agent API with a user token, e.g. one minted
+// with gentoken for debugging.
+func (s *Server) authorizeAgent(ctx context.Context, subject string) error {
+	if s.store == nil {
+		return nil
+	}
+	user, err := s.store.Users().GetByID(ctx, subject)
+	if err != nil {
+		s.logger.Error("failed to look up token subject", "error", err)
+		return status.Error(codes.Internal, "failed to authorize token")
+	}
+	if user != nil && user.Role != store.RoleOwner {
+		s.logger.Warn("user token rejected by agent api", "user_id", user.ID)
+		return status.Error(codes.PermissionDenied, "user tokens cannot call the agent api")
+	}
+	if user != nil {
+		return nil
+	}
+
+	// Nodes that joined with a join token wait for an admin's approval
+	node, err := s.store.Nodes().Get(ctx, subject)
+	if err == nil && node != nil {
+		switch node.Status {
+		case models.NodeStatusPending:
+			return status.Error(codes.PermissionDenied, "node is awaiting approval")
+		case models.NodeStatusRejected:
+			return status.Error(codes.PermissionDenied, "node was rejected")
+		}
+	}
+	return nil
+}
+
 // loggingInterceptor returns a unary server interceptor that logs requests.
 func (s *Server) loggingInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -119,6 +202,23 @@ func (s *Server) streamLoggingInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
+// streamMetricsInterceptor returns a stream server interceptor that counts
+// open and finished streams, if metrics are enabled.
+func (s *Server) streamMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s.openStreams == nil {
+			return handler(srv, ss)
+		}
+		open := s.openStreams.With(info.FullMethod)
+		open.Inc()
+		defer open.Dec()
+
+		err := handler(srv, ss)
+		s.streamsTotal.With(info.FullMethod, status.Code(err).String()).Inc()
+		return err
+	}
+}
+
 // authenticatedServerStream wraps a grpc.ServerStream with an authenticated context.
 type authenticatedServerStream struct {
 	grpc.ServerStream
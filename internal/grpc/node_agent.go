@@ -0,0 +1,169 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/narvanalabs/control-plane/api/proto"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// NodeAgent handles an agent's heartbeat stream. Each heartbeat records the
+// node's capacity and load and is answered with its health score. Nodes
+// that stop sending are marked unhealthy by the scheduler's health monitor,
+// which also moves their deployments.
+func (s *Server) NodeAgent(stream pb.ControlPlaneService_NodeAgentServer) error {
+	ctx := stream.Context()
+	var nodeID string
+
+	for {
+		hb, err := stream.Recv()
+		if err == io.EOF {
+			s.logger.Debug("node agent stream ended", "node_id", nodeID)
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				s.logger.Debug("node agent stream closed", "node_id", nodeID)
+				return nil
+			}
+			s.logger.Error("error receiving heartbeat", "node_id", nodeID, "error", err)
+			return status.Error(codes.Internal, "error receiving heartbeat")
+		}
+
+		if hb.NodeId == "" {
+			return status.Error(codes.InvalidArgument, "node_id is required")
+		}
+		if nodeID != "" && hb.NodeId != nodeID {
+			return status.Error(codes.InvalidArgument, "node_id changed on the stream")
+		}
+		if nodeID == "" {
+			nodeID = hb.NodeId
+			s.logger.Info("node agent stream opened", "node_id", nodeID)
+		}
+
+		ack, err := s.agentHeartbeat(ctx, hb)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(ack); err != nil {
+			s.logger.Debug("failed to acknowledge heartbeat", "node_id", nodeID, "error", err)
+			return nil
+		}
+	}
+}
+
+// agentHeartbeat records a heartbeat from the NodeAgent stream.
+func (s *Server) agentHeartbeat(ctx context.Context, hb *pb.AgentHeartbeat) (*pb.AgentHeartbeatAck, error) {
+	node, err := s.store.Nodes().Get(ctx, hb.NodeId)
+	if err != nil || node == nil {
+		return nil, status.Error(codes.NotFound, "node not found")
+	}
+
+	// Keep the last known capacity if the agent could not measure it
+	resources := protoResourcesToModel(hb.Resources)
+	if resources == nil {
+		resources = node.Resources
+	}
+	if resources == nil {
+		resources = &models.NodeResources{}
+	}
+	if err := s.store.Nodes().UpdateHeartbeatWithDiskMetrics(ctx, hb.NodeId, resources, protoDiskMetricsToModel(hb.DiskMetrics)); err != nil {
+		s.logger.Error("failed to update heartbeat", "node_id", hb.NodeId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to update heartbeat")
+	}
+
+	load := &models.NodeLoad{Load1: hb.Load1, Load5: hb.Load5, Load15: hb.Load15}
+	score := s.recordHealth(ctx, node, resources, load)
+	s.recordClock(ctx, node, hb.SentAt, hb.CertificateExpiresAt)
+
+	if s.nodeManager != nil {
+		s.nodeManager.UpdateHeartbeat(hb.NodeId, nil)
+		if hb.Draining {
+			s.nodeManager.SetNodeDraining(hb.NodeId, true)
+		}
+	}
+
+	return &pb.AgentHeartbeatAck{
+		Healthy:                  true,
+		HealthScore:              int32(score),
+		HeartbeatIntervalSeconds: int32(s.config.HeartbeatInterval.Seconds()),
+	}, nil
+}
+
+// recordHealth stores the health score of a node that just sent a heartbeat
+// and records its recovery if its heartbeats had lapsed. It returns the score.
+func (s *Server) recordHealth(ctx context.Context, node *models.Node, resources *models.NodeResources, load *models.NodeLoad) int {
+	score := models.NodeHealthScore(resources, load)
+	if err := s.store.Nodes().UpdateHealthScore(ctx, node.ID, score, load); err != nil {
+		s.logger.Error("failed to update node health score", "node_id", node.ID, "error", err)
+	}
+
+	if !node.Healthy && s.lapsed(ctx, node.ID) {
+		s.logger.Info("node is healthy again", "node_id", node.ID, "health_score", score)
+		event := &models.NodeHealthEvent{
+			NodeID:      node.ID,
+			Healthy:     true,
+			HealthScore: score,
+			Reason:      "heartbeat resumed",
+		}
+		if err := s.store.Nodes().CreateHealthEvent(ctx, event); err != nil {
+			s.logger.Error("failed to record node health event", "node_id", node.ID, "error", err)
+		} else if s.events != nil {
+			s.events.Publish(&events.Transition{Kind: events.TransitionNodeHealth, Node: node, HealthEvent: event})
+		}
+	}
+	return score
+}
+
+// recordClock stores how far a node's clock is off, judged by when its
+// heartbeat was sent, and when its agent's certificate expires, and publishes
+// a transition if that changed the node's conditions.
+func (s *Server) recordClock(ctx context.Context, node *models.Node, sentAt, certificateExpiresAt *timestamppb.Timestamp) {
+	if sentAt == nil && certificateExpiresAt == nil {
+		return
+	}
+	now := time.Now()
+	previous := *node
+	previous.UpdateConditions(now)
+	updated := previous
+
+	if sentAt != nil {
+		skew := now.Sub(sentAt.AsTime())
+		updated.ClockSkewMs = skew.Milliseconds()
+		if s.nodeManager != nil {
+			s.nodeManager.RecordClockSkew(node.ID, skew)
+		}
+	}
+	if certificateExpiresAt != nil {
+		expires := certificateExpiresAt.AsTime()
+		updated.CertificateExpiresAt = &expires
+	}
+	if err := s.store.Nodes().UpdateClock(ctx, node.ID, updated.ClockSkew(), updated.CertificateExpiresAt); err != nil {
+		s.logger.Error("failed to update node clock", "node_id", node.ID, "error", err)
+		return
+	}
+	updated.UpdateConditions(now)
+
+	if updated.ClockStatus.Worse(previous.ClockStatus) {
+		s.logger.Warn("node clock is skewed", "node_id", node.ID, "skew", updated.ClockSkew())
+	}
+	if updated.CertificateStatus.Worse(previous.CertificateStatus) {
+		s.logger.Warn("node agent certificate expires soon", "node_id", node.ID, "expires_at", updated.CertificateExpiresAt)
+	}
+	if s.events != nil && (updated.ClockStatus != previous.ClockStatus || updated.CertificateStatus != previous.CertificateStatus) {
+		s.events.Publish(&events.Transition{Kind: events.TransitionNodeHealth, Node: &updated, PreviousNode: &previous})
+	}
+}
+
+// lapsed reports whether a node's latest health event marked it unhealthy.
+func (s *Server) lapsed(ctx context.Context, nodeID string) bool {
+	latest, err := s.store.Nodes().ListHealthEvents(ctx, nodeID, 1)
+	return err == nil && len(latest) > 0 && !latest[0].Healthy
+}
@@ -0,0 +1,213 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/narvanalabs/control-plane/api/proto"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// agentStore is an in-memory store providing only the node operations the
+// NodeAgent stream uses.
+type agentStore struct {
+	store.Store
+	nodes  map[string]*models.Node
+	events []*models.NodeHealthEvent
+}
+
+func (s *agentStore) Nodes() store.NodeStore { return agentNodes{s: s} }
+
+type agentNodes struct {
+	store.NodeStore
+	s *agentStore
+}
+
+func (m agentNodes) Get(ctx context.Context, id string) (*models.Node, error) {
+	node, ok := m.s.nodes[id]
+	if !ok {
+		return nil, errors.New("node not found")
+	}
+	copied := *node
+	return &copied, nil
+}
+
+func (m agentNodes) UpdateHeartbeatWithDiskMetrics(ctx context.Context, id string, resources *models.NodeResources, diskMetrics *models.NodeDiskMetrics) error {
+	node := m.s.nodes[id]
+	node.Resources = resources
+	node.DiskMetrics = diskMetrics
+	node.Healthy = true
+	node.LastHeartbeat = time.Now()
+	return nil
+}
+
+func (m agentNodes) UpdateHealthScore(ctx context.Context, id string, score int, load *models.NodeLoad) error {
+	m.s.nodes[id].HealthScore = score
+	m.s.nodes[id].Load = load
+	return nil
+}
+
+func (m agentNodes) UpdateClock(ctx context.Context, id string, skew time.Duration, certificateExpiresAt *time.Time) error {
+	m.s.nodes[id].ClockSkewMs = skew.Milliseconds()
+	m.s.nodes[id].CertificateExpiresAt = certificateExpiresAt
+	return nil
+}
+
+func (m agentNodes) CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error {
+	m.s.events = append(m.s.events, event)
+	return nil
+}
+
+func (m agentNodes) ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error) {
+	var events []*models.NodeHealthEvent
+	for i := len(m.s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if m.s.events[i].NodeID == nodeID {
+			events = append(events, m.s.events[i])
+		}
+	}
+	return events, nil
+}
+
+// agentStream replays heartbeats and collects the acks sent back.
+type agentStream struct {
+	grpc.ServerStream
+	heartbeats []*pb.AgentHeartbeat
+	acks       []*pb.AgentHeartbeatAck
+}
+
+func (s *agentStream) Context() context.Context { return context.Background() }
+
+func (s *agentStream) Recv() (*pb.AgentHeartbeat, error) {
+	if len(s.heartbeats) == 0 {
+		return nil, io.EOF
+	}
+	hb := s.heartbeats[0]
+	s.heartbeats = s.heartbeats[1:]
+	return hb, nil
+}
+
+func (s *agentStream) Send(ack *pb.AgentHeartbeatAck) error {
+	s.acks = append(s.acks, ack)
+	return nil
+}
+
+func TestNodeAgentRecordsHeartbeats(t *testing.T) {
+	st := &agentStore{nodes: map[string]*models.Node{
+		"node-1": {ID: "node-1", Healthy: false},
+	}}
+	// The health monitor saw the node's heartbeats lapse
+	st.events = []*models.NodeHealthEvent{{NodeID: "node-1", Healthy: false, Reason: "no heartbeat for 45s"}}
+	srv, _ := NewServer(nil, st, nil, nil)
+
+	resources := &pb.ResourceMetrics{CpuTotal: 4, CpuAvailable: 2, MemoryTotal: 100, MemoryAvailable: 100}
+	stream := &agentStream{heartbeats: []*pb.AgentHeartbeat{
+		{NodeId: "node-1", Resources: resources, Load1: 2},
+		{NodeId: "node-1", Load1: 8, Load5: 6, Load15: 4, SentAt: timestamppb.New(time.Now().Add(-2 * time.Minute))},
+	}}
+	if err := srv.NodeAgent(stream); err != nil {
+		t.Fatalf("NodeAgent() = %v", err)
+	}
+
+	if len(stream.acks) != 2 {
+		t.Fatalf("got %d acks, want 2", len(stream.acks))
+	}
+	// Half the CPU and half the load per CPU cost 12.5 points each
+	if ack := stream.acks[0]; !ack.Healthy || ack.HealthScore != 75 || ack.HeartbeatIntervalSeconds != 10 {
+		t.Errorf("first ack = %+v", ack)
+	}
+	// A heartbeat without resources keeps the last reported ones
+	if ack := stream.acks[1]; ack.HealthScore != 63 {
+		t.Errorf("second ack score = %d, want 63", ack.HealthScore)
+	}
+
+	node := st.nodes["node-1"]
+	if !node.Healthy || node.HealthScore != 63 || node.Load == nil || node.Load.Load15 != 4 || node.Resources.CPUTotal != 4 {
+		t.Errorf("node = %+v, load = %+v", node, node.Load)
+	}
+	if len(st.events) != 2 || !st.events[1].Healthy || st.events[1].Reason != "heartbeat resumed" {
+		t.Errorf("events = %+v, want one recovery", st.events)
+	}
+	// The agent's clock runs two minutes behind
+	if skew := srv.NodeManager().ClockSkews()["node-1"]; skew < 2*time.Minute || skew > 3*time.Minute {
+		t.Errorf("clock skew = %s, want about 2m", skew)
+	}
+}
+
+// recordingPublisher records the transitions published.
+type recordingPublisher struct {
+	transitions []events.Transition
+}
+
+func (p *recordingPublisher) Publish(t *events.Transition) {
+	p.transitions = append(p.transitions, *t)
+}
+
+func TestNodeAgentRecordsClockAndCertificate(t *testing.T) {
+	st := &agentStore{nodes: map[string]*models.Node{"node-1": {ID: "node-1", Healthy: true}}}
+	srv, _ := NewServer(nil, st, nil, nil)
+	published := &recordingPublisher{}
+	srv.SetEvents(published)
+
+	expires := timestamppb.New(time.Now().Add(10 * 24 * time.Hour))
+	stream := &agentStream{heartbeats: []*pb.AgentHeartbeat{
+		{NodeId: "node-1", SentAt: timestamppb.Now()},
+		{NodeId: "node-1", SentAt: timestamppb.New(time.Now().Add(10 * time.Minute)), CertificateExpiresAt: expires},
+		{NodeId: "node-1", SentAt: timestamppb.New(time.Now().Add(10 * time.Minute))},
+	}}
+	if err := srv.NodeAgent(stream); err != nil {
+		t.Fatalf("NodeAgent() = %v", err)
+	}
+
+	node := st.nodes["node-1"]
+	if skew := node.ClockSkew(); skew > -9*time.Minute || skew < -11*time.Minute {
+		t.Errorf("clock skew = %s, want about -10m", skew)
+	}
+	if node.CertificateExpiresAt == nil || !node.CertificateExpiresAt.Equal(expires.AsTime()) {
+		t.Errorf("certificate expires at %v, want %v", node.CertificateExpiresAt, expires.AsTime())
+	}
+	// The second heartbeat made both worse; the third changed nothing
+	if len(published.transitions) != 1 {
+		t.Fatalf("published %d transitions, want 1", len(published.transitions))
+	}
+	if tr := published.transitions[0]; tr.Kind != events.TransitionNodeHealth ||
+		tr.Node.ClockStatus != models.NodeConditionCritical || tr.Node.CertificateStatus != models.NodeConditionWarning ||
+		tr.PreviousNode.ClockStatus != models.NodeConditionOK {
+		t.Errorf("published %+v, want a critical clock and expiring certificate", tr)
+	}
+}
+
+func TestNodeAgentRejectsInvalidHeartbeats(t *testing.T) {
+	st := &agentStore{nodes: map[string]*models.Node{
+		"node-1": {ID: "node-1", Healthy: true},
+		"node-2": {ID: "node-2", Healthy: true},
+	}}
+	srv, _ := NewServer(nil, st, nil, nil)
+
+	for _, tc := range []struct {
+		name       string
+		heartbeats []*pb.AgentHeartbeat
+		code       codes.Code
+	}{
+		{"missing node", []*pb.AgentHeartbeat{{}}, codes.InvalidArgument},
+		{"unknown node", []*pb.AgentHeartbeat{{NodeId: "node-9"}}, codes.NotFound},
+		{"node changed", []*pb.AgentHeartbeat{{NodeId: "node-1"}, {NodeId: "node-2"}}, codes.InvalidArgument},
+	} {
+		err := srv.NodeAgent(&agentStream{heartbeats: tc.heartbeats})
+		if status.Code(err) != tc.code {
+			t.Errorf("%s: NodeAgent() = %v, want %s", tc.name, err, tc.code)
+		}
+	}
+	if len(st.events) != 0 {
+		t.Errorf("healthy nodes recorded events: %+v", st.events)
+	}
+}
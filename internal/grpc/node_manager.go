@@ -59,7 +59,9 @@ func (nc *NodeConnection) UpdateHeartbeat(info *pb.NodeInfo) {
 	nc.mu.Lock()
 	defer nc.mu.Unlock()
 	nc.LastHeartbeat = time.Now()
-	nc.Info = info
+	if info != nil {
+		nc.Info = info
+	}
 	// If node was degraded or down and sends heartbeat, mark as healthy
 	if nc.Status != NodeStatusHealthy {
 		nc.Status = NodeStatusHealthy
@@ -126,8 +128,11 @@ func (nc *NodeConnection) SetDraining(draining bool) {
 type NodeManager struct {
 	store       store.Store
 	connections map[string]*NodeConnection
-	mu          sync.RWMutex
-	logger      *slog.Logger
+	// clockSkews holds how far each agent's clock was behind the control
+	// plane's when it sent its last heartbeat
+	clockSkews map[string]time.Duration
+	mu         sync.RWMutex
+	logger     *slog.Logger
 
 	// Health check configuration
 	healthCheckInterval time.Duration
@@ -172,6 +177,7 @@ func NewNodeManagerWithConfig(st store.Store, logger *slog.Logger, cfg *NodeMana
 	return &NodeManager{
 		store:               st,
 		connections:         make(map[string]*NodeConnection),
+		clockSkews:          make(map[string]time.Duration),
 		logger:              logger,
 		healthCheckInterval: cfg.HealthCheckInterval,
 		degradedThreshold:   cfg.DegradedThreshold,
@@ -318,6 +324,25 @@ func (m *NodeManager) UpdateHeartbeat(nodeID string, info *pb.NodeInfo) {
 	}
 }
 
+// RecordClockSkew records how far a node's clock is behind the control
+// plane's, as measured from the send time of its last heartbeat.
+func (m *NodeManager) RecordClockSkew(nodeID string, skew time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clockSkews[nodeID] = skew
+}
+
+// ClockSkews returns the last recorded clock skew of each node.
+func (m *NodeManager) ClockSkews() map[string]time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	skews := make(map[string]time.Duration, len(m.clockSkews))
+	for id, skew := range m.clockSkews {
+		skews[id] = skew
+	}
+	return skews
+}
+
 // StartHealthChecker starts the background health checker goroutine.
 func (m *NodeManager) StartHealthChecker(ctx context.Context) {
 	m.wg.Add(1)
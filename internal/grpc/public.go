@@ -0,0 +1,548 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/narvanalabs/control-plane/internal/api/idempotency"
+	"github.com/narvanalabs/control-plane/internal/api/versioning"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	narvanav1 "github.com/narvanalabs/control-plane/pkg/client/narvana/v1"
+)
+
+// publicLogPollInterval is how often StreamLogs polls for new log entries.
+const publicLogPollInterval = 500 * time.Millisecond
+
+// publicLogPageSize is how many log entries StreamLogs reads at once.
+const publicLogPageSize = 100
+
+// PublicAPI serves the public narvana.v1 API used by automation. Each call is
+// served by the matching /v1 route of the HTTP API, so it is authorized,
+// rate limited, audited and gated exactly like the HTTP request would be.
+type PublicAPI struct {
+	narvanav1.UnimplementedAppServiceServer
+	narvanav1.UnimplementedDeploymentServiceServer
+	narvanav1.UnimplementedBuildServiceServer
+	narvanav1.UnimplementedLogServiceServer
+
+	handler      http.Handler
+	store        store.Store
+	logger       *slog.Logger
+	pollInterval time.Duration
+}
+
+// NewPublicAPI creates the public API, serving calls with the HTTP API's
+// handler.
+func NewPublicAPI(handler http.Handler, st store.Store, logger *slog.Logger) *PublicAPI {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PublicAPI{
+		handler:      handler,
+		store:        st,
+		logger:       logger,
+		pollInterval: publicLogPollInterval,
+	}
+}
+
+// ListApps lists the apps of the caller's organization.
+func (p *PublicAPI) ListApps(ctx context.Context, req *narvanav1.ListAppsRequest) (*narvanav1.ListAppsResponse, error) {
+	var apps []*models.App
+	call := p.newCall(http.MethodGet, "/v1/apps", nil)
+	if req.OrgId != "" {
+		call.header.Set("X-Org-ID", req.OrgId)
+	}
+	if err := p.do(ctx, call, &apps); err != nil {
+		return nil, err
+	}
+	resp := &narvanav1.ListAppsResponse{}
+	for _, app := range apps {
+		resp.Apps = append(resp.Apps, appToProto(app))
+	}
+	return resp, nil
+}
+
+// GetApp gets an app by ID or name.
+func (p *PublicAPI) GetApp(ctx context.Context, req *narvanav1.GetAppRequest) (*narvanav1.GetAppResponse, error) {
+	app, err := p.getApp(ctx, req.App)
+	if err != nil {
+		return nil, err
+	}
+	return &narvanav1.GetAppResponse{App: appToProto(app)}, nil
+}
+
+// ListServices lists the services of an app.
+func (p *PublicAPI) ListServices(ctx context.Context, req *narvanav1.ListServicesRequest) (*narvanav1.ListServicesResponse, error) {
+	if req.App == "" {
+		return nil, status.Error(codes.InvalidArgument, "app is required")
+	}
+	var services []models.ServiceConfig
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/apps/"+url.PathEscape(req.App)+"/services", nil), &services); err != nil {
+		return nil, err
+	}
+	resp := &narvanav1.ListServicesResponse{}
+	for i := range services {
+		resp.Services = append(resp.Services, serviceToProto(&services[i]))
+	}
+	return resp, nil
+}
+
+// GetService gets a service of an app by name.
+func (p *PublicAPI) GetService(ctx context.Context, req *narvanav1.GetServiceRequest) (*narvanav1.GetServiceResponse, error) {
+	if req.App == "" || req.Service == "" {
+		return nil, status.Error(codes.InvalidArgument, "app and service are required")
+	}
+	var service models.ServiceConfig
+	path := "/v1/apps/" + url.PathEscape(req.App) + "/services/" + url.PathEscape(req.Service)
+	if err := p.do(ctx, p.newCall(http.MethodGet, path, nil), &service); err != nil {
+		return nil, err
+	}
+	return &narvanav1.GetServiceResponse{Service: serviceToProto(&service)}, nil
+}
+
+// Deploy builds and deploys a service.
+func (p *PublicAPI) Deploy(ctx context.Context, req *narvanav1.DeployRequest) (*narvanav1.DeployResponse, error) {
+	if req.App == "" || req.Service == "" {
+		return nil, status.Error(codes.InvalidArgument, "app and service are required")
+	}
+	body := map[string]any{}
+	if req.GitRef != "" {
+		body["git_ref"] = req.GitRef
+	}
+	if req.OnCallAckId != "" {
+		body["on_call_ack_id"] = req.OnCallAckId
+	}
+	if req.FreezeOverrideReason != "" {
+		body["freeze_override"] = map[string]string{"reason": req.FreezeOverrideReason}
+	}
+	path := "/v1/apps/" + url.PathEscape(req.App) + "/services/" + url.PathEscape(req.Service) + "/deploy"
+	call := p.newCall(http.MethodPost, path, body)
+	if req.IdempotencyKey != "" {
+		call.header.Set(idempotency.Header, req.IdempotencyKey)
+	}
+	var deployment models.Deployment
+	if err := p.do(ctx, call, &deployment); err != nil {
+		return nil, err
+	}
+	return &narvanav1.DeployResponse{Deployment: deploymentToProto(&deployment)}, nil
+}
+
+// ListDeployments lists the deployments of an app, newest first.
+func (p *PublicAPI) ListDeployments(ctx context.Context, req *narvanav1.ListDeploymentsRequest) (*narvanav1.ListDeploymentsResponse, error) {
+	if req.App == "" {
+		return nil, status.Error(codes.InvalidArgument, "app is required")
+	}
+	var deployments []*models.Deployment
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/apps/"+url.PathEscape(req.App)+"/deployments", nil), &deployments); err != nil {
+		return nil, err
+	}
+	resp := &narvanav1.ListDeploymentsResponse{}
+	for _, d := range deployments {
+		resp.Deployments = append(resp.Deployments, deploymentToProto(d))
+	}
+	return resp, nil
+}
+
+// GetDeployment gets a deployment by ID.
+func (p *PublicAPI) GetDeployment(ctx context.Context, req *narvanav1.GetDeploymentRequest) (*narvanav1.GetDeploymentResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var deployment models.Deployment
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/deployments/"+url.PathEscape(req.Id), nil), &deployment); err != nil {
+		return nil, err
+	}
+	return &narvanav1.GetDeploymentResponse{Deployment: deploymentToProto(&deployment)}, nil
+}
+
+// CancelDeployment cancels a deployment that has not started.
+func (p *PublicAPI) CancelDeployment(ctx context.Context, req *narvanav1.CancelDeploymentRequest) (*narvanav1.CancelDeploymentResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var deployment models.Deployment
+	if err := p.do(ctx, p.newCall(http.MethodPost, "/v1/deployments/"+url.PathEscape(req.Id)+"/cancel", nil), &deployment); err != nil {
+		return nil, err
+	}
+	return &narvanav1.CancelDeploymentResponse{Deployment: deploymentToProto(&deployment)}, nil
+}
+
+// ListBuilds lists the caller's builds, newest first.
+func (p *PublicAPI) ListBuilds(ctx context.Context, req *narvanav1.ListBuildsRequest) (*narvanav1.ListBuildsResponse, error) {
+	var builds []*models.BuildJob
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/builds", nil), &builds); err != nil {
+		return nil, err
+	}
+	resp := &narvanav1.ListBuildsResponse{}
+	for _, b := range builds {
+		resp.Builds = append(resp.Builds, buildToProto(b))
+	}
+	return resp, nil
+}
+
+// GetBuild gets a build by ID.
+func (p *PublicAPI) GetBuild(ctx context.Context, req *narvanav1.GetBuildRequest) (*narvanav1.GetBuildResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var build models.BuildJob
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/builds/"+url.PathEscape(req.Id), nil), &build); err != nil {
+		return nil, err
+	}
+	return &narvanav1.GetBuildResponse{Build: buildToProto(&build)}, nil
+}
+
+// CancelBuild cancels a queued or running build.
+func (p *PublicAPI) CancelBuild(ctx context.Context, req *narvanav1.CancelBuildRequest) (*narvanav1.CancelBuildResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var build models.BuildJob
+	if err := p.do(ctx, p.newCall(http.MethodPost, "/v1/builds/"+url.PathEscape(req.Id)+"/cancel", nil), &build); err != nil {
+		return nil, err
+	}
+	return &narvanav1.CancelBuildResponse{Build: buildToProto(&build)}, nil
+}
+
+// StreamLogs streams the logs of a deployment as they are written. The app
+// is read through the HTTP API to authorize the caller; logs are then polled
+// from the store like the HTTP API's event stream does, without its request
+// timeout.
+func (p *PublicAPI) StreamLogs(req *narvanav1.StreamLogsRequest, stream narvanav1.LogService_StreamLogsServer) error {
+	ctx := stream.Context()
+	app, err := p.getApp(ctx, req.App)
+	if err != nil {
+		return err
+	}
+	if req.Source != "" && req.Source != "build" && req.Source != "runtime" {
+		return status.Error(codes.InvalidArgument, "source must be build or runtime")
+	}
+
+	deploymentID := req.DeploymentId
+	if deploymentID != "" {
+		d, err := p.store.Deployments().Get(ctx, deploymentID)
+		if err != nil || d.AppID != app.ID {
+			return status.Error(codes.NotFound, "deployment not found")
+		}
+	}
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	// The stream starts with the latest entries of a deployment, then reads
+	// forward from the last entry sent until it has caught up
+	current := deploymentID
+	var cursor *models.LogCursor
+	for {
+		if req.DeploymentId == "" {
+			latest, err := p.latestDeployment(ctx, app.ID, req.Service)
+			if err != nil {
+				p.logger.Error("failed to find latest deployment", "error", err, "app_id", app.ID)
+				return status.Error(codes.Internal, "failed to find deployment")
+			}
+			if latest != current {
+				current, cursor = latest, nil
+			}
+		}
+
+		for current != "" {
+			entries, err := p.nextLogs(ctx, app.ID, current, req.Source, cursor)
+			if err != nil {
+				p.logger.Error("failed to list logs", "error", err, "deployment_id", current)
+				return status.Error(codes.Internal, "failed to list logs")
+			}
+			for _, entry := range entries {
+				if err := stream.Send(&narvanav1.StreamLogsResponse{Entry: logEntryToProto(entry)}); err != nil {
+					return err
+				}
+				if next := models.CursorOf(entry); cursor == nil || cursorAfter(next, *cursor) {
+					cursor = &next
+				}
+			}
+			if cursor == nil || len(entries) < publicLogPageSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// latestDeployment returns the ID of the latest deployment of an app, or of
+// one of its services, or "" if there is none.
+func (p *PublicAPI) latestDeployment(ctx context.Context, appID, serviceName string) (string, error) {
+	deployments, err := p.store.Deployments().List(ctx, appID)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range deployments {
+		if serviceName == "" || d.ServiceName == serviceName {
+			return d.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// cursorAfter reports whether a comes after b in oldest-first order.
+func cursorAfter(a, b models.LogCursor) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.After(b.Timestamp)
+	}
+	return a.ID > b.ID
+}
+
+// nextLogs returns the log entries of a deployment following cursor, oldest
+// first. Without a cursor, it returns the most recent entries.
+func (p *PublicAPI) nextLogs(ctx context.Context, appID, deploymentID, source string, cursor *models.LogCursor) ([]*models.LogEntry, error) {
+	if cursor != nil {
+		return p.store.Logs().Search(ctx, models.LogFilter{
+			AppID:        appID,
+			DeploymentID: deploymentID,
+			Source:       source,
+			After:        cursor,
+			Limit:        publicLogPageSize,
+		})
+	}
+
+	var entries []*models.LogEntry
+	var err error
+	if source != "" {
+		entries, err = p.store.Logs().ListBySource(ctx, deploymentID, source, publicLogPageSize)
+	} else {
+		entries, err = p.store.Logs().List(ctx, deploymentID, publicLogPageSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Entries are newest first
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// getApp reads an app by ID or name through the HTTP API.
+func (p *PublicAPI) getApp(ctx context.Context, appIDOrName string) (*models.App, error) {
+	if appIDOrName == "" {
+		return nil, status.Error(codes.InvalidArgument, "app is required")
+	}
+	var app models.App
+	if err := p.do(ctx, p.newCall(http.MethodGet, "/v1/apps/"+url.PathEscape(appIDOrName), nil), &app); err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// publicCall is an HTTP API request made for a public API call.
+type publicCall struct {
+	method string
+	path   string
+	body   any
+	header http.Header
+}
+
+func (p *PublicAPI) newCall(method, path string, body any) *publicCall {
+	return &publicCall{method: method, path: path, body: body, header: http.Header{}}
+}
+
+// do serves call with the HTTP API as the caller and decodes the response
+// into out. Error responses are returned as gRPC errors with their message.
+func (p *PublicAPI) do(ctx context.Context, call *publicCall, out any) error {
+	var body io.Reader = http.NoBody
+	if call.body != nil {
+		data, err := json.Marshal(call.body)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to encode request")
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, call.method, call.path, body)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+	for key, values := range call.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKeyFromContext(ctx))
+	req.Header.Set(versioning.Header, versioning.Current)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			req.Header.Set("User-Agent", ua[0])
+		}
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		req.RemoteAddr = pr.Addr.String()
+	}
+
+	rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+	p.handler.ServeHTTP(rec, req)
+
+	if rec.status >= http.StatusBadRequest {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(rec.body.Bytes(), &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(rec.status)
+		}
+		return status.Error(httpStatusCode(rec.status), apiErr.Message)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.body.Bytes(), out); err != nil {
+			p.logger.Error("failed to decode http api response", "error", err, "path", call.path)
+			return status.Error(codes.Internal, "failed to decode response")
+		}
+	}
+	return nil
+}
+
+// responseRecorder buffers the HTTP API's response to a public API call.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// httpStatusCode returns the gRPC code of an HTTP API error status.
+func httpStatusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	}
+	return codes.Internal
+}
+
+func appToProto(app *models.App) *narvanav1.App {
+	out := &narvanav1.App{
+		Id:          app.ID,
+		Name:        app.Name,
+		Description: app.Description,
+		OrgId:       app.OrgID,
+		OwnerId:     app.OwnerID,
+		CreatedAt:   protoTime(&app.CreatedAt),
+		UpdatedAt:   protoTime(&app.UpdatedAt),
+	}
+	for i := range app.Services {
+		out.Services = append(out.Services, serviceToProto(&app.Services[i]))
+	}
+	return out
+}
+
+func serviceToProto(svc *models.ServiceConfig) *narvanav1.Service {
+	return &narvanav1.Service{
+		Name:       svc.Name,
+		SourceType: string(svc.SourceType),
+		GitRepo:    svc.GitRepo,
+		GitRef:     svc.GitRef,
+		FlakeUri:   svc.FlakeURI,
+		Image:      svc.Image,
+		Replicas:   int32(svc.Replicas),
+		DependsOn:  svc.DependsOn,
+	}
+}
+
+func deploymentToProto(d *models.Deployment) *narvanav1.Deployment {
+	return &narvanav1.Deployment{
+		Id:          d.ID,
+		AppId:       d.AppID,
+		ServiceName: d.ServiceName,
+		Version:     int32(d.Version),
+		GitRef:      d.GitRef,
+		GitCommit:   d.GitCommit,
+		Status:      string(d.Status),
+		NodeId:      d.NodeID,
+		Artifact:    d.Artifact,
+		Error:       d.Error,
+		CreatedAt:   protoTime(&d.CreatedAt),
+		UpdatedAt:   protoTime(&d.UpdatedAt),
+		StartedAt:   protoTime(d.StartedAt),
+		FinishedAt:  protoTime(d.FinishedAt),
+	}
+}
+
+func buildToProto(b *models.BuildJob) *narvanav1.Build {
+	return &narvanav1.Build{
+		Id:            b.ID,
+		DeploymentId:  b.DeploymentID,
+		AppId:         b.AppID,
+		ServiceName:   b.ServiceName,
+		GitRef:        b.GitRef,
+		BuildType:     string(b.BuildType),
+		BuildStrategy: string(b.BuildStrategy),
+		Status:        string(b.Status),
+		QueuePosition: int32(b.QueuePosition),
+		CreatedAt:     protoTime(&b.CreatedAt),
+		StartedAt:     protoTime(b.StartedAt),
+		FinishedAt:    protoTime(b.FinishedAt),
+	}
+}
+
+func logEntryToProto(e *models.LogEntry) *narvanav1.LogEntry {
+	return &narvanav1.LogEntry{
+		Id:           e.ID,
+		DeploymentId: e.DeploymentID,
+		Source:       e.Source,
+		Level:        e.Level,
+		Stream:       e.Stream,
+		Message:      e.Message,
+		Timestamp:    protoTime(&e.Timestamp),
+	}
+}
+
+// protoTime converts a time to a timestamp, or nil if it is unset.
+func protoTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
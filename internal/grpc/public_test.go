@@ -0,0 +1,350 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/pkg/client"
+	narvanav1 "github.com/narvanalabs/control-plane/pkg/client/narvana/v1"
+)
+
+const testAPIKey = "nrv_test-key"
+
+// publicStore is an in-memory store of the deployments and logs StreamLogs
+// reads.
+type publicStore struct {
+	store.Store
+	mu          sync.Mutex
+	deployments []*models.Deployment
+	logs        []*models.LogEntry
+}
+
+func (s *publicStore) Deployments() store.DeploymentStore { return publicDeployments{s: s} }
+func (s *publicStore) Logs() store.LogStore               { return publicLogs{s: s} }
+
+func (s *publicStore) addLog(entry *models.LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, entry)
+}
+
+type publicDeployments struct {
+	store.DeploymentStore
+	s *publicStore
+}
+
+func (m publicDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	for _, d := range m.s.deployments {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, errors.New("deployment not found")
+}
+
+func (m publicDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	var deployments []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.AppID == appID {
+			deployments = append(deployments, d)
+		}
+	}
+	return deployments, nil
+}
+
+type publicLogs struct {
+	store.LogStore
+	s *publicStore
+}
+
+func (m publicLogs) List(ctx context.Context, deploymentID string, limit int) ([]*models.LogEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	var entries []*models.LogEntry
+	for i := len(m.s.logs) - 1; i >= 0 && len(entries) < limit; i-- {
+		if m.s.logs[i].DeploymentID == deploymentID {
+			entries = append(entries, m.s.logs[i])
+		}
+	}
+	return entries, nil
+}
+
+func (m publicLogs) Search(ctx context.Context, filter models.LogFilter) ([]*models.LogEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	var entries []*models.LogEntry
+	for _, entry := range m.s.logs {
+		if entry.DeploymentID == filter.DeploymentID && cursorAfter(models.CursorOf(entry), *filter.After) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b *models.LogEntry) int {
+		if cursorAfter(models.CursorOf(a), models.CursorOf(b)) {
+			return 1
+		}
+		return -1
+	})
+	return entries[:min(len(entries), filter.Limit)], nil
+}
+
+// fakeHTTPAPI serves the HTTP API routes the public API calls, recording the
+// requests made.
+type fakeHTTPAPI struct {
+	chi.Router
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []map[string]any
+}
+
+func newFakeHTTPAPI() *fakeHTTPAPI {
+	api := &fakeHTTPAPI{Router: chi.NewRouter()}
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			api.mu.Lock()
+			api.requests = append(api.requests, r)
+			api.bodies = append(api.bodies, body)
+			api.mu.Unlock()
+			if r.Header.Get("Authorization") != "Bearer "+testAPIKey {
+				writeTestJSON(w, http.StatusUnauthorized, map[string]string{"code": "unauthorized", "message": "Invalid API key"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	shop := &models.App{
+		ID:        "app-1",
+		Name:      "shop",
+		OrgID:     "org-1",
+		Services:  []models.ServiceConfig{{Name: "web", SourceType: models.SourceTypeGit, GitRepo: "github.com/acme/shop", Replicas: 2}},
+		CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	api.Get("/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, []*models.App{shop})
+	})
+	api.Get("/v1/apps/{appID}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "appID") != "shop" {
+			writeTestJSON(w, http.StatusForbidden, map[string]string{"code": "forbidden", "message": "Access denied"})
+			return
+		}
+		writeTestJSON(w, http.StatusOK, shop)
+	})
+	api.Post("/v1/apps/{appID}/services/{serviceName}/deploy", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusAccepted, &models.Deployment{
+			ID:          "dep-1",
+			AppID:       "app-1",
+			ServiceName: chi.URLParam(r, "serviceName"),
+			Version:     3,
+			Status:      models.DeploymentStatusPending,
+		})
+	})
+	return api
+}
+
+func (a *fakeHTTPAPI) lastRequest() (*http.Request, map[string]any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[len(a.requests)-1], a.bodies[len(a.bodies)-1]
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// startPublicServer serves the public API with handler and st, returning
+// the server's address.
+func startPublicServer(t *testing.T, handler http.Handler, st store.Store) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv, err := NewServer(DefaultConfig(), nil, &mockAuthService{validAPIKey: testAPIKey}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	public := NewPublicAPI(handler, st, slog.Default())
+	public.pollInterval = 10 * time.Millisecond
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(srv.authInterceptor()),
+		grpc.ChainStreamInterceptor(srv.streamAuthInterceptor()),
+	)
+	narvanav1.RegisterAppServiceServer(grpcServer, public)
+	narvanav1.RegisterDeploymentServiceServer(grpcServer, public)
+	narvanav1.RegisterLogServiceServer(grpcServer, public)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	return lis.Addr().String()
+}
+
+func newTestClient(t *testing.T, addr, apiKey string) *client.Client {
+	t.Helper()
+	c, err := client.New(addr, apiKey, client.WithInsecure())
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestPublicAPI(t *testing.T) {
+	api := newFakeHTTPAPI()
+	addr := startPublicServer(t, api, nil)
+	c := newTestClient(t, addr, testAPIKey)
+	ctx := context.Background()
+
+	apps, err := c.ListApps(ctx, &narvanav1.ListAppsRequest{OrgId: "org-1"})
+	if err != nil {
+		t.Fatalf("ListApps: %v", err)
+	}
+	if len(apps.Apps) != 1 || apps.Apps[0].Name != "shop" || apps.Apps[0].Services[0].Replicas != 2 || apps.Apps[0].CreatedAt.AsTime().Day() != 1 {
+		t.Errorf("apps = %v", apps.Apps)
+	}
+	if req, _ := api.lastRequest(); req.Header.Get("X-Org-ID") != "org-1" {
+		t.Errorf("X-Org-ID = %q, want org-1", req.Header.Get("X-Org-ID"))
+	}
+
+	// Errors of the HTTP API are returned with its message
+	_, err = c.GetApp(ctx, &narvanav1.GetAppRequest{App: "billing"})
+	if st, _ := status.FromError(err); st.Code() != codes.PermissionDenied || st.Message() != "Access denied" {
+		t.Errorf("GetApp of another team's app: %v, want permission denied", err)
+	}
+
+	resp, err := c.Deploy(ctx, &narvanav1.DeployRequest{
+		App:                  "shop",
+		Service:              "web",
+		GitRef:               "v1.2.0",
+		FreezeOverrideReason: "hotfix",
+		IdempotencyKey:       "release-42",
+	})
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if resp.Deployment.Id != "dep-1" || resp.Deployment.Status != "pending" || resp.Deployment.Version != 3 {
+		t.Errorf("deployment = %v", resp.Deployment)
+	}
+	req, body := api.lastRequest()
+	if req.URL.Path != "/v1/apps/shop/services/web/deploy" || req.Header.Get("Idempotency-Key") != "release-42" {
+		t.Errorf("deploy request = %s %s, Idempotency-Key %q", req.Method, req.URL.Path, req.Header.Get("Idempotency-Key"))
+	}
+	if body["git_ref"] != "v1.2.0" || body["freeze_override"].(map[string]any)["reason"] != "hotfix" {
+		t.Errorf("deploy body = %v", body)
+	}
+
+	if _, err := c.GetApp(ctx, &narvanav1.GetAppRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetApp without an app: %v, want invalid argument", err)
+	}
+}
+
+func TestPublicAPIAuthentication(t *testing.T) {
+	api := newFakeHTTPAPI()
+	addr := startPublicServer(t, api, nil)
+	ctx := context.Background()
+
+	c := newTestClient(t, addr, "nrv_revoked")
+	if _, err := c.ListApps(ctx, &narvanav1.ListAppsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("invalid key: %v, want unauthenticated", err)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("creating connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := narvanav1.NewAppServiceClient(conn).ListApps(ctx, &narvanav1.ListAppsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no key: %v, want unauthenticated", err)
+	}
+	stream, err := narvanav1.NewLogServiceClient(conn).StreamLogs(ctx, &narvanav1.StreamLogsRequest{App: "shop"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("streaming logs without a key: %v, want unauthenticated", err)
+	}
+
+	if len(api.requests) != 0 {
+		t.Errorf("unauthenticated calls reached the HTTP API: %d requests", len(api.requests))
+	}
+}
+
+func TestPublicStreamLogs(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	st := &publicStore{
+		deployments: []*models.Deployment{
+			{ID: "dep-2", AppID: "app-1", ServiceName: "web"},
+			{ID: "dep-1", AppID: "app-1", ServiceName: "web"},
+			{ID: "dep-other", AppID: "app-2", ServiceName: "web"},
+		},
+		logs: []*models.LogEntry{
+			{ID: "l1", DeploymentID: "dep-1", Message: "old deployment", Timestamp: now},
+			{ID: "l2", DeploymentID: "dep-2", Message: "starting", Timestamp: now.Add(time.Second)},
+			{ID: "l3", DeploymentID: "dep-2", Message: "listening on :8080", Timestamp: now.Add(2 * time.Second)},
+		},
+	}
+	addr := startPublicServer(t, newFakeHTTPAPI(), st)
+	c := newTestClient(t, addr, testAPIKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := c.StreamLogs(ctx, &narvanav1.StreamLogsRequest{App: "shop", Service: "web"})
+	if err != nil {
+		t.Fatalf("StreamLogs: %v", err)
+	}
+	recv := func() string {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("receiving log entry: %v", err)
+		}
+		return resp.Entry.Message
+	}
+
+	// The latest deployment's entries are streamed oldest first, then new ones
+	if got := []string{recv(), recv()}; got[0] != "starting" || got[1] != "listening on :8080" {
+		t.Errorf("entries = %q", got)
+	}
+	st.addLog(&models.LogEntry{ID: "l4", DeploymentID: "dep-2", Message: "ready", Timestamp: now.Add(3 * time.Second)})
+	if got := recv(); got != "ready" {
+		t.Errorf("new entry = %q, want ready", got)
+	}
+
+	// A burst larger than a page, sharing the last entry's timestamp, is
+	// streamed whole and in order
+	for i := 0; i < 250; i++ {
+		st.addLog(&models.LogEntry{ID: fmt.Sprintf("l5-%03d", i), DeploymentID: "dep-2", Message: fmt.Sprintf("line %d", i), Timestamp: now.Add(3 * time.Second)})
+	}
+	for i := 0; i < 250; i++ {
+		if got, want := recv(), fmt.Sprintf("line %d", i); got != want {
+			t.Fatalf("burst entry = %q, want %q", got, want)
+		}
+	}
+
+	stream, err = c.StreamLogs(ctx, &narvanav1.StreamLogsRequest{App: "shop", DeploymentId: "dep-other"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("streaming another app's deployment: %v, want not found", err)
+	}
+}
@@ -20,8 +20,13 @@ import (
 
 	pb "github.com/narvanalabs/control-plane/api/proto"
 	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/logs"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/internal/telemetry"
+	"github.com/narvanalabs/control-plane/internal/tracing"
+	narvanav1 "github.com/narvanalabs/control-plane/pkg/client/narvana/v1"
 )
 
 // contextKey is a type for context keys used in this package.
@@ -30,6 +35,9 @@ type contextKey string
 const (
 	// nodeIDKey is the context key for the authenticated node ID.
 	nodeIDKey contextKey = "node_id"
+
+	// apiKeyKey is the context key for the API key of a public API call.
+	apiKeyKey contextKey = "api_key"
 )
 
 // Config holds the gRPC server configuration.
@@ -41,6 +49,9 @@ type Config struct {
 	KeepaliveTime        time.Duration
 	KeepaliveTimeout     time.Duration
 	MaxRecvMsgSize       int
+	// HeartbeatInterval is how often agents are asked to send heartbeats
+	// on their NodeAgent stream.
+	HeartbeatInterval time.Duration
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -51,12 +62,14 @@ func DefaultConfig() *Config {
 		KeepaliveTime:        30 * time.Second,
 		KeepaliveTimeout:     10 * time.Second,
 		MaxRecvMsgSize:       16 * 1024 * 1024, // 16MB
+		HeartbeatInterval:    10 * time.Second,
 	}
 }
 
 // AuthService defines the interface for authentication operations.
 type AuthService interface {
 	ValidateToken(tokenString string) (*auth.Claims, error)
+	ValidateAPIKey(ctx context.Context, apiKey string) (*auth.User, error)
 }
 
 // HealthChecker defines the interface for checking service health.
@@ -77,6 +90,13 @@ type Server struct {
 	grpcServer    *grpc.Server
 	healthChecker HealthChecker
 	nodeManager   *NodeManager
+	logIngester   *logs.Ingester
+	events        events.Publisher
+	publicAPI     *PublicAPI
+
+	// openStreams and streamsTotal count streams by method; nil records none.
+	openStreams  *telemetry.GaugeVec
+	streamsTotal *telemetry.CounterVec
 
 	// Server state
 	serving atomic.Bool
@@ -118,6 +138,33 @@ func (s *Server) SetNodeManager(nm *NodeManager) {
 	s.nodeManager = nm
 }
 
+// SetLogIngester routes pushed log entries through a batching ingester
+// instead of inserting them one at a time.
+func (s *Server) SetLogIngester(ing *logs.Ingester) {
+	s.logIngester = ing
+}
+
+// SetMetrics registers the number of open and finished streams, by method,
+// in reg. It must be called before Start.
+func (s *Server) SetMetrics(reg *telemetry.Registry) {
+	s.openStreams = reg.Gauge("narvana_grpc_streams_open",
+		"gRPC streams open, such as node agent connections, by method.", "method")
+	s.streamsTotal = reg.Counter("narvana_grpc_streams_total",
+		"gRPC streams that have ended, by method and status code.", "method", "code")
+}
+
+// SetPublicAPI serves the public narvana.v1 API for automation alongside
+// the agent API. It must be called before Start.
+func (s *Server) SetPublicAPI(p *PublicAPI) {
+	s.publicAPI = p
+}
+
+// SetEvents sets where the deployment status changes agents report, and
+// the health transitions of their nodes, are published.
+func (s *Server) SetEvents(p events.Publisher) {
+	s.events = p
+}
+
 // buildServerOptions constructs the gRPC server options.
 func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 	opts := []grpc.ServerOption{
@@ -131,11 +178,13 @@ func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 			MinTime:             10 * time.Second,
 			PermitWithoutStream: true,
 		}),
+		grpc.StatsHandler(tracing.ServerHandler()),
 		grpc.ChainUnaryInterceptor(
 			s.loggingInterceptor(),
 			s.authInterceptor(),
 		),
 		grpc.ChainStreamInterceptor(
+			s.streamMetricsInterceptor(),
 			s.streamLoggingInterceptor(),
 			s.streamAuthInterceptor(),
 		),
@@ -167,6 +216,12 @@ func (s *Server) Start(ctx context.Context) error {
 	s.grpcServer = grpc.NewServer(opts...)
 	pb.RegisterControlPlaneServiceServer(s.grpcServer, s)
 	pb.RegisterHealthServer(s.grpcServer, s)
+	if s.publicAPI != nil {
+		narvanav1.RegisterAppServiceServer(s.grpcServer, s.publicAPI)
+		narvanav1.RegisterDeploymentServiceServer(s.grpcServer, s.publicAPI)
+		narvanav1.RegisterBuildServiceServer(s.grpcServer, s.publicAPI)
+		narvanav1.RegisterLogServiceServer(s.grpcServer, s.publicAPI)
+	}
 
 	addr := fmt.Sprintf(":%d", s.config.Port)
 	lis, err := net.Listen("tcp", addr)
@@ -250,6 +305,30 @@ func extractToken(ctx context.Context) (string, error) {
 	return auth.ExtractBearerToken(tokens[0]), nil
 }
 
+// extractAPIKey extracts the API key of a public API call from gRPC
+// metadata, sent as a bearer token or in an x-api-key header.
+func extractAPIKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if keys := md.Get("x-api-key"); len(keys) > 0 {
+		return keys[0]
+	}
+	if tokens := md.Get("authorization"); len(tokens) > 0 {
+		if token := auth.ExtractBearerToken(tokens[0]); auth.IsAPIKey(token) {
+			return token
+		}
+	}
+	return ""
+}
+
+// apiKeyFromContext returns the API key of an authenticated public API call.
+func apiKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyKey).(string)
+	return key
+}
+
 // NodeIDFromContext extracts the node ID from the context.
 func NodeIDFromContext(ctx context.Context) (string, bool) {
 	nodeID, ok := ctx.Value(nodeIDKey).(string)
@@ -265,17 +344,48 @@ func protoNodeInfoToModel(info *pb.NodeInfo) *models.Node {
 		GRPCPort: int(info.GrpcPort),
 	}
 
-	if info.Resources != nil {
-		node.Resources = &models.NodeResources{
-			CPUTotal:        info.Resources.CpuTotal,
-			CPUAvailable:    info.Resources.CpuAvailable,
-			MemoryTotal:     info.Resources.MemoryTotal,
-			MemoryAvailable: info.Resources.MemoryAvailable,
-			DiskTotal:       info.Resources.DiskTotal,
-			DiskAvailable:   info.Resources.DiskAvailable,
-		}
-	}
-
+	node.Resources = protoResourcesToModel(info.Resources)
 	node.CachedPaths = info.CachedPaths
+	for _, c := range info.Capabilities {
+		node.Capabilities = append(node.Capabilities, models.NodeCapability(c))
+	}
 	return node
 }
+
+// protoResourcesToModel converts proto ResourceMetrics to models.NodeResources.
+func protoResourcesToModel(r *pb.ResourceMetrics) *models.NodeResources {
+	if r == nil {
+		return nil
+	}
+	return &models.NodeResources{
+		CPUTotal:        r.CpuTotal,
+		CPUAvailable:    r.CpuAvailable,
+		MemoryTotal:     r.MemoryTotal,
+		MemoryAvailable: r.MemoryAvailable,
+		DiskTotal:       r.DiskTotal,
+		DiskAvailable:   r.DiskAvailable,
+	}
+}
+
+// protoDiskMetricsToModel converts proto NodeDiskMetrics to models.NodeDiskMetrics.
+func protoDiskMetricsToModel(m *pb.NodeDiskMetrics) *models.NodeDiskMetrics {
+	if m == nil {
+		return nil
+	}
+	convert := func(d *pb.DiskStats) *models.DiskStats {
+		if d == nil {
+			return nil
+		}
+		return &models.DiskStats{
+			Path:         d.Path,
+			Total:        d.Total,
+			Used:         d.Used,
+			Available:    d.Available,
+			UsagePercent: d.UsagePercent,
+		}
+	}
+	return &models.NodeDiskMetrics{
+		NixStore:         convert(m.NixStore),
+		ContainerStorage: convert(m.ContainerStorage),
+	}
+}
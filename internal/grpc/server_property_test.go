@@ -28,7 +28,15 @@ import (
 
 // mockAuthService is a mock implementation of AuthService for testing.
 type mockAuthService struct {
-	validToken string
+	validToken  string
+	validAPIKey string
+}
+
+func (m *mockAuthService) ValidateAPIKey(ctx context.Context, apiKey string) (*auth.User, error) {
+	if m.validAPIKey != "" && apiKey == m.validAPIKey {
+		return &auth.User{ID: "user-1", Email: "dev@example.com", APIKeyID: "key-1"}, nil
+	}
+	return nil, auth.ErrInvalidToken
 }
 
 func (m *mockAuthService) ValidateToken(tokenString string) (*auth.Claims, error) {
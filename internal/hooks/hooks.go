@@ -0,0 +1,150 @@
+// Package hooks calls apps' lifecycle hooks: user-specified URLs, or a
+// designated hook service of the app, that receive a JSON payload when a
+// deployment goes live, a service is scaled or a secret changes.
+//
+// Like notifications, hook calls are not made inline: a Trigger queues one
+// delivery per subscribed hook in the database and a Worker running in the
+// API server sends them with exponential backoff, so a slow or failing hook
+// never holds up the deployment, scaling or secret change that fired it.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Trigger queues lifecycle events for delivery to the enabled hooks of the
+// app they happened in.
+type Trigger struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewTrigger creates a new trigger.
+func NewTrigger(st store.Store, logger *slog.Logger) *Trigger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Trigger{store: st, logger: logger}
+}
+
+// Fire queues a payload for every enabled hook of its app subscribed to its
+// event. Failures are logged rather than returned so that a hook problem
+// never fails the change that fired it.
+func (t *Trigger) Fire(ctx context.Context, payload *models.AppHookPayload) {
+	t.enqueue(ctx, payload)
+}
+
+// HandleTransition fires on_deploy_success for deployment transitions
+// published on the event bus. Unlike Fire it also returns failures to queue
+// the payload, so the bus delivers the transition again; hooks queued it
+// before the failure may then be called twice.
+func (t *Trigger) HandleTransition(ctx context.Context, tr *events.Transition) error {
+	if tr.Kind != events.TransitionDeploymentStatus {
+		return nil
+	}
+	if payload := deploySuccessPayload(tr.Deployment, tr.PreviousStatus); payload != nil {
+		return t.enqueue(ctx, payload)
+	}
+	return nil
+}
+
+// enqueue queues a payload for the subscribed hooks of its app, logging and
+// returning failures.
+func (t *Trigger) enqueue(ctx context.Context, payload *models.AppHookPayload) error {
+	hooks, err := t.store.AppHooks().ListByApp(ctx, payload.AppID)
+	if err != nil {
+		t.logger.Error("failed to list app hooks", "error", err, "app_id", payload.AppID, "event", payload.Event)
+		return fmt.Errorf("listing app hooks: %w", err)
+	}
+
+	var subscribed []*models.AppHook
+	for _, h := range hooks {
+		if h.Enabled && h.Subscribes(payload.Event) {
+			subscribed = append(subscribed, h)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	if payload.OccurredAt.IsZero() {
+		payload.OccurredAt = time.Now()
+	}
+	if payload.AppName == "" {
+		if app, err := t.store.Apps().Get(ctx, payload.AppID); err == nil && app != nil {
+			payload.AppName = app.Name
+		}
+	}
+
+	var errs []error
+	for _, h := range subscribed {
+		delivery := &models.AppHookDelivery{HookID: h.ID, Payload: *payload}
+		if err := t.store.AppHooks().EnqueueDelivery(ctx, delivery); err != nil {
+			t.logger.Error("failed to queue app hook",
+				"error", err,
+				"hook_id", h.ID,
+				"event", payload.Event,
+			)
+			errs = append(errs, fmt.Errorf("queueing %s for hook %s: %w", payload.Event, h.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DeploymentStatusChanged fires on_deploy_success when a deployment moves
+// into the running status from another. Cron runs are not deploys and are skipped.
+func (t *Trigger) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if payload := deploySuccessPayload(deployment, previous); payload != nil {
+		t.Fire(ctx, payload)
+	}
+}
+
+// deploySuccessPayload returns the on_deploy_success payload for a
+// deployment status change, or nil if the deployment did not go live.
+func deploySuccessPayload(deployment *models.Deployment, previous models.DeploymentStatus) *models.AppHookPayload {
+	if deployment.Status != models.DeploymentStatusRunning || previous == models.DeploymentStatusRunning || deployment.IsCronRun() {
+		return nil
+	}
+	return &models.AppHookPayload{
+		Event:        models.AppHookDeploySuccess,
+		AppID:        deployment.AppID,
+		ServiceName:  deployment.ServiceName,
+		DeploymentID: deployment.ID,
+		Version:      deployment.Version,
+		GitRef:       deployment.GitRef,
+		GitCommit:    deployment.GitCommit,
+	}
+}
+
+// Scaled fires on_scale when a service's replica count changed.
+func (t *Trigger) Scaled(ctx context.Context, appID, serviceName string, previous, replicas int) {
+	if previous == replicas {
+		return
+	}
+	t.Fire(ctx, &models.AppHookPayload{
+		Event:            models.AppHookScale,
+		AppID:            appID,
+		ServiceName:      serviceName,
+		Replicas:         &replicas,
+		PreviousReplicas: &previous,
+	})
+}
+
+// SecretChanged fires on_secret_change for a secret that was set or deleted.
+// Only the key is sent, never the value.
+func (t *Trigger) SecretChanged(ctx context.Context, appID, key, action string) {
+	t.Fire(ctx, &models.AppHookPayload{
+		Event:     models.AppHookSecretChange,
+		AppID:     appID,
+		SecretKey: key,
+		Action:    action,
+	})
+}
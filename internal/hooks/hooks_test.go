@@ -0,0 +1,261 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing the stores hooks use.
+type memStore struct {
+	store.Store
+	hooks       *memHooks
+	deployments []*models.Deployment
+	nodes       map[string]*models.Node
+}
+
+func newMemStore(hooks ...*models.AppHook) *memStore {
+	return &memStore{hooks: &memHooks{hooks: hooks}, nodes: map[string]*models.Node{}}
+}
+
+func (s *memStore) AppHooks() store.AppHookStore       { return s.hooks }
+func (s *memStore) Apps() store.AppStore               { return memApps{} }
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) Nodes() store.NodeStore             { return memNodes{s: s} }
+
+type memApps struct{ store.AppStore }
+
+func (memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	return &models.App{ID: id, Name: "shop"}, nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	return m.s.deployments, nil
+}
+
+type memNodes struct {
+	store.NodeStore
+	s *memStore
+}
+
+func (m memNodes) Get(ctx context.Context, id string) (*models.Node, error) {
+	return m.s.nodes[id], nil
+}
+
+type memHooks struct {
+	store.AppHookStore
+	hooks      []*models.AppHook
+	deliveries []*models.AppHookDelivery
+	lastError  map[string]string
+}
+
+func (m *memHooks) ListByApp(ctx context.Context, appID string) ([]*models.AppHook, error) {
+	var result []*models.AppHook
+	for _, h := range m.hooks {
+		if h.AppID == appID {
+			result = append(result, h)
+		}
+	}
+	return result, nil
+}
+
+func (m *memHooks) Get(ctx context.Context, id string) (*models.AppHook, error) {
+	for _, h := range m.hooks {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memHooks) RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	if m.lastError == nil {
+		m.lastError = map[string]string{}
+	}
+	m.lastError[id] = errMsg
+	return nil
+}
+
+func (m *memHooks) EnqueueDelivery(ctx context.Context, d *models.AppHookDelivery) error {
+	d.ID = "d" + strconv.Itoa(len(m.deliveries))
+	d.Status = models.AppHookDeliveryPending
+	d.NextAttemptAt = time.Now()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memHooks) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.AppHookDelivery, error) {
+	var due []*models.AppHookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == models.AppHookDeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memHooks) UpdateDelivery(ctx context.Context, d *models.AppHookDelivery) error {
+	return nil
+}
+
+func TestAppHookValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		hook models.AppHook
+		ok   bool
+	}{
+		{"url", models.AppHook{Name: "purge", URL: "https://cdn.example.com/purge"}, true},
+		{"service", models.AppHook{Name: "purge", ServiceName: "hooks", Path: "/purge"}, true},
+		{"no name", models.AppHook{URL: "https://example.com"}, false},
+		{"no target", models.AppHook{Name: "purge"}, false},
+		{"both targets", models.AppHook{Name: "purge", URL: "https://example.com", ServiceName: "hooks"}, false},
+		{"relative url", models.AppHook{Name: "purge", URL: "/purge"}, false},
+		{"bad path", models.AppHook{Name: "purge", ServiceName: "hooks", Path: "purge"}, false},
+		{"unknown event", models.AppHook{Name: "purge", URL: "https://example.com", Events: []models.AppHookEvent{"on_build"}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.hook.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestTriggerQueuesForSubscribedHooks(t *testing.T) {
+	st := newMemStore(
+		&models.AppHook{ID: "all", AppID: "app-1", Enabled: true},
+		&models.AppHook{ID: "scale", AppID: "app-1", Enabled: true, Events: []models.AppHookEvent{models.AppHookScale}},
+		&models.AppHook{ID: "off", AppID: "app-1"},
+		&models.AppHook{ID: "other", AppID: "app-2", Enabled: true},
+	)
+	tr := NewTrigger(st, nil)
+	ctx := context.Background()
+
+	if err := tr.HandleTransition(ctx, &events.Transition{
+		Kind:           events.TransitionDeploymentStatus,
+		Deployment:     &models.Deployment{ID: "d1", AppID: "app-1", ServiceName: "api", Version: 3, Status: models.DeploymentStatusRunning},
+		PreviousStatus: models.DeploymentStatusStarting,
+	}); err != nil {
+		t.Fatalf("HandleTransition() = %v", err)
+	}
+	tr.DeploymentStatusChanged(ctx,
+		&models.Deployment{ID: "d1", AppID: "app-1", Status: models.DeploymentStatusRunning}, models.DeploymentStatusRunning)
+	tr.Scaled(ctx, "app-1", "api", 2, 2)
+
+	got := st.hooks.deliveries
+	if len(got) != 1 || got[0].HookID != "all" {
+		t.Fatalf("deliveries = %+v, want one for hook all", got)
+	}
+	p := got[0].Payload
+	if p.Event != models.AppHookDeploySuccess || p.AppName != "shop" || p.Version != 3 || p.OccurredAt.IsZero() {
+		t.Errorf("payload = %+v", p)
+	}
+
+	tr.Scaled(ctx, "app-1", "api", 2, 5)
+	if len(st.hooks.deliveries) != 3 {
+		t.Fatalf("scaling queued %d deliveries, want 2", len(st.hooks.deliveries)-1)
+	}
+	if p := st.hooks.deliveries[2].Payload; *p.Replicas != 5 || *p.PreviousReplicas != 2 {
+		t.Errorf("scale payload = %+v", p)
+	}
+}
+
+func TestWorkerSignsAndRetries(t *testing.T) {
+	var gotSignature, gotEvent string
+	var gotBody []byte
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		gotSignature = r.Header.Get(HeaderSignature)
+		gotEvent = r.Header.Get(HeaderEvent)
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	st := newMemStore(&models.AppHook{ID: "h1", AppID: "app-1", Enabled: true, URL: srv.URL, Secret: "s3cret"})
+	NewTrigger(st, nil).SecretChanged(context.Background(), "app-1", "DATABASE_URL", "set")
+
+	cfg := notifications.DefaultWorkerConfig()
+	w := NewWorker(st, NewSender(st, srv.Client()), cfg, nil)
+
+	if n := w.ProcessDue(context.Background()); n != 0 {
+		t.Fatalf("first attempt delivered %d, want 0", n)
+	}
+	d := st.hooks.deliveries[0]
+	if d.Status != models.AppHookDeliveryPending || d.Attempts != 1 || !strings.Contains(d.LastError, "503") {
+		t.Fatalf("after failure: %+v", d)
+	}
+	if st.hooks.lastError["h1"] == "" {
+		t.Error("hook error not recorded")
+	}
+
+	d.NextAttemptAt = time.Now()
+	if n := w.ProcessDue(context.Background()); n != 1 {
+		t.Fatalf("retry delivered %d, want 1", n)
+	}
+	if gotEvent != string(models.AppHookSecretChange) {
+		t.Errorf("event header = %q", gotEvent)
+	}
+	if gotSignature != Sign("s3cret", gotBody) {
+		t.Errorf("signature = %q, want %q", gotSignature, Sign("s3cret", gotBody))
+	}
+	var payload models.AppHookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil || payload.SecretKey != "DATABASE_URL" || payload.Action != "set" {
+		t.Errorf("payload = %s (%v)", gotBody, err)
+	}
+}
+
+func TestSenderCallsHookService(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	host, portStr, _ := net.SplitHostPort(u.Host)
+	port, _ := strconv.Atoi(portStr)
+
+	hook := &models.AppHook{ID: "h1", AppID: "app-1", Enabled: true, ServiceName: "hooks", Path: "/purge"}
+	st := newMemStore(hook)
+	st.nodes["node-1"] = &models.Node{ID: "node-1", Address: host}
+	st.deployments = []*models.Deployment{
+		{ServiceName: "hooks", Version: 1, Status: models.DeploymentStatusStopped, NodeID: "node-0"},
+		{ServiceName: "hooks", Version: 2, Status: models.DeploymentStatusRunning, NodeID: "node-1",
+			Config: &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: port}}}},
+		{ServiceName: "api", Version: 7, Status: models.DeploymentStatusRunning, NodeID: "node-1"},
+	}
+
+	s := NewSender(st, srv.Client())
+	if err := s.Send(context.Background(), hook, &models.AppHookDelivery{ID: "d1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if gotPath != "/purge" {
+		t.Errorf("path = %q, want /purge", gotPath)
+	}
+
+	st.deployments = st.deployments[:1]
+	if err := s.Send(context.Background(), hook, &models.AppHookDelivery{ID: "d2"}); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Send() without running service error = %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Headers sent with every hook call.
+const (
+	HeaderEvent     = "X-Narvana-Event"
+	HeaderDelivery  = "X-Narvana-Delivery"
+	HeaderSignature = "X-Narvana-Signature"
+)
+
+// Sign returns the signature header value of a payload for a hook secret:
+// "sha256=" followed by the hex HMAC-SHA256 of the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender posts payloads to hooks.
+type Sender struct {
+	store  store.Store
+	client *http.Client
+}
+
+// NewSender creates a sender that makes HTTP requests with client. A nil
+// client uses one with a 10 second timeout.
+func NewSender(st store.Store, client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{store: st, client: client}
+}
+
+// Send posts a delivery's payload to its hook and treats any non-2xx
+// response as an error.
+func (s *Sender) Send(ctx context.Context, hook *models.AppHook, delivery *models.AppHookDelivery) error {
+	target, err := s.target(ctx, hook)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.Payload.Event))
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hook returned %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return nil
+}
+
+// target returns the URL a hook is called at. A hook service is called on
+// its primary port on the node running its latest running deployment.
+func (s *Sender) target(ctx context.Context, hook *models.AppHook) (string, error) {
+	if hook.URL != "" {
+		return hook.URL, nil
+	}
+
+	deployments, err := s.store.Deployments().List(ctx, hook.AppID)
+	if err != nil {
+		return "", fmt.Errorf("listing deployments: %w", err)
+	}
+	var running *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName != hook.ServiceName || d.Status != models.DeploymentStatusRunning || d.NodeID == "" {
+			continue
+		}
+		if running == nil || d.Version > running.Version {
+			running = d
+		}
+	}
+	if running == nil {
+		return "", fmt.Errorf("hook service %s is not running", hook.ServiceName)
+	}
+
+	node, err := s.store.Nodes().Get(ctx, running.NodeID)
+	if err != nil || node == nil {
+		return "", fmt.Errorf("hook service %s: node %s not found", hook.ServiceName, running.NodeID)
+	}
+
+	port := 8080
+	if running.Config != nil && len(running.Config.Ports) > 0 {
+		port = running.Config.Ports[0].ContainerPort
+	}
+	path := hook.Path
+	if path == "" {
+		path = "/"
+	}
+	return "http://" + net.JoinHostPort(node.Address, strconv.Itoa(port)) + path, nil
+}
+
+// Worker sends queued hook deliveries and reschedules failed ones. It is
+// configured like the notification worker.
+type Worker struct {
+	store  store.Store
+	sender *Sender
+	config notifications.WorkerConfig
+	logger *slog.Logger
+}
+
+// NewWorker creates a hook delivery worker.
+func NewWorker(st store.Store, sender *Sender, cfg notifications.WorkerConfig, logger *slog.Logger) *Worker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Worker{
+		store:  st,
+		sender: sender,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Run processes due deliveries every poll interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.ProcessDue(ctx)
+		}
+	}
+}
+
+// ProcessDue claims and sends one batch of due deliveries and returns how
+// many were delivered.
+func (w *Worker) ProcessDue(ctx context.Context) int {
+	deliveries, err := w.store.AppHooks().ClaimDueDeliveries(ctx, w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		w.logger.Error("failed to claim app hook deliveries", "error", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, d := range deliveries {
+		if w.deliver(ctx, d) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliver sends a single delivery and records the outcome on the delivery and its hook.
+func (w *Worker) deliver(ctx context.Context, d *models.AppHookDelivery) bool {
+	hook, err := w.store.AppHooks().Get(ctx, d.HookID)
+	if err != nil {
+		w.logger.Error("failed to load app hook", "error", err, "hook_id", d.HookID)
+		return false
+	}
+
+	now := time.Now()
+	d.Attempts++
+	switch {
+	case hook == nil || !hook.Enabled:
+		// The hook was disabled after the event was queued; drop it.
+		d.Status = models.AppHookDeliveryFailed
+		d.LastError = "hook disabled"
+	default:
+		sendErr := w.sender.Send(ctx, hook, d)
+		if sendErr == nil {
+			d.Status = models.AppHookDeliveryDelivered
+			d.LastError = ""
+			d.DeliveredAt = &now
+		} else {
+			d.LastError = sendErr.Error()
+			if d.Attempts >= w.config.MaxAttempts {
+				d.Status = models.AppHookDeliveryFailed
+			} else {
+				d.NextAttemptAt = now.Add(notifications.Backoff(d.Attempts, w.config.BaseBackoff, w.config.MaxBackoff))
+			}
+			w.logger.Warn("app hook delivery failed",
+				"delivery_id", d.ID,
+				"hook_id", hook.ID,
+				"app_id", hook.AppID,
+				"attempt", d.Attempts,
+				"error", sendErr,
+			)
+		}
+		if err := w.store.AppHooks().RecordResult(ctx, hook.ID, now, d.LastError); err != nil {
+			w.logger.Error("failed to record app hook result", "error", err, "hook_id", hook.ID)
+		}
+	}
+
+	if err := w.store.AppHooks().UpdateDelivery(ctx, d); err != nil {
+		w.logger.Error("failed to update app hook delivery", "error", err, "delivery_id", d.ID)
+	}
+	return d.Status == models.AppHookDeliveryDelivered
+}
@@ -0,0 +1,298 @@
+// Package identity issues workload identity tokens: short-lived ES256 JWTs
+// naming the app, service and deployment a workload runs as. Node agents
+// request tokens for the deployments they run and hand them to the workload,
+// which presents them to the control plane API or to other services. The
+// public keys are published as a JWKS so anyone can verify the tokens.
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Token errors.
+var (
+	ErrInvalidToken    = errors.New("invalid workload identity token")
+	ErrInvalidAudience = errors.New("invalid workload identity audience")
+)
+
+// maxAudiences caps how many audiences a single token may name.
+const maxAudiences = 10
+
+// Config holds workload identity settings.
+type Config struct {
+	// Issuer is the iss claim of issued tokens, normally the public URL of
+	// the control plane. It is also the audience of tokens accepted by the API.
+	Issuer string
+	// TokenTTL is how long issued tokens are valid.
+	TokenTTL time.Duration
+}
+
+// DefaultConfig returns the default workload identity configuration.
+func DefaultConfig() Config {
+	return Config{
+		Issuer:   "http://localhost:8080",
+		TokenTTL: 15 * time.Minute,
+	}
+}
+
+// Claims are the claims of a workload identity token. The subject is
+// "app:<appID>:service:<service>"; the token is issued to one deployment of
+// that service on one node.
+type Claims struct {
+	jwt.RegisteredClaims
+	OrgID        string `json:"org_id,omitempty"`
+	AppID        string `json:"app_id"`
+	Service      string `json:"service"`
+	DeploymentID string `json:"deployment_id"`
+	NodeID       string `json:"node_id"`
+}
+
+// Subject returns the subject of tokens issued to a service.
+func Subject(appID, service string) string {
+	return "app:" + appID + ":service:" + service
+}
+
+// Issuer signs workload identity tokens with an ECDSA P-256 key.
+type Issuer struct {
+	config Config
+	key    *ecdsa.PrivateKey
+	keyID  string
+	now    func() time.Time
+}
+
+// NewIssuer creates an issuer signing with key.
+func NewIssuer(cfg Config, key *ecdsa.PrivateKey) *Issuer {
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = DefaultConfig().TokenTTL
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Issuer{
+		config: cfg,
+		key:    key,
+		keyID:  thumbprint(&key.PublicKey),
+		now:    time.Now,
+	}
+}
+
+// LoadOrCreateSigningKey reads the PEM-encoded ECDSA P-256 key at path,
+// generating and saving one if the file does not exist.
+func LoadOrCreateSigningKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing signing key %s: %w", path, err)
+		}
+		key, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok || key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("signing key %s is not a P-256 key", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encoding signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating signing key directory: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, fmt.Errorf("writing signing key: %w", err)
+	}
+	return key, nil
+}
+
+// Issuer returns the iss claim of issued tokens.
+func (i *Issuer) Issuer() string {
+	return i.config.Issuer
+}
+
+// Issue signs a token for a deployment of app. An empty audience issues a
+// token for the control plane API. It returns the token and its expiry.
+func (i *Issuer) Issue(app *models.App, deployment *models.Deployment, audience []string) (string, time.Time, error) {
+	if len(audience) == 0 {
+		audience = []string{i.config.Issuer}
+	}
+	if len(audience) > maxAudiences {
+		return "", time.Time{}, fmt.Errorf("%w: at most %d audiences may be requested", ErrInvalidAudience, maxAudiences)
+	}
+	for _, aud := range audience {
+		if strings.TrimSpace(aud) == "" {
+			return "", time.Time{}, fmt.Errorf("%w: audience must not be empty", ErrInvalidAudience)
+		}
+	}
+
+	now := i.now()
+	expiresAt := now.Add(i.config.TokenTTL)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.config.Issuer,
+			Subject:   Subject(app.ID, deployment.ServiceName),
+			Audience:  audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.New().String(),
+		},
+		OrgID:        app.OrgID,
+		AppID:        app.ID,
+		Service:      deployment.ServiceName,
+		DeploymentID: deployment.ID,
+		NodeID:       deployment.NodeID,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = i.keyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("signing workload token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks a token's signature, issuer and expiry and that it was
+// issued for audience.
+func (i *Issuer) Verify(token, audience string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if kid, _ := t.Header["kid"].(string); kid != i.keyID {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return &i.key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(i.config.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(i.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.AppID == "" || claims.Subject != Subject(claims.AppID, claims.Service) {
+		return nil, fmt.Errorf("%w: subject does not match the token's service", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// IsWorkloadToken reports whether token is a JWT signed by this issuer,
+// without verifying it. It tells workload tokens apart from user tokens.
+func (i *Issuer) IsWorkloadToken(token string) bool {
+	header, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+	var h struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return false
+	}
+	return h.Alg == jwt.SigningMethodES256.Alg() && h.Kid == i.keyID
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the key set verifying the issuer's tokens.
+func (i *Issuer) JWKS() JWKS {
+	x, y := coordinates(&i.key.PublicKey)
+	return JWKS{Keys: []JWK{{
+		KeyType:   "EC",
+		Curve:     "P-256",
+		X:         x,
+		Y:         y,
+		KeyID:     i.keyID,
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodES256.Alg(),
+	}}}
+}
+
+// Discovery is the subset of an OpenID provider configuration that lets
+// verifiers find the issuer's keys.
+type Discovery struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
+}
+
+// JWKSPath is the path below the issuer where the key set is served.
+const JWKSPath = "/.well-known/jwks.json"
+
+// Discovery returns the issuer's OpenID configuration.
+func (i *Issuer) Discovery() Discovery {
+	return Discovery{
+		Issuer:                           i.config.Issuer,
+		JWKSURI:                          i.config.Issuer + JWKSPath,
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{jwt.SigningMethodES256.Alg()},
+		ClaimsSupported:                  []string{"iss", "sub", "aud", "exp", "iat", "nbf", "jti", "org_id", "app_id", "service", "deployment_id", "node_id"},
+	}
+}
+
+// coordinates returns the base64url-encoded, fixed-width coordinates of a
+// P-256 public key.
+func coordinates(key *ecdsa.PublicKey) (string, string) {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return base64.RawURLEncoding.EncodeToString(x), base64.RawURLEncoding.EncodeToString(y)
+}
+
+// thumbprint returns the RFC 7638 JWK thumbprint of a P-256 public key,
+// used as its key ID.
+func thumbprint(key *ecdsa.PublicKey) string {
+	x, y := coordinates(key)
+	sum := sha256.Sum256([]byte(`{"crv":"P-256","kty":"EC","x":"` + x + `","y":"` + y + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package identity
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func newTestIssuer(t *testing.T) *Issuer {
+	t.Helper()
+	key, err := LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewIssuer(Config{Issuer: "https://narvana.example.com/", TokenTTL: time.Minute}, key)
+}
+
+var (
+	testApp        = &models.App{ID: "app-1", OrgID: "org-1"}
+	testDeployment = &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web", NodeID: "node-1"}
+)
+
+func TestIssueAndVerify(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	token, expiresAt, err := issuer.Issue(testApp, testDeployment, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("expires at %s, want within the TTL", expiresAt)
+	}
+	if !issuer.IsWorkloadToken(token) {
+		t.Error("IsWorkloadToken = false for an issued token")
+	}
+
+	claims, err := issuer.Verify(token, "https://narvana.example.com")
+	if err != nil {
+		t.Fatalf("verify with the default audience: %v", err)
+	}
+	if claims.Subject != "app:app-1:service:web" || claims.OrgID != "org-1" || claims.DeploymentID != "dep-1" || claims.NodeID != "node-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	if _, err := issuer.Verify(token, "https://billing.internal"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("verify with another audience = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyRejectsExpiredAndForeignTokens(t *testing.T) {
+	issuer := newTestIssuer(t)
+	token, _, err := issuer.Issue(testApp, testDeployment, []string{"billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := issuer.Verify(token, "billing"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("verify expired token = %v, want ErrInvalidToken", err)
+	}
+
+	other := newTestIssuer(t)
+	if other.IsWorkloadToken(token) {
+		t.Error("IsWorkloadToken = true for another issuer's token")
+	}
+	if _, err := other.Verify(token, "billing"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("verify with another key = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestIssueRejectsBadAudiences(t *testing.T) {
+	issuer := newTestIssuer(t)
+	if _, _, err := issuer.Issue(testApp, testDeployment, []string{" "}); !errors.Is(err, ErrInvalidAudience) {
+		t.Errorf("empty audience = %v, want ErrInvalidAudience", err)
+	}
+	if _, _, err := issuer.Issue(testApp, testDeployment, make([]string, maxAudiences+1)); !errors.Is(err, ErrInvalidAudience) {
+		t.Errorf("too many audiences = %v, want ErrInvalidAudience", err)
+	}
+}
+
+func TestLoadOrCreateSigningKeyPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity", "key")
+	first, err := LoadOrCreateSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadOrCreateSigningKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Equal(second) {
+		t.Error("reloaded key differs from the generated key")
+	}
+
+	jwks := NewIssuer(DefaultConfig(), first).JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != thumbprint(&second.PublicKey) {
+		t.Errorf("jwks = %+v, want the key's thumbprint as kid", jwks)
+	}
+}
@@ -241,3 +241,111 @@ func (c *Client) ListUserRepositories(ctx context.Context, token string) ([]map[
 
 	return repos, nil
 }
+
+// Commit is a single commit returned by the compare API.
+type Commit struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	HTMLURL string    `json:"html_url"`
+}
+
+// Comparison is the commit range between two refs.
+type Comparison struct {
+	TotalCommits int      `json:"total_commits"`
+	Commits      []Commit `json:"commits"` // Oldest first; GitHub returns at most 250
+	HTMLURL      string   `json:"html_url"`
+}
+
+// CompareCommits lists the commits reachable from head but not from base.
+// An empty token makes an unauthenticated request.
+func (c *Client) CompareCommits(ctx context.Context, token, owner, repo, base, head string) (*Comparison, error) {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/compare/%s...%s?per_page=250",
+		url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(base), url.PathEscape(head))
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Public repositories can be compared without a token, subject to rate limits
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to compare commits: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		TotalCommits int    `json:"total_commits"`
+		HTMLURL      string `json:"html_url"`
+		Commits      []struct {
+			SHA     string `json:"sha"`
+			HTMLURL string `json:"html_url"`
+			Commit  struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{
+		TotalCommits: result.TotalCommits,
+		HTMLURL:      result.HTMLURL,
+		Commits:      make([]Commit, 0, len(result.Commits)),
+	}
+	for _, rc := range result.Commits {
+		author := rc.Commit.Author.Name
+		if rc.Author != nil && rc.Author.Login != "" {
+			author = rc.Author.Login
+		}
+		comparison.Commits = append(comparison.Commits, Commit{
+			SHA:     rc.SHA,
+			Message: rc.Commit.Message,
+			Author:  author,
+			Date:    rc.Commit.Author.Date,
+			HTMLURL: rc.HTMLURL,
+		})
+	}
+
+	return comparison, nil
+}
+
+// ParseRepo extracts the owner and repository name from a GitHub repository
+// reference such as "github.com/owner/repo", "https://github.com/owner/repo.git"
+// or "git@github.com:owner/repo.git". It reports false for non-GitHub repositories.
+func ParseRepo(gitRepo string) (owner, repo string, ok bool) {
+	s := strings.TrimSpace(gitRepo)
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	s = strings.TrimPrefix(s, "git@")
+	s = strings.TrimPrefix(s, "www.")
+
+	rest, found := strings.CutPrefix(s, "github.com")
+	if !found || rest == "" || (rest[0] != '/' && rest[0] != ':') {
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.Trim(rest[1:], "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
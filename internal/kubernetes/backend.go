@@ -0,0 +1,477 @@
+// Package kubernetes runs services on an existing Kubernetes cluster. The
+// cluster is registered as a single node of its own node pool: services that
+// select the pool are scheduled to it like to any other node, and the backend
+// translates their deployments into Deployments, Services and Ingresses.
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config configures the cluster backend.
+type Config struct {
+	// NodeID is the node the cluster registers as; derived from the API
+	// server URL when empty.
+	NodeID string
+	// Pool is the node pool services select to run on the cluster.
+	Pool string
+	// APIServer is the API server URL; empty uses the cluster the control
+	// plane runs in.
+	APIServer string
+	TokenFile string
+	CAFile    string
+	// Namespace is where workloads are created.
+	Namespace string
+	// IngressClass routes service domains; empty creates no ingresses.
+	IngressClass string
+	// SyncInterval is how often the cluster's capacity and the status of its
+	// deployments are synced.
+	SyncInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Pool:         "kubernetes",
+		TokenFile:    "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		CAFile:       "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt",
+		Namespace:    "narvana",
+		SyncInterval: 15 * time.Second,
+	}
+}
+
+// Backend deploys to a Kubernetes cluster and reports the cluster as a node.
+type Backend struct {
+	client *Client
+	store  store.Store
+	config Config
+	nodeID string
+	events events.Publisher
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewBackend creates a backend for the cluster client talks to.
+func NewBackend(client *Client, st store.Store, cfg Config, logger *slog.Logger) *Backend {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	nodeID := cfg.NodeID
+	if nodeID == "" {
+		nodeID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(client.Server())).String()
+	}
+	return &Backend{
+		client: client,
+		store:  st,
+		config: cfg,
+		nodeID: nodeID,
+		logger: logger.With("node_id", nodeID, "pool", cfg.Pool),
+		now:    time.Now,
+	}
+}
+
+// NodeID returns the ID of the node the cluster is registered as.
+func (b *Backend) NodeID() string {
+	return b.nodeID
+}
+
+// SetEvents sets where the deployment status changes the backend observes
+// are published.
+func (b *Backend) SetEvents(p events.Publisher) {
+	b.events = p
+}
+
+// Capabilities returns the node capabilities of the cluster. Clusters run OCI
+// images only, and route domains when an ingress class is configured.
+func (b *Backend) Capabilities() []models.NodeCapability {
+	capabilities := []models.NodeCapability{models.NodeCapabilityOCIImages}
+	if b.config.IngressClass != "" {
+		capabilities = append(capabilities, models.NodeCapabilityIngress)
+	}
+	return capabilities
+}
+
+// Run syncs the cluster every sync interval until ctx is cancelled.
+func (b *Backend) Run(ctx context.Context) {
+	b.SyncOnce(ctx)
+
+	ticker := time.NewTicker(b.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.SyncOnce(ctx)
+		}
+	}
+}
+
+// SyncOnce heartbeats the cluster's node with its free capacity and brings
+// the cluster's workloads and the status of its deployments up to date.
+func (b *Backend) SyncOnce(ctx context.Context) {
+	if err := b.heartbeat(ctx); err != nil {
+		b.logger.Error("failed to heartbeat kubernetes node", "error", err)
+		return
+	}
+	if err := b.reconcile(ctx); err != nil {
+		b.logger.Error("failed to sync kubernetes deployments", "error", err)
+	}
+}
+
+// Deploy creates or updates the workload running a deployment's service.
+func (b *Backend) Deploy(ctx context.Context, deployment *models.Deployment) error {
+	if deployment.BuildType == models.BuildTypePureNix {
+		return errors.New("pure-nix artifacts cannot run on kubernetes")
+	}
+
+	replicas := 1
+	app, err := b.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil {
+		return fmt.Errorf("getting app: %w", err)
+	}
+	if app != nil {
+		for i := range app.Services {
+			if app.Services[i].Name == deployment.ServiceName {
+				replicas = app.Services[i].Replicas
+				break
+			}
+		}
+	}
+
+	name := resourceName(deployment.AppID, deployment.ServiceName)
+	if err := b.client.Apply(ctx, b.deploymentPath(name), deploymentManifest(deployment, b.config.Namespace, replicas)); err != nil {
+		return fmt.Errorf("applying deployment: %w", err)
+	}
+
+	if svc := serviceManifest(deployment, b.config.Namespace); svc != nil {
+		err = b.client.Apply(ctx, b.servicePath(name), svc)
+	} else {
+		err = b.client.Delete(ctx, b.servicePath(name))
+	}
+	if err != nil {
+		return fmt.Errorf("syncing service: %w", err)
+	}
+
+	var domains []*models.Domain
+	if b.config.IngressClass != "" {
+		if domains, err = b.store.Domains().List(ctx, deployment.AppID); err != nil {
+			return fmt.Errorf("listing domains: %w", err)
+		}
+	}
+	if ing := ingressManifest(deployment, b.config.Namespace, b.config.IngressClass, domains); ing != nil {
+		err = b.client.Apply(ctx, b.ingressPath(name), ing)
+	} else {
+		err = b.client.Delete(ctx, b.ingressPath(name))
+	}
+	if err != nil {
+		return fmt.Errorf("syncing ingress: %w", err)
+	}
+	return nil
+}
+
+// Stop removes the workload of a deployment unless a newer deployment of
+// the service has taken it over.
+func (b *Backend) Stop(ctx context.Context, deploymentID string) error {
+	deployment, err := b.store.Deployments().Get(ctx, deploymentID)
+	if err != nil {
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+
+	name := resourceName(deployment.AppID, deployment.ServiceName)
+	var live k8sDeployment
+	if err := b.client.Get(ctx, b.deploymentPath(name), &live); err != nil {
+		if IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+	if live.Metadata.Annotations[annotationDeployment] != deploymentID {
+		return nil
+	}
+	return b.remove(ctx, name)
+}
+
+// remove deletes the objects of a service's workload.
+func (b *Backend) remove(ctx context.Context, name string) error {
+	for _, path := range []string{b.ingressPath(name), b.servicePath(name), b.deploymentPath(name)} {
+		if err := b.client.Delete(ctx, path); err != nil {
+			return fmt.Errorf("deleting %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// heartbeat registers the cluster's node with its current free capacity.
+func (b *Backend) heartbeat(ctx context.Context) error {
+	resources, err := b.capacity(ctx)
+	if err != nil {
+		return err
+	}
+	server, _ := url.Parse(b.client.Server())
+	node := &models.Node{
+		ID:            b.nodeID,
+		Hostname:      server.Hostname(),
+		Address:       b.client.Server(),
+		Healthy:       true,
+		Provider:      models.NodeProviderKubernetes,
+		Pool:          b.config.Pool,
+		Capabilities:  b.Capabilities(),
+		Resources:     resources,
+		LastHeartbeat: b.now(),
+	}
+	if err := b.store.Nodes().Register(ctx, node); err != nil {
+		return fmt.Errorf("registering node: %w", err)
+	}
+	return nil
+}
+
+// capacity sums the allocatable resources of the cluster's ready,
+// schedulable nodes, less the requests of the pods running on it.
+func (b *Backend) capacity(ctx context.Context) (*models.NodeResources, error) {
+	var nodes struct {
+		Items []struct {
+			Spec struct {
+				Unschedulable bool `json:"unschedulable"`
+			} `json:"spec"`
+			Status struct {
+				Allocatable map[string]string `json:"allocatable"`
+				Conditions  []condition       `json:"conditions"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := b.client.Get(ctx, "/api/v1/nodes", &nodes); err != nil {
+		return nil, fmt.Errorf("listing cluster nodes: %w", err)
+	}
+
+	resources := &models.NodeResources{}
+	for _, n := range nodes.Items {
+		if n.Spec.Unschedulable || !conditionTrue(n.Status.Conditions, "Ready") {
+			continue
+		}
+		resources.CPUTotal += parseQuantity(n.Status.Allocatable["cpu"])
+		resources.MemoryTotal += int64(parseQuantity(n.Status.Allocatable["memory"]))
+		resources.DiskTotal += int64(parseQuantity(n.Status.Allocatable["ephemeral-storage"]))
+	}
+
+	var pods struct {
+		Items []struct {
+			Spec struct {
+				Containers []struct {
+					Resources resourceRequirements `json:"resources"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	selector := url.Values{"fieldSelector": {"status.phase!=Succeeded,status.phase!=Failed"}}
+	if err := b.client.Get(ctx, "/api/v1/pods?"+selector.Encode(), &pods); err != nil {
+		return nil, fmt.Errorf("listing cluster pods: %w", err)
+	}
+
+	var cpu float64
+	var memory int64
+	for _, p := range pods.Items {
+		for _, c := range p.Spec.Containers {
+			cpu += parseQuantity(c.Resources.Requests["cpu"])
+			memory += int64(parseQuantity(c.Resources.Requests["memory"]))
+		}
+	}
+	resources.CPUAvailable = max(resources.CPUTotal-cpu, 0)
+	resources.MemoryAvailable = max(resources.MemoryTotal-memory, 0)
+	resources.DiskAvailable = resources.DiskTotal
+	return resources, nil
+}
+
+// serviceKey identifies an app's service.
+type serviceKey struct {
+	appID   string
+	service string
+}
+
+// reconcile makes the cluster run the latest active deployment of every
+// service placed on it, records their status, and removes the workloads of
+// services that have none.
+func (b *Backend) reconcile(ctx context.Context) error {
+	deployments, err := b.store.Deployments().ListByNode(ctx, b.nodeID)
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+
+	current := make(map[serviceKey]*models.Deployment)
+	var active []*models.Deployment
+	for _, d := range deployments {
+		if !isActive(d.Status) {
+			continue
+		}
+		active = append(active, d)
+		key := serviceKey{d.AppID, d.ServiceName}
+		if c, ok := current[key]; !ok || d.Version > c.Version {
+			current[key] = d
+		}
+	}
+
+	for _, d := range current {
+		b.syncDeployment(ctx, d)
+	}
+
+	// Once a release is running, Kubernetes has replaced the pods of the
+	// releases before it
+	for _, d := range active {
+		c := current[serviceKey{d.AppID, d.ServiceName}]
+		if c != d && c.Status == models.DeploymentStatusRunning {
+			b.setStatus(ctx, d, models.DeploymentStatusStopped)
+		}
+	}
+
+	var live struct {
+		Items []k8sDeployment `json:"items"`
+	}
+	selector := url.Values{"labelSelector": {labelManagedBy + "=" + fieldManager}}
+	if err := b.client.Get(ctx, b.deploymentPath("")+"?"+selector.Encode(), &live); err != nil {
+		return fmt.Errorf("listing workloads: %w", err)
+	}
+	for _, w := range live.Items {
+		labels := w.Metadata.Labels
+		if c, ok := current[serviceKey{labels[labelAppID], labels[labelService]}]; ok && c.Status != models.DeploymentStatusFailed {
+			continue
+		}
+		b.logger.Info("removing workload without a running deployment", "name", w.Metadata.Name)
+		if err := b.remove(ctx, w.Metadata.Name); err != nil {
+			b.logger.Error("failed to remove workload", "name", w.Metadata.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+// syncDeployment applies a deployment's workload and records its rollout
+// status on the deployment.
+func (b *Backend) syncDeployment(ctx context.Context, d *models.Deployment) {
+	if err := b.Deploy(ctx, d); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusUnprocessableEntity) {
+			b.logger.Error("kubernetes rejected deployment", "deployment_id", d.ID, "error", err)
+			b.setStatus(ctx, d, models.DeploymentStatusFailed)
+			return
+		}
+		b.logger.Error("failed to apply deployment", "deployment_id", d.ID, "error", err)
+		return
+	}
+
+	var live k8sDeployment
+	if err := b.client.Get(ctx, b.deploymentPath(resourceName(d.AppID, d.ServiceName)), &live); err != nil {
+		b.logger.Error("failed to get deployment status", "deployment_id", d.ID, "error", err)
+		return
+	}
+
+	switch {
+	case rolledOut(&live):
+		b.setStatus(ctx, d, models.DeploymentStatusRunning)
+	case progressDeadlineExceeded(&live):
+		b.logger.Error("kubernetes rollout stalled", "deployment_id", d.ID)
+		b.setStatus(ctx, d, models.DeploymentStatusFailed)
+	case d.Status == models.DeploymentStatusScheduled:
+		b.setStatus(ctx, d, models.DeploymentStatusStarting)
+	}
+}
+
+// setStatus records a deployment status change and notifies about it.
+func (b *Backend) setStatus(ctx context.Context, d *models.Deployment, status models.DeploymentStatus) {
+	previous := d.Status
+	if previous == status {
+		return
+	}
+	now := b.now()
+	d.Status = status
+	d.UpdatedAt = now
+	if status == models.DeploymentStatusRunning && d.StartedAt == nil {
+		d.StartedAt = &now
+	}
+	if (status == models.DeploymentStatusStopped || status == models.DeploymentStatusFailed) && d.FinishedAt == nil {
+		d.FinishedAt = &now
+	}
+	if err := b.store.Deployments().Update(ctx, d); err != nil {
+		b.logger.Error("failed to update deployment status", "deployment_id", d.ID, "status", status, "error", err)
+		d.Status = previous
+		return
+	}
+	b.logger.Info("deployment status updated", "deployment_id", d.ID, "status", status)
+	if b.events != nil {
+		b.events.Publish(&events.Transition{
+			Kind:           events.TransitionDeploymentStatus,
+			Deployment:     d,
+			PreviousStatus: previous,
+		})
+	}
+}
+
+func (b *Backend) deploymentPath(name string) string {
+	return b.objectPath("/apis/apps/v1", "deployments", name)
+}
+
+func (b *Backend) servicePath(name string) string {
+	return b.objectPath("/api/v1", "services", name)
+}
+
+func (b *Backend) ingressPath(name string) string {
+	return b.objectPath("/apis/networking.k8s.io/v1", "ingresses", name)
+}
+
+func (b *Backend) objectPath(group, resource, name string) string {
+	path := fmt.Sprintf("%s/namespaces/%s/%s", group, b.config.Namespace, resource)
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+// isActive reports whether a deployment placed on a node should be running.
+func isActive(status models.DeploymentStatus) bool {
+	return status == models.DeploymentStatusScheduled ||
+		status == models.DeploymentStatusStarting ||
+		status == models.DeploymentStatusRunning
+}
+
+// rolledOut reports whether every replica of a Deployment runs its latest
+// spec and is available.
+func rolledOut(d *k8sDeployment) bool {
+	s := d.Status
+	return s != nil &&
+		s.ObservedGeneration >= d.Metadata.Generation &&
+		s.UpdatedReplicas >= d.Spec.Replicas &&
+		s.Replicas == s.UpdatedReplicas &&
+		s.AvailableReplicas >= d.Spec.Replicas
+}
+
+// progressDeadlineExceeded reports whether Kubernetes gave up on a rollout.
+func progressDeadlineExceeded(d *k8sDeployment) bool {
+	if d.Status == nil {
+		return false
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == "Progressing" && c.Status == "False" && c.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+func conditionTrue(conditions []condition, conditionType string) bool {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c.Status == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// fakeAPIServer stores applied objects by path and serves them back.
+type fakeAPIServer struct {
+	mu      sync.Mutex
+	objects map[string]json.RawMessage
+}
+
+func newFakeAPIServer(t *testing.T) (*fakeAPIServer, *Client) {
+	t.Helper()
+	f := &fakeAPIServer{objects: make(map[string]json.RawMessage)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(Config{APIServer: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, client
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/v1/nodes":
+		w.Write([]byte(`{"items":[
+			{"status":{"allocatable":{"cpu":"4","memory":"8Gi","ephemeral-storage":"100Gi"},"conditions":[{"type":"Ready","status":"True"}]}},
+			{"spec":{"unschedulable":true},"status":{"allocatable":{"cpu":"4","memory":"8Gi"},"conditions":[{"type":"Ready","status":"True"}]}}
+		]}`))
+	case r.URL.Path == "/api/v1/pods":
+		w.Write([]byte(`{"items":[{"spec":{"containers":[{"resources":{"requests":{"cpu":"1500m","memory":"1Gi"}}}]}}]}`))
+	case r.Method == http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/apply-patch+yaml" || r.URL.Query().Get("fieldManager") != fieldManager {
+			http.Error(w, `{"message":"not an apply"}`, http.StatusBadRequest)
+			return
+		}
+		// Like the API server, keep the status of an object applied again
+		var obj map[string]json.RawMessage
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &obj)
+		var old map[string]json.RawMessage
+		if json.Unmarshal(f.objects[r.URL.Path], &old) == nil && old["status"] != nil {
+			obj["status"] = old["status"]
+		}
+		f.objects[r.URL.Path], _ = json.Marshal(obj)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/deployments"):
+		var items []json.RawMessage
+		for path, obj := range f.objects {
+			if strings.HasPrefix(path, r.URL.Path+"/") {
+				items = append(items, obj)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"items": items})
+	case r.Method == http.MethodGet:
+		obj, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write(obj)
+	}
+}
+
+// setRolledOut marks the stored Deployment at path as fully available.
+func (f *fakeAPIServer) setRolledOut(t *testing.T, path string) {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var d k8sDeployment
+	if err := json.Unmarshal(f.objects[path], &d); err != nil {
+		t.Fatal(err)
+	}
+	n := d.Spec.Replicas
+	d.Status = &deploymentStatus{Replicas: n, UpdatedReplicas: n, AvailableReplicas: n}
+	f.objects[path], _ = json.Marshal(d)
+}
+
+func (f *fakeAPIServer) has(path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.objects[path]
+	return ok
+}
+
+type k8sDeploymentStore struct {
+	store.DeploymentStore
+	deployments []*models.Deployment
+}
+
+func (s *k8sDeploymentStore) ListByNode(ctx context.Context, nodeID string) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range s.deployments {
+		if d.NodeID == nodeID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (s *k8sDeploymentStore) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	for _, d := range s.deployments {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, errors.New("deployment not found")
+}
+
+func (s *k8sDeploymentStore) Update(ctx context.Context, d *models.Deployment) error { return nil }
+
+type k8sNodeStore struct {
+	store.NodeStore
+	registered *models.Node
+}
+
+func (s *k8sNodeStore) Register(ctx context.Context, node *models.Node) error {
+	s.registered = node
+	return nil
+}
+
+type k8sAppStore struct{ store.AppStore }
+
+func (s *k8sAppStore) Get(ctx context.Context, id string) (*models.App, error) {
+	return &models.App{ID: id, Services: []models.ServiceConfig{{Name: "web", Replicas: 2, NodePool: "kubernetes"}}}, nil
+}
+
+type k8sDomainStore struct{ store.DomainStore }
+
+func (s *k8sDomainStore) List(ctx context.Context, appID string) ([]*models.Domain, error) {
+	return []*models.Domain{{AppID: appID, Service: "web", Domain: "app.example.com", Verified: true}}, nil
+}
+
+type k8sMockStore struct {
+	store.Store
+	deployments *k8sDeploymentStore
+	nodes       *k8sNodeStore
+}
+
+func (s *k8sMockStore) Deployments() store.DeploymentStore { return s.deployments }
+func (s *k8sMockStore) Nodes() store.NodeStore             { return s.nodes }
+func (s *k8sMockStore) Apps() store.AppStore               { return &k8sAppStore{} }
+func (s *k8sMockStore) Domains() store.DomainStore         { return &k8sDomainStore{} }
+
+type recordingPublisher struct {
+	changes []models.DeploymentStatus
+}
+
+func (p *recordingPublisher) Publish(t *events.Transition) {
+	p.changes = append(p.changes, t.Deployment.Status)
+}
+
+func newTestBackend(t *testing.T, deployments ...*models.Deployment) (*Backend, *fakeAPIServer, *k8sMockStore) {
+	t.Helper()
+	api, client := newFakeAPIServer(t)
+	st := &k8sMockStore{deployments: &k8sDeploymentStore{deployments: deployments}, nodes: &k8sNodeStore{}}
+	cfg := DefaultConfig()
+	cfg.NodeID = "node-k8s"
+	cfg.IngressClass = "nginx"
+	return NewBackend(client, st, cfg, slog.New(slog.NewTextHandler(io.Discard, nil))), api, st
+}
+
+func webDeployment(id string, version int, status models.DeploymentStatus) *models.Deployment {
+	return &models.Deployment{
+		ID: id, AppID: "0f8fad5b-d9cb-469f-a165-70867728950e", ServiceName: "web", Version: version,
+		BuildType: models.BuildTypeOCI, Artifact: "registry.example.com/web:" + id,
+		Status: status, NodeID: "node-k8s",
+		Config: &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: 8080}}},
+	}
+}
+
+const webPath = "/apis/apps/v1/namespaces/narvana/deployments/web-0f8fad5b"
+
+func TestBackendHeartbeatReportsClusterCapacity(t *testing.T) {
+	b, _, st := newTestBackend(t)
+	b.SyncOnce(context.Background())
+
+	node := st.nodes.registered
+	if node == nil || node.ID != "node-k8s" || node.Provider != models.NodeProviderKubernetes || node.Pool != "kubernetes" {
+		t.Fatalf("registered node = %+v", node)
+	}
+	if node.Resources.CPUTotal != 4 || node.Resources.CPUAvailable != 2.5 || node.Resources.MemoryAvailable != 7<<30 {
+		t.Errorf("resources = %+v, want the schedulable node less pod requests", node.Resources)
+	}
+	if node.Supports(models.NodeCapabilityNixClosures) || !node.Supports(models.NodeCapabilityOCIImages, models.NodeCapabilityIngress) {
+		t.Errorf("capabilities = %v", node.Capabilities)
+	}
+}
+
+func TestBackendRollsOutNewReleases(t *testing.T) {
+	v1 := webDeployment("dep-1", 1, models.DeploymentStatusRunning)
+	v2 := webDeployment("dep-2", 2, models.DeploymentStatusScheduled)
+	b, api, _ := newTestBackend(t, v1, v2)
+	published := &recordingPublisher{}
+	b.SetEvents(published)
+
+	b.SyncOnce(context.Background())
+	if v2.Status != models.DeploymentStatusStarting || v1.Status != models.DeploymentStatusRunning {
+		t.Fatalf("statuses = %s, %s; want the new release starting while the old one runs", v1.Status, v2.Status)
+	}
+	var applied k8sDeployment
+	json.Unmarshal(api.objects[webPath], &applied)
+	if applied.Metadata.Annotations[annotationDeployment] != "dep-2" || applied.Spec.Replicas != 2 {
+		t.Errorf("applied deployment = %+v", applied.Metadata)
+	}
+	for _, path := range []string{"/api/v1/namespaces/narvana/services/web-0f8fad5b", "/apis/networking.k8s.io/v1/namespaces/narvana/ingresses/web-0f8fad5b"} {
+		if !api.has(path) {
+			t.Errorf("%s was not applied", path)
+		}
+	}
+
+	api.setRolledOut(t, webPath)
+	b.SyncOnce(context.Background())
+	if v2.Status != models.DeploymentStatusRunning || v2.StartedAt == nil {
+		t.Errorf("new release = %s, want running", v2.Status)
+	}
+	if v1.Status != models.DeploymentStatusStopped {
+		t.Errorf("old release = %s, want stopped once replaced", v1.Status)
+	}
+	if len(published.changes) != 3 {
+		t.Errorf("published changes = %v", published.changes)
+	}
+
+	// Stopping a replaced release leaves the service running
+	if err := b.Stop(context.Background(), "dep-1"); err != nil {
+		t.Fatal(err)
+	}
+	if !api.has(webPath) {
+		t.Error("stopping the old release removed the service's workload")
+	}
+}
+
+func TestBackendRemovesStoppedServices(t *testing.T) {
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	b, api, _ := newTestBackend(t, d)
+	b.SyncOnce(context.Background())
+	if !api.has(webPath) {
+		t.Fatal("deployment was not applied")
+	}
+
+	d.Status = models.DeploymentStatusStopped
+	b.SyncOnce(context.Background())
+	if api.has(webPath) || api.has("/api/v1/namespaces/narvana/services/web-0f8fad5b") {
+		t.Error("workload of a stopped service was not removed")
+	}
+}
+
+func TestRouterSendsClusterDeploymentsToBackend(t *testing.T) {
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	b, api, _ := newTestBackend(t, d)
+	agents := &recordingAgents{}
+	r := scheduler.NewRouter(agents, b)
+
+	if err := r.Deploy(context.Background(), "node-k8s", d); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Deploy(context.Background(), "node-agent", d); err != nil {
+		t.Fatal(err)
+	}
+	if !api.has(webPath) || len(agents.deployed) != 1 || agents.deployed[0] != "node-agent" {
+		t.Errorf("agent deploys = %v, cluster applied = %v", agents.deployed, api.has(webPath))
+	}
+}
+
+type recordingAgents struct {
+	deployed []string
+}
+
+func (a *recordingAgents) Deploy(ctx context.Context, nodeID string, d *models.Deployment) error {
+	a.deployed = append(a.deployed, nodeID)
+	return nil
+}
+
+func (a *recordingAgents) Stop(ctx context.Context, nodeID, deploymentID string) error { return nil }
@@ -0,0 +1,157 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// fieldManager owns the fields the control plane sets with server-side apply.
+const fieldManager = "narvana"
+
+// APIError is a non-2xx response from the Kubernetes API server.
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("kubernetes api: %d %s", e.Code, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API server.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// Client calls the Kubernetes API server with a bearer token. It speaks the
+// REST API directly: the control plane only needs to apply, read and delete a
+// handful of object kinds.
+type Client struct {
+	server    string
+	tokenFile string
+	http      *http.Client
+}
+
+// NewClient creates a client for the API server in cfg, or for the cluster
+// the control plane runs in when no server is configured.
+func NewClient(cfg Config) (*Client, error) {
+	server := strings.TrimSuffix(cfg.APIServer, "/")
+	if server == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("no API server configured and not running in a cluster")
+		}
+		server = "https://" + net.JoinHostPort(host, port)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA bundle %s", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Client{
+		server:    server,
+		tokenFile: cfg.TokenFile,
+		http:      &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Server returns the API server URL.
+func (c *Client) Server() string {
+	return c.server
+}
+
+// Get reads the object at path into out.
+func (c *Client) Get(ctx context.Context, path string, out any) error {
+	return c.do(ctx, http.MethodGet, path, "", nil, out)
+}
+
+// Apply creates or updates the object at path with server-side apply, taking
+// ownership of conflicting fields.
+func (c *Client) Apply(ctx context.Context, path string, obj any) error {
+	query := url.Values{"fieldManager": {fieldManager}, "force": {"true"}}
+	return c.do(ctx, http.MethodPatch, path+"?"+query.Encode(), "application/apply-patch+yaml", obj, nil)
+}
+
+// Delete deletes the object at path and its dependents. Deleting an object
+// that does not exist succeeds.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	body := map[string]string{"propagationPolicy": "Background"}
+	if err := c.do(ctx, http.MethodDelete, path, "application/json", body, nil); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path, contentType string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	// Service account tokens are rotated on disk, so read it per request
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("reading service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling kubernetes api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &status) != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(data))
+		}
+		return &APIError{Code: resp.StatusCode, Message: status.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+)
+
+// Labels and annotations set on every object the control plane manages.
+const (
+	labelManagedBy       = "app.kubernetes.io/managed-by"
+	labelAppID           = "narvana.io/app-id"
+	labelService         = "narvana.io/service"
+	annotationDeployment = "narvana.io/deployment-id"
+)
+
+// The subset of the Kubernetes object schemas the backend writes and reads.
+
+type objectMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Generation  int64             `json:"generation,omitempty"`
+}
+
+type k8sDeployment struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   objectMeta        `json:"metadata"`
+	Spec       deploymentSpec    `json:"spec"`
+	Status     *deploymentStatus `json:"status,omitempty"`
+}
+
+type deploymentSpec struct {
+	Replicas int           `json:"replicas"`
+	Selector labelSelector `json:"selector"`
+	Template podTemplate   `json:"template"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+type podTemplate struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     podSpec    `json:"spec"`
+}
+
+type podSpec struct {
+	Containers []container `json:"containers"`
+}
+
+type container struct {
+	Name           string                `json:"name"`
+	Image          string                `json:"image"`
+	Env            []envVar              `json:"env,omitempty"`
+	Ports          []containerPort       `json:"ports,omitempty"`
+	Resources      *resourceRequirements `json:"resources,omitempty"`
+	ReadinessProbe *probe                `json:"readinessProbe,omitempty"`
+}
+
+type envVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type containerPort struct {
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type probe struct {
+	Exec             *execAction      `json:"exec,omitempty"`
+	HTTPGet          *httpGetAction   `json:"httpGet,omitempty"`
+	TCPSocket        *tcpSocketAction `json:"tcpSocket,omitempty"`
+	PeriodSeconds    int              `json:"periodSeconds,omitempty"`
+	TimeoutSeconds   int              `json:"timeoutSeconds,omitempty"`
+	FailureThreshold int              `json:"failureThreshold,omitempty"`
+}
+
+type httpGetAction struct {
+	Path string `json:"path"`
+	Port int    `json:"port"`
+}
+
+type tcpSocketAction struct {
+	Port int `json:"port"`
+}
+
+type execAction struct {
+	Command []string `json:"command"`
+}
+
+type deploymentStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration"`
+	Replicas           int         `json:"replicas"`
+	UpdatedReplicas    int         `json:"updatedReplicas"`
+	AvailableReplicas  int         `json:"availableReplicas"`
+	Conditions         []condition `json:"conditions,omitempty"`
+}
+
+type condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type k8sService struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   objectMeta  `json:"metadata"`
+	Spec       serviceSpec `json:"spec"`
+}
+
+type serviceSpec struct {
+	Selector map[string]string `json:"selector"`
+	Ports    []servicePort     `json:"ports"`
+}
+
+type servicePort struct {
+	Name       string `json:"name"`
+	Port       int    `json:"port"`
+	TargetPort int    `json:"targetPort"`
+	Protocol   string `json:"protocol"`
+}
+
+type k8sIngress struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   objectMeta  `json:"metadata"`
+	Spec       ingressSpec `json:"spec"`
+}
+
+type ingressSpec struct {
+	IngressClassName string        `json:"ingressClassName"`
+	Rules            []ingressRule `json:"rules"`
+}
+
+type ingressRule struct {
+	Host string           `json:"host"`
+	HTTP ingressRuleValue `json:"http"`
+}
+
+type ingressRuleValue struct {
+	Paths []ingressPath `json:"paths"`
+}
+
+type ingressPath struct {
+	Path     string         `json:"path"`
+	PathType string         `json:"pathType"`
+	Backend  ingressBackend `json:"backend"`
+}
+
+type ingressBackend struct {
+	Service ingressServiceBackend `json:"service"`
+}
+
+type ingressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ingressServicePort `json:"port"`
+}
+
+type ingressServicePort struct {
+	Number int `json:"number"`
+}
+
+// resourceName returns the name of the objects that run an app's service.
+// Every deployment of the service updates the same objects, so Kubernetes
+// rolls pods over to a new release.
+func resourceName(appID, service string) string {
+	suffix := appID
+	if len(suffix) > 8 {
+		suffix = suffix[:8]
+	}
+	if len(service) > 54 {
+		service = strings.TrimRight(service[:54], "-")
+	}
+	return strings.ToLower(service + "-" + suffix)
+}
+
+// selectorLabels select the pods of an app's service.
+func selectorLabels(appID, service string) map[string]string {
+	return map[string]string{
+		labelManagedBy: fieldManager,
+		labelAppID:     appID,
+		labelService:   service,
+	}
+}
+
+// objectMetaFor returns the metadata of an object running a deployment.
+func objectMetaFor(deployment *models.Deployment, namespace string) objectMeta {
+	return objectMeta{
+		Name:        resourceName(deployment.AppID, deployment.ServiceName),
+		Namespace:   namespace,
+		Labels:      selectorLabels(deployment.AppID, deployment.ServiceName),
+		Annotations: map[string]string{annotationDeployment: deployment.ID},
+	}
+}
+
+// deploymentManifest translates a deployment of an OCI artifact into a
+// Kubernetes Deployment.
+func deploymentManifest(deployment *models.Deployment, namespace string, replicas int) *k8sDeployment {
+	meta := objectMetaFor(deployment, namespace)
+	c := container{
+		Name:  "app",
+		Image: deployment.Artifact,
+	}
+
+	if cfg := deployment.Config; cfg != nil {
+		names := make([]string, 0, len(cfg.EnvVars))
+		for name := range cfg.EnvVars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c.Env = append(c.Env, envVar{Name: name, Value: cfg.EnvVars[name]})
+		}
+		for _, p := range cfg.Ports {
+			c.Ports = append(c.Ports, containerPort{ContainerPort: p.ContainerPort, Protocol: protocol(p.Protocol)})
+		}
+		c.ReadinessProbe = readinessProbe(cfg.HealthCheck, cfg.Ports)
+	}
+
+	// Requests match what the scheduler reserved for the deployment
+	req := scheduler.GetResourceRequirements(deployment.Resources)
+	quantities := map[string]string{
+		"cpu":    fmt.Sprintf("%dm", int64(req.CPU*1000)),
+		"memory": strconv.FormatInt(req.Memory, 10),
+	}
+	c.Resources = &resourceRequirements{Requests: quantities, Limits: quantities}
+
+	if replicas < 1 {
+		replicas = 1
+	}
+	return &k8sDeployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   meta,
+		Spec: deploymentSpec{
+			Replicas: replicas,
+			Selector: labelSelector{MatchLabels: selectorLabels(deployment.AppID, deployment.ServiceName)},
+			Template: podTemplate{
+				Metadata: objectMeta{Labels: meta.Labels, Annotations: meta.Annotations},
+				Spec:     podSpec{Containers: []container{c}},
+			},
+		},
+	}
+}
+
+// readinessProbe translates a service health check. Services without a
+// health check are ready once their first port accepts connections.
+func readinessProbe(hc *models.HealthCheckConfig, ports []models.PortMapping) *probe {
+	port := 0
+	if len(ports) > 0 {
+		port = ports[0].ContainerPort
+	}
+	if hc == nil {
+		if port == 0 {
+			return nil
+		}
+		return &probe{TCPSocket: &tcpSocketAction{Port: port}}
+	}
+
+	if hc.Port != 0 {
+		port = hc.Port
+	}
+	p := &probe{
+		PeriodSeconds:    hc.IntervalSeconds,
+		TimeoutSeconds:   hc.TimeoutSeconds,
+		FailureThreshold: hc.Retries,
+	}
+	if len(hc.Command) > 0 {
+		p.Exec = &execAction{Command: hc.Command}
+		return p
+	}
+	if port == 0 {
+		return nil
+	}
+	if hc.Path != "" {
+		p.HTTPGet = &httpGetAction{Path: hc.Path, Port: port}
+	} else {
+		p.TCPSocket = &tcpSocketAction{Port: port}
+	}
+	return p
+}
+
+// serviceManifest returns the Service exposing a deployment's ports inside
+// the cluster, or nil when it has none.
+func serviceManifest(deployment *models.Deployment, namespace string) *k8sService {
+	if deployment.Config == nil || len(deployment.Config.Ports) == 0 {
+		return nil
+	}
+	svc := &k8sService{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   objectMetaFor(deployment, namespace),
+		Spec:       serviceSpec{Selector: selectorLabels(deployment.AppID, deployment.ServiceName)},
+	}
+	for _, p := range deployment.Config.Ports {
+		proto := protocol(p.Protocol)
+		svc.Spec.Ports = append(svc.Spec.Ports, servicePort{
+			Name:       fmt.Sprintf("%s-%d", strings.ToLower(proto), p.ContainerPort),
+			Port:       p.ContainerPort,
+			TargetPort: p.ContainerPort,
+			Protocol:   proto,
+		})
+	}
+	return svc
+}
+
+// ingressManifest routes a service's verified domains to its first port, or
+// returns nil when there is nothing to route.
+func ingressManifest(deployment *models.Deployment, namespace, ingressClass string, domains []*models.Domain) *k8sIngress {
+	if ingressClass == "" || deployment.Config == nil || len(deployment.Config.Ports) == 0 {
+		return nil
+	}
+	ing := &k8sIngress{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "Ingress",
+		Metadata:   objectMetaFor(deployment, namespace),
+		Spec:       ingressSpec{IngressClassName: ingressClass},
+	}
+	backend := ingressBackend{Service: ingressServiceBackend{
+		Name: ing.Metadata.Name,
+		Port: ingressServicePort{Number: deployment.Config.Ports[0].ContainerPort},
+	}}
+	for _, d := range domains {
+		if d.Service != deployment.ServiceName || !d.Verified {
+			continue
+		}
+		ing.Spec.Rules = append(ing.Spec.Rules, ingressRule{
+			Host: d.Domain,
+			HTTP: ingressRuleValue{Paths: []ingressPath{{Path: "/", PathType: "Prefix", Backend: backend}}},
+		})
+	}
+	if len(ing.Spec.Rules) == 0 {
+		return nil
+	}
+	return ing
+}
+
+// protocol returns the Kubernetes name of a port protocol.
+func protocol(p string) string {
+	if strings.EqualFold(p, "udp") {
+		return "UDP"
+	}
+	return "TCP"
+}
+
+// parseQuantity parses a Kubernetes resource quantity such as "3800m",
+// "16Gi" or "1e9" into base units.
+func parseQuantity(q string) float64 {
+	q = strings.TrimSpace(q)
+	suffixes := []struct {
+		suffix string
+		factor float64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+		{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+	}
+	if milli, ok := strings.CutSuffix(q, "m"); ok {
+		v, err := strconv.ParseFloat(milli, 64)
+		if err != nil {
+			return 0
+		}
+		return v / 1000
+	}
+	for _, s := range suffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(q, s.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return v * s.factor
+		}
+	}
+	v, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestDeploymentManifest(t *testing.T) {
+	d := &models.Deployment{
+		ID: "dep-1", AppID: "0f8fad5b-d9cb", ServiceName: "api", Artifact: "ghcr.io/acme/api:1",
+		Resources: &models.ResourceSpec{CPU: "0.5", Memory: "1Gi"},
+		Config: &models.RuntimeConfig{
+			EnvVars:     map[string]string{"B": "2", "A": "1"},
+			Ports:       []models.PortMapping{{ContainerPort: 8080}, {ContainerPort: 5353, Protocol: "udp"}},
+			HealthCheck: &models.HealthCheckConfig{Path: "/healthz", IntervalSeconds: 10},
+		},
+	}
+	m := deploymentManifest(d, "apps", 0)
+
+	if m.Metadata.Name != "api-0f8fad5b" || m.Metadata.Namespace != "apps" || m.Spec.Replicas != 1 {
+		t.Errorf("metadata = %+v, replicas = %d", m.Metadata, m.Spec.Replicas)
+	}
+	c := m.Spec.Template.Spec.Containers[0]
+	if c.Image != "ghcr.io/acme/api:1" || len(c.Env) != 2 || c.Env[0].Name != "A" {
+		t.Errorf("container = %+v", c)
+	}
+	if c.Ports[1].Protocol != "UDP" {
+		t.Errorf("ports = %+v", c.Ports)
+	}
+	if c.Resources.Requests["cpu"] != "500m" || c.Resources.Limits["memory"] != "1073741824" {
+		t.Errorf("resources = %+v", c.Resources)
+	}
+	if c.ReadinessProbe.HTTPGet == nil || c.ReadinessProbe.HTTPGet.Path != "/healthz" || c.ReadinessProbe.HTTPGet.Port != 8080 {
+		t.Errorf("readiness probe = %+v", c.ReadinessProbe)
+	}
+
+	svc := serviceManifest(d, "apps")
+	if svc == nil || len(svc.Spec.Ports) != 2 || svc.Spec.Ports[1].Name != "udp-5353" {
+		t.Errorf("service = %+v", svc)
+	}
+}
+
+func TestReadinessProbeRunsCommand(t *testing.T) {
+	hc := &models.HealthCheckConfig{Port: 5432, Command: []string{"healthcheck.sh"}, IntervalSeconds: 10, Retries: 12}
+	p := readinessProbe(hc, []models.PortMapping{{ContainerPort: 5432}})
+	if p == nil || p.Exec == nil || p.Exec.Command[0] != "healthcheck.sh" || p.TCPSocket != nil || p.FailureThreshold != 12 {
+		t.Errorf("readiness probe = %+v", p)
+	}
+}
+
+func TestIngressManifestRoutesVerifiedDomains(t *testing.T) {
+	d := &models.Deployment{ID: "dep-1", AppID: "app-1", ServiceName: "web", Config: &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: 3000}}}}
+	domains := []*models.Domain{
+		{Service: "web", Domain: "example.com", Verified: true},
+		{Service: "web", Domain: "pending.example.com"},
+		{Service: "api", Domain: "api.example.com", Verified: true},
+	}
+
+	ing := ingressManifest(d, "apps", "nginx", domains)
+	if ing == nil || len(ing.Spec.Rules) != 1 || ing.Spec.Rules[0].Host != "example.com" {
+		t.Fatalf("ingress = %+v", ing)
+	}
+	if port := ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number; port != 3000 {
+		t.Errorf("backend port = %d", port)
+	}
+	if ingressManifest(d, "apps", "", domains) != nil {
+		t.Error("ingress created without an ingress class")
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	for q, want := range map[string]float64{
+		"4":          4,
+		"3800m":      3.8,
+		"16Gi":       16 << 30,
+		"16393916Ki": 16393916 << 10,
+		"500M":       500e6,
+		"1e9":        1e9,
+		"bogus":      0,
+	} {
+		if got := parseQuantity(q); got != want {
+			t.Errorf("parseQuantity(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package loadtest runs on-demand load tests from build workers. A test
+// sends requests at a constant rate to one deployment for a fixed duration
+// and records the latencies and errors of the responses on the test, so a
+// service's releases can be compared before and after a change.
+//
+// Requests are sent on schedule whether or not earlier ones were answered,
+// so a slow deployment shows up as higher latencies rather than a lower
+// request rate.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxErrorSamples caps the distinct transport errors kept on a result.
+const maxErrorSamples = 5
+
+// Config controls how often a worker looks for tests.
+type Config struct {
+	// PollInterval is how often pending tests are claimed.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 5 * time.Second,
+	}
+}
+
+// Runner claims the load tests designated to its worker, or to no worker,
+// and runs them one at a time.
+type Runner struct {
+	store    store.Store
+	workerID string
+	client   *http.Client
+	config   Config
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewRunner creates a load test runner for the build worker with the given
+// registry ID.
+func NewRunner(st store.Store, workerID string, cfg Config, logger *slog.Logger) *Runner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Runner{
+		store:    st,
+		workerID: workerID,
+		client: &http.Client{
+			Transport: &http.Transport{
+				MaxIdleConns:        512,
+				MaxIdleConnsPerHost: 512,
+				IdleConnTimeout:     30 * time.Second,
+			},
+			// Redirects are measured like any other response
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		config: cfg,
+		logger: logger.With("build_worker_id", workerID),
+		now:    time.Now,
+	}
+}
+
+// Run claims and runs tests every poll interval until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.failOverdue(ctx)
+			for r.RunOnce(ctx) && ctx.Err() == nil {
+			}
+		}
+	}
+}
+
+// failOverdue fails tests whose worker stopped while running them.
+func (r *Runner) failOverdue(ctx context.Context) {
+	n, err := r.store.LoadTests().FailOverdue(ctx)
+	if err != nil {
+		r.logger.Error("failed to fail overdue load tests", "error", err)
+		return
+	}
+	if n > 0 {
+		r.logger.Warn("failed load tests abandoned by their worker", "count", n)
+	}
+}
+
+// RunOnce claims one pending test and runs it. It returns false if there
+// was none.
+func (r *Runner) RunOnce(ctx context.Context) bool {
+	test, err := r.store.LoadTests().Claim(ctx, r.workerID)
+	if err != nil {
+		r.logger.Error("failed to claim load test", "error", err)
+		return false
+	}
+	if test == nil {
+		return false
+	}
+
+	r.logger.Info("load test started",
+		"load_test_id", test.ID,
+		"deployment_id", test.DeploymentID,
+		"rps", test.RPS,
+		"duration_seconds", test.DurationSeconds,
+	)
+	if err := r.run(ctx, test); err != nil {
+		r.logger.Error("failed to run load test", "error", err, "load_test_id", test.ID)
+	}
+	return true
+}
+
+// run sends a claimed test's requests to its deployment and records the result.
+func (r *Runner) run(ctx context.Context, test *models.LoadTest) error {
+	deployment, err := r.store.Deployments().Get(ctx, test.DeploymentID)
+	if err != nil || deployment == nil {
+		return r.finish(ctx, test, nil, "deployment no longer exists")
+	}
+	if deployment.Status != models.DeploymentStatusRunning {
+		return r.finish(ctx, test, nil, fmt.Sprintf("deployment is not running (%s)", deployment.Status))
+	}
+	target, err := r.target(ctx, deployment)
+	if err != nil {
+		return r.finish(ctx, test, nil, err.Error())
+	}
+
+	result := Attack(ctx, r.client, target, test)
+	if ctx.Err() != nil {
+		return r.finish(context.WithoutCancel(ctx), test, result, "worker stopped before the test finished")
+	}
+	return r.finish(ctx, test, result, "")
+}
+
+// finish records a test's result, or the reason it failed.
+func (r *Runner) finish(ctx context.Context, test *models.LoadTest, result *models.LoadTestResult, reason string) error {
+	now := r.now()
+	test.Status = models.LoadTestCompleted
+	if reason != "" {
+		test.Status = models.LoadTestFailed
+	}
+	test.Error = reason
+	test.Result = result
+	test.FinishedAt = &now
+	if err := r.store.LoadTests().Update(ctx, test); err != nil {
+		return fmt.Errorf("updating load test: %w", err)
+	}
+
+	attrs := []any{"load_test_id", test.ID, "deployment_id", test.DeploymentID, "status", test.Status}
+	if reason != "" {
+		attrs = append(attrs, "reason", reason)
+	}
+	if result != nil {
+		attrs = append(attrs, "requests", result.Requests, "error_rate", result.ErrorRate, "latency_p99_ms", result.LatencyP99)
+	}
+	r.logger.Info("load test finished", attrs...)
+	return nil
+}
+
+// target returns the base URL of a deployment: its primary port on the node
+// running it.
+func (r *Runner) target(ctx context.Context, deployment *models.Deployment) (string, error) {
+	if deployment.NodeID == "" {
+		return "", fmt.Errorf("deployment is not placed on a node")
+	}
+	node, err := r.store.Nodes().Get(ctx, deployment.NodeID)
+	if err != nil || node == nil {
+		return "", fmt.Errorf("node %s not found", deployment.NodeID)
+	}
+	port := 8080
+	if deployment.Config != nil && len(deployment.Config.Ports) > 0 {
+		port = deployment.Config.Ports[0].ContainerPort
+	}
+	return "http://" + net.JoinHostPort(node.Address, strconv.Itoa(port)), nil
+}
+
+// Attack sends test.RPS requests per second to target+test.Path for the
+// test's duration, or until ctx is cancelled, and summarizes the responses.
+func Attack(ctx context.Context, client *http.Client, target string, test *models.LoadTest) *models.LoadTestResult {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies []float64
+		result    = &models.LoadTestResult{StatusCodes: map[int]int{}}
+		samples   = map[string]bool{}
+	)
+	record := func(status int, latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Requests++
+		switch {
+		case err != nil:
+			result.Errors++
+			if msg := err.Error(); !samples[msg] && len(samples) < maxErrorSamples {
+				samples[msg] = true
+				result.ErrorSamples = append(result.ErrorSamples, msg)
+			}
+			return
+		case status < 200 || status >= 400:
+			result.Errors++
+		}
+		result.StatusCodes[status]++
+		latencies = append(latencies, float64(latency.Microseconds())/1000)
+	}
+
+	timeout := time.Duration(test.TimeoutSeconds) * time.Second
+	interval := time.Second / time.Duration(test.RPS)
+	total := test.RPS * test.DurationSeconds
+	start := time.Now()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+schedule:
+	for i := 0; i < total; i++ {
+		timer.Reset(time.Until(start.Add(time.Duration(i) * interval)))
+		select {
+		case <-ctx.Done():
+			break schedule
+		case <-timer.C:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, latency, err := send(ctx, client, target, test, timeout)
+			record(status, latency, err)
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start).Seconds()
+	if result.Requests > 0 {
+		result.ErrorRate = float64(result.Errors) / float64(result.Requests)
+	}
+	if elapsed > 0 {
+		result.AchievedRPS = round(float64(result.Requests-result.Errors) / elapsed)
+	}
+	sort.Float64s(latencies)
+	result.LatencyP50 = percentile(latencies, 0.50)
+	result.LatencyP90 = percentile(latencies, 0.90)
+	result.LatencyP99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		result.LatencyMax = latencies[len(latencies)-1]
+	}
+	return result
+}
+
+// send makes one request and returns its status and how long the response
+// took to arrive in full.
+func send(ctx context.Context, client *http.Client, target string, test *models.LoadTest, timeout time.Duration) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, test.Method, target+test.Path, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("User-Agent", "narvana-loadtest")
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, 0, err
+	}
+	return resp.StatusCode, time.Since(started), nil
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// round rounds to two decimals.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
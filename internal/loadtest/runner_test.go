@@ -0,0 +1,193 @@
+package loadtest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the runner uses.
+type memStore struct {
+	store.Store
+	nodes       []*models.Node
+	deployments map[string]*models.Deployment
+	tests       []*models.LoadTest
+}
+
+func (s *memStore) Nodes() store.NodeStore             { return memNodes{s: s} }
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) LoadTests() store.LoadTestStore     { return memTests{s: s} }
+
+type memNodes struct {
+	store.NodeStore
+	s *memStore
+}
+
+func (m memNodes) Get(ctx context.Context, id string) (*models.Node, error) {
+	for _, n := range m.s.nodes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	return m.s.deployments[id], nil
+}
+
+type memTests struct {
+	store.LoadTestStore
+	s *memStore
+}
+
+func (m memTests) Claim(ctx context.Context, workerID string) (*models.LoadTest, error) {
+	for _, t := range m.s.tests {
+		if t.Status == models.LoadTestPending && (t.WorkerID == "" || t.WorkerID == workerID) {
+			now := time.Now()
+			t.Status, t.RunBy, t.StartedAt = models.LoadTestRunning, workerID, &now
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m memTests) Update(ctx context.Context, test *models.LoadTest) error { return nil }
+
+// setup serves a running deployment from handler and returns a store with it.
+func setup(t *testing.T, handler http.HandlerFunc) *memStore {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	return &memStore{
+		nodes: []*models.Node{{ID: "node-1", Address: host}},
+		deployments: map[string]*models.Deployment{
+			"dep-1": {ID: "dep-1", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusRunning, NodeID: "node-1",
+				Config: &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: port}}}},
+			"dep-0": {ID: "dep-0", AppID: "app-1", ServiceName: "web", Status: models.DeploymentStatusStopped, NodeID: "node-1"},
+		},
+	}
+}
+
+func newTest(deploymentID, workerID string) *models.LoadTest {
+	test := &models.LoadTest{
+		ID:              "test-" + deploymentID,
+		DeploymentID:    deploymentID,
+		WorkerID:        workerID,
+		Path:            "/api",
+		RPS:             50,
+		DurationSeconds: 1,
+		Status:          models.LoadTestPending,
+	}
+	test.Normalize()
+	return test
+}
+
+func TestRunnerRecordsResults(t *testing.T) {
+	var hits atomic.Int64
+	st := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		// Every fifth request fails
+		if hits.Add(1)%5 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path != "/api" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	st.tests = []*models.LoadTest{newTest("dep-1", "other-worker"), newTest("dep-1", "")}
+	r := NewRunner(st, "worker-1", DefaultConfig(), nil)
+
+	if !r.RunOnce(context.Background()) {
+		t.Fatal("RunOnce() found no test")
+	}
+	if st.tests[0].Status != models.LoadTestPending {
+		t.Errorf("the test designated to another worker was run")
+	}
+
+	test := st.tests[1]
+	if test.Status != models.LoadTestCompleted || test.RunBy != "worker-1" || test.FinishedAt == nil {
+		t.Fatalf("test = %+v", test)
+	}
+	res := test.Result
+	if res.Requests != 50 || res.Errors != 10 || res.ErrorRate != 0.2 {
+		t.Errorf("requests = %d, errors = %d, error rate = %v", res.Requests, res.Errors, res.ErrorRate)
+	}
+	if res.StatusCodes[200] != 40 || res.StatusCodes[500] != 10 {
+		t.Errorf("status codes = %v", res.StatusCodes)
+	}
+	if res.LatencyP50 <= 0 || res.LatencyP50 > res.LatencyP99 || res.LatencyP99 > res.LatencyMax {
+		t.Errorf("latencies p50 = %v, p99 = %v, max = %v", res.LatencyP50, res.LatencyP99, res.LatencyMax)
+	}
+
+	if r.RunOnce(context.Background()) {
+		t.Error("RunOnce() ran a test twice")
+	}
+}
+
+func TestRunnerFailsUnreachableDeployments(t *testing.T) {
+	st := setup(t, func(w http.ResponseWriter, r *http.Request) {})
+	st.tests = []*models.LoadTest{newTest("dep-0", ""), newTest("dep-gone", "")}
+	r := NewRunner(st, "worker-1", DefaultConfig(), nil)
+
+	for r.RunOnce(context.Background()) {
+	}
+	if test := st.tests[0]; test.Status != models.LoadTestFailed || test.Error != "deployment is not running (stopped)" || test.Result != nil {
+		t.Errorf("stopped deployment test = %+v", test)
+	}
+	if test := st.tests[1]; test.Status != models.LoadTestFailed || test.Error != "deployment no longer exists" {
+		t.Errorf("missing deployment test = %+v", test)
+	}
+}
+
+func TestAttackCountsTransportErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target := srv.URL
+	srv.Close()
+
+	test := newTest("dep-1", "")
+	test.RPS = 10
+	res := Attack(context.Background(), http.DefaultClient, target, test)
+	if res.Requests != 10 || res.Errors != 10 || res.ErrorRate != 1 || res.AchievedRPS != 0 {
+		t.Errorf("result = %+v", res)
+	}
+	if len(res.ErrorSamples) == 0 || len(res.ErrorSamples) > maxErrorSamples || len(res.StatusCodes) != 0 {
+		t.Errorf("error samples = %v, status codes = %v", res.ErrorSamples, res.StatusCodes)
+	}
+}
+
+func TestLoadTestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		test  models.LoadTest
+		valid bool
+	}{
+		{models.LoadTest{RPS: 100}, true},
+		{models.LoadTest{RPS: 0}, false},
+		{models.LoadTest{RPS: models.MaxLoadTestRPS + 1}, false},
+		{models.LoadTest{RPS: 10, Path: "api"}, false},
+		{models.LoadTest{RPS: 10, Method: http.MethodDelete}, false},
+		{models.LoadTest{RPS: 10, DurationSeconds: models.MaxLoadTestDurationSeconds + 1}, false},
+		{models.LoadTest{RPS: 10, DurationSeconds: 5, TimeoutSeconds: 6}, false},
+	} {
+		tc.test.Normalize()
+		if err := tc.test.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tc.test, err, tc.valid)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+// Package localnode runs services on the control plane's own host with
+// Podman, so an all-in-one control plane can deploy without a node agent.
+// The host registers as a single node and the latest deployment of each
+// service placed on it runs as a container publishing its ports on the host.
+package localnode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Labels set on every container the backend runs.
+const (
+	labelManagedBy  = "narvana.managed-by"
+	labelAppID      = "narvana.app-id"
+	labelService    = "narvana.service"
+	labelDeployment = "narvana.deployment-id"
+	managedBy       = "narvana-local"
+)
+
+// errRejected is returned by Deploy when a deployment's container cannot be
+// run, as opposed to Podman being unavailable.
+var errRejected = errors.New("deployment cannot run on the local node")
+
+// Config configures the local node.
+type Config struct {
+	// NodeID is the node the host registers as.
+	NodeID string
+	// Pool is the node pool of the host; empty is the default pool.
+	Pool string
+	// HostAddress is the address services' ports are published on.
+	HostAddress string
+	// SyncInterval is how often the host heartbeats and the status of its
+	// containers is synced; it must be shorter than the scheduler's health
+	// threshold.
+	SyncInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		NodeID:       "local",
+		HostAddress:  "127.0.0.1",
+		SyncInterval: 5 * time.Second,
+	}
+}
+
+// Backend deploys to the host's Podman service and reports the host as a node.
+type Backend struct {
+	store  store.Store
+	config Config
+	events events.Publisher
+	logger *slog.Logger
+	now    func() time.Time
+	// podman runs the podman CLI and returns its standard output; replaced
+	// in tests.
+	podman func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// NewBackend creates a backend for the host.
+func NewBackend(st store.Store, cfg Config, logger *slog.Logger) *Backend {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Backend{
+		store:  st,
+		config: cfg,
+		logger: logger.With("node_id", cfg.NodeID),
+		now:    time.Now,
+		podman: runPodman,
+	}
+}
+
+var _ scheduler.NodeBackend = (*Backend)(nil)
+
+// NodeID returns the ID of the node the host is registered as.
+func (b *Backend) NodeID() string {
+	return b.config.NodeID
+}
+
+// SetEvents sets where the deployment status changes the backend observes
+// are published.
+func (b *Backend) SetEvents(p events.Publisher) {
+	b.events = p
+}
+
+// Run syncs the host every sync interval until ctx is cancelled.
+func (b *Backend) Run(ctx context.Context) {
+	b.SyncOnce(ctx)
+
+	ticker := time.NewTicker(b.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.SyncOnce(ctx)
+		}
+	}
+}
+
+// SyncOnce heartbeats the host's node with its free capacity and brings its
+// containers and the status of its deployments up to date.
+func (b *Backend) SyncOnce(ctx context.Context) {
+	deployments, err := b.store.Deployments().ListByNode(ctx, b.config.NodeID)
+	if err != nil {
+		b.logger.Error("failed to list local deployments", "error", err)
+		return
+	}
+	if err := b.heartbeat(ctx, deployments); err != nil {
+		b.logger.Error("failed to heartbeat local node", "error", err)
+		return
+	}
+	if err := b.reconcile(ctx, deployments); err != nil {
+		b.logger.Error("failed to sync local deployments", "error", err)
+	}
+}
+
+// Deploy runs a deployment's container in place of the one running its
+// service, unless it already runs.
+func (b *Backend) Deploy(ctx context.Context, deployment *models.Deployment) error {
+	if deployment.BuildType == models.BuildTypePureNix {
+		return fmt.Errorf("%w: pure-nix artifacts need a node agent", errRejected)
+	}
+
+	name := containerName(deployment.AppID, deployment.ServiceName)
+	live, err := b.container(ctx, name)
+	if err != nil {
+		return err
+	}
+	if live != nil && live.Labels[labelDeployment] == deployment.ID {
+		return nil
+	}
+	if live != nil {
+		if _, err := b.podman(ctx, "rm", "--force", name); err != nil {
+			return fmt.Errorf("removing previous container: %w", err)
+		}
+	}
+	if _, err := b.podman(ctx, runArgs(deployment, name, b.config.HostAddress)...); err != nil {
+		return fmt.Errorf("%w: starting container: %v", errRejected, err)
+	}
+	return nil
+}
+
+// Stop removes the container of a deployment unless a newer deployment of
+// the service has taken it over.
+func (b *Backend) Stop(ctx context.Context, deploymentID string) error {
+	deployment, err := b.store.Deployments().Get(ctx, deploymentID)
+	if err != nil {
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+
+	name := containerName(deployment.AppID, deployment.ServiceName)
+	live, err := b.container(ctx, name)
+	if err != nil || live == nil || live.Labels[labelDeployment] != deploymentID {
+		return err
+	}
+	if _, err := b.podman(ctx, "rm", "--force", "--time", "10", name); err != nil {
+		return fmt.Errorf("removing container: %w", err)
+	}
+	return nil
+}
+
+// heartbeat registers the host's node with the capacity not reserved by the
+// deployments placed on it.
+func (b *Backend) heartbeat(ctx context.Context, deployments []*models.Deployment) error {
+	cpu := float64(runtime.NumCPU())
+	memory := hostMemory()
+	resources := &models.NodeResources{CPUTotal: cpu, MemoryTotal: memory}
+	for _, d := range deployments {
+		if isActive(d.Status) {
+			req := scheduler.GetResourceRequirements(d.Resources)
+			cpu -= req.CPU
+			memory -= req.Memory
+		}
+	}
+	resources.CPUAvailable = max(cpu, 0)
+	resources.MemoryAvailable = max(memory, 0)
+
+	hostname, _ := os.Hostname()
+	node := &models.Node{
+		ID:            b.config.NodeID,
+		Hostname:      hostname,
+		Address:       b.config.HostAddress,
+		Healthy:       true,
+		Provider:      models.NodeProviderLocal,
+		Pool:          b.config.Pool,
+		Capabilities:  []models.NodeCapability{models.NodeCapabilityOCIImages},
+		Resources:     resources,
+		LastHeartbeat: b.now(),
+	}
+	if err := b.store.Nodes().Register(ctx, node); err != nil {
+		return fmt.Errorf("registering node: %w", err)
+	}
+	return nil
+}
+
+// serviceKey identifies an app's service.
+type serviceKey struct {
+	appID   string
+	service string
+}
+
+// reconcile makes the host run the latest active deployment of every service
+// placed on it, records their status, and removes the containers of
+// services that have none.
+func (b *Backend) reconcile(ctx context.Context, deployments []*models.Deployment) error {
+	current := make(map[serviceKey]*models.Deployment)
+	var active []*models.Deployment
+	for _, d := range deployments {
+		if !isActive(d.Status) {
+			continue
+		}
+		active = append(active, d)
+		key := serviceKey{d.AppID, d.ServiceName}
+		if c, ok := current[key]; !ok || d.Version > c.Version {
+			current[key] = d
+		}
+	}
+
+	for _, d := range current {
+		b.syncDeployment(ctx, d)
+	}
+
+	// The container of a release replaced the one of the release before it
+	for _, d := range active {
+		c := current[serviceKey{d.AppID, d.ServiceName}]
+		if c != d && c.Status == models.DeploymentStatusRunning {
+			b.setStatus(ctx, d, models.DeploymentStatusStopped)
+		}
+	}
+
+	containers, err := b.containers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if d, ok := current[serviceKey{c.Labels[labelAppID], c.Labels[labelService]}]; ok && d.Status != models.DeploymentStatusFailed {
+			continue
+		}
+		name := c.name()
+		b.logger.Info("removing container without a running deployment", "name", name)
+		if _, err := b.podman(ctx, "rm", "--force", name); err != nil {
+			b.logger.Error("failed to remove container", "name", name, "error", err)
+		}
+	}
+	return nil
+}
+
+// syncDeployment starts a deployment's container and records its state on
+// the deployment.
+func (b *Backend) syncDeployment(ctx context.Context, d *models.Deployment) {
+	if err := b.Deploy(ctx, d); err != nil {
+		b.logger.Error("failed to run deployment", "deployment_id", d.ID, "error", err)
+		if errors.Is(err, errRejected) {
+			b.setStatus(ctx, d, models.DeploymentStatusFailed)
+		}
+		return
+	}
+
+	live, err := b.container(ctx, containerName(d.AppID, d.ServiceName))
+	if err != nil || live == nil {
+		b.logger.Error("failed to get container status", "deployment_id", d.ID, "error", err)
+		return
+	}
+
+	switch live.State {
+	case "running":
+		// Deployments with a health check stay starting until it passes
+		if check := d.HealthCheck(); check != nil && d.Status != models.DeploymentStatusRunning {
+			b.checkReadiness(ctx, d, check)
+			return
+		}
+		b.setStatus(ctx, d, models.DeploymentStatusRunning)
+	case "exited", "stopped":
+		b.logger.Error("container exited", "deployment_id", d.ID, "exit_code", live.ExitCode)
+		b.setStatus(ctx, d, models.DeploymentStatusFailed)
+	default:
+		if d.Status == models.DeploymentStatusScheduled {
+			b.setStatus(ctx, d, models.DeploymentStatusStarting)
+		}
+	}
+}
+
+// setStatus records a deployment status change and notifies about it.
+func (b *Backend) setStatus(ctx context.Context, d *models.Deployment, status models.DeploymentStatus) {
+	previous := d.Status
+	if previous == status {
+		return
+	}
+	now := b.now()
+	d.Status = status
+	d.UpdatedAt = now
+	if status == models.DeploymentStatusRunning && d.StartedAt == nil {
+		d.StartedAt = &now
+	}
+	if (status == models.DeploymentStatusStopped || status == models.DeploymentStatusFailed) && d.FinishedAt == nil {
+		d.FinishedAt = &now
+	}
+	if err := b.store.Deployments().Update(ctx, d); err != nil {
+		b.logger.Error("failed to update deployment status", "deployment_id", d.ID, "status", status, "error", err)
+		d.Status = previous
+		return
+	}
+	b.logger.Info("deployment status updated", "deployment_id", d.ID, "status", status)
+	if b.events != nil {
+		b.events.Publish(&events.Transition{
+			Kind:           events.TransitionDeploymentStatus,
+			Deployment:     d,
+			PreviousStatus: previous,
+		})
+	}
+}
+
+// podmanContainer is the subset of podman ps --format json the backend reads.
+type podmanContainer struct {
+	Names    []string          `json:"Names"`
+	State    string            `json:"State"`
+	ExitCode int               `json:"ExitCode"`
+	Labels   map[string]string `json:"Labels"`
+}
+
+func (c *podmanContainer) name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return c.Names[0]
+}
+
+// containers lists the containers the backend runs.
+func (b *Backend) containers(ctx context.Context) ([]podmanContainer, error) {
+	out, err := b.podman(ctx, "ps", "--all", "--format", "json", "--filter", "label="+labelManagedBy+"="+managedBy)
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+	var containers []podmanContainer
+	if len(bytes.TrimSpace(out)) > 0 {
+		if err := json.Unmarshal(out, &containers); err != nil {
+			return nil, fmt.Errorf("decoding containers: %w", err)
+		}
+	}
+	return containers, nil
+}
+
+// container returns the container with the given name, or nil if there is none.
+func (b *Backend) container(ctx context.Context, name string) (*podmanContainer, error) {
+	containers, err := b.containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range containers {
+		if containers[i].name() == name {
+			return &containers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// containerName returns the name of the container running an app's service.
+// Every deployment of the service replaces the same container.
+func containerName(appID, service string) string {
+	suffix := appID
+	if len(suffix) > 8 {
+		suffix = suffix[:8]
+	}
+	return strings.ToLower("narvana-" + service + "-" + suffix)
+}
+
+// runArgs returns the podman arguments that start a deployment's container,
+// publishing each of its ports on the same port of the host address.
+func runArgs(deployment *models.Deployment, name, hostAddress string) []string {
+	args := []string{"run", "--detach", "--name", name,
+		"--label", labelManagedBy + "=" + managedBy,
+		"--label", labelAppID + "=" + deployment.AppID,
+		"--label", labelService + "=" + deployment.ServiceName,
+		"--label", labelDeployment + "=" + deployment.ID,
+	}
+
+	if cfg := deployment.Config; cfg != nil {
+		names := make([]string, 0, len(cfg.EnvVars))
+		for name := range cfg.EnvVars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			args = append(args, "--env", name+"="+cfg.EnvVars[name])
+		}
+		for _, p := range cfg.Ports {
+			protocol := strings.ToLower(p.Protocol)
+			if protocol != "udp" {
+				protocol = "tcp"
+			}
+			args = append(args, "--publish", fmt.Sprintf("%s:%d:%d/%s", hostAddress, p.ContainerPort, p.ContainerPort, protocol))
+		}
+	}
+
+	// Limits match what the scheduler reserved for the deployment
+	req := scheduler.GetResourceRequirements(deployment.Resources)
+	args = append(args,
+		"--cpus", strconv.FormatFloat(req.CPU, 'f', -1, 64),
+		"--memory", strconv.FormatInt(req.Memory, 10),
+		deployment.Artifact,
+	)
+	return args
+}
+
+// isActive reports whether a deployment placed on a node should be running.
+func isActive(status models.DeploymentStatus) bool {
+	return status == models.DeploymentStatusScheduled ||
+		status == models.DeploymentStatusStarting ||
+		status == models.DeploymentStatusRunning
+}
+
+// hostMemory returns the host's total memory in bytes from /proc/meminfo,
+// or zero if it cannot be read.
+func hostMemory() int64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb << 10
+		}
+	}
+	return 0
+}
+
+// runPodman runs the podman CLI and returns its standard output.
+func runPodman(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "podman", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("podman %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
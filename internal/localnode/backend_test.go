@@ -0,0 +1,294 @@
+package localnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// fakePodman keeps the containers started through it in memory.
+type fakePodman struct {
+	containers map[string]*podmanContainer
+	runs       [][]string
+	failRun    bool
+	// execErr is returned by commands run in containers
+	execErr error
+	logs    string
+}
+
+func (f *fakePodman) run(ctx context.Context, args ...string) ([]byte, error) {
+	switch args[0] {
+	case "ps":
+		var list []*podmanContainer
+		for _, c := range f.containers {
+			if c.Labels[labelManagedBy] == managedBy {
+				list = append(list, c)
+			}
+		}
+		return json.Marshal(list)
+	case "rm":
+		delete(f.containers, args[len(args)-1])
+	case "exec":
+		return []byte("ok\n"), f.execErr
+	case "logs":
+		return []byte(f.logs), nil
+	case "run":
+		if f.failRun {
+			return nil, errors.New("image not found")
+		}
+		f.runs = append(f.runs, args)
+		c := &podmanContainer{State: "running", Labels: map[string]string{}}
+		for i := 0; i < len(args)-1; i++ {
+			switch args[i] {
+			case "--name":
+				c.Names = []string{args[i+1]}
+			case "--label":
+				k, v, _ := strings.Cut(args[i+1], "=")
+				c.Labels[k] = v
+			}
+		}
+		f.containers[c.name()] = c
+	}
+	return nil, nil
+}
+
+type localDeploymentStore struct {
+	store.DeploymentStore
+	deployments []*models.Deployment
+}
+
+func (s *localDeploymentStore) ListByNode(ctx context.Context, nodeID string) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range s.deployments {
+		if d.NodeID == nodeID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (s *localDeploymentStore) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	for _, d := range s.deployments {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, errors.New("deployment not found")
+}
+
+func (s *localDeploymentStore) Update(ctx context.Context, d *models.Deployment) error { return nil }
+
+type localNodeStore struct {
+	store.NodeStore
+	registered *models.Node
+}
+
+func (s *localNodeStore) Register(ctx context.Context, node *models.Node) error {
+	s.registered = node
+	return nil
+}
+
+type localMockStore struct {
+	store.Store
+	deployments *localDeploymentStore
+	nodes       *localNodeStore
+}
+
+func (s *localMockStore) Deployments() store.DeploymentStore { return s.deployments }
+func (s *localMockStore) Nodes() store.NodeStore             { return s.nodes }
+
+func newTestBackend(t *testing.T, deployments ...*models.Deployment) (*Backend, *fakePodman, *localMockStore) {
+	t.Helper()
+	podman := &fakePodman{containers: map[string]*podmanContainer{}}
+	st := &localMockStore{deployments: &localDeploymentStore{deployments: deployments}, nodes: &localNodeStore{}}
+	b := NewBackend(st, DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.podman = podman.run
+	return b, podman, st
+}
+
+func webDeployment(id string, version int, status models.DeploymentStatus) *models.Deployment {
+	return &models.Deployment{
+		ID: id, AppID: "0f8fad5b-d9cb-469f-a165-70867728950e", ServiceName: "web", Version: version,
+		BuildType: models.BuildTypeOCI, Artifact: "localhost:5000/web:" + id,
+		Status: status, NodeID: "local",
+		Config: &models.RuntimeConfig{
+			Ports:   []models.PortMapping{{ContainerPort: 3000}},
+			EnvVars: map[string]string{"PORT": "3000"},
+		},
+	}
+}
+
+const webContainer = "narvana-web-0f8fad5b"
+
+func TestBackendRunsLatestDeployment(t *testing.T) {
+	old := webDeployment("dep-1", 1, models.DeploymentStatusRunning)
+	d := webDeployment("dep-2", 2, models.DeploymentStatusScheduled)
+	b, podman, st := newTestBackend(t, old, d)
+
+	b.SyncOnce(context.Background())
+	if node := st.nodes.registered; node == nil || node.Provider != models.NodeProviderLocal || node.Resources.CPUTotal == 0 {
+		t.Fatalf("registered node = %+v", node)
+	}
+	c := podman.containers[webContainer]
+	if c == nil || c.Labels[labelDeployment] != "dep-2" {
+		t.Fatalf("containers = %v", podman.containers)
+	}
+	args := podman.runs[0]
+	if !slices.Contains(args, "127.0.0.1:3000:3000/tcp") || !slices.Contains(args, "PORT=3000") || args[len(args)-1] != d.Artifact {
+		t.Errorf("run args = %v", args)
+	}
+	if d.Status != models.DeploymentStatusRunning || old.Status != models.DeploymentStatusStopped {
+		t.Errorf("statuses = %s, %s; want the new deployment running and the old one stopped", d.Status, old.Status)
+	}
+
+	// A running deployment is not restarted
+	b.SyncOnce(context.Background())
+	if len(podman.runs) != 1 {
+		t.Errorf("container started %d times", len(podman.runs))
+	}
+
+	// Stopping an older deployment leaves the service's container alone
+	if err := b.Stop(context.Background(), "dep-1"); err != nil || podman.containers[webContainer] == nil {
+		t.Errorf("Stop() of a replaced deployment = %v, containers %v", err, podman.containers)
+	}
+	if err := b.Stop(context.Background(), "dep-2"); err != nil || podman.containers[webContainer] != nil {
+		t.Errorf("Stop() = %v, containers %v", err, podman.containers)
+	}
+}
+
+func TestBackendFailsDeploymentsItCannotRun(t *testing.T) {
+	nix := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	nix.BuildType = models.BuildTypePureNix
+	b, podman, _ := newTestBackend(t, nix)
+	b.SyncOnce(context.Background())
+	if nix.Status != models.DeploymentStatusFailed || len(podman.runs) != 0 {
+		t.Errorf("pure-nix deployment status = %s, runs = %v", nix.Status, podman.runs)
+	}
+
+	d := webDeployment("dep-2", 1, models.DeploymentStatusScheduled)
+	b, podman, _ = newTestBackend(t, d)
+	podman.failRun = true
+	b.SyncOnce(context.Background())
+	if d.Status != models.DeploymentStatusFailed {
+		t.Errorf("status after failed run = %s", d.Status)
+	}
+}
+
+func TestBackendRemovesStoppedServices(t *testing.T) {
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	b, podman, _ := newTestBackend(t, d)
+	b.SyncOnce(context.Background())
+
+	d.Status = models.DeploymentStatusStopped
+	b.SyncOnce(context.Background())
+	if len(podman.containers) != 0 {
+		t.Errorf("container of a stopped service was kept: %v", podman.containers)
+	}
+}
+
+func TestBackendDebugSessions(t *testing.T) {
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	b, podman, _ := newTestBackend(t, d)
+	b.SyncOnce(context.Background())
+
+	session := &models.DebugSession{ID: "s1", DeploymentID: "dep-1", Image: "busybox"}
+	if err := b.StartDebug(context.Background(), session); err != nil {
+		t.Fatalf("StartDebug: %v", err)
+	}
+	args := podman.runs[len(podman.runs)-1]
+	if !slices.Contains(args, "container:"+webContainer) || !slices.Equal(args[len(args)-3:], []string{"busybox", "sleep", "infinity"}) {
+		t.Errorf("debug run args = %v", args)
+	}
+
+	// Reconciling leaves the debug container alone
+	b.SyncOnce(context.Background())
+	if podman.containers["narvana-debug-s1"] == nil {
+		t.Fatalf("debug container was removed: %v", podman.containers)
+	}
+
+	if err := b.StopDebug(context.Background(), "s1"); err != nil || podman.containers["narvana-debug-s1"] != nil {
+		t.Errorf("StopDebug() = %v, containers %v", err, podman.containers)
+	}
+
+	// Sessions need the deployment's container to be running
+	d.Status = models.DeploymentStatusStopped
+	b.SyncOnce(context.Background())
+	if err := b.StartDebug(context.Background(), session); err == nil {
+		t.Error("StartDebug() of a stopped deployment succeeded")
+	}
+}
+
+func TestBackendGatesDeploymentsOnHealthChecks(t *testing.T) {
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" || !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	host, port, _ := strings.Cut(u.Host, ":")
+
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	d.Config.HealthCheck = &models.HealthCheckConfig{Path: "/ready", IntervalSeconds: 10, Retries: 3}
+	d.Config.Ports[0].ContainerPort, _ = strconv.Atoi(port)
+	b, _, _ := newTestBackend(t, d)
+	b.config.HostAddress = host
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.SyncOnce(context.Background())
+	if d.Status != models.DeploymentStatusStarting || d.Health == nil || d.Health.Failures != 1 || d.Health.Output != "HTTP 503" {
+		t.Fatalf("status = %s, health = %+v; want starting after a failed probe", d.Status, d.Health)
+	}
+
+	// Probes wait for the check's interval
+	b.SyncOnce(context.Background())
+	if d.Health.Failures != 1 {
+		t.Errorf("probed again within the interval: %+v", d.Health)
+	}
+
+	healthy = true
+	now = now.Add(10 * time.Second)
+	b.SyncOnce(context.Background())
+	if d.Status != models.DeploymentStatusRunning || !d.Health.Passed || d.StartedAt == nil {
+		t.Errorf("status = %s, health = %+v; want running", d.Status, d.Health)
+	}
+}
+
+func TestBackendFailsDeploymentsAfterHealthCheckRetries(t *testing.T) {
+	d := webDeployment("dep-1", 1, models.DeploymentStatusScheduled)
+	d.Config.HealthCheck = &models.HealthCheckConfig{Command: []string{"pg_isready"}, IntervalSeconds: 5, Retries: 2}
+	b, podman, _ := newTestBackend(t, d)
+	podman.execErr = errors.New("exit status 2")
+	podman.logs = "FATAL: could not bind to port\n"
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.SyncOnce(context.Background())
+	now = now.Add(5 * time.Second)
+	b.SyncOnce(context.Background())
+	if d.Status != models.DeploymentStatusFailed || d.FinishedAt == nil {
+		t.Fatalf("status = %s, want failed after 2 failed probes", d.Status)
+	}
+	want := "2 consecutive health checks failed (command [\"pg_isready\"]): exit status 2\n\nContainer output:\nFATAL: could not bind to port"
+	if d.Error != want {
+		t.Errorf("Error = %q, want %q", d.Error, want)
+	}
+	if len(podman.containers) != 0 {
+		t.Errorf("container of a failed deployment was kept: %v", podman.containers)
+	}
+}
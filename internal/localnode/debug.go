@@ -0,0 +1,66 @@
+package localnode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+)
+
+// labelDebugSession is set on debug containers. They do not carry the
+// managed-by label so reconcile leaves them to the debug session manager.
+const labelDebugSession = "narvana.debug-session"
+
+var _ scheduler.DebugBackend = (*Backend)(nil)
+
+// StartDebug runs a debug session's container in the namespaces of its
+// deployment's container.
+func (b *Backend) StartDebug(ctx context.Context, session *models.DebugSession) error {
+	deployment, err := b.store.Deployments().Get(ctx, session.DeploymentID)
+	if err != nil {
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+
+	target := containerName(deployment.AppID, deployment.ServiceName)
+	live, err := b.container(ctx, target)
+	if err != nil {
+		return err
+	}
+	if live == nil || live.Labels[labelDeployment] != deployment.ID || live.State != "running" {
+		return fmt.Errorf("deployment %s is not running on this node", deployment.ID)
+	}
+
+	if _, err := b.podman(ctx, debugRunArgs(session, target)...); err != nil {
+		return fmt.Errorf("starting debug container: %w", err)
+	}
+	return nil
+}
+
+// StopDebug removes a debug session's container, if it still exists.
+func (b *Backend) StopDebug(ctx context.Context, sessionID string) error {
+	name := (&models.DebugSession{ID: sessionID}).ContainerName()
+	if _, err := b.podman(ctx, "rm", "--force", "--ignore", "--time", "0", name); err != nil {
+		return fmt.Errorf("removing debug container: %w", err)
+	}
+	return nil
+}
+
+// debugRunArgs returns the podman arguments that start a debug session's
+// container sharing the PID, network and IPC namespaces of target. Without
+// a command the container idles until it is removed.
+func debugRunArgs(session *models.DebugSession, target string) []string {
+	args := []string{"run", "--detach", "--name", session.ContainerName(),
+		"--label", labelDebugSession + "=" + session.ID,
+		"--label", labelDeployment + "=" + session.DeploymentID,
+		"--pid", "container:" + target,
+		"--network", "container:" + target,
+		"--ipc", "container:" + target,
+		"--cap-add", "SYS_PTRACE",
+		session.Image,
+	}
+	if len(session.Command) > 0 {
+		return append(args, session.Command...)
+	}
+	return append(args, "sleep", "infinity")
+}
@@ -0,0 +1,91 @@
+package localnode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// logTail is how many lines of a container's output are added to the error
+// of a deployment whose health check fails.
+const logTail = 20
+
+// checkReadiness probes a starting deployment's health check once its
+// interval has passed. The deployment runs once a probe passes and fails
+// when MaxFailures probes in a row have not.
+func (b *Backend) checkReadiness(ctx context.Context, d *models.Deployment, check *models.HealthCheckConfig) {
+	now := b.now()
+	if d.Status != models.DeploymentStatusStarting || d.Health == nil {
+		d.Health = &models.DeploymentHealth{StartedAt: now}
+		b.setStatus(ctx, d, models.DeploymentStatusStarting)
+	} else if d.Health.CheckedAt != nil && now.Sub(*d.Health.CheckedAt) < check.Interval() {
+		return
+	}
+
+	passed, output := b.probe(ctx, d, check)
+	d.Health.RecordProbe(passed, output, now)
+	switch {
+	case passed:
+		b.setStatus(ctx, d, models.DeploymentStatusRunning)
+	case d.Health.Failures >= check.MaxFailures():
+		d.Error = d.Health.Diagnostics(check)
+		logs, err := b.podman(ctx, "logs", "--tail", strconv.Itoa(logTail), containerName(d.AppID, d.ServiceName))
+		if err == nil && len(logs) > 0 {
+			d.Error += "\n\nContainer output:\n" + strings.TrimSpace(string(logs))
+		}
+		b.logger.Warn("deployment failed its health check", "deployment_id", d.ID, "failures", d.Health.Failures, "output", output)
+		b.setStatus(ctx, d, models.DeploymentStatusFailed)
+	default:
+		if err := b.store.Deployments().Update(ctx, d); err != nil {
+			b.logger.Error("failed to record health check", "deployment_id", d.ID, "error", err)
+		}
+	}
+}
+
+// probe runs a health check once: a command in the deployment's container,
+// an HTTP GET, or a TCP connection to the port published on the host. It
+// returns whether the probe passed and what it observed.
+func (b *Backend) probe(ctx context.Context, d *models.Deployment, check *models.HealthCheckConfig) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout())
+	defer cancel()
+
+	if len(check.Command) > 0 {
+		args := append([]string{"exec", containerName(d.AppID, d.ServiceName)}, check.Command...)
+		out, err := b.podman(ctx, args...)
+		if err != nil {
+			return false, err.Error()
+		}
+		return true, strings.TrimSpace(string(out))
+	}
+
+	port := check.Port
+	if port == 0 && d.Config != nil && len(d.Config.Ports) > 0 {
+		port = d.Config.Ports[0].ContainerPort
+	}
+	addr := net.JoinHostPort(b.config.HostAddress, strconv.Itoa(port))
+
+	if check.Path == "" {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return false, err.Error()
+		}
+		conn.Close()
+		return true, "connected to " + addr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+check.Path, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 400, fmt.Sprintf("HTTP %d", resp.StatusCode)
+}
@@ -0,0 +1,286 @@
+// Package loglevel changes the log levels of the control plane's components
+// at runtime. Changes are stored in the settings table so every API server
+// and build worker process picks them up, and revert on their own after a
+// while so debug logging is not left on in production.
+package loglevel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/pkg/logger"
+)
+
+// Components whose levels can be changed.
+const (
+	ComponentAPI    = "api"
+	ComponentWorker = "worker"
+	ComponentGRPC   = "grpc"
+)
+
+// Components lists the components whose levels can be changed.
+var Components = []string{ComponentAPI, ComponentWorker, ComponentGRPC}
+
+// settingKey is the setting holding the changed levels.
+const settingKey = "log_levels"
+
+var (
+	// ErrUnknownComponent is returned when changing the level of a component
+	// not in Components.
+	ErrUnknownComponent = errors.New("unknown component")
+	// ErrInvalidDuration is returned when a level would revert too soon or
+	// too late.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// Config controls how level changes are picked up and reverted.
+type Config struct {
+	// Interval is how often the stored levels are read.
+	Interval time.Duration
+	// RevertAfter is how long a change lasts when no duration is given.
+	RevertAfter time.Duration
+	// MaxDuration is the longest a change may last.
+	MaxDuration time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Interval:    15 * time.Second,
+		RevertAfter: time.Hour,
+		MaxDuration: 24 * time.Hour,
+	}
+}
+
+// Status is the current log level of a component.
+type Status struct {
+	Component    string     `json:"component"`
+	Level        string     `json:"level"`
+	DefaultLevel string     `json:"default_level"`
+	RevertAt     *time.Time `json:"revert_at,omitempty"`
+}
+
+// override is a changed level of a component, as stored.
+type override struct {
+	Level    slog.Level `json:"level"`
+	RevertAt time.Time  `json:"revert_at"`
+}
+
+// Controller changes component levels and applies the stored changes to the
+// levels of its process.
+type Controller struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	levels    *logger.Levels
+	overrides map[string]override
+	timer     *time.Timer
+}
+
+// NewController creates a log level controller.
+func NewController(st store.Store, cfg Config, logger *slog.Logger) *Controller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Controller{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Manage makes the controller apply changes to levels. Without it changes are
+// only stored, for other processes to apply.
+func (c *Controller) Manage(levels *logger.Levels) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.levels = levels
+	c.apply()
+}
+
+// Run applies the stored levels every interval until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	c.RunOnce(ctx)
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			if c.timer != nil {
+				c.timer.Stop()
+			}
+			c.mu.Unlock()
+			return
+		case <-ticker.C:
+			c.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce applies the stored levels.
+func (c *Controller) RunOnce(ctx context.Context) {
+	overrides, err := c.load(ctx)
+	if err != nil {
+		c.logger.Error("failed to load log levels", "error", err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overrides = overrides
+	c.apply()
+}
+
+// Status returns the stored level of every component.
+func (c *Controller) Status(ctx context.Context) ([]Status, error) {
+	overrides, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overrides = overrides
+	c.apply()
+	return c.status(), nil
+}
+
+// Set changes the level of a component, or of every component if component
+// is empty, for d or the configured RevertAfter if d is zero.
+func (c *Controller) Set(ctx context.Context, component string, level slog.Level, d time.Duration) ([]Status, error) {
+	components := Components
+	if component != "" {
+		if !slices.Contains(Components, component) {
+			return nil, fmt.Errorf("%w %q, must be one of %s", ErrUnknownComponent, component, strings.Join(Components, ", "))
+		}
+		components = []string{component}
+	}
+	if d == 0 {
+		d = c.config.RevertAfter
+	}
+	if d < time.Second || d > c.config.MaxDuration {
+		return nil, fmt.Errorf("%w %s, must be between 1s and %s", ErrInvalidDuration, d, c.config.MaxDuration)
+	}
+
+	overrides, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revertAt := c.now().Add(d)
+	for _, name := range components {
+		overrides[name] = override{Level: level, RevertAt: revertAt}
+	}
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling log levels: %w", err)
+	}
+	if err := c.store.Settings().Set(ctx, settingKey, string(data)); err != nil {
+		return nil, fmt.Errorf("saving log levels: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overrides = overrides
+	c.apply()
+	return c.status(), nil
+}
+
+// load reads the stored changes that have not reverted yet.
+func (c *Controller) load(ctx context.Context) (map[string]override, error) {
+	value, err := c.store.Settings().Get(ctx, settingKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting log levels: %w", err)
+	}
+	overrides := make(map[string]override)
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+			return nil, fmt.Errorf("unmarshaling log levels: %w", err)
+		}
+	}
+	now := c.now()
+	for name, o := range overrides {
+		if !now.Before(o.RevertAt) {
+			delete(overrides, name)
+		}
+	}
+	return overrides, nil
+}
+
+// apply sets the managed levels to the changes that have not reverted, and
+// arms a timer to revert the next one on time. c.mu must be held.
+func (c *Controller) apply() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.levels == nil {
+		return
+	}
+
+	now := c.now()
+	var next time.Time
+	for _, name := range Components {
+		o, ok := c.overrides[name]
+		if !ok || !now.Before(o.RevertAt) {
+			if ok {
+				delete(c.overrides, name)
+			}
+			if c.levels.Level(name) != c.levels.Default() {
+				c.levels.Reset(name)
+				c.logger.Info("log level reverted", "component", name, "level", c.levels.Default().String())
+			}
+			continue
+		}
+		if c.levels.Level(name) != o.Level {
+			c.levels.Set(name, o.Level)
+			c.logger.Info("log level changed", "component", name, "level", o.Level.String(), "revert_at", o.RevertAt)
+		}
+		if next.IsZero() || o.RevertAt.Before(next) {
+			next = o.RevertAt
+		}
+	}
+
+	if !next.IsZero() {
+		c.timer = time.AfterFunc(next.Sub(now), func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.apply()
+		})
+	}
+}
+
+// status describes the level of every component. c.mu must be held.
+func (c *Controller) status() []Status {
+	fallback := slog.LevelInfo
+	if c.levels != nil {
+		fallback = c.levels.Default()
+	}
+	statuses := make([]Status, 0, len(Components))
+	for _, name := range Components {
+		s := Status{Component: name, Level: levelName(fallback), DefaultLevel: levelName(fallback)}
+		if o, ok := c.overrides[name]; ok {
+			revertAt := o.RevertAt
+			s.Level, s.RevertAt = levelName(o.Level), &revertAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// levelName returns a level's lowercase name, such as "debug".
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
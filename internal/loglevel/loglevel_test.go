@@ -0,0 +1,108 @@
+package loglevel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/pkg/logger"
+)
+
+type memStore struct {
+	store.Store
+	settings memSettings
+}
+
+func (s *memStore) Settings() store.SettingsStore { return s.settings }
+
+type memSettings map[string]string
+
+func (m memSettings) Get(_ context.Context, key string) (string, error) { return m[key], nil }
+
+func (m memSettings) Set(_ context.Context, key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m memSettings) GetAll(context.Context) (map[string]string, error) { return m, nil }
+
+func TestController(t *testing.T) {
+	ctx := context.Background()
+	st := &memStore{settings: memSettings{}}
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	// Two processes sharing the settings, like an API server and a worker
+	api := NewController(st, DefaultConfig(), nil)
+	api.now = func() time.Time { return now }
+	apiLevels := logger.NewLevels(slog.LevelInfo)
+	api.Manage(apiLevels)
+	worker := NewController(st, DefaultConfig(), nil)
+	worker.now = api.now
+	workerLevels := logger.NewLevels(slog.LevelInfo)
+	worker.Manage(workerLevels)
+
+	statuses, err := api.Set(ctx, ComponentWorker, slog.LevelDebug, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statuses[1]; s.Component != ComponentWorker || s.Level != "debug" || s.DefaultLevel != "info" || !s.RevertAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("worker status = %+v", s)
+	}
+	if s := statuses[0]; s.Level != "info" || s.RevertAt != nil {
+		t.Errorf("api status = %+v", s)
+	}
+	if got := apiLevels.Level(ComponentWorker); got != slog.LevelDebug {
+		t.Errorf("api process worker level = %s, want DEBUG", got)
+	}
+
+	worker.RunOnce(ctx)
+	if got := workerLevels.Level(ComponentWorker); got != slog.LevelDebug {
+		t.Errorf("worker level = %s, want DEBUG", got)
+	}
+	if got := workerLevels.Level(ComponentAPI); got != slog.LevelInfo {
+		t.Errorf("api level = %s, want INFO", got)
+	}
+
+	// The change reverts once its duration has passed
+	now = now.Add(time.Hour)
+	worker.RunOnce(ctx)
+	if got := workerLevels.Level(ComponentWorker); got != slog.LevelInfo {
+		t.Errorf("worker level after revert = %s, want INFO", got)
+	}
+	statuses, err = api.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statuses[1]; s.Level != "info" || s.RevertAt != nil {
+		t.Errorf("worker status after revert = %+v", s)
+	}
+}
+
+func TestControllerSetAll(t *testing.T) {
+	c := NewController(&memStore{settings: memSettings{}}, DefaultConfig(), nil)
+	levels := logger.NewLevels(slog.LevelInfo)
+	c.Manage(levels)
+
+	if _, err := c.Set(context.Background(), "", slog.LevelWarn, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range Components {
+		if got := levels.Level(name); got != slog.LevelWarn {
+			t.Errorf("%s level = %s, want WARN", name, got)
+		}
+	}
+}
+
+func TestControllerSetErrors(t *testing.T) {
+	c := NewController(&memStore{settings: memSettings{}}, DefaultConfig(), nil)
+
+	if _, err := c.Set(context.Background(), "web", slog.LevelDebug, 0); !errors.Is(err, ErrUnknownComponent) {
+		t.Errorf("unknown component: err = %v", err)
+	}
+	if _, err := c.Set(context.Background(), ComponentAPI, slog.LevelDebug, 48*time.Hour); !errors.Is(err, ErrInvalidDuration) {
+		t.Errorf("too long: err = %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ErrLogDropped is returned by Enqueue when an entry is discarded because the
+// ingestion buffer stayed full.
+var ErrLogDropped = errors.New("log entry dropped: ingestion buffer full")
+
+// BatchWriter persists log entries in bulk.
+type BatchWriter interface {
+	CreateBatch(ctx context.Context, entries []*models.LogEntry) error
+}
+
+// DropPolicy chooses which entries to discard when the buffer is full.
+type DropPolicy string
+
+const (
+	// DropOldest discards the oldest buffered entry to make room, keeping the
+	// most recent output.
+	DropOldest DropPolicy = "oldest"
+	// DropNewest rejects the incoming entry.
+	DropNewest DropPolicy = "newest"
+)
+
+// IngesterConfig configures log ingestion batching.
+type IngesterConfig struct {
+	BatchSize      int           // Entries per insert
+	FlushInterval  time.Duration // Maximum time an entry waits before being written
+	BufferSize     int           // Entries held while the database is slow or unavailable
+	EnqueueTimeout time.Duration // How long Enqueue blocks on a full buffer before dropping; negative drops immediately
+	DropPolicy     DropPolicy
+	MaxRetries     int           // Attempts per batch before it is dropped
+	RetryBackoff   time.Duration // Delay between attempts, doubled each retry
+}
+
+// DefaultIngesterConfig returns sensible defaults for log ingestion.
+func DefaultIngesterConfig() IngesterConfig {
+	return IngesterConfig{
+		BatchSize:      500,
+		FlushInterval:  250 * time.Millisecond,
+		BufferSize:     20000,
+		EnqueueTimeout: 2 * time.Second,
+		DropPolicy:     DropOldest,
+		MaxRetries:     3,
+		RetryBackoff:   500 * time.Millisecond,
+	}
+}
+
+// IngesterStats is a snapshot of ingestion counters.
+type IngesterStats struct {
+	Received      int64 `json:"received"`       // Entries accepted or dropped by Enqueue
+	Written       int64 `json:"written"`        // Entries persisted
+	Dropped       int64 `json:"dropped"`        // Entries discarded by the drop policy or after failed retries
+	Batches       int64 `json:"batches"`        // Successful inserts
+	FailedFlushes int64 `json:"failed_flushes"` // Insert attempts that returned an error
+	Buffered      int   `json:"buffered"`       // Entries currently waiting to be written
+}
+
+// Ingester buffers log entries and writes them in batches, so bursts of log
+// lines cost one insert per batch rather than one per line. When the buffer is
+// full, Enqueue blocks for up to EnqueueTimeout, which slows the caller's
+// stream and pushes back on the sender, before applying the drop policy.
+type Ingester struct {
+	writer BatchWriter
+	config IngesterConfig
+	logger *slog.Logger
+
+	entries chan *models.LogEntry
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	received      atomic.Int64
+	written       atomic.Int64
+	dropped       atomic.Int64
+	batches       atomic.Int64
+	failedFlushes atomic.Int64
+}
+
+// NewIngester creates a log ingester. Zero config fields take their defaults.
+func NewIngester(writer BatchWriter, config IngesterConfig, logger *slog.Logger) *Ingester {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	defaults := DefaultIngesterConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.EnqueueTimeout == 0 {
+		config.EnqueueTimeout = defaults.EnqueueTimeout
+	}
+	if config.DropPolicy == "" {
+		config.DropPolicy = defaults.DropPolicy
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+
+	return &Ingester{
+		writer:  writer,
+		config:  config,
+		logger:  logger,
+		entries: make(chan *models.LogEntry, config.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start begins writing batches in the background.
+func (i *Ingester) Start() {
+	go i.run()
+}
+
+// Stop flushes buffered entries and stops the background writer. It returns
+// when the buffer is drained or ctx is done.
+func (i *Ingester) Stop(ctx context.Context) error {
+	i.once.Do(func() { close(i.stop) })
+	select {
+	case <-i.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue adds an entry to the buffer. It blocks while the buffer is full, up
+// to EnqueueTimeout or until ctx is done, then applies the drop policy.
+// ErrLogDropped means the entry (or, with DropOldest, an older one) was lost.
+func (i *Ingester) Enqueue(ctx context.Context, entry *models.LogEntry) error {
+	i.received.Add(1)
+
+	select {
+	case i.entries <- entry:
+		return nil
+	default:
+	}
+
+	// Buffer full: wait for the writer to catch up
+	if i.config.EnqueueTimeout > 0 {
+		timer := time.NewTimer(i.config.EnqueueTimeout)
+		defer timer.Stop()
+		select {
+		case i.entries <- entry:
+			return nil
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+	}
+
+	if i.config.DropPolicy == DropOldest {
+		select {
+		case <-i.entries:
+		default:
+		}
+		select {
+		case i.entries <- entry:
+		default:
+			// Another producer took the freed slot; drop this entry too
+			i.dropped.Add(1)
+		}
+	}
+
+	i.dropped.Add(1)
+	return ErrLogDropped
+}
+
+// Stats returns a snapshot of the ingestion counters.
+func (i *Ingester) Stats() IngesterStats {
+	return IngesterStats{
+		Received:      i.received.Load(),
+		Written:       i.written.Load(),
+		Dropped:       i.dropped.Load(),
+		Batches:       i.batches.Load(),
+		FailedFlushes: i.failedFlushes.Load(),
+		Buffered:      len(i.entries),
+	}
+}
+
+// run collects entries into batches and writes them when a batch is full or
+// the flush interval elapses.
+func (i *Ingester) run() {
+	defer close(i.done)
+
+	ticker := time.NewTicker(i.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.LogEntry, 0, i.config.BatchSize)
+	for {
+		select {
+		case entry := <-i.entries:
+			batch = append(batch, entry)
+			if len(batch) >= i.config.BatchSize {
+				i.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				i.flush(batch)
+				batch = batch[:0]
+			}
+		case <-i.stop:
+			// Drain whatever is still buffered
+			for {
+				select {
+				case entry := <-i.entries:
+					batch = append(batch, entry)
+					if len(batch) >= i.config.BatchSize {
+						i.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						i.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch, retrying with backoff. While it retries, new entries
+// accumulate in the buffer; once retries are exhausted the batch is dropped.
+func (i *Ingester) flush(batch []*models.LogEntry) {
+	backoff := i.config.RetryBackoff
+	var err error
+	for attempt := 1; attempt <= i.config.MaxRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = i.writer.CreateBatch(ctx, batch)
+		cancel()
+		if err == nil {
+			i.written.Add(int64(len(batch)))
+			i.batches.Add(1)
+			return
+		}
+
+		i.failedFlushes.Add(1)
+		if attempt < i.config.MaxRetries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	dropped := i.dropped.Add(int64(len(batch)))
+	i.logger.Error("dropping log batch after failed writes",
+		"entries", len(batch),
+		"attempts", i.config.MaxRetries,
+		"total_dropped", dropped,
+		"error", err,
+	)
+}
@@ -0,0 +1,180 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// fakeBatchWriter records batches and can be told to fail.
+type fakeBatchWriter struct {
+	mu      sync.Mutex
+	batches [][]*models.LogEntry
+	err     error
+}
+
+func (w *fakeBatchWriter) CreateBatch(ctx context.Context, entries []*models.LogEntry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.batches = append(w.batches, append([]*models.LogEntry(nil), entries...))
+	return nil
+}
+
+func (w *fakeBatchWriter) count() (batches, entries int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.batches {
+		entries += len(b)
+	}
+	return len(w.batches), entries
+}
+
+func TestIngester_BatchesEntries(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	ing := NewIngester(writer, IngesterConfig{BatchSize: 10, FlushInterval: time.Hour}, nil)
+	ing.Start()
+
+	for i := 0; i < 25; i++ {
+		if err := ing.Enqueue(context.Background(), &models.LogEntry{Message: "line"}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	if err := ing.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	batches, entries := writer.count()
+	if entries != 25 {
+		t.Errorf("wrote %d entries, want 25", entries)
+	}
+	if batches != 3 {
+		t.Errorf("wrote %d batches, want 3", batches)
+	}
+
+	stats := ing.Stats()
+	if stats.Received != 25 || stats.Written != 25 || stats.Dropped != 0 || stats.Batches != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestIngester_FlushInterval(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	ing := NewIngester(writer, IngesterConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, nil)
+	ing.Start()
+	defer ing.Stop(context.Background())
+
+	if err := ing.Enqueue(context.Background(), &models.LogEntry{Message: "line"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, entries := writer.count(); entries == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("entry was not flushed within the flush interval")
+}
+
+func TestIngester_DropPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DropPolicy
+		want   []string
+	}{
+		{name: "drop oldest keeps the newest entries", policy: DropOldest, want: []string{"b", "c"}},
+		{name: "drop newest keeps the buffered entries", policy: DropNewest, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeBatchWriter{}
+			// Not started, so the buffer fills and stays full
+			ing := NewIngester(writer, IngesterConfig{BufferSize: 2, EnqueueTimeout: -1, DropPolicy: tt.policy}, nil)
+
+			var dropErr error
+			for _, msg := range []string{"a", "b", "c"} {
+				if err := ing.Enqueue(context.Background(), &models.LogEntry{Message: msg}); err != nil {
+					dropErr = err
+				}
+			}
+			if !errors.Is(dropErr, ErrLogDropped) {
+				t.Fatalf("expected ErrLogDropped, got %v", dropErr)
+			}
+
+			ing.Start()
+			if err := ing.Stop(context.Background()); err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+
+			var got []string
+			for _, b := range writer.batches {
+				for _, e := range b {
+					got = append(got, e.Message)
+				}
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("wrote %v, want %v", got, tt.want)
+			}
+			if stats := ing.Stats(); stats.Dropped != 1 || stats.Received != 3 {
+				t.Errorf("unexpected stats: %+v", stats)
+			}
+		})
+	}
+}
+
+func TestIngester_EnqueueBlocksBeforeDropping(t *testing.T) {
+	writer := &fakeBatchWriter{}
+	ing := NewIngester(writer, IngesterConfig{BufferSize: 1, EnqueueTimeout: 20 * time.Millisecond}, nil)
+
+	if err := ing.Enqueue(context.Background(), &models.LogEntry{Message: "a"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	start := time.Now()
+	err := ing.Enqueue(context.Background(), &models.LogEntry{Message: "b"})
+	if !errors.Is(err, ErrLogDropped) {
+		t.Fatalf("expected ErrLogDropped, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Enqueue returned after %v, expected it to wait for the timeout", elapsed)
+	}
+}
+
+func TestIngester_DropsBatchAfterRetries(t *testing.T) {
+	writer := &fakeBatchWriter{err: errors.New("database unavailable")}
+	ing := NewIngester(writer, IngesterConfig{
+		BatchSize:    5,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, nil)
+	ing.Start()
+
+	for i := 0; i < 5; i++ {
+		if err := ing.Enqueue(context.Background(), &models.LogEntry{Message: "line"}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	if err := ing.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	stats := ing.Stats()
+	if stats.Dropped != 5 {
+		t.Errorf("dropped %d entries, want 5", stats.Dropped)
+	}
+	if stats.FailedFlushes != 2 {
+		t.Errorf("failed flushes = %d, want 2", stats.FailedFlushes)
+	}
+	if stats.Written != 0 {
+		t.Errorf("written = %d, want 0", stats.Written)
+	}
+}
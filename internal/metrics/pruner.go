@@ -0,0 +1,78 @@
+// Package metrics keeps the container resource usage rollups node agents
+// report within their retention period.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how long rollups are kept.
+type Config struct {
+	// Retention is how long rollups are kept. It should cover the longest
+	// range the metrics API serves.
+	Retention time.Duration
+	// PruneInterval is how often expired rollups are deleted.
+	PruneInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Retention:     8 * 24 * time.Hour,
+		PruneInterval: time.Hour,
+	}
+}
+
+// Pruner deletes rollups older than the retention period.
+type Pruner struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewPruner creates a metrics pruner.
+func NewPruner(st store.Store, cfg Config, logger *slog.Logger) *Pruner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Pruner{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run prunes expired rollups every prune interval until ctx is cancelled.
+func (p *Pruner) Run(ctx context.Context) {
+	p.PruneOnce(ctx)
+
+	ticker := time.NewTicker(p.config.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PruneOnce(ctx)
+		}
+	}
+}
+
+// PruneOnce deletes rollups older than the retention period.
+func (p *Pruner) PruneOnce(ctx context.Context) {
+	deleted, err := p.store.Metrics().DeleteBefore(ctx, p.now().Add(-p.config.Retention))
+	if err != nil {
+		p.logger.Error("failed to prune metric rollups", "error", err)
+		return
+	}
+	if deleted > 0 {
+		p.logger.Debug("pruned metric rollups", "deleted", deleted)
+	}
+}
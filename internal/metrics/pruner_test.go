@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+type memStore struct {
+	store.Store
+	rollups []*models.MetricRollup
+}
+
+func (s *memStore) Metrics() store.MetricStore { return memMetrics{s: s} }
+
+type memMetrics struct {
+	store.MetricStore
+	s *memStore
+}
+
+func (m memMetrics) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var kept []*models.MetricRollup
+	for _, r := range m.s.rollups {
+		if !r.Bucket.Before(before) {
+			kept = append(kept, r)
+		}
+	}
+	deleted := int64(len(m.s.rollups) - len(kept))
+	m.s.rollups = kept
+	return deleted, nil
+}
+
+func TestPruneOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	st := &memStore{rollups: []*models.MetricRollup{
+		{DeploymentID: "old", Bucket: now.Add(-9 * 24 * time.Hour)},
+		{DeploymentID: "edge", Bucket: now.Add(-8 * 24 * time.Hour)},
+		{DeploymentID: "recent", Bucket: now.Add(-time.Hour)},
+	}}
+	p := NewPruner(st, DefaultConfig(), nil)
+	p.now = func() time.Time { return now }
+
+	p.PruneOnce(context.Background())
+
+	if len(st.rollups) != 2 || st.rollups[0].DeploymentID != "edge" || st.rollups[1].DeploymentID != "recent" {
+		t.Errorf("rollups after pruning = %+v, want edge and recent", st.rollups)
+	}
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+)
+
+// Tuning of resource recommendations.
+const (
+	// RecommendationWindow is how far back usage is analyzed.
+	RecommendationWindow = 7 * 24 * time.Hour
+	// MinRecommendationSamples is the usage, in reported minutes, needed
+	// before a service is right-sized: a day's worth.
+	MinRecommendationSamples = 24 * 60
+
+	// usageHeadroom is kept above p95 usage, and peakHeadroom above the
+	// highest memory seen so a right-sized service is not OOM killed.
+	usageHeadroom = 1.3
+	peakHeadroom  = 1.1
+	// minReduction is the share a resource must shrink by to be worth a
+	// redeploy. Any increase is recommended.
+	minReduction = 0.2
+
+	memoryStep = 64 << 20
+	cpuStep    = 0.1
+)
+
+// Pricing is the monthly cost of resources, used to estimate savings.
+type Pricing struct {
+	CPUCoreMonth   float64
+	MemoryGiBMonth float64
+}
+
+// Recommend right-sizes a service from its rollups over the recommendation
+// window. Each deployment's per-minute average usage counts as one
+// observation. It returns nil if the service reported too little usage or
+// its resources already fit.
+func Recommend(service *models.ServiceConfig, current models.ResourceSpec, rollups []*models.MetricRollup, pricing Pricing) *models.ResourceRecommendation {
+	var cpu []float64
+	var memory []int64
+	usage := models.ResourceUsageSummary{}
+	for _, r := range rollups {
+		if r.Samples == 0 {
+			continue
+		}
+		cpu = append(cpu, r.CPUPercentSum/float64(r.Samples)/100)
+		memory = append(memory, r.MemoryBytesSum/int64(r.Samples))
+		usage.CPUCoresMax = max(usage.CPUCoresMax, r.CPUPercentMax/100)
+		usage.MemoryBytesMax = max(usage.MemoryBytesMax, r.MemoryBytesMax)
+	}
+	usage.Samples = len(cpu)
+	if usage.Samples < MinRecommendationSamples {
+		return nil
+	}
+	sort.Float64s(cpu)
+	sort.Slice(memory, func(i, j int) bool { return memory[i] < memory[j] })
+	p95 := int(math.Ceil(0.95*float64(usage.Samples))) - 1
+	usage.CPUCoresP95 = round(cpu[p95], 3)
+	usage.MemoryBytesP95 = memory[p95]
+	usage.CPUCoresMax = round(usage.CPUCoresMax, 3)
+
+	have := scheduler.GetResourceRequirements(&current)
+	rec := &models.ResourceRecommendation{
+		ServiceName: service.Name,
+		Replicas:    max(service.Replicas, 1),
+		Current:     current,
+		Recommended: current,
+		Usage:       usage,
+	}
+
+	wantCPU := max(math.Ceil(usage.CPUCoresP95*usageHeadroom/cpuStep)*cpuStep, cpuStep)
+	if resize(have.CPU, wantCPU) {
+		rec.Recommended.CPU = strconv.FormatFloat(round(wantCPU, 2), 'f', -1, 64)
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("%s uses p95 %s of its %s CPU cores, peaking at %s",
+			service.Name, formatCores(usage.CPUCoresP95), formatCores(have.CPU), formatCores(usage.CPUCoresMax)))
+	} else {
+		wantCPU = have.CPU
+	}
+
+	wantMemory := max(float64(usage.MemoryBytesP95)*usageHeadroom, float64(usage.MemoryBytesMax)*peakHeadroom)
+	wantMemoryBytes := max(int64(math.Ceil(wantMemory/memoryStep))*memoryStep, memoryStep)
+	if resize(float64(have.Memory), float64(wantMemoryBytes)) {
+		rec.Recommended.Memory = memorySpec(wantMemoryBytes)
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("%s uses p95 %s of its %s memory, peaking at %s",
+			service.Name, formatBytes(usage.MemoryBytesP95), formatBytes(have.Memory), formatBytes(usage.MemoryBytesMax)))
+	} else {
+		wantMemoryBytes = have.Memory
+	}
+
+	if len(rec.Reasons) == 0 {
+		return nil
+	}
+
+	replicas := float64(rec.Replicas)
+	rec.Savings.CPUCores = round((have.CPU-wantCPU)*replicas, 2)
+	rec.Savings.MemoryBytes = (have.Memory - wantMemoryBytes) * int64(rec.Replicas)
+	rec.Savings.MonthlyCost = round(rec.Savings.CPUCores*pricing.CPUCoreMonth+
+		float64(rec.Savings.MemoryBytes)/(1<<30)*pricing.MemoryGiBMonth, 2)
+	return rec
+}
+
+// resize returns true if a resource should change from have to want: it
+// grows, or shrinks by enough to be worth a redeploy.
+func resize(have, want float64) bool {
+	return want > have || want <= have*(1-minReduction)
+}
+
+// memorySpec formats bytes, a multiple of memoryStep, as a resource spec
+// memory such as "512Mi" or "2Gi".
+func memorySpec(bytes int64) string {
+	if bytes%(1<<30) == 0 {
+		return strconv.FormatInt(bytes>>30, 10) + "Gi"
+	}
+	return strconv.FormatInt(bytes>>20, 10) + "Mi"
+}
+
+// formatBytes formats a memory size for people, e.g. "180Mi" or "1.5Gi".
+func formatBytes(bytes int64) string {
+	if bytes < 1<<30 {
+		return strconv.FormatInt(int64(math.Round(float64(bytes)/(1<<20))), 10) + "Mi"
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(bytes)/(1<<30)), ".0") + "Gi"
+}
+
+// formatCores formats a CPU amount for people, e.g. "0.12".
+func formatCores(cores float64) string {
+	return strconv.FormatFloat(round(cores, 2), 'f', -1, 64)
+}
+
+// round rounds v to the given number of decimals.
+func round(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
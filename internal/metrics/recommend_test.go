@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// usage returns a deployment's rollups for the given minutes of constant
+// usage, with one peak sample.
+func usage(minutes int, cpuPercent float64, memory int64, peakCPU float64, peakMemory int64) []*models.MetricRollup {
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	rollups := make([]*models.MetricRollup, minutes)
+	for i := range rollups {
+		rollups[i] = &models.MetricRollup{
+			DeploymentID:   "dep-1",
+			Bucket:         start.Add(time.Duration(i) * time.Minute),
+			Samples:        2,
+			CPUPercentSum:  2 * cpuPercent,
+			CPUPercentMax:  cpuPercent,
+			MemoryBytesSum: 2 * memory,
+			MemoryBytesMax: memory,
+		}
+	}
+	rollups[0].CPUPercentMax, rollups[0].MemoryBytesMax = peakCPU, peakMemory
+	return rollups
+}
+
+func TestRecommend(t *testing.T) {
+	pricing := Pricing{CPUCoreMonth: 20, MemoryGiBMonth: 2.5}
+	day := MinRecommendationSamples
+
+	t.Run("oversized", func(t *testing.T) {
+		service := &models.ServiceConfig{Name: "web", Replicas: 2}
+		rec := Recommend(service, models.ResourceSpec{CPU: "1", Memory: "1Gi"}, usage(day, 10, 180<<20, 40, 210<<20), pricing)
+		if rec == nil {
+			t.Fatal("Recommend() = nil")
+		}
+		if want := (models.ResourceSpec{CPU: "0.2", Memory: "256Mi"}); rec.Recommended != want {
+			t.Errorf("recommended = %+v, want %+v", rec.Recommended, want)
+		}
+		wantReasons := []string{
+			"web uses p95 0.1 of its 1 CPU cores, peaking at 0.4",
+			"web uses p95 180Mi of its 1Gi memory, peaking at 210Mi",
+		}
+		if !reflect.DeepEqual(rec.Reasons, wantReasons) {
+			t.Errorf("reasons = %q, want %q", rec.Reasons, wantReasons)
+		}
+		if want := (models.ResourceSavings{CPUCores: 1.6, MemoryBytes: 1536 << 20, MonthlyCost: 35.75}); rec.Savings != want {
+			t.Errorf("savings = %+v, want %+v", rec.Savings, want)
+		}
+		if rec.Usage.Samples != day || rec.Usage.MemoryBytesMax != 210<<20 || rec.Usage.CPUCoresMax != 0.4 {
+			t.Errorf("usage = %+v", rec.Usage)
+		}
+	})
+
+	t.Run("near memory limit", func(t *testing.T) {
+		service := &models.ServiceConfig{Name: "api", Replicas: 1}
+		rec := Recommend(service, models.ResourceSpec{CPU: "0.5", Memory: "512Mi"}, usage(day, 35, 500<<20, 50, 500<<20), pricing)
+		if rec == nil {
+			t.Fatal("Recommend() = nil")
+		}
+		if want := (models.ResourceSpec{CPU: "0.5", Memory: "704Mi"}); rec.Recommended != want {
+			t.Errorf("recommended = %+v, want %+v", rec.Recommended, want)
+		}
+		if want := (models.ResourceSavings{MemoryBytes: -192 << 20, MonthlyCost: -0.47}); rec.Savings != want {
+			t.Errorf("savings = %+v, want %+v", rec.Savings, want)
+		}
+	})
+
+	t.Run("already fits", func(t *testing.T) {
+		service := &models.ServiceConfig{Name: "web", Replicas: 1}
+		if rec := Recommend(service, models.ResourceSpec{CPU: "0.2", Memory: "256Mi"}, usage(day, 10, 180<<20, 40, 210<<20), pricing); rec != nil {
+			t.Errorf("Recommend() = %+v, want nil", rec)
+		}
+	})
+
+	t.Run("too little usage", func(t *testing.T) {
+		service := &models.ServiceConfig{Name: "web", Replicas: 1}
+		if rec := Recommend(service, models.ResourceSpec{CPU: "1", Memory: "1Gi"}, usage(day-1, 10, 180<<20, 40, 210<<20), pricing); rec != nil {
+			t.Errorf("Recommend() = %+v, want nil", rec)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AdmissionStage is the point in a deploy at which a policy is evaluated.
+type AdmissionStage string
+
+const (
+	// AdmissionStageBuild policies run when a deploy is requested, before
+	// its build is queued. They see the app, service and deployment.
+	AdmissionStageBuild AdmissionStage = "build"
+	// AdmissionStageDeploy policies run when a built deployment is about to
+	// be scheduled. They also see the build's artifact and attestation.
+	AdmissionStageDeploy AdmissionStage = "deploy"
+)
+
+// AdmissionEnforcement says what happens when a policy is violated.
+type AdmissionEnforcement string
+
+const (
+	// AdmissionEnforcementDeny rejects the deploy.
+	AdmissionEnforcementDeny AdmissionEnforcement = "deny"
+	// AdmissionEnforcementWarn records the violation and lets the deploy go ahead.
+	AdmissionEnforcementWarn AdmissionEnforcement = "warn"
+)
+
+// AdmissionPolicy is an org-wide rule deploys must satisfy, e.g. "no :latest
+// tags". Expression must evaluate to true for deploys the policy applies to;
+// Match, when set, limits it to deploys for which Match is true.
+type AdmissionPolicy struct {
+	ID          string               `json:"id"`
+	OrgID       string               `json:"org_id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Stage       AdmissionStage       `json:"stage"`
+	Match       string               `json:"match,omitempty"`
+	Expression  string               `json:"expression"`
+	Message     string               `json:"message,omitempty"` // Shown when violated; defaults to the name
+	Enforcement AdmissionEnforcement `json:"enforcement"`
+	Enabled     bool                 `json:"enabled"`
+	CreatedBy   string               `json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// Validate checks the policy's fields and applies defaults. Expressions are
+// compiled by the admission package.
+func (p *AdmissionPolicy) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(p.Name) > 100 {
+		return errors.New("name must be 100 characters or fewer")
+	}
+	if len(p.Description) > 500 || len(p.Message) > 500 {
+		return errors.New("description and message must be 500 characters or fewer")
+	}
+	switch p.Stage {
+	case "":
+		p.Stage = AdmissionStageBuild
+	case AdmissionStageBuild, AdmissionStageDeploy:
+	default:
+		return errors.New("stage must be one of: build, deploy")
+	}
+	switch p.Enforcement {
+	case "":
+		p.Enforcement = AdmissionEnforcementDeny
+	case AdmissionEnforcementDeny, AdmissionEnforcementWarn:
+	default:
+		return errors.New("enforcement must be one of: deny, warn")
+	}
+	return nil
+}
+
+// ViolationMessage returns the message reported when the policy is violated.
+func (p *AdmissionPolicy) ViolationMessage() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	return p.Name
+}
+
+// AdmissionViolation records a deploy that violated a policy.
+type AdmissionViolation struct {
+	ID           string               `json:"id"`
+	OrgID        string               `json:"org_id"`
+	PolicyID     string               `json:"policy_id"`
+	PolicyName   string               `json:"policy_name"`
+	Stage        AdmissionStage       `json:"stage"`
+	Enforcement  AdmissionEnforcement `json:"enforcement"`
+	AppID        string               `json:"app_id"`
+	ServiceName  string               `json:"service_name"`
+	DeploymentID string               `json:"deployment_id,omitempty"` // Empty for build-stage rejections, which create no deployment
+	UserID       string               `json:"user_id,omitempty"`
+	Message      string               `json:"message"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
@@ -0,0 +1,105 @@
+// Package models provides data structures for the Narvana platform.
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AnnouncementLevel represents the severity of an announcement banner.
+type AnnouncementLevel string
+
+const (
+	// AnnouncementLevelInfo is used for general notices.
+	AnnouncementLevelInfo AnnouncementLevel = "info"
+	// AnnouncementLevelWarning is used for upcoming maintenance or degraded service.
+	AnnouncementLevelWarning AnnouncementLevel = "warning"
+	// AnnouncementLevelCritical is used for outages and urgent action items.
+	AnnouncementLevelCritical AnnouncementLevel = "critical"
+)
+
+// AnnouncementAudience determines which users see an announcement.
+type AnnouncementAudience string
+
+const (
+	// AnnouncementAudienceAll shows the announcement to every user.
+	AnnouncementAudienceAll AnnouncementAudience = "all"
+	// AnnouncementAudienceOwners shows the announcement to owners only.
+	AnnouncementAudienceOwners AnnouncementAudience = "owners"
+	// AnnouncementAudienceMembers shows the announcement to members only.
+	AnnouncementAudienceMembers AnnouncementAudience = "members"
+)
+
+// MaxAnnouncementMessageLength is the maximum length of an announcement message.
+const MaxAnnouncementMessageLength = 500
+
+// Announcement represents an admin-managed banner displayed across the web UI.
+type Announcement struct {
+	ID        string               `json:"id"`
+	Message   string               `json:"message"`
+	Level     AnnouncementLevel    `json:"level"`
+	Audience  AnnouncementAudience `json:"audience"`
+	StartsAt  *time.Time           `json:"starts_at,omitempty"` // Shown immediately when nil
+	EndsAt    *time.Time           `json:"ends_at,omitempty"`   // Shown indefinitely when nil
+	CreatedBy string               `json:"created_by"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// Validate checks the announcement fields and applies defaults for level and audience.
+func (a *Announcement) Validate() error {
+	a.Message = strings.TrimSpace(a.Message)
+	if a.Message == "" {
+		return errors.New("message is required")
+	}
+	if len(a.Message) > MaxAnnouncementMessageLength {
+		return errors.New("message must be 500 characters or fewer")
+	}
+
+	if a.Level == "" {
+		a.Level = AnnouncementLevelInfo
+	}
+	switch a.Level {
+	case AnnouncementLevelInfo, AnnouncementLevelWarning, AnnouncementLevelCritical:
+	default:
+		return errors.New("level must be one of: info, warning, critical")
+	}
+
+	if a.Audience == "" {
+		a.Audience = AnnouncementAudienceAll
+	}
+	switch a.Audience {
+	case AnnouncementAudienceAll, AnnouncementAudienceOwners, AnnouncementAudienceMembers:
+	default:
+		return errors.New("audience must be one of: all, owners, members")
+	}
+
+	if a.StartsAt != nil && a.EndsAt != nil && !a.EndsAt.After(*a.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// IsActiveAt returns true if the announcement should be displayed at the given time.
+func (a *Announcement) IsActiveAt(now time.Time) bool {
+	if a.StartsAt != nil && now.Before(*a.StartsAt) {
+		return false
+	}
+	if a.EndsAt != nil && !now.Before(*a.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// IsVisibleTo returns true if a user with the given role is in the announcement's audience.
+func (a *Announcement) IsVisibleTo(role Role) bool {
+	switch a.Audience {
+	case AnnouncementAudienceOwners:
+		return role == RoleOwner
+	case AnnouncementAudienceMembers:
+		return role == RoleMember
+	default:
+		return true
+	}
+}
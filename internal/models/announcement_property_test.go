@@ -0,0 +1,105 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: announcements, Property 1: Announcement Display Window**
+// For any announcement with a start and end time, the announcement SHALL be active
+// exactly when the current time is at or after the start and before the end.
+
+func TestAnnouncementIsActiveAt(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	properties.Property("active exactly within [starts_at, ends_at)", prop.ForAll(
+		func(startOffset, duration, nowOffset int) bool {
+			startsAt := base.Add(time.Duration(startOffset) * time.Minute)
+			endsAt := startsAt.Add(time.Duration(duration) * time.Minute)
+			now := base.Add(time.Duration(nowOffset) * time.Minute)
+
+			a := &Announcement{StartsAt: &startsAt, EndsAt: &endsAt}
+			expected := !now.Before(startsAt) && now.Before(endsAt)
+			return a.IsActiveAt(now) == expected
+		},
+		gen.IntRange(-1000, 1000),
+		gen.IntRange(1, 1000),
+		gen.IntRange(-2000, 2000),
+	))
+
+	properties.Property("open-ended announcements are always active", prop.ForAll(
+		func(nowOffset int) bool {
+			a := &Announcement{}
+			return a.IsActiveAt(base.Add(time.Duration(nowOffset) * time.Minute))
+		},
+		gen.IntRange(-100000, 100000),
+	))
+
+	properties.TestingRun(t)
+}
+
+// **Feature: announcements, Property 2: Announcement Audience**
+// For any role, an announcement SHALL be visible when its audience is "all" or
+// matches the role, and hidden otherwise.
+
+func TestAnnouncementIsVisibleTo(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("audience filters by role", prop.ForAll(
+		func(audience AnnouncementAudience, role Role) bool {
+			a := &Announcement{Audience: audience}
+			expected := audience == AnnouncementAudienceAll ||
+				(audience == AnnouncementAudienceOwners && role == RoleOwner) ||
+				(audience == AnnouncementAudienceMembers && role == RoleMember)
+			return a.IsVisibleTo(role) == expected
+		},
+		gen.OneConstOf(AnnouncementAudienceAll, AnnouncementAudienceOwners, AnnouncementAudienceMembers),
+		gen.OneConstOf(RoleOwner, RoleMember),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestAnnouncementValidate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		a       Announcement
+		wantErr bool
+	}{
+		{"defaults applied", Announcement{Message: "Maintenance tonight"}, false},
+		{"empty message", Announcement{Message: "   "}, true},
+		{"message too long", Announcement{Message: strings.Repeat("a", MaxAnnouncementMessageLength+1)}, true},
+		{"invalid level", Announcement{Message: "hi", Level: "urgent"}, true},
+		{"invalid audience", Announcement{Message: "hi", Audience: "admins"}, true},
+		{"valid window", Announcement{Message: "hi", StartsAt: &start, EndsAt: &end}, false},
+		{"inverted window", Announcement{Message: "hi", StartsAt: &end, EndsAt: &start}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.a.Level == "" || tt.a.Audience == "") {
+				t.Errorf("expected defaults to be applied, got level=%q audience=%q", tt.a.Level, tt.a.Audience)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaxOpenAPISpecSize is the largest OpenAPI spec fetched for the API catalog.
+const MaxOpenAPISpecSize = 5 << 20
+
+// APIOperation is one operation of a service's OpenAPI spec.
+type APIOperation struct {
+	Method      string   `json:"method"` // Uppercase, e.g. "GET"
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+}
+
+// ServiceAPISpec is the OpenAPI spec a service publishes at its OpenAPIURL,
+// as last fetched by the control plane. A failed fetch keeps the spec of the
+// last successful one and reports why in Error.
+type ServiceAPISpec struct {
+	AppID       string         `json:"app_id"`
+	ServiceName string         `json:"service_name"`
+	URL         string         `json:"url"`
+	Title       string         `json:"title"`
+	Version     string         `json:"version"`
+	Description string         `json:"description,omitempty"`
+	Operations  []APIOperation `json:"operations"`
+	// Spec is the spec itself, converted to JSON if it was published as
+	// YAML. It is left out of catalog listings.
+	Spec  json.RawMessage `json:"spec,omitempty"`
+	Error string          `json:"error,omitempty"`
+
+	// FetchedAt is when the spec was last fetched successfully, CheckedAt
+	// when a fetch was last attempted.
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+	CheckedAt time.Time  `json:"checked_at"`
+}
+
+// CatalogEntry is a service's API in the catalog of an organization.
+type CatalogEntry struct {
+	ServiceAPISpec
+	AppName string `json:"app_name"`
+}
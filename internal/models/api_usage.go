@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// APIUsage counts the requests one client made to a deprecated route, or
+// with a deprecated API version, so that admins can tell who still depends
+// on them before they are sunset.
+type APIUsage struct {
+	// Route is the method and route pattern requested, e.g.
+	// "POST /v1/nodes/heartbeat"
+	Route string `json:"route"`
+	// Version is the API version the requests were served with
+	Version  string `json:"version"`
+	UserID   string `json:"user_id,omitempty"`
+	APIKeyID string `json:"api_key_id,omitempty"`
+	// Client is the Narvana-Client header of the requests, or their
+	// User-Agent, e.g. "narvanactl/1.8.0"
+	Client    string    `json:"client"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// APIUsageFilter selects recorded API usage.
+type APIUsageFilter struct {
+	// Route limits usage to a route, e.g. "POST /v1/nodes/heartbeat"
+	Route string
+	// Since limits usage to clients seen at or after it
+	Since time.Time
+	Limit int
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// APIKeyAction is an operation an API key scope grants on an app.
+type APIKeyAction string
+
+const (
+	// APIKeyActionRead allows reading the app, its services, deployments and logs.
+	APIKeyActionRead APIKeyAction = "read"
+	// APIKeyActionDeploy allows deploying, rolling back and starting or stopping services.
+	APIKeyActionDeploy APIKeyAction = "deploy"
+	// APIKeyActionWrite allows changing the app's configuration, services, secrets and domains.
+	APIKeyActionWrite APIKeyAction = "write"
+)
+
+// APIKeyAllApps is the scope app ID that matches every app the key's user can access.
+const APIKeyAllApps = "*"
+
+// Validation errors for API keys.
+var (
+	ErrAPIKeyNameRequired = errors.New("API key name is required")
+	ErrAPIKeyInvalidScope = errors.New("invalid API key scope")
+)
+
+// IsValid reports whether a is a known action.
+func (a APIKeyAction) IsValid() bool {
+	switch a {
+	case APIKeyActionRead, APIKeyActionDeploy, APIKeyActionWrite:
+		return true
+	}
+	return false
+}
+
+// APIKeyScope grants actions on a single app, or on all apps when AppID is APIKeyAllApps.
+type APIKeyScope struct {
+	AppID   string         `json:"app_id"`
+	Actions []APIKeyAction `json:"actions"`
+}
+
+// Validate checks that the scope names an app and only known actions.
+func (s APIKeyScope) Validate() error {
+	if s.AppID == "" {
+		return fmt.Errorf("%w: app_id is required", ErrAPIKeyInvalidScope)
+	}
+	if len(s.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required for app %s", ErrAPIKeyInvalidScope, s.AppID)
+	}
+	for _, a := range s.Actions {
+		if !a.IsValid() {
+			return fmt.Errorf("%w: unknown action %q (must be read, deploy or write)", ErrAPIKeyInvalidScope, a)
+		}
+	}
+	return nil
+}
+
+// ScopeName formats an app scope as "app:<appID>:<action>" for error messages.
+func ScopeName(appID string, action APIKeyAction) string {
+	return "app:" + appID + ":" + string(action)
+}
+
+// APIKey is a long-lived credential for the API. A key without scopes has the
+// full access of its user; a scoped key may only perform the listed actions on
+// the listed apps.
+type APIKey struct {
+	ID         string        `json:"id"`
+	UserID     string        `json:"user_id"`
+	KeyHash    string        `json:"-"` // SHA256 hash of the key
+	Prefix     string        `json:"prefix"`
+	Name       string        `json:"name"`
+	Scopes     []APIKeyScope `json:"scopes"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty"`
+}
+
+// AllowsApp reports whether scopes permit action on appID. Empty scopes allow everything.
+func AllowsApp(scopes []APIKeyScope, appID string, action APIKeyAction) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s.AppID != appID && s.AppID != APIKeyAllApps {
+			continue
+		}
+		for _, a := range s.Actions {
+			if a == action {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net/url"
+	"path"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 )
@@ -37,6 +41,9 @@ type HealthCheckConfig struct {
 	IntervalSeconds int    `json:"interval_seconds,omitempty"`
 	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"`
 	Retries         int    `json:"retries,omitempty"`
+	// Command is run in the service's container instead of an HTTP or TCP
+	// check; it passes when the command exits with status 0.
+	Command []string `json:"command,omitempty"`
 }
 
 // SourceType represents the type of source for a service.
@@ -49,6 +56,15 @@ const (
 	SourceTypeDatabase SourceType = "database" // Internal database (SQLite, etc.)
 )
 
+// ServiceRuntime is the driver a node uses to run a service's pure-nix
+// releases. OCI releases always run as containers.
+type ServiceRuntime string
+
+const (
+	ServiceRuntimeContainer ServiceRuntime = "container" // Podman container with the closure mounted (default)
+	ServiceRuntimeSystemd   ServiceRuntime = "systemd"   // Sandboxed systemd unit running the closure directly
+)
+
 // ValidationError represents a service configuration validation error.
 type ValidationError struct {
 	Field   string
@@ -63,6 +79,11 @@ func (e *ValidationError) Error() string {
 type ServiceConfig struct {
 	Name string `json:"name"`
 
+	// Type says how the service runs; empty is ServiceTypeService. Cron
+	// services run Cron.Command on a schedule instead of continuously.
+	Type ServiceType `json:"type,omitempty"`
+	Cron *CronConfig `json:"cron,omitempty"`
+
 	// Source configuration (exactly one must be set)
 	SourceType SourceType `json:"source_type"`
 
@@ -71,6 +92,7 @@ type ServiceConfig struct {
 	GitRepo     string `json:"git_repo,omitempty"`     // e.g., "github.com/company/web-ui"
 	GitRef      string `json:"git_ref,omitempty"`      // Branch/tag/commit (default: "main")
 	FlakeOutput string `json:"flake_output,omitempty"` // Output path (default: "packages.${system}.default")
+	BuildPath   string `json:"build_path,omitempty"`   // Directory to build, relative to the repository root (default: the root)
 
 	// Flake source (SourceTypeFlake)
 	// Complete flake URI used as-is by build worker
@@ -94,12 +116,51 @@ type ServiceConfig struct {
 	HealthCheck *HealthCheckConfig `json:"health_check,omitempty"`
 	EnvVars     map[string]string  `json:"env_vars,omitempty"` // Service-level env vars (override app-level)
 	DependsOn   []string           `json:"depends_on,omitempty"`
+	Egress      *EgressPolicy      `json:"egress,omitempty"` // Outbound network policy (default: allow all)
+
+	// NodePool selects the nodes the service runs on, such as a Kubernetes
+	// cluster's pool; empty runs it on the default pool of agent nodes
+	NodePool string `json:"node_pool,omitempty"`
+
+	// Runtime selects how pure-nix releases run on agent nodes; empty is
+	// ServiceRuntimeContainer
+	Runtime ServiceRuntime `json:"runtime,omitempty"`
+
+	// SmokeTests run against each new deployment once it reports running
+	SmokeTests []SmokeTest `json:"smoke_tests,omitempty"`
+
+	// Canary makes new deployments canaries, compared with the running
+	// deployment before they are promoted or rolled back
+	Canary *CanaryConfig `json:"canary,omitempty"`
+
+	// OpenAPIURL is where the service serves its OpenAPI spec, fetched after
+	// each deploy into the organization's API catalog
+	OpenAPIURL string `json:"openapi_url,omitempty"`
+
+	// Scheduled scaling: Replicas follows the schedule, or a manual override
+	// of it, and is kept current by the scaling cron
+	ScalingSchedule *ScalingSchedule `json:"scaling_schedule,omitempty"`
+	ReplicaOverride *ReplicaOverride `json:"replica_override,omitempty"`
+
+	// Autoscaling sizes Replicas to the service's usage, unless a manual
+	// override holds; a service has a scaling schedule or autoscaling, not both
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+
+	// Template is set on services instantiated from a service template
+	Template *ServiceTemplateRef `json:"template,omitempty"`
+
+	// Version of the stored service, for optimistic locking. It is set on
+	// services read from the store and is not part of the configuration.
+	Version int `json:"version,omitempty"`
 }
 
 // DatabaseConfig defines settings for internal database services.
 type DatabaseConfig struct {
 	Type    string `json:"type"`    // e.g., "sqlite", "postgres"
 	Version string `json:"version"` // e.g., "3", "16"
+	// Options overrides the engine's configuration options, e.g.
+	// "maxmemory" for Redis
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // DefaultResourceSpec returns the default resource specification.
@@ -171,6 +232,12 @@ func (s *ServiceConfig) Clone() ServiceConfig {
 	// Deep copy pointer fields
 	if s.Database != nil {
 		dbCopy := *s.Database
+		if s.Database.Options != nil {
+			dbCopy.Options = make(map[string]string, len(s.Database.Options))
+			for k, v := range s.Database.Options {
+				dbCopy.Options[k] = v
+			}
+		}
 		clone.Database = &dbCopy
 	}
 
@@ -186,6 +253,7 @@ func (s *ServiceConfig) Clone() ServiceConfig {
 
 	if s.HealthCheck != nil {
 		hcCopy := *s.HealthCheck
+		hcCopy.Command = append([]string(nil), s.HealthCheck.Command...)
 		clone.HealthCheck = &hcCopy
 	}
 
@@ -208,6 +276,52 @@ func (s *ServiceConfig) Clone() ServiceConfig {
 		}
 	}
 
+	if s.ScalingSchedule != nil {
+		schedule := *s.ScalingSchedule
+		schedule.Profiles = make([]ScalingProfile, len(s.ScalingSchedule.Profiles))
+		for i, p := range s.ScalingSchedule.Profiles {
+			p.Days = append([]string(nil), p.Days...)
+			schedule.Profiles[i] = p
+		}
+		clone.ScalingSchedule = &schedule
+	}
+
+	if s.ReplicaOverride != nil {
+		override := *s.ReplicaOverride
+		clone.ReplicaOverride = &override
+	}
+	if s.Autoscaling != nil {
+		autoscaling := *s.Autoscaling
+		clone.Autoscaling = &autoscaling
+	}
+	if s.Canary != nil {
+		canary := *s.Canary
+		clone.Canary = &canary
+	}
+
+	if s.Cron != nil {
+		cron := *s.Cron
+		cron.Command = append([]string(nil), s.Cron.Command...)
+		clone.Cron = &cron
+	}
+
+	if s.SmokeTests != nil {
+		clone.SmokeTests = make([]SmokeTest, len(s.SmokeTests))
+		for i, t := range s.SmokeTests {
+			t.Command = append([]string(nil), t.Command...)
+			clone.SmokeTests[i] = t
+		}
+	}
+
+	if s.Template != nil {
+		ref := *s.Template
+		ref.Params = make(map[string]string, len(s.Template.Params))
+		for k, v := range s.Template.Params {
+			ref.Params[k] = v
+		}
+		clone.Template = &ref
+	}
+
 	return clone
 }
 
@@ -238,7 +352,8 @@ func (s *ServiceConfig) Equals(other *ServiceConfig) bool {
 	if (s.Database == nil) != (other.Database == nil) {
 		return false
 	}
-	if s.Database != nil && (s.Database.Type != other.Database.Type || s.Database.Version != other.Database.Version) {
+	if s.Database != nil && (s.Database.Type != other.Database.Type || s.Database.Version != other.Database.Version ||
+		!maps.Equal(s.Database.Options, other.Database.Options)) {
 		return false
 	}
 
@@ -259,7 +374,8 @@ func (s *ServiceConfig) Equals(other *ServiceConfig) bool {
 			s.HealthCheck.Port != other.HealthCheck.Port ||
 			s.HealthCheck.IntervalSeconds != other.HealthCheck.IntervalSeconds ||
 			s.HealthCheck.TimeoutSeconds != other.HealthCheck.TimeoutSeconds ||
-			s.HealthCheck.Retries != other.HealthCheck.Retries {
+			s.HealthCheck.Retries != other.HealthCheck.Retries ||
+			!slices.Equal(s.HealthCheck.Command, other.HealthCheck.Command) {
 			return false
 		}
 	}
@@ -359,6 +475,11 @@ func (s *ServiceConfig) Validate() error {
 		if s.FlakeOutput == "" {
 			s.FlakeOutput = fmt.Sprintf("packages.%s.default", GetCurrentSystem())
 		}
+		buildPath, err := CleanBuildPath(s.BuildPath)
+		if err != nil {
+			return &ValidationError{Field: "build_path", Message: err.Error()}
+		}
+		s.BuildPath = buildPath
 	case SourceTypeFlake:
 		if err := validateFlakeURI(s.FlakeURI); err != nil {
 			return &ValidationError{Field: "flake_uri", Message: err.Error()}
@@ -376,9 +497,84 @@ func (s *ServiceConfig) Validate() error {
 		}
 	}
 
+	if s.BuildPath != "" && s.SourceType != SourceTypeGit {
+		return &ValidationError{Field: "build_path", Message: "build_path is only allowed for git sources"}
+	}
+
+	switch s.Type {
+	case "", ServiceTypeService:
+		if s.Cron != nil {
+			return &ValidationError{Field: "cron", Message: "cron is only allowed for services of type cron"}
+		}
+	case ServiceTypeCron:
+		if s.Cron == nil {
+			return &ValidationError{Field: "cron", Message: "cron configuration is required for cron services"}
+		}
+		if err := s.Cron.Validate(); err != nil {
+			return &ValidationError{Field: "cron", Message: err.Error()}
+		}
+	default:
+		return &ValidationError{Field: "type", Message: "type must be service or cron"}
+	}
+
+	if err := ValidateSmokeTests(s.SmokeTests); err != nil {
+		return &ValidationError{Field: "smoke_tests", Message: err.Error()}
+	}
+
+	if s.Canary != nil {
+		if s.IsCron() {
+			return &ValidationError{Field: "canary", Message: "canary deployments are not available for cron services"}
+		}
+		if err := s.Canary.Validate(); err != nil {
+			return &ValidationError{Field: "canary", Message: err.Error()}
+		}
+	}
+
+	if s.OpenAPIURL != "" {
+		if u, err := url.Parse(s.OpenAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{Field: "openapi_url", Message: "openapi_url must be an http:// or https:// URL"}
+		}
+	}
+
+	if s.NodePool != "" && !nodePoolPattern.MatchString(s.NodePool) {
+		return &ValidationError{Field: "node_pool", Message: "node pool must be lowercase letters, digits and dashes"}
+	}
+
+	switch s.Runtime {
+	case "", ServiceRuntimeContainer:
+	case ServiceRuntimeSystemd:
+		if !s.BuildsPureNix() {
+			return &ValidationError{Field: "runtime", Message: "the systemd runtime requires a service that builds a pure-nix closure"}
+		}
+	default:
+		return &ValidationError{Field: "runtime", Message: "runtime must be container or systemd"}
+	}
+
 	return nil
 }
 
+// nodePoolPattern matches node pool names.
+var nodePoolPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// BuildsPureNix reports whether the service's releases can be pure-nix
+// closures. Image sources and the Dockerfile and Nixpacks strategies always
+// produce OCI images.
+func (s *ServiceConfig) BuildsPureNix() bool {
+	if s.SourceType == SourceTypeImage {
+		return false
+	}
+	switch s.BuildStrategy {
+	case BuildStrategyDockerfile, BuildStrategyNixpacks:
+		return false
+	}
+	return true
+}
+
+// IsCron returns true if the service runs on a schedule rather than continuously.
+func (s *ServiceConfig) IsCron() bool {
+	return s.Type == ServiceTypeCron
+}
+
 // validateGitRepo validates a git repository URL.
 func validateGitRepo(repo string) error {
 	if repo == "" {
@@ -399,6 +595,33 @@ func validateGitRepo(repo string) error {
 	return errors.New("invalid git repository URL format")
 }
 
+// CleanBuildPath validates a service's build path and returns it in canonical
+// form: a slash-separated path relative to the repository root, or empty for
+// the root itself.
+func CleanBuildPath(buildPath string) (string, error) {
+	if buildPath == "" {
+		return "", nil
+	}
+	if !buildPathPattern.MatchString(buildPath) {
+		return "", errors.New("build_path may only contain letters, digits, '.', '_', '-' and '/'")
+	}
+	if path.IsAbs(buildPath) {
+		return "", errors.New("build_path must be relative to the repository root")
+	}
+	cleaned := path.Clean(buildPath)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.New("build_path must be within the repository")
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+// buildPathPattern matches the characters allowed in build paths, which are
+// passed to build scripts and flake references unquoted.
+var buildPathPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
 // validateFlakeURI validates a Nix flake URI.
 func validateFlakeURI(uri string) error {
 	if uri == "" {
@@ -496,8 +719,11 @@ type App struct {
 	Description string          `json:"description,omitempty"`
 	IconURL     string          `json:"icon_url,omitempty"`
 	Services    []ServiceConfig `json:"services"`
-	Version     int             `json:"version"` // Version for optimistic locking
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	DeletedAt   *time.Time      `json:"deleted_at,omitempty"`
+	// EnvVars are non-secret env vars inherited by every service. App secrets
+	// and service env vars with the same key take precedence.
+	EnvVars   map[string]string `json:"env_vars,omitempty"`
+	Version   int               `json:"version"` // Version for optimistic locking
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt *time.Time        `json:"deleted_at,omitempty"`
 }
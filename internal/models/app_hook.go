@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AppHookEvent is an app lifecycle event that can trigger a hook.
+type AppHookEvent string
+
+const (
+	// AppHookDeploySuccess fires when a deployment of one of the app's
+	// services starts running.
+	AppHookDeploySuccess AppHookEvent = "on_deploy_success"
+	// AppHookScale fires when a service's replica count changes, manually or
+	// through its scaling schedule.
+	AppHookScale AppHookEvent = "on_scale"
+	// AppHookSecretChange fires when one of the app's secrets is set or deleted.
+	AppHookSecretChange AppHookEvent = "on_secret_change"
+)
+
+// AppHookEvents lists the events hooks can subscribe to.
+var AppHookEvents = []AppHookEvent{
+	AppHookDeploySuccess,
+	AppHookScale,
+	AppHookSecretChange,
+}
+
+// IsValid reports whether e is an event hooks can subscribe to.
+func (e AppHookEvent) IsValid() bool {
+	for _, known := range AppHookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Validation errors for app hooks.
+var (
+	ErrAppHookName   = errors.New("hook name is required")
+	ErrAppHookEvent  = errors.New("unknown hook event")
+	ErrAppHookTarget = errors.New("hook needs exactly one of url or service_name")
+	ErrAppHookURL    = errors.New("hook url must be an absolute http or https URL")
+	ErrAppHookPath   = errors.New("hook path must start with /")
+)
+
+// AppHook calls a URL, or a service of the app, with a JSON payload when one
+// of the app's lifecycle events happens. Events lists the events it receives;
+// an empty list subscribes to all of them.
+type AppHook struct {
+	ID      string         `json:"id"`
+	AppID   string         `json:"app_id"`
+	Name    string         `json:"name"`
+	Enabled bool           `json:"enabled"`
+	Events  []AppHookEvent `json:"events"`
+
+	// URL receives the payload in a POST request.
+	URL string `json:"url,omitempty"`
+	// ServiceName designates a service of the app as the hook service. The
+	// payload is posted to Path on its primary port, on the node running it.
+	ServiceName string `json:"service_name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	// Secret signs payloads with HMAC-SHA256. It is never returned by the API.
+	Secret string `json:"secret,omitempty"`
+
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Validate checks the hook's name, target and event subscriptions.
+func (h *AppHook) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return ErrAppHookName
+	}
+	if (h.URL == "") == (h.ServiceName == "") {
+		return ErrAppHookTarget
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrAppHookURL
+		}
+	}
+	if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+		return ErrAppHookPath
+	}
+	for _, e := range h.Events {
+		if !e.IsValid() {
+			return fmt.Errorf("%w: %q", ErrAppHookEvent, e)
+		}
+	}
+	return nil
+}
+
+// Subscribes reports whether the hook receives events of type e.
+func (h *AppHook) Subscribes(e AppHookEvent) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, s := range h.Events {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+// AppHookPayload is the JSON body posted to a hook. Fields not relevant to
+// the event are omitted; secret values are never included.
+type AppHookPayload struct {
+	Event       AppHookEvent `json:"event"`
+	AppID       string       `json:"app_id"`
+	AppName     string       `json:"app_name,omitempty"`
+	ServiceName string       `json:"service_name,omitempty"`
+
+	// Set for on_deploy_success.
+	DeploymentID string `json:"deployment_id,omitempty"`
+	Version      int    `json:"version,omitempty"`
+	GitRef       string `json:"git_ref,omitempty"`
+	GitCommit    string `json:"git_commit,omitempty"`
+
+	// Set for on_scale.
+	Replicas         *int `json:"replicas,omitempty"`
+	PreviousReplicas *int `json:"previous_replicas,omitempty"`
+
+	// Set for on_secret_change: the secret's key and "set" or "deleted".
+	SecretKey string `json:"secret_key,omitempty"`
+	Action    string `json:"action,omitempty"`
+
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// AppHookDeliveryStatus is the state of a queued hook call.
+type AppHookDeliveryStatus string
+
+const (
+	AppHookDeliveryPending   AppHookDeliveryStatus = "pending"
+	AppHookDeliveryDelivered AppHookDeliveryStatus = "delivered"
+	AppHookDeliveryFailed    AppHookDeliveryStatus = "failed"
+)
+
+// AppHookDelivery is a queued call of one hook with one payload.
+type AppHookDelivery struct {
+	ID            string                `json:"id"`
+	HookID        string                `json:"hook_id"`
+	Payload       AppHookPayload        `json:"payload"`
+	Status        AppHookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	LastError     string                `json:"last_error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// AppSpecChange is a single difference between an app and an app spec.
+// Fields lists the changed settings of an updated service; From and To are
+// the services a domain routes to. Env var and secret values are not shown.
+type AppSpecChange struct {
+	Action string   `json:"action"` // add, update or remove
+	Kind   string   `json:"kind"`   // service, env, domain or secret
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"`
+	From   string   `json:"from,omitempty"`
+	To     string   `json:"to,omitempty"`
+}
+
+// AppManifest is the app spec last applied to an app: the baseline its live
+// state is compared against to detect changes made outside the spec.
+type AppManifest struct {
+	AppID string `json:"app_id"`
+	Spec  string `json:"spec"` // The YAML or JSON document as applied
+	Prune bool   `json:"prune"`
+	// Resources are the default resources services that declare none were
+	// given when the spec was applied
+	Resources *ResourceSpec `json:"resources,omitempty"`
+	// SecretDigests are SHA-256 digests of the app's stored secret values
+	// when the spec was applied, by key
+	SecretDigests map[string]string `json:"-"`
+	AppliedBy     string            `json:"applied_by,omitempty"`
+	AppliedAt     time.Time         `json:"applied_at"`
+	// Drift lists the changes made to the app since the spec was applied,
+	// as of DriftCheckedAt
+	Drift          []AppSpecChange `json:"drift"`
+	DriftCheckedAt *time.Time      `json:"drift_checked_at,omitempty"`
+}
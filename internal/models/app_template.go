@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// AppVariable is the app template variable set to the new app's name.
+const AppVariable = "app"
+
+// appTemplateNamePattern matches app template names.
+var appTemplateNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// appTemplateSecretKeyPattern matches the keys of an app template's secrets.
+var appTemplateSecretKeyPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// AppTemplateSecret is a secret set on apps created from a template.
+type AppTemplateSecret struct {
+	Key         string `json:"key"`
+	Description string `json:"description,omitempty"`
+	// Value may reference variables as ${name}. An empty value is generated:
+	// 32 random bytes, hex encoded.
+	Value string `json:"value,omitempty"`
+}
+
+// AppTemplate is a starter for new apps: an app spec (narvana.yaml) and the
+// secrets its services expect, instantiated in one call. String values of
+// the spec and secret values may reference variables as ${name}; ${app} is
+// the new app's name. Builtin templates are bundled with the control plane;
+// others are added by instance admins.
+type AppTemplate struct {
+	Name        string                    `json:"name"`
+	Title       string                    `json:"title"`
+	Description string                    `json:"description,omitempty"`
+	Variables   []ServiceTemplateVariable `json:"variables"`
+	Secrets     []AppTemplateSecret       `json:"secrets"`
+	Spec        string                    `json:"spec"`
+	Builtin     bool                      `json:"builtin"`
+	CreatedBy   string                    `json:"created_by,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// Validate checks the template's name, variables and secret keys, and that
+// its spec and secrets only reference declared variables. The spec itself
+// is checked when rendered.
+func (t *AppTemplate) Validate() error {
+	if !appTemplateNamePattern.MatchString(t.Name) {
+		return &ValidationError{Field: "name", Message: "template name must be 1-63 lowercase letters, digits and hyphens, starting and ending with a letter or digit"}
+	}
+	if t.Title == "" {
+		return &ValidationError{Field: "title", Message: "template title is required"}
+	}
+	if t.Spec == "" {
+		return &ValidationError{Field: "spec", Message: "template spec is required"}
+	}
+
+	declared, err := declareTemplateVariables(t.Variables, AppVariable)
+	if err != nil {
+		return err
+	}
+	if err := checkTemplateReferences("spec", t.Spec, declared); err != nil {
+		return err
+	}
+
+	keys := make(map[string]bool, len(t.Secrets))
+	for _, secret := range t.Secrets {
+		if !appTemplateSecretKeyPattern.MatchString(secret.Key) {
+			return &ValidationError{Field: "secrets", Message: fmt.Sprintf("invalid secret key %q: use uppercase letters, digits and underscores", secret.Key)}
+		}
+		if keys[secret.Key] {
+			return &ValidationError{Field: "secrets", Message: fmt.Sprintf("secret %q is declared more than once", secret.Key)}
+		}
+		keys[secret.Key] = true
+		if err := checkTemplateReferences("secrets."+secret.Key, secret.Value, declared); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Values returns the value of each of the template's variables for an app
+// named app. Params set variables; unset variables take their default.
+func (t *AppTemplate) Values(app string, params map[string]string) (map[string]string, error) {
+	return resolveTemplateParams(t.Variables, params, AppVariable, app)
+}
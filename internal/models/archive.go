@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// ArchiveStatus is the state of an archived deployment.
+type ArchiveStatus string
+
+const (
+	// ArchiveStatusArchived means the deployment's history is in object storage.
+	ArchiveStatusArchived ArchiveStatus = "archived"
+	// ArchiveStatusRestoring means the history is being copied back.
+	ArchiveStatusRestoring ArchiveStatus = "restoring"
+	// ArchiveStatusRestoreFailed means a restore failed and will be retried.
+	ArchiveStatusRestoreFailed ArchiveStatus = "restore_failed"
+)
+
+// Archive records a deployment whose build output and logs were moved to
+// object storage. The deployment and build rows stay in the database as
+// stubs; the archive is restored when its history is read again.
+type Archive struct {
+	ID           string `json:"id"`
+	DeploymentID string `json:"deployment_id"`
+	BuildID      string `json:"build_id,omitempty"`
+	AppID        string `json:"app_id"`
+	ObjectKey    string `json:"object_key"`
+	// SizeBytes is the compressed size in object storage, OriginalBytes
+	// the size of the uncompressed history
+	SizeBytes     int64         `json:"size_bytes"`
+	OriginalBytes int64         `json:"original_bytes"`
+	Status        ArchiveStatus `json:"status"`
+	Error         string        `json:"error,omitempty"`
+	ArchivedAt    time.Time     `json:"archived_at"`
+	// RestoreRequestedAt is when the history was first read after archiving
+	RestoreRequestedAt *time.Time `json:"restore_requested_at,omitempty"`
+}
+
+// ArchiveStats summarizes the archive tier.
+type ArchiveStats struct {
+	Archives      int   `json:"archives"`
+	SizeBytes     int64 `json:"size_bytes"`
+	OriginalBytes int64 `json:"original_bytes"`
+}
+
+// ArchiveBundle is the history of a deployment as stored in object storage.
+type ArchiveBundle struct {
+	Deployment *Deployment      `json:"deployment"`
+	Build      *BuildJob        `json:"build,omitempty"`
+	BuildLogs  []*BuildLogChunk `json:"build_logs,omitempty"`
+	Logs       []*LogEntry      `json:"logs,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// AuditEntry records one mutating API request: who made it, which resource
+// it targeted, how it ended and, for tracked resources, what it changed.
+type AuditEntry struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	UserEmail string `json:"user_email,omitempty"`
+	APIKeyID  string `json:"api_key_id,omitempty"` // Set when the request was made with an API key
+	// Action is the method and route of the request, e.g.
+	// "PATCH /v1/apps/{appID}/services/{serviceName}", or for entries of
+	// status transitions their kind, e.g. "deployment.status".
+	Action       string        `json:"action"`
+	ResourceType string        `json:"resource_type"`
+	ResourceID   string        `json:"resource_id,omitempty"`
+	AppID        string        `json:"app_id,omitempty"`
+	Path         string        `json:"path"`
+	Status       int           `json:"status"`
+	Changes      []AuditChange `json:"changes,omitempty"`
+	RequestID    string        `json:"request_id,omitempty"`
+	RemoteAddr   string        `json:"remote_addr,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// AuditChange is one field a request changed. Old is nil for added fields
+// and New is nil for removed ones. Sensitive values are redacted.
+type AuditChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	UserID       string
+	APIKeyID     string
+	ResourceType string
+	ResourceID   string
+	AppID        string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
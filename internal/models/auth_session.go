@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AuthSession is a user's sign-in on one device. The device holds a refresh
+// token, rotated on every use, that exchanges for short-lived access tokens
+// naming the session; revoking the session stops both from working.
+type AuthSession struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	RefreshTokenHash string     `json:"-"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastUsedAt       time.Time  `json:"last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active returns true if the session is neither revoked nor expired at now.
+func (s *AuthSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -13,16 +13,19 @@ const (
 	BuildStatusRunning   BuildStatus = "running"
 	BuildStatusSucceeded BuildStatus = "succeeded"
 	BuildStatusFailed    BuildStatus = "failed"
+	BuildStatusCanceled  BuildStatus = "canceled"
 )
 
 // ValidStatusTransitions defines the allowed state transitions for build jobs.
 // The state machine is: queued → running → (succeeded | failed)
-// with running → queued only allowed for retry operations.
+// with running → queued only allowed for retry operations. Queued and
+// running builds may also be canceled.
 var ValidStatusTransitions = map[BuildStatus][]BuildStatus{
-	BuildStatusQueued:    {BuildStatusRunning},
-	BuildStatusRunning:   {BuildStatusSucceeded, BuildStatusFailed, BuildStatusQueued}, // Queued only for retry
-	BuildStatusSucceeded: {},                                                           // Terminal state
-	BuildStatusFailed:    {},                                                           // Terminal state
+	BuildStatusQueued:    {BuildStatusRunning, BuildStatusCanceled},
+	BuildStatusRunning:   {BuildStatusSucceeded, BuildStatusFailed, BuildStatusQueued, BuildStatusCanceled}, // Queued only for retry
+	BuildStatusSucceeded: {},                                                                                // Terminal state
+	BuildStatusFailed:    {},                                                                                // Terminal state
+	BuildStatusCanceled:  {},                                                                                // Terminal state
 }
 
 // CanTransition checks if a state transition is valid.
@@ -42,10 +45,43 @@ func CanTransition(from, to BuildStatus, isRetry bool) bool {
 	return false
 }
 
-// IsTerminalState returns true if the status is a terminal state (succeeded or failed).
-// Terminal states do not allow any further transitions.
+// IsTerminalState returns true if the status is a terminal state (succeeded,
+// failed or canceled). Terminal states do not allow any further transitions.
 func IsTerminalState(status BuildStatus) bool {
-	return status == BuildStatusSucceeded || status == BuildStatusFailed
+	return status == BuildStatusSucceeded || status == BuildStatusFailed || status == BuildStatusCanceled
+}
+
+// BuildPriority decides the order queued builds are claimed in: builds
+// people start go ahead of builds started by webhooks, which go ahead of
+// automatic retries. Builds of the same priority run oldest first.
+type BuildPriority string
+
+const (
+	BuildPriorityUser    BuildPriority = "user"
+	BuildPriorityWebhook BuildPriority = "webhook"
+	BuildPriorityRetry   BuildPriority = "retry"
+)
+
+// IsValid reports whether the priority is a known priority.
+func (p BuildPriority) IsValid() bool {
+	switch p {
+	case BuildPriorityUser, BuildPriorityWebhook, BuildPriorityRetry:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities; builds of a higher rank are claimed first. Builds
+// queued without a priority rank with webhook builds.
+func (p BuildPriority) Rank() int {
+	switch p {
+	case BuildPriorityUser:
+		return 2
+	case BuildPriorityRetry:
+		return 0
+	default:
+		return 1
+	}
 }
 
 // BuildStrategy represents the method used to build an application.
@@ -138,8 +174,9 @@ type FastAPIOptions struct {
 
 // DatabaseOptions contains database-specific build options.
 type DatabaseOptions struct {
-	Type    string `json:"type,omitempty"`
-	Version string `json:"version,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	Version string            `json:"version,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // BuildConfig contains strategy-specific configuration options.
@@ -149,6 +186,8 @@ type BuildConfig struct {
 	StartCommand string `json:"start_command,omitempty"`
 	EntryPoint   string `json:"entry_point,omitempty"`
 	BuildTimeout int    `json:"build_timeout,omitempty"` // seconds, default 1800
+	BuildCPU     string `json:"build_cpu,omitempty"`     // cores for the build container, e.g. "4"
+	BuildMemory  string `json:"build_memory,omitempty"`  // memory for the build container, e.g. "8Gi"
 
 	// Go-specific
 	GoVersion  string `json:"go_version,omitempty"`
@@ -178,6 +217,11 @@ type BuildConfig struct {
 	// Python-specific
 	PythonVersion string `json:"python_version,omitempty"`
 
+	// Dockerfile-specific
+	DockerfilePath string            `json:"dockerfile_path,omitempty"` // Relative to the build path (default "Dockerfile")
+	BuildArgs      map[string]string `json:"build_args,omitempty"`      // Passed to the build as --build-arg
+	Target         string            `json:"target,omitempty"`          // Stage of a multi-stage Dockerfile to build
+
 	// Framework-specific options
 	NextJSOptions   *NextJSOptions   `json:"nextjs_options,omitempty"`
 	DjangoOptions   *DjangoOptions   `json:"django_options,omitempty"`
@@ -190,6 +234,97 @@ type BuildConfig struct {
 
 	// Fallback behavior
 	AutoRetryAsOCI bool `json:"auto_retry_as_oci,omitempty"`
+
+	// VerifyReproducibility rebuilds pure-nix outputs a second time and
+	// compares them, recording the result on the build.
+	VerifyReproducibility bool `json:"verify_reproducibility,omitempty"`
+
+	// DebugSnapshot keeps the working directory of failed builds on the
+	// worker for a limited time, so the failure can be reproduced in a shell
+	// with the build's environment.
+	DebugSnapshot bool `json:"debug_snapshot,omitempty"`
+}
+
+// ReproducibilityStatus is the outcome of double-build verification.
+type ReproducibilityStatus string
+
+const (
+	// ReproducibilityVerified means the rebuild produced identical output.
+	ReproducibilityVerified ReproducibilityStatus = "verified"
+	// ReproducibilityMismatch means the rebuild produced different output.
+	ReproducibilityMismatch ReproducibilityStatus = "mismatch"
+	// ReproducibilityError means verification could not be completed.
+	ReproducibilityError ReproducibilityStatus = "error"
+)
+
+// BuildCacheStats is the binary cache usage of a build. Hits are store paths
+// substituted from a binary cache; misses are derivations built locally.
+type BuildCacheStats struct {
+	Cache  string `json:"cache"` // Attic cache the build pulled from and pushed to
+	Hits   int    `json:"hits"`
+	Misses int    `json:"misses"`
+}
+
+// HitRate returns the fraction of the build's store paths that came from a
+// binary cache, or 0 if the build needed nothing.
+func (s *BuildCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// BuildFailureCategory is a heuristic classification of a build failure.
+type BuildFailureCategory string
+
+const (
+	BuildFailureDependencyFetch BuildFailureCategory = "dependency_fetch"
+	BuildFailureNetwork         BuildFailureCategory = "network"
+	BuildFailureInfrastructure  BuildFailureCategory = "infrastructure"
+	BuildFailureDiskFull        BuildFailureCategory = "disk_full"
+	BuildFailureCompile         BuildFailureCategory = "compile_error"
+	BuildFailureOutOfMemory     BuildFailureCategory = "out_of_memory"
+	BuildFailureTimeout         BuildFailureCategory = "timeout"
+	BuildFailureInvalidConfig   BuildFailureCategory = "invalid_config"
+	BuildFailureUnknown         BuildFailureCategory = "unknown"
+)
+
+// IsTransient reports whether failures of the category are usually not
+// caused by the build's inputs, so building the same inputs again is likely
+// to succeed.
+func (c BuildFailureCategory) IsTransient() bool {
+	return c == BuildFailureNetwork || c == BuildFailureInfrastructure
+}
+
+// BuildFailure explains why a build failed without opening its logs: the
+// likely cause, the last meaningful log lines and suggested fixes. Retryable
+// failures are retried automatically while the build has retries left.
+type BuildFailure struct {
+	Category    BuildFailureCategory `json:"category"`
+	Summary     string               `json:"summary"`
+	Excerpt     []string             `json:"excerpt,omitempty"`
+	Suggestions []string             `json:"suggestions,omitempty"`
+	Retryable   bool                 `json:"retryable,omitempty"`
+}
+
+// BuildFailureClassStats counts a service's failed builds of one category.
+type BuildFailureClassStats struct {
+	Category   BuildFailureCategory `json:"category"`
+	Count      int                  `json:"count"`
+	Retryable  bool                 `json:"retryable"`
+	LastSeenAt time.Time            `json:"last_seen_at"`
+}
+
+// BuildFailureStats summarizes how a service's builds failed since a time:
+// how many builds ran, failed and needed retries, and the failures by
+// category, most frequent first.
+type BuildFailureStats struct {
+	ServiceName string                    `json:"service_name"`
+	Since       time.Time                 `json:"since"`
+	Builds      int                       `json:"builds"`
+	Failed      int                       `json:"failed"`
+	Retried     int                       `json:"retried"`
+	Classes     []*BuildFailureClassStats `json:"classes"`
 }
 
 // BuildJob represents a build task in the queue.
@@ -210,12 +345,24 @@ type BuildJob struct {
 	FlakeURI    string     `json:"flake_uri,omitempty" db:"flake_uri"` // Constructed or direct flake URI
 	FlakeOutput string     `json:"flake_output"`
 
+	// BuildPath is the directory of the repository the service is built
+	// from, relative to its root; empty builds the root
+	BuildPath string `json:"build_path,omitempty" db:"build_path"`
+
 	BuildType  BuildType   `json:"build_type"`
 	Status     BuildStatus `json:"status"`
 	CreatedAt  time.Time   `json:"created_at"`
 	StartedAt  *time.Time  `json:"started_at,omitempty"`
 	FinishedAt *time.Time  `json:"finished_at,omitempty"`
 
+	// Priority decides when the build is claimed from the queue;
+	// TriggeredBy is the user who started it, whose concurrent builds may
+	// be capped. QueuePosition is the build's 1-based place in the queue,
+	// set when a queued build is read through the API.
+	Priority      BuildPriority `json:"priority,omitempty" db:"priority"`
+	TriggeredBy   string        `json:"triggered_by,omitempty" db:"triggered_by"`
+	QueuePosition int           `json:"queue_position,omitempty" db:"-"`
+
 	// Build strategy fields
 	BuildStrategy  BuildStrategy `json:"build_strategy,omitempty" db:"build_strategy"`
 	BuildConfig    *BuildConfig  `json:"build_config,omitempty" db:"build_config"`
@@ -233,10 +380,44 @@ type BuildJob struct {
 	DetectionResult *DetectionResult `json:"detection_result,omitempty" db:"detection_result"`
 	DetectedAt      *time.Time       `json:"detected_at,omitempty" db:"detected_at"`
 
+	// Content-addressed deduplication: builds with the same ContentHash reuse
+	// the Artifact of an earlier successful build instead of rebuilding.
+	ContentHash      string `json:"content_hash,omitempty" db:"content_hash"`
+	Artifact         string `json:"artifact,omitempty" db:"artifact"`
+	DeduplicatedFrom string `json:"deduplicated_from,omitempty" db:"deduplicated_from"`
+
+	// Reproducibility is set when the build was verified by rebuilding it;
+	// OutputHash is the NAR hash of the output that was checked.
+	Reproducibility ReproducibilityStatus `json:"reproducibility,omitempty" db:"reproducibility"`
+	OutputHash      string                `json:"output_hash,omitempty" db:"output_hash"`
+
+	// CacheStats records how much of a pure-nix build was pulled from the
+	// binary cache rather than built.
+	CacheStats *BuildCacheStats `json:"cache_stats,omitempty" db:"cache_stats"`
+
+	// Failure is set when the build failed, with its likely cause.
+	Failure *BuildFailure `json:"failure,omitempty" db:"failure"`
+
+	// CancelRequestedAt is set when someone asked to cancel the build while
+	// it was running; the worker running it stops it and marks it canceled.
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty" db:"cancel_requested_at"`
+
+	// ExternalMetadata is set for builds produced by an external CI system and
+	// handed to Narvana for deployment only, e.g. the CI provider and run URL.
+	ExternalMetadata map[string]string `json:"external_metadata,omitempty" db:"external_metadata"`
+
 	// PreClonedRepoPath is the path to a pre-cloned repository from the pre-build phase.
 	// When set, the build container will mount this path instead of cloning the repository.
 	// **Validates: Requirements 4.2**
 	PreClonedRepoPath string `json:"pre_cloned_repo_path,omitempty" db:"-"`
+
+	// Snapshot is set by the builder when it kept the working directory of a
+	// failed build for debugging (see BuildConfig.DebugSnapshot).
+	Snapshot *BuildSnapshot `json:"-" db:"-"`
+
+	// TraceContext carries the trace of the request that queued the build
+	// to the worker running it; it is only kept in the queue.
+	TraceContext map[string]string `json:"trace_context,omitempty" db:"-"`
 }
 
 // ValidateBuildJobSource validates that the BuildJob source fields are consistent.
@@ -426,3 +607,17 @@ func GetExpectedArtifactType(buildType BuildType) ArtifactType {
 		return ArtifactTypeUnknown
 	}
 }
+
+// BuildTypeForArtifact infers the build type from an artifact produced
+// outside Narvana: a Nix store path deploys as pure-nix and an image
+// reference as OCI. It returns false if the artifact is neither.
+func BuildTypeForArtifact(artifact string) (BuildType, bool) {
+	switch {
+	case IsNixStorePath(artifact):
+		return BuildTypePureNix, true
+	case IsOCIImageTag(artifact):
+		return BuildTypeOCI, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BuildAttestation is the signed SLSA provenance of a successful build: a
+// DSSE envelope holding an in-toto statement, and the public key of the
+// worker that signed it.
+type BuildAttestation struct {
+	BuildID  string `json:"build_id"`
+	WorkerID string `json:"worker_id,omitempty"`
+	KeyID    string `json:"key_id"`
+	// PublicKey is the base64-encoded ed25519 public key of the signing worker.
+	PublicKey string          `json:"public_key"`
+	Envelope  json.RawMessage `json:"envelope"`
+	CreatedAt time.Time       `json:"created_at"`
+}
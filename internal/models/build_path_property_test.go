@@ -0,0 +1,74 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: monorepo-services, Property 1: Build Paths Stay In The Repository**
+// For any path made of segments, a build path accepted by CleanBuildPath
+// SHALL be canonical and name a directory within the repository.
+
+func TestCleanBuildPathStaysInRepository(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 200
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	segment := gen.OneConstOf("apps", "web", "api", ".", "..", "", "services")
+
+	properties.Property("accepted build paths are canonical and relative", prop.ForAll(
+		func(segments []string) bool {
+			cleaned, err := CleanBuildPath(strings.Join(segments, "/"))
+			if err != nil {
+				return true
+			}
+			if cleaned == "" {
+				return true
+			}
+			return cleaned == path.Clean(cleaned) && !path.IsAbs(cleaned) &&
+				cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+		},
+		gen.SliceOf(segment),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestCleanBuildPath(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{"", "", true},
+		{".", "", true},
+		{"frontend", "frontend", true},
+		{"./services/api/", "services/api", true},
+		{"services/../api", "api", true},
+		{"..", "", false},
+		{"services/../../etc", "", false},
+		{"/etc", "", false},
+		{"apps/$(id)", "", false},
+		{`apps\web`, "", false},
+	} {
+		got, err := CleanBuildPath(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("CleanBuildPath(%q) = %q, %v", tc.in, got, err)
+		}
+	}
+
+	service := ServiceConfig{Name: "web", FlakeURI: "github:owner/repo", BuildPath: "web"}
+	if err := service.Validate(); err == nil {
+		t.Error("Validate() accepted a build path for a flake source")
+	}
+	service = ServiceConfig{Name: "web", GitRepo: "github.com/owner/repo", BuildPath: "./apps/web/"}
+	if err := service.Validate(); err != nil || service.BuildPath != "apps/web" {
+		t.Errorf("Validate() = %v, build path %q", err, service.BuildPath)
+	}
+}
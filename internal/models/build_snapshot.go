@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// BuildSnapshot is the preserved environment of a failed build: its working
+// directory, kept on the worker host that ran it, and the container image
+// and environment the build ran with. Starting a container from the snapshot
+// gives a shell in the same environment as the failed build.
+type BuildSnapshot struct {
+	BuildID  string `json:"build_id"`
+	WorkerID string `json:"worker_id,omitempty"`
+	// Hostname is the worker host the working directory is kept on.
+	Hostname string `json:"hostname"`
+	// Path is the working directory on the worker host, mounted at WorkDir.
+	Path     string            `json:"path"`
+	Image    string            `json:"image"`
+	Shell    string            `json:"shell,omitempty"`
+	WorkDir  string            `json:"work_dir"`
+	FlakeRef string            `json:"flake_ref,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired returns true if the snapshot may no longer be used at the given time.
+func (s *BuildSnapshot) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
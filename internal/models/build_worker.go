@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// BuildWorkerState describes whether a build worker is still processing jobs.
+type BuildWorkerState string
+
+const (
+	// BuildWorkerStateActive means the worker heartbeat is current.
+	BuildWorkerStateActive BuildWorkerState = "active"
+	// BuildWorkerStateUnresponsive means the worker missed heartbeats long
+	// enough for its job leases to expire; its jobs are requeued.
+	BuildWorkerStateUnresponsive BuildWorkerState = "unresponsive"
+	// BuildWorkerStateStopped means the worker shut down cleanly.
+	BuildWorkerStateStopped BuildWorkerState = "stopped"
+)
+
+// BuildWorker is a running build worker process registered in the database.
+type BuildWorker struct {
+	ID              string           `json:"id"`
+	Hostname        string           `json:"hostname"`
+	Version         string           `json:"version"`
+	Concurrency     int              `json:"concurrency"`
+	ActiveJobs      int              `json:"active_jobs"`
+	LeaseSeconds    int              `json:"lease_seconds"`          // how long claimed jobs survive without a heartbeat
+	PodmanError     string           `json:"podman_error,omitempty"` // why the worker cannot reach Podman; empty when it can
+	ClaimedJobs     []string         `json:"claimed_jobs"`           // IDs of the queue jobs the worker holds
+	State           BuildWorkerState `json:"state"`
+	StartedAt       time.Time        `json:"started_at"`
+	LastHeartbeatAt time.Time        `json:"last_heartbeat_at"`
+	StoppedAt       *time.Time       `json:"stopped_at,omitempty"`
+}
+
+// StateAt returns the worker's state at now. A worker whose last heartbeat
+// is older than its lease is unresponsive.
+func (w *BuildWorker) StateAt(now time.Time) BuildWorkerState {
+	if w.StoppedAt != nil {
+		return BuildWorkerStateStopped
+	}
+	if now.Sub(w.LastHeartbeatAt) > time.Duration(w.LeaseSeconds)*time.Second {
+		return BuildWorkerStateUnresponsive
+	}
+	return BuildWorkerStateActive
+}
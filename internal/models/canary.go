@@ -0,0 +1,155 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Canary defaults, used for zero fields of a CanaryConfig.
+const (
+	DefaultCanaryWeight                    = 10
+	DefaultCanaryWindow                    = 5 * time.Minute
+	DefaultCanaryMaxErrorRateIncrease      = 1.0
+	DefaultCanaryMaxLatencyIncreasePercent = 20.0
+	DefaultCanaryMinRequests               = 100
+)
+
+// CanaryConfig makes each new deployment of a service a canary: it serves
+// Weight percent of the service's requests next to the running deployment,
+// its baseline, for the evaluation window. The canary is then promoted, and
+// the baseline stopped, unless its error rate or latency exceeded the
+// baseline's by more than the thresholds, in which case it is rolled back.
+type CanaryConfig struct {
+	// Weight is the percentage of requests sent to the canary
+	Weight int `json:"weight,omitempty"`
+	// WindowSeconds is how long both deployments are compared
+	WindowSeconds int `json:"window_seconds,omitempty"`
+	// MaxErrorRateIncrease is how many percentage points the canary's 5xx
+	// rate may exceed the baseline's, e.g. 1 allows 1.5% against 0.5%
+	MaxErrorRateIncrease float64 `json:"max_error_rate_increase,omitempty"`
+	// MaxLatencyIncreasePercent is how much slower, in percent, the canary's
+	// mean response time may be than the baseline's
+	MaxLatencyIncreasePercent float64 `json:"max_latency_increase_percent,omitempty"`
+	// MinRequests is how many requests each side must serve in the window
+	// for a verdict; with fewer the analysis is inconclusive
+	MinRequests int64 `json:"min_requests,omitempty"`
+}
+
+// Validate checks the weight, window and thresholds. Zero fields take
+// their defaults.
+func (c *CanaryConfig) Validate() error {
+	if c.Weight < 0 || c.Weight > 50 {
+		return errors.New("weight must be between 1 and 50")
+	}
+	if c.WindowSeconds != 0 && (c.WindowSeconds < 60 || c.WindowSeconds > 86400) {
+		return errors.New("window_seconds must be between 60 and 86400")
+	}
+	if c.MaxErrorRateIncrease < 0 || c.MaxErrorRateIncrease > 100 {
+		return errors.New("max_error_rate_increase must be between 0 and 100")
+	}
+	if c.MaxLatencyIncreasePercent < 0 {
+		return errors.New("max_latency_increase_percent must be positive")
+	}
+	if c.MinRequests < 0 {
+		return errors.New("min_requests must be positive")
+	}
+	return nil
+}
+
+// WithDefaults returns the config with zero fields set to their defaults.
+func (c CanaryConfig) WithDefaults() CanaryConfig {
+	if c.Weight == 0 {
+		c.Weight = DefaultCanaryWeight
+	}
+	if c.WindowSeconds == 0 {
+		c.WindowSeconds = int(DefaultCanaryWindow / time.Second)
+	}
+	if c.MaxErrorRateIncrease == 0 {
+		c.MaxErrorRateIncrease = DefaultCanaryMaxErrorRateIncrease
+	}
+	if c.MaxLatencyIncreasePercent == 0 {
+		c.MaxLatencyIncreasePercent = DefaultCanaryMaxLatencyIncreasePercent
+	}
+	if c.MinRequests == 0 {
+		c.MinRequests = DefaultCanaryMinRequests
+	}
+	return c
+}
+
+// Window returns the evaluation window.
+func (c CanaryConfig) Window() time.Duration {
+	return time.Duration(c.WithDefaults().WindowSeconds) * time.Second
+}
+
+// CanaryStatus is the state of a canary analysis; every status but pending
+// is a verdict.
+type CanaryStatus string
+
+const (
+	CanaryPending      CanaryStatus = "pending"      // Waiting for the canary to run through the window
+	CanaryPassed       CanaryStatus = "passed"       // Within the thresholds; the canary was promoted
+	CanaryFailed       CanaryStatus = "failed"       // Exceeded a threshold; the canary was rolled back
+	CanaryInconclusive CanaryStatus = "inconclusive" // Too little traffic or the baseline stopped; the canary was promoted
+)
+
+// CanaryMetrics is what one side of a canary analysis served over the
+// evaluation window.
+type CanaryMetrics struct {
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`      // Percentage of requests that were 5xx responses
+	LatencyMs float64 `json:"mean_latency_ms"` // Mean response time
+}
+
+// NewCanaryMetrics computes the error rate and mean latency of requests.
+func NewCanaryMetrics(requests, errors, latencyMsSum int64) CanaryMetrics {
+	m := CanaryMetrics{Requests: requests, Errors: errors}
+	if requests > 0 {
+		m.ErrorRate = float64(errors) * 100 / float64(requests)
+		m.LatencyMs = float64(latencyMsSum) / float64(requests)
+	}
+	return m
+}
+
+// CanaryAnalysis compares a canary deployment with its baseline and records
+// the verdict on the canary.
+type CanaryAnalysis struct {
+	Status     CanaryStatus   `json:"status"`
+	BaselineID string         `json:"baseline_id"`
+	Config     CanaryConfig   `json:"config"` // The service's canary config with defaults, as of the deployment
+	Canary     *CanaryMetrics `json:"canary,omitempty"`
+	Baseline   *CanaryMetrics `json:"baseline,omitempty"`
+	Reasons    []string       `json:"reasons,omitempty"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"` // When the canary started running
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Evaluate compares the canary's metrics with the baseline's against the
+// thresholds and returns the verdict with the reasons for it.
+func (c CanaryConfig) Evaluate(canary, baseline CanaryMetrics) (CanaryStatus, []string) {
+	c = c.WithDefaults()
+	if canary.Requests < c.MinRequests || baseline.Requests < c.MinRequests {
+		return CanaryInconclusive, []string{fmt.Sprintf(
+			"too little traffic: canary served %d and baseline %d requests, %d each are needed",
+			canary.Requests, baseline.Requests, c.MinRequests)}
+	}
+
+	var reasons []string
+	if increase := canary.ErrorRate - baseline.ErrorRate; increase > c.MaxErrorRateIncrease {
+		reasons = append(reasons, fmt.Sprintf(
+			"error rate %.2f%% is %.2f points above the baseline's %.2f%%, more than the %.2f allowed",
+			canary.ErrorRate, increase, baseline.ErrorRate, c.MaxErrorRateIncrease))
+	}
+	if baseline.LatencyMs > 0 {
+		if increase := (canary.LatencyMs - baseline.LatencyMs) * 100 / baseline.LatencyMs; increase > c.MaxLatencyIncreasePercent {
+			reasons = append(reasons, fmt.Sprintf(
+				"mean latency %.0fms is %.0f%% above the baseline's %.0fms, more than the %.0f%% allowed",
+				canary.LatencyMs, increase, baseline.LatencyMs, c.MaxLatencyIncreasePercent))
+		}
+	}
+	if len(reasons) > 0 {
+		return CanaryFailed, reasons
+	}
+	return CanaryPassed, nil
+}
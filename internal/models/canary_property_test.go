@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: canary-analysis, Property 1: Identical Sides Pass**
+// For any traffic of at least min_requests, a canary that served the same
+// error rate and latency as its baseline SHALL pass.
+
+func TestCanaryEvaluateIdenticalSidesPass(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("identical metrics pass", prop.ForAll(
+		func(requests, errors, latency int64) bool {
+			m := NewCanaryMetrics(requests, min(errors, requests), latency*requests)
+			status, reasons := CanaryConfig{}.Evaluate(m, m)
+			return status == CanaryPassed && len(reasons) == 0
+		},
+		gen.Int64Range(DefaultCanaryMinRequests, 1_000_000),
+		gen.Int64Range(0, 1_000_000),
+		gen.Int64Range(1, 5000),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestCanaryEvaluate(t *testing.T) {
+	baseline := NewCanaryMetrics(1000, 5, 100_000) // 0.5% errors, 100ms
+	tests := []struct {
+		name    string
+		config  CanaryConfig
+		canary  CanaryMetrics
+		want    CanaryStatus
+		reasons int
+	}{
+		{"within thresholds", CanaryConfig{}, NewCanaryMetrics(1000, 10, 115_000), CanaryPassed, 0},
+		{"error rate", CanaryConfig{}, NewCanaryMetrics(1000, 20, 100_000), CanaryFailed, 1},
+		{"latency", CanaryConfig{}, NewCanaryMetrics(1000, 5, 150_000), CanaryFailed, 1},
+		{"both", CanaryConfig{}, NewCanaryMetrics(1000, 20, 150_000), CanaryFailed, 2},
+		{"looser threshold", CanaryConfig{MaxLatencyIncreasePercent: 60}, NewCanaryMetrics(1000, 5, 150_000), CanaryPassed, 0},
+		{"too little traffic", CanaryConfig{}, NewCanaryMetrics(50, 50, 500_000), CanaryInconclusive, 1},
+		{"lower min requests", CanaryConfig{MinRequests: 10}, NewCanaryMetrics(50, 0, 5_000), CanaryPassed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reasons := tt.config.Evaluate(tt.canary, baseline)
+			if status != tt.want || len(reasons) != tt.reasons {
+				t.Errorf("Evaluate() = %s %q, want %s with %d reasons", status, reasons, tt.want, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestCanaryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config CanaryConfig
+		ok     bool
+	}{
+		{"defaults", CanaryConfig{}, true},
+		{"all set", CanaryConfig{Weight: 25, WindowSeconds: 600, MaxErrorRateIncrease: 0.5, MaxLatencyIncreasePercent: 10, MinRequests: 500}, true},
+		{"weight above 50", CanaryConfig{Weight: 60}, false},
+		{"negative weight", CanaryConfig{Weight: -1}, false},
+		{"short window", CanaryConfig{WindowSeconds: 30}, false},
+		{"long window", CanaryConfig{WindowSeconds: 90000}, false},
+		{"negative error rate", CanaryConfig{MaxErrorRateIncrease: -1}, false},
+		{"negative latency", CanaryConfig{MaxLatencyIncreasePercent: -5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() error = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CDNProviderType identifies a CDN whose cache can be purged.
+type CDNProviderType string
+
+const (
+	CDNProviderCloudflare CDNProviderType = "cloudflare"
+	CDNProviderFastly     CDNProviderType = "fastly"
+	CDNProviderBunny      CDNProviderType = "bunny"
+)
+
+// CDNProviderTypes lists every supported CDN in display order.
+var CDNProviderTypes = []CDNProviderType{
+	CDNProviderCloudflare,
+	CDNProviderFastly,
+	CDNProviderBunny,
+}
+
+// IsValid reports whether t is a supported CDN.
+func (t CDNProviderType) IsValid() bool {
+	for _, known := range CDNProviderTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Validation errors for CDN integrations.
+var (
+	ErrCDNProviderType  = errors.New("unknown CDN provider")
+	ErrCDNName          = errors.New("CDN integration name is required")
+	ErrCDNConfigMissing = errors.New("CDN integration config is incomplete")
+)
+
+// CDNIntegration purges an app's content from a CDN. Services lists the
+// services whose successful deploys purge the cache automatically; with none
+// the integration is only purged manually.
+type CDNIntegration struct {
+	ID       string            `json:"id"`
+	AppID    string            `json:"app_id"`
+	Provider CDNProviderType   `json:"provider"`
+	Name     string            `json:"name"`
+	Enabled  bool              `json:"enabled"`
+	Config   map[string]string `json:"config"`
+	Services []string          `json:"services"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the integration's provider and name. Config fields are
+// checked by the provider implementation.
+func (i *CDNIntegration) Validate() error {
+	if !i.Provider.IsValid() {
+		return fmt.Errorf("%w: %q", ErrCDNProviderType, i.Provider)
+	}
+	if i.Name == "" {
+		return ErrCDNName
+	}
+	return nil
+}
+
+// PurgesOnDeploy reports whether a successful deploy of the service purges
+// the integration's cache.
+func (i *CDNIntegration) PurgesOnDeploy(serviceName string) bool {
+	if !i.Enabled {
+		return false
+	}
+	for _, s := range i.Services {
+		if s == serviceName {
+			return true
+		}
+	}
+	return false
+}
+
+// CDNPurgeTrigger is what started a purge.
+type CDNPurgeTrigger string
+
+const (
+	CDNPurgeTriggerDeploy CDNPurgeTrigger = "deploy"
+	CDNPurgeTriggerManual CDNPurgeTrigger = "manual"
+)
+
+// CDNPurge records one purge of one integration and its result. URLs is empty
+// when the whole cache was purged.
+type CDNPurge struct {
+	ID            string          `json:"id"`
+	AppID         string          `json:"app_id"`
+	IntegrationID string          `json:"integration_id"`
+	Provider      CDNProviderType `json:"provider"`
+	Trigger       CDNPurgeTrigger `json:"trigger"`
+	// DeploymentID and ServiceName are set for purges triggered by a deploy.
+	DeploymentID string `json:"deployment_id,omitempty"`
+	ServiceName  string `json:"service_name,omitempty"`
+	// RequestedBy is the user who purged manually.
+	RequestedBy string   `json:"requested_by,omitempty"`
+	URLs        []string `json:"urls,omitempty"`
+	Succeeded   bool     `json:"succeeded"`
+	Error       string   `json:"error,omitempty"`
+	DurationMs  int64    `json:"duration_ms"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,286 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceType says how a service runs.
+type ServiceType string
+
+const (
+	// ServiceTypeService runs continuously with its configured replicas.
+	ServiceTypeService ServiceType = "service"
+	// ServiceTypeCron runs a command to completion on a schedule.
+	ServiceTypeCron ServiceType = "cron"
+)
+
+// Limits and defaults of cron services.
+const (
+	DefaultCronTimeoutSeconds = 3600
+	MaxCronTimeoutSeconds     = 24 * 3600
+)
+
+// CronConfig is the schedule and command of a cron service. Each time the
+// schedule fires, a one-off run starts from the service's latest deployed
+// artifact and executes Command.
+type CronConfig struct {
+	Schedule string   `json:"schedule"`           // Five-field cron expression or @hourly, @daily, ...
+	Timezone string   `json:"timezone,omitempty"` // IANA name, UTC when empty
+	Command  []string `json:"command"`
+	// TimeoutSeconds stops runs that take longer (default: DefaultCronTimeoutSeconds).
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Validate checks the schedule, command and timeout.
+func (c *CronConfig) Validate() error {
+	if _, err := ParseCronSchedule(c.Schedule); err != nil {
+		return err
+	}
+	if _, err := c.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q", c.Timezone)
+	}
+	if len(c.Command) == 0 || strings.TrimSpace(c.Command[0]) == "" {
+		return errors.New("command is required")
+	}
+	if c.TimeoutSeconds < 0 || c.TimeoutSeconds > MaxCronTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds must be between 1 and %d, or 0 for the default", MaxCronTimeoutSeconds)
+	}
+	return nil
+}
+
+// Timeout returns how long a run may take.
+func (c *CronConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultCronTimeoutSeconds * time.Second
+}
+
+// Next returns the first time after t the schedule fires, or the zero time
+// if the schedule is invalid or never fires.
+func (c *CronConfig) Next(t time.Time) time.Time {
+	schedule, err := ParseCronSchedule(c.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	loc, err := c.location()
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(t.In(loc))
+}
+
+func (c *CronConfig) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// CronSchedule is a parsed cron expression: the minutes, hours, days of the
+// month, months and weekdays it fires on.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record unrestricted day fields; when both day fields
+	// are restricted, a day matching either one fires.
+	domAny, dowAny bool
+}
+
+// cronMacros are the shorthand schedules.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	cronDayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// ParseCronSchedule parses a five-field cron expression (minute, hour, day of
+// month, month, day of week) or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly shorthands. Fields accept *, numbers, ranges, lists
+// and steps; months and weekdays also accept three-letter names, and 7 is
+// Sunday.
+func ParseCronSchedule(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields", expr)
+	}
+
+	var s CronSchedule
+	var err error
+	if s.minute, _, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule minute: %w", err)
+	}
+	if s.hour, _, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule hour: %w", err)
+	}
+	if s.dom, s.domAny, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule day of month: %w", err)
+	}
+	if s.month, _, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule month: %w", err)
+	}
+	if s.dow, s.dowAny, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule day of week: %w", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return &s, nil
+}
+
+// parseCronField parses one field into a bit set of the values it matches
+// and reports whether the field is unrestricted, i.e. starts with *.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], names); err != nil {
+				return 0, false, err
+			}
+			if hi, err = cronValue(bounds[1], names); err != nil {
+				return 0, false, err
+			}
+		default:
+			n, err := cronValue(rangePart, names)
+			if err != nil {
+				return 0, false, err
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, false, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, strings.HasPrefix(field, "*"), nil
+}
+
+// cronValue parses a number or a name.
+func cronValue(s string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return n, nil
+}
+
+// Next returns the first minute after t the schedule fires, in t's location.
+// It returns the zero time if the schedule never fires, e.g. on February 30.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Every schedule that fires at all does so within four years (leap days).
+	limit := t.AddDate(4, 0, 1)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for the two day fields: if either is
+// unrestricted the other decides, otherwise matching either one suffices.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// CronRunStatus is the state of a cron service run.
+type CronRunStatus string
+
+const (
+	CronRunStatusPending   CronRunStatus = "pending" // Waiting to be placed on a node
+	CronRunStatusRunning   CronRunStatus = "running"
+	CronRunStatusSucceeded CronRunStatus = "succeeded" // Exited with status 0
+	CronRunStatusFailed    CronRunStatus = "failed"
+	CronRunStatusTimedOut  CronRunStatus = "timed_out" // Stopped after exceeding its timeout
+	CronRunStatusSkipped   CronRunStatus = "skipped"   // Not started because an earlier run was still active
+)
+
+// Finished returns true if the run will not change state again.
+func (s CronRunStatus) Finished() bool {
+	return s != CronRunStatusPending && s != CronRunStatusRunning
+}
+
+// CronRunTrigger says what started a run.
+type CronRunTrigger string
+
+const (
+	CronRunTriggerSchedule CronRunTrigger = "schedule"
+	CronRunTriggerManual   CronRunTrigger = "manual"
+)
+
+// CronRun is one execution of a cron service's command. Each run is a
+// deployment of the service's artifact that runs the command and exits.
+type CronRun struct {
+	ID           string         `json:"id"`
+	AppID        string         `json:"app_id"`
+	ServiceName  string         `json:"service_name"`
+	DeploymentID string         `json:"deployment_id,omitempty"`
+	Trigger      CronRunTrigger `json:"trigger"`
+	TriggeredBy  string         `json:"triggered_by,omitempty"`
+	Status       CronRunStatus  `json:"status"`
+	// ScheduledAt is the time the schedule fired, or the request time of a manual run.
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
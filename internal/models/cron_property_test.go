@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: cron-services, Property 1: Next Fire Time**
+// For any time, the next fire time of "*/15 9-17 * * mon-fri" SHALL be the
+// first later minute that is a quarter hour between 09:00 and 17:59 on a
+// weekday.
+
+func TestCronScheduleNext(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	// Sunday 00:00 UTC
+	base := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := ParseCronSchedule("*/15 9-17 * * mon-fri")
+	if err != nil {
+		t.Fatalf("ParseCronSchedule: %v", err)
+	}
+	fires := func(t time.Time) bool {
+		weekday := t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+		return weekday && t.Hour() >= 9 && t.Hour() <= 17 && t.Minute()%15 == 0
+	}
+
+	properties.Property("next is the first later matching minute", prop.ForAll(
+		func(seconds int) bool {
+			now := base.Add(time.Duration(seconds) * time.Second)
+			expected := now.Truncate(time.Minute).Add(time.Minute)
+			for !fires(expected) {
+				expected = expected.Add(time.Minute)
+			}
+			return schedule.Next(now).Equal(expected)
+		},
+		gen.IntRange(0, 3*7*24*3600),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestCronScheduleDays(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		// Both day fields restricted: either one matches (13th or any Friday)
+		{"0 0 13 * 5", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)},
+		// 7 is Sunday
+		{"30 6 * * 7", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 11, 6, 30, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Leap days only
+		{"0 0 29 feb *", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Never fires
+		{"0 0 30 2 *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronSchedule(%q): %v", tt.expr, err)
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestCronConfigValidate(t *testing.T) {
+	valid := CronConfig{Schedule: "0 3 * * *", Timezone: "Europe/Berlin", Command: []string{"./cleanup"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	// 03:00 in Berlin is 02:00 UTC in winter
+	next := valid.Next(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 1, 5, 2, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("Next = %s, want %s", next.UTC(), want)
+	}
+
+	invalid := []CronConfig{
+		{Schedule: "0 3 * *", Command: []string{"./cleanup"}},
+		{Schedule: "60 * * * *", Command: []string{"./cleanup"}},
+		{Schedule: "*/0 * * * *", Command: []string{"./cleanup"}},
+		{Schedule: "0 3 * * *", Timezone: "Mars/Olympus", Command: []string{"./cleanup"}},
+		{Schedule: "0 3 * * *"},
+		{Schedule: "0 3 * * *", Command: []string{"./cleanup"}, TimeoutSeconds: MaxCronTimeoutSeconds + 1},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", c)
+		}
+	}
+
+	svc := ServiceConfig{Name: "web", SourceType: SourceTypeImage, Image: "nginx", Cron: &valid}
+	if err := svc.Validate(); err == nil {
+		t.Error("cron config on a non-cron service validated")
+	}
+	svc = ServiceConfig{Name: "cleanup", SourceType: SourceTypeImage, Image: "nginx", Type: ServiceTypeCron}
+	if err := svc.Validate(); err == nil {
+		t.Error("cron service without cron config validated")
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// TableStats is the size and row counts of a database table.
+type TableStats struct {
+	Table string `json:"table"`
+	// TotalBytes includes the table's indexes and TOAST data
+	TotalBytes int64      `json:"total_bytes"`
+	IndexBytes int64      `json:"index_bytes"`
+	LiveRows   int64      `json:"live_rows"`
+	DeadRows   int64      `json:"dead_rows"`
+	LastVacuum *time.Time `json:"last_vacuum,omitempty"` // Latest manual or automatic vacuum
+}
+
+// DeadRatio is the share of the table's rows that are dead, waiting for a
+// vacuum to reuse their space.
+func (t *TableStats) DeadRatio() float64 {
+	if t.LiveRows+t.DeadRows == 0 {
+		return 0
+	}
+	return float64(t.DeadRows) / float64(t.LiveRows+t.DeadRows)
+}
+
+// TableSizeSample is the size of a table at a point in time, kept to report
+// growth trends.
+type TableSizeSample struct {
+	Table      string    `json:"table"`
+	TotalBytes int64     `json:"total_bytes"`
+	LiveRows   int64     `json:"live_rows"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// DBOrphans counts rows nothing refers to any more.
+type DBOrphans struct {
+	// QueueJobs are build queue jobs whose build is missing or finished
+	QueueJobs int64 `json:"queue_jobs"`
+	// Logs are log rows whose deployment is missing or belongs to a deleted app
+	Logs int64 `json:"logs"`
+}
+
+// TableHealth is a table's statistics with its growth and maintenance advice.
+type TableHealth struct {
+	TableStats
+	DeadRatio float64 `json:"dead_ratio"`
+	Bloated   bool    `json:"bloated"`
+	// GrowthBytesPerDay is the average growth over the trend window, when
+	// older snapshots exist
+	GrowthBytesPerDay *int64 `json:"growth_bytes_per_day,omitempty"`
+	// Remedy says how to reclaim the space of a bloated table
+	Remedy string `json:"remedy,omitempty"`
+}
+
+// DBMaintenanceRun is the outcome of a database maintenance pass.
+type DBMaintenanceRun struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	DeletedQueueJobs int64     `json:"deleted_queue_jobs"`
+	DeletedLogs      int64     `json:"deleted_logs"`
+	VacuumedTables   []string  `json:"vacuumed_tables,omitempty"`
+	Errors           []string  `json:"errors,omitempty"`
+}
+
+// DBHealthReport describes the size, growth and bloat of the database.
+type DBHealthReport struct {
+	DatabaseBytes int64         `json:"database_bytes"`
+	Tables        []TableHealth `json:"tables"` // Largest first
+	Orphans       DBOrphans     `json:"orphans"`
+	// TrendWindow is the period growth is averaged over
+	TrendWindow     string            `json:"trend_window"`
+	LastMaintenance *DBMaintenanceRun `json:"last_maintenance,omitempty"`
+	CheckedAt       time.Time         `json:"checked_at"`
+}
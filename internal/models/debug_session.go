@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Limits and defaults of debug sessions.
+const (
+	DefaultDebugImage      = "docker.io/library/busybox:latest"
+	DefaultDebugTTLSeconds = 30 * 60
+	MaxDebugTTLSeconds     = 4 * 60 * 60
+)
+
+// DebugSessionStatus is the state of a debug session.
+type DebugSessionStatus string
+
+const (
+	// DebugSessionPending sessions wait for their container to be started.
+	DebugSessionPending DebugSessionStatus = "pending"
+	// DebugSessionRunning sessions have a debug container on the node.
+	DebugSessionRunning DebugSessionStatus = "running"
+	// DebugSessionTerminating sessions wait for their container to be removed.
+	DebugSessionTerminating DebugSessionStatus = "terminating"
+	// DebugSessionTerminated sessions ended and have no container left.
+	DebugSessionTerminated DebugSessionStatus = "terminated"
+	// DebugSessionFailed sessions could not start or remove their container.
+	DebugSessionFailed DebugSessionStatus = "failed"
+)
+
+// Finished returns true if the status will not change again.
+func (s DebugSessionStatus) Finished() bool {
+	return s == DebugSessionTerminated || s == DebugSessionFailed
+}
+
+// DebugSession is a short-lived debug container started on the node of a
+// running deployment, sharing the PID, network and IPC namespaces of the
+// deployment's container. It brings the tools of Image to workloads built
+// without a shell, and is removed at ExpiresAt if not ended before.
+type DebugSession struct {
+	ID           string `json:"id"`
+	AppID        string `json:"app_id"`
+	ServiceName  string `json:"service_name"`
+	DeploymentID string `json:"deployment_id"`
+	NodeID       string `json:"node_id"`
+
+	Image string `json:"image"`
+	// Command runs in the debug container; when empty the container idles
+	// until the session ends so it can be attached to
+	Command    []string `json:"command,omitempty"`
+	TTLSeconds int      `json:"ttl_seconds"`
+
+	Status DebugSessionStatus `json:"status"`
+	Error  string             `json:"error,omitempty"`
+
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// Normalize fills in the defaults of unset settings.
+func (s *DebugSession) Normalize() {
+	s.Image = strings.TrimSpace(s.Image)
+	if s.Image == "" {
+		s.Image = DefaultDebugImage
+	}
+	if s.TTLSeconds == 0 {
+		s.TTLSeconds = DefaultDebugTTLSeconds
+	}
+}
+
+// Validate checks the session's settings.
+func (s *DebugSession) Validate() error {
+	if strings.ContainsAny(s.Image, " \t\n") {
+		return fmt.Errorf("image must be an image reference")
+	}
+	if s.TTLSeconds < 60 || s.TTLSeconds > MaxDebugTTLSeconds {
+		return fmt.Errorf("ttl_seconds must be between 60 and %d", MaxDebugTTLSeconds)
+	}
+	return nil
+}
+
+// ContainerName returns the name of the session's debug container.
+func (s *DebugSession) ContainerName() string {
+	return "narvana-debug-" + s.ID
+}
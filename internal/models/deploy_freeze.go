@@ -0,0 +1,176 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FreezeWindowKind determines how a freeze window's schedule is interpreted.
+type FreezeWindowKind string
+
+const (
+	// FreezeWindowRecurring repeats every week, e.g. Friday 16:00 to Monday 08:00.
+	FreezeWindowRecurring FreezeWindowKind = "recurring"
+	// FreezeWindowRange covers a single absolute time range, e.g. a holiday period.
+	FreezeWindowRange FreezeWindowKind = "range"
+)
+
+// MaxFreezeOverrideReasonLength is the maximum length of a freeze override reason.
+const MaxFreezeOverrideReasonLength = 500
+
+// ErrDeployFrozen is returned when a deploy is attempted during an active freeze window.
+var ErrDeployFrozen = errors.New("deploys are frozen")
+
+// FreezeWindow is an org-wide period during which non-emergency deploys are blocked.
+// Recurring windows use StartDay/StartTime/EndDay/EndTime in Timezone; range
+// windows use StartsAt/EndsAt.
+type FreezeWindow struct {
+	ID        string           `json:"id"`
+	OrgID     string           `json:"org_id"`
+	Name      string           `json:"name"`
+	Kind      FreezeWindowKind `json:"kind"`
+	StartDay  string           `json:"start_day,omitempty"`  // Weekday name, e.g. "friday"
+	StartTime string           `json:"start_time,omitempty"` // HH:MM
+	EndDay    string           `json:"end_day,omitempty"`
+	EndTime   string           `json:"end_time,omitempty"`
+	Timezone  string           `json:"timezone,omitempty"` // IANA name, UTC when empty
+	StartsAt  *time.Time       `json:"starts_at,omitempty"`
+	EndsAt    *time.Time       `json:"ends_at,omitempty"`
+	CreatedBy string           `json:"created_by"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// FreezeOverride records a deploy that went ahead during an active freeze window.
+type FreezeOverride struct {
+	ID           string    `json:"id"`
+	OrgID        string    `json:"org_id"`
+	WindowID     string    `json:"window_id"`
+	DeploymentID string    `json:"deployment_id"`
+	AppID        string    `json:"app_id"`
+	ServiceName  string    `json:"service_name"`
+	UserID       string    `json:"user_id"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// weekdays maps lowercase weekday names to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Validate checks the window's schedule and normalizes weekday names.
+func (f *FreezeWindow) Validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(f.Name) > 100 {
+		return errors.New("name must be 100 characters or fewer")
+	}
+
+	switch f.Kind {
+	case FreezeWindowRecurring:
+		f.StartDay = strings.ToLower(strings.TrimSpace(f.StartDay))
+		f.EndDay = strings.ToLower(strings.TrimSpace(f.EndDay))
+		start, err := weekMinute(f.StartDay, f.StartTime)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+		end, err := weekMinute(f.EndDay, f.EndTime)
+		if err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+		if start == end {
+			return errors.New("start and end must differ")
+		}
+		if _, err := f.location(); err != nil {
+			return fmt.Errorf("invalid timezone %q", f.Timezone)
+		}
+		f.StartsAt, f.EndsAt = nil, nil
+	case FreezeWindowRange:
+		if f.StartsAt == nil || f.EndsAt == nil {
+			return errors.New("starts_at and ends_at are required")
+		}
+		if !f.EndsAt.After(*f.StartsAt) {
+			return errors.New("ends_at must be after starts_at")
+		}
+		f.StartDay, f.StartTime, f.EndDay, f.EndTime, f.Timezone = "", "", "", "", ""
+	default:
+		return errors.New("kind must be one of: recurring, range")
+	}
+	return nil
+}
+
+// ActiveAt returns true if deploys are frozen by this window at the given time.
+// Recurring windows whose end is earlier in the week than their start wrap
+// around the weekend, so Friday 16:00 to Monday 08:00 works as expected.
+func (f *FreezeWindow) ActiveAt(now time.Time) bool {
+	switch f.Kind {
+	case FreezeWindowRange:
+		if f.StartsAt == nil || f.EndsAt == nil {
+			return false
+		}
+		return !now.Before(*f.StartsAt) && now.Before(*f.EndsAt)
+	case FreezeWindowRecurring:
+		loc, err := f.location()
+		if err != nil {
+			return false
+		}
+		start, err := weekMinute(f.StartDay, f.StartTime)
+		if err != nil {
+			return false
+		}
+		end, err := weekMinute(f.EndDay, f.EndTime)
+		if err != nil {
+			return false
+		}
+		local := now.In(loc)
+		m := int(local.Weekday())*24*60 + local.Hour()*60 + local.Minute()
+		if start < end {
+			return m >= start && m < end
+		}
+		return m >= start || m < end
+	default:
+		return false
+	}
+}
+
+// location returns the window's time zone, defaulting to UTC.
+func (f *FreezeWindow) location() (*time.Location, error) {
+	if f.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(f.Timezone)
+}
+
+// weekMinute converts a weekday name and HH:MM time into minutes since Sunday 00:00.
+func weekMinute(day, clock string) (int, error) {
+	wd, ok := weekdays[day]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", day)
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return int(wd)*24*60 + t.Hour()*60 + t.Minute(), nil
+}
+
+// ActiveFreezeWindow returns the first window that freezes deploys at the
+// given time, or nil if deploys are allowed.
+func ActiveFreezeWindow(windows []*FreezeWindow, now time.Time) *FreezeWindow {
+	for _, w := range windows {
+		if w.ActiveAt(now) {
+			return w
+		}
+	}
+	return nil
+}
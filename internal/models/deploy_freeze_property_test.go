@@ -0,0 +1,134 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: deploy-freeze, Property 1: Recurring Freeze Window**
+// For any time, a Friday 16:00 to Monday 08:00 window SHALL be active exactly
+// when the local time falls between Friday 16:00 and the following Monday 08:00.
+
+func TestFreezeWindowRecurringActiveAt(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	// Sunday 00:00 UTC
+	base := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	window := &FreezeWindow{
+		Name:      "weekend",
+		Kind:      FreezeWindowRecurring,
+		StartDay:  "friday",
+		StartTime: "16:00",
+		EndDay:    "monday",
+		EndTime:   "08:00",
+	}
+	if err := window.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	properties.Property("active from Friday 16:00 until Monday 08:00", prop.ForAll(
+		func(minute int) bool {
+			now := base.Add(time.Duration(minute) * time.Minute)
+			m := minute % (7 * 24 * 60)
+			friday := int(time.Friday)*24*60 + 16*60
+			monday := int(time.Monday)*24*60 + 8*60
+			expected := m >= friday || m < monday
+			return window.ActiveAt(now) == expected
+		},
+		gen.IntRange(0, 4*7*24*60),
+	))
+
+	properties.TestingRun(t)
+}
+
+// **Feature: deploy-freeze, Property 2: Range Freeze Window**
+// For any range window, the window SHALL be active exactly within [starts_at, ends_at).
+
+func TestFreezeWindowRangeActiveAt(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	base := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+
+	properties.Property("active exactly within [starts_at, ends_at)", prop.ForAll(
+		func(duration, nowOffset int) bool {
+			startsAt := base
+			endsAt := base.Add(time.Duration(duration) * time.Hour)
+			now := base.Add(time.Duration(nowOffset) * time.Hour)
+
+			w := &FreezeWindow{Kind: FreezeWindowRange, StartsAt: &startsAt, EndsAt: &endsAt}
+			expected := !now.Before(startsAt) && now.Before(endsAt)
+			return w.ActiveAt(now) == expected
+		},
+		gen.IntRange(1, 500),
+		gen.IntRange(-500, 1000),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestFreezeWindowTimezone(t *testing.T) {
+	window := &FreezeWindow{
+		Name:      "evening",
+		Kind:      FreezeWindowRecurring,
+		StartDay:  "Wednesday",
+		StartTime: "18:00",
+		EndDay:    "thursday",
+		EndTime:   "09:00",
+		Timezone:  "America/New_York",
+	}
+	if err := window.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if window.StartDay != "wednesday" {
+		t.Errorf("start_day = %q, want it normalized to lowercase", window.StartDay)
+	}
+
+	// Wednesday 20:00 UTC is 15:00 in New York, before the window opens
+	if window.ActiveAt(time.Date(2026, 1, 7, 20, 0, 0, 0, time.UTC)) {
+		t.Error("window should not be active at 15:00 local time")
+	}
+	// Thursday 01:00 UTC is Wednesday 20:00 in New York
+	if !window.ActiveAt(time.Date(2026, 1, 8, 1, 0, 0, 0, time.UTC)) {
+		t.Error("window should be active at 20:00 local time")
+	}
+}
+
+func TestFreezeWindowValidate(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		window FreezeWindow
+		ok     bool
+	}{
+		{"recurring", FreezeWindow{Name: "w", Kind: FreezeWindowRecurring, StartDay: "friday", StartTime: "16:00", EndDay: "monday", EndTime: "08:00"}, true},
+		{"range", FreezeWindow{Name: "w", Kind: FreezeWindowRange, StartsAt: &now, EndsAt: &later}, true},
+		{"missing name", FreezeWindow{Kind: FreezeWindowRange, StartsAt: &now, EndsAt: &later}, false},
+		{"unknown kind", FreezeWindow{Name: "w", Kind: "monthly"}, false},
+		{"bad weekday", FreezeWindow{Name: "w", Kind: FreezeWindowRecurring, StartDay: "fri", StartTime: "16:00", EndDay: "monday", EndTime: "08:00"}, false},
+		{"bad time", FreezeWindow{Name: "w", Kind: FreezeWindowRecurring, StartDay: "friday", StartTime: "4pm", EndDay: "monday", EndTime: "08:00"}, false},
+		{"empty window", FreezeWindow{Name: "w", Kind: FreezeWindowRecurring, StartDay: "friday", StartTime: "16:00", EndDay: "friday", EndTime: "16:00"}, false},
+		{"bad timezone", FreezeWindow{Name: "w", Kind: FreezeWindowRecurring, StartDay: "friday", StartTime: "16:00", EndDay: "monday", EndTime: "08:00", Timezone: "Mars/Olympus"}, false},
+		{"range reversed", FreezeWindow{Name: "w", Kind: FreezeWindowRange, StartsAt: &later, EndsAt: &now}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() error = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ const (
 	DeploymentStatusStopping  DeploymentStatus = "stopping"
 	DeploymentStatusStopped   DeploymentStatus = "stopped"
 	DeploymentStatusFailed    DeploymentStatus = "failed"
+	DeploymentStatusCanceled  DeploymentStatus = "canceled"
 )
 
 // RuntimeConfig holds runtime configuration for a deployment.
@@ -26,6 +27,29 @@ type RuntimeConfig struct {
 	EnvVars     map[string]string  `json:"env_vars,omitempty"`
 	Ports       []PortMapping      `json:"ports,omitempty"`
 	HealthCheck *HealthCheckConfig `json:"health_check,omitempty"`
+	Egress      *EgressPolicy      `json:"egress,omitempty"`
+	// Command replaces the artifact's entrypoint; set on cron service runs,
+	// whose container exits when the command completes.
+	Command []string `json:"command,omitempty"`
+	// Runtime is the driver the service selected for pure-nix releases,
+	// copied from the service when the deployment is scheduled
+	Runtime ServiceRuntime `json:"runtime,omitempty"`
+	// CanaryBaselineID is set on canary deployments to the deployment the
+	// agent keeps serving all but CanaryWeight percent of requests
+	CanaryBaselineID string `json:"canary_baseline_id,omitempty"`
+	CanaryWeight     int    `json:"canary_weight,omitempty"`
+}
+
+// WithoutCanary returns a copy of the config for a deployment that is not a
+// canary, such as a rollback to a release that was deployed as one.
+func (c *RuntimeConfig) WithoutCanary() *RuntimeConfig {
+	if c == nil || c.CanaryBaselineID == "" {
+		return c
+	}
+	config := *c
+	config.CanaryBaselineID = ""
+	config.CanaryWeight = 0
+	return &config
 }
 
 // Deployment represents an instance of an application version running on one or more nodes.
@@ -47,6 +71,45 @@ type Deployment struct {
 	UpdatedAt   time.Time        `json:"updated_at"`
 	StartedAt   *time.Time       `json:"started_at,omitempty"`
 	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+
+	// RollbackOf is the deployment whose artifact this rollback redeploys.
+	RollbackOf string `json:"rollback_of,omitempty"`
+
+	// Canary is the analysis of a canary deployment against its baseline.
+	Canary *CanaryAnalysis `json:"canary,omitempty"`
+
+	// Health records the readiness probes of a deployment with a health
+	// check, which stays starting until one passes.
+	Health *DeploymentHealth `json:"health,omitempty"`
+
+	// Error explains why the deployment failed, e.g. the output of the health
+	// check that never passed.
+	Error string `json:"error,omitempty"`
+}
+
+// HealthCheck returns the deployment's health check, or nil if it has none.
+// One-off runs are never probed.
+func (d *Deployment) HealthCheck() *HealthCheckConfig {
+	if d.Config == nil || d.IsCronRun() {
+		return nil
+	}
+	return d.Config.HealthCheck
+}
+
+// Succeeded reports whether the deployment has an artifact that is known to
+// start: it is running now, or it ran before being stopped or replaced.
+func (d *Deployment) Succeeded() bool {
+	if d.Artifact == "" || d.Status == DeploymentStatusFailed {
+		return false
+	}
+	return d.Status == DeploymentStatusRunning || d.StartedAt != nil
+}
+
+// IsCronRun returns true if the deployment is a one-off run, of a cron
+// service or a smoke test command, which executes a command once instead of
+// serving.
+func (d *Deployment) IsCronRun() bool {
+	return d.Config != nil && len(d.Config.Command) > 0
 }
 
 // GenerateContainerName creates a unique container name with version.
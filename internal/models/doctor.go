@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// DoctorSeverity is how serious a doctor finding is.
+type DoctorSeverity string
+
+const (
+	DoctorSeverityOK      DoctorSeverity = "ok"      // The check passed
+	DoctorSeverityInfo    DoctorSeverity = "info"    // Worth knowing, nothing is broken
+	DoctorSeverityWarning DoctorSeverity = "warning" // Some features will not work
+	DoctorSeverityError   DoctorSeverity = "error"   // Builds or deployments will fail
+)
+
+// rank orders severities from ok to error.
+func (s DoctorSeverity) rank() int {
+	switch s {
+	case DoctorSeverityInfo:
+		return 1
+	case DoctorSeverityWarning:
+		return 2
+	case DoctorSeverityError:
+		return 3
+	}
+	return 0
+}
+
+// DoctorFinding is the outcome of one installation check.
+type DoctorFinding struct {
+	Check    string         `json:"check"` // e.g. "database", "registry", "clock_skew"
+	Severity DoctorSeverity `json:"severity"`
+	Message  string         `json:"message"`
+	// Remedy says how to fix a failed check
+	Remedy string `json:"remedy,omitempty"`
+}
+
+// DoctorReport lists the findings of an installation health check.
+type DoctorReport struct {
+	// Status is the most serious severity among the findings
+	Status    DoctorSeverity  `json:"status"`
+	Findings  []DoctorFinding `json:"findings"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// Add appends a finding and raises the report's status to its severity.
+func (r *DoctorReport) Add(f DoctorFinding) {
+	r.Findings = append(r.Findings, f)
+	if f.Severity.rank() > r.Status.rank() {
+		r.Status = f.Severity
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// EgressPolicy restricts outbound network connections from a service's
+// containers. A nil policy, or one with DenyAll unset, allows all egress.
+type EgressPolicy struct {
+	// DenyAll blocks outbound traffic except to destinations in Allow.
+	DenyAll bool         `json:"deny_all"`
+	Allow   []EgressRule `json:"allow,omitempty"`
+}
+
+// EgressRule allows outbound traffic to a host or CIDR. Exactly one of Host
+// and CIDR is set. Hosts are resolved by the node agent when it applies the
+// policy.
+type EgressRule struct {
+	Host     string `json:"host,omitempty"`     // e.g., "api.stripe.com"
+	CIDR     string `json:"cidr,omitempty"`     // e.g., "10.0.0.0/8"
+	Ports    []int  `json:"ports,omitempty"`    // Empty allows all ports
+	Protocol string `json:"protocol,omitempty"` // "tcp" or "udp", empty allows both
+}
+
+// Restricts reports whether the policy blocks any outbound traffic.
+func (p *EgressPolicy) Restricts() bool {
+	return p != nil && p.DenyAll
+}
+
+// EgressViolation counts outbound connections from a deployment that were
+// blocked by its egress policy. Counts are cumulative for the deployment.
+type EgressViolation struct {
+	DeploymentID string    `json:"deployment_id"`
+	AppID        string    `json:"app_id"`
+	ServiceName  string    `json:"service_name"`
+	Destination  string    `json:"destination"`
+	Port         int       `json:"port"`
+	Protocol     string    `json:"protocol"`
+	Count        int64     `json:"count"`
+	FirstSeenAt  time.Time `json:"first_seen_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Validation errors for environments.
+var (
+	ErrEnvironmentName = errors.New("environment name must be a lowercase DNS label of at most 63 characters")
+	ErrEnvironmentApp  = errors.New("app_id of the app backing the environment is required")
+)
+
+// environmentNamePattern matches environment names such as "staging" or "production".
+var environmentNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Environment is one stage of an app's release pipeline, e.g. staging or
+// production. Each environment runs in its own app, so its secrets, domains
+// and replicas are set on that app; promotion deploys a build running in one
+// environment to the next without rebuilding it. Environments are ordered by
+// Position, and promotion into an environment that requires approval waits
+// until an owner approves it.
+type Environment struct {
+	ID string `json:"id"`
+	// AppID is the app whose pipeline the environment belongs to.
+	AppID string `json:"app_id"`
+	Name  string `json:"name"`
+	// Position orders the pipeline; builds are promoted towards higher positions.
+	Position int `json:"position"`
+	// EnvAppID is the app the environment's deployments run in. It may be
+	// the pipeline's own app, typically for its first environment.
+	EnvAppID        string    `json:"env_app_id"`
+	RequireApproval bool      `json:"require_approval"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Validate checks the environment's name and backing app.
+func (e *Environment) Validate() error {
+	e.Name = strings.TrimSpace(e.Name)
+	if !environmentNamePattern.MatchString(e.Name) {
+		return ErrEnvironmentName
+	}
+	if e.EnvAppID == "" {
+		return ErrEnvironmentApp
+	}
+	return nil
+}
+
+// NextEnvironment returns the environment after the one at the given
+// position in a pipeline, or nil if it is the last. Environments need not be
+// sorted.
+func NextEnvironment(environments []*Environment, position int) *Environment {
+	var next *Environment
+	for _, e := range environments {
+		if e.Position > position && (next == nil || e.Position < next.Position) {
+			next = e
+		}
+	}
+	return next
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// EventKind is the kind of object an Event is about.
+type EventKind string
+
+const (
+	EventKindApp        EventKind = "app"
+	EventKindService    EventKind = "service"
+	EventKindDeployment EventKind = "deployment"
+	EventKindBuild      EventKind = "build"
+	// EventKindNode events report nodes becoming unhealthy or healthy, and
+	// changes of their clock or agent certificate condition. They are
+	// streamed to instance admins only.
+	EventKindNode EventKind = "node"
+
+	// EventKindReset tells subscribers that events may have been missed,
+	// e.g. while the database connection was lost, so state they show
+	// should be reloaded.
+	EventKindReset EventKind = "reset"
+)
+
+// IsValid reports whether k is a known event kind.
+func (k EventKind) IsValid() bool {
+	switch k {
+	case EventKindApp, EventKindService, EventKindDeployment, EventKindBuild, EventKindNode, EventKindReset:
+		return true
+	}
+	return false
+}
+
+// Event actions.
+const (
+	EventActionCreated = "created"
+	EventActionUpdated = "updated"
+	EventActionDeleted = "deleted"
+)
+
+// Event is a change of an app, service, deployment, build or node, streamed
+// to the subscribers of /v1/events. Deployments and builds produce an event
+// when they are created and whenever their status changes. Node events
+// have the status healthy or unhealthy when heartbeats resume or lapse, and
+// otherwise the worse of the node's clock and certificate conditions.
+type Event struct {
+	// Seq orders the events of a control plane instance; clients resume a
+	// stream after the last one they saw.
+	Seq    int64     `json:"seq"`
+	Kind   EventKind `json:"kind"`
+	Action string    `json:"action,omitempty"`
+	// ID is the ID of the app, service, deployment, build or node.
+	ID    string `json:"id,omitempty"`
+	AppID string `json:"app_id,omitempty"`
+	// OrgID and OwnerID are set on app events, which outlive deleted apps.
+	OrgID          string    `json:"org_id,omitempty"`
+	OwnerID        string    `json:"owner_id,omitempty"`
+	Name           string    `json:"name,omitempty"`
+	ServiceName    string    `json:"service_name,omitempty"`
+	DeploymentID   string    `json:"deployment_id,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// Type is the event's Server-Sent Events type, e.g. "deployment.updated".
+func (e *Event) Type() string {
+	if e.Action == "" {
+		return string(e.Kind)
+	}
+	return string(e.Kind) + "." + e.Action
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxEvidenceRange is the longest date range an evidence package covers.
+const MaxEvidenceRange = 366 * 24 * time.Hour
+
+// Validation errors for evidence exports.
+var (
+	ErrEvidenceRange    = errors.New("until must be after since")
+	ErrEvidenceRangeMax = errors.New("the date range cannot be longer than 366 days")
+)
+
+// EvidenceExport is an evidence package compiled for a compliance audit of
+// an app: its deployments over a date range with who started and approved
+// them, the audit entries, build provenance and access to its workloads. The
+// package is a gzipped tar archive whose manifest lists the SHA-256 of every
+// file and is signed by the control plane's key, identified by KeyID.
+type EvidenceExport struct {
+	ID    string    `json:"id"`
+	AppID string    `json:"app_id"`
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// OperationID is the operation that compiled the package
+	OperationID string `json:"operation_id,omitempty"`
+	// Counts is the number of records of each kind in the package
+	Counts    map[string]int `json:"counts"`
+	SHA256    string         `json:"sha256"`
+	SizeBytes int64          `json:"size_bytes"`
+	KeyID     string         `json:"key_id"`
+	CreatedBy string         `json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ValidateEvidenceRange checks the date range of an evidence package.
+func ValidateEvidenceRange(since, until time.Time) error {
+	if !until.After(since) {
+		return ErrEvidenceRange
+	}
+	if until.Sub(since) > MaxEvidenceRange {
+		return ErrEvidenceRangeMax
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Validation errors for federation peers.
+var (
+	ErrFederationPeerName   = errors.New("peer name is required")
+	ErrFederationPeerURL    = errors.New("peer URL must be an absolute http or https URL")
+	ErrFederationPeerAPIKey = errors.New("peer API key is required")
+)
+
+// FederationPeer is another Narvana instance whose apps, nodes and
+// deployments are shown read-only in this instance's federation view. URL is
+// the peer's API and WebURL its web UI, which rows link to for changes;
+// without a WebURL links go to URL. APIKey belongs to an admin of the peer
+// and is never returned by the API.
+type FederationPeer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	WebURL string `json:"web_url,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the peer's name, URLs and API key.
+func (p *FederationPeer) Validate() error {
+	if p.Name == "" {
+		return ErrFederationPeerName
+	}
+	if !isHTTPURL(p.URL) {
+		return fmt.Errorf("%w: url %q", ErrFederationPeerURL, p.URL)
+	}
+	if p.WebURL != "" && !isHTTPURL(p.WebURL) {
+		return fmt.Errorf("%w: web_url %q", ErrFederationPeerURL, p.WebURL)
+	}
+	if p.APIKey == "" {
+		return ErrFederationPeerAPIKey
+	}
+	return nil
+}
+
+// LinkBase returns the URL the peer's rows link to.
+func (p *FederationPeer) LinkBase() string {
+	if p.WebURL != "" {
+		return p.WebURL
+	}
+	return p.URL
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// FederatedApp is an app as listed in the federation view.
+type FederatedApp struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OrgID     string    `json:"org_id,omitempty"`
+	Services  int       `json:"services"`
+	CreatedAt time.Time `json:"created_at"`
+	Link      string    `json:"link,omitempty"`
+}
+
+// FederatedNode is a node as listed in the federation view.
+type FederatedNode struct {
+	ID            string     `json:"id"`
+	Hostname      string     `json:"hostname"`
+	Status        NodeStatus `json:"status"`
+	Healthy       bool       `json:"healthy"`
+	LastHeartbeat time.Time  `json:"last_heartbeat"`
+	Link          string     `json:"link,omitempty"`
+}
+
+// FederatedDeployment is an active deployment as listed in the federation view.
+type FederatedDeployment struct {
+	ID          string           `json:"id"`
+	AppID       string           `json:"app_id"`
+	AppName     string           `json:"app_name,omitempty"`
+	ServiceName string           `json:"service_name"`
+	Version     int              `json:"version"`
+	Status      DeploymentStatus `json:"status"`
+	NodeID      string           `json:"node_id,omitempty"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Link        string           `json:"link,omitempty"`
+}
+
+// FederationSummary is what an instance shares with the instances it is a
+// peer of: all its apps and nodes and its deployments that have not finished.
+type FederationSummary struct {
+	Apps        []FederatedApp        `json:"apps"`
+	Nodes       []FederatedNode       `json:"nodes"`
+	Deployments []FederatedDeployment `json:"deployments"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// FederatedInstance is one instance in the federation view. PeerID is empty
+// for the local instance. An instance that could not be reached has Error set
+// and an empty summary.
+type FederatedInstance struct {
+	PeerID  string            `json:"peer_id,omitempty"`
+	Name    string            `json:"name"`
+	WebURL  string            `json:"web_url,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Summary FederationSummary `json:"summary"`
+}
+
+// FederationView is the combined read-only view of this instance and its peers.
+type FederationView struct {
+	Instances []FederatedInstance `json:"instances"`
+}
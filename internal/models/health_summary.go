@@ -0,0 +1,80 @@
+package models
+
+// StateCounts counts resources by state.
+type StateCounts struct {
+	Total   int            `json:"total"`
+	ByState map[string]int `json:"by_state"`
+}
+
+// Add records n resources in the given state.
+func (c *StateCounts) Add(state string, n int) {
+	if c.ByState == nil {
+		c.ByState = make(map[string]int)
+	}
+	c.Total += n
+	c.ByState[state] += n
+}
+
+// HealthSummary is a compact health rollup of an organization, sized for
+// frequent dashboard polling.
+type HealthSummary struct {
+	Apps     StateCounts `json:"apps"`     // By AppHealthState
+	Services StateCounts `json:"services"` // By ServiceState
+	Nodes    StateCounts `json:"nodes"`    // "healthy" or "unhealthy"
+	Builds   StateCounts `json:"builds"`   // By BuildStatus, for builds created in the last 24 hours or still in progress
+	// NonReproducibleBuilds counts builds from the last 24 hours whose
+	// outputs differed when rebuilt for verification.
+	NonReproducibleBuilds int `json:"non_reproducible_builds"`
+	// ActiveAlerts counts conditions that need attention: failed services,
+	// unhealthy nodes and non-reproducible builds.
+	ActiveAlerts int `json:"active_alerts"`
+}
+
+// NewHealthSummary returns an empty summary whose state maps are non-nil.
+func NewHealthSummary() *HealthSummary {
+	return &HealthSummary{
+		Apps:     StateCounts{ByState: map[string]int{}},
+		Services: StateCounts{ByState: map[string]int{}},
+		Nodes:    StateCounts{ByState: map[string]int{}},
+		Builds:   StateCounts{ByState: map[string]int{}},
+	}
+}
+
+// Node health states used in HealthSummary.
+const (
+	NodeStateHealthy   = "healthy"
+	NodeStateUnhealthy = "unhealthy"
+)
+
+// AppHealthState summarizes the states of an app's services.
+type AppHealthState string
+
+const (
+	// AppHealthFailed indicates at least one service's last deployment failed.
+	AppHealthFailed AppHealthState = "failed"
+	// AppHealthDeploying indicates a deployment is in progress.
+	AppHealthDeploying AppHealthState = "deploying"
+	// AppHealthRunning indicates at least one service is running and none failed.
+	AppHealthRunning AppHealthState = "running"
+	// AppHealthIdle indicates no service is running.
+	AppHealthIdle AppHealthState = "idle"
+)
+
+// RollupAppHealth derives an app's health from its service states. The most
+// urgent state wins: failed, then deploying, then running.
+func RollupAppHealth(states []ServiceState) AppHealthState {
+	health := AppHealthIdle
+	for _, s := range states {
+		switch s {
+		case ServiceStateFailed:
+			return AppHealthFailed
+		case ServiceStateDeploying:
+			health = AppHealthDeploying
+		case ServiceStateRunning:
+			if health == AppHealthIdle {
+				health = AppHealthRunning
+			}
+		}
+	}
+	return health
+}
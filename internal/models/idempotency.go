@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey is a mutating request sent with an Idempotency-Key header
+// and, once it has been served, its response, which is replayed when the
+// request is retried with the same key.
+type IdempotencyKey struct {
+	UserID      string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string // SHA-256 of the method, URL and body
+	// Status is the response status, or zero while the request is in progress.
+	Status    int
+	Headers   map[string]string // Content-Type, Location and ETag of the response
+	Response  []byte
+	CreatedAt time.Time
+}
@@ -19,13 +19,17 @@ const (
 	InvitationStatusRevoked InvitationStatus = "revoked"
 )
 
-// Invitation represents an invitation to join the platform.
+// Invitation represents an invitation to join the platform. An invitation
+// with an OrgID also adds the invitee to that organization with OrgRole, and
+// may be accepted by an existing user signed in with the invited email.
 type Invitation struct {
 	ID         string           `json:"id"`
 	Email      string           `json:"email"`
 	Token      string           `json:"token"` // Unique token for accepting the invitation
 	InvitedBy  string           `json:"invited_by"`
 	Role       Role             `json:"role"`
+	OrgID      string           `json:"org_id,omitempty"`
+	OrgRole    Role             `json:"org_role,omitempty"`
 	Status     InvitationStatus `json:"status"`
 	ExpiresAt  time.Time        `json:"expires_at"`
 	AcceptedAt *time.Time       `json:"accepted_at,omitempty"`
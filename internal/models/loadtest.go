@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Limits and defaults of load tests.
+const (
+	MaxLoadTestRPS                 = 2000
+	DefaultLoadTestDurationSeconds = 30
+	MaxLoadTestDurationSeconds     = 600
+	DefaultLoadTestTimeoutSeconds  = 10
+)
+
+// LoadTestStatus is the state of a load test.
+type LoadTestStatus string
+
+const (
+	LoadTestPending   LoadTestStatus = "pending"
+	LoadTestRunning   LoadTestStatus = "running"
+	LoadTestCompleted LoadTestStatus = "completed"
+	LoadTestFailed    LoadTestStatus = "failed"
+)
+
+// Finished returns true if the status will not change again.
+func (s LoadTestStatus) Finished() bool {
+	return s == LoadTestCompleted || s == LoadTestFailed
+}
+
+// LoadTest is an on-demand run of requests at a constant rate against one
+// deployment of a service, made from a build worker. Its results are kept
+// with the deployment so releases can be compared before and after.
+type LoadTest struct {
+	ID           string `json:"id"`
+	AppID        string `json:"app_id"`
+	ServiceName  string `json:"service_name"`
+	DeploymentID string `json:"deployment_id"`
+	// WorkerID is the build worker that must run the test; any worker
+	// running load tests may claim it when empty
+	WorkerID string `json:"worker_id,omitempty"`
+
+	// Requests are sent to Path on the deployment's primary port
+	Method          string `json:"method"`
+	Path            string `json:"path"`
+	RPS             int    `json:"rps"`
+	DurationSeconds int    `json:"duration_seconds"`
+	TimeoutSeconds  int    `json:"timeout_seconds"` // Per request
+
+	Status LoadTestStatus  `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Result *LoadTestResult `json:"result,omitempty"`
+
+	// RunBy is the build worker that claimed the test
+	RunBy      string     `json:"run_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Normalize fills in the defaults of unset settings.
+func (t *LoadTest) Normalize() {
+	if t.Method == "" {
+		t.Method = http.MethodGet
+	}
+	if t.Path == "" {
+		t.Path = "/"
+	}
+	if t.DurationSeconds == 0 {
+		t.DurationSeconds = DefaultLoadTestDurationSeconds
+	}
+	if t.TimeoutSeconds == 0 {
+		t.TimeoutSeconds = DefaultLoadTestTimeoutSeconds
+	}
+}
+
+// Validate checks the test's settings.
+func (t *LoadTest) Validate() error {
+	switch t.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+	default:
+		return fmt.Errorf("method must be GET, HEAD or POST")
+	}
+	if !strings.HasPrefix(t.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	if t.RPS < 1 || t.RPS > MaxLoadTestRPS {
+		return fmt.Errorf("rps must be between 1 and %d", MaxLoadTestRPS)
+	}
+	if t.DurationSeconds < 1 || t.DurationSeconds > MaxLoadTestDurationSeconds {
+		return fmt.Errorf("duration_seconds must be between 1 and %d", MaxLoadTestDurationSeconds)
+	}
+	if t.TimeoutSeconds < 1 || t.TimeoutSeconds > t.DurationSeconds {
+		return fmt.Errorf("timeout_seconds must be between 1 and duration_seconds")
+	}
+	return nil
+}
+
+// Duration returns how long requests are sent for.
+func (t *LoadTest) Duration() time.Duration {
+	return time.Duration(t.DurationSeconds) * time.Second
+}
+
+// LoadTestResult summarizes the responses of a load test. Latencies are in
+// milliseconds; requests that failed without a response count as errors and
+// are left out of the latencies.
+type LoadTestResult struct {
+	Requests    int         `json:"requests"`
+	Errors      int         `json:"errors"` // Failed requests and non-2xx/3xx responses
+	ErrorRate   float64     `json:"error_rate"`
+	AchievedRPS float64     `json:"achieved_rps"` // Successful responses per second
+	LatencyP50  float64     `json:"latency_p50_ms"`
+	LatencyP90  float64     `json:"latency_p90_ms"`
+	LatencyP99  float64     `json:"latency_p99_ms"`
+	LatencyMax  float64     `json:"latency_max_ms"`
+	StatusCodes map[int]int `json:"status_codes"`
+	// ErrorSamples are the distinct transport errors seen, at most a few
+	ErrorSamples []string `json:"error_samples,omitempty"`
+}
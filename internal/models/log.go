@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // LogEntry represents a single log entry from a build or runtime.
 type LogEntry struct {
@@ -8,6 +14,74 @@ type LogEntry struct {
 	DeploymentID string    `json:"deployment_id"`
 	Source       string    `json:"source"` // "build" or "runtime"
 	Level        string    `json:"level"`
+	Stream       string    `json:"stream,omitempty"` // "stdout" or "stderr" for runtime logs
 	Message      string    `json:"message"`
 	Timestamp    time.Time `json:"timestamp"`
 }
+
+// LogFilter selects the log entries of an app. Zero fields match everything
+// but AppID, which is required.
+type LogFilter struct {
+	AppID        string
+	ServiceName  string
+	DeploymentID string
+	// Query is a full-text search of the messages in web search syntax,
+	// e.g. `timeout -health "connection refused"`
+	Query  string
+	Levels []string
+	Source string
+	Stream string
+	Since  time.Time
+	Until  time.Time
+	// Before continues a search after the last entry of a previous page
+	Before *LogCursor
+	// After returns the entries following a cursor, oldest first, to read
+	// entries as they are written
+	After *LogCursor
+	Limit  int
+}
+
+// LogCursor is the position of a log entry in newest-first order, used to
+// page through search results.
+type LogCursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+// CursorOf returns the cursor continuing after an entry.
+func CursorOf(entry *LogEntry) LogCursor {
+	return LogCursor{Timestamp: entry.Timestamp, ID: entry.ID}
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c LogCursor) String() string {
+	raw := strconv.FormatInt(c.Timestamp.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseLogCursor decodes a cursor returned by LogCursor.String.
+func ParseLogCursor(s string) (*LogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &LogCursor{Timestamp: time.Unix(0, n).UTC(), ID: id}, nil
+}
+
+// BuildLogChunk is a piece of a build's output, persisted while the build
+// runs. Chunks are numbered from 1 in the order they were written; a build
+// that is retried keeps appending to the same sequence.
+type BuildLogChunk struct {
+	BuildID   string    `json:"build_id"`
+	Seq       int       `json:"seq"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetricsResolution is the granularity at which container resource usage
+// samples are rolled up and stored.
+const MetricsResolution = time.Minute
+
+// MetricsRange is a time window the metrics API serves, and the step its
+// series is downsampled to.
+type MetricsRange struct {
+	Name     string
+	Duration time.Duration
+	Step     time.Duration
+}
+
+// MetricsRanges are the windows a service's metrics can be requested for.
+// Steps keep every series at 60 to 168 points.
+var MetricsRanges = []MetricsRange{
+	{Name: "15m", Duration: 15 * time.Minute, Step: MetricsResolution},
+	{Name: "1h", Duration: time.Hour, Step: MetricsResolution},
+	{Name: "6h", Duration: 6 * time.Hour, Step: 5 * time.Minute},
+	{Name: "24h", Duration: 24 * time.Hour, Step: 15 * time.Minute},
+	{Name: "7d", Duration: 7 * 24 * time.Hour, Step: time.Hour},
+}
+
+// ParseMetricsRange looks up a metrics range by name, e.g. "1h".
+func ParseMetricsRange(name string) (MetricsRange, error) {
+	names := make([]string, len(MetricsRanges))
+	for i, r := range MetricsRanges {
+		if r.Name == name {
+			return r, nil
+		}
+		names[i] = r.Name
+	}
+	return MetricsRange{}, fmt.Errorf("range must be one of %s", strings.Join(names, ", "))
+}
+
+// MetricSample is one container resource usage report from a node agent.
+// Network and request counters are cumulative for the deployment.
+type MetricSample struct {
+	DeploymentID   string
+	AppID          string
+	ServiceName    string
+	CPUPercent     float64
+	MemoryBytes    int64
+	NetworkRxBytes int64
+	NetworkTxBytes int64
+	Requests       int64
+	Errors         int64 // 5xx responses among Requests
+	LatencyMsSum   int64 // Total response time of Requests
+	SampledAt      time.Time
+}
+
+// MetricRollup aggregates one deployment's samples over a MetricsResolution
+// bucket. Sums are kept rather than averages so samples can be added as
+// they arrive; network and request counters hold the highest value seen in
+// the bucket.
+type MetricRollup struct {
+	DeploymentID   string
+	AppID          string
+	ServiceName    string
+	Bucket         time.Time
+	Samples        int
+	CPUPercentSum  float64
+	CPUPercentMax  float64
+	MemoryBytesSum int64
+	MemoryBytesMax int64
+	NetworkRxBytes int64
+	NetworkTxBytes int64
+	Requests       int64
+	Errors         int64
+	LatencyMsSum   int64
+}
+
+// MetricPoint is a service's resource usage over one step of a series,
+// summed across the deployments that reported in it.
+type MetricPoint struct {
+	Timestamp      time.Time `json:"timestamp"`
+	CPUPercent     float64   `json:"cpu_percent"`      // Average over the step
+	CPUPercentMax  float64   `json:"cpu_percent_max"`  // Sum of each deployment's highest sample
+	MemoryBytes    int64     `json:"memory_bytes"`     // Average over the step
+	MemoryBytesMax int64     `json:"memory_bytes_max"` // Sum of each deployment's highest sample
+	NetworkRxRate  float64   `json:"network_rx_bytes_per_second"`
+	NetworkTxRate  float64   `json:"network_tx_bytes_per_second"`
+	RequestRate    float64   `json:"requests_per_second"`
+	Deployments    int       `json:"deployments"`
+}
+
+// DownsampleMetrics merges rollups into points step apart starting at from.
+// Each deployment's rollups within a step are averaged, then deployments are
+// summed. Network and request rates are the counter increase since the deployment's
+// previous rollup, which may lie in an earlier step; a counter that went
+// backwards (the container restarted) counts from zero. Steps without data
+// are omitted.
+func DownsampleMetrics(rollups []*MetricRollup, from time.Time, step time.Duration) []MetricPoint {
+	sorted := make([]*MetricRollup, len(rollups))
+	copy(sorted, rollups)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Bucket.Before(sorted[j].Bucket) })
+
+	type counters struct{ rx, tx, requests int64 }
+	type usage struct {
+		samples        int
+		cpuSum, cpuMax float64
+		memSum, memMax int64
+		rx, tx         int64
+		requests       int64
+	}
+
+	last := make(map[string]counters)
+	steps := make(map[int64]map[string]*usage)
+	for _, r := range sorted {
+		if r.Bucket.Before(from) || r.Samples == 0 {
+			if r.Samples > 0 {
+				last[r.DeploymentID] = counters{rx: r.NetworkRxBytes, tx: r.NetworkTxBytes, requests: r.Requests}
+			}
+			continue
+		}
+		index := int64(r.Bucket.Sub(from) / step)
+		deployments := steps[index]
+		if deployments == nil {
+			deployments = make(map[string]*usage)
+			steps[index] = deployments
+		}
+		u := deployments[r.DeploymentID]
+		if u == nil {
+			u = &usage{}
+			deployments[r.DeploymentID] = u
+		}
+		u.samples += r.Samples
+		u.cpuSum += r.CPUPercentSum
+		u.cpuMax = max(u.cpuMax, r.CPUPercentMax)
+		u.memSum += r.MemoryBytesSum
+		u.memMax = max(u.memMax, r.MemoryBytesMax)
+
+		if prev, ok := last[r.DeploymentID]; ok {
+			u.rx += counterIncrease(prev.rx, r.NetworkRxBytes)
+			u.tx += counterIncrease(prev.tx, r.NetworkTxBytes)
+			u.requests += counterIncrease(prev.requests, r.Requests)
+		}
+		last[r.DeploymentID] = counters{rx: r.NetworkRxBytes, tx: r.NetworkTxBytes, requests: r.Requests}
+	}
+
+	indexes := make([]int64, 0, len(steps))
+	for i := range steps {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	points := make([]MetricPoint, 0, len(indexes))
+	for _, i := range indexes {
+		p := MetricPoint{Timestamp: from.Add(time.Duration(i) * step)}
+		for _, u := range steps[i] {
+			p.CPUPercent += u.cpuSum / float64(u.samples)
+			p.CPUPercentMax += u.cpuMax
+			p.MemoryBytes += u.memSum / int64(u.samples)
+			p.MemoryBytesMax += u.memMax
+			p.NetworkRxRate += float64(u.rx) / step.Seconds()
+			p.NetworkTxRate += float64(u.tx) / step.Seconds()
+			p.RequestRate += float64(u.requests) / step.Seconds()
+			p.Deployments++
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// counterIncrease returns how much a cumulative counter grew from prev to
+// cur, treating a decrease as a reset to zero.
+func counterIncrease(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
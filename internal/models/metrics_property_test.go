@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: service-metrics, Property 1: Downsampling Preserves Averages**
+// For any per-minute rollups of one deployment with constant usage, every
+// downsampled point SHALL report that usage regardless of step, and network
+// rates SHALL equal the counter's constant growth per second.
+
+func TestDownsampleMetrics(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	properties.Property("constant usage survives downsampling", prop.ForAll(
+		func(minutes, stepMinutes, cpu int, growth int64) bool {
+			step := time.Duration(stepMinutes) * time.Minute
+			// Start one minute early to seed the counter
+			var rollups []*MetricRollup
+			for i := -1; i < minutes; i++ {
+				rollups = append(rollups, &MetricRollup{
+					DeploymentID:   "d1",
+					Bucket:         from.Add(time.Duration(i) * time.Minute),
+					Samples:        2,
+					CPUPercentSum:  float64(2 * cpu),
+					CPUPercentMax:  float64(cpu),
+					MemoryBytesSum: 2048,
+					MemoryBytesMax: 1024,
+					NetworkRxBytes: int64(i+1) * growth,
+				})
+			}
+
+			points := DownsampleMetrics(rollups, from, step)
+			if len(points) != (minutes+stepMinutes-1)/stepMinutes {
+				return false
+			}
+			for i, p := range points {
+				if !p.Timestamp.Equal(from.Add(time.Duration(i)*step)) || p.Deployments != 1 {
+					return false
+				}
+				if p.CPUPercent != float64(cpu) || p.MemoryBytes != 1024 {
+					return false
+				}
+				full := i < len(points)-1 || minutes%stepMinutes == 0
+				if full && p.NetworkRxRate != float64(growth)/60 {
+					return false
+				}
+			}
+			return true
+		},
+		gen.IntRange(1, 180),
+		gen.IntRange(1, 60),
+		gen.IntRange(0, 400),
+		gen.Int64Range(0, 1<<30),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestDownsampleMetricsCounterReset(t *testing.T) {
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	rollups := []*MetricRollup{
+		{DeploymentID: "d1", Bucket: from, Samples: 1, NetworkTxBytes: 9000, Requests: 500},
+		{DeploymentID: "d1", Bucket: from.Add(time.Minute), Samples: 1, NetworkTxBytes: 600, Requests: 120},
+	}
+	points := DownsampleMetrics(rollups, from, time.Minute)
+	if len(points) != 2 || points[0].NetworkTxRate != 0 || points[1].NetworkTxRate != 10 || points[1].RequestRate != 2 {
+		t.Errorf("points = %+v, want the restart to count from zero", points)
+	}
+}
+
+func TestParseMetricsRange(t *testing.T) {
+	r, err := ParseMetricsRange("24h")
+	if err != nil || r.Duration != 24*time.Hour || r.Step != 15*time.Minute {
+		t.Errorf("ParseMetricsRange(24h) = %+v, %v", r, err)
+	}
+	if _, err := ParseMetricsRange("2h"); err == nil {
+		t.Error("ParseMetricsRange(2h) succeeded, want an error")
+	}
+}
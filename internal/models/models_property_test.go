@@ -1953,3 +1953,25 @@ func TestServiceConfigRoundTrip(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// TestServiceRuntimeValidation tests that only services building pure-nix
+// closures can select the systemd runtime.
+func TestServiceRuntimeValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		service ServiceConfig
+		wantErr bool
+	}{
+		{"git with systemd", ServiceConfig{Name: "api", GitRepo: "github.com/acme/api", Runtime: ServiceRuntimeSystemd}, false},
+		{"flake with container", ServiceConfig{Name: "api", FlakeURI: "github:acme/api", Runtime: ServiceRuntimeContainer}, false},
+		{"image with systemd", ServiceConfig{Name: "api", Image: "ghcr.io/acme/api:1", Runtime: ServiceRuntimeSystemd}, true},
+		{"dockerfile with systemd", ServiceConfig{Name: "api", GitRepo: "github.com/acme/api", BuildStrategy: BuildStrategyDockerfile, Runtime: ServiceRuntimeSystemd}, true},
+		{"unknown runtime", ServiceConfig{Name: "api", GitRepo: "github.com/acme/api", Runtime: "vm"}, true},
+	}
+	for _, tt := range tests {
+		err := tt.service.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // NodeResources represents the resource availability of a node.
 type NodeResources struct {
@@ -29,16 +32,252 @@ type NodeDiskMetrics struct {
 	ContainerStorage *DiskStats `json:"container_storage,omitempty"`
 }
 
-// Node represents a compute instance running the Narvana node agent, Podman, and Caddy.
+// NodeProvider is the backend that runs a node's deployments.
+type NodeProvider string
+
+const (
+	// NodeProviderPodman is a host running the Narvana node agent, Podman, and Caddy.
+	NodeProviderPodman NodeProvider = "podman"
+	// NodeProviderKubernetes is a Kubernetes cluster the control plane
+	// deploys to through its API server.
+	NodeProviderKubernetes NodeProvider = "kubernetes"
+	// NodeProviderLocal is the host of an all-in-one control plane, which
+	// runs deployments with its own Podman service.
+	NodeProviderLocal NodeProvider = "local"
+)
+
+// NodeCapability is a feature a node supports for the deployments placed on it.
+type NodeCapability string
+
+const (
+	NodeCapabilityNixClosures  NodeCapability = "nix-closures"  // Runs pure-nix build outputs
+	NodeCapabilityOCIImages    NodeCapability = "oci-images"    // Runs OCI images
+	NodeCapabilityEgressPolicy NodeCapability = "egress-policy" // Enforces egress policies
+	NodeCapabilityCronRuns     NodeCapability = "cron-runs"     // Runs one-off cron service commands
+	NodeCapabilityIngress      NodeCapability = "ingress"       // Routes service domains to deployments
+	NodeCapabilitySystemdUnits NodeCapability = "systemd-units" // Runs pure-nix closures as sandboxed systemd units
+)
+
+// PodmanCapabilities are the capabilities of nodes running the node agent.
+var PodmanCapabilities = []NodeCapability{
+	NodeCapabilityNixClosures,
+	NodeCapabilityOCIImages,
+	NodeCapabilityEgressPolicy,
+	NodeCapabilityCronRuns,
+	NodeCapabilityIngress,
+}
+
+// NodeStatus is where a node is in its lifecycle.
+type NodeStatus string
+
+const (
+	NodeStatusPending  NodeStatus = "pending"  // Joined with a join token, awaiting approval
+	NodeStatusActive   NodeStatus = "active"   // Receives new deployments
+	NodeStatusRejected NodeStatus = "rejected" // Denied by an admin; its agent is refused
+	NodeStatusCordoned NodeStatus = "cordoned" // Keeps its deployments but receives no new ones
+	NodeStatusDraining NodeStatus = "draining" // Its deployments are being moved to other nodes
+)
+
+// IsValid reports whether the status is a known node status.
+func (s NodeStatus) IsValid() bool {
+	switch s {
+	case NodeStatusPending, NodeStatusActive, NodeStatusRejected, NodeStatusCordoned, NodeStatusDraining:
+		return true
+	}
+	return false
+}
+
+// Node represents a compute instance that runs deployments. Most nodes run
+// the Narvana node agent, Podman, and Caddy; a Kubernetes cluster is
+// registered as a single node of its own pool.
 type Node struct {
 	ID            string           `json:"id"`
 	Hostname      string           `json:"hostname"`
 	Address       string           `json:"address"`
 	GRPCPort      int              `json:"grpc_port"`
 	Healthy       bool             `json:"healthy"`
+	Status        NodeStatus       `json:"status"`
+	Provider      NodeProvider     `json:"provider"`
+	Pool          string           `json:"pool,omitempty"`
+	Capabilities  []NodeCapability `json:"capabilities,omitempty"`
 	Resources     *NodeResources   `json:"resources"`
 	DiskMetrics   *NodeDiskMetrics `json:"disk_metrics,omitempty"`
 	CachedPaths   []string         `json:"cached_paths,omitempty"`
+	HealthScore   int              `json:"health_score"`
+	Load          *NodeLoad        `json:"load,omitempty"`
 	LastHeartbeat time.Time        `json:"last_heartbeat"`
 	RegisteredAt  time.Time        `json:"registered_at"`
+	// ClockSkewMs is how far the node's clock was behind the control plane's
+	// at its last heartbeat, negative when ahead
+	ClockSkewMs          int64         `json:"clock_skew_ms"`
+	ClockStatus          NodeCondition `json:"clock_status"`
+	CertificateExpiresAt *time.Time    `json:"certificate_expires_at,omitempty"`
+	CertificateStatus    NodeCondition `json:"certificate_status,omitempty"`
+}
+
+// NodeCondition rates a node's clock or agent certificate.
+type NodeCondition string
+
+const (
+	NodeConditionOK       NodeCondition = "ok"
+	NodeConditionWarning  NodeCondition = "warning"
+	NodeConditionCritical NodeCondition = "critical"
+)
+
+// Worse reports whether c needs more attention than other.
+func (c NodeCondition) Worse(other NodeCondition) bool {
+	rank := map[NodeCondition]int{NodeConditionWarning: 1, NodeConditionCritical: 2}
+	return rank[c] > rank[other]
+}
+
+// Thresholds for node clocks and agent certificates. Tokens and TLS
+// certificates are validated against the node's clock, so a node whose clock
+// is minutes off rejects valid ones.
+const (
+	ClockSkewWarning          = 30 * time.Second
+	ClockSkewCritical         = 5 * time.Minute
+	CertificateExpiryWarning  = 14 * 24 * time.Hour
+	CertificateExpiryCritical = 3 * 24 * time.Hour
+)
+
+// ClockSkew returns how far the node's clock was behind at its last heartbeat.
+func (n *Node) ClockSkew() time.Duration {
+	return time.Duration(n.ClockSkewMs) * time.Millisecond
+}
+
+// UpdateConditions rates the node's clock skew and, if its agent reported
+// one, the expiry of its certificate as of now.
+func (n *Node) UpdateConditions(now time.Time) {
+	switch skew := n.ClockSkew().Abs(); {
+	case skew >= ClockSkewCritical:
+		n.ClockStatus = NodeConditionCritical
+	case skew >= ClockSkewWarning:
+		n.ClockStatus = NodeConditionWarning
+	default:
+		n.ClockStatus = NodeConditionOK
+	}
+
+	n.CertificateStatus = ""
+	if n.CertificateExpiresAt == nil {
+		return
+	}
+	switch left := n.CertificateExpiresAt.Sub(now); {
+	case left < CertificateExpiryCritical:
+		n.CertificateStatus = NodeConditionCritical
+	case left < CertificateExpiryWarning:
+		n.CertificateStatus = NodeConditionWarning
+	default:
+		n.CertificateStatus = NodeConditionOK
+	}
+}
+
+// NodeLoad holds a node's load averages as reported by its agent.
+type NodeLoad struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// NodeHealthScore rates a node's headroom from 0 (saturated) to 100 (idle).
+// CPU, memory and disk usage and the 1-minute load per CPU each cost up to
+// 25 points; metrics the node did not report cost nothing.
+func NodeHealthScore(resources *NodeResources, load *NodeLoad) int {
+	if resources == nil {
+		return 100
+	}
+	used := usedFraction(resources.CPUTotal, resources.CPUAvailable) +
+		usedFraction(float64(resources.MemoryTotal), float64(resources.MemoryAvailable)) +
+		usedFraction(float64(resources.DiskTotal), float64(resources.DiskAvailable))
+	if load != nil && resources.CPUTotal > 0 {
+		used += math.Max(0, math.Min(1, load.Load1/resources.CPUTotal))
+	}
+	return int(math.Round(100 - 25*used))
+}
+
+// usedFraction returns the share of total that is not available, clamped to [0, 1].
+func usedFraction(total, available float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (total-available)/total))
+}
+
+// NodeHealthEvent records a node becoming healthy or unhealthy.
+type NodeHealthEvent struct {
+	ID          string    `json:"id"`
+	NodeID      string    `json:"node_id"`
+	Healthy     bool      `json:"healthy"`
+	HealthScore int       `json:"health_score"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Schedulable reports whether new deployments may be placed on the node.
+// Nodes registered before statuses were tracked are active.
+func (n *Node) Schedulable() bool {
+	return n.Status == "" || n.Status == NodeStatusActive
+}
+
+// NodeJoinToken is a credential that lets a new node register itself. Nodes
+// that join with one wait in NodeStatusPending until an admin approves them.
+// Only a hash of the token is stored; TokenPrefix identifies it in the UI.
+type NodeJoinToken struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Supports reports whether the node has every given capability. Agent nodes
+// that have not reported capabilities have PodmanCapabilities.
+func (n *Node) Supports(required ...NodeCapability) bool {
+	capabilities := n.Capabilities
+	if capabilities == nil && (n.Provider == "" || n.Provider == NodeProviderPodman) {
+		capabilities = PodmanCapabilities
+	}
+	for _, c := range required {
+		found := false
+		for _, have := range capabilities {
+			if have == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// RequiredCapabilities returns the node capabilities needed to run a deployment.
+func (d *Deployment) RequiredCapabilities() []NodeCapability {
+	var required []NodeCapability
+	if d.BuildType == BuildTypePureNix {
+		required = append(required, NodeCapabilityNixClosures)
+	} else {
+		required = append(required, NodeCapabilityOCIImages)
+	}
+	if d.Runtime() == ServiceRuntimeSystemd {
+		required = append(required, NodeCapabilitySystemdUnits)
+	}
+	if d.IsCronRun() {
+		required = append(required, NodeCapabilityCronRuns)
+	}
+	if d.Config != nil && d.Config.Egress.Restricts() {
+		required = append(required, NodeCapabilityEgressPolicy)
+	}
+	return required
+}
+
+// Runtime returns the driver that runs the deployment. Only pure-nix
+// releases can run as systemd units.
+func (d *Deployment) Runtime() ServiceRuntime {
+	if d.BuildType == BuildTypePureNix && d.Config != nil && d.Config.Runtime == ServiceRuntimeSystemd {
+		return ServiceRuntimeSystemd
+	}
+	return ServiceRuntimeContainer
 }
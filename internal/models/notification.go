@@ -0,0 +1,203 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NotificationProviderType identifies a notification delivery channel.
+type NotificationProviderType string
+
+const (
+	NotificationProviderSlack    NotificationProviderType = "slack"
+	NotificationProviderDiscord  NotificationProviderType = "discord"
+	NotificationProviderTelegram NotificationProviderType = "telegram"
+	NotificationProviderEmail    NotificationProviderType = "email"
+	NotificationProviderGotify   NotificationProviderType = "gotify"
+	NotificationProviderWebhook  NotificationProviderType = "webhook"
+)
+
+// NotificationProviderTypes lists every supported provider type in display order.
+var NotificationProviderTypes = []NotificationProviderType{
+	NotificationProviderSlack,
+	NotificationProviderDiscord,
+	NotificationProviderTelegram,
+	NotificationProviderEmail,
+	NotificationProviderGotify,
+	NotificationProviderWebhook,
+}
+
+// IsValid reports whether t is a supported provider type.
+func (t NotificationProviderType) IsValid() bool {
+	for _, known := range NotificationProviderTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationEventType is a platform event that can trigger a notification.
+type NotificationEventType string
+
+const (
+	NotificationBuildSucceeded   NotificationEventType = "build.succeeded"
+	NotificationBuildFailed      NotificationEventType = "build.failed"
+	NotificationDeploymentLive   NotificationEventType = "deployment.running"
+	NotificationDeploymentFailed NotificationEventType = "deployment.failed"
+	NotificationNodeClockSkew    NotificationEventType = "node.clock_skew"
+	NotificationNodeCertificate  NotificationEventType = "node.certificate_expiring"
+	NotificationAppDrift         NotificationEventType = "app.drift"
+	NotificationTest             NotificationEventType = "test"
+)
+
+// NotificationEventTypes lists the event types providers can subscribe to.
+var NotificationEventTypes = []NotificationEventType{
+	NotificationBuildSucceeded,
+	NotificationBuildFailed,
+	NotificationDeploymentLive,
+	NotificationDeploymentFailed,
+	NotificationNodeClockSkew,
+	NotificationNodeCertificate,
+	NotificationAppDrift,
+}
+
+// IsValid reports whether t is an event type providers can subscribe to.
+func (t NotificationEventType) IsValid() bool {
+	for _, known := range NotificationEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Validation errors for notification providers.
+var (
+	ErrNotificationProviderType  = errors.New("unknown notification provider type")
+	ErrNotificationEventType     = errors.New("unknown notification event type")
+	ErrNotificationProviderName  = errors.New("notification provider name is required")
+	ErrNotificationConfigMissing = errors.New("notification provider config is incomplete")
+)
+
+// NotificationProvider is a configured delivery channel. Events lists the
+// event types it receives; an empty list subscribes to all of them.
+type NotificationProvider struct {
+	ID             string                   `json:"id"`
+	Type           NotificationProviderType `json:"type"`
+	Name           string                   `json:"name"`
+	Enabled        bool                     `json:"enabled"`
+	Config         map[string]string        `json:"config"`
+	Events         []NotificationEventType  `json:"events"`
+	LastDeliveryAt *time.Time               `json:"last_delivery_at,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// Validate checks the provider's type, name and event subscriptions. Config
+// fields are checked by the provider implementation.
+func (p *NotificationProvider) Validate() error {
+	if !p.Type.IsValid() {
+		return fmt.Errorf("%w: %q", ErrNotificationProviderType, p.Type)
+	}
+	if p.Name == "" {
+		return ErrNotificationProviderName
+	}
+	for _, e := range p.Events {
+		if !e.IsValid() {
+			return fmt.Errorf("%w: %q", ErrNotificationEventType, e)
+		}
+	}
+	return nil
+}
+
+// Subscribes reports whether the provider receives events of type t.
+func (p *NotificationProvider) Subscribes(t NotificationEventType) bool {
+	if len(p.Events) == 0 {
+		return true
+	}
+	for _, e := range p.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationEvent describes a build or deployment status change, or a
+// node that needs attention.
+type NotificationEvent struct {
+	Type         NotificationEventType `json:"type"`
+	AppID        string                `json:"app_id,omitempty"`
+	AppName      string                `json:"app_name,omitempty"`
+	ServiceName  string                `json:"service_name,omitempty"`
+	DeploymentID string                `json:"deployment_id,omitempty"`
+	BuildID      string                `json:"build_id,omitempty"`
+	GitRef       string                `json:"git_ref,omitempty"`
+	NodeID       string                `json:"node_id,omitempty"`
+	NodeName     string                `json:"node_name,omitempty"`
+	Message      string                `json:"message"`
+	Failure      *BuildFailure         `json:"failure,omitempty"` // Set for build.failed
+	OccurredAt   time.Time             `json:"occurred_at"`
+}
+
+// Title returns a one-line summary of the event, e.g. "Build failed: shop/api".
+func (e *NotificationEvent) Title() string {
+	var what string
+	switch e.Type {
+	case NotificationBuildSucceeded:
+		what = "Build succeeded"
+	case NotificationBuildFailed:
+		what = "Build failed"
+	case NotificationDeploymentLive:
+		what = "Deployment live"
+	case NotificationDeploymentFailed:
+		what = "Deployment failed"
+	case NotificationNodeClockSkew:
+		what = "Node clock skewed"
+	case NotificationNodeCertificate:
+		what = "Node certificate expiring"
+	case NotificationAppDrift:
+		what = "App drifted from its manifest"
+	default:
+		what = "Test notification"
+	}
+	target := e.AppName
+	if target == "" {
+		target = e.AppID
+	}
+	if e.ServiceName != "" {
+		target += "/" + e.ServiceName
+	}
+	if target == "" {
+		target = e.NodeName
+	}
+	if target == "" {
+		return what
+	}
+	return what + ": " + target
+}
+
+// NotificationDeliveryStatus is the state of a queued notification.
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending   NotificationDeliveryStatus = "pending"
+	NotificationDeliveryDelivered NotificationDeliveryStatus = "delivered"
+	NotificationDeliveryFailed    NotificationDeliveryStatus = "failed"
+)
+
+// NotificationDelivery is a queued send of one event to one provider.
+type NotificationDelivery struct {
+	ID            string                     `json:"id"`
+	ProviderID    string                     `json:"provider_id"`
+	Event         NotificationEvent          `json:"event"`
+	Status        NotificationDeliveryStatus `json:"status"`
+	Attempts      int                        `json:"attempts"`
+	NextAttemptAt time.Time                  `json:"next_attempt_at"`
+	LastError     string                     `json:"last_error,omitempty"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	DeliveredAt   *time.Time                 `json:"delivered_at,omitempty"`
+}
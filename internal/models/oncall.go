@@ -0,0 +1,199 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OnCallProvider is the incident management service holding an on-call schedule.
+type OnCallProvider string
+
+const (
+	OnCallProviderPagerDuty OnCallProvider = "pagerduty"
+	OnCallProviderOpsgenie  OnCallProvider = "opsgenie"
+)
+
+// OnCallRequirement is what an on-call gate asks of deploys outside business hours.
+type OnCallRequirement string
+
+const (
+	// OnCallRequireOnCall lets only the person currently on call deploy.
+	OnCallRequireOnCall OnCallRequirement = "on_call"
+	// OnCallRequireAck also lets others deploy once the person on call has
+	// acknowledged their request.
+	OnCallRequireAck OnCallRequirement = "on_call_ack"
+)
+
+// OnCallAckTTL is how long an on-call acknowledgement request waits for an
+// answer, and how long an approved one allows deploys.
+const OnCallAckTTL = time.Hour
+
+// MaxOnCallAckReasonLength is the maximum length of an on-call acknowledgement reason.
+const MaxOnCallAckReasonLength = 500
+
+// DefaultBusinessDays are the business days of gates that set none.
+var DefaultBusinessDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+
+// OnCallGate requires deploys to the apps it matches outside business hours
+// to be made, or acknowledged, by whoever is on call in a PagerDuty or
+// Opsgenie schedule. Match is an admission expression over app, such as
+// app.name.endsWith("-production"); an empty match covers every app in the
+// org, so a gate can be set up per environment.
+type OnCallGate struct {
+	ID            string            `json:"id"`
+	OrgID         string            `json:"org_id"`
+	Name          string            `json:"name"`
+	Match         string            `json:"match,omitempty"`
+	Provider      OnCallProvider    `json:"provider"`
+	ScheduleID    string            `json:"schedule_id"`
+	APIToken      string            `json:"-"`
+	Requirement   OnCallRequirement `json:"requirement"`
+	BusinessDays  []string          `json:"business_days"`
+	BusinessStart string            `json:"business_start"` // HH:MM
+	BusinessEnd   string            `json:"business_end"`   // HH:MM
+	Timezone      string            `json:"timezone,omitempty"`
+	Enabled       bool              `json:"enabled"`
+	CreatedBy     string            `json:"created_by"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// Validate checks the gate's provider and business hours and fills in
+// defaults: weekdays, 09:00 to 17:00 and the on_call requirement.
+func (g *OnCallGate) Validate() error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(g.Name) > 100 {
+		return errors.New("name must be 100 characters or fewer")
+	}
+	switch g.Provider {
+	case OnCallProviderPagerDuty, OnCallProviderOpsgenie:
+	default:
+		return errors.New("provider must be one of: pagerduty, opsgenie")
+	}
+	g.ScheduleID = strings.TrimSpace(g.ScheduleID)
+	if g.ScheduleID == "" {
+		return errors.New("schedule_id is required")
+	}
+	if g.APIToken == "" {
+		return errors.New("api_token is required")
+	}
+	switch g.Requirement {
+	case "":
+		g.Requirement = OnCallRequireOnCall
+	case OnCallRequireOnCall, OnCallRequireAck:
+	default:
+		return errors.New("requirement must be one of: on_call, on_call_ack")
+	}
+
+	if len(g.BusinessDays) == 0 {
+		g.BusinessDays = append([]string(nil), DefaultBusinessDays...)
+	}
+	for i, day := range g.BusinessDays {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("invalid business day %q", day)
+		}
+		g.BusinessDays[i] = day
+	}
+	if g.BusinessStart == "" {
+		g.BusinessStart = "09:00"
+	}
+	if g.BusinessEnd == "" {
+		g.BusinessEnd = "17:00"
+	}
+	start, err := time.Parse("15:04", g.BusinessStart)
+	if err != nil {
+		return fmt.Errorf("invalid business_start %q, expected HH:MM", g.BusinessStart)
+	}
+	end, err := time.Parse("15:04", g.BusinessEnd)
+	if err != nil {
+		return fmt.Errorf("invalid business_end %q, expected HH:MM", g.BusinessEnd)
+	}
+	if !end.After(start) {
+		return errors.New("business_end must be after business_start")
+	}
+	if _, err := g.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q", g.Timezone)
+	}
+	return nil
+}
+
+// InBusinessHours returns true if deploys at the given time are within the
+// gate's business hours and need no on-call approval.
+func (g *OnCallGate) InBusinessHours(now time.Time) bool {
+	loc, err := g.location()
+	if err != nil {
+		return false
+	}
+	local := now.In(loc)
+	businessDay := false
+	for _, day := range g.BusinessDays {
+		if weekdays[day] == local.Weekday() {
+			businessDay = true
+			break
+		}
+	}
+	if !businessDay {
+		return false
+	}
+	start, err := time.Parse("15:04", g.BusinessStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", g.BusinessEnd)
+	if err != nil {
+		return false
+	}
+	m := local.Hour()*60 + local.Minute()
+	return m >= start.Hour()*60+start.Minute() && m < end.Hour()*60+end.Minute()
+}
+
+// location returns the gate's time zone, defaulting to UTC.
+func (g *OnCallGate) location() (*time.Location, error) {
+	if g.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(g.Timezone)
+}
+
+// OnCallAckStatus is the state of an on-call acknowledgement request.
+type OnCallAckStatus string
+
+const (
+	OnCallAckPending  OnCallAckStatus = "pending"
+	OnCallAckApproved OnCallAckStatus = "approved"
+	OnCallAckRejected OnCallAckStatus = "rejected"
+)
+
+// OnCallAck is a request to deploy an app outside business hours, answered
+// by whoever is on call for the gate's schedule. Once approved it lets the
+// requester deploy the app until ExpiresAt.
+type OnCallAck struct {
+	ID          string          `json:"id"`
+	OrgID       string          `json:"org_id"`
+	GateID      string          `json:"gate_id"`
+	AppID       string          `json:"app_id"`
+	RequestedBy string          `json:"requested_by"`
+	Reason      string          `json:"reason"`
+	Status      OnCallAckStatus `json:"status"`
+	DecidedBy   string          `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time      `json:"decided_at,omitempty"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Expired returns true if the request can no longer be answered or used.
+func (a *OnCallAck) Expired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
+
+// Allows returns true if the acknowledgement lets a user deploy an app under a gate.
+func (a *OnCallAck) Allows(gateID, appID, userID string, now time.Time) bool {
+	return a.Status == OnCallAckApproved && !a.Expired(now) &&
+		a.GateID == gateID && a.AppID == appID && a.RequestedBy == userID
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// OperationKind is the kind of long-running operation.
+type OperationKind string
+
+const (
+	OperationNodeDrain          OperationKind = "node.drain"
+	OperationCleanupContainers  OperationKind = "cleanup.containers"
+	OperationCleanupImages      OperationKind = "cleanup.images"
+	OperationNixGC              OperationKind = "cleanup.nix_gc"
+	OperationArchiveDeployments OperationKind = "cleanup.deployments"
+	OperationCleanupAttic       OperationKind = "cleanup.attic"
+	OperationEvidenceExport     OperationKind = "evidence.export"
+)
+
+// OperationStatus is the state of an operation.
+type OperationStatus string
+
+const (
+	OperationRunning   OperationStatus = "running"
+	OperationSucceeded OperationStatus = "succeeded"
+	OperationFailed    OperationStatus = "failed"
+)
+
+// Finished returns true if the status will not change again.
+func (s OperationStatus) Finished() bool {
+	return s == OperationSucceeded || s == OperationFailed
+}
+
+// Operation is a long-running action, such as draining a node or garbage
+// collecting the Nix stores, that the endpoint starting it returns right
+// away. Its progress is polled at /v1/operations/{id} or streamed.
+type Operation struct {
+	ID     string          `json:"id"`
+	Kind   OperationKind   `json:"kind"`
+	Status OperationStatus `json:"status"`
+	// TargetID is the resource operated on, e.g. the drained node
+	TargetID string `json:"target_id,omitempty"`
+	// Progress is the percentage done, 0 to 100
+	Progress int    `json:"progress"`
+	Stage    string `json:"stage,omitempty"`
+	// Errors lists problems that did not stop the operation, e.g. a node
+	// that could not be garbage collected; Error is the one that did
+	Errors []string `json:"errors"`
+	Error  string   `json:"error,omitempty"`
+	// Result holds kind-specific totals, e.g. items_removed
+	Result     map[string]any `json:"result,omitempty"`
+	CreatedBy  string         `json:"created_by,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
@@ -12,10 +12,35 @@ import (
 type Role string
 
 const (
-	RoleOwner  Role = "owner"  // Full access, can invite users
-	RoleMember Role = "member" // Standard access, no admin functions
+	RoleOwner     Role = "owner"     // Full access, can delete the org and appoint owners
+	RoleAdmin     Role = "admin"     // Manages members, invitations and org settings
+	RoleDeveloper Role = "developer" // Creates, changes and deploys apps
+	RoleViewer    Role = "viewer"    // Read-only access
+	RoleMember    Role = "member"    // Legacy role, equivalent to developer
 )
 
+// roleRanks orders org roles by privilege.
+var roleRanks = map[Role]int{
+	RoleViewer:    1,
+	RoleDeveloper: 2,
+	RoleMember:    2,
+	RoleAdmin:     3,
+	RoleOwner:     4,
+}
+
+// Valid reports whether the role is a known org role.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether the role grants at least the privileges of min.
+// Unknown roles grant nothing.
+func (r Role) AtLeast(min Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[min]
+}
+
 // Organization represents a top-level grouping for all resources.
 type Organization struct {
 	ID          string    `json:"id"`
@@ -31,7 +56,7 @@ type Organization struct {
 type OrgMembership struct {
 	OrgID     string    `json:"org_id"`
 	UserID    string    `json:"user_id"`
-	Role      Role      `json:"role"` // owner or member
+	Role      Role      `json:"role"` // owner, admin, developer, viewer or member
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -44,6 +69,7 @@ var (
 	ErrOrgSlugInvalid  = errors.New("organization slug must contain only lowercase letters, numbers, and hyphens")
 	ErrOrgSlugStartEnd = errors.New("organization slug must start and end with a letter or number")
 	ErrLastOrgDelete   = errors.New("cannot delete the last organization")
+	ErrInvalidOrgRole  = errors.New("role must be one of: owner, admin, developer, viewer")
 )
 
 // slugPattern matches valid slug characters: lowercase letters, numbers, and hyphens.
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// OrgSecretScope says which of an org's apps a shared secret is injected into.
+type OrgSecretScope string
+
+const (
+	// OrgSecretScopeAll shares the secret into every app in the org,
+	// including apps created later.
+	OrgSecretScopeAll OrgSecretScope = "all"
+	// OrgSecretScopeSelected shares the secret only into the listed apps.
+	OrgSecretScopeSelected OrgSecretScope = "selected"
+)
+
+// OrgSecret is a secret managed once at the org level and shared into some or
+// all of the org's apps, e.g. a registry token or an APM key. Services see it
+// as an env var below app secrets, so an app secret with the same key
+// overrides it for that app.
+type OrgSecret struct {
+	ID             string         `json:"id"`
+	OrgID          string         `json:"org_id"`
+	Key            string         `json:"key"`
+	EncryptedValue []byte         `json:"-"`
+	Description    string         `json:"description,omitempty"`
+	Scope          OrgSecretScope `json:"scope"`
+	AppIDs         []string       `json:"app_ids,omitempty"` // Apps shared into when Scope is selected
+	CreatedBy      string         `json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Validate checks the secret's fields and applies defaults. Keys are
+// upper-cased like app secrets.
+func (s *OrgSecret) Validate() error {
+	s.Key = strings.ToUpper(strings.TrimSpace(s.Key))
+	if s.Key == "" {
+		return errors.New("key is required")
+	}
+	for _, c := range s.Key {
+		if !((c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
+			return errors.New("key must contain only alphanumeric characters and underscores")
+		}
+	}
+	if len(s.Description) > 500 {
+		return errors.New("description must be 500 characters or fewer")
+	}
+	switch s.Scope {
+	case "":
+		s.Scope = OrgSecretScopeSelected
+	case OrgSecretScopeAll:
+		s.AppIDs = nil
+	case OrgSecretScopeSelected:
+	default:
+		return errors.New("scope must be one of: all, selected")
+	}
+	return nil
+}
+
+// SharedWith reports whether the secret is shared into an app of its org.
+func (s *OrgSecret) SharedWith(appID string) bool {
+	if s.Scope == OrgSecretScopeAll {
+		return true
+	}
+	for _, id := range s.AppIDs {
+		if id == appID {
+			return true
+		}
+	}
+	return false
+}
+
+// OrgSecretUsage records that a shared secret was injected into a service
+// when it was last deployed.
+type OrgSecretUsage struct {
+	SecretID    string    `json:"secret_id"`
+	AppID       string    `json:"app_id"`
+	ServiceName string    `json:"service_name"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Limits on a promotion policy's evaluation window.
+const (
+	MinPromotionWindowMinutes = 1
+	MaxPromotionWindowMinutes = 7 * 24 * 60
+)
+
+// Validation errors for promotion policies.
+var (
+	ErrPromotionPolicyService   = errors.New("source and target service names are required")
+	ErrPromotionPolicyTarget    = errors.New("target app is required")
+	ErrPromotionPolicySelf      = errors.New("a service cannot be promoted into itself")
+	ErrPromotionPolicyWindow    = errors.New("window_minutes must be between 1 and 10080")
+	ErrPromotionPolicyErrorRate = errors.New("max_error_rate must be between 0 and 1")
+)
+
+// PromotionPolicy promotes a service's artifact from one app to another, e.g.
+// from a staging app to a production app, once a deployment has run healthy
+// for WindowMinutes with a runtime error rate below MaxErrorRate.
+type PromotionPolicy struct {
+	ID                string    `json:"id"`
+	AppID             string    `json:"app_id"`
+	ServiceName       string    `json:"service_name"`
+	TargetAppID       string    `json:"target_app_id"`
+	TargetServiceName string    `json:"target_service_name"`
+	WindowMinutes     int       `json:"window_minutes"`
+	MaxErrorRate      float64   `json:"max_error_rate"`
+	RequireApproval   bool      `json:"require_approval"`
+	Enabled           bool      `json:"enabled"`
+	CreatedBy         string    `json:"created_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Validate checks the policy's services and criteria. TargetServiceName
+// defaults to ServiceName when empty.
+func (p *PromotionPolicy) Validate() error {
+	p.ServiceName = strings.TrimSpace(p.ServiceName)
+	p.TargetServiceName = strings.TrimSpace(p.TargetServiceName)
+	if p.TargetServiceName == "" {
+		p.TargetServiceName = p.ServiceName
+	}
+	if p.ServiceName == "" {
+		return ErrPromotionPolicyService
+	}
+	if p.TargetAppID == "" {
+		return ErrPromotionPolicyTarget
+	}
+	if p.TargetAppID == p.AppID && p.TargetServiceName == p.ServiceName {
+		return ErrPromotionPolicySelf
+	}
+	if p.WindowMinutes < MinPromotionWindowMinutes || p.WindowMinutes > MaxPromotionWindowMinutes {
+		return ErrPromotionPolicyWindow
+	}
+	if p.MaxErrorRate < 0 || p.MaxErrorRate > 1 {
+		return ErrPromotionPolicyErrorRate
+	}
+	return nil
+}
+
+// Window returns the policy's evaluation window as a duration.
+func (p *PromotionPolicy) Window() time.Duration {
+	return time.Duration(p.WindowMinutes) * time.Minute
+}
+
+// PromotionStatus is the state of a promotion evaluation.
+type PromotionStatus string
+
+const (
+	// PromotionEvaluating means the source deployment is inside its evaluation window.
+	PromotionEvaluating PromotionStatus = "evaluating"
+	// PromotionAwaitingApproval means the criteria were met and an owner must approve.
+	PromotionAwaitingApproval PromotionStatus = "awaiting_approval"
+	// PromotionBlocked means the criteria were met but the target is in a
+	// deploy freeze; promotion is retried once the freeze ends.
+	PromotionBlocked PromotionStatus = "blocked"
+	// PromotionPromoted means a deployment of the artifact was created in the target.
+	PromotionPromoted PromotionStatus = "promoted"
+	// PromotionFailed means the source deployment did not meet the criteria.
+	PromotionFailed PromotionStatus = "failed"
+	// PromotionRejected means an owner declined the promotion.
+	PromotionRejected PromotionStatus = "rejected"
+)
+
+// IsTerminal reports whether the promotion will not change state again.
+func (s PromotionStatus) IsTerminal() bool {
+	return s == PromotionPromoted || s == PromotionFailed || s == PromotionRejected
+}
+
+// Promotion records the evaluation of one source deployment against a
+// policy, or a request to promote it into the next environment of its app's
+// pipeline. A policy's criteria are copied when evaluation starts so later
+// policy edits do not change how an in-flight deployment is judged; requested
+// promotions have no evaluation window.
+type Promotion struct {
+	ID                 string          `json:"id"`
+	PolicyID           string          `json:"policy_id,omitempty"`
+	EnvironmentID      string          `json:"environment_id,omitempty"`
+	SourceDeploymentID string          `json:"source_deployment_id"`
+	TargetDeploymentID string          `json:"target_deployment_id,omitempty"`
+	Status             PromotionStatus `json:"status"`
+	WindowMinutes      int             `json:"window_minutes"`
+	MaxErrorRate       float64         `json:"max_error_rate"`
+	WindowEndsAt       time.Time       `json:"window_ends_at"`
+	ErrorRate          float64         `json:"error_rate"`
+	LogLines           int             `json:"log_lines"`
+	Reason             string          `json:"reason,omitempty"`
+	RequestedBy        string          `json:"requested_by,omitempty"`
+	DecidedBy          string          `json:"decided_by,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// ErrorRate returns the share of runtime log entries at error level, and the
+// number of entries considered. Entries before since are ignored.
+func ErrorRate(entries []*LogEntry, since time.Time) (float64, int) {
+	total, errs := 0, 0
+	for _, e := range entries {
+		if e.Timestamp.Before(since) {
+			continue
+		}
+		total++
+		if strings.EqualFold(e.Level, "error") {
+			errs++
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(errs) / float64(total), total
+}
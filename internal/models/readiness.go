@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Defaults for health check settings left at zero.
+const (
+	DefaultHealthCheckInterval = 10 * time.Second
+	DefaultHealthCheckTimeout  = 5 * time.Second
+	DefaultHealthCheckRetries  = 3
+)
+
+// Interval returns the time between probes.
+func (c *HealthCheckConfig) Interval() time.Duration {
+	if c.IntervalSeconds > 0 {
+		return time.Duration(c.IntervalSeconds) * time.Second
+	}
+	return DefaultHealthCheckInterval
+}
+
+// Timeout returns how long a probe may take before it fails.
+func (c *HealthCheckConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultHealthCheckTimeout
+}
+
+// MaxFailures returns the number of consecutive failed probes after which a
+// starting deployment fails.
+func (c *HealthCheckConfig) MaxFailures() int {
+	if c.Retries > 0 {
+		return c.Retries
+	}
+	return DefaultHealthCheckRetries
+}
+
+// ReadinessBudget returns how long a deployment may stay starting while its
+// probes fail: every probe taking its full timeout, with an interval between
+// them.
+func (c *HealthCheckConfig) ReadinessBudget() time.Duration {
+	return time.Duration(c.MaxFailures()) * (c.Interval() + c.Timeout())
+}
+
+// DeploymentHealth records the readiness probes of a deployment with a
+// health check. The deployment stays starting until a probe passes and
+// fails once MaxFailures probes in a row have not.
+type DeploymentHealth struct {
+	// StartedAt is when probing began
+	StartedAt time.Time `json:"started_at"`
+	Passed    bool      `json:"passed"`
+	// Failures counts the consecutive failed probes
+	Failures int `json:"failures"`
+	// Output is the result of the latest probe, e.g. the status code of an
+	// HTTP check or the output of a command
+	Output    string     `json:"output,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// RecordProbe records the result of a probe.
+func (h *DeploymentHealth) RecordProbe(passed bool, output string, at time.Time) {
+	h.Passed = passed
+	if passed {
+		h.Failures = 0
+	} else {
+		h.Failures++
+	}
+	h.Output = output
+	h.CheckedAt = &at
+}
+
+// Diagnostics explains why a deployment's health check did not pass.
+func (h *DeploymentHealth) Diagnostics(check *HealthCheckConfig) string {
+	msg := fmt.Sprintf("%d consecutive health checks failed", h.Failures)
+	if h.Failures == 0 {
+		msg = fmt.Sprintf("health check did not pass within %s", check.ReadinessBudget())
+	}
+	msg += " (" + check.Describe() + ")"
+	if h.Output != "" {
+		msg += ": " + h.Output
+	}
+	return msg
+}
+
+// Describe returns what the check probes, e.g. "GET :8080/health".
+func (c *HealthCheckConfig) Describe() string {
+	switch {
+	case len(c.Command) > 0:
+		return fmt.Sprintf("command %q", c.Command)
+	case c.Path != "":
+		return fmt.Sprintf("GET :%d%s", c.Port, c.Path)
+	default:
+		return fmt.Sprintf("TCP :%d", c.Port)
+	}
+}
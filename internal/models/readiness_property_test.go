@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: readiness-gating, Property 1: Consecutive Failures**
+// For any sequence of probe results, Failures SHALL count the failed probes
+// since the last one that passed.
+
+func TestDeploymentHealthCountsConsecutiveFailures(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("failures reset on a pass", prop.ForAll(
+		func(results []bool) bool {
+			h := &DeploymentHealth{}
+			want := 0
+			for _, passed := range results {
+				h.RecordProbe(passed, "", time.Now())
+				if passed {
+					want = 0
+				} else {
+					want++
+				}
+			}
+			return h.Failures == want && (len(results) == 0 || h.Passed == results[len(results)-1])
+		},
+		gen.SliceOf(gen.Bool()),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestHealthCheckDefaults(t *testing.T) {
+	check := &HealthCheckConfig{Path: "/health", Port: 8080}
+	if check.Interval() != DefaultHealthCheckInterval || check.Timeout() != DefaultHealthCheckTimeout || check.MaxFailures() != DefaultHealthCheckRetries {
+		t.Errorf("defaults = %s, %s, %d", check.Interval(), check.Timeout(), check.MaxFailures())
+	}
+	if got := check.ReadinessBudget(); got != 45*time.Second {
+		t.Errorf("ReadinessBudget() = %s, want 45s", got)
+	}
+
+	h := &DeploymentHealth{}
+	if got, want := h.Diagnostics(check), "health check did not pass within 45s (GET :8080/health)"; got != want {
+		t.Errorf("Diagnostics() = %q, want %q", got, want)
+	}
+	h.RecordProbe(false, "connection refused", time.Now())
+	tcp := &HealthCheckConfig{Port: 5432, Retries: 1}
+	if got, want := h.Diagnostics(tcp), "1 consecutive health checks failed (TCP :5432): connection refused"; got != want {
+		t.Errorf("Diagnostics() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+// Default prices used to estimate the savings of resource recommendations,
+// overridable with the cost_cpu_core_month and cost_memory_gib_month settings.
+const (
+	DefaultCostPerCPUCoreMonth   = 20.0
+	DefaultCostPerMemoryGiBMonth = 2.5
+)
+
+// ResourceUsageSummary is a service's observed per-deployment usage. CPU is
+// in cores, where one core is 100 percent.
+type ResourceUsageSummary struct {
+	Samples        int     `json:"samples"` // Minutes with usage reported
+	CPUCoresP95    float64 `json:"cpu_cores_p95"`
+	CPUCoresMax    float64 `json:"cpu_cores_max"`
+	MemoryBytesP95 int64   `json:"memory_bytes_p95"`
+	MemoryBytesMax int64   `json:"memory_bytes_max"`
+}
+
+// ResourceSavings is what applying a recommendation frees across a service's
+// replicas. Negative values are added resources and cost.
+type ResourceSavings struct {
+	CPUCores    float64 `json:"cpu_cores"`
+	MemoryBytes int64   `json:"memory_bytes"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// ResourceRecommendation right-sizes a service's resources from its observed
+// usage.
+type ResourceRecommendation struct {
+	ServiceName string               `json:"service_name"`
+	Replicas    int                  `json:"replicas"`
+	Current     ResourceSpec         `json:"current"`
+	Recommended ResourceSpec         `json:"recommended"`
+	Usage       ResourceUsageSummary `json:"usage"`
+	// Reasons explain each changed resource, e.g. "uses p95 180Mi of its 1Gi memory"
+	Reasons []string        `json:"reasons"`
+	Savings ResourceSavings `json:"savings"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ReleaseNotesSource records how a release's notes were produced.
+type ReleaseNotesSource string
+
+const (
+	// ReleaseNotesSourceGenerated means the notes are derived from the commit list.
+	ReleaseNotesSourceGenerated ReleaseNotesSource = "generated"
+	// ReleaseNotesSourceUploaded means hand-written markdown notes were uploaded.
+	ReleaseNotesSourceUploaded ReleaseNotesSource = "uploaded"
+)
+
+const (
+	// MaxReleaseCommits is the maximum number of commits stored for a single release.
+	MaxReleaseCommits = 1000
+	// MaxReleaseNotesLength is the maximum length of uploaded release notes markdown.
+	MaxReleaseNotesLength = 64 * 1024
+)
+
+// Release links release notes to a deployment. The commit range covers the
+// changes between the previous deployment of the service and this one.
+type Release struct {
+	ID           string             `json:"id"`
+	AppID        string             `json:"app_id"`
+	DeploymentID string             `json:"deployment_id"`
+	ServiceName  string             `json:"service_name"`
+	Version      int                `json:"version"`               // Deployment version
+	FromCommit   string             `json:"from_commit,omitempty"` // Commit of the previous deployment (exclusive)
+	ToCommit     string             `json:"to_commit,omitempty"`   // Commit of this deployment
+	Commits      []string           `json:"commits"`               // Commit messages in the range, newest first
+	Notes        string             `json:"notes,omitempty"`       // Uploaded markdown notes
+	Source       ReleaseNotesSource `json:"source"`
+	CreatedBy    string             `json:"created_by"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Validate checks the release fields and sets Source from the provided content.
+func (r *Release) Validate() error {
+	commits := make([]string, 0, len(r.Commits))
+	for _, c := range r.Commits {
+		if c = strings.TrimSpace(c); c != "" {
+			commits = append(commits, c)
+		}
+	}
+	r.Commits = commits
+	r.Notes = strings.TrimSpace(r.Notes)
+
+	if len(r.Commits) == 0 && r.Notes == "" {
+		return errors.New("commits or notes are required")
+	}
+	if len(r.Commits) > MaxReleaseCommits {
+		return errors.New("a release may contain at most 1000 commits")
+	}
+	if len(r.Notes) > MaxReleaseNotesLength {
+		return errors.New("notes must be 64KB or smaller")
+	}
+
+	if r.Notes != "" {
+		r.Source = ReleaseNotesSourceUploaded
+	} else {
+		r.Source = ReleaseNotesSourceGenerated
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxScalingProfiles is the maximum number of profiles in a scaling schedule.
+const MaxScalingProfiles = 20
+
+// MaxAutoscalingReplicas is the highest max_replicas autoscaling accepts.
+const MaxAutoscalingReplicas = 100
+
+// AutoscalingOverrideDuration is how long a manual scale of an autoscaled
+// service holds before the autoscaler resumes.
+const AutoscalingOverrideDuration = time.Hour
+
+// ScalingProfile sets a service's replica count during a daily time window,
+// e.g. 6 replicas from 08:00 to 20:00 on weekdays.
+type ScalingProfile struct {
+	Name      string   `json:"name,omitempty"`
+	Days      []string `json:"days,omitempty"` // Weekday names the window starts on; every day when empty
+	StartTime string   `json:"start_time"`     // HH:MM
+	EndTime   string   `json:"end_time"`       // HH:MM; at or before StartTime runs past midnight
+	Replicas  int      `json:"replicas"`
+}
+
+// ScalingSchedule changes a service's replica count by time of day and day
+// of week. The first profile active at a given time wins; outside all
+// profiles the service runs DefaultReplicas.
+type ScalingSchedule struct {
+	Timezone        string           `json:"timezone,omitempty"` // IANA name, UTC when empty
+	DefaultReplicas int              `json:"default_replicas"`
+	Profiles        []ScalingProfile `json:"profiles"`
+}
+
+// ReplicaOverride records a manual scale of a service with a scaling
+// schedule or autoscaling. It takes precedence over the schedule until Until,
+// the schedule's next change of replica count, and over the autoscaler for
+// AutoscalingOverrideDuration; a nil Until holds until the schedule itself is
+// changed.
+type ReplicaOverride struct {
+	Replicas int        `json:"replicas"`
+	Until    *time.Time `json:"until,omitempty"`
+	SetBy    string     `json:"set_by,omitempty"`
+	SetAt    time.Time  `json:"set_at"`
+}
+
+// Autoscaling keeps a service's replica count between MinReplicas and
+// MaxReplicas, sized so its average usage per replica stays near the
+// targets. With several targets the one needing the most replicas wins.
+type Autoscaling struct {
+	MinReplicas int `json:"min_replicas"`
+	MaxReplicas int `json:"max_replicas"`
+	// TargetCPUPercent is CPU usage per replica as a percentage of the
+	// service's CPU resources; 0 ignores CPU
+	TargetCPUPercent int `json:"target_cpu_percent,omitempty"`
+	// TargetMemoryPercent is memory usage per replica as a percentage of the
+	// service's memory resources; 0 ignores memory
+	TargetMemoryPercent int `json:"target_memory_percent,omitempty"`
+	// TargetRequestsPerSecond is HTTP requests served per replica, as
+	// counted by node agents; 0 ignores the request rate
+	TargetRequestsPerSecond float64 `json:"target_requests_per_second,omitempty"`
+}
+
+// Validate checks the replica bounds and that at least one target is set.
+func (a *Autoscaling) Validate() error {
+	if a.MinReplicas < 1 {
+		return errors.New("min_replicas must be at least 1")
+	}
+	if a.MaxReplicas < a.MinReplicas {
+		return errors.New("max_replicas must be at least min_replicas")
+	}
+	if a.MaxReplicas > MaxAutoscalingReplicas {
+		return fmt.Errorf("max_replicas must be at most %d", MaxAutoscalingReplicas)
+	}
+	if a.TargetCPUPercent < 0 || a.TargetCPUPercent > 100 {
+		return errors.New("target_cpu_percent must be between 1 and 100")
+	}
+	if a.TargetMemoryPercent < 0 || a.TargetMemoryPercent > 100 {
+		return errors.New("target_memory_percent must be between 1 and 100")
+	}
+	if a.TargetRequestsPerSecond < 0 {
+		return errors.New("target_requests_per_second must be positive")
+	}
+	if a.TargetCPUPercent == 0 && a.TargetMemoryPercent == 0 && a.TargetRequestsPerSecond == 0 {
+		return errors.New("at least one of target_cpu_percent, target_memory_percent and target_requests_per_second is required")
+	}
+	return nil
+}
+
+// Clamp returns replicas limited to the autoscaling bounds.
+func (a *Autoscaling) Clamp(replicas int) int {
+	return min(max(replicas, a.MinReplicas), a.MaxReplicas)
+}
+
+// ScaleEventSource is what changed a service's replica count.
+type ScaleEventSource string
+
+const (
+	ScaleEventAutoscaler ScaleEventSource = "autoscaler"
+	ScaleEventSchedule   ScaleEventSource = "schedule"
+	ScaleEventManual     ScaleEventSource = "manual"
+)
+
+// ScaleEvent records a change of a service's replica count.
+type ScaleEvent struct {
+	ID           string           `json:"id"`
+	AppID        string           `json:"app_id"`
+	ServiceName  string           `json:"service_name"`
+	FromReplicas int              `json:"from_replicas"`
+	ToReplicas   int              `json:"to_replicas"`
+	Source       ScaleEventSource `json:"source"`
+	Reason       string           `json:"reason,omitempty"`
+	UserID       string           `json:"user_id,omitempty"` // Set for manual scales
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+// ActiveAt returns true if the override still applies at the given time.
+func (o *ReplicaOverride) ActiveAt(now time.Time) bool {
+	return o != nil && (o.Until == nil || now.Before(*o.Until))
+}
+
+// Validate checks the schedule's profiles and normalizes weekday names.
+func (s *ScalingSchedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q", s.Timezone)
+	}
+	if s.DefaultReplicas < 1 {
+		return errors.New("default_replicas must be at least 1")
+	}
+	if len(s.Profiles) == 0 {
+		return errors.New("at least one profile is required")
+	}
+	if len(s.Profiles) > MaxScalingProfiles {
+		return fmt.Errorf("at most %d profiles are allowed", MaxScalingProfiles)
+	}
+
+	for i := range s.Profiles {
+		p := &s.Profiles[i]
+		label := fmt.Sprintf("profile %d", i+1)
+		if p.Name != "" {
+			label = fmt.Sprintf("profile %q", p.Name)
+		}
+		if p.Replicas < 1 {
+			return fmt.Errorf("%s: replicas must be at least 1", label)
+		}
+		if _, err := clockMinute(p.StartTime); err != nil {
+			return fmt.Errorf("%s: start: %w", label, err)
+		}
+		if _, err := clockMinute(p.EndTime); err != nil {
+			return fmt.Errorf("%s: end: %w", label, err)
+		}
+		for j, day := range p.Days {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := weekdays[day]; !ok {
+				return fmt.Errorf("%s: invalid weekday %q", label, day)
+			}
+			p.Days[j] = day
+		}
+	}
+	return nil
+}
+
+// ReplicasAt returns the replica count the schedule sets at the given time
+// and the profile that sets it, or nil outside all profiles.
+func (s *ScalingSchedule) ReplicasAt(now time.Time) (int, *ScalingProfile) {
+	loc, err := s.location()
+	if err != nil {
+		return s.DefaultReplicas, nil
+	}
+	local := now.In(loc)
+	for i := range s.Profiles {
+		if s.Profiles[i].activeAt(local) {
+			return s.Profiles[i].Replicas, &s.Profiles[i]
+		}
+	}
+	return s.DefaultReplicas, nil
+}
+
+// NextChange returns the first time after now at which the schedule sets a
+// different replica count, or nil if it never does.
+func (s *ScalingSchedule) NextChange(now time.Time) *time.Time {
+	loc, err := s.location()
+	if err != nil {
+		return nil
+	}
+	current, _ := s.ReplicasAt(now)
+
+	// Replica counts only change at profile boundaries, and the schedule
+	// repeats weekly, so a week and a day of boundaries covers every change
+	local := now.In(loc)
+	var candidates []time.Time
+	for d := 0; d <= 8; d++ {
+		for _, p := range s.Profiles {
+			for _, clock := range []string{p.StartTime, p.EndTime} {
+				m, err := clockMinute(clock)
+				if err != nil {
+					continue
+				}
+				t := time.Date(local.Year(), local.Month(), local.Day()+d, m/60, m%60, 0, 0, loc)
+				if t.After(now) {
+					candidates = append(candidates, t)
+				}
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+
+	for _, t := range candidates {
+		if replicas, _ := s.ReplicasAt(t); replicas != current {
+			return &t
+		}
+	}
+	return nil
+}
+
+// activeAt returns true if the profile's window covers the given local time.
+// Windows that end at or before their start run into the next day.
+func (p *ScalingProfile) activeAt(local time.Time) bool {
+	start, err := clockMinute(p.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := clockMinute(p.EndTime)
+	if err != nil {
+		return false
+	}
+	m := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	if start < end {
+		return p.onDay(today) && m >= start && m < end
+	}
+	return (p.onDay(today) && m >= start) || (p.onDay(yesterday) && m < end)
+}
+
+// onDay returns true if the profile's window starts on the given weekday.
+func (p *ScalingProfile) onDay(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, d := range p.Days {
+		if wd, ok := weekdays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// location returns the schedule's time zone, defaulting to UTC.
+func (s *ScalingSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// clockMinute converts an HH:MM time into minutes since midnight.
+func clockMinute(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// DesiredReplicas returns the replica count the service should run at the
+// given time. A manual override wins over the scaling schedule or autoscaler
+// until it expires; services without a schedule run their configured
+// Replicas, which the autoscaler keeps current.
+func (s *ServiceConfig) DesiredReplicas(now time.Time) int {
+	if (s.ScalingSchedule != nil || s.Autoscaling != nil) && s.ReplicaOverride.ActiveAt(now) {
+		return s.ReplicaOverride.Replicas
+	}
+	if s.ScalingSchedule == nil {
+		return s.Replicas
+	}
+	replicas, _ := s.ScalingSchedule.ReplicasAt(now)
+	return replicas
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func businessHoursSchedule() *ScalingSchedule {
+	return &ScalingSchedule{
+		DefaultReplicas: 2,
+		Profiles: []ScalingProfile{{
+			Name:      "business-hours",
+			Days:      []string{"Monday", "tuesday", "wednesday", "thursday", "friday"},
+			StartTime: "08:00",
+			EndTime:   "20:00",
+			Replicas:  6,
+		}},
+	}
+}
+
+// **Feature: scheduled-scaling, Property 1: Profile Replicas**
+// For any time, a weekday 08:00 to 20:00 profile of 6 replicas over a default
+// of 2 SHALL set 6 replicas exactly within the window and 2 otherwise.
+
+func TestScalingScheduleReplicasAt(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	// Sunday 00:00 UTC
+	base := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	schedule := businessHoursSchedule()
+	if err := schedule.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	properties.Property("6 replicas on weekdays from 08:00 to 20:00, 2 otherwise", prop.ForAll(
+		func(minute int) bool {
+			now := base.Add(time.Duration(minute) * time.Minute)
+			weekday := now.Weekday() != time.Saturday && now.Weekday() != time.Sunday
+			clock := now.Hour()*60 + now.Minute()
+			expected := 2
+			if weekday && clock >= 8*60 && clock < 20*60 {
+				expected = 6
+			}
+			replicas, _ := schedule.ReplicasAt(now)
+			return replicas == expected
+		},
+		gen.IntRange(0, 4*7*24*60),
+	))
+
+	properties.TestingRun(t)
+}
+
+// **Feature: scheduled-scaling, Property 2: Next Change**
+// For any time, the schedule's next change SHALL set a different replica count
+// and no earlier minute SHALL.
+
+func TestScalingScheduleNextChange(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 50
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+	base := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	schedule := businessHoursSchedule()
+
+	properties.Property("next change is the first minute with a different count", prop.ForAll(
+		func(minute int) bool {
+			now := base.Add(time.Duration(minute) * time.Minute)
+			current, _ := schedule.ReplicasAt(now)
+			next := schedule.NextChange(now)
+			if next == nil || !next.After(now) {
+				return false
+			}
+			if replicas, _ := schedule.ReplicasAt(*next); replicas == current {
+				return false
+			}
+			for t := now.Truncate(time.Minute).Add(time.Minute); t.Before(*next); t = t.Add(time.Minute) {
+				if replicas, _ := schedule.ReplicasAt(t); replicas != current {
+					return false
+				}
+			}
+			return true
+		},
+		gen.IntRange(0, 2*7*24*60),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestScalingScheduleOvernightAndTimezone(t *testing.T) {
+	schedule := &ScalingSchedule{
+		Timezone:        "America/New_York",
+		DefaultReplicas: 4,
+		Profiles:        []ScalingProfile{{Name: "night", Days: []string{"friday"}, StartTime: "22:00", EndTime: "06:00", Replicas: 1}},
+	}
+	if err := schedule.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	// Saturday 08:00 UTC is Saturday 03:00 in New York, inside Friday's night
+	if replicas, p := schedule.ReplicasAt(time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)); replicas != 1 || p == nil {
+		t.Errorf("replicas = %d, want 1 from the night profile", replicas)
+	}
+	// Sunday 08:00 UTC is Sunday 03:00 in New York; the window starts on Fridays only
+	if replicas, _ := schedule.ReplicasAt(time.Date(2026, 1, 11, 8, 0, 0, 0, time.UTC)); replicas != 4 {
+		t.Errorf("replicas = %d, want the default 4", replicas)
+	}
+}
+
+func TestServiceConfigDesiredReplicas(t *testing.T) {
+	monday := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	until := monday.Add(time.Hour)
+
+	svc := &ServiceConfig{Name: "web", Replicas: 3}
+	if got := svc.DesiredReplicas(monday); got != 3 {
+		t.Errorf("unscheduled: desired = %d, want configured 3", got)
+	}
+
+	svc.ScalingSchedule = businessHoursSchedule()
+	if got := svc.DesiredReplicas(monday); got != 6 {
+		t.Errorf("scheduled: desired = %d, want 6", got)
+	}
+
+	svc.ReplicaOverride = &ReplicaOverride{Replicas: 10, Until: &until}
+	if got := svc.DesiredReplicas(monday); got != 10 {
+		t.Errorf("overridden: desired = %d, want 10", got)
+	}
+	if got := svc.DesiredReplicas(until); got != 6 {
+		t.Errorf("override expired: desired = %d, want 6", got)
+	}
+
+	autoscaled := &ServiceConfig{Name: "api", Replicas: 4, Autoscaling: &Autoscaling{MinReplicas: 2, MaxReplicas: 8, TargetCPUPercent: 70}}
+	autoscaled.ReplicaOverride = &ReplicaOverride{Replicas: 1, Until: &until}
+	if got := autoscaled.DesiredReplicas(monday); got != 1 {
+		t.Errorf("autoscaled overridden: desired = %d, want 1", got)
+	}
+	if got := autoscaled.DesiredReplicas(until); got != 4 {
+		t.Errorf("autoscaled: desired = %d, want the autoscaler's 4", got)
+	}
+}
+
+func TestScalingScheduleValidate(t *testing.T) {
+	profile := ScalingProfile{StartTime: "08:00", EndTime: "20:00", Replicas: 2}
+
+	tests := []struct {
+		name     string
+		schedule ScalingSchedule
+		ok       bool
+	}{
+		{"valid", ScalingSchedule{DefaultReplicas: 1, Profiles: []ScalingProfile{profile}}, true},
+		{"no profiles", ScalingSchedule{DefaultReplicas: 1}, false},
+		{"zero default", ScalingSchedule{Profiles: []ScalingProfile{profile}}, false},
+		{"zero replicas", ScalingSchedule{DefaultReplicas: 1, Profiles: []ScalingProfile{{StartTime: "08:00", EndTime: "20:00"}}}, false},
+		{"bad time", ScalingSchedule{DefaultReplicas: 1, Profiles: []ScalingProfile{{StartTime: "8am", EndTime: "20:00", Replicas: 2}}}, false},
+		{"bad weekday", ScalingSchedule{DefaultReplicas: 1, Profiles: []ScalingProfile{{Days: []string{"mon"}, StartTime: "08:00", EndTime: "20:00", Replicas: 2}}}, false},
+		{"bad timezone", ScalingSchedule{Timezone: "Mars/Olympus", DefaultReplicas: 1, Profiles: []ScalingProfile{profile}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() error = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAutoscalingValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoscaling Autoscaling
+		ok          bool
+	}{
+		{"cpu", Autoscaling{MinReplicas: 1, MaxReplicas: 5, TargetCPUPercent: 70}, true},
+		{"requests", Autoscaling{MinReplicas: 2, MaxReplicas: 2, TargetRequestsPerSecond: 50}, true},
+		{"no target", Autoscaling{MinReplicas: 1, MaxReplicas: 5}, false},
+		{"zero min", Autoscaling{MaxReplicas: 5, TargetCPUPercent: 70}, false},
+		{"max below min", Autoscaling{MinReplicas: 3, MaxReplicas: 2, TargetCPUPercent: 70}, false},
+		{"max too high", Autoscaling{MinReplicas: 1, MaxReplicas: MaxAutoscalingReplicas + 1, TargetCPUPercent: 70}, false},
+		{"cpu above 100", Autoscaling{MinReplicas: 1, MaxReplicas: 5, TargetCPUPercent: 150}, false},
+		{"negative requests", Autoscaling{MinReplicas: 1, MaxReplicas: 5, TargetRequestsPerSecond: -1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.autoscaling.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() error = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// SCIMToken is an org's SCIM provisioning credential. Only a hash of the token
+// is stored; TokenPrefix identifies it in the UI.
+type SCIMToken struct {
+	OrgID       string     `json:"org_id"`
+	TokenPrefix string     `json:"token_prefix"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// SCIMUser links an identity provider user to a Narvana account in an org.
+// Provisioned is true when the account was created by SCIM rather than
+// linked to an existing user with the same email.
+type SCIMUser struct {
+	ID          string    `json:"id"`
+	OrgID       string    `json:"org_id"`
+	UserID      string    `json:"user_id"`
+	ExternalID  string    `json:"external_id,omitempty"`
+	UserName    string    `json:"user_name"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Active      bool      `json:"active"`
+	Provisioned bool      `json:"provisioned"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SCIMGroup is an identity provider group pushed to an org. MemberIDs holds
+// SCIMUser IDs.
+type SCIMGroup struct {
+	ID          string    `json:"id"`
+	OrgID       string    `json:"org_id"`
+	ExternalID  string    `json:"external_id,omitempty"`
+	DisplayName string    `json:"display_name"`
+	MemberIDs   []string  `json:"member_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SCIMGroupMapping grants members of the named IdP group a role in the org.
+type SCIMGroupMapping struct {
+	GroupName string `json:"group_name"`
+	Role      Role   `json:"role"`
+}
+
+// SCIMEventStatus is the outcome of a SCIM operation.
+type SCIMEventStatus string
+
+const (
+	SCIMEventSuccess  SCIMEventStatus = "success"
+	SCIMEventConflict SCIMEventStatus = "conflict"
+	SCIMEventError    SCIMEventStatus = "error"
+)
+
+// SCIMEvent records a provisioning operation for the org's sync status page.
+type SCIMEvent struct {
+	ID           string          `json:"id"`
+	OrgID        string          `json:"org_id"`
+	Operation    string          `json:"operation"`     // create, replace, patch, delete or sync
+	ResourceType string          `json:"resource_type"` // User, Group or Mapping
+	ResourceID   string          `json:"resource_id,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	Status       SCIMEventStatus `json:"status"`
+	Message      string          `json:"message,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// SCIMRole returns the org role for a SCIM user: the highest role mapped to
+// any group containing them, otherwise member.
+func SCIMRole(scimUserID string, groups []*SCIMGroup, mappings []SCIMGroupMapping) Role {
+	mapped := make(map[string]Role, len(mappings))
+	for _, m := range mappings {
+		mapped[m.GroupName] = m.Role
+	}
+	var role Role
+	for _, g := range groups {
+		groupRole, ok := mapped[g.DisplayName]
+		if !ok || !groupRole.AtLeast(role) {
+			continue
+		}
+		for _, id := range g.MemberIDs {
+			if id == scimUserID {
+				role = groupRole
+				break
+			}
+		}
+	}
+	if role == "" {
+		return RoleMember
+	}
+	return role
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstanceVariable is the template variable set to the instance's name.
+const InstanceVariable = "instance"
+
+// templateVariableNameRegex matches template variable names.
+var templateVariableNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// templateReferenceRegex matches ${name} references in template fields.
+var templateReferenceRegex = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// ServiceTemplateVariable declares a parameter of a service template.
+type ServiceTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"` // instances must set it; Default is ignored
+}
+
+// ServiceTemplate is a parametrized service within an app, instantiated any
+// number of times with different parameters, e.g. one worker per tenant.
+// The source fields and env var values of Service may reference variables as
+// ${name}; ${instance} is the instance's name.
+type ServiceTemplate struct {
+	ID          string                    `json:"id"`
+	AppID       string                    `json:"app_id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Variables   []ServiceTemplateVariable `json:"variables"`
+	Service     ServiceConfig             `json:"service"` // Name is ignored; instances are named <template>-<instance>
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// ServiceTemplateRef links a service to the template it was instantiated from.
+type ServiceTemplateRef struct {
+	Name     string            `json:"name"`
+	Instance string            `json:"instance"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// TemplateInstanceName returns the service name of a template instance.
+func TemplateInstanceName(template, instance string) string {
+	return template + "-" + instance
+}
+
+// Validate checks the template's variables and that its service only
+// references declared variables.
+func (t *ServiceTemplate) Validate() error {
+	if t.Name == "" {
+		return &ValidationError{Field: "name", Message: "template name is required"}
+	}
+
+	declared, err := declareTemplateVariables(t.Variables, InstanceVariable)
+	if err != nil {
+		return err
+	}
+
+	svc := &t.Service
+	if (svc.GitRepo == "" && svc.FlakeURI == "") || svc.Image != "" || svc.Database != nil {
+		return &ValidationError{Field: "service", Message: "templates support git and flake sources only"}
+	}
+
+	svc.forEachTemplatedField(func(field, value string) string {
+		if err == nil {
+			err = checkTemplateReferences(field, value, declared)
+		}
+		return value
+	})
+	return err
+}
+
+// Render instantiates the template as a service named <template>-<instance>.
+// Params set the template's variables; unset variables take their default.
+func (t *ServiceTemplate) Render(instance string, params map[string]string) (ServiceConfig, error) {
+	values, err := resolveTemplateParams(t.Variables, params, InstanceVariable, instance)
+	if err != nil {
+		return ServiceConfig{}, err
+	}
+
+	svc := t.Service.Clone()
+	svc.Name = TemplateInstanceName(t.Name, instance)
+	svc.forEachTemplatedField(func(field, value string) string {
+		return ExpandTemplateReferences(value, values)
+	})
+
+	paramsCopy := make(map[string]string, len(params))
+	for k, v := range params {
+		paramsCopy[k] = v
+	}
+	svc.Template = &ServiceTemplateRef{Name: t.Name, Instance: instance, Params: paramsCopy}
+	return svc, nil
+}
+
+// forEachTemplatedField replaces each field that may reference template
+// variables with the result of fn.
+func (s *ServiceConfig) forEachTemplatedField(fn func(field, value string) string) {
+	s.GitRepo = fn("git_repo", s.GitRepo)
+	s.GitRef = fn("git_ref", s.GitRef)
+	s.FlakeOutput = fn("flake_output", s.FlakeOutput)
+	s.FlakeURI = fn("flake_uri", s.FlakeURI)
+	for k, v := range s.EnvVars {
+		s.EnvVars[k] = fn("env_vars."+k, v)
+	}
+}
+
+// declareTemplateVariables checks the names of a template's variables and
+// returns them with the builtin variable, which they may not redeclare.
+func declareTemplateVariables(variables []ServiceTemplateVariable, builtin string) (map[string]bool, error) {
+	declared := map[string]bool{builtin: true}
+	for _, v := range variables {
+		if !templateVariableNameRegex.MatchString(v.Name) {
+			return nil, &ValidationError{Field: "variables", Message: fmt.Sprintf("invalid variable name %q: use lowercase letters, digits and underscores", v.Name)}
+		}
+		if declared[v.Name] {
+			return nil, &ValidationError{Field: "variables", Message: fmt.Sprintf("variable %q is declared more than once or is reserved", v.Name)}
+		}
+		declared[v.Name] = true
+	}
+	return declared, nil
+}
+
+// checkTemplateReferences checks that a field only references declared variables.
+func checkTemplateReferences(field, value string, declared map[string]bool) error {
+	for _, ref := range templateReferenceRegex.FindAllStringSubmatch(value, -1) {
+		if !declared[ref[1]] {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("undeclared variable ${%s}", ref[1])}
+		}
+	}
+	return nil
+}
+
+// resolveTemplateParams returns the value of each of a template's variables
+// and of its builtin variable. Params set variables; unset variables take
+// their default unless required, and params for undeclared variables are
+// rejected.
+func resolveTemplateParams(variables []ServiceTemplateVariable, params map[string]string, builtin, builtinValue string) (map[string]string, error) {
+	values := map[string]string{builtin: builtinValue}
+	known := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		known[v.Name] = true
+		value, ok := params[v.Name]
+		if !ok {
+			if v.Required {
+				return nil, &ValidationError{Field: "params", Message: fmt.Sprintf("variable %q is required", v.Name)}
+			}
+			value = v.Default
+		}
+		values[v.Name] = value
+	}
+
+	var unknown []string
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &ValidationError{Field: "params", Message: "unknown variables: " + strings.Join(unknown, ", ")}
+	}
+	return values, nil
+}
+
+// ExpandTemplateReferences replaces the ${name} references in value with the
+// values of the variables. References to unknown variables become empty.
+func ExpandTemplateReferences(value string, values map[string]string) string {
+	return templateReferenceRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		return values[ref[2:len(ref)-1]]
+	})
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// **Feature: service-templates, Property 1: Template Rendering**
+// For any instance name and parameter value, rendering a template SHALL name
+// the service <template>-<instance> and substitute every variable reference.
+
+func TestServiceTemplateRender(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	parameters.Rng.Seed(time.Now().UnixNano())
+
+	properties := gopter.NewProperties(parameters)
+
+	tmpl := &ServiceTemplate{
+		Name: "worker",
+		Variables: []ServiceTemplateVariable{
+			{Name: "tenant", Required: true},
+			{Name: "region", Default: "eu"},
+		},
+		Service: ServiceConfig{
+			SourceType: SourceTypeGit,
+			GitRepo:    "github.com/acme/worker",
+			GitRef:     "main",
+			EnvVars: map[string]string{
+				"TENANT":    "${tenant}",
+				"QUEUE":     "jobs-${instance}-${region}",
+				"LOG_LEVEL": "info",
+			},
+		},
+	}
+	if err := tmpl.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	properties.Property("instance name and variables are substituted", prop.ForAll(
+		func(instance, tenant string) bool {
+			svc, err := tmpl.Render(instance, map[string]string{"tenant": tenant})
+			if err != nil {
+				return false
+			}
+			return svc.Name == "worker-"+instance &&
+				svc.EnvVars["TENANT"] == tenant &&
+				svc.EnvVars["QUEUE"] == "jobs-"+instance+"-eu" &&
+				svc.EnvVars["LOG_LEVEL"] == "info" &&
+				svc.Template.Instance == instance &&
+				svc.Template.Params["tenant"] == tenant &&
+				tmpl.Service.EnvVars["TENANT"] == "${tenant}"
+		},
+		gen.Identifier(),
+		gen.AlphaString(),
+	))
+
+	properties.TestingRun(t)
+
+	if _, err := tmpl.Render("a", nil); err == nil {
+		t.Error("Render without a required variable succeeded")
+	}
+	if _, err := tmpl.Render("a", map[string]string{"tenant": "t", "zone": "z"}); err == nil {
+		t.Error("Render with an unknown variable succeeded")
+	}
+}
+
+func TestServiceTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*ServiceTemplate)
+		wantErr bool
+	}{
+		{"valid", func(*ServiceTemplate) {}, false},
+		{"undeclared reference", func(t *ServiceTemplate) { t.Service.GitRef = "${branch}" }, true},
+		{"reserved variable", func(t *ServiceTemplate) {
+			t.Variables = append(t.Variables, ServiceTemplateVariable{Name: InstanceVariable})
+		}, true},
+		{"invalid variable name", func(t *ServiceTemplate) { t.Variables[0].Name = "Tenant" }, true},
+		{"image source", func(t *ServiceTemplate) { t.Service.Image = "nginx" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := &ServiceTemplate{
+				Name:      "worker",
+				Variables: []ServiceTemplateVariable{{Name: "tenant"}},
+				Service: ServiceConfig{
+					SourceType: SourceTypeGit,
+					GitRepo:    "github.com/acme/${tenant}",
+				},
+			}
+			tt.mutate(tmpl)
+			if err := tmpl.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SmokeTestType says how a smoke test checks a deployment.
+type SmokeTestType string
+
+const (
+	// SmokeTestHTTP sends a request to the deployment and checks the response.
+	SmokeTestHTTP SmokeTestType = "http"
+	// SmokeTestCommand runs a command from the deployment's artifact, which
+	// passes when it exits 0.
+	SmokeTestCommand SmokeTestType = "command"
+)
+
+// Limits and defaults of smoke tests.
+const (
+	MaxSmokeTests                  = 20
+	DefaultSmokeTestTimeoutSeconds = 30
+	MaxSmokeTestTimeoutSeconds     = 1800
+)
+
+// SmokeTest is a check run against each new deployment of a service once it
+// reports running. A deployment that fails any of its service's smoke tests
+// is stopped and the service is rolled back.
+type SmokeTest struct {
+	Name string        `json:"name"`
+	Type SmokeTestType `json:"type"`
+
+	// HTTP tests request Path on the deployment's primary port
+	Path         string `json:"path,omitempty"`
+	Method       string `json:"method,omitempty"`        // Default: GET
+	ExpectStatus int    `json:"expect_status,omitempty"` // Default: any 2xx
+	ExpectBody   string `json:"expect_body,omitempty"`   // Substring the response body must contain
+
+	// Command tests run Command in a one-off run of the deployment's
+	// artifact, with SMOKE_TEST_URL set to the deployment's address
+	Command []string `json:"command,omitempty"`
+
+	// TimeoutSeconds fails the test if it takes longer (default: DefaultSmokeTestTimeoutSeconds).
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Validate checks the test's fields for its type.
+func (t *SmokeTest) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch t.Type {
+	case SmokeTestHTTP:
+		if !strings.HasPrefix(t.Path, "/") {
+			return fmt.Errorf("%s: path must start with /", t.Name)
+		}
+		switch t.Method {
+		case "", http.MethodGet, http.MethodHead, http.MethodPost:
+		default:
+			return fmt.Errorf("%s: method must be GET, HEAD or POST", t.Name)
+		}
+		if t.ExpectStatus != 0 && (t.ExpectStatus < 100 || t.ExpectStatus > 599) {
+			return fmt.Errorf("%s: expect_status must be an HTTP status code", t.Name)
+		}
+		if len(t.Command) > 0 {
+			return fmt.Errorf("%s: command is only allowed for command tests", t.Name)
+		}
+	case SmokeTestCommand:
+		if len(t.Command) == 0 || strings.TrimSpace(t.Command[0]) == "" {
+			return fmt.Errorf("%s: command is required", t.Name)
+		}
+		if t.Path != "" || t.ExpectStatus != 0 || t.ExpectBody != "" {
+			return fmt.Errorf("%s: path and expectations are only allowed for http tests", t.Name)
+		}
+	default:
+		return fmt.Errorf("%s: type must be http or command", t.Name)
+	}
+	if t.TimeoutSeconds < 0 || t.TimeoutSeconds > MaxSmokeTestTimeoutSeconds {
+		return fmt.Errorf("%s: timeout_seconds must be between 1 and %d, or 0 for the default", t.Name, MaxSmokeTestTimeoutSeconds)
+	}
+	return nil
+}
+
+// Timeout returns how long the test may take.
+func (t *SmokeTest) Timeout() time.Duration {
+	if t.TimeoutSeconds > 0 {
+		return time.Duration(t.TimeoutSeconds) * time.Second
+	}
+	return DefaultSmokeTestTimeoutSeconds * time.Second
+}
+
+// ValidateSmokeTests checks a service's smoke tests and that their names are unique.
+func ValidateSmokeTests(tests []SmokeTest) error {
+	if len(tests) > MaxSmokeTests {
+		return fmt.Errorf("at most %d smoke tests are allowed", MaxSmokeTests)
+	}
+	seen := make(map[string]bool, len(tests))
+	for i := range tests {
+		if err := tests[i].Validate(); err != nil {
+			return err
+		}
+		if seen[tests[i].Name] {
+			return fmt.Errorf("duplicate smoke test name %q", tests[i].Name)
+		}
+		seen[tests[i].Name] = true
+	}
+	return nil
+}
+
+// SmokeTestStatus is the state of a smoke test run or of one test in it.
+type SmokeTestStatus string
+
+const (
+	SmokeTestPending SmokeTestStatus = "pending"
+	SmokeTestRunning SmokeTestStatus = "running"
+	SmokeTestPassed  SmokeTestStatus = "passed"
+	SmokeTestFailed  SmokeTestStatus = "failed"
+)
+
+// Finished returns true if the status will not change again.
+func (s SmokeTestStatus) Finished() bool {
+	return s == SmokeTestPassed || s == SmokeTestFailed
+}
+
+// SmokeTestResult is the outcome of one test of a run.
+type SmokeTestResult struct {
+	Name   string          `json:"name"`
+	Type   SmokeTestType   `json:"type"`
+	Status SmokeTestStatus `json:"status"`
+	// Output is the HTTP status and the start of the response body; the
+	// output of command tests is in the logs of their run deployment
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// RunDeploymentID is the one-off deployment a command test ran in
+	RunDeploymentID string     `json:"run_deployment_id,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// SmokeTestRun is the smoke testing of one deployment: a result for each of
+// its service's tests, and the rollback started if any of them failed.
+type SmokeTestRun struct {
+	ID           string            `json:"id"`
+	DeploymentID string            `json:"deployment_id"`
+	AppID        string            `json:"app_id"`
+	ServiceName  string            `json:"service_name"`
+	Status       SmokeTestStatus   `json:"status"`
+	Tests        []SmokeTest       `json:"tests"`
+	Results      []SmokeTestResult `json:"results"`
+	Error        string            `json:"error,omitempty"`
+	// RollbackDeploymentID is the deployment that replaced a failed one
+	RollbackDeploymentID string     `json:"rollback_deployment_id,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	StartedAt            *time.Time `json:"started_at,omitempty"`
+	FinishedAt           *time.Time `json:"finished_at,omitempty"`
+}
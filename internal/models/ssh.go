@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Validation errors for SSH keys.
+var (
+	ErrSSHKeyNameRequired = errors.New("SSH key name is required")
+	ErrSSHKeyInvalid      = errors.New("invalid SSH public key")
+)
+
+// SSHKey is a public key a user registered to open SSH sessions to nodes
+// through the control plane.
+type SSHKey struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	PublicKey   string     `json:"public_key"`
+	Fingerprint string     `json:"fingerprint"` // SHA256 fingerprint, e.g. "SHA256:..."
+	KeyType     string     `json:"key_type"`    // e.g. "ssh-ed25519"
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ParseSSHKey parses a public key in authorized_keys format. The key's
+// comment is used as its name when name is empty.
+func ParseSSHKey(name, authorizedKey string) (*SSHKey, error) {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(authorizedKey)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSHKeyInvalid, err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = comment
+	}
+	if name == "" {
+		return nil, ErrSSHKeyNameRequired
+	}
+	return &SSHKey{
+		Name:        name,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+		KeyType:     pub.Type(),
+	}, nil
+}
+
+// SSHSession is the audit record of an SSH session brokered to a node. The
+// broker only forwards the encrypted connection, so it records who connected
+// where, with which key, for how long and how much data was exchanged.
+type SSHSession struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	NodeID         string     `json:"node_id"`
+	KeyFingerprint string     `json:"key_fingerprint"`
+	RemoteAddr     string     `json:"remote_addr"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	BytesIn        int64      `json:"bytes_in"`  // From the user to the node
+	BytesOut       int64      `json:"bytes_out"` // From the node to the user
+	// Error is set when the session could not be established or ended abnormally.
+	Error string `json:"error,omitempty"`
+}
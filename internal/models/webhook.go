@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookEvent is a lifecycle event that can be sent to a webhook.
+type WebhookEvent string
+
+const (
+	// WebhookDeploymentStarted fires when a deployment starts its containers.
+	WebhookDeploymentStarted WebhookEvent = "deployment.started"
+	// WebhookDeploymentSucceeded fires when a deployment starts running.
+	WebhookDeploymentSucceeded WebhookEvent = "deployment.succeeded"
+	// WebhookDeploymentFailed fires when a deployment fails.
+	WebhookDeploymentFailed WebhookEvent = "deployment.failed"
+	// WebhookBuildSucceeded fires when a build succeeds.
+	WebhookBuildSucceeded WebhookEvent = "build.succeeded"
+	// WebhookBuildFailed fires when a build fails.
+	WebhookBuildFailed WebhookEvent = "build.failed"
+	// WebhookNodeUnhealthy fires when a node's heartbeats lapse. Only
+	// webhooks of instance admins receive node events.
+	WebhookNodeUnhealthy WebhookEvent = "node.unhealthy"
+	// WebhookNodeRecovered fires when an unhealthy node heartbeats again.
+	WebhookNodeRecovered WebhookEvent = "node.recovered"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []WebhookEvent{
+	WebhookDeploymentStarted,
+	WebhookDeploymentSucceeded,
+	WebhookDeploymentFailed,
+	WebhookBuildSucceeded,
+	WebhookBuildFailed,
+	WebhookNodeUnhealthy,
+	WebhookNodeRecovered,
+}
+
+// IsValid reports whether e is an event webhooks can subscribe to.
+func (e WebhookEvent) IsValid() bool {
+	for _, known := range WebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNode reports whether e is about a node rather than an app.
+func (e WebhookEvent) IsNode() bool {
+	return strings.HasPrefix(string(e), "node.")
+}
+
+// Validation errors for webhooks.
+var (
+	ErrWebhookName  = errors.New("webhook name is required")
+	ErrWebhookEvent = errors.New("unknown webhook event")
+	ErrWebhookURL   = errors.New("webhook url must be an absolute http or https URL")
+)
+
+// Webhook is an endpoint of a user that receives a signed JSON payload when
+// a lifecycle event happens in an app the user can access, or for instance
+// admins on a node. Events lists the events it receives and AppID, if set,
+// limits it to one app's events; an empty list subscribes to all events.
+type Webhook struct {
+	ID      string         `json:"id"`
+	UserID  string         `json:"user_id"`
+	Name    string         `json:"name"`
+	URL     string         `json:"url"`
+	Enabled bool           `json:"enabled"`
+	Events  []WebhookEvent `json:"events"`
+	AppID   string         `json:"app_id,omitempty"`
+	// Secret signs payloads with HMAC-SHA256. It is never returned by the API.
+	Secret string `json:"secret,omitempty"`
+
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Validate checks the webhook's name, URL and event subscriptions.
+func (w *Webhook) Validate() error {
+	if strings.TrimSpace(w.Name) == "" {
+		return ErrWebhookName
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrWebhookURL
+	}
+	for _, e := range w.Events {
+		if !e.IsValid() {
+			return fmt.Errorf("%w: %q", ErrWebhookEvent, e)
+		}
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook receives events of type e.
+func (w *Webhook) Subscribes(e WebhookEvent) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, s := range w.Events {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPayload is the JSON body posted to a webhook. ID identifies the
+// event: it is the same in every delivery of the event, including
+// redeliveries, so receivers can skip events they already handled. Fields
+// not relevant to the event are omitted.
+type WebhookPayload struct {
+	ID          string       `json:"id"`
+	Event       WebhookEvent `json:"event"`
+	AppID       string       `json:"app_id,omitempty"`
+	AppName     string       `json:"app_name,omitempty"`
+	ServiceName string       `json:"service_name,omitempty"`
+
+	// Set for deployment events.
+	DeploymentID   string `json:"deployment_id,omitempty"`
+	Version        int    `json:"version,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+
+	// Set for build events.
+	BuildID string        `json:"build_id,omitempty"`
+	Failure *BuildFailure `json:"failure,omitempty"`
+
+	// Set for deployment and build events.
+	GitRef    string `json:"git_ref,omitempty"`
+	GitCommit string `json:"git_commit,omitempty"`
+
+	// Set for node events.
+	NodeID   string `json:"node_id,omitempty"`
+	NodeName string `json:"node_name,omitempty"`
+
+	Message    string    `json:"message,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// WebhookDeliveryStatus is the state of a queued webhook call.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a queued call of one webhook with one payload, and the
+// outcome of its latest attempt. RedeliveryOf is set on deliveries queued
+// again on request, to the delivery they repeat.
+type WebhookDelivery struct {
+	ID            string                `json:"id"`
+	WebhookID     string                `json:"webhook_id"`
+	Payload       WebhookPayload        `json:"payload"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	// ResponseStatus and ResponseBody, truncated, are what the endpoint
+	// answered; zero and empty if it could not be reached.
+	ResponseStatus int    `json:"response_status,omitempty"`
+	ResponseBody   string `json:"response_body,omitempty"`
+	DurationMS     int64  `json:"duration_ms"`
+	LastError      string `json:"last_error,omitempty"`
+	RedeliveryOf   string `json:"redelivery_of,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,292 @@
+// Package notifications delivers build and deployment events to configured
+// providers such as Slack, Discord, Telegram, email, Gotify and webhooks.
+//
+// Events are not sent inline: a Notifier queues one delivery per subscribed
+// provider in the database, and a Worker running in the API server sends them
+// with exponential backoff. This lets the build worker and the API server
+// raise events without waiting on, or failing because of, third-party services.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Dispatcher sends events through the sender for each provider type.
+type Dispatcher struct {
+	senders map[models.NotificationProviderType]Sender
+}
+
+// NewDispatcher creates a dispatcher that makes HTTP requests with client.
+// A nil client uses one with a 10 second timeout.
+func NewDispatcher(client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Dispatcher{
+		senders: map[models.NotificationProviderType]Sender{
+			models.NotificationProviderSlack:    &slackSender{client: client},
+			models.NotificationProviderDiscord:  &discordSender{client: client},
+			models.NotificationProviderTelegram: &telegramSender{client: client, baseURL: "https://api.telegram.org"},
+			models.NotificationProviderEmail:    &emailSender{sendMail: smtp.SendMail},
+			models.NotificationProviderGotify:   &gotifySender{client: client},
+			models.NotificationProviderWebhook:  &webhookSender{client: client},
+		},
+	}
+}
+
+// Validate checks a provider's settings and the config fields its type requires.
+func (d *Dispatcher) Validate(provider *models.NotificationProvider) error {
+	if err := provider.Validate(); err != nil {
+		return err
+	}
+	return d.senders[provider.Type].Validate(provider.Config)
+}
+
+// Send delivers an event to a provider immediately.
+func (d *Dispatcher) Send(ctx context.Context, provider *models.NotificationProvider, event *models.NotificationEvent) error {
+	sender, ok := d.senders[provider.Type]
+	if !ok {
+		return fmt.Errorf("%w: %q", models.ErrNotificationProviderType, provider.Type)
+	}
+	return sender.Send(ctx, provider.Config, event)
+}
+
+// Redact returns a copy of a provider with secret config fields blanked, for API responses.
+func (d *Dispatcher) Redact(provider *models.NotificationProvider) *models.NotificationProvider {
+	redacted := *provider
+	redacted.Config = make(map[string]string, len(provider.Config))
+	for k, v := range provider.Config {
+		redacted.Config[k] = v
+	}
+	if sender, ok := d.senders[provider.Type]; ok {
+		for _, f := range sender.SecretFields() {
+			if redacted.Config[f] != "" {
+				redacted.Config[f] = ""
+			}
+		}
+	}
+	return &redacted
+}
+
+// MergeSecrets copies secret fields left blank in an update from the stored
+// config, so clients can edit a provider without re-entering its credentials.
+func (d *Dispatcher) MergeSecrets(update, existing *models.NotificationProvider) {
+	sender, ok := d.senders[update.Type]
+	if !ok {
+		return
+	}
+	if update.Config == nil {
+		update.Config = map[string]string{}
+	}
+	for _, f := range sender.SecretFields() {
+		if update.Config[f] == "" {
+			update.Config[f] = existing.Config[f]
+		}
+	}
+}
+
+// Notifier queues events for delivery to every enabled provider subscribed to them.
+type Notifier struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewNotifier creates a new notifier.
+func NewNotifier(st store.Store, logger *slog.Logger) *Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Notifier{store: st, logger: logger}
+}
+
+// Notify queues an event. Failures are logged rather than returned so that a
+// notification problem never fails the build or deployment that raised it.
+func (n *Notifier) Notify(ctx context.Context, event *models.NotificationEvent) {
+	n.enqueue(ctx, event)
+}
+
+// HandleTransition queues the events a transition published on the event
+// bus calls for. Unlike Notify it also returns failures to queue them, so the
+// bus delivers the transition again; providers that were queued an event
+// before the failure may then be sent it twice.
+func (n *Notifier) HandleTransition(ctx context.Context, t *events.Transition) error {
+	var raised []*models.NotificationEvent
+	switch t.Kind {
+	case events.TransitionBuildFinished:
+		raised = append(raised, buildFinishedEvent(t.Build))
+	case events.TransitionDeploymentStatus:
+		raised = append(raised, deploymentStatusEvent(t.Deployment, t.PreviousStatus))
+	case events.TransitionNodeHealth:
+		if t.PreviousNode != nil {
+			raised = nodeConditionEvents(t.Node, t.PreviousNode)
+		}
+	}
+	for _, event := range raised {
+		if event == nil {
+			continue
+		}
+		if err := n.enqueue(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue queues an event for every enabled provider subscribed to it,
+// logging and returning failures.
+func (n *Notifier) enqueue(ctx context.Context, event *models.NotificationEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.AppName == "" && event.AppID != "" {
+		if app, err := n.store.Apps().Get(ctx, event.AppID); err == nil && app != nil {
+			event.AppName = app.Name
+		}
+	}
+
+	providers, err := n.store.Notifications().ListProviders(ctx)
+	if err != nil {
+		n.logger.Error("failed to list notification providers", "error", err, "event", event.Type)
+		return fmt.Errorf("listing notification providers: %w", err)
+	}
+	var errs []error
+	for _, p := range providers {
+		if !p.Enabled || !p.Subscribes(event.Type) {
+			continue
+		}
+		delivery := &models.NotificationDelivery{ProviderID: p.ID, Event: *event}
+		if err := n.store.Notifications().EnqueueDelivery(ctx, delivery); err != nil {
+			n.logger.Error("failed to queue notification",
+				"error", err,
+				"provider_id", p.ID,
+				"event", event.Type,
+			)
+			errs = append(errs, fmt.Errorf("queueing %s for provider %s: %w", event.Type, p.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BuildFinished queues a build.succeeded or build.failed event for a build
+// that reached a terminal status. Other statuses are ignored.
+func (n *Notifier) BuildFinished(ctx context.Context, job *models.BuildJob) {
+	if event := buildFinishedEvent(job); event != nil {
+		n.Notify(ctx, event)
+	}
+}
+
+// buildFinishedEvent returns the event for a finished build, or nil for
+// one that has not finished.
+func buildFinishedEvent(job *models.BuildJob) *models.NotificationEvent {
+	event := &models.NotificationEvent{
+		AppID:        job.AppID,
+		ServiceName:  job.ServiceName,
+		DeploymentID: job.DeploymentID,
+		BuildID:      job.ID,
+		GitRef:       job.GitRef,
+	}
+	switch job.Status {
+	case models.BuildStatusSucceeded:
+		event.Type = models.NotificationBuildSucceeded
+		event.Message = "Build finished successfully."
+	case models.BuildStatusFailed:
+		event.Type = models.NotificationBuildFailed
+		event.Message = "Build failed. Check the build logs for details."
+		if f := job.Failure; f != nil {
+			event.Failure = f
+			event.Message = f.Summary
+			if len(f.Suggestions) > 0 {
+				event.Message += " " + f.Suggestions[0]
+			}
+		}
+	default:
+		return nil
+	}
+	return event
+}
+
+// DeploymentStatusChanged queues a deployment.running or deployment.failed
+// event when a deployment moves into one of those statuses from another.
+func (n *Notifier) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if event := deploymentStatusEvent(deployment, previous); event != nil {
+		n.Notify(ctx, event)
+	}
+}
+
+// deploymentStatusEvent returns the event for a deployment status change, or
+// nil if the change is not worth one.
+func deploymentStatusEvent(deployment *models.Deployment, previous models.DeploymentStatus) *models.NotificationEvent {
+	// Cron runs start and exit on every schedule; their history has the outcome
+	if deployment.Status == previous || deployment.IsCronRun() {
+		return nil
+	}
+	event := &models.NotificationEvent{
+		AppID:        deployment.AppID,
+		ServiceName:  deployment.ServiceName,
+		DeploymentID: deployment.ID,
+		GitRef:       deployment.GitRef,
+	}
+	switch deployment.Status {
+	case models.DeploymentStatusRunning:
+		event.Type = models.NotificationDeploymentLive
+		event.Message = fmt.Sprintf("Version %d is running.", deployment.Version)
+	case models.DeploymentStatusFailed:
+		event.Type = models.NotificationDeploymentFailed
+		event.Message = fmt.Sprintf("Version %d failed to start.", deployment.Version)
+	default:
+		return nil
+	}
+	return event
+}
+
+// NodeConditionsChanged queues a node.clock_skew or node.certificate_expiring
+// event when a node's clock or agent certificate needs more attention than it
+// did before.
+func (n *Notifier) NodeConditionsChanged(ctx context.Context, node, previous *models.Node) {
+	for _, event := range nodeConditionEvents(node, previous) {
+		n.Notify(ctx, event)
+	}
+}
+
+// nodeConditionEvents returns the events for the conditions of a node that
+// got worse.
+func nodeConditionEvents(node, previous *models.Node) []*models.NotificationEvent {
+	name := node.Hostname
+	if name == "" {
+		name = node.ID
+	}
+	var raised []*models.NotificationEvent
+	if node.ClockStatus.Worse(previous.ClockStatus) {
+		raised = append(raised, &models.NotificationEvent{
+			Type:     models.NotificationNodeClockSkew,
+			NodeID:   node.ID,
+			NodeName: name,
+			Message: fmt.Sprintf("The clock of node %s is off by %s, so it may reject valid tokens and TLS certificates. Enable time synchronization on the node.",
+				name, node.ClockSkew().Abs().Round(time.Second)),
+		})
+	}
+	if node.CertificateStatus.Worse(previous.CertificateStatus) && node.CertificateExpiresAt != nil {
+		verb := "expires"
+		if node.CertificateExpiresAt.Before(time.Now()) {
+			verb = "expired"
+		}
+		raised = append(raised, &models.NotificationEvent{
+			Type:     models.NotificationNodeCertificate,
+			NodeID:   node.ID,
+			NodeName: name,
+			Message: fmt.Sprintf("The agent certificate of node %s %s on %s. Renew it so the control plane can keep reaching the node.",
+				name, verb, node.CertificateExpiresAt.UTC().Format("2006-01-02 15:04 MST")),
+		})
+	}
+	return raised
+}
@@ -0,0 +1,367 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only the notification and app stores.
+type memStore struct {
+	store.Store
+	notifications *memNotifications
+}
+
+func newMemStore(providers ...*models.NotificationProvider) *memStore {
+	return &memStore{notifications: &memNotifications{providers: providers}}
+}
+
+func (s *memStore) Notifications() store.NotificationStore { return s.notifications }
+func (s *memStore) Apps() store.AppStore                   { return memApps{} }
+
+type memApps struct{ store.AppStore }
+
+func (memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	return &models.App{ID: id, Name: "shop"}, nil
+}
+
+type memNotifications struct {
+	store.NotificationStore
+	providers  []*models.NotificationProvider
+	deliveries []*models.NotificationDelivery
+	lastError  map[string]string
+	enqueueErr error
+}
+
+func (m *memNotifications) ListProviders(ctx context.Context) ([]*models.NotificationProvider, error) {
+	return m.providers, nil
+}
+
+func (m *memNotifications) GetProvider(ctx context.Context, id string) (*models.NotificationProvider, error) {
+	for _, p := range m.providers {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memNotifications) RecordProviderResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	if m.lastError == nil {
+		m.lastError = map[string]string{}
+	}
+	m.lastError[id] = errMsg
+	return nil
+}
+
+func (m *memNotifications) EnqueueDelivery(ctx context.Context, d *models.NotificationDelivery) error {
+	if m.enqueueErr != nil {
+		return m.enqueueErr
+	}
+	d.ID = "d" + string(rune('0'+len(m.deliveries)))
+	d.Status = models.NotificationDeliveryPending
+	d.NextAttemptAt = time.Now()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memNotifications) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error) {
+	var due []*models.NotificationDelivery
+	for _, d := range m.deliveries {
+		if d.Status == models.NotificationDeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memNotifications) UpdateDelivery(ctx context.Context, d *models.NotificationDelivery) error {
+	return nil
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := Backoff(i+1, base, max); got != w {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestNotifierQueuesForSubscribedProviders(t *testing.T) {
+	st := newMemStore(
+		&models.NotificationProvider{ID: "all", Type: models.NotificationProviderSlack, Enabled: true},
+		&models.NotificationProvider{ID: "failures", Type: models.NotificationProviderSlack, Enabled: true,
+			Events: []models.NotificationEventType{models.NotificationBuildFailed}},
+		&models.NotificationProvider{ID: "off", Type: models.NotificationProviderSlack},
+	)
+	n := NewNotifier(st, nil)
+
+	n.BuildFinished(context.Background(), &models.BuildJob{ID: "b1", AppID: "app-1", Status: models.BuildStatusSucceeded})
+	n.BuildFinished(context.Background(), &models.BuildJob{ID: "b2", AppID: "app-1", Status: models.BuildStatusRunning})
+	n.DeploymentStatusChanged(context.Background(),
+		&models.Deployment{ID: "d1", AppID: "app-1", Status: models.DeploymentStatusRunning}, models.DeploymentStatusRunning)
+
+	got := st.notifications.deliveries
+	if len(got) != 1 || got[0].ProviderID != "all" {
+		t.Fatalf("deliveries = %+v, want one for provider all", got)
+	}
+	if got[0].Event.Title() != "Build succeeded: shop" {
+		t.Errorf("title = %q", got[0].Event.Title())
+	}
+
+	n.BuildFinished(context.Background(), &models.BuildJob{ID: "b3", AppID: "app-1", ServiceName: "api", Status: models.BuildStatusFailed})
+	if len(st.notifications.deliveries) != 3 {
+		t.Errorf("failed build queued %d deliveries, want 2", len(st.notifications.deliveries)-1)
+	}
+}
+
+func TestNotifierQueuesNodeConditions(t *testing.T) {
+	st := newMemStore(&models.NotificationProvider{ID: "all", Type: models.NotificationProviderSlack, Enabled: true})
+	n := NewNotifier(st, nil)
+
+	expires := time.Now().Add(48 * time.Hour)
+	previous := &models.Node{ID: "node-1", Hostname: "worker-1", ClockStatus: models.NodeConditionOK}
+	node := &models.Node{ID: "node-1", Hostname: "worker-1", ClockSkewMs: -90_000, CertificateExpiresAt: &expires}
+	node.UpdateConditions(time.Now())
+	n.NodeConditionsChanged(context.Background(), node, previous)
+
+	got := st.notifications.deliveries
+	if len(got) != 2 || got[0].Event.Type != models.NotificationNodeClockSkew || got[1].Event.Type != models.NotificationNodeCertificate {
+		t.Fatalf("deliveries = %+v, want clock skew and certificate events", got)
+	}
+	if got[0].Event.Title() != "Node clock skewed: worker-1" || !strings.Contains(got[0].Event.Message, "off by 1m30s") {
+		t.Errorf("clock skew event = %q: %q", got[0].Event.Title(), got[0].Event.Message)
+	}
+
+	// Conditions that did not get worse are not notified again
+	n.NodeConditionsChanged(context.Background(), node, node)
+	if len(st.notifications.deliveries) != 2 {
+		t.Errorf("unchanged conditions queued %d more deliveries", len(st.notifications.deliveries)-2)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	provider := &models.NotificationProvider{ID: "p1", Type: models.NotificationProviderSlack, Enabled: true,
+		Config: map[string]string{"webhook_url": srv.URL}}
+	st := newMemStore(provider)
+	NewNotifier(st, nil).Notify(context.Background(), &models.NotificationEvent{Type: models.NotificationBuildFailed})
+
+	cfg := DefaultWorkerConfig()
+	w := NewWorker(st, NewDispatcher(srv.Client()), cfg, nil)
+
+	if n := w.ProcessDue(context.Background()); n != 0 {
+		t.Fatalf("first attempt delivered %d, want 0", n)
+	}
+	d := st.notifications.deliveries[0]
+	if d.Status != models.NotificationDeliveryPending || d.Attempts != 1 || !strings.Contains(d.LastError, "429") {
+		t.Fatalf("after failure: %+v", d)
+	}
+	if until := time.Until(d.NextAttemptAt); until < cfg.BaseBackoff-time.Second || until > cfg.BaseBackoff {
+		t.Errorf("next attempt in %s, want about %s", until, cfg.BaseBackoff)
+	}
+	if st.notifications.lastError["p1"] == "" {
+		t.Error("provider error not recorded")
+	}
+
+	d.NextAttemptAt = time.Now()
+	if n := w.ProcessDue(context.Background()); n != 1 {
+		t.Fatalf("retry delivered %d, want 1", n)
+	}
+	if d.Status != models.NotificationDeliveryDelivered || d.DeliveredAt == nil {
+		t.Errorf("after retry: %+v", d)
+	}
+}
+
+func TestNotifierHandlesTransitions(t *testing.T) {
+	st := newMemStore(&models.NotificationProvider{ID: "all", Type: models.NotificationProviderSlack, Enabled: true})
+	n := NewNotifier(st, nil)
+	ctx := context.Background()
+
+	transitions := []*events.Transition{
+		{Kind: events.TransitionBuildFinished, Build: &models.BuildJob{ID: "b1", AppID: "app-1", Status: models.BuildStatusFailed}},
+		{Kind: events.TransitionDeploymentStatus, Deployment: &models.Deployment{ID: "d1", AppID: "app-1", Status: models.DeploymentStatusRunning},
+			PreviousStatus: models.DeploymentStatusStarting},
+		// Heartbeats lapsing are not notified
+		{Kind: events.TransitionNodeHealth, Node: &models.Node{ID: "n1"}, HealthEvent: &models.NodeHealthEvent{NodeID: "n1"}},
+	}
+	for _, tr := range transitions {
+		if err := n.HandleTransition(ctx, tr); err != nil {
+			t.Fatalf("HandleTransition(%s) = %v", tr.Kind, err)
+		}
+	}
+	var types []models.NotificationEventType
+	for _, d := range st.notifications.deliveries {
+		types = append(types, d.Event.Type)
+	}
+	if len(types) != 2 || types[0] != models.NotificationBuildFailed || types[1] != models.NotificationDeploymentLive {
+		t.Errorf("queued %v, want build.failed and deployment.running", types)
+	}
+
+	// Failures to queue are returned so the bus delivers the transition again
+	st.notifications.enqueueErr = errors.New("database is down")
+	if err := n.HandleTransition(ctx, transitions[0]); err == nil {
+		t.Error("HandleTransition() = nil, want the queueing error")
+	}
+}
+
+func TestWorkerGivesUpAfterMaxAttempts(t *testing.T) {
+	provider := &models.NotificationProvider{ID: "p1", Type: models.NotificationProviderDiscord, Enabled: true,
+		Config: map[string]string{"webhook_url": "http://127.0.0.1:1/unreachable"}}
+	st := newMemStore(provider)
+	NewNotifier(st, nil).Notify(context.Background(), &models.NotificationEvent{Type: models.NotificationTest})
+
+	cfg := DefaultWorkerConfig()
+	cfg.MaxAttempts = 2
+	w := NewWorker(st, NewDispatcher(nil), cfg, nil)
+	d := st.notifications.deliveries[0]
+	for i := 0; i < cfg.MaxAttempts; i++ {
+		d.NextAttemptAt = time.Now()
+		w.ProcessDue(context.Background())
+	}
+	if d.Status != models.NotificationDeliveryFailed || d.Attempts != 2 {
+		t.Errorf("delivery = %+v, want failed after 2 attempts", d)
+	}
+}
+
+func TestSenderPayloads(t *testing.T) {
+	var gotPath, gotHeader string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.RequestURI()
+		gotHeader = r.Header.Get("X-Token")
+		data, _ := io.ReadAll(r.Body)
+		gotBody = nil
+		json.Unmarshal(data, &gotBody)
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(srv.Client())
+	d.senders[models.NotificationProviderTelegram].(*telegramSender).baseURL = srv.URL
+	event := &models.NotificationEvent{Type: models.NotificationDeploymentFailed, AppName: "shop", ServiceName: "api", Message: "boom"}
+
+	tests := []struct {
+		provider models.NotificationProviderType
+		config   map[string]string
+		path     string
+		field    string
+	}{
+		{models.NotificationProviderSlack, map[string]string{"webhook_url": srv.URL + "/slack"}, "/slack", "text"},
+		{models.NotificationProviderDiscord, map[string]string{"webhook_url": srv.URL + "/discord"}, "/discord", "content"},
+		{models.NotificationProviderTelegram, map[string]string{"bot_token": "123:abc", "chat_id": "-100"}, "/bot123:abc/sendMessage", "text"},
+		{models.NotificationProviderGotify, map[string]string{"gotify_url": srv.URL + "/", "app_token": "tok"}, "/message?token=tok", "message"},
+		{models.NotificationProviderWebhook, map[string]string{"webhook_url": srv.URL + "/hook", "headers": `{"X-Token":"secret"}`}, "/hook", "message"},
+	}
+	for _, tt := range tests {
+		p := &models.NotificationProvider{Type: tt.provider, Name: string(tt.provider), Config: tt.config}
+		if err := d.Validate(p); err != nil {
+			t.Errorf("%s: Validate() = %v", tt.provider, err)
+			continue
+		}
+		if err := d.Send(context.Background(), p, event); err != nil {
+			t.Errorf("%s: Send() = %v", tt.provider, err)
+			continue
+		}
+		if gotPath != tt.path {
+			t.Errorf("%s: path = %q, want %q", tt.provider, gotPath, tt.path)
+		}
+		if msg, _ := gotBody[tt.field].(string); !strings.Contains(msg, "boom") {
+			t.Errorf("%s: body %v missing message in %q", tt.provider, gotBody, tt.field)
+		}
+	}
+	if gotHeader != "secret" {
+		t.Errorf("webhook custom header = %q, want secret", gotHeader)
+	}
+}
+
+func TestEmailSender(t *testing.T) {
+	var gotAddr string
+	var gotTo []string
+	var gotMsg string
+	sender := &emailSender{sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return nil
+	}}
+	config := map[string]string{"smtp_host": "mail.example.com", "smtp_user": "ops@example.com", "to": "a@example.com, b@example.com"}
+	if err := sender.Validate(config); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	event := &models.NotificationEvent{Type: models.NotificationBuildFailed, AppName: "shop"}
+	if err := sender.Send(context.Background(), config, event); err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	if gotAddr != "mail.example.com:587" || len(gotTo) != 2 || !strings.Contains(gotMsg, "Subject: [Narvana] Build failed: shop") {
+		t.Errorf("addr=%q to=%v msg=%q", gotAddr, gotTo, gotMsg)
+	}
+}
+
+func TestValidateRejectsIncompleteConfig(t *testing.T) {
+	d := NewDispatcher(nil)
+	p := &models.NotificationProvider{Type: models.NotificationProviderTelegram, Name: "ops", Config: map[string]string{"bot_token": "x"}}
+	if err := d.Validate(p); !errors.Is(err, models.ErrNotificationConfigMissing) || !strings.Contains(err.Error(), "chat_id") {
+		t.Errorf("Validate() = %v, want missing chat_id", err)
+	}
+	p = &models.NotificationProvider{Type: "pager", Name: "ops"}
+	if err := d.Validate(p); !errors.Is(err, models.ErrNotificationProviderType) {
+		t.Errorf("Validate() = %v, want unknown type", err)
+	}
+}
+
+func TestRedactAndMergeSecrets(t *testing.T) {
+	d := NewDispatcher(nil)
+	stored := &models.NotificationProvider{Type: models.NotificationProviderTelegram,
+		Config: map[string]string{"bot_token": "123:abc", "chat_id": "-100"}}
+
+	redacted := d.Redact(stored)
+	if redacted.Config["bot_token"] != "" || redacted.Config["chat_id"] != "-100" {
+		t.Errorf("redacted config = %v", redacted.Config)
+	}
+	if stored.Config["bot_token"] != "123:abc" {
+		t.Error("Redact modified the stored provider")
+	}
+
+	update := &models.NotificationProvider{Type: models.NotificationProviderTelegram, Config: map[string]string{"chat_id": "-200"}}
+	d.MergeSecrets(update, stored)
+	if update.Config["bot_token"] != "123:abc" || update.Config["chat_id"] != "-200" {
+		t.Errorf("merged config = %v", update.Config)
+	}
+}
+
+func TestTextQuotesBuildFailure(t *testing.T) {
+	event := &models.NotificationEvent{
+		Type:    models.NotificationBuildFailed,
+		AppName: "shop",
+		Message: "The code failed to compile.",
+		Failure: &models.BuildFailure{
+			Category: models.BuildFailureCompile,
+			Excerpt:  []string{"./main.go:12:2: undefined: handler"},
+		},
+	}
+	msg := text(event)
+	if !strings.Contains(msg, "Last log lines:\n./main.go:12:2: undefined: handler") {
+		t.Errorf("text() = %q, want the failure excerpt", msg)
+	}
+}
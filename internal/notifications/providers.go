@@ -0,0 +1,239 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Sender delivers events through one kind of notification provider.
+type Sender interface {
+	// Validate checks that config holds every field the provider needs.
+	Validate(config map[string]string) error
+	// Send delivers an event using the provider's config.
+	Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error
+	// SecretFields lists config fields that are never returned by the API.
+	SecretFields() []string
+}
+
+// requireFields returns an error naming the first missing config field.
+func requireFields(config map[string]string, fields ...string) error {
+	for _, f := range fields {
+		if strings.TrimSpace(config[f]) == "" {
+			return fmt.Errorf("%w: %s is required", models.ErrNotificationConfigMissing, f)
+		}
+	}
+	return nil
+}
+
+// postJSON sends body as JSON to url and treats any non-2xx response as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, body any, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
+
+// text renders an event as a short plain-text message.
+func text(event *models.NotificationEvent) string {
+	msg := event.Title()
+	if event.Message != "" {
+		msg += "\n" + event.Message
+	}
+	if event.GitRef != "" {
+		msg += "\nRef: " + event.GitRef
+	}
+	if event.Failure != nil && len(event.Failure.Excerpt) > 0 {
+		msg += "\n\nLast log lines:\n" + strings.Join(event.Failure.Excerpt, "\n")
+	}
+	return msg
+}
+
+// slackSender posts to a Slack incoming webhook.
+type slackSender struct {
+	client *http.Client
+}
+
+func (s *slackSender) Validate(config map[string]string) error {
+	return requireFields(config, "webhook_url")
+}
+
+func (s *slackSender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	return postJSON(ctx, s.client, config["webhook_url"], map[string]string{"text": text(event)}, nil)
+}
+
+func (s *slackSender) SecretFields() []string { return []string{"webhook_url"} }
+
+// discordSender posts to a Discord channel webhook.
+type discordSender struct {
+	client *http.Client
+}
+
+func (s *discordSender) Validate(config map[string]string) error {
+	return requireFields(config, "webhook_url")
+}
+
+func (s *discordSender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	return postJSON(ctx, s.client, config["webhook_url"], map[string]string{"content": text(event)}, nil)
+}
+
+func (s *discordSender) SecretFields() []string { return []string{"webhook_url"} }
+
+// telegramSender sends a message through the Telegram Bot API.
+type telegramSender struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (s *telegramSender) Validate(config map[string]string) error {
+	return requireFields(config, "bot_token", "chat_id")
+}
+
+func (s *telegramSender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	endpoint := s.baseURL + "/bot" + config["bot_token"] + "/sendMessage"
+	body := map[string]string{"chat_id": config["chat_id"], "text": text(event)}
+	return postJSON(ctx, s.client, endpoint, body, nil)
+}
+
+func (s *telegramSender) SecretFields() []string { return []string{"bot_token"} }
+
+// gotifySender pushes a message to a Gotify server.
+type gotifySender struct {
+	client *http.Client
+}
+
+func (s *gotifySender) Validate(config map[string]string) error {
+	return requireFields(config, "gotify_url", "app_token")
+}
+
+func (s *gotifySender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	endpoint := strings.TrimRight(config["gotify_url"], "/") + "/message?token=" + url.QueryEscape(config["app_token"])
+	priority := 5
+	if event.Type == models.NotificationBuildFailed || event.Type == models.NotificationDeploymentFailed {
+		priority = 8
+	}
+	body := map[string]any{"title": event.Title(), "message": text(event), "priority": priority}
+	return postJSON(ctx, s.client, endpoint, body, nil)
+}
+
+func (s *gotifySender) SecretFields() []string { return []string{"app_token"} }
+
+// webhookSender posts the event as JSON to any URL, with optional custom
+// headers given as a JSON object.
+type webhookSender struct {
+	client *http.Client
+}
+
+func (s *webhookSender) Validate(config map[string]string) error {
+	if err := requireFields(config, "webhook_url"); err != nil {
+		return err
+	}
+	_, err := webhookHeaders(config)
+	return err
+}
+
+func (s *webhookSender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	headers, err := webhookHeaders(config)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, config["webhook_url"], event, headers)
+}
+
+func (s *webhookSender) SecretFields() []string { return []string{"headers"} }
+
+// webhookHeaders parses the optional "headers" config field.
+func webhookHeaders(config map[string]string) (map[string]string, error) {
+	raw := strings.TrimSpace(config["headers"])
+	if raw == "" {
+		return nil, nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		return nil, fmt.Errorf("%w: headers must be a JSON object of strings", models.ErrNotificationConfigMissing)
+	}
+	return headers, nil
+}
+
+// emailSender sends mail through an SMTP server. Recipients are given as a
+// comma-separated "to" list; "from" defaults to the SMTP username.
+type emailSender struct {
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (s *emailSender) Validate(config map[string]string) error {
+	if err := requireFields(config, "smtp_host", "to"); err != nil {
+		return err
+	}
+	if emailFrom(config) == "" {
+		return fmt.Errorf("%w: from or smtp_user is required", models.ErrNotificationConfigMissing)
+	}
+	return nil
+}
+
+func (s *emailSender) Send(ctx context.Context, config map[string]string, event *models.NotificationEvent) error {
+	port := config["smtp_port"]
+	if port == "" {
+		port = "587"
+	}
+	host := config["smtp_host"]
+
+	var auth smtp.Auth
+	if config["smtp_user"] != "" {
+		auth = smtp.PlainAuth("", config["smtp_user"], config["smtp_password"], host)
+	}
+
+	var to []string
+	for _, addr := range strings.Split(config["to"], ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+
+	from := emailFrom(config)
+	msg := "From: " + from + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
+		"Subject: [Narvana] " + event.Title() + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		text(event) + "\r\n"
+
+	return s.sendMail(net.JoinHostPort(host, port), auth, from, to, []byte(msg))
+}
+
+func (s *emailSender) SecretFields() []string { return []string{"smtp_password"} }
+
+func emailFrom(config map[string]string) string {
+	if config["from"] != "" {
+		return config["from"]
+	}
+	return config["smtp_user"]
+}
@@ -0,0 +1,155 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// WorkerConfig controls how queued deliveries are sent and retried.
+type WorkerConfig struct {
+	// PollInterval is how often the queue is checked for due deliveries.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of deliveries claimed per poll.
+	BatchSize int
+	// MaxAttempts is the number of sends before a delivery is marked failed.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles per attempt.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// Lease is how long a claimed delivery is hidden from other workers.
+	Lease time.Duration
+}
+
+// DefaultWorkerConfig returns a WorkerConfig with sensible defaults: five
+// attempts over roughly fifteen minutes.
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		PollInterval: 5 * time.Second,
+		BatchSize:    20,
+		MaxAttempts:  5,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   10 * time.Minute,
+		Lease:        2 * time.Minute,
+	}
+}
+
+// Backoff returns the delay after the given failed attempt (1-based):
+// base, 2*base, 4*base, ... capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// Worker sends queued deliveries and reschedules failed ones.
+type Worker struct {
+	store      store.Store
+	dispatcher *Dispatcher
+	config     WorkerConfig
+	logger     *slog.Logger
+}
+
+// NewWorker creates a delivery worker.
+func NewWorker(st store.Store, dispatcher *Dispatcher, cfg WorkerConfig, logger *slog.Logger) *Worker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Worker{
+		store:      st,
+		dispatcher: dispatcher,
+		config:     cfg,
+		logger:     logger,
+	}
+}
+
+// Run processes due deliveries every poll interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.ProcessDue(ctx)
+		}
+	}
+}
+
+// ProcessDue claims and sends one batch of due deliveries and returns how
+// many were delivered.
+func (w *Worker) ProcessDue(ctx context.Context) int {
+	deliveries, err := w.store.Notifications().ClaimDueDeliveries(ctx, w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		w.logger.Error("failed to claim notification deliveries", "error", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, d := range deliveries {
+		if w.deliver(ctx, d) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliver sends a single delivery and records the outcome on the delivery and its provider.
+func (w *Worker) deliver(ctx context.Context, d *models.NotificationDelivery) bool {
+	provider, err := w.store.Notifications().GetProvider(ctx, d.ProviderID)
+	if err != nil {
+		w.logger.Error("failed to load notification provider", "error", err, "provider_id", d.ProviderID)
+		return false
+	}
+
+	now := time.Now()
+	d.Attempts++
+	switch {
+	case provider == nil || !provider.Enabled:
+		// The provider was disabled after the event was queued; drop it.
+		d.Status = models.NotificationDeliveryFailed
+		d.LastError = "provider disabled"
+	default:
+		sendErr := w.dispatcher.Send(ctx, provider, &d.Event)
+		if sendErr == nil {
+			d.Status = models.NotificationDeliveryDelivered
+			d.LastError = ""
+			d.DeliveredAt = &now
+		} else {
+			d.LastError = sendErr.Error()
+			if d.Attempts >= w.config.MaxAttempts {
+				d.Status = models.NotificationDeliveryFailed
+			} else {
+				d.NextAttemptAt = now.Add(Backoff(d.Attempts, w.config.BaseBackoff, w.config.MaxBackoff))
+			}
+			w.logger.Warn("notification delivery failed",
+				"delivery_id", d.ID,
+				"provider_id", provider.ID,
+				"attempt", d.Attempts,
+				"error", sendErr,
+			)
+		}
+		if err := w.store.Notifications().RecordProviderResult(ctx, provider.ID, now, d.LastError); err != nil {
+			w.logger.Error("failed to record notification provider result", "error", err, "provider_id", provider.ID)
+		}
+	}
+
+	if err := w.store.Notifications().UpdateDelivery(ctx, d); err != nil {
+		w.logger.Error("failed to update notification delivery", "error", err, "delivery_id", d.ID)
+	}
+	return d.Status == models.NotificationDeliveryDelivered
+}
@@ -0,0 +1,181 @@
+// Package oncall enforces on-call gates: outside business hours, deploys to
+// the apps a gate matches must be made by whoever is on call in its
+// PagerDuty or Opsgenie schedule, or acknowledged by them.
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// cacheTTL is how long a schedule's on-call list is reused, so a burst of
+// deploys doesn't exhaust the provider's rate limit.
+const cacheTTL = time.Minute
+
+// Block describes a deploy stopped by an on-call gate.
+type Block struct {
+	Gate        *models.OnCallGate       `json:"gate"`
+	Requirement models.OnCallRequirement `json:"requirement"`
+	// OnCall lists the emails of whoever is on call, who can deploy or
+	// acknowledge the deploy.
+	OnCall []string `json:"on_call"`
+	// Error is set when the schedule could not be read; deploys are then
+	// blocked rather than let through unchecked.
+	Error string `json:"error,omitempty"`
+}
+
+// Checker checks deploys against an org's on-call gates.
+type Checker struct {
+	store     store.Store
+	providers map[models.OnCallProvider]Provider
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedOnCall
+}
+
+type cachedOnCall struct {
+	emails  []string
+	fetched time.Time
+}
+
+// NewChecker creates a checker that reads schedules with client. A nil
+// client uses one with a 10 second timeout.
+func NewChecker(st store.Store, client *http.Client, logger *slog.Logger) *Checker {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Checker{
+		store: st,
+		providers: map[models.OnCallProvider]Provider{
+			models.OnCallProviderPagerDuty: &pagerDutyProvider{client: client, baseURL: "https://api.pagerduty.com"},
+			models.OnCallProviderOpsgenie:  &opsgenieProvider{client: client, baseURL: "https://api.opsgenie.com"},
+		},
+		logger: logger,
+		now:    time.Now,
+		cache:  make(map[string]cachedOnCall),
+	}
+}
+
+// Matches returns true if the gate covers the app. A match expression that
+// fails to evaluate covers the app, so a broken gate blocks rather than
+// silently lets deploys through.
+func Matches(gate *models.OnCallGate, app *models.App) (bool, error) {
+	if strings.TrimSpace(gate.Match) == "" {
+		return true, nil
+	}
+	expr, err := admission.Compile(gate.Match)
+	if err != nil {
+		return true, err
+	}
+	matched, err := expr.EvalBool(admission.Input(admission.Subject{App: app}))
+	if err != nil {
+		return true, err
+	}
+	return matched, nil
+}
+
+// OnCall returns the lowercased emails of whoever is on call now in the
+// gate's schedule.
+func (c *Checker) OnCall(ctx context.Context, gate *models.OnCallGate) ([]string, error) {
+	key := gate.ID + "\x00" + gate.UpdatedAt.String()
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.fetched) < cacheTTL {
+		return cached.emails, nil
+	}
+
+	provider, ok := c.providers[gate.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown on-call provider %q", gate.Provider)
+	}
+	emails, err := provider.OnCall(ctx, gate.ScheduleID, gate.APIToken)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s schedule %s: %w", gate.Provider, gate.ScheduleID, err)
+	}
+	emails = normalizeEmails(emails)
+
+	c.mu.Lock()
+	c.cache[key] = cachedOnCall{emails: emails, fetched: now}
+	c.mu.Unlock()
+	return emails, nil
+}
+
+// IsOnCall returns true if the email is on call now in the gate's schedule.
+func (c *Checker) IsOnCall(ctx context.Context, gate *models.OnCallGate, email string) (bool, []string, error) {
+	emails, err := c.OnCall(ctx, gate)
+	if err != nil {
+		return false, nil, err
+	}
+	return slices.Contains(emails, strings.ToLower(strings.TrimSpace(email))), emails, nil
+}
+
+// Check returns the first of the app's org gates that stops the user
+// deploying it now, or nil if none does. ackID names an approved
+// acknowledgement, which satisfies gates requiring on_call_ack.
+func (c *Checker) Check(ctx context.Context, app *models.App, userID, ackID string) (*Block, error) {
+	if app.OrgID == "" {
+		return nil, nil
+	}
+	gates, err := c.store.OnCall().ListGates(ctx, app.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("listing on-call gates: %w", err)
+	}
+
+	now := c.now()
+	var user *store.User
+	for _, gate := range gates {
+		if !gate.Enabled || gate.InBusinessHours(now) {
+			continue
+		}
+		matched, err := Matches(gate, app)
+		if err != nil {
+			c.logger.Warn("on-call gate match failed", "gate_id", gate.ID, "app_id", app.ID, "error", err)
+		}
+		if !matched {
+			continue
+		}
+
+		if ackID != "" && gate.Requirement == models.OnCallRequireAck {
+			ack, err := c.store.OnCall().GetAck(ctx, ackID)
+			if err != nil {
+				return nil, fmt.Errorf("getting on-call ack: %w", err)
+			}
+			if ack != nil && ack.Allows(gate.ID, app.ID, userID, now) {
+				continue
+			}
+		}
+
+		if user == nil {
+			if user, err = c.store.Users().GetByID(ctx, userID); err != nil {
+				return nil, fmt.Errorf("getting user: %w", err)
+			}
+		}
+		onCall, emails, err := c.IsOnCall(ctx, gate, user.Email)
+		if err != nil {
+			c.logger.Warn("failed to read on-call schedule", "gate_id", gate.ID, "error", err)
+			return &Block{Gate: gate, Requirement: gate.Requirement, OnCall: []string{}, Error: err.Error()}, nil
+		}
+		if onCall {
+			continue
+		}
+		return &Block{Gate: gate, Requirement: gate.Requirement, OnCall: emails}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,176 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing gates, acks and users.
+type memStore struct {
+	store.Store
+	onCall *memOnCall
+	users  map[string]*store.User
+}
+
+func (s *memStore) OnCall() store.OnCallStore { return s.onCall }
+func (s *memStore) Users() store.UserStore    { return memUsers{s: s} }
+
+type memUsers struct {
+	store.UserStore
+	s *memStore
+}
+
+func (u memUsers) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return u.s.users[id], nil
+}
+
+type memOnCall struct {
+	store.OnCallStore
+	gates []*models.OnCallGate
+	acks  map[string]*models.OnCallAck
+}
+
+func (m *memOnCall) ListGates(ctx context.Context, orgID string) ([]*models.OnCallGate, error) {
+	return m.gates, nil
+}
+
+func (m *memOnCall) GetAck(ctx context.Context, id string) (*models.OnCallAck, error) {
+	return m.acks[id], nil
+}
+
+// newTestChecker points every provider's API at srv.
+func newTestChecker(st store.Store, srv *httptest.Server, now time.Time) *Checker {
+	c := NewChecker(st, srv.Client(), nil)
+	c.providers[models.OnCallProviderPagerDuty].(*pagerDutyProvider).baseURL = srv.URL
+	c.providers[models.OnCallProviderOpsgenie].(*opsgenieProvider).baseURL = srv.URL
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestProviderRequests(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.RequestURI()+" "+r.Header.Get("Authorization"))
+		if r.URL.Path == "/oncalls" {
+			w.Write([]byte(`{"oncalls":[{"user":{"email":"Alice@Example.com"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"onCallRecipients":["bob@example.com"]}}`))
+	}))
+	defer srv.Close()
+	c := newTestChecker(&memStore{}, srv, time.Now())
+
+	tests := []struct {
+		gate   *models.OnCallGate
+		want   string
+		emails []string
+	}{
+		{&models.OnCallGate{ID: "g1", Provider: models.OnCallProviderPagerDuty, ScheduleID: "P1", APIToken: "pd"},
+			"/oncalls?earliest=true&include%5B%5D=users&schedule_ids%5B%5D=P1 Token token=pd", []string{"alice@example.com"}},
+		{&models.OnCallGate{ID: "g2", Provider: models.OnCallProviderOpsgenie, ScheduleID: "s 1", APIToken: "og"},
+			"/v2/schedules/s%201/on-calls?scheduleIdentifierType=id&flat=true GenieKey og", []string{"bob@example.com"}},
+	}
+	for _, tt := range tests {
+		got = nil
+		emails, err := c.OnCall(context.Background(), tt.gate)
+		if err != nil {
+			t.Fatalf("%s: OnCall() error = %v", tt.gate.Provider, err)
+		}
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: requests = %q, want %q", tt.gate.Provider, got, tt.want)
+		}
+		if !slices.Equal(emails, tt.emails) {
+			t.Errorf("%s: OnCall() = %v, want %v", tt.gate.Provider, emails, tt.emails)
+		}
+
+		// A second lookup within a minute is served from the cache
+		got = nil
+		c.OnCall(context.Background(), tt.gate)
+		if len(got) != 0 {
+			t.Errorf("%s: cached lookup sent %q", tt.gate.Provider, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	failing := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"oncalls":[{"user":{"email":"oncall@example.com"}}]}`))
+	}))
+	defer srv.Close()
+
+	// Saturday 22:00 UTC is outside the default business hours
+	night := time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)
+	gate := &models.OnCallGate{
+		ID: "gate-1", OrgID: "org-1", Name: "Production", Match: `app.name.endsWith("-production")`,
+		Provider: models.OnCallProviderPagerDuty, ScheduleID: "P1", APIToken: "t",
+		Requirement: models.OnCallRequireAck, Enabled: true,
+	}
+	if err := gate.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	st := &memStore{
+		onCall: &memOnCall{
+			gates: []*models.OnCallGate{gate},
+			acks: map[string]*models.OnCallAck{
+				"ack-1": {ID: "ack-1", GateID: "gate-1", AppID: "app-1", RequestedBy: "dev",
+					Status: models.OnCallAckApproved, ExpiresAt: night.Add(time.Hour)},
+			},
+		},
+		users: map[string]*store.User{
+			"dev":    {ID: "dev", Email: "dev@example.com"},
+			"oncall": {ID: "oncall", Email: "OnCall@example.com"},
+		},
+	}
+	prod := &models.App{ID: "app-1", OrgID: "org-1", Name: "shop-production"}
+	staging := &models.App{ID: "app-2", OrgID: "org-1", Name: "shop-staging"}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		app     *models.App
+		userID  string
+		ackID   string
+		failing bool
+		blocked bool
+	}{
+		{"off hours, not on call", night, prod, "dev", "", false, true},
+		{"off hours, on call", night, prod, "oncall", "", false, false},
+		{"business hours", time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC), prod, "dev", "", false, false},
+		{"unmatched app", night, staging, "dev", "", false, false},
+		{"approved ack", night, prod, "dev", "ack-1", false, false},
+		{"expired ack", night.Add(2 * time.Hour), prod, "dev", "ack-1", false, true},
+		{"provider error", night, prod, "oncall", "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing = tt.failing
+			c := newTestChecker(st, srv, tt.now)
+			block, err := c.Check(context.Background(), tt.app, tt.userID, tt.ackID)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if (block != nil) != tt.blocked {
+				t.Fatalf("Check() = %+v, want blocked %v", block, tt.blocked)
+			}
+			if block != nil && tt.failing && !strings.Contains(block.Error, "401") {
+				t.Errorf("Check() error = %q, want the provider's status", block.Error)
+			}
+			if block != nil && !tt.failing && !slices.Equal(block.OnCall, []string{"oncall@example.com"}) {
+				t.Errorf("Check() on call = %v", block.OnCall)
+			}
+		})
+	}
+}
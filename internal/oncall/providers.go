@@ -0,0 +1,121 @@
+package oncall
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Provider reads who is on call in one kind of schedule.
+type Provider interface {
+	// OnCall returns the emails of the people on call now in a schedule.
+	OnCall(ctx context.Context, scheduleID, token string) ([]string, error)
+}
+
+// get sends a GET request with the given headers and treats any non-2xx
+// response as an error.
+func get(ctx context.Context, client *http.Client, target string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := data
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, fmt.Errorf("schedule provider returned %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return data, nil
+}
+
+// pagerDutyProvider reads the on-call users of a PagerDuty schedule with a
+// read-only REST API key.
+type pagerDutyProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (p *pagerDutyProvider) OnCall(ctx context.Context, scheduleID, token string) ([]string, error) {
+	query := url.Values{
+		"schedule_ids[]": {scheduleID},
+		"include[]":      {"users"},
+		"earliest":       {"true"},
+	}
+	data, err := get(ctx, p.client, p.baseURL+"/oncalls?"+query.Encode(), map[string]string{
+		"Authorization": "Token token=" + token,
+		"Accept":        "application/vnd.pagerduty+json;version=2",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		OnCalls []struct {
+			User struct {
+				Email string `json:"email"`
+			} `json:"user"`
+		} `json:"oncalls"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding PagerDuty response: %w", err)
+	}
+	emails := make([]string, 0, len(result.OnCalls))
+	for _, oc := range result.OnCalls {
+		emails = append(emails, oc.User.Email)
+	}
+	return emails, nil
+}
+
+// opsgenieProvider reads the on-call recipients of an Opsgenie schedule
+// with an API key allowed to read configuration.
+type opsgenieProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func (p *opsgenieProvider) OnCall(ctx context.Context, scheduleID, token string) ([]string, error) {
+	target := p.baseURL + "/v2/schedules/" + url.PathEscape(scheduleID) +
+		"/on-calls?scheduleIdentifierType=id&flat=true"
+	data, err := get(ctx, p.client, target, map[string]string{"Authorization": "GenieKey " + token})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			OnCallRecipients []string `json:"onCallRecipients"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding Opsgenie response: %w", err)
+	}
+	return result.Data.OnCallRecipients, nil
+}
+
+// normalizeEmails lowercases emails and drops empty ones.
+func normalizeEmails(emails []string) []string {
+	result := make([]string, 0, len(emails))
+	for _, e := range emails {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
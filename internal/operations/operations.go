@@ -0,0 +1,228 @@
+// Package operations runs long operations, such as node drains and cleanups,
+// in the background and records their progress, so the endpoints starting
+// them can return right away and clients follow them at /v1/operations/{id}.
+package operations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how long operations are kept.
+type Config struct {
+	// StaleAfter is how long a running operation may go without an update
+	// before it is failed, e.g. because the server running it stopped.
+	// Running operations are updated at a third of it.
+	StaleAfter time.Duration
+	// Retention is how long finished operations are kept.
+	Retention time.Duration
+	// SweepInterval is how often stale and expired operations are handled.
+	SweepInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		StaleAfter:    10 * time.Minute,
+		Retention:     7 * 24 * time.Hour,
+		SweepInterval: time.Minute,
+	}
+}
+
+// Func is the work of an operation. It reports progress through p and
+// returns the error that failed the operation, if any.
+type Func func(ctx context.Context, p *Progress) error
+
+// Manager starts operations and records their outcome.
+type Manager struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+	wg     sync.WaitGroup
+}
+
+// NewManager creates an operation manager.
+func NewManager(st store.Store, cfg Config, logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Start records op as running and runs fn in the background. The operation
+// outlives the request that started it: fn's context is not cancelled when
+// ctx is.
+func (m *Manager) Start(ctx context.Context, op *models.Operation, fn Func) error {
+	op.Status = models.OperationRunning
+	if err := m.store.Operations().Create(ctx, op); err != nil {
+		return err
+	}
+	m.logger.Info("operation started", "operation_id", op.ID, "kind", op.Kind, "target_id", op.TargetID)
+
+	p := &Progress{manager: m, op: *op}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(context.WithoutCancel(ctx), p, fn)
+	}()
+	return nil
+}
+
+// Wait waits for the operations started by this manager to finish.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// run runs fn, keeping the operation from going stale, and records its
+// outcome. A panic fails the operation instead of the server.
+func (m *Manager) run(ctx context.Context, p *Progress, fn Func) {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(max(m.config.StaleAfter/3, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.touch(ctx)
+			}
+		}
+	}()
+
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("operation panicked: %v", r)
+			}
+		}()
+		err = fn(ctx, p)
+	}()
+	close(stop)
+	p.finish(ctx, err)
+}
+
+// Run fails stale operations and deletes expired ones every sweep interval
+// until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	m.Sweep(ctx)
+
+	ticker := time.NewTicker(m.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep fails running operations that stopped being updated and deletes
+// finished ones past their retention.
+func (m *Manager) Sweep(ctx context.Context) {
+	now := m.now()
+	if failed, err := m.store.Operations().FailStale(ctx, now.Add(-m.config.StaleAfter)); err != nil {
+		m.logger.Error("failed to fail stale operations", "error", err)
+	} else if failed > 0 {
+		m.logger.Warn("failed stale operations", "count", failed)
+	}
+	if m.config.Retention <= 0 {
+		return
+	}
+	if deleted, err := m.store.Operations().DeleteFinishedBefore(ctx, now.Add(-m.config.Retention)); err != nil {
+		m.logger.Error("failed to delete finished operations", "error", err)
+	} else if deleted > 0 {
+		m.logger.Info("deleted finished operations", "count", deleted)
+	}
+}
+
+// Progress reports the progress of a running operation. Each report is
+// saved, so it should be called per step rather than per item of a large
+// batch.
+type Progress struct {
+	manager *Manager
+	mu      sync.Mutex
+	op      models.Operation
+}
+
+// Set records the percentage done, clamped to 0 to 100, and the current
+// stage.
+func (p *Progress) Set(ctx context.Context, percent int, stage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.op.Progress = min(max(percent, 0), 100)
+	p.op.Stage = stage
+	p.saveLocked(ctx)
+}
+
+// AddError records a problem that does not stop the operation.
+func (p *Progress) AddError(ctx context.Context, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.op.Errors = append(p.op.Errors, err.Error())
+	p.saveLocked(ctx)
+}
+
+// SetResult records a kind-specific total, e.g. items_removed. It is saved
+// with the next report.
+func (p *Progress) SetResult(key string, value any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.op.Result == nil {
+		p.op.Result = make(map[string]any)
+	}
+	p.op.Result[key] = value
+}
+
+// touch saves the operation unchanged so it does not go stale.
+func (p *Progress) touch(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.saveLocked(ctx)
+}
+
+// finish records the operation as succeeded, or as failed with err.
+func (p *Progress) finish(ctx context.Context, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.manager.now()
+	p.op.FinishedAt = &now
+	if err != nil {
+		p.op.Status = models.OperationFailed
+		p.op.Error = err.Error()
+	} else {
+		p.op.Status = models.OperationSucceeded
+		p.op.Progress = 100
+	}
+	p.saveLocked(ctx)
+
+	p.manager.logger.Info("operation finished",
+		"operation_id", p.op.ID,
+		"kind", p.op.Kind,
+		"status", p.op.Status,
+		"errors", len(p.op.Errors),
+		"error", p.op.Error,
+	)
+}
+
+func (p *Progress) saveLocked(ctx context.Context) {
+	if err := p.manager.store.Operations().Update(ctx, &p.op); err != nil {
+		p.manager.logger.Error("failed to save operation progress", "operation_id", p.op.ID, "error", err)
+	}
+}
@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only operations. It records
+// every saved state of each operation.
+type memStore struct {
+	store.Store
+	mu      sync.Mutex
+	ops     map[string]*models.Operation
+	history map[string][]models.Operation
+	stale   time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{ops: make(map[string]*models.Operation), history: make(map[string][]models.Operation)}
+}
+
+func (s *memStore) Operations() store.OperationStore { return memOperations{s: s} }
+
+type memOperations struct {
+	store.OperationStore
+	s *memStore
+}
+
+func (m memOperations) Create(ctx context.Context, op *models.Operation) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	op.ID = uuid.New().String()
+	saved := *op
+	m.s.ops[op.ID] = &saved
+	m.s.history[op.ID] = append(m.s.history[op.ID], saved)
+	return nil
+}
+
+func (m memOperations) Update(ctx context.Context, op *models.Operation) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	saved := *op
+	saved.Errors = append([]string(nil), op.Errors...)
+	m.s.ops[op.ID] = &saved
+	m.s.history[op.ID] = append(m.s.history[op.ID], saved)
+	return nil
+}
+
+func (m memOperations) FailStale(ctx context.Context, before time.Time) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+	m.s.stale = before
+	return 0, nil
+}
+
+func (m memOperations) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestStartRecordsProgressAndOutcome(t *testing.T) {
+	st := newMemStore()
+	m := NewManager(st, DefaultConfig(), nil)
+
+	// The operation outlives the request that started it
+	ctx, cancel := context.WithCancel(context.Background())
+	op := &models.Operation{Kind: models.OperationNixGC, CreatedBy: "admin"}
+	err := m.Start(ctx, op, func(ctx context.Context, p *Progress) error {
+		cancel()
+		p.Set(ctx, 50, "Collecting node-1")
+		p.AddError(ctx, errors.New("node-2: agent unreachable"))
+		p.SetResult("paths_removed", 12)
+		p.Set(ctx, 150, "Collecting node-3")
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	m.Wait()
+
+	history := st.history[op.ID]
+	if len(history) != 5 || history[0].Status != models.OperationRunning || history[1].Progress != 50 || history[3].Progress != 100 {
+		t.Fatalf("history = %+v, want created, 50%%, error, clamped 100%% and finished", history)
+	}
+	final := st.ops[op.ID]
+	if final.Status != models.OperationSucceeded || final.FinishedAt == nil || final.Stage != "Collecting node-3" {
+		t.Errorf("final = %+v, want succeeded at the last stage", final)
+	}
+	if len(final.Errors) != 1 || final.Result["paths_removed"] != 12 {
+		t.Errorf("errors = %v, result = %v", final.Errors, final.Result)
+	}
+}
+
+func TestStartRecordsFailure(t *testing.T) {
+	st := newMemStore()
+	m := NewManager(st, DefaultConfig(), nil)
+
+	for _, fn := range []Func{
+		func(ctx context.Context, p *Progress) error { return errors.New("node went away") },
+		func(ctx context.Context, p *Progress) error { panic("boom") },
+	} {
+		op := &models.Operation{Kind: models.OperationNodeDrain, TargetID: "node-1"}
+		if err := m.Start(context.Background(), op, fn); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		m.Wait()
+		if final := st.ops[op.ID]; final.Status != models.OperationFailed || final.Error == "" || final.Progress == 100 {
+			t.Errorf("final = %+v, want failed with an error", final)
+		}
+	}
+}
+
+func TestSweepFailsStaleOperations(t *testing.T) {
+	st := newMemStore()
+	m := NewManager(st, DefaultConfig(), nil)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.Sweep(context.Background())
+	if want := now.Add(-10 * time.Minute); !st.stale.Equal(want) {
+		t.Errorf("failed operations not updated since %v, want %v", st.stale, want)
+	}
+}
@@ -70,6 +70,51 @@ func NewClient(socketPath string, logger *slog.Logger) *Client {
 	}
 }
 
+// DefaultBuildLimits are the build container limits used when neither the
+// worker nor the service configures them.
+var DefaultBuildLimits = ResourceLimits{CPUQuota: 2.0, MemoryMB: 4096, PidsLimit: 1000}
+
+// BuildLimits returns the limits for a build container: the service's
+// build_cpu and build_memory where set, the defaults otherwise.
+func BuildLimits(config *models.BuildConfig, defaults ResourceLimits) *ResourceLimits {
+	limits := defaults
+	if config == nil {
+		return &limits
+	}
+	if config.BuildCPU != "" {
+		var cpu float64
+		fmt.Sscanf(config.BuildCPU, "%f", &cpu)
+		if cpu > 0 {
+			limits.CPUQuota = cpu
+		}
+	}
+	if config.BuildMemory != "" {
+		limits.MemoryMB = parseMemoryToMB(config.BuildMemory)
+	}
+	return &limits
+}
+
+// Args returns the podman flags applying the limits, for both podman run
+// and podman build.
+func (l *ResourceLimits) Args() []string {
+	var args []string
+	if l.CPUQuota > 0 {
+		// Convert CPU quota to period/quota format
+		// Period is 100000 microseconds (100ms), quota is proportional
+		period := 100000
+		quota := int(l.CPUQuota * float64(period))
+		args = append(args, "--cpu-period", fmt.Sprintf("%d", period))
+		args = append(args, "--cpu-quota", fmt.Sprintf("%d", quota))
+	}
+	if l.MemoryMB > 0 {
+		args = append(args, "--memory", fmt.Sprintf("%dm", l.MemoryMB))
+	}
+	if l.PidsLimit > 0 {
+		args = append(args, "--pids-limit", fmt.Sprintf("%d", l.PidsLimit))
+	}
+	return args
+}
+
 // ResourceLimitsFromSpec returns resource limits from a ResourceSpec.
 // If spec is nil, returns default limits (0.5 CPU, 512MB).
 func ResourceLimitsFromSpec(spec *models.ResourceSpec) *ResourceLimits {
@@ -218,6 +263,10 @@ func (c *Client) RunWithStreaming(ctx context.Context, cfg *ContainerConfig, std
 
 	err := cmd.Run()
 	duration := time.Since(start)
+	if ctx.Err() != nil {
+		c.removeCanceled(cfg.Name)
+		return nil, fmt.Errorf("running container: %w", context.Cause(ctx))
+	}
 
 	result := &ContainerResult{
 		Duration: duration,
@@ -234,6 +283,19 @@ func (c *Client) RunWithStreaming(ctx context.Context, cfg *ContainerConfig, std
 	return result, nil
 }
 
+// removeCanceled force-removes the container of a run whose context was
+// canceled: killing the podman client leaves the container running.
+func (c *Client) removeCanceled(name string) {
+	if name == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := c.RemoveContainer(ctx, name); err != nil {
+		c.logger.Warn("failed to remove container of canceled run", "name", name, "error", err)
+	}
+}
+
 // buildRunArgs constructs the podman run command arguments.
 func (c *Client) buildRunArgs(cfg *ContainerConfig) []string {
 	args := []string{"run"}
@@ -289,20 +351,7 @@ func (c *Client) buildRunArgs(cfg *ContainerConfig) []string {
 
 	// Resource limits
 	if cfg.Limits != nil {
-		if cfg.Limits.CPUQuota > 0 {
-			// Convert CPU quota to period/quota format
-			// Period is 100000 microseconds (100ms), quota is proportional
-			period := 100000
-			quota := int(cfg.Limits.CPUQuota * float64(period))
-			args = append(args, "--cpu-period", fmt.Sprintf("%d", period))
-			args = append(args, "--cpu-quota", fmt.Sprintf("%d", quota))
-		}
-		if cfg.Limits.MemoryMB > 0 {
-			args = append(args, "--memory", fmt.Sprintf("%dm", cfg.Limits.MemoryMB))
-		}
-		if cfg.Limits.PidsLimit > 0 {
-			args = append(args, "--pids-limit", fmt.Sprintf("%d", cfg.Limits.PidsLimit))
-		}
+		args = append(args, cfg.Limits.Args()...)
 	}
 
 	// Entrypoint override
@@ -324,6 +373,23 @@ func (c *Client) buildRunArgs(cfg *ContainerConfig) []string {
 	return args
 }
 
+// Ping checks that the Podman service at the client's socket answers.
+func (c *Client) Ping(ctx context.Context) error {
+	var args []string
+	if c.socketPath != "" {
+		args = append(args, "--url", c.socketPath)
+	}
+	args = append(args, "info", "--format", "{{.Version.Version}}")
+
+	cmd := exec.CommandContext(ctx, "podman", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("reaching podman at %s: %w\nOutput: %s", c.socketPath, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
 // Pull pulls an image from a registry.
 func (c *Client) Pull(ctx context.Context, image string) error {
 	c.logger.Debug("pulling image", "image", image)
@@ -419,6 +485,14 @@ func (c *Client) Exec(containerName string, command []string) *exec.Cmd {
 	return exec.Command("podman", args...)
 }
 
+// RunInteractive prepares a podman run command for a container with an
+// interactive terminal attached.
+func (c *Client) RunInteractive(cfg *ContainerConfig) *exec.Cmd {
+	args := c.buildRunArgs(cfg)
+	args = append([]string{args[0], "-it"}, args[1:]...)
+	return exec.Command("podman", args...)
+}
+
 // ContainerInfo holds information about a container.
 type ContainerInfo struct {
 	ID        string
@@ -0,0 +1,390 @@
+// Package promotion evaluates deployments against promotion policies and
+// promotes healthy artifacts to the policy's target app, e.g. from staging to
+// production. It also promotes deployments into the next environment of their
+// app's pipeline on request.
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// ErrNotAwaitingApproval is returned when approving a promotion that is not
+// waiting for approval.
+var ErrNotAwaitingApproval = errors.New("promotion is not awaiting approval")
+
+// target is where a promotion deploys its source artifact.
+type target struct {
+	appID           string
+	serviceName     string
+	requireApproval bool
+}
+
+// Config controls how often promotions are evaluated.
+type Config struct {
+	// PollInterval is how often policies and open promotions are evaluated.
+	PollInterval time.Duration
+	// LogSample is the maximum number of recent runtime log lines used to
+	// compute a deployment's error rate.
+	LogSample int
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 30 * time.Second,
+		LogSample:    5000,
+	}
+}
+
+// Evaluator starts evaluations for new deployments covered by enabled
+// policies and advances open evaluations until they are promoted or fail.
+type Evaluator struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewEvaluator creates a promotion evaluator.
+func NewEvaluator(st store.Store, cfg Config, logger *slog.Logger) *Evaluator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Evaluator{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run evaluates promotions every poll interval until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.EvaluateOnce(ctx)
+		}
+	}
+}
+
+// EvaluateOnce starts evaluations for newly running deployments and advances
+// every open evaluation by one step.
+func (e *Evaluator) EvaluateOnce(ctx context.Context) {
+	policies, err := e.store.Promotions().ListEnabledPolicies(ctx)
+	if err != nil {
+		e.logger.Error("failed to list promotion policies", "error", err)
+		return
+	}
+	for _, policy := range policies {
+		if err := e.start(ctx, policy); err != nil {
+			e.logger.Error("failed to start promotion evaluation", "error", err, "policy_id", policy.ID)
+		}
+	}
+
+	open, err := e.store.Promotions().ListOpen(ctx)
+	if err != nil {
+		e.logger.Error("failed to list open promotions", "error", err)
+		return
+	}
+	for _, p := range open {
+		if err := e.advance(ctx, p); err != nil {
+			e.logger.Error("failed to evaluate promotion", "error", err, "promotion_id", p.ID)
+		}
+	}
+}
+
+// Approve records an owner's approval of a promotion awaiting approval and
+// promotes it, unless the target is frozen, in which case it stays blocked
+// until the freeze ends.
+func (e *Evaluator) Approve(ctx context.Context, p *models.Promotion, userID string) error {
+	if p.Status != models.PromotionAwaitingApproval {
+		return ErrNotAwaitingApproval
+	}
+	to, source, err := e.load(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.DecidedBy = userID
+	if to == nil {
+		return e.finish(ctx, p, models.PromotionFailed, "promotion target no longer exists")
+	}
+	return e.promote(ctx, p, to, source)
+}
+
+// Promote promotes a source deployment into an environment on behalf of a
+// user and returns the promotion recording it. The promotion waits for
+// approval if the environment requires it, and is blocked while the
+// environment's app is in a deploy freeze.
+func (e *Evaluator) Promote(ctx context.Context, source *models.Deployment, env *models.Environment, userID string) (*models.Promotion, error) {
+	p := &models.Promotion{
+		EnvironmentID:      env.ID,
+		SourceDeploymentID: source.ID,
+		Status:             models.PromotionEvaluating,
+		WindowEndsAt:       e.now(),
+		RequestedBy:        userID,
+	}
+	if env.RequireApproval {
+		p.Status = models.PromotionAwaitingApproval
+		p.Reason = fmt.Sprintf("waiting for approval to deploy to %s", env.Name)
+	}
+	if err := e.store.Promotions().Create(ctx, p); err != nil {
+		return nil, fmt.Errorf("creating promotion: %w", err)
+	}
+	e.logger.Info("promotion requested",
+		"promotion_id", p.ID,
+		"environment_id", env.ID,
+		"deployment_id", source.ID,
+		"user_id", userID,
+	)
+	if env.RequireApproval {
+		return p, nil
+	}
+	to := &target{appID: env.EnvAppID, serviceName: source.ServiceName}
+	if err := e.promote(ctx, p, to, source); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// start creates an evaluation for the policy's newest source deployment if it
+// is running and has not been evaluated yet. Deployments started before the
+// policy was created are not evaluated, so adding a policy never promotes
+// an artifact immediately.
+func (e *Evaluator) start(ctx context.Context, policy *models.PromotionPolicy) error {
+	deployments, err := e.store.Deployments().List(ctx, policy.AppID)
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+
+	// Cron and smoke test command runs are not releases of the service
+	var latest *models.Deployment
+	for _, d := range deployments {
+		if d.ServiceName == policy.ServiceName && !d.IsCronRun() {
+			latest = d
+			break
+		}
+	}
+	if latest == nil || latest.Status != models.DeploymentStatusRunning || latest.Artifact == "" {
+		return nil
+	}
+	startedAt := latest.UpdatedAt
+	if latest.StartedAt != nil {
+		startedAt = *latest.StartedAt
+	}
+	if startedAt.Before(policy.CreatedAt) {
+		return nil
+	}
+
+	existing, err := e.store.Promotions().GetBySource(ctx, policy.ID, latest.ID)
+	if err != nil {
+		return fmt.Errorf("checking existing promotion: %w", err)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	p := &models.Promotion{
+		PolicyID:           policy.ID,
+		SourceDeploymentID: latest.ID,
+		Status:             models.PromotionEvaluating,
+		WindowMinutes:      policy.WindowMinutes,
+		MaxErrorRate:       policy.MaxErrorRate,
+		WindowEndsAt:       startedAt.Add(policy.Window()),
+	}
+	if err := e.store.Promotions().Create(ctx, p); err != nil {
+		return fmt.Errorf("creating promotion: %w", err)
+	}
+	e.logger.Info("promotion evaluation started",
+		"promotion_id", p.ID,
+		"policy_id", policy.ID,
+		"deployment_id", latest.ID,
+		"window_ends_at", p.WindowEndsAt,
+	)
+	return nil
+}
+
+// advance moves an open promotion forward: it fails evaluations whose source
+// stopped running or exceeded the error rate, holds those awaiting approval,
+// and promotes the rest once their window has elapsed. Requested promotions
+// into an environment have no window and are promoted directly.
+func (e *Evaluator) advance(ctx context.Context, p *models.Promotion) error {
+	if p.Status == models.PromotionAwaitingApproval {
+		return nil
+	}
+	to, source, err := e.load(ctx, p)
+	if err != nil {
+		return err
+	}
+	if to == nil {
+		return e.finish(ctx, p, models.PromotionFailed, "promotion target no longer exists")
+	}
+	if p.Status == models.PromotionBlocked || p.EnvironmentID != "" {
+		return e.promote(ctx, p, to, source)
+	}
+
+	if source.Status != models.DeploymentStatusRunning {
+		return e.finish(ctx, p, models.PromotionFailed,
+			fmt.Sprintf("deployment stopped running (%s) before the window ended", source.Status))
+	}
+
+	windowStart := p.WindowEndsAt.Add(-time.Duration(p.WindowMinutes) * time.Minute)
+	entries, err := e.store.Logs().ListBySource(ctx, source.ID, "runtime", e.config.LogSample)
+	if err != nil {
+		return fmt.Errorf("listing runtime logs: %w", err)
+	}
+	p.ErrorRate, p.LogLines = models.ErrorRate(entries, windowStart)
+	if p.ErrorRate > p.MaxErrorRate {
+		return e.finish(ctx, p, models.PromotionFailed,
+			fmt.Sprintf("error rate %.1f%% exceeded %.1f%%", p.ErrorRate*100, p.MaxErrorRate*100))
+	}
+
+	if e.now().Before(p.WindowEndsAt) {
+		return e.store.Promotions().Update(ctx, p)
+	}
+	if to.requireApproval {
+		return e.finish(ctx, p, models.PromotionAwaitingApproval, "healthy for the full window; waiting for approval")
+	}
+	return e.promote(ctx, p, to, source)
+}
+
+// promote deploys the source artifact to the target service. The promotion
+// is blocked instead while the target app's org has an active deploy freeze.
+func (e *Evaluator) promote(ctx context.Context, p *models.Promotion, to *target, source *models.Deployment) error {
+	app, err := e.store.Apps().Get(ctx, to.appID)
+	if err != nil {
+		return fmt.Errorf("loading target app: %w", err)
+	}
+	if app == nil {
+		return e.finish(ctx, p, models.PromotionFailed, "target app not found")
+	}
+	var service *models.ServiceConfig
+	for i := range app.Services {
+		if app.Services[i].Name == to.serviceName {
+			service = &app.Services[i]
+			break
+		}
+	}
+	if service == nil {
+		return e.finish(ctx, p, models.PromotionFailed, fmt.Sprintf("target service %q not found", to.serviceName))
+	}
+
+	if app.OrgID != "" {
+		windows, err := e.store.DeployFreezes().ListWindows(ctx, app.OrgID)
+		if err != nil {
+			return fmt.Errorf("listing freeze windows: %w", err)
+		}
+		if active := models.ActiveFreezeWindow(windows, e.now()); active != nil {
+			if p.Status == models.PromotionBlocked {
+				return nil
+			}
+			return e.finish(ctx, p, models.PromotionBlocked, fmt.Sprintf("target deploys are frozen by %q", active.Name))
+		}
+	}
+
+	version, err := e.store.Deployments().GetNextVersion(ctx, app.ID, service.Name)
+	if err != nil {
+		return fmt.Errorf("getting next version: %w", err)
+	}
+
+	// The deployment starts as built so the scheduler picks it up directly
+	now := e.now()
+	deployment := &models.Deployment{
+		ID:          uuid.New().String(),
+		AppID:       app.ID,
+		ServiceName: service.Name,
+		Version:     version,
+		GitRef:      source.GitRef,
+		GitCommit:   source.GitCommit,
+		BuildType:   source.BuildType,
+		Artifact:    source.Artifact,
+		Status:      models.DeploymentStatusBuilt,
+		Resources:   service.Resources,
+		Config: &models.RuntimeConfig{
+			Resources:   service.Resources,
+			EnvVars:     service.EnvVars,
+			Ports:       service.Ports,
+			HealthCheck: service.HealthCheck,
+			Egress:      service.Egress,
+		},
+		DependsOn: service.DependsOn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	p.Status = models.PromotionPromoted
+	p.TargetDeploymentID = deployment.ID
+	p.Reason = ""
+	err = e.store.WithTx(ctx, func(txStore store.Store) error {
+		if err := txStore.Deployments().Create(ctx, deployment); err != nil {
+			return err
+		}
+		return txStore.Promotions().Update(ctx, p)
+	})
+	if err != nil {
+		return fmt.Errorf("promoting deployment: %w", err)
+	}
+
+	e.logger.Info("deployment promoted",
+		"promotion_id", p.ID,
+		"source_deployment_id", source.ID,
+		"target_deployment_id", deployment.ID,
+		"target_app_id", app.ID,
+		"service_name", service.Name,
+	)
+	return nil
+}
+
+// load fetches the promotion's target, from its policy or environment, and
+// its source deployment. Both are nil if the policy or environment no longer
+// exists.
+func (e *Evaluator) load(ctx context.Context, p *models.Promotion) (*target, *models.Deployment, error) {
+	source, err := e.store.Deployments().Get(ctx, p.SourceDeploymentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading source deployment: %w", err)
+	}
+
+	if p.EnvironmentID != "" {
+		env, err := e.store.Environments().Get(ctx, p.EnvironmentID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading environment: %w", err)
+		}
+		if env == nil {
+			return nil, nil, nil
+		}
+		return &target{appID: env.EnvAppID, serviceName: source.ServiceName, requireApproval: env.RequireApproval}, source, nil
+	}
+
+	policy, err := e.store.Promotions().GetPolicy(ctx, p.PolicyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading promotion policy: %w", err)
+	}
+	if policy == nil {
+		return nil, nil, nil
+	}
+	return &target{appID: policy.TargetAppID, serviceName: policy.TargetServiceName, requireApproval: policy.RequireApproval}, source, nil
+}
+
+// finish records a promotion's new status and the reason for it.
+func (e *Evaluator) finish(ctx context.Context, p *models.Promotion, status models.PromotionStatus, reason string) error {
+	p.Status = status
+	p.Reason = reason
+	if err := e.store.Promotions().Update(ctx, p); err != nil {
+		return fmt.Errorf("updating promotion: %w", err)
+	}
+	e.logger.Info("promotion updated", "promotion_id", p.ID, "status", status, "reason", reason)
+	return nil
+}
@@ -0,0 +1,364 @@
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the evaluator uses.
+type memStore struct {
+	store.Store
+	apps        map[string]*models.App
+	deployments []*models.Deployment
+	logs        []*models.LogEntry
+	freezes     []*models.FreezeWindow
+	promotions  *memPromotions
+	envs        []*models.Environment
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		apps: map[string]*models.App{
+			"staging":    {ID: "staging", Services: []models.ServiceConfig{{Name: "web"}}},
+			"production": {ID: "production", OrgID: "org-1", Services: []models.ServiceConfig{{Name: "web"}}},
+		},
+		promotions: &memPromotions{},
+	}
+}
+
+func (s *memStore) Apps() store.AppStore                   { return memApps{s: s} }
+func (s *memStore) Deployments() store.DeploymentStore     { return memDeployments{s: s} }
+func (s *memStore) Logs() store.LogStore                   { return memLogs{s: s} }
+func (s *memStore) DeployFreezes() store.DeployFreezeStore { return memFreezes{s: s} }
+func (s *memStore) Promotions() store.PromotionStore       { return s.promotions }
+func (s *memStore) Environments() store.EnvironmentStore   { return memEnvironments{s: s} }
+
+func (s *memStore) WithTx(ctx context.Context, fn func(store.Store) error) error { return fn(s) }
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (m memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	return m.s.apps[id], nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Create(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments = append([]*models.Deployment{d}, m.s.deployments...)
+	return nil
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	for _, d := range m.s.deployments {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("deployment %s not found", id)
+}
+
+func (m memDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	var out []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.AppID == appID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m memDeployments) GetNextVersion(ctx context.Context, appID, serviceName string) (int, error) {
+	version := 1
+	for _, d := range m.s.deployments {
+		if d.AppID == appID && d.ServiceName == serviceName && d.Version >= version {
+			version = d.Version + 1
+		}
+	}
+	return version, nil
+}
+
+type memLogs struct {
+	store.LogStore
+	s *memStore
+}
+
+func (m memLogs) ListBySource(ctx context.Context, deploymentID, source string, limit int) ([]*models.LogEntry, error) {
+	return m.s.logs, nil
+}
+
+type memFreezes struct {
+	store.DeployFreezeStore
+	s *memStore
+}
+
+func (m memFreezes) ListWindows(ctx context.Context, orgID string) ([]*models.FreezeWindow, error) {
+	return m.s.freezes, nil
+}
+
+type memEnvironments struct {
+	store.EnvironmentStore
+	s *memStore
+}
+
+func (m memEnvironments) Get(ctx context.Context, id string) (*models.Environment, error) {
+	for _, e := range m.s.envs {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+type memPromotions struct {
+	store.PromotionStore
+	policies   []*models.PromotionPolicy
+	promotions []*models.Promotion
+}
+
+func (m *memPromotions) ListEnabledPolicies(ctx context.Context) ([]*models.PromotionPolicy, error) {
+	return m.policies, nil
+}
+
+func (m *memPromotions) GetPolicy(ctx context.Context, id string) (*models.PromotionPolicy, error) {
+	for _, p := range m.policies {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memPromotions) Create(ctx context.Context, p *models.Promotion) error {
+	p.ID = fmt.Sprintf("promo-%d", len(m.promotions)+1)
+	m.promotions = append(m.promotions, p)
+	return nil
+}
+
+func (m *memPromotions) GetBySource(ctx context.Context, policyID, sourceID string) (*models.Promotion, error) {
+	for _, p := range m.promotions {
+		if p.PolicyID == policyID && p.SourceDeploymentID == sourceID {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memPromotions) ListOpen(ctx context.Context) ([]*models.Promotion, error) {
+	var out []*models.Promotion
+	for _, p := range m.promotions {
+		if !p.Status.IsTerminal() {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memPromotions) Update(ctx context.Context, p *models.Promotion) error { return nil }
+
+func logLines(at time.Time, total, errs int) []*models.LogEntry {
+	entries := make([]*models.LogEntry, 0, total)
+	for i := 0; i < total; i++ {
+		level := "info"
+		if i < errs {
+			level = "error"
+		}
+		entries = append(entries, &models.LogEntry{Level: level, Timestamp: at})
+	}
+	return entries
+}
+
+func TestEvaluator(t *testing.T) {
+	policyCreated := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	started := policyCreated.Add(time.Hour)
+
+	tests := []struct {
+		name            string
+		elapsed         time.Duration
+		status          models.DeploymentStatus
+		errors          int
+		requireApproval bool
+		frozen          bool
+		want            models.PromotionStatus
+	}{
+		{"inside window", 10 * time.Minute, models.DeploymentStatusRunning, 0, false, false, models.PromotionEvaluating},
+		{"healthy window promotes", 31 * time.Minute, models.DeploymentStatusRunning, 1, false, false, models.PromotionPromoted},
+		{"error rate fails early", 10 * time.Minute, models.DeploymentStatusRunning, 20, false, false, models.PromotionFailed},
+		{"stopped deployment fails", 31 * time.Minute, models.DeploymentStatusFailed, 0, false, false, models.PromotionFailed},
+		{"approval gate holds", 31 * time.Minute, models.DeploymentStatusRunning, 0, true, false, models.PromotionAwaitingApproval},
+		{"freeze blocks", 31 * time.Minute, models.DeploymentStatusRunning, 0, false, true, models.PromotionBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newMemStore()
+			st.promotions.policies = []*models.PromotionPolicy{{
+				ID: "policy-1", AppID: "staging", ServiceName: "web",
+				TargetAppID: "production", TargetServiceName: "web",
+				WindowMinutes: 30, MaxErrorRate: 0.05, RequireApproval: tt.requireApproval,
+				Enabled: true, CreatedAt: policyCreated,
+			}}
+			source := &models.Deployment{
+				ID: "dep-1", AppID: "staging", ServiceName: "web", Version: 3,
+				Artifact: "/nix/store/abc-web", BuildType: models.BuildTypePureNix,
+				Status: models.DeploymentStatusRunning, StartedAt: &started,
+			}
+			st.deployments = []*models.Deployment{source}
+			st.logs = logLines(started.Add(time.Minute), 100, tt.errors)
+			if tt.frozen {
+				from, until := started, started.Add(24*time.Hour)
+				st.freezes = []*models.FreezeWindow{{Name: "release", Kind: models.FreezeWindowRange, StartsAt: &from, EndsAt: &until}}
+			}
+
+			e := NewEvaluator(st, DefaultConfig(), nil)
+			e.now = func() time.Time { return started.Add(10 * time.Minute) }
+			e.EvaluateOnce(context.Background())
+			if len(st.promotions.promotions) != 1 {
+				t.Fatalf("promotions = %d, want 1", len(st.promotions.promotions))
+			}
+
+			source.Status = tt.status
+			e.now = func() time.Time { return started.Add(tt.elapsed) }
+			e.EvaluateOnce(context.Background())
+
+			p := st.promotions.promotions[0]
+			if p.Status != tt.want {
+				t.Fatalf("status = %s, want %s (reason %q)", p.Status, tt.want, p.Reason)
+			}
+			if p.WindowMinutes != 30 || !p.WindowEndsAt.Equal(started.Add(30*time.Minute)) {
+				t.Errorf("criteria not recorded: %+v", p)
+			}
+
+			if tt.want != models.PromotionPromoted {
+				if p.TargetDeploymentID != "" {
+					t.Error("unpromoted evaluation has a target deployment")
+				}
+				return
+			}
+			target, _ := st.Deployments().Get(context.Background(), p.TargetDeploymentID)
+			if target == nil || target.AppID != "production" || target.Artifact != source.Artifact ||
+				target.Status != models.DeploymentStatusBuilt || target.BuildType != source.BuildType {
+				t.Errorf("unexpected target deployment: %+v", target)
+			}
+		})
+	}
+}
+
+func TestEvaluatorSkipsDeploymentsBeforePolicy(t *testing.T) {
+	st := newMemStore()
+	created := time.Now()
+	started := created.Add(-time.Hour)
+	st.promotions.policies = []*models.PromotionPolicy{{
+		ID: "policy-1", AppID: "staging", ServiceName: "web", TargetAppID: "production",
+		TargetServiceName: "web", WindowMinutes: 5, Enabled: true, CreatedAt: created,
+	}}
+	st.deployments = []*models.Deployment{{
+		ID: "dep-1", AppID: "staging", ServiceName: "web", Artifact: "/nix/store/abc-web",
+		Status: models.DeploymentStatusRunning, StartedAt: &started,
+	}}
+
+	NewEvaluator(st, DefaultConfig(), nil).EvaluateOnce(context.Background())
+	if len(st.promotions.promotions) != 0 {
+		t.Fatalf("deployment started before the policy was evaluated: %+v", st.promotions.promotions[0])
+	}
+}
+
+func TestApprove(t *testing.T) {
+	st := newMemStore()
+	started := time.Now().Add(-time.Hour)
+	st.promotions.policies = []*models.PromotionPolicy{{
+		ID: "policy-1", AppID: "staging", ServiceName: "web", TargetAppID: "production",
+		TargetServiceName: "web", WindowMinutes: 30, RequireApproval: true, Enabled: true,
+	}}
+	st.deployments = []*models.Deployment{{
+		ID: "dep-1", AppID: "staging", ServiceName: "web", Artifact: "/nix/store/abc-web",
+		Status: models.DeploymentStatusRunning, StartedAt: &started,
+	}}
+	p := &models.Promotion{ID: "promo-1", PolicyID: "policy-1", SourceDeploymentID: "dep-1", Status: models.PromotionEvaluating}
+
+	e := NewEvaluator(st, DefaultConfig(), nil)
+	if err := e.Approve(context.Background(), p, "user-1"); err != ErrNotAwaitingApproval {
+		t.Fatalf("Approve of evaluating promotion = %v, want ErrNotAwaitingApproval", err)
+	}
+
+	p.Status = models.PromotionAwaitingApproval
+	if err := e.Approve(context.Background(), p, "user-1"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if p.Status != models.PromotionPromoted || p.DecidedBy != "user-1" || p.TargetDeploymentID == "" {
+		t.Errorf("unexpected promotion after approval: %+v", p)
+	}
+}
+
+func TestPromoteToEnvironment(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		requireApproval bool
+		frozen          bool
+		want            models.PromotionStatus
+	}{
+		{"promotes directly", false, false, models.PromotionPromoted},
+		{"waits for approval", true, false, models.PromotionAwaitingApproval},
+		{"freeze blocks", false, true, models.PromotionBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newMemStore()
+			env := &models.Environment{
+				ID: "env-prod", AppID: "staging", Name: "production", Position: 2,
+				EnvAppID: "production", RequireApproval: tt.requireApproval,
+			}
+			st.envs = []*models.Environment{env}
+			source := &models.Deployment{
+				ID: "dep-1", AppID: "staging", ServiceName: "web", Artifact: "/nix/store/abc-web",
+				Status: models.DeploymentStatusRunning,
+			}
+			st.deployments = []*models.Deployment{source}
+			if tt.frozen {
+				from, until := now.Add(-time.Hour), now.Add(time.Hour)
+				st.freezes = []*models.FreezeWindow{{Name: "release", Kind: models.FreezeWindowRange, StartsAt: &from, EndsAt: &until}}
+			}
+
+			e := NewEvaluator(st, DefaultConfig(), nil)
+			e.now = func() time.Time { return now }
+			p, err := e.Promote(context.Background(), source, env, "user-1")
+			if err != nil {
+				t.Fatalf("Promote: %v", err)
+			}
+			if p.Status != tt.want || p.EnvironmentID != env.ID || p.RequestedBy != "user-1" {
+				t.Fatalf("promotion = %+v, want %s into %s requested by user-1", p, tt.want, env.ID)
+			}
+			if tt.want != models.PromotionAwaitingApproval {
+				return
+			}
+
+			// The evaluator leaves it for an owner to approve
+			e.EvaluateOnce(context.Background())
+			if p.Status != models.PromotionAwaitingApproval {
+				t.Fatalf("status after evaluation = %s, want awaiting_approval", p.Status)
+			}
+			if err := e.Approve(context.Background(), p, "user-2"); err != nil {
+				t.Fatalf("Approve: %v", err)
+			}
+			if p.Status != models.PromotionPromoted || p.DecidedBy != "user-2" {
+				t.Fatalf("promotion after approval = %+v, want promoted and decided by user-2", p)
+			}
+			target, _ := st.Deployments().Get(context.Background(), p.TargetDeploymentID)
+			if target == nil || target.AppID != "production" || target.Artifact != source.Artifact {
+				t.Errorf("unexpected target deployment: %+v", target)
+			}
+		})
+	}
+}
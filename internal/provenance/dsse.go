@@ -0,0 +1,173 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// PayloadType is the DSSE payload type of in-toto statements.
+const PayloadType = "application/vnd.in-toto+json"
+
+// Verification errors.
+var (
+	ErrPayloadType      = errors.New("envelope does not hold the expected payload type")
+	ErrSignatureInvalid = errors.New("no valid signature from the key")
+)
+
+// Envelope is a DSSE envelope carrying a signed statement.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is one signature of an envelope's payload.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Signer signs statements with a worker's ed25519 key.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner creates a signer for the key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// LoadOrCreateSigner reads the PEM-encoded ed25519 key at path, generating
+// and saving one if the file does not exist.
+func LoadOrCreateSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing signing key %s: %w", path, err)
+		}
+		key, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an ed25519 key", path)
+		}
+		return NewSigner(key), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("encoding signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating signing key directory: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, fmt.Errorf("writing signing key: %w", err)
+	}
+	return NewSigner(key), nil
+}
+
+// PublicKey returns the signer's public key.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID returns the ID of the signer's key.
+func (s *Signer) KeyID() string {
+	return KeyID(s.PublicKey())
+}
+
+// Sign encodes a statement and signs it.
+func (s *Signer) Sign(statement *Statement) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("encoding statement: %w", err)
+	}
+	return s.SignPayload(PayloadType, payload), nil
+}
+
+// SignPayload signs a payload of any DSSE payload type.
+func (s *Signer) SignPayload(payloadType string, payload []byte) *Envelope {
+	sig := ed25519.Sign(s.key, pae(payloadType, payload))
+	return &Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: s.KeyID(), Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}
+}
+
+// Verify checks that the envelope is signed by the public key and returns its statement.
+func Verify(envelope *Envelope, publicKey ed25519.PublicKey) (*Statement, error) {
+	payload, err := VerifyPayload(envelope, PayloadType, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("decoding statement: %w", err)
+	}
+	return &statement, nil
+}
+
+// VerifyPayload checks that the envelope holds a payload of the given type
+// signed by the public key and returns the payload.
+func VerifyPayload(envelope *Envelope, payloadType string, publicKey ed25519.PublicKey) ([]byte, error) {
+	if envelope.PayloadType != payloadType {
+		return nil, ErrPayloadType
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	keyID := KeyID(publicKey)
+	verified := false
+	for _, s := range envelope.Signatures {
+		if s.KeyID != "" && s.KeyID != keyID {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && ed25519.Verify(publicKey, pae(envelope.PayloadType, payload), sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrSignatureInvalid
+	}
+	return payload, nil
+}
+
+// KeyID returns the hex SHA-256 of a public key, identifying it in signatures.
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// pae is the DSSE pre-authentication encoding of a payload, the bytes that are signed.
+func pae(payloadType string, payload []byte) []byte {
+	b := []byte("DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " + strconv.Itoa(len(payload)) + " ")
+	return append(b, payload...)
+}
@@ -0,0 +1,185 @@
+// Package provenance generates SLSA provenance for builds: an in-toto
+// statement describing who built an artifact, from which source and with which
+// parameters, signed by the build worker in a DSSE envelope.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Identifiers of the statement and predicate formats.
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies the parameters recorded for Narvana builds.
+	BuildType = "https://github.com/narvanalabs/control-plane/build/v1"
+	// BuilderIDPrefix is prepended to a worker ID to form the builder ID.
+	BuilderIDPrefix = "https://github.com/narvanalabs/control-plane/build-worker/"
+)
+
+// Statement is an in-toto statement with an SLSA provenance predicate.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is an SLSA v1 provenance predicate.
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition records what was built and how.
+type BuildDefinition struct {
+	BuildType string `json:"buildType"`
+	// ExternalParameters are the inputs chosen by the user: source and build settings.
+	ExternalParameters map[string]any `json:"externalParameters"`
+	// InternalParameters are set by the platform, e.g. the content hash used for deduplication.
+	InternalParameters   map[string]any       `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ResourceDescriptor identifies an input of the build.
+type ResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RunDetails records who ran the build and when.
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata"`
+}
+
+// Builder identifies the build worker.
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// BuildMetadata identifies the build run.
+type BuildMetadata struct {
+	InvocationID string     `json:"invocationId"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// Generate describes a successful build. commit is the source commit the
+// build's deployment resolved, if known; workerID and version identify the
+// worker that ran it.
+func Generate(job *models.BuildJob, commit, workerID, version string) *Statement {
+	external := map[string]any{
+		"buildType": string(job.BuildType),
+	}
+	setIf := func(m map[string]any, key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	setIf(external, "sourceType", string(job.SourceType))
+	setIf(external, "gitUrl", job.GitURL)
+	setIf(external, "gitRef", job.GitRef)
+	setIf(external, "flakeUri", job.FlakeURI)
+	setIf(external, "flakeOutput", job.FlakeOutput)
+	setIf(external, "buildStrategy", string(job.BuildStrategy))
+	if job.BuildConfig != nil {
+		external["buildConfig"] = job.BuildConfig
+	}
+
+	internal := map[string]any{
+		"appId":        job.AppID,
+		"serviceName":  job.ServiceName,
+		"deploymentId": job.DeploymentID,
+	}
+	setIf(internal, "contentHash", job.ContentHash)
+	setIf(internal, "deduplicatedFrom", job.DeduplicatedFrom)
+	setIf(internal, "vendorHash", job.VendorHash)
+	setIf(internal, "reproducibility", string(job.Reproducibility))
+	if job.GeneratedFlake != "" {
+		internal["generatedFlakeSha256"] = sha256Hex(job.GeneratedFlake)
+	}
+	if job.FlakeLock != "" {
+		internal["flakeLockSha256"] = sha256Hex(job.FlakeLock)
+	}
+
+	var deps []ResourceDescriptor
+	if job.GitURL != "" {
+		dep := ResourceDescriptor{URI: "git+" + job.GitURL}
+		if job.GitRef != "" {
+			dep.URI += "@" + job.GitRef
+		}
+		if commit != "" {
+			dep.Digest = map[string]string{"gitCommit": commit}
+		}
+		deps = append(deps, dep)
+	} else if job.FlakeURI != "" {
+		deps = append(deps, ResourceDescriptor{URI: job.FlakeURI})
+	}
+
+	builder := Builder{ID: BuilderIDPrefix + workerID}
+	if version != "" {
+		builder.Version = map[string]string{"narvana-worker": version}
+	}
+
+	return &Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: job.Artifact, Digest: ArtifactDigest(job.Artifact, job.OutputHash)}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType:            BuildType,
+				ExternalParameters:   external,
+				InternalParameters:   internal,
+				ResolvedDependencies: deps,
+			},
+			RunDetails: RunDetails{
+				Builder: builder,
+				Metadata: BuildMetadata{
+					InvocationID: job.ID,
+					StartedOn:    job.StartedAt,
+					FinishedOn:   job.FinishedAt,
+				},
+			},
+		},
+	}
+}
+
+// ArtifactDigest returns the digests identifying an artifact: the image
+// digest of an OCI reference pinned by digest, the store path hash and NAR
+// hash of a Nix store path, and otherwise the SHA-256 of the reference itself.
+func ArtifactDigest(artifact, narHash string) map[string]string {
+	digest := map[string]string{}
+	if i := strings.LastIndex(artifact, "@sha256:"); i >= 0 {
+		digest["sha256"] = artifact[i+len("@sha256:"):]
+	}
+	if rest, ok := strings.CutPrefix(artifact, "/nix/store/"); ok {
+		if hash, _, ok := strings.Cut(rest, "-"); ok {
+			digest["nixStorePath"] = hash
+		}
+		if narHash != "" {
+			digest["narHash"] = narHash
+		}
+	}
+	if len(digest) == 0 {
+		digest["sha256"] = sha256Hex(artifact)
+	}
+	return digest
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
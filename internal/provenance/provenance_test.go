@@ -0,0 +1,104 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func testJob() *models.BuildJob {
+	return &models.BuildJob{
+		ID:           "build-1",
+		AppID:        "app-1",
+		ServiceName:  "web",
+		DeploymentID: "dep-1",
+		GitURL:       "https://github.com/acme/shop",
+		GitRef:       "main",
+		BuildType:    models.BuildTypePureNix,
+		Artifact:     "/nix/store/abc123-web",
+		OutputHash:   "sha256-xyz",
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	st := Generate(testJob(), "deadbeef", "worker-a", "1.2.0")
+
+	if st.Type != StatementType || st.PredicateType != PredicateType {
+		t.Errorf("types = %q, %q", st.Type, st.PredicateType)
+	}
+	wantSubject := []Subject{{Name: "/nix/store/abc123-web", Digest: map[string]string{"nixStorePath": "abc123", "narHash": "sha256-xyz"}}}
+	if !reflect.DeepEqual(st.Subject, wantSubject) {
+		t.Errorf("subject = %+v, want %+v", st.Subject, wantSubject)
+	}
+	wantDeps := []ResourceDescriptor{{URI: "git+https://github.com/acme/shop@main", Digest: map[string]string{"gitCommit": "deadbeef"}}}
+	if !reflect.DeepEqual(st.Predicate.BuildDefinition.ResolvedDependencies, wantDeps) {
+		t.Errorf("dependencies = %+v, want %+v", st.Predicate.BuildDefinition.ResolvedDependencies, wantDeps)
+	}
+	if got := st.Predicate.RunDetails.Builder.ID; got != BuilderIDPrefix+"worker-a" {
+		t.Errorf("builder id = %q", got)
+	}
+	if got := st.Predicate.RunDetails.Metadata.InvocationID; got != "build-1" {
+		t.Errorf("invocation id = %q", got)
+	}
+}
+
+func TestArtifactDigest(t *testing.T) {
+	tests := []struct {
+		artifact string
+		want     map[string]string
+	}{
+		{"registry.local/shop/web@sha256:0123", map[string]string{"sha256": "0123"}},
+		{"/nix/store/abc123-web", map[string]string{"nixStorePath": "abc123"}},
+		{"registry.local/shop/web:v1", map[string]string{"sha256": sha256Hex("registry.local/shop/web:v1")}},
+	}
+	for _, tt := range tests {
+		if got := ArtifactDigest(tt.artifact, ""); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ArtifactDigest(%q) = %v, want %v", tt.artifact, got, tt.want)
+		}
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "attestation_key")
+	signer, err := LoadOrCreateSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadOrCreateSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.KeyID() != signer.KeyID() {
+		t.Fatal("reloaded key differs from the generated one")
+	}
+
+	envelope, err := signer.Sign(Generate(testJob(), "deadbeef", "worker-a", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := Verify(envelope, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if st.Subject[0].Name != "/nix/store/abc123-web" {
+		t.Errorf("subject = %q", st.Subject[0].Name)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, err := Verify(envelope, other); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify with another key: err = %v, want ErrSignatureInvalid", err)
+	}
+
+	// Changing the statement breaks the signature
+	tampered := *st
+	tampered.Subject = []Subject{{Name: "/nix/store/evil-web", Digest: map[string]string{"nixStorePath": "evil"}}}
+	forged, _ := signer.Sign(&tampered)
+	envelope.Payload = forged.Payload
+	if _, err := Verify(envelope, signer.PublicKey()); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify tampered: err = %v, want ErrSignatureInvalid", err)
+	}
+}
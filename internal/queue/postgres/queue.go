@@ -12,12 +12,32 @@ import (
 
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/queue"
+	"github.com/narvanalabs/control-plane/internal/tracing"
 )
 
-// PostgresQueue implements queue.Queue using PostgreSQL.
+// DefaultLeaseDuration is how long a claimed job survives without its
+// worker renewing the lease.
+const DefaultLeaseDuration = 2 * time.Minute
+
+// dequeueLock is the advisory lock dequeues hold while they check the
+// concurrency caps, so two workers cannot both claim an app's last slot.
+const dequeueLock = "build_queue_dequeue"
+
+// PostgresQueue implements queue.LeasedQueue and queue.ManagedQueue using
+// PostgreSQL.
 type PostgresQueue struct {
 	db     *sql.DB
 	logger *slog.Logger
+
+	// workerID identifies the worker claiming jobs through this queue; empty
+	// for processes that only enqueue.
+	workerID      string
+	leaseDuration time.Duration
+
+	// maxPerApp and maxPerUser cap how many jobs of one app or started by
+	// one user are processed at once; zero leaves them uncapped.
+	maxPerApp  int
+	maxPerUser int
 }
 
 // NewPostgresQueue creates a new PostgreSQL-backed queue.
@@ -26,36 +46,66 @@ func NewPostgresQueue(db *sql.DB, logger *slog.Logger) *PostgresQueue {
 		logger = slog.Default()
 	}
 	return &PostgresQueue{
-		db:     db,
-		logger: logger,
+		db:            db,
+		logger:        logger,
+		leaseDuration: DefaultLeaseDuration,
+	}
+}
+
+// SetWorker sets the worker that claims jobs through this queue and how long
+// its leases last. Ack and Nack then only succeed for jobs the worker holds.
+func (q *PostgresQueue) SetWorker(workerID string, leaseDuration time.Duration) {
+	q.workerID = workerID
+	if leaseDuration > 0 {
+		q.leaseDuration = leaseDuration
 	}
 }
 
+// SetLimits caps how many jobs of one app and how many jobs started by one
+// user are processed at once across all workers; zero leaves a cap off.
+// Jobs over a cap stay queued while later jobs are claimed.
+func (q *PostgresQueue) SetLimits(perApp, perUser int) {
+	q.maxPerApp = perApp
+	q.maxPerUser = perUser
+}
+
+// LeaseDuration returns how long a claimed job survives without renewal.
+func (q *PostgresQueue) LeaseDuration() time.Duration {
+	return q.leaseDuration
+}
+
 // Enqueue adds a new build job to the queue.
-// The job is serialized to JSON and stored in the build_queue table.
+// The job is serialized to JSON, with the trace context of ctx, and stored in
+// the build_queue table with the rank of its priority.
 func (q *PostgresQueue) Enqueue(ctx context.Context, job *models.BuildJob) error {
+	queued := *job
+	queued.TraceContext = tracing.Inject(ctx)
+	queued.QueuePosition = 0
+
 	// Serialize the job to JSON
-	jobData, err := json.Marshal(job)
+	jobData, err := json.Marshal(&queued)
 	if err != nil {
 		return fmt.Errorf("marshaling job to JSON: %w", err)
 	}
 
 	query := `
-		INSERT INTO build_queue (id, job_data, status, created_at)
-		VALUES ($1, $2, 'pending', $3)`
+		INSERT INTO build_queue (id, job_data, status, created_at, priority, app_id, triggered_by)
+		VALUES ($1, $2, 'pending', $3, $4, $5, NULLIF($6, ''))`
 
 	now := time.Now().UTC()
-	_, err = q.db.ExecContext(ctx, query, job.ID, jobData, now)
+	_, err = q.db.ExecContext(ctx, query, job.ID, jobData, now, job.Priority.Rank(), job.AppID, job.TriggeredBy)
 	if err != nil {
 		return fmt.Errorf("inserting job into queue: %w", err)
 	}
 
-	q.logger.Debug("enqueued build job", "job_id", job.ID)
+	q.logger.Debug("enqueued build job", "job_id", job.ID, "priority", job.Priority)
 	return nil
 }
 
-// Dequeue retrieves and locks the next available build job from the queue.
-// Uses SELECT FOR UPDATE SKIP LOCKED for concurrent worker safety.
+// Dequeue retrieves and locks the next available build job from the queue:
+// the oldest job of the highest priority whose app and user are within the
+// concurrency caps. Uses SELECT FOR UPDATE SKIP LOCKED for concurrent worker
+// safety, and leases the job to this queue's worker.
 func (q *PostgresQueue) Dequeue(ctx context.Context) (*models.BuildJob, error) {
 	// Use a transaction to atomically select and update the job status
 	tx, err := q.db.BeginTx(ctx, nil)
@@ -64,18 +114,32 @@ func (q *PostgresQueue) Dequeue(ctx context.Context) (*models.BuildJob, error) {
 	}
 	defer tx.Rollback()
 
-	// Select the oldest pending job and lock it
+	// Caps count jobs other workers are processing, so workers check them
+	// one at a time
+	if q.maxPerApp > 0 || q.maxPerUser > 0 {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, dequeueLock); err != nil {
+			return nil, fmt.Errorf("locking queue: %w", err)
+		}
+	}
+
+	// Select the next pending job within the caps and lock it
 	selectQuery := `
 		SELECT id, job_data
-		FROM build_queue
+		FROM build_queue q
 		WHERE status = 'pending'
-		ORDER BY created_at ASC
+			AND ($1 = 0 OR app_id IS NULL OR (
+				SELECT COUNT(*) FROM build_queue p
+				WHERE p.status = 'processing' AND p.app_id = q.app_id) < $1)
+			AND ($2 = 0 OR triggered_by IS NULL OR (
+				SELECT COUNT(*) FROM build_queue p
+				WHERE p.status = 'processing' AND p.triggered_by = q.triggered_by) < $2)
+		ORDER BY priority DESC, created_at ASC, id
 		LIMIT 1
 		FOR UPDATE SKIP LOCKED`
 
 	var jobID string
 	var jobData []byte
-	err = tx.QueryRowContext(ctx, selectQuery).Scan(&jobID, &jobData)
+	err = tx.QueryRowContext(ctx, selectQuery, q.maxPerApp, q.maxPerUser).Scan(&jobID, &jobData)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, queue.ErrNoJobs
@@ -83,14 +147,14 @@ func (q *PostgresQueue) Dequeue(ctx context.Context) (*models.BuildJob, error) {
 		return nil, fmt.Errorf("selecting job from queue: %w", err)
 	}
 
-	// Update the job status to processing
+	// Update the job status to processing and lease it to this worker
 	updateQuery := `
 		UPDATE build_queue
-		SET status = 'processing', started_at = $2
+		SET status = 'processing', started_at = $2, claimed_by = NULLIF($3, ''), lease_expires_at = $4
 		WHERE id = $1`
 
 	now := time.Now().UTC()
-	_, err = tx.ExecContext(ctx, updateQuery, jobID, now)
+	_, err = tx.ExecContext(ctx, updateQuery, jobID, now, q.workerID, now.Add(q.leaseDuration))
 	if err != nil {
 		return nil, fmt.Errorf("updating job status: %w", err)
 	}
@@ -106,17 +170,20 @@ func (q *PostgresQueue) Dequeue(ctx context.Context) (*models.BuildJob, error) {
 		return nil, fmt.Errorf("unmarshaling job from JSON: %w", err)
 	}
 
-	q.logger.Debug("dequeued build job", "job_id", job.ID)
+	q.logger.Debug("dequeued build job", "job_id", job.ID, "worker_id", q.workerID)
 	return &job, nil
 }
 
 // Ack acknowledges successful processing of a job, removing it from the queue.
+// It returns queue.ErrJobNotFound if the worker's lease expired and the job
+// was requeued.
 func (q *PostgresQueue) Ack(ctx context.Context, jobID string) error {
 	query := `
 		DELETE FROM build_queue
 		WHERE id = $1 AND status = 'processing'`
 
-	result, err := q.db.ExecContext(ctx, query, jobID)
+	query, args := q.ownedBy(query, jobID)
+	result, err := q.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("deleting job from queue: %w", err)
 	}
@@ -138,10 +205,12 @@ func (q *PostgresQueue) Ack(ctx context.Context, jobID string) error {
 func (q *PostgresQueue) Nack(ctx context.Context, jobID string) error {
 	query := `
 		UPDATE build_queue
-		SET status = 'pending', started_at = NULL, retry_count = retry_count + 1
+		SET status = 'pending', started_at = NULL, claimed_by = NULL, lease_expires_at = NULL,
+			retry_count = retry_count + 1
 		WHERE id = $1 AND status = 'processing'`
 
-	result, err := q.db.ExecContext(ctx, query, jobID)
+	query, args := q.ownedBy(query, jobID)
+	result, err := q.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("updating job status: %w", err)
 	}
@@ -158,3 +227,185 @@ func (q *PostgresQueue) Nack(ctx context.Context, jobID string) error {
 	q.logger.Debug("nacked build job", "job_id", jobID)
 	return nil
 }
+
+// RenewLeases extends the leases on all jobs claimed by this queue's worker.
+func (q *PostgresQueue) RenewLeases(ctx context.Context) (int, error) {
+	if q.workerID == "" {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE build_queue
+		SET lease_expires_at = $2
+		WHERE claimed_by = $1 AND status = 'processing'`
+
+	result, err := q.db.ExecContext(ctx, query, q.workerID, time.Now().UTC().Add(q.leaseDuration))
+	if err != nil {
+		return 0, fmt.Errorf("renewing job leases: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// RequeueExpired returns jobs whose lease expired, because their worker
+// stopped heartbeating, to the queue.
+func (q *PostgresQueue) RequeueExpired(ctx context.Context) ([]string, error) {
+	query := `
+		UPDATE build_queue
+		SET status = 'pending', started_at = NULL, claimed_by = NULL, lease_expires_at = NULL,
+			retry_count = retry_count + 1
+		WHERE status = 'processing' AND lease_expires_at < $1
+		RETURNING id`
+
+	rows, err := q.db.QueryContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("requeuing expired jobs: %w", err)
+	}
+	defer rows.Close()
+
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("requeuing expired jobs: %w", err)
+	}
+
+	for _, id := range ids {
+		q.logger.Warn("requeued build job with expired lease", "job_id", id)
+	}
+	return ids, nil
+}
+
+// ListClaimed returns the IDs of all jobs currently claimed by any worker.
+func (q *PostgresQueue) ListClaimed(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT id FROM build_queue WHERE status = 'processing'`)
+	if err != nil {
+		return nil, fmt.Errorf("listing claimed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("listing claimed jobs: %w", err)
+	}
+	return ids, nil
+}
+
+// pendingOrder numbers pending jobs in the order Dequeue claims them.
+const pendingOrder = `
+	SELECT id, app_id, triggered_by, job_data->>'priority', created_at,
+		ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC, id) AS position
+	FROM build_queue
+	WHERE status = 'pending'`
+
+// ListPending returns the pending jobs in the order they are claimed.
+func (q *PostgresQueue) ListPending(ctx context.Context) ([]*queue.QueuedJob, error) {
+	rows, err := q.db.QueryContext(ctx, pendingOrder+` ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("listing pending jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*queue.QueuedJob
+	for rows.Next() {
+		var job queue.QueuedJob
+		var appID, triggeredBy, priority sql.NullString
+		if err := rows.Scan(&job.ID, &appID, &triggeredBy, &priority, &job.CreatedAt, &job.Position); err != nil {
+			return nil, fmt.Errorf("scanning pending job: %w", err)
+		}
+		job.AppID = appID.String
+		job.TriggeredBy = triggeredBy.String
+		job.Priority = models.BuildPriority(priority.String)
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing pending jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Position returns the 1-based place of a pending job in the queue.
+func (q *PostgresQueue) Position(ctx context.Context, jobID string) (int, error) {
+	query := `SELECT position FROM (` + pendingOrder + `) ranked WHERE id = $1`
+
+	var position int
+	if err := q.db.QueryRowContext(ctx, query, jobID).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, queue.ErrJobNotFound
+		}
+		return 0, fmt.Errorf("getting queue position: %w", err)
+	}
+	return position, nil
+}
+
+// Reorder gives a pending job a new priority. With first, the job's queue
+// time is moved just before the oldest pending job of that priority so it
+// is claimed next among them.
+func (q *PostgresQueue) Reorder(ctx context.Context, jobID string, priority models.BuildPriority, first bool) error {
+	query := `
+		UPDATE build_queue
+		SET priority = $2,
+			job_data = jsonb_set(job_data, '{priority}', to_jsonb($3::text)),
+			created_at = CASE WHEN $4 THEN LEAST(created_at, (
+				SELECT MIN(created_at) - INTERVAL '1 millisecond' FROM build_queue
+				WHERE status = 'pending' AND priority = $2 AND id <> $1)) ELSE created_at END
+		WHERE id = $1 AND status = 'pending'`
+
+	result, err := q.db.ExecContext(ctx, query, jobID, priority.Rank(), string(priority), first)
+	if err != nil {
+		return fmt.Errorf("reordering job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return queue.ErrJobNotFound
+	}
+
+	q.logger.Info("reordered build job", "job_id", jobID, "priority", priority, "first", first)
+	return nil
+}
+
+// Cancel removes a pending job from the queue.
+func (q *PostgresQueue) Cancel(ctx context.Context, jobID string) error {
+	result, err := q.db.ExecContext(ctx, `DELETE FROM build_queue WHERE id = $1 AND status = 'pending'`, jobID)
+	if err != nil {
+		return fmt.Errorf("cancelling job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return queue.ErrJobNotFound
+	}
+
+	q.logger.Info("cancelled build job", "job_id", jobID)
+	return nil
+}
+
+// ownedBy restricts a query on job $1 to jobs claimed by this queue's worker.
+func (q *PostgresQueue) ownedBy(query, jobID string) (string, []any) {
+	if q.workerID == "" {
+		return query, []any{jobID}
+	}
+	return query + " AND claimed_by = $2", []any{jobID, q.workerID}
+}
+
+// scanIDs reads a single text column from every row.
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -4,6 +4,7 @@ package queue
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/narvanalabs/control-plane/internal/models"
 )
@@ -33,3 +34,59 @@ type Queue interface {
 	// Nack indicates that job processing failed, making the job available for retry.
 	Nack(ctx context.Context, jobID string) error
 }
+
+// LeasedQueue is a Queue shared by several worker processes. A dequeued job
+// is leased to the worker that claimed it; the worker renews its leases while
+// it is alive, and jobs whose lease expires are returned to the queue so
+// another worker can run them.
+type LeasedQueue interface {
+	Queue
+
+	// LeaseDuration returns how long a claimed job survives without renewal.
+	LeaseDuration() time.Duration
+
+	// RenewLeases extends the leases on all jobs claimed by this worker and
+	// returns how many were renewed.
+	RenewLeases(ctx context.Context) (int, error)
+
+	// RequeueExpired returns jobs with expired leases to the queue and
+	// returns their IDs.
+	RequeueExpired(ctx context.Context) ([]string, error)
+
+	// ListClaimed returns the IDs of all jobs currently claimed by any worker.
+	ListClaimed(ctx context.Context) ([]string, error)
+}
+
+// QueuedJob is a pending job as it waits in the queue.
+type QueuedJob struct {
+	ID          string               `json:"id"`
+	AppID       string               `json:"app_id"`
+	TriggeredBy string               `json:"triggered_by,omitempty"`
+	Priority    models.BuildPriority `json:"priority"`
+	// Position is the job's 1-based place in the order pending jobs are
+	// claimed in, before any concurrency caps hold jobs back.
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ManagedQueue is a Queue whose pending jobs can be inspected, reordered
+// and cancelled.
+type ManagedQueue interface {
+	Queue
+
+	// ListPending returns the pending jobs in the order they are claimed.
+	ListPending(ctx context.Context) ([]*QueuedJob, error)
+
+	// Position returns the 1-based place of a pending job in the queue.
+	// Returns ErrJobNotFound if the job is not pending.
+	Position(ctx context.Context, jobID string) (int, error)
+
+	// Reorder gives a pending job a new priority and, with first, moves it
+	// ahead of every pending job of that priority.
+	// Returns ErrJobNotFound if the job is not pending.
+	Reorder(ctx context.Context, jobID string, priority models.BuildPriority, first bool) error
+
+	// Cancel removes a pending job from the queue.
+	// Returns ErrJobNotFound if the job is not pending.
+	Cancel(ctx context.Context, jobID string) error
+}
@@ -0,0 +1,176 @@
+package quickstart
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Migrate applies the .sql files of migrations that have not been applied to
+// the database yet, in file name order, each in its own transaction. Applied
+// migrations are recorded in the schema_migrations table; migrations applied
+// before it existed, as by make migrate-up, are detected and recorded first.
+// It returns the number of migrations applied.
+func Migrate(ctx context.Context, db *sql.DB, migrations fs.FS, logger *slog.Logger) (int, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return 0, fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT name FROM schema_migrations`)
+	if err != nil {
+		return 0, fmt.Errorf("listing applied migrations: %w", err)
+	}
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("listing applied migrations: %w", err)
+		}
+		applied[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("listing applied migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(names)
+
+	if len(applied) == 0 {
+		recorded, err := recordExisting(ctx, db, migrations, names)
+		if err != nil {
+			return 0, fmt.Errorf("detecting applied migrations: %w", err)
+		}
+		for _, name := range recorded {
+			applied[name] = true
+		}
+		if len(recorded) > 0 {
+			logger.Info("recorded migrations applied before tracking", "count", len(recorded))
+		}
+	}
+
+	count := 0
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		script, err := fs.ReadFile(migrations, name)
+		if err != nil {
+			return count, err
+		}
+		if err := applyMigration(ctx, db, name, string(script)); err != nil {
+			return count, fmt.Errorf("applying %s: %w", name, err)
+		}
+		logger.Info("applied migration", "name", name)
+		count++
+	}
+	return count, nil
+}
+
+// createTable matches the tables created by a migration.
+var createTable = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?"?(\w+)"?`)
+
+// recordExisting records the migrations already applied to a database whose
+// migrations were not tracked, and returns their names. As in the doctor, a
+// migration counts as applied when every table it creates exists; since
+// migrations run in order, so does every migration before the last one found
+// applied.
+func recordExisting(ctx context.Context, db *sql.DB, migrations fs.FS, names []string) ([]string, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()`)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables[strings.ToLower(name)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	last := -1
+	for i, name := range names {
+		script, err := fs.ReadFile(migrations, name)
+		if err != nil {
+			return nil, err
+		}
+		if migrationApplied(string(script), tables) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, name := range names[:last+1] {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (name) VALUES ($1)`, name); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return names[:last+1], nil
+}
+
+// migrationApplied reports whether script creates tables and all of them
+// exist.
+func migrationApplied(script string, tables map[string]bool) bool {
+	matches := createTable.FindAllStringSubmatch(script, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	for _, m := range matches {
+		if !tables[strings.ToLower(m[1])] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyMigration runs a migration script and records it as applied.
+func applyMigration(ctx context.Context, db *sql.DB, name, script string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (name) VALUES ($1)`, name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,267 @@
+// Package quickstart prepares a single machine to run the whole control plane
+// in one process: a data directory with generated secrets, and Postgres and
+// an image registry in Podman containers. It backs narvana up.
+//
+// The database is deliberately not embedded. The stores need Postgres, and
+// running the official image in Podman, which the local node requires anyway,
+// avoids shipping and supervising a second server binary.
+package quickstart
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Config configures the quickstart environment.
+type Config struct {
+	// DataDir holds the generated secrets, build directories and keys.
+	DataDir string
+	// PostgresPort is the host port of the Postgres container.
+	PostgresPort int
+	// RegistryPort is the host port of the image registry container.
+	RegistryPort int
+	// StartTimeout is how long Postgres may take to accept connections.
+	StartTimeout time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults. Its ports differ
+// from the usual ones so they do not clash with a database or registry
+// already running on the machine.
+func DefaultConfig() Config {
+	dataDir := ".narvana"
+	if home, err := os.UserHomeDir(); err == nil {
+		dataDir = filepath.Join(home, ".local", "share", "narvana")
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		dataDir = filepath.Join(xdg, "narvana")
+	}
+	return Config{
+		DataDir:      dataDir,
+		PostgresPort: 5433,
+		RegistryPort: 5050,
+		StartTimeout: time.Minute,
+	}
+}
+
+// Names of the containers the quickstart runs.
+const (
+	PostgresContainer = "narvana-quickstart-postgres"
+	RegistryContainer = "narvana-quickstart-registry"
+)
+
+const (
+	postgresImage = "docker.io/library/postgres:16-alpine"
+	registryImage = "docker.io/library/registry:2"
+)
+
+// Environment is a prepared quickstart machine.
+type Environment struct {
+	config Config
+	logger *slog.Logger
+	// podman runs the podman CLI and returns its standard output; replaced
+	// in tests.
+	podman func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// New creates the data directory and returns the environment it describes.
+func New(cfg Config, logger *slog.Logger) (*Environment, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := os.MkdirAll(cfg.DataDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	return &Environment{config: cfg, logger: logger, podman: runPodman}, nil
+}
+
+// Env returns the control plane configuration of the quickstart as
+// environment variables, for the keys not already set in the environment.
+// databaseURL is the database the control plane uses.
+func (e *Environment) Env(databaseURL string, apiPort int) (map[string]string, error) {
+	jwtSecret, err := e.secret("jwt_secret")
+	if err != nil {
+		return nil, err
+	}
+	defaults := map[string]string{
+		"DATABASE_URL":           databaseURL,
+		"JWT_SECRET":             jwtSecret,
+		"API_HOST":               "127.0.0.1",
+		"API_PORT":               strconv.Itoa(apiPort),
+		"API_URL":                "http://127.0.0.1:" + strconv.Itoa(apiPort),
+		"REGISTRY_URL":           e.registryHost(),
+		"WORKER_WORKDIR":         filepath.Join(e.config.DataDir, "builds"),
+		"WORKER_MAX_CONCURRENCY": "2",
+		"WORKER_ATTESTATION_KEY": filepath.Join(e.config.DataDir, "attestation_ed25519_key"),
+		"SSH_BROKER_HOST_KEY":    filepath.Join(e.config.DataDir, "ssh_host_ed25519_key"),
+		"WORKLOAD_IDENTITY_KEY":  filepath.Join(e.config.DataDir, "workload_identity_p256_key"),
+		"CHANGELOG_PATH":         filepath.Join(e.config.DataDir, "CHANGELOG.md"),
+	}
+	env := make(map[string]string)
+	for key, value := range defaults {
+		if _, ok := os.LookupEnv(key); !ok {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
+
+// StartPostgres starts the Postgres container, creating it with a generated
+// password on first use, and returns its URL once it accepts connections.
+func (e *Environment) StartPostgres(ctx context.Context) (string, error) {
+	password, err := e.secret("postgres_password")
+	if err != nil {
+		return "", err
+	}
+	err = e.startContainer(ctx, PostgresContainer, postgresImage,
+		"--publish", fmt.Sprintf("127.0.0.1:%d:5432", e.config.PostgresPort),
+		"--volume", PostgresContainer+":/var/lib/postgresql/data",
+		"--env", "POSTGRES_USER=narvana",
+		"--env", "POSTGRES_PASSWORD="+password,
+		"--env", "POSTGRES_DB=narvana",
+	)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("postgres://narvana:%s@127.0.0.1:%d/narvana?sslmode=disable", password, e.config.PostgresPort)
+	if err := waitForDatabase(ctx, url, e.config.StartTimeout); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// StartRegistry starts the image registry container that builds push to and
+// the local node pulls from, and lets Podman reach it over plain HTTP.
+func (e *Environment) StartRegistry(ctx context.Context) error {
+	if err := allowInsecureRegistry(e.registryHost()); err != nil {
+		return err
+	}
+	return e.startContainer(ctx, RegistryContainer, registryImage,
+		"--publish", fmt.Sprintf("127.0.0.1:%d:5000", e.config.RegistryPort),
+		"--volume", RegistryContainer+":/var/lib/registry",
+	)
+}
+
+// Stop stops the containers the quickstart started, keeping their data.
+func (e *Environment) Stop(ctx context.Context) error {
+	var errs []error
+	for _, name := range []string{RegistryContainer, PostgresContainer} {
+		if _, err := e.podman(ctx, "stop", "--ignore", name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startContainer starts the named container, running it from image with
+// args if it does not exist yet.
+func (e *Environment) startContainer(ctx context.Context, name, image string, args ...string) error {
+	if _, err := e.podman(ctx, "container", "exists", name); err == nil {
+		e.logger.Info("starting container", "name", name)
+		if _, err := e.podman(ctx, "start", name); err != nil {
+			return fmt.Errorf("starting %s: %w", name, err)
+		}
+		return nil
+	}
+
+	e.logger.Info("creating container", "name", name, "image", image)
+	run := append([]string{"run", "--detach", "--name", name}, args...)
+	if _, err := e.podman(ctx, append(run, image)...); err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	return nil
+}
+
+func (e *Environment) registryHost() string {
+	return "localhost:" + strconv.Itoa(e.config.RegistryPort)
+}
+
+// secret returns the secret stored under name in the data directory,
+// generating it on first use.
+func (e *Environment) secret(name string) (string, error) {
+	path := filepath.Join(e.config.DataDir, name)
+	if data, err := os.ReadFile(path); err == nil {
+		if s := strings.TrimSpace(string(data)); s != "" {
+			return s, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	s := hex.EncodeToString(buf)
+	if err := os.WriteFile(path, []byte(s+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// waitForDatabase polls the database until it accepts connections.
+func waitForDatabase(ctx context.Context, url string, timeout time.Duration) error {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for postgres: %w", err)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// allowInsecureRegistry adds a registries.conf drop-in that lets Podman push
+// to and pull from the registry at host over plain HTTP.
+func allowInsecureRegistry(host string) error {
+	dir := "/etc/containers/registries.conf.d"
+	if os.Geteuid() != 0 {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(config, "containers", "registries.conf.d")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating registries.conf.d: %w", err)
+	}
+	conf := fmt.Sprintf("[[registry]]\nlocation = %q\ninsecure = true\n", host)
+	return os.WriteFile(filepath.Join(dir, "50-narvana-quickstart.conf"), []byte(conf), 0644)
+}
+
+// runPodman runs the podman CLI and returns its standard output.
+func runPodman(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "podman", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("podman %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package quickstart
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestEnvironment(t *testing.T) *Environment {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.DataDir = filepath.Join(t.TempDir(), "narvana")
+	e, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return e
+}
+
+func TestEnvKeepsSecretsAndExistingSettings(t *testing.T) {
+	e := newTestEnvironment(t)
+	t.Setenv("REGISTRY_URL", "registry.example.com")
+
+	env, err := e.Env("postgres://db", 8080)
+	if err != nil {
+		t.Fatalf("Env() error = %v", err)
+	}
+	if _, ok := env["REGISTRY_URL"]; ok {
+		t.Error("Env() overrides REGISTRY_URL set in the environment")
+	}
+	if env["DATABASE_URL"] != "postgres://db" || len(env["JWT_SECRET"]) < 32 {
+		t.Errorf("Env() = %v", env)
+	}
+	if !strings.HasPrefix(env["WORKER_WORKDIR"], e.config.DataDir) {
+		t.Errorf("WORKER_WORKDIR = %q, want it under the data directory", env["WORKER_WORKDIR"])
+	}
+
+	again, err := e.Env("postgres://db", 8080)
+	if err != nil {
+		t.Fatalf("Env() error = %v", err)
+	}
+	if again["JWT_SECRET"] != env["JWT_SECRET"] {
+		t.Error("JWT_SECRET changed between runs")
+	}
+}
+
+func TestStartContainerReusesExistingContainer(t *testing.T) {
+	e := newTestEnvironment(t)
+	var calls [][]string
+	exists := false
+	e.podman = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if args[0] == "container" && !exists {
+			return nil, errors.New("no such container")
+		}
+		return nil, nil
+	}
+
+	if err := e.startContainer(context.Background(), RegistryContainer, registryImage, "--publish", "127.0.0.1:5050:5000"); err != nil {
+		t.Fatalf("startContainer() error = %v", err)
+	}
+	run := calls[len(calls)-1]
+	if run[0] != "run" || run[len(run)-1] != registryImage {
+		t.Errorf("first start ran %v, want a new container", run)
+	}
+
+	exists = true
+	calls = nil
+	if err := e.startContainer(context.Background(), RegistryContainer, registryImage); err != nil {
+		t.Fatalf("startContainer() error = %v", err)
+	}
+	if last := calls[len(calls)-1]; last[0] != "start" || last[1] != RegistryContainer {
+		t.Errorf("second start ran %v, want the existing container started", last)
+	}
+}
+
+func TestMigrationApplied(t *testing.T) {
+	tables := map[string]bool{"apps": true, "builds": true}
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{"CREATE TABLE apps (id UUID);\nCREATE TABLE IF NOT EXISTS builds (id UUID);", true},
+		{"CREATE TABLE apps (id UUID);\nCREATE TABLE secrets (id UUID);", false},
+		{"ALTER TABLE apps ADD COLUMN icon TEXT;", false},
+	}
+	for _, tt := range tests {
+		if got := migrationApplied(tt.script, tables); got != tt.want {
+			t.Errorf("migrationApplied(%q) = %v, want %v", tt.script, got, tt.want)
+		}
+	}
+}
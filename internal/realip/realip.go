@@ -0,0 +1,75 @@
+// Package realip resolves the IP address of a request's client. The
+// X-Forwarded-For and X-Real-IP headers are set by anyone making a request,
+// so they are only honored when the request comes from a trusted proxy;
+// otherwise the client is the peer of the connection.
+package realip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver resolves client addresses behind a set of trusted proxies.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// New creates a resolver honoring forwarded addresses from peers in trusted.
+func New(trusted []*net.IPNet) *Resolver {
+	return &Resolver{trusted: trusted}
+}
+
+// ClientIP returns the IP address of the client making r.
+//
+// When the peer is a trusted proxy, the client is the last address of
+// X-Forwarded-For that is not itself a trusted proxy: every earlier entry was
+// written by a hop that is not trusted. Without such an address, X-Real-IP
+// names the client.
+func (res *Resolver) ClientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	if !res.isTrusted(peer) {
+		return peer
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !res.isTrusted(hop) {
+			return hop
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return peer
+}
+
+// Middleware sets the RemoteAddr of requests to their client's IP address.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = res.ClientIP(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isTrusted reports whether ip is the address of a trusted proxy.
+func (res *Resolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range res.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package realip
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/pkg/config"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := config.ParseNetworks("127.0.0.1, 10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := New(trusted)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{"untrusted peer", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"untrusted peer forging headers", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"trusted peer without headers", "127.0.0.1:5000", "", "", "127.0.0.1"},
+		{"trusted peer with X-Real-IP", "127.0.0.1:5000", "", "198.51.100.2", "198.51.100.2"},
+		{"trusted peer with X-Forwarded-For", "10.0.0.5:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"client prepending a forged hop", "10.0.0.5:5000", "192.0.2.9, 198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.5:5000", "198.51.100.1, 10.0.0.6", "", "198.51.100.1"},
+		{"invalid X-Real-IP", "127.0.0.1:5000", "", "not-an-ip", "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := res.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package releasenotes
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ChangelogEntry represents a single release entry in the changelog.
+type ChangelogEntry struct {
+	Version      string    // e.g., "1.0.0"
+	Date         time.Time // Release date
+	ReleaseURL   string    // URL to the GitHub release
+	ReleaseNotes string    // Release notes content (markdown)
+}
+
+// changelogHeadingRegex matches a release heading such as "## [1.0.0] - 2026-01-07".
+var changelogHeadingRegex = regexp.MustCompile(`(?m)^## \[(\d+\.\d+\.\d+)\] - (\d{4}-\d{2}-\d{2})\s*$`)
+
+// changelogLinkRegex matches a footer version link such as "[1.0.0]: https://...".
+var changelogLinkRegex = regexp.MustCompile(`(?m)^\[(\d+\.\d+\.\d+)\]:\s*(\S+)\s*$`)
+
+// ParseChangelog parses a changelog produced by PrependChangelogEntry back into entries.
+// Entries are returned in the order they appear, which is newest first.
+// Headings with an unparseable date are skipped.
+func ParseChangelog(changelog string) []ChangelogEntry {
+	links := make(map[string]string)
+	for _, match := range changelogLinkRegex.FindAllStringSubmatch(changelog, -1) {
+		links[match[1]] = match[2]
+	}
+
+	// Release notes end where the footer links begin
+	body := changelog
+	if loc := changelogLinkRegex.FindStringIndex(changelog); loc != nil {
+		body = changelog[:loc[0]]
+	}
+
+	headings := changelogHeadingRegex.FindAllStringSubmatchIndex(body, -1)
+	entries := make([]ChangelogEntry, 0, len(headings))
+	for i, loc := range headings {
+		version := body[loc[2]:loc[3]]
+		date, err := time.Parse("2006-01-02", body[loc[4]:loc[5]])
+		if err != nil {
+			continue
+		}
+
+		end := len(body)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+
+		entries = append(entries, ChangelogEntry{
+			Version:      version,
+			Date:         date,
+			ReleaseURL:   links[version],
+			ReleaseNotes: strings.TrimSpace(body[loc[1]:end]),
+		})
+	}
+	return entries
+}
@@ -0,0 +1,12 @@
+package releasenotes
+
+import "testing"
+
+func TestParseChangelogEmpty(t *testing.T) {
+	if entries := ParseChangelog(""); len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+	if entries := ParseChangelog("# Changelog\n\nAll notable changes to Narvana are documented here.\n\n"); len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+}
@@ -0,0 +1,789 @@
+// Package releasenotes parses conventional commits and changelogs and groups
+// them into release note sections. It is shared by the release tooling in
+// scripts/ and the API's what's new feed.
+package releasenotes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CommitType represents the type of change in a conventional commit.
+type CommitType string
+
+const (
+	CommitTypeFeat     CommitType = "feat"
+	CommitTypeFix      CommitType = "fix"
+	CommitTypeDocs     CommitType = "docs"
+	CommitTypeStyle    CommitType = "style"
+	CommitTypeRefactor CommitType = "refactor"
+	CommitTypePerf     CommitType = "perf"
+	CommitTypeTest     CommitType = "test"
+	CommitTypeBuild    CommitType = "build"
+	CommitTypeCI       CommitType = "ci"
+	CommitTypeChore    CommitType = "chore"
+	CommitTypeOther    CommitType = "other"
+)
+
+// AllCommitTypes returns all valid commit types.
+func AllCommitTypes() []CommitType {
+	return []CommitType{
+		CommitTypeFeat,
+		CommitTypeFix,
+		CommitTypeDocs,
+		CommitTypeStyle,
+		CommitTypeRefactor,
+		CommitTypePerf,
+		CommitTypeTest,
+		CommitTypeBuild,
+		CommitTypeCI,
+		CommitTypeChore,
+		CommitTypeOther,
+	}
+}
+
+// IsValidCommitType checks if a string is a valid commit type.
+func IsValidCommitType(t string) bool {
+	switch CommitType(t) {
+	case CommitTypeFeat, CommitTypeFix, CommitTypeDocs, CommitTypeStyle,
+		CommitTypeRefactor, CommitTypePerf, CommitTypeTest, CommitTypeBuild,
+		CommitTypeCI, CommitTypeChore:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParsedCommit represents a parsed conventional commit.
+type ParsedCommit struct {
+	Type           CommitType // The type of commit (feat, fix, etc.)
+	Scope          string     // Optional scope in parentheses
+	Description    string     // The commit description
+	Body           string     // Optional commit body
+	BreakingChange bool       // Whether this is a breaking change
+	Hash           string     // Short commit hash (for reference only, not displayed)
+	Raw            string     // Original raw commit message
+}
+
+// conventionalCommitRegex matches the conventional commit format:
+// type(scope)!: description
+// where scope and ! are optional
+var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?\s*:\s*(.*)$`)
+
+// ParseCommit parses a raw commit message into structured form.
+// It handles the conventional commit format: type(scope): description
+// If the message doesn't match the format, it returns a commit with type "other".
+func ParseCommit(raw string) ParsedCommit {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ParsedCommit{
+			Type:        CommitTypeOther,
+			Description: "",
+			Raw:         raw,
+		}
+	}
+
+	// Split into first line and body
+	lines := strings.SplitN(raw, "\n", 2)
+	firstLine := strings.TrimSpace(lines[0])
+	var body string
+	if len(lines) > 1 {
+		body = strings.TrimSpace(lines[1])
+	}
+
+	// Check for BREAKING CHANGE in body
+	breakingChange := strings.Contains(strings.ToUpper(body), "BREAKING CHANGE")
+
+	// Try to match conventional commit format
+	matches := conventionalCommitRegex.FindStringSubmatch(firstLine)
+	if matches == nil {
+		// Not a conventional commit, categorize as "other"
+		return ParsedCommit{
+			Type:           CommitTypeOther,
+			Description:    firstLine,
+			Body:           body,
+			BreakingChange: breakingChange,
+			Raw:            raw,
+		}
+	}
+
+	// Extract components
+	commitType := strings.ToLower(matches[1])
+	scope := matches[2]
+	bangIndicator := matches[3]
+	description := strings.TrimSpace(matches[4])
+
+	// Check for breaking change indicator (!)
+	if bangIndicator == "!" {
+		breakingChange = true
+	}
+
+	// Validate commit type
+	var parsedType CommitType
+	if IsValidCommitType(commitType) {
+		parsedType = CommitType(commitType)
+	} else {
+		parsedType = CommitTypeOther
+	}
+
+	return ParsedCommit{
+		Type:           parsedType,
+		Scope:          scope,
+		Description:    description,
+		Body:           body,
+		BreakingChange: breakingChange,
+		Raw:            raw,
+	}
+}
+
+// FormatCommit formats a ParsedCommit back to conventional commit string.
+// This enables round-trip testing: parse(format(commit)) should equal commit.
+func FormatCommit(commit ParsedCommit) string {
+	var sb strings.Builder
+
+	// Write type
+	sb.WriteString(string(commit.Type))
+
+	// Write scope if present
+	if commit.Scope != "" {
+		sb.WriteString("(")
+		sb.WriteString(commit.Scope)
+		sb.WriteString(")")
+	}
+
+	// Write breaking change indicator if applicable
+	if commit.BreakingChange {
+		sb.WriteString("!")
+	}
+
+	// Write description
+	sb.WriteString(": ")
+	sb.WriteString(commit.Description)
+
+	// Write body if present
+	if commit.Body != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(commit.Body)
+	}
+
+	return sb.String()
+}
+
+// hashInParensRegex matches commit hashes in parentheses like (abc123) or (abcd1234)
+var hashInParensRegex = regexp.MustCompile(`\s*\([a-fA-F0-9]{6,8}\)`)
+
+// leadingPrefixRegex matches leading "Add ", "Update ", "Fix " prefixes (case-insensitive)
+var leadingPrefixRegex = regexp.MustCompile(`^(?i)(add|update|fix)\s+`)
+
+// CleanDescription processes a commit description for display.
+// - Removes commit hashes in parentheses (abc123)
+// - Removes leading "Add ", "Update ", "Fix " prefixes
+// - Capitalizes first letter
+// - Adds period if missing punctuation at end
+func CleanDescription(desc string) string {
+	if desc == "" {
+		return ""
+	}
+
+	// Step 1: Remove commit hashes in parentheses
+	cleaned := hashInParensRegex.ReplaceAllString(desc, "")
+
+	// Step 2: Trim any leading/trailing whitespace after hash removal
+	cleaned = strings.TrimSpace(cleaned)
+
+	if cleaned == "" {
+		return ""
+	}
+
+	// Step 3: Remove leading "Add ", "Update ", "Fix " prefixes
+	cleaned = leadingPrefixRegex.ReplaceAllString(cleaned, "")
+
+	// Step 4: Trim again after prefix removal
+	cleaned = strings.TrimSpace(cleaned)
+
+	if cleaned == "" {
+		return ""
+	}
+
+	// Step 5: Capitalize first letter
+	runes := []rune(cleaned)
+	if len(runes) > 0 {
+		runes[0] = []rune(strings.ToUpper(string(runes[0])))[0]
+	}
+	cleaned = string(runes)
+
+	// Step 6: Add period if missing punctuation at end
+	if len(cleaned) > 0 && !endsWithPunctuation(cleaned) {
+		cleaned += "."
+	}
+
+	return cleaned
+}
+
+// endsWithPunctuation checks if a string ends with common punctuation marks.
+func endsWithPunctuation(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	lastChar := s[len(s)-1]
+	return lastChar == '.' || lastChar == '!' || lastChar == '?' || lastChar == ':' || lastChar == ';'
+}
+
+// ContainsHash checks if description contains a commit hash pattern in parentheses.
+func ContainsHash(desc string) bool {
+	return hashInParensRegex.MatchString(desc)
+}
+
+// NoiseFilterConfig holds configuration for filtering noise commits.
+type NoiseFilterConfig struct {
+	ExcludedTypes []CommitType // Types to always exclude (chore, style, ci, test)
+	NoisePatterns []string     // Regex patterns for noise descriptions
+	PreserveStats bool         // Whether to track original count
+}
+
+// FilterResult contains filtered commits and statistics.
+type FilterResult struct {
+	Commits       []ParsedCommit // Commits that passed the filter
+	OriginalCount int            // Original number of commits before filtering
+	FilteredCount int            // Number of commits that passed the filter
+	NoiseCommits  []ParsedCommit // Commits that were filtered out
+}
+
+// DefaultNoiseFilterConfig returns the default noise filter configuration.
+// Excludes chore, style, ci, test types and common noise patterns.
+func DefaultNoiseFilterConfig() NoiseFilterConfig {
+	return NoiseFilterConfig{
+		ExcludedTypes: []CommitType{
+			CommitTypeChore,
+			CommitTypeStyle,
+			CommitTypeCI,
+			CommitTypeTest,
+		},
+		NoisePatterns: []string{
+			`(?i)^fix(ing)?\s+whitespace`,
+			`(?i)^fix(ing)?\s+typo`,
+			`(?i)^fix(ing)?\s+lint`,
+			`(?i)^fix(ing)?\s+format`,
+			`(?i)^remove\s+trailing`,
+			`(?i)^update\s+lock\s+file`,
+			`(?i)^merge\s+(branch|pull\s+request)`,
+			`(?i)^wip\b`,
+			`(?i)^minor\b`,
+		},
+		PreserveStats: true,
+	}
+}
+
+// IsNoiseCommit checks if a single commit is noise based on the config.
+// A commit is noise if its type is in the excluded types list OR
+// if its description matches any of the noise patterns.
+func IsNoiseCommit(commit ParsedCommit, config NoiseFilterConfig) bool {
+	// Check if type is excluded
+	for _, excludedType := range config.ExcludedTypes {
+		if commit.Type == excludedType {
+			return true
+		}
+	}
+
+	// Check if description matches any noise pattern
+	for _, pattern := range config.NoisePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue // Skip invalid patterns
+		}
+		if re.MatchString(commit.Description) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterCommits removes noise commits from the list based on the config.
+// It filters by excluded types and noise patterns, preserving the original count.
+func FilterCommits(commits []ParsedCommit, config NoiseFilterConfig) FilterResult {
+	result := FilterResult{
+		OriginalCount: len(commits),
+		Commits:       make([]ParsedCommit, 0, len(commits)),
+		NoiseCommits:  make([]ParsedCommit, 0),
+	}
+
+	for _, commit := range commits {
+		if IsNoiseCommit(commit, config) {
+			result.NoiseCommits = append(result.NoiseCommits, commit)
+		} else {
+			result.Commits = append(result.Commits, commit)
+		}
+	}
+
+	result.FilteredCount = len(result.Commits)
+	return result
+}
+
+// FeatureArea represents a detected feature area for grouping commits.
+type FeatureArea string
+
+const (
+	FeatureAreaBuildSystem      FeatureArea = "Build System"
+	FeatureAreaDeployment       FeatureArea = "Deployment"
+	FeatureAreaAuthentication   FeatureArea = "Authentication"
+	FeatureAreaAPI              FeatureArea = "API"
+	FeatureAreaDatabase         FeatureArea = "Database"
+	FeatureAreaUserInterface    FeatureArea = "User Interface"
+	FeatureAreaConfiguration    FeatureArea = "Configuration"
+	FeatureAreaContainerization FeatureArea = "Containerization"
+	FeatureAreaCommunication    FeatureArea = "Communication"
+	FeatureAreaScheduler        FeatureArea = "Scheduler"
+	FeatureAreaLogging          FeatureArea = "Logging"
+	FeatureAreaSecurity         FeatureArea = "Security"
+	FeatureAreaTesting          FeatureArea = "Testing"
+	FeatureAreaDocumentation    FeatureArea = "Documentation"
+	FeatureAreaOther            FeatureArea = "Other"
+)
+
+// KeywordMapping maps keywords to feature areas.
+// Keywords are matched case-insensitively against commit descriptions.
+var KeywordMapping = map[string]FeatureArea{
+	// Build System
+	"build":    FeatureAreaBuildSystem,
+	"nix":      FeatureAreaBuildSystem,
+	"flake":    FeatureAreaBuildSystem,
+	"compile":  FeatureAreaBuildSystem,
+	"makefile": FeatureAreaBuildSystem,
+
+	// Deployment
+	"deploy":     FeatureAreaDeployment,
+	"deployment": FeatureAreaDeployment,
+	"release":    FeatureAreaDeployment,
+	"rollout":    FeatureAreaDeployment,
+
+	// Authentication
+	"auth":           FeatureAreaAuthentication,
+	"authentication": FeatureAreaAuthentication,
+	"login":          FeatureAreaAuthentication,
+	"logout":         FeatureAreaAuthentication,
+	"session":        FeatureAreaAuthentication,
+	"token":          FeatureAreaAuthentication,
+	"oauth":          FeatureAreaAuthentication,
+	"rbac":           FeatureAreaAuthentication,
+
+	// API
+	"api":      FeatureAreaAPI,
+	"endpoint": FeatureAreaAPI,
+	"rest":     FeatureAreaAPI,
+	"openapi":  FeatureAreaAPI,
+	"handler":  FeatureAreaAPI,
+
+	// Database
+	"database":  FeatureAreaDatabase,
+	"db":        FeatureAreaDatabase,
+	"migration": FeatureAreaDatabase,
+	"postgres":  FeatureAreaDatabase,
+	"sql":       FeatureAreaDatabase,
+	"query":     FeatureAreaDatabase,
+	"store":     FeatureAreaDatabase,
+
+	// User Interface
+	"ui":        FeatureAreaUserInterface,
+	"web":       FeatureAreaUserInterface,
+	"dashboard": FeatureAreaUserInterface,
+	"frontend":  FeatureAreaUserInterface,
+	"page":      FeatureAreaUserInterface,
+	"template":  FeatureAreaUserInterface,
+	"templ":     FeatureAreaUserInterface,
+
+	// Configuration
+	"config":   FeatureAreaConfiguration,
+	"settings": FeatureAreaConfiguration,
+	"env":      FeatureAreaConfiguration,
+	"secret":   FeatureAreaConfiguration,
+	"secrets":  FeatureAreaConfiguration,
+
+	// Containerization
+	"docker":     FeatureAreaContainerization,
+	"container":  FeatureAreaContainerization,
+	"podman":     FeatureAreaContainerization,
+	"oci":        FeatureAreaContainerization,
+	"image":      FeatureAreaContainerization,
+	"dockerfile": FeatureAreaContainerization,
+
+	// Communication
+	"grpc":      FeatureAreaCommunication,
+	"websocket": FeatureAreaCommunication,
+	"socket":    FeatureAreaCommunication,
+	"stream":    FeatureAreaCommunication,
+	"proto":     FeatureAreaCommunication,
+	"protobuf":  FeatureAreaCommunication,
+
+	// Scheduler
+	"scheduler": FeatureAreaScheduler,
+	"schedule":  FeatureAreaScheduler,
+	"queue":     FeatureAreaScheduler,
+	"job":       FeatureAreaScheduler,
+	"worker":    FeatureAreaScheduler,
+
+	// Logging
+	"log":     FeatureAreaLogging,
+	"logs":    FeatureAreaLogging,
+	"logging": FeatureAreaLogging,
+	"logger":  FeatureAreaLogging,
+
+	// Security
+	"security":   FeatureAreaSecurity,
+	"encrypt":    FeatureAreaSecurity,
+	"encryption": FeatureAreaSecurity,
+	"sops":       FeatureAreaSecurity,
+	"tls":        FeatureAreaSecurity,
+	"ssl":        FeatureAreaSecurity,
+
+	// Testing
+	"test":  FeatureAreaTesting,
+	"tests": FeatureAreaTesting,
+
+	// Documentation
+	"doc":           FeatureAreaDocumentation,
+	"docs":          FeatureAreaDocumentation,
+	"documentation": FeatureAreaDocumentation,
+	"readme":        FeatureAreaDocumentation,
+}
+
+// DetectFeatureArea analyzes a commit description to detect feature area.
+// It matches keywords case-insensitively and returns the first matching feature area.
+// If no keywords match or multiple conflicting keywords are found, it returns FeatureAreaOther.
+func DetectFeatureArea(commit ParsedCommit) FeatureArea {
+	desc := strings.ToLower(commit.Description)
+
+	// Track matched feature areas to detect ambiguity
+	matchedAreas := make(map[FeatureArea]bool)
+
+	// Check each keyword against the description
+	for keyword, area := range KeywordMapping {
+		// Use word boundary matching to avoid partial matches
+		// Check if keyword appears as a word in the description
+		if containsWord(desc, keyword) {
+			matchedAreas[area] = true
+		}
+	}
+
+	// If no matches found, return Other
+	if len(matchedAreas) == 0 {
+		return FeatureAreaOther
+	}
+
+	// If multiple different areas matched, it's ambiguous - return Other
+	if len(matchedAreas) > 1 {
+		return FeatureAreaOther
+	}
+
+	// Return the single matched area
+	for area := range matchedAreas {
+		return area
+	}
+
+	return FeatureAreaOther
+}
+
+// containsWord checks if a word appears in the text as a complete word.
+// It handles word boundaries to avoid partial matches (e.g., "api" shouldn't match "capital").
+func containsWord(text, word string) bool {
+	// Simple word boundary check using spaces and punctuation
+	text = " " + text + " "
+	word = strings.ToLower(word)
+
+	// Check for the word with various boundary characters
+	boundaries := []string{" ", ".", ",", ":", ";", "-", "_", "/", "(", ")", "[", "]", "'", "\""}
+
+	for _, leftBound := range boundaries {
+		for _, rightBound := range boundaries {
+			if strings.Contains(text, leftBound+word+rightBound) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// GetEffectiveScope returns the scope to use for grouping a commit.
+// If the commit has an explicit scope, it returns that scope.
+// Otherwise, it falls back to the detected feature area from keywords.
+// This ensures explicit scopes take priority over keyword matching.
+func GetEffectiveScope(commit ParsedCommit) string {
+	// If commit has an explicit scope, use it
+	if commit.Scope != "" {
+		return commit.Scope
+	}
+
+	// Fall back to detected feature area
+	area := DetectFeatureArea(commit)
+	return string(area)
+}
+
+// CommitGroup represents a group of related commits.
+// Commits are grouped by their effective scope (explicit scope or detected feature area).
+type CommitGroup struct {
+	Name      string         // Group name (scope or detected feature area)
+	Commits   []ParsedCommit // Commits in this group
+	Summary   string         // Merged summary of changes (for groups > 3 commits)
+	IsSummary bool           // Whether this group uses a summary vs individual list
+}
+
+// GroupCommits groups commits by their effective scope.
+// Commits with the same effective scope are placed in the same group.
+// Groups track whether they should be summarized (>3 commits).
+func GroupCommits(commits []ParsedCommit) []CommitGroup {
+	if len(commits) == 0 {
+		return []CommitGroup{}
+	}
+
+	// Map to collect commits by effective scope
+	groupMap := make(map[string][]ParsedCommit)
+	// Track order of first appearance for consistent output
+	order := make([]string, 0)
+
+	for _, commit := range commits {
+		scope := GetEffectiveScope(commit)
+		if _, exists := groupMap[scope]; !exists {
+			order = append(order, scope)
+		}
+		groupMap[scope] = append(groupMap[scope], commit)
+	}
+
+	// Build groups in order of first appearance
+	groups := make([]CommitGroup, 0, len(order))
+	for _, scope := range order {
+		commits := groupMap[scope]
+		group := CommitGroup{
+			Name:      scope,
+			Commits:   commits,
+			IsSummary: ShouldSummarize(CommitGroup{Commits: commits}),
+		}
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// ShouldSummarize returns true if a group should be summarized.
+// Groups with more than 3 commits should produce a summary instead of listing each commit.
+func ShouldSummarize(group CommitGroup) bool {
+	return len(group.Commits) > 3
+}
+
+// ExtractKeyFeatures identifies the main features from a group of commits.
+// It extracts unique, meaningful descriptions from the commits, removing duplicates
+// and preserving important technical details.
+func ExtractKeyFeatures(commits []ParsedCommit) []string {
+	if len(commits) == 0 {
+		return []string{}
+	}
+
+	// Use a map to track unique features (case-insensitive deduplication)
+	seen := make(map[string]bool)
+	features := make([]string, 0, len(commits))
+
+	for _, commit := range commits {
+		// Clean the description for display
+		cleaned := CleanDescription(commit.Description)
+		if cleaned == "" {
+			continue
+		}
+
+		// Remove trailing period for comparison
+		normalized := strings.TrimSuffix(strings.ToLower(cleaned), ".")
+
+		// Skip if we've seen a similar feature
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+
+		features = append(features, cleaned)
+	}
+
+	return features
+}
+
+// SummarizeGroup creates a high-level summary for a commit group.
+// For groups with more than 3 commits, it creates a concise summary paragraph
+// that extracts key functionality rather than listing every implementation detail.
+// It preserves important technical details that affect users.
+func SummarizeGroup(group CommitGroup) string {
+	if len(group.Commits) == 0 {
+		return ""
+	}
+
+	// For small groups (<=3), don't summarize - return empty to indicate individual listing
+	if !ShouldSummarize(group) {
+		return ""
+	}
+
+	// Extract key features from the commits
+	features := ExtractKeyFeatures(group.Commits)
+	if len(features) == 0 {
+		return ""
+	}
+
+	// Determine the primary action based on commit types
+	primaryAction := determinePrimaryAction(group.Commits)
+
+	// Build the summary
+	var sb strings.Builder
+
+	// Start with the group name and primary action
+	groupName := group.Name
+	if groupName == "" || groupName == string(FeatureAreaOther) {
+		groupName = "Various"
+	}
+
+	// Create a summary based on the number of features
+	if len(features) == 1 {
+		// Single unique feature - just use it directly
+		sb.WriteString(features[0])
+	} else if len(features) <= 3 {
+		// 2-3 unique features - list them concisely
+		sb.WriteString(primaryAction)
+		sb.WriteString(" ")
+		sb.WriteString(strings.ToLower(groupName))
+		sb.WriteString(": ")
+		for i, feature := range features {
+			// Remove trailing period for inline listing
+			feature = strings.TrimSuffix(feature, ".")
+			// Lowercase the first letter for inline listing
+			if len(feature) > 0 {
+				feature = strings.ToLower(feature[:1]) + feature[1:]
+			}
+			if i > 0 {
+				if i == len(features)-1 {
+					sb.WriteString(", and ")
+				} else {
+					sb.WriteString(", ")
+				}
+			}
+			sb.WriteString(feature)
+		}
+		sb.WriteString(".")
+	} else {
+		// Many features - create a high-level summary
+		sb.WriteString(primaryAction)
+		sb.WriteString(" multiple ")
+		sb.WriteString(strings.ToLower(groupName))
+		sb.WriteString(" improvements including ")
+
+		// Take the first 3 most important features
+		topFeatures := features
+		if len(topFeatures) > 3 {
+			topFeatures = topFeatures[:3]
+		}
+
+		for i, feature := range topFeatures {
+			// Remove trailing period for inline listing
+			feature = strings.TrimSuffix(feature, ".")
+			// Lowercase the first letter for inline listing
+			if len(feature) > 0 {
+				feature = strings.ToLower(feature[:1]) + feature[1:]
+			}
+			if i > 0 {
+				if i == len(topFeatures)-1 {
+					sb.WriteString(", and ")
+				} else {
+					sb.WriteString(", ")
+				}
+			}
+			sb.WriteString(feature)
+		}
+
+		// Add indication of more changes
+		remaining := len(features) - 3
+		if remaining > 0 {
+			sb.WriteString(fmt.Sprintf(", plus %d more enhancement", remaining))
+			if remaining > 1 {
+				sb.WriteString("s")
+			}
+		}
+		sb.WriteString(".")
+	}
+
+	return sb.String()
+}
+
+// determinePrimaryAction determines the primary action verb based on commit types.
+// It prioritizes feat > fix > perf > refactor > other types.
+func determinePrimaryAction(commits []ParsedCommit) string {
+	typeCounts := make(map[CommitType]int)
+	for _, c := range commits {
+		typeCounts[c.Type]++
+	}
+
+	// Priority order for determining primary action
+	if typeCounts[CommitTypeFeat] > 0 {
+		return "Enhanced"
+	}
+	if typeCounts[CommitTypeFix] > 0 {
+		return "Fixed"
+	}
+	if typeCounts[CommitTypePerf] > 0 {
+		return "Optimized"
+	}
+	if typeCounts[CommitTypeRefactor] > 0 {
+		return "Improved"
+	}
+	if typeCounts[CommitTypeDocs] > 0 {
+		return "Updated"
+	}
+	if typeCounts[CommitTypeBuild] > 0 {
+		return "Updated"
+	}
+
+	return "Updated"
+}
+
+// ParseRawCommits splits raw release notes into individual commit messages.
+// It handles various formats:
+// - One commit per line
+// - Commits prefixed with "* " or "- " (GitHub release notes format)
+// - Commits with hash prefixes like "abc1234 feat: description"
+func ParseRawCommits(releaseNotes string) []string {
+	if strings.TrimSpace(releaseNotes) == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(releaseNotes, "\n")
+	commits := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Remove common prefixes from GitHub release notes
+		// "* " or "- " bullet points
+		line = strings.TrimPrefix(line, "* ")
+		line = strings.TrimPrefix(line, "- ")
+
+		// Remove leading hash if present (e.g., "abc1234 feat: description")
+		// This pattern matches a short hash followed by space
+		if len(line) > 8 && isHexString(line[:7]) && line[7] == ' ' {
+			line = line[8:]
+		}
+
+		line = strings.TrimSpace(line)
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+
+	return commits
+}
+
+// isHexString checks if a string contains only hexadecimal characters.
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
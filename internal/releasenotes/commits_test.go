@@ -0,0 +1,68 @@
+package releasenotes
+
+import "testing"
+
+// TestParseRawCommits tests the commit parsing helper function.
+func TestParseRawCommits(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "empty input",
+			input:    "",
+			expected: []string{},
+		},
+		{
+			name:     "single commit",
+			input:    "feat: add feature",
+			expected: []string{"feat: add feature"},
+		},
+		{
+			name:     "multiple commits",
+			input:    "feat: add feature\nfix: fix bug\ndocs: update docs",
+			expected: []string{"feat: add feature", "fix: fix bug", "docs: update docs"},
+		},
+		{
+			name:     "commits with bullet points",
+			input:    "* feat: add feature\n* fix: fix bug",
+			expected: []string{"feat: add feature", "fix: fix bug"},
+		},
+		{
+			name:     "commits with dash bullets",
+			input:    "- feat: add feature\n- fix: fix bug",
+			expected: []string{"feat: add feature", "fix: fix bug"},
+		},
+		{
+			name:     "commits with hash prefix",
+			input:    "abc1234 feat: add feature\ndef5678 fix: fix bug",
+			expected: []string{"feat: add feature", "fix: fix bug"},
+		},
+		{
+			name:     "mixed format",
+			input:    "* abc1234 feat: add feature\n- def5678 fix: fix bug\nperf: improve speed",
+			expected: []string{"feat: add feature", "fix: fix bug", "perf: improve speed"},
+		},
+		{
+			name:     "empty lines ignored",
+			input:    "feat: add feature\n\nfix: fix bug\n\n",
+			expected: []string{"feat: add feature", "fix: fix bug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseRawCommits(tt.input)
+			if len(result) != len(tt.expected) {
+				t.Errorf("Expected %d commits, got %d", len(tt.expected), len(result))
+				return
+			}
+			for i, expected := range tt.expected {
+				if result[i] != expected {
+					t.Errorf("Commit %d: expected %q, got %q", i, expected, result[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package releasenotes
+
+import "strings"
+
+// ParseCommitWithFallback parses a raw commit message with fallback handling.
+// If parsing fails or produces an "other" type commit, it ensures the raw message
+// is preserved for display in the "Other Changes" section.
+// This function wraps ParseCommit and ensures graceful degradation.
+func ParseCommitWithFallback(raw string) ParsedCommit {
+	commit := ParseCommit(raw)
+
+	// If the commit type is "other", ensure the raw message is preserved
+	// and the description is set to something displayable
+	if commit.Type == CommitTypeOther {
+		// If description is empty but we have a raw message, use the raw message
+		if commit.Description == "" && commit.Raw != "" {
+			// Use the first line of the raw message as description
+			lines := strings.SplitN(commit.Raw, "\n", 2)
+			commit.Description = strings.TrimSpace(lines[0])
+		}
+		// Ensure raw is always set for fallback commits
+		if commit.Raw == "" {
+			commit.Raw = raw
+		}
+	}
+
+	return commit
+}
+
+// ParseCommitsWithFallback parses multiple raw commit messages with fallback handling.
+// Any commits that fail to parse are categorized as "other" with their raw message preserved.
+// This ensures the release notes generation never fails due to unparseable commits.
+func ParseCommitsWithFallback(rawCommits []string) []ParsedCommit {
+	commits := make([]ParsedCommit, 0, len(rawCommits))
+	for _, raw := range rawCommits {
+		commit := ParseCommitWithFallback(raw)
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// GroupCommitsWithFallback groups commits by their effective scope with fallback handling.
+// If grouping fails for any reason, it falls back to listing commits by type without grouping.
+// This ensures the release notes generation never fails due to grouping errors.
+func GroupCommitsWithFallback(commits []ParsedCommit) []CommitGroup {
+	// Attempt normal grouping
+	groups := GroupCommits(commits)
+
+	// If grouping produced no results but we have commits, fall back to type-based listing
+	if len(groups) == 0 && len(commits) > 0 {
+		return groupCommitsByTypeOnly(commits)
+	}
+
+	return groups
+}
+
+// groupCommitsByTypeOnly creates groups based solely on commit type.
+// This is a fallback when scope-based grouping fails or produces no results.
+// Each commit type becomes its own group, ensuring all commits are included.
+func groupCommitsByTypeOnly(commits []ParsedCommit) []CommitGroup {
+	if len(commits) == 0 {
+		return []CommitGroup{}
+	}
+
+	// Map to collect commits by type
+	typeMap := make(map[CommitType][]ParsedCommit)
+	// Track order of first appearance for consistent output
+	order := make([]CommitType, 0)
+
+	for _, commit := range commits {
+		if _, exists := typeMap[commit.Type]; !exists {
+			order = append(order, commit.Type)
+		}
+		typeMap[commit.Type] = append(typeMap[commit.Type], commit)
+	}
+
+	// Build groups in order of first appearance
+	groups := make([]CommitGroup, 0, len(order))
+	for _, commitType := range order {
+		typeCommits := typeMap[commitType]
+		group := CommitGroup{
+			Name:      getTypeDisplayName(commitType),
+			Commits:   typeCommits,
+			IsSummary: len(typeCommits) > 3,
+		}
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// getTypeDisplayName returns a human-readable display name for a commit type.
+// Used when falling back to type-based grouping.
+func getTypeDisplayName(t CommitType) string {
+	switch t {
+	case CommitTypeFeat:
+		return "Features"
+	case CommitTypeFix:
+		return "Bug Fixes"
+	case CommitTypeDocs:
+		return "Documentation"
+	case CommitTypeStyle:
+		return "Style"
+	case CommitTypeRefactor:
+		return "Refactoring"
+	case CommitTypePerf:
+		return "Performance"
+	case CommitTypeTest:
+		return "Testing"
+	case CommitTypeBuild:
+		return "Build"
+	case CommitTypeCI:
+		return "CI/CD"
+	case CommitTypeChore:
+		return "Chores"
+	case CommitTypeOther:
+		return "Other Changes"
+	default:
+		return "Other Changes"
+	}
+}
+
+// CategorizeCommitsWithFallback categorizes commit groups into sections with fallback handling.
+// If normal categorization fails, it falls back to placing all commits in the "Other" section.
+// This ensures the release notes generation never fails due to categorization errors.
+func CategorizeCommitsWithFallback(groups []CommitGroup) CategorizedContent {
+	// Attempt normal categorization
+	result := CategorizeCommits(groups)
+
+	// If categorization produced no content but we have groups, fall back to Other section
+	if !result.HasContent() && len(groups) > 0 {
+		// Place all commits in Other section
+		result.Other = groups
+	}
+
+	return result
+}
+
+// ProcessCommitsWithFallback is a high-level function that processes raw commit messages
+// through the entire pipeline with fallback handling at each stage.
+// It parses, filters, groups, and categorizes commits, ensuring graceful degradation
+// at each step if errors occur.
+func ProcessCommitsWithFallback(rawCommits []string, filterConfig NoiseFilterConfig) CategorizedContent {
+	// Step 1: Parse commits with fallback
+	commits := ParseCommitsWithFallback(rawCommits)
+
+	// Step 2: Filter noise commits (this is safe and won't fail)
+	filterResult := FilterCommits(commits, filterConfig)
+
+	// Step 3: Group commits with fallback
+	groups := GroupCommitsWithFallback(filterResult.Commits)
+
+	// Step 4: Categorize with fallback
+	return CategorizeCommitsWithFallback(groups)
+}
+
+// EnsureOtherCommitsPreserved ensures that commits with type "other" are properly
+// preserved in the categorized content. This is a validation function that can be
+// used to verify the fallback behavior is working correctly.
+func EnsureOtherCommitsPreserved(commits []ParsedCommit, content CategorizedContent) bool {
+	// Count "other" type commits in input
+	otherCount := 0
+	for _, c := range commits {
+		if c.Type == CommitTypeOther && !c.BreakingChange {
+			otherCount++
+		}
+	}
+
+	// Count commits in Other section
+	otherInContent := 0
+	for _, group := range content.Other {
+		otherInContent += len(group.Commits)
+	}
+
+	// All "other" type commits should be in the Other section
+	return otherInContent >= otherCount
+}
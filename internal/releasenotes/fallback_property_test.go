@@ -0,0 +1,250 @@
+package releasenotes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// genUnparseableCommit generates commit messages that cannot be parsed as conventional commits.
+// These are messages that will result in type "other" when parsed.
+func genUnparseableCommit() gopter.Gen {
+	return gen.OneConstOf(
+		// Plain text messages without any structure
+		"this is a plain message without structure",
+		"updated some files",
+		"made changes to the codebase",
+		"work in progress",
+		"initial commit",
+		// Messages with invalid type prefixes
+		"invalid_type: some description",
+		"unknown: another description",
+		"random: yet another message",
+		// Messages starting with numbers
+		"123: numbered message",
+		"456: another numbered one",
+		// Messages with special characters
+		"* bullet point message",
+		"- dash message",
+		"# hash message",
+		// Merge commit messages
+		"Merge branch 'feature' into main",
+		"Merge pull request #123",
+		"Merged changes from upstream",
+	)
+}
+
+// genMixedRawCommitList generates a list of raw commit strings with both parseable and unparseable messages.
+func genMixedRawCommitList() gopter.Gen {
+	return gen.SliceOfN(5, gen.OneGenOf(
+		// Conventional commits
+		gen.OneConstOf(
+			"feat: add new feature",
+			"fix: resolve bug",
+			"docs: update readme",
+			"feat(api): add endpoint",
+			"fix(web): fix login",
+		),
+		// Unparseable commits
+		genUnparseableCommit(),
+	))
+}
+
+// **Feature: intelligent-release-notes, Property 26: Failed parsing falls back to Other Changes**
+// For any commit that fails to parse, it SHALL appear in the "Other Changes" section
+// with its raw message preserved.
+// **Validates: Requirements 14.1**
+func TestPropertyParsingFallback(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	// Property 26.1: Unparseable commits get type "other"
+	properties.Property("unparseable commits get type other", prop.ForAll(
+		func(raw string) bool {
+			if raw == "" {
+				return true // Skip empty strings
+			}
+			commit := ParseCommitWithFallback(raw)
+			return commit.Type == CommitTypeOther
+		},
+		genUnparseableCommit(),
+	))
+
+	// Property 26.2: Unparseable commits preserve raw message
+	properties.Property("unparseable commits preserve raw message", prop.ForAll(
+		func(raw string) bool {
+			if raw == "" {
+				return true // Skip empty strings
+			}
+			commit := ParseCommitWithFallback(raw)
+			// Raw should be preserved
+			return commit.Raw == strings.TrimSpace(raw)
+		},
+		genUnparseableCommit(),
+	))
+
+	// Property 26.3: Unparseable commits have non-empty description
+	properties.Property("unparseable commits have non-empty description", prop.ForAll(
+		func(raw string) bool {
+			if raw == "" {
+				return true // Skip empty strings
+			}
+			commit := ParseCommitWithFallback(raw)
+			// Description should not be empty for non-empty input
+			return commit.Description != ""
+		},
+		genUnparseableCommit(),
+	))
+
+	// Property 26.4: Unparseable commits appear in Other section after categorization
+	properties.Property("unparseable commits appear in Other section after categorization", prop.ForAll(
+		func(raw string) bool {
+			if raw == "" {
+				return true // Skip empty strings
+			}
+
+			// Parse the commit
+			commit := ParseCommitWithFallback(raw)
+			commits := []ParsedCommit{commit}
+
+			// Group and categorize
+			groups := GroupCommitsWithFallback(commits)
+			content := CategorizeCommitsWithFallback(groups)
+
+			// The commit should be in the Other section
+			otherCount := 0
+			for _, group := range content.Other {
+				otherCount += len(group.Commits)
+			}
+			return otherCount == 1
+		},
+		genUnparseableCommit(),
+	))
+
+	// Property 26.5: Mixed commits are properly separated
+	properties.Property("mixed commits are properly separated", prop.ForAll(
+		func(rawCommits []string) bool {
+			if len(rawCommits) == 0 {
+				return true
+			}
+
+			// Parse all commits
+			commits := ParseCommitsWithFallback(rawCommits)
+
+			// Count expected "other" type commits
+			expectedOther := 0
+			for _, c := range commits {
+				if c.Type == CommitTypeOther {
+					expectedOther++
+				}
+			}
+
+			// Group and categorize (without filtering to preserve all commits)
+			groups := GroupCommitsWithFallback(commits)
+			content := CategorizeCommitsWithFallback(groups)
+
+			// Count actual "other" commits in content
+			actualOther := 0
+			for _, group := range content.Other {
+				actualOther += len(group.Commits)
+			}
+
+			// All "other" type commits should be in Other section
+			return actualOther >= expectedOther
+		},
+		genMixedRawCommitList(),
+	))
+
+	// Property 26.6: ParseCommitsWithFallback processes all commits
+	properties.Property("ParseCommitsWithFallback processes all commits", prop.ForAll(
+		func(rawCommits []string) bool {
+			commits := ParseCommitsWithFallback(rawCommits)
+			return len(commits) == len(rawCommits)
+		},
+		genMixedRawCommitList(),
+	))
+
+	// Property 26.7: ProcessCommitsWithFallback never panics
+	properties.Property("ProcessCommitsWithFallback never panics", prop.ForAll(
+		func(rawCommits []string) bool {
+			// This should never panic
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("ProcessCommitsWithFallback panicked: %v", r)
+				}
+			}()
+
+			config := DefaultNoiseFilterConfig()
+			_ = ProcessCommitsWithFallback(rawCommits, config)
+			return true
+		},
+		genMixedRawCommitList(),
+	))
+
+	// Property 26.8: EnsureOtherCommitsPreserved validates correctly
+	properties.Property("EnsureOtherCommitsPreserved validates correctly", prop.ForAll(
+		func(rawCommits []string) bool {
+			if len(rawCommits) == 0 {
+				return true
+			}
+
+			// Parse commits
+			commits := ParseCommitsWithFallback(rawCommits)
+
+			// Group and categorize
+			groups := GroupCommitsWithFallback(commits)
+			content := CategorizeCommitsWithFallback(groups)
+
+			// Validation should pass
+			return EnsureOtherCommitsPreserved(commits, content)
+		},
+		genMixedRawCommitList(),
+	))
+
+	// Property 26.9: Grouping fallback produces valid groups
+	properties.Property("grouping fallback produces valid groups", prop.ForAll(
+		func(rawCommits []string) bool {
+			if len(rawCommits) == 0 {
+				return true
+			}
+
+			commits := ParseCommitsWithFallback(rawCommits)
+			groups := GroupCommitsWithFallback(commits)
+
+			// All commits should be in some group
+			totalInGroups := 0
+			for _, g := range groups {
+				totalInGroups += len(g.Commits)
+			}
+
+			return totalInGroups == len(commits)
+		},
+		genMixedRawCommitList(),
+	))
+
+	// Property 26.10: Type-based fallback grouping works
+	properties.Property("type-based fallback grouping works", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true
+			}
+
+			groups := groupCommitsByTypeOnly(commits)
+
+			// All commits should be in some group
+			totalInGroups := 0
+			for _, g := range groups {
+				totalInGroups += len(g.Commits)
+			}
+
+			return totalInGroups == len(commits)
+		},
+		genCommitList(),
+	))
+
+	properties.TestingRun(t)
+}
@@ -0,0 +1,269 @@
+package releasenotes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReleaseSection represents a section in the release notes.
+// Each section groups commits by their semantic meaning (features, fixes, etc.).
+type ReleaseSection string
+
+const (
+	SectionFeatures        ReleaseSection = "features"
+	SectionImprovements    ReleaseSection = "improvements"
+	SectionBugFixes        ReleaseSection = "bugfixes"
+	SectionBreakingChanges ReleaseSection = "breaking"
+	SectionOther           ReleaseSection = "other"
+)
+
+// SectionHeaders maps each section to its icon-enhanced header.
+// These headers are used when generating the release notes markdown.
+// Uses Font Awesome SVG icons for visual appeal.
+var SectionHeaders = map[ReleaseSection]string{
+	SectionFeatures:        "![rocket](../../assets/icons/rocket.svg) New Features & Enhancements",
+	SectionImprovements:    "![bolt](../../assets/icons/bolt.svg) Performance & Improvements",
+	SectionBugFixes:        "![bug](../../assets/icons/bug.svg) Bug Fixes",
+	SectionBreakingChanges: "![warning](../../assets/icons/warning.svg) Breaking Changes",
+	SectionOther:           "![box](../../assets/icons/box.svg) Other Changes",
+}
+
+// SectionHeader returns the icon-enhanced header for a section.
+// Returns the appropriate header with icon prefix based on the section type.
+func SectionHeader(section ReleaseSection) string {
+	if header, ok := SectionHeaders[section]; ok {
+		return header
+	}
+	return "![box](../../assets/icons/box.svg) Other Changes"
+}
+
+// CategorizedContent holds commits organized by section.
+// This is the result of categorizing commit groups into release note sections.
+type CategorizedContent struct {
+	Features        []CommitGroup // New features (feat commits)
+	Improvements    []CommitGroup // Performance improvements (perf commits)
+	BugFixes        []CommitGroup // Bug fixes (fix commits)
+	BreakingChanges []CommitGroup // Breaking changes (commits with BreakingChange=true)
+	Other           []CommitGroup // Other changes (remaining commits)
+}
+
+// HasContent returns true if any section has commits.
+// Used to determine if there's any content to render in the release notes.
+func (c CategorizedContent) HasContent() bool {
+	return len(c.Features) > 0 ||
+		len(c.Improvements) > 0 ||
+		len(c.BugFixes) > 0 ||
+		len(c.BreakingChanges) > 0 ||
+		len(c.Other) > 0
+}
+
+// NonEmptySections returns a list of sections that have content.
+// Useful for iterating only over sections that should be rendered.
+func (c CategorizedContent) NonEmptySections() []ReleaseSection {
+	var sections []ReleaseSection
+	if len(c.BreakingChanges) > 0 {
+		sections = append(sections, SectionBreakingChanges)
+	}
+	if len(c.Features) > 0 {
+		sections = append(sections, SectionFeatures)
+	}
+	if len(c.Improvements) > 0 {
+		sections = append(sections, SectionImprovements)
+	}
+	if len(c.BugFixes) > 0 {
+		sections = append(sections, SectionBugFixes)
+	}
+	if len(c.Other) > 0 {
+		sections = append(sections, SectionOther)
+	}
+	return sections
+}
+
+// GetSectionGroups returns the commit groups for a given section.
+func (c CategorizedContent) GetSectionGroups(section ReleaseSection) []CommitGroup {
+	switch section {
+	case SectionFeatures:
+		return c.Features
+	case SectionImprovements:
+		return c.Improvements
+	case SectionBugFixes:
+		return c.BugFixes
+	case SectionBreakingChanges:
+		return c.BreakingChanges
+	case SectionOther:
+		return c.Other
+	default:
+		return nil
+	}
+}
+
+// CategorizeCommits organizes commit groups into sections based on commit types.
+// - feat commits go to Features
+// - fix commits go to BugFixes
+// - perf commits go to Improvements
+// - Commits with BreakingChange=true go to BreakingChanges (regardless of type)
+// - Remaining commits go to Other
+//
+// Note: A commit with BreakingChange=true will appear in BreakingChanges AND
+// its type-appropriate section (e.g., a breaking feat will be in both Features and BreakingChanges).
+func CategorizeCommits(groups []CommitGroup) CategorizedContent {
+	result := CategorizedContent{
+		Features:        make([]CommitGroup, 0),
+		Improvements:    make([]CommitGroup, 0),
+		BugFixes:        make([]CommitGroup, 0),
+		BreakingChanges: make([]CommitGroup, 0),
+		Other:           make([]CommitGroup, 0),
+	}
+
+	for _, group := range groups {
+		// Separate commits by their target section
+		featCommits := make([]ParsedCommit, 0)
+		fixCommits := make([]ParsedCommit, 0)
+		perfCommits := make([]ParsedCommit, 0)
+		breakingCommits := make([]ParsedCommit, 0)
+		otherCommits := make([]ParsedCommit, 0)
+
+		for _, commit := range group.Commits {
+			// Breaking changes go to their own section
+			if commit.BreakingChange {
+				breakingCommits = append(breakingCommits, commit)
+			}
+
+			// Categorize by type
+			switch commit.Type {
+			case CommitTypeFeat:
+				featCommits = append(featCommits, commit)
+			case CommitTypeFix:
+				fixCommits = append(fixCommits, commit)
+			case CommitTypePerf:
+				perfCommits = append(perfCommits, commit)
+			default:
+				// Only add to Other if not a breaking change (to avoid duplication)
+				if !commit.BreakingChange {
+					otherCommits = append(otherCommits, commit)
+				}
+			}
+		}
+
+		// Create groups for each section if there are commits
+		if len(featCommits) > 0 {
+			result.Features = append(result.Features, CommitGroup{
+				Name:      group.Name,
+				Commits:   featCommits,
+				IsSummary: len(featCommits) > 3,
+			})
+		}
+
+		if len(fixCommits) > 0 {
+			result.BugFixes = append(result.BugFixes, CommitGroup{
+				Name:      group.Name,
+				Commits:   fixCommits,
+				IsSummary: len(fixCommits) > 3,
+			})
+		}
+
+		if len(perfCommits) > 0 {
+			result.Improvements = append(result.Improvements, CommitGroup{
+				Name:      group.Name,
+				Commits:   perfCommits,
+				IsSummary: len(perfCommits) > 3,
+			})
+		}
+
+		if len(breakingCommits) > 0 {
+			result.BreakingChanges = append(result.BreakingChanges, CommitGroup{
+				Name:      group.Name,
+				Commits:   breakingCommits,
+				IsSummary: len(breakingCommits) > 3,
+			})
+		}
+
+		if len(otherCommits) > 0 {
+			result.Other = append(result.Other, CommitGroup{
+				Name:      group.Name,
+				Commits:   otherCommits,
+				IsSummary: len(otherCommits) > 3,
+			})
+		}
+	}
+
+	return result
+}
+
+// CategorizeCommitsByType is a simpler categorization that works directly on commits
+// without pre-grouping. It groups commits by type first, then by scope within each section.
+func CategorizeCommitsByType(commits []ParsedCommit) CategorizedContent {
+	// First group all commits by scope
+	groups := GroupCommits(commits)
+	// Then categorize the groups
+	return CategorizeCommits(groups)
+}
+
+// FormatSectionItem formats a single commit or group for display in release notes.
+// It uses consistent indentation (bullet point with space) for all items.
+const SectionItemIndent = "- "
+
+// FormatSectionItems formats all items in a section with consistent indentation.
+// Each item is prefixed with "- " for bullet point formatting.
+func FormatSectionItems(groups []CommitGroup) []string {
+	var items []string
+
+	for _, group := range groups {
+		if group.IsSummary && len(group.Commits) > 3 {
+			// Use summary for large groups, preferring one set by SummarizeContent
+			summary := group.Summary
+			if summary == "" {
+				summary = SummarizeGroup(group)
+			}
+			if summary != "" {
+				// Format as bold group name followed by summary
+				item := fmt.Sprintf("%s**%s**: %s", SectionItemIndent, group.Name, summary)
+				items = append(items, item)
+			}
+		} else {
+			// List individual commits
+			for _, commit := range group.Commits {
+				desc := CleanDescription(commit.Description)
+				if desc == "" {
+					continue
+				}
+				if group.Name != "" && group.Name != string(FeatureAreaOther) {
+					// Include scope/group name for context
+					item := fmt.Sprintf("%s**%s**: %s", SectionItemIndent, group.Name, desc)
+					items = append(items, item)
+				} else {
+					// No scope, just the description
+					item := fmt.Sprintf("%s%s", SectionItemIndent, desc)
+					items = append(items, item)
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// FormatSection formats a complete section with header and items.
+// Returns empty string if the section has no items.
+func FormatSection(section ReleaseSection, groups []CommitGroup) string {
+	if len(groups) == 0 {
+		return ""
+	}
+
+	items := FormatSectionItems(groups)
+	if len(items) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## ")
+	sb.WriteString(SectionHeader(section))
+	sb.WriteString("\n\n")
+
+	for _, item := range items {
+		sb.WriteString(item)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,604 @@
+package releasenotes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// genFeatCommit generates a feat commit for testing section placement.
+func genFeatCommit() gopter.Gen {
+	return gopter.CombineGens(
+		gen.OneConstOf("", "api", "web", "db", "auth"),
+		genNonNoiseDescription(),
+		gen.Bool(),
+	).Map(func(values []interface{}) ParsedCommit {
+		return ParsedCommit{
+			Type:           CommitTypeFeat,
+			Scope:          values[0].(string),
+			Description:    values[1].(string),
+			BreakingChange: values[2].(bool),
+		}
+	})
+}
+
+// genFixCommit generates a fix commit for testing section placement.
+func genFixCommit() gopter.Gen {
+	return gopter.CombineGens(
+		gen.OneConstOf("", "api", "web", "db", "auth"),
+		genNonNoiseDescription(),
+		gen.Bool(),
+	).Map(func(values []interface{}) ParsedCommit {
+		return ParsedCommit{
+			Type:           CommitTypeFix,
+			Scope:          values[0].(string),
+			Description:    values[1].(string),
+			BreakingChange: values[2].(bool),
+		}
+	})
+}
+
+// genPerfCommit generates a perf commit for testing section placement.
+func genPerfCommit() gopter.Gen {
+	return gopter.CombineGens(
+		gen.OneConstOf("", "api", "web", "db", "auth"),
+		genNonNoiseDescription(),
+		gen.Bool(),
+	).Map(func(values []interface{}) ParsedCommit {
+		return ParsedCommit{
+			Type:           CommitTypePerf,
+			Scope:          values[0].(string),
+			Description:    values[1].(string),
+			BreakingChange: values[2].(bool),
+		}
+	})
+}
+
+// genBreakingCommit generates a commit with BreakingChange=true.
+func genBreakingCommit() gopter.Gen {
+	return gopter.CombineGens(
+		genNonExcludedCommitType(),
+		gen.OneConstOf("", "api", "web", "db", "auth"),
+		genNonNoiseDescription(),
+	).Map(func(values []interface{}) ParsedCommit {
+		return ParsedCommit{
+			Type:           values[0].(CommitType),
+			Scope:          values[1].(string),
+			Description:    values[2].(string),
+			BreakingChange: true,
+		}
+	})
+}
+
+// **Feature: intelligent-release-notes, Property 8: Commits placed in correct section by type**
+// For any commit with type "feat", it SHALL appear in the Features section;
+// type "fix" SHALL appear in BugFixes; commits with BreakingChange=true SHALL appear in BreakingChanges.
+// **Validates: Requirements 4.2, 4.3, 4.5**
+func TestPropertySectionPlacement(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	// Property 8.1: feat commits appear in Features section
+	properties.Property("feat commits appear in Features section", prop.ForAll(
+		func(commit ParsedCommit) bool {
+			groups := GroupCommits([]ParsedCommit{commit})
+			categorized := CategorizeCommits(groups)
+			// Should have at least one group in Features
+			if len(categorized.Features) == 0 {
+				return false
+			}
+			// The commit should be in the Features section
+			for _, group := range categorized.Features {
+				for _, c := range group.Commits {
+					if c.Description == commit.Description && c.Type == CommitTypeFeat {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		genFeatCommit(),
+	))
+
+	// Property 8.2: fix commits appear in BugFixes section
+	properties.Property("fix commits appear in BugFixes section", prop.ForAll(
+		func(commit ParsedCommit) bool {
+			groups := GroupCommits([]ParsedCommit{commit})
+			categorized := CategorizeCommits(groups)
+			// Should have at least one group in BugFixes
+			if len(categorized.BugFixes) == 0 {
+				return false
+			}
+			// The commit should be in the BugFixes section
+			for _, group := range categorized.BugFixes {
+				for _, c := range group.Commits {
+					if c.Description == commit.Description && c.Type == CommitTypeFix {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		genFixCommit(),
+	))
+
+	// Property 8.3: perf commits appear in Improvements section
+	properties.Property("perf commits appear in Improvements section", prop.ForAll(
+		func(commit ParsedCommit) bool {
+			groups := GroupCommits([]ParsedCommit{commit})
+			categorized := CategorizeCommits(groups)
+			// Should have at least one group in Improvements
+			if len(categorized.Improvements) == 0 {
+				return false
+			}
+			// The commit should be in the Improvements section
+			for _, group := range categorized.Improvements {
+				for _, c := range group.Commits {
+					if c.Description == commit.Description && c.Type == CommitTypePerf {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		genPerfCommit(),
+	))
+
+	// Property 8.4: breaking change commits appear in BreakingChanges section
+	properties.Property("breaking change commits appear in BreakingChanges section", prop.ForAll(
+		func(commit ParsedCommit) bool {
+			groups := GroupCommits([]ParsedCommit{commit})
+			categorized := CategorizeCommits(groups)
+			// Should have at least one group in BreakingChanges
+			if len(categorized.BreakingChanges) == 0 {
+				return false
+			}
+			// The commit should be in the BreakingChanges section
+			for _, group := range categorized.BreakingChanges {
+				for _, c := range group.Commits {
+					if c.Description == commit.Description && c.BreakingChange {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		genBreakingCommit(),
+	))
+
+	// Property 8.5: mixed commits are placed in correct sections
+	properties.Property("mixed commits are placed in correct sections", prop.ForAll(
+		func(featCommit ParsedCommit, fixCommit ParsedCommit, perfCommit ParsedCommit) bool {
+			// Ensure commits are not breaking for this test
+			featCommit.BreakingChange = false
+			fixCommit.BreakingChange = false
+			perfCommit.BreakingChange = false
+
+			commits := []ParsedCommit{featCommit, fixCommit, perfCommit}
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// Count commits in each section
+			featCount := 0
+			for _, g := range categorized.Features {
+				featCount += len(g.Commits)
+			}
+			fixCount := 0
+			for _, g := range categorized.BugFixes {
+				fixCount += len(g.Commits)
+			}
+			perfCount := 0
+			for _, g := range categorized.Improvements {
+				perfCount += len(g.Commits)
+			}
+
+			// Each section should have exactly 1 commit
+			return featCount == 1 && fixCount == 1 && perfCount == 1
+		},
+		genFeatCommit(),
+		genFixCommit(),
+		genPerfCommit(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// genCommitsOfSingleType generates commits all of the same type.
+func genCommitsOfSingleType(commitType CommitType) gopter.Gen {
+	return gen.IntRange(1, 5).FlatMap(func(n interface{}) gopter.Gen {
+		return gen.SliceOfN(n.(int), gopter.CombineGens(
+			gen.OneConstOf("", "api", "web", "db"),
+			genNonNoiseDescription(),
+		).Map(func(values []interface{}) ParsedCommit {
+			return ParsedCommit{
+				Type:           commitType,
+				Scope:          values[0].(string),
+				Description:    values[1].(string),
+				BreakingChange: false,
+			}
+		}))
+	}, reflect.TypeOf([]ParsedCommit{}))
+}
+
+// **Feature: intelligent-release-notes, Property 9: Empty sections omitted from output**
+// For any CategorizedContent where a section has zero commit groups,
+// the generated markdown SHALL not contain that section's heading.
+// **Validates: Requirements 4.6**
+func TestPropertyEmptySections(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	// Property 9.1: Only feat commits means only Features section has content
+	properties.Property("only feat commits means only Features section has content", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// Features should have content
+			if len(categorized.Features) == 0 {
+				return false
+			}
+			// Other sections should be empty
+			return len(categorized.BugFixes) == 0 &&
+				len(categorized.Improvements) == 0 &&
+				len(categorized.BreakingChanges) == 0
+		},
+		genCommitsOfSingleType(CommitTypeFeat),
+	))
+
+	// Property 9.2: Only fix commits means only BugFixes section has content
+	properties.Property("only fix commits means only BugFixes section has content", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// BugFixes should have content
+			if len(categorized.BugFixes) == 0 {
+				return false
+			}
+			// Other sections should be empty
+			return len(categorized.Features) == 0 &&
+				len(categorized.Improvements) == 0 &&
+				len(categorized.BreakingChanges) == 0
+		},
+		genCommitsOfSingleType(CommitTypeFix),
+	))
+
+	// Property 9.3: Only perf commits means only Improvements section has content
+	properties.Property("only perf commits means only Improvements section has content", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// Improvements should have content
+			if len(categorized.Improvements) == 0 {
+				return false
+			}
+			// Other sections should be empty
+			return len(categorized.Features) == 0 &&
+				len(categorized.BugFixes) == 0 &&
+				len(categorized.BreakingChanges) == 0
+		},
+		genCommitsOfSingleType(CommitTypePerf),
+	))
+
+	// Property 9.4: NonEmptySections returns only sections with content
+	properties.Property("NonEmptySections returns only sections with content", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+			nonEmpty := categorized.NonEmptySections()
+
+			// Check that all returned sections actually have content
+			for _, section := range nonEmpty {
+				groups := categorized.GetSectionGroups(section)
+				if len(groups) == 0 {
+					return false
+				}
+			}
+
+			// Check that no section with content is missing
+			if len(categorized.Features) > 0 {
+				found := false
+				for _, s := range nonEmpty {
+					if s == SectionFeatures {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return false
+				}
+			}
+
+			return true
+		},
+		genCommitsOfSingleType(CommitTypeFeat),
+	))
+
+	// Property 9.5: Empty input produces empty categorized content
+	properties.Property("empty input produces empty categorized content", prop.ForAll(
+		func(_ bool) bool {
+			groups := GroupCommits([]ParsedCommit{})
+			categorized := CategorizeCommits(groups)
+
+			return len(categorized.Features) == 0 &&
+				len(categorized.BugFixes) == 0 &&
+				len(categorized.Improvements) == 0 &&
+				len(categorized.BreakingChanges) == 0 &&
+				len(categorized.Other) == 0 &&
+				!categorized.HasContent()
+		},
+		gen.Bool(),
+	))
+
+	// Property 9.6: HasContent returns false for empty categorized content
+	properties.Property("HasContent returns false for empty categorized content", prop.ForAll(
+		func(_ bool) bool {
+			categorized := CategorizedContent{}
+			return !categorized.HasContent()
+		},
+		gen.Bool(),
+	))
+
+	// Property 9.7: HasContent returns true when any section has content
+	properties.Property("HasContent returns true when any section has content", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true // Skip empty input
+			}
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+			return categorized.HasContent()
+		},
+		genCommitsOfSingleType(CommitTypeFeat),
+	))
+
+	properties.TestingRun(t)
+}
+
+// genReleaseSection generates valid ReleaseSection values.
+func genReleaseSection() gopter.Gen {
+	return gen.OneConstOf(
+		SectionFeatures,
+		SectionImprovements,
+		SectionBugFixes,
+		SectionBreakingChanges,
+		SectionOther,
+	)
+}
+
+// **Feature: intelligent-release-notes, Property 22: Section headers use icon format**
+// For any non-empty section in the output, the section header SHALL contain
+// the appropriate icon prefix (✦, ↑, ✓, !, or ○).
+// **Validates: Requirements 11.1, 11.2, 11.3, 11.4**
+func TestPropertyEmojiHeaders(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	// Property 22.1: Features section header contains rocket icon
+	properties.Property("Features section header contains rocket icon", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(SectionFeatures)
+			return strings.Contains(header, "rocket.svg") &&
+				strings.Contains(header, "New Features")
+		},
+		gen.Bool(),
+	))
+
+	// Property 22.2: Improvements section header contains bolt icon
+	properties.Property("Improvements section header contains bolt icon", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(SectionImprovements)
+			return strings.Contains(header, "bolt.svg") &&
+				strings.Contains(header, "Performance")
+		},
+		gen.Bool(),
+	))
+
+	// Property 22.3: BugFixes section header contains bug icon
+	properties.Property("BugFixes section header contains bug icon", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(SectionBugFixes)
+			return strings.Contains(header, "bug.svg") &&
+				strings.Contains(header, "Bug Fixes")
+		},
+		gen.Bool(),
+	))
+
+	// Property 22.4: BreakingChanges section header contains warning icon
+	properties.Property("BreakingChanges section header contains warning icon", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(SectionBreakingChanges)
+			return strings.Contains(header, "warning.svg") &&
+				strings.Contains(header, "Breaking Changes")
+		},
+		gen.Bool(),
+	))
+
+	// Property 22.5: Other section header contains box icon
+	properties.Property("Other section header contains box icon", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(SectionOther)
+			return strings.Contains(header, "box.svg") &&
+				strings.Contains(header, "Other Changes")
+		},
+		gen.Bool(),
+	))
+
+	// Property 22.6: All sections have non-empty headers
+	properties.Property("all sections have non-empty headers", prop.ForAll(
+		func(section ReleaseSection) bool {
+			header := SectionHeader(section)
+			return len(header) > 0
+		},
+		genReleaseSection(),
+	))
+
+	// Property 22.7: SectionHeaders map contains all sections
+	properties.Property("SectionHeaders map contains all sections", prop.ForAll(
+		func(section ReleaseSection) bool {
+			_, exists := SectionHeaders[section]
+			return exists
+		},
+		genReleaseSection(),
+	))
+
+	// Property 22.8: Unknown section returns default header
+	properties.Property("unknown section returns default header", prop.ForAll(
+		func(_ bool) bool {
+			header := SectionHeader(ReleaseSection("unknown"))
+			return strings.Contains(header, "box.svg") &&
+				strings.Contains(header, "Other Changes")
+		},
+		gen.Bool(),
+	))
+
+	properties.TestingRun(t)
+}
+
+// genMixedCommitList generates a list of commits with various types and scopes.
+func genMixedCommitList() gopter.Gen {
+	return gen.IntRange(2, 10).FlatMap(func(n interface{}) gopter.Gen {
+		return gen.SliceOfN(n.(int), gopter.CombineGens(
+			gen.OneConstOf(CommitTypeFeat, CommitTypeFix, CommitTypePerf, CommitTypeDocs, CommitTypeRefactor),
+			gen.OneConstOf("", "api", "web", "db", "auth"),
+			genNonNoiseDescription(),
+			gen.Bool(),
+		).Map(func(values []interface{}) ParsedCommit {
+			return ParsedCommit{
+				Type:           values[0].(CommitType),
+				Scope:          values[1].(string),
+				Description:    values[2].(string),
+				BreakingChange: values[3].(bool),
+			}
+		}))
+	}, reflect.TypeOf([]ParsedCommit{}))
+}
+
+// **Feature: intelligent-release-notes, Property 23: Section items have consistent indentation**
+// For any section with multiple items, all items SHALL have the same indentation level.
+// **Validates: Requirements 11.5**
+func TestPropertyConsistentIndentation(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	// Property 23.1: All formatted items start with the same indent
+	properties.Property("all formatted items start with the same indent", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true
+			}
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// Check each section
+			for _, section := range categorized.NonEmptySections() {
+				sectionGroups := categorized.GetSectionGroups(section)
+				items := FormatSectionItems(sectionGroups)
+
+				// All items should start with "- "
+				for _, item := range items {
+					if !strings.HasPrefix(item, SectionItemIndent) {
+						return false
+					}
+				}
+			}
+			return true
+		},
+		genMixedCommitList(),
+	))
+
+	// Property 23.2: SectionItemIndent is consistent
+	properties.Property("SectionItemIndent is consistent", prop.ForAll(
+		func(_ bool) bool {
+			return SectionItemIndent == "- "
+		},
+		gen.Bool(),
+	))
+
+	// Property 23.3: FormatSectionItems returns items with consistent prefix
+	properties.Property("FormatSectionItems returns items with consistent prefix", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true
+			}
+			groups := GroupCommits(commits)
+			items := FormatSectionItems(groups)
+
+			// All items should have the same prefix
+			for _, item := range items {
+				if !strings.HasPrefix(item, "- ") {
+					return false
+				}
+			}
+			return true
+		},
+		genCommitsOfSingleType(CommitTypeFeat),
+	))
+
+	// Property 23.4: FormatSection includes header and items
+	properties.Property("FormatSection includes header and items", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true
+			}
+			groups := GroupCommits(commits)
+			categorized := CategorizeCommits(groups)
+
+			// Check Features section if it has content
+			if len(categorized.Features) > 0 {
+				formatted := FormatSection(SectionFeatures, categorized.Features)
+				// Should contain the header
+				if !strings.Contains(formatted, SectionHeader(SectionFeatures)) {
+					return false
+				}
+				// Should contain bullet points
+				if !strings.Contains(formatted, "- ") {
+					return false
+				}
+			}
+			return true
+		},
+		genCommitsOfSingleType(CommitTypeFeat),
+	))
+
+	// Property 23.5: Empty groups produce empty section
+	properties.Property("empty groups produce empty section", prop.ForAll(
+		func(_ bool) bool {
+			formatted := FormatSection(SectionFeatures, []CommitGroup{})
+			return formatted == ""
+		},
+		gen.Bool(),
+	))
+
+	// Property 23.6: Items don't have extra leading whitespace
+	properties.Property("items don't have extra leading whitespace", prop.ForAll(
+		func(commits []ParsedCommit) bool {
+			if len(commits) == 0 {
+				return true
+			}
+			groups := GroupCommits(commits)
+			items := FormatSectionItems(groups)
+
+			for _, item := range items {
+				// Should start with "- " not " - " or "  - "
+				if strings.HasPrefix(item, " ") {
+					return false
+				}
+			}
+			return true
+		},
+		genMixedCommitList(),
+	))
+
+	properties.TestingRun(t)
+}
@@ -0,0 +1,269 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// AutoscalerConfig controls how autoscaled services are sized.
+type AutoscalerConfig struct {
+	// Interval is how often every autoscaled service is evaluated.
+	Interval time.Duration
+	// Window is how much recent usage an evaluation averages.
+	Window time.Duration
+	// Tolerance is how far usage may stray from a target, as a share of
+	// it, before the replica count changes.
+	Tolerance float64
+	// ScaleUpCooldown and ScaleDownCooldown are how long after its last
+	// change of replica count a service may scale up or down again.
+	ScaleUpCooldown   time.Duration
+	ScaleDownCooldown time.Duration
+	// EventRetention is how long scale events are kept.
+	EventRetention time.Duration
+}
+
+// DefaultAutoscalerConfig returns an AutoscalerConfig with sensible defaults.
+func DefaultAutoscalerConfig() AutoscalerConfig {
+	return AutoscalerConfig{
+		Interval:          time.Minute,
+		Window:            5 * time.Minute,
+		Tolerance:         0.1,
+		ScaleUpCooldown:   3 * time.Minute,
+		ScaleDownCooldown: 10 * time.Minute,
+		EventRetention:    30 * 24 * time.Hour,
+	}
+}
+
+// Autoscaler sets the replica counts of services with autoscaling from
+// their recent CPU, memory and request rate, and drops manual overrides
+// once they expire.
+type Autoscaler struct {
+	store  store.Store
+	config AutoscalerConfig
+	logger *slog.Logger
+	hooks  *hooks.Trigger
+	now    func() time.Time
+}
+
+// NewAutoscaler creates an autoscaler.
+func NewAutoscaler(st store.Store, cfg AutoscalerConfig, logger *slog.Logger) *Autoscaler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Autoscaler{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetHooks sets the trigger told when the autoscaler changes a service's replica count.
+func (a *Autoscaler) SetHooks(t *hooks.Trigger) {
+	a.hooks = t
+}
+
+// Run evaluates autoscaled services every interval until ctx is cancelled.
+func (a *Autoscaler) Run(ctx context.Context) {
+	a.RunOnce(ctx)
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce scales every autoscaled service whose usage is off its targets,
+// saves the apps that changed and forgets expired scale events.
+func (a *Autoscaler) RunOnce(ctx context.Context) {
+	apps, err := a.store.Apps().ListAll(ctx)
+	if err != nil {
+		a.logger.Error("failed to list apps for autoscaling", "error", err)
+		return
+	}
+
+	now := a.now()
+	for _, app := range apps {
+		var events []*models.ScaleEvent
+		changed := false
+		for i := range app.Services {
+			svc := &app.Services[i]
+			if svc.Autoscaling == nil || svc.ScalingSchedule != nil {
+				continue
+			}
+			overrideExpired, event := a.evaluate(ctx, app.ID, svc, now)
+			changed = changed || overrideExpired || event != nil
+			if event != nil {
+				events = append(events, event)
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		app.UpdatedAt = now
+		if err := a.store.Apps().Update(ctx, app); err != nil {
+			a.logger.Error("failed to save autoscaling", "error", err, "app_id", app.ID)
+			continue
+		}
+		for _, event := range events {
+			if err := a.store.ScaleEvents().Create(ctx, event); err != nil {
+				a.logger.Error("failed to record scale event", "error", err, "app_id", app.ID, "service_name", event.ServiceName)
+			}
+			if a.hooks != nil {
+				a.hooks.Scaled(ctx, app.ID, event.ServiceName, event.FromReplicas, event.ToReplicas)
+			}
+		}
+	}
+
+	if deleted, err := a.store.ScaleEvents().DeleteBefore(ctx, now.Add(-a.config.EventRetention)); err != nil {
+		a.logger.Error("failed to delete old scale events", "error", err)
+	} else if deleted > 0 {
+		a.logger.Info("deleted old scale events", "count", deleted)
+	}
+}
+
+// evaluate sizes an autoscaled service in place. It reports whether a
+// manual override expired and returns the scale event if the replica count
+// changed.
+func (a *Autoscaler) evaluate(ctx context.Context, appID string, svc *models.ServiceConfig, now time.Time) (bool, *models.ScaleEvent) {
+	overrideExpired := false
+	if svc.ReplicaOverride != nil {
+		if svc.ReplicaOverride.ActiveAt(now) {
+			return false, nil
+		}
+		a.logger.Info("manual replica override expired, resuming autoscaling",
+			"app_id", appID,
+			"service_name", svc.Name,
+		)
+		svc.ReplicaOverride = nil
+		overrideExpired = true
+	}
+
+	// Only whole minutes are averaged, with the minute before the window
+	// giving each deployment's starting counters
+	to := now.Truncate(models.MetricsResolution)
+	from := to.Add(-a.config.Window)
+	rollups, err := a.store.Metrics().ListByService(ctx, appID, svc.Name, from.Add(-models.MetricsResolution), to)
+	if err != nil {
+		a.logger.Error("failed to list metrics for autoscaling", "error", err, "app_id", appID, "service_name", svc.Name)
+		return overrideExpired, nil
+	}
+	var usage *models.MetricPoint
+	if points := models.DownsampleMetrics(rollups, from, a.config.Window); len(points) > 0 {
+		usage = &points[0]
+	}
+
+	desired, reason := desiredReplicas(svc, usage, a.config.Tolerance)
+	if desired == svc.Replicas {
+		return overrideExpired, nil
+	}
+
+	cooldown := a.config.ScaleUpCooldown
+	if desired < svc.Replicas {
+		cooldown = a.config.ScaleDownCooldown
+	}
+	last, err := a.store.ScaleEvents().List(ctx, appID, svc.Name, 1)
+	if err != nil {
+		a.logger.Error("failed to list scale events", "error", err, "app_id", appID, "service_name", svc.Name)
+		return overrideExpired, nil
+	}
+	if len(last) > 0 && now.Sub(last[0].CreatedAt) < cooldown {
+		return overrideExpired, nil
+	}
+
+	a.logger.Info("autoscaling",
+		"app_id", appID,
+		"service_name", svc.Name,
+		"from", svc.Replicas,
+		"to", desired,
+		"reason", reason,
+	)
+	event := &models.ScaleEvent{
+		AppID:        appID,
+		ServiceName:  svc.Name,
+		FromReplicas: svc.Replicas,
+		ToReplicas:   desired,
+		Source:       models.ScaleEventAutoscaler,
+		Reason:       reason,
+		CreatedAt:    now,
+	}
+	svc.Replicas = desired
+	return overrideExpired, event
+}
+
+// desiredReplicas returns the replica count that brings the service's usage
+// per replica to its targets, within its bounds, and why. Each target asks
+// for enough replicas to serve the total usage at the target; usage within
+// tolerance of a target keeps the current count. Without usage the count is
+// only kept within bounds.
+func desiredReplicas(svc *models.ServiceConfig, usage *models.MetricPoint, tolerance float64) (int, string) {
+	as := svc.Autoscaling
+	current := max(svc.Replicas, 1)
+	desired, reason := 0, ""
+
+	if usage != nil {
+		have := scheduler.GetResourceRequirements(svc.Resources)
+		consider := func(total, perReplicaTarget float64, describe string) {
+			if perReplicaTarget <= 0 {
+				return
+			}
+			want := total / perReplicaTarget
+			n := current
+			if math.Abs(want/float64(current)-1) > tolerance {
+				n = max(int(math.Ceil(want)), 1)
+			}
+			if n > desired {
+				desired = n
+				reason = describe
+			}
+		}
+
+		perReplica := func(total float64) float64 { return total / float64(current) }
+		if as.TargetCPUPercent > 0 {
+			cores := usage.CPUPercent / 100
+			consider(cores, have.CPU*float64(as.TargetCPUPercent)/100,
+				fmt.Sprintf("CPU at %.0f%% per replica, target %d%%", perReplica(cores)/have.CPU*100, as.TargetCPUPercent))
+		}
+		if as.TargetMemoryPercent > 0 && have.Memory > 0 {
+			memory := float64(usage.MemoryBytes)
+			consider(memory, float64(have.Memory)*float64(as.TargetMemoryPercent)/100,
+				fmt.Sprintf("memory at %.0f%% per replica, target %d%%", perReplica(memory)/float64(have.Memory)*100, as.TargetMemoryPercent))
+		}
+		if as.TargetRequestsPerSecond > 0 {
+			consider(usage.RequestRate, as.TargetRequestsPerSecond,
+				fmt.Sprintf("%.1f requests/s per replica, target %g", perReplica(usage.RequestRate), as.TargetRequestsPerSecond))
+		}
+	}
+	if desired == 0 {
+		desired = current
+	}
+
+	switch clamped := as.Clamp(desired); {
+	case clamped > desired:
+		return clamped, fmt.Sprintf("raised to min_replicas %d", as.MinReplicas)
+	case clamped < desired:
+		limited := fmt.Sprintf("limited to max_replicas %d", as.MaxReplicas)
+		if reason != "" {
+			limited += ": " + reason
+		}
+		return clamped, limited
+	default:
+		return desired, reason
+	}
+}
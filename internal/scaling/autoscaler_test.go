@@ -0,0 +1,160 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestDesiredReplicas(t *testing.T) {
+	oneCore := &models.ResourceSpec{CPU: "1", Memory: "1Gi"}
+	tests := []struct {
+		name        string
+		replicas    int
+		autoscaling models.Autoscaling
+		usage       *models.MetricPoint
+		want        int
+	}{
+		{
+			name:        "scales up on CPU",
+			replicas:    2,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetCPUPercent: 60},
+			usage:       &models.MetricPoint{CPUPercent: 180},
+			want:        3,
+		},
+		{
+			name:        "scales down on CPU",
+			replicas:    4,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetCPUPercent: 50},
+			usage:       &models.MetricPoint{CPUPercent: 80},
+			want:        2,
+		},
+		{
+			name:        "keeps the count within tolerance",
+			replicas:    3,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetCPUPercent: 50},
+			usage:       &models.MetricPoint{CPUPercent: 155},
+			want:        3,
+		},
+		{
+			name:        "the target needing most replicas wins",
+			replicas:    2,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetCPUPercent: 50, TargetRequestsPerSecond: 100},
+			usage:       &models.MetricPoint{CPUPercent: 50, RequestRate: 450},
+			want:        5,
+		},
+		{
+			name:        "scales on memory",
+			replicas:    1,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetMemoryPercent: 50},
+			usage:       &models.MetricPoint{MemoryBytes: 1 << 30},
+			want:        2,
+		},
+		{
+			name:        "limited to max replicas",
+			replicas:    4,
+			autoscaling: models.Autoscaling{MinReplicas: 1, MaxReplicas: 5, TargetCPUPercent: 10},
+			usage:       &models.MetricPoint{CPUPercent: 400},
+			want:        5,
+		},
+		{
+			name:        "raised to min replicas without usage",
+			replicas:    1,
+			autoscaling: models.Autoscaling{MinReplicas: 2, MaxReplicas: 5, TargetCPUPercent: 50},
+			want:        2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &models.ServiceConfig{Name: "web", Replicas: tt.replicas, Resources: oneCore, Autoscaling: &tt.autoscaling}
+			got, reason := desiredReplicas(svc, tt.usage, DefaultAutoscalerConfig().Tolerance)
+			if got != tt.want {
+				t.Errorf("desiredReplicas = %d (%s), want %d", got, reason, tt.want)
+			}
+			if got != tt.replicas && reason == "" {
+				t.Error("scaling has no reason")
+			}
+		})
+	}
+}
+
+func TestAutoscalerRunOnce(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 30, 0, time.UTC)
+	busy := func() []*models.MetricRollup {
+		var rollups []*models.MetricRollup
+		for _, d := range []string{"d1", "d2"} {
+			for m := 6; m >= 1; m-- {
+				rollups = append(rollups, &models.MetricRollup{
+					DeploymentID: d, AppID: "app-1", ServiceName: "web",
+					Bucket:  now.Truncate(time.Minute).Add(-time.Duration(m) * time.Minute),
+					Samples: 1, CPUPercentSum: 90,
+				})
+			}
+		}
+		return rollups
+	}
+	until := now.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		override     *models.ReplicaOverride
+		events       []*models.ScaleEvent
+		wantReplicas int
+		wantOverride bool
+	}{
+		{name: "scales up to the target", wantReplicas: 3},
+		{name: "manual override holds", override: &models.ReplicaOverride{Replicas: 2, Until: &until}, wantReplicas: 2, wantOverride: true},
+		{name: "expired override resumes autoscaling", override: &models.ReplicaOverride{Replicas: 2, Until: &now}, wantReplicas: 3},
+		{
+			name:         "waits out the cooldown",
+			events:       []*models.ScaleEvent{{AppID: "app-1", ServiceName: "web", CreatedAt: now.Add(-time.Minute)}},
+			wantReplicas: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &memStore{
+				apps: []*models.App{{
+					ID: "app-1",
+					Services: []models.ServiceConfig{
+						{
+							Name: "web", Replicas: 2, Resources: &models.ResourceSpec{CPU: "1", Memory: "1Gi"},
+							Autoscaling:     &models.Autoscaling{MinReplicas: 1, MaxReplicas: 10, TargetCPUPercent: 60},
+							ReplicaOverride: tt.override,
+						},
+						{Name: "worker", Replicas: 1},
+					},
+				}},
+				rollups: busy(),
+				events:  tt.events,
+			}
+			a := NewAutoscaler(st, DefaultAutoscalerConfig(), nil)
+			a.now = func() time.Time { return now }
+
+			a.RunOnce(context.Background())
+
+			web := st.apps[0].Services[0]
+			if web.Replicas != tt.wantReplicas {
+				t.Errorf("replicas = %d, want %d", web.Replicas, tt.wantReplicas)
+			}
+			if (web.ReplicaOverride != nil) != tt.wantOverride {
+				t.Errorf("override present = %v, want %v", web.ReplicaOverride != nil, tt.wantOverride)
+			}
+			scaled := len(st.events) > len(tt.events)
+			if scaled != (tt.wantReplicas != 2) {
+				t.Errorf("recorded scale event = %v, want %v", scaled, tt.wantReplicas != 2)
+			}
+			if scaled {
+				event := st.events[len(st.events)-1]
+				if event.Source != models.ScaleEventAutoscaler || event.FromReplicas != 2 || event.ToReplicas != 3 {
+					t.Errorf("event = %+v, want autoscaler 2 -> 3", event)
+				}
+			}
+			if worker := st.apps[0].Services[1]; worker.Replicas != 1 {
+				t.Errorf("service without autoscaling replicas = %d, want 1", worker.Replicas)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package scaling sets services' replica counts: the cron applies scaling
+// schedules, setting each scheduled service's replica count to the one its
+// active profile, or a manual override of it, calls for, and the autoscaler
+// sizes autoscaled services to their usage.
+package scaling
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Config controls how often scaling schedules are applied.
+type Config struct {
+	// PollInterval is how often every scheduled service is checked. Profile
+	// changes take effect within one interval of their start or end time.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Minute,
+	}
+}
+
+// Cron keeps the replica counts of services with a scaling schedule in line
+// with the schedule and drops manual overrides once they expire.
+type Cron struct {
+	store  store.Store
+	config Config
+	logger *slog.Logger
+	hooks  *hooks.Trigger
+	now    func() time.Time
+}
+
+// NewCron creates a scaling cron.
+func NewCron(st store.Store, cfg Config, logger *slog.Logger) *Cron {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Cron{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetHooks sets the trigger told when a schedule changes a service's replica count.
+func (c *Cron) SetHooks(t *hooks.Trigger) {
+	c.hooks = t
+}
+
+// Run applies scaling schedules every poll interval until ctx is cancelled.
+func (c *Cron) Run(ctx context.Context) {
+	c.ApplyOnce(ctx)
+
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.ApplyOnce(ctx)
+		}
+	}
+}
+
+// ApplyOnce updates every scheduled service whose replica count differs
+// from its desired count and saves the apps that changed.
+func (c *Cron) ApplyOnce(ctx context.Context) {
+	apps, err := c.store.Apps().ListAll(ctx)
+	if err != nil {
+		c.logger.Error("failed to list apps for scheduled scaling", "error", err)
+		return
+	}
+
+	now := c.now()
+	for _, app := range apps {
+		changed, events := c.apply(app, now)
+		if !changed {
+			continue
+		}
+		app.UpdatedAt = now
+		if err := c.store.Apps().Update(ctx, app); err != nil {
+			c.logger.Error("failed to save scheduled scaling", "error", err, "app_id", app.ID)
+			continue
+		}
+		for _, event := range events {
+			if err := c.store.ScaleEvents().Create(ctx, event); err != nil {
+				c.logger.Error("failed to record scale event", "error", err, "app_id", app.ID, "service_name", event.ServiceName)
+			}
+			if c.hooks != nil {
+				c.hooks.Scaled(ctx, app.ID, event.ServiceName, event.FromReplicas, event.ToReplicas)
+			}
+		}
+	}
+}
+
+// apply updates the app's scheduled services in place. It reports whether
+// any of them changed and returns the events of the replica counts that did.
+func (c *Cron) apply(app *models.App, now time.Time) (bool, []*models.ScaleEvent) {
+	changed := false
+	var events []*models.ScaleEvent
+	for i := range app.Services {
+		svc := &app.Services[i]
+		if svc.ScalingSchedule == nil {
+			continue
+		}
+
+		if svc.ReplicaOverride != nil && !svc.ReplicaOverride.ActiveAt(now) {
+			c.logger.Info("manual replica override expired, resuming scaling schedule",
+				"app_id", app.ID,
+				"service_name", svc.Name,
+			)
+			svc.ReplicaOverride = nil
+			changed = true
+		}
+
+		desired := svc.DesiredReplicas(now)
+		if desired == svc.Replicas {
+			continue
+		}
+
+		profile, reason := "", "manual override"
+		if svc.ReplicaOverride == nil {
+			reason = "default replicas"
+			if _, p := svc.ScalingSchedule.ReplicasAt(now); p != nil {
+				profile = p.Name
+				reason = "profile " + cmp.Or(p.Name, p.StartTime+"-"+p.EndTime)
+			}
+		}
+		c.logger.Info("scheduled scaling",
+			"app_id", app.ID,
+			"service_name", svc.Name,
+			"from", svc.Replicas,
+			"to", desired,
+			"profile", profile,
+		)
+		events = append(events, &models.ScaleEvent{
+			AppID:        app.ID,
+			ServiceName:  svc.Name,
+			FromReplicas: svc.Replicas,
+			ToReplicas:   desired,
+			Source:       models.ScaleEventSchedule,
+			Reason:       reason,
+			CreatedAt:    now,
+		})
+		svc.Replicas = desired
+		changed = true
+	}
+	return changed, events
+}
@@ -0,0 +1,147 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the cron and the
+// autoscaler use.
+type memStore struct {
+	store.Store
+	apps    []*models.App
+	updates int
+	rollups []*models.MetricRollup
+	events  []*models.ScaleEvent
+}
+
+func (s *memStore) Apps() store.AppStore               { return memApps{s: s} }
+func (s *memStore) Metrics() store.MetricStore         { return memMetrics{s: s} }
+func (s *memStore) ScaleEvents() store.ScaleEventStore { return memEvents{s: s} }
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (m memApps) ListAll(ctx context.Context) ([]*models.App, error) {
+	return m.s.apps, nil
+}
+
+func (m memApps) Update(ctx context.Context, app *models.App) error {
+	m.s.updates++
+	return nil
+}
+
+type memMetrics struct {
+	store.MetricStore
+	s *memStore
+}
+
+func (m memMetrics) ListByService(ctx context.Context, appID, serviceName string, from, to time.Time) ([]*models.MetricRollup, error) {
+	var rollups []*models.MetricRollup
+	for _, r := range m.s.rollups {
+		if r.AppID == appID && r.ServiceName == serviceName && !r.Bucket.Before(from) && r.Bucket.Before(to) {
+			rollups = append(rollups, r)
+		}
+	}
+	return rollups, nil
+}
+
+type memEvents struct {
+	store.ScaleEventStore
+	s *memStore
+}
+
+func (m memEvents) Create(ctx context.Context, event *models.ScaleEvent) error {
+	m.s.events = append(m.s.events, event)
+	return nil
+}
+
+func (m memEvents) List(ctx context.Context, appID, serviceName string, limit int) ([]*models.ScaleEvent, error) {
+	var events []*models.ScaleEvent
+	for i := len(m.s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if e := m.s.events[i]; e.AppID == appID && e.ServiceName == serviceName {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (m memEvents) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func businessHours() *models.ScalingSchedule {
+	return &models.ScalingSchedule{
+		DefaultReplicas: 2,
+		Profiles: []models.ScalingProfile{{
+			Name:      "business-hours",
+			Days:      []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+			StartTime: "08:00",
+			EndTime:   "20:00",
+			Replicas:  6,
+		}},
+	}
+}
+
+func TestCronApplyOnce(t *testing.T) {
+	// Monday 2026-01-05
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2026, 1, 5, hour, minute, 0, 0, time.UTC)
+	}
+	until := monday(20, 0)
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		replicas     int
+		override     *models.ReplicaOverride
+		wantReplicas int
+		wantOverride bool
+		wantUpdate   bool
+	}{
+		{"scales up when the profile starts", monday(8, 0), 2, nil, 6, false, true},
+		{"scales down outside the profile", monday(20, 1), 6, nil, 2, false, true},
+		{"leaves a matching count alone", monday(12, 0), 6, nil, 6, false, false},
+		{"manual override holds", monday(12, 0), 3, &models.ReplicaOverride{Replicas: 3, Until: &until}, 3, true, false},
+		{"expired override resumes the schedule", monday(20, 0), 3, &models.ReplicaOverride{Replicas: 3, Until: &until}, 2, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &memStore{apps: []*models.App{{
+				ID: "app-1",
+				Services: []models.ServiceConfig{
+					{Name: "web", Replicas: tt.replicas, ScalingSchedule: businessHours(), ReplicaOverride: tt.override},
+					{Name: "worker", Replicas: 1},
+				},
+			}}}
+			c := NewCron(st, DefaultConfig(), nil)
+			c.now = func() time.Time { return tt.now }
+
+			c.ApplyOnce(context.Background())
+
+			web := st.apps[0].Services[0]
+			if web.Replicas != tt.wantReplicas {
+				t.Errorf("replicas = %d, want %d", web.Replicas, tt.wantReplicas)
+			}
+			if (web.ReplicaOverride != nil) != tt.wantOverride {
+				t.Errorf("override present = %v, want %v", web.ReplicaOverride != nil, tt.wantOverride)
+			}
+			if (st.updates > 0) != tt.wantUpdate {
+				t.Errorf("updates = %d, want update %v", st.updates, tt.wantUpdate)
+			}
+			if scaled := tt.wantReplicas != tt.replicas; (len(st.events) == 1) != scaled {
+				t.Errorf("recorded %d scale events, want scaled %v", len(st.events), scaled)
+			}
+			if worker := st.apps[0].Services[1]; worker.Replicas != 1 {
+				t.Errorf("unscheduled service replicas = %d, want 1", worker.Replicas)
+			}
+		})
+	}
+}
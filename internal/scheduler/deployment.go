@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
@@ -421,9 +422,14 @@ func (d *ZeroDowntimeDeployer) Rollback(ctx context.Context, appID, serviceName
 		return nil, fmt.Errorf("deployment does not belong to app %s service %s", appID, serviceName)
 	}
 
-	// Verify the deployment has an artifact
-	if targetDeployment.Artifact == "" {
-		return nil, ErrNoArtifact
+	// Fall back to the last deployment that ran if the target never did
+	history, err := d.store.Deployments().List(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+	source, err := RollbackSource(targetDeployment, history)
+	if err != nil {
+		return nil, err
 	}
 
 	// 2. Get the next version number
@@ -434,22 +440,7 @@ func (d *ZeroDowntimeDeployer) Rollback(ctx context.Context, appID, serviceName
 
 	// 3. Create a new deployment with the same artifact but new version
 	// **Validates: Requirements 20.3, 20.4**
-	newDeployment := &models.Deployment{
-		ID:          generateDeploymentID(),
-		AppID:       appID,
-		ServiceName: serviceName,
-		Version:     nextVersion,
-		GitRef:      targetDeployment.GitRef,
-		GitCommit:   targetDeployment.GitCommit,
-		BuildType:   targetDeployment.BuildType,
-		Artifact:    targetDeployment.Artifact,    // Use the same artifact
-		Status:      models.DeploymentStatusBuilt, // Skip build phase
-		Resources:   targetDeployment.Resources,
-		Config:      targetDeployment.Config,
-		DependsOn:   targetDeployment.DependsOn,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+	newDeployment := CreateRollbackDeployment(source, nextVersion, generateDeploymentID())
 
 	// 4. Save the new deployment
 	if err := d.store.Deployments().Create(ctx, newDeployment); err != nil {
@@ -460,7 +451,7 @@ func (d *ZeroDowntimeDeployer) Rollback(ctx context.Context, appID, serviceName
 		"new_deployment_id", newDeployment.ID,
 		"new_version", newDeployment.Version,
 		"artifact", newDeployment.Artifact,
-		"rolled_back_from", targetDeploymentID,
+		"rollback_of", newDeployment.RollbackOf,
 	)
 
 	return newDeployment, nil
@@ -500,7 +491,7 @@ func (d *ZeroDowntimeDeployer) RollbackToLatestSuccessful(ctx context.Context, a
 
 // generateDeploymentID generates a unique deployment ID.
 func generateDeploymentID() string {
-	return fmt.Sprintf("dep-%d", time.Now().UnixNano())
+	return uuid.New().String()
 }
 
 // RollbackResult represents the result of a rollback operation.
@@ -511,8 +502,36 @@ type RollbackResult struct {
 	Message          string             `json:"message,omitempty"`
 }
 
+// RollbackSource returns the deployment whose artifact a rollback to target
+// redeploys: target itself if it ran successfully, otherwise the most recent
+// earlier deployment of the same service that did. This lets a failed
+// deployment be rolled back to the last known-good image or store path.
+// history holds the app's deployments in any order.
+func RollbackSource(target *models.Deployment, history []*models.Deployment) (*models.Deployment, error) {
+	if target.Succeeded() {
+		return target, nil
+	}
+
+	var source *models.Deployment
+	for _, dep := range history {
+		if dep.ServiceName != target.ServiceName || dep.Version >= target.Version || !dep.Succeeded() {
+			continue
+		}
+		if source == nil || dep.Version > source.Version {
+			source = dep
+		}
+	}
+	if source == nil {
+		if target.Artifact == "" {
+			return nil, ErrNoArtifact
+		}
+		return nil, ErrNoPreviousDeployment
+	}
+	return source, nil
+}
+
 // CreateRollbackDeployment is a pure function that creates a rollback deployment
-// from a source deployment. This is useful for testing.
+// from a source deployment. The new deployment records the source in RollbackOf.
 // **Validates: Requirements 10.5, 20.3, 20.4**
 func CreateRollbackDeployment(source *models.Deployment, newVersion int, newID string) *models.Deployment {
 	now := time.Now()
@@ -527,8 +546,9 @@ func CreateRollbackDeployment(source *models.Deployment, newVersion int, newID s
 		Artifact:    source.Artifact,
 		Status:      models.DeploymentStatusBuilt,
 		Resources:   source.Resources,
-		Config:      source.Config,
+		Config:      source.Config.WithoutCanary(),
 		DependsOn:   source.DependsOn,
+		RollbackOf:  source.ID,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -563,5 +583,10 @@ func ValidateRollbackDeployment(source, rollback *models.Deployment) bool {
 		return false
 	}
 
+	// The rollback must link back to its source
+	if rollback.RollbackOf != source.ID {
+		return false
+	}
+
 	return true
 }
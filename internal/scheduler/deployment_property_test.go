@@ -286,3 +286,47 @@ func TestValidateRollbackDeploymentRejectsInvalid(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestRollbackSource(t *testing.T) {
+	started := time.Now().Add(-time.Hour)
+	dep := func(id string, version int, status models.DeploymentStatus, artifact string, ran bool) *models.Deployment {
+		d := &models.Deployment{ID: id, AppID: "app", ServiceName: "web", Version: version, Status: status, Artifact: artifact}
+		if ran {
+			d.StartedAt = &started
+		}
+		return d
+	}
+	v1 := dep("v1", 1, models.DeploymentStatusStopped, "img:1", true)
+	v2 := dep("v2", 2, models.DeploymentStatusStopped, "img:2", true)
+	v3 := dep("v3", 3, models.DeploymentStatusRunning, "img:3", true)
+	v4 := dep("v4", 4, models.DeploymentStatusFailed, "img:4", true)
+	v5 := dep("v5", 5, models.DeploymentStatusFailed, "", false)
+	other := dep("other", 9, models.DeploymentStatusRunning, "img:other", true)
+	other.ServiceName = "worker"
+	history := []*models.Deployment{v5, v4, v3, v2, v1, other}
+
+	tests := []struct {
+		name    string
+		target  *models.Deployment
+		want    string
+		wantErr error
+	}{
+		{"running target redeploys itself", v3, "v3", nil},
+		{"stopped target that ran redeploys itself", v2, "v2", nil},
+		{"failed target falls back to last success", v4, "v3", nil},
+		{"unbuilt target falls back to last success", v5, "v3", nil},
+		{"no earlier success", dep("v0", 0, models.DeploymentStatusFailed, "img:0", false), "", ErrNoPreviousDeployment},
+		{"no artifact and no earlier success", dep("v0", 0, models.DeploymentStatusFailed, "", false), "", ErrNoArtifact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RollbackSource(tt.target, history)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ID != tt.want {
+				t.Errorf("source = %s, want %s", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Drain moves the active deployments of a node to other nodes, stopping each
+// one on the node once it has been placed elsewhere. Deployments no other
+// node can take keep running where they are; the number of them is returned
+// so draining can be retried.
+func (s *Scheduler) Drain(ctx context.Context, nodeID string) (int, error) {
+	deployments, err := s.store.Deployments().ListByNode(ctx, nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("listing deployments for node: %w", err)
+	}
+
+	var moved, remaining int
+	for _, deployment := range deployments {
+		if deployment.Status != models.DeploymentStatusRunning &&
+			deployment.Status != models.DeploymentStatusStarting &&
+			deployment.Status != models.DeploymentStatusScheduled {
+			continue
+		}
+
+		// Only move deployments that have somewhere to go
+		if _, err := s.Schedule(ctx, deployment); err != nil {
+			remaining++
+			continue
+		}
+
+		deployment.NodeID = ""
+		deployment.Status = models.DeploymentStatusBuilt
+		if err := s.ScheduleAndAssign(ctx, deployment); err != nil || deployment.NodeID == "" {
+			s.logger.Error("failed to move deployment off draining node",
+				"node_id", nodeID,
+				"deployment_id", deployment.ID,
+				"error", err,
+			)
+			remaining++
+			continue
+		}
+		moved++
+
+		if s.agentClient != nil {
+			if err := s.agentClient.Stop(ctx, nodeID, deployment.ID); err != nil {
+				s.logger.Warn("failed to stop deployment on draining node",
+					"node_id", nodeID,
+					"deployment_id", deployment.ID,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	if moved > 0 || remaining > 0 {
+		s.logger.Info("draining node",
+			"node_id", nodeID,
+			"moved", moved,
+			"remaining", remaining,
+		)
+	}
+	return remaining, nil
+}
+
+// DrainNodes drains every node in NodeStatusDraining and cordons the ones
+// left without active deployments.
+func (s *Scheduler) DrainNodes(ctx context.Context) error {
+	nodes, err := s.store.Nodes().List(ctx)
+	if err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+
+	for _, node := range nodes {
+		if node.Status != models.NodeStatusDraining {
+			continue
+		}
+		remaining, err := s.Drain(ctx, node.ID)
+		if err != nil {
+			s.logger.Error("failed to drain node", "node_id", node.ID, "error", err)
+			continue
+		}
+		if remaining > 0 {
+			continue
+		}
+		if err := s.store.Nodes().UpdateStatus(ctx, node.ID, models.NodeStatusCordoned); err != nil {
+			s.logger.Error("failed to cordon drained node", "node_id", node.ID, "error", err)
+			continue
+		}
+		s.logger.Info("node drained", "node_id", node.ID)
+	}
+	return nil
+}
@@ -141,6 +141,65 @@ func (c *GRPCAgentClient) Stop(ctx context.Context, nodeID string, deploymentID
 	return fmt.Errorf("stop command failed after %d attempts: %w", c.maxRetries+1, lastErr)
 }
 
+// StartDebug sends a command to start a debug session's container on a node.
+func (c *GRPCAgentClient) StartDebug(ctx context.Context, nodeID string, session *models.DebugSession) error {
+	if session.DeploymentID == "" {
+		return fmt.Errorf("deployment_id is required")
+	}
+
+	cmd := &pb.DeploymentCommand{
+		CommandId: uuid.New().String(),
+		Type:      pb.CommandType_COMMAND_DEBUG,
+		Deadline:  timestamppb.New(time.Now().Add(c.commandTimeout)),
+		Command: &pb.DeploymentCommand_Debug{
+			Debug: &pb.CPDebugRequest{
+				SessionId:    session.ID,
+				DeploymentId: session.DeploymentID,
+				Image:        session.Image,
+				Command:      session.Command,
+				ExpiresAt:    timestamppb.New(session.ExpiresAt),
+			},
+		},
+	}
+	return c.sendWithRetry(ctx, nodeID, cmd, "debug")
+}
+
+// StopDebug sends a command to remove a debug session's container from a node.
+func (c *GRPCAgentClient) StopDebug(ctx context.Context, nodeID string, sessionID string) error {
+	cmd := &pb.DeploymentCommand{
+		CommandId: uuid.New().String(),
+		Type:      pb.CommandType_COMMAND_STOP_DEBUG,
+		Deadline:  timestamppb.New(time.Now().Add(c.commandTimeout)),
+		Command: &pb.DeploymentCommand_StopDebug{
+			StopDebug: &pb.CPStopDebugRequest{SessionId: sessionID},
+		},
+	}
+	return c.sendWithRetry(ctx, nodeID, cmd, "stop debug")
+}
+
+// sendWithRetry sends a command, retrying on transient errors.
+func (c *GRPCAgentClient) sendWithRetry(ctx context.Context, nodeID string, cmd *pb.DeploymentCommand, name string) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		err := c.commandSender.SendCommand(ctx, nodeID, cmd)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+
+		if !isRetryableError(err) {
+			return fmt.Errorf("%s command failed: %w", name, err)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return fmt.Errorf("%s command failed after %d attempts: %w", name, c.maxRetries+1, lastErr)
+}
+
 // buildDeployCommand creates a DeploymentCommand from a Deployment model.
 // Requirements: 3.2, 3.4
 func (c *GRPCAgentClient) buildDeployCommand(deployment *models.Deployment) *pb.DeploymentCommand {
@@ -175,11 +234,23 @@ func (c *GRPCAgentClient) buildDeployCommand(deployment *models.Deployment) *pb.
 				Port:            int32(deployment.Config.HealthCheck.Port),
 				IntervalSeconds: int32(deployment.Config.HealthCheck.IntervalSeconds),
 				TimeoutSeconds:  int32(deployment.Config.HealthCheck.TimeoutSeconds),
+				Command:         deployment.Config.HealthCheck.Command,
+				// One passing probe makes the deployment ready; the retry
+				// budget of failed probes in a row fails it
+				HealthyThreshold:   1,
+				UnhealthyThreshold: int32(deployment.Config.HealthCheck.MaxFailures()),
 			}
 		}
 		if len(deployment.Config.Ports) > 0 {
 			config.Port = int32(deployment.Config.Ports[0].ContainerPort)
 		}
+		config.Egress = egressPolicyToProto(deployment.Config.Egress)
+		config.Command = deployment.Config.Command
+		config.CanaryBaselineId = deployment.Config.CanaryBaselineID
+		config.CanaryWeight = int32(deployment.Config.CanaryWeight)
+	}
+	if deployment.BuildType == models.BuildTypePureNix {
+		config.Runtime = string(deployment.Runtime())
 	}
 
 	return &pb.DeploymentCommand{
@@ -199,6 +270,28 @@ func (c *GRPCAgentClient) buildDeployCommand(deployment *models.Deployment) *pb.
 	}
 }
 
+// egressPolicyToProto converts an egress policy for the agent. Policies that
+// don't restrict anything are omitted so the agent leaves egress open.
+func egressPolicyToProto(policy *models.EgressPolicy) *pb.CPEgressPolicy {
+	if !policy.Restricts() {
+		return nil
+	}
+
+	pbPolicy := &pb.CPEgressPolicy{DenyAll: true}
+	for _, rule := range policy.Allow {
+		pbRule := &pb.CPEgressRule{
+			Host:     rule.Host,
+			Cidr:     rule.CIDR,
+			Protocol: rule.Protocol,
+		}
+		for _, port := range rule.Ports {
+			pbRule.Ports = append(pbRule.Ports, int32(port))
+		}
+		pbPolicy.Allow = append(pbPolicy.Allow, pbRule)
+	}
+	return pbPolicy
+}
+
 // isRetryableError checks if an error should trigger a retry.
 // Requirements: 9.1, 9.2
 func isRetryableError(err error) bool {
@@ -204,6 +204,62 @@ func TestGRPCAgentClientBuildTypeMapping(t *testing.T) {
 	}
 }
 
+// TestBuildDeployCommandEgressPolicy tests that egress policies reach the agent
+// only when they restrict traffic.
+func TestBuildDeployCommandEgressPolicy(t *testing.T) {
+	deployment := &models.Deployment{
+		ID:        "test-id",
+		BuildType: models.BuildTypeOCI,
+		Config: &models.RuntimeConfig{
+			Egress: &models.EgressPolicy{
+				DenyAll: true,
+				Allow: []models.EgressRule{
+					{Host: "api.stripe.com", Ports: []int{443}, Protocol: "tcp"},
+					{CIDR: "10.0.0.0/8"},
+				},
+			},
+		},
+	}
+
+	egress := BuildDeployCommand(deployment).GetDeploy().GetConfig().GetEgress()
+	if egress == nil || !egress.DenyAll {
+		t.Fatalf("expected a deny-all policy, got %v", egress)
+	}
+	if len(egress.Allow) != 2 {
+		t.Fatalf("expected 2 allow rules, got %d", len(egress.Allow))
+	}
+	if rule := egress.Allow[0]; rule.Host != "api.stripe.com" || len(rule.Ports) != 1 || rule.Ports[0] != 443 || rule.Protocol != "tcp" {
+		t.Errorf("unexpected host rule: %v", rule)
+	}
+	if rule := egress.Allow[1]; rule.Cidr != "10.0.0.0/8" {
+		t.Errorf("unexpected cidr rule: %v", rule)
+	}
+
+	// A policy that doesn't deny anything leaves egress open
+	deployment.Config.Egress = &models.EgressPolicy{Allow: []models.EgressRule{{CIDR: "10.0.0.0/8"}}}
+	if egress := BuildDeployCommand(deployment).GetDeploy().GetConfig().GetEgress(); egress != nil {
+		t.Errorf("expected no policy, got %v", egress)
+	}
+}
+
+// TestBuildDeployCommandRuntime tests that only pure-nix deployments carry
+// the runtime driver their service selected.
+func TestBuildDeployCommandRuntime(t *testing.T) {
+	deployment := &models.Deployment{
+		ID:        "test-id",
+		BuildType: models.BuildTypePureNix,
+		Config:    &models.RuntimeConfig{Runtime: models.ServiceRuntimeSystemd},
+	}
+	if got := BuildDeployCommand(deployment).GetDeploy().GetConfig().GetRuntime(); got != "systemd" {
+		t.Errorf("pure-nix runtime = %q, want systemd", got)
+	}
+
+	deployment.BuildType = models.BuildTypeOCI
+	if got := BuildDeployCommand(deployment).GetDeploy().GetConfig().GetRuntime(); got != "" {
+		t.Errorf("OCI runtime = %q, want none", got)
+	}
+}
+
 // TestGRPCAgentClientDeploySuccess tests that successful deployments work correctly.
 func TestGRPCAgentClientDeploySuccess(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
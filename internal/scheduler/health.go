@@ -3,10 +3,12 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"sync"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/events"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
 )
@@ -20,8 +22,14 @@ type HealthMonitor struct {
 	healthThreshold   time.Duration
 	checkInterval     time.Duration
 	deploymentTimeout time.Duration // Timeout for deployments waiting to be scheduled
+	schedulePending   bool          // Whether checks also place pending deployments
+	events            events.Publisher
 	logger            *slog.Logger
 
+	// lapsed holds the stale nodes already handled, so each lapse is recorded
+	// and rescheduled once even if the node was marked unhealthy elsewhere first
+	lapsed map[string]bool
+
 	mu       sync.Mutex
 	running  bool
 	stopChan chan struct{}
@@ -44,11 +52,24 @@ func NewHealthMonitorWithTimeout(s store.Store, scheduler *Scheduler, healthThre
 		healthThreshold:   healthThreshold,
 		checkInterval:     checkInterval,
 		deploymentTimeout: deploymentTimeout,
+		schedulePending:   true,
 		logger:            logger,
+		lapsed:            make(map[string]bool),
 		stopChan:          make(chan struct{}),
 	}
 }
 
+// SetSchedulePending sets whether health checks also place deployments
+// waiting for a node. Disable it when another loop schedules them.
+func (h *HealthMonitor) SetSchedulePending(enabled bool) {
+	h.schedulePending = enabled
+}
+
+// SetEvents sets where nodes whose heartbeats lapsed are published.
+func (h *HealthMonitor) SetEvents(p events.Publisher) {
+	h.events = p
+}
+
 // Start begins the periodic health check loop.
 func (h *HealthMonitor) Start(ctx context.Context) error {
 	h.mu.Lock()
@@ -112,24 +133,34 @@ func (h *HealthMonitor) checkNodes(ctx context.Context) error {
 	for _, node := range nodes {
 		isStale := node.LastHeartbeat.Before(threshold)
 
-		if node.Healthy && isStale {
-			// Node was healthy but heartbeat is stale - mark as unhealthy
-			h.logger.Warn("marking node as unhealthy due to stale heartbeat",
-				"node_id", node.ID,
-				"last_heartbeat", node.LastHeartbeat,
-				"threshold", threshold,
-			)
-
-			if err := h.store.Nodes().UpdateHealth(ctx, node.ID, false); err != nil {
-				h.logger.Error("failed to update node health",
+		if isStale {
+			if node.Healthy {
+				// Node was healthy but heartbeat is stale - mark as unhealthy
+				h.logger.Warn("marking node as unhealthy due to stale heartbeat",
 					"node_id", node.ID,
-					"error", err,
+					"last_heartbeat", node.LastHeartbeat,
+					"threshold", threshold,
 				)
-				continue
+
+				if err := h.store.Nodes().UpdateHealth(ctx, node.ID, false); err != nil {
+					h.logger.Error("failed to update node health",
+						"node_id", node.ID,
+						"error", err,
+					)
+					continue
+				}
 			}
 
-			unhealthyNodes = append(unhealthyNodes, node.ID)
-		} else if node.Healthy && !isStale {
+			if !h.lapsed[node.ID] {
+				h.lapsed[node.ID] = true
+				h.recordLapse(ctx, node)
+				unhealthyNodes = append(unhealthyNodes, node.ID)
+			}
+			continue
+		}
+
+		delete(h.lapsed, node.ID)
+		if node.Healthy {
 			healthyNodeCount++
 			// Track available resources for resource constraint retry
 			// **Validates: Requirements 16.4**
@@ -152,9 +183,15 @@ func (h *HealthMonitor) checkNodes(ctx context.Context) error {
 		}
 	}
 
+	if err := h.checkStartingDeployments(ctx); err != nil {
+		h.logger.Error("failed to check starting deployments",
+			"error", err,
+		)
+	}
+
 	// Process pending deployments when healthy nodes are available or resources have changed
 	// **Validates: Requirements 16.2, 16.4**
-	if healthyNodeCount > 0 && h.scheduler != nil {
+	if healthyNodeCount > 0 && h.scheduler != nil && h.schedulePending {
 		if err := h.processPendingDeployments(ctx); err != nil {
 			h.logger.Error("failed to process pending deployments",
 				"error", err,
@@ -165,6 +202,78 @@ func (h *HealthMonitor) checkNodes(ctx context.Context) error {
 	return nil
 }
 
+// recordLapse records a node becoming unhealthy because its heartbeats
+// stopped, unless its latest health event already says so.
+func (h *HealthMonitor) recordLapse(ctx context.Context, node *models.Node) {
+	latest, err := h.store.Nodes().ListHealthEvents(ctx, node.ID, 1)
+	if err == nil && len(latest) > 0 && !latest[0].Healthy {
+		return
+	}
+	event := &models.NodeHealthEvent{
+		NodeID:      node.ID,
+		HealthScore: node.HealthScore,
+		Reason:      fmt.Sprintf("no heartbeat for %s", time.Since(node.LastHeartbeat).Round(time.Second)),
+	}
+	if err := h.store.Nodes().CreateHealthEvent(ctx, event); err != nil {
+		h.logger.Error("failed to record node health event",
+			"node_id", node.ID,
+			"error", err,
+		)
+		return
+	}
+	if h.events != nil {
+		h.events.Publish(&events.Transition{Kind: events.TransitionNodeHealth, Node: node, HealthEvent: event})
+	}
+}
+
+// readinessGrace is how long past its readiness budget a starting
+// deployment is given for its node's final report to arrive.
+const readinessGrace = 30 * time.Second
+
+// checkStartingDeployments fails deployments whose health checks have not
+// passed within their readiness budget. Agents fail deployments themselves
+// once the retry budget is exhausted; this catches those whose agent stopped
+// reporting.
+func (h *HealthMonitor) checkStartingDeployments(ctx context.Context) error {
+	deployments, err := h.store.Deployments().ListByStatus(ctx, models.DeploymentStatusStarting)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, deployment := range deployments {
+		check := deployment.HealthCheck()
+		if check == nil {
+			continue
+		}
+		health := deployment.Health
+		if health == nil {
+			health = &models.DeploymentHealth{StartedAt: deployment.UpdatedAt}
+		}
+		if now.Sub(health.StartedAt) <= check.ReadinessBudget()+readinessGrace {
+			continue
+		}
+
+		h.logger.Warn("deployment did not become ready",
+			"deployment_id", deployment.ID,
+			"probing_since", health.StartedAt,
+			"failures", health.Failures,
+		)
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Error = health.Diagnostics(check)
+		deployment.UpdatedAt = now
+		finishedAt := now
+		deployment.FinishedAt = &finishedAt
+		if err := h.store.Deployments().Update(ctx, deployment); err != nil {
+			h.logger.Error("failed to mark deployment as not ready",
+				"deployment_id", deployment.ID,
+				"error", err,
+			)
+		}
+	}
+	return nil
+}
+
 // ResourceAvailability tracks the total available resources across all healthy nodes.
 // **Validates: Requirements 16.4**
 type ResourceAvailability struct {
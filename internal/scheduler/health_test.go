@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// healthStore is an in-memory store providing only the node and deployment
+// operations the health monitor uses.
+type healthStore struct {
+	store.Store
+	nodes       []*models.Node
+	events      []*models.NodeHealthEvent
+	deployments []*models.Deployment
+}
+
+func (s *healthStore) Nodes() store.NodeStore             { return healthNodes{s: s} }
+func (s *healthStore) Deployments() store.DeploymentStore { return healthDeployments{s: s} }
+
+type healthDeployments struct {
+	store.DeploymentStore
+	s *healthStore
+}
+
+func (m healthDeployments) ListByStatus(ctx context.Context, status models.DeploymentStatus) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.Status == status {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m healthDeployments) Update(ctx context.Context, deployment *models.Deployment) error {
+	return nil
+}
+
+type healthNodes struct {
+	store.NodeStore
+	s *healthStore
+}
+
+func (m healthNodes) List(ctx context.Context) ([]*models.Node, error) {
+	return m.s.nodes, nil
+}
+
+func (m healthNodes) UpdateHealth(ctx context.Context, id string, healthy bool) error {
+	for _, n := range m.s.nodes {
+		if n.ID == id {
+			n.Healthy = healthy
+		}
+	}
+	return nil
+}
+
+func (m healthNodes) CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error {
+	m.s.events = append(m.s.events, event)
+	return nil
+}
+
+func (m healthNodes) ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error) {
+	var events []*models.NodeHealthEvent
+	for i := len(m.s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if m.s.events[i].NodeID == nodeID {
+			events = append(events, m.s.events[i])
+		}
+	}
+	return events, nil
+}
+
+func TestHealthMonitorRecordsLapsesOnce(t *testing.T) {
+	ctx := context.Background()
+	stale := time.Now().Add(-time.Minute)
+	st := &healthStore{nodes: []*models.Node{
+		{ID: "lapsed", Healthy: true, HealthScore: 80, LastHeartbeat: stale},
+		// Marked unhealthy by the gRPC node manager before the monitor ran
+		{ID: "degraded", Healthy: false, LastHeartbeat: stale},
+		{ID: "fresh", Healthy: true, LastHeartbeat: time.Now()},
+	}}
+	h := NewHealthMonitor(st, nil, 30*time.Second, time.Second, nil)
+
+	if err := h.checkNodes(ctx); err != nil {
+		t.Fatalf("checkNodes() = %v", err)
+	}
+	if st.nodes[0].Healthy || !st.nodes[2].Healthy {
+		t.Errorf("health = %v, %v, want the stale node unhealthy", st.nodes[0].Healthy, st.nodes[2].Healthy)
+	}
+	if len(st.events) != 2 || st.events[0].NodeID != "lapsed" || st.events[0].Healthy || st.events[0].HealthScore != 80 || st.events[1].NodeID != "degraded" {
+		t.Fatalf("events = %+v, want a lapse for both stale nodes", st.events)
+	}
+	if !h.lapsed["lapsed"] || !h.lapsed["degraded"] || h.lapsed["fresh"] {
+		t.Errorf("lapsed = %v", h.lapsed)
+	}
+
+	if err := h.checkNodes(ctx); err != nil {
+		t.Fatalf("checkNodes() = %v", err)
+	}
+	if len(st.events) != 2 {
+		t.Errorf("a lapse was recorded twice: %+v", st.events)
+	}
+
+	// A node whose heartbeats resume may lapse again
+	st.nodes[0].Healthy = true
+	st.nodes[0].LastHeartbeat = time.Now()
+	if err := h.checkNodes(ctx); err != nil {
+		t.Fatalf("checkNodes() = %v", err)
+	}
+	if h.lapsed["lapsed"] {
+		t.Error("recovered node is still lapsed")
+	}
+}
+
+func TestHealthMonitorFailsDeploymentsThatDoNotBecomeReady(t *testing.T) {
+	check := &models.HealthCheckConfig{Path: "/health", Port: 8080, IntervalSeconds: 5, TimeoutSeconds: 2, Retries: 3}
+	probing := func(since time.Duration) *models.DeploymentHealth {
+		return &models.DeploymentHealth{StartedAt: time.Now().Add(-since), Failures: 2, Output: "HTTP 503"}
+	}
+	st := &healthStore{deployments: []*models.Deployment{
+		// Past its 21s budget and the grace period
+		{ID: "stuck", Status: models.DeploymentStatusStarting, Config: &models.RuntimeConfig{HealthCheck: check}, Health: probing(time.Minute)},
+		{ID: "probing", Status: models.DeploymentStatusStarting, Config: &models.RuntimeConfig{HealthCheck: check}, Health: probing(10 * time.Second)},
+		// Without a health check a deployment is not gated
+		{ID: "unchecked", Status: models.DeploymentStatusStarting, Config: &models.RuntimeConfig{}, UpdatedAt: time.Now().Add(-time.Hour)},
+	}}
+	h := NewHealthMonitor(st, nil, 30*time.Second, time.Second, nil)
+
+	if err := h.checkNodes(context.Background()); err != nil {
+		t.Fatalf("checkNodes() = %v", err)
+	}
+	stuck := st.deployments[0]
+	if stuck.Status != models.DeploymentStatusFailed || stuck.FinishedAt == nil {
+		t.Errorf("stuck deployment status = %s, want failed", stuck.Status)
+	}
+	if want := "2 consecutive health checks failed (GET :8080/health): HTTP 503"; stuck.Error != want {
+		t.Errorf("Error = %q, want %q", stuck.Error, want)
+	}
+	for _, d := range st.deployments[1:] {
+		if d.Status != models.DeploymentStatusStarting {
+			t.Errorf("%s status = %s, want starting", d.ID, d.Status)
+		}
+	}
+}
@@ -84,6 +84,18 @@ func parseMemoryToBytes(mem string) int64 {
 	return 512 << 20 // default 512MB
 }
 
+// FilterByPool returns the nodes of a pool that have every required
+// capability. The empty pool is the default pool of agent nodes.
+func FilterByPool(nodes []*models.Node, pool string, required []models.NodeCapability) []*models.Node {
+	var matched []*models.Node
+	for _, node := range nodes {
+		if node.Pool == pool && node.Supports(required...) {
+			matched = append(matched, node)
+		}
+	}
+	return matched
+}
+
 // filterByCapacity returns nodes that have sufficient resources for the given spec.
 func (s *Scheduler) filterByCapacity(nodes []*models.Node, spec *models.ResourceSpec) []*models.Node {
 	requirements := GetResourceRequirements(spec)
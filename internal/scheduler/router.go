@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// NodeBackend runs the deployments of a node in place of a node agent, such
+// as a Kubernetes cluster or the Podman service of the control plane's host.
+type NodeBackend interface {
+	NodeID() string
+	Deploy(ctx context.Context, deployment *models.Deployment) error
+	Stop(ctx context.Context, deploymentID string) error
+}
+
+// Debugger starts and removes the debug containers of debug sessions on
+// nodes.
+type Debugger interface {
+	StartDebug(ctx context.Context, nodeID string, session *models.DebugSession) error
+	StopDebug(ctx context.Context, nodeID string, sessionID string) error
+}
+
+// DebugBackend is implemented by node backends that can run debug sessions.
+type DebugBackend interface {
+	StartDebug(ctx context.Context, session *models.DebugSession) error
+	StopDebug(ctx context.Context, sessionID string) error
+}
+
+// ErrDebugUnsupported is returned for debug sessions on nodes whose backend
+// cannot run them.
+var ErrDebugUnsupported = errors.New("debug sessions are not supported on this node")
+
+// Router sends the deployment commands of nodes run by a backend to that
+// backend and those of every other node to its node agent.
+type Router struct {
+	agents   AgentClient
+	backends map[string]NodeBackend
+}
+
+// NewRouter creates a router in front of the node agent client.
+func NewRouter(agents AgentClient, backends ...NodeBackend) *Router {
+	r := &Router{agents: agents, backends: make(map[string]NodeBackend)}
+	for _, b := range backends {
+		r.backends[b.NodeID()] = b
+	}
+	return r
+}
+
+// Deploy starts a deployment on a node.
+func (r *Router) Deploy(ctx context.Context, nodeID string, deployment *models.Deployment) error {
+	if b, ok := r.backends[nodeID]; ok {
+		return b.Deploy(ctx, deployment)
+	}
+	return r.agents.Deploy(ctx, nodeID, deployment)
+}
+
+// Stop stops a deployment on a node.
+func (r *Router) Stop(ctx context.Context, nodeID string, deploymentID string) error {
+	if b, ok := r.backends[nodeID]; ok {
+		return b.Stop(ctx, deploymentID)
+	}
+	return r.agents.Stop(ctx, nodeID, deploymentID)
+}
+
+// StartDebug starts a debug session's container on a node.
+func (r *Router) StartDebug(ctx context.Context, nodeID string, session *models.DebugSession) error {
+	if b, ok := r.backends[nodeID]; ok {
+		if d, ok := b.(DebugBackend); ok {
+			return d.StartDebug(ctx, session)
+		}
+		return ErrDebugUnsupported
+	}
+	if d, ok := r.agents.(Debugger); ok {
+		return d.StartDebug(ctx, nodeID, session)
+	}
+	return ErrDebugUnsupported
+}
+
+// StopDebug removes a debug session's container from a node.
+func (r *Router) StopDebug(ctx context.Context, nodeID string, sessionID string) error {
+	if b, ok := r.backends[nodeID]; ok {
+		if d, ok := b.(DebugBackend); ok {
+			return d.StopDebug(ctx, sessionID)
+		}
+		return ErrDebugUnsupported
+	}
+	if d, ok := r.agents.(Debugger); ok {
+		return d.StopDebug(ctx, nodeID, sessionID)
+	}
+	return ErrDebugUnsupported
+}
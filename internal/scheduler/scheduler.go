@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/admission"
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
 	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
 	"github.com/narvanalabs/control-plane/pkg/config"
@@ -20,6 +22,8 @@ var (
 	ErrDependenciesNotRunning = errors.New("service dependencies are not running")
 	ErrDeploymentQueued       = errors.New("deployment queued waiting for available nodes")
 	ErrDeploymentTimeout      = errors.New("deployment timed out waiting for scheduling")
+	ErrAdmissionDenied        = errors.New("deployment rejected by admission policies")
+	ErrNoCapableNodes         = errors.New("no nodes in the service's pool support the deployment")
 )
 
 // AgentClient defines the interface for communicating with node agents.
@@ -39,6 +43,7 @@ type Scheduler struct {
 	store           store.Store
 	agentClient     AgentClient
 	envMerger       EnvMergerInterface
+	admission       *admission.Controller
 	healthThreshold time.Duration
 	maxRetries      int
 	retryBackoff    time.Duration
@@ -67,6 +72,12 @@ func (s *Scheduler) SetEnvMerger(envMerger EnvMergerInterface) {
 	s.envMerger = envMerger
 }
 
+// SetAdmission sets the controller that evaluates deploy-stage admission
+// policies once a deployment has been placed on a node.
+func (s *Scheduler) SetAdmission(controller *admission.Controller) {
+	s.admission = controller
+}
+
 // Schedule assigns a deployment to an appropriate node.
 // It filters nodes by health, resources, and cache locality, then selects the best candidate.
 func (s *Scheduler) Schedule(ctx context.Context, deployment *models.Deployment) (*models.Node, error) {
@@ -93,8 +104,22 @@ func (s *Scheduler) Schedule(ctx context.Context, deployment *models.Deployment)
 		return nil, ErrNoHealthyNodes
 	}
 
-	// 3. Filter by resource capacity
-	capableNodes := s.filterByCapacity(healthyNodes, deployment.Resources)
+	// 3. Filter by the service's node pool and the capabilities it needs
+	pool, err := s.nodePool(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	poolNodes := FilterByPool(healthyNodes, pool, deployment.RequiredCapabilities())
+	if len(poolNodes) == 0 {
+		s.logger.Warn("no nodes in pool support deployment",
+			"pool", pool,
+			"healthy_nodes", len(healthyNodes),
+		)
+		return nil, ErrNoCapableNodes
+	}
+
+	// 4. Filter by resource capacity
+	capableNodes := s.filterByCapacity(poolNodes, deployment.Resources)
 	if len(capableNodes) == 0 {
 		s.logger.Warn("no nodes with sufficient resources",
 			"healthy_nodes", len(healthyNodes),
@@ -102,7 +127,7 @@ func (s *Scheduler) Schedule(ctx context.Context, deployment *models.Deployment)
 		return nil, ErrInsufficientResources
 	}
 
-	// 4. For pure Nix: prefer nodes with cached closure
+	// 5. For pure Nix: prefer nodes with cached closure
 	var selectedNode *models.Node
 	if deployment.BuildType == models.BuildTypePureNix && deployment.Artifact != "" {
 		selectedNode = s.findNodeWithClosure(capableNodes, deployment.Artifact)
@@ -114,7 +139,7 @@ func (s *Scheduler) Schedule(ctx context.Context, deployment *models.Deployment)
 		}
 	}
 
-	// 5. Fallback: select node with most available capacity
+	// 6. Fallback: select node with most available capacity
 	if selectedNode == nil {
 		selectedNode = s.selectByCapacity(capableNodes)
 		s.logger.Info("selected node by capacity",
@@ -125,13 +150,131 @@ func (s *Scheduler) Schedule(ctx context.Context, deployment *models.Deployment)
 	return selectedNode, nil
 }
 
-// filterHealthy returns nodes that have sent a heartbeat within the health threshold.
+// nodePool returns the node pool of the deployment's service.
+func (s *Scheduler) nodePool(ctx context.Context, deployment *models.Deployment) (string, error) {
+	service, err := s.service(ctx, deployment)
+	if err != nil || service == nil {
+		return "", err
+	}
+	return service.NodePool, nil
+}
+
+// service returns the configuration of the deployment's service, or nil if
+// the app no longer has it.
+func (s *Scheduler) service(ctx context.Context, deployment *models.Deployment) (*models.ServiceConfig, error) {
+	app, err := s.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("getting app: %w", err)
+	}
+	if app == nil {
+		return nil, nil
+	}
+	for i := range app.Services {
+		if app.Services[i].Name == deployment.ServiceName {
+			return &app.Services[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// applyRuntime copies the runtime driver the service selects onto a pure-nix
+// deployment, so placement only considers nodes that can run it.
+func (s *Scheduler) applyRuntime(ctx context.Context, deployment *models.Deployment) error {
+	if deployment.BuildType != models.BuildTypePureNix {
+		return nil
+	}
+	service, err := s.service(ctx, deployment)
+	if err != nil || service == nil {
+		return err
+	}
+	if deployment.Config == nil {
+		if service.Runtime == "" {
+			return nil
+		}
+		deployment.Config = &models.RuntimeConfig{}
+	}
+	deployment.Config.Runtime = service.Runtime
+	return nil
+}
+
+// applyCanary makes a new deployment of a service with canary config a
+// canary of the service's running deployment: the agent keeps the baseline
+// serving beside it, and a pending analysis is recorded for the canary
+// analyzer. First deployments, rollbacks and cron runs are not canaries.
+func (s *Scheduler) applyCanary(ctx context.Context, deployment *models.Deployment) error {
+	if deployment.Canary != nil || deployment.RollbackOf != "" || deployment.IsCronRun() {
+		return nil
+	}
+	service, err := s.service(ctx, deployment)
+	if err != nil || service == nil || service.Canary == nil {
+		return err
+	}
+
+	deployments, err := s.store.Deployments().List(ctx, deployment.AppID)
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+	var baseline *models.Deployment
+	for _, d := range deployments {
+		if d.ID == deployment.ID || d.ServiceName != deployment.ServiceName ||
+			d.Status != models.DeploymentStatusRunning || d.IsCronRun() {
+			continue
+		}
+		if baseline == nil || d.Version > baseline.Version {
+			baseline = d
+		}
+	}
+	if baseline == nil {
+		return nil
+	}
+
+	config := service.Canary.WithDefaults()
+	if deployment.Config == nil {
+		deployment.Config = &models.RuntimeConfig{}
+	}
+	deployment.Config.CanaryBaselineID = baseline.ID
+	deployment.Config.CanaryWeight = config.Weight
+	deployment.Canary = &models.CanaryAnalysis{
+		Status:     models.CanaryPending,
+		BaselineID: baseline.ID,
+		Config:     config,
+	}
+	s.logger.Info("deploying canary",
+		"deployment_id", deployment.ID,
+		"baseline_id", baseline.ID,
+		"weight", config.Weight,
+	)
+	return nil
+}
+
+// applyDatabaseEnv passes a database service's generated credentials to its
+// engine under the names the engine reads them from, e.g. DB_PASSWORD and
+// MYSQL_ROOT_PASSWORD. Variables the service sets itself are kept.
+func (s *Scheduler) applyDatabaseEnv(ctx context.Context, deployment *models.Deployment) error {
+	if deployment.Config == nil {
+		return nil
+	}
+	service, err := s.service(ctx, deployment)
+	if err != nil || service == nil || service.SourceType != models.SourceTypeDatabase || service.Database == nil {
+		return err
+	}
+	vars := databases.EngineEnv(databases.DatabaseType(service.Database.Type), service.Name, deployment.Config.EnvVars)
+	for name, value := range vars {
+		if _, ok := deployment.Config.EnvVars[name]; !ok {
+			deployment.Config.EnvVars[name] = value
+		}
+	}
+	return nil
+}
+
+// filterHealthy returns schedulable nodes that have sent a heartbeat within
+// the health threshold. Pending, cordoned and draining nodes are skipped.
 func (s *Scheduler) filterHealthy(nodes []*models.Node) []*models.Node {
 	threshold := time.Now().Add(-s.healthThreshold)
 	var healthy []*models.Node
 
 	for _, node := range nodes {
-		if node.Healthy && node.LastHeartbeat.After(threshold) {
+		if node.Healthy && node.Schedulable() && node.LastHeartbeat.After(threshold) {
 			healthy = append(healthy, node)
 		}
 	}
@@ -154,6 +297,12 @@ func (s *Scheduler) GetHealthThreshold() time.Duration {
 // If no healthy nodes are available, the deployment remains in "built" status (queued).
 // **Validates: Requirements 16.1, 6.2**
 func (s *Scheduler) ScheduleAndAssign(ctx context.Context, deployment *models.Deployment) error {
+	// Cron services only run on schedule: hold their deployed artifact for
+	// the runs started from it instead of starting the service
+	if held, err := s.holdCronRelease(ctx, deployment); err != nil || held {
+		return err
+	}
+
 	// Check if dependencies are running before scheduling
 	if len(deployment.DependsOn) > 0 {
 		depsRunning, err := s.AreDependenciesRunning(ctx, deployment)
@@ -169,11 +318,18 @@ func (s *Scheduler) ScheduleAndAssign(ctx context.Context, deployment *models.De
 		}
 	}
 
+	if err := s.applyRuntime(ctx, deployment); err != nil {
+		return err
+	}
+	if err := s.applyCanary(ctx, deployment); err != nil {
+		return err
+	}
+
 	node, err := s.Schedule(ctx, deployment)
 	if err != nil {
 		// If no healthy nodes or insufficient resources, keep deployment in "built" status (queued)
 		// **Validates: Requirements 16.1, 16.4**
-		if errors.Is(err, ErrNoHealthyNodes) || errors.Is(err, ErrInsufficientResources) {
+		if errors.Is(err, ErrNoHealthyNodes) || errors.Is(err, ErrInsufficientResources) || errors.Is(err, ErrNoCapableNodes) {
 			s.logger.Info("no nodes available, deployment queued",
 				"deployment_id", deployment.ID,
 				"reason", err.Error(),
@@ -194,6 +350,12 @@ func (s *Scheduler) ScheduleAndAssign(ctx context.Context, deployment *models.De
 		return err
 	}
 
+	// Deploy-stage admission policies run once a node is found, so a
+	// deployment waiting for capacity isn't re-evaluated on every retry
+	if err := s.admit(ctx, deployment); err != nil {
+		return err
+	}
+
 	// Merge environment variables if EnvMerger is configured
 	// **Validates: Requirements 6.1, 6.2, 6.3**
 	if s.envMerger != nil && deployment.Config != nil {
@@ -218,6 +380,10 @@ func (s *Scheduler) ScheduleAndAssign(ctx context.Context, deployment *models.De
 		}
 	}
 
+	if err := s.applyDatabaseEnv(ctx, deployment); err != nil {
+		return err
+	}
+
 	// Update deployment with placement
 	deployment.NodeID = node.ID
 	deployment.Status = models.DeploymentStatusScheduled
@@ -248,6 +414,84 @@ func (s *Scheduler) ScheduleAndAssign(ctx context.Context, deployment *models.De
 	return nil
 }
 
+// admit evaluates deploy-stage admission policies against a deployment and
+// its build. A deployment that violates a deny policy is marked failed.
+func (s *Scheduler) admit(ctx context.Context, deployment *models.Deployment) error {
+	if s.admission == nil {
+		return nil
+	}
+	app, err := s.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil {
+		return fmt.Errorf("getting app: %w", err)
+	}
+	if app == nil {
+		return nil
+	}
+	subject := admission.Subject{App: app, Deployment: deployment}
+	for i := range app.Services {
+		if app.Services[i].Name == deployment.ServiceName {
+			subject.Service = &app.Services[i]
+			break
+		}
+	}
+	if subject.Build, err = s.admission.BuildFacts(ctx, deployment.ID); err != nil {
+		return fmt.Errorf("loading build for admission: %w", err)
+	}
+
+	decision, err := s.admission.Check(ctx, models.AdmissionStageDeploy, subject)
+	if err != nil {
+		return fmt.Errorf("checking admission policies: %w", err)
+	}
+	s.admission.RecordViolations(ctx, decision, subject, "")
+	if !decision.Denied() {
+		return nil
+	}
+
+	deployment.Status = models.DeploymentStatusFailed
+	deployment.UpdatedAt = time.Now()
+	if err := s.store.Deployments().Update(ctx, deployment); err != nil {
+		return fmt.Errorf("updating denied deployment: %w", err)
+	}
+	return fmt.Errorf("%w: %s", ErrAdmissionDenied, decision.Summary())
+}
+
+// holdCronRelease marks a built deployment of a cron service stopped rather
+// than scheduling it, and reports whether it did. Runs of the service carry
+// the command to execute and are scheduled like any other deployment.
+func (s *Scheduler) holdCronRelease(ctx context.Context, deployment *models.Deployment) (bool, error) {
+	if deployment.IsCronRun() {
+		return false, nil
+	}
+	app, err := s.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil {
+		return false, fmt.Errorf("getting app: %w", err)
+	}
+	if app == nil {
+		return false, nil
+	}
+	isCron := false
+	for i := range app.Services {
+		if app.Services[i].Name == deployment.ServiceName {
+			isCron = app.Services[i].IsCron()
+			break
+		}
+	}
+	if !isCron {
+		return false, nil
+	}
+
+	deployment.Status = models.DeploymentStatusStopped
+	deployment.UpdatedAt = time.Now()
+	if err := s.store.Deployments().Update(ctx, deployment); err != nil {
+		return false, fmt.Errorf("updating cron service deployment: %w", err)
+	}
+	s.logger.Info("cron service deployed, runs will start on schedule",
+		"deployment_id", deployment.ID,
+		"service_name", deployment.ServiceName,
+	)
+	return true, nil
+}
+
 // AreDependenciesRunning checks if all service dependencies for a deployment are running.
 // It looks for deployments of the same app with the dependent service names that are in running state.
 func (s *Scheduler) AreDependenciesRunning(ctx context.Context, deployment *models.Deployment) (bool, error) {
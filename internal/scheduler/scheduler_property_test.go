@@ -293,6 +293,20 @@ func TestSchedulerHealthFiltering(t *testing.T) {
 	properties.TestingRun(t)
 }
 
+func TestSchedulerSkipsUnschedulableNodes(t *testing.T) {
+	scheduler := NewScheduler(nil, nil, &config.SchedulerConfig{HealthThreshold: time.Minute}, nil)
+
+	var nodes []*models.Node
+	for _, status := range []models.NodeStatus{"", models.NodeStatusActive, models.NodeStatusPending, models.NodeStatusCordoned, models.NodeStatusDraining, models.NodeStatusRejected} {
+		nodes = append(nodes, &models.Node{ID: string(status), Healthy: true, Status: status, LastHeartbeat: time.Now()})
+	}
+
+	filtered := scheduler.filterHealthy(nodes)
+	if len(filtered) != 2 || filtered[0].Status != "" || filtered[1].Status != models.NodeStatusActive {
+		t.Errorf("filtered = %v, want only active nodes", filtered)
+	}
+}
+
 // **Feature: control-plane, Property 12: Scheduler resource filtering**
 // For any scheduling decision, the selected node must have sufficient resources for the requested resource tier.
 // **Validates: Requirements 4.2**
@@ -698,3 +712,30 @@ func TestMultipleDependenciesOrdering(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestFilterByPool(t *testing.T) {
+	agent := &models.Node{ID: "agent"}
+	cluster := &models.Node{ID: "cluster", Provider: models.NodeProviderKubernetes, Pool: "kubernetes",
+		Capabilities: []models.NodeCapability{models.NodeCapabilityOCIImages}}
+	nodes := []*models.Node{agent, cluster}
+
+	oci := &models.Deployment{BuildType: models.BuildTypeOCI}
+	nix := &models.Deployment{BuildType: models.BuildTypePureNix}
+
+	if got := FilterByPool(nodes, "", oci.RequiredCapabilities()); len(got) != 1 || got[0] != agent {
+		t.Errorf("default pool = %v, want the agent node", got)
+	}
+	if got := FilterByPool(nodes, "kubernetes", oci.RequiredCapabilities()); len(got) != 1 || got[0] != cluster {
+		t.Errorf("kubernetes pool = %v, want the cluster", got)
+	}
+	if got := FilterByPool(nodes, "kubernetes", nix.RequiredCapabilities()); len(got) != 0 {
+		t.Errorf("pure-nix deployment placed on %v, want no nodes", got)
+	}
+
+	// Systemd units only run on agents that report the capability
+	unitAgent := &models.Node{ID: "unit-agent", Capabilities: append([]models.NodeCapability{models.NodeCapabilitySystemdUnits}, models.PodmanCapabilities...)}
+	unit := &models.Deployment{BuildType: models.BuildTypePureNix, Config: &models.RuntimeConfig{Runtime: models.ServiceRuntimeSystemd}}
+	if got := FilterByPool(append(nodes, unitAgent), "", unit.RequiredCapabilities()); len(got) != 1 || got[0] != unitAgent {
+		t.Errorf("systemd deployment placed on %v, want the agent with systemd units", got)
+	}
+}
@@ -0,0 +1,125 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed `attribute eq "value"` filter, the only form identity
+// providers use when looking up users and groups before provisioning them.
+type Filter struct {
+	Attribute string // Lowercased attribute name
+	Value     string
+}
+
+// filterPattern matches `attr eq "value"`, case-insensitively for attr and operator.
+var filterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// ParseFilter parses a SCIM filter. An empty string yields a nil filter.
+func ParseFilter(s string) (*Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	m := filterPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errorf(http.StatusBadRequest, "invalidFilter", "unsupported filter %q: only `attribute eq \"value\"` is supported", s)
+	}
+	value, err := strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalidFilter", "invalid filter value in %q", s)
+	}
+	return &Filter{Attribute: strings.ToLower(m[1]), Value: value}, nil
+}
+
+// memberPathPattern matches `members[value eq "id"]`.
+var memberPathPattern = regexp.MustCompile(`(?i)^members\[value\s+eq\s+"([^"]*)"\]$`)
+
+// memberFromPath returns the member ID selected by a value filter path.
+func memberFromPath(path string) (string, bool) {
+	m := memberPathPattern.FindStringSubmatch(strings.TrimSpace(path))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// page applies SCIM 1-based startIndex and count to n items and returns the
+// slice bounds along with the normalized startIndex.
+func page(n, startIndex, count int) (int, int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > MaxResults {
+		count = MaxResults
+	}
+	from := startIndex - 1
+	if from > n {
+		from = n
+	}
+	to := from + count
+	if to > n {
+		to = n
+	}
+	return from, to, startIndex
+}
+
+// patchValues normalizes a patch operation into attribute/value pairs. An
+// operation with a path applies its value to that path; one without a path
+// carries an object whose keys are attribute names.
+func patchValues(op PatchOperation) (map[string]json.RawMessage, error) {
+	if op.Path != "" {
+		return map[string]json.RawMessage{strings.ToLower(op.Path): op.Value}, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &obj); err != nil {
+		return nil, errInvalidValue("patch operation without a path must have an object value")
+	}
+	values := make(map[string]json.RawMessage, len(obj))
+	for k, v := range obj {
+		values[strings.ToLower(k)] = v
+	}
+	return values, nil
+}
+
+// boolValue decodes a boolean that some identity providers send as a string.
+func boolValue(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v, nil
+		}
+	}
+	return false, errInvalidValue("expected a boolean, got %s", raw)
+}
+
+// stringValue decodes a string attribute value.
+func stringValue(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", errInvalidValue("expected a string, got %s", raw)
+	}
+	return s, nil
+}
+
+// memberValues decodes a list of {"value": id} members.
+func memberValues(raw json.RawMessage) ([]string, error) {
+	var members []MultiValue
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, errInvalidValue("expected a list of members")
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.Value)
+	}
+	return ids, nil
+}
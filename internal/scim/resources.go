@@ -0,0 +1,268 @@
+// Package scim implements SCIM 2.0 (RFC 7643/7644) user and group provisioning
+// so identity providers can manage org membership automatically.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// SCIM schema URNs.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// ContentType is the media type for SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// MaxResults is the largest page returned by list requests.
+const MaxResults = 1000
+
+// Meta holds resource metadata.
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Name is a user's structured name.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails or members.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// User is a SCIM User resource.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Password    string       `json:"password,omitempty"`
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// Group is a SCIM Group resource.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is a page of resources.
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// PatchRequest is a SCIM PATCH body.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, replace or remove operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Error is a SCIM error response. It is returned by Service methods for
+// failures the client should see, such as conflicts and invalid values.
+type Error struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// MarshalJSON renders the error in the SCIM error response format.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		SCIMType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}{[]string{SchemaError}, fmt.Sprint(e.Status), e.SCIMType, e.Detail})
+}
+
+// errorf builds a SCIM error.
+func errorf(status int, scimType, format string, args ...interface{}) *Error {
+	return &Error{Status: status, SCIMType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Common SCIM errors.
+func errNotFound(resource, id string) *Error {
+	return errorf(http.StatusNotFound, "", "%s %s not found", resource, id)
+}
+
+func errInvalidValue(format string, args ...interface{}) *Error {
+	return errorf(http.StatusBadRequest, "invalidValue", format, args...)
+}
+
+func errUniqueness(format string, args ...interface{}) *Error {
+	return errorf(http.StatusConflict, "uniqueness", format, args...)
+}
+
+// userResource converts a SCIM user link to its SCIM representation.
+func userResource(u *models.SCIMUser, email string, groups []*models.SCIMGroup) *User {
+	active := u.Active
+	res := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          u.ID,
+		ExternalID:  u.ExternalID,
+		UserName:    u.UserName,
+		DisplayName: u.DisplayName,
+		Active:      &active,
+		Groups:      []MultiValue{},
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     "/scim/v2/Users/" + u.ID,
+		},
+	}
+	if email != "" {
+		res.Emails = []MultiValue{{Value: email, Type: "work", Primary: true}}
+	}
+	for _, g := range groups {
+		for _, id := range g.MemberIDs {
+			if id == u.ID {
+				res.Groups = append(res.Groups, MultiValue{Value: g.ID, Display: g.DisplayName})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// groupResource converts a SCIM group to its SCIM representation.
+func groupResource(g *models.SCIMGroup, users map[string]*models.SCIMUser) *Group {
+	res := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          g.ID,
+		ExternalID:  g.ExternalID,
+		DisplayName: g.DisplayName,
+		Members:     []MultiValue{},
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + g.ID,
+		},
+	}
+	for _, id := range g.MemberIDs {
+		member := MultiValue{Value: id}
+		if u, ok := users[id]; ok {
+			member.Display = u.UserName
+		}
+		res.Members = append(res.Members, member)
+	}
+	return res
+}
+
+// email returns the user's primary email, or the first email, falling back to userName.
+func (u *User) email() string {
+	for _, e := range u.Emails {
+		if e.Primary && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Value != "" {
+			return e.Value
+		}
+	}
+	return u.UserName
+}
+
+// displayName returns the display name, falling back to the formatted or given and family names.
+func (u *User) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	if u.Name.GivenName != "" && u.Name.FamilyName != "" {
+		return u.Name.GivenName + " " + u.Name.FamilyName
+	}
+	return u.Name.GivenName + u.Name.FamilyName
+}
+
+// ServiceProviderConfig describes the supported SCIM features.
+func ServiceProviderConfig() map[string]interface{} {
+	supported := func(v bool) map[string]bool { return map[string]bool{"supported": v} }
+	return map[string]interface{}{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": MaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Org SCIM token generated in the organization settings",
+			"primary":     true,
+		}},
+	}
+}
+
+// ResourceTypes describes the User and Group resource types.
+func ResourceTypes() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"schemas": []string{SchemaResourceType}, "id": "User", "name": "User", "endpoint": "/Users", "schema": SchemaUser},
+		{"schemas": []string{SchemaResourceType}, "id": "Group", "name": "Group", "endpoint": "/Groups", "schema": SchemaGroup},
+	}
+}
+
+// WriteJSON writes v as a SCIM response.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes a SCIM error response.
+func WriteError(w http.ResponseWriter, err *Error) {
+	WriteJSON(w, err.Status, err)
+}
@@ -0,0 +1,992 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// tokenPrefix starts every SCIM token so they are recognizable in logs and secret scanners.
+const tokenPrefix = "scim_"
+
+// recentEvents is the number of events included in the sync status.
+const recentEvents = 50
+
+// Service provisions users and groups into an org from SCIM requests.
+//
+// Users are matched to existing accounts by email; an account is created only
+// when none exists. Group memberships drive org roles through the org's group
+// mappings: members of a group mapped to owner become owners and everyone
+// else a member. Without mappings, existing members keep their role.
+// Deactivating or deleting a user removes their org membership, but the org's
+// only owner is never removed or demoted.
+type Service struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewService creates a new SCIM provisioning service.
+func NewService(st store.Store, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		store:  st,
+		logger: logger,
+	}
+}
+
+// Status summarizes an org's SCIM configuration and recent sync activity.
+type Status struct {
+	Enabled     bool                      `json:"enabled"`
+	Token       *models.SCIMToken         `json:"token,omitempty"`
+	Mappings    []models.SCIMGroupMapping `json:"mappings"`
+	Users       int                       `json:"users"`
+	ActiveUsers int                       `json:"active_users"`
+	Groups      int                       `json:"groups"`
+	LastSyncAt  *time.Time                `json:"last_sync_at,omitempty"`
+	Events      []*models.SCIMEvent       `json:"events"`
+}
+
+// Status returns the org's SCIM configuration and recent events.
+func (s *Service) Status(ctx context.Context, orgID string) (*Status, error) {
+	token, err := s.store.SCIM().GetToken(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := s.store.SCIM().ListMappings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.store.SCIM().ListUsers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.store.SCIM().ListGroups(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.store.SCIM().ListEvents(ctx, orgID, recentEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Enabled:  token != nil,
+		Token:    token,
+		Mappings: mappings,
+		Users:    len(users),
+		Groups:   len(groups),
+		Events:   events,
+	}
+	if status.Mappings == nil {
+		status.Mappings = []models.SCIMGroupMapping{}
+	}
+	if status.Events == nil {
+		status.Events = []*models.SCIMEvent{}
+	}
+	for _, u := range users {
+		if u.Active {
+			status.ActiveUsers++
+		}
+	}
+	if len(events) > 0 {
+		status.LastSyncAt = &events[0].CreatedAt
+	}
+	return status, nil
+}
+
+// GenerateToken creates a new SCIM token for the org, replacing any existing
+// one. The token is returned once and only its hash is stored.
+func (s *Service) GenerateToken(ctx context.Context, orgID, userID string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(b)
+
+	meta := &models.SCIMToken{
+		OrgID:       orgID,
+		TokenPrefix: token[:len(tokenPrefix)+6],
+		CreatedBy:   userID,
+	}
+	if err := s.store.SCIM().SetToken(ctx, meta, auth.HashAPIKey(token)); err != nil {
+		return "", err
+	}
+	s.logger.Info("scim token generated", "org_id", orgID, "user_id", userID)
+	return token, nil
+}
+
+// RevokeToken disables SCIM provisioning for the org.
+func (s *Service) RevokeToken(ctx context.Context, orgID string) error {
+	return s.store.SCIM().DeleteToken(ctx, orgID)
+}
+
+// Authenticate returns the org ID for a SCIM token, or an empty string if it is invalid.
+func (s *Service) Authenticate(ctx context.Context, token string) (string, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return "", nil
+	}
+	return s.store.SCIM().AuthenticateToken(ctx, auth.HashAPIKey(token))
+}
+
+// SetMappings replaces the org's group to role mappings and re-syncs every
+// provisioned user's role.
+func (s *Service) SetMappings(ctx context.Context, orgID string, mappings []models.SCIMGroupMapping) error {
+	seen := make(map[string]bool, len(mappings))
+	for i := range mappings {
+		m := &mappings[i]
+		m.GroupName = strings.TrimSpace(m.GroupName)
+		if m.GroupName == "" {
+			return errInvalidValue("group name is required")
+		}
+		if !m.Role.Valid() {
+			return errInvalidValue("role for group %q must be one of: owner, admin, developer, viewer, member", m.GroupName)
+		}
+		if seen[m.GroupName] {
+			return errInvalidValue("duplicate mapping for group %q", m.GroupName)
+		}
+		seen[m.GroupName] = true
+	}
+
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.SCIM().SetMappings(ctx, orgID, mappings); err != nil {
+			return err
+		}
+		users, err := tx.SCIM().ListUsers(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := s.syncMembership(ctx, tx, orgID, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.record(ctx, orgID, "sync", "Mapping", "", fmt.Sprintf("%d group mappings", len(mappings)), err, "")
+	return err
+}
+
+// ============================================================================
+// Users
+// ============================================================================
+
+// ListUsers returns a page of the org's SCIM users matching filter.
+func (s *Service) ListUsers(ctx context.Context, orgID string, filter *Filter, startIndex, count int) (*ListResponse, error) {
+	if filter != nil {
+		switch filter.Attribute {
+		case "id", "username", "externalid":
+		default:
+			return nil, errorf(http.StatusBadRequest, "invalidFilter", "filtering users by %q is not supported", filter.Attribute)
+		}
+	}
+
+	users, err := s.store.SCIM().ListUsers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.store.SCIM().ListGroups(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*models.SCIMUser
+	for _, u := range users {
+		if filter == nil ||
+			(filter.Attribute == "id" && u.ID == filter.Value) ||
+			(filter.Attribute == "username" && strings.EqualFold(u.UserName, filter.Value)) ||
+			(filter.Attribute == "externalid" && u.ExternalID == filter.Value) {
+			matched = append(matched, u)
+		}
+	}
+
+	from, to, startIndex := page(len(matched), startIndex, count)
+	resources := make([]*User, 0, to-from)
+	for _, u := range matched[from:to] {
+		res, err := s.userResource(ctx, s.store, u, groups)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// GetUser returns a SCIM user.
+func (s *Service) GetUser(ctx context.Context, orgID, id string) (*User, error) {
+	u, err := s.store.SCIM().GetUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, errNotFound("User", id)
+	}
+	return s.userResource(ctx, s.store, u, nil)
+}
+
+// CreateUser provisions a user into the org. An existing account with the
+// same email is linked instead of creating a new one, unless it is already
+// linked to another SCIM user in the org.
+func (s *Service) CreateUser(ctx context.Context, orgID string, in *User) (*User, error) {
+	in.UserName = strings.TrimSpace(in.UserName)
+	if in.UserName == "" {
+		return nil, errInvalidValue("userName is required")
+	}
+
+	var created *models.SCIMUser
+	var res *User
+	detail := ""
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		users, err := tx.SCIM().ListUsers(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if existing := findUserName(users, in.UserName, ""); existing != nil {
+			return errUniqueness("a user with userName %q already exists", in.UserName)
+		}
+
+		email := in.email()
+		account, err := tx.Users().GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		provisioned := account == nil
+		if account != nil {
+			for _, u := range users {
+				if u.UserID == account.ID {
+					return errUniqueness("the account for %s is already linked to user %q", email, u.UserName)
+				}
+			}
+			detail = "linked existing account"
+		} else {
+			password := in.Password
+			if password == "" {
+				if password, err = randomPassword(); err != nil {
+					return err
+				}
+			}
+			account, err = tx.Users().CreateWithRole(ctx, email, password, store.RoleMember, "")
+			if err != nil {
+				return err
+			}
+			if name := in.displayName(); name != "" {
+				account.Name = name
+				if err := tx.Users().Update(ctx, account); err != nil {
+					return err
+				}
+			}
+		}
+
+		created = &models.SCIMUser{
+			OrgID:       orgID,
+			UserID:      account.ID,
+			ExternalID:  in.ExternalID,
+			UserName:    in.UserName,
+			DisplayName: in.displayName(),
+			Active:      in.Active == nil || *in.Active,
+			Provisioned: provisioned,
+		}
+		if err := tx.SCIM().CreateUser(ctx, created); err != nil {
+			return err
+		}
+		if err := s.syncMembership(ctx, tx, orgID, created); err != nil {
+			return err
+		}
+		res, err = s.userResource(ctx, tx, created, nil)
+		return err
+	})
+
+	id := ""
+	if created != nil {
+		id = created.ID
+	}
+	s.record(ctx, orgID, "create", "User", id, in.UserName, err, detail)
+	return res, err
+}
+
+// ReplaceUser replaces a user's attributes (PUT).
+func (s *Service) ReplaceUser(ctx context.Context, orgID, id string, in *User) (*User, error) {
+	in.UserName = strings.TrimSpace(in.UserName)
+	if in.UserName == "" {
+		return nil, errInvalidValue("userName is required")
+	}
+
+	var res *User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		u, err := tx.SCIM().GetUser(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return errNotFound("User", id)
+		}
+
+		u.UserName = in.UserName
+		u.ExternalID = in.ExternalID
+		u.DisplayName = in.displayName()
+		u.Active = in.Active == nil || *in.Active
+		res, err = s.saveUser(ctx, tx, u, in.email())
+		return err
+	})
+	s.record(ctx, orgID, "replace", "User", id, in.UserName, err, "")
+	return res, err
+}
+
+// PatchUser applies PATCH operations to a user. Attributes Narvana does not
+// store are ignored so that identity providers can send their full schema.
+func (s *Service) PatchUser(ctx context.Context, orgID, id string, ops []PatchOperation) (*User, error) {
+	var res *User
+	name := ""
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		u, err := tx.SCIM().GetUser(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return errNotFound("User", id)
+		}
+
+		email := ""
+		for _, op := range ops {
+			switch strings.ToLower(op.Op) {
+			case "add", "replace":
+				values, err := patchValues(op)
+				if err != nil {
+					return err
+				}
+				for attr, raw := range values {
+					if err := applyUserValue(u, attr, raw, &email); err != nil {
+						return err
+					}
+				}
+			case "remove":
+				switch strings.ToLower(op.Path) {
+				case "externalid":
+					u.ExternalID = ""
+				case "displayname":
+					u.DisplayName = ""
+				}
+			default:
+				return errInvalidValue("unsupported patch operation %q", op.Op)
+			}
+		}
+
+		u.UserName = strings.TrimSpace(u.UserName)
+		if u.UserName == "" {
+			return errInvalidValue("userName is required")
+		}
+		name = u.UserName
+		res, err = s.saveUser(ctx, tx, u, email)
+		return err
+	})
+	s.record(ctx, orgID, "patch", "User", id, name, err, "")
+	return res, err
+}
+
+// applyUserValue sets a single patched user attribute. A new email is written
+// to email rather than the user.
+func applyUserValue(u *models.SCIMUser, attr string, raw []byte, email *string) error {
+	var err error
+	switch attr {
+	case "active":
+		u.Active, err = boolValue(raw)
+	case "username":
+		u.UserName, err = stringValue(raw)
+	case "displayname":
+		u.DisplayName, err = stringValue(raw)
+	case "externalid":
+		u.ExternalID, err = stringValue(raw)
+	case `emails[type eq "work"].value`:
+		*email, err = stringValue(raw)
+	}
+	return err
+}
+
+// DeleteUser deprovisions a user: their org membership and SCIM link are
+// removed, and an account created by SCIM is deleted once it belongs to no org.
+func (s *Service) DeleteUser(ctx context.Context, orgID, id string) error {
+	name := ""
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		u, err := tx.SCIM().GetUser(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return errNotFound("User", id)
+		}
+		name = u.UserName
+
+		if err := s.removeMembership(ctx, tx, orgID, u.UserID); err != nil {
+			return err
+		}
+		if err := tx.SCIM().DeleteUser(ctx, orgID, id); err != nil {
+			return err
+		}
+		if !u.Provisioned {
+			return nil
+		}
+
+		orgs, err := tx.Orgs().List(ctx, u.UserID)
+		if err != nil {
+			return err
+		}
+		account, err := tx.Users().GetByID(ctx, u.UserID)
+		if err != nil {
+			return err
+		}
+		if len(orgs) == 0 && account != nil && account.Role != store.RoleOwner {
+			return tx.Users().Delete(ctx, u.UserID)
+		}
+		return nil
+	})
+	s.record(ctx, orgID, "delete", "User", id, name, err, "")
+	return err
+}
+
+// saveUser checks userName uniqueness, syncs the linked account and the
+// user's org membership, and returns the updated resource.
+func (s *Service) saveUser(ctx context.Context, tx store.Store, u *models.SCIMUser, email string) (*User, error) {
+	users, err := tx.SCIM().ListUsers(ctx, u.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if existing := findUserName(users, u.UserName, u.ID); existing != nil {
+		return nil, errUniqueness("a user with userName %q already exists", u.UserName)
+	}
+
+	// Only accounts created by SCIM follow IdP profile changes
+	if u.Provisioned {
+		account, err := tx.Users().GetByID(ctx, u.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if account != nil {
+			if email == "" {
+				email = account.Email
+			}
+			if email != account.Email {
+				other, err := tx.Users().GetByEmail(ctx, email)
+				if err != nil {
+					return nil, err
+				}
+				if other != nil {
+					return nil, errUniqueness("another account already uses %s", email)
+				}
+			}
+			account.Email = email
+			account.Name = u.DisplayName
+			if err := tx.Users().Update(ctx, account); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.SCIM().UpdateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	if err := s.syncMembership(ctx, tx, u.OrgID, u); err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, tx, u, nil)
+}
+
+// findUserName returns the user other than excludeID whose userName matches, case-insensitively.
+func findUserName(users []*models.SCIMUser, userName, excludeID string) *models.SCIMUser {
+	for _, u := range users {
+		if u.ID != excludeID && strings.EqualFold(u.UserName, userName) {
+			return u
+		}
+	}
+	return nil
+}
+
+// userResource builds the SCIM representation of u. Groups are loaded when nil.
+func (s *Service) userResource(ctx context.Context, st store.Store, u *models.SCIMUser, groups []*models.SCIMGroup) (*User, error) {
+	if groups == nil {
+		var err error
+		if groups, err = st.SCIM().ListGroups(ctx, u.OrgID); err != nil {
+			return nil, err
+		}
+	}
+	email := ""
+	account, err := st.Users().GetByID(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil {
+		email = account.Email
+	}
+	return userResource(u, email, groups), nil
+}
+
+// ============================================================================
+// Groups
+// ============================================================================
+
+// ListGroups returns a page of the org's SCIM groups matching filter.
+func (s *Service) ListGroups(ctx context.Context, orgID string, filter *Filter, startIndex, count int) (*ListResponse, error) {
+	if filter != nil {
+		switch filter.Attribute {
+		case "id", "displayname", "externalid":
+		default:
+			return nil, errorf(http.StatusBadRequest, "invalidFilter", "filtering groups by %q is not supported", filter.Attribute)
+		}
+	}
+
+	groups, err := s.store.SCIM().ListGroups(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.usersByID(ctx, s.store, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*models.SCIMGroup
+	for _, g := range groups {
+		if filter == nil ||
+			(filter.Attribute == "id" && g.ID == filter.Value) ||
+			(filter.Attribute == "displayname" && strings.EqualFold(g.DisplayName, filter.Value)) ||
+			(filter.Attribute == "externalid" && g.ExternalID == filter.Value) {
+			matched = append(matched, g)
+		}
+	}
+
+	from, to, startIndex := page(len(matched), startIndex, count)
+	resources := make([]*Group, 0, to-from)
+	for _, g := range matched[from:to] {
+		resources = append(resources, groupResource(g, users))
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// GetGroup returns a SCIM group.
+func (s *Service) GetGroup(ctx context.Context, orgID, id string) (*Group, error) {
+	g, err := s.store.SCIM().GetGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errNotFound("Group", id)
+	}
+	users, err := s.usersByID(ctx, s.store, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return groupResource(g, users), nil
+}
+
+// CreateGroup creates a group and syncs its members' roles.
+func (s *Service) CreateGroup(ctx context.Context, orgID string, in *Group) (*Group, error) {
+	in.DisplayName = strings.TrimSpace(in.DisplayName)
+	if in.DisplayName == "" {
+		return nil, errInvalidValue("displayName is required")
+	}
+
+	group := &models.SCIMGroup{
+		OrgID:       orgID,
+		ExternalID:  in.ExternalID,
+		DisplayName: in.DisplayName,
+	}
+	for _, m := range in.Members {
+		group.MemberIDs = append(group.MemberIDs, m.Value)
+	}
+
+	var res *Group
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		res, err = s.saveGroup(ctx, tx, group, nil, true)
+		return err
+	})
+	s.record(ctx, orgID, "create", "Group", group.ID, in.DisplayName, err, "")
+	return res, err
+}
+
+// ReplaceGroup replaces a group's attributes and members (PUT).
+func (s *Service) ReplaceGroup(ctx context.Context, orgID, id string, in *Group) (*Group, error) {
+	in.DisplayName = strings.TrimSpace(in.DisplayName)
+	if in.DisplayName == "" {
+		return nil, errInvalidValue("displayName is required")
+	}
+
+	var res *Group
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		group, err := tx.SCIM().GetGroup(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return errNotFound("Group", id)
+		}
+		previous := group.MemberIDs
+
+		group.ExternalID = in.ExternalID
+		group.DisplayName = in.DisplayName
+		group.MemberIDs = nil
+		for _, m := range in.Members {
+			group.MemberIDs = append(group.MemberIDs, m.Value)
+		}
+		res, err = s.saveGroup(ctx, tx, group, previous, false)
+		return err
+	})
+	s.record(ctx, orgID, "replace", "Group", id, in.DisplayName, err, "")
+	return res, err
+}
+
+// PatchGroup applies PATCH operations to a group's name and members.
+func (s *Service) PatchGroup(ctx context.Context, orgID, id string, ops []PatchOperation) (*Group, error) {
+	var res *Group
+	name := ""
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		group, err := tx.SCIM().GetGroup(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return errNotFound("Group", id)
+		}
+		previous := append([]string(nil), group.MemberIDs...)
+
+		for _, op := range ops {
+			if err := applyGroupOp(group, op); err != nil {
+				return err
+			}
+		}
+
+		group.DisplayName = strings.TrimSpace(group.DisplayName)
+		if group.DisplayName == "" {
+			return errInvalidValue("displayName is required")
+		}
+		name = group.DisplayName
+		res, err = s.saveGroup(ctx, tx, group, previous, false)
+		return err
+	})
+	s.record(ctx, orgID, "patch", "Group", id, name, err, "")
+	return res, err
+}
+
+// applyGroupOp applies a single PATCH operation to a group.
+func applyGroupOp(group *models.SCIMGroup, op PatchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		values, err := patchValues(op)
+		if err != nil {
+			return err
+		}
+		replace := strings.EqualFold(op.Op, "replace")
+		for attr, raw := range values {
+			switch attr {
+			case "displayname":
+				if group.DisplayName, err = stringValue(raw); err != nil {
+					return err
+				}
+			case "externalid":
+				if group.ExternalID, err = stringValue(raw); err != nil {
+					return err
+				}
+			case "members":
+				ids, err := memberValues(raw)
+				if err != nil {
+					return err
+				}
+				if replace {
+					group.MemberIDs = nil
+				}
+				group.MemberIDs = appendUnique(group.MemberIDs, ids...)
+			}
+		}
+	case "remove":
+		if id, ok := memberFromPath(op.Path); ok {
+			group.MemberIDs = without(group.MemberIDs, id)
+			return nil
+		}
+		if !strings.EqualFold(op.Path, "members") {
+			return errorf(http.StatusBadRequest, "noTarget", "cannot remove %q", op.Path)
+		}
+		if len(op.Value) == 0 {
+			group.MemberIDs = nil
+			return nil
+		}
+		ids, err := memberValues(op.Value)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			group.MemberIDs = without(group.MemberIDs, id)
+		}
+	default:
+		return errInvalidValue("unsupported patch operation %q", op.Op)
+	}
+	return nil
+}
+
+// DeleteGroup removes a group and re-syncs its former members' roles.
+func (s *Service) DeleteGroup(ctx context.Context, orgID, id string) error {
+	name := ""
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		group, err := tx.SCIM().GetGroup(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if group == nil {
+			return errNotFound("Group", id)
+		}
+		name = group.DisplayName
+
+		if err := tx.SCIM().DeleteGroup(ctx, orgID, id); err != nil {
+			return err
+		}
+		return s.syncUsers(ctx, tx, orgID, group.MemberIDs)
+	})
+	s.record(ctx, orgID, "delete", "Group", id, name, err, "")
+	return err
+}
+
+// saveGroup validates a group's name and members, stores it and re-syncs the
+// roles of current and previous members.
+func (s *Service) saveGroup(ctx context.Context, tx store.Store, group *models.SCIMGroup, previous []string, create bool) (*Group, error) {
+	groups, err := tx.SCIM().ListGroups(ctx, group.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID != group.ID && strings.EqualFold(g.DisplayName, group.DisplayName) {
+			return nil, errUniqueness("a group named %q already exists", group.DisplayName)
+		}
+	}
+
+	users, err := s.usersByID(ctx, tx, group.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	group.MemberIDs = appendUnique(nil, group.MemberIDs...)
+	for _, id := range group.MemberIDs {
+		if _, ok := users[id]; !ok {
+			return nil, errInvalidValue("member %s is not a provisioned user", id)
+		}
+	}
+
+	if create {
+		err = tx.SCIM().CreateGroup(ctx, group)
+	} else {
+		err = tx.SCIM().UpdateGroup(ctx, group)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncUsers(ctx, tx, group.OrgID, appendUnique(previous, group.MemberIDs...)); err != nil {
+		return nil, err
+	}
+	return groupResource(group, users), nil
+}
+
+// usersByID returns the org's SCIM users keyed by ID.
+func (s *Service) usersByID(ctx context.Context, st store.Store, orgID string) (map[string]*models.SCIMUser, error) {
+	users, err := st.SCIM().ListUsers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.SCIMUser, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	return byID, nil
+}
+
+// ============================================================================
+// Membership sync
+// ============================================================================
+
+// syncUsers re-syncs the org membership of the given SCIM users.
+func (s *Service) syncUsers(ctx context.Context, tx store.Store, orgID string, ids []string) error {
+	for _, id := range ids {
+		u, err := tx.SCIM().GetUser(ctx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			continue
+		}
+		if err := s.syncMembership(ctx, tx, orgID, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncMembership brings a SCIM user's org membership in line with their
+// active flag and group mappings.
+func (s *Service) syncMembership(ctx context.Context, tx store.Store, orgID string, u *models.SCIMUser) error {
+	if !u.Active {
+		return s.removeMembership(ctx, tx, orgID, u.UserID)
+	}
+
+	mappings, err := tx.SCIM().ListMappings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	members, err := tx.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	current := currentRole(members, u.UserID)
+
+	role := models.RoleMember
+	if len(mappings) == 0 {
+		// Without mappings SCIM only grants membership and leaves roles alone
+		if current != "" {
+			return nil
+		}
+	} else {
+		groups, err := tx.SCIM().ListGroups(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		role = models.SCIMRole(u.ID, groups, mappings)
+	}
+
+	if role == current {
+		return nil
+	}
+	if current == models.RoleOwner && countOwners(members) == 1 {
+		s.logger.Warn("not demoting the only org owner via scim", "org_id", orgID, "user_id", u.UserID)
+		return nil
+	}
+	return tx.Orgs().AddMember(ctx, orgID, u.UserID, role)
+}
+
+// removeMembership removes a user from the org unless they are its only owner.
+func (s *Service) removeMembership(ctx context.Context, tx store.Store, orgID, userID string) error {
+	members, err := tx.Orgs().ListMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	current := currentRole(members, userID)
+	if current == "" {
+		return nil
+	}
+	if current == models.RoleOwner && countOwners(members) == 1 {
+		return errorf(http.StatusConflict, "mutability", "cannot deprovision the organization's only owner")
+	}
+	return tx.Orgs().RemoveMember(ctx, orgID, userID)
+}
+
+// currentRole returns the user's role in the org, or an empty role if they are not a member.
+func currentRole(members []*models.OrgMembership, userID string) models.Role {
+	for _, m := range members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// countOwners returns the number of owners among members.
+func countOwners(members []*models.OrgMembership) int {
+	n := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// record stores the outcome of an operation for the sync status page.
+// Failures to record are logged and otherwise ignored.
+func (s *Service) record(ctx context.Context, orgID, operation, resourceType, resourceID, name string, opErr error, message string) {
+	event := &models.SCIMEvent{
+		OrgID:        orgID,
+		Operation:    operation,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         name,
+		Status:       models.SCIMEventSuccess,
+		Message:      message,
+	}
+	if opErr != nil {
+		event.Status = models.SCIMEventError
+		event.Message = opErr.Error()
+		var scimErr *Error
+		if errors.As(opErr, &scimErr) {
+			if scimErr.Status == http.StatusConflict {
+				event.Status = models.SCIMEventConflict
+			}
+		} else {
+			// Internal errors are logged in full but not shown in the UI
+			event.Message = "internal error"
+		}
+	}
+
+	if err := s.store.SCIM().RecordEvent(ctx, event); err != nil {
+		s.logger.Error("failed to record scim event", "error", err, "org_id", orgID)
+	}
+	if opErr != nil {
+		s.logger.Warn("scim operation failed",
+			"org_id", orgID,
+			"operation", operation,
+			"resource_type", resourceType,
+			"resource_id", resourceID,
+			"error", opErr,
+		)
+	}
+}
+
+// randomPassword returns a random password for accounts created without one.
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// appendUnique appends ids not already present in list.
+func appendUnique(list []string, ids ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, id := range list {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// without returns list with id removed.
+func without(list []string, id string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,421 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store with just the users, orgs and SCIM
+// operations the service uses. Other methods panic via the nil embedded interfaces.
+type memStore struct {
+	store.Store
+	users *memUsers
+	orgs  *memOrgs
+	scim  *memSCIM
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		users: &memUsers{byID: map[string]*store.User{}},
+		orgs:  &memOrgs{members: map[string]models.Role{}},
+		scim:  &memSCIM{users: map[string]*models.SCIMUser{}, groups: map[string]*models.SCIMGroup{}},
+	}
+}
+
+func (s *memStore) Users() store.UserStore { return s.users }
+func (s *memStore) Orgs() store.OrgStore   { return s.orgs }
+func (s *memStore) SCIM() store.SCIMStore  { return s.scim }
+func (s *memStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(s)
+}
+
+type memUsers struct {
+	store.UserStore
+	byID map[string]*store.User
+	next int
+}
+
+func (u *memUsers) add(email string, role store.Role) *store.User {
+	u.next++
+	user := &store.User{ID: fmt.Sprintf("user-%d", u.next), Email: email, Role: role}
+	u.byID[user.ID] = user
+	return user
+}
+
+func (u *memUsers) CreateWithRole(ctx context.Context, email, password string, role store.Role, invitedBy string) (*store.User, error) {
+	return u.add(email, role), nil
+}
+
+func (u *memUsers) GetByEmail(ctx context.Context, email string) (*store.User, error) {
+	for _, user := range u.byID {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (u *memUsers) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return u.byID[id], nil
+}
+
+func (u *memUsers) Update(ctx context.Context, user *store.User) error {
+	u.byID[user.ID] = user
+	return nil
+}
+
+func (u *memUsers) Delete(ctx context.Context, id string) error {
+	delete(u.byID, id)
+	return nil
+}
+
+// memOrgs tracks the members of a single org.
+type memOrgs struct {
+	store.OrgStore
+	members map[string]models.Role
+}
+
+func (o *memOrgs) List(ctx context.Context, userID string) ([]*models.Organization, error) {
+	if _, ok := o.members[userID]; ok {
+		return []*models.Organization{{ID: "org-1"}}, nil
+	}
+	return nil, nil
+}
+
+func (o *memOrgs) ListMembers(ctx context.Context, orgID string) ([]*models.OrgMembership, error) {
+	var members []*models.OrgMembership
+	for userID, role := range o.members {
+		members = append(members, &models.OrgMembership{OrgID: orgID, UserID: userID, Role: role})
+	}
+	return members, nil
+}
+
+func (o *memOrgs) AddMember(ctx context.Context, orgID, userID string, role models.Role) error {
+	o.members[userID] = role
+	return nil
+}
+
+func (o *memOrgs) RemoveMember(ctx context.Context, orgID, userID string) error {
+	delete(o.members, userID)
+	return nil
+}
+
+type memSCIM struct {
+	store.SCIMStore
+	users    map[string]*models.SCIMUser
+	groups   map[string]*models.SCIMGroup
+	order    []string
+	mappings []models.SCIMGroupMapping
+	events   []*models.SCIMEvent
+	next     int
+}
+
+func (m *memSCIM) id() string {
+	m.next++
+	return fmt.Sprintf("scim-%d", m.next)
+}
+
+func (m *memSCIM) CreateUser(ctx context.Context, u *models.SCIMUser) error {
+	u.ID = m.id()
+	m.users[u.ID] = u
+	m.order = append(m.order, u.ID)
+	return nil
+}
+
+func (m *memSCIM) GetUser(ctx context.Context, orgID, id string) (*models.SCIMUser, error) {
+	if u, ok := m.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *memSCIM) ListUsers(ctx context.Context, orgID string) ([]*models.SCIMUser, error) {
+	var users []*models.SCIMUser
+	for _, id := range m.order {
+		if u, ok := m.users[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (m *memSCIM) UpdateUser(ctx context.Context, u *models.SCIMUser) error {
+	copied := *u
+	m.users[u.ID] = &copied
+	return nil
+}
+
+func (m *memSCIM) DeleteUser(ctx context.Context, orgID, id string) error {
+	delete(m.users, id)
+	for _, g := range m.groups {
+		g.MemberIDs = without(g.MemberIDs, id)
+	}
+	return nil
+}
+
+func (m *memSCIM) CreateGroup(ctx context.Context, g *models.SCIMGroup) error {
+	g.ID = m.id()
+	copied := *g
+	m.groups[g.ID] = &copied
+	return nil
+}
+
+func (m *memSCIM) GetGroup(ctx context.Context, orgID, id string) (*models.SCIMGroup, error) {
+	if g, ok := m.groups[id]; ok {
+		copied := *g
+		copied.MemberIDs = append([]string(nil), g.MemberIDs...)
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *memSCIM) ListGroups(ctx context.Context, orgID string) ([]*models.SCIMGroup, error) {
+	var groups []*models.SCIMGroup
+	for _, g := range m.groups {
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (m *memSCIM) UpdateGroup(ctx context.Context, g *models.SCIMGroup) error {
+	copied := *g
+	m.groups[g.ID] = &copied
+	return nil
+}
+
+func (m *memSCIM) DeleteGroup(ctx context.Context, orgID, id string) error {
+	delete(m.groups, id)
+	return nil
+}
+
+func (m *memSCIM) ListMappings(ctx context.Context, orgID string) ([]models.SCIMGroupMapping, error) {
+	return m.mappings, nil
+}
+
+func (m *memSCIM) SetMappings(ctx context.Context, orgID string, mappings []models.SCIMGroupMapping) error {
+	m.mappings = mappings
+	return nil
+}
+
+func (m *memSCIM) RecordEvent(ctx context.Context, event *models.SCIMEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func newTestService() (*Service, *memStore) {
+	st := newMemStore()
+	return NewService(st, nil), st
+}
+
+func scimStatus(err error) int {
+	var scimErr *Error
+	if errors.As(err, &scimErr) {
+		return scimErr.Status
+	}
+	return 0
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		in        string
+		attribute string
+		value     string
+		wantErr   bool
+	}{
+		{in: `userName eq "alice@example.com"`, attribute: "username", value: "alice@example.com"},
+		{in: `externalId EQ "a\"b"`, attribute: "externalid", value: `a"b`},
+		{in: `userName sw "a"`, wantErr: true},
+		{in: `userName eq "a" and active eq "true"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.in)
+		if tt.wantErr {
+			if scimStatus(err) != http.StatusBadRequest {
+				t.Errorf("ParseFilter(%q) error = %v, want 400", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseFilter(%q) error = %v", tt.in, err)
+		}
+		if f.Attribute != tt.attribute || f.Value != tt.value {
+			t.Errorf("ParseFilter(%q) = %+v", tt.in, f)
+		}
+	}
+
+	if f, err := ParseFilter(""); f != nil || err != nil {
+		t.Errorf("ParseFilter(\"\") = %v, %v, want nil, nil", f, err)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+	svc, st := newTestService()
+	existing := st.users.add("bob@example.com", store.RoleMember)
+
+	created, err := svc.CreateUser(ctx, "org-1", &User{UserName: "alice@example.com", DisplayName: "Alice"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	alice := st.scim.users[created.ID]
+	if !alice.Provisioned || st.users.byID[alice.UserID].Name != "Alice" {
+		t.Errorf("new account not provisioned: %+v", alice)
+	}
+	if st.orgs.members[alice.UserID] != models.RoleMember {
+		t.Errorf("new user not added to org")
+	}
+
+	linked, err := svc.CreateUser(ctx, "org-1", &User{UserName: "bob", Emails: []MultiValue{{Value: "bob@example.com", Primary: true}}})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if bob := st.scim.users[linked.ID]; bob.Provisioned || bob.UserID != existing.ID {
+		t.Errorf("existing account not linked: %+v", bob)
+	}
+
+	// userName conflicts are case-insensitive
+	_, err = svc.CreateUser(ctx, "org-1", &User{UserName: "ALICE@example.com"})
+	if scimStatus(err) != http.StatusConflict {
+		t.Errorf("duplicate userName error = %v, want 409", err)
+	}
+	// An account can only be linked once per org
+	_, err = svc.CreateUser(ctx, "org-1", &User{UserName: "bob2", Emails: []MultiValue{{Value: "bob@example.com"}}})
+	if scimStatus(err) != http.StatusConflict {
+		t.Errorf("duplicate link error = %v, want 409", err)
+	}
+
+	last := st.scim.events[len(st.scim.events)-1]
+	if last.Status != models.SCIMEventConflict {
+		t.Errorf("last event status = %s, want conflict", last.Status)
+	}
+}
+
+func TestDeactivateUser(t *testing.T) {
+	ctx := context.Background()
+	svc, st := newTestService()
+	owner := st.users.add("owner@example.com", store.RoleOwner)
+	st.orgs.members[owner.ID] = models.RoleOwner
+
+	u, err := svc.CreateUser(ctx, "org-1", &User{UserName: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	accountID := st.scim.users[u.ID].UserID
+
+	ops := []PatchOperation{{Op: "replace", Value: json.RawMessage(`{"active": "False"}`)}}
+	if _, err := svc.PatchUser(ctx, "org-1", u.ID, ops); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if _, ok := st.orgs.members[accountID]; ok {
+		t.Error("deactivated user still a member")
+	}
+
+	ops = []PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`true`)}}
+	if _, err := svc.PatchUser(ctx, "org-1", u.ID, ops); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if st.orgs.members[accountID] != models.RoleMember {
+		t.Error("reactivated user not re-added")
+	}
+
+	if err := svc.DeleteUser(ctx, "org-1", u.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if st.users.byID[accountID] != nil {
+		t.Error("provisioned account not deleted")
+	}
+}
+
+func TestDeprovisionOnlyOwner(t *testing.T) {
+	ctx := context.Background()
+	svc, st := newTestService()
+	owner := st.users.add("owner@example.com", store.RoleMember)
+	st.orgs.members[owner.ID] = models.RoleOwner
+
+	u, err := svc.CreateUser(ctx, "org-1", &User{UserName: "owner@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := svc.DeleteUser(ctx, "org-1", u.ID); scimStatus(err) != http.StatusConflict {
+		t.Errorf("DeleteUser() error = %v, want 409", err)
+	}
+	if st.orgs.members[owner.ID] != models.RoleOwner {
+		t.Error("only owner was removed")
+	}
+}
+
+func TestGroupRoleMapping(t *testing.T) {
+	ctx := context.Background()
+	svc, st := newTestService()
+	founder := st.users.add("founder@example.com", store.RoleOwner)
+	st.orgs.members[founder.ID] = models.RoleOwner
+
+	alice, _ := svc.CreateUser(ctx, "org-1", &User{UserName: "alice@example.com"})
+	aliceID := st.scim.users[alice.ID].UserID
+
+	if err := svc.SetMappings(ctx, "org-1", []models.SCIMGroupMapping{{GroupName: "Admins", Role: models.RoleOwner}}); err != nil {
+		t.Fatalf("SetMappings() error = %v", err)
+	}
+	group, err := svc.CreateGroup(ctx, "org-1", &Group{DisplayName: "Admins", Members: []MultiValue{{Value: alice.ID}}})
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	if st.orgs.members[aliceID] != models.RoleOwner {
+		t.Errorf("group member role = %s, want owner", st.orgs.members[aliceID])
+	}
+
+	ops := []PatchOperation{{Op: "remove", Path: fmt.Sprintf(`members[value eq "%s"]`, alice.ID)}}
+	if _, err := svc.PatchGroup(ctx, "org-1", group.ID, ops); err != nil {
+		t.Fatalf("PatchGroup() error = %v", err)
+	}
+	if st.orgs.members[aliceID] != models.RoleMember {
+		t.Errorf("removed member role = %s, want member", st.orgs.members[aliceID])
+	}
+
+	_, err = svc.CreateGroup(ctx, "org-1", &Group{DisplayName: "Ops", Members: []MultiValue{{Value: "missing"}}})
+	if scimStatus(err) != http.StatusBadRequest {
+		t.Errorf("unknown member error = %v, want 400", err)
+	}
+	if err := svc.SetMappings(ctx, "org-1", []models.SCIMGroupMapping{{GroupName: "Admins", Role: "superuser"}}); scimStatus(err) != http.StatusBadRequest {
+		t.Errorf("invalid role error = %v, want 400", err)
+	}
+}
+
+func TestListUsersFilterAndPaging(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService()
+	for _, name := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := svc.CreateUser(ctx, "org-1", &User{UserName: name}); err != nil {
+			t.Fatalf("CreateUser() error = %v", err)
+		}
+	}
+
+	res, err := svc.ListUsers(ctx, "org-1", &Filter{Attribute: "username", Value: "B@example.com"}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if res.TotalResults != 1 {
+		t.Errorf("filtered TotalResults = %d, want 1", res.TotalResults)
+	}
+
+	res, err = svc.ListUsers(ctx, "org-1", nil, 2, 1)
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	users := res.Resources.([]*User)
+	if res.TotalResults != 3 || len(users) != 1 || users[0].UserName != "b@example.com" {
+		t.Errorf("page = %d %+v", res.TotalResults, users)
+	}
+
+	if _, err := svc.ListUsers(ctx, "org-1", &Filter{Attribute: "title", Value: "x"}, 1, 10); scimStatus(err) != http.StatusBadRequest {
+		t.Errorf("unsupported filter error = %v, want 400", err)
+	}
+}
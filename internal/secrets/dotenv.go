@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseDotenv parses the contents of a .env file. Blank lines, comments and
+// an "export " prefix are ignored. Unquoted values end at a " #" comment.
+// Double-quoted values may contain \n, \r, \t, \" and \\ escapes, single-
+// quoted values are taken literally, and both may span several lines, e.g.
+// for PEM keys. A key set twice keeps its last value.
+func ParseDotenv(content string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			vars[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		// Quoted values continue on the following lines until the closing quote
+		quote := value[0]
+		value = value[1:]
+		for {
+			if end := closingQuote(value, quote); end >= 0 {
+				value = value[:end]
+				break
+			}
+			if i+1 >= len(lines) {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", lineNo, key)
+			}
+			i++
+			value += "\n" + lines[i]
+		}
+		if quote == '"' {
+			value = unescapeDotenv(value)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// closingQuote returns the index of the unescaped closing quote in s, or -1.
+// Backslashes only escape in double-quoted values.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotenv processes the escapes of a double-quoted value in a single
+// pass, so \\n becomes a backslash followed by n rather than a newline.
+func unescapeDotenv(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\':
+			b.WriteByte(s[i])
+		default:
+			// Unknown escapes keep their backslash
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// FormatDotenv renders variables as a .env file sorted by key. Values with
+// spaces, quotes, comments or control characters are double-quoted and
+// escaped, so ParseDotenv reads them back unchanged.
+func FormatDotenv(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := vars[k]
+		b.WriteString(k)
+		b.WriteByte('=')
+		if v == "" || !strings.ContainsAny(v, " \t\n\r\"'#\\") {
+			b.WriteString(v)
+		} else {
+			r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+			b.WriteByte('"')
+			b.WriteString(r.Replace(v))
+			b.WriteByte('"')
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := `# Production settings
+export DATABASE_URL=postgres://db:5432/app
+API_KEY = abc123 # rotated monthly
+GREETING="hello \"world\"\n"
+PATTERN='^\d+$'
+PRIVATE_KEY="-----BEGIN KEY-----
+MIIB
+-----END KEY-----"
+EMPTY=
+API_KEY=def456
+`
+	got, err := ParseDotenv(content)
+	if err != nil {
+		t.Fatalf("ParseDotenv() error = %v", err)
+	}
+	want := map[string]string{
+		"DATABASE_URL": "postgres://db:5432/app",
+		"API_KEY":      "def456",
+		"GREETING":     "hello \"world\"\n",
+		"PATTERN":      `^\d+$`,
+		"PRIVATE_KEY":  "-----BEGIN KEY-----\nMIIB\n-----END KEY-----",
+		"EMPTY":        "",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("ParseDotenv() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"NOT A VARIABLE", "=value", "KEY=\"unterminated\nvalue"} {
+		if _, err := ParseDotenv(bad); err == nil {
+			t.Errorf("ParseDotenv(%q) error = nil, want an error", bad)
+		}
+	}
+}
+
+func TestFormatDotenvRoundTrips(t *testing.T) {
+	vars := map[string]string{
+		"PLAIN":   "value",
+		"SPACES":  "two words",
+		"QUOTES":  `say "hi" and 'bye'`,
+		"ESCAPES": "line\nbreak\\n # not a comment\t",
+		"EMPTY":   "",
+	}
+	formatted := FormatDotenv(vars)
+	got, err := ParseDotenv(formatted)
+	if err != nil {
+		t.Fatalf("ParseDotenv(FormatDotenv()) error = %v\n%s", err, formatted)
+	}
+	if !maps.Equal(got, vars) {
+		t.Errorf("round trip = %q, want %q\n%s", got, vars, formatted)
+	}
+}
@@ -0,0 +1,486 @@
+// Package smoketest runs services' smoke tests against each of their new
+// deployments once it reports running, records the results on the
+// deployment and rolls the service back when a test fails.
+//
+// HTTP tests are requests made from the control plane to the deployment.
+// Command tests are one-off runs of the deployment's artifact, like cron
+// runs, so their output is kept as the logs of the run's deployment.
+package smoketest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/scheduler"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// EnvTargetURL is set on command test runs to the tested deployment's address.
+const EnvTargetURL = "SMOKE_TEST_URL"
+
+// maxBodyBytes is how much of a response body HTTP tests read.
+const maxBodyBytes = 1 << 20
+
+// Config controls how often runs are advanced.
+type Config struct {
+	// PollInterval is how often pending and running runs are advanced.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 5 * time.Second,
+	}
+}
+
+// Stopper stops a deployment's containers on the node running it.
+type Stopper interface {
+	Stop(ctx context.Context, nodeID string, deploymentID string) error
+}
+
+// Runner starts a smoke test run for every new deployment of a service with
+// smoke tests, as a deployment notifier of the gRPC server, and advances
+// the runs until they pass or fail.
+type Runner struct {
+	store   store.Store
+	stopper Stopper
+	client  *http.Client
+	config  Config
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewRunner creates a smoke test runner. stopper stops deployments that fail
+// their tests and cancelled command runs; it may be nil, in which case
+// failed deployments are only marked failed.
+func NewRunner(st store.Store, stopper Stopper, cfg Config, logger *slog.Logger) *Runner {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Runner{
+		store:   st,
+		stopper: stopper,
+		client: &http.Client{
+			// Redirects are checked like any other response
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		config: cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// DeploymentStatusChanged queues a run when a deployment of a service with
+// smoke tests starts running. Cron runs, including command test runs, are
+// not tested.
+func (r *Runner) DeploymentStatusChanged(ctx context.Context, deployment *models.Deployment, previous models.DeploymentStatus) {
+	if deployment.Status != models.DeploymentStatusRunning || previous == models.DeploymentStatusRunning || deployment.IsCronRun() {
+		return
+	}
+
+	existing, err := r.store.SmokeTests().GetByDeployment(ctx, deployment.ID)
+	if err != nil {
+		r.logger.Error("failed to get smoke test run", "error", err, "deployment_id", deployment.ID)
+		return
+	}
+	if existing != nil {
+		// The agent restarted the deployment; it was tested when it first ran
+		return
+	}
+
+	app, err := r.store.Apps().Get(ctx, deployment.AppID)
+	if err != nil || app == nil {
+		return
+	}
+	var tests []models.SmokeTest
+	for i := range app.Services {
+		if app.Services[i].Name == deployment.ServiceName {
+			tests = app.Services[i].Clone().SmokeTests
+			break
+		}
+	}
+	if len(tests) == 0 {
+		return
+	}
+
+	run := &models.SmokeTestRun{
+		DeploymentID: deployment.ID,
+		AppID:        deployment.AppID,
+		ServiceName:  deployment.ServiceName,
+		Status:       models.SmokeTestPending,
+		Tests:        tests,
+		Results:      make([]models.SmokeTestResult, len(tests)),
+	}
+	for i, t := range tests {
+		run.Results[i] = models.SmokeTestResult{Name: t.Name, Type: t.Type, Status: models.SmokeTestPending}
+	}
+	if err := r.store.SmokeTests().Create(ctx, run); err != nil {
+		r.logger.Error("failed to create smoke test run", "error", err, "deployment_id", deployment.ID)
+		return
+	}
+	r.logger.Info("smoke tests queued",
+		"app_id", run.AppID,
+		"service_name", run.ServiceName,
+		"deployment_id", run.DeploymentID,
+		"tests", len(tests),
+	)
+}
+
+// Run advances runs every poll interval until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce advances every pending and running run by one step.
+func (r *Runner) RunOnce(ctx context.Context) {
+	runs, err := r.store.SmokeTests().ListActive(ctx)
+	if err != nil {
+		r.logger.Error("failed to list active smoke test runs", "error", err)
+		return
+	}
+	for _, run := range runs {
+		if err := r.advance(ctx, run); err != nil {
+			r.logger.Error("failed to advance smoke test run", "error", err, "run_id", run.ID)
+		}
+	}
+}
+
+// advance runs the HTTP tests of a run that has not started, starts its
+// command tests and checks on those already started, then finishes the run
+// once a test failed or all of them passed.
+func (r *Runner) advance(ctx context.Context, run *models.SmokeTestRun) error {
+	deployment, err := r.store.Deployments().Get(ctx, run.DeploymentID)
+	if err != nil || deployment == nil {
+		return r.finish(ctx, run, models.SmokeTestFailed, "deployment no longer exists")
+	}
+	if deployment.Status != models.DeploymentStatusRunning {
+		r.cancelCommands(ctx, run)
+		return r.finish(ctx, run, models.SmokeTestFailed,
+			fmt.Sprintf("deployment stopped running (%s) before its smoke tests finished", deployment.Status))
+	}
+
+	now := r.now()
+	if run.Status == models.SmokeTestPending {
+		run.Status = models.SmokeTestRunning
+		run.StartedAt = &now
+	}
+	target, err := r.target(ctx, deployment)
+	if err != nil {
+		return r.fail(ctx, run, deployment, err.Error())
+	}
+
+	for i := range run.Results {
+		res := &run.Results[i]
+		if res.Status.Finished() {
+			continue
+		}
+		test := &run.Tests[i]
+		switch test.Type {
+		case models.SmokeTestHTTP:
+			r.runHTTP(ctx, target, test, res)
+		case models.SmokeTestCommand:
+			if err := r.checkCommand(ctx, deployment, target, test, res); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, res := range run.Results {
+		if res.Status == models.SmokeTestFailed {
+			r.cancelCommands(ctx, run)
+			return r.fail(ctx, run, deployment, fmt.Sprintf("smoke test %q failed: %s", res.Name, res.Error))
+		}
+	}
+	for _, res := range run.Results {
+		if res.Status != models.SmokeTestPassed {
+			return r.store.SmokeTests().Update(ctx, run)
+		}
+	}
+	return r.finish(ctx, run, models.SmokeTestPassed, "")
+}
+
+// runHTTP sends an HTTP test's request to the deployment and checks the response.
+func (r *Runner) runHTTP(ctx context.Context, target string, test *models.SmokeTest, res *models.SmokeTestResult) {
+	started := r.now()
+	res.StartedAt = &started
+	defer func() {
+		finished := r.now()
+		res.FinishedAt = &finished
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, test.Timeout())
+	defer cancel()
+
+	method := test.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target+test.Path, nil)
+	if err != nil {
+		res.Status, res.Error = models.SmokeTestFailed, err.Error()
+		return
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		res.Status, res.Error = models.SmokeTestFailed, err.Error()
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+
+	snippet := bytes.TrimSpace(body)
+	if len(snippet) > 200 {
+		snippet = snippet[:200]
+	}
+	res.Output = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, snippet)
+
+	switch {
+	case test.ExpectStatus != 0 && resp.StatusCode != test.ExpectStatus:
+		res.Status, res.Error = models.SmokeTestFailed, fmt.Sprintf("expected status %d, got %d", test.ExpectStatus, resp.StatusCode)
+	case test.ExpectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 300):
+		res.Status, res.Error = models.SmokeTestFailed, fmt.Sprintf("expected a 2xx status, got %d", resp.StatusCode)
+	case test.ExpectBody != "" && !strings.Contains(string(body), test.ExpectBody):
+		res.Status, res.Error = models.SmokeTestFailed, fmt.Sprintf("response body does not contain %q", test.ExpectBody)
+	default:
+		res.Status = models.SmokeTestPassed
+	}
+}
+
+// checkCommand starts a command test's run on its first step and then
+// passes or fails it once the run's deployment exits or times out.
+func (r *Runner) checkCommand(ctx context.Context, deployment *models.Deployment, target string, test *models.SmokeTest, res *models.SmokeTestResult) error {
+	now := r.now()
+	if res.RunDeploymentID == "" {
+		version, err := r.store.Deployments().GetNextVersion(ctx, deployment.AppID, deployment.ServiceName)
+		if err != nil {
+			return fmt.Errorf("getting next version: %w", err)
+		}
+		runDeployment := commandDeployment(deployment, test, target, version, now)
+		if err := r.store.Deployments().Create(ctx, runDeployment); err != nil {
+			return fmt.Errorf("creating command test run: %w", err)
+		}
+		res.RunDeploymentID = runDeployment.ID
+		res.Status = models.SmokeTestRunning
+		res.StartedAt = &now
+		return nil
+	}
+
+	runDeployment, err := r.store.Deployments().Get(ctx, res.RunDeploymentID)
+	if err != nil || runDeployment == nil {
+		res.Status, res.Error = models.SmokeTestFailed, "command test run no longer exists"
+		res.FinishedAt = &now
+		return nil
+	}
+	switch runDeployment.Status {
+	case models.DeploymentStatusStopped:
+		res.Status = models.SmokeTestPassed
+		res.FinishedAt = &now
+	case models.DeploymentStatusFailed:
+		res.Status, res.Error = models.SmokeTestFailed, "command exited with an error; see the run deployment's logs"
+		res.FinishedAt = &now
+	default:
+		if res.StartedAt != nil && now.Sub(*res.StartedAt) > test.Timeout() {
+			r.stop(ctx, runDeployment)
+			res.Status, res.Error = models.SmokeTestFailed, fmt.Sprintf("exceeded timeout of %s", test.Timeout())
+			res.FinishedAt = &now
+		}
+	}
+	return nil
+}
+
+// commandDeployment describes a command test's run: the tested deployment's
+// artifact running the test's command, pointed at the deployment.
+func commandDeployment(deployment *models.Deployment, test *models.SmokeTest, target string, version int, now time.Time) *models.Deployment {
+	config := &models.RuntimeConfig{}
+	if deployment.Config != nil {
+		*config = *deployment.Config.WithoutCanary()
+	}
+	config.EnvVars = make(map[string]string, len(config.EnvVars)+1)
+	if deployment.Config != nil {
+		for k, v := range deployment.Config.EnvVars {
+			config.EnvVars[k] = v
+		}
+	}
+	config.EnvVars[EnvTargetURL] = target
+	config.Command = append([]string(nil), test.Command...)
+	// Runs exit when the command completes; nothing probes or routes to them
+	config.HealthCheck = nil
+	config.Ports = nil
+
+	return &models.Deployment{
+		ID:          uuid.New().String(),
+		AppID:       deployment.AppID,
+		ServiceName: deployment.ServiceName,
+		Version:     version,
+		GitRef:      deployment.GitRef,
+		GitCommit:   deployment.GitCommit,
+		BuildType:   deployment.BuildType,
+		Artifact:    deployment.Artifact,
+		Status:      models.DeploymentStatusBuilt,
+		Resources:   deployment.Resources,
+		Config:      config,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// fail finishes a run as failed, fails and stops its deployment and, unless
+// the deployment is itself a rollback, rolls the service back to the last
+// deployment that ran and did not fail its smoke tests.
+func (r *Runner) fail(ctx context.Context, run *models.SmokeTestRun, deployment *models.Deployment, reason string) error {
+	now := r.now()
+	deployment.Status = models.DeploymentStatusFailed
+	deployment.FinishedAt = &now
+	deployment.UpdatedAt = now
+	if err := r.store.Deployments().Update(ctx, deployment); err != nil {
+		return fmt.Errorf("failing deployment: %w", err)
+	}
+	r.stop(ctx, deployment)
+
+	if deployment.RollbackOf == "" {
+		rollback, err := r.rollback(ctx, deployment)
+		switch {
+		case err != nil:
+			r.logger.Error("failed to roll back after smoke tests failed", "error", err, "deployment_id", deployment.ID)
+			reason += "; rollback failed: " + err.Error()
+		case rollback == nil:
+			reason += "; no earlier deployment to roll back to"
+		default:
+			run.RollbackDeploymentID = rollback.ID
+		}
+	}
+	return r.finish(ctx, run, models.SmokeTestFailed, reason)
+}
+
+// rollback creates a deployment of the newest earlier release of the
+// service that ran and did not fail its own smoke tests. It returns nil if
+// there is none.
+func (r *Runner) rollback(ctx context.Context, failed *models.Deployment) (*models.Deployment, error) {
+	history, err := r.store.Deployments().List(ctx, failed.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %w", err)
+	}
+
+	var source *models.Deployment
+	for _, d := range history {
+		if d.ServiceName != failed.ServiceName || d.Version >= failed.Version || d.IsCronRun() || !d.Succeeded() {
+			continue
+		}
+		if source != nil && d.Version <= source.Version {
+			continue
+		}
+		run, err := r.store.SmokeTests().GetByDeployment(ctx, d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting smoke test run: %w", err)
+		}
+		if run != nil && run.Status == models.SmokeTestFailed {
+			continue
+		}
+		source = d
+	}
+	if source == nil {
+		return nil, nil
+	}
+
+	version, err := r.store.Deployments().GetNextVersion(ctx, failed.AppID, failed.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("getting next version: %w", err)
+	}
+	rollback := scheduler.CreateRollbackDeployment(source, version, uuid.New().String())
+	if err := r.store.Deployments().Create(ctx, rollback); err != nil {
+		return nil, fmt.Errorf("creating rollback deployment: %w", err)
+	}
+	r.logger.Info("rolled back after smoke tests failed",
+		"app_id", failed.AppID,
+		"service_name", failed.ServiceName,
+		"failed_deployment_id", failed.ID,
+		"rollback_deployment_id", rollback.ID,
+		"rollback_of", source.ID,
+	)
+	return rollback, nil
+}
+
+// cancelCommands stops the command test runs of a run that are still going.
+func (r *Runner) cancelCommands(ctx context.Context, run *models.SmokeTestRun) {
+	now := r.now()
+	for i := range run.Results {
+		res := &run.Results[i]
+		if res.Status.Finished() {
+			continue
+		}
+		if res.RunDeploymentID != "" {
+			if d, err := r.store.Deployments().Get(ctx, res.RunDeploymentID); err == nil && d != nil {
+				r.stop(ctx, d)
+			}
+		}
+		res.Status, res.Error = models.SmokeTestFailed, "cancelled"
+		res.FinishedAt = &now
+	}
+}
+
+// stop stops a deployment on its node, if it was placed on one.
+func (r *Runner) stop(ctx context.Context, deployment *models.Deployment) {
+	if deployment.NodeID == "" || r.stopper == nil {
+		return
+	}
+	if err := r.stopper.Stop(ctx, deployment.NodeID, deployment.ID); err != nil {
+		r.logger.Error("failed to stop deployment", "error", err, "deployment_id", deployment.ID, "node_id", deployment.NodeID)
+	}
+}
+
+// finish records a run's final status and the reason for it.
+func (r *Runner) finish(ctx context.Context, run *models.SmokeTestRun, status models.SmokeTestStatus, reason string) error {
+	now := r.now()
+	run.Status = status
+	run.Error = reason
+	run.FinishedAt = &now
+	if err := r.store.SmokeTests().Update(ctx, run); err != nil {
+		return fmt.Errorf("updating smoke test run: %w", err)
+	}
+	r.logger.Info("smoke tests finished",
+		"app_id", run.AppID,
+		"service_name", run.ServiceName,
+		"deployment_id", run.DeploymentID,
+		"status", status,
+		"reason", reason,
+	)
+	return nil
+}
+
+// target returns the base URL of a deployment: its primary port on the node
+// running it.
+func (r *Runner) target(ctx context.Context, deployment *models.Deployment) (string, error) {
+	if deployment.NodeID == "" {
+		return "", fmt.Errorf("deployment is not placed on a node")
+	}
+	node, err := r.store.Nodes().Get(ctx, deployment.NodeID)
+	if err != nil || node == nil {
+		return "", fmt.Errorf("node %s not found", deployment.NodeID)
+	}
+	port := 8080
+	if deployment.Config != nil && len(deployment.Config.Ports) > 0 {
+		port = deployment.Config.Ports[0].ContainerPort
+	}
+	return "http://" + net.JoinHostPort(node.Address, strconv.Itoa(port)), nil
+}
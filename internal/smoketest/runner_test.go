@@ -0,0 +1,280 @@
+package smoketest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing only what the runner uses.
+type memStore struct {
+	store.Store
+	apps        []*models.App
+	nodes       []*models.Node
+	deployments map[string]*models.Deployment
+	runs        []*models.SmokeTestRun
+}
+
+func (s *memStore) Apps() store.AppStore               { return memApps{s: s} }
+func (s *memStore) Nodes() store.NodeStore             { return memNodes{s: s} }
+func (s *memStore) Deployments() store.DeploymentStore { return memDeployments{s: s} }
+func (s *memStore) SmokeTests() store.SmokeTestStore   { return memRuns{s: s} }
+
+type memApps struct {
+	store.AppStore
+	s *memStore
+}
+
+func (m memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	for _, app := range m.s.apps {
+		if app.ID == id {
+			return app, nil
+		}
+	}
+	return nil, nil
+}
+
+type memNodes struct {
+	store.NodeStore
+	s *memStore
+}
+
+func (m memNodes) Get(ctx context.Context, id string) (*models.Node, error) {
+	for _, n := range m.s.nodes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	s *memStore
+}
+
+func (m memDeployments) Create(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments[d.ID] = d
+	return nil
+}
+
+func (m memDeployments) Get(ctx context.Context, id string) (*models.Deployment, error) {
+	return m.s.deployments[id], nil
+}
+
+func (m memDeployments) Update(ctx context.Context, d *models.Deployment) error {
+	m.s.deployments[d.ID] = d
+	return nil
+}
+
+func (m memDeployments) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
+	var result []*models.Deployment
+	for _, d := range m.s.deployments {
+		if d.AppID == appID {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+	return result, nil
+}
+
+func (m memDeployments) GetNextVersion(ctx context.Context, appID, serviceName string) (int, error) {
+	next := 1
+	for _, d := range m.s.deployments {
+		if d.AppID == appID && d.ServiceName == serviceName && d.Version >= next {
+			next = d.Version + 1
+		}
+	}
+	return next, nil
+}
+
+type memRuns struct {
+	store.SmokeTestStore
+	s *memStore
+}
+
+func (m memRuns) Create(ctx context.Context, run *models.SmokeTestRun) error {
+	run.ID = fmt.Sprintf("run-%d", len(m.s.runs)+1)
+	m.s.runs = append(m.s.runs, run)
+	return nil
+}
+
+func (m memRuns) Update(ctx context.Context, run *models.SmokeTestRun) error { return nil }
+
+func (m memRuns) GetByDeployment(ctx context.Context, deploymentID string) (*models.SmokeTestRun, error) {
+	for _, run := range m.s.runs {
+		if run.DeploymentID == deploymentID {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m memRuns) ListActive(ctx context.Context) ([]*models.SmokeTestRun, error) {
+	var active []*models.SmokeTestRun
+	for _, run := range m.s.runs {
+		if !run.Status.Finished() {
+			active = append(active, run)
+		}
+	}
+	return active, nil
+}
+
+type recordingStopper struct {
+	stopped []string
+}
+
+func (s *recordingStopper) Stop(ctx context.Context, nodeID, deploymentID string) error {
+	s.stopped = append(s.stopped, deploymentID)
+	return nil
+}
+
+// setup serves the tested deployment from handler and returns a store with
+// a running v1 and a v2 that just started running.
+func setup(t *testing.T, handler http.HandlerFunc, tests ...models.SmokeTest) (*memStore, *models.Deployment) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	started := time.Now().Add(-time.Hour)
+	config := &models.RuntimeConfig{Ports: []models.PortMapping{{ContainerPort: port}}, EnvVars: map[string]string{"MODE": "prod"}}
+	st := &memStore{
+		apps:  []*models.App{{ID: "app-1", Services: []models.ServiceConfig{{Name: "web", SmokeTests: tests}}}},
+		nodes: []*models.Node{{ID: "node-1", Address: host}},
+		deployments: map[string]*models.Deployment{
+			"dep-1": {ID: "dep-1", AppID: "app-1", ServiceName: "web", Version: 1, Artifact: "img:v1",
+				Status: models.DeploymentStatusStopped, StartedAt: &started, NodeID: "node-1", Config: config},
+			"dep-2": {ID: "dep-2", AppID: "app-1", ServiceName: "web", Version: 2, Artifact: "img:v2",
+				Status: models.DeploymentStatusRunning, StartedAt: &started, NodeID: "node-1", Config: config},
+		},
+	}
+	return st, st.deployments["dep-2"]
+}
+
+func TestRunnerPassesDeployment(t *testing.T) {
+	ctx := context.Background()
+	st, dep := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"ok"}`)
+	},
+		models.SmokeTest{Name: "health", Type: models.SmokeTestHTTP, Path: "/health", ExpectBody: `"ok"`},
+		models.SmokeTest{Name: "e2e", Type: models.SmokeTestCommand, Command: []string{"./e2e.sh"}},
+	)
+	stopper := &recordingStopper{}
+	r := NewRunner(st, stopper, DefaultConfig(), nil)
+
+	r.DeploymentStatusChanged(ctx, dep, models.DeploymentStatusStarting)
+	r.DeploymentStatusChanged(ctx, dep, models.DeploymentStatusStarting)
+	if len(st.runs) != 1 || st.runs[0].Status != models.SmokeTestPending {
+		t.Fatalf("runs = %+v, want one pending run", st.runs)
+	}
+	run := st.runs[0]
+
+	r.RunOnce(ctx)
+	if run.Results[0].Status != models.SmokeTestPassed || run.Results[0].Output == "" {
+		t.Errorf("http result = %+v", run.Results[0])
+	}
+	cmd := st.deployments[run.Results[1].RunDeploymentID]
+	if cmd == nil || !cmd.IsCronRun() || cmd.Status != models.DeploymentStatusBuilt || cmd.Artifact != "img:v2" {
+		t.Fatalf("command run deployment = %+v", cmd)
+	}
+	if cmd.Config.EnvVars[EnvTargetURL] == "" || cmd.Config.EnvVars["MODE"] != "prod" || dep.Config.EnvVars[EnvTargetURL] != "" {
+		t.Errorf("command run env = %v, tested deployment env = %v", cmd.Config.EnvVars, dep.Config.EnvVars)
+	}
+	if run.Status != models.SmokeTestRunning {
+		t.Errorf("status = %s while the command runs", run.Status)
+	}
+
+	// The run is not a release of its own, so it is never tested
+	cmd.Status = models.DeploymentStatusRunning
+	r.DeploymentStatusChanged(ctx, cmd, models.DeploymentStatusStarting)
+	if len(st.runs) != 1 {
+		t.Errorf("command run was smoke tested")
+	}
+
+	cmd.Status = models.DeploymentStatusStopped
+	r.RunOnce(ctx)
+	if run.Status != models.SmokeTestPassed || dep.Status != models.DeploymentStatusRunning || len(stopper.stopped) != 0 {
+		t.Errorf("run = %s, deployment = %s, stopped = %v", run.Status, dep.Status, stopper.stopped)
+	}
+}
+
+func TestRunnerRollsBackFailedDeployment(t *testing.T) {
+	ctx := context.Background()
+	st, dep := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	},
+		models.SmokeTest{Name: "e2e", Type: models.SmokeTestCommand, Command: []string{"./e2e.sh"}},
+		models.SmokeTest{Name: "health", Type: models.SmokeTestHTTP, Path: "/health"},
+	)
+	stopper := &recordingStopper{}
+	r := NewRunner(st, stopper, DefaultConfig(), nil)
+
+	r.DeploymentStatusChanged(ctx, dep, models.DeploymentStatusStarting)
+	r.RunOnce(ctx)
+
+	run := st.runs[0]
+	if run.Status != models.SmokeTestFailed || run.Results[1].Error != "expected a 2xx status, got 503" {
+		t.Fatalf("run = %+v", run)
+	}
+	if run.Results[0].Status != models.SmokeTestFailed || run.Results[0].Error != "cancelled" {
+		t.Errorf("command result = %+v, want cancelled", run.Results[0])
+	}
+	if dep.Status != models.DeploymentStatusFailed {
+		t.Errorf("deployment status = %s, want failed", dep.Status)
+	}
+	if len(stopper.stopped) != 1 || stopper.stopped[0] != "dep-2" {
+		t.Errorf("stopped = %v, want the failed deployment", stopper.stopped)
+	}
+
+	rollback := st.deployments[run.RollbackDeploymentID]
+	if rollback == nil || rollback.RollbackOf != "dep-1" || rollback.Artifact != "img:v1" || rollback.Status != models.DeploymentStatusBuilt {
+		t.Fatalf("rollback = %+v", rollback)
+	}
+
+	// A rollback that fails its tests is not rolled back again
+	rollback.Status = models.DeploymentStatusRunning
+	rollback.NodeID = "node-1"
+	r.DeploymentStatusChanged(ctx, rollback, models.DeploymentStatusStarting)
+	r.RunOnce(ctx)
+	if len(st.runs) != 2 || st.runs[1].Status != models.SmokeTestFailed || st.runs[1].RollbackDeploymentID != "" {
+		t.Errorf("rollback run = %+v", st.runs[1])
+	}
+}
+
+func TestSmokeTestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		test  models.SmokeTest
+		valid bool
+	}{
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestHTTP, Path: "/"}, true},
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestHTTP, Path: "health"}, false},
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestHTTP, Path: "/", Method: "DELETE"}, false},
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestCommand, Command: []string{"make", "smoke"}}, true},
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestCommand, Command: []string{"x"}, ExpectStatus: 200}, false},
+		{models.SmokeTest{Name: "a", Type: models.SmokeTestCommand}, false},
+		{models.SmokeTest{Type: models.SmokeTestHTTP, Path: "/"}, false},
+	} {
+		if err := tc.test.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tc.test, err, tc.valid)
+		}
+	}
+
+	dup := []models.SmokeTest{
+		{Name: "a", Type: models.SmokeTestHTTP, Path: "/"},
+		{Name: "a", Type: models.SmokeTestHTTP, Path: "/x"},
+	}
+	if err := models.ValidateSmokeTests(dup); err == nil {
+		t.Error("duplicate names were accepted")
+	}
+}
@@ -0,0 +1,390 @@
+// Package sshbroker lets authorized users open SSH sessions to nodes through
+// the control plane. It runs an SSH server that authenticates users by the
+// public keys they registered and forwards their connection to a node's SSH
+// daemon, the way a ProxyJump host does:
+//
+//	ssh -J narvana@control-plane:2222 root@node-hostname
+//
+// The user authenticates to the node end to end, so the broker never sees
+// session contents; every session is audited with who connected to which
+// node, with which key, for how long and how much data was exchanged.
+package sshbroker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"golang.org/x/crypto/ssh"
+)
+
+// Permission extension keys set on authenticated connections.
+const (
+	extUserID      = "user-id"
+	extKeyID       = "key-id"
+	extFingerprint = "fingerprint"
+)
+
+// Config controls the SSH broker.
+type Config struct {
+	// Addr is the address the broker listens on, e.g. ":2222".
+	Addr string
+	// HostKeyPath is where the broker's ed25519 host key is stored. It is
+	// generated on first start.
+	HostKeyPath string
+	// NodePort is the port of the SSH daemon on nodes. Users may only
+	// forward to this port.
+	NodePort int
+	// DialTimeout bounds connecting to a node.
+	DialTimeout time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Addr:        ":2222",
+		HostKeyPath: "/var/lib/narvana/ssh_host_ed25519_key",
+		NodePort:    22,
+		DialTimeout: 10 * time.Second,
+	}
+}
+
+// Broker accepts SSH connections from users and forwards them to nodes.
+type Broker struct {
+	store     store.Store
+	config    Config
+	logger    *slog.Logger
+	sshConfig *ssh.ServerConfig
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*ssh.ServerConn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewBroker creates an SSH broker that presents hostKey to users.
+func NewBroker(st store.Store, cfg Config, hostKey ssh.Signer, logger *slog.Logger) *Broker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout}
+	b := &Broker{
+		store:  st,
+		config: cfg,
+		logger: logger,
+		dial:   dialer.DialContext,
+		conns:  make(map[*ssh.ServerConn]struct{}),
+	}
+	b.sshConfig = &ssh.ServerConfig{
+		PublicKeyCallback: b.authenticate,
+		ServerVersion:     "SSH-2.0-narvana-broker",
+	}
+	b.sshConfig.AddHostKey(hostKey)
+	return b
+}
+
+// LoadOrCreateHostKey reads the host key at path, generating and saving a new
+// ed25519 key if the file does not exist.
+func LoadOrCreateHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing host key %s: %w", path, err)
+		}
+		return signer, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading host key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating host key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "narvana ssh broker")
+	if err != nil {
+		return nil, fmt.Errorf("encoding host key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating host key directory: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, fmt.Errorf("writing host key: %w", err)
+	}
+	return ssh.NewSignerFromKey(key)
+}
+
+// ListenAndServe listens on the configured address and serves connections
+// until Stop is called.
+func (b *Broker) ListenAndServe() error {
+	l, err := net.Listen("tcp", b.config.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", b.config.Addr, err)
+	}
+	return b.Serve(l)
+}
+
+// Serve accepts connections on l until Stop is called.
+func (b *Broker) Serve(l net.Listener) error {
+	b.mu.Lock()
+	b.listener = l
+	b.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.handleConn(conn)
+		}()
+	}
+}
+
+// Stop stops accepting connections and closes open sessions once ctx is
+// done, waiting for their audit records to be written.
+func (b *Broker) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if b.listener != nil {
+		b.listener.Close()
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	for conn := range b.conns {
+		conn.Close()
+	}
+	b.mu.Unlock()
+	<-done
+	return nil
+}
+
+// authenticate accepts registered keys whose user's role allows node SSH.
+func (b *Broker) authenticate(meta ssh.ConnMetadata, pub ssh.PublicKey) (*ssh.Permissions, error) {
+	ctx := context.Background()
+	fingerprint := ssh.FingerprintSHA256(pub)
+
+	key, err := b.store.SSH().GetKeyByFingerprint(ctx, fingerprint)
+	if err != nil {
+		b.logger.Error("failed to look up ssh key", "error", err, "fingerprint", fingerprint)
+		return nil, errors.New("key lookup failed")
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown key %s", fingerprint)
+	}
+
+	user, err := b.store.Users().GetByID(ctx, key.UserID)
+	if err != nil {
+		b.logger.Error("failed to load ssh key owner", "error", err, "user_id", key.UserID)
+		return nil, errors.New("user lookup failed")
+	}
+	if user == nil {
+		return nil, fmt.Errorf("key %s has no user", fingerprint)
+	}
+	if err := auth.CheckRolePermission(user.Role, auth.PermissionNodeSSH); err != nil {
+		b.logger.Warn("ssh session denied by role",
+			"user_id", user.ID,
+			"role", user.Role,
+			"fingerprint", fingerprint,
+			"remote_addr", meta.RemoteAddr().String(),
+		)
+		return nil, fmt.Errorf("role %s may not open node sessions", user.Role)
+	}
+
+	return &ssh.Permissions{Extensions: map[string]string{
+		extUserID:      user.ID,
+		extKeyID:       key.ID,
+		extFingerprint: fingerprint,
+	}}, nil
+}
+
+// handleConn runs the SSH handshake and serves the connection's channels.
+func (b *Broker) handleConn(nc net.Conn) {
+	conn, chans, reqs, err := ssh.NewServerConn(nc, b.sshConfig)
+	if err != nil {
+		b.logger.Debug("ssh handshake failed", "error", err, "remote_addr", nc.RemoteAddr().String())
+		nc.Close()
+		return
+	}
+	defer conn.Close()
+
+	b.mu.Lock()
+	b.conns[conn] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+	}()
+
+	if err := b.store.SSH().MarkKeyUsed(context.Background(), conn.Permissions.Extensions[extKeyID]); err != nil {
+		b.logger.Warn("failed to record ssh key use", "error", err)
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	var wg sync.WaitGroup
+	for newCh := range chans {
+		if newCh.ChannelType() != "direct-tcpip" {
+			newCh.Reject(ssh.UnknownChannelType, "only forwarding to nodes is supported; connect with ssh -J")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.forward(conn, newCh)
+		}()
+	}
+	wg.Wait()
+}
+
+// directTCPIP is the payload of a direct-tcpip channel request (RFC 4254, 7.2).
+type directTCPIP struct {
+	Host     string
+	Port     uint32
+	OrigHost string
+	OrigPort uint32
+}
+
+// forward connects a direct-tcpip channel to the SSH daemon of the node it
+// names and records the session.
+func (b *Broker) forward(conn *ssh.ServerConn, newCh ssh.NewChannel) {
+	ctx := context.Background()
+
+	var target directTCPIP
+	if err := ssh.Unmarshal(newCh.ExtraData(), &target); err != nil {
+		newCh.Reject(ssh.ConnectionFailed, "invalid forwarding request")
+		return
+	}
+	if int(target.Port) != b.config.NodePort {
+		newCh.Reject(ssh.Prohibited, fmt.Sprintf("only port %d on nodes may be reached", b.config.NodePort))
+		return
+	}
+	node, err := b.findNode(ctx, target.Host)
+	if err != nil {
+		b.logger.Error("failed to look up node", "error", err, "target", target.Host)
+		newCh.Reject(ssh.ConnectionFailed, "node lookup failed")
+		return
+	}
+	if node == nil {
+		newCh.Reject(ssh.Prohibited, fmt.Sprintf("%s is not a registered node", target.Host))
+		return
+	}
+
+	session := &models.SSHSession{
+		UserID:         conn.Permissions.Extensions[extUserID],
+		NodeID:         node.ID,
+		KeyFingerprint: conn.Permissions.Extensions[extFingerprint],
+		RemoteAddr:     conn.RemoteAddr().String(),
+	}
+	if err := b.store.SSH().CreateSession(ctx, session); err != nil {
+		// Sessions are only allowed if they can be audited
+		b.logger.Error("failed to record ssh session", "error", err, "node_id", node.ID)
+		newCh.Reject(ssh.ConnectionFailed, "failed to record session")
+		return
+	}
+	logger := b.logger.With("session_id", session.ID, "user_id", session.UserID, "node_id", node.ID)
+
+	upstream, err := b.dial(ctx, "tcp", net.JoinHostPort(node.Address, strconv.Itoa(b.config.NodePort)))
+	if err != nil {
+		newCh.Reject(ssh.ConnectionFailed, "could not reach node")
+		session.Error = err.Error()
+		b.endSession(session, logger)
+		return
+	}
+	defer upstream.Close()
+
+	ch, chReqs, err := newCh.Accept()
+	if err != nil {
+		session.Error = err.Error()
+		b.endSession(session, logger)
+		return
+	}
+	defer ch.Close()
+	go ssh.DiscardRequests(chReqs)
+	logger.Info("ssh session started", "fingerprint", session.KeyFingerprint, "remote_addr", session.RemoteAddr)
+
+	var in, out atomic.Int64
+	done := make(chan struct{}, 2)
+	go func() {
+		n, _ := io.Copy(upstream, ch)
+		in.Add(n)
+		if tcp, ok := upstream.(interface{ CloseWrite() error }); ok {
+			tcp.CloseWrite()
+		}
+		done <- struct{}{}
+	}()
+	go func() {
+		n, _ := io.Copy(ch, upstream)
+		out.Add(n)
+		ch.CloseWrite()
+		done <- struct{}{}
+	}()
+	// Either side finishing ends the session
+	<-done
+	ch.Close()
+	upstream.Close()
+	<-done
+
+	session.BytesIn, session.BytesOut = in.Load(), out.Load()
+	b.endSession(session, logger)
+}
+
+// findNode resolves a forwarding target to a registered node by ID,
+// hostname or address.
+func (b *Broker) findNode(ctx context.Context, target string) (*models.Node, error) {
+	nodes, err := b.store.Nodes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		if n.ID == target || n.Hostname == target || n.Address == target {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *Broker) endSession(session *models.SSHSession, logger *slog.Logger) {
+	if err := b.store.SSH().EndSession(context.Background(), session); err != nil {
+		logger.Error("failed to record end of ssh session", "error", err)
+	}
+	logger.Info("ssh session ended",
+		"bytes_in", session.BytesIn,
+		"bytes_out", session.BytesOut,
+		"error", session.Error,
+	)
+}
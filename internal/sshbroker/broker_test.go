@@ -0,0 +1,228 @@
+package sshbroker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+	"golang.org/x/crypto/ssh"
+)
+
+// memStore is an in-memory store providing only what the broker uses.
+type memStore struct {
+	store.Store
+	users map[string]*store.User
+	nodes []*models.Node
+	ssh   *memSSH
+}
+
+func (s *memStore) Users() store.UserStore { return memUsers{s: s} }
+func (s *memStore) Nodes() store.NodeStore { return memNodes{s: s} }
+func (s *memStore) SSH() store.SSHStore    { return s.ssh }
+
+type memUsers struct {
+	store.UserStore
+	s *memStore
+}
+
+func (m memUsers) GetByID(ctx context.Context, id string) (*store.User, error) {
+	return m.s.users[id], nil
+}
+
+type memNodes struct {
+	store.NodeStore
+	s *memStore
+}
+
+func (m memNodes) List(ctx context.Context) ([]*models.Node, error) {
+	return m.s.nodes, nil
+}
+
+type memSSH struct {
+	store.SSHStore
+	mu       sync.Mutex
+	keys     []*models.SSHKey
+	sessions []*models.SSHSession
+	ended    chan *models.SSHSession
+}
+
+func (m *memSSH) GetKeyByFingerprint(ctx context.Context, fingerprint string) (*models.SSHKey, error) {
+	for _, k := range m.keys {
+		if k.Fingerprint == fingerprint {
+			return k, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memSSH) MarkKeyUsed(ctx context.Context, id string) error { return nil }
+
+func (m *memSSH) CreateSession(ctx context.Context, session *models.SSHSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session.ID = "session-" + strconv.Itoa(len(m.sessions)+1)
+	m.sessions = append(m.sessions, session)
+	return nil
+}
+
+func (m *memSSH) EndSession(ctx context.Context, session *models.SSHSession) error {
+	m.ended <- session
+	return nil
+}
+
+// newSigner generates a user key and registers it for userID.
+func newSigner(t *testing.T, st *memStore, userID string) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.ssh.keys = append(st.ssh.keys, &models.SSHKey{
+		ID: "key-" + userID, UserID: userID, Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	})
+	return signer
+}
+
+// startEchoNode starts a TCP echo server standing in for a node's SSH daemon.
+func startEchoNode(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func startBroker(t *testing.T, st *memStore, nodePort int) string {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.NodePort = nodePort
+	b := NewBroker(st, cfg, hostSigner, nil)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go b.Serve(l)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		b.Stop(ctx)
+	})
+	return l.Addr().String()
+}
+
+func dialBroker(addr string, signer ssh.Signer) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "narvana",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+func TestBrokerForwardsToNode(t *testing.T) {
+	st := &memStore{
+		users: map[string]*store.User{
+			"owner":  {ID: "owner", Role: store.RoleOwner},
+			"member": {ID: "member", Role: store.RoleMember},
+		},
+		nodes: []*models.Node{{ID: "node-1", Hostname: "worker-1", Address: "127.0.0.1"}},
+		ssh:   &memSSH{ended: make(chan *models.SSHSession, 1)},
+	}
+	nodePort := startEchoNode(t)
+	addr := startBroker(t, st, nodePort)
+	ownerKey := newSigner(t, st, "owner")
+
+	client, err := dialBroker(addr, ownerKey)
+	if err != nil {
+		t.Fatalf("dialing broker: %v", err)
+	}
+	defer client.Close()
+
+	conn, err := client.Dial("tcp", net.JoinHostPort("worker-1", strconv.Itoa(nodePort)))
+	if err != nil {
+		t.Fatalf("forwarding to node: %v", err)
+	}
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("read %q, %v; want echo of hello", buf, err)
+	}
+	conn.Close()
+
+	select {
+	case s := <-st.ssh.ended:
+		if s.UserID != "owner" || s.NodeID != "node-1" || s.KeyFingerprint != ssh.FingerprintSHA256(ownerKey.PublicKey()) {
+			t.Errorf("unexpected session: %+v", s)
+		}
+		if s.BytesIn != 5 || s.BytesOut != 5 || s.Error != "" {
+			t.Errorf("session traffic = %d in, %d out (error %q), want 5 and 5", s.BytesIn, s.BytesOut, s.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session was not ended")
+	}
+
+	if _, err := client.Dial("tcp", net.JoinHostPort("unknown-host", strconv.Itoa(nodePort))); err == nil {
+		t.Error("forwarding to an unregistered host succeeded")
+	}
+	if _, err := client.Dial("tcp", "worker-1:5432"); err == nil {
+		t.Error("forwarding to a port other than the node SSH port succeeded")
+	}
+	if _, err := client.NewSession(); err == nil {
+		t.Error("opening a shell on the broker succeeded")
+	}
+}
+
+func TestBrokerRejectsUnauthorizedKeys(t *testing.T) {
+	st := &memStore{
+		users: map[string]*store.User{"member": {ID: "member", Role: store.RoleMember}},
+		ssh:   &memSSH{ended: make(chan *models.SSHSession, 1)},
+	}
+	addr := startBroker(t, st, 22)
+
+	if _, err := dialBroker(addr, newSigner(t, st, "member")); err == nil {
+		t.Error("member role was allowed to connect")
+	}
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	unregistered, _ := ssh.NewSignerFromKey(key)
+	if _, err := dialBroker(addr, unregistered); err == nil {
+		t.Error("unregistered key was allowed to connect")
+	}
+}
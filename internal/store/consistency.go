@@ -0,0 +1,20 @@
+package store
+
+import "context"
+
+type readAfterKey struct{}
+
+// ReadAfter marks ctx as allowing reads from a read replica once the replica
+// has caught up with the writes of token. An empty token allows reads from a
+// replica however far behind it is. Reads outside contexts so marked, and
+// reads within transactions, are served by the primary.
+func ReadAfter(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, readAfterKey{}, token)
+}
+
+// ReadAfterToken returns the token ctx was marked with by ReadAfter, and
+// whether it was marked.
+func ReadAfterToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(readAfterKey{}).(string)
+	return token, ok
+}
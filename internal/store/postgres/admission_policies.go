@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AdmissionPolicyStore implements store.AdmissionPolicyStore using PostgreSQL.
+type AdmissionPolicyStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AdmissionPolicyStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// admissionPolicyColumns lists the columns read by scanAdmissionPolicy.
+const admissionPolicyColumns = `id, org_id, name, description, stage, match_expression, expression, message,
+	enforcement, enabled, created_by, created_at, updated_at`
+
+// Create stores a new policy. Names are unique within an org.
+func (s *AdmissionPolicyStore) Create(ctx context.Context, policy *models.AdmissionPolicy) error {
+	if policy.ID == "" {
+		policy.ID = uuid.New().String()
+	}
+	now := time.Now()
+	policy.CreatedAt, policy.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO admission_policies (id, org_id, name, description, stage, match_expression, expression, message,
+			enforcement, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		policy.ID, policy.OrgID, policy.Name, policy.Description, policy.Stage, policy.Match, policy.Expression,
+		policy.Message, policy.Enforcement, policy.Enabled, policy.CreatedBy, policy.CreatedAt, policy.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting admission policy: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting admission policy: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a policy by ID. It returns nil if the policy does not exist.
+func (s *AdmissionPolicyStore) Get(ctx context.Context, id string) (*models.AdmissionPolicy, error) {
+	query, args := newSelect(admissionPolicyColumns, "admission_policies").Where("id = ?", id).Build()
+
+	policy, err := scanAdmissionPolicy(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying admission policy: %w", err)
+	}
+	return policy, nil
+}
+
+// List retrieves all of an org's policies, ordered by name.
+func (s *AdmissionPolicyStore) List(ctx context.Context, orgID string) ([]*models.AdmissionPolicy, error) {
+	q := newSelect(admissionPolicyColumns, "admission_policies").Where("org_id = ?", orgID).OrderBy("name ASC")
+	return listRows(ctx, s.conn(), "admission policy", q, scanAdmissionPolicy)
+}
+
+// Update saves a policy's settings.
+func (s *AdmissionPolicyStore) Update(ctx context.Context, policy *models.AdmissionPolicy) error {
+	policy.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE admission_policies
+		SET name = $2, description = $3, stage = $4, match_expression = $5, expression = $6, message = $7,
+			enforcement = $8, enabled = $9, updated_at = $10
+		WHERE id = $1
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		policy.ID, policy.Name, policy.Description, policy.Stage, policy.Match, policy.Expression, policy.Message,
+		policy.Enforcement, policy.Enabled, policy.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("updating admission policy: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("updating admission policy: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a policy. Its recorded violations are kept.
+func (s *AdmissionPolicyStore) Delete(ctx context.Context, id string) error {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM admission_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting admission policy: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordViolations stores policy violations of a deploy.
+func (s *AdmissionPolicyStore) RecordViolations(ctx context.Context, violations []*models.AdmissionViolation) error {
+	query := `
+		INSERT INTO admission_violations (id, org_id, policy_id, policy_name, stage, enforcement, app_id,
+			service_name, deployment_id, user_id, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, v := range violations {
+		if v.ID == "" {
+			v.ID = uuid.New().String()
+		}
+		if v.CreatedAt.IsZero() {
+			v.CreatedAt = time.Now()
+		}
+		_, err := s.conn().ExecContext(ctx, query,
+			v.ID, v.OrgID, nullString(v.PolicyID), v.PolicyName, v.Stage, v.Enforcement, v.AppID,
+			v.ServiceName, nullString(v.DeploymentID), v.UserID, v.Message, v.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting admission violation: %w", err)
+		}
+	}
+	return nil
+}
+
+// admissionViolationColumns lists the columns read by scanAdmissionViolation.
+const admissionViolationColumns = `id, org_id, policy_id, policy_name, stage, enforcement, app_id,
+	service_name, deployment_id, user_id, message, created_at`
+
+// ListViolations retrieves an org's most recent violations, newest first,
+// optionally limited to one app.
+func (s *AdmissionPolicyStore) ListViolations(ctx context.Context, orgID, appID string, limit int) ([]*models.AdmissionViolation, error) {
+	q := newSelect(admissionViolationColumns, "admission_violations").Where("org_id = ?", orgID)
+	if appID != "" {
+		q.Where("app_id = ?", appID)
+	}
+	q.OrderBy("created_at DESC").Page(limit, 0)
+	return listRows(ctx, s.conn(), "admission violation", q, scanAdmissionViolation)
+}
+
+// scanAdmissionPolicy reads a single admission policy row.
+func scanAdmissionPolicy(row rowScanner) (*models.AdmissionPolicy, error) {
+	var p models.AdmissionPolicy
+	if err := row.Scan(
+		&p.ID, &p.OrgID, &p.Name, &p.Description, &p.Stage, &p.Match, &p.Expression, &p.Message,
+		&p.Enforcement, &p.Enabled, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// scanAdmissionViolation reads a single admission violation row.
+func scanAdmissionViolation(row rowScanner) (*models.AdmissionViolation, error) {
+	var v models.AdmissionViolation
+	var policyID, deploymentID sql.NullString
+	if err := row.Scan(
+		&v.ID, &v.OrgID, &policyID, &v.PolicyName, &v.Stage, &v.Enforcement, &v.AppID,
+		&v.ServiceName, &deploymentID, &v.UserID, &v.Message, &v.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	v.PolicyID = policyID.String
+	v.DeploymentID = deploymentID.String
+	return &v, nil
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AnnouncementStore implements store.AnnouncementStore using PostgreSQL.
+type AnnouncementStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AnnouncementStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Create creates a new announcement.
+func (s *AnnouncementStore) Create(ctx context.Context, announcement *models.Announcement) error {
+	if announcement.ID == "" {
+		announcement.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if announcement.CreatedAt.IsZero() {
+		announcement.CreatedAt = now
+	}
+	announcement.UpdatedAt = now
+
+	query := `
+		INSERT INTO announcements (id, message, level, audience, starts_at, ends_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		announcement.ID,
+		announcement.Message,
+		string(announcement.Level),
+		string(announcement.Audience),
+		announcement.StartsAt,
+		announcement.EndsAt,
+		announcement.CreatedBy,
+		announcement.CreatedAt,
+		announcement.UpdatedAt,
+	)
+	return err
+}
+
+// Get retrieves an announcement by ID.
+func (s *AnnouncementStore) Get(ctx context.Context, id string) (*models.Announcement, error) {
+	query := `
+		SELECT id, message, level, audience, starts_at, ends_at, created_by, created_at, updated_at
+		FROM announcements WHERE id = $1
+	`
+
+	var a models.Announcement
+	var level, audience string
+	var startsAt, endsAt sql.NullTime
+
+	err := s.conn().QueryRowContext(ctx, query, id).Scan(
+		&a.ID, &a.Message, &level, &audience, &startsAt, &endsAt,
+		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.Level = models.AnnouncementLevel(level)
+	a.Audience = models.AnnouncementAudience(audience)
+	if startsAt.Valid {
+		a.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		a.EndsAt = &endsAt.Time
+	}
+
+	return &a, nil
+}
+
+// List retrieves all announcements, newest first.
+func (s *AnnouncementStore) List(ctx context.Context) ([]*models.Announcement, error) {
+	query := `
+		SELECT id, message, level, audience, starts_at, ends_at, created_by, created_at, updated_at
+		FROM announcements ORDER BY created_at DESC
+	`
+	return s.query(ctx, query)
+}
+
+// ListActive retrieves announcements whose display window contains the given time.
+func (s *AnnouncementStore) ListActive(ctx context.Context, now time.Time) ([]*models.Announcement, error) {
+	query := `
+		SELECT id, message, level, audience, starts_at, ends_at, created_by, created_at, updated_at
+		FROM announcements
+		WHERE (starts_at IS NULL OR starts_at <= $1)
+		  AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY created_at DESC
+	`
+	return s.query(ctx, query, now)
+}
+
+// query runs a SELECT returning announcement rows.
+func (s *AnnouncementStore) query(ctx context.Context, query string, args ...any) ([]*models.Announcement, error) {
+	rows, err := s.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []*models.Announcement
+	for rows.Next() {
+		var a models.Announcement
+		var level, audience string
+		var startsAt, endsAt sql.NullTime
+
+		if err := rows.Scan(
+			&a.ID, &a.Message, &level, &audience, &startsAt, &endsAt,
+			&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		a.Level = models.AnnouncementLevel(level)
+		a.Audience = models.AnnouncementAudience(audience)
+		if startsAt.Valid {
+			a.StartsAt = &startsAt.Time
+		}
+		if endsAt.Valid {
+			a.EndsAt = &endsAt.Time
+		}
+
+		announcements = append(announcements, &a)
+	}
+
+	return announcements, rows.Err()
+}
+
+// Update updates an announcement.
+func (s *AnnouncementStore) Update(ctx context.Context, announcement *models.Announcement) error {
+	announcement.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE announcements
+		SET message = $1, level = $2, audience = $3, starts_at = $4, ends_at = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		announcement.Message,
+		string(announcement.Level),
+		string(announcement.Audience),
+		announcement.StartsAt,
+		announcement.EndsAt,
+		announcement.UpdatedAt,
+		announcement.ID,
+	)
+	return err
+}
+
+// Delete removes an announcement.
+func (s *AnnouncementStore) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM announcements WHERE id = $1`
+	_, err := s.conn().ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// APICatalogStore implements store.APICatalogStore using PostgreSQL.
+type APICatalogStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *APICatalogStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Upsert stores the spec last fetched for a service, replacing the previous one.
+func (s *APICatalogStore) Upsert(ctx context.Context, spec *models.ServiceAPISpec) error {
+	operations, err := json.Marshal(spec.Operations)
+	if err != nil {
+		return fmt.Errorf("marshaling operations: %w", err)
+	}
+	var document any
+	if len(spec.Spec) > 0 {
+		document = []byte(spec.Spec)
+	}
+
+	query := `
+		INSERT INTO service_api_specs (app_id, service_name, url, title, version, description,
+			operations, spec, error, fetched_at, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (app_id, service_name) DO UPDATE SET
+			url = EXCLUDED.url,
+			title = EXCLUDED.title,
+			version = EXCLUDED.version,
+			description = EXCLUDED.description,
+			operations = EXCLUDED.operations,
+			spec = EXCLUDED.spec,
+			error = EXCLUDED.error,
+			fetched_at = EXCLUDED.fetched_at,
+			checked_at = EXCLUDED.checked_at
+	`
+
+	_, err = s.conn().ExecContext(ctx, query,
+		spec.AppID,
+		spec.ServiceName,
+		spec.URL,
+		spec.Title,
+		spec.Version,
+		spec.Description,
+		operations,
+		document,
+		spec.Error,
+		spec.FetchedAt,
+		spec.CheckedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upserting service API spec: %w", err)
+	}
+	return nil
+}
+
+// apiSpecColumns lists the columns read by scanServiceAPISpec.
+const apiSpecColumns = `app_id, service_name, url, title, version, description, operations, error, fetched_at, checked_at`
+
+// Get retrieves a service's spec. It returns nil if none was fetched.
+func (s *APICatalogStore) Get(ctx context.Context, appID, serviceName string) (*models.ServiceAPISpec, error) {
+	query, args := newSelect(apiSpecColumns+", spec", "service_api_specs").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		Build()
+
+	var document []byte
+	spec, err := scanServiceAPISpec(s.conn().QueryRowContext(ctx, query, args...), &document)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying service API spec: %w", err)
+	}
+	spec.Spec = document
+	return spec, nil
+}
+
+// ListByApp retrieves the specs of an app's services, without the specs
+// themselves, ordered by service name.
+func (s *APICatalogStore) ListByApp(ctx context.Context, appID string) ([]*models.ServiceAPISpec, error) {
+	q := newSelect(apiSpecColumns, "service_api_specs").
+		Where("app_id = ?", appID).
+		OrderBy("service_name ASC")
+	return listRows(ctx, s.conn(), "service API spec", q, func(row rowScanner) (*models.ServiceAPISpec, error) {
+		return scanServiceAPISpec(row)
+	})
+}
+
+// ListByOrg retrieves the catalog of an org, without the specs themselves,
+// ordered by app and service name. Specs of deleted apps are left out.
+func (s *APICatalogStore) ListByOrg(ctx context.Context, orgID string) ([]*models.CatalogEntry, error) {
+	q := newSelect(qualifyColumns("s", apiSpecColumns)+", a.name", "service_api_specs s JOIN apps a ON s.app_id = a.id").
+		Where("a.org_id = ?", orgID).
+		NotDeleted("a").
+		OrderBy("a.name ASC, s.service_name ASC")
+	return listRows(ctx, s.conn(), "catalog entry", q, func(row rowScanner) (*models.CatalogEntry, error) {
+		var appName string
+		spec, err := scanServiceAPISpec(row, &appName)
+		if err != nil {
+			return nil, err
+		}
+		return &models.CatalogEntry{ServiceAPISpec: *spec, AppName: appName}, nil
+	})
+}
+
+// Delete removes a service's spec.
+func (s *APICatalogStore) Delete(ctx context.Context, appID, serviceName string) error {
+	query := `DELETE FROM service_api_specs WHERE app_id = $1 AND service_name = $2`
+	if _, err := s.conn().ExecContext(ctx, query, appID, serviceName); err != nil {
+		return fmt.Errorf("deleting service API spec: %w", err)
+	}
+	return nil
+}
+
+// scanServiceAPISpec scans the apiSpecColumns of a row, followed by any
+// extra columns into extra.
+func scanServiceAPISpec(row rowScanner, extra ...any) (*models.ServiceAPISpec, error) {
+	var s models.ServiceAPISpec
+	var operations []byte
+	var fetchedAt sql.NullTime
+
+	dest := []any{
+		&s.AppID, &s.ServiceName, &s.URL, &s.Title, &s.Version, &s.Description,
+		&operations, &s.Error, &fetchedAt, &s.CheckedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(operations, &s.Operations); err != nil {
+		return nil, fmt.Errorf("unmarshaling operations: %w", err)
+	}
+	if fetchedAt.Valid {
+		s.FetchedAt = &fetchedAt.Time
+	}
+	return &s, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// APIUsageStore implements store.APIUsageStore using PostgreSQL.
+type APIUsageStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *APIUsageStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const apiUsageColumns = `route, version, user_id, api_key_id, client, count, first_seen, last_seen`
+
+// Record adds usage counted since the last call to the totals of each
+// route, version and client.
+func (s *APIUsageStore) Record(ctx context.Context, usage []*models.APIUsage) error {
+	query := `
+		INSERT INTO api_usage (route, version, user_id, api_key_id, client, count, first_seen, last_seen)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (route, version, user_id, api_key_id, client) DO UPDATE SET
+			count = api_usage.count + EXCLUDED.count,
+			first_seen = LEAST(api_usage.first_seen, EXCLUDED.first_seen),
+			last_seen = GREATEST(api_usage.last_seen, EXCLUDED.last_seen)
+	`
+	for _, u := range usage {
+		_, err := s.conn().ExecContext(ctx, query,
+			u.Route, u.Version, u.UserID, u.APIKeyID, u.Client, u.Count, u.FirstSeen, u.LastSeen,
+		)
+		if err != nil {
+			return fmt.Errorf("recording api usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// List retrieves usage matching filter, most recently seen first.
+func (s *APIUsageStore) List(ctx context.Context, filter models.APIUsageFilter) ([]*models.APIUsage, error) {
+	q := newSelect(apiUsageColumns, "api_usage")
+	if filter.Route != "" {
+		q.Where("route = ?", filter.Route)
+	}
+	if !filter.Since.IsZero() {
+		q.Where("last_seen >= ?", filter.Since)
+	}
+	q.OrderBy("last_seen DESC").Page(filter.Limit, 0)
+	return listRows(ctx, s.conn(), "api usage", q, scanAPIUsage)
+}
+
+func scanAPIUsage(row rowScanner) (*models.APIUsage, error) {
+	var u models.APIUsage
+	if err := row.Scan(
+		&u.Route, &u.Version, &u.UserID, &u.APIKeyID, &u.Client, &u.Count, &u.FirstSeen, &u.LastSeen,
+	); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// APIKeyStore implements store.APIKeyStore using PostgreSQL.
+type APIKeyStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *APIKeyStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// apiKeyColumns lists the columns read by scanAPIKey.
+const apiKeyColumns = `id, user_id, name, key_hash, key_prefix, scopes, created_at, expires_at, last_used_at`
+
+// GetByHash retrieves an API key by its hash. It returns nil if no key matches.
+func (s *APIKeyStore) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query, args := newSelect(apiKeyColumns, "api_keys").Where("key_hash = ?", hash).Build()
+
+	key, err := scanAPIKey(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying api key: %w", err)
+	}
+	return key, nil
+}
+
+// Create creates a new API key.
+func (s *APIKeyStore) Create(ctx context.Context, key *models.APIKey) error {
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []models.APIKeyScope{}
+	}
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return fmt.Errorf("marshaling api key scopes: %w", err)
+	}
+
+	var expiresAt sql.NullTime
+	if !key.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: key.ExpiresAt, Valid: true}
+	}
+
+	query := `
+		INSERT INTO api_keys (id, user_id, name, key_hash, key_prefix, scopes, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		key.ID, key.UserID, key.Name, key.KeyHash, key.Prefix, scopesJSON, key.CreatedAt, expiresAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting api key: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting api key: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an API key.
+func (s *APIKeyStore) Delete(ctx context.Context, id string) error {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting api key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListByUser retrieves all API keys for a user, newest first.
+func (s *APIKeyStore) ListByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	q := newSelect(apiKeyColumns, "api_keys").Where("user_id = ?", userID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "api key", q, scanAPIKey)
+}
+
+// MarkUsed records that an API key was used.
+func (s *APIKeyStore) MarkUsed(ctx context.Context, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("updating api key last use: %w", err)
+	}
+	return nil
+}
+
+// scanAPIKey reads a single API key row selected with apiKeyColumns.
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var k models.APIKey
+	var scopesJSON []byte
+	var expiresAt, lastUsed sql.NullTime
+	if err := row.Scan(
+		&k.ID, &k.UserID, &k.Name, &k.KeyHash, &k.Prefix, &scopesJSON, &k.CreatedAt, &expiresAt, &lastUsed,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &k.Scopes); err != nil {
+		return nil, fmt.Errorf("unmarshaling api key scopes: %w", err)
+	}
+	if expiresAt.Valid {
+		k.ExpiresAt = expiresAt.Time
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+	return &k, nil
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AppHookStore implements store.AppHookStore using PostgreSQL.
+type AppHookStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AppHookStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// appHookColumns lists the columns read by scanAppHook.
+const appHookColumns = `id, app_id, name, enabled, events, url, service_name, path, secret, last_delivery_at,
+	last_error, created_at, updated_at`
+
+// appHookDeliveryColumns lists the columns read by scanAppHookDelivery.
+const appHookDeliveryColumns = `id, hook_id, payload, status, attempts, next_attempt_at, last_error, created_at,
+	delivered_at`
+
+// Create stores a new hook.
+func (s *AppHookStore) Create(ctx context.Context, hook *models.AppHook) error {
+	if hook.ID == "" {
+		hook.ID = uuid.New().String()
+	}
+	now := time.Now()
+	hook.CreatedAt, hook.UpdatedAt = now, now
+
+	eventsJSON, err := marshalHookEvents(hook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO app_hooks (id, app_id, name, enabled, events, url, service_name, path, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		hook.ID, hook.AppID, hook.Name, hook.Enabled, eventsJSON, hook.URL, hook.ServiceName, hook.Path, hook.Secret,
+		hook.CreatedAt, hook.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting app hook: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a hook by ID. It returns nil if the hook does not exist.
+func (s *AppHookStore) Get(ctx context.Context, id string) (*models.AppHook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(appHookColumns, "app_hooks").Where("id = ?", id).Build()
+
+	hook, err := scanAppHook(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying app hook: %w", err)
+	}
+	return hook, nil
+}
+
+// ListByApp retrieves an app's hooks, oldest first.
+func (s *AppHookStore) ListByApp(ctx context.Context, appID string) ([]*models.AppHook, error) {
+	q := newSelect(appHookColumns, "app_hooks").Where("app_id = ?", appID).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "app hook", q, scanAppHook)
+}
+
+// Update updates a hook's name, enabled flag, events and target.
+func (s *AppHookStore) Update(ctx context.Context, hook *models.AppHook) error {
+	hook.UpdatedAt = time.Now()
+
+	eventsJSON, err := marshalHookEvents(hook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE app_hooks
+		SET name = $1, enabled = $2, events = $3, url = $4, service_name = $5, path = $6, secret = $7, updated_at = $8
+		WHERE id = $9
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		hook.Name, hook.Enabled, eventsJSON, hook.URL, hook.ServiceName, hook.Path, hook.Secret, hook.UpdatedAt,
+		hook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating app hook: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a hook and its queued deliveries.
+func (s *AppHookStore) Delete(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM app_hooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting app hook: %w", err)
+	}
+	return nil
+}
+
+// RecordResult stores the time and error, if any, of a hook's latest delivery attempt.
+func (s *AppHookStore) RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	query := `UPDATE app_hooks SET last_delivery_at = $1, last_error = $2 WHERE id = $3`
+	if _, err := s.conn().ExecContext(ctx, query, at, errMsg, id); err != nil {
+		return fmt.Errorf("recording app hook result: %w", err)
+	}
+	return nil
+}
+
+// EnqueueDelivery queues a payload for delivery to a hook.
+func (s *AppHookStore) EnqueueDelivery(ctx context.Context, delivery *models.AppHookDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	now := time.Now()
+	delivery.CreatedAt = now
+	if delivery.Status == "" {
+		delivery.Status = models.AppHookDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+
+	payloadJSON, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("marshaling app hook payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO app_hook_deliveries (id, hook_id, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		delivery.ID, delivery.HookID, payloadJSON, string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt, delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting app hook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt is
+// due and pushes their next attempt back by lease. Uses FOR UPDATE SKIP LOCKED
+// so concurrent API servers claim disjoint sets.
+func (s *AppHookStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.AppHookDelivery, error) {
+	query := `
+		UPDATE app_hook_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM app_hook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + appHookDeliveryColumns
+	rows, err := s.conn().QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming app hook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*models.AppHookDelivery
+	for rows.Next() {
+		d, err := scanAppHookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning app hook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating app hook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+func (s *AppHookStore) UpdateDelivery(ctx context.Context, delivery *models.AppHookDelivery) error {
+	query := `
+		UPDATE app_hook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5
+		WHERE id = $6
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt, delivery.LastError,
+		delivery.DeliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating app hook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves a hook's most recent deliveries, newest first.
+func (s *AppHookStore) ListDeliveries(ctx context.Context, hookID string, limit int) ([]*models.AppHookDelivery, error) {
+	q := newSelect(appHookDeliveryColumns, "app_hook_deliveries").
+		Where("hook_id = ?", hookID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "app hook delivery", q, scanAppHookDelivery)
+}
+
+// marshalHookEvents encodes a hook's events, storing nil as empty.
+func marshalHookEvents(hook *models.AppHook) ([]byte, error) {
+	events := hook.Events
+	if events == nil {
+		events = []models.AppHookEvent{}
+	}
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling app hook events: %w", err)
+	}
+	return eventsJSON, nil
+}
+
+func scanAppHook(row rowScanner) (*models.AppHook, error) {
+	var h models.AppHook
+	var eventsJSON []byte
+	var lastDelivery sql.NullTime
+	if err := row.Scan(
+		&h.ID, &h.AppID, &h.Name, &h.Enabled, &eventsJSON, &h.URL, &h.ServiceName, &h.Path, &h.Secret,
+		&lastDelivery, &h.LastError, &h.CreatedAt, &h.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventsJSON, &h.Events); err != nil {
+		return nil, fmt.Errorf("unmarshaling app hook events: %w", err)
+	}
+	if lastDelivery.Valid {
+		h.LastDeliveryAt = &lastDelivery.Time
+	}
+	return &h, nil
+}
+
+func scanAppHookDelivery(row rowScanner) (*models.AppHookDelivery, error) {
+	var d models.AppHookDelivery
+	var status string
+	var payloadJSON []byte
+	var deliveredAt sql.NullTime
+	if err := row.Scan(
+		&d.ID, &d.HookID, &payloadJSON, &status, &d.Attempts, &d.NextAttemptAt, &d.LastError,
+		&d.CreatedAt, &deliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Status = models.AppHookDeliveryStatus(status)
+	if err := json.Unmarshal(payloadJSON, &d.Payload); err != nil {
+		return nil, fmt.Errorf("unmarshaling app hook payload: %w", err)
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
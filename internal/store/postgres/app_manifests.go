@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AppManifestStore implements store.AppManifestStore using PostgreSQL.
+type AppManifestStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AppManifestStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// appManifestColumns lists the columns read by scanAppManifest.
+const appManifestColumns = `m.app_id, m.spec, m.prune, m.resources, m.secret_digests, m.applied_by, m.applied_at,
+	m.drift, m.drift_checked_at`
+
+// Get retrieves an app's manifest. It returns nil if no spec has been applied to the app.
+func (s *AppManifestStore) Get(ctx context.Context, appID string) (*models.AppManifest, error) {
+	if _, err := uuid.Parse(appID); err != nil {
+		return nil, nil
+	}
+	query := `SELECT ` + appManifestColumns + ` FROM app_manifests m WHERE m.app_id = $1`
+
+	m, err := scanAppManifest(s.conn().QueryRowContext(ctx, query, appID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying app manifest: %w", err)
+	}
+	return m, nil
+}
+
+// List retrieves the manifests of apps that are not deleted.
+func (s *AppManifestStore) List(ctx context.Context) ([]*models.AppManifest, error) {
+	query := `
+		SELECT ` + appManifestColumns + `
+		FROM app_manifests m
+		JOIN apps a ON a.id = m.app_id
+		WHERE a.deleted_at IS NULL
+		ORDER BY m.applied_at ASC
+	`
+	rows, err := s.conn().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying app manifests: %w", err)
+	}
+	defer rows.Close()
+
+	var manifests []*models.AppManifest
+	for rows.Next() {
+		m, err := scanAppManifest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning app manifest: %w", err)
+		}
+		manifests = append(manifests, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating app manifests: %w", err)
+	}
+	return manifests, nil
+}
+
+// Save creates or replaces an app's manifest, clearing its drift.
+func (s *AppManifestStore) Save(ctx context.Context, m *models.AppManifest) error {
+	if m.AppliedAt.IsZero() {
+		m.AppliedAt = time.Now()
+	}
+	m.Drift, m.DriftCheckedAt = nil, nil
+
+	var resourcesJSON []byte
+	if m.Resources != nil {
+		data, err := json.Marshal(m.Resources)
+		if err != nil {
+			return fmt.Errorf("marshaling manifest resources: %w", err)
+		}
+		resourcesJSON = data
+	}
+	digests := m.SecretDigests
+	if digests == nil {
+		digests = map[string]string{}
+	}
+	digestsJSON, err := json.Marshal(digests)
+	if err != nil {
+		return fmt.Errorf("marshaling secret digests: %w", err)
+	}
+
+	query := `
+		INSERT INTO app_manifests (app_id, spec, prune, resources, secret_digests, applied_by, applied_at, drift, drift_checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, '[]', NULL)
+		ON CONFLICT (app_id) DO UPDATE SET
+			spec = EXCLUDED.spec,
+			prune = EXCLUDED.prune,
+			resources = EXCLUDED.resources,
+			secret_digests = EXCLUDED.secret_digests,
+			applied_by = EXCLUDED.applied_by,
+			applied_at = EXCLUDED.applied_at,
+			drift = EXCLUDED.drift,
+			drift_checked_at = EXCLUDED.drift_checked_at
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		m.AppID, m.Spec, m.Prune, resourcesJSON, digestsJSON, m.AppliedBy, m.AppliedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("saving app manifest: %w", err)
+	}
+	return nil
+}
+
+// UpdateDrift records the drift of an app from its manifest, checked at checkedAt.
+func (s *AppManifestStore) UpdateDrift(ctx context.Context, appID string, drift []models.AppSpecChange, checkedAt time.Time) error {
+	if drift == nil {
+		drift = []models.AppSpecChange{}
+	}
+	driftJSON, err := json.Marshal(drift)
+	if err != nil {
+		return fmt.Errorf("marshaling drift: %w", err)
+	}
+
+	result, err := s.conn().ExecContext(ctx,
+		`UPDATE app_manifests SET drift = $1, drift_checked_at = $2 WHERE app_id = $3`,
+		driftJSON, checkedAt, appID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating app drift: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanAppManifest(row rowScanner) (*models.AppManifest, error) {
+	var m models.AppManifest
+	var resourcesJSON, digestsJSON, driftJSON []byte
+	var checkedAt sql.NullTime
+	if err := row.Scan(
+		&m.AppID, &m.Spec, &m.Prune, &resourcesJSON, &digestsJSON, &m.AppliedBy, &m.AppliedAt,
+		&driftJSON, &checkedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(resourcesJSON) > 0 {
+		if err := json.Unmarshal(resourcesJSON, &m.Resources); err != nil {
+			return nil, fmt.Errorf("unmarshaling manifest resources: %w", err)
+		}
+	}
+	if err := json.Unmarshal(digestsJSON, &m.SecretDigests); err != nil {
+		return nil, fmt.Errorf("unmarshaling secret digests: %w", err)
+	}
+	if err := json.Unmarshal(driftJSON, &m.Drift); err != nil {
+		return nil, fmt.Errorf("unmarshaling drift: %w", err)
+	}
+	if checkedAt.Valid {
+		m.DriftCheckedAt = &checkedAt.Time
+	}
+	return &m, nil
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AppTemplateStore implements store.AppTemplateStore using PostgreSQL.
+type AppTemplateStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AppTemplateStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// appTemplateColumns lists the columns read by scanAppTemplate.
+const appTemplateColumns = `name, title, description, variables, secrets, spec, COALESCE(created_by, ''), created_at, updated_at`
+
+// Create stores a new app template. Names are unique.
+func (s *AppTemplateStore) Create(ctx context.Context, template *models.AppTemplate) error {
+	now := time.Now()
+	template.CreatedAt, template.UpdatedAt = now, now
+
+	variables := template.Variables
+	if variables == nil {
+		variables = []models.ServiceTemplateVariable{}
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return fmt.Errorf("marshaling app template variables: %w", err)
+	}
+	secrets := template.Secrets
+	if secrets == nil {
+		secrets = []models.AppTemplateSecret{}
+	}
+	secretsJSON, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("marshaling app template secrets: %w", err)
+	}
+
+	query := `
+		INSERT INTO app_templates (name, title, description, variables, secrets, spec, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		template.Name, template.Title, template.Description, variablesJSON, secretsJSON, template.Spec,
+		nullString(template.CreatedBy), template.CreatedAt, template.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting app template: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting app template: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an app template by name. It returns nil if the template does not exist.
+func (s *AppTemplateStore) Get(ctx context.Context, name string) (*models.AppTemplate, error) {
+	query, args := newSelect(appTemplateColumns, "app_templates").Where("name = ?", name).Build()
+
+	template, err := scanAppTemplate(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying app template: %w", err)
+	}
+	return template, nil
+}
+
+// List retrieves all app templates ordered by name.
+func (s *AppTemplateStore) List(ctx context.Context) ([]*models.AppTemplate, error) {
+	q := newSelect(appTemplateColumns, "app_templates").OrderBy("name ASC")
+	return listRows(ctx, s.conn(), "app template", q, scanAppTemplate)
+}
+
+// Delete removes an app template.
+func (s *AppTemplateStore) Delete(ctx context.Context, name string) error {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM app_templates WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("deleting app template: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanAppTemplate reads a single app template row selected with appTemplateColumns.
+func scanAppTemplate(row rowScanner) (*models.AppTemplate, error) {
+	var t models.AppTemplate
+	var variablesJSON, secretsJSON []byte
+	if err := row.Scan(
+		&t.Name, &t.Title, &t.Description, &variablesJSON, &secretsJSON, &t.Spec, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variablesJSON, &t.Variables); err != nil {
+		return nil, fmt.Errorf("unmarshaling app template variables: %w", err)
+	}
+	if err := json.Unmarshal(secretsJSON, &t.Secrets); err != nil {
+		return nil, fmt.Errorf("unmarshaling app template secrets: %w", err)
+	}
+	return &t, nil
+}
@@ -17,14 +17,12 @@ type AppStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *AppStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new application.
@@ -33,10 +31,14 @@ func (s *AppStore) Create(ctx context.Context, app *models.App) error {
 	if err != nil {
 		return fmt.Errorf("marshaling services: %w", err)
 	}
+	envJSON, err := marshalAppEnv(app.EnvVars)
+	if err != nil {
+		return err
+	}
 
 	query := `
-		INSERT INTO apps (id, org_id, owner_id, name, description, icon_url, services, version, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO apps (id, org_id, owner_id, name, description, icon_url, services, env_vars, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, version, created_at, updated_at`
 
 	now := time.Now().UTC()
@@ -56,86 +58,96 @@ func (s *AppStore) Create(ctx context.Context, app *models.App) error {
 		orgID = app.OrgID
 	}
 
-	err = s.conn().QueryRowContext(ctx, query,
-		app.ID,
-		orgID,
-		app.OwnerID,
-		app.Name,
-		app.Description,
-		app.IconURL,
-		servicesJSON,
-		app.Version,
-		app.CreatedAt,
-		app.UpdatedAt,
-	).Scan(&app.ID, &app.Version, &app.CreatedAt, &app.UpdatedAt)
+	return s.withTx(ctx, func(conn queryable) error {
+		err := conn.QueryRowContext(ctx, query,
+			app.ID,
+			orgID,
+			app.OwnerID,
+			app.Name,
+			app.Description,
+			app.IconURL,
+			servicesJSON,
+			envJSON,
+			app.Version,
+			app.CreatedAt,
+			app.UpdatedAt,
+		).Scan(&app.ID, &app.Version, &app.CreatedAt, &app.UpdatedAt)
 
-	if err != nil {
-		if isUniqueViolation(err) {
-			return ErrDuplicateName
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateName
+			}
+			return fmt.Errorf("inserting app: %w", err)
 		}
-		return fmt.Errorf("inserting app: %w", err)
-	}
 
-	return nil
+		return syncServices(ctx, conn, app.ID, app.Services)
+	})
 }
 
+// appColumns lists the columns read by scanApp.
+const appColumns = `id, COALESCE(org_id::text, ''), owner_id, name, COALESCE(description, ''), COALESCE(icon_url, ''), ` + servicesColumn + `,
+	env_vars, version, created_at, updated_at, deleted_at`
+
 // Get retrieves an application by ID.
 func (s *AppStore) Get(ctx context.Context, id string) (*models.App, error) {
-	query := `
-		SELECT id, COALESCE(org_id::text, ''), owner_id, name, COALESCE(description, ''), COALESCE(icon_url, ''), services, 
-		       version, created_at, updated_at, deleted_at
-		FROM apps
-		WHERE id = $1 AND deleted_at IS NULL`
-
-	app := &models.App{}
-	var servicesJSON []byte
-	var deletedAt sql.NullTime
-
-	err := s.conn().QueryRowContext(ctx, query, id).Scan(
-		&app.ID,
-		&app.OrgID,
-		&app.OwnerID,
-		&app.Name,
-		&app.Description,
-		&app.IconURL,
-		&servicesJSON,
-		&app.Version,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-		&deletedAt,
-	)
+	query, args := newSelect(appColumns, "apps").Where("id = ?", id).NotDeleted("").Build()
 
+	app, err := scanApp(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying app: %w", err)
 	}
+	return app, nil
+}
 
-	if err := json.Unmarshal(servicesJSON, &app.Services); err != nil {
-		return nil, fmt.Errorf("unmarshaling services: %w", err)
-	}
+// GetByName retrieves an application by owner ID and name.
+func (s *AppStore) GetByName(ctx context.Context, ownerID, name string) (*models.App, error) {
+	query, args := newSelect(appColumns, "apps").
+		Where("owner_id = ? AND name = ?", ownerID, name).
+		NotDeleted("").
+		Build()
 
-	if deletedAt.Valid {
-		app.DeletedAt = &deletedAt.Time
+	app, err := scanApp(s.conn().QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying app by name: %w", err)
 	}
-
 	return app, nil
 }
 
-// GetByName retrieves an application by owner ID and name.
-func (s *AppStore) GetByName(ctx context.Context, ownerID, name string) (*models.App, error) {
-	query := `
-		SELECT id, COALESCE(org_id::text, ''), owner_id, name, COALESCE(description, ''), COALESCE(icon_url, ''), services, 
-		       version, created_at, updated_at, deleted_at
-		FROM apps
-		WHERE owner_id = $1 AND name = $2 AND deleted_at IS NULL`
+// List retrieves all applications for a given owner.
+func (s *AppStore) List(ctx context.Context, ownerID string) ([]*models.App, error) {
+	return s.list(ctx, newSelect(appColumns, "apps").Where("owner_id = ?", ownerID))
+}
+
+// ListByOrg retrieves all applications for a given organization.
+// Excludes soft-deleted apps.
+func (s *AppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.App, error) {
+	return s.list(ctx, newSelect(appColumns, "apps").Where("org_id = ?", orgID))
+}
+
+// ListAll retrieves every application on the instance.
+// Excludes soft-deleted apps.
+func (s *AppStore) ListAll(ctx context.Context) ([]*models.App, error) {
+	return s.list(ctx, newSelect(appColumns, "apps"))
+}
+
+// list runs an app query, excluding soft-deleted apps, newest first.
+func (s *AppStore) list(ctx context.Context, q *selectQuery) ([]*models.App, error) {
+	return listRows(ctx, s.conn(), "app", q.NotDeleted("").OrderBy("created_at DESC"), scanApp)
+}
 
+// scanApp reads a single app row selected with appColumns.
+func scanApp(row rowScanner) (*models.App, error) {
 	app := &models.App{}
-	var servicesJSON []byte
+	var servicesJSON, envJSON []byte
 	var deletedAt sql.NullTime
 
-	err := s.conn().QueryRowContext(ctx, query, ownerID, name).Scan(
+	err := row.Scan(
 		&app.ID,
 		&app.OrgID,
 		&app.OwnerID,
@@ -143,22 +155,22 @@ func (s *AppStore) GetByName(ctx context.Context, ownerID, name string) (*models
 		&app.Description,
 		&app.IconURL,
 		&servicesJSON,
+		&envJSON,
 		&app.Version,
 		&app.CreatedAt,
 		&app.UpdatedAt,
 		&deletedAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNotFound
-		}
-		return nil, fmt.Errorf("querying app by name: %w", err)
+		return nil, err
 	}
 
 	if err := json.Unmarshal(servicesJSON, &app.Services); err != nil {
 		return nil, fmt.Errorf("unmarshaling services: %w", err)
 	}
+	if err := json.Unmarshal(envJSON, &app.EnvVars); err != nil {
+		return nil, fmt.Errorf("unmarshaling env vars: %w", err)
+	}
 
 	if deletedAt.Valid {
 		app.DeletedAt = &deletedAt.Time
@@ -167,174 +179,115 @@ func (s *AppStore) GetByName(ctx context.Context, ownerID, name string) (*models
 	return app, nil
 }
 
-// List retrieves all applications for a given owner.
-func (s *AppStore) List(ctx context.Context, ownerID string) ([]*models.App, error) {
-	query := `
-		SELECT id, COALESCE(org_id::text, ''), owner_id, name, COALESCE(description, ''), COALESCE(icon_url, ''), services, 
-		       version, created_at, updated_at, deleted_at
-		FROM apps
-		WHERE owner_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, ownerID)
+// Update updates an existing application with optimistic locking.
+// Returns ErrConcurrentModification if the version doesn't match.
+func (s *AppStore) Update(ctx context.Context, app *models.App) error {
+	servicesJSON, err := json.Marshal(app.Services)
 	if err != nil {
-		return nil, fmt.Errorf("querying apps: %w", err)
-	}
-	defer rows.Close()
-
-	var apps []*models.App
-	for rows.Next() {
-		app := &models.App{}
-		var servicesJSON []byte
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&app.ID,
-			&app.OrgID,
-			&app.OwnerID,
-			&app.Name,
-			&app.Description,
-			&app.IconURL,
-			&servicesJSON,
-			&app.Version,
-			&app.CreatedAt,
-			&app.UpdatedAt,
-			&deletedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scanning app row: %w", err)
-		}
-
-		if err := json.Unmarshal(servicesJSON, &app.Services); err != nil {
-			return nil, fmt.Errorf("unmarshaling services: %w", err)
-		}
-
-		if deletedAt.Valid {
-			app.DeletedAt = &deletedAt.Time
-		}
-
-		apps = append(apps, app)
+		return fmt.Errorf("marshaling services: %w", err)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating app rows: %w", err)
+	envJSON, err := marshalAppEnv(app.EnvVars)
+	if err != nil {
+		return err
 	}
 
-	return apps, nil
-}
-
-// ListByOrg retrieves all applications for a given organization.
-// Excludes soft-deleted apps.
-func (s *AppStore) ListByOrg(ctx context.Context, orgID string) ([]*models.App, error) {
+	// Use optimistic locking: check version and increment on success
 	query := `
-		SELECT id, COALESCE(org_id::text, ''), owner_id, name, COALESCE(description, ''), COALESCE(icon_url, ''), services, 
-		       version, created_at, updated_at, deleted_at
-		FROM apps
-		WHERE org_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC`
+		UPDATE apps
+		SET name = $2, description = $3, icon_url = $4, services = $5, env_vars = $8,
+		    version = version + 1, updated_at = $6
+		WHERE id = $1 AND version = $7 AND deleted_at IS NULL`
 
-	rows, err := s.conn().QueryContext(ctx, query, orgID)
-	if err != nil {
-		return nil, fmt.Errorf("querying apps by org: %w", err)
-	}
-	defer rows.Close()
-
-	var apps []*models.App
-	for rows.Next() {
-		app := &models.App{}
-		var servicesJSON []byte
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&app.ID,
-			&app.OrgID,
-			&app.OwnerID,
-			&app.Name,
-			&app.Description,
-			&app.IconURL,
-			&servicesJSON,
-			&app.Version,
-			&app.CreatedAt,
-			&app.UpdatedAt,
-			&deletedAt,
+	app.UpdatedAt = time.Now().UTC()
+
+	err = s.withTx(ctx, func(conn queryable) error {
+		result, err := conn.ExecContext(ctx, query,
+			app.ID,
+			app.Name,
+			app.Description,
+			app.IconURL,
+			servicesJSON,
+			app.UpdatedAt,
+			app.Version,
+			envJSON,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning app row: %w", err)
+			if isUniqueViolation(err) {
+				return ErrDuplicateName
+			}
+			return fmt.Errorf("updating app: %w", err)
 		}
 
-		if err := json.Unmarshal(servicesJSON, &app.Services); err != nil {
-			return nil, fmt.Errorf("unmarshaling services: %w", err)
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("getting rows affected: %w", err)
 		}
 
-		if deletedAt.Valid {
-			app.DeletedAt = &deletedAt.Time
+		if rowsAffected == 0 {
+			// Check if the app exists to distinguish between not found and version mismatch
+			var exists bool
+			checkQuery := `SELECT EXISTS(SELECT 1 FROM apps WHERE id = $1 AND deleted_at IS NULL)`
+			if err := conn.QueryRowContext(ctx, checkQuery, app.ID).Scan(&exists); err != nil {
+				return fmt.Errorf("checking app existence: %w", err)
+			}
+			if !exists {
+				return ErrNotFound
+			}
+			// App exists but version didn't match - concurrent modification
+			return ErrConcurrentModification
 		}
 
-		apps = append(apps, app)
+		return syncServices(ctx, conn, app.ID, app.Services)
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating app rows: %w", err)
-	}
+	// Increment the version in the app struct to reflect the new state
+	app.Version++
 
-	return apps, nil
+	return nil
 }
 
-// Update updates an existing application with optimistic locking.
-// Returns ErrConcurrentModification if the version doesn't match.
-func (s *AppStore) Update(ctx context.Context, app *models.App) error {
-	servicesJSON, err := json.Marshal(app.Services)
+// marshalAppEnv encodes app-level env vars, storing nil as an empty object.
+func marshalAppEnv(envVars map[string]string) ([]byte, error) {
+	if envVars == nil {
+		envVars = map[string]string{}
+	}
+	data, err := json.Marshal(envVars)
 	if err != nil {
-		return fmt.Errorf("marshaling services: %w", err)
+		return nil, fmt.Errorf("marshaling env vars: %w", err)
 	}
+	return data, nil
+}
 
-	// Use optimistic locking: check version and increment on success
-	query := `
-		UPDATE apps
-		SET name = $2, description = $3, icon_url = $4, services = $5, 
-		    version = version + 1, updated_at = $6
-		WHERE id = $1 AND version = $7 AND deleted_at IS NULL`
-
-	app.UpdatedAt = time.Now().UTC()
+// withTx runs fn in the store's transaction, or in a new one when the store
+// is not transaction-scoped, so the app row and its services change together.
+func (s *AppStore) withTx(ctx context.Context, fn func(conn queryable) error) error {
+	return withConnTx(ctx, s.db, s.tx, s.stmts, s.logger, fn)
+}
 
-	result, err := s.conn().ExecContext(ctx, query,
-		app.ID,
-		app.Name,
-		app.Description,
-		app.IconURL,
-		servicesJSON,
-		app.UpdatedAt,
-		app.Version,
-	)
-	if err != nil {
-		if isUniqueViolation(err) {
-			return ErrDuplicateName
-		}
-		return fmt.Errorf("updating app: %w", err)
+// withConnTx runs fn in tx, or in a new transaction on db when tx is nil.
+func withConnTx(ctx context.Context, db *sql.DB, tx *sql.Tx, stmts *stmtCache, logger *slog.Logger, fn func(conn queryable) error) error {
+	if tx != nil {
+		return fn(stmts.conn(db, tx))
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("getting rows affected: %w", err)
+		return fmt.Errorf("beginning transaction: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		// Check if the app exists to distinguish between not found and version mismatch
-		var exists bool
-		checkQuery := `SELECT EXISTS(SELECT 1 FROM apps WHERE id = $1 AND deleted_at IS NULL)`
-		if err := s.conn().QueryRowContext(ctx, checkQuery, app.ID).Scan(&exists); err != nil {
-			return fmt.Errorf("checking app existence: %w", err)
-		}
-		if !exists {
-			return ErrNotFound
+	if err := fn(stmts.conn(db, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logger.Error("failed to rollback transaction", "error", rbErr)
 		}
-		// App exists but version didn't match - concurrent modification
-		return ErrConcurrentModification
+		return err
 	}
 
-	// Increment the version in the app struct to reflect the new state
-	app.Version++
-
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
@@ -67,6 +67,7 @@ func runMigrations(db *sql.DB) error {
 	_, _ = db.Exec("DROP TABLE IF EXISTS builds CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS deployments CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS nodes CASCADE")
+	_, _ = db.Exec("DROP TABLE IF EXISTS services CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS apps CASCADE")
 
 	schema := `
@@ -91,6 +92,18 @@ func runMigrations(db *sql.DB) error {
 		CREATE UNIQUE INDEX apps_org_name_unique ON apps(org_id, name) WHERE deleted_at IS NULL;
 		CREATE INDEX idx_apps_owner_id ON apps(owner_id) WHERE deleted_at IS NULL;
 		CREATE INDEX idx_apps_org_id ON apps(org_id) WHERE deleted_at IS NULL;
+
+		CREATE TABLE services (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+			name VARCHAR(63) NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			config JSONB NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CONSTRAINT services_app_name_unique UNIQUE (app_id, name)
+		);
 	`
 	_, err := db.Exec(schema)
 	return err
@@ -626,6 +639,86 @@ func TestAppOptimisticLockingConflictDetection(t *testing.T) {
 	properties.TestingRun(t)
 }
 
+// TestServiceVersions checks that services are versioned on their own rows:
+// updates to different services of an app do not conflict, while a stale
+// update of the same service does.
+func TestServiceVersions(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(t, db)
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	apps := &AppStore{db: db, logger: logger}
+	services := &ServiceStore{db: db, logger: logger}
+
+	app := &models.App{
+		ID:      uuid.New().String(),
+		OwnerID: "user-1",
+		Name:    "versions",
+		Services: []models.ServiceConfig{
+			{Name: "web", SourceType: models.SourceTypeImage, Image: "nginx", Replicas: 1},
+			{Name: "worker", SourceType: models.SourceTypeImage, Image: "busybox", Replicas: 1},
+		},
+	}
+	if err := apps.Create(ctx, app); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	snapshot, err := apps.Get(ctx, app.ID)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+
+	// Both services are changed from the same snapshot of the app
+	web, worker := snapshot.Services[0], snapshot.Services[1]
+	web.Replicas, worker.Replicas = 2, 3
+	if err := services.Update(ctx, app.ID, &web); err != nil {
+		t.Fatalf("Update(web) = %v", err)
+	}
+	if err := services.Update(ctx, app.ID, &worker); err != nil {
+		t.Fatalf("Update(worker) = %v", err)
+	}
+	if web.Version != 2 || worker.Version != 2 {
+		t.Errorf("versions = %d, %d, want 2, 2", web.Version, worker.Version)
+	}
+
+	stale := snapshot.Services[0]
+	stale.Replicas = 5
+	if err := services.Update(ctx, app.ID, &stale); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("stale Update(web) = %v, want ErrConcurrentModification", err)
+	}
+	missing := models.ServiceConfig{Name: "db", Version: 1}
+	if err := services.Update(ctx, app.ID, &missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(db) = %v, want ErrNotFound", err)
+	}
+
+	// Adding a service changes the app's services, and so its version
+	api := models.ServiceConfig{Name: "api", SourceType: models.SourceTypeImage, Image: "caddy", Replicas: 1}
+	if err := services.Create(ctx, app.ID, &api); err != nil {
+		t.Fatalf("Create(api) = %v", err)
+	}
+	if err := services.Create(ctx, app.ID, &api); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("Create(api) again = %v, want ErrDuplicateName", err)
+	}
+
+	got, err := apps.Get(ctx, app.ID)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got.Version != snapshot.Version+1 || len(got.Services) != 3 || got.Services[2].Name != "api" {
+		t.Fatalf("app = version %d, services %+v", got.Version, got.Services)
+	}
+	if got.Services[0].Replicas != 2 || got.Services[1].Replicas != 3 {
+		t.Errorf("replicas = %d, %d, want 2, 3", got.Services[0].Replicas, got.Services[1].Replicas)
+	}
+
+	if err := services.Delete(ctx, app.ID, "api"); err != nil {
+		t.Fatalf("Delete(api) = %v", err)
+	}
+	if _, err := services.Get(ctx, app.ID, "api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(api) after delete = %v, want ErrNotFound", err)
+	}
+}
+
 // **Feature: backend-source-of-truth, Property 3: App Organization Filtering**
 // *For any* organization with apps, listing apps SHALL return only apps
 // where org_id matches the current organization context.
@@ -1039,7 +1132,11 @@ func TestTransactionRollbackOnFailure(t *testing.T) {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
-			finished_at TIMESTAMPTZ
+			finished_at TIMESTAMPTZ,
+			rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL,
+			canary JSONB,
+			health JSONB,
+			error TEXT NOT NULL DEFAULT ''
 		);
 	`)
 	if err != nil {
@@ -1223,7 +1320,11 @@ func TestTransactionRollbackOnAppDeletion(t *testing.T) {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
-			finished_at TIMESTAMPTZ
+			finished_at TIMESTAMPTZ,
+			rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL,
+			canary JSONB,
+			health JSONB,
+			error TEXT NOT NULL DEFAULT ''
 		);
 	`)
 	if err != nil {
@@ -1393,7 +1494,11 @@ func TestTransactionRollbackOnServiceRename(t *testing.T) {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
-			finished_at TIMESTAMPTZ
+			finished_at TIMESTAMPTZ,
+			rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL,
+			canary JSONB,
+			health JSONB,
+			error TEXT NOT NULL DEFAULT ''
 		);
 	`)
 	if err != nil {
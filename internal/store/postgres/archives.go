@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ArchiveStore implements store.ArchiveStore using PostgreSQL.
+type ArchiveStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *ArchiveStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const archiveColumns = `id, deployment_id, build_id, app_id, object_key, size_bytes, original_bytes,
+	status, error, archived_at, restore_requested_at`
+
+// Create records an archived deployment.
+func (s *ArchiveStore) Create(ctx context.Context, archive *models.Archive) error {
+	if archive.ID == "" {
+		archive.ID = uuid.New().String()
+	}
+	if archive.ArchivedAt.IsZero() {
+		archive.ArchivedAt = time.Now()
+	}
+	if archive.Status == "" {
+		archive.Status = models.ArchiveStatusArchived
+	}
+
+	query := `
+		INSERT INTO archives (id, deployment_id, build_id, app_id, object_key, size_bytes, original_bytes,
+			status, archived_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		archive.ID, archive.DeploymentID, nullString(archive.BuildID), archive.AppID, archive.ObjectKey,
+		archive.SizeBytes, archive.OriginalBytes, archive.Status, archive.ArchivedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	return nil
+}
+
+// GetByDeployment retrieves a deployment's archive. It returns nil if the
+// deployment is not archived.
+func (s *ArchiveStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.Archive, error) {
+	query, args := newSelect(archiveColumns, "archives").Where("deployment_id = ?", deploymentID).Build()
+	archive, err := scanArchive(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying archive: %w", err)
+	}
+	return archive, nil
+}
+
+// ListCandidates retrieves up to limit stopped, failed or canceled
+// deployments last updated before the given time that are not archived and
+// have at least keep newer deployments of the same service, oldest first.
+func (s *ArchiveStore) ListCandidates(ctx context.Context, before time.Time, keep, limit int) ([]string, error) {
+	query := `
+		SELECT d.id FROM deployments d
+		WHERE d.status IN ($1, $2, $3) AND d.updated_at < $4
+			AND NOT EXISTS (SELECT 1 FROM archives a WHERE a.deployment_id = d.id)
+			AND (
+				SELECT COUNT(*) FROM deployments n
+				WHERE n.app_id = d.app_id AND n.service_name = d.service_name AND n.version > d.version
+			) >= $5
+		ORDER BY d.updated_at
+		LIMIT $6
+	`
+	rows, err := s.conn().QueryContext(ctx, query,
+		models.DeploymentStatusStopped, models.DeploymentStatusFailed, models.DeploymentStatusCanceled, before, keep, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying archive candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning archive candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating archive candidates: %w", err)
+	}
+	return ids, nil
+}
+
+// ClaimRestore marks an archive as restoring unless another restore of it
+// started after staleBefore. The first claim sets the restore request time.
+// It returns false if the archive is being restored or does not exist.
+func (s *ArchiveStore) ClaimRestore(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE archives
+		SET status = $2, restore_started_at = NOW(), restore_requested_at = COALESCE(restore_requested_at, NOW())
+		WHERE id = $1 AND (status <> $2 OR restore_started_at < $3)
+	`
+	res, err := s.conn().ExecContext(ctx, query, id, models.ArchiveStatusRestoring, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("claiming archive restore: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("checking claimed archive: %w", err)
+	}
+	return n > 0, nil
+}
+
+// FailRestore marks a restore as failed so it is retried.
+func (s *ArchiveStore) FailRestore(ctx context.Context, id, message string) error {
+	query := `UPDATE archives SET status = $2, error = $3 WHERE id = $1`
+	if _, err := s.conn().ExecContext(ctx, query, id, models.ArchiveStatusRestoreFailed, message); err != nil {
+		return fmt.Errorf("failing archive restore: %w", err)
+	}
+	return nil
+}
+
+// ListRestoreQueue retrieves the archives being or waiting to be restored,
+// oldest request first.
+func (s *ArchiveStore) ListRestoreQueue(ctx context.Context) ([]*models.Archive, error) {
+	q := newSelect(archiveColumns, "archives").
+		Where("status <> ?", models.ArchiveStatusArchived).
+		OrderBy("restore_requested_at")
+	return listRows(ctx, s.conn(), "archive", q, scanArchive)
+}
+
+// Delete removes an archive record once it is restored.
+func (s *ArchiveStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM archives WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("deleting archive: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the number and size of archives.
+func (s *ArchiveStore) Stats(ctx context.Context) (*models.ArchiveStats, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(size_bytes), 0), COALESCE(SUM(original_bytes), 0) FROM archives`
+	var stats models.ArchiveStats
+	if err := s.conn().QueryRowContext(ctx, query).Scan(&stats.Archives, &stats.SizeBytes, &stats.OriginalBytes); err != nil {
+		return nil, fmt.Errorf("querying archive stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// scanArchive reads a single archive row.
+func scanArchive(row rowScanner) (*models.Archive, error) {
+	var archive models.Archive
+	var buildID sql.NullString
+	var requestedAt sql.NullTime
+	err := row.Scan(
+		&archive.ID, &archive.DeploymentID, &buildID, &archive.AppID, &archive.ObjectKey, &archive.SizeBytes,
+		&archive.OriginalBytes, &archive.Status, &archive.Error, &archive.ArchivedAt, &requestedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	archive.BuildID = buildID.String
+	if requestedAt.Valid {
+		archive.RestoreRequestedAt = &requestedAt.Time
+	}
+	return &archive, nil
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AuditStore implements store.AuditStore using PostgreSQL.
+type AuditStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AuditStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Record stores an audit entry.
+func (s *AuditStore) Record(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	changesJSON, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("marshaling audit changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (id, user_id, user_email, api_key_id, action, resource_type, resource_id, app_id,
+			path, status, changes, request_id, remote_addr, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		entry.ID, entry.UserID, entry.UserEmail, entry.APIKeyID, entry.Action, entry.ResourceType, entry.ResourceID,
+		entry.AppID, entry.Path, entry.Status, changesJSON, entry.RequestID, entry.RemoteAddr, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditColumns lists the columns read by scanAuditEntry.
+const auditColumns = `id, user_id, user_email, api_key_id, action, resource_type, resource_id, app_id,
+	path, status, changes, request_id, remote_addr, created_at`
+
+// List retrieves the entries matching a filter, newest first.
+func (s *AuditStore) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	q := newSelect(auditColumns, "audit_log")
+	if filter.UserID != "" {
+		q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.APIKeyID != "" {
+		q.Where("api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.ResourceType != "" {
+		q.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		q.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.AppID != "" {
+		q.Where("app_id = ?", filter.AppID)
+	}
+	if !filter.Since.IsZero() {
+		q.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.Where("created_at < ?", filter.Until)
+	}
+	q.OrderBy("created_at DESC").Page(filter.Limit, 0)
+	return listRows(ctx, s.conn(), "audit entry", q, scanAuditEntry)
+}
+
+// DeleteBefore removes entries created before the given time.
+func (s *AuditStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM audit_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting audit entries: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return rows, nil
+}
+
+// scanAuditEntry reads a single audit entry row.
+func scanAuditEntry(row rowScanner) (*models.AuditEntry, error) {
+	var e models.AuditEntry
+	var changesJSON []byte
+	if err := row.Scan(
+		&e.ID, &e.UserID, &e.UserEmail, &e.APIKeyID, &e.Action, &e.ResourceType, &e.ResourceID, &e.AppID,
+		&e.Path, &e.Status, &changesJSON, &e.RequestID, &e.RemoteAddr, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(changesJSON) > 0 {
+		if err := json.Unmarshal(changesJSON, &e.Changes); err != nil {
+			return nil, fmt.Errorf("unmarshaling audit changes: %w", err)
+		}
+	}
+	return &e, nil
+}
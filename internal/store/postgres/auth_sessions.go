@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// AuthSessionStore implements store.AuthSessionStore using PostgreSQL.
+type AuthSessionStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *AuthSessionStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const authSessionColumns = `id, user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at,
+	expires_at, revoked_at`
+
+// Create stores a new session.
+func (s *AuthSessionStore) Create(ctx context.Context, session *models.AuthSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = session.CreatedAt
+	}
+
+	query := `
+		INSERT INTO auth_sessions (id, user_id, refresh_token_hash, user_agent, ip_address, created_at,
+			last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		session.ID, session.UserID, session.RefreshTokenHash, session.UserAgent, session.IPAddress,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating auth session: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a session by ID. It returns nil if it does not exist.
+func (s *AuthSessionStore) Get(ctx context.Context, id string) (*models.AuthSession, error) {
+	return s.getWhere(ctx, "id = ?", id)
+}
+
+// GetByRefreshHash retrieves the session holding a refresh token by the
+// token's hash. It returns nil if no session matches.
+func (s *AuthSessionStore) GetByRefreshHash(ctx context.Context, hash string) (*models.AuthSession, error) {
+	return s.getWhere(ctx, "refresh_token_hash = ?", hash)
+}
+
+func (s *AuthSessionStore) getWhere(ctx context.Context, cond string, arg any) (*models.AuthSession, error) {
+	query, args := newSelect(authSessionColumns, "auth_sessions").Where(cond, arg).Build()
+	session, err := scanAuthSession(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying auth session: %w", err)
+	}
+	return session, nil
+}
+
+// Rotate replaces an unrevoked session's refresh token hash if it still is
+// oldHash, and records its use. It returns false if another refresh rotated
+// it first or the session was revoked.
+func (s *AuthSessionStore) Rotate(ctx context.Context, id, oldHash, newHash string, usedAt, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE auth_sessions
+		SET refresh_token_hash = $3, last_used_at = $4, expires_at = $5
+		WHERE id = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL
+	`
+	result, err := s.conn().ExecContext(ctx, query, id, oldHash, newHash, usedAt, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("rotating auth session: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rotating auth session: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ListActiveByUser retrieves a user's unrevoked and unexpired sessions, most
+// recently used first.
+func (s *AuthSessionStore) ListActiveByUser(ctx context.Context, userID string) ([]*models.AuthSession, error) {
+	q := newSelect(authSessionColumns, "auth_sessions").
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > NOW()").
+		OrderBy("last_used_at DESC")
+	return listRows(ctx, s.conn(), "auth session", q, scanAuthSession)
+}
+
+// Revoke revokes a session. Revoking a revoked session changes nothing.
+func (s *AuthSessionStore) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := s.conn().ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("revoking auth session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllByUser revokes every session of a user but exceptID, and returns
+// how many it revoked.
+func (s *AuthSessionStore) RevokeAllByUser(ctx context.Context, userID, exceptID string) (int64, error) {
+	query := `
+		UPDATE auth_sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id::text <> $2 AND revoked_at IS NULL
+	`
+	result, err := s.conn().ExecContext(ctx, query, userID, exceptID)
+	if err != nil {
+		return 0, fmt.Errorf("revoking auth sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// scanAuthSession reads a single auth session row.
+func scanAuthSession(row rowScanner) (*models.AuthSession, error) {
+	var session models.AuthSession
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.RefreshTokenHash, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
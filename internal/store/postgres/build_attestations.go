@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// BuildAttestationStore implements store.BuildAttestationStore using PostgreSQL.
+type BuildAttestationStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *BuildAttestationStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const buildAttestationColumns = `build_id, worker_id, key_id, public_key, envelope, created_at`
+
+// Save records a build's attestation, replacing any earlier one of the build.
+func (s *BuildAttestationStore) Save(ctx context.Context, attestation *models.BuildAttestation) error {
+	if attestation.CreatedAt.IsZero() {
+		attestation.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO build_attestations (build_id, worker_id, key_id, public_key, envelope, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (build_id) DO UPDATE SET
+			worker_id = EXCLUDED.worker_id,
+			key_id = EXCLUDED.key_id,
+			public_key = EXCLUDED.public_key,
+			envelope = EXCLUDED.envelope,
+			created_at = EXCLUDED.created_at
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		attestation.BuildID, attestation.WorkerID, attestation.KeyID, attestation.PublicKey,
+		[]byte(attestation.Envelope), attestation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("saving build attestation: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a build's attestation. It returns nil if the build has none.
+func (s *BuildAttestationStore) Get(ctx context.Context, buildID string) (*models.BuildAttestation, error) {
+	query, args := newSelect(buildAttestationColumns, "build_attestations").Where("build_id = ?", buildID).Build()
+
+	var attestation models.BuildAttestation
+	var envelope []byte
+	err := s.conn().QueryRowContext(ctx, query, args...).Scan(
+		&attestation.BuildID, &attestation.WorkerID, &attestation.KeyID, &attestation.PublicKey,
+		&envelope, &attestation.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying build attestation: %w", err)
+	}
+	attestation.Envelope = envelope
+	return &attestation, nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// BuildLogStore implements store.BuildLogStore using PostgreSQL.
+type BuildLogStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *BuildLogStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Append stores the next chunk of a build's output and sets its Seq and
+// CreatedAt. Only the worker holding the build's queue lease writes its
+// output, so numbering from the current maximum does not race.
+func (s *BuildLogStore) Append(ctx context.Context, chunk *models.BuildLogChunk) error {
+	query := `
+		INSERT INTO build_log_chunks (build_id, seq, content)
+		SELECT $1, COALESCE(MAX(seq), 0) + 1, $2 FROM build_log_chunks WHERE build_id = $1
+		RETURNING seq, created_at`
+	err := s.conn().QueryRowContext(ctx, query, chunk.BuildID, chunk.Content).Scan(&chunk.Seq, &chunk.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("appending build log chunk: %w", err)
+	}
+	return nil
+}
+
+// List retrieves a build's chunks with a Seq greater than afterSeq, oldest first.
+func (s *BuildLogStore) List(ctx context.Context, buildID string, afterSeq, limit int) ([]*models.BuildLogChunk, error) {
+	q := newSelect("build_id, seq, content, created_at", "build_log_chunks").
+		Where("build_id = ?", buildID).
+		Where("seq > ?", afterSeq).
+		OrderBy("seq").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "build log chunk", q, scanBuildLogChunk)
+}
+
+// Restore stores archived chunks with their original Seq and CreatedAt.
+// Columns are sent as arrays and expanded with unnest, like LogStore.CreateBatch.
+func (s *BuildLogStore) Restore(ctx context.Context, chunks []*models.BuildLogChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	buildIDs := make([]string, len(chunks))
+	seqs := make([]int64, len(chunks))
+	contents := make([]string, len(chunks))
+	createdAts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		buildIDs[i] = chunk.BuildID
+		seqs[i] = int64(chunk.Seq)
+		contents[i] = chunk.Content
+		createdAts[i] = chunk.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := `
+		INSERT INTO build_log_chunks (build_id, seq, content, created_at)
+		SELECT * FROM unnest($1::uuid[], $2::int[], $3::text[], $4::timestamptz[])
+		ON CONFLICT DO NOTHING`
+	_, err := s.conn().ExecContext(ctx, query, pq.Array(buildIDs), pq.Array(seqs), pq.Array(contents), pq.Array(createdAts))
+	if err != nil {
+		return fmt.Errorf("restoring build log chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteByBuild removes all of a build's chunks.
+func (s *BuildLogStore) DeleteByBuild(ctx context.Context, buildID string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM build_log_chunks WHERE build_id = $1`, buildID)
+	if err != nil {
+		return fmt.Errorf("deleting build log chunks: %w", err)
+	}
+	return nil
+}
+
+// scanBuildLogChunk reads a single build log chunk row.
+func scanBuildLogChunk(row rowScanner) (*models.BuildLogChunk, error) {
+	var c models.BuildLogChunk
+	if err := row.Scan(&c.BuildID, &c.Seq, &c.Content, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
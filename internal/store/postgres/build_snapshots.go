@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// BuildSnapshotStore implements store.BuildSnapshotStore using PostgreSQL.
+type BuildSnapshotStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *BuildSnapshotStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const buildSnapshotColumns = `build_id, worker_id, hostname, path, image, shell, work_dir, flake_ref, env, created_at, expires_at`
+
+// Save records a build's snapshot, replacing any earlier one of the build.
+func (s *BuildSnapshotStore) Save(ctx context.Context, snapshot *models.BuildSnapshot) error {
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+	env := snapshot.Env
+	if env == nil {
+		env = map[string]string{}
+	}
+	envJSON, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshaling snapshot env: %w", err)
+	}
+
+	query := `
+		INSERT INTO build_snapshots (build_id, worker_id, hostname, path, image, shell, work_dir, flake_ref, env, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (build_id) DO UPDATE SET
+			worker_id = EXCLUDED.worker_id,
+			hostname = EXCLUDED.hostname,
+			path = EXCLUDED.path,
+			image = EXCLUDED.image,
+			shell = EXCLUDED.shell,
+			work_dir = EXCLUDED.work_dir,
+			flake_ref = EXCLUDED.flake_ref,
+			env = EXCLUDED.env,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		snapshot.BuildID, snapshot.WorkerID, snapshot.Hostname, snapshot.Path, snapshot.Image,
+		snapshot.Shell, snapshot.WorkDir, snapshot.FlakeRef, envJSON, snapshot.CreatedAt, snapshot.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("saving build snapshot: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a build's snapshot. It returns nil if the build has none.
+func (s *BuildSnapshotStore) Get(ctx context.Context, buildID string) (*models.BuildSnapshot, error) {
+	query, args := newSelect(buildSnapshotColumns, "build_snapshots").Where("build_id = ?", buildID).Build()
+
+	snapshot, err := scanBuildSnapshot(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying build snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ListExpired retrieves the snapshots kept on a host that expired before the given time.
+func (s *BuildSnapshotStore) ListExpired(ctx context.Context, hostname string, before time.Time) ([]*models.BuildSnapshot, error) {
+	q := newSelect(buildSnapshotColumns, "build_snapshots").
+		Where("hostname = ?", hostname).
+		Where("expires_at <= ?", before).
+		OrderBy("expires_at")
+	return listRows(ctx, s.conn(), "build snapshot", q, scanBuildSnapshot)
+}
+
+// Delete removes a build's snapshot record.
+func (s *BuildSnapshotStore) Delete(ctx context.Context, buildID string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM build_snapshots WHERE build_id = $1`, buildID); err != nil {
+		return fmt.Errorf("deleting build snapshot: %w", err)
+	}
+	return nil
+}
+
+// scanBuildSnapshot reads a single build snapshot row.
+func scanBuildSnapshot(row rowScanner) (*models.BuildSnapshot, error) {
+	var snapshot models.BuildSnapshot
+	var envJSON []byte
+	err := row.Scan(
+		&snapshot.BuildID, &snapshot.WorkerID, &snapshot.Hostname, &snapshot.Path, &snapshot.Image,
+		&snapshot.Shell, &snapshot.WorkDir, &snapshot.FlakeRef, &envJSON, &snapshot.CreatedAt, &snapshot.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(envJSON, &snapshot.Env); err != nil {
+		return nil, fmt.Errorf("unmarshaling snapshot env: %w", err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// BuildWorkerStore implements store.BuildWorkerStore using PostgreSQL.
+type BuildWorkerStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *BuildWorkerStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// buildWorkerColumns lists the columns read by scanBuildWorker. claimed_jobs
+// collects the queue jobs the worker currently holds.
+const buildWorkerColumns = `id, hostname, version, concurrency, active_jobs, lease_seconds, podman_error,
+	started_at, last_heartbeat_at, stopped_at,
+	ARRAY(SELECT q.id::text FROM build_queue q WHERE q.claimed_by = build_workers.id AND q.status = 'processing' ORDER BY q.started_at) AS claimed_jobs`
+
+// Register records a starting worker, replacing any earlier registration with the same ID.
+func (s *BuildWorkerStore) Register(ctx context.Context, worker *models.BuildWorker) error {
+	now := time.Now()
+	if worker.StartedAt.IsZero() {
+		worker.StartedAt = now
+	}
+	worker.LastHeartbeatAt = now
+	worker.StoppedAt = nil
+
+	query := `
+		INSERT INTO build_workers (id, hostname, version, concurrency, active_jobs, lease_seconds, podman_error, started_at, last_heartbeat_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			hostname = EXCLUDED.hostname,
+			version = EXCLUDED.version,
+			concurrency = EXCLUDED.concurrency,
+			active_jobs = EXCLUDED.active_jobs,
+			lease_seconds = EXCLUDED.lease_seconds,
+			podman_error = EXCLUDED.podman_error,
+			started_at = EXCLUDED.started_at,
+			last_heartbeat_at = EXCLUDED.last_heartbeat_at,
+			stopped_at = NULL
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		worker.ID, worker.Hostname, worker.Version, worker.Concurrency, worker.ActiveJobs,
+		worker.LeaseSeconds, worker.PodmanError, worker.StartedAt, worker.LastHeartbeatAt,
+	)
+	if err != nil {
+		return fmt.Errorf("registering build worker: %w", err)
+	}
+	return nil
+}
+
+// Heartbeat records that a worker is alive and how many jobs it is running.
+func (s *BuildWorkerStore) Heartbeat(ctx context.Context, id string, activeJobs int) error {
+	result, err := s.conn().ExecContext(ctx,
+		`UPDATE build_workers SET last_heartbeat_at = NOW(), active_jobs = $2 WHERE id = $1`,
+		id, activeJobs,
+	)
+	if err != nil {
+		return fmt.Errorf("updating build worker heartbeat: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkStopped records that a worker shut down cleanly.
+func (s *BuildWorkerStore) MarkStopped(ctx context.Context, id string) error {
+	result, err := s.conn().ExecContext(ctx,
+		`UPDATE build_workers SET stopped_at = NOW(), active_jobs = 0 WHERE id = $1`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("marking build worker stopped: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List retrieves all registered workers with the queue jobs they hold, newest first.
+func (s *BuildWorkerStore) List(ctx context.Context) ([]*models.BuildWorker, error) {
+	q := newSelect(buildWorkerColumns, "build_workers").OrderBy("started_at DESC")
+	return listRows(ctx, s.conn(), "build worker", q, scanBuildWorker)
+}
+
+// DeleteStale removes workers whose last heartbeat is before the given time.
+func (s *BuildWorkerStore) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM build_workers WHERE last_heartbeat_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting stale build workers: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// scanBuildWorker reads a single build worker row selected with buildWorkerColumns.
+func scanBuildWorker(row rowScanner) (*models.BuildWorker, error) {
+	var w models.BuildWorker
+	var stoppedAt sql.NullTime
+	var claimed []string
+	if err := row.Scan(
+		&w.ID, &w.Hostname, &w.Version, &w.Concurrency, &w.ActiveJobs, &w.LeaseSeconds, &w.PodmanError,
+		&w.StartedAt, &w.LastHeartbeatAt, &stoppedAt, pq.Array(&claimed),
+	); err != nil {
+		return nil, err
+	}
+	if stoppedAt.Valid {
+		w.StoppedAt = &stoppedAt.Time
+	}
+	w.ClaimedJobs = claimed
+	if w.ClaimedJobs == nil {
+		w.ClaimedJobs = []string{}
+	}
+	return &w, nil
+}
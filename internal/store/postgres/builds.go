@@ -17,14 +17,12 @@ type BuildStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *BuildStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new build job.
@@ -33,8 +31,9 @@ func (s *BuildStore) Create(ctx context.Context, build *models.BuildJob) error {
 		INSERT INTO builds (id, deployment_id, app_id, git_url, git_ref, 
 			flake_output, build_type, status, created_at, started_at, finished_at,
 			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			generated_flake, flake_lock, vendor_hash, detection_result, detected_at,
+			artifact, external_metadata, build_path, priority, triggered_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
 		RETURNING id, created_at`
 
 	now := time.Now().UTC()
@@ -76,6 +75,21 @@ func (s *BuildStore) Create(ctx context.Context, build *models.BuildJob) error {
 		}
 	}
 
+	// Handle nullable artifact and external_metadata (JSONB), set for
+	// builds submitted with an externally built artifact
+	var artifact sql.NullString
+	if build.Artifact != "" {
+		artifact = sql.NullString{String: build.Artifact, Valid: true}
+	}
+	var externalMetadata []byte
+	if build.ExternalMetadata != nil {
+		var err error
+		externalMetadata, err = json.Marshal(build.ExternalMetadata)
+		if err != nil {
+			return fmt.Errorf("marshaling external metadata: %w", err)
+		}
+	}
+
 	err := s.conn().QueryRowContext(ctx, query,
 		build.ID,
 		build.DeploymentID,
@@ -97,6 +111,11 @@ func (s *BuildStore) Create(ctx context.Context, build *models.BuildJob) error {
 		vendorHash,
 		detectionResult,
 		build.DetectedAt,
+		artifact,
+		externalMetadata,
+		build.BuildPath,
+		build.Priority,
+		nullString(build.TriggeredBy),
 	).Scan(&build.ID, &build.CreatedAt)
 
 	if err != nil {
@@ -106,157 +125,44 @@ func (s *BuildStore) Create(ctx context.Context, build *models.BuildJob) error {
 	return nil
 }
 
+// buildColumns lists the columns read by scanBuild.
+const buildColumns = `id, deployment_id, app_id, git_url, git_ref,
+	flake_output, build_type, status, created_at, started_at, finished_at,
+	build_strategy, timeout_seconds, retry_count, retry_as_oci,
+	generated_flake, flake_lock, vendor_hash, detection_result, detected_at,
+	content_hash, artifact, deduplicated_from, reproducibility, output_hash,
+	external_metadata, cache_stats, build_path, failure, priority, triggered_by,
+	cancel_requested_at`
+
 // Get retrieves a build job by ID.
 func (s *BuildStore) Get(ctx context.Context, id string) (*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE id = $1`
-
-	build := &models.BuildJob{}
-	var startedAt, finishedAt, detectedAt sql.NullTime
-	var buildStrategy, generatedFlake, flakeLock, vendorHash sql.NullString
-	var detectionResultJSON []byte
-
-	err := s.conn().QueryRowContext(ctx, query, id).Scan(
-		&build.ID,
-		&build.DeploymentID,
-		&build.AppID,
-		&build.GitURL,
-		&build.GitRef,
-		&build.FlakeOutput,
-		&build.BuildType,
-		&build.Status,
-		&build.CreatedAt,
-		&startedAt,
-		&finishedAt,
-		&buildStrategy,
-		&build.TimeoutSeconds,
-		&build.RetryCount,
-		&build.RetryAsOCI,
-		&generatedFlake,
-		&flakeLock,
-		&vendorHash,
-		&detectionResultJSON,
-		&detectedAt,
-	)
+	query, args := newSelect(buildColumns, "builds").Where("id = ?", id).Build()
 
+	build, err := scanBuild(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying build: %w", err)
 	}
-
-	if startedAt.Valid {
-		build.StartedAt = &startedAt.Time
-	}
-	if finishedAt.Valid {
-		build.FinishedAt = &finishedAt.Time
-	}
-	if buildStrategy.Valid {
-		build.BuildStrategy = models.BuildStrategy(buildStrategy.String)
-	}
-	if generatedFlake.Valid {
-		build.GeneratedFlake = generatedFlake.String
-	}
-	if flakeLock.Valid {
-		build.FlakeLock = flakeLock.String
-	}
-	if vendorHash.Valid {
-		build.VendorHash = vendorHash.String
-	}
-	if detectionResultJSON != nil {
-		build.DetectionResult = &models.DetectionResult{}
-		if err := json.Unmarshal(detectionResultJSON, build.DetectionResult); err != nil {
-			return nil, fmt.Errorf("unmarshaling detection result: %w", err)
-		}
-	}
-	if detectedAt.Valid {
-		build.DetectedAt = &detectedAt.Time
-	}
-
 	return build, nil
 }
 
 // GetByDeployment retrieves a build job by deployment ID.
 func (s *BuildStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE deployment_id = $1
-		ORDER BY created_at DESC
-		LIMIT 1`
-
-	build := &models.BuildJob{}
-	var startedAt, finishedAt, detectedAt sql.NullTime
-	var buildStrategy, generatedFlake, flakeLock, vendorHash sql.NullString
-	var detectionResultJSON []byte
-
-	err := s.conn().QueryRowContext(ctx, query, deploymentID).Scan(
-		&build.ID,
-		&build.DeploymentID,
-		&build.AppID,
-		&build.GitURL,
-		&build.GitRef,
-		&build.FlakeOutput,
-		&build.BuildType,
-		&build.Status,
-		&build.CreatedAt,
-		&startedAt,
-		&finishedAt,
-		&buildStrategy,
-		&build.TimeoutSeconds,
-		&build.RetryCount,
-		&build.RetryAsOCI,
-		&generatedFlake,
-		&flakeLock,
-		&vendorHash,
-		&detectionResultJSON,
-		&detectedAt,
-	)
+	query, args := newSelect(buildColumns, "builds").
+		Where("deployment_id = ?", deploymentID).
+		OrderBy("created_at DESC").
+		Page(1, 0).
+		Build()
 
+	build, err := scanBuild(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying build by deployment: %w", err)
 	}
-
-	if startedAt.Valid {
-		build.StartedAt = &startedAt.Time
-	}
-	if finishedAt.Valid {
-		build.FinishedAt = &finishedAt.Time
-	}
-	if buildStrategy.Valid {
-		build.BuildStrategy = models.BuildStrategy(buildStrategy.String)
-	}
-	if generatedFlake.Valid {
-		build.GeneratedFlake = generatedFlake.String
-	}
-	if flakeLock.Valid {
-		build.FlakeLock = flakeLock.String
-	}
-	if vendorHash.Valid {
-		build.VendorHash = vendorHash.String
-	}
-	if detectionResultJSON != nil {
-		build.DetectionResult = &models.DetectionResult{}
-		if err := json.Unmarshal(detectionResultJSON, build.DetectionResult); err != nil {
-			return nil, fmt.Errorf("unmarshaling detection result: %w", err)
-		}
-	}
-	if detectedAt.Valid {
-		build.DetectedAt = &detectedAt.Time
-	}
-
 	return build, nil
 }
 
@@ -267,7 +173,11 @@ func (s *BuildStore) Update(ctx context.Context, build *models.BuildJob) error {
 		SET status = $2, started_at = $3, finished_at = $4,
 			build_strategy = $5, retry_count = $6, retry_as_oci = $7,
 			generated_flake = $8, flake_lock = $9, vendor_hash = $10,
-			detection_result = $11, detected_at = $12
+			detection_result = $11, detected_at = $12,
+			content_hash = $13, artifact = $14, deduplicated_from = $15,
+			reproducibility = $16, output_hash = $17, cache_stats = $18,
+			failure = $19, priority = $20, triggered_by = $21,
+			cancel_requested_at = CASE WHEN $2 = 'queued' THEN NULL ELSE cancel_requested_at END
 		WHERE id = $1`
 
 	// Handle nullable build_strategy
@@ -304,6 +214,26 @@ func (s *BuildStore) Update(ctx context.Context, build *models.BuildJob) error {
 		}
 	}
 
+	// Handle nullable cache_stats (JSONB)
+	var cacheStats []byte
+	if build.CacheStats != nil {
+		var err error
+		cacheStats, err = json.Marshal(build.CacheStats)
+		if err != nil {
+			return fmt.Errorf("marshaling cache stats: %w", err)
+		}
+	}
+
+	// Handle nullable failure (JSONB)
+	var failure []byte
+	if build.Failure != nil {
+		var err error
+		failure, err = json.Marshal(build.Failure)
+		if err != nil {
+			return fmt.Errorf("marshaling failure: %w", err)
+		}
+	}
+
 	result, err := s.conn().ExecContext(ctx, query,
 		build.ID,
 		build.Status,
@@ -317,6 +247,15 @@ func (s *BuildStore) Update(ctx context.Context, build *models.BuildJob) error {
 		vendorHash,
 		detectionResult,
 		build.DetectedAt,
+		nullString(build.ContentHash),
+		nullString(build.Artifact),
+		nullString(build.DeduplicatedFrom),
+		nullString(string(build.Reproducibility)),
+		nullString(build.OutputHash),
+		cacheStats,
+		failure,
+		build.Priority,
+		nullString(build.TriggeredBy),
 	)
 	if err != nil {
 		return fmt.Errorf("updating build: %w", err)
@@ -334,181 +273,228 @@ func (s *BuildStore) Update(ctx context.Context, build *models.BuildJob) error {
 	return nil
 }
 
-// List retrieves all builds for a given application.
-func (s *BuildStore) List(ctx context.Context, appID string) ([]*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE app_id = $1
-		ORDER BY created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, appID)
+// RequestCancel records that a build should be canceled, keeping the time
+// of the first request.
+func (s *BuildStore) RequestCancel(ctx context.Context, id string) error {
+	query := `UPDATE builds SET cancel_requested_at = COALESCE(cancel_requested_at, NOW()) WHERE id = $1`
+	result, err := s.conn().ExecContext(ctx, query, id)
 	if err != nil {
-		return nil, fmt.Errorf("querying builds by app: %w", err)
+		return fmt.Errorf("requesting build cancel: %w", err)
 	}
-	defer rows.Close()
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
 
-	return s.scanBuilds(rows)
+// FindByContentHash returns the most recent successful build with the given
+// content hash that recorded an artifact, or ErrNotFound.
+func (s *BuildStore) FindByContentHash(ctx context.Context, contentHash string) (*models.BuildJob, error) {
+	query, args := newSelect(buildColumns, "builds").
+		Where("content_hash = ?", contentHash).
+		Where("status = ?", models.BuildStatusSucceeded).
+		Where("artifact IS NOT NULL").
+		OrderBy("finished_at DESC").
+		Page(1, 0).
+		Build()
+
+	build, err := scanBuild(s.conn().QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying build by content hash: %w", err)
+	}
+	return build, nil
 }
 
-// ListByUser retrieves all builds for a given user across all apps.
-func (s *BuildStore) ListByUser(ctx context.Context, userID string) ([]*models.BuildJob, error) {
-	query := `
-		SELECT b.id, b.deployment_id, b.app_id, b.git_url, b.git_ref, 
-			b.flake_output, b.build_type, b.status, b.created_at, b.started_at, b.finished_at,
-			b.build_strategy, b.timeout_seconds, b.retry_count, b.retry_as_oci,
-			b.generated_flake, b.flake_lock, b.vendor_hash, b.detection_result, b.detected_at
-		FROM builds b
-		JOIN apps a ON b.app_id = a.id
-		WHERE a.owner_id = $1
-		ORDER BY b.created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, userID)
+// FailureStats counts a service's builds created since the given time, and
+// its failed builds by failure category, most frequent first. Failed builds
+// recorded before failures were classified count as unknown.
+func (s *BuildStore) FailureStats(ctx context.Context, appID, serviceName string, since time.Time) (*models.BuildFailureStats, error) {
+	stats := &models.BuildFailureStats{ServiceName: serviceName, Since: since, Classes: []*models.BuildFailureClassStats{}}
+
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE b.status = 'failed'),
+			COUNT(*) FILTER (WHERE b.retry_count > 0)
+		FROM builds b JOIN deployments d ON b.deployment_id = d.id
+		WHERE b.app_id = $1 AND d.service_name = $2 AND b.created_at >= $3
+	`, appID, serviceName, since).Scan(&stats.Builds, &stats.Failed, &stats.Retried)
+	if err != nil {
+		return nil, fmt.Errorf("counting builds: %w", err)
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT COALESCE(b.failure->>'category', 'unknown') AS category, COUNT(*),
+			MAX(COALESCE(b.finished_at, b.created_at))
+		FROM builds b JOIN deployments d ON b.deployment_id = d.id
+		WHERE b.app_id = $1 AND d.service_name = $2 AND b.created_at >= $3 AND b.status = 'failed'
+		GROUP BY category
+		ORDER BY COUNT(*) DESC, category
+	`, appID, serviceName, since)
 	if err != nil {
-		return nil, fmt.Errorf("querying builds by user: %w", err)
+		return nil, fmt.Errorf("querying build failures: %w", err)
 	}
 	defer rows.Close()
+	for rows.Next() {
+		var class models.BuildFailureClassStats
+		if err := rows.Scan(&class.Category, &class.Count, &class.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("scanning build failures: %w", err)
+		}
+		class.Retryable = class.Category.IsTransient()
+		stats.Classes = append(stats.Classes, &class)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating build failures: %w", err)
+	}
+	return stats, nil
+}
 
-	return s.scanBuilds(rows)
+// List retrieves all builds for a given application.
+func (s *BuildStore) List(ctx context.Context, appID string) ([]*models.BuildJob, error) {
+	q := newSelect(buildColumns, "builds").Where("app_id = ?", appID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "build", q, scanBuild)
 }
 
-// scanBuilds is a helper to scan build rows into a slice.
-func (s *BuildStore) scanBuilds(rows *sql.Rows) ([]*models.BuildJob, error) {
-	var builds []*models.BuildJob
+// ListByUser retrieves all builds for a given user across all apps.
+// Builds of soft-deleted apps are excluded.
+func (s *BuildStore) ListByUser(ctx context.Context, userID string) ([]*models.BuildJob, error) {
+	q := newSelect(qualifyColumns("b", buildColumns), "builds b JOIN apps a ON b.app_id = a.id").
+		Where("a.owner_id = ?", userID).
+		NotDeleted("a").
+		OrderBy("b.created_at DESC")
+	return listRows(ctx, s.conn(), "build", q, scanBuild)
+}
 
-	for rows.Next() {
-		build := &models.BuildJob{}
-		var startedAt, finishedAt, detectedAt sql.NullTime
-		var buildStrategy, generatedFlake, flakeLock, vendorHash sql.NullString
-		var detectionResultJSON []byte
-
-		err := rows.Scan(
-			&build.ID,
-			&build.DeploymentID,
-			&build.AppID,
-			&build.GitURL,
-			&build.GitRef,
-			&build.FlakeOutput,
-			&build.BuildType,
-			&build.Status,
-			&build.CreatedAt,
-			&startedAt,
-			&finishedAt,
-			&buildStrategy,
-			&build.TimeoutSeconds,
-			&build.RetryCount,
-			&build.RetryAsOCI,
-			&generatedFlake,
-			&flakeLock,
-			&vendorHash,
-			&detectionResultJSON,
-			&detectedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scanning build row: %w", err)
-		}
+// scanBuild reads a single build row selected with buildColumns.
+func scanBuild(row rowScanner) (*models.BuildJob, error) {
+	build := &models.BuildJob{}
+	var startedAt, finishedAt, detectedAt, cancelRequestedAt sql.NullTime
+	var buildStrategy, generatedFlake, flakeLock, vendorHash sql.NullString
+	var contentHash, artifact, deduplicatedFrom, reproducibility, outputHash, triggeredBy sql.NullString
+	var detectionResultJSON, externalMetadataJSON, cacheStatsJSON, failureJSON []byte
 
-		if startedAt.Valid {
-			build.StartedAt = &startedAt.Time
-		}
-		if finishedAt.Valid {
-			build.FinishedAt = &finishedAt.Time
-		}
-		if buildStrategy.Valid {
-			build.BuildStrategy = models.BuildStrategy(buildStrategy.String)
-		}
-		if generatedFlake.Valid {
-			build.GeneratedFlake = generatedFlake.String
-		}
-		if flakeLock.Valid {
-			build.FlakeLock = flakeLock.String
-		}
-		if vendorHash.Valid {
-			build.VendorHash = vendorHash.String
+	err := row.Scan(
+		&build.ID,
+		&build.DeploymentID,
+		&build.AppID,
+		&build.GitURL,
+		&build.GitRef,
+		&build.FlakeOutput,
+		&build.BuildType,
+		&build.Status,
+		&build.CreatedAt,
+		&startedAt,
+		&finishedAt,
+		&buildStrategy,
+		&build.TimeoutSeconds,
+		&build.RetryCount,
+		&build.RetryAsOCI,
+		&generatedFlake,
+		&flakeLock,
+		&vendorHash,
+		&detectionResultJSON,
+		&detectedAt,
+		&contentHash,
+		&artifact,
+		&deduplicatedFrom,
+		&reproducibility,
+		&outputHash,
+		&externalMetadataJSON,
+		&cacheStatsJSON,
+		&build.BuildPath,
+		&failureJSON,
+		&build.Priority,
+		&triggeredBy,
+		&cancelRequestedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if startedAt.Valid {
+		build.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		build.FinishedAt = &finishedAt.Time
+	}
+	if buildStrategy.Valid {
+		build.BuildStrategy = models.BuildStrategy(buildStrategy.String)
+	}
+	if generatedFlake.Valid {
+		build.GeneratedFlake = generatedFlake.String
+	}
+	if flakeLock.Valid {
+		build.FlakeLock = flakeLock.String
+	}
+	if vendorHash.Valid {
+		build.VendorHash = vendorHash.String
+	}
+	if detectionResultJSON != nil {
+		build.DetectionResult = &models.DetectionResult{}
+		if err := json.Unmarshal(detectionResultJSON, build.DetectionResult); err != nil {
+			return nil, fmt.Errorf("unmarshaling detection result: %w", err)
 		}
-		if detectionResultJSON != nil {
-			build.DetectionResult = &models.DetectionResult{}
-			if err := json.Unmarshal(detectionResultJSON, build.DetectionResult); err != nil {
-				return nil, fmt.Errorf("unmarshaling detection result: %w", err)
-			}
+	}
+	if detectedAt.Valid {
+		build.DetectedAt = &detectedAt.Time
+	}
+	if cancelRequestedAt.Valid {
+		build.CancelRequestedAt = &cancelRequestedAt.Time
+	}
+	build.ContentHash = contentHash.String
+	build.Artifact = artifact.String
+	build.DeduplicatedFrom = deduplicatedFrom.String
+	build.Reproducibility = models.ReproducibilityStatus(reproducibility.String)
+	build.OutputHash = outputHash.String
+	build.TriggeredBy = triggeredBy.String
+	if externalMetadataJSON != nil {
+		if err := json.Unmarshal(externalMetadataJSON, &build.ExternalMetadata); err != nil {
+			return nil, fmt.Errorf("unmarshaling external metadata: %w", err)
 		}
-		if detectedAt.Valid {
-			build.DetectedAt = &detectedAt.Time
+	}
+	if cacheStatsJSON != nil {
+		build.CacheStats = &models.BuildCacheStats{}
+		if err := json.Unmarshal(cacheStatsJSON, build.CacheStats); err != nil {
+			return nil, fmt.Errorf("unmarshaling cache stats: %w", err)
 		}
-
-		builds = append(builds, build)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating build rows: %w", err)
+	if failureJSON != nil {
+		build.Failure = &models.BuildFailure{}
+		if err := json.Unmarshal(failureJSON, build.Failure); err != nil {
+			return nil, fmt.Errorf("unmarshaling failure: %w", err)
+		}
 	}
 
-	return builds, nil
+	return build, nil
 }
 
 // ListPending retrieves all pending build jobs.
 func (s *BuildStore) ListPending(ctx context.Context) ([]*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE status = 'queued'
-		ORDER BY created_at ASC`
-
-	rows, err := s.conn().QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("querying pending builds: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanBuilds(rows)
+	return s.listByStatus(ctx, models.BuildStatusQueued)
 }
 
 // ListRunning retrieves all builds with status 'running'.
 // Used for startup recovery to identify interrupted builds.
 // **Validates: Requirements 15.1, 15.2**
 func (s *BuildStore) ListRunning(ctx context.Context) ([]*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE status = 'running'
-		ORDER BY created_at ASC`
-
-	rows, err := s.conn().QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("querying running builds: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanBuilds(rows)
+	return s.listByStatus(ctx, models.BuildStatusRunning)
 }
 
 // ListQueued retrieves all builds with status 'queued'.
 // Used for startup recovery to resume pending builds.
 // **Validates: Requirements 15.1**
 func (s *BuildStore) ListQueued(ctx context.Context) ([]*models.BuildJob, error) {
-	query := `
-		SELECT id, deployment_id, app_id, git_url, git_ref, 
-			flake_output, build_type, status, created_at, started_at, finished_at,
-			build_strategy, timeout_seconds, retry_count, retry_as_oci,
-			generated_flake, flake_lock, vendor_hash, detection_result, detected_at
-		FROM builds
-		WHERE status = 'queued'
-		ORDER BY created_at ASC`
-
-	rows, err := s.conn().QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("querying queued builds: %w", err)
-	}
-	defer rows.Close()
+	return s.listByStatus(ctx, models.BuildStatusQueued)
+}
 
-	return s.scanBuilds(rows)
+// listByStatus retrieves builds in the given status, oldest first.
+func (s *BuildStore) listByStatus(ctx context.Context, status models.BuildStatus) ([]*models.BuildJob, error) {
+	q := newSelect(buildColumns, "builds").Where("status = ?", status).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "build", q, scanBuild)
 }
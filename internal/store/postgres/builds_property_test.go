@@ -61,6 +61,7 @@ func runBuildMigrations(db *sql.DB) error {
 	_, _ = db.Exec("DROP TABLE IF EXISTS builds CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS deployments CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS nodes CASCADE")
+	_, _ = db.Exec("DROP TABLE IF EXISTS services CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS apps CASCADE")
 
 	schema := `
@@ -80,6 +81,18 @@ func runBuildMigrations(db *sql.DB) error {
 			deleted_at TIMESTAMPTZ
 		);
 
+		CREATE TABLE services (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+			name VARCHAR(63) NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			config JSONB NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CONSTRAINT services_app_name_unique UNIQUE (app_id, name)
+		);
+
 		CREATE TABLE deployments (
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
 			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
@@ -91,7 +104,7 @@ func runBuildMigrations(db *sql.DB) error {
 			artifact TEXT,
 			status VARCHAR(20) NOT NULL CHECK (status IN (
 				'pending', 'building', 'built', 'scheduled', 
-				'starting', 'running', 'stopping', 'stopped', 'failed'
+				'starting', 'running', 'stopping', 'stopped', 'failed', 'canceled'
 			)),
 			node_id UUID,
 			resources JSONB,
@@ -100,7 +113,11 @@ func runBuildMigrations(db *sql.DB) error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
-			finished_at TIMESTAMPTZ
+			finished_at TIMESTAMPTZ,
+			rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL,
+			canary JSONB,
+			health JSONB,
+			error TEXT NOT NULL DEFAULT ''
 		);
 
 		CREATE TABLE builds (
@@ -112,7 +129,7 @@ func runBuildMigrations(db *sql.DB) error {
 			flake_output VARCHAR(255) NOT NULL,
 			build_type VARCHAR(20) NOT NULL CHECK (build_type IN ('oci', 'pure-nix')),
 			status VARCHAR(20) NOT NULL CHECK (status IN (
-				'queued', 'running', 'succeeded', 'failed'
+				'queued', 'running', 'succeeded', 'failed', 'canceled'
 			)),
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
@@ -125,7 +142,19 @@ func runBuildMigrations(db *sql.DB) error {
 			flake_lock TEXT,
 			vendor_hash VARCHAR(255),
 			detection_result JSONB,
-			detected_at TIMESTAMPTZ
+			detected_at TIMESTAMPTZ,
+			content_hash VARCHAR(64),
+			artifact TEXT,
+			deduplicated_from UUID REFERENCES builds(id) ON DELETE SET NULL,
+			reproducibility VARCHAR(20),
+			output_hash TEXT,
+			external_metadata JSONB,
+			cache_stats JSONB,
+			build_path TEXT NOT NULL DEFAULT '',
+			failure JSONB,
+			priority VARCHAR(20) NOT NULL DEFAULT '',
+			triggered_by VARCHAR(255),
+			cancel_requested_at TIMESTAMPTZ
 		);
 	`
 	_, err := db.Exec(schema)
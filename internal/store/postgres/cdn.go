@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// CDNStore implements store.CDNStore using PostgreSQL.
+type CDNStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *CDNStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// cdnIntegrationColumns lists the columns read by scanCDNIntegration.
+const cdnIntegrationColumns = `id, app_id, provider, name, enabled, config, services, created_at, updated_at`
+
+// cdnPurgeColumns lists the columns read by scanCDNPurge.
+const cdnPurgeColumns = `id, app_id, integration_id, provider, trigger, deployment_id, service_name, requested_by,
+	urls, succeeded, error, duration_ms, created_at`
+
+// CreateIntegration stores a new CDN integration.
+func (s *CDNStore) CreateIntegration(ctx context.Context, integration *models.CDNIntegration) error {
+	if integration.ID == "" {
+		integration.ID = uuid.New().String()
+	}
+	now := time.Now()
+	integration.CreatedAt, integration.UpdatedAt = now, now
+
+	configJSON, servicesJSON, err := marshalCDNSettings(integration)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cdn_integrations (id, app_id, provider, name, enabled, config, services, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		integration.ID, integration.AppID, string(integration.Provider), integration.Name, integration.Enabled,
+		configJSON, servicesJSON, integration.CreatedAt, integration.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting CDN integration: %w", err)
+	}
+	return nil
+}
+
+// GetIntegration retrieves an integration by ID. It returns nil if the integration does not exist.
+func (s *CDNStore) GetIntegration(ctx context.Context, id string) (*models.CDNIntegration, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(cdnIntegrationColumns, "cdn_integrations").Where("id = ?", id).Build()
+
+	integration, err := scanCDNIntegration(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying CDN integration: %w", err)
+	}
+	return integration, nil
+}
+
+// ListIntegrations retrieves an app's integrations, oldest first.
+func (s *CDNStore) ListIntegrations(ctx context.Context, appID string) ([]*models.CDNIntegration, error) {
+	q := newSelect(cdnIntegrationColumns, "cdn_integrations").Where("app_id = ?", appID).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "CDN integration", q, scanCDNIntegration)
+}
+
+// UpdateIntegration updates an integration's name, enabled flag, config and services.
+func (s *CDNStore) UpdateIntegration(ctx context.Context, integration *models.CDNIntegration) error {
+	integration.UpdatedAt = time.Now()
+
+	configJSON, servicesJSON, err := marshalCDNSettings(integration)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE cdn_integrations SET name = $1, enabled = $2, config = $3, services = $4, updated_at = $5
+		WHERE id = $6
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		integration.Name, integration.Enabled, configJSON, servicesJSON, integration.UpdatedAt, integration.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating CDN integration: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIntegration removes an integration and its purge log.
+func (s *CDNStore) DeleteIntegration(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM cdn_integrations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting CDN integration: %w", err)
+	}
+	return nil
+}
+
+// RecordPurge logs a purge and its result.
+func (s *CDNStore) RecordPurge(ctx context.Context, purge *models.CDNPurge) error {
+	if purge.ID == "" {
+		purge.ID = uuid.New().String()
+	}
+	if purge.CreatedAt.IsZero() {
+		purge.CreatedAt = time.Now()
+	}
+
+	urls := purge.URLs
+	if urls == nil {
+		urls = []string{}
+	}
+	urlsJSON, err := json.Marshal(urls)
+	if err != nil {
+		return fmt.Errorf("marshaling CDN purge urls: %w", err)
+	}
+
+	query := `
+		INSERT INTO cdn_purges (id, app_id, integration_id, provider, trigger, deployment_id, service_name,
+			requested_by, urls, succeeded, error, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		purge.ID, purge.AppID, purge.IntegrationID, string(purge.Provider), string(purge.Trigger),
+		nullString(purge.DeploymentID), purge.ServiceName, purge.RequestedBy, urlsJSON, purge.Succeeded,
+		purge.Error, purge.DurationMs, purge.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting CDN purge: %w", err)
+	}
+	return nil
+}
+
+// ListPurges retrieves an app's most recent purges, newest first.
+func (s *CDNStore) ListPurges(ctx context.Context, appID string, limit int) ([]*models.CDNPurge, error) {
+	q := newSelect(cdnPurgeColumns, "cdn_purges").
+		Where("app_id = ?", appID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "CDN purge", q, scanCDNPurge)
+}
+
+// marshalCDNSettings encodes an integration's config and services, storing nil as empty.
+func marshalCDNSettings(integration *models.CDNIntegration) ([]byte, []byte, error) {
+	config := integration.Config
+	if config == nil {
+		config = map[string]string{}
+	}
+	services := integration.Services
+	if services == nil {
+		services = []string{}
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling CDN integration config: %w", err)
+	}
+	servicesJSON, err := json.Marshal(services)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling CDN integration services: %w", err)
+	}
+	return configJSON, servicesJSON, nil
+}
+
+func scanCDNIntegration(row rowScanner) (*models.CDNIntegration, error) {
+	var i models.CDNIntegration
+	var provider string
+	var configJSON, servicesJSON []byte
+	if err := row.Scan(
+		&i.ID, &i.AppID, &provider, &i.Name, &i.Enabled, &configJSON, &servicesJSON, &i.CreatedAt, &i.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	i.Provider = models.CDNProviderType(provider)
+	if err := json.Unmarshal(configJSON, &i.Config); err != nil {
+		return nil, fmt.Errorf("unmarshaling CDN integration config: %w", err)
+	}
+	if err := json.Unmarshal(servicesJSON, &i.Services); err != nil {
+		return nil, fmt.Errorf("unmarshaling CDN integration services: %w", err)
+	}
+	return &i, nil
+}
+
+func scanCDNPurge(row rowScanner) (*models.CDNPurge, error) {
+	var p models.CDNPurge
+	var provider, trigger string
+	var deploymentID sql.NullString
+	var urlsJSON []byte
+	if err := row.Scan(
+		&p.ID, &p.AppID, &p.IntegrationID, &provider, &trigger, &deploymentID, &p.ServiceName, &p.RequestedBy,
+		&urlsJSON, &p.Succeeded, &p.Error, &p.DurationMs, &p.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	p.Provider = models.CDNProviderType(provider)
+	p.Trigger = models.CDNPurgeTrigger(trigger)
+	p.DeploymentID = deploymentID.String
+	if err := json.Unmarshal(urlsJSON, &p.URLs); err != nil {
+		return nil, fmt.Errorf("unmarshaling CDN purge urls: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// replayPollInterval is how often a replica behind a read's consistency
+// token is checked again.
+const replayPollInterval = 20 * time.Millisecond
+
+// ConsistencyStore implements store.ConsistencyStore using PostgreSQL. Its
+// tokens are positions in the primary's write-ahead log.
+type ConsistencyStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+// Token returns the primary's current WAL position, which every committed
+// write precedes. Without a replica it returns an empty token.
+func (s *ConsistencyStore) Token(ctx context.Context) (string, error) {
+	if s.stmts == nil || s.stmts.replica == nil {
+		return "", nil
+	}
+	// Always ask the primary, never a replica
+	var conn queryable = s.db
+	if s.tx != nil {
+		conn = s.tx
+	}
+	var lsn string
+	if err := conn.QueryRowContext(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&lsn); err != nil {
+		return "", fmt.Errorf("getting WAL position: %w", err)
+	}
+	return lsn, nil
+}
+
+// replica is a read replica serving the reads of contexts marked with
+// store.ReadAfter.
+type replica struct {
+	db     *sql.DB
+	wait   time.Duration // How long a read waits for the replica to catch up
+	logger *slog.Logger
+
+	replayed atomic.Uint64 // Highest WAL position the replica was seen to have replayed
+}
+
+// openReplica connects to the read replica at dsn, which must be a standby.
+func openReplica(cfg *Config, logger *slog.Logger) (*replica, error) {
+	db, err := sql.Open("pgx", cfg.ReplicaDSN)
+	if err != nil {
+		return nil, fmt.Errorf("opening read replica: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var standby bool
+	if err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&standby); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("pinging read replica: %w", err)
+	}
+	if !standby {
+		db.Close()
+		return nil, fmt.Errorf("read replica is not a standby server")
+	}
+	return &replica{db: db, wait: cfg.ReplicaWait, logger: logger}, nil
+}
+
+// serves reports whether the replica may serve a read of ctx: whether ctx
+// allows replica reads and the replica has replayed the writes of its token.
+// It waits up to r.wait for a lagging replica to catch up.
+func (r *replica) serves(ctx context.Context) bool {
+	token, ok := store.ReadAfterToken(ctx)
+	if !ok {
+		return false
+	}
+	if token == "" {
+		return true
+	}
+	want, err := parseLSN(token)
+	if err != nil {
+		return false
+	}
+	if r.replayed.Load() >= want {
+		return true
+	}
+
+	deadline := time.Now().Add(r.wait)
+	for {
+		replayed, err := r.replayPosition(ctx)
+		if err != nil {
+			r.logger.Warn("failed to check read replica position, reading from primary", "error", err)
+			return false
+		}
+		if replayed >= want {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(replayPollInterval):
+		}
+	}
+}
+
+// replayPosition returns the WAL position the replica has replayed up to.
+func (r *replica) replayPosition(ctx context.Context) (uint64, error) {
+	var lsn sql.NullString
+	if err := r.db.QueryRowContext(ctx, "SELECT pg_last_wal_replay_lsn()::text").Scan(&lsn); err != nil {
+		return 0, err
+	}
+	if !lsn.Valid {
+		return 0, fmt.Errorf("read replica is not replaying WAL")
+	}
+	pos, err := parseLSN(lsn.String)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		seen := r.replayed.Load()
+		if pos <= seen || r.replayed.CompareAndSwap(seen, pos) {
+			return pos, nil
+		}
+	}
+}
+
+// parseLSN parses a WAL position written as two hexadecimal halves, e.g.
+// "16/B374D848".
+func parseLSN(s string) (uint64, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	h, err1 := strconv.ParseUint(hi, 16, 32)
+	l, err2 := strconv.ParseUint(lo, 16, 32)
+	if !ok || err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("invalid WAL position %q", s)
+	}
+	return h<<32 | l, nil
+}
+
+// readOnly reports whether query only reads, so that a replica can run it.
+func readOnly(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	return strings.HasPrefix(q, "SELECT") && !strings.Contains(q, " FOR UPDATE") && !strings.Contains(q, " FOR SHARE")
+}
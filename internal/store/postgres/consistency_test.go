@@ -0,0 +1,38 @@
+package postgres
+
+import "testing"
+
+func TestParseLSN(t *testing.T) {
+	for s, want := range map[string]uint64{
+		"0/0":         0,
+		"0/16B3748":   0x16B3748,
+		"16/B374D848": 0x16<<32 | 0xB374D848,
+	} {
+		got, err := parseLSN(s)
+		if err != nil || got != want {
+			t.Errorf("parseLSN(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "16", "x/1", "1/100000000"} {
+		if _, err := parseLSN(s); err == nil {
+			t.Errorf("parseLSN(%q) succeeded", s)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT id FROM apps WHERE id = $1":                       true,
+		"\n\t\tselect count(*) FROM users":                        true,
+		"INSERT INTO apps (id) VALUES ($1) RETURNING id":          false,
+		"UPDATE builds SET status = $1 RETURNING id":              false,
+		"WITH due AS (SELECT id FROM jobs) UPDATE jobs SET a = 1": false,
+		"SELECT id FROM notification_deliveries FOR UPDATE":       false,
+		"SELECT id FROM notification_deliveries FOR SHARE":        false,
+	}
+	for query, want := range tests {
+		if got := readOnly(query); got != want {
+			t.Errorf("readOnly(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
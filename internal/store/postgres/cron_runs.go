@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// CronRunStore implements store.CronRunStore using PostgreSQL.
+type CronRunStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *CronRunStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const cronRunColumns = `id, app_id, service_name, deployment_id, trigger, triggered_by, status,
+	scheduled_at, started_at, finished_at, error, created_at`
+
+// Create stores a new run.
+func (s *CronRunStore) Create(ctx context.Context, run *models.CronRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO cron_runs (id, app_id, service_name, deployment_id, trigger, triggered_by, status,
+			scheduled_at, started_at, finished_at, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		run.ID, run.AppID, run.ServiceName, nullString(run.DeploymentID), run.Trigger, run.TriggeredBy,
+		run.Status, run.ScheduledAt, run.StartedAt, run.FinishedAt, run.Error, run.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating cron run: %w", err)
+	}
+	return nil
+}
+
+// Update saves a run's deployment, status, times and error.
+func (s *CronRunStore) Update(ctx context.Context, run *models.CronRun) error {
+	query := `
+		UPDATE cron_runs
+		SET deployment_id = $2, status = $3, started_at = $4, finished_at = $5, error = $6
+		WHERE id = $1
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		run.ID, nullString(run.DeploymentID), run.Status, run.StartedAt, run.FinishedAt, run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("updating cron run: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a run by ID. It returns nil if the run does not exist.
+func (s *CronRunStore) Get(ctx context.Context, id string) (*models.CronRun, error) {
+	return s.getWhere(ctx, newSelect(cronRunColumns, "cron_runs").Where("id = ?", id))
+}
+
+// GetByDeployment retrieves the run executed by a deployment. It returns nil
+// if the deployment is not a run.
+func (s *CronRunStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.CronRun, error) {
+	return s.getWhere(ctx, newSelect(cronRunColumns, "cron_runs").Where("deployment_id = ?", deploymentID))
+}
+
+// GetLatest retrieves a service's run with the latest scheduled time started
+// by the given trigger. It returns nil if there is none.
+func (s *CronRunStore) GetLatest(ctx context.Context, appID, serviceName string, trigger models.CronRunTrigger) (*models.CronRun, error) {
+	q := newSelect(cronRunColumns, "cron_runs").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		Where("trigger = ?", trigger).
+		OrderBy("scheduled_at DESC").
+		Page(1, 0)
+	return s.getWhere(ctx, q)
+}
+
+// List retrieves a service's most recent runs, newest first.
+func (s *CronRunStore) List(ctx context.Context, appID, serviceName string, limit int) ([]*models.CronRun, error) {
+	q := newSelect(cronRunColumns, "cron_runs").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		OrderBy("scheduled_at DESC, created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "cron run", q, scanCronRun)
+}
+
+// ListActive retrieves the runs of all services that are pending or running.
+func (s *CronRunStore) ListActive(ctx context.Context) ([]*models.CronRun, error) {
+	q := newSelect(cronRunColumns, "cron_runs").
+		Where("status IN (?, ?)", models.CronRunStatusPending, models.CronRunStatusRunning).
+		OrderBy("created_at")
+	return listRows(ctx, s.conn(), "cron run", q, scanCronRun)
+}
+
+func (s *CronRunStore) getWhere(ctx context.Context, q *selectQuery) (*models.CronRun, error) {
+	query, args := q.Build()
+	run, err := scanCronRun(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying cron run: %w", err)
+	}
+	return run, nil
+}
+
+// scanCronRun reads a single cron run row.
+func scanCronRun(row rowScanner) (*models.CronRun, error) {
+	var run models.CronRun
+	var deploymentID sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(
+		&run.ID, &run.AppID, &run.ServiceName, &deploymentID, &run.Trigger, &run.TriggeredBy, &run.Status,
+		&run.ScheduledAt, &startedAt, &finishedAt, &run.Error, &run.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	run.DeploymentID = deploymentID.String
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DBHealthStore implements store.DBHealthStore using PostgreSQL.
+type DBHealthStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *DBHealthStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// primary returns a connection to the primary, never a read replica: the
+// statistics of a standby do not describe the tables being written.
+func (s *DBHealthStore) primary() queryable {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// orphanedLogs selects log rows written before $1 whose deployment is missing,
+// which only happens when the foreign key was dropped, or belongs to an app
+// deleted before $1. Apps are soft-deleted and never restored.
+const orphanedLogs = `
+	SELECT l.id FROM logs l
+	LEFT JOIN deployments d ON d.id = l.deployment_id
+	LEFT JOIN apps a ON a.id = d.app_id
+	WHERE l.timestamp < $1
+		AND (d.id IS NULL OR a.id IS NULL OR a.deleted_at < $1)`
+
+// orphanedQueueJobs is the condition of build queue jobs, aliased q, created
+// before $1 whose build, sharing the job's ID, is missing or finished before
+// $1.
+const orphanedQueueJobs = `
+	q.created_at < $1 AND NOT EXISTS (
+		SELECT 1 FROM builds b
+		WHERE b.id = q.id
+			AND (b.status IN ('queued', 'running') OR b.finished_at IS NULL OR b.finished_at >= $1)
+	)`
+
+// DatabaseSize returns the size of the database in bytes.
+func (s *DBHealthStore) DatabaseSize(ctx context.Context) (int64, error) {
+	var size int64
+	if err := s.primary().QueryRowContext(ctx, `SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+		return 0, fmt.Errorf("getting database size: %w", err)
+	}
+	return size, nil
+}
+
+// TableStats returns the size, row counts and last vacuum of every table in
+// the current schema, largest first.
+func (s *DBHealthStore) TableStats(ctx context.Context) ([]*models.TableStats, error) {
+	query := `
+		SELECT relname, pg_total_relation_size(relid), pg_indexes_size(relid),
+			n_live_tup, n_dead_tup, GREATEST(last_vacuum, last_autovacuum)
+		FROM pg_stat_user_tables
+		WHERE schemaname = current_schema()
+		ORDER BY pg_total_relation_size(relid) DESC, relname`
+
+	rows, err := s.primary().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying table statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []*models.TableStats
+	for rows.Next() {
+		t := &models.TableStats{}
+		var lastVacuum sql.NullTime
+		if err := rows.Scan(&t.Table, &t.TotalBytes, &t.IndexBytes, &t.LiveRows, &t.DeadRows, &lastVacuum); err != nil {
+			return nil, fmt.Errorf("scanning table statistics: %w", err)
+		}
+		if lastVacuum.Valid {
+			t.LastVacuum = &lastVacuum.Time
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating table statistics: %w", err)
+	}
+	return tables, nil
+}
+
+// RecordSizes stores a snapshot of the sizes of tables taken at at.
+func (s *DBHealthStore) RecordSizes(ctx context.Context, tables []*models.TableStats, at time.Time) error {
+	query := `
+		INSERT INTO table_size_snapshots (table_name, total_bytes, live_rows, recorded_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (table_name, recorded_at) DO NOTHING`
+
+	for _, t := range tables {
+		if _, err := s.conn().ExecContext(ctx, query, t.Table, t.TotalBytes, t.LiveRows, at); err != nil {
+			return fmt.Errorf("recording size of table %s: %w", t.Table, err)
+		}
+	}
+	return nil
+}
+
+// SizesSince returns the size snapshots taken since since, oldest first.
+func (s *DBHealthStore) SizesSince(ctx context.Context, since time.Time) ([]*models.TableSizeSample, error) {
+	query := `
+		SELECT table_name, total_bytes, live_rows, recorded_at
+		FROM table_size_snapshots
+		WHERE recorded_at >= $1
+		ORDER BY recorded_at, table_name`
+
+	rows, err := s.conn().QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying table size snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []*models.TableSizeSample
+	for rows.Next() {
+		sample := &models.TableSizeSample{}
+		if err := rows.Scan(&sample.Table, &sample.TotalBytes, &sample.LiveRows, &sample.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scanning table size snapshot: %w", err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating table size snapshots: %w", err)
+	}
+	return samples, nil
+}
+
+// DeleteSizesBefore removes size snapshots taken before before.
+func (s *DBHealthStore) DeleteSizesBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM table_size_snapshots WHERE recorded_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting table size snapshots: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// CountOrphans counts the orphaned queue jobs and log rows created before
+// before.
+func (s *DBHealthStore) CountOrphans(ctx context.Context, before time.Time) (*models.DBOrphans, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM build_queue q WHERE ` + orphanedQueueJobs + `),
+			(SELECT COUNT(*) FROM (` + orphanedLogs + `) orphans)`
+
+	orphans := &models.DBOrphans{}
+	if err := s.primary().QueryRowContext(ctx, query, before).Scan(&orphans.QueueJobs, &orphans.Logs); err != nil {
+		return nil, fmt.Errorf("counting orphaned rows: %w", err)
+	}
+	return orphans, nil
+}
+
+// DeleteOrphanedQueueJobs removes build queue jobs created before before
+// whose build is missing or finished before before.
+func (s *DBHealthStore) DeleteOrphanedQueueJobs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM build_queue q WHERE `+orphanedQueueJobs, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting orphaned queue jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteOrphanedLogs removes up to limit log rows written before before whose
+// deployment is missing or belongs to a deleted app. Deleting in batches keeps
+// each statement's locks and WAL short.
+func (s *DBHealthStore) DeleteOrphanedLogs(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `DELETE FROM logs WHERE id IN (` + orphanedLogs + ` LIMIT $2)`
+	res, err := s.conn().ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("deleting orphaned logs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Vacuum runs VACUUM (ANALYZE) on a table. VACUUM cannot run in a
+// transaction, so it fails on a transaction's store.
+func (s *DBHealthStore) Vacuum(ctx context.Context, table string) error {
+	if s.db == nil {
+		return fmt.Errorf("cannot vacuum table %s in a transaction", table)
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM (ANALYZE) "+pgx.Identifier{table}.Sanitize()); err != nil {
+		return fmt.Errorf("vacuuming table %s: %w", table, err)
+	}
+	return nil
+}
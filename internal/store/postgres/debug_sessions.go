@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DebugSessionStore implements store.DebugSessionStore using PostgreSQL.
+type DebugSessionStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *DebugSessionStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const debugSessionColumns = `id, app_id, service_name, deployment_id, node_id, image, command, ttl_seconds,
+	status, error, created_by, created_at, started_at, expires_at, ended_at`
+
+// Create stores a new session.
+func (s *DebugSessionStore) Create(ctx context.Context, session *models.DebugSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	command, err := json.Marshal(session.Command)
+	if err != nil {
+		return fmt.Errorf("marshaling debug command: %w", err)
+	}
+	if session.Command == nil {
+		command = []byte("[]")
+	}
+
+	query := `
+		INSERT INTO debug_sessions (id, app_id, service_name, deployment_id, node_id, image, command,
+			ttl_seconds, status, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		session.ID, session.AppID, session.ServiceName, session.DeploymentID, session.NodeID, session.Image, command,
+		session.TTLSeconds, session.Status, session.CreatedBy, session.CreatedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating debug session: %w", err)
+	}
+	return nil
+}
+
+// Update saves a session's status, error and times.
+func (s *DebugSessionStore) Update(ctx context.Context, session *models.DebugSession) error {
+	query := `
+		UPDATE debug_sessions
+		SET status = $2, error = $3, started_at = $4, ended_at = $5
+		WHERE id = $1
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		session.ID, session.Status, session.Error, session.StartedAt, session.EndedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating debug session: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a session by ID. It returns nil if it does not exist.
+func (s *DebugSessionStore) Get(ctx context.Context, id string) (*models.DebugSession, error) {
+	query, args := newSelect(debugSessionColumns, "debug_sessions").Where("id = ?", id).Build()
+	session, err := scanDebugSession(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying debug session: %w", err)
+	}
+	return session, nil
+}
+
+// ListByService retrieves a service's sessions, newest first.
+func (s *DebugSessionStore) ListByService(ctx context.Context, appID, serviceName string, limit int) ([]*models.DebugSession, error) {
+	q := newSelect(debugSessionColumns, "debug_sessions").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "debug session", q, scanDebugSession)
+}
+
+// ListActive retrieves the sessions that are pending, running or
+// terminating, oldest first.
+func (s *DebugSessionStore) ListActive(ctx context.Context) ([]*models.DebugSession, error) {
+	q := newSelect(debugSessionColumns, "debug_sessions").
+		Where("status IN (?, ?, ?)", models.DebugSessionPending, models.DebugSessionRunning, models.DebugSessionTerminating).
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "debug session", q, scanDebugSession)
+}
+
+// scanDebugSession reads a single debug session row.
+func scanDebugSession(row rowScanner) (*models.DebugSession, error) {
+	var session models.DebugSession
+	var command []byte
+	var startedAt, endedAt sql.NullTime
+	err := row.Scan(
+		&session.ID, &session.AppID, &session.ServiceName, &session.DeploymentID, &session.NodeID, &session.Image,
+		&command, &session.TTLSeconds, &session.Status, &session.Error, &session.CreatedBy, &session.CreatedAt,
+		&startedAt, &session.ExpiresAt, &endedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(command, &session.Command); err != nil {
+		return nil, fmt.Errorf("unmarshaling debug command: %w", err)
+	}
+	if startedAt.Valid {
+		session.StartedAt = &startedAt.Time
+	}
+	if endedAt.Valid {
+		session.EndedAt = &endedAt.Time
+	}
+	return &session, nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// DeployFreezeStore implements store.DeployFreezeStore using PostgreSQL.
+type DeployFreezeStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *DeployFreezeStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// CreateWindow creates a new freeze window.
+func (s *DeployFreezeStore) CreateWindow(ctx context.Context, window *models.FreezeWindow) error {
+	if window.ID == "" {
+		window.ID = uuid.New().String()
+	}
+	if window.CreatedAt.IsZero() {
+		window.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO deploy_freeze_windows (id, org_id, name, kind, start_day, start_time, end_day, end_time,
+			timezone, starts_at, ends_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		window.ID,
+		window.OrgID,
+		window.Name,
+		string(window.Kind),
+		window.StartDay,
+		window.StartTime,
+		window.EndDay,
+		window.EndTime,
+		window.Timezone,
+		window.StartsAt,
+		window.EndsAt,
+		window.CreatedBy,
+		window.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting freeze window: %w", err)
+	}
+	return nil
+}
+
+// freezeWindowColumns lists the columns read by scanFreezeWindow.
+const freezeWindowColumns = `id, org_id, name, kind, start_day, start_time, end_day, end_time,
+	timezone, starts_at, ends_at, created_by, created_at`
+
+// GetWindow retrieves a freeze window by ID. It returns nil if the window does not exist.
+func (s *DeployFreezeStore) GetWindow(ctx context.Context, id string) (*models.FreezeWindow, error) {
+	query, args := newSelect(freezeWindowColumns, "deploy_freeze_windows").Where("id = ?", id).Build()
+
+	window, err := scanFreezeWindow(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying freeze window: %w", err)
+	}
+	return window, nil
+}
+
+// ListWindows retrieves all freeze windows for an organization, oldest first.
+func (s *DeployFreezeStore) ListWindows(ctx context.Context, orgID string) ([]*models.FreezeWindow, error) {
+	q := newSelect(freezeWindowColumns, "deploy_freeze_windows").
+		Where("org_id = ?", orgID).
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "freeze window", q, scanFreezeWindow)
+}
+
+// DeleteWindow removes a freeze window. Overrides recorded against it are kept.
+func (s *DeployFreezeStore) DeleteWindow(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM deploy_freeze_windows WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting freeze window: %w", err)
+	}
+	return nil
+}
+
+// RecordOverride stores a deploy that went ahead during an active freeze window.
+func (s *DeployFreezeStore) RecordOverride(ctx context.Context, override *models.FreezeOverride) error {
+	if override.ID == "" {
+		override.ID = uuid.New().String()
+	}
+	if override.CreatedAt.IsZero() {
+		override.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO deploy_freeze_overrides (id, org_id, window_id, deployment_id, app_id, service_name,
+			user_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		override.ID,
+		override.OrgID,
+		nullString(override.WindowID),
+		nullString(override.DeploymentID),
+		override.AppID,
+		override.ServiceName,
+		override.UserID,
+		override.Reason,
+		override.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting freeze override: %w", err)
+	}
+	return nil
+}
+
+// ListOverrides retrieves the most recent freeze overrides for an organization.
+func (s *DeployFreezeStore) ListOverrides(ctx context.Context, orgID string, limit int) ([]*models.FreezeOverride, error) {
+	q := newSelect(`id, org_id, window_id, deployment_id, app_id, service_name, user_id, reason, created_at`,
+		"deploy_freeze_overrides").
+		Where("org_id = ?", orgID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "freeze override", q, scanFreezeOverride)
+}
+
+// scanFreezeWindow reads a single freeze window row selected with freezeWindowColumns.
+func scanFreezeWindow(row rowScanner) (*models.FreezeWindow, error) {
+	var w models.FreezeWindow
+	var kind string
+	var startsAt, endsAt sql.NullTime
+
+	if err := row.Scan(
+		&w.ID, &w.OrgID, &w.Name, &kind, &w.StartDay, &w.StartTime, &w.EndDay, &w.EndTime,
+		&w.Timezone, &startsAt, &endsAt, &w.CreatedBy, &w.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	w.Kind = models.FreezeWindowKind(kind)
+	if startsAt.Valid {
+		w.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		w.EndsAt = &endsAt.Time
+	}
+	return &w, nil
+}
+
+// scanFreezeOverride reads a single freeze override row.
+func scanFreezeOverride(row rowScanner) (*models.FreezeOverride, error) {
+	var o models.FreezeOverride
+	var windowID, deploymentID sql.NullString
+
+	if err := row.Scan(
+		&o.ID, &o.OrgID, &windowID, &deploymentID, &o.AppID, &o.ServiceName,
+		&o.UserID, &o.Reason, &o.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	o.WindowID = windowID.String
+	o.DeploymentID = deploymentID.String
+	return &o, nil
+}
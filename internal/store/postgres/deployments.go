@@ -17,14 +17,12 @@ type DeploymentStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *DeploymentStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new deployment.
@@ -44,11 +42,21 @@ func (s *DeploymentStore) Create(ctx context.Context, deployment *models.Deploym
 		return fmt.Errorf("marshaling resources: %w", err)
 	}
 
+	canaryJSON, err := json.Marshal(deployment.Canary)
+	if err != nil {
+		return fmt.Errorf("marshaling canary: %w", err)
+	}
+
+	healthJSON, err := json.Marshal(deployment.Health)
+	if err != nil {
+		return fmt.Errorf("marshaling health: %w", err)
+	}
+
 	query := `
 		INSERT INTO deployments (id, app_id, service_name, version, git_ref, git_commit, 
 			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			created_at, updated_at, started_at, finished_at, rollback_of, canary, health, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now().UTC()
@@ -64,6 +72,11 @@ func (s *DeploymentStore) Create(ctx context.Context, deployment *models.Deploym
 		nodeID = &deployment.NodeID
 	}
 
+	var rollbackOf *string
+	if deployment.RollbackOf != "" {
+		rollbackOf = &deployment.RollbackOf
+	}
+
 	err = s.conn().QueryRowContext(ctx, query,
 		deployment.ID,
 		deployment.AppID,
@@ -82,6 +95,10 @@ func (s *DeploymentStore) Create(ctx context.Context, deployment *models.Deploym
 		deployment.UpdatedAt,
 		deployment.StartedAt,
 		deployment.FinishedAt,
+		rollbackOf,
+		canaryJSON,
+		healthJSON,
+		deployment.Error,
 	).Scan(&deployment.ID, &deployment.CreatedAt, &deployment.UpdatedAt)
 
 	if err != nil {
@@ -91,135 +108,41 @@ func (s *DeploymentStore) Create(ctx context.Context, deployment *models.Deploym
 	return nil
 }
 
+// deploymentColumns lists the columns read by scanDeployment.
+const deploymentColumns = `id, app_id, service_name, version, git_ref, git_commit,
+	build_type, artifact, status, node_id, resources, config, depends_on,
+	created_at, updated_at, started_at, finished_at, rollback_of, canary, health, error`
+
 // Get retrieves a deployment by ID.
 func (s *DeploymentStore) Get(ctx context.Context, id string) (*models.Deployment, error) {
-	query := `
-		SELECT id, app_id, service_name, version, git_ref, git_commit, 
-			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at
-		FROM deployments
-		WHERE id = $1`
-
-	deployment := &models.Deployment{}
-	var configJSON []byte
-	var dependsOnJSON []byte
-	var resourcesJSON []byte
-	var nodeID sql.NullString
-	var startedAt, finishedAt sql.NullTime
-
-	err := s.conn().QueryRowContext(ctx, query, id).Scan(
-		&deployment.ID,
-		&deployment.AppID,
-		&deployment.ServiceName,
-		&deployment.Version,
-		&deployment.GitRef,
-		&deployment.GitCommit,
-		&deployment.BuildType,
-		&deployment.Artifact,
-		&deployment.Status,
-		&nodeID,
-		&resourcesJSON,
-		&configJSON,
-		&dependsOnJSON,
-		&deployment.CreatedAt,
-		&deployment.UpdatedAt,
-		&startedAt,
-		&finishedAt,
-	)
+	query, args := newSelect(deploymentColumns, "deployments").Where("id = ?", id).Build()
 
+	deployment, err := scanDeployment(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying deployment: %w", err)
 	}
-
-	if nodeID.Valid {
-		deployment.NodeID = nodeID.String
-	}
-	if startedAt.Valid {
-		deployment.StartedAt = &startedAt.Time
-	}
-	if finishedAt.Valid {
-		deployment.FinishedAt = &finishedAt.Time
-	}
-
-	if len(configJSON) > 0 {
-		if err := json.Unmarshal(configJSON, &deployment.Config); err != nil {
-			return nil, fmt.Errorf("unmarshaling config: %w", err)
-		}
-	}
-
-	if len(dependsOnJSON) > 0 {
-		if err := json.Unmarshal(dependsOnJSON, &deployment.DependsOn); err != nil {
-			return nil, fmt.Errorf("unmarshaling depends_on: %w", err)
-		}
-	}
-
-	if len(resourcesJSON) > 0 {
-		if err := json.Unmarshal(resourcesJSON, &deployment.Resources); err != nil {
-			return nil, fmt.Errorf("unmarshaling resources: %w", err)
-		}
-	}
-
 	return deployment, nil
 }
 
 // List retrieves all deployments for a given application, ordered by created_at DESC.
 func (s *DeploymentStore) List(ctx context.Context, appID string) ([]*models.Deployment, error) {
-	query := `
-		SELECT id, app_id, service_name, version, git_ref, git_commit, 
-			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at
-		FROM deployments
-		WHERE app_id = $1
-		ORDER BY created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, appID)
-	if err != nil {
-		return nil, fmt.Errorf("querying deployments: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanDeployments(rows)
+	q := newSelect(deploymentColumns, "deployments").Where("app_id = ?", appID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "deployment", q, scanDeployment)
 }
 
 // ListByNode retrieves all deployments assigned to a given node.
 func (s *DeploymentStore) ListByNode(ctx context.Context, nodeID string) ([]*models.Deployment, error) {
-	query := `
-		SELECT id, app_id, service_name, version, git_ref, git_commit, 
-			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at
-		FROM deployments
-		WHERE node_id = $1
-		ORDER BY created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, nodeID)
-	if err != nil {
-		return nil, fmt.Errorf("querying deployments by node: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanDeployments(rows)
+	q := newSelect(deploymentColumns, "deployments").Where("node_id = ?", nodeID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "deployment", q, scanDeployment)
 }
 
 // ListByStatus retrieves all deployments with a given status.
 func (s *DeploymentStore) ListByStatus(ctx context.Context, status models.DeploymentStatus) ([]*models.Deployment, error) {
-	query := `
-		SELECT id, app_id, service_name, version, git_ref, git_commit, 
-			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at
-		FROM deployments
-		WHERE status = $1
-		ORDER BY created_at ASC`
-
-	rows, err := s.conn().QueryContext(ctx, query, status)
-	if err != nil {
-		return nil, fmt.Errorf("querying deployments by status: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanDeployments(rows)
+	q := newSelect(deploymentColumns, "deployments").Where("status = ?", status).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "deployment", q, scanDeployment)
 }
 
 // Update updates an existing deployment.
@@ -239,12 +162,22 @@ func (s *DeploymentStore) Update(ctx context.Context, deployment *models.Deploym
 		return fmt.Errorf("marshaling resources: %w", err)
 	}
 
+	canaryJSON, err := json.Marshal(deployment.Canary)
+	if err != nil {
+		return fmt.Errorf("marshaling canary: %w", err)
+	}
+
+	healthJSON, err := json.Marshal(deployment.Health)
+	if err != nil {
+		return fmt.Errorf("marshaling health: %w", err)
+	}
+
 	query := `
 		UPDATE deployments
 		SET service_name = $2, version = $3, git_ref = $4, git_commit = $5,
 			build_type = $6, artifact = $7, status = $8, node_id = $9,
 			resources = $10, config = $11, depends_on = $12, updated_at = $13,
-			started_at = $14, finished_at = $15
+			started_at = $14, finished_at = $15, canary = $16, health = $17, error = $18
 		WHERE id = $1`
 
 	deployment.UpdatedAt = time.Now().UTC()
@@ -270,6 +203,9 @@ func (s *DeploymentStore) Update(ctx context.Context, deployment *models.Deploym
 		deployment.UpdatedAt,
 		deployment.StartedAt,
 		deployment.FinishedAt,
+		canaryJSON,
+		healthJSON,
+		deployment.Error,
 	)
 	if err != nil {
 		return fmt.Errorf("updating deployment: %w", err)
@@ -289,98 +225,30 @@ func (s *DeploymentStore) Update(ctx context.Context, deployment *models.Deploym
 
 // GetLatestSuccessful retrieves the most recent successful deployment for an app.
 func (s *DeploymentStore) GetLatestSuccessful(ctx context.Context, appID string) (*models.Deployment, error) {
-	query := `
-		SELECT id, app_id, service_name, version, git_ref, git_commit, 
-			build_type, artifact, status, node_id, resources, config, depends_on,
-			created_at, updated_at, started_at, finished_at
-		FROM deployments
-		WHERE app_id = $1 AND status = 'running'
-		ORDER BY created_at DESC
-		LIMIT 1`
-
-	deployment := &models.Deployment{}
-	var configJSON []byte
-	var dependsOnJSON []byte
-	var resourcesJSON []byte
-	var nodeID sql.NullString
-	var startedAt, finishedAt sql.NullTime
-
-	err := s.conn().QueryRowContext(ctx, query, appID).Scan(
-		&deployment.ID,
-		&deployment.AppID,
-		&deployment.ServiceName,
-		&deployment.Version,
-		&deployment.GitRef,
-		&deployment.GitCommit,
-		&deployment.BuildType,
-		&deployment.Artifact,
-		&deployment.Status,
-		&nodeID,
-		&resourcesJSON,
-		&configJSON,
-		&dependsOnJSON,
-		&deployment.CreatedAt,
-		&deployment.UpdatedAt,
-		&startedAt,
-		&finishedAt,
-	)
+	query, args := newSelect(deploymentColumns, "deployments").
+		Where("app_id = ? AND status = ?", appID, models.DeploymentStatusRunning).
+		OrderBy("created_at DESC").
+		Page(1, 0).
+		Build()
 
+	deployment, err := scanDeployment(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying latest successful deployment: %w", err)
 	}
-
-	if nodeID.Valid {
-		deployment.NodeID = nodeID.String
-	}
-	if startedAt.Valid {
-		deployment.StartedAt = &startedAt.Time
-	}
-	if finishedAt.Valid {
-		deployment.FinishedAt = &finishedAt.Time
-	}
-
-	if len(configJSON) > 0 {
-		if err := json.Unmarshal(configJSON, &deployment.Config); err != nil {
-			return nil, fmt.Errorf("unmarshaling config: %w", err)
-		}
-	}
-
-	if len(dependsOnJSON) > 0 {
-		if err := json.Unmarshal(dependsOnJSON, &deployment.DependsOn); err != nil {
-			return nil, fmt.Errorf("unmarshaling depends_on: %w", err)
-		}
-	}
-
-	if len(resourcesJSON) > 0 {
-		if err := json.Unmarshal(resourcesJSON, &deployment.Resources); err != nil {
-			return nil, fmt.Errorf("unmarshaling resources: %w", err)
-		}
-	}
-
 	return deployment, nil
 }
 
 // ListByUser retrieves all deployments for all apps owned by a given user.
+// Deployments of soft-deleted apps are excluded.
 func (s *DeploymentStore) ListByUser(ctx context.Context, userID string) ([]*models.Deployment, error) {
-	query := `
-		SELECT d.id, d.app_id, d.service_name, d.version, d.git_ref, d.git_commit, 
-			d.build_type, d.artifact, d.status, d.node_id, d.resources, d.config, d.depends_on,
-			d.created_at, d.updated_at, d.started_at, d.finished_at
-		FROM deployments d
-		JOIN apps a ON d.app_id = a.id
-		WHERE a.owner_id = $1
-		ORDER BY d.created_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, userID)
-	if err != nil {
-		return nil, fmt.Errorf("querying deployments by user: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanDeployments(rows)
+	q := newSelect(qualifyColumns("d", deploymentColumns), "deployments d JOIN apps a ON d.app_id = a.id").
+		Where("a.owner_id = ?", userID).
+		NotDeleted("a").
+		OrderBy("d.created_at DESC")
+	return listRows(ctx, s.conn(), "deployment", q, scanDeployment)
 }
 
 // GetNextVersion returns the next version number for a service.
@@ -420,75 +288,84 @@ func (s *DeploymentStore) CountByStatusAndOrg(ctx context.Context, status models
 	return count, nil
 }
 
-// scanDeployments scans multiple deployment rows.
-func (s *DeploymentStore) scanDeployments(rows *sql.Rows) ([]*models.Deployment, error) {
-	var deployments []*models.Deployment
-
-	for rows.Next() {
-		deployment := &models.Deployment{}
-		var configJSON []byte
-		var dependsOnJSON []byte
-		var resourcesJSON []byte
-		var nodeID sql.NullString
-		var startedAt, finishedAt sql.NullTime
-
-		err := rows.Scan(
-			&deployment.ID,
-			&deployment.AppID,
-			&deployment.ServiceName,
-			&deployment.Version,
-			&deployment.GitRef,
-			&deployment.GitCommit,
-			&deployment.BuildType,
-			&deployment.Artifact,
-			&deployment.Status,
-			&nodeID,
-			&resourcesJSON,
-			&configJSON,
-			&dependsOnJSON,
-			&deployment.CreatedAt,
-			&deployment.UpdatedAt,
-			&startedAt,
-			&finishedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scanning deployment row: %w", err)
-		}
+// scanDeployment reads a single deployment row selected with deploymentColumns.
+func scanDeployment(row rowScanner) (*models.Deployment, error) {
+	deployment := &models.Deployment{}
+	var configJSON []byte
+	var dependsOnJSON []byte
+	var resourcesJSON []byte
+	var canaryJSON []byte
+	var healthJSON []byte
+	var nodeID, rollbackOf sql.NullString
+	var startedAt, finishedAt sql.NullTime
 
-		if nodeID.Valid {
-			deployment.NodeID = nodeID.String
-		}
-		if startedAt.Valid {
-			deployment.StartedAt = &startedAt.Time
-		}
-		if finishedAt.Valid {
-			deployment.FinishedAt = &finishedAt.Time
-		}
+	err := row.Scan(
+		&deployment.ID,
+		&deployment.AppID,
+		&deployment.ServiceName,
+		&deployment.Version,
+		&deployment.GitRef,
+		&deployment.GitCommit,
+		&deployment.BuildType,
+		&deployment.Artifact,
+		&deployment.Status,
+		&nodeID,
+		&resourcesJSON,
+		&configJSON,
+		&dependsOnJSON,
+		&deployment.CreatedAt,
+		&deployment.UpdatedAt,
+		&startedAt,
+		&finishedAt,
+		&rollbackOf,
+		&canaryJSON,
+		&healthJSON,
+		&deployment.Error,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(configJSON) > 0 {
-			if err := json.Unmarshal(configJSON, &deployment.Config); err != nil {
-				return nil, fmt.Errorf("unmarshaling config: %w", err)
-			}
+	if nodeID.Valid {
+		deployment.NodeID = nodeID.String
+	}
+	deployment.RollbackOf = rollbackOf.String
+	if startedAt.Valid {
+		deployment.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		deployment.FinishedAt = &finishedAt.Time
+	}
+
+	if len(configJSON) > 0 {
+		if err := json.Unmarshal(configJSON, &deployment.Config); err != nil {
+			return nil, fmt.Errorf("unmarshaling config: %w", err)
 		}
+	}
 
-		if len(dependsOnJSON) > 0 {
-			if err := json.Unmarshal(dependsOnJSON, &deployment.DependsOn); err != nil {
-				return nil, fmt.Errorf("unmarshaling depends_on: %w", err)
-			}
+	if len(dependsOnJSON) > 0 {
+		if err := json.Unmarshal(dependsOnJSON, &deployment.DependsOn); err != nil {
+			return nil, fmt.Errorf("unmarshaling depends_on: %w", err)
 		}
+	}
 
-		if len(resourcesJSON) > 0 {
-			if err := json.Unmarshal(resourcesJSON, &deployment.Resources); err != nil {
-				return nil, fmt.Errorf("unmarshaling resources: %w", err)
-			}
+	if len(resourcesJSON) > 0 {
+		if err := json.Unmarshal(resourcesJSON, &deployment.Resources); err != nil {
+			return nil, fmt.Errorf("unmarshaling resources: %w", err)
 		}
+	}
 
-		deployments = append(deployments, deployment)
+	if len(canaryJSON) > 0 {
+		if err := json.Unmarshal(canaryJSON, &deployment.Canary); err != nil {
+			return nil, fmt.Errorf("unmarshaling canary: %w", err)
+		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating deployment rows: %w", err)
+	if len(healthJSON) > 0 {
+		if err := json.Unmarshal(healthJSON, &deployment.Health); err != nil {
+			return nil, fmt.Errorf("unmarshaling health: %w", err)
+		}
 	}
 
-	return deployments, nil
+	return deployment, nil
 }
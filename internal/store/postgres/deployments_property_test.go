@@ -52,6 +52,7 @@ func runDeploymentMigrations(db *sql.DB) error {
 	_, _ = db.Exec("DROP TABLE IF EXISTS builds CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS deployments CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS nodes CASCADE")
+	_, _ = db.Exec("DROP TABLE IF EXISTS services CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS apps CASCADE")
 
 	schema := `
@@ -71,6 +72,18 @@ func runDeploymentMigrations(db *sql.DB) error {
 			deleted_at TIMESTAMPTZ
 		);
 
+		CREATE TABLE services (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+			name VARCHAR(63) NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			config JSONB NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CONSTRAINT services_app_name_unique UNIQUE (app_id, name)
+		);
+
 		CREATE TABLE deployments (
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
 			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
@@ -82,7 +95,7 @@ func runDeploymentMigrations(db *sql.DB) error {
 			artifact TEXT,
 			status VARCHAR(20) NOT NULL CHECK (status IN (
 				'pending', 'building', 'built', 'scheduled', 
-				'starting', 'running', 'stopping', 'stopped', 'failed'
+				'starting', 'running', 'stopping', 'stopped', 'failed', 'canceled'
 			)),
 			node_id UUID,
 			resources JSONB,
@@ -91,7 +104,11 @@ func runDeploymentMigrations(db *sql.DB) error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			started_at TIMESTAMPTZ,
-			finished_at TIMESTAMPTZ
+			finished_at TIMESTAMPTZ,
+			rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL,
+			canary JSONB,
+			health JSONB,
+			error TEXT NOT NULL DEFAULT ''
 		);
 
 		CREATE INDEX idx_deployments_app_id ON deployments(app_id);
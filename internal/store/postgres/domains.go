@@ -105,6 +105,29 @@ func (s *domainStore) List(ctx context.Context, appID string) ([]*models.Domain,
 	return domains, nil
 }
 
+func (s *domainStore) Update(ctx context.Context, domain *models.Domain) error {
+	query := `
+		UPDATE domains SET service = $2, updated_at = $3
+		WHERE id = $1
+	`
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx, query, domain.ID, domain.Service, now)
+	if err != nil {
+		return fmt.Errorf("updating domain: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("domain not found")
+	}
+
+	domain.UpdatedAt = now
+	return nil
+}
+
 func (s *domainStore) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM domains WHERE id = $1`
 	result, err := s.db.ExecContext(ctx, query, id)
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// EgressViolationStore implements store.EgressViolationStore using PostgreSQL.
+type EgressViolationStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *EgressViolationStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Record upserts violation counters in a single statement. Counts reported by
+// agents are cumulative for the deployment, so they replace the stored count
+// rather than adding to it; a lower count (e.g. after an agent restart) never
+// moves the counter backwards.
+func (s *EgressViolationStore) Record(ctx context.Context, violations []*models.EgressViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	deploymentIDs := make([]string, len(violations))
+	appIDs := make([]string, len(violations))
+	serviceNames := make([]string, len(violations))
+	destinations := make([]string, len(violations))
+	ports := make([]int64, len(violations))
+	protocols := make([]string, len(violations))
+	counts := make([]int64, len(violations))
+	lastSeen := make([]string, len(violations))
+
+	now := time.Now().UTC()
+	for i, v := range violations {
+		if v.LastSeenAt.IsZero() {
+			v.LastSeenAt = now
+		}
+		deploymentIDs[i] = v.DeploymentID
+		appIDs[i] = v.AppID
+		serviceNames[i] = v.ServiceName
+		destinations[i] = v.Destination
+		ports[i] = int64(v.Port)
+		protocols[i] = v.Protocol
+		counts[i] = v.Count
+		lastSeen[i] = v.LastSeenAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := `
+		INSERT INTO egress_violations (deployment_id, app_id, service_name, destination, port, protocol, count, first_seen_at, last_seen_at)
+		SELECT d, a, sn, dst, p, pr, c, ls, ls
+		FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[], $5::int[], $6::text[], $7::bigint[], $8::timestamptz[])
+			AS v(d, a, sn, dst, p, pr, c, ls)
+		ON CONFLICT (deployment_id, destination, port, protocol) DO UPDATE SET
+			count = GREATEST(egress_violations.count, EXCLUDED.count),
+			last_seen_at = GREATEST(egress_violations.last_seen_at, EXCLUDED.last_seen_at)`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		pq.Array(deploymentIDs),
+		pq.Array(appIDs),
+		pq.Array(serviceNames),
+		pq.Array(destinations),
+		pq.Array(ports),
+		pq.Array(protocols),
+		pq.Array(counts),
+		pq.Array(lastSeen),
+	)
+	if err != nil {
+		return fmt.Errorf("recording egress violations: %w", err)
+	}
+	return nil
+}
+
+// egressViolationColumns lists the columns read by scanEgressViolation.
+const egressViolationColumns = `deployment_id, app_id, service_name, destination, port, protocol,
+	count, first_seen_at, last_seen_at`
+
+// ListByService retrieves violations for a service seen since the given time,
+// most recent first.
+func (s *EgressViolationStore) ListByService(ctx context.Context, appID, serviceName string, since time.Time) ([]*models.EgressViolation, error) {
+	q := newSelect(egressViolationColumns, "egress_violations").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		Where("last_seen_at >= ?", since).
+		OrderBy("last_seen_at DESC")
+	return listRows(ctx, s.conn(), "egress violation", q, scanEgressViolation)
+}
+
+// scanEgressViolation reads a single egress violation row.
+func scanEgressViolation(row rowScanner) (*models.EgressViolation, error) {
+	var v models.EgressViolation
+	if err := row.Scan(
+		&v.DeploymentID, &v.AppID, &v.ServiceName, &v.Destination, &v.Port, &v.Protocol,
+		&v.Count, &v.FirstSeenAt, &v.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
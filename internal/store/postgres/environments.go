@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// EnvironmentStore implements store.EnvironmentStore using PostgreSQL.
+type EnvironmentStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *EnvironmentStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// environmentColumns lists the columns read by scanEnvironment.
+const environmentColumns = `id, app_id, name, position, env_app_id, require_approval, created_at, updated_at`
+
+// Create stores a new environment.
+func (s *EnvironmentStore) Create(ctx context.Context, env *models.Environment) error {
+	if env.ID == "" {
+		env.ID = uuid.New().String()
+	}
+	now := time.Now()
+	env.CreatedAt, env.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO environments (id, app_id, name, position, env_app_id, require_approval, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		env.ID, env.AppID, env.Name, env.Position, env.EnvAppID, env.RequireApproval, env.CreatedAt, env.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting environment: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting environment: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an environment by ID. It returns nil if the environment does not exist.
+func (s *EnvironmentStore) Get(ctx context.Context, id string) (*models.Environment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(environmentColumns, "environments").Where("id = ?", id).Build()
+	return s.getOne(ctx, query, args)
+}
+
+// GetByEnvApp retrieves the environment backed by an app. It returns nil if
+// the app backs no environment.
+func (s *EnvironmentStore) GetByEnvApp(ctx context.Context, envAppID string) (*models.Environment, error) {
+	if _, err := uuid.Parse(envAppID); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(environmentColumns, "environments").Where("env_app_id = ?", envAppID).Build()
+	return s.getOne(ctx, query, args)
+}
+
+func (s *EnvironmentStore) getOne(ctx context.Context, query string, args []any) (*models.Environment, error) {
+	env, err := scanEnvironment(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying environment: %w", err)
+	}
+	return env, nil
+}
+
+// List retrieves an app's environments ordered by position.
+func (s *EnvironmentStore) List(ctx context.Context, appID string) ([]*models.Environment, error) {
+	q := newSelect(environmentColumns, "environments").
+		Where("app_id = ?", appID).
+		OrderBy("position ASC")
+	return listRows(ctx, s.conn(), "environment", q, scanEnvironment)
+}
+
+// Update saves an environment's name, position and approval requirement.
+func (s *EnvironmentStore) Update(ctx context.Context, env *models.Environment) error {
+	env.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE environments
+		SET name = $1, position = $2, require_approval = $3, updated_at = $4
+		WHERE id = $5
+	`
+	result, err := s.conn().ExecContext(ctx, query, env.Name, env.Position, env.RequireApproval, env.UpdatedAt, env.ID)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("updating environment: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("updating environment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes an environment and the promotions into it.
+func (s *EnvironmentStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM environments WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("deleting environment: %w", err)
+	}
+	return nil
+}
+
+func scanEnvironment(row rowScanner) (*models.Environment, error) {
+	var e models.Environment
+	if err := row.Scan(
+		&e.ID, &e.AppID, &e.Name, &e.Position, &e.EnvAppID, &e.RequireApproval, &e.CreatedAt, &e.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// eventsChannel is the notification channel the triggers of migration 083
+// send changes on.
+const eventsChannel = "narvana_events"
+
+// EventStore implements store.EventStore with Postgres notifications.
+type EventStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+// Listen listens for changes on a connection of its own, calling fn with
+// each. The connection is closed rather than returned to the pool, which
+// would otherwise keep listening.
+func (s *EventStore) Listen(ctx context.Context, fn func(*models.Event)) error {
+	if s.tx != nil {
+		return errors.New("cannot listen for events in a transaction")
+	}
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("getting connection: %w", err)
+	}
+	defer conn.Close()
+
+	var listenErr error
+	conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			listenErr = fmt.Errorf("unexpected driver connection %T", driverConn)
+			return driver.ErrBadConn
+		}
+		pgConn := c.Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+			listenErr = fmt.Errorf("listening on %s: %w", eventsChannel, err)
+			return driver.ErrBadConn
+		}
+		for {
+			n, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				listenErr = fmt.Errorf("waiting for notification: %w", err)
+				return driver.ErrBadConn
+			}
+			var event models.Event
+			if err := json.Unmarshal([]byte(n.Payload), &event); err != nil {
+				s.logger.Warn("invalid event notification", "error", err, "payload", n.Payload)
+				continue
+			}
+			fn(&event)
+		}
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return listenErr
+}
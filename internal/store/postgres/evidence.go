@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// EvidenceExportStore implements store.EvidenceExportStore using PostgreSQL.
+type EvidenceExportStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *EvidenceExportStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// evidenceExportColumns lists the columns read by scanEvidenceExport; the
+// archive is only read by GetArchive.
+const evidenceExportColumns = `id, app_id, since, until, operation_id, counts, sha256, size_bytes, key_id, created_by, created_at`
+
+// Create stores a new export with its archive.
+func (s *EvidenceExportStore) Create(ctx context.Context, export *models.EvidenceExport, archive []byte) error {
+	if export.ID == "" {
+		export.ID = uuid.New().String()
+	}
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = time.Now()
+	}
+	counts, err := json.Marshal(export.Counts)
+	if err != nil {
+		return fmt.Errorf("encoding evidence export counts: %w", err)
+	}
+
+	query := `
+		INSERT INTO evidence_exports (id, app_id, since, until, operation_id, counts, sha256, size_bytes, key_id, archive, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		export.ID, export.AppID, export.Since, export.Until, export.OperationID, counts,
+		export.SHA256, export.SizeBytes, export.KeyID, archive, export.CreatedBy, export.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting evidence export: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an export by ID, without its archive. It returns nil if the
+// export does not exist.
+func (s *EvidenceExportStore) Get(ctx context.Context, id string) (*models.EvidenceExport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(evidenceExportColumns, "evidence_exports").Where("id = ?", id).Build()
+
+	export, err := scanEvidenceExport(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying evidence export: %w", err)
+	}
+	return export, nil
+}
+
+// GetArchive retrieves the archive of an export. It returns nil if the
+// export does not exist.
+func (s *EvidenceExportStore) GetArchive(ctx context.Context, id string) ([]byte, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	var archive []byte
+	err := s.conn().QueryRowContext(ctx, `SELECT archive FROM evidence_exports WHERE id = $1`, id).Scan(&archive)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying evidence archive: %w", err)
+	}
+	return archive, nil
+}
+
+// ListByApp retrieves an app's exports, newest first, without their archives.
+func (s *EvidenceExportStore) ListByApp(ctx context.Context, appID string) ([]*models.EvidenceExport, error) {
+	q := newSelect(evidenceExportColumns, "evidence_exports").Where("app_id = ?", appID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "evidence export", q, scanEvidenceExport)
+}
+
+// Delete removes an export.
+func (s *EvidenceExportStore) Delete(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM evidence_exports WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting evidence export: %w", err)
+	}
+	return nil
+}
+
+func scanEvidenceExport(row rowScanner) (*models.EvidenceExport, error) {
+	var e models.EvidenceExport
+	var counts []byte
+	if err := row.Scan(
+		&e.ID, &e.AppID, &e.Since, &e.Until, &e.OperationID, &counts,
+		&e.SHA256, &e.SizeBytes, &e.KeyID, &e.CreatedBy, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(counts, &e.Counts); err != nil {
+		return nil, fmt.Errorf("decoding evidence export counts: %w", err)
+	}
+	return &e, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// FederationStore implements store.FederationStore using PostgreSQL.
+type FederationStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *FederationStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// federationPeerColumns lists the columns read by scanFederationPeer.
+const federationPeerColumns = `id, name, url, web_url, api_key, created_by, created_at, updated_at`
+
+// CreatePeer registers a new peer.
+func (s *FederationStore) CreatePeer(ctx context.Context, peer *models.FederationPeer) error {
+	if peer.ID == "" {
+		peer.ID = uuid.New().String()
+	}
+	now := time.Now()
+	peer.CreatedAt, peer.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO federation_peers (id, name, url, web_url, api_key, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		peer.ID, peer.Name, peer.URL, peer.WebURL, peer.APIKey, peer.CreatedBy, peer.CreatedAt, peer.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting federation peer: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting federation peer: %w", err)
+	}
+	return nil
+}
+
+// GetPeer retrieves a peer by ID. It returns nil if the peer does not exist.
+func (s *FederationStore) GetPeer(ctx context.Context, id string) (*models.FederationPeer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(federationPeerColumns, "federation_peers").Where("id = ?", id).Build()
+
+	peer, err := scanFederationPeer(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying federation peer: %w", err)
+	}
+	return peer, nil
+}
+
+// ListPeers retrieves every peer, ordered by name.
+func (s *FederationStore) ListPeers(ctx context.Context) ([]*models.FederationPeer, error) {
+	q := newSelect(federationPeerColumns, "federation_peers").OrderBy("name ASC")
+	return listRows(ctx, s.conn(), "federation peer", q, scanFederationPeer)
+}
+
+// DeletePeer removes a peer.
+func (s *FederationStore) DeletePeer(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM federation_peers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting federation peer: %w", err)
+	}
+	return nil
+}
+
+func scanFederationPeer(row rowScanner) (*models.FederationPeer, error) {
+	var p models.FederationPeer
+	if err := row.Scan(
+		&p.ID, &p.Name, &p.URL, &p.WebURL, &p.APIKey, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
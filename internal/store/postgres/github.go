@@ -16,13 +16,11 @@ type GitHubStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 func (s *GitHubStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // GetConfig retrieves the GitHub App configuration.
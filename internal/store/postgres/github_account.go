@@ -15,13 +15,11 @@ type GitHubAccountStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 func (s *GitHubAccountStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create saves a new GitHub account.
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// IdempotencyKeyStore implements store.IdempotencyKeyStore using PostgreSQL.
+type IdempotencyKeyStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *IdempotencyKeyStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Begin records a request in progress under its user's key, or returns the
+// earlier request that used the key.
+func (s *IdempotencyKeyStore) Begin(ctx context.Context, k *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now()
+	}
+	result, err := s.conn().ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, method, path, request_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO NOTHING
+	`, k.UserID, k.Key, k.Method, k.Path, k.RequestHash, k.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("recording idempotency key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 1 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	var headersJSON []byte
+	err = s.conn().QueryRowContext(ctx, `
+		SELECT user_id, key, method, path, request_hash, status, headers, response, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`, k.UserID, k.Key).Scan(
+		&existing.UserID, &existing.Key, &existing.Method, &existing.Path, &existing.RequestHash,
+		&existing.Status, &headersJSON, &existing.Response, &existing.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		// Deleted since the insert conflicted; the client may retry
+		return nil, fmt.Errorf("idempotency key %s was released concurrently", k.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying idempotency key: %w", err)
+	}
+	if err := json.Unmarshal(headersJSON, &existing.Headers); err != nil {
+		return nil, fmt.Errorf("unmarshaling response headers: %w", err)
+	}
+	return &existing, nil
+}
+
+// Complete records the response to a request in progress.
+func (s *IdempotencyKeyStore) Complete(ctx context.Context, userID, key string, status int, headers map[string]string, response []byte) error {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("marshaling response headers: %w", err)
+	}
+	_, err = s.conn().ExecContext(ctx, `
+		UPDATE idempotency_keys SET status = $3, headers = $4, response = $5
+		WHERE user_id = $1 AND key = $2
+	`, userID, key, status, headersJSON, response)
+	if err != nil {
+		return fmt.Errorf("recording idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Delete forgets a user's key.
+func (s *IdempotencyKeyStore) Delete(ctx context.Context, userID, key string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+	if err != nil {
+		return fmt.Errorf("deleting idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore forgets the keys used before a time.
+func (s *IdempotencyKeyStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
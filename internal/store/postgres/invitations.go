@@ -16,13 +16,11 @@ type InvitationStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 func (s *InvitationStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new invitation.
@@ -38,8 +36,8 @@ func (s *InvitationStore) Create(ctx context.Context, invitation *models.Invitat
 	}
 
 	query := `
-		INSERT INTO invitations (id, email, token, invited_by, role, status, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO invitations (id, email, token, invited_by, role, status, expires_at, created_at, org_id, org_role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := s.conn().ExecContext(ctx, query,
@@ -51,6 +49,8 @@ func (s *InvitationStore) Create(ctx context.Context, invitation *models.Invitat
 		string(invitation.Status),
 		invitation.ExpiresAt,
 		invitation.CreatedAt,
+		nullString(invitation.OrgID),
+		nullString(string(invitation.OrgRole)),
 	)
 	return err
 }
@@ -58,17 +58,18 @@ func (s *InvitationStore) Create(ctx context.Context, invitation *models.Invitat
 // Get retrieves an invitation by ID.
 func (s *InvitationStore) Get(ctx context.Context, id string) (*models.Invitation, error) {
 	query := `
-		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at
+		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at, org_id, org_role
 		FROM invitations WHERE id = $1
 	`
 
 	var inv models.Invitation
 	var role, status string
 	var acceptedAt sql.NullTime
+	var orgID, orgRole sql.NullString
 
 	err := s.conn().QueryRowContext(ctx, query, id).Scan(
 		&inv.ID, &inv.Email, &inv.Token, &inv.InvitedBy,
-		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt,
+		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt, &orgID, &orgRole,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -79,6 +80,8 @@ func (s *InvitationStore) Get(ctx context.Context, id string) (*models.Invitatio
 
 	inv.Role = models.Role(role)
 	inv.Status = models.InvitationStatus(status)
+	inv.OrgID = orgID.String
+	inv.OrgRole = models.Role(orgRole.String)
 	if acceptedAt.Valid {
 		inv.AcceptedAt = &acceptedAt.Time
 	}
@@ -89,17 +92,18 @@ func (s *InvitationStore) Get(ctx context.Context, id string) (*models.Invitatio
 // GetByToken retrieves an invitation by its token.
 func (s *InvitationStore) GetByToken(ctx context.Context, token string) (*models.Invitation, error) {
 	query := `
-		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at
+		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at, org_id, org_role
 		FROM invitations WHERE token = $1
 	`
 
 	var inv models.Invitation
 	var role, status string
 	var acceptedAt sql.NullTime
+	var orgID, orgRole sql.NullString
 
 	err := s.conn().QueryRowContext(ctx, query, token).Scan(
 		&inv.ID, &inv.Email, &inv.Token, &inv.InvitedBy,
-		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt,
+		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt, &orgID, &orgRole,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -110,6 +114,8 @@ func (s *InvitationStore) GetByToken(ctx context.Context, token string) (*models
 
 	inv.Role = models.Role(role)
 	inv.Status = models.InvitationStatus(status)
+	inv.OrgID = orgID.String
+	inv.OrgRole = models.Role(orgRole.String)
 	if acceptedAt.Valid {
 		inv.AcceptedAt = &acceptedAt.Time
 	}
@@ -120,7 +126,7 @@ func (s *InvitationStore) GetByToken(ctx context.Context, token string) (*models
 // GetByEmail retrieves a pending invitation by email.
 func (s *InvitationStore) GetByEmail(ctx context.Context, email string) (*models.Invitation, error) {
 	query := `
-		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at
+		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at, org_id, org_role
 		FROM invitations WHERE email = $1 AND status = 'pending'
 		ORDER BY created_at DESC LIMIT 1
 	`
@@ -128,10 +134,11 @@ func (s *InvitationStore) GetByEmail(ctx context.Context, email string) (*models
 	var inv models.Invitation
 	var role, status string
 	var acceptedAt sql.NullTime
+	var orgID, orgRole sql.NullString
 
 	err := s.conn().QueryRowContext(ctx, query, email).Scan(
 		&inv.ID, &inv.Email, &inv.Token, &inv.InvitedBy,
-		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt,
+		&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt, &orgID, &orgRole,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -142,6 +149,8 @@ func (s *InvitationStore) GetByEmail(ctx context.Context, email string) (*models
 
 	inv.Role = models.Role(role)
 	inv.Status = models.InvitationStatus(status)
+	inv.OrgID = orgID.String
+	inv.OrgRole = models.Role(orgRole.String)
 	if acceptedAt.Valid {
 		inv.AcceptedAt = &acceptedAt.Time
 	}
@@ -152,11 +161,24 @@ func (s *InvitationStore) GetByEmail(ctx context.Context, email string) (*models
 // List retrieves all invitations.
 func (s *InvitationStore) List(ctx context.Context) ([]*models.Invitation, error) {
 	query := `
-		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at
+		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at, org_id, org_role
 		FROM invitations ORDER BY created_at DESC
 	`
+	return s.list(ctx, query)
+}
+
+// ListByOrg retrieves all invitations to join an organization.
+func (s *InvitationStore) ListByOrg(ctx context.Context, orgID string) ([]*models.Invitation, error) {
+	query := `
+		SELECT id, email, token, invited_by, role, status, expires_at, accepted_at, created_at, org_id, org_role
+		FROM invitations WHERE org_id = $1 ORDER BY created_at DESC
+	`
+	return s.list(ctx, query, orgID)
+}
 
-	rows, err := s.conn().QueryContext(ctx, query)
+// list runs an invitation query and scans every row.
+func (s *InvitationStore) list(ctx context.Context, query string, args ...any) ([]*models.Invitation, error) {
+	rows, err := s.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -167,16 +189,19 @@ func (s *InvitationStore) List(ctx context.Context) ([]*models.Invitation, error
 		var inv models.Invitation
 		var role, status string
 		var acceptedAt sql.NullTime
+		var orgID, orgRole sql.NullString
 
 		if err := rows.Scan(
 			&inv.ID, &inv.Email, &inv.Token, &inv.InvitedBy,
-			&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt,
+			&role, &status, &inv.ExpiresAt, &acceptedAt, &inv.CreatedAt, &orgID, &orgRole,
 		); err != nil {
 			return nil, err
 		}
 
 		inv.Role = models.Role(role)
 		inv.Status = models.InvitationStatus(status)
+		inv.OrgID = orgID.String
+		inv.OrgRole = models.Role(orgRole.String)
 		if acceptedAt.Valid {
 			inv.AcceptedAt = &acceptedAt.Time
 		}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// LoadTestStore implements store.LoadTestStore using PostgreSQL.
+type LoadTestStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *LoadTestStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const loadTestColumns = `id, app_id, service_name, deployment_id, worker_id, method, path, rps, duration_seconds,
+	timeout_seconds, status, error, result, run_by, created_at, started_at, finished_at`
+
+// Create stores a new test.
+func (s *LoadTestStore) Create(ctx context.Context, test *models.LoadTest) error {
+	if test.ID == "" {
+		test.ID = uuid.New().String()
+	}
+	if test.CreatedAt.IsZero() {
+		test.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO load_tests (id, app_id, service_name, deployment_id, worker_id, method, path, rps,
+			duration_seconds, timeout_seconds, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		test.ID, test.AppID, test.ServiceName, test.DeploymentID, test.WorkerID, test.Method, test.Path, test.RPS,
+		test.DurationSeconds, test.TimeoutSeconds, test.Status, test.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating load test: %w", err)
+	}
+	return nil
+}
+
+// Update saves a test's status, error, result and times.
+func (s *LoadTestStore) Update(ctx context.Context, test *models.LoadTest) error {
+	var resultJSON []byte
+	if test.Result != nil {
+		var err error
+		if resultJSON, err = json.Marshal(test.Result); err != nil {
+			return fmt.Errorf("marshaling load test result: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE load_tests
+		SET status = $2, error = $3, result = $4, run_by = $5, started_at = $6, finished_at = $7
+		WHERE id = $1
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		test.ID, test.Status, test.Error, resultJSON, test.RunBy, test.StartedAt, test.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating load test: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a test by ID. It returns nil if the test does not exist.
+func (s *LoadTestStore) Get(ctx context.Context, id string) (*models.LoadTest, error) {
+	query, args := newSelect(loadTestColumns, "load_tests").Where("id = ?", id).Build()
+	test, err := scanLoadTest(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying load test: %w", err)
+	}
+	return test, nil
+}
+
+// Claim marks the oldest pending test designated to the worker, or to no
+// worker, as running by it and returns it. Uses FOR UPDATE SKIP LOCKED so
+// concurrent workers never claim the same test. It returns nil if there is
+// none.
+func (s *LoadTestStore) Claim(ctx context.Context, workerID string) (*models.LoadTest, error) {
+	query := `
+		UPDATE load_tests SET status = $2, run_by = $1, started_at = NOW()
+		WHERE id = (
+			SELECT id FROM load_tests
+			WHERE status = $3 AND (worker_id = '' OR worker_id = $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + loadTestColumns
+	test, err := scanLoadTest(s.conn().QueryRowContext(ctx, query, workerID, models.LoadTestRunning, models.LoadTestPending))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claiming load test: %w", err)
+	}
+	return test, nil
+}
+
+// ListByDeployment retrieves a deployment's tests, newest first.
+func (s *LoadTestStore) ListByDeployment(ctx context.Context, deploymentID string) ([]*models.LoadTest, error) {
+	q := newSelect(loadTestColumns, "load_tests").
+		Where("deployment_id = ?", deploymentID).
+		OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "load test", q, scanLoadTest)
+}
+
+// ListByService retrieves the tests of all of a service's deployments, newest first.
+func (s *LoadTestStore) ListByService(ctx context.Context, appID, serviceName string, limit int) ([]*models.LoadTest, error) {
+	q := newSelect(loadTestColumns, "load_tests").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "load test", q, scanLoadTest)
+}
+
+// FailOverdue fails running tests that should have finished over a minute
+// ago, e.g. because their worker stopped.
+func (s *LoadTestStore) FailOverdue(ctx context.Context) (int, error) {
+	query := `
+		UPDATE load_tests SET status = $1, error = 'worker stopped before the test finished', finished_at = NOW()
+		WHERE status = $2 AND started_at + make_interval(secs => duration_seconds + timeout_seconds + 60) < NOW()
+	`
+	res, err := s.conn().ExecContext(ctx, query, models.LoadTestFailed, models.LoadTestRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failing overdue load tests: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting overdue load tests: %w", err)
+	}
+	return int(n), nil
+}
+
+// scanLoadTest reads a single load test row.
+func scanLoadTest(row rowScanner) (*models.LoadTest, error) {
+	var test models.LoadTest
+	var resultJSON []byte
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(
+		&test.ID, &test.AppID, &test.ServiceName, &test.DeploymentID, &test.WorkerID, &test.Method, &test.Path,
+		&test.RPS, &test.DurationSeconds, &test.TimeoutSeconds, &test.Status, &test.Error, &resultJSON, &test.RunBy,
+		&test.CreatedAt, &startedAt, &finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(resultJSON) > 0 {
+		if err := json.Unmarshal(resultJSON, &test.Result); err != nil {
+			return nil, fmt.Errorf("unmarshaling load test result: %w", err)
+		}
+	}
+	if startedAt.Valid {
+		test.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		test.FinishedAt = &finishedAt.Time
+	}
+	return &test, nil
+}
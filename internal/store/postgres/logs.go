@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
@@ -15,21 +17,19 @@ type LogStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *LogStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new log entry.
 func (s *LogStore) Create(ctx context.Context, entry *models.LogEntry) error {
 	query := `
-		INSERT INTO logs (id, deployment_id, source, level, message, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO logs (id, deployment_id, source, level, stream, message, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id`
 
 	if entry.Timestamp.IsZero() {
@@ -41,6 +41,7 @@ func (s *LogStore) Create(ctx context.Context, entry *models.LogEntry) error {
 		entry.DeploymentID,
 		entry.Source,
 		entry.Level,
+		entry.Stream,
 		entry.Message,
 		entry.Timestamp,
 	).Scan(&entry.ID)
@@ -52,10 +53,63 @@ func (s *LogStore) Create(ctx context.Context, entry *models.LogEntry) error {
 	return nil
 }
 
+// CreateBatch inserts many log entries in a single statement. Columns are sent
+// as arrays and expanded with unnest, so every batch size shares one prepared
+// statement. Entries without an ID or timestamp get one assigned.
+func (s *LogStore) CreateBatch(ctx context.Context, entries []*models.LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	deploymentIDs := make([]string, len(entries))
+	sources := make([]string, len(entries))
+	levels := make([]string, len(entries))
+	streams := make([]string, len(entries))
+	messages := make([]string, len(entries))
+	timestamps := make([]string, len(entries))
+
+	now := time.Now().UTC()
+	for i, entry := range entries {
+		if entry.ID == "" {
+			entry.ID = uuid.New().String()
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = now
+		}
+		ids[i] = entry.ID
+		deploymentIDs[i] = entry.DeploymentID
+		sources[i] = entry.Source
+		levels[i] = entry.Level
+		streams[i] = entry.Stream
+		messages[i] = entry.Message
+		timestamps[i] = entry.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := `
+		INSERT INTO logs (id, deployment_id, source, level, stream, message, timestamp)
+		SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[], $5::text[], $6::text[], $7::timestamptz[])`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		pq.Array(ids),
+		pq.Array(deploymentIDs),
+		pq.Array(sources),
+		pq.Array(levels),
+		pq.Array(streams),
+		pq.Array(messages),
+		pq.Array(timestamps),
+	)
+	if err != nil {
+		return fmt.Errorf("inserting log batch: %w", err)
+	}
+
+	return nil
+}
+
 // List retrieves log entries for a deployment.
 func (s *LogStore) List(ctx context.Context, deploymentID string, limit int) ([]*models.LogEntry, error) {
 	query := `
-		SELECT id, deployment_id, source, level, message, timestamp
+		SELECT ` + logColumns + `
 		FROM logs
 		WHERE deployment_id = $1
 		ORDER BY timestamp DESC
@@ -73,7 +127,7 @@ func (s *LogStore) List(ctx context.Context, deploymentID string, limit int) ([]
 // ListBySource retrieves log entries filtered by source (build/runtime).
 func (s *LogStore) ListBySource(ctx context.Context, deploymentID, source string, limit int) ([]*models.LogEntry, error) {
 	query := `
-		SELECT id, deployment_id, source, level, message, timestamp
+		SELECT ` + logColumns + `
 		FROM logs
 		WHERE deployment_id = $1 AND source = $2
 		ORDER BY timestamp DESC
@@ -88,6 +142,49 @@ func (s *LogStore) ListBySource(ctx context.Context, deploymentID, source string
 	return s.scanLogs(rows)
 }
 
+// Search retrieves the log entries of an app matching a filter, newest first,
+// or oldest first when the filter continues after a cursor.
+// The query is matched against the messages' full-text index with
+// websearch_to_tsquery, so it supports quoted phrases, "or" and -exclusions.
+func (s *LogStore) Search(ctx context.Context, filter models.LogFilter) ([]*models.LogEntry, error) {
+	q := newSelect(qualifyColumns("l", logColumns), "logs l JOIN deployments d ON l.deployment_id = d.id").
+		Where("d.app_id = ?", filter.AppID)
+	if filter.ServiceName != "" {
+		q.Where("d.service_name = ?", filter.ServiceName)
+	}
+	if filter.DeploymentID != "" {
+		q.Where("l.deployment_id = ?", filter.DeploymentID)
+	}
+	if filter.Query != "" {
+		q.Where("l.search @@ websearch_to_tsquery('simple', ?)", filter.Query)
+	}
+	if len(filter.Levels) > 0 {
+		q.Where("l.level = ANY(?)", pq.Array(filter.Levels))
+	}
+	if filter.Source != "" {
+		q.Where("l.source = ?", filter.Source)
+	}
+	if filter.Stream != "" {
+		q.Where("l.stream = ?", filter.Stream)
+	}
+	if !filter.Since.IsZero() {
+		q.Where("l.timestamp >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.Where("l.timestamp < ?", filter.Until)
+	}
+	if filter.Before != nil {
+		q.Where("(l.timestamp, l.id) < (?, ?::uuid)", filter.Before.Timestamp, filter.Before.ID)
+	}
+	if filter.After != nil {
+		q.Where("(l.timestamp, l.id) > (?, ?::uuid)", filter.After.Timestamp, filter.After.ID)
+		q.OrderBy("l.timestamp, l.id").Page(filter.Limit, 0)
+	} else {
+		q.OrderBy("l.timestamp DESC, l.id DESC").Page(filter.Limit, 0)
+	}
+	return listRows(ctx, s.conn(), "log entry", q, scanLogEntry)
+}
+
 // DeleteOlderThan removes log entries older than the specified timestamp.
 func (s *LogStore) DeleteOlderThan(ctx context.Context, deploymentID string, before int64) error {
 	query := `DELETE FROM logs WHERE deployment_id = $1 AND timestamp < $2`
@@ -102,21 +199,42 @@ func (s *LogStore) DeleteOlderThan(ctx context.Context, deploymentID string, bef
 	return nil
 }
 
+// DeleteByDeployment removes all of a deployment's log entries.
+func (s *LogStore) DeleteByDeployment(ctx context.Context, deploymentID string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM logs WHERE deployment_id = $1`, deploymentID)
+	if err != nil {
+		return fmt.Errorf("deleting deployment logs: %w", err)
+	}
+	return nil
+}
+
+// logColumns lists the columns read by scanLogEntry.
+const logColumns = `id, deployment_id, source, level, stream, message, timestamp`
+
+// scanLogEntry reads a single log entry row.
+func scanLogEntry(row rowScanner) (*models.LogEntry, error) {
+	entry := &models.LogEntry{}
+	err := row.Scan(
+		&entry.ID,
+		&entry.DeploymentID,
+		&entry.Source,
+		&entry.Level,
+		&entry.Stream,
+		&entry.Message,
+		&entry.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
 // scanLogs scans multiple log entry rows.
 func (s *LogStore) scanLogs(rows *sql.Rows) ([]*models.LogEntry, error) {
 	var entries []*models.LogEntry
 
 	for rows.Next() {
-		entry := &models.LogEntry{}
-
-		err := rows.Scan(
-			&entry.ID,
-			&entry.DeploymentID,
-			&entry.Source,
-			&entry.Level,
-			&entry.Message,
-			&entry.Timestamp,
-		)
+		entry, err := scanLogEntry(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scanning log row: %w", err)
 		}
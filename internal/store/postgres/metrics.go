@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// MetricStore implements store.MetricStore using PostgreSQL.
+type MetricStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *MetricStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Record upserts the sample into its deployment's rollup for the minute in a
+// single statement, so concurrent reports from replicas never lose samples.
+func (s *MetricStore) Record(ctx context.Context, sample *models.MetricSample) error {
+	if sample.SampledAt.IsZero() {
+		sample.SampledAt = time.Now()
+	}
+	bucket := sample.SampledAt.UTC().Truncate(models.MetricsResolution)
+
+	query := `
+		INSERT INTO service_metrics (deployment_id, app_id, service_name, bucket, samples,
+			cpu_percent_sum, cpu_percent_max, memory_bytes_sum, memory_bytes_max, network_rx_bytes, network_tx_bytes, requests,
+			errors, latency_ms_sum)
+		VALUES ($1, $2, $3, $4, 1, $5, $5, $6, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (deployment_id, bucket) DO UPDATE SET
+			samples = service_metrics.samples + 1,
+			cpu_percent_sum = service_metrics.cpu_percent_sum + EXCLUDED.cpu_percent_sum,
+			cpu_percent_max = GREATEST(service_metrics.cpu_percent_max, EXCLUDED.cpu_percent_max),
+			memory_bytes_sum = service_metrics.memory_bytes_sum + EXCLUDED.memory_bytes_sum,
+			memory_bytes_max = GREATEST(service_metrics.memory_bytes_max, EXCLUDED.memory_bytes_max),
+			network_rx_bytes = GREATEST(service_metrics.network_rx_bytes, EXCLUDED.network_rx_bytes),
+			network_tx_bytes = GREATEST(service_metrics.network_tx_bytes, EXCLUDED.network_tx_bytes),
+			requests = GREATEST(service_metrics.requests, EXCLUDED.requests),
+			errors = GREATEST(service_metrics.errors, EXCLUDED.errors),
+			latency_ms_sum = GREATEST(service_metrics.latency_ms_sum, EXCLUDED.latency_ms_sum)`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		sample.DeploymentID, sample.AppID, sample.ServiceName, bucket,
+		sample.CPUPercent, sample.MemoryBytes, sample.NetworkRxBytes, sample.NetworkTxBytes, sample.Requests,
+		sample.Errors, sample.LatencyMsSum,
+	)
+	if err != nil {
+		return fmt.Errorf("recording metric sample: %w", err)
+	}
+	return nil
+}
+
+// metricRollupColumns lists the columns read by scanMetricRollup.
+const metricRollupColumns = `deployment_id, app_id, service_name, bucket, samples,
+	cpu_percent_sum, cpu_percent_max, memory_bytes_sum, memory_bytes_max, network_rx_bytes, network_tx_bytes, requests,
+	errors, latency_ms_sum`
+
+// ListByService retrieves the rollups of all of a service's deployments with
+// buckets in [from, to), oldest first.
+func (s *MetricStore) ListByService(ctx context.Context, appID, serviceName string, from, to time.Time) ([]*models.MetricRollup, error) {
+	q := newSelect(metricRollupColumns, "service_metrics").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		Where("bucket >= ?", from).
+		Where("bucket < ?", to).
+		OrderBy("bucket, deployment_id")
+	return listRows(ctx, s.conn(), "metric rollup", q, scanMetricRollup)
+}
+
+// DeleteBefore removes rollups with buckets before the given time.
+func (s *MetricStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM service_metrics WHERE bucket < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting metric rollups: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return rows, nil
+}
+
+// scanMetricRollup reads a single metric rollup row.
+func scanMetricRollup(row rowScanner) (*models.MetricRollup, error) {
+	var r models.MetricRollup
+	if err := row.Scan(
+		&r.DeploymentID, &r.AppID, &r.ServiceName, &r.Bucket, &r.Samples,
+		&r.CPUPercentSum, &r.CPUPercentMax, &r.MemoryBytesSum, &r.MemoryBytesMax, &r.NetworkRxBytes, &r.NetworkTxBytes, &r.Requests,
+		&r.Errors, &r.LatencyMsSum,
+	); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
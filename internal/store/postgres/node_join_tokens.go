@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// NodeJoinTokenStore implements store.NodeJoinTokenStore using PostgreSQL.
+type NodeJoinTokenStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *NodeJoinTokenStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// nodeJoinTokenColumns lists the columns read by scanNodeJoinToken.
+const nodeJoinTokenColumns = `id, description, token_prefix, created_by, created_at, expires_at, last_used_at`
+
+// Create stores a new join token by its hash.
+func (s *NodeJoinTokenStore) Create(ctx context.Context, token *models.NodeJoinToken, tokenHash string) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	var expiresAt sql.NullTime
+	if token.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *token.ExpiresAt, Valid: true}
+	}
+
+	query := `
+		INSERT INTO node_join_tokens (id, description, token_hash, token_prefix, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		token.ID, token.Description, tokenHash, token.TokenPrefix, token.CreatedBy, token.CreatedAt, expiresAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting node join token: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting node join token: %w", err)
+	}
+	return nil
+}
+
+// List retrieves all join tokens, newest first.
+func (s *NodeJoinTokenStore) List(ctx context.Context) ([]*models.NodeJoinToken, error) {
+	q := newSelect(nodeJoinTokenColumns, "node_join_tokens").OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "node join token", q, scanNodeJoinToken)
+}
+
+// Delete revokes a join token.
+func (s *NodeJoinTokenStore) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound
+	}
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM node_join_tokens WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting node join token: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the unexpired join token with a hash and records its
+// use. It returns nil if no token matches.
+func (s *NodeJoinTokenStore) Authenticate(ctx context.Context, tokenHash string) (*models.NodeJoinToken, error) {
+	query := `
+		UPDATE node_join_tokens SET last_used_at = NOW()
+		WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING ` + nodeJoinTokenColumns
+
+	token, err := scanNodeJoinToken(s.conn().QueryRowContext(ctx, query, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("authenticating node join token: %w", err)
+	}
+	return token, nil
+}
+
+// scanNodeJoinToken reads a single join token row selected with nodeJoinTokenColumns.
+func scanNodeJoinToken(row rowScanner) (*models.NodeJoinToken, error) {
+	var t models.NodeJoinToken
+	var expiresAt, lastUsed sql.NullTime
+	if err := row.Scan(&t.ID, &t.Description, &t.TokenPrefix, &t.CreatedBy, &t.CreatedAt, &expiresAt, &lastUsed); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	return &t, nil
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
@@ -17,14 +18,12 @@ type NodeStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *NodeStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Register registers a new node or updates an existing one.
@@ -35,8 +34,8 @@ func (s *NodeStore) Register(ctx context.Context, node *models.Node) error {
 			disk_total, disk_available, 
 			nix_store_total, nix_store_used, nix_store_available, nix_store_usage_percent,
 			container_storage_total, container_storage_used, container_storage_available, container_storage_usage_percent,
-			cached_paths, last_heartbeat, registered_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+			cached_paths, last_heartbeat, registered_at, provider, pool, capabilities, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 		ON CONFLICT (id) DO UPDATE SET
 			hostname = EXCLUDED.hostname,
 			address = EXCLUDED.address,
@@ -57,8 +56,11 @@ func (s *NodeStore) Register(ctx context.Context, node *models.Node) error {
 			container_storage_available = EXCLUDED.container_storage_available,
 			container_storage_usage_percent = EXCLUDED.container_storage_usage_percent,
 			cached_paths = EXCLUDED.cached_paths,
-			last_heartbeat = EXCLUDED.last_heartbeat
-		RETURNING id, registered_at`
+			last_heartbeat = EXCLUDED.last_heartbeat,
+			provider = EXCLUDED.provider,
+			pool = EXCLUDED.pool,
+			capabilities = EXCLUDED.capabilities
+		RETURNING id, registered_at, status`
 
 	now := time.Now().UTC()
 	if node.LastHeartbeat.IsZero() {
@@ -68,6 +70,23 @@ func (s *NodeStore) Register(ctx context.Context, node *models.Node) error {
 		node.RegisteredAt = now
 	}
 
+	// Nodes registered by the node agent run on Podman
+	if node.Provider == "" {
+		node.Provider = models.NodeProviderPodman
+	}
+	// Re-registering keeps a node's status; new nodes are active unless
+	// they joined with a join token
+	if node.Status == "" {
+		node.Status = models.NodeStatusActive
+	}
+	if node.Capabilities == nil && node.Provider == models.NodeProviderPodman {
+		node.Capabilities = models.PodmanCapabilities
+	}
+	capabilities := make([]string, len(node.Capabilities))
+	for i, c := range node.Capabilities {
+		capabilities[i] = string(c)
+	}
+
 	resources := node.Resources
 	if resources == nil {
 		resources = &models.NodeResources{}
@@ -117,7 +136,11 @@ func (s *NodeStore) Register(ctx context.Context, node *models.Node) error {
 		pq.Array(node.CachedPaths),
 		node.LastHeartbeat,
 		node.RegisteredAt,
-	).Scan(&node.ID, &node.RegisteredAt)
+		node.Provider,
+		node.Pool,
+		pq.Array(capabilities),
+		node.Status,
+	).Scan(&node.ID, &node.RegisteredAt, &node.Status)
 
 	if err != nil {
 		return fmt.Errorf("registering node: %w", err)
@@ -126,106 +149,34 @@ func (s *NodeStore) Register(ctx context.Context, node *models.Node) error {
 	return nil
 }
 
+// nodeColumns lists the columns read by scanNode.
+const nodeColumns = `id, hostname, address, grpc_port, healthy,
+	cpu_total, cpu_available, memory_total, memory_available,
+	disk_total, disk_available,
+	COALESCE(nix_store_total, 0), COALESCE(nix_store_used, 0),
+	COALESCE(nix_store_available, 0), COALESCE(nix_store_usage_percent, 0),
+	COALESCE(container_storage_total, 0), COALESCE(container_storage_used, 0),
+	COALESCE(container_storage_available, 0), COALESCE(container_storage_usage_percent, 0),
+	cached_paths, last_heartbeat, registered_at, provider, pool, capabilities, status,
+	health_score, load1, load5, load15, clock_skew_ms, certificate_expires_at`
+
 // Get retrieves a node by ID.
 func (s *NodeStore) Get(ctx context.Context, id string) (*models.Node, error) {
-	query := `
-		SELECT id, hostname, address, grpc_port, healthy,
-			cpu_total, cpu_available, memory_total, memory_available,
-			disk_total, disk_available,
-			COALESCE(nix_store_total, 0), COALESCE(nix_store_used, 0), 
-			COALESCE(nix_store_available, 0), COALESCE(nix_store_usage_percent, 0),
-			COALESCE(container_storage_total, 0), COALESCE(container_storage_used, 0),
-			COALESCE(container_storage_available, 0), COALESCE(container_storage_usage_percent, 0),
-			cached_paths, last_heartbeat, registered_at
-		FROM nodes
-		WHERE id = $1`
-
-	node := &models.Node{
-		Resources:   &models.NodeResources{},
-		DiskMetrics: &models.NodeDiskMetrics{},
-	}
-
-	var nixStoreTotal, nixStoreUsed, nixStoreAvailable int64
-	var nixStoreUsagePercent float64
-	var containerStorageTotal, containerStorageUsed, containerStorageAvailable int64
-	var containerStorageUsagePercent float64
-
-	err := s.conn().QueryRowContext(ctx, query, id).Scan(
-		&node.ID,
-		&node.Hostname,
-		&node.Address,
-		&node.GRPCPort,
-		&node.Healthy,
-		&node.Resources.CPUTotal,
-		&node.Resources.CPUAvailable,
-		&node.Resources.MemoryTotal,
-		&node.Resources.MemoryAvailable,
-		&node.Resources.DiskTotal,
-		&node.Resources.DiskAvailable,
-		&nixStoreTotal,
-		&nixStoreUsed,
-		&nixStoreAvailable,
-		&nixStoreUsagePercent,
-		&containerStorageTotal,
-		&containerStorageUsed,
-		&containerStorageAvailable,
-		&containerStorageUsagePercent,
-		pq.Array(&node.CachedPaths),
-		&node.LastHeartbeat,
-		&node.RegisteredAt,
-	)
+	query, args := newSelect(nodeColumns, "nodes").Where("id = ?", id).Build()
 
+	node, err := scanNode(s.conn().QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("querying node: %w", err)
 	}
-
-	// Populate disk metrics if any values are non-zero
-	if nixStoreTotal > 0 || nixStoreUsed > 0 {
-		node.DiskMetrics.NixStore = &models.DiskStats{
-			Path:         "/nix/store",
-			Total:        nixStoreTotal,
-			Used:         nixStoreUsed,
-			Available:    nixStoreAvailable,
-			UsagePercent: nixStoreUsagePercent,
-		}
-	}
-	if containerStorageTotal > 0 || containerStorageUsed > 0 {
-		node.DiskMetrics.ContainerStorage = &models.DiskStats{
-			Path:         "/var/lib/containers",
-			Total:        containerStorageTotal,
-			Used:         containerStorageUsed,
-			Available:    containerStorageAvailable,
-			UsagePercent: containerStorageUsagePercent,
-		}
-	}
-
 	return node, nil
 }
 
 // List retrieves all registered nodes.
 func (s *NodeStore) List(ctx context.Context) ([]*models.Node, error) {
-	query := `
-		SELECT id, hostname, address, grpc_port, healthy,
-			cpu_total, cpu_available, memory_total, memory_available,
-			disk_total, disk_available,
-			COALESCE(nix_store_total, 0), COALESCE(nix_store_used, 0), 
-			COALESCE(nix_store_available, 0), COALESCE(nix_store_usage_percent, 0),
-			COALESCE(container_storage_total, 0), COALESCE(container_storage_used, 0),
-			COALESCE(container_storage_available, 0), COALESCE(container_storage_usage_percent, 0),
-			cached_paths, last_heartbeat, registered_at
-		FROM nodes
-		ORDER BY registered_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("querying nodes: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanNodes(rows)
+	return listRows(ctx, s.conn(), "node", newSelect(nodeColumns, "nodes").OrderBy("registered_at DESC"), scanNode)
 }
 
 // UpdateHeartbeat updates a node's last heartbeat timestamp and resource metrics.
@@ -363,121 +314,209 @@ func (s *NodeStore) UpdateHealth(ctx context.Context, id string, healthy bool) e
 
 // ListHealthy retrieves all healthy nodes.
 func (s *NodeStore) ListHealthy(ctx context.Context) ([]*models.Node, error) {
-	query := `
-		SELECT id, hostname, address, grpc_port, healthy,
-			cpu_total, cpu_available, memory_total, memory_available,
-			disk_total, disk_available,
-			COALESCE(nix_store_total, 0), COALESCE(nix_store_used, 0), 
-			COALESCE(nix_store_available, 0), COALESCE(nix_store_usage_percent, 0),
-			COALESCE(container_storage_total, 0), COALESCE(container_storage_used, 0),
-			COALESCE(container_storage_available, 0), COALESCE(container_storage_usage_percent, 0),
-			cached_paths, last_heartbeat, registered_at
-		FROM nodes
-		WHERE healthy = true
-		ORDER BY registered_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query)
+	q := newSelect(nodeColumns, "nodes").Where("healthy = true").OrderBy("registered_at DESC")
+	return listRows(ctx, s.conn(), "node", q, scanNode)
+}
+
+// ListWithClosure retrieves nodes that have a specific store path cached.
+func (s *NodeStore) ListWithClosure(ctx context.Context, storePath string) ([]*models.Node, error) {
+	q := newSelect(nodeColumns, "nodes").Where("? = ANY(cached_paths)", storePath).OrderBy("registered_at DESC")
+	return listRows(ctx, s.conn(), "node", q, scanNode)
+}
+
+// UpdateStatus moves a node to a lifecycle status.
+func (s *NodeStore) UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error {
+	result, err := s.conn().ExecContext(ctx, `UPDATE nodes SET status = $2 WHERE id = $1`, id, status)
 	if err != nil {
-		return nil, fmt.Errorf("querying healthy nodes: %w", err)
+		return fmt.Errorf("updating node status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
 	}
-	defer rows.Close()
+	return nil
+}
 
-	return s.scanNodes(rows)
+// Delete removes a node. Deployments placed on it are left without a node.
+func (s *NodeStore) Delete(ctx context.Context, id string) error {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM nodes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting node: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-// ListWithClosure retrieves nodes that have a specific store path cached.
-func (s *NodeStore) ListWithClosure(ctx context.Context, storePath string) ([]*models.Node, error) {
-	query := `
-		SELECT id, hostname, address, grpc_port, healthy,
-			cpu_total, cpu_available, memory_total, memory_available,
-			disk_total, disk_available,
-			COALESCE(nix_store_total, 0), COALESCE(nix_store_used, 0), 
-			COALESCE(nix_store_available, 0), COALESCE(nix_store_usage_percent, 0),
-			COALESCE(container_storage_total, 0), COALESCE(container_storage_used, 0),
-			COALESCE(container_storage_available, 0), COALESCE(container_storage_usage_percent, 0),
-			cached_paths, last_heartbeat, registered_at
-		FROM nodes
-		WHERE $1 = ANY(cached_paths)
-		ORDER BY registered_at DESC`
-
-	rows, err := s.conn().QueryContext(ctx, query, storePath)
+// UpdateHealthScore records a node's health score and load from its last heartbeat.
+func (s *NodeStore) UpdateHealthScore(ctx context.Context, id string, score int, load *models.NodeLoad) error {
+	if load == nil {
+		load = &models.NodeLoad{}
+	}
+	result, err := s.conn().ExecContext(ctx,
+		`UPDATE nodes SET health_score = $2, load1 = $3, load5 = $4, load15 = $5 WHERE id = $1`,
+		id, score, load.Load1, load.Load5, load.Load15)
 	if err != nil {
-		return nil, fmt.Errorf("querying nodes with closure: %w", err)
+		return fmt.Errorf("updating node health score: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
 	}
-	defer rows.Close()
+	return nil
+}
 
-	return s.scanNodes(rows)
+// UpdateClock records a node's clock skew and, if known, when its agent's
+// certificate expires.
+func (s *NodeStore) UpdateClock(ctx context.Context, id string, skew time.Duration, certificateExpiresAt *time.Time) error {
+	result, err := s.conn().ExecContext(ctx,
+		`UPDATE nodes SET clock_skew_ms = $2, certificate_expires_at = COALESCE($3, certificate_expires_at) WHERE id = $1`,
+		id, skew.Milliseconds(), certificateExpiresAt)
+	if err != nil {
+		return fmt.Errorf("updating node clock: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-// scanNodes scans multiple node rows.
-func (s *NodeStore) scanNodes(rows *sql.Rows) ([]*models.Node, error) {
-	var nodes []*models.Node
+// CreateHealthEvent records a node becoming healthy or unhealthy.
+func (s *NodeStore) CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO node_health_events (id, node_id, healthy, health_score, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := s.conn().ExecContext(ctx, query,
+		event.ID, event.NodeID, event.Healthy, event.HealthScore, event.Reason, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating node health event: %w", err)
+	}
+	return nil
+}
 
-	for rows.Next() {
-		node := &models.Node{
-			Resources:   &models.NodeResources{},
-			DiskMetrics: &models.NodeDiskMetrics{},
-		}
+// ListHealthEvents retrieves the latest health events of a node, or of all
+// nodes if nodeID is empty, newest first.
+func (s *NodeStore) ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error) {
+	q := newSelect("id, node_id, healthy, health_score, reason, created_at", "node_health_events")
+	if nodeID != "" {
+		q = q.Where("node_id = ?", nodeID)
+	}
+	q = q.OrderBy("created_at DESC").Page(limit, 0)
+	return listRows(ctx, s.conn(), "node health event", q, scanNodeHealthEvent)
+}
 
-		var nixStoreTotal, nixStoreUsed, nixStoreAvailable int64
-		var nixStoreUsagePercent float64
-		var containerStorageTotal, containerStorageUsed, containerStorageAvailable int64
-		var containerStorageUsagePercent float64
-
-		err := rows.Scan(
-			&node.ID,
-			&node.Hostname,
-			&node.Address,
-			&node.GRPCPort,
-			&node.Healthy,
-			&node.Resources.CPUTotal,
-			&node.Resources.CPUAvailable,
-			&node.Resources.MemoryTotal,
-			&node.Resources.MemoryAvailable,
-			&node.Resources.DiskTotal,
-			&node.Resources.DiskAvailable,
-			&nixStoreTotal,
-			&nixStoreUsed,
-			&nixStoreAvailable,
-			&nixStoreUsagePercent,
-			&containerStorageTotal,
-			&containerStorageUsed,
-			&containerStorageAvailable,
-			&containerStorageUsagePercent,
-			pq.Array(&node.CachedPaths),
-			&node.LastHeartbeat,
-			&node.RegisteredAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scanning node row: %w", err)
-		}
+// scanNodeHealthEvent reads a single node health event row.
+func scanNodeHealthEvent(row rowScanner) (*models.NodeHealthEvent, error) {
+	var e models.NodeHealthEvent
+	if err := row.Scan(&e.ID, &e.NodeID, &e.Healthy, &e.HealthScore, &e.Reason, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
 
-		// Populate disk metrics if any values are non-zero
-		if nixStoreTotal > 0 || nixStoreUsed > 0 {
-			node.DiskMetrics.NixStore = &models.DiskStats{
-				Path:         "/nix/store",
-				Total:        nixStoreTotal,
-				Used:         nixStoreUsed,
-				Available:    nixStoreAvailable,
-				UsagePercent: nixStoreUsagePercent,
-			}
-		}
-		if containerStorageTotal > 0 || containerStorageUsed > 0 {
-			node.DiskMetrics.ContainerStorage = &models.DiskStats{
-				Path:         "/var/lib/containers",
-				Total:        containerStorageTotal,
-				Used:         containerStorageUsed,
-				Available:    containerStorageAvailable,
-				UsagePercent: containerStorageUsagePercent,
-			}
-		}
+// scanNode reads a single node row selected with nodeColumns.
+func scanNode(row rowScanner) (*models.Node, error) {
+	node := &models.Node{
+		Resources:   &models.NodeResources{},
+		DiskMetrics: &models.NodeDiskMetrics{},
+	}
 
-		nodes = append(nodes, node)
+	var nixStoreTotal, nixStoreUsed, nixStoreAvailable int64
+	var nixStoreUsagePercent float64
+	var containerStorageTotal, containerStorageUsed, containerStorageAvailable int64
+	var containerStorageUsagePercent float64
+	var capabilities []string
+	var load models.NodeLoad
+	var certificateExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&node.ID,
+		&node.Hostname,
+		&node.Address,
+		&node.GRPCPort,
+		&node.Healthy,
+		&node.Resources.CPUTotal,
+		&node.Resources.CPUAvailable,
+		&node.Resources.MemoryTotal,
+		&node.Resources.MemoryAvailable,
+		&node.Resources.DiskTotal,
+		&node.Resources.DiskAvailable,
+		&nixStoreTotal,
+		&nixStoreUsed,
+		&nixStoreAvailable,
+		&nixStoreUsagePercent,
+		&containerStorageTotal,
+		&containerStorageUsed,
+		&containerStorageAvailable,
+		&containerStorageUsagePercent,
+		pq.Array(&node.CachedPaths),
+		&node.LastHeartbeat,
+		&node.RegisteredAt,
+		&node.Provider,
+		&node.Pool,
+		pq.Array(&capabilities),
+		&node.Status,
+		&node.HealthScore,
+		&load.Load1,
+		&load.Load5,
+		&load.Load15,
+		&node.ClockSkewMs,
+		&certificateExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if load != (models.NodeLoad{}) {
+		node.Load = &load
+	}
+	if certificateExpiresAt.Valid {
+		node.CertificateExpiresAt = &certificateExpiresAt.Time
+	}
+	node.UpdateConditions(time.Now())
+	for _, c := range capabilities {
+		node.Capabilities = append(node.Capabilities, models.NodeCapability(c))
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating node rows: %w", err)
+	// Populate disk metrics if any values are non-zero
+	if nixStoreTotal > 0 || nixStoreUsed > 0 {
+		node.DiskMetrics.NixStore = &models.DiskStats{
+			Path:         "/nix/store",
+			Total:        nixStoreTotal,
+			Used:         nixStoreUsed,
+			Available:    nixStoreAvailable,
+			UsagePercent: nixStoreUsagePercent,
+		}
+	}
+	if containerStorageTotal > 0 || containerStorageUsed > 0 {
+		node.DiskMetrics.ContainerStorage = &models.DiskStats{
+			Path:         "/var/lib/containers",
+			Total:        containerStorageTotal,
+			Used:         containerStorageUsed,
+			Available:    containerStorageAvailable,
+			UsagePercent: containerStorageUsagePercent,
+		}
 	}
 
-	return nodes, nil
+	return node, nil
 }
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// NotificationStore implements store.NotificationStore using PostgreSQL.
+type NotificationStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *NotificationStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// notificationProviderColumns lists the columns read by scanNotificationProvider.
+const notificationProviderColumns = `id, type, name, enabled, config, events, last_delivery_at, last_error,
+	created_at, updated_at`
+
+// CreateProvider stores a new notification provider.
+func (s *NotificationStore) CreateProvider(ctx context.Context, provider *models.NotificationProvider) error {
+	if provider.ID == "" {
+		provider.ID = uuid.New().String()
+	}
+	now := time.Now()
+	provider.CreatedAt, provider.UpdatedAt = now, now
+
+	configJSON, eventsJSON, err := marshalProviderSettings(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO notification_providers (id, type, name, enabled, config, events, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		provider.ID, string(provider.Type), provider.Name, provider.Enabled, configJSON, eventsJSON,
+		provider.CreatedAt, provider.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting notification provider: %w", err)
+	}
+	return nil
+}
+
+// GetProvider retrieves a notification provider by ID. It returns nil if the provider does not exist.
+func (s *NotificationStore) GetProvider(ctx context.Context, id string) (*models.NotificationProvider, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(notificationProviderColumns, "notification_providers").Where("id = ?", id).Build()
+
+	provider, err := scanNotificationProvider(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying notification provider: %w", err)
+	}
+	return provider, nil
+}
+
+// ListProviders retrieves all notification providers, oldest first.
+func (s *NotificationStore) ListProviders(ctx context.Context) ([]*models.NotificationProvider, error) {
+	q := newSelect(notificationProviderColumns, "notification_providers").OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "notification provider", q, scanNotificationProvider)
+}
+
+// UpdateProvider updates a provider's name, enabled flag, config and events.
+func (s *NotificationStore) UpdateProvider(ctx context.Context, provider *models.NotificationProvider) error {
+	provider.UpdatedAt = time.Now()
+
+	configJSON, eventsJSON, err := marshalProviderSettings(provider)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE notification_providers SET name = $1, enabled = $2, config = $3, events = $4, updated_at = $5
+		WHERE id = $6
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		provider.Name, provider.Enabled, configJSON, eventsJSON, provider.UpdatedAt, provider.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating notification provider: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteProvider removes a provider and its queued deliveries.
+func (s *NotificationStore) DeleteProvider(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM notification_providers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting notification provider: %w", err)
+	}
+	return nil
+}
+
+// RecordProviderResult stores the time and error, if any, of a provider's latest delivery attempt.
+func (s *NotificationStore) RecordProviderResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	query := `UPDATE notification_providers SET last_delivery_at = $1, last_error = $2 WHERE id = $3`
+	if _, err := s.conn().ExecContext(ctx, query, at, errMsg, id); err != nil {
+		return fmt.Errorf("recording notification provider result: %w", err)
+	}
+	return nil
+}
+
+// EnqueueDelivery queues an event for delivery to a provider.
+func (s *NotificationStore) EnqueueDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	now := time.Now()
+	delivery.CreatedAt = now
+	if delivery.Status == "" {
+		delivery.Status = models.NotificationDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+
+	eventJSON, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return fmt.Errorf("marshaling notification event: %w", err)
+	}
+
+	query := `
+		INSERT INTO notification_deliveries (id, provider_id, event, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		delivery.ID, delivery.ProviderID, eventJSON, string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt, delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting notification delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt is
+// due and pushes their next attempt back by lease. Uses FOR UPDATE SKIP LOCKED
+// so concurrent workers claim disjoint sets; a worker that dies mid-send leaves
+// its deliveries to be retried once the lease expires.
+func (s *NotificationStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, provider_id, event, status, attempts, next_attempt_at, last_error, created_at, delivered_at
+	`
+	rows, err := s.conn().QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*models.NotificationDelivery
+	for rows.Next() {
+		d, err := scanNotificationDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notification deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+func (s *NotificationStore) UpdateDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5
+		WHERE id = $6
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt, delivery.LastError,
+		delivery.DeliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating notification delivery: %w", err)
+	}
+	return nil
+}
+
+// marshalProviderSettings encodes a provider's config and events, storing nil as empty.
+func marshalProviderSettings(provider *models.NotificationProvider) ([]byte, []byte, error) {
+	config := provider.Config
+	if config == nil {
+		config = map[string]string{}
+	}
+	events := provider.Events
+	if events == nil {
+		events = []models.NotificationEventType{}
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling notification provider config: %w", err)
+	}
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling notification provider events: %w", err)
+	}
+	return configJSON, eventsJSON, nil
+}
+
+func scanNotificationProvider(row rowScanner) (*models.NotificationProvider, error) {
+	var p models.NotificationProvider
+	var providerType string
+	var configJSON, eventsJSON []byte
+	var lastDelivery sql.NullTime
+	if err := row.Scan(
+		&p.ID, &providerType, &p.Name, &p.Enabled, &configJSON, &eventsJSON, &lastDelivery, &p.LastError,
+		&p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	p.Type = models.NotificationProviderType(providerType)
+	if err := json.Unmarshal(configJSON, &p.Config); err != nil {
+		return nil, fmt.Errorf("unmarshaling notification provider config: %w", err)
+	}
+	if err := json.Unmarshal(eventsJSON, &p.Events); err != nil {
+		return nil, fmt.Errorf("unmarshaling notification provider events: %w", err)
+	}
+	if lastDelivery.Valid {
+		p.LastDeliveryAt = &lastDelivery.Time
+	}
+	return &p, nil
+}
+
+func scanNotificationDelivery(row rowScanner) (*models.NotificationDelivery, error) {
+	var d models.NotificationDelivery
+	var status string
+	var eventJSON []byte
+	var deliveredAt sql.NullTime
+	if err := row.Scan(
+		&d.ID, &d.ProviderID, &eventJSON, &status, &d.Attempts, &d.NextAttemptAt, &d.LastError,
+		&d.CreatedAt, &deliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Status = models.NotificationDeliveryStatus(status)
+	if err := json.Unmarshal(eventJSON, &d.Event); err != nil {
+		return nil, fmt.Errorf("unmarshaling notification event: %w", err)
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
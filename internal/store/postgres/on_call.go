@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// OnCallStore implements store.OnCallStore using PostgreSQL.
+type OnCallStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *OnCallStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// CreateGate stores a new on-call gate.
+func (s *OnCallStore) CreateGate(ctx context.Context, gate *models.OnCallGate) error {
+	if gate.ID == "" {
+		gate.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if gate.CreatedAt.IsZero() {
+		gate.CreatedAt = now
+	}
+	gate.UpdatedAt = gate.CreatedAt
+
+	query := `
+		INSERT INTO on_call_gates (id, org_id, name, match, provider, schedule_id, api_token, requirement,
+			business_days, business_start, business_end, timezone, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		gate.ID,
+		gate.OrgID,
+		gate.Name,
+		gate.Match,
+		string(gate.Provider),
+		gate.ScheduleID,
+		gate.APIToken,
+		string(gate.Requirement),
+		pq.Array(gate.BusinessDays),
+		gate.BusinessStart,
+		gate.BusinessEnd,
+		gate.Timezone,
+		gate.Enabled,
+		gate.CreatedBy,
+		gate.CreatedAt,
+		gate.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting on-call gate: %w", err)
+	}
+	return nil
+}
+
+// onCallGateColumns lists the columns read by scanOnCallGate.
+const onCallGateColumns = `id, org_id, name, match, provider, schedule_id, api_token, requirement,
+	business_days, business_start, business_end, timezone, enabled, created_by, created_at, updated_at`
+
+// GetGate retrieves an on-call gate by ID. It returns nil if the gate does not exist.
+func (s *OnCallStore) GetGate(ctx context.Context, id string) (*models.OnCallGate, error) {
+	query, args := newSelect(onCallGateColumns, "on_call_gates").Where("id = ?", id).Build()
+
+	gate, err := scanOnCallGate(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying on-call gate: %w", err)
+	}
+	return gate, nil
+}
+
+// ListGates retrieves all on-call gates for an organization, oldest first.
+func (s *OnCallStore) ListGates(ctx context.Context, orgID string) ([]*models.OnCallGate, error) {
+	q := newSelect(onCallGateColumns, "on_call_gates").
+		Where("org_id = ?", orgID).
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "on-call gate", q, scanOnCallGate)
+}
+
+// UpdateGate saves an on-call gate's settings.
+func (s *OnCallStore) UpdateGate(ctx context.Context, gate *models.OnCallGate) error {
+	gate.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE on_call_gates
+		SET name = $2, match = $3, provider = $4, schedule_id = $5, api_token = $6, requirement = $7,
+			business_days = $8, business_start = $9, business_end = $10, timezone = $11, enabled = $12,
+			updated_at = $13
+		WHERE id = $1
+	`
+
+	result, err := s.conn().ExecContext(ctx, query,
+		gate.ID,
+		gate.Name,
+		gate.Match,
+		string(gate.Provider),
+		gate.ScheduleID,
+		gate.APIToken,
+		string(gate.Requirement),
+		pq.Array(gate.BusinessDays),
+		gate.BusinessStart,
+		gate.BusinessEnd,
+		gate.Timezone,
+		gate.Enabled,
+		gate.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating on-call gate: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("on-call gate not found: %s", gate.ID)
+	}
+	return nil
+}
+
+// DeleteGate removes an on-call gate and its acknowledgements.
+func (s *OnCallStore) DeleteGate(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM on_call_gates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting on-call gate: %w", err)
+	}
+	return nil
+}
+
+// CreateAck stores a new on-call acknowledgement request.
+func (s *OnCallStore) CreateAck(ctx context.Context, ack *models.OnCallAck) error {
+	if ack.ID == "" {
+		ack.ID = uuid.New().String()
+	}
+	if ack.CreatedAt.IsZero() {
+		ack.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO on_call_acks (id, org_id, gate_id, app_id, requested_by, reason, status,
+			decided_by, decided_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := s.conn().ExecContext(ctx, query,
+		ack.ID,
+		ack.OrgID,
+		ack.GateID,
+		ack.AppID,
+		ack.RequestedBy,
+		ack.Reason,
+		string(ack.Status),
+		ack.DecidedBy,
+		ack.DecidedAt,
+		ack.ExpiresAt,
+		ack.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting on-call ack: %w", err)
+	}
+	return nil
+}
+
+// onCallAckColumns lists the columns read by scanOnCallAck.
+const onCallAckColumns = `id, org_id, gate_id, app_id, requested_by, reason, status,
+	decided_by, decided_at, expires_at, created_at`
+
+// GetAck retrieves an on-call acknowledgement by ID. It returns nil if it does not exist.
+func (s *OnCallStore) GetAck(ctx context.Context, id string) (*models.OnCallAck, error) {
+	query, args := newSelect(onCallAckColumns, "on_call_acks").Where("id = ?", id).Build()
+
+	ack, err := scanOnCallAck(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying on-call ack: %w", err)
+	}
+	return ack, nil
+}
+
+// ListAcks retrieves the most recent on-call acknowledgement requests for an organization.
+func (s *OnCallStore) ListAcks(ctx context.Context, orgID string, limit int) ([]*models.OnCallAck, error) {
+	q := newSelect(onCallAckColumns, "on_call_acks").
+		Where("org_id = ?", orgID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "on-call ack", q, scanOnCallAck)
+}
+
+// DecideAck saves the answer to an on-call acknowledgement request.
+func (s *OnCallStore) DecideAck(ctx context.Context, ack *models.OnCallAck) error {
+	query := `
+		UPDATE on_call_acks
+		SET status = $2, decided_by = $3, decided_at = $4, expires_at = $5
+		WHERE id = $1
+	`
+
+	result, err := s.conn().ExecContext(ctx, query,
+		ack.ID,
+		string(ack.Status),
+		ack.DecidedBy,
+		ack.DecidedAt,
+		ack.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("deciding on-call ack: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("on-call ack not found: %s", ack.ID)
+	}
+	return nil
+}
+
+// scanOnCallGate reads a single on-call gate row selected with onCallGateColumns.
+func scanOnCallGate(row rowScanner) (*models.OnCallGate, error) {
+	var g models.OnCallGate
+	var provider, requirement string
+
+	if err := row.Scan(
+		&g.ID, &g.OrgID, &g.Name, &g.Match, &provider, &g.ScheduleID, &g.APIToken, &requirement,
+		pq.Array(&g.BusinessDays), &g.BusinessStart, &g.BusinessEnd, &g.Timezone, &g.Enabled,
+		&g.CreatedBy, &g.CreatedAt, &g.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	g.Provider = models.OnCallProvider(provider)
+	g.Requirement = models.OnCallRequirement(requirement)
+	return &g, nil
+}
+
+// scanOnCallAck reads a single on-call acknowledgement row selected with onCallAckColumns.
+func scanOnCallAck(row rowScanner) (*models.OnCallAck, error) {
+	var a models.OnCallAck
+	var status string
+	var decidedAt sql.NullTime
+
+	if err := row.Scan(
+		&a.ID, &a.OrgID, &a.GateID, &a.AppID, &a.RequestedBy, &a.Reason, &status,
+		&a.DecidedBy, &decidedAt, &a.ExpiresAt, &a.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	a.Status = models.OnCallAckStatus(status)
+	if decidedAt.Valid {
+		a.DecidedAt = &decidedAt.Time
+	}
+	return &a, nil
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// OperationStore implements store.OperationStore using PostgreSQL.
+type OperationStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *OperationStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const operationColumns = `id, kind, status, target_id, progress, stage, errors, error, result, created_by,
+	created_at, updated_at, finished_at`
+
+// Create stores a new operation.
+func (s *OperationStore) Create(ctx context.Context, op *models.Operation) error {
+	if op.ID == "" {
+		op.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if op.CreatedAt.IsZero() {
+		op.CreatedAt = now
+	}
+	op.UpdatedAt = op.CreatedAt
+	if op.Status == "" {
+		op.Status = models.OperationRunning
+	}
+	if op.Errors == nil {
+		op.Errors = []string{}
+	}
+
+	errorsJSON, resultJSON, err := marshalOperation(op)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO operations (id, kind, status, target_id, progress, stage, errors, error, result, created_by,
+			created_at, updated_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		op.ID, op.Kind, op.Status, op.TargetID, op.Progress, op.Stage, errorsJSON, op.Error, resultJSON, op.CreatedBy,
+		op.CreatedAt, op.UpdatedAt, op.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating operation: %w", err)
+	}
+	return nil
+}
+
+// Update saves an operation's status, progress, stage, errors, result and
+// finish time, and sets its update time to now.
+func (s *OperationStore) Update(ctx context.Context, op *models.Operation) error {
+	errorsJSON, resultJSON, err := marshalOperation(op)
+	if err != nil {
+		return err
+	}
+	op.UpdatedAt = time.Now()
+	query := `
+		UPDATE operations
+		SET status = $2, progress = $3, stage = $4, errors = $5, error = $6, result = $7, updated_at = $8, finished_at = $9
+		WHERE id = $1
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		op.ID, op.Status, op.Progress, op.Stage, errorsJSON, op.Error, resultJSON, op.UpdatedAt, op.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating operation: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an operation by ID. It returns nil if the operation does not
+// exist.
+func (s *OperationStore) Get(ctx context.Context, id string) (*models.Operation, error) {
+	query, args := newSelect(operationColumns, "operations").Where("id = ?", id).Build()
+	op, err := scanOperation(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying operation: %w", err)
+	}
+	return op, nil
+}
+
+// FailStale fails running operations not updated since before, e.g. because
+// the server running them stopped.
+func (s *OperationStore) FailStale(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		UPDATE operations SET status = $1, error = 'the server running the operation stopped', finished_at = NOW()
+		WHERE status = $2 AND updated_at < $3
+	`
+	res, err := s.conn().ExecContext(ctx, query, models.OperationFailed, models.OperationRunning, before)
+	if err != nil {
+		return 0, fmt.Errorf("failing stale operations: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting stale operations: %w", err)
+	}
+	return int(n), nil
+}
+
+// DeleteFinishedBefore deletes operations that finished before the given
+// time.
+func (s *OperationStore) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM operations WHERE finished_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting finished operations: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting deleted operations: %w", err)
+	}
+	return int(n), nil
+}
+
+func marshalOperation(op *models.Operation) (errorsJSON, resultJSON []byte, err error) {
+	errs := op.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+	if errorsJSON, err = json.Marshal(errs); err != nil {
+		return nil, nil, fmt.Errorf("marshaling operation errors: %w", err)
+	}
+	if op.Result != nil {
+		if resultJSON, err = json.Marshal(op.Result); err != nil {
+			return nil, nil, fmt.Errorf("marshaling operation result: %w", err)
+		}
+	}
+	return errorsJSON, resultJSON, nil
+}
+
+// scanOperation reads a single operation row.
+func scanOperation(row rowScanner) (*models.Operation, error) {
+	var op models.Operation
+	var errorsJSON, resultJSON []byte
+	var finishedAt sql.NullTime
+	err := row.Scan(
+		&op.ID, &op.Kind, &op.Status, &op.TargetID, &op.Progress, &op.Stage, &errorsJSON, &op.Error, &resultJSON,
+		&op.CreatedBy, &op.CreatedAt, &op.UpdatedAt, &finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(errorsJSON, &op.Errors); err != nil {
+		return nil, fmt.Errorf("unmarshaling operation errors: %w", err)
+	}
+	if len(resultJSON) > 0 {
+		if err := json.Unmarshal(resultJSON, &op.Result); err != nil {
+			return nil, fmt.Errorf("unmarshaling operation result: %w", err)
+		}
+	}
+	if finishedAt.Valid {
+		op.FinishedAt = &finishedAt.Time
+	}
+	return &op, nil
+}
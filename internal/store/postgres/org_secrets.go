@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// OrgSecretStore implements store.OrgSecretStore using PostgreSQL.
+type OrgSecretStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *OrgSecretStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// orgSecretColumns lists the columns read by scanOrgSecret.
+const orgSecretColumns = `id, org_id, key, encrypted_value, description, scope, app_ids, created_by, created_at, updated_at`
+
+// Create stores a new shared secret. Keys are unique within an org.
+func (s *OrgSecretStore) Create(ctx context.Context, secret *models.OrgSecret) error {
+	if secret.ID == "" {
+		secret.ID = uuid.New().String()
+	}
+	now := time.Now()
+	secret.CreatedAt, secret.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO org_secrets (id, org_id, key, encrypted_value, description, scope, app_ids, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		secret.ID, secret.OrgID, secret.Key, secret.EncryptedValue, secret.Description, secret.Scope,
+		pq.Array(secret.AppIDs), secret.CreatedBy, secret.CreatedAt, secret.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting org secret: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting org secret: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an org's shared secret by key. It returns nil if the secret does not exist.
+func (s *OrgSecretStore) Get(ctx context.Context, orgID, key string) (*models.OrgSecret, error) {
+	query, args := newSelect(orgSecretColumns, "org_secrets").Where("org_id = ?", orgID).Where("key = ?", key).Build()
+
+	secret, err := scanOrgSecret(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying org secret: %w", err)
+	}
+	return secret, nil
+}
+
+// List retrieves all of an org's shared secrets, ordered by key.
+func (s *OrgSecretStore) List(ctx context.Context, orgID string) ([]*models.OrgSecret, error) {
+	q := newSelect(orgSecretColumns, "org_secrets").Where("org_id = ?", orgID).OrderBy("key ASC")
+	return listRows(ctx, s.conn(), "org secret", q, scanOrgSecret)
+}
+
+// Update saves a shared secret's value, description and scope.
+func (s *OrgSecretStore) Update(ctx context.Context, secret *models.OrgSecret) error {
+	secret.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE org_secrets
+		SET encrypted_value = $2, description = $3, scope = $4, app_ids = $5, updated_at = $6
+		WHERE id = $1
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		secret.ID, secret.EncryptedValue, secret.Description, secret.Scope, pq.Array(secret.AppIDs), secret.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating org secret: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a shared secret and its usage records.
+func (s *OrgSecretStore) Delete(ctx context.Context, id string) error {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM org_secrets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting org secret: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordUsage records that shared secrets were injected into services,
+// replacing the previous time for each secret, app and service.
+func (s *OrgSecretStore) RecordUsage(ctx context.Context, usages []*models.OrgSecretUsage) error {
+	query := `
+		INSERT INTO org_secret_usages (secret_id, app_id, service_name, last_used_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (secret_id, app_id, service_name) DO UPDATE SET last_used_at = EXCLUDED.last_used_at
+	`
+	for _, u := range usages {
+		if u.LastUsedAt.IsZero() {
+			u.LastUsedAt = time.Now()
+		}
+		if _, err := s.conn().ExecContext(ctx, query, u.SecretID, u.AppID, u.ServiceName, u.LastUsedAt); err != nil {
+			return fmt.Errorf("recording org secret usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListUsage retrieves where a shared secret has been injected, most recent first.
+func (s *OrgSecretStore) ListUsage(ctx context.Context, secretID string) ([]*models.OrgSecretUsage, error) {
+	q := newSelect("secret_id, app_id, service_name, last_used_at", "org_secret_usages").
+		Where("secret_id = ?", secretID).
+		OrderBy("last_used_at DESC")
+	return listRows(ctx, s.conn(), "org secret usage", q, func(row rowScanner) (*models.OrgSecretUsage, error) {
+		var u models.OrgSecretUsage
+		if err := row.Scan(&u.SecretID, &u.AppID, &u.ServiceName, &u.LastUsedAt); err != nil {
+			return nil, err
+		}
+		return &u, nil
+	})
+}
+
+// scanOrgSecret reads a single org secret row.
+func scanOrgSecret(row rowScanner) (*models.OrgSecret, error) {
+	var secret models.OrgSecret
+	var appIDs pq.StringArray
+	if err := row.Scan(
+		&secret.ID, &secret.OrgID, &secret.Key, &secret.EncryptedValue, &secret.Description, &secret.Scope, &appIDs,
+		&secret.CreatedBy, &secret.CreatedAt, &secret.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	secret.AppIDs = appIDs
+	return &secret, nil
+}
@@ -16,14 +16,12 @@ type OrgStore struct {
 	db     *sql.DB
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 }
 
 // conn returns the queryable connection (transaction or database).
 func (s *OrgStore) conn() queryable {
-	if s.tx != nil {
-		return s.tx
-	}
-	return s.db
+	return s.stmts.conn(s.db, s.tx)
 }
 
 // Create creates a new organization.
@@ -284,6 +282,23 @@ func (s *OrgStore) IsMember(ctx context.Context, orgID, userID string) (bool, er
 	return exists, nil
 }
 
+// GetMemberRole returns a user's role in an organization, or an empty role
+// if they are not a member.
+func (s *OrgStore) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	query := `SELECT role FROM org_memberships WHERE org_id = $1 AND user_id = $2`
+
+	var role string
+	err := s.conn().QueryRowContext(ctx, query, orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting organization member role: %w", err)
+	}
+
+	return models.Role(role), nil
+}
+
 // GetDefault returns the default organization (first created).
 func (s *OrgStore) GetDefault(ctx context.Context) (*models.Organization, error) {
 	query := `
@@ -397,3 +412,42 @@ func (s *OrgStore) ListMembers(ctx context.Context, orgID string) ([]*models.Org
 
 	return members, nil
 }
+
+// ListAll retrieves every organization on the instance.
+func (s *OrgStore) ListAll(ctx context.Context) ([]*models.Organization, error) {
+	query := `
+		SELECT id, name, slug, COALESCE(description, ''), COALESCE(icon_url, ''),
+		       created_at, updated_at
+		FROM organizations
+		ORDER BY created_at ASC`
+
+	rows, err := s.conn().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying all organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []*models.Organization
+	for rows.Next() {
+		org := &models.Organization{}
+		err := rows.Scan(
+			&org.ID,
+			&org.Name,
+			&org.Slug,
+			&org.Description,
+			&org.IconURL,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning organization row: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating organization rows: %w", err)
+	}
+
+	return orgs, nil
+}
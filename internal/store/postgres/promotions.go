@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// PromotionStore implements store.PromotionStore using PostgreSQL.
+type PromotionStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *PromotionStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// promotionPolicyColumns lists the columns read by scanPromotionPolicy.
+const promotionPolicyColumns = `id, app_id, service_name, target_app_id, target_service_name, window_minutes,
+	max_error_rate, require_approval, enabled, created_by, created_at, updated_at`
+
+// promotionColumns lists the columns read by scanPromotion.
+const promotionColumns = `id, policy_id, environment_id, source_deployment_id, target_deployment_id, status,
+	window_minutes, max_error_rate, window_ends_at, error_rate, log_lines, reason, requested_by, decided_by,
+	created_at, updated_at`
+
+// CreatePolicy stores a new promotion policy.
+func (s *PromotionStore) CreatePolicy(ctx context.Context, policy *models.PromotionPolicy) error {
+	if policy.ID == "" {
+		policy.ID = uuid.New().String()
+	}
+	now := time.Now()
+	policy.CreatedAt, policy.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO promotion_policies (id, app_id, service_name, target_app_id, target_service_name,
+			window_minutes, max_error_rate, require_approval, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		policy.ID, policy.AppID, policy.ServiceName, policy.TargetAppID, policy.TargetServiceName,
+		policy.WindowMinutes, policy.MaxErrorRate, policy.RequireApproval, policy.Enabled, policy.CreatedBy,
+		policy.CreatedAt, policy.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting promotion policy: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting promotion policy: %w", err)
+	}
+	return nil
+}
+
+// GetPolicy retrieves a promotion policy by ID. It returns nil if the policy does not exist.
+func (s *PromotionStore) GetPolicy(ctx context.Context, id string) (*models.PromotionPolicy, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(promotionPolicyColumns, "promotion_policies").Where("id = ?", id).Build()
+
+	policy, err := scanPromotionPolicy(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying promotion policy: %w", err)
+	}
+	return policy, nil
+}
+
+// ListPolicies retrieves the promotion policies whose source is the given app, oldest first.
+func (s *PromotionStore) ListPolicies(ctx context.Context, appID string) ([]*models.PromotionPolicy, error) {
+	q := newSelect(promotionPolicyColumns, "promotion_policies").
+		Where("app_id = ?", appID).
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "promotion policy", q, scanPromotionPolicy)
+}
+
+// ListEnabledPolicies retrieves every enabled promotion policy.
+func (s *PromotionStore) ListEnabledPolicies(ctx context.Context) ([]*models.PromotionPolicy, error) {
+	q := newSelect(promotionPolicyColumns, "promotion_policies").
+		Where("enabled = TRUE").
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "promotion policy", q, scanPromotionPolicy)
+}
+
+// UpdatePolicy updates a policy's target, criteria and flags.
+func (s *PromotionStore) UpdatePolicy(ctx context.Context, policy *models.PromotionPolicy) error {
+	policy.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE promotion_policies
+		SET target_app_id = $1, target_service_name = $2, window_minutes = $3, max_error_rate = $4,
+			require_approval = $5, enabled = $6, updated_at = $7
+		WHERE id = $8
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		policy.TargetAppID, policy.TargetServiceName, policy.WindowMinutes, policy.MaxErrorRate,
+		policy.RequireApproval, policy.Enabled, policy.UpdatedAt, policy.ID,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("updating promotion policy: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("updating promotion policy: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeletePolicy removes a policy and its promotion history.
+func (s *PromotionStore) DeletePolicy(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM promotion_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting promotion policy: %w", err)
+	}
+	return nil
+}
+
+// Create stores a new promotion evaluation.
+func (s *PromotionStore) Create(ctx context.Context, promotion *models.Promotion) error {
+	if promotion.ID == "" {
+		promotion.ID = uuid.New().String()
+	}
+	now := time.Now()
+	promotion.CreatedAt, promotion.UpdatedAt = now, now
+	if promotion.Status == "" {
+		promotion.Status = models.PromotionEvaluating
+	}
+
+	query := `
+		INSERT INTO promotions (id, policy_id, environment_id, source_deployment_id, target_deployment_id,
+			status, window_minutes, max_error_rate, window_ends_at, error_rate, log_lines, reason,
+			requested_by, decided_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		promotion.ID, nullString(promotion.PolicyID), nullString(promotion.EnvironmentID), promotion.SourceDeploymentID,
+		nullString(promotion.TargetDeploymentID), string(promotion.Status), promotion.WindowMinutes,
+		promotion.MaxErrorRate, promotion.WindowEndsAt, promotion.ErrorRate, promotion.LogLines, promotion.Reason,
+		promotion.RequestedBy, promotion.DecidedBy, promotion.CreatedAt, promotion.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting promotion: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting promotion: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a promotion by ID. It returns nil if the promotion does not exist.
+func (s *PromotionStore) Get(ctx context.Context, id string) (*models.Promotion, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(promotionColumns, "promotions").Where("id = ?", id).Build()
+	return s.getOne(ctx, query, args)
+}
+
+// GetBySource retrieves the promotion of a source deployment under a policy.
+// It returns nil if the deployment has not been evaluated.
+func (s *PromotionStore) GetBySource(ctx context.Context, policyID, sourceDeploymentID string) (*models.Promotion, error) {
+	query, args := newSelect(promotionColumns, "promotions").
+		Where("policy_id = ?", policyID).
+		Where("source_deployment_id = ?", sourceDeploymentID).
+		Build()
+	return s.getOne(ctx, query, args)
+}
+
+func (s *PromotionStore) getOne(ctx context.Context, query string, args []any) (*models.Promotion, error) {
+	promotion, err := scanPromotion(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// ListOpen retrieves promotions that are evaluating, awaiting approval or blocked.
+func (s *PromotionStore) ListOpen(ctx context.Context) ([]*models.Promotion, error) {
+	q := newSelect(promotionColumns, "promotions").
+		Where("status IN ('evaluating', 'awaiting_approval', 'blocked')").
+		OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "promotion", q, scanPromotion)
+}
+
+// ListByApp retrieves the promotions of policies whose source is the given
+// app and the promotions into its environments, newest first.
+func (s *PromotionStore) ListByApp(ctx context.Context, appID string, limit int) ([]*models.Promotion, error) {
+	q := newSelect(qualifyColumns("p", promotionColumns),
+		"promotions p LEFT JOIN promotion_policies pp ON p.policy_id = pp.id LEFT JOIN environments e ON p.environment_id = e.id").
+		Where("(pp.app_id = ? OR e.app_id = ?)", appID, appID).
+		OrderBy("p.created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "promotion", q, scanPromotion)
+}
+
+// ListByDeployment retrieves the promotions a deployment was the source or target of, newest first.
+func (s *PromotionStore) ListByDeployment(ctx context.Context, deploymentID string) ([]*models.Promotion, error) {
+	if _, err := uuid.Parse(deploymentID); err != nil {
+		return nil, nil
+	}
+	q := newSelect(promotionColumns, "promotions").
+		Where("(source_deployment_id = ? OR target_deployment_id = ?)", deploymentID, deploymentID).
+		OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "promotion", q, scanPromotion)
+}
+
+// Update records a promotion's status, observations and outcome.
+func (s *PromotionStore) Update(ctx context.Context, promotion *models.Promotion) error {
+	promotion.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE promotions
+		SET status = $1, target_deployment_id = $2, error_rate = $3, log_lines = $4, reason = $5,
+			decided_by = $6, updated_at = $7
+		WHERE id = $8
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		string(promotion.Status), nullString(promotion.TargetDeploymentID), promotion.ErrorRate,
+		promotion.LogLines, promotion.Reason, promotion.DecidedBy, promotion.UpdatedAt, promotion.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating promotion: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanPromotionPolicy(row rowScanner) (*models.PromotionPolicy, error) {
+	var p models.PromotionPolicy
+	if err := row.Scan(
+		&p.ID, &p.AppID, &p.ServiceName, &p.TargetAppID, &p.TargetServiceName, &p.WindowMinutes,
+		&p.MaxErrorRate, &p.RequireApproval, &p.Enabled, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func scanPromotion(row rowScanner) (*models.Promotion, error) {
+	var p models.Promotion
+	var status string
+	var policyID, environmentID, targetID sql.NullString
+	if err := row.Scan(
+		&p.ID, &policyID, &environmentID, &p.SourceDeploymentID, &targetID, &status, &p.WindowMinutes,
+		&p.MaxErrorRate, &p.WindowEndsAt, &p.ErrorRate, &p.LogLines, &p.Reason, &p.RequestedBy,
+		&p.DecidedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	p.Status = models.PromotionStatus(status)
+	p.PolicyID = policyID.String
+	p.EnvironmentID = environmentID.String
+	p.TargetDeploymentID = targetID.String
+	return &p, nil
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// selectQuery builds SELECT statements with positional arguments. Conditions
+// use ? placeholders, which are renumbered to $n in the order they are added,
+// so list variants can share one column list and scan function.
+type selectQuery struct {
+	columns string
+	from    string
+	where   []string
+	args    []any
+	orderBy string
+	limit   int
+	offset  int
+}
+
+// newSelect starts a query over the given table expression, e.g. "apps" or
+// "deployments d JOIN apps a ON d.app_id = a.id".
+func newSelect(columns, from string) *selectQuery {
+	return &selectQuery{columns: columns, from: from}
+}
+
+// Where adds a condition joined with AND. Each ? in cond consumes one arg.
+func (q *selectQuery) Where(cond string, args ...any) *selectQuery {
+	q.where = append(q.where, cond)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// NotDeleted excludes soft-deleted rows. alias qualifies the column when the
+// query joins several tables; pass "" for a single table.
+func (q *selectQuery) NotDeleted(alias string) *selectQuery {
+	if alias != "" {
+		return q.Where(alias + ".deleted_at IS NULL")
+	}
+	return q.Where("deleted_at IS NULL")
+}
+
+// OrderBy sets the ORDER BY clause.
+func (q *selectQuery) OrderBy(order string) *selectQuery {
+	q.orderBy = order
+	return q
+}
+
+// Page limits the result to one page. A limit of zero or less means no limit.
+func (q *selectQuery) Page(limit, offset int) *selectQuery {
+	q.limit = limit
+	q.offset = offset
+	return q
+}
+
+// Build renders the SQL and its arguments. LIMIT and OFFSET are passed as
+// arguments so paginated queries share a prepared statement.
+func (q *selectQuery) Build() (string, []any) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	sb.WriteString(q.columns)
+	sb.WriteString(" FROM ")
+	sb.WriteString(q.from)
+
+	args := append([]any(nil), q.args...)
+	if len(q.where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(q.where, " AND "))
+	}
+	if q.orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(q.orderBy)
+	}
+	if q.limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		args = append(args, q.limit)
+		if q.offset > 0 {
+			sb.WriteString(" OFFSET ?")
+			args = append(args, q.offset)
+		}
+	}
+
+	return numberPlaceholders(sb.String()), args
+}
+
+// numberPlaceholders rewrites ? placeholders to PostgreSQL's $1, $2, ...
+// Question marks inside single-quoted literals are left alone.
+func numberPlaceholders(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+	n := 0
+	inLiteral := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inLiteral = !inLiteral
+			sb.WriteRune(r)
+		case r == '?' && !inLiteral:
+			n++
+			fmt.Fprintf(&sb, "$%d", n)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// qualifyColumns prefixes each column in a comma-separated list with alias,
+// for reusing a column list in joined queries. Columns must be plain names.
+func qualifyColumns(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, col := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(col)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// listRows runs a query and scans every row with scan. entity names the rows
+// in error messages, e.g. "app".
+func listRows[T any](ctx context.Context, conn queryable, entity string, q *selectQuery, scan func(rowScanner) (T, error)) ([]T, error) {
+	query, args := q.Build()
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying %ss: %w", entity, err)
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning %s row: %w", entity, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating %s rows: %w", entity, err)
+	}
+
+	return items, nil
+}
+
+// maxCachedStatements bounds the statement cache. Queries beyond it run
+// unprepared rather than evicting, since the set of query shapes is fixed.
+const maxCachedStatements = 512
+
+// stmtCache prepares each distinct query once per database handle and
+// reuses it across requests and transactions.
+type stmtCache struct {
+	db      *sql.DB
+	replica *replica // Serves reads of contexts marked with store.ReadAfter, if set
+
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStmtCache creates an empty statement cache for db.
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{db: db, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare returns the cached statement for query, preparing it on first use.
+func (c *stmtCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+	if len(c.stmts) >= maxCachedStatements {
+		return nil, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// Close releases every cached statement.
+func (c *stmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	return firstErr
+}
+
+// conn returns a queryable that runs queries through the cache, bound to tx
+// when it is non-nil. A nil cache returns the plain handle.
+func (c *stmtCache) conn(db *sql.DB, tx *sql.Tx) queryable {
+	if c == nil {
+		if tx != nil {
+			return tx
+		}
+		return db
+	}
+	return &preparedConn{cache: c, tx: tx}
+}
+
+// preparedConn implements queryable using cached prepared statements. If a
+// statement cannot be prepared the query runs directly, so errors surface from
+// the query itself.
+type preparedConn struct {
+	cache *stmtCache
+	tx    *sql.Tx
+}
+
+// stmt returns the statement for query, scoped to the transaction if any.
+func (p *preparedConn) stmt(ctx context.Context, query string) *sql.Stmt {
+	stmt, err := p.cache.prepare(ctx, query)
+	if err != nil || stmt == nil {
+		return nil
+	}
+	if p.tx != nil {
+		return p.tx.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+// direct returns the underlying handle for unprepared queries.
+func (p *preparedConn) direct() queryable {
+	if p.tx != nil {
+		return p.tx
+	}
+	return p.cache.db
+}
+
+func (p *preparedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return p.direct().ExecContext(ctx, query, args...)
+}
+
+// replicaFor returns the read replica to run query of ctx, or nil to run it
+// on the primary.
+func (p *preparedConn) replicaFor(ctx context.Context, query string) *sql.DB {
+	r := p.cache.replica
+	if r == nil || p.tx != nil || !readOnly(query) || !r.serves(ctx) {
+		return nil
+	}
+	return r.db
+}
+
+func (p *preparedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if db := p.replicaFor(ctx, query); db != nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return p.direct().QueryContext(ctx, query, args...)
+}
+
+func (p *preparedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if db := p.replicaFor(ctx, query); db != nil {
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return p.direct().QueryRowContext(ctx, query, args...)
+}
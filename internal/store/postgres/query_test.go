@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectQueryBuild(t *testing.T) {
+	query, args := newSelect("id, name", "apps").
+		Where("owner_id = ? AND name = ?", "u1", "web").
+		NotDeleted("").
+		OrderBy("created_at DESC").
+		Page(20, 40).
+		Build()
+
+	want := "SELECT id, name FROM apps WHERE owner_id = $1 AND name = $2 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $3 OFFSET $4"
+	if query != want {
+		t.Errorf("query = %q\nwant    %q", query, want)
+	}
+	if !reflect.DeepEqual(args, []any{"u1", "web", 20, 40}) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestSelectQueryJoinedSoftDelete(t *testing.T) {
+	query, args := newSelect(qualifyColumns("d", "id, app_id"), "deployments d JOIN apps a ON d.app_id = a.id").
+		Where("a.owner_id = ?", "u1").
+		NotDeleted("a").
+		Build()
+
+	want := "SELECT d.id, d.app_id FROM deployments d JOIN apps a ON d.app_id = a.id WHERE a.owner_id = $1 AND a.deleted_at IS NULL"
+	if query != want {
+		t.Errorf("query = %q\nwant    %q", query, want)
+	}
+	if len(args) != 1 {
+		t.Errorf("expected one arg, got %v", args)
+	}
+}
+
+func TestNumberPlaceholdersSkipsLiterals(t *testing.T) {
+	got := numberPlaceholders("SELECT '?' FROM t WHERE a = ? AND b = ?")
+	want := "SELECT '?' FROM t WHERE a = $1 AND b = $2"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
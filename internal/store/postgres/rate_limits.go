@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RateLimitStore implements store.RateLimitStore using PostgreSQL.
+type RateLimitStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *RateLimitStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Take refills a bucket holding up to burst tokens at rate tokens per second
+// and removes a token if it holds one. The refill and the removal are one
+// statement, so concurrent requests on any server cannot overdraw a bucket.
+func (s *RateLimitStore) Take(ctx context.Context, key string, rate float64, burst int) (float64, bool, error) {
+	// In the update, rate_limit_buckets is the bucket before this request
+	refill := `LEAST($3::float8, rate_limit_buckets.tokens +
+		EXTRACT(EPOCH FROM NOW() - rate_limit_buckets.updated_at) * $2::float8)`
+	query := `
+		INSERT INTO rate_limit_buckets (key, tokens, allowed, updated_at)
+		VALUES ($1, $3::float8 - 1, TRUE, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE WHEN ` + refill + ` >= 1 THEN ` + refill + ` - 1 ELSE ` + refill + ` END,
+			allowed = ` + refill + ` >= 1,
+			updated_at = NOW()
+		RETURNING tokens, allowed
+	`
+	var tokens float64
+	var allowed bool
+	if err := s.conn().QueryRowContext(ctx, query, key, rate, burst).Scan(&tokens, &allowed); err != nil {
+		return 0, false, fmt.Errorf("taking rate limit token: %w", err)
+	}
+	return tokens, allowed, nil
+}
+
+// Peek returns the tokens a bucket would hold after refilling, without
+// changing it. A missing bucket is full.
+func (s *RateLimitStore) Peek(ctx context.Context, key string, rate float64, burst int) (float64, error) {
+	query := `
+		SELECT LEAST($3::float8, tokens + EXTRACT(EPOCH FROM NOW() - updated_at) * $2::float8)
+		FROM rate_limit_buckets
+		WHERE key = $1
+	`
+	var tokens float64
+	err := s.conn().QueryRowContext(ctx, query, key, rate, burst).Scan(&tokens)
+	if errors.Is(err, sql.ErrNoRows) {
+		return float64(burst), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading rate limit bucket: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteIdle removes buckets not used since before.
+func (s *RateLimitStore) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.conn().ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting idle rate limit buckets: %w", err)
+	}
+	return res.RowsAffected()
+}
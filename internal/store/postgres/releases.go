@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ReleaseStore implements store.ReleaseStore using PostgreSQL.
+type ReleaseStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *ReleaseStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Upsert creates or replaces the release for its deployment.
+func (s *ReleaseStore) Upsert(ctx context.Context, release *models.Release) error {
+	if release.ID == "" {
+		release.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if release.CreatedAt.IsZero() {
+		release.CreatedAt = now
+	}
+	release.UpdatedAt = now
+
+	commitsJSON, err := json.Marshal(release.Commits)
+	if err != nil {
+		return fmt.Errorf("marshaling commits: %w", err)
+	}
+
+	// On conflict the original id and created_at are kept; RETURNING reports them back
+	query := `
+		INSERT INTO releases (id, app_id, deployment_id, service_name, version, from_commit, to_commit,
+			commits, notes, source, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (deployment_id) DO UPDATE SET
+			from_commit = EXCLUDED.from_commit,
+			to_commit = EXCLUDED.to_commit,
+			commits = EXCLUDED.commits,
+			notes = EXCLUDED.notes,
+			source = EXCLUDED.source,
+			created_by = EXCLUDED.created_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	return s.conn().QueryRowContext(ctx, query,
+		release.ID,
+		release.AppID,
+		release.DeploymentID,
+		release.ServiceName,
+		release.Version,
+		nullString(release.FromCommit),
+		nullString(release.ToCommit),
+		commitsJSON,
+		nullString(release.Notes),
+		string(release.Source),
+		release.CreatedBy,
+		release.CreatedAt,
+		release.UpdatedAt,
+	).Scan(&release.ID, &release.CreatedAt)
+}
+
+// releaseColumns lists the columns read by scanRelease.
+const releaseColumns = `id, app_id, deployment_id, service_name, version, from_commit, to_commit,
+	commits, notes, source, created_by, created_at, updated_at`
+
+// GetByDeployment retrieves the release for a deployment.
+func (s *ReleaseStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.Release, error) {
+	query, args := newSelect(releaseColumns, "releases").Where("deployment_id = ?", deploymentID).Build()
+
+	release, err := scanRelease(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return release, err
+}
+
+// ListByApp retrieves all releases for an application, newest first.
+func (s *ReleaseStore) ListByApp(ctx context.Context, appID string) ([]*models.Release, error) {
+	q := newSelect(releaseColumns, "releases").Where("app_id = ?", appID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "release", q, scanRelease)
+}
+
+// scanRelease reads a single release row.
+func scanRelease(row rowScanner) (*models.Release, error) {
+	var r models.Release
+	var fromCommit, toCommit, notes sql.NullString
+	var commitsJSON []byte
+	var source string
+
+	if err := row.Scan(
+		&r.ID, &r.AppID, &r.DeploymentID, &r.ServiceName, &r.Version, &fromCommit, &toCommit,
+		&commitsJSON, &notes, &source, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	r.FromCommit = fromCommit.String
+	r.ToCommit = toCommit.String
+	r.Notes = notes.String
+	r.Source = models.ReleaseNotesSource(source)
+	if err := json.Unmarshal(commitsJSON, &r.Commits); err != nil {
+		return nil, fmt.Errorf("unmarshaling commits: %w", err)
+	}
+
+	return &r, nil
+}
+
+// nullString maps an empty string to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ScaleEventStore implements store.ScaleEventStore using PostgreSQL.
+type ScaleEventStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *ScaleEventStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const scaleEventColumns = `id, app_id, service_name, from_replicas, to_replicas, source, reason, user_id, created_at`
+
+// Create stores a new event.
+func (s *ScaleEventStore) Create(ctx context.Context, event *models.ScaleEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO scale_events (id, app_id, service_name, from_replicas, to_replicas, source, reason, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		event.ID, event.AppID, event.ServiceName, event.FromReplicas, event.ToReplicas,
+		event.Source, event.Reason, event.UserID, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating scale event: %w", err)
+	}
+	return nil
+}
+
+// List retrieves a service's most recent events, newest first.
+func (s *ScaleEventStore) List(ctx context.Context, appID, serviceName string, limit int) ([]*models.ScaleEvent, error) {
+	q := newSelect(scaleEventColumns, "scale_events").
+		Where("app_id = ?", appID).
+		Where("service_name = ?", serviceName).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "scale event", q, scanScaleEvent)
+}
+
+// DeleteBefore removes events created before the given time.
+func (s *ScaleEventStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM scale_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting scale events: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return rows, nil
+}
+
+// scanScaleEvent reads a single scale event row.
+func scanScaleEvent(row rowScanner) (*models.ScaleEvent, error) {
+	var e models.ScaleEvent
+	if err := row.Scan(
+		&e.ID, &e.AppID, &e.ServiceName, &e.FromReplicas, &e.ToReplicas,
+		&e.Source, &e.Reason, &e.UserID, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
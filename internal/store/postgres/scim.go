@@ -0,0 +1,396 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// SCIMStore implements store.SCIMStore using PostgreSQL.
+type SCIMStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *SCIMStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// GetToken retrieves an org's SCIM token metadata. It returns nil if SCIM is disabled.
+func (s *SCIMStore) GetToken(ctx context.Context, orgID string) (*models.SCIMToken, error) {
+	query := `SELECT org_id, token_prefix, created_by, created_at, last_used_at FROM scim_tokens WHERE org_id = $1`
+
+	var t models.SCIMToken
+	var lastUsed sql.NullTime
+	err := s.conn().QueryRowContext(ctx, query, orgID).Scan(&t.OrgID, &t.TokenPrefix, &t.CreatedBy, &t.CreatedAt, &lastUsed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying scim token: %w", err)
+	}
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	return &t, nil
+}
+
+// SetToken creates or replaces an org's SCIM token.
+func (s *SCIMStore) SetToken(ctx context.Context, token *models.SCIMToken, tokenHash string) error {
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO scim_tokens (org_id, token_hash, token_prefix, created_by, created_at, last_used_at)
+		VALUES ($1, $2, $3, $4, $5, NULL)
+		ON CONFLICT (org_id) DO UPDATE SET token_hash = $2, token_prefix = $3, created_by = $4,
+			created_at = $5, last_used_at = NULL
+	`
+	_, err := s.conn().ExecContext(ctx, query, token.OrgID, tokenHash, token.TokenPrefix, token.CreatedBy, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("storing scim token: %w", err)
+	}
+	return nil
+}
+
+// DeleteToken removes an org's SCIM token.
+func (s *SCIMStore) DeleteToken(ctx context.Context, orgID string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM scim_tokens WHERE org_id = $1`, orgID); err != nil {
+		return fmt.Errorf("deleting scim token: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateToken returns the org ID for a token hash and records its use.
+func (s *SCIMStore) AuthenticateToken(ctx context.Context, tokenHash string) (string, error) {
+	query := `UPDATE scim_tokens SET last_used_at = NOW() WHERE token_hash = $1 RETURNING org_id`
+
+	var orgID string
+	err := s.conn().QueryRowContext(ctx, query, tokenHash).Scan(&orgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("authenticating scim token: %w", err)
+	}
+	return orgID, nil
+}
+
+// scimUserColumns lists the columns read by scanSCIMUser.
+const scimUserColumns = `id, org_id, user_id, external_id, user_name, display_name, active, provisioned,
+	created_at, updated_at`
+
+// CreateUser stores a new SCIM user link.
+func (s *SCIMStore) CreateUser(ctx context.Context, user *models.SCIMUser) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO scim_users (id, org_id, user_id, external_id, user_name, display_name, active, provisioned,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		user.ID, user.OrgID, user.UserID, user.ExternalID, user.UserName, user.DisplayName,
+		user.Active, user.Provisioned, user.CreatedAt, user.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting scim user: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting scim user: %w", err)
+	}
+	return nil
+}
+
+// GetUser retrieves a SCIM user by ID within an org. It returns nil if the user does not exist.
+func (s *SCIMStore) GetUser(ctx context.Context, orgID, id string) (*models.SCIMUser, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(scimUserColumns, "scim_users").Where("org_id = ?", orgID).Where("id = ?", id).Build()
+
+	user, err := scanSCIMUser(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying scim user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers retrieves all SCIM users for an org, oldest first.
+func (s *SCIMStore) ListUsers(ctx context.Context, orgID string) ([]*models.SCIMUser, error) {
+	q := newSelect(scimUserColumns, "scim_users").Where("org_id = ?", orgID).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "scim user", q, scanSCIMUser)
+}
+
+// UpdateUser updates a SCIM user's attributes.
+func (s *SCIMStore) UpdateUser(ctx context.Context, user *models.SCIMUser) error {
+	user.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE scim_users SET external_id = $1, user_name = $2, display_name = $3, active = $4, updated_at = $5
+		WHERE org_id = $6 AND id = $7
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		user.ExternalID, user.UserName, user.DisplayName, user.Active, user.UpdatedAt, user.OrgID, user.ID,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("updating scim user: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("updating scim user: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser removes a SCIM user link. Group memberships are removed by cascade.
+func (s *SCIMStore) DeleteUser(ctx context.Context, orgID, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM scim_users WHERE org_id = $1 AND id = $2`, orgID, id); err != nil {
+		return fmt.Errorf("deleting scim user: %w", err)
+	}
+	return nil
+}
+
+// scimGroupColumns lists the columns read by scanSCIMGroup.
+const scimGroupColumns = `id, org_id, external_id, display_name, created_at, updated_at`
+
+// CreateGroup stores a new SCIM group with its members.
+func (s *SCIMStore) CreateGroup(ctx context.Context, group *models.SCIMGroup) error {
+	if group.ID == "" {
+		group.ID = uuid.New().String()
+	}
+	now := time.Now()
+	group.CreatedAt, group.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO scim_groups (id, org_id, external_id, display_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		group.ID, group.OrgID, group.ExternalID, group.DisplayName, group.CreatedAt, group.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting scim group: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting scim group: %w", err)
+	}
+	return s.setGroupMembers(ctx, group)
+}
+
+// GetGroup retrieves a SCIM group by ID within an org. It returns nil if the group does not exist.
+func (s *SCIMStore) GetGroup(ctx context.Context, orgID, id string) (*models.SCIMGroup, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(scimGroupColumns, "scim_groups").Where("org_id = ?", orgID).Where("id = ?", id).Build()
+
+	group, err := scanSCIMGroup(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying scim group: %w", err)
+	}
+	if err := s.loadGroupMembers(ctx, []*models.SCIMGroup{group}); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroups retrieves all SCIM groups for an org with their members, oldest first.
+func (s *SCIMStore) ListGroups(ctx context.Context, orgID string) ([]*models.SCIMGroup, error) {
+	q := newSelect(scimGroupColumns, "scim_groups").Where("org_id = ?", orgID).OrderBy("created_at ASC")
+	groups, err := listRows(ctx, s.conn(), "scim group", q, scanSCIMGroup)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadGroupMembers(ctx, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// UpdateGroup updates a SCIM group's attributes and replaces its members.
+func (s *SCIMStore) UpdateGroup(ctx context.Context, group *models.SCIMGroup) error {
+	group.UpdatedAt = time.Now()
+
+	query := `UPDATE scim_groups SET external_id = $1, display_name = $2, updated_at = $3 WHERE org_id = $4 AND id = $5`
+	_, err := s.conn().ExecContext(ctx, query, group.ExternalID, group.DisplayName, group.UpdatedAt, group.OrgID, group.ID)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("updating scim group: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("updating scim group: %w", err)
+	}
+	return s.setGroupMembers(ctx, group)
+}
+
+// DeleteGroup removes a SCIM group. Memberships are removed by cascade.
+func (s *SCIMStore) DeleteGroup(ctx context.Context, orgID, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM scim_groups WHERE org_id = $1 AND id = $2`, orgID, id); err != nil {
+		return fmt.Errorf("deleting scim group: %w", err)
+	}
+	return nil
+}
+
+// setGroupMembers replaces a group's member rows.
+func (s *SCIMStore) setGroupMembers(ctx context.Context, group *models.SCIMGroup) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM scim_group_members WHERE group_id = $1`, group.ID); err != nil {
+		return fmt.Errorf("clearing scim group members: %w", err)
+	}
+	for _, memberID := range group.MemberIDs {
+		_, err := s.conn().ExecContext(ctx,
+			`INSERT INTO scim_group_members (group_id, scim_user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			group.ID, memberID)
+		if err != nil {
+			return fmt.Errorf("adding scim group member: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadGroupMembers fills in MemberIDs for the given groups.
+func (s *SCIMStore) loadGroupMembers(ctx context.Context, groups []*models.SCIMGroup) error {
+	for _, g := range groups {
+		rows, err := s.conn().QueryContext(ctx,
+			`SELECT scim_user_id FROM scim_group_members WHERE group_id = $1 ORDER BY scim_user_id`, g.ID)
+		if err != nil {
+			return fmt.Errorf("querying scim group members: %w", err)
+		}
+		g.MemberIDs = []string{}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scanning scim group member: %w", err)
+			}
+			g.MemberIDs = append(g.MemberIDs, id)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("iterating scim group members: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListMappings retrieves an org's IdP group to role mappings, ordered by group name.
+func (s *SCIMStore) ListMappings(ctx context.Context, orgID string) ([]models.SCIMGroupMapping, error) {
+	rows, err := s.conn().QueryContext(ctx,
+		`SELECT group_name, role FROM scim_group_mappings WHERE org_id = $1 ORDER BY group_name`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("querying scim group mappings: %w", err)
+	}
+	defer rows.Close()
+
+	mappings := []models.SCIMGroupMapping{}
+	for rows.Next() {
+		var m models.SCIMGroupMapping
+		var role string
+		if err := rows.Scan(&m.GroupName, &role); err != nil {
+			return nil, fmt.Errorf("scanning scim group mapping: %w", err)
+		}
+		m.Role = models.Role(role)
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// SetMappings replaces an org's IdP group to role mappings.
+func (s *SCIMStore) SetMappings(ctx context.Context, orgID string, mappings []models.SCIMGroupMapping) error {
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM scim_group_mappings WHERE org_id = $1`, orgID); err != nil {
+		return fmt.Errorf("clearing scim group mappings: %w", err)
+	}
+	for _, m := range mappings {
+		_, err := s.conn().ExecContext(ctx,
+			`INSERT INTO scim_group_mappings (org_id, group_name, role) VALUES ($1, $2, $3)`,
+			orgID, m.GroupName, string(m.Role))
+		if err != nil {
+			return fmt.Errorf("inserting scim group mapping: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordEvent stores a provisioning event.
+func (s *SCIMStore) RecordEvent(ctx context.Context, event *models.SCIMEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO scim_events (id, org_id, operation, resource_type, resource_id, name, status, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		event.ID, event.OrgID, event.Operation, event.ResourceType, event.ResourceID, event.Name,
+		string(event.Status), event.Message, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting scim event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents retrieves an org's most recent provisioning events.
+func (s *SCIMStore) ListEvents(ctx context.Context, orgID string, limit int) ([]*models.SCIMEvent, error) {
+	q := newSelect(`id, org_id, operation, resource_type, resource_id, name, status, message, created_at`, "scim_events").
+		Where("org_id = ?", orgID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "scim event", q, scanSCIMEvent)
+}
+
+// scanSCIMUser reads a single SCIM user row selected with scimUserColumns.
+func scanSCIMUser(row rowScanner) (*models.SCIMUser, error) {
+	var u models.SCIMUser
+	if err := row.Scan(
+		&u.ID, &u.OrgID, &u.UserID, &u.ExternalID, &u.UserName, &u.DisplayName, &u.Active, &u.Provisioned,
+		&u.CreatedAt, &u.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// scanSCIMGroup reads a single SCIM group row selected with scimGroupColumns.
+func scanSCIMGroup(row rowScanner) (*models.SCIMGroup, error) {
+	var g models.SCIMGroup
+	if err := row.Scan(&g.ID, &g.OrgID, &g.ExternalID, &g.DisplayName, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// scanSCIMEvent reads a single SCIM event row.
+func scanSCIMEvent(row rowScanner) (*models.SCIMEvent, error) {
+	var e models.SCIMEvent
+	var status string
+	if err := row.Scan(
+		&e.ID, &e.OrgID, &e.Operation, &e.ResourceType, &e.ResourceID, &e.Name, &status, &e.Message, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	e.Status = models.SCIMEventStatus(status)
+	return &e, nil
+}
@@ -51,6 +51,7 @@ func runSecretMigrations(db *sql.DB) error {
 	_, _ = db.Exec("DROP TABLE IF EXISTS builds CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS deployments CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS nodes CASCADE")
+	_, _ = db.Exec("DROP TABLE IF EXISTS services CASCADE")
 	_, _ = db.Exec("DROP TABLE IF EXISTS apps CASCADE")
 
 	schema := `
@@ -70,6 +71,18 @@ func runSecretMigrations(db *sql.DB) error {
 			deleted_at TIMESTAMPTZ
 		);
 
+		CREATE TABLE services (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+			name VARCHAR(63) NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			config JSONB NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			CONSTRAINT services_app_name_unique UNIQUE (app_id, name)
+		);
+
 		CREATE TABLE secrets (
 			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
 			app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// ServiceTemplateStore implements store.ServiceTemplateStore using PostgreSQL.
+type ServiceTemplateStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *ServiceTemplateStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// serviceTemplateColumns lists the columns read by scanServiceTemplate.
+const serviceTemplateColumns = `id, app_id, name, description, variables, service, created_at, updated_at`
+
+// Create stores a new service template. Names are unique within an app.
+func (s *ServiceTemplateStore) Create(ctx context.Context, template *models.ServiceTemplate) error {
+	if template.ID == "" {
+		template.ID = uuid.New().String()
+	}
+	now := time.Now()
+	template.CreatedAt, template.UpdatedAt = now, now
+
+	variablesJSON, serviceJSON, err := marshalServiceTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO service_templates (id, app_id, name, description, variables, service, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		template.ID, template.AppID, template.Name, template.Description, variablesJSON, serviceJSON,
+		template.CreatedAt, template.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting service template: %w", ErrDuplicateName)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting service template: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an app's service template by name. It returns nil if the template does not exist.
+func (s *ServiceTemplateStore) Get(ctx context.Context, appID, name string) (*models.ServiceTemplate, error) {
+	query, args := newSelect(serviceTemplateColumns, "service_templates").
+		Where("app_id = ?", appID).Where("name = ?", name).Build()
+
+	template, err := scanServiceTemplate(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying service template: %w", err)
+	}
+	return template, nil
+}
+
+// List retrieves an app's service templates ordered by name.
+func (s *ServiceTemplateStore) List(ctx context.Context, appID string) ([]*models.ServiceTemplate, error) {
+	q := newSelect(serviceTemplateColumns, "service_templates").Where("app_id = ?", appID).OrderBy("name ASC")
+	return listRows(ctx, s.conn(), "service template", q, scanServiceTemplate)
+}
+
+// Update saves a template's description, variables and service.
+func (s *ServiceTemplateStore) Update(ctx context.Context, template *models.ServiceTemplate) error {
+	template.UpdatedAt = time.Now()
+
+	variablesJSON, serviceJSON, err := marshalServiceTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE service_templates SET description = $2, variables = $3, service = $4, updated_at = $5
+		WHERE id = $1
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		template.ID, template.Description, variablesJSON, serviceJSON, template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating service template: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes an app's service template.
+func (s *ServiceTemplateStore) Delete(ctx context.Context, appID, name string) error {
+	result, err := s.conn().ExecContext(ctx,
+		`DELETE FROM service_templates WHERE app_id = $1 AND name = $2`, appID, name,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting service template: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// marshalServiceTemplate encodes a template's variables and service, storing nil variables as empty.
+func marshalServiceTemplate(template *models.ServiceTemplate) ([]byte, []byte, error) {
+	variables := template.Variables
+	if variables == nil {
+		variables = []models.ServiceTemplateVariable{}
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling service template variables: %w", err)
+	}
+	serviceJSON, err := json.Marshal(template.Service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling service template service: %w", err)
+	}
+	return variablesJSON, serviceJSON, nil
+}
+
+// scanServiceTemplate reads a single service template row selected with serviceTemplateColumns.
+func scanServiceTemplate(row rowScanner) (*models.ServiceTemplate, error) {
+	var t models.ServiceTemplate
+	var variablesJSON, serviceJSON []byte
+	if err := row.Scan(
+		&t.ID, &t.AppID, &t.Name, &t.Description, &variablesJSON, &serviceJSON, &t.CreatedAt, &t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variablesJSON, &t.Variables); err != nil {
+		return nil, fmt.Errorf("unmarshaling service template variables: %w", err)
+	}
+	if err := json.Unmarshal(serviceJSON, &t.Service); err != nil {
+		return nil, fmt.Errorf("unmarshaling service template service: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// Services are stored one row per service in the services table. AppStore
+// keeps the models.App shape by aggregating the rows back into App.Services
+// on read (see servicesColumn) and by syncing them on every write, while
+// ServiceStore changes a single row.
+//
+// Each row has its own version. Updating a service checks only that
+// version, so changes to different services of an app do not conflict.
+// Adding or removing a service changes the app's set of services and also
+// increments the app's version.
+
+// servicesColumn selects an app's services as a JSON array in declaration
+// order, matching the layout of the legacy apps.services column, with each
+// service's version.
+const servicesColumn = `COALESCE((SELECT jsonb_agg(sv.config || jsonb_build_object('version', sv.version) ORDER BY sv.position) FROM services sv WHERE sv.app_id = apps.id), '[]'::jsonb)`
+
+// legacyServicesColumn rebuilds the deprecated apps.services column of app $1
+// from its service rows, for rollback compatibility.
+const legacyServicesColumn = `COALESCE((SELECT jsonb_agg(sv.config ORDER BY sv.position) FROM services sv WHERE sv.app_id = $1), '[]'::jsonb)`
+
+// marshalServiceConfig encodes a service's configuration as stored in the
+// services table. The version is kept in its own column.
+func marshalServiceConfig(svc models.ServiceConfig) ([]byte, error) {
+	svc.Version = 0
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling service %s: %w", svc.Name, err)
+	}
+	return data, nil
+}
+
+// syncServices makes the services table match services for the given app:
+// removed services are deleted and the rest are upserted with their position.
+// A service whose config changed is checked against its version when it has
+// one, and its version is incremented; the new versions are set on services.
+func syncServices(ctx context.Context, conn queryable, appID string, services []models.ServiceConfig) error {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+
+	if _, err := conn.ExecContext(ctx,
+		`DELETE FROM services WHERE app_id = $1 AND NOT (name = ANY($2))`,
+		appID, pq.Array(names),
+	); err != nil {
+		return fmt.Errorf("deleting removed services: %w", err)
+	}
+
+	query := `
+		INSERT INTO services (app_id, name, position, config)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (app_id, name) DO UPDATE SET
+			position = EXCLUDED.position,
+			config = EXCLUDED.config,
+			version = services.version + CASE WHEN services.config = EXCLUDED.config THEN 0 ELSE 1 END
+		WHERE services.config = EXCLUDED.config OR $5::int = 0 OR services.version = $5::int
+		RETURNING version`
+
+	for i := range services {
+		configJSON, err := marshalServiceConfig(services[i])
+		if err != nil {
+			return err
+		}
+		err = conn.QueryRowContext(ctx, query, appID, services[i].Name, i, configJSON, services[i].Version).
+			Scan(&services[i].Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrConcurrentModification
+		}
+		if err != nil {
+			return fmt.Errorf("upserting service %s: %w", services[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ServiceStore implements store.ServiceStore using PostgreSQL.
+type ServiceStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+// conn returns the queryable connection (transaction or database).
+func (s *ServiceStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// Get retrieves a service of an application by name.
+func (s *ServiceStore) Get(ctx context.Context, appID, name string) (*models.ServiceConfig, error) {
+	return getService(ctx, s.conn(), appID, name)
+}
+
+// getService reads a service of an app that is not deleted.
+func getService(ctx context.Context, conn queryable, appID, name string) (*models.ServiceConfig, error) {
+	query := `
+		SELECT sv.config, sv.version
+		FROM services sv JOIN apps a ON a.id = sv.app_id
+		WHERE sv.app_id = $1 AND sv.name = $2 AND a.deleted_at IS NULL`
+
+	var configJSON []byte
+	var version int
+	err := conn.QueryRowContext(ctx, query, appID, name).Scan(&configJSON, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying service: %w", err)
+	}
+
+	var svc models.ServiceConfig
+	if err := json.Unmarshal(configJSON, &svc); err != nil {
+		return nil, fmt.Errorf("unmarshaling service: %w", err)
+	}
+	svc.Version = version
+	return &svc, nil
+}
+
+// Create adds a service after the application's existing services and
+// increments the application's version.
+// Returns ErrDuplicateName if the application already has a service with that name.
+func (s *ServiceStore) Create(ctx context.Context, appID string, service *models.ServiceConfig) error {
+	configJSON, err := marshalServiceConfig(*service)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO services (app_id, name, position, config)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM services WHERE app_id = $1), $3)
+		RETURNING version`
+
+	return withConnTx(ctx, s.db, s.tx, s.stmts, s.logger, func(conn queryable) error {
+		// Lock the app first so concurrent creates get distinct positions
+		if err := touchApp(ctx, conn, appID, true); err != nil {
+			return err
+		}
+		err := conn.QueryRowContext(ctx, query, appID, service.Name, configJSON).Scan(&service.Version)
+		if isUniqueViolation(err) {
+			return ErrDuplicateName
+		}
+		if err != nil {
+			return fmt.Errorf("inserting service: %w", err)
+		}
+		return writeLegacyServices(ctx, conn, appID)
+	})
+}
+
+// Update updates a service with optimistic locking on the service's version.
+// Returns ErrConcurrentModification if the version doesn't match.
+func (s *ServiceStore) Update(ctx context.Context, appID string, service *models.ServiceConfig) error {
+	configJSON, err := marshalServiceConfig(*service)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE services
+		SET config = $3, version = version + 1
+		WHERE app_id = $1 AND name = $2 AND version = $4
+		RETURNING version`
+
+	return withConnTx(ctx, s.db, s.tx, s.stmts, s.logger, func(conn queryable) error {
+		var version int
+		err := conn.QueryRowContext(ctx, query, appID, service.Name, configJSON, service.Version).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Distinguish between not found and version mismatch
+			if _, err := getService(ctx, conn, appID, service.Name); err != nil {
+				return err
+			}
+			return ErrConcurrentModification
+		}
+		if err != nil {
+			return fmt.Errorf("updating service: %w", err)
+		}
+
+		if err := touchApp(ctx, conn, appID, false); err != nil {
+			return err
+		}
+		if err := writeLegacyServices(ctx, conn, appID); err != nil {
+			return err
+		}
+		service.Version = version
+		return nil
+	})
+}
+
+// Delete removes a service from an application and increments the
+// application's version.
+func (s *ServiceStore) Delete(ctx context.Context, appID, name string) error {
+	return withConnTx(ctx, s.db, s.tx, s.stmts, s.logger, func(conn queryable) error {
+		if err := touchApp(ctx, conn, appID, true); err != nil {
+			return err
+		}
+
+		result, err := conn.ExecContext(ctx, `DELETE FROM services WHERE app_id = $1 AND name = $2`, appID, name)
+		if err != nil {
+			return fmt.Errorf("deleting service: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("getting rows affected: %w", err)
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return writeLegacyServices(ctx, conn, appID)
+	})
+}
+
+// touchApp sets the updated_at of an app that is not deleted, incrementing
+// its version when bumpVersion is set. Returns ErrNotFound if there is no
+// such app.
+func touchApp(ctx context.Context, conn queryable, appID string, bumpVersion bool) error {
+	query := `
+		UPDATE apps
+		SET updated_at = $2, version = version + CASE WHEN $3 THEN 1 ELSE 0 END
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn.ExecContext(ctx, query, appID, time.Now().UTC(), bumpVersion)
+	if err != nil {
+		return fmt.Errorf("updating app: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// writeLegacyServices rewrites the deprecated apps.services column of an app
+// from its service rows.
+func writeLegacyServices(ctx context.Context, conn queryable, appID string) error {
+	if _, err := conn.ExecContext(ctx, `UPDATE apps SET services = `+legacyServicesColumn+` WHERE id = $1`, appID); err != nil {
+		return fmt.Errorf("updating app services: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// SmokeTestStore implements store.SmokeTestStore using PostgreSQL.
+type SmokeTestStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *SmokeTestStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+const smokeTestRunColumns = `id, deployment_id, app_id, service_name, status, tests, results, error,
+	rollback_deployment_id, created_at, started_at, finished_at`
+
+// Create stores a new run.
+func (s *SmokeTestStore) Create(ctx context.Context, run *models.SmokeTestRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+
+	testsJSON, err := json.Marshal(run.Tests)
+	if err != nil {
+		return fmt.Errorf("marshaling smoke tests: %w", err)
+	}
+	resultsJSON, err := json.Marshal(run.Results)
+	if err != nil {
+		return fmt.Errorf("marshaling smoke test results: %w", err)
+	}
+
+	query := `
+		INSERT INTO smoke_test_runs (id, deployment_id, app_id, service_name, status, tests, results, error,
+			rollback_deployment_id, created_at, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		run.ID, run.DeploymentID, run.AppID, run.ServiceName, run.Status, testsJSON, resultsJSON, run.Error,
+		nullString(run.RollbackDeploymentID), run.CreatedAt, run.StartedAt, run.FinishedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateKey
+		}
+		return fmt.Errorf("creating smoke test run: %w", err)
+	}
+	return nil
+}
+
+// Update saves a run's status, results, error, rollback and times.
+func (s *SmokeTestStore) Update(ctx context.Context, run *models.SmokeTestRun) error {
+	resultsJSON, err := json.Marshal(run.Results)
+	if err != nil {
+		return fmt.Errorf("marshaling smoke test results: %w", err)
+	}
+
+	query := `
+		UPDATE smoke_test_runs
+		SET status = $2, results = $3, error = $4, rollback_deployment_id = $5, started_at = $6, finished_at = $7
+		WHERE id = $1
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		run.ID, run.Status, resultsJSON, run.Error, nullString(run.RollbackDeploymentID), run.StartedAt, run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("updating smoke test run: %w", err)
+	}
+	return nil
+}
+
+// GetByDeployment retrieves the run of a deployment. It returns nil if the
+// deployment was not smoke tested.
+func (s *SmokeTestStore) GetByDeployment(ctx context.Context, deploymentID string) (*models.SmokeTestRun, error) {
+	query, args := newSelect(smokeTestRunColumns, "smoke_test_runs").Where("deployment_id = ?", deploymentID).Build()
+	run, err := scanSmokeTestRun(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying smoke test run: %w", err)
+	}
+	return run, nil
+}
+
+// ListActive retrieves the runs of all deployments that are pending or running.
+func (s *SmokeTestStore) ListActive(ctx context.Context) ([]*models.SmokeTestRun, error) {
+	q := newSelect(smokeTestRunColumns, "smoke_test_runs").
+		Where("status IN (?, ?)", models.SmokeTestPending, models.SmokeTestRunning).
+		OrderBy("created_at")
+	return listRows(ctx, s.conn(), "smoke test run", q, scanSmokeTestRun)
+}
+
+// scanSmokeTestRun reads a single smoke test run row.
+func scanSmokeTestRun(row rowScanner) (*models.SmokeTestRun, error) {
+	var run models.SmokeTestRun
+	var testsJSON, resultsJSON []byte
+	var rollbackID sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(
+		&run.ID, &run.DeploymentID, &run.AppID, &run.ServiceName, &run.Status, &testsJSON, &resultsJSON, &run.Error,
+		&rollbackID, &run.CreatedAt, &startedAt, &finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(testsJSON, &run.Tests); err != nil {
+		return nil, fmt.Errorf("unmarshaling smoke tests: %w", err)
+	}
+	if err := json.Unmarshal(resultsJSON, &run.Results); err != nil {
+		return nil, fmt.Errorf("unmarshaling smoke test results: %w", err)
+	}
+	run.RollbackDeploymentID = rollbackID.String
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// SSHStore implements store.SSHStore using PostgreSQL.
+type SSHStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *SSHStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// sshKeyColumns lists the columns read by scanSSHKey.
+const sshKeyColumns = `id, user_id, name, public_key, fingerprint, key_type, created_at, last_used_at`
+
+// sshSessionColumns lists the columns read by scanSSHSession.
+const sshSessionColumns = `id, user_id, node_id, key_fingerprint, remote_addr, started_at, ended_at, bytes_in, bytes_out, error`
+
+// CreateKey stores a new SSH key. Fingerprints are unique across users.
+func (s *SSHStore) CreateKey(ctx context.Context, key *models.SSHKey) error {
+	if key.ID == "" {
+		key.ID = uuid.New().String()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO ssh_keys (id, user_id, name, public_key, fingerprint, key_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		key.ID, key.UserID, key.Name, key.PublicKey, key.Fingerprint, key.KeyType, key.CreatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("inserting ssh key: %w", ErrDuplicateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting ssh key: %w", err)
+	}
+	return nil
+}
+
+// GetKeyByFingerprint retrieves an SSH key by fingerprint. It returns nil if no key matches.
+func (s *SSHStore) GetKeyByFingerprint(ctx context.Context, fingerprint string) (*models.SSHKey, error) {
+	query, args := newSelect(sshKeyColumns, "ssh_keys").Where("fingerprint = ?", fingerprint).Build()
+
+	key, err := scanSSHKey(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying ssh key: %w", err)
+	}
+	return key, nil
+}
+
+// ListKeys retrieves a user's SSH keys, newest first.
+func (s *SSHStore) ListKeys(ctx context.Context, userID string) ([]*models.SSHKey, error) {
+	q := newSelect(sshKeyColumns, "ssh_keys").Where("user_id = ?", userID).OrderBy("created_at DESC")
+	return listRows(ctx, s.conn(), "ssh key", q, scanSSHKey)
+}
+
+// DeleteKey removes one of a user's SSH keys.
+func (s *SSHStore) DeleteKey(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrNotFound
+	}
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM ssh_keys WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting ssh key: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkKeyUsed records that an SSH key was used.
+func (s *SSHStore) MarkKeyUsed(ctx context.Context, id string) error {
+	if _, err := s.conn().ExecContext(ctx, `UPDATE ssh_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("updating ssh key last use: %w", err)
+	}
+	return nil
+}
+
+// CreateSession records the start of a brokered SSH session.
+func (s *SSHStore) CreateSession(ctx context.Context, session *models.SSHSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	if session.StartedAt.IsZero() {
+		session.StartedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO ssh_sessions (id, user_id, node_id, key_fingerprint, remote_addr, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		session.ID, session.UserID, session.NodeID, session.KeyFingerprint, session.RemoteAddr, session.StartedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting ssh session: %w", err)
+	}
+	return nil
+}
+
+// EndSession records how a session ended and how much data it exchanged.
+func (s *SSHStore) EndSession(ctx context.Context, session *models.SSHSession) error {
+	if session.EndedAt == nil {
+		now := time.Now()
+		session.EndedAt = &now
+	}
+
+	query := `
+		UPDATE ssh_sessions SET ended_at = $2, bytes_in = $3, bytes_out = $4, error = $5
+		WHERE id = $1
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		session.ID, *session.EndedAt, session.BytesIn, session.BytesOut, session.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("updating ssh session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListSessions retrieves sessions, newest first, optionally limited to one node.
+func (s *SSHStore) ListSessions(ctx context.Context, nodeID string, limit int) ([]*models.SSHSession, error) {
+	q := newSelect(sshSessionColumns, "ssh_sessions")
+	if nodeID != "" {
+		q.Where("node_id = ?", nodeID)
+	}
+	q.OrderBy("started_at DESC").Page(limit, 0)
+	return listRows(ctx, s.conn(), "ssh session", q, scanSSHSession)
+}
+
+// scanSSHKey reads a single SSH key row selected with sshKeyColumns.
+func scanSSHKey(row rowScanner) (*models.SSHKey, error) {
+	var k models.SSHKey
+	var lastUsed sql.NullTime
+	if err := row.Scan(
+		&k.ID, &k.UserID, &k.Name, &k.PublicKey, &k.Fingerprint, &k.KeyType, &k.CreatedAt, &lastUsed,
+	); err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+	return &k, nil
+}
+
+// scanSSHSession reads a single SSH session row selected with sshSessionColumns.
+func scanSSHSession(row rowScanner) (*models.SSHSession, error) {
+	var sess models.SSHSession
+	var endedAt sql.NullTime
+	if err := row.Scan(
+		&sess.ID, &sess.UserID, &sess.NodeID, &sess.KeyFingerprint, &sess.RemoteAddr, &sess.StartedAt,
+		&endedAt, &sess.BytesIn, &sess.BytesOut, &sess.Error,
+	); err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		sess.EndedAt = &endedAt.Time
+	}
+	return &sess, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// StatsStore implements store.StatsStore using PostgreSQL.
+type StatsStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *StatsStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// HealthSummary rolls up app, service, node and build states for an organization
+// in a single round trip. Each row is (kind, app_id, state, count); service rows
+// are grouped per app so app health can be derived from them.
+func (s *StatsStore) HealthSummary(ctx context.Context, orgID string) (*models.HealthSummary, error) {
+	query := `
+		WITH org_apps AS (
+			SELECT id FROM apps WHERE org_id = $1 AND deleted_at IS NULL
+		),
+		latest AS (
+			SELECT DISTINCT ON (d.app_id, d.service_name) d.app_id, d.service_name, d.status
+			FROM deployments d
+			INNER JOIN org_apps a ON d.app_id = a.id
+			WHERE d.status <> 'canceled'
+			ORDER BY d.app_id, d.service_name, d.version DESC
+		)
+		SELECT 'service', a.id::text, COALESCE(l.status, ''), COUNT(*)
+		FROM org_apps a
+		INNER JOIN services svc ON svc.app_id = a.id
+		LEFT JOIN latest l ON l.app_id = a.id AND l.service_name = svc.name
+		GROUP BY a.id, l.status
+		UNION ALL
+		SELECT 'app', id::text, '', 0 FROM org_apps
+		UNION ALL
+		SELECT 'node', '', CASE WHEN healthy THEN 'healthy' ELSE 'unhealthy' END, COUNT(*)
+		FROM nodes
+		GROUP BY healthy
+		UNION ALL
+		SELECT 'build', '', b.status, COUNT(*)
+		FROM builds b
+		INNER JOIN org_apps a ON b.app_id = a.id
+		WHERE b.created_at > NOW() - INTERVAL '24 hours' OR b.status IN ('queued', 'running')
+		GROUP BY b.status
+		UNION ALL
+		SELECT 'nonreproducible', '', '', COUNT(*)
+		FROM builds b
+		INNER JOIN org_apps a ON b.app_id = a.id
+		WHERE b.reproducibility = 'mismatch' AND b.finished_at > NOW() - INTERVAL '24 hours'
+	`
+
+	rows, err := s.conn().QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("querying health summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := models.NewHealthSummary()
+	appOrder := []string{}
+	appServices := make(map[string][]models.ServiceState)
+
+	for rows.Next() {
+		var kind, appID, state string
+		var count int
+		if err := rows.Scan(&kind, &appID, &state, &count); err != nil {
+			return nil, fmt.Errorf("scanning health summary: %w", err)
+		}
+
+		switch kind {
+		case "app":
+			appOrder = append(appOrder, appID)
+		case "service":
+			var latest *models.Deployment
+			if state != "" {
+				latest = &models.Deployment{Status: models.DeploymentStatus(state)}
+			}
+			serviceState := models.DeriveServiceState(latest)
+			summary.Services.Add(string(serviceState), count)
+			for i := 0; i < count; i++ {
+				appServices[appID] = append(appServices[appID], serviceState)
+			}
+		case "node":
+			summary.Nodes.Add(state, count)
+		case "build":
+			summary.Builds.Add(state, count)
+		case "nonreproducible":
+			summary.NonReproducibleBuilds = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating health summary: %w", err)
+	}
+
+	for _, appID := range appOrder {
+		summary.Apps.Add(string(models.RollupAppHealth(appServices[appID])), 1)
+	}
+
+	summary.ActiveAlerts = summary.Services.ByState[string(models.ServiceStateFailed)] +
+		summary.Nodes.ByState[models.NodeStateUnhealthy] +
+		summary.NonReproducibleBuilds
+
+	return summary, nil
+}
+
+// DeploymentsByStatus counts the deployments of apps that have not been
+// deleted by status, across all organizations.
+func (s *StatsStore) DeploymentsByStatus(ctx context.Context) (map[models.DeploymentStatus]int, error) {
+	query := `
+		SELECT d.status, COUNT(*)
+		FROM deployments d
+		INNER JOIN apps a ON d.app_id = a.id AND a.deleted_at IS NULL
+		GROUP BY d.status
+	`
+	counts, err := s.countByStatus(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("counting deployments by status: %w", err)
+	}
+	byStatus := make(map[models.DeploymentStatus]int, len(counts))
+	for status, n := range counts {
+		byStatus[models.DeploymentStatus(status)] = n
+	}
+	return byStatus, nil
+}
+
+// BuildQueueDepth counts the jobs in the build queue by queue status.
+func (s *StatsStore) BuildQueueDepth(ctx context.Context) (map[string]int, error) {
+	counts, err := s.countByStatus(ctx, `SELECT status, COUNT(*) FROM build_queue GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("counting build queue jobs: %w", err)
+	}
+	return counts, nil
+}
+
+// countByStatus runs a query returning (status, count) rows.
+func (s *StatsStore) countByStatus(ctx context.Context, query string) (map[string]int, error) {
+	rows, err := s.conn().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
@@ -15,21 +15,67 @@ import (
 type PostgresStore struct {
 	db     *sql.DB
 	logger *slog.Logger
+	stmts  *stmtCache // Prepared statements shared by all sub-stores
 
 	// Sub-stores
-	orgs           *OrgStore
-	apps           *AppStore
-	deployments    *DeploymentStore
-	nodes          *NodeStore
-	builds         *BuildStore
-	secrets        *SecretStore
-	logs           *LogStore
-	users          *UserStore
-	github         *GitHubStore
-	githubAccounts *GitHubAccountStore
-	settings       *SettingsStore
-	domains        *domainStore
-	invitations    *InvitationStore
+	orgs              *OrgStore
+	apps              *AppStore
+	deployments       *DeploymentStore
+	nodes             *NodeStore
+	builds            *BuildStore
+	secrets           *SecretStore
+	logs              *LogStore
+	users             *UserStore
+	github            *GitHubStore
+	githubAccounts    *GitHubAccountStore
+	settings          *SettingsStore
+	domains           *domainStore
+	invitations       *InvitationStore
+	announcements     *AnnouncementStore
+	releases          *ReleaseStore
+	stats             *StatsStore
+	egress            *EgressViolationStore
+	deployFreeze      *DeployFreezeStore
+	onCall            *OnCallStore
+	apiCatalog        *APICatalogStore
+	scim              *SCIMStore
+	apiKeys           *APIKeyStore
+	notifications     *NotificationStore
+	promotions        *PromotionStore
+	ssh               *SSHStore
+	buildWorkers      *BuildWorkerStore
+	templates         *ServiceTemplateStore
+	buildLogs         *BuildLogStore
+	buildSnapshots    *BuildSnapshotStore
+	appHooks          *AppHookStore
+	cdn               *CDNStore
+	attestations      *BuildAttestationStore
+	cronRuns          *CronRunStore
+	metrics           *MetricStore
+	admissionPolicies *AdmissionPolicyStore
+	audit             *AuditStore
+	orgSecrets        *OrgSecretStore
+	nodeJoinTokens    *NodeJoinTokenStore
+	smokeTests        *SmokeTestStore
+	loadTests         *LoadTestStore
+	archives          *ArchiveStore
+	operations        *OperationStore
+	debugSessions     *DebugSessionStore
+	authSessions      *AuthSessionStore
+	rateLimits        *RateLimitStore
+	consistency       *ConsistencyStore
+	dbHealth          *DBHealthStore
+	appManifests      *AppManifestStore
+	idempotencyKeys   *IdempotencyKeyStore
+	scaleEvents       *ScaleEventStore
+	environments      *EnvironmentStore
+	federation        *FederationStore
+	evidenceExports   *EvidenceExportStore
+	apiUsage          *APIUsageStore
+	events            *EventStore
+	webhooks          *WebhookStore
+	appTemplates      *AppTemplateStore
+	services          *ServiceStore
 }
 
 // Config holds PostgreSQL connection configuration.
@@ -39,6 +85,12 @@ type Config struct {
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
+
+	// ReplicaDSN connects to a read replica serving the reads of contexts
+	// marked with store.ReadAfter. ReplicaWait is how long such a read waits
+	// for the replica to catch up before it is served by the primary.
+	ReplicaDSN  string
+	ReplicaWait time.Duration
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -49,6 +101,7 @@ func DefaultConfig(dsn string) *Config {
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
 		ConnMaxIdleTime: 1 * time.Minute,
+		ReplicaWait:     2 * time.Second,
 	}
 }
 
@@ -81,22 +134,77 @@ func NewPostgresStore(cfg *Config, logger *slog.Logger) (*PostgresStore, error)
 	s := &PostgresStore{
 		db:     db,
 		logger: logger,
+		stmts:  newStmtCache(db),
+	}
+	if cfg.ReplicaDSN != "" {
+		r, err := openReplica(cfg, logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		s.stmts.replica = r
+		logger.Info("serving reads from read replica", "wait", cfg.ReplicaWait)
 	}
 
 	// Initialize sub-stores
-	s.orgs = &OrgStore{db: db, logger: logger}
-	s.apps = &AppStore{db: db, logger: logger}
-	s.deployments = &DeploymentStore{db: db, logger: logger}
-	s.nodes = &NodeStore{db: db, logger: logger}
-	s.builds = &BuildStore{db: db, logger: logger}
-	s.secrets = &SecretStore{db: db, logger: logger}
-	s.logs = &LogStore{db: db, logger: logger}
-	s.users = &UserStore{db: db, logger: logger}
-	s.github = &GitHubStore{db: db, logger: logger}
-	s.githubAccounts = &GitHubAccountStore{db: db, logger: logger}
+	s.orgs = &OrgStore{db: db, logger: logger, stmts: s.stmts}
+	s.apps = &AppStore{db: db, logger: logger, stmts: s.stmts}
+	s.deployments = &DeploymentStore{db: db, logger: logger, stmts: s.stmts}
+	s.nodes = &NodeStore{db: db, logger: logger, stmts: s.stmts}
+	s.builds = &BuildStore{db: db, logger: logger, stmts: s.stmts}
+	s.secrets = &SecretStore{db: db, logger: logger, stmts: s.stmts}
+	s.logs = &LogStore{db: db, logger: logger, stmts: s.stmts}
+	s.users = &UserStore{db: db, logger: logger, stmts: s.stmts}
+	s.github = &GitHubStore{db: db, logger: logger, stmts: s.stmts}
+	s.githubAccounts = &GitHubAccountStore{db: db, logger: logger, stmts: s.stmts}
 	s.settings = &SettingsStore{db: db, logger: logger}
 	s.domains = NewDomainStore(db)
-	s.invitations = &InvitationStore{db: db, logger: logger}
+	s.invitations = &InvitationStore{db: db, logger: logger, stmts: s.stmts}
+	s.announcements = &AnnouncementStore{db: db, logger: logger, stmts: s.stmts}
+	s.releases = &ReleaseStore{db: db, logger: logger, stmts: s.stmts}
+	s.stats = &StatsStore{db: db, logger: logger, stmts: s.stmts}
+	s.egress = &EgressViolationStore{db: db, logger: logger, stmts: s.stmts}
+	s.deployFreeze = &DeployFreezeStore{db: db, logger: logger, stmts: s.stmts}
+	s.onCall = &OnCallStore{db: db, logger: logger, stmts: s.stmts}
+	s.apiCatalog = &APICatalogStore{db: db, logger: logger, stmts: s.stmts}
+	s.scim = &SCIMStore{db: db, logger: logger, stmts: s.stmts}
+	s.apiKeys = &APIKeyStore{db: db, logger: logger, stmts: s.stmts}
+	s.notifications = &NotificationStore{db: db, logger: logger, stmts: s.stmts}
+	s.promotions = &PromotionStore{db: db, logger: logger, stmts: s.stmts}
+	s.ssh = &SSHStore{db: db, logger: logger, stmts: s.stmts}
+	s.buildWorkers = &BuildWorkerStore{db: db, logger: logger, stmts: s.stmts}
+	s.templates = &ServiceTemplateStore{db: db, logger: logger, stmts: s.stmts}
+	s.buildLogs = &BuildLogStore{db: db, logger: logger, stmts: s.stmts}
+	s.buildSnapshots = &BuildSnapshotStore{db: db, logger: logger, stmts: s.stmts}
+	s.appHooks = &AppHookStore{db: db, logger: logger, stmts: s.stmts}
+	s.cdn = &CDNStore{db: db, logger: logger, stmts: s.stmts}
+	s.attestations = &BuildAttestationStore{db: db, logger: logger, stmts: s.stmts}
+	s.cronRuns = &CronRunStore{db: db, logger: logger, stmts: s.stmts}
+	s.metrics = &MetricStore{db: db, logger: logger, stmts: s.stmts}
+	s.admissionPolicies = &AdmissionPolicyStore{db: db, logger: logger, stmts: s.stmts}
+	s.audit = &AuditStore{db: db, logger: logger, stmts: s.stmts}
+	s.orgSecrets = &OrgSecretStore{db: db, logger: logger, stmts: s.stmts}
+	s.nodeJoinTokens = &NodeJoinTokenStore{db: db, logger: logger, stmts: s.stmts}
+	s.smokeTests = &SmokeTestStore{db: db, logger: logger, stmts: s.stmts}
+	s.loadTests = &LoadTestStore{db: db, logger: logger, stmts: s.stmts}
+	s.archives = &ArchiveStore{db: db, logger: logger, stmts: s.stmts}
+	s.operations = &OperationStore{db: db, logger: logger, stmts: s.stmts}
+	s.debugSessions = &DebugSessionStore{db: db, logger: logger, stmts: s.stmts}
+	s.authSessions = &AuthSessionStore{db: db, logger: logger, stmts: s.stmts}
+	s.rateLimits = &RateLimitStore{db: db, logger: logger, stmts: s.stmts}
+	s.consistency = &ConsistencyStore{db: db, logger: logger, stmts: s.stmts}
+	s.dbHealth = &DBHealthStore{db: db, logger: logger, stmts: s.stmts}
+	s.appManifests = &AppManifestStore{db: db, logger: logger, stmts: s.stmts}
+	s.idempotencyKeys = &IdempotencyKeyStore{db: db, logger: logger, stmts: s.stmts}
+	s.scaleEvents = &ScaleEventStore{db: db, logger: logger, stmts: s.stmts}
+	s.environments = &EnvironmentStore{db: db, logger: logger, stmts: s.stmts}
+	s.federation = &FederationStore{db: db, logger: logger, stmts: s.stmts}
+	s.evidenceExports = &EvidenceExportStore{db: db, logger: logger, stmts: s.stmts}
+	s.apiUsage = &APIUsageStore{db: db, logger: logger, stmts: s.stmts}
+	s.events = &EventStore{db: db, logger: logger, stmts: s.stmts}
+	s.webhooks = &WebhookStore{db: db, logger: logger, stmts: s.stmts}
+	s.appTemplates = &AppTemplateStore{db: db, logger: logger, stmts: s.stmts}
+	s.services = &ServiceStore{db: db, logger: logger, stmts: s.stmts}
 
 	logger.Info("connected to PostgreSQL database")
 	return s, nil
@@ -167,6 +275,231 @@ func (s *PostgresStore) Invitations() store.InvitationStore {
 	return s.invitations
 }
 
+// Announcements returns the AnnouncementStore.
+func (s *PostgresStore) Announcements() store.AnnouncementStore {
+	return s.announcements
+}
+
+// Releases returns the ReleaseStore.
+func (s *PostgresStore) Releases() store.ReleaseStore {
+	return s.releases
+}
+
+// Stats returns the StatsStore.
+func (s *PostgresStore) Stats() store.StatsStore {
+	return s.stats
+}
+
+// EgressViolations returns the EgressViolationStore.
+func (s *PostgresStore) EgressViolations() store.EgressViolationStore {
+	return s.egress
+}
+
+// DeployFreezes returns the DeployFreezeStore.
+func (s *PostgresStore) DeployFreezes() store.DeployFreezeStore {
+	return s.deployFreeze
+}
+
+// OnCall returns the OnCallStore.
+func (s *PostgresStore) OnCall() store.OnCallStore {
+	return s.onCall
+}
+
+// APICatalog returns the APICatalogStore.
+func (s *PostgresStore) APICatalog() store.APICatalogStore {
+	return s.apiCatalog
+}
+
+// SCIM returns the SCIMStore.
+func (s *PostgresStore) SCIM() store.SCIMStore {
+	return s.scim
+}
+
+// APIKeys returns the APIKeyStore.
+func (s *PostgresStore) APIKeys() store.APIKeyStore {
+	return s.apiKeys
+}
+
+// Notifications returns the NotificationStore.
+func (s *PostgresStore) Notifications() store.NotificationStore {
+	return s.notifications
+}
+
+// Promotions returns the PromotionStore.
+func (s *PostgresStore) Promotions() store.PromotionStore {
+	return s.promotions
+}
+
+// SSH returns the SSHStore.
+func (s *PostgresStore) SSH() store.SSHStore {
+	return s.ssh
+}
+
+// BuildWorkers returns the BuildWorkerStore.
+func (s *PostgresStore) BuildWorkers() store.BuildWorkerStore {
+	return s.buildWorkers
+}
+
+// ServiceTemplates returns the ServiceTemplateStore.
+func (s *PostgresStore) ServiceTemplates() store.ServiceTemplateStore {
+	return s.templates
+}
+
+// BuildLogs returns the BuildLogStore.
+func (s *PostgresStore) BuildLogs() store.BuildLogStore {
+	return s.buildLogs
+}
+
+// BuildSnapshots returns the BuildSnapshotStore.
+func (s *PostgresStore) BuildSnapshots() store.BuildSnapshotStore {
+	return s.buildSnapshots
+}
+
+// AppHooks returns the AppHookStore.
+func (s *PostgresStore) AppHooks() store.AppHookStore {
+	return s.appHooks
+}
+
+// CDN returns the CDNStore.
+func (s *PostgresStore) CDN() store.CDNStore {
+	return s.cdn
+}
+
+// BuildAttestations returns the BuildAttestationStore.
+func (s *PostgresStore) BuildAttestations() store.BuildAttestationStore {
+	return s.attestations
+}
+
+// CronRuns returns the CronRunStore.
+func (s *PostgresStore) CronRuns() store.CronRunStore {
+	return s.cronRuns
+}
+
+// Metrics returns the MetricStore.
+func (s *PostgresStore) Metrics() store.MetricStore {
+	return s.metrics
+}
+
+// AdmissionPolicies returns the AdmissionPolicyStore.
+func (s *PostgresStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	return s.admissionPolicies
+}
+
+// Audit returns the AuditStore.
+func (s *PostgresStore) Audit() store.AuditStore {
+	return s.audit
+}
+
+// OrgSecrets returns the OrgSecretStore.
+func (s *PostgresStore) OrgSecrets() store.OrgSecretStore {
+	return s.orgSecrets
+}
+
+// NodeJoinTokens returns the NodeJoinTokenStore.
+func (s *PostgresStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	return s.nodeJoinTokens
+}
+
+// SmokeTests returns the SmokeTestStore.
+func (s *PostgresStore) SmokeTests() store.SmokeTestStore {
+	return s.smokeTests
+}
+
+// LoadTests returns the LoadTestStore.
+func (s *PostgresStore) LoadTests() store.LoadTestStore {
+	return s.loadTests
+}
+
+// Archives returns the ArchiveStore.
+func (s *PostgresStore) Archives() store.ArchiveStore {
+	return s.archives
+}
+
+// Operations returns the OperationStore.
+func (s *PostgresStore) Operations() store.OperationStore {
+	return s.operations
+}
+
+// DebugSessions returns the DebugSessionStore.
+func (s *PostgresStore) DebugSessions() store.DebugSessionStore {
+	return s.debugSessions
+}
+
+// AuthSessions returns the AuthSessionStore.
+func (s *PostgresStore) AuthSessions() store.AuthSessionStore {
+	return s.authSessions
+}
+
+// RateLimits returns the RateLimitStore.
+func (s *PostgresStore) RateLimits() store.RateLimitStore {
+	return s.rateLimits
+}
+
+// Consistency returns the ConsistencyStore.
+func (s *PostgresStore) Consistency() store.ConsistencyStore {
+	return s.consistency
+}
+
+// DBHealth returns the DBHealthStore.
+func (s *PostgresStore) DBHealth() store.DBHealthStore {
+	return s.dbHealth
+}
+
+// AppManifests returns the AppManifestStore.
+func (s *PostgresStore) AppManifests() store.AppManifestStore {
+	return s.appManifests
+}
+
+// IdempotencyKeys returns the IdempotencyKeyStore.
+func (s *PostgresStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	return s.idempotencyKeys
+}
+
+// ScaleEvents returns the ScaleEventStore.
+func (s *PostgresStore) ScaleEvents() store.ScaleEventStore {
+	return s.scaleEvents
+}
+
+// Environments returns the EnvironmentStore.
+func (s *PostgresStore) Environments() store.EnvironmentStore {
+	return s.environments
+}
+
+// Federation returns the FederationStore.
+func (s *PostgresStore) Federation() store.FederationStore {
+	return s.federation
+}
+
+// EvidenceExports returns the EvidenceExportStore.
+func (s *PostgresStore) EvidenceExports() store.EvidenceExportStore {
+	return s.evidenceExports
+}
+
+// APIUsage returns the APIUsageStore.
+func (s *PostgresStore) APIUsage() store.APIUsageStore {
+	return s.apiUsage
+}
+
+// Events returns the EventStore.
+func (s *PostgresStore) Events() store.EventStore {
+	return s.events
+}
+
+// Webhooks returns the WebhookStore.
+func (s *PostgresStore) Webhooks() store.WebhookStore {
+	return s.webhooks
+}
+
+// AppTemplates returns the AppTemplateStore.
+func (s *PostgresStore) AppTemplates() store.AppTemplateStore {
+	return s.appTemplates
+}
+
+// Services returns the ServiceStore.
+func (s *PostgresStore) Services() store.ServiceStore {
+	return s.services
+}
+
 // WithTx executes the given function within a database transaction.
 func (s *PostgresStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -178,6 +511,7 @@ func (s *PostgresStore) WithTx(ctx context.Context, fn func(store.Store) error)
 	txStore := &txStore{
 		tx:     tx,
 		logger: s.logger,
+		stmts:  s.stmts,
 	}
 
 	// Execute the function
@@ -199,6 +533,14 @@ func (s *PostgresStore) WithTx(ctx context.Context, fn func(store.Store) error)
 // Close closes the database connection.
 func (s *PostgresStore) Close() error {
 	s.logger.Info("closing PostgreSQL connection")
+	if err := s.stmts.Close(); err != nil {
+		s.logger.Warn("failed to close prepared statements", "error", err)
+	}
+	if s.stmts.replica != nil {
+		if err := s.stmts.replica.db.Close(); err != nil {
+			s.logger.Warn("failed to close read replica connection", "error", err)
+		}
+	}
 	return s.db.Close()
 }
 
@@ -217,88 +559,134 @@ func (s *PostgresStore) DB() *sql.DB {
 type txStore struct {
 	tx     *sql.Tx
 	logger *slog.Logger
+	stmts  *stmtCache
 
-	orgs           *OrgStore
-	apps           *AppStore
-	deployments    *DeploymentStore
-	nodes          *NodeStore
-	builds         *BuildStore
-	secrets        *SecretStore
-	logs           *LogStore
-	users          *UserStore
-	github         *GitHubStore
-	githubAccounts *GitHubAccountStore
-	settings       *SettingsStore
-	domains        *domainStore
-	invitations    *InvitationStore
+	orgs              *OrgStore
+	apps              *AppStore
+	deployments       *DeploymentStore
+	nodes             *NodeStore
+	builds            *BuildStore
+	secrets           *SecretStore
+	logs              *LogStore
+	users             *UserStore
+	github            *GitHubStore
+	githubAccounts    *GitHubAccountStore
+	settings          *SettingsStore
+	domains           *domainStore
+	invitations       *InvitationStore
+	announcements     *AnnouncementStore
+	releases          *ReleaseStore
+	stats             *StatsStore
+	egress            *EgressViolationStore
+	deployFreeze      *DeployFreezeStore
+	onCall            *OnCallStore
+	apiCatalog        *APICatalogStore
+	scim              *SCIMStore
+	apiKeys           *APIKeyStore
+	notifications     *NotificationStore
+	promotions        *PromotionStore
+	ssh               *SSHStore
+	buildWorkers      *BuildWorkerStore
+	templates         *ServiceTemplateStore
+	buildLogs         *BuildLogStore
+	buildSnapshots    *BuildSnapshotStore
+	appHooks          *AppHookStore
+	cdn               *CDNStore
+	attestations      *BuildAttestationStore
+	cronRuns          *CronRunStore
+	metrics           *MetricStore
+	admissionPolicies *AdmissionPolicyStore
+	audit             *AuditStore
+	orgSecrets        *OrgSecretStore
+	nodeJoinTokens    *NodeJoinTokenStore
+	smokeTests        *SmokeTestStore
+	loadTests         *LoadTestStore
+	archives          *ArchiveStore
+	operations        *OperationStore
+	debugSessions     *DebugSessionStore
+	authSessions      *AuthSessionStore
+	rateLimits        *RateLimitStore
+	consistency       *ConsistencyStore
+	dbHealth          *DBHealthStore
+	appManifests      *AppManifestStore
+	idempotencyKeys   *IdempotencyKeyStore
+	scaleEvents       *ScaleEventStore
+	environments      *EnvironmentStore
+	federation        *FederationStore
+	evidenceExports   *EvidenceExportStore
+	apiUsage          *APIUsageStore
+	events            *EventStore
+	webhooks          *WebhookStore
+	appTemplates      *AppTemplateStore
+	services          *ServiceStore
 }
 
 func (s *txStore) Orgs() store.OrgStore {
 	if s.orgs == nil {
-		s.orgs = &OrgStore{tx: s.tx, logger: s.logger}
+		s.orgs = &OrgStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.orgs
 }
 
 func (s *txStore) Apps() store.AppStore {
 	if s.apps == nil {
-		s.apps = &AppStore{tx: s.tx, logger: s.logger}
+		s.apps = &AppStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.apps
 }
 
 func (s *txStore) Deployments() store.DeploymentStore {
 	if s.deployments == nil {
-		s.deployments = &DeploymentStore{tx: s.tx, logger: s.logger}
+		s.deployments = &DeploymentStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.deployments
 }
 
 func (s *txStore) Nodes() store.NodeStore {
 	if s.nodes == nil {
-		s.nodes = &NodeStore{tx: s.tx, logger: s.logger}
+		s.nodes = &NodeStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.nodes
 }
 
 func (s *txStore) Builds() store.BuildStore {
 	if s.builds == nil {
-		s.builds = &BuildStore{tx: s.tx, logger: s.logger}
+		s.builds = &BuildStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.builds
 }
 
 func (s *txStore) Secrets() store.SecretStore {
 	if s.secrets == nil {
-		s.secrets = &SecretStore{tx: s.tx, logger: s.logger}
+		s.secrets = &SecretStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.secrets
 }
 
 func (s *txStore) Logs() store.LogStore {
 	if s.logs == nil {
-		s.logs = &LogStore{tx: s.tx, logger: s.logger}
+		s.logs = &LogStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.logs
 }
 
 func (s *txStore) Users() store.UserStore {
 	if s.users == nil {
-		s.users = &UserStore{tx: s.tx, logger: s.logger}
+		s.users = &UserStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.users
 }
 
 func (s *txStore) GitHub() store.GitHubStore {
 	if s.github == nil {
-		s.github = &GitHubStore{tx: s.tx, logger: s.logger}
+		s.github = &GitHubStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.github
 }
 
 func (s *txStore) GitHubAccounts() store.GitHubAccountStore {
 	if s.githubAccounts == nil {
-		s.githubAccounts = &GitHubAccountStore{tx: s.tx, logger: s.logger}
+		s.githubAccounts = &GitHubAccountStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.githubAccounts
 }
@@ -319,11 +707,326 @@ func (s *txStore) Domains() store.DomainStore {
 
 func (s *txStore) Invitations() store.InvitationStore {
 	if s.invitations == nil {
-		s.invitations = &InvitationStore{tx: s.tx, logger: s.logger}
+		s.invitations = &InvitationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
 	}
 	return s.invitations
 }
 
+func (s *txStore) Announcements() store.AnnouncementStore {
+	if s.announcements == nil {
+		s.announcements = &AnnouncementStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.announcements
+}
+
+func (s *txStore) Releases() store.ReleaseStore {
+	if s.releases == nil {
+		s.releases = &ReleaseStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.releases
+}
+
+func (s *txStore) Stats() store.StatsStore {
+	if s.stats == nil {
+		s.stats = &StatsStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.stats
+}
+
+func (s *txStore) EgressViolations() store.EgressViolationStore {
+	if s.egress == nil {
+		s.egress = &EgressViolationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.egress
+}
+
+func (s *txStore) DeployFreezes() store.DeployFreezeStore {
+	if s.deployFreeze == nil {
+		s.deployFreeze = &DeployFreezeStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.deployFreeze
+}
+
+func (s *txStore) OnCall() store.OnCallStore {
+	if s.onCall == nil {
+		s.onCall = &OnCallStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.onCall
+}
+
+func (s *txStore) APICatalog() store.APICatalogStore {
+	if s.apiCatalog == nil {
+		s.apiCatalog = &APICatalogStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.apiCatalog
+}
+
+func (s *txStore) SCIM() store.SCIMStore {
+	if s.scim == nil {
+		s.scim = &SCIMStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.scim
+}
+
+func (s *txStore) APIKeys() store.APIKeyStore {
+	if s.apiKeys == nil {
+		s.apiKeys = &APIKeyStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.apiKeys
+}
+
+func (s *txStore) Notifications() store.NotificationStore {
+	if s.notifications == nil {
+		s.notifications = &NotificationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.notifications
+}
+
+func (s *txStore) Promotions() store.PromotionStore {
+	if s.promotions == nil {
+		s.promotions = &PromotionStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.promotions
+}
+
+func (s *txStore) SSH() store.SSHStore {
+	if s.ssh == nil {
+		s.ssh = &SSHStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.ssh
+}
+
+func (s *txStore) BuildWorkers() store.BuildWorkerStore {
+	if s.buildWorkers == nil {
+		s.buildWorkers = &BuildWorkerStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.buildWorkers
+}
+
+func (s *txStore) ServiceTemplates() store.ServiceTemplateStore {
+	if s.templates == nil {
+		s.templates = &ServiceTemplateStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.templates
+}
+
+func (s *txStore) BuildLogs() store.BuildLogStore {
+	if s.buildLogs == nil {
+		s.buildLogs = &BuildLogStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.buildLogs
+}
+
+func (s *txStore) BuildSnapshots() store.BuildSnapshotStore {
+	if s.buildSnapshots == nil {
+		s.buildSnapshots = &BuildSnapshotStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.buildSnapshots
+}
+
+func (s *txStore) AppHooks() store.AppHookStore {
+	if s.appHooks == nil {
+		s.appHooks = &AppHookStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.appHooks
+}
+
+func (s *txStore) CDN() store.CDNStore {
+	if s.cdn == nil {
+		s.cdn = &CDNStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.cdn
+}
+
+func (s *txStore) BuildAttestations() store.BuildAttestationStore {
+	if s.attestations == nil {
+		s.attestations = &BuildAttestationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.attestations
+}
+
+func (s *txStore) CronRuns() store.CronRunStore {
+	if s.cronRuns == nil {
+		s.cronRuns = &CronRunStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.cronRuns
+}
+
+func (s *txStore) Metrics() store.MetricStore {
+	if s.metrics == nil {
+		s.metrics = &MetricStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.metrics
+}
+
+func (s *txStore) AdmissionPolicies() store.AdmissionPolicyStore {
+	if s.admissionPolicies == nil {
+		s.admissionPolicies = &AdmissionPolicyStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.admissionPolicies
+}
+
+func (s *txStore) Audit() store.AuditStore {
+	if s.audit == nil {
+		s.audit = &AuditStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.audit
+}
+
+func (s *txStore) OrgSecrets() store.OrgSecretStore {
+	if s.orgSecrets == nil {
+		s.orgSecrets = &OrgSecretStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.orgSecrets
+}
+
+func (s *txStore) NodeJoinTokens() store.NodeJoinTokenStore {
+	if s.nodeJoinTokens == nil {
+		s.nodeJoinTokens = &NodeJoinTokenStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.nodeJoinTokens
+}
+
+func (s *txStore) SmokeTests() store.SmokeTestStore {
+	if s.smokeTests == nil {
+		s.smokeTests = &SmokeTestStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.smokeTests
+}
+
+func (s *txStore) LoadTests() store.LoadTestStore {
+	if s.loadTests == nil {
+		s.loadTests = &LoadTestStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.loadTests
+}
+
+func (s *txStore) Archives() store.ArchiveStore {
+	if s.archives == nil {
+		s.archives = &ArchiveStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.archives
+}
+
+func (s *txStore) Operations() store.OperationStore {
+	if s.operations == nil {
+		s.operations = &OperationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.operations
+}
+
+func (s *txStore) DebugSessions() store.DebugSessionStore {
+	if s.debugSessions == nil {
+		s.debugSessions = &DebugSessionStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.debugSessions
+}
+
+func (s *txStore) AuthSessions() store.AuthSessionStore {
+	if s.authSessions == nil {
+		s.authSessions = &AuthSessionStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.authSessions
+}
+
+func (s *txStore) RateLimits() store.RateLimitStore {
+	if s.rateLimits == nil {
+		s.rateLimits = &RateLimitStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.rateLimits
+}
+
+func (s *txStore) Consistency() store.ConsistencyStore {
+	if s.consistency == nil {
+		s.consistency = &ConsistencyStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.consistency
+}
+
+func (s *txStore) DBHealth() store.DBHealthStore {
+	if s.dbHealth == nil {
+		s.dbHealth = &DBHealthStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.dbHealth
+}
+
+func (s *txStore) AppManifests() store.AppManifestStore {
+	if s.appManifests == nil {
+		s.appManifests = &AppManifestStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.appManifests
+}
+
+func (s *txStore) IdempotencyKeys() store.IdempotencyKeyStore {
+	if s.idempotencyKeys == nil {
+		s.idempotencyKeys = &IdempotencyKeyStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.idempotencyKeys
+}
+
+func (s *txStore) ScaleEvents() store.ScaleEventStore {
+	if s.scaleEvents == nil {
+		s.scaleEvents = &ScaleEventStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.scaleEvents
+}
+
+func (s *txStore) Environments() store.EnvironmentStore {
+	if s.environments == nil {
+		s.environments = &EnvironmentStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.environments
+}
+
+func (s *txStore) Federation() store.FederationStore {
+	if s.federation == nil {
+		s.federation = &FederationStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.federation
+}
+
+func (s *txStore) EvidenceExports() store.EvidenceExportStore {
+	if s.evidenceExports == nil {
+		s.evidenceExports = &EvidenceExportStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.evidenceExports
+}
+
+func (s *txStore) APIUsage() store.APIUsageStore {
+	if s.apiUsage == nil {
+		s.apiUsage = &APIUsageStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.apiUsage
+}
+
+func (s *txStore) Events() store.EventStore {
+	if s.events == nil {
+		s.events = &EventStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.events
+}
+
+func (s *txStore) Webhooks() store.WebhookStore {
+	if s.webhooks == nil {
+		s.webhooks = &WebhookStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.webhooks
+}
+
+func (s *txStore) AppTemplates() store.AppTemplateStore {
+	if s.appTemplates == nil {
+		s.appTemplates = &AppTemplateStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.appTemplates
+}
+
+func (s *txStore) Services() store.ServiceStore {
+	if s.services == nil {
+		s.services = &ServiceStore{tx: s.tx, logger: s.logger, stmts: s.stmts}
+	}
+	return s.services
+}
+
 func (s *txStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	// Already in a transaction, just execute the function
 	return fn(s)
@@ -0,0 +1,303 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// WebhookStore implements store.WebhookStore using PostgreSQL.
+type WebhookStore struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger *slog.Logger
+	stmts  *stmtCache
+}
+
+func (s *WebhookStore) conn() queryable {
+	return s.stmts.conn(s.db, s.tx)
+}
+
+// webhookColumns lists the columns read by scanWebhook.
+const webhookColumns = `id, user_id, name, url, enabled, events, COALESCE(app_id::text, ''), secret, last_delivery_at,
+	last_error, created_at, updated_at`
+
+// webhookDeliveryColumns lists the columns read by scanWebhookDelivery.
+const webhookDeliveryColumns = `id, webhook_id, payload, status, attempts, next_attempt_at, response_status,
+	response_body, duration_ms, last_error, COALESCE(redelivery_of::text, ''), created_at, delivered_at`
+
+// Create stores a new webhook.
+func (s *WebhookStore) Create(ctx context.Context, webhook *models.Webhook) error {
+	if webhook.ID == "" {
+		webhook.ID = uuid.New().String()
+	}
+	now := time.Now()
+	webhook.CreatedAt, webhook.UpdatedAt = now, now
+
+	eventsJSON, err := marshalWebhookEvents(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (id, user_id, name, url, enabled, events, app_id, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		webhook.ID, webhook.UserID, webhook.Name, webhook.URL, webhook.Enabled, eventsJSON,
+		nullString(webhook.AppID), webhook.Secret, webhook.CreatedAt, webhook.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting webhook: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a webhook by ID. It returns nil if the webhook does not exist.
+func (s *WebhookStore) Get(ctx context.Context, id string) (*models.Webhook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(webhookColumns, "webhooks").Where("id = ?", id).Build()
+
+	webhook, err := scanWebhook(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListByUser retrieves a user's webhooks, oldest first.
+func (s *WebhookStore) ListByUser(ctx context.Context, userID string) ([]*models.Webhook, error) {
+	q := newSelect(webhookColumns, "webhooks").Where("user_id = ?", userID).OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "webhook", q, scanWebhook)
+}
+
+// ListEnabled retrieves every enabled webhook.
+func (s *WebhookStore) ListEnabled(ctx context.Context) ([]*models.Webhook, error) {
+	q := newSelect(webhookColumns, "webhooks").Where("enabled").OrderBy("created_at ASC")
+	return listRows(ctx, s.conn(), "webhook", q, scanWebhook)
+}
+
+// Update updates a webhook's name, URL, enabled flag, events, app and secret.
+func (s *WebhookStore) Update(ctx context.Context, webhook *models.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+
+	eventsJSON, err := marshalWebhookEvents(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET name = $1, url = $2, enabled = $3, events = $4, app_id = $5, secret = $6, updated_at = $7
+		WHERE id = $8
+	`
+	result, err := s.conn().ExecContext(ctx, query,
+		webhook.Name, webhook.URL, webhook.Enabled, eventsJSON, nullString(webhook.AppID), webhook.Secret,
+		webhook.UpdatedAt, webhook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating webhook: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a webhook and its deliveries.
+func (s *WebhookStore) Delete(ctx context.Context, id string) error {
+	_, err := s.conn().ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	return nil
+}
+
+// RecordResult stores the time and error, if any, of a webhook's latest delivery attempt.
+func (s *WebhookStore) RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	query := `UPDATE webhooks SET last_delivery_at = $1, last_error = $2 WHERE id = $3`
+	if _, err := s.conn().ExecContext(ctx, query, at, errMsg, id); err != nil {
+		return fmt.Errorf("recording webhook result: %w", err)
+	}
+	return nil
+}
+
+// EnqueueDelivery queues a payload for delivery to a webhook.
+func (s *WebhookStore) EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	now := time.Now()
+	delivery.CreatedAt = now
+	if delivery.Status == "" {
+		delivery.Status = models.WebhookDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+
+	payloadJSON, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("marshaling webhook payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, payload, status, attempts, next_attempt_at, redelivery_of, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.conn().ExecContext(ctx, query,
+		delivery.ID, delivery.WebhookID, payloadJSON, string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt, nullString(delivery.RedeliveryOf), delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDelivery retrieves a delivery by ID. It returns nil if the delivery does not exist.
+func (s *WebhookStore) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	query, args := newSelect(webhookDeliveryColumns, "webhook_deliveries").Where("id = ?", id).Build()
+
+	delivery, err := scanWebhookDelivery(s.conn().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt is
+// due and pushes their next attempt back by lease. Uses FOR UPDATE SKIP LOCKED
+// so concurrent API servers claim disjoint sets.
+func (s *WebhookStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+	rows, err := s.conn().QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+func (s *WebhookStore) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, response_status = $4, response_body = $5,
+			duration_ms = $6, last_error = $7, delivered_at = $8
+		WHERE id = $9
+	`
+	_, err := s.conn().ExecContext(ctx, query,
+		string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus,
+		delivery.ResponseBody, delivery.DurationMS, delivery.LastError, delivery.DeliveredAt, delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves a webhook's most recent deliveries, newest first.
+func (s *WebhookStore) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	q := newSelect(webhookDeliveryColumns, "webhook_deliveries").
+		Where("webhook_id = ?", webhookID).
+		OrderBy("created_at DESC").
+		Page(limit, 0)
+	return listRows(ctx, s.conn(), "webhook delivery", q, scanWebhookDelivery)
+}
+
+// marshalWebhookEvents encodes a webhook's events, storing nil as empty.
+func marshalWebhookEvents(webhook *models.Webhook) ([]byte, error) {
+	events := webhook.Events
+	if events == nil {
+		events = []models.WebhookEvent{}
+	}
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling webhook events: %w", err)
+	}
+	return eventsJSON, nil
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var w models.Webhook
+	var eventsJSON []byte
+	var lastDelivery sql.NullTime
+	if err := row.Scan(
+		&w.ID, &w.UserID, &w.Name, &w.URL, &w.Enabled, &eventsJSON, &w.AppID, &w.Secret,
+		&lastDelivery, &w.LastError, &w.CreatedAt, &w.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventsJSON, &w.Events); err != nil {
+		return nil, fmt.Errorf("unmarshaling webhook events: %w", err)
+	}
+	if lastDelivery.Valid {
+		w.LastDeliveryAt = &lastDelivery.Time
+	}
+	return &w, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var status string
+	var payloadJSON []byte
+	var deliveredAt sql.NullTime
+	if err := row.Scan(
+		&d.ID, &d.WebhookID, &payloadJSON, &status, &d.Attempts, &d.NextAttemptAt, &d.ResponseStatus,
+		&d.ResponseBody, &d.DurationMS, &d.LastError, &d.RedeliveryOf, &d.CreatedAt, &deliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Status = models.WebhookDeliveryStatus(status)
+	if err := json.Unmarshal(payloadJSON, &d.Payload); err != nil {
+		return nil, fmt.Errorf("unmarshaling webhook payload: %w", err)
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
@@ -3,6 +3,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/narvanalabs/control-plane/internal/models"
 )
@@ -27,6 +28,9 @@ type OrgStore interface {
 	RemoveMember(ctx context.Context, orgID, userID string) error
 	// IsMember checks if a user is a member of an organization.
 	IsMember(ctx context.Context, orgID, userID string) (bool, error)
+	// GetMemberRole returns a user's role in an organization, or an empty
+	// role if they are not a member.
+	GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error)
 	// GetDefault returns the default organization (first created).
 	GetDefault(ctx context.Context) (*models.Organization, error)
 	// GetDefaultForUser returns the user's default organization.
@@ -35,6 +39,8 @@ type OrgStore interface {
 	Count(ctx context.Context) (int, error)
 	// ListMembers retrieves all members of an organization.
 	ListMembers(ctx context.Context, orgID string) ([]*models.OrgMembership, error)
+	// ListAll retrieves every organization on the instance.
+	ListAll(ctx context.Context) ([]*models.Organization, error)
 }
 
 // Store is the main interface for database operations.
@@ -66,6 +72,97 @@ type Store interface {
 	// Invitations returns the InvitationStore for invitation operations.
 	Invitations() InvitationStore
 
+	// Announcements returns the AnnouncementStore for announcement operations.
+	Announcements() AnnouncementStore
+	// Releases returns the ReleaseStore for deployment release notes.
+	Releases() ReleaseStore
+	// Stats returns the StatsStore for aggregate health queries.
+	Stats() StatsStore
+	// EgressViolations returns the EgressViolationStore for blocked outbound connections.
+	EgressViolations() EgressViolationStore
+	// DeployFreezes returns the DeployFreezeStore for deploy freeze windows and overrides.
+	DeployFreezes() DeployFreezeStore
+	// OnCall returns the OnCallStore for on-call deploy gates and acknowledgements.
+	OnCall() OnCallStore
+	// APICatalog returns the APICatalogStore for the OpenAPI specs of services.
+	APICatalog() APICatalogStore
+	// SCIM returns the SCIMStore for SCIM provisioning state.
+	SCIM() SCIMStore
+	// APIKeys returns the APIKeyStore for API key operations.
+	APIKeys() APIKeyStore
+	// Notifications returns the NotificationStore for notification providers and deliveries.
+	Notifications() NotificationStore
+	// Promotions returns the PromotionStore for promotion policies and their evaluations.
+	Promotions() PromotionStore
+	// SSH returns the SSHStore for user SSH keys and brokered node sessions.
+	SSH() SSHStore
+	// BuildWorkers returns the BuildWorkerStore for the registry of build workers.
+	BuildWorkers() BuildWorkerStore
+	// ServiceTemplates returns the ServiceTemplateStore for parametrized services within apps.
+	ServiceTemplates() ServiceTemplateStore
+	// BuildLogs returns the BuildLogStore for build output persisted while builds run.
+	BuildLogs() BuildLogStore
+	// BuildSnapshots returns the BuildSnapshotStore for failed build environments kept for debugging.
+	BuildSnapshots() BuildSnapshotStore
+	// AppHooks returns the AppHookStore for app lifecycle hooks and their deliveries.
+	AppHooks() AppHookStore
+	// CDN returns the CDNStore for CDN purge integrations and the purge log.
+	CDN() CDNStore
+	// BuildAttestations returns the BuildAttestationStore for signed build provenance.
+	BuildAttestations() BuildAttestationStore
+	// CronRuns returns the CronRunStore for the run history of cron services.
+	CronRuns() CronRunStore
+	// Metrics returns the MetricStore for container resource usage rollups.
+	Metrics() MetricStore
+	// AdmissionPolicies returns the AdmissionPolicyStore for deploy admission policies and their violations.
+	AdmissionPolicies() AdmissionPolicyStore
+	// Audit returns the AuditStore for the audit log of mutating API requests.
+	Audit() AuditStore
+	// OrgSecrets returns the OrgSecretStore for secrets shared across an org's apps.
+	OrgSecrets() OrgSecretStore
+	// NodeJoinTokens returns the NodeJoinTokenStore for node join token operations.
+	NodeJoinTokens() NodeJoinTokenStore
+	// SmokeTests returns the SmokeTestStore for smoke test runs of deployments.
+	SmokeTests() SmokeTestStore
+	// LoadTests returns the LoadTestStore for on-demand load tests of deployments.
+	LoadTests() LoadTestStore
+	// Archives returns the ArchiveStore for deployments moved to cold storage.
+	Archives() ArchiveStore
+	// Operations returns the OperationStore for long-running operations.
+	Operations() OperationStore
+	// DebugSessions returns the DebugSessionStore for debug containers started next to running deployments.
+	DebugSessions() DebugSessionStore
+	// AuthSessions returns the AuthSessionStore for sign-in sessions.
+	AuthSessions() AuthSessionStore
+	// RateLimits returns the RateLimitStore for rate limiting token buckets.
+	RateLimits() RateLimitStore
+	// Consistency returns the ConsistencyStore for read-after-write consistency tokens.
+	Consistency() ConsistencyStore
+	// DBHealth returns the DBHealthStore for database size, bloat and orphan maintenance.
+	DBHealth() DBHealthStore
+	// AppManifests returns the AppManifestStore for the specs last applied to apps.
+	AppManifests() AppManifestStore
+	// IdempotencyKeys returns the IdempotencyKeyStore for the responses of requests sent with idempotency keys.
+	IdempotencyKeys() IdempotencyKeyStore
+	// ScaleEvents returns the ScaleEventStore for the history of services' replica count changes.
+	ScaleEvents() ScaleEventStore
+	// Environments returns the EnvironmentStore for the environments of apps' release pipelines.
+	Environments() EnvironmentStore
+	// Federation returns the FederationStore for the other instances registered as federation peers.
+	Federation() FederationStore
+	// EvidenceExports returns the EvidenceExportStore for evidence packages of apps.
+	EvidenceExports() EvidenceExportStore
+	// APIUsage returns the APIUsageStore for requests made to deprecated API routes and versions.
+	APIUsage() APIUsageStore
+	// Events returns the EventStore for changes of apps, services, deployments and builds, whichever process made them.
+	Events() EventStore
+	// Webhooks returns the WebhookStore for users' webhooks and their deliveries.
+	Webhooks() WebhookStore
+	// AppTemplates returns the AppTemplateStore for app templates added by instance admins.
+	AppTemplates() AppTemplateStore
+	// Services returns the ServiceStore for operations on individual services.
+	Services() ServiceStore
+
 	// WithTx executes the given function within a database transaction.
 	// If the function returns an error, the transaction is rolled back.
 	// Otherwise, the transaction is committed.
@@ -170,12 +267,29 @@ type AppStore interface {
 	// ListByOrg retrieves all applications for a given organization.
 	// Excludes soft-deleted apps.
 	ListByOrg(ctx context.Context, orgID string) ([]*models.App, error)
+	// ListAll retrieves every application on the instance.
+	// Excludes soft-deleted apps.
+	ListAll(ctx context.Context) ([]*models.App, error)
 	// Update updates an existing application.
 	Update(ctx context.Context, app *models.App) error
 	// Delete soft-deletes an application by setting deleted_at.
 	Delete(ctx context.Context, id string) error
 }
 
+// ServiceStore defines operations on a single service of an application.
+// Each service has its own version, so changes to different services of an
+// app do not conflict with each other.
+type ServiceStore interface {
+	// Get retrieves a service of an application by name.
+	Get(ctx context.Context, appID, name string) (*models.ServiceConfig, error)
+	// Create adds a service after the application's existing services.
+	Create(ctx context.Context, appID string, service *models.ServiceConfig) error
+	// Update updates a service, checking its version.
+	Update(ctx context.Context, appID string, service *models.ServiceConfig) error
+	// Delete removes a service from an application.
+	Delete(ctx context.Context, appID, name string) error
+}
+
 // DeploymentStore defines operations for deployment management.
 type DeploymentStore interface {
 	// Create creates a new deployment.
@@ -220,6 +334,33 @@ type NodeStore interface {
 	ListHealthy(ctx context.Context) ([]*models.Node, error)
 	// ListWithClosure retrieves nodes that have a specific store path cached.
 	ListWithClosure(ctx context.Context, storePath string) ([]*models.Node, error)
+	// UpdateStatus moves a node to a lifecycle status.
+	UpdateStatus(ctx context.Context, id string, status models.NodeStatus) error
+	// Delete removes a node. Deployments placed on it are left without a node.
+	Delete(ctx context.Context, id string) error
+	// UpdateHealthScore records a node's health score and load from its last heartbeat.
+	UpdateHealthScore(ctx context.Context, id string, score int, load *models.NodeLoad) error
+	// UpdateClock records a node's clock skew and, if known, when its
+	// agent's certificate expires.
+	UpdateClock(ctx context.Context, id string, skew time.Duration, certificateExpiresAt *time.Time) error
+	// CreateHealthEvent records a node becoming healthy or unhealthy.
+	CreateHealthEvent(ctx context.Context, event *models.NodeHealthEvent) error
+	// ListHealthEvents retrieves the latest health events of a node, or of
+	// all nodes if nodeID is empty, newest first.
+	ListHealthEvents(ctx context.Context, nodeID string, limit int) ([]*models.NodeHealthEvent, error)
+}
+
+// NodeJoinTokenStore defines operations for the tokens new nodes join with.
+type NodeJoinTokenStore interface {
+	// Create stores a new join token by its hash.
+	Create(ctx context.Context, token *models.NodeJoinToken, tokenHash string) error
+	// List retrieves all join tokens, newest first.
+	List(ctx context.Context) ([]*models.NodeJoinToken, error)
+	// Delete revokes a join token.
+	Delete(ctx context.Context, id string) error
+	// Authenticate returns the unexpired join token with a hash and records
+	// its use. It returns nil if no token matches.
+	Authenticate(ctx context.Context, tokenHash string) (*models.NodeJoinToken, error)
 }
 
 // BuildStore defines operations for build job management.
@@ -246,6 +387,15 @@ type BuildStore interface {
 	// Used for startup recovery to resume pending builds.
 	// **Validates: Requirements 15.1**
 	ListQueued(ctx context.Context) ([]*models.BuildJob, error)
+	// FindByContentHash returns the most recent successful build with the
+	// given content hash that recorded an artifact.
+	FindByContentHash(ctx context.Context, contentHash string) (*models.BuildJob, error)
+	// FailureStats counts a service's builds created since the given time,
+	// and its failed builds by failure category, most frequent first.
+	FailureStats(ctx context.Context, appID, serviceName string, since time.Time) (*models.BuildFailureStats, error)
+	// RequestCancel records that a build should be canceled. The worker
+	// running the build stops it; Update leaves the request in place.
+	RequestCancel(ctx context.Context, id string) error
 }
 
 // SecretStore defines operations for secret management.
@@ -266,12 +416,19 @@ type SecretStore interface {
 type LogStore interface {
 	// Create creates a new log entry.
 	Create(ctx context.Context, entry *models.LogEntry) error
+	// CreateBatch inserts many log entries in a single statement.
+	CreateBatch(ctx context.Context, entries []*models.LogEntry) error
 	// List retrieves log entries for a deployment.
 	List(ctx context.Context, deploymentID string, limit int) ([]*models.LogEntry, error)
 	// ListBySource retrieves log entries filtered by source (build/runtime).
 	ListBySource(ctx context.Context, deploymentID, source string, limit int) ([]*models.LogEntry, error)
+	// Search retrieves the log entries of an app matching a filter, newest
+	// first, or oldest first when the filter continues after a cursor.
+	Search(ctx context.Context, filter models.LogFilter) ([]*models.LogEntry, error)
 	// DeleteOlderThan removes log entries older than the specified time.
 	DeleteOlderThan(ctx context.Context, deploymentID string, before int64) error
+	// DeleteByDeployment removes all of a deployment's log entries.
+	DeleteByDeployment(ctx context.Context, deploymentID string) error
 }
 
 // SettingsStore defines operations for global system settings.
@@ -294,6 +451,8 @@ type DomainStore interface {
 	List(ctx context.Context, appID string) ([]*models.Domain, error)
 	// ListAll retrieves all domains across all applications.
 	ListAll(ctx context.Context) ([]*models.Domain, error)
+	// Update changes the service a domain mapping routes to.
+	Update(ctx context.Context, domain *models.Domain) error
 	// Delete removes a domain mapping.
 	Delete(ctx context.Context, id string) error
 	// GetByDomain retrieves a domain mapping by the domain name itself.
@@ -312,8 +471,765 @@ type InvitationStore interface {
 	GetByEmail(ctx context.Context, email string) (*models.Invitation, error)
 	// List retrieves all invitations.
 	List(ctx context.Context) ([]*models.Invitation, error)
+	// ListByOrg retrieves all invitations to join an organization, newest first.
+	ListByOrg(ctx context.Context, orgID string) ([]*models.Invitation, error)
 	// Update updates an invitation.
 	Update(ctx context.Context, invitation *models.Invitation) error
 	// Delete removes an invitation.
 	Delete(ctx context.Context, id string) error
 }
+
+// AnnouncementStore defines operations for admin-managed announcement banners.
+type AnnouncementStore interface {
+	// Create creates a new announcement.
+	Create(ctx context.Context, announcement *models.Announcement) error
+	// Get retrieves an announcement by ID.
+	Get(ctx context.Context, id string) (*models.Announcement, error)
+	// List retrieves all announcements, newest first.
+	List(ctx context.Context) ([]*models.Announcement, error)
+	// ListActive retrieves announcements whose display window contains the given time.
+	ListActive(ctx context.Context, now time.Time) ([]*models.Announcement, error)
+	// Update updates an announcement.
+	Update(ctx context.Context, announcement *models.Announcement) error
+	// Delete removes an announcement.
+	Delete(ctx context.Context, id string) error
+}
+
+// ReleaseStore defines operations for release notes linked to deployments.
+type ReleaseStore interface {
+	// Upsert creates or replaces the release for its deployment.
+	Upsert(ctx context.Context, release *models.Release) error
+	// GetByDeployment retrieves the release for a deployment.
+	GetByDeployment(ctx context.Context, deploymentID string) (*models.Release, error)
+	// ListByApp retrieves all releases for an application, newest first.
+	ListByApp(ctx context.Context, appID string) ([]*models.Release, error)
+}
+
+// EgressViolationStore defines operations for egress policy violations.
+type EgressViolationStore interface {
+	// Record upserts violation counters for a deployment. Counts are
+	// cumulative, so a reported count replaces the stored one.
+	Record(ctx context.Context, violations []*models.EgressViolation) error
+	// ListByService retrieves violations for a service seen since the given
+	// time, most recent first.
+	ListByService(ctx context.Context, appID, serviceName string, since time.Time) ([]*models.EgressViolation, error)
+}
+
+// DeployFreezeStore defines operations for org deploy freeze windows.
+type DeployFreezeStore interface {
+	// CreateWindow creates a new freeze window.
+	CreateWindow(ctx context.Context, window *models.FreezeWindow) error
+	// GetWindow retrieves a freeze window by ID.
+	GetWindow(ctx context.Context, id string) (*models.FreezeWindow, error)
+	// ListWindows retrieves all freeze windows for an organization.
+	ListWindows(ctx context.Context, orgID string) ([]*models.FreezeWindow, error)
+	// DeleteWindow removes a freeze window.
+	DeleteWindow(ctx context.Context, id string) error
+	// RecordOverride stores a deploy that went ahead during an active freeze window.
+	RecordOverride(ctx context.Context, override *models.FreezeOverride) error
+	// ListOverrides retrieves the most recent freeze overrides for an organization.
+	ListOverrides(ctx context.Context, orgID string, limit int) ([]*models.FreezeOverride, error)
+}
+
+// OnCallStore defines operations for org on-call deploy gates and the
+// acknowledgements the people on call give.
+type OnCallStore interface {
+	// CreateGate stores a new gate.
+	CreateGate(ctx context.Context, gate *models.OnCallGate) error
+	// GetGate retrieves a gate by ID. It returns nil if the gate does not exist.
+	GetGate(ctx context.Context, id string) (*models.OnCallGate, error)
+	// ListGates retrieves all of an org's gates, oldest first.
+	ListGates(ctx context.Context, orgID string) ([]*models.OnCallGate, error)
+	// UpdateGate saves a gate's settings.
+	UpdateGate(ctx context.Context, gate *models.OnCallGate) error
+	// DeleteGate removes a gate and its acknowledgements.
+	DeleteGate(ctx context.Context, id string) error
+	// CreateAck stores a new acknowledgement request.
+	CreateAck(ctx context.Context, ack *models.OnCallAck) error
+	// GetAck retrieves an acknowledgement by ID. It returns nil if it does not exist.
+	GetAck(ctx context.Context, id string) (*models.OnCallAck, error)
+	// ListAcks retrieves an org's most recent acknowledgement requests, newest first.
+	ListAcks(ctx context.Context, orgID string, limit int) ([]*models.OnCallAck, error)
+	// DecideAck saves the answer to an acknowledgement request: its status,
+	// who decided and when, and its new expiry.
+	DecideAck(ctx context.Context, ack *models.OnCallAck) error
+}
+
+// APICatalogStore defines operations for the OpenAPI specs services publish,
+// which make up the API catalog of each org.
+type APICatalogStore interface {
+	// Upsert stores the spec last fetched for a service, replacing the
+	// previous one.
+	Upsert(ctx context.Context, spec *models.ServiceAPISpec) error
+	// Get retrieves a service's spec. It returns nil if none was fetched.
+	Get(ctx context.Context, appID, serviceName string) (*models.ServiceAPISpec, error)
+	// ListByApp retrieves the specs of an app's services, without the specs
+	// themselves, ordered by service name.
+	ListByApp(ctx context.Context, appID string) ([]*models.ServiceAPISpec, error)
+	// ListByOrg retrieves the catalog of an org, without the specs
+	// themselves, ordered by app and service name.
+	ListByOrg(ctx context.Context, orgID string) ([]*models.CatalogEntry, error)
+	// Delete removes a service's spec.
+	Delete(ctx context.Context, appID, serviceName string) error
+}
+
+// DebugSessionStore defines operations for debug containers started next to
+// running deployments.
+type DebugSessionStore interface {
+	// Create stores a new session.
+	Create(ctx context.Context, session *models.DebugSession) error
+	// Update saves a session's status, error and times.
+	Update(ctx context.Context, session *models.DebugSession) error
+	// Get retrieves a session by ID. It returns nil if it does not exist.
+	Get(ctx context.Context, id string) (*models.DebugSession, error)
+	// ListByService retrieves a service's sessions, newest first.
+	ListByService(ctx context.Context, appID, serviceName string, limit int) ([]*models.DebugSession, error)
+	// ListActive retrieves the sessions that are pending, running or
+	// terminating, oldest first.
+	ListActive(ctx context.Context) ([]*models.DebugSession, error)
+}
+
+// AdmissionPolicyStore defines operations for org admission policies and the
+// violations they record.
+type AdmissionPolicyStore interface {
+	// Create stores a new policy. Names are unique within an org.
+	Create(ctx context.Context, policy *models.AdmissionPolicy) error
+	// Get retrieves a policy by ID. It returns nil if the policy does not exist.
+	Get(ctx context.Context, id string) (*models.AdmissionPolicy, error)
+	// List retrieves all of an org's policies, ordered by name.
+	List(ctx context.Context, orgID string) ([]*models.AdmissionPolicy, error)
+	// Update saves a policy's settings.
+	Update(ctx context.Context, policy *models.AdmissionPolicy) error
+	// Delete removes a policy. Its recorded violations are kept.
+	Delete(ctx context.Context, id string) error
+	// RecordViolations stores policy violations of a deploy.
+	RecordViolations(ctx context.Context, violations []*models.AdmissionViolation) error
+	// ListViolations retrieves an org's most recent violations, newest first,
+	// optionally limited to one app.
+	ListViolations(ctx context.Context, orgID, appID string, limit int) ([]*models.AdmissionViolation, error)
+}
+
+// SCIMStore defines operations for SCIM provisioning tokens, users, groups,
+// group role mappings and the sync event log.
+type SCIMStore interface {
+	// GetToken retrieves an org's SCIM token metadata, or nil if SCIM is disabled.
+	GetToken(ctx context.Context, orgID string) (*models.SCIMToken, error)
+	// SetToken creates or replaces an org's SCIM token.
+	SetToken(ctx context.Context, token *models.SCIMToken, tokenHash string) error
+	// DeleteToken removes an org's SCIM token, disabling provisioning.
+	DeleteToken(ctx context.Context, orgID string) error
+	// AuthenticateToken returns the org ID for a token hash and records its use.
+	// It returns an empty string if no token matches.
+	AuthenticateToken(ctx context.Context, tokenHash string) (string, error)
+
+	// CreateUser stores a new SCIM user link.
+	CreateUser(ctx context.Context, user *models.SCIMUser) error
+	// GetUser retrieves a SCIM user by ID within an org.
+	GetUser(ctx context.Context, orgID, id string) (*models.SCIMUser, error)
+	// ListUsers retrieves all SCIM users for an org, oldest first.
+	ListUsers(ctx context.Context, orgID string) ([]*models.SCIMUser, error)
+	// UpdateUser updates a SCIM user's attributes.
+	UpdateUser(ctx context.Context, user *models.SCIMUser) error
+	// DeleteUser removes a SCIM user link and its group memberships.
+	DeleteUser(ctx context.Context, orgID, id string) error
+
+	// CreateGroup stores a new SCIM group with its members.
+	CreateGroup(ctx context.Context, group *models.SCIMGroup) error
+	// GetGroup retrieves a SCIM group by ID within an org.
+	GetGroup(ctx context.Context, orgID, id string) (*models.SCIMGroup, error)
+	// ListGroups retrieves all SCIM groups for an org with their members.
+	ListGroups(ctx context.Context, orgID string) ([]*models.SCIMGroup, error)
+	// UpdateGroup updates a SCIM group's attributes and replaces its members.
+	UpdateGroup(ctx context.Context, group *models.SCIMGroup) error
+	// DeleteGroup removes a SCIM group.
+	DeleteGroup(ctx context.Context, orgID, id string) error
+
+	// ListMappings retrieves an org's IdP group to role mappings.
+	ListMappings(ctx context.Context, orgID string) ([]models.SCIMGroupMapping, error)
+	// SetMappings replaces an org's IdP group to role mappings.
+	SetMappings(ctx context.Context, orgID string, mappings []models.SCIMGroupMapping) error
+
+	// RecordEvent stores a provisioning event.
+	RecordEvent(ctx context.Context, event *models.SCIMEvent) error
+	// ListEvents retrieves an org's most recent provisioning events.
+	ListEvents(ctx context.Context, orgID string, limit int) ([]*models.SCIMEvent, error)
+}
+
+// StatsStore defines aggregate queries used for dashboards.
+type StatsStore interface {
+	// HealthSummary rolls up app, service, node and build states for an organization.
+	HealthSummary(ctx context.Context, orgID string) (*models.HealthSummary, error)
+	// DeploymentsByStatus counts the deployments of every organization by status.
+	DeploymentsByStatus(ctx context.Context) (map[models.DeploymentStatus]int, error)
+	// BuildQueueDepth counts the jobs in the build queue by queue status.
+	BuildQueueDepth(ctx context.Context) (map[string]int, error)
+}
+
+// APIKeyStore defines operations for API key management.
+type APIKeyStore interface {
+	// GetByHash retrieves an API key by its hash. It returns nil if no key matches.
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// Create creates a new API key.
+	Create(ctx context.Context, key *models.APIKey) error
+	// Delete removes an API key.
+	Delete(ctx context.Context, id string) error
+	// ListByUser retrieves all API keys for a user, newest first.
+	ListByUser(ctx context.Context, userID string) ([]*models.APIKey, error)
+	// MarkUsed records that an API key was used.
+	MarkUsed(ctx context.Context, id string) error
+}
+
+// AuthSessionStore defines operations for users' sign-in sessions.
+type AuthSessionStore interface {
+	// Create stores a new session.
+	Create(ctx context.Context, session *models.AuthSession) error
+	// Get retrieves a session by ID. It returns nil if it does not exist.
+	Get(ctx context.Context, id string) (*models.AuthSession, error)
+	// GetByRefreshHash retrieves the session holding a refresh token by the
+	// token's hash. It returns nil if no session matches.
+	GetByRefreshHash(ctx context.Context, hash string) (*models.AuthSession, error)
+	// Rotate replaces an unrevoked session's refresh token hash if it still
+	// is oldHash, and records its use. It returns false if another refresh
+	// rotated it first or the session was revoked.
+	Rotate(ctx context.Context, id, oldHash, newHash string, usedAt, expiresAt time.Time) (bool, error)
+	// ListActiveByUser retrieves a user's unrevoked and unexpired sessions,
+	// most recently used first.
+	ListActiveByUser(ctx context.Context, userID string) ([]*models.AuthSession, error)
+	// Revoke revokes a session. Revoking a revoked session changes nothing.
+	Revoke(ctx context.Context, id string) error
+	// RevokeAllByUser revokes every session of a user but exceptID, and
+	// returns how many it revoked.
+	RevokeAllByUser(ctx context.Context, userID, exceptID string) (int64, error)
+}
+
+// RateLimitStore defines operations for rate limiting token buckets shared
+// by every API server.
+type RateLimitStore interface {
+	// Take refills a bucket holding up to burst tokens at rate tokens per
+	// second and removes a token if it holds one. It returns the tokens left
+	// and whether a token was removed. Missing buckets start full.
+	Take(ctx context.Context, key string, rate float64, burst int) (float64, bool, error)
+	// Peek returns the tokens a bucket would hold after refilling, without
+	// changing it.
+	Peek(ctx context.Context, key string, rate float64, burst int) (float64, error)
+	// DeleteIdle removes buckets not used since before, which are full again.
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ConsistencyStore issues consistency tokens, which let reads served by a
+// read replica never see the state from before a write.
+type ConsistencyStore interface {
+	// Token returns a token for the writes committed so far, or an empty
+	// token when reads are not served by replicas and are always consistent.
+	Token(ctx context.Context) (string, error)
+}
+
+// AppManifestStore defines operations for the app specs last applied to
+// apps, against which drift is detected.
+type AppManifestStore interface {
+	// Get retrieves an app's manifest. It returns nil if no spec has been applied to the app.
+	Get(ctx context.Context, appID string) (*models.AppManifest, error)
+	// List retrieves the manifests of apps that are not deleted.
+	List(ctx context.Context) ([]*models.AppManifest, error)
+	// Save creates or replaces an app's manifest, clearing its drift.
+	Save(ctx context.Context, manifest *models.AppManifest) error
+	// UpdateDrift records the drift of an app from its manifest, checked at checkedAt.
+	UpdateDrift(ctx context.Context, appID string, drift []models.AppSpecChange, checkedAt time.Time) error
+}
+
+// IdempotencyKeyStore defines operations for the responses of requests sent
+// with idempotency keys.
+type IdempotencyKeyStore interface {
+	// Begin records a request in progress under its user's key. If the user
+	// already used the key, nothing is recorded and the earlier request is
+	// returned; otherwise it returns nil.
+	Begin(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	// Complete records the response to a request in progress.
+	Complete(ctx context.Context, userID, key string, status int, headers map[string]string, response []byte) error
+	// Delete forgets a user's key so the request can be sent again.
+	Delete(ctx context.Context, userID, key string) error
+	// DeleteBefore forgets the keys used before a time, returning how many.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DBHealthStore inspects the database itself and removes rows nothing refers
+// to any more.
+type DBHealthStore interface {
+	// DatabaseSize returns the size of the database in bytes.
+	DatabaseSize(ctx context.Context) (int64, error)
+	// TableStats returns the size, row counts and last vacuum of every table,
+	// largest first.
+	TableStats(ctx context.Context) ([]*models.TableStats, error)
+	// RecordSizes stores a snapshot of the sizes of tables taken at at.
+	RecordSizes(ctx context.Context, tables []*models.TableStats, at time.Time) error
+	// SizesSince returns the size snapshots taken since since, oldest first.
+	SizesSince(ctx context.Context, since time.Time) ([]*models.TableSizeSample, error)
+	// DeleteSizesBefore removes size snapshots taken before before.
+	DeleteSizesBefore(ctx context.Context, before time.Time) (int64, error)
+	// CountOrphans counts the orphaned queue jobs and log rows created before
+	// before.
+	CountOrphans(ctx context.Context, before time.Time) (*models.DBOrphans, error)
+	// DeleteOrphanedQueueJobs removes build queue jobs created before before
+	// whose build is missing or finished before before.
+	DeleteOrphanedQueueJobs(ctx context.Context, before time.Time) (int64, error)
+	// DeleteOrphanedLogs removes up to limit log rows written before before
+	// whose deployment is missing or belongs to a deleted app.
+	DeleteOrphanedLogs(ctx context.Context, before time.Time, limit int) (int64, error)
+	// Vacuum runs VACUUM (ANALYZE) on a table. It fails in a transaction.
+	Vacuum(ctx context.Context, table string) error
+}
+
+// NotificationStore defines operations for notification providers and their
+// delivery queue.
+type NotificationStore interface {
+	// CreateProvider stores a new notification provider.
+	CreateProvider(ctx context.Context, provider *models.NotificationProvider) error
+	// GetProvider retrieves a notification provider by ID. It returns nil if the provider does not exist.
+	GetProvider(ctx context.Context, id string) (*models.NotificationProvider, error)
+	// ListProviders retrieves all notification providers, oldest first.
+	ListProviders(ctx context.Context) ([]*models.NotificationProvider, error)
+	// UpdateProvider updates a provider's name, enabled flag, config and events.
+	UpdateProvider(ctx context.Context, provider *models.NotificationProvider) error
+	// DeleteProvider removes a provider and its queued deliveries.
+	DeleteProvider(ctx context.Context, id string) error
+	// RecordProviderResult stores the time and error, if any, of a provider's latest delivery attempt.
+	RecordProviderResult(ctx context.Context, id string, at time.Time, errMsg string) error
+
+	// EnqueueDelivery queues an event for delivery to a provider.
+	EnqueueDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+	// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt
+	// is due and pushes their next attempt back by lease, so other workers skip them.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationDelivery, error)
+	// UpdateDelivery records the outcome of a delivery attempt.
+	UpdateDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+}
+
+// PromotionStore defines operations for promotion policies and the
+// evaluations of deployments against them.
+type PromotionStore interface {
+	// CreatePolicy stores a new promotion policy.
+	CreatePolicy(ctx context.Context, policy *models.PromotionPolicy) error
+	// GetPolicy retrieves a promotion policy by ID. It returns nil if the policy does not exist.
+	GetPolicy(ctx context.Context, id string) (*models.PromotionPolicy, error)
+	// ListPolicies retrieves the promotion policies whose source is the given app.
+	ListPolicies(ctx context.Context, appID string) ([]*models.PromotionPolicy, error)
+	// ListEnabledPolicies retrieves every enabled promotion policy.
+	ListEnabledPolicies(ctx context.Context) ([]*models.PromotionPolicy, error)
+	// UpdatePolicy updates a policy's target, criteria and flags.
+	UpdatePolicy(ctx context.Context, policy *models.PromotionPolicy) error
+	// DeletePolicy removes a policy and its promotion history.
+	DeletePolicy(ctx context.Context, id string) error
+
+	// Create stores a new promotion evaluation.
+	Create(ctx context.Context, promotion *models.Promotion) error
+	// Get retrieves a promotion by ID. It returns nil if the promotion does not exist.
+	Get(ctx context.Context, id string) (*models.Promotion, error)
+	// GetBySource retrieves the promotion of a source deployment under a policy,
+	// or nil if the deployment has not been evaluated.
+	GetBySource(ctx context.Context, policyID, sourceDeploymentID string) (*models.Promotion, error)
+	// ListOpen retrieves promotions that are evaluating, awaiting approval or blocked.
+	ListOpen(ctx context.Context) ([]*models.Promotion, error)
+	// ListByApp retrieves the promotions of policies whose source is the given app, newest first.
+	ListByApp(ctx context.Context, appID string, limit int) ([]*models.Promotion, error)
+	// ListByDeployment retrieves the promotions a deployment was the source or target of.
+	ListByDeployment(ctx context.Context, deploymentID string) ([]*models.Promotion, error)
+	// Update records a promotion's status, observations and outcome.
+	Update(ctx context.Context, promotion *models.Promotion) error
+}
+
+// SSHStore defines operations for user SSH keys and the audit trail of SSH
+// sessions brokered to nodes.
+type SSHStore interface {
+	// CreateKey stores a new SSH key. Fingerprints are unique across users.
+	CreateKey(ctx context.Context, key *models.SSHKey) error
+	// GetKeyByFingerprint retrieves an SSH key by fingerprint. It returns nil if no key matches.
+	GetKeyByFingerprint(ctx context.Context, fingerprint string) (*models.SSHKey, error)
+	// ListKeys retrieves a user's SSH keys, newest first.
+	ListKeys(ctx context.Context, userID string) ([]*models.SSHKey, error)
+	// DeleteKey removes one of a user's SSH keys.
+	DeleteKey(ctx context.Context, userID, id string) error
+	// MarkKeyUsed records that an SSH key was used.
+	MarkKeyUsed(ctx context.Context, id string) error
+
+	// CreateSession records the start of a brokered SSH session.
+	CreateSession(ctx context.Context, session *models.SSHSession) error
+	// EndSession records how a session ended and how much data it exchanged.
+	EndSession(ctx context.Context, session *models.SSHSession) error
+	// ListSessions retrieves sessions, newest first, optionally limited to one node.
+	ListSessions(ctx context.Context, nodeID string, limit int) ([]*models.SSHSession, error)
+}
+
+// BuildWorkerStore defines operations for the registry of build workers.
+type BuildWorkerStore interface {
+	// Register records a starting worker, replacing any earlier registration with the same ID.
+	Register(ctx context.Context, worker *models.BuildWorker) error
+	// Heartbeat records that a worker is alive and how many jobs it is running.
+	Heartbeat(ctx context.Context, id string, activeJobs int) error
+	// MarkStopped records that a worker shut down cleanly.
+	MarkStopped(ctx context.Context, id string) error
+	// List retrieves all registered workers with the queue jobs they hold, newest first.
+	List(ctx context.Context) ([]*models.BuildWorker, error)
+	// DeleteStale removes workers whose last heartbeat is before the given time.
+	DeleteStale(ctx context.Context, before time.Time) (int, error)
+}
+
+// BuildLogStore defines operations for incrementally persisted build output.
+type BuildLogStore interface {
+	// Append stores the next chunk of a build's output and sets its Seq and CreatedAt.
+	Append(ctx context.Context, chunk *models.BuildLogChunk) error
+	// List retrieves a build's chunks with a Seq greater than afterSeq, oldest
+	// first. A limit of zero or less means no limit.
+	List(ctx context.Context, buildID string, afterSeq, limit int) ([]*models.BuildLogChunk, error)
+	// Restore stores archived chunks with their original Seq and CreatedAt.
+	Restore(ctx context.Context, chunks []*models.BuildLogChunk) error
+	// DeleteByBuild removes all of a build's chunks.
+	DeleteByBuild(ctx context.Context, buildID string) error
+}
+
+// BuildSnapshotStore defines operations for failed build environments kept
+// on worker hosts for debugging.
+type BuildSnapshotStore interface {
+	// Save records a build's snapshot, replacing any earlier one of the build.
+	Save(ctx context.Context, snapshot *models.BuildSnapshot) error
+	// Get retrieves a build's snapshot. It returns nil if the build has none.
+	Get(ctx context.Context, buildID string) (*models.BuildSnapshot, error)
+	// ListExpired retrieves the snapshots kept on a host that expired before the given time.
+	ListExpired(ctx context.Context, hostname string, before time.Time) ([]*models.BuildSnapshot, error)
+	// Delete removes a build's snapshot record.
+	Delete(ctx context.Context, buildID string) error
+}
+
+// AppHookStore defines operations for app lifecycle hooks and their
+// delivery queue.
+type AppHookStore interface {
+	// Create stores a new hook.
+	Create(ctx context.Context, hook *models.AppHook) error
+	// Get retrieves a hook by ID. It returns nil if the hook does not exist.
+	Get(ctx context.Context, id string) (*models.AppHook, error)
+	// ListByApp retrieves an app's hooks, oldest first.
+	ListByApp(ctx context.Context, appID string) ([]*models.AppHook, error)
+	// Update updates a hook's name, enabled flag, events and target.
+	Update(ctx context.Context, hook *models.AppHook) error
+	// Delete removes a hook and its queued deliveries.
+	Delete(ctx context.Context, id string) error
+	// RecordResult stores the time and error, if any, of a hook's latest delivery attempt.
+	RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error
+
+	// EnqueueDelivery queues a payload for delivery to a hook.
+	EnqueueDelivery(ctx context.Context, delivery *models.AppHookDelivery) error
+	// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt
+	// is due and pushes their next attempt back by lease, so other workers skip them.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.AppHookDelivery, error)
+	// UpdateDelivery records the outcome of a delivery attempt.
+	UpdateDelivery(ctx context.Context, delivery *models.AppHookDelivery) error
+	// ListDeliveries retrieves a hook's most recent deliveries, newest first.
+	ListDeliveries(ctx context.Context, hookID string, limit int) ([]*models.AppHookDelivery, error)
+}
+
+// WebhookStore defines operations for users' webhooks and the log of their
+// deliveries.
+type WebhookStore interface {
+	// Create stores a new webhook.
+	Create(ctx context.Context, webhook *models.Webhook) error
+	// Get retrieves a webhook by ID. It returns nil if the webhook does not exist.
+	Get(ctx context.Context, id string) (*models.Webhook, error)
+	// ListByUser retrieves a user's webhooks, oldest first.
+	ListByUser(ctx context.Context, userID string) ([]*models.Webhook, error)
+	// ListEnabled retrieves every enabled webhook.
+	ListEnabled(ctx context.Context) ([]*models.Webhook, error)
+	// Update updates a webhook's name, URL, enabled flag, events, app and secret.
+	Update(ctx context.Context, webhook *models.Webhook) error
+	// Delete removes a webhook and its deliveries.
+	Delete(ctx context.Context, id string) error
+	// RecordResult stores the time and error, if any, of a webhook's latest delivery attempt.
+	RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error
+
+	// EnqueueDelivery queues a payload for delivery to a webhook.
+	EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// GetDelivery retrieves a delivery by ID. It returns nil if the delivery does not exist.
+	GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	// ClaimDueDeliveries locks up to limit pending deliveries whose next attempt
+	// is due and pushes their next attempt back by lease, so other workers skip them.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	// UpdateDelivery records the outcome of a delivery attempt.
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// ListDeliveries retrieves a webhook's most recent deliveries, newest first.
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error)
+}
+
+// CDNStore defines operations for apps' CDN integrations and the log of
+// their purges.
+type CDNStore interface {
+	// CreateIntegration stores a new CDN integration.
+	CreateIntegration(ctx context.Context, integration *models.CDNIntegration) error
+	// GetIntegration retrieves an integration by ID. It returns nil if the integration does not exist.
+	GetIntegration(ctx context.Context, id string) (*models.CDNIntegration, error)
+	// ListIntegrations retrieves an app's integrations, oldest first.
+	ListIntegrations(ctx context.Context, appID string) ([]*models.CDNIntegration, error)
+	// UpdateIntegration updates an integration's name, enabled flag, config and services.
+	UpdateIntegration(ctx context.Context, integration *models.CDNIntegration) error
+	// DeleteIntegration removes an integration and its purge log.
+	DeleteIntegration(ctx context.Context, id string) error
+
+	// RecordPurge logs a purge and its result.
+	RecordPurge(ctx context.Context, purge *models.CDNPurge) error
+	// ListPurges retrieves an app's most recent purges, newest first.
+	ListPurges(ctx context.Context, appID string, limit int) ([]*models.CDNPurge, error)
+}
+
+// FederationStore defines operations for the other instances registered as
+// federation peers.
+type FederationStore interface {
+	// CreatePeer registers a new peer.
+	CreatePeer(ctx context.Context, peer *models.FederationPeer) error
+	// GetPeer retrieves a peer by ID. It returns nil if the peer does not exist.
+	GetPeer(ctx context.Context, id string) (*models.FederationPeer, error)
+	// ListPeers retrieves every peer, ordered by name.
+	ListPeers(ctx context.Context) ([]*models.FederationPeer, error)
+	// DeletePeer removes a peer.
+	DeletePeer(ctx context.Context, id string) error
+}
+
+// EvidenceExportStore defines operations for the evidence packages compiled
+// for compliance audits of apps.
+type EvidenceExportStore interface {
+	// Create stores a new export with its archive.
+	Create(ctx context.Context, export *models.EvidenceExport, archive []byte) error
+	// Get retrieves an export by ID, without its archive. It returns nil if
+	// the export does not exist.
+	Get(ctx context.Context, id string) (*models.EvidenceExport, error)
+	// GetArchive retrieves the archive of an export. It returns nil if the
+	// export does not exist.
+	GetArchive(ctx context.Context, id string) ([]byte, error)
+	// ListByApp retrieves an app's exports, newest first, without their archives.
+	ListByApp(ctx context.Context, appID string) ([]*models.EvidenceExport, error)
+	// Delete removes an export.
+	Delete(ctx context.Context, id string) error
+}
+
+// APIUsageStore defines operations for the requests clients make to
+// deprecated API routes and versions.
+type APIUsageStore interface {
+	// Record adds usage counted since the last call to the totals of each
+	// route, version and client.
+	Record(ctx context.Context, usage []*models.APIUsage) error
+	// List retrieves usage matching filter, most recently seen first.
+	List(ctx context.Context, filter models.APIUsageFilter) ([]*models.APIUsage, error)
+}
+
+// EventStore delivers the changes of apps, services, deployments and builds
+// made by any process using the database.
+type EventStore interface {
+	// Listen calls fn with each change until ctx is done or the connection
+	// delivering them is lost, returning why it stopped.
+	Listen(ctx context.Context, fn func(*models.Event)) error
+}
+
+// BuildAttestationStore defines operations for the signed provenance of builds.
+type BuildAttestationStore interface {
+	// Save records a build's attestation, replacing any earlier one of the build.
+	Save(ctx context.Context, attestation *models.BuildAttestation) error
+	// Get retrieves a build's attestation. It returns nil if the build has none.
+	Get(ctx context.Context, buildID string) (*models.BuildAttestation, error)
+}
+
+// CronRunStore defines operations for the runs of cron services.
+type CronRunStore interface {
+	// Create stores a new run.
+	Create(ctx context.Context, run *models.CronRun) error
+	// Update saves a run's deployment, status, times and error.
+	Update(ctx context.Context, run *models.CronRun) error
+	// Get retrieves a run by ID. It returns nil if the run does not exist.
+	Get(ctx context.Context, id string) (*models.CronRun, error)
+	// GetByDeployment retrieves the run executed by a deployment. It returns
+	// nil if the deployment is not a run.
+	GetByDeployment(ctx context.Context, deploymentID string) (*models.CronRun, error)
+	// GetLatest retrieves a service's run with the latest scheduled time
+	// started by the given trigger. It returns nil if there is none.
+	GetLatest(ctx context.Context, appID, serviceName string, trigger models.CronRunTrigger) (*models.CronRun, error)
+	// List retrieves a service's most recent runs, newest first.
+	List(ctx context.Context, appID, serviceName string, limit int) ([]*models.CronRun, error)
+	// ListActive retrieves the runs of all services that are pending or running.
+	ListActive(ctx context.Context) ([]*models.CronRun, error)
+}
+
+// EnvironmentStore defines operations for the environments of apps' release
+// pipelines.
+type EnvironmentStore interface {
+	// Create stores a new environment.
+	Create(ctx context.Context, env *models.Environment) error
+	// Get retrieves an environment by ID. It returns nil if the environment
+	// does not exist.
+	Get(ctx context.Context, id string) (*models.Environment, error)
+	// GetByEnvApp retrieves the environment backed by an app. It returns nil
+	// if the app backs no environment.
+	GetByEnvApp(ctx context.Context, envAppID string) (*models.Environment, error)
+	// List retrieves an app's environments ordered by position.
+	List(ctx context.Context, appID string) ([]*models.Environment, error)
+	// Update saves an environment's name, position and approval requirement.
+	Update(ctx context.Context, env *models.Environment) error
+	// Delete removes an environment and the promotions into it.
+	Delete(ctx context.Context, id string) error
+}
+
+// ScaleEventStore defines operations for the history of services' replica
+// count changes.
+type ScaleEventStore interface {
+	// Create stores a new event.
+	Create(ctx context.Context, event *models.ScaleEvent) error
+	// List retrieves a service's most recent events, newest first.
+	List(ctx context.Context, appID, serviceName string, limit int) ([]*models.ScaleEvent, error)
+	// DeleteBefore removes events created before the given time and returns
+	// how many were removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SmokeTestStore defines operations for the smoke test runs of deployments.
+type SmokeTestStore interface {
+	// Create stores a new run.
+	Create(ctx context.Context, run *models.SmokeTestRun) error
+	// Update saves a run's status, results, error, rollback and times.
+	Update(ctx context.Context, run *models.SmokeTestRun) error
+	// GetByDeployment retrieves the run of a deployment. It returns nil if
+	// the deployment was not smoke tested.
+	GetByDeployment(ctx context.Context, deploymentID string) (*models.SmokeTestRun, error)
+	// ListActive retrieves the runs of all deployments that are pending or running.
+	ListActive(ctx context.Context) ([]*models.SmokeTestRun, error)
+}
+
+// LoadTestStore defines operations for on-demand load tests of deployments.
+type LoadTestStore interface {
+	// Create stores a new test.
+	Create(ctx context.Context, test *models.LoadTest) error
+	// Update saves a test's status, error, result and times.
+	Update(ctx context.Context, test *models.LoadTest) error
+	// Get retrieves a test by ID.
+	Get(ctx context.Context, id string) (*models.LoadTest, error)
+	// Claim marks the oldest pending test designated to the worker, or to no
+	// worker, as running by it and returns it. It returns nil if there is none.
+	Claim(ctx context.Context, workerID string) (*models.LoadTest, error)
+	// ListByDeployment retrieves a deployment's tests, newest first.
+	ListByDeployment(ctx context.Context, deploymentID string) ([]*models.LoadTest, error)
+	// ListByService retrieves the tests of all of a service's deployments, newest first.
+	ListByService(ctx context.Context, appID, serviceName string, limit int) ([]*models.LoadTest, error)
+	// FailOverdue fails running tests that should have finished over a
+	// minute ago, e.g. because their worker stopped.
+	FailOverdue(ctx context.Context) (int, error)
+}
+
+// OperationStore defines operations for long-running operations and their
+// progress.
+type OperationStore interface {
+	// Create stores a new operation.
+	Create(ctx context.Context, op *models.Operation) error
+	// Update saves an operation's status, progress, stage, errors, result and
+	// finish time, and sets its update time to now.
+	Update(ctx context.Context, op *models.Operation) error
+	// Get retrieves an operation by ID. It returns nil if the operation does
+	// not exist.
+	Get(ctx context.Context, id string) (*models.Operation, error)
+	// FailStale fails running operations not updated since before, e.g.
+	// because the server running them stopped.
+	FailStale(ctx context.Context, before time.Time) (int, error)
+	// DeleteFinishedBefore deletes operations that finished before the given
+	// time.
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// ArchiveStore defines operations for deployments moved to cold storage and
+// their restore queue.
+type ArchiveStore interface {
+	// Create records an archived deployment.
+	Create(ctx context.Context, archive *models.Archive) error
+	// GetByDeployment retrieves a deployment's archive. It returns nil if
+	// the deployment is not archived.
+	GetByDeployment(ctx context.Context, deploymentID string) (*models.Archive, error)
+	// ListCandidates retrieves up to limit stopped, failed or canceled
+	// deployments last updated before the given time that are not archived
+	// and have at least keep newer deployments of the same service, oldest
+	// first.
+	ListCandidates(ctx context.Context, before time.Time, keep, limit int) ([]string, error)
+	// ClaimRestore marks an archive as restoring unless another restore of
+	// it started after staleBefore. It returns false if the archive is
+	// being restored or does not exist.
+	ClaimRestore(ctx context.Context, id string, staleBefore time.Time) (bool, error)
+	// FailRestore marks a restore as failed so it is retried.
+	FailRestore(ctx context.Context, id, message string) error
+	// ListRestoreQueue retrieves the archives being or waiting to be
+	// restored, oldest request first.
+	ListRestoreQueue(ctx context.Context) ([]*models.Archive, error)
+	// Delete removes an archive record once it is restored.
+	Delete(ctx context.Context, id string) error
+	// Stats returns the number and size of archives.
+	Stats(ctx context.Context) (*models.ArchiveStats, error)
+}
+
+// MetricStore defines operations for per-deployment resource usage rollups.
+type MetricStore interface {
+	// Record adds a sample to its deployment's rollup for the sample's
+	// minute, creating the rollup if needed.
+	Record(ctx context.Context, sample *models.MetricSample) error
+	// ListByService retrieves the rollups of all of a service's deployments
+	// with buckets in [from, to), oldest first.
+	ListByService(ctx context.Context, appID, serviceName string, from, to time.Time) ([]*models.MetricRollup, error)
+	// DeleteBefore removes rollups with buckets before the given time and
+	// returns how many were removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OrgSecretStore defines operations for secrets shared across an org's apps.
+type OrgSecretStore interface {
+	// Create stores a new shared secret. Keys are unique within an org.
+	Create(ctx context.Context, secret *models.OrgSecret) error
+	// Get retrieves an org's shared secret by key. It returns nil if the secret does not exist.
+	Get(ctx context.Context, orgID, key string) (*models.OrgSecret, error)
+	// List retrieves all of an org's shared secrets, ordered by key.
+	List(ctx context.Context, orgID string) ([]*models.OrgSecret, error)
+	// Update saves a shared secret's value, description and scope.
+	Update(ctx context.Context, secret *models.OrgSecret) error
+	// Delete removes a shared secret and its usage records.
+	Delete(ctx context.Context, id string) error
+	// RecordUsage records that shared secrets were injected into services,
+	// replacing the previous time for each secret, app and service.
+	RecordUsage(ctx context.Context, usages []*models.OrgSecretUsage) error
+	// ListUsage retrieves where a shared secret has been injected, most recent first.
+	ListUsage(ctx context.Context, secretID string) ([]*models.OrgSecretUsage, error)
+}
+
+// AuditStore defines operations for the audit log of mutating API requests.
+type AuditStore interface {
+	// Record stores an audit entry.
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	// List retrieves the entries matching a filter, newest first.
+	List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error)
+	// DeleteBefore removes entries created before the given time and returns
+	// how many were removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ServiceTemplateStore defines operations for service templates.
+type ServiceTemplateStore interface {
+	// Create stores a new service template. Names are unique within an app.
+	Create(ctx context.Context, template *models.ServiceTemplate) error
+	// Get retrieves an app's service template by name. It returns nil if the template does not exist.
+	Get(ctx context.Context, appID, name string) (*models.ServiceTemplate, error)
+	// List retrieves an app's service templates ordered by name.
+	List(ctx context.Context, appID string) ([]*models.ServiceTemplate, error)
+	// Update saves a template's description, variables and service.
+	Update(ctx context.Context, template *models.ServiceTemplate) error
+	// Delete removes an app's service template.
+	Delete(ctx context.Context, appID, name string) error
+}
+
+// AppTemplateStore defines operations for app templates added by instance
+// admins. Bundled templates are not stored.
+type AppTemplateStore interface {
+	// Create stores a new app template. Names are unique.
+	Create(ctx context.Context, template *models.AppTemplate) error
+	// Get retrieves an app template by name. It returns nil if the template does not exist.
+	Get(ctx context.Context, name string) (*models.AppTemplate, error)
+	// List retrieves all app templates ordered by name.
+	List(ctx context.Context) ([]*models.AppTemplate, error)
+	// Delete removes an app template.
+	Delete(ctx context.Context, name string) error
+}
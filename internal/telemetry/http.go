@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// scrapeTimeout bounds the collection of metrics read from the database.
+const scrapeTimeout = 10 * time.Second
+
+// Handler serves the registry's metrics at /metrics. With a token, scrapes
+// must send it as a bearer token; without one the metrics are public, so
+// the endpoint should then only be reachable from the monitoring network.
+func (r *Registry) Handler(token string, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if token != "" {
+			got := []byte(req.Header.Get("Authorization"))
+			if subtle.ConstantTimeCompare(got, []byte("Bearer "+token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), scrapeTimeout)
+		defer cancel()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteTo(ctx, w); err != nil {
+			// The metrics that could be collected were still written
+			logger.Warn("failed to collect metrics", "error", err)
+		}
+	})
+}
+
+// HTTPMetrics records the requests served by a chi router: how many, by
+// method, route pattern and status code, and how long they took.
+type HTTPMetrics struct {
+	requests *CounterVec
+	duration *HistogramVec
+	inFlight *Gauge
+}
+
+// NewHTTPMetrics registers the HTTP request metrics.
+func NewHTTPMetrics(r *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: r.Counter("narvana_http_requests_total",
+			"HTTP requests served, by method, route and status code.", "method", "route", "code"),
+		duration: r.Histogram("narvana_http_request_duration_seconds",
+			"Time taken to serve HTTP requests, by method and route. Streaming requests count until the stream ends.",
+			DefaultBuckets, "method", "route"),
+		inFlight: r.Gauge("narvana_http_requests_in_flight",
+			"HTTP requests being served, including open streams.").With(),
+	}
+}
+
+// Middleware records each request. Requests are labelled with the route
+// pattern, such as /v1/apps/{appID}, rather than the path, so IDs don't
+// create a series each; requests no route matched are labelled "unmatched".
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.requests.With(r.Method, route, strconv.Itoa(status)).Inc()
+		m.duration.With(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,411 @@
+// Package telemetry collects metrics about the control plane itself and
+// serves them in the Prometheus text exposition format, so operators can
+// scrape the API server, build workers and web UI.
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets in seconds suited to request latencies.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds the metrics of a process in registration order.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// metric is a family of series sharing a name, type and label names.
+type metric interface {
+	// write writes the family's series; collected metrics may fail.
+	write(ctx context.Context, w *bufio.Writer) error
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// register adds a metric, panicking if its name is taken as registering the
+// same metric twice is a programming error.
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("telemetry: metric " + name + " registered twice")
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo writes every metric in the Prometheus text format. A collected
+// metric that fails is left out and its error returned once the others have
+// been written.
+func (r *Registry) WriteTo(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	var errs []error
+	for _, m := range metrics {
+		if err := m.write(ctx, bw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// desc describes a metric family.
+type desc struct {
+	name   string
+	help   string
+	kind   string // counter, gauge or histogram
+	labels []string
+}
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.kind)
+}
+
+// series identifies the series of a family by its label values.
+type series struct {
+	key    string
+	values []string
+}
+
+func (d desc) series(values []string) series {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("telemetry: %s has %d labels, got %d values", d.name, len(d.labels), len(values)))
+	}
+	return series{key: strings.Join(values, "\xff"), values: append([]string(nil), values...)}
+}
+
+// labelPairs renders label values as {a="1",b="2"}, with extra pairs such as
+// a histogram's le appended.
+func (d desc) labelPairs(values []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range d.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escapeLabel escapes backslashes, quotes and newlines in a label value.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of a family's series in a stable order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	mu sync.Mutex
+	v  float64
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds a non-negative amount to the counter.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.v += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	series
+	counter *Counter
+}
+
+// Counter registers a counter family with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{desc: desc{name, help, "counter", labels}, series: make(map[string]*counterSeries)}
+	r.register(name, v)
+	return v
+}
+
+// With returns the counter for the given label values, in the order of the
+// family's label names.
+func (v *CounterVec) With(values ...string) *Counter {
+	s := v.desc.series(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if cs := v.series[s.key]; cs != nil {
+		return cs.counter
+	}
+	cs := &counterSeries{series: s, counter: &Counter{}}
+	v.series[s.key] = cs
+	return cs.counter
+}
+
+func (v *CounterVec) write(ctx context.Context, w *bufio.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, k := range sortedKeys(v.series) {
+		s := v.series[k]
+		fmt.Fprintf(w, "%s%s %s\n", v.name, v.labelPairs(s.values), formatFloat(s.counter.value()))
+	}
+	return nil
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	mu sync.Mutex
+	v  float64
+}
+
+// Set sets the gauge to a value.
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	g.v = v
+	g.mu.Unlock()
+}
+
+// Add adds an amount, which may be negative, to the gauge.
+func (g *Gauge) Add(v float64) {
+	g.mu.Lock()
+	g.v += v
+	g.mu.Unlock()
+}
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc() { g.Add(1) }
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec() { g.Add(-1) }
+
+func (g *Gauge) value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.v
+}
+
+// GaugeVec is a family of gauges partitioned by labels.
+type GaugeVec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*gaugeSeries
+}
+
+type gaugeSeries struct {
+	series
+	gauge *Gauge
+}
+
+// Gauge registers a gauge family with the given label names.
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	v := &GaugeVec{desc: desc{name, help, "gauge", labels}, series: make(map[string]*gaugeSeries)}
+	r.register(name, v)
+	return v
+}
+
+// With returns the gauge for the given label values, in the order of the
+// family's label names.
+func (v *GaugeVec) With(values ...string) *Gauge {
+	s := v.desc.series(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if gs := v.series[s.key]; gs != nil {
+		return gs.gauge
+	}
+	gs := &gaugeSeries{series: s, gauge: &Gauge{}}
+	v.series[s.key] = gs
+	return gs.gauge
+}
+
+func (v *GaugeVec) write(ctx context.Context, w *bufio.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, k := range sortedKeys(v.series) {
+		s := v.series[k]
+		fmt.Fprintf(w, "%s%s %s\n", v.name, v.labelPairs(s.values), formatFloat(s.gauge.value()))
+	}
+	return nil
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64 // Per bucket, not cumulative
+	sum     float64
+	count   uint64
+}
+
+// Observe records a value, such as a duration in seconds.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec is a family of histograms partitioned by labels.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	series
+	histogram *Histogram
+}
+
+// Histogram registers a histogram family with the given upper bucket bounds,
+// which must be sorted, and label names. A +Inf bucket is always added.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	v := &HistogramVec{
+		desc:    desc{name, help, "histogram", labels},
+		buckets: append([]float64(nil), buckets...),
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(name, v)
+	return v
+}
+
+// With returns the histogram for the given label values, in the order of the
+// family's label names.
+func (v *HistogramVec) With(values ...string) *Histogram {
+	s := v.desc.series(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if hs := v.series[s.key]; hs != nil {
+		return hs.histogram
+	}
+	hs := &histogramSeries{series: s, histogram: &Histogram{buckets: v.buckets, counts: make([]uint64, len(v.buckets))}}
+	v.series[s.key] = hs
+	return hs.histogram
+}
+
+func (v *HistogramVec) write(ctx context.Context, w *bufio.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, k := range sortedKeys(v.series) {
+		s := v.series[k]
+		h := s.histogram
+		h.mu.Lock()
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labelPairs(s.values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labelPairs(s.values, "le", "+Inf"), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, v.labelPairs(s.values), formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, v.labelPairs(s.values), h.count)
+		h.mu.Unlock()
+	}
+	return nil
+}
+
+// Sample is a value of a collected metric.
+type Sample struct {
+	// Labels are the values of the metric's labels, in order.
+	Labels []string
+	Value  float64
+}
+
+// collected is a gauge family whose samples are computed on each scrape.
+type collected struct {
+	desc
+	collect func(ctx context.Context) ([]Sample, error)
+}
+
+// Collect registers a gauge family whose samples fn computes on each scrape,
+// for values that live elsewhere such as row counts in the database.
+func (r *Registry) Collect(name, help string, labels []string, fn func(ctx context.Context) ([]Sample, error)) {
+	r.register(name, &collected{desc: desc{name, help, "gauge", labels}, collect: fn})
+}
+
+func (c *collected) write(ctx context.Context, w *bufio.Writer) error {
+	samples, err := c.collect(ctx)
+	if err != nil {
+		return fmt.Errorf("collecting %s: %w", c.name, err)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].Labels, "\xff") < strings.Join(samples[j].Labels, "\xff")
+	})
+	c.writeHeader(w)
+	for _, s := range samples {
+		c.desc.series(s.Labels) // Validates the label count
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(s.Labels), formatFloat(s.Value))
+	}
+	return nil
+}
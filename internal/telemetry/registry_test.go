@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestWriteToUsesTextFormat(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("jobs_total", "Jobs run.", "status").With("done").Add(3)
+	r.Gauge("queue_depth", "Jobs waiting.\nPer queue.").With().Set(2)
+	h := r.Histogram("build_seconds", "Build time.", []float64{1, 10}, "strategy").With(`auto "go"`)
+	for _, v := range []float64{0.5, 1, 5, 30} {
+		h.Observe(v)
+	}
+	r.Collect("deployments", "Deployments by status.", []string{"status"}, func(ctx context.Context) ([]Sample, error) {
+		return []Sample{{Labels: []string{"running"}, Value: 4}, {Labels: []string{"failed"}, Value: 1}}, nil
+	})
+
+	var buf bytes.Buffer
+	if err := r.WriteTo(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := `# HELP jobs_total Jobs run.
+# TYPE jobs_total counter
+jobs_total{status="done"} 3
+# HELP queue_depth Jobs waiting.\nPer queue.
+# TYPE queue_depth gauge
+queue_depth 2
+# HELP build_seconds Build time.
+# TYPE build_seconds histogram
+build_seconds_bucket{strategy="auto \"go\"",le="1"} 2
+build_seconds_bucket{strategy="auto \"go\"",le="10"} 3
+build_seconds_bucket{strategy="auto \"go\"",le="+Inf"} 4
+build_seconds_sum{strategy="auto \"go\""} 36.5
+build_seconds_count{strategy="auto \"go\""} 4
+# HELP deployments Deployments by status.
+# TYPE deployments gauge
+deployments{status="failed"} 1
+deployments{status="running"} 4
+`
+	if buf.String() != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteToSkipsFailedCollections(t *testing.T) {
+	r := NewRegistry()
+	r.Collect("broken", "Fails.", nil, func(ctx context.Context) ([]Sample, error) {
+		return nil, errors.New("database unavailable")
+	})
+	r.Counter("requests_total", "Requests.").With().Inc()
+
+	var buf bytes.Buffer
+	err := r.WriteTo(context.Background(), &buf)
+	if err == nil || !strings.Contains(err.Error(), "database unavailable") {
+		t.Errorf("WriteTo() error = %v, want the collection error", err)
+	}
+	if strings.Contains(buf.String(), "broken") || !strings.Contains(buf.String(), "requests_total 1") {
+		t.Errorf("WriteTo() = %q, want only requests_total", buf.String())
+	}
+}
+
+func TestMiddlewareLabelsRoutePatterns(t *testing.T) {
+	reg := NewRegistry()
+	m := NewHTTPMetrics(reg)
+	router := chi.NewRouter()
+	router.Use(m.Middleware)
+	router.Get("/v1/apps/{appID}", func(w http.ResponseWriter, r *http.Request) {})
+	router.Handle("/metrics", reg.Handler("s3cret", nil))
+
+	for _, path := range []string{"/v1/apps/a", "/v1/apps/b", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("scrape without token: status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	router.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	for _, want := range []string{
+		`narvana_http_requests_total{method="GET",route="/v1/apps/{appID}",code="200"} 2`,
+		`narvana_http_requests_total{method="GET",route="unmatched",code="404"} 1`,
+		`narvana_http_request_duration_seconds_count{method="GET",route="/v1/apps/{appID}"} 2`,
+		`narvana_http_requests_in_flight 1`, // The scrape itself
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// CollectBuildQueue registers the number of jobs in the build queue by
+// status, read from the database on each scrape.
+func CollectBuildQueue(r *Registry, st store.Store) {
+	r.Collect("narvana_build_queue_jobs", "Jobs in the build queue, by status.", []string{"status"},
+		func(ctx context.Context) ([]Sample, error) {
+			counts, err := st.Stats().BuildQueueDepth(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]Sample, 0, len(counts))
+			for status, n := range counts {
+				samples = append(samples, Sample{Labels: []string{status}, Value: float64(n)})
+			}
+			return samples, nil
+		})
+}
+
+// CollectDeployments registers the number of deployments by status, read
+// from the database on each scrape.
+func CollectDeployments(r *Registry, st store.Store) {
+	r.Collect("narvana_deployments", "Deployments of apps that have not been deleted, by status.", []string{"status"},
+		func(ctx context.Context) ([]Sample, error) {
+			counts, err := st.Stats().DeploymentsByStatus(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]Sample, 0, len(counts))
+			for status, n := range counts {
+				samples = append(samples, Sample{Labels: []string{string(status)}, Value: float64(n)})
+			}
+			return samples, nil
+		})
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/narvanalabs/control-plane/pkg/config"
+)
+
+// record installs a tracer provider keeping every span in memory.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	if _, err := Setup(context.Background(), config.TracingConfig{}, "test"); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := record(t)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/v1/apps/{appID}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+
+	// A request carrying the trace context of the web UI continues its trace
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/v1/apps/app-1", nil)
+	req.Header.Set("traceparent", parent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if got := spans[0].Name(); got != "GET /v1/apps/{appID}" {
+		t.Errorf("span name = %q, want the route pattern", got)
+	}
+	if got := spans[0].Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %q, want the caller's", got)
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	recorder := record(t)
+
+	if carrier := Inject(context.Background()); carrier != nil {
+		t.Errorf("Inject() outside a trace = %v, want nil", carrier)
+	}
+
+	// A queued job continues the trace of the request that queued it
+	ctx, span := Start(context.Background(), "deploy")
+	carrier := Inject(ctx)
+	End(span, nil)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("Inject() = %v, want a traceparent", carrier)
+	}
+
+	_, build := Start(Extract(context.Background(), carrier), "build")
+	End(build, nil)
+	spans := recorder.Ended()
+	if got := spans[len(spans)-1].Parent(); !got.Equal(trace.SpanContextFromContext(ctx).WithRemote(true)) {
+		t.Errorf("build parent = %v, want %v", got, span.SpanContext())
+	}
+}
@@ -2,31 +2,41 @@ package validation
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/narvanalabs/control-plane/internal/builder/templates/databases"
 	"github.com/narvanalabs/control-plane/internal/models"
 )
 
-// SupportedDatabaseTypes defines the supported database types and their versions.
-var SupportedDatabaseTypes = map[string][]string{
-	"postgres": {"14", "15", "16"},
-	"mysql":    {"8.0", "8.4"},
-	"mariadb":  {"10.11", "11.4"},
-	"mongodb":  {"6.0", "7.0"},
-	"redis":    {"7.0", "7.2"},
-	"sqlite":   {"3"},
-}
+// SupportedDatabaseTypes defines the supported database types and their
+// versions: those of the database template registry, plus SQLite.
+var SupportedDatabaseTypes = supportedDatabaseTypes()
 
 // DefaultDatabaseVersions defines the default version for each database type.
-var DefaultDatabaseVersions = map[string]string{
-	"postgres": "16",
-	"mysql":    "8.4",
-	"mariadb":  "11.4",
-	"mongodb":  "7.0",
-	"redis":    "7.2",
-	"sqlite":   "3",
+var DefaultDatabaseVersions = defaultDatabaseVersions()
+
+func supportedDatabaseTypes() map[string][]string {
+	types := map[string][]string{"sqlite": {"3"}}
+	for dbType, tmpl := range databases.Registry {
+		types[string(dbType)] = tmpl.AvailableVersions
+	}
+	return types
+}
+
+func defaultDatabaseVersions() map[string]string {
+	versions := map[string]string{"sqlite": "3"}
+	for dbType, tmpl := range databases.Registry {
+		versions[string(dbType)] = tmpl.DefaultVersion
+	}
+	return versions
 }
 
+// databaseOptionValue matches the values database options may take. They are
+// rendered into the service's flake, so quotes, $ and the like are rejected.
+var databaseOptionValue = regexp.MustCompile(`^[A-Za-z0-9 ._:*/-]{1,64}$`)
+
 // ValidateDatabaseConfig validates a database configuration.
 // Requirements: 29.1, 29.2
 func ValidateDatabaseConfig(config *models.DatabaseConfig) error {
@@ -55,18 +65,42 @@ func ValidateDatabaseConfig(config *models.DatabaseConfig) error {
 	}
 
 	// If version is empty, it will use the default - that's valid
-	if config.Version == "" {
-		return nil
-	}
-
-	// Validate version is supported for this type
-	if !isVersionSupported(config.Version, supportedVersions) {
+	if config.Version != "" && !isVersionSupported(config.Version, supportedVersions) {
 		return &models.ValidationError{
 			Field:   "database.version",
 			Message: fmt.Sprintf("unsupported version '%s' for database type '%s'; supported versions are: %s", config.Version, config.Type, strings.Join(supportedVersions, ", ")),
 		}
 	}
 
+	return validateDatabaseOptions(config)
+}
+
+// validateDatabaseOptions checks that a database's options are ones its
+// template knows, with values that are safe to render into it.
+func validateDatabaseOptions(config *models.DatabaseConfig) error {
+	known := make(map[string]bool)
+	for _, opt := range databases.GetConfigOptions(databases.DatabaseType(config.Type)) {
+		known[opt.Name] = true
+	}
+	for name, value := range config.Options {
+		if !known[name] {
+			names := make([]string, 0, len(known))
+			for n := range known {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return &models.ValidationError{
+				Field:   "database.options." + name,
+				Message: fmt.Sprintf("unknown option '%s' for database type '%s'; known options are: %s", name, config.Type, strings.Join(names, ", ")),
+			}
+		}
+		if !databaseOptionValue.MatchString(value) {
+			return &models.ValidationError{
+				Field:   "database.options." + name,
+				Message: "option values may only contain letters, digits, spaces and . _ : * / -",
+			}
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestValidateDatabaseOptions(t *testing.T) {
+	for _, tc := range []struct {
+		options map[string]string
+		valid   bool
+	}{
+		{map[string]string{"maxmemory": "512mb", "maxmemory_policy": "volatile-lru"}, true},
+		{map[string]string{"backup_schedule": "0 3 * * *"}, true},
+		{map[string]string{"max_connections": "100"}, false},
+		{map[string]string{"maxmemory": `1gb"; rm -rf /; "`}, false},
+		{map[string]string{"maxmemory": "${pkgs.hello}"}, false},
+	} {
+		err := ValidateDatabaseConfig(&models.DatabaseConfig{Type: "redis", Version: "7", Options: tc.options})
+		if (err == nil) != tc.valid {
+			t.Errorf("ValidateDatabaseConfig(%v) error = %v, want valid = %v", tc.options, err, tc.valid)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// MaxEgressRules bounds the allowlist so the generated firewall rules stay small.
+const MaxEgressRules = 50
+
+// hostnameRegex validates DNS hostnames, optionally with a leading wildcard
+// label (e.g., "*.amazonaws.com").
+var hostnameRegex = regexp.MustCompile(`^(\*\.)?([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// ValidateEgressPolicy validates a service's egress policy. A nil policy is
+// valid and allows all outbound traffic.
+func ValidateEgressPolicy(policy *models.EgressPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if len(policy.Allow) > MaxEgressRules {
+		return &models.ValidationError{
+			Field:   "egress.allow",
+			Message: fmt.Sprintf("at most %d egress rules are allowed", MaxEgressRules),
+		}
+	}
+
+	for i, rule := range policy.Allow {
+		field := fmt.Sprintf("egress.allow[%d]", i)
+
+		switch {
+		case rule.Host == "" && rule.CIDR == "":
+			return &models.ValidationError{Field: field, Message: "either host or cidr is required"}
+		case rule.Host != "" && rule.CIDR != "":
+			return &models.ValidationError{Field: field, Message: "only one of host or cidr may be set"}
+		case rule.Host != "":
+			if len(rule.Host) > 253 || !hostnameRegex.MatchString(rule.Host) {
+				return &models.ValidationError{
+					Field:   field + ".host",
+					Message: fmt.Sprintf("%q is not a valid hostname", rule.Host),
+				}
+			}
+		default:
+			if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+				return &models.ValidationError{
+					Field:   field + ".cidr",
+					Message: fmt.Sprintf("%q is not a valid CIDR (e.g., \"10.0.0.0/8\")", rule.CIDR),
+				}
+			}
+		}
+
+		for _, port := range rule.Ports {
+			if port < 1 || port > 65535 {
+				return &models.ValidationError{
+					Field:   field + ".ports",
+					Message: fmt.Sprintf("port %d must be between 1 and 65535", port),
+				}
+			}
+		}
+
+		if rule.Protocol != "" && rule.Protocol != "tcp" && rule.Protocol != "udp" {
+			return &models.ValidationError{
+				Field:   field + ".protocol",
+				Message: "protocol must be \"tcp\" or \"udp\"",
+			}
+		}
+	}
+
+	return nil
+}
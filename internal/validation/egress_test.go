@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+func TestValidateEgressPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *models.EgressPolicy
+		wantErr string
+	}{
+		{name: "nil", policy: nil},
+		{name: "allow all", policy: &models.EgressPolicy{}},
+		{
+			name: "valid allowlist",
+			policy: &models.EgressPolicy{DenyAll: true, Allow: []models.EgressRule{
+				{Host: "api.stripe.com", Ports: []int{443}, Protocol: "tcp"},
+				{Host: "*.amazonaws.com"},
+				{CIDR: "10.0.0.0/8"},
+				{CIDR: "2001:db8::/32", Protocol: "udp"},
+			}},
+		},
+		{
+			name:    "empty rule",
+			policy:  &models.EgressPolicy{DenyAll: true, Allow: []models.EgressRule{{Ports: []int{443}}}},
+			wantErr: "egress.allow[0]",
+		},
+		{
+			name:    "host and cidr",
+			policy:  &models.EgressPolicy{Allow: []models.EgressRule{{Host: "example.com", CIDR: "10.0.0.0/8"}}},
+			wantErr: "only one of host or cidr",
+		},
+		{
+			name:    "invalid host",
+			policy:  &models.EgressPolicy{Allow: []models.EgressRule{{Host: "bad host"}}},
+			wantErr: "egress.allow[0].host",
+		},
+		{
+			name:    "invalid cidr",
+			policy:  &models.EgressPolicy{Allow: []models.EgressRule{{CIDR: "10.0.0.0/33"}}},
+			wantErr: "egress.allow[0].cidr",
+		},
+		{
+			name:    "port out of range",
+			policy:  &models.EgressPolicy{Allow: []models.EgressRule{{CIDR: "10.0.0.0/8", Ports: []int{0}}}},
+			wantErr: "egress.allow[0].ports",
+		},
+		{
+			name:    "unknown protocol",
+			policy:  &models.EgressPolicy{Allow: []models.EgressRule{{CIDR: "10.0.0.0/8", Protocol: "icmp"}}},
+			wantErr: "egress.allow[0].protocol",
+		},
+		{
+			name:    "too many rules",
+			policy:  &models.EgressPolicy{Allow: make([]models.EgressRule, MaxEgressRules+1)},
+			wantErr: "at most",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEgressPolicy(tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -75,3 +75,30 @@ func ValidateMemory(memory string) error {
 	}
 	return nil
 }
+
+// ValidateBuildLimits validates the build timeout and build container limits
+// of a build configuration. Formats match those of ValidateResourceSpec.
+func ValidateBuildLimits(config *models.BuildConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.BuildTimeout < 0 {
+		return &models.ValidationError{
+			Field:   "build_config.build_timeout",
+			Message: "build timeout must be a positive number of seconds",
+		}
+	}
+	if config.BuildCPU != "" && (!cpuRegex.MatchString(config.BuildCPU) || config.BuildCPU == "0") {
+		return &models.ValidationError{
+			Field:   "build_config.build_cpu",
+			Message: "build CPU must be a valid decimal number (e.g., \"2\", \"4\")",
+		}
+	}
+	if config.BuildMemory != "" && !memoryRegex.MatchString(config.BuildMemory) {
+		return &models.ValidationError{
+			Field:   "build_config.build_memory",
+			Message: "build memory must be a valid size with unit suffix (e.g., \"4Gi\", \"8Gi\")",
+		}
+	}
+	return nil
+}
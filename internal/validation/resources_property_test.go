@@ -244,3 +244,33 @@ func TestResourceFormatValidation(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+func TestValidateBuildLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *models.BuildConfig
+		wantField string
+	}{
+		{"nil config", nil, ""},
+		{"unset limits", &models.BuildConfig{}, ""},
+		{"valid limits", &models.BuildConfig{BuildTimeout: 3600, BuildCPU: "4", BuildMemory: "8Gi"}, ""},
+		{"negative timeout", &models.BuildConfig{BuildTimeout: -1}, "build_config.build_timeout"},
+		{"zero CPU", &models.BuildConfig{BuildCPU: "0"}, "build_config.build_cpu"},
+		{"memory without unit", &models.BuildConfig{BuildMemory: "8192"}, "build_config.build_memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBuildLimits(tt.config)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateBuildLimits() = %v, want nil", err)
+				}
+				return
+			}
+			verr, ok := err.(*models.ValidationError)
+			if !ok || verr.Field != tt.wantField {
+				t.Errorf("ValidateBuildLimits() = %v, want an error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
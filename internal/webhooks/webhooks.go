@@ -0,0 +1,219 @@
+// Package webhooks calls users' webhooks: endpoints that receive a signed
+// JSON payload when a deployment starts, succeeds or fails, a build
+// finishes, or, for instance admins, a node turns unhealthy or recovers.
+//
+// A Dispatcher subscribed to the event bus queues one delivery per
+// subscribed webhook in the database and a Worker running in the API server
+// sends them with exponential backoff, keeping each endpoint's response so
+// users can see why a delivery failed and queue it again.
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/narvanalabs/control-plane/internal/auth"
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// Dispatcher queues lifecycle events for delivery to the enabled webhooks
+// subscribed to them.
+type Dispatcher struct {
+	store  store.Store
+	logger *slog.Logger
+}
+
+// NewDispatcher creates a new dispatcher.
+func NewDispatcher(st store.Store, logger *slog.Logger) *Dispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Dispatcher{store: st, logger: logger}
+}
+
+// HandleTransition queues the webhook event of a transition published on
+// the event bus, if it has one. Failures are returned so the bus delivers
+// the transition again; webhooks queued it before the failure then get the
+// event twice, with the same payload ID.
+func (d *Dispatcher) HandleTransition(ctx context.Context, t *events.Transition) error {
+	payload := transitionPayload(t)
+	if payload == nil {
+		return nil
+	}
+	return d.Dispatch(ctx, payload)
+}
+
+// Dispatch queues a payload for every enabled webhook subscribed to its
+// event whose user may see it: app events go to users who own the app or
+// are members of its org, node events to instance admins.
+func (d *Dispatcher) Dispatch(ctx context.Context, payload *models.WebhookPayload) error {
+	webhooks, err := d.store.Webhooks().ListEnabled(ctx)
+	if err != nil {
+		d.logger.Error("failed to list webhooks", "error", err, "event", payload.Event)
+		return fmt.Errorf("listing webhooks: %w", err)
+	}
+
+	var app *models.App
+	if payload.AppID != "" {
+		app, err = d.store.Apps().Get(ctx, payload.AppID)
+		if err != nil || app == nil {
+			// Events of apps deleted since are of no use to anyone
+			return nil
+		}
+		payload.AppName = app.Name
+	}
+
+	allowed := make(map[string]bool)
+	var errs []error
+	for _, w := range webhooks {
+		if !w.Subscribes(payload.Event) || (w.AppID != "" && w.AppID != payload.AppID) {
+			continue
+		}
+		ok, checked := allowed[w.UserID]
+		if !checked {
+			ok, err = d.allowed(ctx, w.UserID, payload.Event, app)
+			if err != nil {
+				d.logger.Error("failed to check webhook access", "error", err, "webhook_id", w.ID, "user_id", w.UserID)
+				errs = append(errs, fmt.Errorf("checking access of webhook %s: %w", w.ID, err))
+				continue
+			}
+			allowed[w.UserID] = ok
+		}
+		if !ok {
+			continue
+		}
+
+		delivery := &models.WebhookDelivery{WebhookID: w.ID, Payload: *payload}
+		if err := d.store.Webhooks().EnqueueDelivery(ctx, delivery); err != nil {
+			d.logger.Error("failed to queue webhook",
+				"error", err,
+				"webhook_id", w.ID,
+				"event", payload.Event,
+			)
+			errs = append(errs, fmt.Errorf("queueing %s for webhook %s: %w", payload.Event, w.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allowed reports whether a user may receive an event of an app, or for
+// node events whether the user is an instance admin.
+func (d *Dispatcher) allowed(ctx context.Context, userID string, event models.WebhookEvent, app *models.App) (bool, error) {
+	if event.IsNode() {
+		user, err := d.store.Users().GetByID(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		return user != nil && auth.CheckRolePermission(user.Role, auth.PermissionAdminConsole) == nil, nil
+	}
+	if app.OwnerID == userID {
+		return true, nil
+	}
+	if app.OrgID == "" {
+		return false, nil
+	}
+	role, err := d.store.Orgs().GetMemberRole(ctx, app.OrgID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role != "", nil
+}
+
+// transitionPayload returns the webhook payload of a transition, or nil if
+// it is not a webhook event.
+func transitionPayload(t *events.Transition) *models.WebhookPayload {
+	var payload *models.WebhookPayload
+	var subject string
+	switch {
+	case t.Kind == events.TransitionBuildFinished && t.Build != nil:
+		payload, subject = buildPayload(t.Build), t.Build.ID
+	case t.Kind == events.TransitionDeploymentStatus && t.Deployment != nil:
+		payload, subject = deploymentPayload(t.Deployment, t.PreviousStatus), t.Deployment.ID
+	case t.Kind == events.TransitionNodeHealth && t.Node != nil && t.HealthEvent != nil:
+		payload, subject = nodePayload(t.Node, t.HealthEvent), t.Node.ID
+	}
+	if payload == nil {
+		return nil
+	}
+	payload.OccurredAt = t.Time
+	// The bus delivers the same transition again after a failure, so the
+	// ID derived from it is too
+	payload.ID = uuid.NewSHA1(uuid.NameSpaceURL,
+		[]byte(fmt.Sprintf("narvana:%s:%s:%d", payload.Event, subject, t.Time.UnixNano()))).String()
+	return payload
+}
+
+func buildPayload(job *models.BuildJob) *models.WebhookPayload {
+	payload := &models.WebhookPayload{
+		AppID:        job.AppID,
+		ServiceName:  job.ServiceName,
+		DeploymentID: job.DeploymentID,
+		BuildID:      job.ID,
+		GitRef:       job.GitRef,
+		Status:       string(job.Status),
+	}
+	switch job.Status {
+	case models.BuildStatusSucceeded:
+		payload.Event = models.WebhookBuildSucceeded
+		payload.Message = "Build finished successfully."
+	case models.BuildStatusFailed:
+		payload.Event = models.WebhookBuildFailed
+		payload.Message = "Build failed."
+		if f := job.Failure; f != nil {
+			payload.Failure = f
+			payload.Message = f.Summary
+		}
+	default:
+		return nil
+	}
+	return payload
+}
+
+func deploymentPayload(deployment *models.Deployment, previous models.DeploymentStatus) *models.WebhookPayload {
+	// Cron runs start and exit on every schedule; their history has the outcome
+	if deployment.Status == previous || deployment.IsCronRun() {
+		return nil
+	}
+	payload := &models.WebhookPayload{
+		AppID:          deployment.AppID,
+		ServiceName:    deployment.ServiceName,
+		DeploymentID:   deployment.ID,
+		Version:        deployment.Version,
+		Status:         string(deployment.Status),
+		PreviousStatus: string(previous),
+		GitRef:         deployment.GitRef,
+		GitCommit:      deployment.GitCommit,
+	}
+	switch deployment.Status {
+	case models.DeploymentStatusStarting:
+		payload.Event = models.WebhookDeploymentStarted
+		payload.Message = fmt.Sprintf("Version %d is starting.", deployment.Version)
+	case models.DeploymentStatusRunning:
+		payload.Event = models.WebhookDeploymentSucceeded
+		payload.Message = fmt.Sprintf("Version %d is running.", deployment.Version)
+	case models.DeploymentStatusFailed:
+		payload.Event = models.WebhookDeploymentFailed
+		payload.Message = fmt.Sprintf("Version %d failed to start.", deployment.Version)
+	default:
+		return nil
+	}
+	return payload
+}
+
+func nodePayload(node *models.Node, health *models.NodeHealthEvent) *models.WebhookPayload {
+	payload := &models.WebhookPayload{
+		Event:    models.WebhookNodeUnhealthy,
+		NodeID:   node.ID,
+		NodeName: node.Hostname,
+		Message:  health.Reason,
+	}
+	if health.Healthy {
+		payload.Event = models.WebhookNodeRecovered
+	}
+	return payload
+}
@@ -0,0 +1,258 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/events"
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// memStore is an in-memory store providing the stores webhooks use. App
+// app-1 is owned by owner and app-2 belongs to org-1, of which member is a
+// member; admin is an instance admin.
+type memStore struct {
+	store.Store
+	webhooks *memWebhooks
+}
+
+func newMemStore(webhooks ...*models.Webhook) *memStore {
+	return &memStore{webhooks: &memWebhooks{webhooks: webhooks}}
+}
+
+func (s *memStore) Webhooks() store.WebhookStore { return s.webhooks }
+func (s *memStore) Apps() store.AppStore         { return memApps{} }
+func (s *memStore) Orgs() store.OrgStore         { return memOrgs{} }
+func (s *memStore) Users() store.UserStore       { return memUsers{} }
+
+type memApps struct{ store.AppStore }
+
+func (memApps) Get(ctx context.Context, id string) (*models.App, error) {
+	switch id {
+	case "app-1":
+		return &models.App{ID: id, Name: "shop", OwnerID: "owner"}, nil
+	case "app-2":
+		return &models.App{ID: id, Name: "blog", OwnerID: "someone", OrgID: "org-1"}, nil
+	}
+	return nil, nil
+}
+
+type memOrgs struct{ store.OrgStore }
+
+func (memOrgs) GetMemberRole(ctx context.Context, orgID, userID string) (models.Role, error) {
+	if orgID == "org-1" && userID == "member" {
+		return models.RoleMember, nil
+	}
+	return "", nil
+}
+
+type memUsers struct{ store.UserStore }
+
+func (memUsers) GetByID(ctx context.Context, id string) (*store.User, error) {
+	if id == "admin" {
+		return &store.User{ID: id, Role: store.RoleOwner}, nil
+	}
+	return &store.User{ID: id, Role: store.RoleMember}, nil
+}
+
+type memWebhooks struct {
+	store.WebhookStore
+	webhooks   []*models.Webhook
+	deliveries []*models.WebhookDelivery
+	lastError  map[string]string
+}
+
+func (m *memWebhooks) ListEnabled(ctx context.Context) ([]*models.Webhook, error) {
+	var result []*models.Webhook
+	for _, w := range m.webhooks {
+		if w.Enabled {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (m *memWebhooks) Get(ctx context.Context, id string) (*models.Webhook, error) {
+	for _, w := range m.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memWebhooks) RecordResult(ctx context.Context, id string, at time.Time, errMsg string) error {
+	if m.lastError == nil {
+		m.lastError = map[string]string{}
+	}
+	m.lastError[id] = errMsg
+	return nil
+}
+
+func (m *memWebhooks) EnqueueDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	d.ID = "d" + strconv.Itoa(len(m.deliveries))
+	d.Status = models.WebhookDeliveryPending
+	d.NextAttemptAt = time.Now()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memWebhooks) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	var due []*models.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == models.WebhookDeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memWebhooks) UpdateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	return nil
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook models.Webhook
+		ok      bool
+	}{
+		{"valid", models.Webhook{Name: "deploys", URL: "https://ci.example.com/narvana"}, true},
+		{"no name", models.Webhook{URL: "https://example.com"}, false},
+		{"relative url", models.Webhook{Name: "deploys", URL: "/hook"}, false},
+		{"other scheme", models.Webhook{Name: "deploys", URL: "ftp://example.com"}, false},
+		{"unknown event", models.Webhook{Name: "deploys", URL: "https://example.com", Events: []models.WebhookEvent{"deployment.running"}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.webhook.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestDispatcherQueuesForUsersWithAccess(t *testing.T) {
+	st := newMemStore(
+		&models.Webhook{ID: "owner", UserID: "owner", Enabled: true},
+		&models.Webhook{ID: "member", UserID: "member", Enabled: true},
+		&models.Webhook{ID: "admin", UserID: "admin", Enabled: true},
+		&models.Webhook{ID: "failures", UserID: "owner", Enabled: true, Events: []models.WebhookEvent{models.WebhookDeploymentFailed}},
+		&models.Webhook{ID: "other-app", UserID: "owner", Enabled: true, AppID: "app-3"},
+		&models.Webhook{ID: "off", UserID: "owner"},
+	)
+	d := NewDispatcher(st, nil)
+	ctx := context.Background()
+
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	transitions := []*events.Transition{
+		{Kind: events.TransitionDeploymentStatus, Time: at,
+			Deployment:     &models.Deployment{ID: "d1", AppID: "app-1", ServiceName: "api", Version: 3, Status: models.DeploymentStatusRunning},
+			PreviousStatus: models.DeploymentStatusStarting},
+		// Not a webhook event
+		{Kind: events.TransitionDeploymentStatus, Time: at,
+			Deployment:     &models.Deployment{ID: "d1", AppID: "app-1", Status: models.DeploymentStatusStopping},
+			PreviousStatus: models.DeploymentStatusRunning},
+		{Kind: events.TransitionBuildFinished, Time: at,
+			Build: &models.BuildJob{ID: "b1", AppID: "app-2", Status: models.BuildStatusFailed}},
+		{Kind: events.TransitionNodeHealth, Time: at,
+			Node: &models.Node{ID: "n1", Hostname: "worker-1"}, HealthEvent: &models.NodeHealthEvent{NodeID: "n1", Reason: "heartbeat lapsed"}},
+	}
+	for _, tr := range transitions {
+		if err := d.HandleTransition(ctx, tr); err != nil {
+			t.Fatalf("HandleTransition() = %v", err)
+		}
+	}
+
+	var got []string
+	for _, dl := range st.webhooks.deliveries {
+		got = append(got, dl.WebhookID+":"+string(dl.Payload.Event))
+	}
+	want := []string{"owner:deployment.succeeded", "member:build.failed", "admin:node.unhealthy"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("deliveries = %v, want %v", got, want)
+	}
+
+	p := st.webhooks.deliveries[0].Payload
+	if p.AppName != "shop" || p.Version != 3 || p.PreviousStatus != "starting" || !p.OccurredAt.Equal(at) || p.ID == "" {
+		t.Errorf("payload = %+v", p)
+	}
+	if n := st.webhooks.deliveries[2].Payload; n.NodeName != "worker-1" || n.Message != "heartbeat lapsed" {
+		t.Errorf("node payload = %+v", n)
+	}
+
+	// The bus delivers a transition again after a failure; the event ID
+	// stays the same so receivers can skip it
+	if err := d.HandleTransition(ctx, transitions[0]); err != nil {
+		t.Fatalf("HandleTransition() = %v", err)
+	}
+	if again := st.webhooks.deliveries[3].Payload.ID; again != p.ID {
+		t.Errorf("redelivered event ID = %s, want %s", again, p.ID)
+	}
+}
+
+func TestWorkerSignsRecordsAndRetries(t *testing.T) {
+	var gotSignature, gotEvent string
+	var gotBody []byte
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		gotSignature = r.Header.Get(hooks.HeaderSignature)
+		gotEvent = r.Header.Get(hooks.HeaderEvent)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	st := newMemStore(&models.Webhook{ID: "w1", UserID: "owner", Enabled: true, URL: srv.URL, Secret: "s3cret"})
+	payload := &models.WebhookPayload{ID: "e1", Event: models.WebhookDeploymentFailed, AppID: "app-1"}
+	if err := NewDispatcher(st, nil).Dispatch(context.Background(), payload); err != nil {
+		t.Fatalf("Dispatch() = %v", err)
+	}
+
+	w := NewWorker(st, NewSender(srv.Client()), notifications.DefaultWorkerConfig(), nil)
+
+	if n := w.ProcessDue(context.Background()); n != 0 {
+		t.Fatalf("first attempt delivered %d, want 0", n)
+	}
+	d := st.webhooks.deliveries[0]
+	if d.Status != models.WebhookDeliveryPending || d.Attempts != 1 || d.ResponseStatus != http.StatusServiceUnavailable || d.ResponseBody != "busy" {
+		t.Fatalf("after failure: %+v", d)
+	}
+	if !d.NextAttemptAt.After(time.Now()) {
+		t.Error("retry not backed off")
+	}
+	if st.webhooks.lastError["w1"] == "" {
+		t.Error("webhook error not recorded")
+	}
+
+	d.NextAttemptAt = time.Now()
+	if n := w.ProcessDue(context.Background()); n != 1 {
+		t.Fatalf("retry delivered %d, want 1", n)
+	}
+	if d.Status != models.WebhookDeliveryDelivered || d.ResponseStatus != http.StatusOK || d.ResponseBody != "ok" || d.LastError != "" {
+		t.Errorf("after delivery: %+v", d)
+	}
+	if gotEvent != string(models.WebhookDeploymentFailed) {
+		t.Errorf("event header = %q", gotEvent)
+	}
+	if gotSignature != hooks.Sign("s3cret", gotBody) {
+		t.Errorf("signature = %q, want %q", gotSignature, hooks.Sign("s3cret", gotBody))
+	}
+	var got models.WebhookPayload
+	if err := json.Unmarshal(gotBody, &got); err != nil || got.ID != "e1" || got.AppName != "shop" {
+		t.Errorf("payload = %s (%v)", gotBody, err)
+	}
+}
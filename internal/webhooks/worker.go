@@ -0,0 +1,175 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/hooks"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/notifications"
+	"github.com/narvanalabs/control-plane/internal/store"
+)
+
+// maxResponseBody is how much of an endpoint's response a delivery keeps.
+const maxResponseBody = 1024
+
+// Sender posts payloads to webhooks. Requests carry the same headers as app
+// hook calls, so receivers verify signatures the same way.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender that makes HTTP requests with client. A nil
+// client uses one with a 10 second timeout.
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{client: client}
+}
+
+// Send posts a delivery's payload to its webhook, recording the response and
+// how long it took on the delivery, and treats any non-2xx response as an
+// error.
+func (s *Sender) Send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) error {
+	delivery.ResponseStatus, delivery.ResponseBody, delivery.DurationMS = 0, "", 0
+
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(hooks.HeaderEvent, string(delivery.Payload.Event))
+	req.Header.Set(hooks.HeaderDelivery, delivery.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(hooks.HeaderSignature, hooks.Sign(webhook.Secret, body))
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = strings.ToValidUTF8(string(bytes.TrimSpace(snippet)), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Worker sends queued webhook deliveries and reschedules failed ones. It is
+// configured like the notification worker.
+type Worker struct {
+	store  store.Store
+	sender *Sender
+	config notifications.WorkerConfig
+	logger *slog.Logger
+}
+
+// NewWorker creates a webhook delivery worker.
+func NewWorker(st store.Store, sender *Sender, cfg notifications.WorkerConfig, logger *slog.Logger) *Worker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Worker{
+		store:  st,
+		sender: sender,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Run processes due deliveries every poll interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.ProcessDue(ctx)
+		}
+	}
+}
+
+// ProcessDue claims and sends one batch of due deliveries and returns how
+// many were delivered.
+func (w *Worker) ProcessDue(ctx context.Context) int {
+	deliveries, err := w.store.Webhooks().ClaimDueDeliveries(ctx, w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		w.logger.Error("failed to claim webhook deliveries", "error", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, d := range deliveries {
+		if w.deliver(ctx, d) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliver sends a single delivery and records the outcome on the delivery and its webhook.
+func (w *Worker) deliver(ctx context.Context, d *models.WebhookDelivery) bool {
+	webhook, err := w.store.Webhooks().Get(ctx, d.WebhookID)
+	if err != nil {
+		w.logger.Error("failed to load webhook", "error", err, "webhook_id", d.WebhookID)
+		return false
+	}
+
+	now := time.Now()
+	d.Attempts++
+	switch {
+	case webhook == nil || !webhook.Enabled:
+		// The webhook was disabled after the event was queued; drop it.
+		d.Status = models.WebhookDeliveryFailed
+		d.LastError = "webhook disabled"
+	default:
+		sendErr := w.sender.Send(ctx, webhook, d)
+		if sendErr == nil {
+			d.Status = models.WebhookDeliveryDelivered
+			d.LastError = ""
+			d.DeliveredAt = &now
+		} else {
+			d.LastError = sendErr.Error()
+			if d.Attempts >= w.config.MaxAttempts {
+				d.Status = models.WebhookDeliveryFailed
+			} else {
+				d.NextAttemptAt = now.Add(notifications.Backoff(d.Attempts, w.config.BaseBackoff, w.config.MaxBackoff))
+			}
+			w.logger.Warn("webhook delivery failed",
+				"delivery_id", d.ID,
+				"webhook_id", webhook.ID,
+				"attempt", d.Attempts,
+				"error", sendErr,
+			)
+		}
+		if err := w.store.Webhooks().RecordResult(ctx, webhook.ID, now, d.LastError); err != nil {
+			w.logger.Error("failed to record webhook result", "error", err, "webhook_id", webhook.ID)
+		}
+	}
+
+	if err := w.store.Webhooks().UpdateDelivery(ctx, d); err != nil {
+		w.logger.Error("failed to update webhook delivery", "error", err, "delivery_id", d.ID)
+	}
+	return d.Status == models.WebhookDeliveryDelivered
+}
@@ -0,0 +1,21 @@
+-- Migration: 025_announcements.sql
+-- Add announcements table for admin-managed banners shown across the web UI
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message TEXT NOT NULL,
+    level VARCHAR(20) NOT NULL DEFAULT 'info' CHECK (level IN ('info', 'warning', 'critical')),
+    audience VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (audience IN ('all', 'owners', 'members')),
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT announcements_window_check CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+-- Indexes for announcements
+CREATE INDEX IF NOT EXISTS idx_announcements_window ON announcements(starts_at, ends_at);
+
+-- Announcements previously lived in the settings table as a single banner
+DELETE FROM settings WHERE key IN ('announcement_message', 'announcement_level');
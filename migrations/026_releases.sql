@@ -0,0 +1,21 @@
+-- Migration: 026_releases.sql
+-- Add releases table linking release notes to deployments for in-product changelogs
+
+CREATE TABLE IF NOT EXISTS releases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    deployment_id UUID NOT NULL UNIQUE REFERENCES deployments(id) ON DELETE CASCADE,
+    service_name VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    from_commit VARCHAR(40),
+    to_commit VARCHAR(40),
+    commits JSONB NOT NULL DEFAULT '[]',
+    notes TEXT,
+    source VARCHAR(20) NOT NULL DEFAULT 'generated' CHECK (source IN ('generated', 'uploaded')),
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Indexes for releases
+CREATE INDEX IF NOT EXISTS idx_releases_app_id ON releases(app_id, created_at DESC);
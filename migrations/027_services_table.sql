@@ -0,0 +1,60 @@
+-- Migration: 027_services_table.sql
+-- Move services out of the apps.services JSONB column into their own table so
+-- they can be queried individually and referenced from deployments
+
+CREATE TABLE IF NOT EXISTS services (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(63) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    config JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT services_app_name_unique UNIQUE (app_id, name)
+);
+
+-- Indexes for services
+CREATE INDEX IF NOT EXISTS idx_services_app_id ON services(app_id, position);
+
+CREATE TRIGGER update_services_updated_at
+    BEFORE UPDATE ON services
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Copy existing services, preserving their order within each app
+INSERT INTO services (app_id, name, position, config)
+SELECT a.id, svc.value->>'name', svc.ordinality - 1, svc.value
+FROM apps a
+CROSS JOIN LATERAL jsonb_array_elements(a.services) WITH ORDINALITY AS svc(value, ordinality)
+WHERE jsonb_typeof(a.services) = 'array' AND svc.value->>'name' IS NOT NULL
+ON CONFLICT (app_id, name) DO NOTHING;
+
+-- Link deployments to their service. Deployments outlive removed services,
+-- so the reference is cleared rather than cascaded.
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS service_id UUID REFERENCES services(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_deployments_service_id ON deployments(service_id);
+
+UPDATE deployments d
+SET service_id = s.id
+FROM services s
+WHERE s.app_id = d.app_id AND s.name = d.service_name AND d.service_id IS NULL;
+
+CREATE OR REPLACE FUNCTION set_deployment_service_id()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.service_id IS NULL THEN
+        SELECT id INTO NEW.service_id FROM services
+        WHERE app_id = NEW.app_id AND name = NEW.service_name;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER set_deployments_service_id
+    BEFORE INSERT ON deployments
+    FOR EACH ROW
+    EXECUTE FUNCTION set_deployment_service_id();
+
+-- apps.services is still written alongside the services table so older
+-- releases keep working after a rollback, but it is no longer read.
+COMMENT ON COLUMN apps.services IS 'Deprecated: read from the services table. Still written for rollback compatibility.';
@@ -0,0 +1,15 @@
+-- Migration: 028_build_deduplication.sql
+-- Key builds by a content hash of their inputs so identical builds can reuse a
+-- previous artifact instead of rebuilding
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS artifact TEXT;
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS deduplicated_from UUID REFERENCES builds(id) ON DELETE SET NULL;
+
+-- Lookup of reusable builds: succeeded builds with an artifact, newest first
+CREATE INDEX IF NOT EXISTS idx_builds_content_hash ON builds(content_hash, finished_at DESC)
+    WHERE status = 'succeeded' AND artifact IS NOT NULL;
+
+COMMENT ON COLUMN builds.content_hash IS 'SHA-256 of the source tree hash, strategy, build type, config and flake inputs';
+COMMENT ON COLUMN builds.artifact IS 'Build output (store path or image tag), recorded so later builds can reuse it';
+COMMENT ON COLUMN builds.deduplicated_from IS 'Build whose artifact was reused instead of rebuilding';
@@ -0,0 +1,13 @@
+-- Migration: 029_build_reproducibility.sql
+-- Record the outcome of double-build verification for pure-nix builds
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS reproducibility VARCHAR(20)
+    CHECK (reproducibility IN ('verified', 'mismatch', 'error'));
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS output_hash TEXT;
+
+-- Recent non-reproducible builds are surfaced as alerts
+CREATE INDEX IF NOT EXISTS idx_builds_reproducibility_mismatch ON builds(finished_at DESC)
+    WHERE reproducibility = 'mismatch';
+
+COMMENT ON COLUMN builds.reproducibility IS 'Result of rebuilding the derivation and comparing outputs; NULL when not verified';
+COMMENT ON COLUMN builds.output_hash IS 'NAR hash of the build output';
@@ -0,0 +1,19 @@
+-- Migration: 030_egress_violations.sql
+-- Outbound connections blocked by service egress policies, as reported by node agents
+
+CREATE TABLE IF NOT EXISTS egress_violations (
+    deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    destination VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 0,
+    protocol VARCHAR(10) NOT NULL DEFAULT '',
+    count BIGINT NOT NULL DEFAULT 0,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (deployment_id, destination, port, protocol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_egress_violations_service ON egress_violations(app_id, service_name, last_seen_at DESC);
+
+COMMENT ON COLUMN egress_violations.count IS 'Cumulative blocked connections for the deployment, as last reported by the agent';
@@ -0,0 +1,36 @@
+-- Migration: 031_deploy_freeze_windows.sql
+-- Org-wide deploy freeze windows and an audit trail of deploys that overrode them
+
+CREATE TABLE IF NOT EXISTS deploy_freeze_windows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('recurring', 'range')),
+    start_day VARCHAR(10) NOT NULL DEFAULT '',
+    start_time VARCHAR(5) NOT NULL DEFAULT '',
+    end_day VARCHAR(10) NOT NULL DEFAULT '',
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deploy_freeze_windows_org ON deploy_freeze_windows(org_id);
+
+CREATE TABLE IF NOT EXISTS deploy_freeze_overrides (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    window_id UUID REFERENCES deploy_freeze_windows(id) ON DELETE SET NULL,
+    deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    user_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deploy_freeze_overrides_org ON deploy_freeze_overrides(org_id, created_at DESC);
+
+COMMENT ON COLUMN deploy_freeze_windows.timezone IS 'IANA time zone for recurring windows; UTC when empty';
@@ -0,0 +1,68 @@
+-- Migration: 032_scim_provisioning.sql
+-- SCIM 2.0 provisioning: per-org bearer tokens, provisioned users and groups,
+-- IdP group to org role mappings and a sync event log
+
+CREATE TABLE IF NOT EXISTS scim_tokens (
+    org_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS scim_users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL DEFAULT '',
+    user_name TEXT NOT NULL,
+    display_name TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    provisioned BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, user_name),
+    UNIQUE (org_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS scim_groups (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL DEFAULT '',
+    display_name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, display_name)
+);
+
+CREATE TABLE IF NOT EXISTS scim_group_members (
+    group_id UUID NOT NULL REFERENCES scim_groups(id) ON DELETE CASCADE,
+    scim_user_id UUID NOT NULL REFERENCES scim_users(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, scim_user_id)
+);
+
+CREATE TABLE IF NOT EXISTS scim_group_mappings (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    group_name TEXT NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, group_name)
+);
+
+CREATE TABLE IF NOT EXISTS scim_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    operation VARCHAR(20) NOT NULL,
+    resource_type VARCHAR(20) NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('success', 'conflict', 'error')),
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_events_org ON scim_events(org_id, created_at DESC);
+
+COMMENT ON COLUMN scim_users.provisioned IS 'True when the account was created by SCIM rather than linked to an existing user';
+COMMENT ON COLUMN scim_group_mappings.group_name IS 'IdP group display name; members receive this org role';
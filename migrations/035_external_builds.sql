@@ -0,0 +1,6 @@
+-- Migration: 035_external_builds.sql
+-- Builds produced by external CI systems and submitted for deployment only
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS external_metadata JSONB;
+
+COMMENT ON COLUMN builds.external_metadata IS 'CI metadata for builds submitted with an externally built artifact; NULL for builds run by Narvana';
@@ -0,0 +1,6 @@
+-- Migration: 036_deployment_rollbacks.sql
+-- Link rollback deployments to the deployment whose artifact they redeploy
+
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS rollback_of UUID REFERENCES deployments(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_deployments_rollback_of ON deployments(rollback_of) WHERE rollback_of IS NOT NULL;
@@ -0,0 +1,46 @@
+-- Migration: 037_promotions.sql
+-- Health-gated promotion policies between apps (e.g. staging to production)
+-- and the evaluation record for each source deployment
+
+CREATE TABLE IF NOT EXISTS promotion_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    target_app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    target_service_name VARCHAR(63) NOT NULL,
+    window_minutes INTEGER NOT NULL CHECK (window_minutes > 0),
+    max_error_rate DOUBLE PRECISION NOT NULL CHECK (max_error_rate >= 0 AND max_error_rate <= 1),
+    require_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (app_id, service_name, target_app_id, target_service_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_policies_app ON promotion_policies(app_id);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES promotion_policies(id) ON DELETE CASCADE,
+    source_deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    target_deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'evaluating',
+    window_minutes INTEGER NOT NULL,
+    max_error_rate DOUBLE PRECISION NOT NULL,
+    window_ends_at TIMESTAMPTZ NOT NULL,
+    error_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    log_lines INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    decided_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (policy_id, source_deployment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_open
+    ON promotions(policy_id) WHERE status IN ('evaluating', 'awaiting_approval', 'blocked');
+CREATE INDEX IF NOT EXISTS idx_promotions_target ON promotions(target_deployment_id);
+
+COMMENT ON COLUMN promotions.window_minutes IS 'Evaluation window copied from the policy when evaluation started';
+COMMENT ON COLUMN promotions.error_rate IS 'Share of runtime log lines at error level observed during the window';
@@ -0,0 +1,32 @@
+-- Migration: 038_ssh_keys.sql
+-- User SSH public keys and the audit trail of SSH sessions brokered to nodes
+
+CREATE TABLE IF NOT EXISTS ssh_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    public_key TEXT NOT NULL,
+    fingerprint VARCHAR(100) NOT NULL UNIQUE,
+    key_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ssh_keys_user ON ssh_keys(user_id, created_at DESC);
+
+-- Sessions outlive the keys and nodes they used, so they are not foreign keys
+CREATE TABLE IF NOT EXISTS ssh_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    key_fingerprint VARCHAR(100) NOT NULL,
+    remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ,
+    bytes_in BIGINT NOT NULL DEFAULT 0,
+    bytes_out BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_ssh_sessions_started ON ssh_sessions(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ssh_sessions_node ON ssh_sessions(node_id, started_at DESC);
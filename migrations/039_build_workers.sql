@@ -0,0 +1,28 @@
+-- Migration: 039_build_workers.sql
+-- Registry of running build workers and leases on the queue jobs they claim
+
+CREATE TABLE IF NOT EXISTS build_workers (
+    id VARCHAR(255) PRIMARY KEY,
+    hostname VARCHAR(255) NOT NULL DEFAULT '',
+    version VARCHAR(50) NOT NULL DEFAULT '',
+    concurrency INTEGER NOT NULL DEFAULT 0,
+    active_jobs INTEGER NOT NULL DEFAULT 0,
+    lease_seconds INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    stopped_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_build_workers_heartbeat ON build_workers(last_heartbeat_at);
+
+-- A claimed job belongs to its worker until the lease expires; workers renew
+-- leases on every heartbeat, so expired leases mark jobs of dead workers
+ALTER TABLE build_queue ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255);
+ALTER TABLE build_queue ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+
+-- Jobs claimed before leases existed have no owner to renew them
+UPDATE build_queue SET lease_expires_at = started_at
+WHERE status = 'processing' AND lease_expires_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_build_queue_claimed_by ON build_queue(claimed_by) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_build_queue_lease ON build_queue(lease_expires_at) WHERE status = 'processing';
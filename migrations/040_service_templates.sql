@@ -0,0 +1,16 @@
+-- Migration: 040_service_templates.sql
+-- Parametrized services instantiated several times within an app
+
+CREATE TABLE IF NOT EXISTS service_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(63) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    variables JSONB NOT NULL DEFAULT '[]',
+    service JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (app_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_templates_app ON service_templates(app_id);
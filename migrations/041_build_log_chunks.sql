@@ -0,0 +1,10 @@
+-- Migration: 041_build_log_chunks.sql
+-- Build output persisted in chunks while the build runs, so clients can tail it
+
+CREATE TABLE IF NOT EXISTS build_log_chunks (
+    build_id UUID NOT NULL REFERENCES builds(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (build_id, seq)
+);
@@ -0,0 +1,18 @@
+-- Migration: 042_build_snapshots.sql
+-- Working directories of failed builds kept on worker hosts for debugging
+
+CREATE TABLE IF NOT EXISTS build_snapshots (
+    build_id UUID PRIMARY KEY REFERENCES builds(id) ON DELETE CASCADE,
+    worker_id TEXT NOT NULL DEFAULT '',
+    hostname TEXT NOT NULL,
+    path TEXT NOT NULL,
+    image TEXT NOT NULL,
+    shell TEXT NOT NULL DEFAULT '',
+    work_dir TEXT NOT NULL,
+    flake_ref TEXT NOT NULL DEFAULT '',
+    env JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_build_snapshots_hostname_expires_at ON build_snapshots (hostname, expires_at);
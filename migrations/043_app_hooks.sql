@@ -0,0 +1,41 @@
+-- Migration: 043_app_hooks.sql
+-- Per-app lifecycle hooks calling a URL or a hook service of the app, and the
+-- queue of pending calls
+
+CREATE TABLE IF NOT EXISTS app_hooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    events JSONB NOT NULL DEFAULT '[]',
+    url TEXT NOT NULL DEFAULT '',
+    service_name VARCHAR(63) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL DEFAULT '',
+    last_delivery_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_hooks_app_id ON app_hooks(app_id);
+
+COMMENT ON COLUMN app_hooks.events IS 'JSON array of subscribed events; an empty array subscribes to all events';
+COMMENT ON COLUMN app_hooks.service_name IS 'Hook service of the app called instead of url';
+
+CREATE TABLE IF NOT EXISTS app_hook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hook_id UUID NOT NULL REFERENCES app_hooks(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_hook_deliveries_due
+    ON app_hook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_app_hook_deliveries_hook
+    ON app_hook_deliveries(hook_id, created_at DESC);
@@ -0,0 +1,37 @@
+-- Migration: 044_cdn_integrations.sql
+-- Per-app CDN cache purge integrations (Cloudflare, Fastly, BunnyCDN) and
+-- the log of purges with their results
+
+CREATE TABLE IF NOT EXISTS cdn_integrations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    config JSONB NOT NULL DEFAULT '{}',
+    services JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cdn_integrations_app_id ON cdn_integrations(app_id);
+
+COMMENT ON COLUMN cdn_integrations.services IS 'JSON array of services whose successful deploys purge the cache; empty for manual purges only';
+
+CREATE TABLE IF NOT EXISTS cdn_purges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    integration_id UUID NOT NULL REFERENCES cdn_integrations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    deployment_id UUID,
+    service_name VARCHAR(63) NOT NULL DEFAULT '',
+    requested_by TEXT NOT NULL DEFAULT '',
+    urls JSONB NOT NULL DEFAULT '[]',
+    succeeded BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cdn_purges_app_created ON cdn_purges(app_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: 045_app_env_vars.sql
+-- App-level non-secret environment variables inherited by every service of
+-- the app; app secrets and service env vars override them
+
+ALTER TABLE apps ADD COLUMN IF NOT EXISTS env_vars JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,15 @@
+-- Migration: 046_build_attestations.sql
+-- SLSA provenance attestations of successful builds, signed by the worker
+-- that ran the build
+
+CREATE TABLE IF NOT EXISTS build_attestations (
+    build_id UUID PRIMARY KEY REFERENCES builds(id) ON DELETE CASCADE,
+    worker_id TEXT NOT NULL DEFAULT '',
+    key_id VARCHAR(64) NOT NULL,
+    public_key TEXT NOT NULL,
+    envelope JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN build_attestations.envelope IS 'DSSE envelope holding the in-toto statement with the SLSA provenance predicate';
+COMMENT ON COLUMN build_attestations.public_key IS 'Base64-encoded ed25519 public key of the signing worker';
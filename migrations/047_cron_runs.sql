@@ -0,0 +1,24 @@
+-- Migration: 047_cron_runs.sql
+-- Run history of cron services; each run is a one-off deployment of the
+-- service's artifact executing its command
+
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
+    trigger VARCHAR(20) NOT NULL,
+    triggered_by TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cron_runs_service ON cron_runs(app_id, service_name, scheduled_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cron_runs_deployment_id ON cron_runs(deployment_id);
+CREATE INDEX IF NOT EXISTS idx_cron_runs_active ON cron_runs(status) WHERE status IN ('pending', 'running');
+
+COMMENT ON COLUMN cron_runs.scheduled_at IS 'Time the schedule fired, or the request time of a manual run';
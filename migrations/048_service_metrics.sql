@@ -0,0 +1,24 @@
+-- Migration: 048_service_metrics.sql
+-- Per-minute rollups of the container resource usage node agents report for
+-- each deployment, served as time series by the service metrics API
+
+CREATE TABLE IF NOT EXISTS service_metrics (
+    deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    samples INTEGER NOT NULL DEFAULT 0,
+    cpu_percent_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cpu_percent_max DOUBLE PRECISION NOT NULL DEFAULT 0,
+    memory_bytes_sum BIGINT NOT NULL DEFAULT 0,
+    memory_bytes_max BIGINT NOT NULL DEFAULT 0,
+    network_rx_bytes BIGINT NOT NULL DEFAULT 0,
+    network_tx_bytes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (deployment_id, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_metrics_service ON service_metrics(app_id, service_name, bucket);
+CREATE INDEX IF NOT EXISTS idx_service_metrics_bucket ON service_metrics(bucket);
+
+COMMENT ON COLUMN service_metrics.network_rx_bytes IS 'Highest cumulative received bytes counter reported in the bucket';
+COMMENT ON COLUMN service_metrics.network_tx_bytes IS 'Highest cumulative transmitted bytes counter reported in the bucket';
@@ -0,0 +1,40 @@
+-- Migration: 049_admission_policies.sql
+-- Org admission policies evaluated before builds and deployments, and the
+-- violations they recorded
+
+CREATE TABLE IF NOT EXISTS admission_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    stage VARCHAR(20) NOT NULL,
+    match_expression TEXT NOT NULL DEFAULT '',
+    expression TEXT NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    enforcement VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS admission_violations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    policy_id UUID REFERENCES admission_policies(id) ON DELETE SET NULL,
+    policy_name VARCHAR(100) NOT NULL,
+    stage VARCHAR(20) NOT NULL,
+    enforcement VARCHAR(20) NOT NULL,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL DEFAULT '',
+    deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
+    user_id TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admission_violations_org ON admission_violations(org_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admission_violations_app ON admission_violations(app_id, created_at DESC);
+
+COMMENT ON COLUMN admission_violations.deployment_id IS 'Unset for deploys rejected before their deployment was created';
@@ -0,0 +1,28 @@
+-- Migration: 050_audit_log.sql
+-- Audit log of mutating API requests: the actor, the resource, the outcome
+-- and the fields that changed
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id TEXT NOT NULL DEFAULT '',
+    user_email TEXT NOT NULL DEFAULT '',
+    api_key_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    resource_type VARCHAR(63) NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    app_id TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    request_id TEXT NOT NULL DEFAULT '',
+    remote_addr TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_app ON audit_log(app_id, created_at DESC) WHERE app_id <> '';
+
+-- Entries are not tied to the rows they describe, so they outlive deleted apps
+-- and users until the retention period removes them
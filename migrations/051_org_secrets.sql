@@ -0,0 +1,27 @@
+-- Migration: 051_org_secrets.sql
+-- Org-level secrets shared into some or all of an org's apps, and the
+-- services each was last injected into
+
+CREATE TABLE IF NOT EXISTS org_secrets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    encrypted_value BYTEA NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    scope VARCHAR(20) NOT NULL DEFAULT 'selected' CHECK (scope IN ('all', 'selected')),
+    app_ids TEXT[] NOT NULL DEFAULT '{}',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS org_secret_usages (
+    secret_id UUID NOT NULL REFERENCES org_secrets(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (secret_id, app_id, service_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_secret_usages_app ON org_secret_usages(app_id);
@@ -0,0 +1,18 @@
+-- Migration: 052_org_roles.sql
+-- Org roles beyond owner and member, and invitations to join an organization.
+-- The legacy member role is kept and grants the same access as developer.
+
+ALTER TABLE org_memberships
+DROP CONSTRAINT IF EXISTS org_memberships_role_check;
+
+ALTER TABLE org_memberships
+ADD CONSTRAINT org_memberships_role_check
+CHECK (role IN ('owner', 'admin', 'developer', 'viewer', 'member'));
+
+-- An invitation with an org adds the invitee to it with org_role on acceptance
+ALTER TABLE invitations
+ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS org_role VARCHAR(20)
+    CHECK (org_role IN ('owner', 'admin', 'developer', 'viewer', 'member'));
+
+CREATE INDEX IF NOT EXISTS idx_invitations_org_id ON invitations(org_id);
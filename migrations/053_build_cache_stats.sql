@@ -0,0 +1,5 @@
+-- Migration: 053_build_cache_stats.sql
+-- Records how much of each build was pulled from the Attic binary cache:
+-- the cache used, store paths substituted (hits) and derivations built (misses).
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS cache_stats JSONB;
@@ -0,0 +1,11 @@
+-- Migration: 054_node_providers.sql
+-- Record the backend that runs each node's deployments, the node pool it
+-- belongs to and the capabilities it reports. Agent nodes stay in the
+-- default pool; a Kubernetes cluster registers as a node of its own pool.
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'podman';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS pool TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS capabilities TEXT[] NOT NULL
+    DEFAULT '{nix-closures,oci-images,egress-policy,cron-runs,ingress}';
+
+CREATE INDEX IF NOT EXISTS idx_nodes_pool ON nodes(pool);
@@ -0,0 +1,19 @@
+-- Migration: 055_node_lifecycle.sql
+-- Track where each node is in its lifecycle and store the join tokens new
+-- nodes register with. Nodes that joined with a token wait for an admin's
+-- approval; existing nodes stay active.
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+
+CREATE INDEX IF NOT EXISTS idx_nodes_status ON nodes(status);
+
+CREATE TABLE IF NOT EXISTS node_join_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    description VARCHAR(100) NOT NULL DEFAULT '',
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ
+);
@@ -0,0 +1,22 @@
+-- Migration: 056_smoke_tests.sql
+-- Smoke test runs of deployments: the service's tests when the deployment
+-- started running, each test's result, and the rollback a failure started
+
+CREATE TABLE IF NOT EXISTS smoke_test_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    deployment_id UUID NOT NULL UNIQUE REFERENCES deployments(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    tests JSONB NOT NULL DEFAULT '[]',
+    results JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    rollback_deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_smoke_test_runs_active ON smoke_test_runs(status) WHERE status IN ('pending', 'running');
+
+COMMENT ON COLUMN smoke_test_runs.tests IS 'Service smoke tests as they were when the deployment started running';
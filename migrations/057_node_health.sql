@@ -0,0 +1,22 @@
+-- Migration: 057_node_health.sql
+-- Node health scores and load reported over the agent heartbeat stream, and
+-- a history of nodes becoming healthy or unhealthy
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS health_score INTEGER NOT NULL DEFAULT 100;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS load1 DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS load5 DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS load15 DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS node_health_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    node_id UUID NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    healthy BOOLEAN NOT NULL,
+    health_score INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_health_events_node ON node_health_events(node_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_node_health_events_created ON node_health_events(created_at DESC);
+
+COMMENT ON COLUMN nodes.health_score IS 'Headroom from 0 (saturated) to 100 (idle), computed from the last heartbeat';
@@ -0,0 +1,29 @@
+-- Migration: 058_load_tests.sql
+-- On-demand load tests of deployments, run from build workers, with their
+-- latency and error results kept for comparing releases
+
+CREATE TABLE IF NOT EXISTS load_tests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    worker_id VARCHAR(255) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    rps INTEGER NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    timeout_seconds INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    result JSONB,
+    run_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_load_tests_deployment ON load_tests(deployment_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_load_tests_service ON load_tests(app_id, service_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_load_tests_pending ON load_tests(created_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN load_tests.worker_id IS 'Build worker designated to run the test; empty lets any worker running load tests claim it';
@@ -0,0 +1,22 @@
+-- Migration: 059_archives.sql
+-- Cold storage of old deployments: their build output and logs are moved to
+-- object storage and the deployment and build rows are kept as stubs
+
+CREATE TABLE IF NOT EXISTS archives (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    deployment_id UUID NOT NULL UNIQUE REFERENCES deployments(id) ON DELETE CASCADE,
+    build_id UUID REFERENCES builds(id) ON DELETE SET NULL,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    object_key TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    original_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'archived',
+    error TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    restore_requested_at TIMESTAMPTZ,
+    restore_started_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_archives_restore_queue ON archives(restore_requested_at) WHERE status <> 'archived';
+
+COMMENT ON TABLE archives IS 'Deployments whose build output and logs were moved to object storage, restored when read';
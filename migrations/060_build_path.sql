@@ -0,0 +1,5 @@
+-- Migration: 060_build_path.sql
+-- Services of a monorepo build from a subdirectory of the repository; the
+-- directory is recorded on each build so retries build the same context
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS build_path TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Migration: 061_build_worker_podman.sql
+-- Build workers report whether they can reach their Podman service, so the
+-- doctor can flag workers that would fail every OCI build
+
+ALTER TABLE build_workers ADD COLUMN IF NOT EXISTS podman_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- Migration: 062_node_clock.sql
+-- Nodes record how far their clock is off and when their agent's TLS
+-- certificate expires, so both can be shown and alerted on
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS clock_skew_ms BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS certificate_expires_at TIMESTAMPTZ;
@@ -0,0 +1,24 @@
+-- Migration: 063_operations.sql
+-- Long-running operations such as node drains and cleanups, whose progress
+-- is polled or streamed after the endpoint starting them returned
+
+CREATE TABLE IF NOT EXISTS operations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    target_id TEXT NOT NULL DEFAULT '',
+    progress INTEGER NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    stage TEXT NOT NULL DEFAULT '',
+    errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    result JSONB,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_operations_running ON operations(updated_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_operations_created_at ON operations(created_at);
+
+COMMENT ON TABLE operations IS 'Long-running operations and their progress';
@@ -0,0 +1,15 @@
+-- Migration: 064_log_search.sql
+-- Adds the output stream of runtime log entries and a full-text index of
+-- log messages for searching an app's logs across its deployments.
+
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS stream VARCHAR(10) NOT NULL DEFAULT '';
+
+-- The 'simple' configuration indexes words as written, without stemming or
+-- stop words, which suits identifiers and error codes in log lines
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_logs_search ON logs USING GIN (search);
+
+-- Newest-first pages of a deployment's logs, ordered by the search cursor
+CREATE INDEX IF NOT EXISTS idx_logs_deployment_timestamp ON logs (deployment_id, timestamp DESC, id DESC);
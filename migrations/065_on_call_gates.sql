@@ -0,0 +1,43 @@
+-- Migration: 065_on_call_gates.sql
+-- On-call gates requiring deploys outside business hours to be made or
+-- acknowledged by whoever is on call in a PagerDuty or Opsgenie schedule
+
+CREATE TABLE IF NOT EXISTS on_call_gates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    match TEXT NOT NULL DEFAULT '',
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('pagerduty', 'opsgenie')),
+    schedule_id VARCHAR(255) NOT NULL,
+    api_token TEXT NOT NULL,
+    requirement VARCHAR(20) NOT NULL CHECK (requirement IN ('on_call', 'on_call_ack')),
+    business_days TEXT[] NOT NULL,
+    business_start VARCHAR(5) NOT NULL,
+    business_end VARCHAR(5) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_on_call_gates_org ON on_call_gates(org_id);
+
+CREATE TABLE IF NOT EXISTS on_call_acks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    gate_id UUID NOT NULL REFERENCES on_call_gates(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    requested_by TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_on_call_acks_org ON on_call_acks(org_id, created_at DESC);
+
+COMMENT ON COLUMN on_call_gates.match IS 'Admission expression over app selecting the apps the gate covers; empty covers all';
+COMMENT ON COLUMN on_call_gates.api_token IS 'PagerDuty or Opsgenie API key used to read the schedule';
@@ -0,0 +1,17 @@
+-- Migration: 066_service_api_specs.sql
+-- OpenAPI specs published by services, fetched into each org's API catalog
+
+CREATE TABLE IF NOT EXISTS service_api_specs (
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    operations JSONB NOT NULL DEFAULT '[]',
+    spec JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (app_id, service_name)
+);
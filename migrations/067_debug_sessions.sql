@@ -0,0 +1,24 @@
+-- Migration: 067_debug_sessions.sql
+-- Short-lived debug containers started next to running deployments, kept as
+-- an audit record of who debugged what
+
+CREATE TABLE IF NOT EXISTS debug_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    node_id VARCHAR(255) NOT NULL,
+    image TEXT NOT NULL,
+    command JSONB NOT NULL DEFAULT '[]',
+    ttl_seconds INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_debug_sessions_service ON debug_sessions(app_id, service_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_debug_sessions_active ON debug_sessions(expires_at) WHERE status IN ('pending', 'running', 'terminating');
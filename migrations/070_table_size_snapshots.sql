@@ -0,0 +1,15 @@
+-- Migration: 070_table_size_snapshots.sql
+-- Periodic snapshots of table sizes, from which /v1/server/db-health reports
+-- growth trends
+
+CREATE TABLE IF NOT EXISTS table_size_snapshots (
+    table_name TEXT NOT NULL,
+    total_bytes BIGINT NOT NULL,
+    live_rows BIGINT NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (table_name, recorded_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_table_size_snapshots_recorded ON table_size_snapshots(recorded_at);
+
+COMMENT ON TABLE table_size_snapshots IS 'Table sizes recorded by database maintenance to report growth trends';
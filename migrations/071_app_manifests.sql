@@ -0,0 +1,18 @@
+-- Migration: 071_app_manifests.sql
+-- The app spec (narvana.yaml) last applied to each app, against which the
+-- drift detector compares the app's live state
+
+CREATE TABLE IF NOT EXISTS app_manifests (
+    app_id UUID PRIMARY KEY REFERENCES apps(id) ON DELETE CASCADE,
+    spec TEXT NOT NULL,
+    prune BOOLEAN NOT NULL DEFAULT FALSE,
+    resources JSONB,
+    secret_digests JSONB NOT NULL DEFAULT '{}',
+    applied_by TEXT NOT NULL DEFAULT '',
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    drift JSONB NOT NULL DEFAULT '[]',
+    drift_checked_at TIMESTAMPTZ
+);
+
+COMMENT ON TABLE app_manifests IS 'Last applied app specs, the baseline for drift detection';
+COMMENT ON COLUMN app_manifests.secret_digests IS 'SHA-256 digests of the app''s secret values when the spec was applied';
@@ -0,0 +1,21 @@
+-- Migration: 072_idempotency_keys.sql
+-- Responses to mutating requests sent with an Idempotency-Key header,
+-- replayed when a client retries the request
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    headers JSONB NOT NULL DEFAULT '{}',
+    response BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Responses of requests sent with idempotency keys, replayed on retries';
+COMMENT ON COLUMN idempotency_keys.status IS 'Response status, 0 while the request is in progress';
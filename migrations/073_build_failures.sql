@@ -0,0 +1,5 @@
+-- Migration: 073_build_failures.sql
+-- Records why a failed build failed: a heuristic category, the last meaningful
+-- log lines and suggested fixes, shown on the build and in notifications.
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS failure JSONB;
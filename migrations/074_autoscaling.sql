@@ -0,0 +1,22 @@
+-- Migration: 074_autoscaling.sql
+-- Request counters reported with container resource usage, used to autoscale
+-- services on their request rate, and the history of replica count changes
+
+ALTER TABLE service_metrics ADD COLUMN IF NOT EXISTS requests BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN service_metrics.requests IS 'Highest cumulative HTTP requests counter reported in the bucket';
+
+CREATE TABLE IF NOT EXISTS scale_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    service_name VARCHAR(63) NOT NULL,
+    from_replicas INTEGER NOT NULL,
+    to_replicas INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scale_events_service ON scale_events(app_id, service_name, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scale_events_created ON scale_events(created_at);
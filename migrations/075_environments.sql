@@ -0,0 +1,26 @@
+-- Migration: 075_environments.sql
+-- Environments of an app's release pipeline, each backed by an app, and
+-- promotions requested into them by hand rather than started by a policy
+
+CREATE TABLE IF NOT EXISTS environments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    name VARCHAR(63) NOT NULL,
+    position INTEGER NOT NULL,
+    env_app_id UUID NOT NULL UNIQUE REFERENCES apps(id) ON DELETE CASCADE,
+    require_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (app_id, name),
+    UNIQUE (app_id, position)
+);
+
+COMMENT ON COLUMN environments.env_app_id IS 'App the environment''s deployments, secrets and domains belong to';
+
+ALTER TABLE promotions ALTER COLUMN policy_id DROP NOT NULL;
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS environment_id UUID REFERENCES environments(id) ON DELETE CASCADE;
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS requested_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_promotions_environment ON promotions(environment_id);
+
+COMMENT ON COLUMN promotions.requested_by IS 'User who asked for a promotion into an environment';
@@ -0,0 +1,20 @@
+-- Migration: 076_build_queue_priority.sql
+-- Queued builds are claimed by priority (user-triggered, then webhook, then
+-- retries) instead of strictly oldest first, and workers can cap how many
+-- builds of one app or one user run at once.
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS priority VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS triggered_by VARCHAR(255);
+
+-- The queue keeps the rank of the priority, higher first, and the app and
+-- user of each job so caps can be checked without decoding job_data
+ALTER TABLE build_queue ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE build_queue ADD COLUMN IF NOT EXISTS app_id VARCHAR(255);
+ALTER TABLE build_queue ADD COLUMN IF NOT EXISTS triggered_by VARCHAR(255);
+
+UPDATE build_queue SET app_id = job_data->>'app_id' WHERE app_id IS NULL;
+
+DROP INDEX IF EXISTS idx_build_queue_pending;
+CREATE INDEX IF NOT EXISTS idx_build_queue_pending ON build_queue(priority DESC, created_at ASC) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_build_queue_app_id ON build_queue(app_id) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_build_queue_triggered_by ON build_queue(triggered_by) WHERE status = 'processing';
@@ -0,0 +1,11 @@
+-- Migration: 077_canary_analysis.sql
+-- Error and latency counters reported with container resource usage, and the
+-- analysis of canary deployments against their baseline, which compares them
+
+ALTER TABLE service_metrics ADD COLUMN IF NOT EXISTS errors BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE service_metrics ADD COLUMN IF NOT EXISTS latency_ms_sum BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN service_metrics.errors IS 'Highest cumulative 5xx responses counter reported in the bucket';
+COMMENT ON COLUMN service_metrics.latency_ms_sum IS 'Highest cumulative response time counter, in milliseconds, reported in the bucket';
+
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS canary JSONB;
@@ -0,0 +1,24 @@
+-- Migration: 078_cancel_builds.sql
+-- Builds and deployments can be canceled. A running build is canceled by the
+-- worker running it, which polls for cancel requests.
+
+ALTER TABLE builds
+DROP CONSTRAINT IF EXISTS builds_status_check;
+
+ALTER TABLE builds
+ADD CONSTRAINT builds_status_check
+CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'canceled'));
+
+ALTER TABLE deployments
+DROP CONSTRAINT IF EXISTS deployments_status_check;
+
+ALTER TABLE deployments
+ADD CONSTRAINT deployments_status_check
+CHECK (status IN (
+    'pending', 'building', 'built', 'scheduled',
+    'starting', 'running', 'stopping', 'stopped', 'failed', 'canceled'
+));
+
+ALTER TABLE builds ADD COLUMN IF NOT EXISTS cancel_requested_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN builds.cancel_requested_at IS 'When someone asked to cancel the build while it was running; cleared when it is queued again';
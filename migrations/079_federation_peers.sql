@@ -0,0 +1,17 @@
+-- Migration: 079_federation_peers.sql
+-- Other Narvana instances registered as federation peers. Their apps, nodes
+-- and deployments are shown read-only in this instance's federation view.
+
+CREATE TABLE IF NOT EXISTS federation_peers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    web_url TEXT NOT NULL DEFAULT '',
+    api_key TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN federation_peers.api_key IS 'API key of an admin of the peer, sent when fetching its summary';
+COMMENT ON COLUMN federation_peers.web_url IS 'Web UI of the peer that rows link to; empty to link to url';
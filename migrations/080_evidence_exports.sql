@@ -0,0 +1,25 @@
+-- Migration: 080_evidence_exports.sql
+-- Evidence packages compiled for compliance audits: an app's deployments with
+-- their approvers, audit entries, build provenance and workload access over a
+-- date range, in an archive whose manifest is signed by the control plane.
+
+CREATE TABLE IF NOT EXISTS evidence_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    app_id UUID NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    since TIMESTAMPTZ NOT NULL,
+    until TIMESTAMPTZ NOT NULL,
+    operation_id TEXT NOT NULL DEFAULT '',
+    counts JSONB NOT NULL DEFAULT '{}',
+    sha256 TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    key_id TEXT NOT NULL,
+    archive BYTEA NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_evidence_exports_app ON evidence_exports(app_id, created_at DESC);
+
+COMMENT ON COLUMN evidence_exports.counts IS 'Number of records of each kind in the package, e.g. deployments and audit_entries';
+COMMENT ON COLUMN evidence_exports.key_id IS 'ID of the control plane key that signed the package manifest';
+COMMENT ON COLUMN evidence_exports.archive IS 'The gzipped tar archive of the package';
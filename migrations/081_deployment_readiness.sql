@@ -0,0 +1,9 @@
+-- Migration: 081_deployment_readiness.sql
+-- Readiness probes of deployments with a health check, which stay starting
+-- until the check passes, and why a deployment failed
+
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS health JSONB;
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS error TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN deployments.health IS 'Readiness probe results: consecutive failures and the latest probe output';
+COMMENT ON COLUMN deployments.error IS 'Why the deployment failed, e.g. the diagnostics of a health check that never passed';
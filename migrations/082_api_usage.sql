@@ -0,0 +1,21 @@
+-- Migration: 082_api_usage.sql
+-- Requests made to deprecated API routes or with deprecated API versions,
+-- counted per route, version and client so admins can see who still depends
+-- on them before they are sunset.
+
+CREATE TABLE IF NOT EXISTS api_usage (
+    route TEXT NOT NULL,
+    version TEXT NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '',
+    api_key_id TEXT NOT NULL DEFAULT '',
+    client TEXT NOT NULL DEFAULT '',
+    count BIGINT NOT NULL DEFAULT 0,
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (route, version, user_id, api_key_id, client)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_last_seen ON api_usage(last_seen DESC);
+
+COMMENT ON COLUMN api_usage.route IS 'Method and route pattern, e.g. POST /v1/nodes/heartbeat';
+COMMENT ON COLUMN api_usage.client IS 'Narvana-Client header of the requests, or their User-Agent';
@@ -0,0 +1,123 @@
+-- Migration: 083_events.sql
+-- Notify listeners on the narvana_events channel when apps, services,
+-- deployments and builds change, whichever process changed them, so the
+-- /v1/events stream can push status updates to the UI
+
+CREATE OR REPLACE FUNCTION notify_app_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    app apps%ROWTYPE;
+    event_action TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        app := OLD;
+        event_action := 'deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        app := NEW;
+        event_action := 'created';
+    ELSIF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+        app := NEW;
+        event_action := 'deleted';
+    ELSE
+        app := NEW;
+        event_action := 'updated';
+    END IF;
+    PERFORM pg_notify('narvana_events', json_build_object(
+        'kind', 'app',
+        'action', event_action,
+        'id', app.id,
+        'app_id', app.id,
+        'org_id', app.org_id,
+        'owner_id', app.owner_id,
+        'name', app.name,
+        'time', NOW()
+    )::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_apps_event
+    AFTER INSERT OR UPDATE OR DELETE ON apps
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_app_event();
+
+CREATE OR REPLACE FUNCTION notify_service_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    service services%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        service := OLD;
+    ELSE
+        service := NEW;
+    END IF;
+    PERFORM pg_notify('narvana_events', json_build_object(
+        'kind', 'service',
+        'action', CASE TG_OP WHEN 'INSERT' THEN 'created' WHEN 'UPDATE' THEN 'updated' ELSE 'deleted' END,
+        'id', service.id,
+        'app_id', service.app_id,
+        'name', service.name,
+        'service_name', service.name,
+        'time', NOW()
+    )::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_services_event
+    AFTER INSERT OR UPDATE OR DELETE ON services
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_service_event();
+
+-- Deployments and builds notify when created and when their status changes
+CREATE OR REPLACE FUNCTION notify_deployment_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+    PERFORM pg_notify('narvana_events', json_build_object(
+        'kind', 'deployment',
+        'action', CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END,
+        'id', NEW.id,
+        'app_id', NEW.app_id,
+        'service_name', NEW.service_name,
+        'deployment_id', NEW.id,
+        'status', NEW.status,
+        'previous_status', CASE TG_OP WHEN 'UPDATE' THEN OLD.status END,
+        'time', NOW()
+    )::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_deployments_event
+    AFTER INSERT OR UPDATE OF status ON deployments
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_deployment_event();
+
+CREATE OR REPLACE FUNCTION notify_build_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+    PERFORM pg_notify('narvana_events', json_build_object(
+        'kind', 'build',
+        'action', CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END,
+        'id', NEW.id,
+        'app_id', NEW.app_id,
+        'service_name', NEW.service_name,
+        'deployment_id', NEW.deployment_id,
+        'status', NEW.status,
+        'previous_status', CASE TG_OP WHEN 'UPDATE' THEN OLD.status END,
+        'time', NOW()
+    )::text);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_builds_event
+    AFTER INSERT OR UPDATE OF status ON builds
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_build_event();
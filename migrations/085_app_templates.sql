@@ -0,0 +1,18 @@
+-- Migration: 085_app_templates.sql
+-- App templates added by instance admins, offered alongside the bundled ones
+-- as starters for new apps
+
+CREATE TABLE IF NOT EXISTS app_templates (
+    name VARCHAR(63) PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    variables JSONB NOT NULL DEFAULT '[]',
+    secrets JSONB NOT NULL DEFAULT '[]',
+    spec TEXT NOT NULL,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN app_templates.spec IS 'App spec (narvana.yaml) whose string values may reference the template variables as ${name}';
+COMMENT ON COLUMN app_templates.secrets IS 'JSON array of secrets set on new apps; an empty value is generated';
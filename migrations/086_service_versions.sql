@@ -0,0 +1,7 @@
+-- Migration: 086_service_versions.sql
+-- Each service row carries its own version for optimistic locking, so edits
+-- to different services of an app no longer conflict on the app's version
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN services.version IS 'Incremented on every change to the service config; checked by service updates';
@@ -0,0 +1,10 @@
+// Package migrations embeds the database schema migrations. They are applied
+// in file name order, each once.
+package migrations
+
+import "embed"
+
+// Files holds the migration scripts.
+//
+//go:embed *.sql
+var Files embed.FS
@@ -0,0 +1,117 @@
+// Package client connects automation to the control plane's public gRPC API,
+// so it does not have to call the HTTP API. The API's messages and service
+// clients, generated from api/proto/public, are in package narvanav1.
+//
+//	c, err := client.New("narvana.example.com:9090", os.Getenv("NARVANA_API_KEY"))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	resp, err := c.Deploy(ctx, &narvanav1.DeployRequest{App: "shop", Service: "web"})
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	narvanav1 "github.com/narvanalabs/control-plane/pkg/client/narvana/v1"
+)
+
+// UserAgent identifies the client to the control plane.
+const UserAgent = "narvana-go-client"
+
+// Client is a connection to the public API. Calls are made as the owner of
+// the client's API key, with its scopes.
+type Client struct {
+	narvanav1.AppServiceClient
+	narvanav1.DeploymentServiceClient
+	narvanav1.BuildServiceClient
+	narvanav1.LogServiceClient
+
+	conn *grpc.ClientConn
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	insecure    bool
+	tlsConfig   *tls.Config
+	dialOptions []grpc.DialOption
+}
+
+// WithInsecure connects without TLS, e.g. to a local control plane. The API
+// key is then sent in plain text.
+func WithInsecure() Option {
+	return func(o *options) { o.insecure = true }
+}
+
+// WithTLSConfig connects with cfg instead of the system's root certificates.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = cfg }
+}
+
+// WithDialOptions adds gRPC dial options, e.g. interceptors.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, opts...) }
+}
+
+// New creates a client of the public API at target, the host and gRPC port
+// of the control plane, e.g. "narvana.example.com:9090". Connections are made
+// when the first call is.
+func New(target, apiKey string, opts ...Option) (*Client, error) {
+	if apiKey == "" {
+		return nil, errors.New("api key is required")
+	}
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	transport := credentials.NewTLS(o.tlsConfig)
+	if o.insecure {
+		transport = insecure.NewCredentials()
+	}
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(transport),
+		grpc.WithPerRPCCredentials(apiKeyCredentials{key: apiKey, requireTLS: !o.insecure}),
+		grpc.WithUserAgent(UserAgent),
+	}, o.dialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("creating client for %s: %w", target, err)
+	}
+	return &Client{
+		AppServiceClient:        narvanav1.NewAppServiceClient(conn),
+		DeploymentServiceClient: narvanav1.NewDeploymentServiceClient(conn),
+		BuildServiceClient:      narvanav1.NewBuildServiceClient(conn),
+		LogServiceClient:        narvanav1.NewLogServiceClient(conn),
+		conn:                    conn,
+	}, nil
+}
+
+// Close closes the client's connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// apiKeyCredentials sends the API key as a bearer token with every call.
+type apiKeyCredentials struct {
+	key        string
+	requireTLS bool
+}
+
+func (c apiKeyCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.key}, nil
+}
+
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
@@ -3,8 +3,10 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,11 @@ import (
 type Config struct {
 	// Database configuration
 	DatabaseDSN string
+	// DatabaseReadDSN connects to a read replica serving API reads, if set.
+	// A read waits up to DatabaseReadWait for the replica to catch up with
+	// the writes a client has seen, and is otherwise served by the primary.
+	DatabaseReadDSN  string
+	DatabaseReadWait time.Duration
 
 	// Authentication
 	JWTSecret    string
@@ -19,19 +26,37 @@ type Config struct {
 	APIKeyHeader string
 
 	// External services
-	AtticEndpoint string
-	AtticToken    string // JWT token for Attic binary cache authentication
-	RegistryURL   string
+	AtticEndpoint    string
+	AtticToken       string // JWT token for Attic binary cache authentication
+	AtticCache       string // Attic cache shared by all apps
+	AtticPerAppCache bool   // Give each app its own build cache
+	AtticAppCaches   string // Caches of specific apps as "appID=cache,..."
+	RegistryURL      string
 
 	// Server configuration
 	APIPort  int
 	GRPCPort int
 	APIHost  string
+	// TrustedProxies are the peers, such as the web UI or a load balancer,
+	// whose X-Forwarded-For and X-Real-IP headers name the client. Requests
+	// from other peers are attributed to their own address.
+	TrustedProxies []*net.IPNet
 
 	// Graceful shutdown timeout
 	// **Validates: Requirements 15.2, 15.3**
 	ShutdownTimeout time.Duration
 
+	// ChangelogPath is the CHANGELOG.md written by the release pipeline,
+	// used to serve the in-app "what's new" feed.
+	ChangelogPath string
+
+	// MetricsToken, if set, must be sent as a bearer token to scrape
+	// /metrics. Without it the metrics are served to anyone.
+	MetricsToken string
+
+	// Sessions configures the tokens of sign-in sessions
+	Sessions SessionsConfig
+
 	// Scheduler configuration
 	Scheduler SchedulerConfig
 
@@ -40,6 +65,147 @@ type Config struct {
 
 	// SOPS configuration for secrets encryption
 	SOPS SOPSConfig
+
+	// Streams limits long-lived SSE and WebSocket connections
+	Streams StreamsConfig
+
+	// Quota limits the API requests of each organization
+	Quota QuotaConfig
+
+	// RateLimit limits requests by client IP, caller and login account
+	RateLimit RateLimitConfig
+
+	// SSHBroker forwards users' SSH sessions to nodes
+	SSHBroker SSHBrokerConfig
+
+	// Audit configures the audit log of mutating API requests
+	Audit AuditConfig
+
+	// DBMaintenance cleans up and vacuums the control plane's database
+	DBMaintenance DBMaintenanceConfig
+
+	// WorkloadIdentity issues identity tokens to running workloads
+	WorkloadIdentity WorkloadIdentityConfig
+
+	// Kubernetes runs services of one node pool on a Kubernetes cluster
+	Kubernetes KubernetesConfig
+
+	// Archive moves the history of old deployments to object storage
+	Archive ArchiveConfig
+
+	// Tracing exports OpenTelemetry traces of requests, builds and deploys
+	Tracing TracingConfig
+
+	// Logging configures log levels changed at runtime
+	Logging LoggingConfig
+
+	// Idempotency configures how long Idempotency-Key responses are replayed
+	Idempotency IdempotencyConfig
+}
+
+// LoggingConfig holds the settings of log levels changed at runtime.
+type LoggingConfig struct {
+	LevelRevertAfter time.Duration // How long a change lasts unless a duration is given
+}
+
+// IdempotencyConfig holds the settings of requests sent with an
+// Idempotency-Key header.
+type IdempotencyConfig struct {
+	KeyTTL time.Duration // How long a key's response is replayed to retries
+}
+
+// TracingConfig holds the OpenTelemetry tracing settings. Tracing is
+// enabled by setting Endpoint.
+type TracingConfig struct {
+	Endpoint    string  // OTLP/HTTP collector URL, e.g. http://otel-collector:4318
+	SampleRatio float64 // Fraction of new traces recorded, from 0 to 1
+}
+
+// WorkloadIdentityConfig holds workload identity token settings.
+type WorkloadIdentityConfig struct {
+	Enabled  bool
+	Issuer   string // Public URL of the control plane, the iss claim of tokens
+	KeyPath  string // ECDSA P-256 signing key, generated if missing
+	TokenTTL time.Duration
+}
+
+// KubernetesConfig holds the settings of a Kubernetes cluster used as a node.
+type KubernetesConfig struct {
+	Enabled      bool
+	NodeID       string // Node ID of the cluster, derived from APIServer when empty
+	Pool         string // Node pool services select to run on the cluster
+	APIServer    string // API server URL, the in-cluster address when empty
+	TokenFile    string // Service account token file
+	CAFile       string // API server CA bundle; empty uses the system roots
+	Namespace    string // Namespace workloads are created in
+	IngressClass string // Ingress class for service domains; empty skips ingresses
+	SyncInterval time.Duration
+}
+
+// ArchiveConfig holds the cold storage settings of old deployments.
+// Archiving is enabled by setting Dir or S3Bucket.
+type ArchiveConfig struct {
+	After          time.Duration // Stopped deployments older than this are archived
+	KeepPerService int           // Newest deployments of each service never archived
+	Interval       time.Duration
+
+	Dir string // Keep archives in a directory, e.g. a mounted volume
+
+	// Keep archives in an S3-compatible bucket
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+// AuditConfig holds audit log settings.
+type AuditConfig struct {
+	Retention time.Duration // Zero keeps entries forever
+	// EvidenceKeyPath is the ed25519 key that signs evidence packages,
+	// generated if missing; empty disables evidence exports
+	EvidenceKeyPath string
+}
+
+// DBMaintenanceConfig holds database maintenance settings.
+type DBMaintenanceConfig struct {
+	Interval    time.Duration // Zero disables maintenance
+	OrphanGrace time.Duration // Age of orphaned rows before they are deleted
+	Vacuum      bool          // Vacuum bloated tables
+}
+
+// SSHBrokerConfig holds the node SSH session broker configuration.
+type SSHBrokerConfig struct {
+	Enabled     bool
+	Addr        string
+	HostKeyPath string
+	NodePort    int // SSH daemon port on nodes
+}
+
+// StreamsConfig holds limits for streaming connections. A zero limit disables it.
+type StreamsConfig struct {
+	MaxPerUser  int
+	MaxPerApp   int
+	IdleTimeout time.Duration
+}
+
+// QuotaConfig holds per-organization API request quotas. A zero PerMinute
+// disables quotas and a zero MaxInFlight disables fair queuing.
+type QuotaConfig struct {
+	PerMinute    int           // Requests an organization may make per minute
+	MaxInFlight  int           // Requests served at once; more wait in per-org queues
+	QueueTimeout time.Duration // How long a request waits for its turn
+	Overrides    string        // Quotas of specific orgs as "org=perMinute,...", 0 for none
+}
+
+// RateLimitConfig holds rate limits protecting the API from floods and
+// password guessing. A zero limit disables it.
+type RateLimitConfig struct {
+	Store          string // Where buckets are kept: "memory" or "postgres" to share them between API servers
+	AuthPerMinute  int    // Sign-in, registration and refresh requests per client IP
+	LoginPerMinute int    // Failed sign-in attempts per account
+	IPPerMinute    int    // API requests per client IP
+	UserPerMinute  int    // API requests per user or API key
 }
 
 // SOPSConfig holds SOPS-Nix secrets encryption configuration.
@@ -52,6 +218,15 @@ type SOPSConfig struct {
 	AgePrivateKey string
 }
 
+// SessionsConfig holds the lifetimes of the tokens of sign-in sessions.
+type SessionsConfig struct {
+	// AccessTokenExpiry is how long access tokens are valid; they are
+	// renewed with the session's refresh token.
+	AccessTokenExpiry time.Duration
+	// RefreshTokenExpiry is how long a session lasts without being used.
+	RefreshTokenExpiry time.Duration
+}
+
 // SchedulerConfig holds scheduler-specific configuration.
 type SchedulerConfig struct {
 	HealthThreshold   time.Duration
@@ -66,54 +241,51 @@ type WorkerConfig struct {
 	PodmanSocket   string
 	BuildTimeout   time.Duration
 	MaxConcurrency int
+	// BuildCPU (cores) and BuildMemoryMB limit the build container of
+	// services that do not set build_cpu and build_memory themselves.
+	BuildCPU      float64
+	BuildMemoryMB int
+	// MaxBuildsPerApp and MaxBuildsPerUser cap how many builds of one app
+	// and started by one user run at once across all workers; zero leaves
+	// a cap off.
+	MaxBuildsPerApp  int
+	MaxBuildsPerUser int
+	// DisableBuildDedup always rebuilds instead of reusing the artifact of an
+	// earlier build with identical inputs.
+	DisableBuildDedup bool
+	// LeaseDuration is how long a claimed job survives without its worker
+	// heartbeating before another worker may run it.
+	LeaseDuration time.Duration
+	// HeartbeatInterval is how often workers report liveness and renew leases.
+	HeartbeatInterval time.Duration
+	// SnapshotTTL is how long the environments of failed builds are kept for
+	// debugging when the service asked for a debug snapshot.
+	SnapshotTTL time.Duration
+	// AttestationKeyPath is the ed25519 key that signs the provenance of
+	// builds; it is generated if missing. Empty disables attestations.
+	AttestationKeyPath string
+	// LoadTests makes the worker claim and run load tests of deployments.
+	LoadTests bool
 }
 
-// Load reads configuration from environment variables.
+// Load reads configuration from environment variables and validates it. A
+// *ValidationError lists every invalid value at once.
 func Load() (*Config, error) {
-	cfg := &Config{
-		DatabaseDSN:     getEnv("DATABASE_URL", "postgres://localhost:5432/narvana?sslmode=disable"),
-		JWTSecret:       getEnv("JWT_SECRET", ""),
-		JWTExpiry:       getDurationEnv("JWT_EXPIRY", 24*time.Hour),
-		APIKeyHeader:    getEnv("API_KEY_HEADER", "X-API-Key"),
-		AtticEndpoint:   getEnv("ATTIC_ENDPOINT", "http://localhost:5000"),
-		AtticToken:      getEnv("ATTIC_TOKEN", ""),
-		RegistryURL:     getEnv("REGISTRY_URL", "localhost:5000"),
-		APIPort:         getIntEnv("API_PORT", 8080),
-		GRPCPort:        getIntEnv("GRPC_PORT", 9090),
-		APIHost:         getEnv("API_HOST", "0.0.0.0"),
-		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
-		Scheduler: SchedulerConfig{
-			HealthThreshold:   getDurationEnv("SCHEDULER_HEALTH_THRESHOLD", 30*time.Second),
-			MaxRetries:        getIntEnv("SCHEDULER_MAX_RETRIES", 5),
-			RetryBackoff:      getDurationEnv("SCHEDULER_RETRY_BACKOFF", 5*time.Second),
-			DeploymentTimeout: getDurationEnv("SCHEDULER_DEPLOYMENT_TIMEOUT", 30*time.Minute),
-		},
-		Worker: WorkerConfig{
-			WorkDir:        getEnv("WORKER_WORKDIR", "/tmp/narvana-builds"),
-			PodmanSocket:   getEnv("PODMAN_SOCKET", "unix:///run/user/1000/podman/podman.sock"),
-			BuildTimeout:   getDurationEnv("BUILD_TIMEOUT", 30*time.Minute),
-			MaxConcurrency: getIntEnv("WORKER_MAX_CONCURRENCY", 4),
-		},
-		SOPS: SOPSConfig{
-			AgePublicKey:  getEnv("SOPS_AGE_PUBLIC_KEY", ""),
-			AgePrivateKey: getEnv("SOPS_AGE_PRIVATE_KEY", ""),
-		},
-	}
-
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	l := &envLoader{}
+	cfg := l.load("")
+	problems := append(l.problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
 	}
-
 	return cfg, nil
 }
 
-// Validate checks that required configuration values are set.
+// Validate checks that required configuration values are set and that
+// values are well-formed and within bounds. A *ValidationError lists every
+// problem found.
 func (c *Config) Validate() error {
-	if c.JWTSecret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
-	}
-	if len(c.JWTSecret) < 32 {
-		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
 	}
 	return nil
 }
@@ -121,58 +293,239 @@ func (c *Config) Validate() error {
 // LoadWithDefaults loads configuration with defaults for development.
 // It does not validate required fields, useful for testing.
 func LoadWithDefaults() *Config {
+	return (&envLoader{}).load("development-secret-key-min-32-chars")
+}
+
+// envLoader reads configuration values from the environment, recording the
+// values it cannot parse instead of silently using the default.
+type envLoader struct {
+	problems []Problem
+}
+
+// load reads the whole configuration. jwtSecret is the default JWT secret.
+func (l *envLoader) load(jwtSecret string) *Config {
 	return &Config{
-		DatabaseDSN:     getEnv("DATABASE_URL", "postgres://localhost:5432/narvana?sslmode=disable"),
-		JWTSecret:       getEnv("JWT_SECRET", "development-secret-key-min-32-chars"),
-		JWTExpiry:       getDurationEnv("JWT_EXPIRY", 24*time.Hour),
-		APIKeyHeader:    getEnv("API_KEY_HEADER", "X-API-Key"),
-		AtticEndpoint:   getEnv("ATTIC_ENDPOINT", "http://localhost:5000"),
-		AtticToken:      getEnv("ATTIC_TOKEN", ""),
-		RegistryURL:     getEnv("REGISTRY_URL", "localhost:5000"),
-		APIPort:         getIntEnv("API_PORT", 8080),
-		GRPCPort:        getIntEnv("GRPC_PORT", 9090),
-		APIHost:         getEnv("API_HOST", "0.0.0.0"),
-		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+		DatabaseDSN:      l.string("DATABASE_URL", "postgres://localhost:5432/narvana?sslmode=disable"),
+		DatabaseReadDSN:  l.string("DATABASE_READ_URL", ""),
+		DatabaseReadWait: l.duration("DATABASE_READ_WAIT", 2*time.Second),
+		JWTSecret:        l.string("JWT_SECRET", jwtSecret),
+		JWTExpiry:        l.duration("JWT_EXPIRY", 24*time.Hour),
+		APIKeyHeader:     l.string("API_KEY_HEADER", "X-API-Key"),
+		AtticEndpoint:    l.string("ATTIC_ENDPOINT", "http://localhost:5000"),
+		AtticToken:       l.string("ATTIC_TOKEN", ""),
+		AtticCache:       l.string("ATTIC_CACHE", "narvana"),
+		AtticPerAppCache: l.bool("ATTIC_PER_APP_CACHE", false),
+		AtticAppCaches:   l.string("ATTIC_APP_CACHES", ""),
+		RegistryURL:      l.string("REGISTRY_URL", "localhost:5000"),
+		APIPort:          l.int("API_PORT", 8080),
+		GRPCPort:         l.int("GRPC_PORT", 9090),
+		TrustedProxies:   l.networks("TRUSTED_PROXIES", DefaultTrustedProxies),
+		APIHost:          l.string("API_HOST", "0.0.0.0"),
+		ShutdownTimeout:  l.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		ChangelogPath:    l.string("CHANGELOG_PATH", "CHANGELOG.md"),
+		MetricsToken:     l.string("METRICS_TOKEN", ""),
+		Sessions: SessionsConfig{
+			AccessTokenExpiry:  l.duration("ACCESS_TOKEN_EXPIRY", 15*time.Minute),
+			RefreshTokenExpiry: l.duration("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour),
+		},
 		Scheduler: SchedulerConfig{
-			HealthThreshold:   getDurationEnv("SCHEDULER_HEALTH_THRESHOLD", 30*time.Second),
-			MaxRetries:        getIntEnv("SCHEDULER_MAX_RETRIES", 5),
-			RetryBackoff:      getDurationEnv("SCHEDULER_RETRY_BACKOFF", 5*time.Second),
-			DeploymentTimeout: getDurationEnv("SCHEDULER_DEPLOYMENT_TIMEOUT", 30*time.Minute),
+			HealthThreshold:   l.duration("SCHEDULER_HEALTH_THRESHOLD", 30*time.Second),
+			MaxRetries:        l.int("SCHEDULER_MAX_RETRIES", 5),
+			RetryBackoff:      l.duration("SCHEDULER_RETRY_BACKOFF", 5*time.Second),
+			DeploymentTimeout: l.duration("SCHEDULER_DEPLOYMENT_TIMEOUT", 30*time.Minute),
 		},
 		Worker: WorkerConfig{
-			WorkDir:        getEnv("WORKER_WORKDIR", "/tmp/narvana-builds"),
-			PodmanSocket:   getEnv("PODMAN_SOCKET", "unix:///run/user/1000/podman/podman.sock"),
-			BuildTimeout:   getDurationEnv("BUILD_TIMEOUT", 30*time.Minute),
-			MaxConcurrency: getIntEnv("WORKER_MAX_CONCURRENCY", 4),
+			WorkDir:            l.string("WORKER_WORKDIR", "/tmp/narvana-builds"),
+			PodmanSocket:       l.string("PODMAN_SOCKET", "unix:///run/user/1000/podman/podman.sock"),
+			BuildTimeout:       l.duration("BUILD_TIMEOUT", 30*time.Minute),
+			BuildCPU:           l.float("WORKER_BUILD_CPU", 2),
+			BuildMemoryMB:      l.int("WORKER_BUILD_MEMORY_MB", 4096),
+			MaxConcurrency:     l.int("WORKER_MAX_CONCURRENCY", 4),
+			MaxBuildsPerApp:    l.int("WORKER_MAX_BUILDS_PER_APP", 2),
+			MaxBuildsPerUser:   l.int("WORKER_MAX_BUILDS_PER_USER", 0),
+			DisableBuildDedup:  l.bool("BUILD_DEDUP_DISABLED", false),
+			LeaseDuration:      l.duration("WORKER_LEASE_DURATION", 2*time.Minute),
+			HeartbeatInterval:  l.duration("WORKER_HEARTBEAT_INTERVAL", 15*time.Second),
+			SnapshotTTL:        l.duration("BUILD_SNAPSHOT_TTL", 24*time.Hour),
+			AttestationKeyPath: l.string("WORKER_ATTESTATION_KEY", "/var/lib/narvana/attestation_ed25519_key"),
+			LoadTests:          l.bool("WORKER_LOAD_TESTS", false),
 		},
 		SOPS: SOPSConfig{
-			AgePublicKey:  getEnv("SOPS_AGE_PUBLIC_KEY", ""),
-			AgePrivateKey: getEnv("SOPS_AGE_PRIVATE_KEY", ""),
+			AgePublicKey:  l.string("SOPS_AGE_PUBLIC_KEY", ""),
+			AgePrivateKey: l.string("SOPS_AGE_PRIVATE_KEY", ""),
+		},
+		Streams: StreamsConfig{
+			MaxPerUser:  l.int("STREAM_MAX_PER_USER", 20),
+			MaxPerApp:   l.int("STREAM_MAX_PER_APP", 100),
+			IdleTimeout: l.duration("STREAM_IDLE_TIMEOUT", 10*time.Minute),
+		},
+		Quota: QuotaConfig{
+			PerMinute:    l.int("API_QUOTA_PER_MINUTE", 600),
+			MaxInFlight:  l.int("API_MAX_IN_FLIGHT", 128),
+			QueueTimeout: l.duration("API_QUEUE_TIMEOUT", 10*time.Second),
+			Overrides:    l.string("API_QUOTA_OVERRIDES", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Store:          l.string("RATE_LIMIT_STORE", "memory"),
+			AuthPerMinute:  l.int("RATE_LIMIT_AUTH_PER_MINUTE", 20),
+			LoginPerMinute: l.int("RATE_LIMIT_LOGIN_PER_MINUTE", 5),
+			IPPerMinute:    l.int("RATE_LIMIT_IP_PER_MINUTE", 1200),
+			UserPerMinute:  l.int("RATE_LIMIT_USER_PER_MINUTE", 600),
+		},
+		SSHBroker: SSHBrokerConfig{
+			Enabled:     l.bool("SSH_BROKER_ENABLED", false),
+			Addr:        l.string("SSH_BROKER_ADDR", ":2222"),
+			HostKeyPath: l.string("SSH_BROKER_HOST_KEY", "/var/lib/narvana/ssh_host_ed25519_key"),
+			NodePort:    l.int("SSH_BROKER_NODE_PORT", 22),
+		},
+		Audit: AuditConfig{
+			Retention:       l.duration("AUDIT_RETENTION", 90*24*time.Hour),
+			EvidenceKeyPath: l.string("EVIDENCE_SIGNING_KEY", "/var/lib/narvana/evidence_ed25519_key"),
+		},
+		DBMaintenance: DBMaintenanceConfig{
+			Interval:    l.duration("DB_MAINTENANCE_INTERVAL", 6*time.Hour),
+			OrphanGrace: l.duration("DB_ORPHAN_GRACE", 24*time.Hour),
+			Vacuum:      l.bool("DB_MAINTENANCE_VACUUM", true),
+		},
+		WorkloadIdentity: WorkloadIdentityConfig{
+			Enabled:  l.bool("WORKLOAD_IDENTITY_ENABLED", false),
+			Issuer:   l.string("WORKLOAD_IDENTITY_ISSUER", "http://localhost:8080"),
+			KeyPath:  l.string("WORKLOAD_IDENTITY_KEY", "/var/lib/narvana/workload_identity_p256_key"),
+			TokenTTL: l.duration("WORKLOAD_IDENTITY_TOKEN_TTL", 15*time.Minute),
+		},
+		Kubernetes: KubernetesConfig{
+			Enabled:      l.bool("KUBERNETES_ENABLED", false),
+			NodeID:       l.string("KUBERNETES_NODE_ID", ""),
+			Pool:         l.string("KUBERNETES_POOL", "kubernetes"),
+			APIServer:    l.string("KUBERNETES_API_SERVER", ""),
+			TokenFile:    l.string("KUBERNETES_TOKEN_FILE", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+			CAFile:       l.string("KUBERNETES_CA_FILE", "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"),
+			Namespace:    l.string("KUBERNETES_NAMESPACE", "narvana"),
+			IngressClass: l.string("KUBERNETES_INGRESS_CLASS", ""),
+			SyncInterval: l.duration("KUBERNETES_SYNC_INTERVAL", 15*time.Second),
+		},
+		Archive: ArchiveConfig{
+			After:             l.duration("ARCHIVE_AFTER", 30*24*time.Hour),
+			KeepPerService:    l.int("ARCHIVE_KEEP_PER_SERVICE", 5),
+			Interval:          l.duration("ARCHIVE_INTERVAL", time.Hour),
+			Dir:               l.string("ARCHIVE_DIR", ""),
+			S3Endpoint:        l.string("ARCHIVE_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			S3Bucket:          l.string("ARCHIVE_S3_BUCKET", ""),
+			S3Region:          l.string("ARCHIVE_S3_REGION", "us-east-1"),
+			S3AccessKeyID:     l.string("ARCHIVE_S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: l.string("ARCHIVE_S3_SECRET_ACCESS_KEY", ""),
+		},
+		Tracing: TracingConfig{
+			Endpoint:    l.string("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			SampleRatio: l.float("TRACING_SAMPLE_RATIO", 1),
+		},
+		Logging: LoggingConfig{
+			LevelRevertAfter: l.duration("LOG_LEVEL_REVERT_AFTER", time.Hour),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: l.duration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
 		},
 	}
 }
 
-func getEnv(key, defaultValue string) string {
+func (l *envLoader) string(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getIntEnv(key string, defaultValue int) int {
+func (l *envLoader) int(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			l.invalid(key, "%q is not a whole number", value)
+			return defaultValue
+		}
+		return i
+	}
+	return defaultValue
+}
+
+func (l *envLoader) duration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			l.invalid(key, "%q is not a duration such as 30s, 5m or 24h", value)
+			return defaultValue
+		}
+		return d
+	}
+	return defaultValue
+}
+
+func (l *envLoader) float(key string, defaultValue float64) float64 {
 	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.invalid(key, "%q is not a number", value)
+			return defaultValue
 		}
+		return f
 	}
 	return defaultValue
 }
 
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+func (l *envLoader) bool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
-		if d, err := time.ParseDuration(value); err == nil {
-			return d
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			l.invalid(key, "%q is not true or false", value)
+			return defaultValue
 		}
+		return b
 	}
 	return defaultValue
 }
+
+func (l *envLoader) networks(key, defaultValue string) []*net.IPNet {
+	value := os.Getenv(key)
+	if value == "" {
+		value = defaultValue
+	}
+	networks, err := ParseNetworks(value)
+	if err != nil {
+		l.invalid(key, "%v", err)
+		networks, _ = ParseNetworks(defaultValue)
+	}
+	return networks
+}
+
+func (l *envLoader) invalid(key, format string, args ...any) {
+	l.problems = append(l.problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// DefaultTrustedProxies trusts forwarded client addresses only from the
+// loopback interface, where the web UI runs in a single-host install.
+const DefaultTrustedProxies = "127.0.0.1/32,::1/128"
+
+// ParseNetworks parses a comma-separated list of IP addresses and CIDR
+// ranges. An address stands for a network holding only itself.
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		ip := net.ParseIP(item)
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", item)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return networks, nil
+}
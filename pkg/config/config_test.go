@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadAcceptsDefaults(t *testing.T) {
+	t.Setenv("JWT_SECRET", "4f9c2e7a1b8d3f6e0a5c9b2d7e1f4a8c")
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("JWT_SECRET", "short")
+	t.Setenv("API_PORT", "abc")
+	t.Setenv("GRPC_PORT", "9090")
+	t.Setenv("SSH_BROKER_ENABLED", "true")
+	t.Setenv("SSH_BROKER_ADDR", ":9090")
+	t.Setenv("BUILD_TIMEOUT", "ten minutes")
+	t.Setenv("ATTIC_ENDPOINT", "attic.internal:8080")
+	t.Setenv("REGISTRY_URL", "https://registry.example.com")
+	t.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+	t.Setenv("WORKER_BUILD_CPU", "0")
+	t.Setenv("WORKER_BUILD_MEMORY_MB", "64")
+
+	_, err := Load()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load() error = %v, want a *ValidationError", err)
+	}
+
+	keys := make(map[string]bool)
+	for _, p := range verr.Problems {
+		keys[p.Key] = true
+	}
+	for _, key := range []string{"JWT_SECRET", "API_PORT", "SSH_BROKER_ADDR", "BUILD_TIMEOUT", "ATTIC_ENDPOINT", "REGISTRY_URL", "TRACING_SAMPLE_RATIO", "WORKER_BUILD_CPU", "WORKER_BUILD_MEMORY_MB"} {
+		if !keys[key] {
+			t.Errorf("no problem reported for %s in:\n%v", key, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "SSH_BROKER_ADDR: port 9090 is also used by GRPC_PORT") {
+		t.Errorf("error does not name the port collision:\n%v", err)
+	}
+}
+
+func TestValidateDurationBounds(t *testing.T) {
+	cfg := LoadWithDefaults()
+	cfg.Worker.HeartbeatInterval = cfg.Worker.LeaseDuration
+	cfg.JWTExpiry = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want duration problems")
+	}
+	for _, want := range []string{"WORKER_HEARTBEAT_INTERVAL", "JWT_EXPIRY: 0s is shorter than the minimum of 1m0s"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+}
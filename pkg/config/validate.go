@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem is an invalid configuration value.
+type Problem struct {
+	Key     string // Environment variable, e.g. "API_PORT"
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// ValidationError lists every problem of a configuration, so they can be
+// fixed in one go instead of one restart at a time.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if len(e.Problems) == 1 {
+		b.WriteString("invalid configuration (1 problem):")
+	} else {
+		fmt.Fprintf(&b, "invalid configuration (%d problems):", len(e.Problems))
+	}
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// validator collects the problems of a configuration.
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(key, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// port checks that a port number is usable.
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.add(key, "%d is not a port between 1 and 65535", port)
+	}
+}
+
+// between checks that a duration lies within [min, max]; a zero max means
+// no upper bound.
+func (v *validator) between(key string, d, min, max time.Duration) {
+	switch {
+	case d < min:
+		v.add(key, "%s is shorter than the minimum of %s", d, min)
+	case max > 0 && d > max:
+		v.add(key, "%s is longer than the maximum of %s", d, max)
+	}
+}
+
+// atLeast checks that a number is at least min.
+func (v *validator) atLeast(key string, n, min int) {
+	if n < min {
+		v.add(key, "%d is less than the minimum of %d", n, min)
+	}
+}
+
+// httpURL checks that value is an absolute http or https URL.
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(key, "%q is not an http:// or https:// URL", value)
+	}
+}
+
+// problems returns every invalid value of the configuration.
+func (c *Config) problems() []Problem {
+	v := &validator{}
+
+	// Required values
+	if c.JWTSecret == "" {
+		v.add("JWT_SECRET", "is required; generate one with openssl rand -hex 32")
+	} else if len(c.JWTSecret) < 32 {
+		v.add("JWT_SECRET", "must be at least 32 characters, has %d", len(c.JWTSecret))
+	}
+	if c.DatabaseDSN == "" {
+		v.add("DATABASE_URL", "is required")
+	} else if !validDatabaseDSN(c.DatabaseDSN) {
+		v.add("DATABASE_URL", "is neither a postgres:// URL nor a key=value connection string")
+	}
+	if c.DatabaseReadDSN != "" && !validDatabaseDSN(c.DatabaseReadDSN) {
+		v.add("DATABASE_READ_URL", "is neither a postgres:// URL nor a key=value connection string")
+	}
+
+	// Listeners must not collide
+	v.port("API_PORT", c.APIPort)
+	v.port("GRPC_PORT", c.GRPCPort)
+	v.port("SSH_BROKER_NODE_PORT", c.SSHBroker.NodePort)
+	listeners := map[int]string{}
+	claim := func(key string, port int) {
+		if other, ok := listeners[port]; ok {
+			v.add(key, "port %d is also used by %s", port, other)
+			return
+		}
+		listeners[port] = key
+	}
+	claim("API_PORT", c.APIPort)
+	claim("GRPC_PORT", c.GRPCPort)
+	if c.SSHBroker.Enabled {
+		_, portStr, err := net.SplitHostPort(c.SSHBroker.Addr)
+		port, perr := strconv.Atoi(portStr)
+		if err != nil || perr != nil {
+			v.add("SSH_BROKER_ADDR", "%q is not a host:port address such as :2222", c.SSHBroker.Addr)
+		} else {
+			v.port("SSH_BROKER_ADDR", port)
+			claim("SSH_BROKER_ADDR", port)
+		}
+	}
+
+	// URLs and addresses
+	if c.AtticEndpoint != "" {
+		v.httpURL("ATTIC_ENDPOINT", c.AtticEndpoint)
+	}
+	if strings.Contains(c.RegistryURL, "://") {
+		v.add("REGISTRY_URL", "%q must be a host[:port][/path] without a scheme, e.g. registry.example.com/narvana", c.RegistryURL)
+	}
+	if c.Worker.PodmanSocket != "" && !hasScheme(c.Worker.PodmanSocket, "unix", "tcp", "ssh") {
+		v.add("PODMAN_SOCKET", "%q must start with unix://, tcp:// or ssh://", c.Worker.PodmanSocket)
+	}
+	for _, entry := range strings.Split(c.AtticAppCaches, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		if app, cache, ok := strings.Cut(entry, "="); !ok || app == "" || cache == "" {
+			v.add("ATTIC_APP_CACHES", "%q is not an appID=cache pair", entry)
+		}
+	}
+	for _, entry := range strings.Split(c.Quota.Overrides, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		org, limit, ok := strings.Cut(entry, "=")
+		if n, err := strconv.Atoi(strings.TrimSpace(limit)); !ok || strings.TrimSpace(org) == "" || err != nil || n < 0 {
+			v.add("API_QUOTA_OVERRIDES", "%q is not an org=perMinute pair", entry)
+		}
+	}
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "postgres" {
+		v.add("RATE_LIMIT_STORE", "%q must be memory or postgres", c.RateLimit.Store)
+	}
+	if c.WorkloadIdentity.Enabled {
+		v.httpURL("WORKLOAD_IDENTITY_ISSUER", c.WorkloadIdentity.Issuer)
+		v.between("WORKLOAD_IDENTITY_TOKEN_TTL", c.WorkloadIdentity.TokenTTL, time.Minute, 24*time.Hour)
+	}
+	if c.Kubernetes.Enabled {
+		if c.Kubernetes.APIServer != "" {
+			v.httpURL("KUBERNETES_API_SERVER", c.Kubernetes.APIServer)
+		}
+		v.between("KUBERNETES_SYNC_INTERVAL", c.Kubernetes.SyncInterval, time.Second, time.Hour)
+	}
+	if c.Archive.S3Bucket != "" {
+		v.httpURL("ARCHIVE_S3_ENDPOINT", c.Archive.S3Endpoint)
+	}
+	if c.Tracing.Endpoint != "" {
+		v.httpURL("OTEL_EXPORTER_OTLP_ENDPOINT", c.Tracing.Endpoint)
+	}
+	if c.Worker.BuildCPU <= 0 {
+		v.add("WORKER_BUILD_CPU", "%g is not a positive number of cores", c.Worker.BuildCPU)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.add("TRACING_SAMPLE_RATIO", "%g is not between 0 and 1", c.Tracing.SampleRatio)
+	}
+
+	// Duration bounds
+	v.between("JWT_EXPIRY", c.JWTExpiry, time.Minute, 30*24*time.Hour)
+	v.between("ACCESS_TOKEN_EXPIRY", c.Sessions.AccessTokenExpiry, time.Minute, 24*time.Hour)
+	v.between("REFRESH_TOKEN_EXPIRY", c.Sessions.RefreshTokenExpiry, time.Hour, 365*24*time.Hour)
+	v.between("DATABASE_READ_WAIT", c.DatabaseReadWait, 0, time.Minute)
+	v.between("SHUTDOWN_TIMEOUT", c.ShutdownTimeout, time.Second, 10*time.Minute)
+	v.between("LOG_LEVEL_REVERT_AFTER", c.Logging.LevelRevertAfter, time.Minute, 24*time.Hour)
+	v.between("IDEMPOTENCY_KEY_TTL", c.Idempotency.KeyTTL, time.Hour, 30*24*time.Hour)
+	v.between("BUILD_TIMEOUT", c.Worker.BuildTimeout, time.Minute, 24*time.Hour)
+	v.between("SCHEDULER_HEALTH_THRESHOLD", c.Scheduler.HealthThreshold, time.Second, 0)
+	v.between("SCHEDULER_RETRY_BACKOFF", c.Scheduler.RetryBackoff, 0, time.Hour)
+	v.between("SCHEDULER_DEPLOYMENT_TIMEOUT", c.Scheduler.DeploymentTimeout, time.Minute, 0)
+	if c.Quota.MaxInFlight > 0 {
+		v.between("API_QUEUE_TIMEOUT", c.Quota.QueueTimeout, 0, time.Minute)
+	}
+	v.between("STREAM_IDLE_TIMEOUT", c.Streams.IdleTimeout, 0, 0)
+	v.between("AUDIT_RETENTION", c.Audit.Retention, 0, 0)
+	if c.DBMaintenance.Interval != 0 {
+		v.between("DB_MAINTENANCE_INTERVAL", c.DBMaintenance.Interval, time.Minute, 0)
+	}
+	v.between("DB_ORPHAN_GRACE", c.DBMaintenance.OrphanGrace, time.Hour, 0)
+	v.between("BUILD_SNAPSHOT_TTL", c.Worker.SnapshotTTL, 0, 0)
+	if c.Worker.HeartbeatInterval <= 0 || c.Worker.HeartbeatInterval >= c.Worker.LeaseDuration {
+		v.add("WORKER_HEARTBEAT_INTERVAL", "must be positive and shorter than WORKER_LEASE_DURATION (%s)", c.Worker.LeaseDuration)
+	}
+	if c.Archive.Dir != "" || c.Archive.S3Bucket != "" {
+		v.between("ARCHIVE_AFTER", c.Archive.After, time.Hour, 0)
+		v.between("ARCHIVE_INTERVAL", c.Archive.Interval, time.Minute, 0)
+	}
+
+	// Counts
+	v.atLeast("WORKER_MAX_CONCURRENCY", c.Worker.MaxConcurrency, 1)
+	v.atLeast("WORKER_MAX_BUILDS_PER_APP", c.Worker.MaxBuildsPerApp, 0)
+	v.atLeast("WORKER_MAX_BUILDS_PER_USER", c.Worker.MaxBuildsPerUser, 0)
+	v.atLeast("WORKER_BUILD_MEMORY_MB", c.Worker.BuildMemoryMB, 256)
+	v.atLeast("SCHEDULER_MAX_RETRIES", c.Scheduler.MaxRetries, 0)
+	v.atLeast("API_QUOTA_PER_MINUTE", c.Quota.PerMinute, 0)
+	v.atLeast("API_MAX_IN_FLIGHT", c.Quota.MaxInFlight, 0)
+	v.atLeast("RATE_LIMIT_AUTH_PER_MINUTE", c.RateLimit.AuthPerMinute, 0)
+	v.atLeast("RATE_LIMIT_LOGIN_PER_MINUTE", c.RateLimit.LoginPerMinute, 0)
+	v.atLeast("RATE_LIMIT_IP_PER_MINUTE", c.RateLimit.IPPerMinute, 0)
+	v.atLeast("RATE_LIMIT_USER_PER_MINUTE", c.RateLimit.UserPerMinute, 0)
+	v.atLeast("STREAM_MAX_PER_USER", c.Streams.MaxPerUser, 0)
+	v.atLeast("STREAM_MAX_PER_APP", c.Streams.MaxPerApp, 0)
+	v.atLeast("ARCHIVE_KEEP_PER_SERVICE", c.Archive.KeepPerService, 0)
+
+	return v.problems
+}
+
+// validDatabaseDSN reports whether dsn looks like a connection string the
+// Postgres driver accepts.
+func validDatabaseDSN(dsn string) bool {
+	if hasScheme(dsn, "postgres", "postgresql") {
+		_, err := url.Parse(dsn)
+		return err == nil
+	}
+	return strings.Contains(dsn, "=")
+}
+
+// hasScheme reports whether value starts with one of the URL schemes.
+func hasScheme(value string, schemes ...string) bool {
+	for _, scheme := range schemes {
+		if strings.HasPrefix(value, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Levels holds the log level of each component of a process, such as the API
+// server or the build worker, so it can be changed without a restart.
+type Levels struct {
+	mu       sync.Mutex
+	fallback slog.Level
+	vars     map[string]*slog.LevelVar
+}
+
+// NewLevels creates levels whose components log at level until changed.
+func NewLevels(level slog.Level) *Levels {
+	return &Levels{fallback: level, vars: make(map[string]*slog.LevelVar)}
+}
+
+// Default returns the level components log at unless changed.
+func (l *Levels) Default() slog.Level {
+	return l.fallback
+}
+
+// Var returns the variable holding a component's level.
+func (l *Levels) Var(component string) *slog.LevelVar {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	v, ok := l.vars[component]
+	if !ok {
+		v = new(slog.LevelVar)
+		v.Set(l.fallback)
+		l.vars[component] = v
+	}
+	return v
+}
+
+// Level returns a component's current level.
+func (l *Levels) Level(component string) slog.Level {
+	return l.Var(component).Level()
+}
+
+// Set changes a component's level.
+func (l *Levels) Set(component string, level slog.Level) {
+	l.Var(component).Set(level)
+}
+
+// Reset returns a component to the default level.
+func (l *Levels) Reset(component string) {
+	l.Var(component).Set(l.fallback)
+}
+
+// levelHandler drops the records of a handler below a level that may change.
+type levelHandler struct {
+	handler slog.Handler
+	level   slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), level: h.level}
+}
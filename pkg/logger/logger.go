@@ -22,14 +22,20 @@ const (
 // Logger wraps slog.Logger with additional context-aware methods.
 type Logger struct {
 	*slog.Logger
+
+	// handler writes records of every level; levels filters them by component
+	handler slog.Handler
+	levels  *Levels
 }
 
-// New creates a new Logger with the specified level and format.
+// New creates a new Logger with the specified level and format. The level is
+// the default of components' levels, which can be changed at runtime through
+// Levels.
 func New(level slog.Level, json bool) *Logger {
 	var handler slog.Handler
 
 	opts := &slog.HandlerOptions{
-		Level:     level,
+		Level:     min(level, slog.LevelDebug),
 		AddSource: level == slog.LevelDebug,
 	}
 
@@ -40,7 +46,9 @@ func New(level slog.Level, json bool) *Logger {
 	}
 
 	return &Logger{
-		Logger: slog.New(handler),
+		Logger:  slog.New(&levelHandler{handler: handler, level: level}),
+		handler: handler,
+		levels:  NewLevels(level),
 	}
 }
 
@@ -89,6 +97,27 @@ func (l *Logger) WithComponent(component string) *Logger {
 	}
 }
 
+// ForComponent returns a Logger with the component field whose level is the
+// component's level in Levels. It keeps no fields added with the With methods;
+// on a Logger derived with them it is the same as WithComponent.
+func (l *Logger) ForComponent(component string) *Logger {
+	if l.levels == nil {
+		return l.WithComponent(component)
+	}
+	handler := &levelHandler{handler: l.handler, level: l.levels.Var(component)}
+	return &Logger{
+		Logger:  slog.New(handler).With("component", component),
+		handler: l.handler,
+		levels:  l.levels,
+	}
+}
+
+// Levels returns the adjustable levels of the Logger's components, or nil if
+// the Logger was not created with New.
+func (l *Logger) Levels() *Levels {
+	return l.levels
+}
+
 // WithError returns a new Logger with the error field.
 func (l *Logger) WithError(err error) *Logger {
 	return &Logger{
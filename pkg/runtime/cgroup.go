@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultCgroupRoot is where the cgroup v2 hierarchy is mounted.
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// readCgroupUsage reads the accounting files of the cgroup v2 group at path,
+// relative to root.
+func readCgroupUsage(root, path string) (*Usage, error) {
+	if path == "" {
+		return nil, ErrNotFound
+	}
+	dir := filepath.Join(root, filepath.Clean("/"+path))
+
+	stat, err := readKeyedFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	usage := &Usage{CPUSeconds: float64(stat["usage_usec"]) / 1e6}
+
+	if usage.MemoryBytes, err = readIntFile(filepath.Join(dir, "memory.current")); err != nil {
+		return nil, err
+	}
+	if peak, err := readIntFile(filepath.Join(dir, "memory.peak")); err == nil {
+		usage.MemoryPeakBytes = peak
+	}
+	if usage.Pids, err = readIntFile(filepath.Join(dir, "pids.current")); err != nil {
+		return nil, err
+	}
+	if events, err := readKeyedFile(filepath.Join(dir, "memory.events")); err == nil {
+		usage.OOMKills = events["oom_kill"]
+	}
+	return usage, nil
+}
+
+// readIntFile reads a cgroup file holding a single integer.
+func readIntFile(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// readKeyedFile reads a cgroup file of "key value" lines.
+func readKeyedFile(path string) (map[string]int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			values[key] = v
+		}
+	}
+	return values, scanner.Err()
+}
+
+// cgroupV2Available reports whether root is a cgroup v2 hierarchy.
+func cgroupV2Available(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	return err == nil
+}
@@ -0,0 +1,201 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContainerConfig configures the container driver.
+type ContainerConfig struct {
+	// Podman is the podman binary.
+	Podman string
+	// NixImage is the image pure-nix closures run in, with the host's Nix
+	// store mounted read-only.
+	NixImage string
+	// Network is the network containers join; empty uses Podman's default.
+	Network    string
+	CgroupRoot string
+}
+
+// DefaultContainerConfig returns the default container driver configuration.
+func DefaultContainerConfig() ContainerConfig {
+	return ContainerConfig{
+		Podman:     "podman",
+		NixImage:   "docker.io/library/busybox:stable",
+		CgroupRoot: DefaultCgroupRoot,
+	}
+}
+
+// ContainerDriver runs workloads as detached Podman containers.
+type ContainerDriver struct {
+	cfg ContainerConfig
+	run runner
+}
+
+// NewContainerDriver creates a container driver.
+func NewContainerDriver(cfg ContainerConfig) *ContainerDriver {
+	defaults := DefaultContainerConfig()
+	if cfg.Podman == "" {
+		cfg.Podman = defaults.Podman
+	}
+	if cfg.NixImage == "" {
+		cfg.NixImage = defaults.NixImage
+	}
+	if cfg.CgroupRoot == "" {
+		cfg.CgroupRoot = defaults.CgroupRoot
+	}
+	return &ContainerDriver{cfg: cfg, run: execRunner}
+}
+
+// Name returns Container.
+func (d *ContainerDriver) Name() string {
+	return Container
+}
+
+// Start replaces any container with the workload's name and starts it.
+func (d *ContainerDriver) Start(ctx context.Context, w *Workload) error {
+	if err := checkName(w.Name); err != nil {
+		return err
+	}
+	d.run(ctx, nil, d.cfg.Podman, "rm", "--force", "--ignore", w.Name)
+
+	// Variables are passed by name and read from podman's environment, so
+	// their values never appear in the process list
+	args, env, err := d.runArgs(w)
+	if err != nil {
+		return err
+	}
+	_, err = d.run(ctx, env, d.cfg.Podman, args...)
+	return err
+}
+
+// runArgs returns the podman run arguments and environment for a workload.
+func (d *ContainerDriver) runArgs(w *Workload) ([]string, []string, error) {
+	args := []string{"run", "--detach", "--name", w.Name,
+		"--label", "narvana.deployment-id=" + w.DeploymentID,
+		"--security-opt", "no-new-privileges",
+		"--cpus", strconv.FormatFloat(w.CPU, 'f', -1, 64),
+		"--memory", fmt.Sprintf("%dm", w.MemoryMB),
+		"--pids-limit", strconv.FormatInt(w.PidsLimit, 10),
+	}
+	if !w.OneShot {
+		args = append(args, "--restart", "on-failure")
+	}
+	if d.cfg.Network != "" {
+		args = append(args, "--network", d.cfg.Network)
+	}
+	for _, p := range w.Ports {
+		args = append(args, "--publish", fmt.Sprintf("127.0.0.1:%d:%d", p, p))
+	}
+
+	names := make([]string, 0, len(w.Env))
+	for name := range w.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		args = append(args, "--env", name)
+		env = append(env, name+"="+w.Env[name])
+	}
+
+	if w.Nix {
+		cmd, err := entrypoint(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, "--volume", "/nix/store:/nix/store:ro", d.cfg.NixImage)
+		return append(args, cmd...), env, nil
+	}
+
+	args = append(args, w.Artifact)
+	return append(args, w.Command...), env, nil
+}
+
+// Stop stops and removes the container.
+func (d *ContainerDriver) Stop(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	_, err := d.run(ctx, nil, d.cfg.Podman, "rm", "--force", "--ignore", "--time", "30", name)
+	return err
+}
+
+// Status returns the state of the container.
+func (d *ContainerDriver) Status(ctx context.Context, name string) (*Status, error) {
+	out, err := d.inspect(ctx, name, "{{.State.Status}} {{.State.ExitCode}}")
+	if err != nil {
+		return nil, err
+	}
+
+	state, code, _ := strings.Cut(out, " ")
+	status := &Status{}
+	status.ExitCode, _ = strconv.Atoi(code)
+	switch state {
+	case "running":
+		status.State = StateRunning
+	case "created", "configured", "initialized", "restarting":
+		status.State = StateStarting
+	default:
+		status.State = StateStopped
+		if status.ExitCode != 0 {
+			status.State = StateFailed
+		}
+	}
+	return status, nil
+}
+
+// Usage reads the accounting of the container's cgroup.
+func (d *ContainerDriver) Usage(ctx context.Context, name string) (*Usage, error) {
+	path, err := d.inspect(ctx, name, "{{.State.CgroupPath}}")
+	if err != nil {
+		return nil, err
+	}
+	return readCgroupUsage(d.cfg.CgroupRoot, path)
+}
+
+// Logs returns the container's output.
+func (d *ContainerDriver) Logs(ctx context.Context, name string, since time.Time) ([]LogEntry, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	out, err := d.run(ctx, nil, d.cfg.Podman, "logs", "--timestamps", "--since", since.Format(time.RFC3339Nano), name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		stamp, message, ok := strings.Cut(line, " ")
+		t, err := time.Parse(time.RFC3339Nano, stamp)
+		if !ok || err != nil || !t.After(since) {
+			continue
+		}
+		entries = append(entries, LogEntry{Time: t, Level: "info", Message: message})
+	}
+	return entries, nil
+}
+
+// inspect formats a field of the container, returning ErrNotFound if there
+// is no container with the name.
+func (d *ContainerDriver) inspect(ctx context.Context, name, format string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	exists, err := d.run(ctx, nil, d.cfg.Podman, "ps", "--all", "--quiet", "--filter", "name=^"+name+"$")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(exists)) == "" {
+		return "", ErrNotFound
+	}
+	out, err := d.run(ctx, nil, d.cfg.Podman, "inspect", "--type", "container", "--format", format, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
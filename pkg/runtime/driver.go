@@ -0,0 +1,198 @@
+// Package runtime defines how a node runs the deployments the control plane
+// sends it. A Driver starts, stops and inspects one kind of workload: the
+// container driver runs OCI images and pure-nix closures under Podman, and
+// the systemd driver runs pure-nix closures directly as sandboxed systemd
+// units. Both drivers confine workloads in their own cgroup v2 group, so
+// resource usage is read the same way whichever runs a workload.
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/narvanalabs/control-plane/api/proto"
+	"github.com/narvanalabs/control-plane/internal/models"
+	"github.com/narvanalabs/control-plane/internal/podman"
+)
+
+// Driver names, as sent in CPDeploymentConfig.runtime.
+const (
+	Container = "container"
+	Systemd   = "systemd"
+)
+
+// CapabilitySystemdUnits is the node capability an agent reports when it can
+// run workloads with the systemd driver.
+const CapabilitySystemdUnits = "systemd-units"
+
+var (
+	// ErrNotFound is returned when a driver has no workload with a name.
+	ErrNotFound = errors.New("workload not found")
+	// ErrUnsupported is returned when a driver cannot run a workload.
+	ErrUnsupported = errors.New("workload not supported by driver")
+)
+
+// Driver runs workloads on a node.
+type Driver interface {
+	// Name returns the driver's name, Container or Systemd.
+	Name() string
+	// Start starts a workload, replacing a previous one with the same name.
+	Start(ctx context.Context, w *Workload) error
+	// Stop stops a workload and removes everything Start created for it.
+	// Stopping a workload that does not exist succeeds.
+	Stop(ctx context.Context, name string) error
+	// Status returns the state of a workload.
+	Status(ctx context.Context, name string) (*Status, error)
+	// Usage returns the resources a workload's cgroup has consumed.
+	Usage(ctx context.Context, name string) (*Usage, error)
+	// Logs returns the output a workload wrote after since.
+	Logs(ctx context.Context, name string, since time.Time) ([]LogEntry, error)
+}
+
+// Workload is a deployment as a driver runs it.
+type Workload struct {
+	// Name identifies the workload on the node: the container name or the
+	// unit name without its .service suffix.
+	Name         string
+	DeploymentID string
+	AppID        string
+	ServiceName  string
+
+	// Artifact is an OCI image reference, or a Nix store path when Nix is set.
+	Artifact string
+	Nix      bool
+	// Runtime is the driver that runs the workload.
+	Runtime string
+
+	// Command replaces the artifact's entrypoint. Workloads with OneShot set
+	// run it once and are never restarted.
+	Command []string
+	OneShot bool
+
+	Env   map[string]string
+	Ports []int
+
+	CPU       float64 // Cores
+	MemoryMB  int64
+	PidsLimit int64
+
+	Egress *pb.CPEgressPolicy
+}
+
+// State is the lifecycle state of a workload.
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateStopped  State = "stopped" // Exited 0, or stopped by the agent
+	StateFailed   State = "failed"
+)
+
+// Status is the state of a workload and, once it has exited, its exit code.
+type Status struct {
+	State    State
+	ExitCode int
+}
+
+// Usage is the resource accounting of a workload's cgroup.
+type Usage struct {
+	CPUSeconds      float64 // CPU time consumed since the workload started
+	MemoryBytes     int64
+	MemoryPeakBytes int64 // Zero on kernels without memory.peak
+	Pids            int64
+	OOMKills        int64
+}
+
+// LogEntry is a line a workload wrote.
+type LogEntry struct {
+	Time    time.Time
+	Level   string // "debug", "info", "warn" or "error"
+	Message string
+}
+
+// namePattern matches workload names safe to use as container and unit names.
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+
+// WorkloadFromDeployRequest translates a deploy command from the control
+// plane into a workload. Pure-nix artifacts run under the driver the service
+// selected; OCI images always run as containers.
+func WorkloadFromDeployRequest(req *pb.CPDeployRequest) *Workload {
+	appName := req.GetAppName()
+	if appName == "" {
+		appName = req.GetAppId()
+	}
+	w := &Workload{
+		Name:         fmt.Sprintf("%s-%s-v%d", appName, req.GetServiceName(), req.GetVersion()),
+		DeploymentID: req.GetDeploymentId(),
+		AppID:        req.GetAppId(),
+		ServiceName:  req.GetServiceName(),
+		Artifact:     req.GetArtifact(),
+		Nix:          req.GetBuildType() == pb.CPBuildType_CP_BUILD_TYPE_NIX,
+		Runtime:      Container,
+	}
+
+	cfg := req.GetConfig()
+	if w.Nix && cfg.GetRuntime() == Systemd {
+		w.Runtime = Systemd
+	}
+	w.Command = cfg.GetCommand()
+	w.OneShot = len(w.Command) > 0
+	w.Env = cfg.GetEnvVars()
+	if port := cfg.GetPort(); port > 0 {
+		w.Ports = []int{int(port)}
+	}
+	w.Egress = cfg.GetEgress()
+
+	var spec *models.ResourceSpec
+	if r := cfg.GetResources(); r != nil {
+		spec = &models.ResourceSpec{CPU: r.GetCpu(), Memory: r.GetMemory()}
+	}
+	limits := podman.ResourceLimitsFromSpec(spec)
+	w.CPU, w.MemoryMB, w.PidsLimit = limits.CPUQuota, limits.MemoryMB, limits.PidsLimit
+	return w
+}
+
+// Select returns the driver that runs a workload.
+func Select(w *Workload, drivers ...Driver) (Driver, error) {
+	for _, d := range drivers {
+		if d.Name() == w.Runtime {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no %s driver on this node", ErrUnsupported, w.Runtime)
+}
+
+// runner runs a host command with extra environment variables and returns
+// its standard output.
+type runner func(ctx context.Context, env []string, name string, args ...string) ([]byte, error)
+
+// execRunner runs commands with os/exec, including their standard error in
+// the returned error.
+func execRunner(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(cmd.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// checkName rejects names that are not safe to use as container and unit names.
+func checkName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid workload name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/narvanalabs/control-plane/api/proto"
+)
+
+func TestWorkloadFromDeployRequest(t *testing.T) {
+	req := &pb.CPDeployRequest{
+		DeploymentId: "dep-1", AppId: "app-1", AppName: "shop", ServiceName: "api", Version: 3,
+		Artifact: "/nix/store/abc-api", BuildType: pb.CPBuildType_CP_BUILD_TYPE_NIX,
+		Config: &pb.CPDeploymentConfig{
+			Runtime:   Systemd,
+			Port:      8080,
+			Resources: &pb.CPResourceSpec{Cpu: "2", Memory: "1Gi"},
+		},
+	}
+
+	w := WorkloadFromDeployRequest(req)
+	if w.Name != "shop-api-v3" || w.Runtime != Systemd || !w.Nix || w.OneShot {
+		t.Errorf("workload = %+v", w)
+	}
+	if w.CPU != 2 || w.MemoryMB != 1024 || w.PidsLimit != 1000 || len(w.Ports) != 1 || w.Ports[0] != 8080 {
+		t.Errorf("limits = %v cpu, %d MB, %d pids, ports %v", w.CPU, w.MemoryMB, w.PidsLimit, w.Ports)
+	}
+
+	// OCI images run as containers whatever the service selected
+	req.BuildType = pb.CPBuildType_CP_BUILD_TYPE_OCI
+	if w := WorkloadFromDeployRequest(req); w.Runtime != Container {
+		t.Errorf("OCI runtime = %s, want container", w.Runtime)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	container := NewContainerDriver(ContainerConfig{})
+	systemd := NewSystemdDriver(SystemdConfig{})
+
+	if d, err := Select(&Workload{Runtime: Systemd}, container, systemd); err != nil || d != systemd {
+		t.Errorf("Select = %v, %v; want the systemd driver", d, err)
+	}
+	if _, err := Select(&Workload{Runtime: Systemd}, container); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Select without the driver = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestContainerDriverRunArgs(t *testing.T) {
+	runner := &fakeRunner{outputs: make(map[string]string)}
+	d := NewContainerDriver(ContainerConfig{Network: "narvana"})
+	d.run = runner.run
+
+	w := &Workload{
+		Name: "shop-api-v3", DeploymentID: "dep-1", Artifact: "ghcr.io/acme/api:3",
+		Env: map[string]string{"SECRET": "hunter2"}, Ports: []int{8080}, CPU: 0.5, MemoryMB: 512, PidsLimit: 200,
+	}
+	args, env, err := d.runArgs(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{"--cpus 0.5", "--memory 512m", "--pids-limit 200", "--restart on-failure", "--network narvana", "--publish 127.0.0.1:8080:8080", "--env SECRET ghcr.io/acme/api:3"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args %q are missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "hunter2") || len(env) != 1 || env[0] != "SECRET=hunter2" {
+		t.Errorf("env values must be passed in the environment: args %q, env %v", got, env)
+	}
+
+	// Pure-nix closures run in the base image with the store mounted
+	w = &Workload{Name: "shop-api-v3", ServiceName: "api", Artifact: nixClosure(t, "api"), Nix: true, OneShot: true}
+	args, _, err = d.runArgs(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = strings.Join(args, " ")
+	if !strings.HasSuffix(got, "/nix/store:/nix/store:ro "+d.cfg.NixImage+" "+w.Artifact+"/bin/api") || strings.Contains(got, "--restart") {
+		t.Errorf("pure-nix args = %q", got)
+	}
+}
+
+func TestContainerDriverStatus(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{
+		"podman ps --all --quiet --filter name=^web-v1$": "abc123\n",
+		"podman inspect": "exited 0\n",
+	}}
+	d := NewContainerDriver(ContainerConfig{})
+	d.run = runner.run
+
+	status, err := d.Status(context.Background(), "web-v1")
+	if err != nil || status.State != StateStopped {
+		t.Errorf("status = %+v, %v; want stopped", status, err)
+	}
+	if _, err := d.Status(context.Background(), "gone-v1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("status of a missing container = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,488 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemdConfig configures the systemd driver.
+type SystemdConfig struct {
+	// UnitDir is where unit files are written. The default, under /run, does
+	// not survive a reboot: the agent starts its deployments again anyway.
+	UnitDir string
+	// EnvDir holds each unit's environment file, readable only by root so
+	// secrets stay out of the unit file and `systemctl show`.
+	EnvDir string
+	// Slice groups the units, so their combined usage can be capped.
+	Slice      string
+	CgroupRoot string
+}
+
+// DefaultSystemdConfig returns the default systemd driver configuration.
+func DefaultSystemdConfig() SystemdConfig {
+	return SystemdConfig{
+		UnitDir:    "/run/systemd/system",
+		EnvDir:     "/run/narvana/env",
+		Slice:      "narvana.slice",
+		CgroupRoot: DefaultCgroupRoot,
+	}
+}
+
+// SystemdDriver runs pure-nix closures as transient, sandboxed systemd
+// units. Each unit runs under a dynamic user with a read-only view of the
+// host, has cgroup v2 limits and accounting enabled, and writes its output
+// to the journal.
+type SystemdDriver struct {
+	cfg    SystemdConfig
+	run    runner
+	lookup func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewSystemdDriver creates a systemd driver.
+func NewSystemdDriver(cfg SystemdConfig) *SystemdDriver {
+	defaults := DefaultSystemdConfig()
+	if cfg.UnitDir == "" {
+		cfg.UnitDir = defaults.UnitDir
+	}
+	if cfg.EnvDir == "" {
+		cfg.EnvDir = defaults.EnvDir
+	}
+	if cfg.Slice == "" {
+		cfg.Slice = defaults.Slice
+	}
+	if cfg.CgroupRoot == "" {
+		cfg.CgroupRoot = defaults.CgroupRoot
+	}
+	return &SystemdDriver{cfg: cfg, run: execRunner, lookup: net.DefaultResolver.LookupHost}
+}
+
+// Name returns Systemd.
+func (d *SystemdDriver) Name() string {
+	return Systemd
+}
+
+// Available returns an error unless the host runs systemd on a cgroup v2
+// hierarchy. Agents report CapabilitySystemdUnits only when it returns nil.
+func (d *SystemdDriver) Available() error {
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return errors.New("host is not running systemd")
+	}
+	if !cgroupV2Available(d.cfg.CgroupRoot) {
+		return errors.New("host does not use the unified cgroup v2 hierarchy")
+	}
+	return nil
+}
+
+// Start writes the workload's unit and environment file and starts it.
+func (d *SystemdDriver) Start(ctx context.Context, w *Workload) error {
+	if err := checkName(w.Name); err != nil {
+		return err
+	}
+	if !w.Nix {
+		return fmt.Errorf("%w: the systemd driver only runs pure-nix closures", ErrUnsupported)
+	}
+
+	allow, err := d.egressAllowList(ctx, w)
+	if err != nil {
+		return err
+	}
+	execStart, err := entrypoint(w)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.cfg.EnvDir, 0o700); err != nil {
+		return fmt.Errorf("creating env dir: %w", err)
+	}
+	envFile := filepath.Join(d.cfg.EnvDir, w.Name+".env")
+	if err := os.WriteFile(envFile, []byte(renderEnvFile(w.Env)), 0o600); err != nil {
+		return fmt.Errorf("writing env file: %w", err)
+	}
+	unit := renderUnit(w, execStart, envFile, d.cfg.Slice, allow)
+	if err := os.WriteFile(d.unitPath(w.Name), []byte(unit), 0o644); err != nil {
+		return fmt.Errorf("writing unit: %w", err)
+	}
+
+	if _, err := d.run(ctx, nil, "systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err = d.run(ctx, nil, "systemctl", "restart", unitName(w.Name))
+	return err
+}
+
+// Stop stops the unit and removes its unit and environment files.
+func (d *SystemdDriver) Stop(ctx context.Context, name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(d.unitPath(name)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if _, err := d.run(ctx, nil, "systemctl", "stop", unitName(name)); err != nil {
+		return err
+	}
+	// A failed unit stays loaded until its failure is cleared
+	d.run(ctx, nil, "systemctl", "reset-failed", unitName(name))
+
+	for _, path := range []string{d.unitPath(name), filepath.Join(d.cfg.EnvDir, name+".env")} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	_, err := d.run(ctx, nil, "systemctl", "daemon-reload")
+	return err
+}
+
+// Status returns the state of the unit.
+func (d *SystemdDriver) Status(ctx context.Context, name string) (*Status, error) {
+	props, err := d.show(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+	status.ExitCode, _ = strconv.Atoi(props["ExecMainStatus"])
+	switch props["ActiveState"] {
+	case "active", "reloading":
+		status.State = StateRunning
+	case "activating":
+		status.State = StateStarting
+	case "failed":
+		status.State = StateFailed
+	default:
+		// Inactive and deactivating: a clean exit or a stop succeeded
+		status.State = StateStopped
+		if props["Result"] != "" && props["Result"] != "success" {
+			status.State = StateFailed
+		}
+	}
+	return status, nil
+}
+
+// Usage reads the accounting of the unit's cgroup.
+func (d *SystemdDriver) Usage(ctx context.Context, name string) (*Usage, error) {
+	props, err := d.show(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return readCgroupUsage(d.cfg.CgroupRoot, props["ControlGroup"])
+}
+
+// Logs reads the unit's output from the journal.
+func (d *SystemdDriver) Logs(ctx context.Context, name string, since time.Time) ([]LogEntry, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	out, err := d.run(ctx, nil, "journalctl", "--unit", unitName(name), "--output", "json", "--no-pager",
+		"--since", fmt.Sprintf("@%d", since.Unix()))
+	if err != nil {
+		return nil, err
+	}
+	return parseJournal(out, since)
+}
+
+// show returns properties of a loaded unit.
+func (d *SystemdDriver) show(ctx context.Context, name string) (map[string]string, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	out, err := d.run(ctx, nil, "systemctl", "show", unitName(name),
+		"--property", "LoadState,ActiveState,Result,ExecMainStatus,ControlGroup")
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			props[key] = value
+		}
+	}
+	if props["LoadState"] == "not-found" {
+		return nil, ErrNotFound
+	}
+	return props, nil
+}
+
+// egressAllowList returns the addresses a workload with a deny-all egress
+// policy may connect to, or nil when egress is open. Units filter by address
+// only, so rules limited to ports or a protocol need the container driver.
+func (d *SystemdDriver) egressAllowList(ctx context.Context, w *Workload) ([]string, error) {
+	if !w.Egress.GetDenyAll() {
+		return nil, nil
+	}
+	allow := []string{"localhost"}
+	for _, rule := range w.Egress.GetAllow() {
+		if len(rule.GetPorts()) > 0 || rule.GetProtocol() != "" {
+			return nil, fmt.Errorf("%w: the systemd driver cannot limit egress to ports or a protocol", ErrUnsupported)
+		}
+		if rule.GetCidr() != "" {
+			allow = append(allow, rule.GetCidr())
+			continue
+		}
+		// Hosts are resolved once, when the unit starts
+		addrs, err := d.lookup(ctx, rule.GetHost())
+		if err != nil {
+			return nil, fmt.Errorf("resolving egress host %s: %w", rule.GetHost(), err)
+		}
+		allow = append(allow, addrs...)
+	}
+	return allow, nil
+}
+
+func (d *SystemdDriver) unitPath(name string) string {
+	return filepath.Join(d.cfg.UnitDir, unitName(name))
+}
+
+func unitName(name string) string {
+	return name + ".service"
+}
+
+// entrypoint returns the command a pure-nix workload runs: the workload's command, with
+// a bare program name looked up in the closure's bin directory, or else the
+// closure's only program.
+func entrypoint(w *Workload) ([]string, error) {
+	bin := filepath.Join(w.Artifact, "bin")
+	if len(w.Command) > 0 {
+		cmd := append([]string(nil), w.Command...)
+		if !strings.Contains(cmd[0], "/") {
+			cmd[0] = filepath.Join(bin, cmd[0])
+		}
+		return cmd, nil
+	}
+
+	entries, err := os.ReadDir(bin)
+	if err != nil {
+		return nil, fmt.Errorf("reading closure programs: %w", err)
+	}
+	var programs []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			programs = append(programs, e.Name())
+		}
+	}
+	switch {
+	case len(programs) == 1:
+		return []string{filepath.Join(bin, programs[0])}, nil
+	case contains(programs, w.ServiceName):
+		return []string{filepath.Join(bin, w.ServiceName)}, nil
+	default:
+		return nil, fmt.Errorf("closure %s has %d programs and none is named %s; set a start command", w.Artifact, len(programs), w.ServiceName)
+	}
+}
+
+// renderUnit returns the unit file that runs a workload.
+func renderUnit(w *Workload, execStart []string, envFile, slice string, egressAllow []string) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("[Unit]")
+	line("Description=Narvana %s/%s (deployment %s)", w.AppID, w.ServiceName, w.DeploymentID)
+	line("Wants=network-online.target")
+	line("After=network-online.target")
+	line("")
+	line("[Service]")
+	line("Type=exec")
+	line("ExecStart=%s", quoteExec(execStart))
+	line("EnvironmentFile=%s", envFile)
+	if w.OneShot {
+		line("Restart=no")
+	} else {
+		line("Restart=on-failure")
+		line("RestartSec=2s")
+	}
+	line("TimeoutStopSec=30s")
+
+	// Journal capture, tagged with the deployment for the agent's log shipper
+	line("StandardOutput=journal")
+	line("StandardError=journal")
+	line("SyslogIdentifier=%s", w.Name)
+	line("LogExtraFields=NARVANA_DEPLOYMENT_ID=%s", w.DeploymentID)
+
+	// cgroup v2 limits and accounting
+	line("Slice=%s", slice)
+	line("CPUAccounting=yes")
+	line("MemoryAccounting=yes")
+	line("TasksAccounting=yes")
+	line("IOAccounting=yes")
+	line("CPUQuota=%d%%", int(w.CPU*100))
+	line("MemoryMax=%dM", w.MemoryMB)
+	line("MemorySwapMax=0")
+	line("TasksMax=%d", w.PidsLimit)
+
+	// Sandboxing: a throwaway user that sees the Nix store and its own state
+	// directory, and nothing else writable. MemoryDenyWriteExecute is left
+	// off because it breaks JIT runtimes such as Node.js and the JVM.
+	line("DynamicUser=yes")
+	line("StateDirectory=narvana/%s", w.Name)
+	line("WorkingDirectory=%%S/narvana/%s", w.Name)
+	line("UMask=0077")
+	line("NoNewPrivileges=yes")
+	line("ProtectSystem=strict")
+	line("ProtectHome=yes")
+	line("PrivateTmp=yes")
+	line("PrivateDevices=yes")
+	line("PrivateIPC=yes")
+	line("ProtectHostname=yes")
+	line("ProtectClock=yes")
+	line("ProtectKernelTunables=yes")
+	line("ProtectKernelModules=yes")
+	line("ProtectKernelLogs=yes")
+	line("ProtectControlGroups=yes")
+	line("ProtectProc=invisible")
+	line("ProcSubset=pid")
+	line("RestrictNamespaces=yes")
+	line("RestrictRealtime=yes")
+	line("RestrictSUIDSGID=yes")
+	line("RemoveIPC=yes")
+	line("LockPersonality=yes")
+	line("RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6")
+	line("SystemCallArchitectures=native")
+	line("SystemCallFilter=@system-service")
+	line("SystemCallFilter=~@privileged @resources")
+	line("SystemCallErrorNumber=EPERM")
+
+	// Only privileged ports need a capability; the service may bind only its own ports
+	lowPort := false
+	for _, p := range w.Ports {
+		lowPort = lowPort || p < 1024
+	}
+	if lowPort {
+		line("CapabilityBoundingSet=CAP_NET_BIND_SERVICE")
+		line("AmbientCapabilities=CAP_NET_BIND_SERVICE")
+	} else {
+		line("CapabilityBoundingSet=")
+	}
+	if len(w.Ports) > 0 {
+		for _, p := range w.Ports {
+			line("SocketBindAllow=%d", p)
+		}
+		line("SocketBindDeny=any")
+	}
+
+	if egressAllow != nil {
+		line("IPAddressDeny=any")
+		line("IPAddressAllow=%s", strings.Join(egressAllow, " "))
+	}
+	return b.String()
+}
+
+// envNamePattern matches environment variable names systemd accepts.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// renderEnvFile returns an EnvironmentFile= file setting env, sorted by name.
+// Variables with names systemd rejects are skipped.
+func renderEnvFile(env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if envNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	// Inside double quotes newlines are kept as is
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=\"%s\"\n", name, escaper.Replace(env[name]))
+	}
+	return b.String()
+}
+
+// quoteExec quotes a command line for ExecStart=, escaping the specifiers
+// and variable expansions systemd would otherwise apply.
+func quoteExec(args []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$", "\n", `\n`)
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = `"` + escaper.Replace(arg) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// journalEntry is the part of a journalctl JSON record the driver reads.
+type journalEntry struct {
+	Message  json.RawMessage `json:"MESSAGE"`
+	Priority string          `json:"PRIORITY"`
+	Realtime string          `json:"__REALTIME_TIMESTAMP"`
+}
+
+// parseJournal parses journalctl --output json, keeping entries after since.
+func parseJournal(out []byte, since time.Time) ([]LogEntry, error) {
+	var entries []LogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parsing journal entry: %w", err)
+		}
+		usec, _ := strconv.ParseInt(e.Realtime, 10, 64)
+		t := time.UnixMicro(usec)
+		if !t.After(since) {
+			continue
+		}
+		entries = append(entries, LogEntry{Time: t, Level: journalLevel(e.Priority), Message: journalMessage(e.Message)})
+	}
+	return entries, scanner.Err()
+}
+
+// journalMessage decodes MESSAGE, which journalctl writes as an array of
+// bytes when it is not valid UTF-8.
+func journalMessage(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var data []byte
+	var ints []int
+	if json.Unmarshal(raw, &ints) == nil {
+		for _, i := range ints {
+			data = append(data, byte(i))
+		}
+	}
+	return string(data)
+}
+
+// journalLevel maps a syslog priority to a log level.
+func journalLevel(priority string) string {
+	p, err := strconv.Atoi(priority)
+	switch {
+	case err != nil:
+		return "info"
+	case p <= 3:
+		return "error"
+	case p == 4:
+		return "warn"
+	case p == 7:
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
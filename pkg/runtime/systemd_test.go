@@ -0,0 +1,203 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/narvanalabs/control-plane/api/proto"
+)
+
+// fakeRunner records commands and answers them from canned output.
+type fakeRunner struct {
+	calls   []string
+	outputs map[string]string
+}
+
+func (f *fakeRunner) run(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+	for prefix, out := range f.outputs {
+		if strings.HasPrefix(call, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+// nixClosure creates a fake store path with the given programs.
+func nixClosure(t *testing.T, programs ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range programs {
+		if err := os.WriteFile(filepath.Join(dir, "bin", p), nil, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newTestSystemdDriver(t *testing.T) (*SystemdDriver, *fakeRunner) {
+	t.Helper()
+	runner := &fakeRunner{outputs: make(map[string]string)}
+	d := NewSystemdDriver(SystemdConfig{UnitDir: t.TempDir(), EnvDir: t.TempDir(), CgroupRoot: t.TempDir()})
+	d.run = runner.run
+	d.lookup = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"203.0.113.7"}, nil
+	}
+	return d, runner
+}
+
+func TestSystemdDriverStartWritesHardenedUnit(t *testing.T) {
+	d, runner := newTestSystemdDriver(t)
+	w := &Workload{
+		Name: "shop-api-v3", DeploymentID: "dep-1", AppID: "app-1", ServiceName: "api",
+		Artifact: nixClosure(t, "api"), Nix: true, Runtime: Systemd,
+		Env:   map[string]string{"DATABASE_URL": `postgres://u:p@db/"shop"`, "PEM": "line1\nline2", "bad name": "x"},
+		Ports: []int{8080}, CPU: 0.5, MemoryMB: 512, PidsLimit: 200,
+		Egress: &pb.CPEgressPolicy{DenyAll: true, Allow: []*pb.CPEgressRule{{Cidr: "10.0.0.0/8"}, {Host: "api.stripe.com"}}},
+	}
+
+	if err := d.Start(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+	unit, err := os.ReadFile(filepath.Join(d.cfg.UnitDir, "shop-api-v3.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, directive := range []string{
+		`ExecStart="` + w.Artifact + `/bin/api"`,
+		"Restart=on-failure",
+		"DynamicUser=yes",
+		"ProtectSystem=strict",
+		"NoNewPrivileges=yes",
+		"CapabilityBoundingSet=\n",
+		"SystemCallFilter=@system-service",
+		"CPUAccounting=yes",
+		"MemoryAccounting=yes",
+		"CPUQuota=50%",
+		"MemoryMax=512M",
+		"TasksMax=200",
+		"Slice=narvana.slice",
+		"StandardOutput=journal",
+		"LogExtraFields=NARVANA_DEPLOYMENT_ID=dep-1",
+		"SocketBindAllow=8080\nSocketBindDeny=any",
+		"IPAddressDeny=any\nIPAddressAllow=localhost 10.0.0.0/8 203.0.113.7",
+	} {
+		if !strings.Contains(string(unit), directive) {
+			t.Errorf("unit is missing %q:\n%s", directive, unit)
+		}
+	}
+	if strings.Contains(string(unit), "postgres://") {
+		t.Error("unit file contains an environment value")
+	}
+
+	envFile := filepath.Join(d.cfg.EnvDir, "shop-api-v3.env")
+	info, err := os.Stat(envFile)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("env file = %v, %v; want mode 0600", info, err)
+	}
+	env, _ := os.ReadFile(envFile)
+	if want := "DATABASE_URL=\"postgres://u:p@db/\\\"shop\\\"\"\nPEM=\"line1\nline2\"\n"; string(env) != want {
+		t.Errorf("env file = %q, want %q", env, want)
+	}
+
+	if got := strings.Join(runner.calls, "; "); got != "systemctl daemon-reload; systemctl restart shop-api-v3.service" {
+		t.Errorf("commands = %s", got)
+	}
+
+	if err := d.Stop(context.Background(), "shop-api-v3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(envFile); !errors.Is(err, os.ErrNotExist) {
+		t.Error("env file was not removed")
+	}
+}
+
+func TestSystemdDriverRejectsUnsupportedWorkloads(t *testing.T) {
+	d, _ := newTestSystemdDriver(t)
+	closure := nixClosure(t, "api", "worker")
+
+	for name, w := range map[string]*Workload{
+		"oci image":     {Name: "web-v1", Artifact: "ghcr.io/acme/web:1"},
+		"port egress":   {Name: "web-v1", Artifact: closure, Nix: true, Egress: &pb.CPEgressPolicy{DenyAll: true, Allow: []*pb.CPEgressRule{{Cidr: "10.0.0.0/8", Ports: []int32{5432}}}}},
+		"no entrypoint": {Name: "web-v1", ServiceName: "web", Artifact: closure, Nix: true},
+		"bad name":      {Name: "../web", Artifact: closure, Nix: true},
+	} {
+		if err := d.Start(context.Background(), w); err == nil {
+			t.Errorf("%s: Start succeeded", name)
+		}
+	}
+
+	// A cron run's command is looked up in the closure and never restarted
+	w := &Workload{Name: "web-v1", ServiceName: "web", Artifact: closure, Nix: true, Command: []string{"worker", "--once", "100%"}, OneShot: true}
+	if err := d.Start(context.Background(), w); err != nil {
+		t.Fatal(err)
+	}
+	unit, _ := os.ReadFile(filepath.Join(d.cfg.UnitDir, "web-v1.service"))
+	if !strings.Contains(string(unit), `ExecStart="`+closure+`/bin/worker" "--once" "100%%"`) || !strings.Contains(string(unit), "Restart=no") {
+		t.Errorf("unit =\n%s", unit)
+	}
+}
+
+func TestSystemdDriverStatusAndUsage(t *testing.T) {
+	d, runner := newTestSystemdDriver(t)
+	cgroup := filepath.Join(d.cfg.CgroupRoot, "narvana.slice", "web-v1.service")
+	os.MkdirAll(cgroup, 0o755)
+	for file, content := range map[string]string{
+		"cpu.stat":       "usage_usec 2500000\nuser_usec 2000000\n",
+		"memory.current": "104857600\n",
+		"memory.peak":    "209715200\n",
+		"pids.current":   "12\n",
+		"memory.events":  "low 0\noom 1\noom_kill 1\n",
+	} {
+		os.WriteFile(filepath.Join(cgroup, file), []byte(content), 0o644)
+	}
+
+	runner.outputs["systemctl show web-v1.service"] = "LoadState=loaded\nActiveState=inactive\nResult=exit-code\nExecMainStatus=3\nControlGroup=/narvana.slice/web-v1.service\n"
+	status, err := d.Status(context.Background(), "web-v1")
+	if err != nil || status.State != StateFailed || status.ExitCode != 3 {
+		t.Errorf("status = %+v, %v; want failed with exit code 3", status, err)
+	}
+
+	usage, err := d.Usage(context.Background(), "web-v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.CPUSeconds != 2.5 || usage.MemoryBytes != 100<<20 || usage.MemoryPeakBytes != 200<<20 || usage.Pids != 12 || usage.OOMKills != 1 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	runner.outputs["systemctl show gone-v1.service"] = "LoadState=not-found\nActiveState=inactive\n"
+	if _, err := d.Status(context.Background(), "gone-v1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("status of a missing unit = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParseJournal(t *testing.T) {
+	since := time.UnixMicro(1700000000000000)
+	out := `{"MESSAGE":"old","PRIORITY":"6","__REALTIME_TIMESTAMP":"1700000000000000"}
+{"MESSAGE":"listening on :8080","PRIORITY":"6","__REALTIME_TIMESTAMP":"1700000001000000"}
+{"MESSAGE":[104,105,255],"PRIORITY":"3","__REALTIME_TIMESTAMP":"1700000002000000"}
+`
+	entries, err := parseJournal([]byte(out), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want the 2 after since", entries)
+	}
+	if entries[0].Message != "listening on :8080" || entries[0].Level != "info" {
+		t.Errorf("entry = %+v", entries[0])
+	}
+	if entries[1].Message != "hi\xff" || entries[1].Level != "error" {
+		t.Errorf("binary entry = %+v", entries[1])
+	}
+}
@@ -0,0 +1,275 @@
+// Package sdk helps services running on Narvana use their workload identity:
+// reading the token the node agent keeps fresh, exchanging it for tokens
+// scoped to other services, and verifying the tokens other workloads present.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Environment variables set on workloads by the node agent.
+const (
+	// TokenFileEnv names the file holding the workload's identity token.
+	TokenFileEnv = "NARVANA_IDENTITY_TOKEN_FILE"
+	// IssuerEnv is the issuer of workload tokens, the control plane URL.
+	IssuerEnv = "NARVANA_IDENTITY_ISSUER"
+)
+
+// APIVersion is the control plane API version the SDK is written against.
+const APIVersion = "1.1"
+
+// DefaultTokenFile is where the node agent writes the identity token when
+// TokenFileEnv is unset.
+const DefaultTokenFile = "/run/narvana/identity/token"
+
+// jwksPath is the path below the issuer where its keys are published.
+const jwksPath = "/.well-known/jwks.json"
+
+// Errors returned by the SDK.
+var (
+	ErrNoToken      = errors.New("no workload identity token")
+	ErrInvalidToken = errors.New("invalid workload identity token")
+)
+
+// Claims are the claims of a workload identity token. The subject is
+// "app:<appID>:service:<service>".
+type Claims struct {
+	jwt.RegisteredClaims
+	OrgID        string `json:"org_id,omitempty"`
+	AppID        string `json:"app_id"`
+	Service      string `json:"service"`
+	DeploymentID string `json:"deployment_id"`
+	NodeID       string `json:"node_id"`
+}
+
+// ReadToken reads the workload's current identity token. The node agent
+// rotates the token before it expires, so read it again for every use rather
+// than caching it.
+func ReadToken() (string, error) {
+	path := os.Getenv(TokenFileEnv)
+	if path == "" {
+		path = DefaultTokenFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s does not exist", ErrNoToken, path)
+		}
+		return "", fmt.Errorf("reading identity token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrNoToken, path)
+	}
+	return token, nil
+}
+
+// Exchange trades the workload's identity token for one whose audience is
+// the given services. issuer is the control plane URL, normally the value
+// of IssuerEnv.
+func Exchange(ctx context.Context, client *http.Client, issuer, token string, audience ...string) (string, time.Time, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(map[string][]string{"audience": audience})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(issuer, "/")+"/v1/workload-identity/token", bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Narvana-API-Version", APIVersion)
+	req.Header.Set("Narvana-Client", "narvana-sdk")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("exchanging identity token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("exchanging identity token: unexpected status %s", resp.Status)
+	}
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", time.Time{}, fmt.Errorf("decoding exchanged token: %w", err)
+	}
+	return out.Token, out.ExpiresAt, nil
+}
+
+// Verifier checks workload identity tokens presented to a service. It only
+// accepts tokens issued for its audience, so a token meant for one service
+// cannot be replayed against another.
+type Verifier struct {
+	issuer   string
+	audience string
+	client   *http.Client
+
+	// RefreshInterval is how long fetched keys are trusted before the key
+	// set is fetched again.
+	RefreshInterval time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]*ecdsa.PublicKey
+	fetched time.Time
+}
+
+// NewVerifier creates a verifier for tokens from issuer addressed to
+// audience. A nil client uses http.DefaultClient.
+func NewVerifier(issuer, audience string, client *http.Client) *Verifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Verifier{
+		issuer:          strings.TrimSuffix(issuer, "/"),
+		audience:        audience,
+		client:          client,
+		RefreshInterval: 10 * time.Minute,
+	}
+}
+
+// Verify checks a token's signature, issuer, audience and expiry and
+// returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// key returns the public key with the ID kid, fetching the key set if the
+// key is unknown or the cached set is stale.
+func (v *Verifier) key(ctx context.Context, kid string) (*ecdsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok && time.Since(v.fetched) < v.RefreshInterval {
+		return key, nil
+	}
+	// Unknown keys refetch at most once a minute so bad tokens can't flood the issuer
+	if v.keys != nil && time.Since(v.fetched) < time.Minute {
+		if key, ok := v.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	keys, err := v.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.keys, v.fetched = keys, time.Now()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// fetch downloads the issuer's key set.
+func (v *Verifier) fetch(ctx context.Context) (map[string]*ecdsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.issuer+jwksPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching key set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching key set: unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+			KeyID   string `json:"kid"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding key set: %w", err)
+	}
+
+	keys := make(map[string]*ecdsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "EC" || k.Curve != "P-256" {
+			continue
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			continue
+		}
+		// Reject points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			continue
+		}
+		keys[k.KeyID] = &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+	}
+	return keys, nil
+}
+
+// claimsKey is the context key for verified claims.
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims verified by Middleware, or nil.
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
+
+// Middleware rejects requests without a valid bearer token for the
+// verifier's audience and stores the token's claims in the request context.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "missing workload identity token", http.StatusUnauthorized)
+			return
+		}
+		claims, err := v.Verify(r.Context(), token)
+		if err != nil {
+			http.Error(w, "invalid workload identity token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+	})
+}
@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/identity"
+	"github.com/narvanalabs/control-plane/internal/models"
+)
+
+// newIssuerServer serves the JWKS of a fresh issuer.
+func newIssuerServer(t *testing.T) (*identity.Issuer, *httptest.Server) {
+	t.Helper()
+	key, err := identity.LoadOrCreateSigningKey(filepath.Join(t.TempDir(), "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issuer *identity.Issuer
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != jwksPath {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(issuer.JWKS())
+	}))
+	t.Cleanup(srv.Close)
+	issuer = identity.NewIssuer(identity.Config{Issuer: srv.URL, TokenTTL: time.Minute}, key)
+	return issuer, srv
+}
+
+func TestVerifierChecksAudience(t *testing.T) {
+	issuer, srv := newIssuerServer(t)
+	app := &models.App{ID: "app-1"}
+	deployment := &models.Deployment{ID: "dep-1", ServiceName: "web", NodeID: "node-1"}
+
+	token, _, err := issuer.Issue(app, deployment, []string{"billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := NewVerifier(srv.URL, "billing", srv.Client()).Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.AppID != "app-1" || claims.Service != "web" || claims.Subject != "app:app-1:service:web" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	if _, err := NewVerifier(srv.URL, "search", srv.Client()).Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("verify for another audience = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifierMiddleware(t *testing.T) {
+	issuer, srv := newIssuerServer(t)
+	token, _, err := issuer.Issue(&models.App{ID: "app-1"}, &models.Deployment{ID: "dep-1", ServiceName: "worker"}, []string{"billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var caller string
+	handler := NewVerifier(srv.URL, "billing", srv.Client()).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller = ClaimsFromContext(r.Context()).Subject
+	}))
+
+	serve := func(auth string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(""); code != http.StatusUnauthorized {
+		t.Errorf("no token = %d, want 401", code)
+	}
+	if code := serve("Bearer not-a-token"); code != http.StatusUnauthorized {
+		t.Errorf("bad token = %d, want 401", code)
+	}
+	if code := serve("Bearer " + token); code != http.StatusOK {
+		t.Fatalf("valid token = %d, want 200", code)
+	}
+	if caller != "app:app-1:service:worker" {
+		t.Errorf("caller = %q", caller)
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	t.Setenv(TokenFileEnv, path)
+
+	if _, err := ReadToken(); !errors.Is(err, ErrNoToken) {
+		t.Errorf("missing file = %v, want ErrNoToken", err)
+	}
+	if err := os.WriteFile(path, []byte("abc.def.ghi\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := ReadToken()
+	if err != nil || token != "abc.def.ghi" {
+		t.Errorf("ReadToken = %q, %v", token, err)
+	}
+}
@@ -0,0 +1,208 @@
+package scripts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+	"gopkg.in/yaml.v3"
+)
+
+// ReposManifest lists the repositories that make up a platform release.
+//
+// Example release-repos.yaml:
+//
+//	components:
+//	  - name: Control Plane
+//	    repo: narvanalabs/control-plane
+//	    path: .
+//	    from: v1.1.0
+//	  - name: Node Agent
+//	    repo: narvanalabs/node-agent
+//	    notes: node-agent-commits.txt
+type ReposManifest struct {
+	Components []ManifestComponent `yaml:"components"`
+
+	dir string // directory of the manifest file, for resolving relative paths
+}
+
+// ManifestComponent describes where to read one component's commits from.
+// Either Notes (a commit list file) or Path (a local git checkout) must be set.
+type ManifestComponent struct {
+	Name  string `yaml:"name"`            // Section heading, e.g. "Control Plane"
+	Repo  string `yaml:"repo"`            // GitHub repository (owner/name)
+	Notes string `yaml:"notes,omitempty"` // File with commit messages, one per line
+	Path  string `yaml:"path,omitempty"`  // Local git checkout
+	From  string `yaml:"from,omitempty"`  // Start of the commit range (exclusive), e.g. the previous tag
+	To    string `yaml:"to,omitempty"`    // End of the commit range (default: HEAD)
+}
+
+// LoadReposManifest reads and validates a repos manifest.
+func LoadReposManifest(path string) (*ReposManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repos manifest: %w", err)
+	}
+
+	var manifest ReposManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse repos manifest: %w", err)
+	}
+	manifest.dir = filepath.Dir(path)
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Validate checks that every component has a name and exactly one commit source.
+func (m *ReposManifest) Validate() error {
+	if len(m.Components) == 0 {
+		return fmt.Errorf("repos manifest has no components")
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range m.Components {
+		if c.Name == "" {
+			return fmt.Errorf("component %d: name is required", i+1)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("component %q: duplicate name", c.Name)
+		}
+		seen[c.Name] = true
+		if (c.Notes == "") == (c.Path == "") {
+			return fmt.Errorf("component %q: exactly one of notes or path is required", c.Name)
+		}
+	}
+	return nil
+}
+
+// resolve interprets relative paths against the manifest's directory.
+func (m *ReposManifest) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || m.dir == "" {
+		return path
+	}
+	return filepath.Join(m.dir, path)
+}
+
+// ReadCommits returns the raw commit list for a component, one commit per line.
+func (m *ReposManifest) ReadCommits(ctx context.Context, c ManifestComponent) (string, error) {
+	if c.Notes != "" {
+		data, err := os.ReadFile(m.resolve(c.Notes))
+		if err != nil {
+			return "", fmt.Errorf("failed to read notes for %s: %w", c.Name, err)
+		}
+		return string(data), nil
+	}
+
+	to := c.To
+	if to == "" {
+		to = "HEAD"
+	}
+	revRange := to
+	if c.From != "" {
+		revRange = c.From + ".." + to
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", m.resolve(c.Path), "log", "--no-merges", "--format=%s", revRange).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git log for %s: %w", c.Name, err)
+	}
+	return string(out), nil
+}
+
+// ComponentNotes is one component's processed commits within a combined release.
+type ComponentNotes struct {
+	Name     string
+	Repo     string
+	RawNotes string
+	Content  releasenotes.CategorizedContent
+}
+
+// AggregateComponents reads and processes every component in the manifest, in
+// manifest order.
+func AggregateComponents(ctx context.Context, manifest *ReposManifest, opts SummaryOptions) ([]ComponentNotes, error) {
+	components := make([]ComponentNotes, 0, len(manifest.Components))
+	for _, c := range manifest.Components {
+		raw, err := manifest.ReadCommits(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		content := releasenotes.ProcessCommitsWithFallback(releasenotes.ParseRawCommits(raw), releasenotes.DefaultNoiseFilterConfig())
+		components = append(components, ComponentNotes{
+			Name:     c.Name,
+			Repo:     c.Repo,
+			RawNotes: raw,
+			Content:  SummarizeContent(ctx, content, opts),
+		})
+	}
+	return components, nil
+}
+
+// GenerateCombinedReleaseNotes generates a single release document with one
+// section per component. Components without user-facing changes are omitted.
+func GenerateCombinedReleaseNotes(components []ComponentNotes, config ReleaseNotesConfig) (string, error) {
+	var sb strings.Builder
+	if err := writeReleaseNotesHeader(&sb, config); err != nil {
+		return "", err
+	}
+
+	componentsWritten := false
+	for _, c := range components {
+		if !c.Content.HasContent() {
+			continue
+		}
+
+		var section strings.Builder
+		if !writeSections(&section, c.Content, "### ") {
+			continue
+		}
+		sb.WriteString("## ")
+		sb.WriteString(c.Name)
+		sb.WriteString("\n\n")
+		sb.WriteString(section.String())
+		componentsWritten = true
+	}
+
+	if !componentsWritten {
+		sb.WriteString("No significant changes in this release.\n\n")
+	}
+
+	writeReleaseNotesClosing(&sb, config)
+	return sb.String(), nil
+}
+
+// CombineRawNotes joins the component commit lists into a single bulleted list
+// for CHANGELOG.md.
+func CombineRawNotes(components []ComponentNotes) string {
+	var sb strings.Builder
+	for _, c := range components {
+		for _, commit := range releasenotes.ParseRawCommits(c.RawNotes) {
+			sb.WriteString("- ")
+			sb.WriteString(commit)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// NewCombinedReleaseDocument builds a release document covering every
+// component in the manifest.
+func NewCombinedReleaseDocument(ctx context.Context, manifest *ReposManifest, config ReleaseNotesConfig, repo string, opts SummaryOptions) (*ReleaseDocument, error) {
+	components, err := AggregateComponents(ctx, manifest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	markdown, err := GenerateCombinedReleaseNotes(components, config)
+	if err != nil {
+		return nil, err
+	}
+	return newReleaseDocument(CombineRawNotes(components), markdown, config, repo)
+}
@@ -0,0 +1,119 @@
+package scripts
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to dir/name and returns the path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// TestLoadReposManifestValidation tests that invalid manifests are rejected.
+func TestLoadReposManifestValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"no components", "components: []\n", "no components"},
+		{"missing name", "components:\n  - notes: a.txt\n", "name is required"},
+		{"no source", "components:\n  - name: CLI\n", "exactly one of notes or path"},
+		{"both sources", "components:\n  - name: CLI\n    notes: a.txt\n    path: .\n", "exactly one of notes or path"},
+		{"duplicate", "components:\n  - name: CLI\n    notes: a.txt\n  - name: CLI\n    notes: b.txt\n", "duplicate name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "repos.yaml", tt.manifest)
+			_, err := LoadReposManifest(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestGenerateCombinedReleaseNotes tests per-component sections in manifest order.
+func TestGenerateCombinedReleaseNotes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "cp.txt", "feat(api): add release publishing\nfix(ui): correct banner spacing\n")
+	writeFile(t, dir, "agent.txt", "feat(runtime): support podman\n")
+	writeFile(t, dir, "cli.txt", "chore: bump deps\n")
+	path := writeFile(t, dir, "repos.yaml", `components:
+  - name: Control Plane
+    repo: narvanalabs/control-plane
+    notes: cp.txt
+  - name: Node Agent
+    repo: narvanalabs/node-agent
+    notes: agent.txt
+  - name: CLI
+    repo: narvanalabs/cli
+    notes: cli.txt
+`)
+
+	manifest, err := LoadReposManifest(path)
+	if err != nil {
+		t.Fatalf("LoadReposManifest() error = %v", err)
+	}
+
+	doc, err := NewCombinedReleaseDocument(context.Background(), manifest,
+		ReleaseNotesConfig{Version: "1.2.0", Date: "2026-01-15"}, "narvanalabs/control-plane", SummaryOptions{})
+	if err != nil {
+		t.Fatalf("NewCombinedReleaseDocument() error = %v", err)
+	}
+
+	cp := strings.Index(doc.Markdown, "## Control Plane")
+	agent := strings.Index(doc.Markdown, "## Node Agent")
+	if cp == -1 || agent == -1 || cp > agent {
+		t.Fatalf("expected component sections in manifest order, got:\n%s", doc.Markdown)
+	}
+	if strings.Contains(doc.Markdown, "## CLI") {
+		t.Error("expected component with only noise commits to be omitted")
+	}
+	if !strings.Contains(doc.Markdown[agent:], "### ") || !strings.Contains(doc.Markdown[agent:], "Support podman") {
+		t.Errorf("expected nested sections under Node Agent, got:\n%s", doc.Markdown[agent:])
+	}
+	if !strings.Contains(doc.RawNotes, "- feat(runtime): support podman") {
+		t.Errorf("expected combined raw notes, got %q", doc.RawNotes)
+	}
+}
+
+// TestReadCommitsFromGit tests reading a commit range from a local checkout.
+func TestReadCommitsFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "feat: initial release")
+	git("tag", "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "feat(api): add webhooks")
+
+	manifest := &ReposManifest{Components: []ManifestComponent{{Name: "Control Plane", Path: dir, From: "v1.0.0"}}}
+	raw, err := manifest.ReadCommits(context.Background(), manifest.Components[0])
+	if err != nil {
+		t.Fatalf("ReadCommits() error = %v", err)
+	}
+	if strings.TrimSpace(raw) != "feat(api): add webhooks" {
+		t.Errorf("expected only commits after v1.0.0, got %q", raw)
+	}
+}
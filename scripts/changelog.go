@@ -7,16 +7,9 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
-	"time"
-)
 
-// ChangelogEntry represents a single release entry in the changelog.
-type ChangelogEntry struct {
-	Version     string    // e.g., "1.0.0"
-	Date        time.Time // Release date
-	ReleaseURL  string    // URL to the GitHub release
-	ReleaseNotes string   // Release notes content (markdown)
-}
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
 
 // changelogEntryTemplate is the template for a single changelog entry.
 const changelogEntryTemplate = `## [{{.Version}}] - {{.FormattedDate}}
@@ -50,7 +43,7 @@ type linkTemplateData struct {
 }
 
 // GenerateChangelogEntry generates a single changelog entry in conventional format.
-func GenerateChangelogEntry(entry ChangelogEntry) (string, error) {
+func GenerateChangelogEntry(entry releasenotes.ChangelogEntry) (string, error) {
 	if entry.Version == "" {
 		return "", fmt.Errorf("version is required")
 	}
@@ -78,7 +71,7 @@ func GenerateChangelogEntry(entry ChangelogEntry) (string, error) {
 }
 
 // GenerateChangelogLink generates a version link for the changelog footer.
-func GenerateChangelogLink(entry ChangelogEntry) (string, error) {
+func GenerateChangelogLink(entry releasenotes.ChangelogEntry) (string, error) {
 	if entry.Version == "" {
 		return "", fmt.Errorf("version is required")
 	}
@@ -106,7 +99,7 @@ func GenerateChangelogLink(entry ChangelogEntry) (string, error) {
 
 // PrependChangelogEntry prepends a new entry to an existing changelog.
 // It preserves the header and inserts the new entry after it.
-func PrependChangelogEntry(existingChangelog string, entry ChangelogEntry) (string, error) {
+func PrependChangelogEntry(existingChangelog string, entry releasenotes.ChangelogEntry) (string, error) {
 	newEntry, err := GenerateChangelogEntry(entry)
 	if err != nil {
 		return "", err
@@ -166,7 +159,7 @@ func PrependChangelogEntry(existingChangelog string, entry ChangelogEntry) (stri
 }
 
 // CreateNewChangelog creates a new changelog with a single entry.
-func CreateNewChangelog(entry ChangelogEntry) (string, error) {
+func CreateNewChangelog(entry releasenotes.ChangelogEntry) (string, error) {
 	return PrependChangelogEntry("", entry)
 }
 
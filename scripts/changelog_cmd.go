@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
 	"github.com/narvanalabs/control-plane/scripts"
 )
 
@@ -57,7 +58,7 @@ func main() {
 		existingChangelog = string(content)
 	}
 
-	entry := scripts.ChangelogEntry{
+	entry := releasenotes.ChangelogEntry{
 		Version:      version,
 		Date:         date,
 		ReleaseNotes: releaseNotes,
@@ -9,6 +9,7 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
 )
 
 // **Feature: release-changelog-cicd, Property 5: CHANGELOG.md Entry Generation**
@@ -27,12 +28,12 @@ func genChangelogEntry() gopter.Gen {
 	return gen.IntRange(0, 999).FlatMap(func(major interface{}) gopter.Gen {
 		return gen.IntRange(0, 999).FlatMap(func(minor interface{}) gopter.Gen {
 			return gen.IntRange(0, 999).FlatMap(func(patch interface{}) gopter.Gen {
-				return gen.AlphaString().Map(func(notes string) ChangelogEntry {
+				return gen.AlphaString().Map(func(notes string) releasenotes.ChangelogEntry {
 					version := fmt.Sprintf("%d.%d.%d", major.(int), minor.(int), patch.(int))
 					if notes == "" {
 						notes = "### Added\n- New feature"
 					}
-					return ChangelogEntry{
+					return releasenotes.ChangelogEntry{
 						Version:      version,
 						Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 						ReleaseNotes: notes,
@@ -98,7 +99,7 @@ func TestPropertyChangelogEntryGeneration(t *testing.T) {
 	properties.Property("generated entry contains version number", prop.ForAll(
 		func(major, minor, patch int) bool {
 			version := fmt.Sprintf("%d.%d.%d", major, minor, patch)
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      version,
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: "### Added\n- Feature",
@@ -123,7 +124,7 @@ func TestPropertyChangelogEntryGeneration(t *testing.T) {
 				return true // Skip invalid dates
 			}
 			date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         date,
 				ReleaseNotes: "### Added\n- Feature",
@@ -148,7 +149,7 @@ func TestPropertyChangelogEntryGeneration(t *testing.T) {
 			if notes == "" {
 				notes = "Some notes"
 			}
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -179,7 +180,7 @@ All notable changes to Narvana are documented here.
 
 [0.1.0]: https://github.com/narvanalabs/control-plane/releases/tag/v0.1.0
 `
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      version,
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: "### Added\n- New feature",
@@ -206,7 +207,7 @@ All notable changes to Narvana are documented here.
 	properties.Property("entry uses conventional changelog format", prop.ForAll(
 		func(major, minor, patch int) bool {
 			version := fmt.Sprintf("%d.%d.%d", major, minor, patch)
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      version,
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: "### Added\n- Feature",
@@ -229,7 +230,7 @@ All notable changes to Narvana are documented here.
 	properties.Property("version link is generated correctly", prop.ForAll(
 		func(major, minor, patch int) bool {
 			version := fmt.Sprintf("%d.%d.%d", major, minor, patch)
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      version,
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: "### Added\n- Feature",
@@ -322,7 +323,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("headers are preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "### Added\n- Feature\n\n### Fixed\n- Bug"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -342,7 +343,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("list items are preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "- Item 1\n- Item 2\n- Item 3"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -362,7 +363,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("code blocks are preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "```go\nfunc main() {}\n```"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -382,7 +383,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("links are preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "See [documentation](https://example.com) for details"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -402,7 +403,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("bold text is preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "This is **bold** text"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -422,7 +423,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("inline code is preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "Use `go run main.go` to start"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -454,7 +455,7 @@ func TestPropertyMarkdownFormattingPreservation(t *testing.T) {
 	properties.Property("mixed markdown content is preserved", prop.ForAll(
 		func(_ int) bool {
 			notes := "### Features\n\n- **New**: Added `feature`\n- See [docs](https://example.com)\n\n```bash\nmake build\n```"
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: notes,
@@ -492,7 +493,7 @@ All notable changes to Narvana are documented here.
 
 [0.1.0]: https://github.com/narvanalabs/control-plane/releases/tag/v0.1.0
 `
-			entry := ChangelogEntry{
+			entry := releasenotes.ChangelogEntry{
 				Version:      "1.0.0",
 				Date:         time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC),
 				ReleaseNotes: "### Added\n- New feature",
@@ -514,3 +515,53 @@ All notable changes to Narvana are documented here.
 
 	properties.TestingRun(t)
 }
+
+// **Feature: whats-new-feed, Property 1: Changelog Parse Round-Trip**
+// For any sequence of releases prepended to a changelog, parsing the changelog SHALL
+// recover every release with its version, date, release URL and notes, newest first.
+func TestPropertyChangelogParseRoundTrip(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("parsed entries match prepended entries", prop.ForAll(
+		func(entries []releasenotes.ChangelogEntry) bool {
+			// Versions must be unique for release links to be unambiguous
+			seen := make(map[string]bool)
+			changelog := ""
+			var added []releasenotes.ChangelogEntry
+			for _, entry := range entries {
+				if seen[entry.Version] {
+					continue
+				}
+				seen[entry.Version] = true
+
+				var err error
+				changelog, err = PrependChangelogEntry(changelog, entry)
+				if err != nil {
+					return false
+				}
+				added = append(added, entry)
+			}
+
+			parsed := releasenotes.ParseChangelog(changelog)
+			if len(parsed) != len(added) {
+				return false
+			}
+			for i, entry := range parsed {
+				want := added[len(added)-1-i]
+				expectedURL := fmt.Sprintf("https://github.com/narvanalabs/control-plane/releases/tag/v%s", want.Version)
+				if entry.Version != want.Version ||
+					!entry.Date.Equal(want.Date) ||
+					entry.ReleaseURL != expectedURL ||
+					entry.ReleaseNotes != strings.TrimSpace(want.ReleaseNotes) {
+					return false
+				}
+			}
+			return true
+		},
+		gen.SliceOfN(5, genChangelogEntry()),
+	))
+
+	properties.TestingRun(t)
+}
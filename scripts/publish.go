@@ -0,0 +1,268 @@
+package scripts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
+
+// DefaultGitHubAPIURL is the base URL of the public GitHub REST API.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// ReleaseDocument is a fully generated release, ready to be handed to publishers.
+type ReleaseDocument struct {
+	Version    string    // e.g., "1.0.0"
+	Tag        string    // e.g., "v1.0.0"
+	Date       time.Time // Release date
+	Title      string    // Release title, e.g. "Narvana v1.0.0"
+	RawNotes   string    // Commit list as recorded in CHANGELOG.md
+	Markdown   string    // Generated release notes including frontmatter
+	ReleaseURL string    // URL to the GitHub release
+}
+
+// NewReleaseDocument builds a release document from raw commit notes by running
+// them through the release notes pipeline.
+func NewReleaseDocument(ctx context.Context, rawNotes string, config ReleaseNotesConfig, repo string, opts SummaryOptions) (*ReleaseDocument, error) {
+	markdown, err := BuildReleaseNotesWithSummaries(ctx, rawNotes, config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newReleaseDocument(rawNotes, markdown, config, repo)
+}
+
+// newReleaseDocument fills in the version, tag and URL fields for generated markdown.
+func newReleaseDocument(rawNotes, markdown string, config ReleaseNotesConfig, repo string) (*ReleaseDocument, error) {
+	date, err := time.Parse("2006-01-02", config.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	version := strings.TrimPrefix(config.Version, "v")
+	tag := "v" + version
+	return &ReleaseDocument{
+		Version:    version,
+		Tag:        tag,
+		Date:       date,
+		Title:      GenerateTitle(version, config.Title),
+		RawNotes:   rawNotes,
+		Markdown:   markdown,
+		ReleaseURL: fmt.Sprintf("https://github.com/%s/releases/tag/%s", repo, tag),
+	}, nil
+}
+
+// BuildReleaseNotes runs raw release notes through the full pipeline: commits are
+// parsed, filtered, grouped, categorized and formatted into markdown.
+// Large groups use the deterministic summary.
+func BuildReleaseNotes(rawNotes string, config ReleaseNotesConfig) (string, error) {
+	return BuildReleaseNotesWithSummaries(context.Background(), rawNotes, config, SummaryOptions{})
+}
+
+// BuildReleaseNotesWithSummaries is BuildReleaseNotes with a configurable
+// summarization step for large commit groups.
+func BuildReleaseNotesWithSummaries(ctx context.Context, rawNotes string, config ReleaseNotesConfig, opts SummaryOptions) (string, error) {
+	content := releasenotes.ProcessCommitsWithFallback(releasenotes.ParseRawCommits(rawNotes), releasenotes.DefaultNoiseFilterConfig())
+	content = SummarizeContent(ctx, content, opts)
+	return GenerateReleaseNotes(content, config)
+}
+
+// Publisher delivers a generated release to one destination.
+type Publisher interface {
+	// Name identifies the publisher in logs and errors.
+	Name() string
+	// Publish writes the release to its destination. Publishing the same
+	// release twice must not duplicate it.
+	Publish(ctx context.Context, doc *ReleaseDocument) error
+}
+
+// PublishAll runs every publisher, continuing past failures so that one broken
+// destination does not block the others. All failures are returned joined.
+func PublishAll(ctx context.Context, doc *ReleaseDocument, publishers ...Publisher) error {
+	var errs []error
+	for _, p := range publishers {
+		if err := p.Publish(ctx, doc); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ChangelogPublisher prepends the release to a CHANGELOG.md file.
+type ChangelogPublisher struct {
+	Path string
+}
+
+// Name returns the publisher name.
+func (p *ChangelogPublisher) Name() string { return "changelog" }
+
+// Publish prepends the release entry, skipping versions already in the changelog.
+func (p *ChangelogPublisher) Publish(ctx context.Context, doc *ReleaseDocument) error {
+	var existing string
+	content, err := os.ReadFile(p.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read changelog: %w", err)
+	}
+	existing = string(content)
+
+	if ChangelogContainsVersion(existing, doc.Version) {
+		return nil
+	}
+
+	updated, err := PrependChangelogEntry(existing, releasenotes.ChangelogEntry{
+		Version:      doc.Version,
+		Date:         doc.Date,
+		ReleaseURL:   doc.ReleaseURL,
+		ReleaseNotes: doc.RawNotes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate changelog: %w", err)
+	}
+
+	if err := os.WriteFile(p.Path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// WebsitePublisher writes the release entry into the changelog site's content directory.
+type WebsitePublisher struct {
+	Dir string // e.g., "changelog-repo/src/content/releases"
+}
+
+// Name returns the publisher name.
+func (p *WebsitePublisher) Name() string { return "website" }
+
+// Path returns the content file path for a version.
+func (p *WebsitePublisher) Path(version string) string {
+	return filepath.Join(p.Dir, GenerateReleaseFilename(version))
+}
+
+// Publish writes the release markdown, overwriting any previous entry for the version.
+func (p *WebsitePublisher) Publish(ctx context.Context, doc *ReleaseDocument) error {
+	if err := os.MkdirAll(p.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(p.Path(doc.Version), []byte(doc.Markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write release entry: %w", err)
+	}
+	return nil
+}
+
+// GitHubReleasePublisher creates or updates the GitHub Release for the tag.
+type GitHubReleasePublisher struct {
+	Repo       string // "owner/name"
+	Token      string
+	BaseURL    string       // defaults to DefaultGitHubAPIURL
+	HTTPClient *http.Client // defaults to a client with a 30s timeout
+}
+
+// Name returns the publisher name.
+func (p *GitHubReleasePublisher) Name() string { return "github-release" }
+
+// githubRelease is the subset of the GitHub release resource we read and write.
+type githubRelease struct {
+	ID      int64  `json:"id,omitempty"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+}
+
+// Publish updates the release for doc.Tag, creating it when it does not exist yet.
+func (p *GitHubReleasePublisher) Publish(ctx context.Context, doc *ReleaseDocument) error {
+	if p.Repo == "" {
+		return fmt.Errorf("repository is required")
+	}
+	if p.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	release := githubRelease{
+		TagName: doc.Tag,
+		Name:    doc.Title,
+		Body:    GitHubReleaseBody(doc.Markdown),
+	}
+
+	var existing githubRelease
+	status, err := p.do(ctx, http.MethodGet, "/repos/"+p.Repo+"/releases/tags/"+doc.Tag, nil, &existing)
+	switch {
+	case status == http.StatusNotFound:
+		_, err = p.do(ctx, http.MethodPost, "/repos/"+p.Repo+"/releases", release, nil)
+	case err == nil:
+		_, err = p.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/releases/%d", p.Repo, existing.ID), release, nil)
+	}
+	return err
+}
+
+// do sends a GitHub API request and decodes the response into out when non-nil.
+// The status code is returned even when the request fails.
+func (p *GitHubReleasePublisher) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGitHubAPIURL
+	}
+	client := p.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// relativeImageRegex matches markdown images with relative paths, such as the
+// banner and section icons, which only resolve on the changelog site.
+var relativeImageRegex = regexp.MustCompile(`!\[[^\]]*\]\(\.{1,2}/[^)]*\)[ \t]*`)
+
+// GitHubReleaseBody converts generated release notes into a GitHub Release body
+// by dropping the frontmatter and site-relative images.
+func GitHubReleaseBody(markdown string) string {
+	if _, content, err := ParseReleaseNotesFrontmatter(markdown); err == nil {
+		markdown = content
+	}
+	markdown = relativeImageRegex.ReplaceAllString(markdown, "")
+	return strings.TrimSpace(markdown) + "\n"
+}
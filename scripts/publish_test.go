@@ -0,0 +1,168 @@
+package scripts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
+
+// testReleaseDocument builds a release document from a small commit list.
+func testReleaseDocument(t *testing.T) *ReleaseDocument {
+	t.Helper()
+	doc, err := NewReleaseDocument(
+		context.Background(),
+		"- feat(api): add release publishing (abc1234)\n- fix(ui): correct banner spacing (def5678)",
+		ReleaseNotesConfig{Version: "v1.2.0", Date: "2026-01-15"},
+		"narvanalabs/control-plane",
+		SummaryOptions{},
+	)
+	if err != nil {
+		t.Fatalf("NewReleaseDocument() error = %v", err)
+	}
+	return doc
+}
+
+// TestNewReleaseDocument tests that version, tag, and release URL are normalized.
+func TestNewReleaseDocument(t *testing.T) {
+	doc := testReleaseDocument(t)
+
+	if doc.Version != "1.2.0" || doc.Tag != "v1.2.0" {
+		t.Errorf("unexpected version/tag %q/%q", doc.Version, doc.Tag)
+	}
+	if doc.ReleaseURL != "https://github.com/narvanalabs/control-plane/releases/tag/v1.2.0" {
+		t.Errorf("unexpected release URL %q", doc.ReleaseURL)
+	}
+	if !strings.HasPrefix(doc.Markdown, "---\n") {
+		t.Error("expected markdown to start with frontmatter")
+	}
+}
+
+// TestChangelogPublisher tests that the changelog is created once and not duplicated.
+func TestChangelogPublisher(t *testing.T) {
+	doc := testReleaseDocument(t)
+	p := &ChangelogPublisher{Path: filepath.Join(t.TempDir(), "CHANGELOG.md")}
+
+	for i := 0; i < 2; i++ {
+		if err := p.Publish(context.Background(), doc); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(p.Path)
+	if err != nil {
+		t.Fatalf("failed to read changelog: %v", err)
+	}
+	entries := releasenotes.ParseChangelog(string(content))
+	if len(entries) != 1 || entries[0].Version != "1.2.0" {
+		t.Fatalf("expected a single 1.2.0 entry, got %+v", entries)
+	}
+	if !strings.Contains(entries[0].ReleaseNotes, "add release publishing") {
+		t.Error("expected raw commit notes in changelog entry")
+	}
+}
+
+// TestWebsitePublisher tests that the release entry is written under the version filename.
+func TestWebsitePublisher(t *testing.T) {
+	doc := testReleaseDocument(t)
+	p := &WebsitePublisher{Dir: filepath.Join(t.TempDir(), "releases")}
+
+	if err := p.Publish(context.Background(), doc); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.Dir, "1_2_0.md"))
+	if err != nil {
+		t.Fatalf("failed to read release entry: %v", err)
+	}
+	if string(content) != doc.Markdown {
+		t.Error("expected release entry to match generated markdown")
+	}
+}
+
+// TestGitHubReleasePublisher tests creating a missing release and updating an existing one.
+func TestGitHubReleasePublisher(t *testing.T) {
+	tests := []struct {
+		name         string
+		exists       bool
+		expectMethod string
+		expectPath   string
+	}{
+		{"creates missing release", false, http.MethodPost, "/repos/acme/app/releases"},
+		{"updates existing release", true, http.MethodPatch, "/repos/acme/app/releases/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotPath, gotAuth string
+			var got githubRelease
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					if r.URL.Path != "/repos/acme/app/releases/tags/v1.2.0" {
+						t.Errorf("unexpected lookup path %q", r.URL.Path)
+					}
+					if !tt.exists {
+						http.NotFound(w, r)
+						return
+					}
+					json.NewEncoder(w).Encode(githubRelease{ID: 42, TagName: "v1.2.0"})
+					return
+				}
+				gotMethod, gotPath, gotAuth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			p := &GitHubReleasePublisher{Repo: "acme/app", Token: "secret", BaseURL: srv.URL}
+			if err := p.Publish(context.Background(), testReleaseDocument(t)); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			if gotMethod != tt.expectMethod || gotPath != tt.expectPath {
+				t.Errorf("expected %s %s, got %s %s", tt.expectMethod, tt.expectPath, gotMethod, gotPath)
+			}
+			if gotAuth != "Bearer secret" {
+				t.Errorf("unexpected Authorization header %q", gotAuth)
+			}
+			if got.TagName != "v1.2.0" || got.Name != "Narvana v1.2.0" {
+				t.Errorf("unexpected release payload %+v", got)
+			}
+			if strings.Contains(got.Body, "versionNumber:") || strings.Contains(got.Body, "../../assets/") {
+				t.Errorf("expected frontmatter and relative images stripped, got %q", got.Body)
+			}
+		})
+	}
+}
+
+// failingPublisher always fails with its name.
+type failingPublisher struct{ name string }
+
+func (p *failingPublisher) Name() string { return p.name }
+
+func (p *failingPublisher) Publish(ctx context.Context, doc *ReleaseDocument) error {
+	return errors.New("unavailable")
+}
+
+// TestPublishAllContinuesPastFailures tests that one failing publisher does not block the rest.
+func TestPublishAllContinuesPastFailures(t *testing.T) {
+	doc := testReleaseDocument(t)
+	website := &WebsitePublisher{Dir: t.TempDir()}
+
+	err := PublishAll(context.Background(), doc, &failingPublisher{name: "broken"}, website)
+	if err == nil || !strings.Contains(err.Error(), "broken: unavailable") {
+		t.Fatalf("expected joined error from broken publisher, got %v", err)
+	}
+	if _, err := os.Stat(website.Path(doc.Version)); err != nil {
+		t.Errorf("expected website entry to be written despite failure: %v", err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/narvanalabs/control-plane/scripts"
@@ -119,14 +118,6 @@ func generateEnhancedReleaseNotes(version, dateStr, releaseNotes, overrideFile,
 		}
 	}
 
-	// Parse raw release notes into individual commit messages
-	// GitHub release notes typically have commits separated by newlines
-	rawCommits := parseRawCommits(releaseNotes)
-
-	// Process commits through the pipeline with fallback handling
-	filterConfig := scripts.DefaultNoiseFilterConfig()
-	categorizedContent := scripts.ProcessCommitsWithFallback(rawCommits, filterConfig)
-
 	// Build the release notes config
 	config := scripts.ReleaseNotesConfig{
 		Version:     version,
@@ -141,55 +132,6 @@ func generateEnhancedReleaseNotes(version, dateStr, releaseNotes, overrideFile,
 		config.Closing = override.Closing
 	}
 
-	// Generate the final markdown
-	return scripts.GenerateReleaseNotes(categorizedContent, config)
-}
-
-// parseRawCommits splits raw release notes into individual commit messages.
-// It handles various formats:
-// - One commit per line
-// - Commits prefixed with "* " or "- " (GitHub release notes format)
-// - Commits with hash prefixes like "abc1234 feat: description"
-func parseRawCommits(releaseNotes string) []string {
-	if strings.TrimSpace(releaseNotes) == "" {
-		return []string{}
-	}
-
-	lines := strings.Split(releaseNotes, "\n")
-	commits := make([]string, 0, len(lines))
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Remove common prefixes from GitHub release notes
-		// "* " or "- " bullet points
-		line = strings.TrimPrefix(line, "* ")
-		line = strings.TrimPrefix(line, "- ")
-
-		// Remove leading hash if present (e.g., "abc1234 feat: description")
-		// This pattern matches a short hash followed by space
-		if len(line) > 8 && isHexString(line[:7]) && line[7] == ' ' {
-			line = line[8:]
-		}
-
-		line = strings.TrimSpace(line)
-		if line != "" {
-			commits = append(commits, line)
-		}
-	}
-
-	return commits
-}
-
-// isHexString checks if a string contains only hexadecimal characters.
-func isHexString(s string) bool {
-	for _, c := range s {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
-	}
-	return true
+	// Parse, filter, group, and format the commits into the final markdown
+	return scripts.BuildReleaseNotes(releaseNotes, config)
 }
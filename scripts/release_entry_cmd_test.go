@@ -8,6 +8,7 @@ import (
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
 )
 
 // **Feature: intelligent-release-notes, Property CLI-1: End-to-end generation with sample commits**
@@ -31,8 +32,8 @@ func TestIntegrationEnhancedReleaseNotes(t *testing.T) {
 	}
 
 	// Process commits through the pipeline
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(sampleCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(sampleCommits, filterConfig)
 
 	// Build config
 	config := ReleaseNotesConfig{
@@ -96,8 +97,8 @@ func TestIntegrationEnhancedReleaseNotes(t *testing.T) {
 // TestIntegrationEmptyCommits tests generation with no commits.
 func TestIntegrationEmptyCommits(t *testing.T) {
 	// Process empty commits
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback([]string{}, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback([]string{}, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:     "1.0.0",
@@ -140,8 +141,8 @@ func TestIntegrationAllNoiseCommits(t *testing.T) {
 		"fix lint errors",
 	}
 
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(noiseCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(noiseCommits, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:     "1.0.1",
@@ -177,8 +178,8 @@ func TestIntegrationWithOverride(t *testing.T) {
 		"fix(web): resolve display issue",
 	}
 
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(sampleCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(sampleCommits, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:      "2.0.0",
@@ -216,8 +217,8 @@ func TestIntegrationMajorVersionTitle(t *testing.T) {
 		"feat: initial release",
 	}
 
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(sampleCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(sampleCommits, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:     "1.0.0",
@@ -242,8 +243,8 @@ func TestIntegrationMinorVersionTitle(t *testing.T) {
 		"feat: new feature",
 	}
 
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(sampleCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(sampleCommits, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:     "1.2.0",
@@ -268,8 +269,8 @@ func TestIntegrationBannerPath(t *testing.T) {
 		"feat: new feature",
 	}
 
-	filterConfig := DefaultNoiseFilterConfig()
-	categorizedContent := ProcessCommitsWithFallback(sampleCommits, filterConfig)
+	filterConfig := releasenotes.DefaultNoiseFilterConfig()
+	categorizedContent := releasenotes.ProcessCommitsWithFallback(sampleCommits, filterConfig)
 
 	config := ReleaseNotesConfig{
 		Version:     "1.2.3",
@@ -307,8 +308,8 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 				"fix: fix bug",
 			}
 
-			filterConfig := DefaultNoiseFilterConfig()
-			categorizedContent := ProcessCommitsWithFallback(commits, filterConfig)
+			filterConfig := releasenotes.DefaultNoiseFilterConfig()
+			categorizedContent := releasenotes.ProcessCommitsWithFallback(commits, filterConfig)
 
 			config := ReleaseNotesConfig{
 				Version:     version,
@@ -343,8 +344,8 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 			version := genVersionString(major, minor, patch)
 			
 			commits := []string{"feat: test"}
-			filterConfig := DefaultNoiseFilterConfig()
-			categorizedContent := ProcessCommitsWithFallback(commits, filterConfig)
+			filterConfig := releasenotes.DefaultNoiseFilterConfig()
+			categorizedContent := releasenotes.ProcessCommitsWithFallback(commits, filterConfig)
 
 			config := ReleaseNotesConfig{
 				Version:     version,
@@ -375,8 +376,8 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 			version := genVersionString(major, minor, patch)
 			
 			commits := []string{"feat: test"}
-			filterConfig := DefaultNoiseFilterConfig()
-			categorizedContent := ProcessCommitsWithFallback(commits, filterConfig)
+			filterConfig := releasenotes.DefaultNoiseFilterConfig()
+			categorizedContent := releasenotes.ProcessCommitsWithFallback(commits, filterConfig)
 
 			config := ReleaseNotesConfig{
 				Version:     version,
@@ -409,8 +410,8 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 			version := genVersionString(major, minor, patch)
 			
 			commits := []string{"feat: test"}
-			filterConfig := DefaultNoiseFilterConfig()
-			categorizedContent := ProcessCommitsWithFallback(commits, filterConfig)
+			filterConfig := releasenotes.DefaultNoiseFilterConfig()
+			categorizedContent := releasenotes.ProcessCommitsWithFallback(commits, filterConfig)
 
 			config := ReleaseNotesConfig{
 				Version:      version,
@@ -438,8 +439,8 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 			version := genVersionString(major, minor, patch)
 			
 			commits := []string{"feat: test"}
-			filterConfig := DefaultNoiseFilterConfig()
-			categorizedContent := ProcessCommitsWithFallback(commits, filterConfig)
+			filterConfig := releasenotes.DefaultNoiseFilterConfig()
+			categorizedContent := releasenotes.ProcessCommitsWithFallback(commits, filterConfig)
 
 			config := ReleaseNotesConfig{
 				Version:     version,
@@ -468,112 +469,3 @@ func TestPropertyEndToEndGeneration(t *testing.T) {
 func genVersionString(major, minor, patch int) string {
 	return fmt.Sprintf("%d.%d.%d", major, minor, patch)
 }
-
-// TestParseRawCommits tests the commit parsing helper function.
-func TestParseRawCommits(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected []string
-	}{
-		{
-			name:     "empty input",
-			input:    "",
-			expected: []string{},
-		},
-		{
-			name:     "single commit",
-			input:    "feat: add feature",
-			expected: []string{"feat: add feature"},
-		},
-		{
-			name:     "multiple commits",
-			input:    "feat: add feature\nfix: fix bug\ndocs: update docs",
-			expected: []string{"feat: add feature", "fix: fix bug", "docs: update docs"},
-		},
-		{
-			name:     "commits with bullet points",
-			input:    "* feat: add feature\n* fix: fix bug",
-			expected: []string{"feat: add feature", "fix: fix bug"},
-		},
-		{
-			name:     "commits with dash bullets",
-			input:    "- feat: add feature\n- fix: fix bug",
-			expected: []string{"feat: add feature", "fix: fix bug"},
-		},
-		{
-			name:     "commits with hash prefix",
-			input:    "abc1234 feat: add feature\ndef5678 fix: fix bug",
-			expected: []string{"feat: add feature", "fix: fix bug"},
-		},
-		{
-			name:     "mixed format",
-			input:    "* abc1234 feat: add feature\n- def5678 fix: fix bug\nperf: improve speed",
-			expected: []string{"feat: add feature", "fix: fix bug", "perf: improve speed"},
-		},
-		{
-			name:     "empty lines ignored",
-			input:    "feat: add feature\n\nfix: fix bug\n\n",
-			expected: []string{"feat: add feature", "fix: fix bug"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := parseRawCommitsForTest(tt.input)
-			if len(result) != len(tt.expected) {
-				t.Errorf("Expected %d commits, got %d", len(tt.expected), len(result))
-				return
-			}
-			for i, expected := range tt.expected {
-				if result[i] != expected {
-					t.Errorf("Commit %d: expected %q, got %q", i, expected, result[i])
-				}
-			}
-		})
-	}
-}
-
-// parseRawCommitsForTest is a copy of the parseRawCommits function from release_entry_cmd.go
-// for testing purposes (since the cmd file uses //go:build ignore).
-func parseRawCommitsForTest(releaseNotes string) []string {
-	if strings.TrimSpace(releaseNotes) == "" {
-		return []string{}
-	}
-
-	lines := strings.Split(releaseNotes, "\n")
-	commits := make([]string, 0, len(lines))
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Remove common prefixes from GitHub release notes
-		line = strings.TrimPrefix(line, "* ")
-		line = strings.TrimPrefix(line, "- ")
-
-		// Remove leading hash if present
-		if len(line) > 8 && isHexStringForTest(line[:7]) && line[7] == ' ' {
-			line = line[8:]
-		}
-
-		line = strings.TrimSpace(line)
-		if line != "" {
-			commits = append(commits, line)
-		}
-	}
-
-	return commits
-}
-
-// isHexStringForTest checks if a string contains only hexadecimal characters.
-func isHexStringForTest(s string) bool {
-	for _, c := range s {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
-	}
-	return true
-}
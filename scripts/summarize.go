@@ -0,0 +1,281 @@
+package scripts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
+
+// DefaultSummaryCachePath is where generated summaries are cached between runs.
+const DefaultSummaryCachePath = ".release-notes-cache.json"
+
+// Summarizer produces a one-paragraph summary for a large commit group.
+// Implementations may call out to an LLM; the pipeline always falls back to the
+// deterministic SummarizeGroup when a summarizer fails.
+type Summarizer interface {
+	// Name identifies the summarizer in logs and cache keys.
+	Name() string
+	// Summarize returns a summary for the group, or an error.
+	Summarize(ctx context.Context, group releasenotes.CommitGroup) (string, error)
+}
+
+// DeterministicSummarizer summarizes groups offline using SummarizeGroup.
+type DeterministicSummarizer struct{}
+
+// Name returns the summarizer name.
+func (DeterministicSummarizer) Name() string { return "deterministic" }
+
+// Summarize returns the deterministic summary for the group.
+func (DeterministicSummarizer) Summarize(ctx context.Context, group releasenotes.CommitGroup) (string, error) {
+	return releasenotes.SummarizeGroup(group), nil
+}
+
+// SummaryOptions configures the summarization step of the release notes pipeline.
+type SummaryOptions struct {
+	Provider Summarizer    // Optional AI provider; nil uses the deterministic summary only
+	Cache    *SummaryCache // Optional cache of provider summaries
+}
+
+// SummarizeContent fills in Summary for every group marked IsSummary. Provider
+// summaries are read from and written to the cache; groups the provider cannot
+// summarize keep the deterministic summary.
+func SummarizeContent(ctx context.Context, content releasenotes.CategorizedContent, opts SummaryOptions) releasenotes.CategorizedContent {
+	summarize := func(groups []releasenotes.CommitGroup) []releasenotes.CommitGroup {
+		result := make([]releasenotes.CommitGroup, len(groups))
+		for i, group := range groups {
+			if group.IsSummary {
+				group.Summary = summarizeGroup(ctx, group, opts)
+			}
+			result[i] = group
+		}
+		return result
+	}
+
+	return releasenotes.CategorizedContent{
+		Features:        summarize(content.Features),
+		Improvements:    summarize(content.Improvements),
+		BugFixes:        summarize(content.BugFixes),
+		BreakingChanges: summarize(content.BreakingChanges),
+		Other:           summarize(content.Other),
+	}
+}
+
+// summarizeGroup summarizes a single group, preferring cached or provider summaries.
+func summarizeGroup(ctx context.Context, group releasenotes.CommitGroup, opts SummaryOptions) string {
+	if opts.Provider == nil {
+		return releasenotes.SummarizeGroup(group)
+	}
+
+	key := CommitRangeHash(opts.Provider.Name(), group)
+	if summary, ok := opts.Cache.Get(key); ok {
+		return summary
+	}
+
+	summary, err := opts.Provider.Summarize(ctx, group)
+	summary = strings.Join(strings.Fields(summary), " ")
+	if err != nil || summary == "" {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s summary failed for %s, using fallback: %v\n", opts.Provider.Name(), group.Name, err)
+		}
+		return releasenotes.SummarizeGroup(group)
+	}
+
+	opts.Cache.Set(key, summary)
+	return summary
+}
+
+// CommitRangeHash returns a stable cache key for a commit group. The key covers
+// the provider, the group name, and every commit in order, so any change to the
+// commit range produces a new key.
+func CommitRangeHash(provider string, group releasenotes.CommitGroup) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", provider, group.Name)
+	for _, commit := range group.Commits {
+		id := commit.Hash
+		if id == "" {
+			id = commit.Raw
+		}
+		fmt.Fprintf(h, "%s\x00", id)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SummaryCache stores summaries keyed by commit-range hash in a JSON file.
+// A nil cache is valid and caches nothing.
+type SummaryCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]string
+	dirty   bool
+}
+
+// LoadSummaryCache reads the cache file at path. A missing file yields an empty cache.
+func LoadSummaryCache(path string) (*SummaryCache, error) {
+	c := &SummaryCache{path: path, entries: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse summary cache: %w", err)
+	}
+	return c, nil
+}
+
+// Get returns the cached summary for key.
+func (c *SummaryCache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summary, ok := c.entries[key]
+	return summary, ok
+}
+
+// Set stores a summary for key.
+func (c *SummaryCache) Set(key, summary string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = summary
+	c.dirty = true
+}
+
+// Save writes the cache back to disk if it changed.
+func (c *SummaryCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write summary cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// ChatCompletionSummarizer summarizes commit groups with any OpenAI-compatible
+// chat completions API.
+type ChatCompletionSummarizer struct {
+	BaseURL    string // e.g., "https://api.openai.com/v1"
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client // defaults to a client with a 60s timeout
+}
+
+// NewChatCompletionSummarizerFromEnv configures a summarizer from
+// RELEASE_NOTES_AI_URL, RELEASE_NOTES_AI_KEY and RELEASE_NOTES_AI_MODEL.
+// It returns nil when no API key is set.
+func NewChatCompletionSummarizerFromEnv() *ChatCompletionSummarizer {
+	key := os.Getenv("RELEASE_NOTES_AI_KEY")
+	if key == "" {
+		return nil
+	}
+	s := &ChatCompletionSummarizer{
+		BaseURL: os.Getenv("RELEASE_NOTES_AI_URL"),
+		APIKey:  key,
+		Model:   os.Getenv("RELEASE_NOTES_AI_MODEL"),
+	}
+	if s.BaseURL == "" {
+		s.BaseURL = "https://api.openai.com/v1"
+	}
+	if s.Model == "" {
+		s.Model = "gpt-4o-mini"
+	}
+	return s
+}
+
+// Name returns the summarizer name, including the model so cache entries are per-model.
+func (s *ChatCompletionSummarizer) Name() string { return "chat:" + s.Model }
+
+// summaryPrompt instructs the model how to summarize a group.
+const summaryPrompt = `You write release notes for %s. Summarize the following %d changes to the %q area ` +
+	`in one or two sentences for end users. Focus on user-visible behavior, keep important ` +
+	`technical details, do not mention commit hashes, and reply with the summary only.`
+
+// Summarize asks the model for a summary of the group's commits.
+func (s *ChatCompletionSummarizer) Summarize(ctx context.Context, group releasenotes.CommitGroup) (string, error) {
+	var changes strings.Builder
+	for _, commit := range group.Commits {
+		changes.WriteString("- ")
+		changes.WriteString(commit.Description)
+		changes.WriteString("\n")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"model":       s.Model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "system", "content": fmt.Sprintf(summaryPrompt, DefaultProjectName, len(group.Commits), group.Name)},
+			{"role": "user", "content": changes.String()},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.BaseURL, "/")+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no summary returned")
+	}
+	return result.Choices[0].Message.Content, nil
+}
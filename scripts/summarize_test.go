@@ -0,0 +1,148 @@
+package scripts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/narvanalabs/control-plane/internal/releasenotes"
+)
+
+// fakeSummarizer returns a fixed summary or error and counts calls.
+type fakeSummarizer struct {
+	summary string
+	err     error
+	calls   int
+}
+
+func (f *fakeSummarizer) Name() string { return "fake" }
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, group releasenotes.CommitGroup) (string, error) {
+	f.calls++
+	return f.summary, f.err
+}
+
+// largeGroupNotes produces enough commits in one scope to trigger summarization.
+const largeGroupNotes = `feat(builds): add build cache
+feat(builds): add parallel builds
+feat(builds): add build logs streaming
+feat(builds): add build retries
+fix(ui): correct banner spacing`
+
+// TestSummarizeContentFallback tests that groups fall back to SummarizeGroup without a provider or on error.
+func TestSummarizeContentFallback(t *testing.T) {
+	content := releasenotes.ProcessCommitsWithFallback(releasenotes.ParseRawCommits(largeGroupNotes), releasenotes.DefaultNoiseFilterConfig())
+	if len(content.Features) != 1 || !content.Features[0].IsSummary {
+		t.Fatalf("expected one summarized feature group, got %+v", content.Features)
+	}
+	expected := releasenotes.SummarizeGroup(content.Features[0])
+
+	tests := []struct {
+		name string
+		opts SummaryOptions
+	}{
+		{"no provider", SummaryOptions{}},
+		{"provider error", SummaryOptions{Provider: &fakeSummarizer{err: errors.New("offline")}}},
+		{"empty summary", SummaryOptions{Provider: &fakeSummarizer{summary: "  "}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SummarizeContent(context.Background(), content, tt.opts)
+			if result.Features[0].Summary != expected {
+				t.Errorf("expected fallback summary %q, got %q", expected, result.Features[0].Summary)
+			}
+			if result.BugFixes[0].Summary != "" {
+				t.Error("expected small groups to stay unsummarized")
+			}
+		})
+	}
+}
+
+// TestSummarizeContentCache tests that provider summaries are cached by commit-range hash across runs.
+func TestSummarizeContentCache(t *testing.T) {
+	content := releasenotes.ProcessCommitsWithFallback(releasenotes.ParseRawCommits(largeGroupNotes), releasenotes.DefaultNoiseFilterConfig())
+	path := filepath.Join(t.TempDir(), "cache.json")
+	provider := &fakeSummarizer{summary: "Builds are faster\nand more reliable."}
+
+	for i := 0; i < 2; i++ {
+		cache, err := LoadSummaryCache(path)
+		if err != nil {
+			t.Fatalf("LoadSummaryCache() error = %v", err)
+		}
+		result := SummarizeContent(context.Background(), content, SummaryOptions{Provider: provider, Cache: cache})
+		if result.Features[0].Summary != "Builds are faster and more reliable." {
+			t.Errorf("run %d: unexpected summary %q", i, result.Features[0].Summary)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("expected provider to be called once, got %d", provider.calls)
+	}
+}
+
+// TestCommitRangeHash tests that the cache key changes with the provider and the commit range.
+func TestCommitRangeHash(t *testing.T) {
+	group := releasenotes.CommitGroup{Name: "builds", Commits: []releasenotes.ParsedCommit{{Raw: "feat: a"}, {Raw: "feat: b"}}}
+	key := CommitRangeHash("fake", group)
+
+	if key != CommitRangeHash("fake", group) {
+		t.Error("expected hash to be stable")
+	}
+	if key == CommitRangeHash("other", group) {
+		t.Error("expected hash to depend on provider")
+	}
+	extended := group
+	extended.Commits = append(extended.Commits, releasenotes.ParsedCommit{Raw: "feat: c"})
+	if key == CommitRangeHash("fake", extended) {
+		t.Error("expected hash to depend on commit range")
+	}
+}
+
+// TestChatCompletionSummarizer tests the OpenAI-compatible provider request and response handling.
+func TestChatCompletionSummarizer(t *testing.T) {
+	var gotAuth string
+	var gotBody struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"content":"Faster builds."}}]}`))
+	}))
+	defer srv.Close()
+
+	s := &ChatCompletionSummarizer{BaseURL: srv.URL, APIKey: "key", Model: "test-model"}
+	summary, err := s.Summarize(context.Background(), releasenotes.CommitGroup{
+		Name:    "builds",
+		Commits: []releasenotes.ParsedCommit{{Description: "add build cache"}},
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if summary != "Faster builds." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if gotAuth != "Bearer key" || gotBody.Model != "test-model" {
+		t.Errorf("unexpected request auth %q model %q", gotAuth, gotBody.Model)
+	}
+	if len(gotBody.Messages) != 2 || !strings.Contains(gotBody.Messages[1].Content, "add build cache") {
+		t.Errorf("expected commit descriptions in prompt, got %+v", gotBody.Messages)
+	}
+}
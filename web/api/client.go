@@ -9,10 +9,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/narvanalabs/control-plane/internal/models"
 	"github.com/narvanalabs/control-plane/internal/store"
+	"github.com/narvanalabs/control-plane/internal/tracing"
 )
 
 // Client is an API client for the control-plane.
@@ -21,37 +25,124 @@ type Client struct {
 	httpClient *http.Client
 	token      string
 	orgID      string // Organization ID for X-Org-ID header
+	clientIP   string // Browser's IP for X-Real-IP header
+
+	consistency *Consistency // Consistency token sent with requests and updated by responses
 }
 
+// APIVersion is the API version the client is written against, sent with
+// every request.
+const APIVersion = "1.1"
+
 // NewClient creates a new API client.
 func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: &versionTransport{base: tracing.Transport(nil), client: "narvana-web"},
 		},
 	}
 }
 
+// WithClientName returns a new client that names itself name, e.g.
+// "narvanactl/1.8.0", so the API can tell which clients still use
+// deprecated routes.
+func (c *Client) WithClientName(name string) *Client {
+	clone := *c
+	base := c.httpClient.Transport
+	if t, ok := base.(*versionTransport); ok {
+		base = t.base
+	}
+	clone.httpClient = &http.Client{
+		Timeout:   c.httpClient.Timeout,
+		Transport: &versionTransport{base: base, client: name},
+	}
+	return &clone
+}
+
+// versionTransport pins the API version of requests and names the client
+// making them.
+type versionTransport struct {
+	base   http.RoundTripper
+	client string
+}
+
+func (t *versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Narvana-API-Version", APIVersion)
+	if t.client != "" {
+		req.Header.Set("Narvana-Client", t.client)
+	}
+	return t.base.RoundTrip(req)
+}
+
 // WithToken returns a new client with the specified auth token.
 func (c *Client) WithToken(token string) *Client {
-	return &Client{
-		baseURL:    c.baseURL,
-		httpClient: c.httpClient,
-		token:      token,
-		orgID:      c.orgID,
-	}
+	clone := *c
+	clone.token = token
+	return &clone
 }
 
 // WithOrg returns a new client with the specified organization ID.
 // The organization ID will be included in the X-Org-ID header for all requests.
 // **Validates: Requirements 13.1**
 func (c *Client) WithOrg(orgID string) *Client {
-	return &Client{
-		baseURL:    c.baseURL,
-		httpClient: c.httpClient,
-		token:      c.token,
-		orgID:      orgID,
+	clone := *c
+	clone.orgID = orgID
+	return &clone
+}
+
+// WithClientIP returns a new client that tells the API the requests are made
+// for a browser at ip, so that the API limits and records them by the
+// browser's address rather than the web server's. The API only believes it
+// when the web server is one of its trusted proxies.
+func (c *Client) WithClientIP(ip string) *Client {
+	clone := *c
+	clone.clientIP = ip
+	return &clone
+}
+
+// WithConsistency returns a new client whose reads see at least the state
+// after the writes recorded in cons, and which records its own writes there.
+func (c *Client) WithConsistency(cons *Consistency) *Client {
+	clone := *c
+	clone.consistency = cons
+	return &clone
+}
+
+// Consistency holds the consistency token of the latest write a browser
+// made, so that pages rendered after it never show the state from before it,
+// even when the API serves reads from a lagging read replica.
+type Consistency struct {
+	mu    sync.Mutex
+	token string
+}
+
+// NewConsistency returns a Consistency holding token, which may be empty.
+func NewConsistency(token string) *Consistency {
+	return &Consistency{token: token}
+}
+
+// Token returns the token of the latest write, or an empty token.
+func (c *Consistency) Token() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// observe records the consistency token of a response, if it carries one.
+func (c *Consistency) observe(resp *http.Response) {
+	if c == nil {
+		return
+	}
+	if token := resp.Header.Get("X-Consistency-Token"); token != "" {
+		c.mu.Lock()
+		c.token = token
+		c.mu.Unlock()
 	}
 }
 
@@ -68,10 +159,12 @@ type App struct {
 	Description string    `json:"description"`
 	IconURL     string    `json:"icon_url"`
 	Services    []Service `json:"services"`
-	Version     int       `json:"version"` // For optimistic locking
-	Domains     []string  `json:"domains"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// EnvVars are non-secret env vars inherited by every service.
+	EnvVars   map[string]string `json:"env_vars,omitempty"`
+	Version   int               `json:"version"` // For optimistic locking
+	Domains   []string          `json:"domains"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
 }
 
 // Service represents a service within an app.
@@ -91,6 +184,124 @@ type Service struct {
 	Port          int               `json:"port,omitempty"` // Container port the app listens on
 	EnvVars       map[string]string `json:"env_vars,omitempty"`
 	DependsOn     []string          `json:"depends_on,omitempty"`
+	Egress        *EgressPolicy     `json:"egress,omitempty"`      // Outbound network policy
+	OpenAPIURL    string            `json:"openapi_url,omitempty"` // Spec listed in the API catalog
+
+	// Cron services run Cron.Command on Cron.Schedule instead of continuously
+	Type string      `json:"type,omitempty"`
+	Cron *CronConfig `json:"cron,omitempty"`
+}
+
+// IsCron returns true if the service runs on a schedule.
+func (s *Service) IsCron() bool {
+	return s.Type == "cron"
+}
+
+// CronConfig is the schedule and command of a cron service.
+type CronConfig struct {
+	Schedule       string   `json:"schedule"`
+	Timezone       string   `json:"timezone,omitempty"`
+	Command        []string `json:"command"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// CronRun is one execution of a cron service's command.
+type CronRun struct {
+	ID           string     `json:"id"`
+	ServiceName  string     `json:"service_name"`
+	DeploymentID string     `json:"deployment_id,omitempty"`
+	Trigger      string     `json:"trigger"`
+	TriggeredBy  string     `json:"triggered_by,omitempty"`
+	Status       string     `json:"status"`
+	ScheduledAt  time.Time  `json:"scheduled_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// EgressPolicy restricts a service's outbound connections.
+type EgressPolicy struct {
+	DenyAll bool         `json:"deny_all"`
+	Allow   []EgressRule `json:"allow,omitempty"`
+}
+
+// EgressRule allows outbound traffic to a host or CIDR.
+type EgressRule struct {
+	Host     string `json:"host,omitempty"`
+	CIDR     string `json:"cidr,omitempty"`
+	Ports    []int  `json:"ports,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// EgressViolation counts blocked outbound connections to one destination.
+type EgressViolation struct {
+	DeploymentID string    `json:"deployment_id"`
+	Destination  string    `json:"destination"`
+	Port         int       `json:"port"`
+	Protocol     string    `json:"protocol"`
+	Count        int64     `json:"count"`
+	FirstSeenAt  time.Time `json:"first_seen_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+}
+
+// ServiceEgress holds a service's egress policy and recent violations.
+type ServiceEgress struct {
+	Policy       *EgressPolicy     `json:"policy,omitempty"`
+	Violations   []EgressViolation `json:"violations"`
+	TotalBlocked int64             `json:"total_blocked"`
+	Since        time.Time         `json:"since"`
+}
+
+// APIOperation is one operation of a service's OpenAPI spec.
+type APIOperation struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+}
+
+// ServiceAPISpec is the OpenAPI spec last fetched from a service's openapi_url.
+type ServiceAPISpec struct {
+	AppID       string         `json:"app_id"`
+	ServiceName string         `json:"service_name"`
+	URL         string         `json:"url"`
+	Title       string         `json:"title"`
+	Version     string         `json:"version"`
+	Description string         `json:"description,omitempty"`
+	Operations  []APIOperation `json:"operations"`
+	Error       string         `json:"error,omitempty"`
+	FetchedAt   *time.Time     `json:"fetched_at,omitempty"`
+	CheckedAt   time.Time      `json:"checked_at"`
+}
+
+// CatalogEntry is a service's API in the org's API catalog.
+type CatalogEntry struct {
+	ServiceAPISpec
+	AppName string `json:"app_name"`
+}
+
+// ServiceMetrics is a service's resource usage series over a range.
+type ServiceMetrics struct {
+	Range       string        `json:"range"`
+	StepSeconds int           `json:"step_seconds"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Points      []MetricPoint `json:"points"`
+}
+
+// MetricPoint is a service's resource usage over one step, summed across
+// its deployments.
+type MetricPoint struct {
+	Timestamp      time.Time `json:"timestamp"`
+	CPUPercent     float64   `json:"cpu_percent"`
+	CPUPercentMax  float64   `json:"cpu_percent_max"`
+	MemoryBytes    int64     `json:"memory_bytes"`
+	MemoryBytesMax int64     `json:"memory_bytes_max"`
+	NetworkRxRate  float64   `json:"network_rx_bytes_per_second"`
+	NetworkTxRate  float64   `json:"network_tx_bytes_per_second"`
+	Deployments    int       `json:"deployments"`
 }
 
 // ResourceSpec represents direct resource allocation.
@@ -101,8 +312,9 @@ type ResourceSpec struct {
 
 // DatabaseConfig represents a database configuration.
 type DatabaseConfig struct {
-	Type    string `json:"type"`
-	Version string `json:"version"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // Deployment represents a deployment from the API.
@@ -113,10 +325,95 @@ type Deployment struct {
 	Version     int       `json:"version"`
 	GitRef      string    `json:"git_ref"`
 	GitCommit   string    `json:"git_commit,omitempty"`
+	Artifact    string    `json:"artifact,omitempty"`
 	Status      string    `json:"status"`
 	NodeID      string    `json:"node_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// RollbackOf is the deployment whose artifact this rollback redeploys.
+	RollbackOf string            `json:"rollback_of,omitempty"`
+	Config     *DeploymentConfig `json:"config,omitempty"`
+}
+
+// DeploymentConfig holds the runtime settings of a deployment shown in the UI.
+type DeploymentConfig struct {
+	// Command is set on runs of cron services.
+	Command []string `json:"command,omitempty"`
+}
+
+// IsCronRun returns true if the deployment is a run of a cron service.
+func (d *Deployment) IsCronRun() bool {
+	return d.Config != nil && len(d.Config.Command) > 0
+}
+
+// Promotion is the evaluation of a deployment against a promotion policy.
+// WindowMinutes and MaxErrorRate are the criteria it is judged by.
+type Promotion struct {
+	ID                 string    `json:"id"`
+	PolicyID           string    `json:"policy_id,omitempty"`
+	EnvironmentID      string    `json:"environment_id,omitempty"`
+	SourceDeploymentID string    `json:"source_deployment_id"`
+	TargetDeploymentID string    `json:"target_deployment_id,omitempty"`
+	Status             string    `json:"status"`
+	WindowMinutes      int       `json:"window_minutes"`
+	MaxErrorRate       float64   `json:"max_error_rate"`
+	WindowEndsAt       time.Time `json:"window_ends_at"`
+	ErrorRate          float64   `json:"error_rate"`
+	LogLines           int       `json:"log_lines"`
+	Reason             string    `json:"reason,omitempty"`
+	RequestedBy        string    `json:"requested_by,omitempty"`
+	DecidedBy          string    `json:"decided_by,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DeploymentComparison describes what changed between deployment A (the
+// baseline) and B, and how each behaved while it was active.
+type DeploymentComparison struct {
+	A       Deployment              `json:"a"`
+	B       Deployment              `json:"b"`
+	Changes []DeploymentFieldChange `json:"changes"`
+	Env     []DeploymentEnvChange   `json:"env"`
+	Routes  []DeploymentRouteChange `json:"routes"`
+	Metrics struct {
+		A DeploymentMetrics `json:"a"`
+		B DeploymentMetrics `json:"b"`
+	} `json:"metrics"`
+	Regressions []string `json:"regressions"`
+}
+
+// DeploymentFieldChange is a deployment setting that differs between A and B.
+type DeploymentFieldChange struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// DeploymentEnvChange is an environment variable added, removed or changed in B.
+type DeploymentEnvChange struct {
+	Key    string `json:"key"`
+	Change string `json:"change"`
+	A      string `json:"a,omitempty"`
+	B      string `json:"b,omitempty"`
+}
+
+// DeploymentRouteChange is a port exposed by only one of the deployments.
+type DeploymentRouteChange struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Change   string `json:"change"`
+}
+
+// DeploymentMetrics summarizes a deployment's behavior over its active period.
+type DeploymentMetrics struct {
+	ActiveFrom    *time.Time `json:"active_from,omitempty"`
+	ActiveUntil   *time.Time `json:"active_until,omitempty"`
+	ActiveSeconds int64      `json:"active_seconds"`
+	LogLines      int        `json:"log_lines"`
+	ErrorLines    int        `json:"error_lines"`
+	ErrorRate     float64    `json:"error_rate"`
+	Sampled       bool       `json:"sampled,omitempty"`
+	BlockedEgress int64      `json:"blocked_egress"`
 }
 
 // Node represents a compute node from the API.
@@ -125,8 +422,55 @@ type Node struct {
 	Hostname      string         `json:"hostname"`
 	Address       string         `json:"address"`
 	Healthy       bool           `json:"healthy"`
+	Status        string         `json:"status,omitempty"`
+	HealthScore   int            `json:"health_score"`
 	Resources     *NodeResources `json:"resources,omitempty"`
 	LastHeartbeat time.Time      `json:"last_heartbeat"`
+	// ClockSkewMs is how far the node's clock is behind the control plane's,
+	// negative when ahead
+	ClockSkewMs          int64      `json:"clock_skew_ms"`
+	ClockStatus          string     `json:"clock_status,omitempty"`
+	CertificateExpiresAt *time.Time `json:"certificate_expires_at,omitempty"`
+	CertificateStatus    string     `json:"certificate_status,omitempty"`
+}
+
+// Operation is a long-running operation, such as a node drain, started by
+// a request that returned right away.
+type Operation struct {
+	ID         string         `json:"id"`
+	Kind       string         `json:"kind"`
+	Status     string         `json:"status"` // running, succeeded or failed
+	TargetID   string         `json:"target_id,omitempty"`
+	Progress   int            `json:"progress"`
+	Stage      string         `json:"stage,omitempty"`
+	Errors     []string       `json:"errors"`
+	Error      string         `json:"error,omitempty"`
+	Result     map[string]any `json:"result,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// NodeHealthEvent records a node becoming healthy or unhealthy.
+type NodeHealthEvent struct {
+	ID          string    `json:"id"`
+	NodeID      string    `json:"node_id"`
+	Healthy     bool      `json:"healthy"`
+	HealthScore int       `json:"health_score"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NodeJoinToken is a token new nodes register with. The token itself is
+// only returned when it is created.
+type NodeJoinToken struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	Token       string     `json:"token,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
 }
 
 // NodeResources represents resource availability.
@@ -139,14 +483,57 @@ type NodeResources struct {
 
 // Build represents a build job from the API.
 type Build struct {
-	ID           string    `json:"id"`
-	AppID        string    `json:"app_id"`
-	DeploymentID string    `json:"deployment_id"`
-	Status       string    `json:"status"`
-	Strategy     string    `json:"build_strategy,omitempty"`
-	Logs         string    `json:"logs,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string `json:"id"`
+	AppID        string `json:"app_id"`
+	DeploymentID string `json:"deployment_id"`
+	Status       string `json:"status"`
+	Strategy     string `json:"build_strategy,omitempty"`
+	Artifact     string `json:"artifact,omitempty"`
+	// DeduplicatedFrom is the earlier build whose artifact was reused.
+	DeduplicatedFrom string `json:"deduplicated_from,omitempty"`
+	// Reproducibility is "verified", "mismatch" or "error" when the build
+	// was rebuilt to check it is deterministic.
+	Reproducibility string `json:"reproducibility,omitempty"`
+	// ExternalMetadata is set when the artifact was built by an external CI
+	// system and submitted for deployment only.
+	ExternalMetadata map[string]string `json:"external_metadata,omitempty"`
+	// CancelRequestedAt is set while the worker running the build stops it.
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"`
+	Logs              string     `json:"logs,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// BuildSnapshot is the environment kept on a worker host for debugging a
+// failed build.
+type BuildSnapshot struct {
+	BuildID   string    `json:"build_id"`
+	Hostname  string    `json:"hostname"`
+	Image     string    `json:"image"`
+	FlakeRef  string    `json:"flake_ref"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Command starts a shell in the environment on the worker host.
+	Command string `json:"command"`
+}
+
+// SubmitBuildRequest hands an externally built artifact to a service.
+type SubmitBuildRequest struct {
+	Artifact       string                 `json:"artifact"`
+	GitRef         string                 `json:"git_ref,omitempty"`
+	GitCommit      string                 `json:"git_commit,omitempty"`
+	Metadata       map[string]string      `json:"metadata,omitempty"`
+	FreezeOverride *FreezeOverrideRequest `json:"freeze_override,omitempty"`
+}
+
+// FreezeOverrideRequest carries the reason for deploying during a freeze window.
+type FreezeOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SubmitBuildResponse is the recorded build and the deployment created for it.
+type SubmitBuildResponse struct {
+	Build      Build      `json:"build"`
+	Deployment Deployment `json:"deployment"`
 }
 
 // Secret represents a secret/env var for an app.
@@ -162,10 +549,27 @@ type Log struct {
 	DeploymentID string    `json:"deployment_id"`
 	Source       string    `json:"source"`
 	Level        string    `json:"level"`
+	Stream       string    `json:"stream,omitempty"`
 	Message      string    `json:"message"`
 	Timestamp    time.Time `json:"timestamp"`
 }
 
+// LogSearch selects the logs of an app. Empty fields match everything.
+type LogSearch struct {
+	ServiceName string
+	Query       string // Full-text search in web search syntax
+	Level       string // A level or comma-separated levels
+	Cursor      string // Continues a previous search with older entries
+	Limit       int
+}
+
+// LogSearchResult is a page of log search results. NextCursor continues the
+// search; it is empty on the last page.
+type LogSearchResult struct {
+	Logs       []Log  `json:"logs"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
 // Domain represents a custom domain mapping.
 type Domain struct {
 	ID         string    `json:"id"`
@@ -212,6 +616,70 @@ func (c *Client) GetDashboardStats(ctx context.Context) (*DashboardStatsResponse
 	return &stats, err
 }
 
+// StateCounts counts resources by state.
+type StateCounts struct {
+	Total   int            `json:"total"`
+	ByState map[string]int `json:"by_state"`
+}
+
+// HealthSummary holds compact health rollups for dashboard polling.
+type HealthSummary struct {
+	Apps     StateCounts `json:"apps"`
+	Services StateCounts `json:"services"`
+	Nodes    StateCounts `json:"nodes"`
+	Builds   StateCounts `json:"builds"`
+
+	NonReproducibleBuilds int `json:"non_reproducible_builds"`
+	ActiveAlerts          int `json:"active_alerts"`
+}
+
+// GetHealthSummary fetches health rollups in a single request. Pass the ETag
+// from a previous call to poll cheaply: when nothing changed it returns a nil
+// summary and the same ETag.
+func (c *Client) GetHealthSummary(ctx context.Context, etag string) (*HealthSummary, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/v1/health/summary", nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.orgID != "" {
+		req.Header.Set("X-Org-ID", c.orgID)
+	}
+	if c.clientIP != "" {
+		req.Header.Set("X-Real-IP", c.clientIP)
+	}
+	if token := c.consistency.Token(); token != "" {
+		req.Header.Set("X-Consistency-Token", token)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+	c.consistency.observe(resp)
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var summary HealthSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, "", fmt.Errorf("decoding response: %w", err)
+	}
+	return &summary, resp.Header.Get("ETag"), nil
+}
+
 // RecentDeployment holds data for recent deployments display.
 type RecentDeployment struct {
 	AppName     string
@@ -225,8 +693,12 @@ type NodeHealth struct {
 	Name       string
 	Address    string
 	Healthy    bool
+	Score      int
 	CPUPercent int
 	MemPercent int
+	// Why and when the node last became healthy or unhealthy, if it has
+	LastTransition    string
+	TransitionTimeAgo string
 }
 
 // ============================================================================
@@ -295,8 +767,11 @@ func (c *Client) UpdateUserProfile(ctx context.Context, name, avatarURL string)
 
 // AuthResponse is returned from login/register.
 type AuthResponse struct {
-	Token  string `json:"token"`
-	UserID string `json:"user_id"`
+	Token            string    `json:"token"`
+	UserID           string    `json:"user_id"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token,omitempty"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 // ============================================================================
@@ -356,6 +831,7 @@ type CreateServiceRequest struct {
 	SourceType    string            `json:"source_type"`
 	GitRepo       string            `json:"git_repo,omitempty"`
 	GitRef        string            `json:"git_ref,omitempty"`
+	BuildPath     string            `json:"build_path,omitempty"`
 	FlakeURI      string            `json:"flake_uri,omitempty"`
 	BuildStrategy BuildStrategy     `json:"build_strategy,omitempty"`
 	BuildConfig   *BuildConfig      `json:"build_config,omitempty"`
@@ -421,6 +897,19 @@ func (c *Client) Login(ctx context.Context, email, password string) (*AuthRespon
 	return &resp, err
 }
 
+// Refresh exchanges a refresh token for a new access token and refresh
+// token. The refresh token passed in stops working.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+	var resp AuthResponse
+	err := c.post(ctx, "/auth/refresh", map[string]string{"refresh_token": refreshToken}, &resp)
+	return &resp, err
+}
+
+// Logout revokes the session of the client's token.
+func (c *Client) Logout(ctx context.Context) error {
+	return c.post(ctx, "/v1/auth/logout", nil, nil)
+}
+
 // Register creates a new user account.
 func (c *Client) Register(ctx context.Context, email, password string) (*AuthResponse, error) {
 	req := RegisterRequest{Email: email, Password: password}
@@ -510,6 +999,599 @@ func (c *Client) DeleteOrg(ctx context.Context, orgID string) error {
 	return c.delete(ctx, "/v1/orgs/"+orgID)
 }
 
+// OrgMember is a member of an organization and their role.
+type OrgMember struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role"` // owner, admin, developer, viewer or member
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrgMembership is a user's role in an organization.
+type OrgMembership struct {
+	OrgID  string `json:"org_id"`
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// GetOrgMembership fetches the current user's role in an organization, used
+// by the org switcher to decide what the user may do in the selected org.
+func (c *Client) GetOrgMembership(ctx context.Context, orgID string) (*OrgMembership, error) {
+	var membership OrgMembership
+	err := c.Get(ctx, "/v1/orgs/"+orgID+"/membership", &membership)
+	return &membership, err
+}
+
+// ListOrgMembers fetches the members of an organization.
+func (c *Client) ListOrgMembers(ctx context.Context, orgID string) ([]OrgMember, error) {
+	var members []OrgMember
+	err := c.Get(ctx, "/v1/orgs/"+orgID+"/members", &members)
+	if members == nil {
+		members = []OrgMember{}
+	}
+	return members, err
+}
+
+// UpdateOrgMember changes a member's role in an organization.
+func (c *Client) UpdateOrgMember(ctx context.Context, orgID, userID, role string) (*OrgMembership, error) {
+	var membership OrgMembership
+	err := c.put(ctx, "/v1/orgs/"+orgID+"/members/"+userID, map[string]string{"role": role}, &membership)
+	return &membership, err
+}
+
+// RemoveOrgMember removes a member from an organization.
+func (c *Client) RemoveOrgMember(ctx context.Context, orgID, userID string) error {
+	return c.delete(ctx, "/v1/orgs/"+orgID+"/members/"+userID)
+}
+
+// CreateOrgInvitation invites someone to join an organization with a role.
+func (c *Client) CreateOrgInvitation(ctx context.Context, orgID, email, role string) (*Invitation, error) {
+	req := map[string]string{
+		"email": email,
+		"role":  role,
+	}
+	var invitation Invitation
+	err := c.post(ctx, "/v1/orgs/"+orgID+"/invitations", req, &invitation)
+	return &invitation, err
+}
+
+// ListOrgInvitations fetches the invitations to join an organization.
+func (c *Client) ListOrgInvitations(ctx context.Context, orgID string) ([]Invitation, error) {
+	var invitations []Invitation
+	err := c.Get(ctx, "/v1/orgs/"+orgID+"/invitations", &invitations)
+	if invitations == nil {
+		invitations = []Invitation{}
+	}
+	return invitations, err
+}
+
+// RevokeOrgInvitation revokes a pending invitation to join an organization.
+func (c *Client) RevokeOrgInvitation(ctx context.Context, orgID, invitationID string) error {
+	return c.delete(ctx, "/v1/orgs/"+orgID+"/invitations/"+invitationID)
+}
+
+// AcceptOrgInvitation adds the signed-in user to the organization they were
+// invited to.
+func (c *Client) AcceptOrgInvitation(ctx context.Context, token string) (*OrgMembership, error) {
+	var membership OrgMembership
+	err := c.post(ctx, "/v1/invitations/accept", map[string]string{"token": token}, &membership)
+	return &membership, err
+}
+
+// FreezeWindow is a period during which an org's deploys are blocked.
+type FreezeWindow struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"` // recurring or range
+	StartDay  string     `json:"start_day,omitempty"`
+	StartTime string     `json:"start_time,omitempty"`
+	EndDay    string     `json:"end_day,omitempty"`
+	EndTime   string     `json:"end_time,omitempty"`
+	Timezone  string     `json:"timezone,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Schedule describes when the window applies, e.g. "friday 16:00 – monday 08:00 (Europe/Berlin)".
+func (f FreezeWindow) Schedule() string {
+	if f.Kind == "range" && f.StartsAt != nil && f.EndsAt != nil {
+		return f.StartsAt.UTC().Format("Jan 2 15:04") + " – " + f.EndsAt.UTC().Format("Jan 2 15:04") + " UTC"
+	}
+	tz := f.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+	return fmt.Sprintf("%s %s – %s %s (%s)", f.StartDay, f.StartTime, f.EndDay, f.EndTime, tz)
+}
+
+// FreezeOverride is a deploy that went ahead during a freeze window.
+type FreezeOverride struct {
+	ID           string    `json:"id"`
+	WindowID     string    `json:"window_id"`
+	DeploymentID string    `json:"deployment_id"`
+	AppID        string    `json:"app_id"`
+	ServiceName  string    `json:"service_name"`
+	UserID       string    `json:"user_id"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// FreezeWindows lists an org's freeze windows, the active one and recent overrides.
+type FreezeWindows struct {
+	Windows   []FreezeWindow   `json:"windows"`
+	Active    *FreezeWindow    `json:"active,omitempty"`
+	Overrides []FreezeOverride `json:"overrides"`
+}
+
+// CreateFreezeWindowRequest is the request body for creating a freeze window.
+type CreateFreezeWindowRequest struct {
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	StartDay  string     `json:"start_day,omitempty"`
+	StartTime string     `json:"start_time,omitempty"`
+	EndDay    string     `json:"end_day,omitempty"`
+	EndTime   string     `json:"end_time,omitempty"`
+	Timezone  string     `json:"timezone,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+// ListFreezeWindows fetches an organization's deploy freeze windows.
+func (c *Client) ListFreezeWindows(ctx context.Context, orgID string) (*FreezeWindows, error) {
+	var windows FreezeWindows
+	err := c.Get(ctx, "/v1/orgs/"+orgID+"/freeze-windows", &windows)
+	return &windows, err
+}
+
+// CreateFreezeWindow creates a deploy freeze window (owners only).
+func (c *Client) CreateFreezeWindow(ctx context.Context, orgID string, req CreateFreezeWindowRequest) (*FreezeWindow, error) {
+	var window FreezeWindow
+	err := c.post(ctx, "/v1/orgs/"+orgID+"/freeze-windows", req, &window)
+	return &window, err
+}
+
+// DeleteFreezeWindow removes a deploy freeze window (owners only).
+func (c *Client) DeleteFreezeWindow(ctx context.Context, orgID, windowID string) error {
+	return c.delete(ctx, "/v1/orgs/"+orgID+"/freeze-windows/"+windowID)
+}
+
+// PolicyChange is one difference applied (or planned) by a policy import.
+type PolicyChange struct {
+	Action string `json:"action"` // add, update or remove
+	Kind   string `json:"kind"`   // binding or freeze_window
+	Name   string `json:"name"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// ApplyPolicyResult lists the changes made by a policy import.
+type ApplyPolicyResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []PolicyChange `json:"changes"`
+}
+
+// ExportPolicy fetches an organization's RBAC policy as a YAML document (owners only).
+func (c *Client) ExportPolicy(ctx context.Context, orgID string) ([]byte, error) {
+	data, _, err := c.GetRaw(ctx, "/v1/orgs/"+orgID+"/policy")
+	return data, err
+}
+
+// ApplyPolicy applies a YAML or JSON policy document to an organization. With
+// dryRun the changes are returned without being applied (owners only).
+func (c *Client) ApplyPolicy(ctx context.Context, orgID string, policy []byte, dryRun bool) (*ApplyPolicyResult, error) {
+	path := "/v1/orgs/" + orgID + "/policy"
+	if dryRun {
+		path += "?dry_run=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.baseURL+path, bytes.NewReader(policy))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+
+	var result ApplyPolicyResult
+	err = c.doRequest(req, &result)
+	return &result, err
+}
+
+// AppSpecChange is one difference applied (or planned) by an app spec.
+type AppSpecChange struct {
+	Action string   `json:"action"` // add, update or remove
+	Kind   string   `json:"kind"`   // service, env or domain
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"` // Changed settings of an updated service
+	From   string   `json:"from,omitempty"`   // Service a domain routed to
+	To     string   `json:"to,omitempty"`     // Service a domain routes to
+}
+
+// ApplyAppSpecResult lists the changes made by an app spec.
+type ApplyAppSpecResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Changes []AppSpecChange `json:"changes"`
+}
+
+// ApplyAppSpec converges an app to a YAML or JSON app spec (narvana.yaml).
+// With dryRun the changes are returned without being applied; with prune
+// services the spec does not list are deleted.
+func (c *Client) ApplyAppSpec(ctx context.Context, appID string, spec []byte, dryRun, prune bool) (*ApplyAppSpecResult, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	if prune {
+		query.Set("prune", "true")
+	}
+	path := "/v1/apps/" + appID + "/apply"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(spec))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+
+	var result ApplyAppSpecResult
+	err = c.doRequest(req, &result)
+	return &result, err
+}
+
+// AppManifest is the app spec last applied to an app, with the changes made
+// to the app since.
+type AppManifest struct {
+	AppID          string          `json:"app_id"`
+	Spec           string          `json:"spec"`
+	Prune          bool            `json:"prune"`
+	AppliedBy      string          `json:"applied_by,omitempty"`
+	AppliedAt      time.Time       `json:"applied_at"`
+	Drift          []AppSpecChange `json:"drift"`
+	DriftCheckedAt *time.Time      `json:"drift_checked_at,omitempty"`
+}
+
+// GetAppDrift checks an app against the app spec last applied to it.
+func (c *Client) GetAppDrift(ctx context.Context, appID string) (*AppManifest, error) {
+	var manifest AppManifest
+	err := c.Get(ctx, "/v1/apps/"+appID+"/drift", &manifest)
+	return &manifest, err
+}
+
+// RevertAppDrift applies the app spec last applied to an app again, undoing
+// changes made outside it. Secrets are not reverted.
+func (c *Client) RevertAppDrift(ctx context.Context, appID string) (*ApplyAppSpecResult, error) {
+	var result ApplyAppSpecResult
+	err := c.post(ctx, "/v1/apps/"+appID+"/drift/revert", nil, &result)
+	return &result, err
+}
+
+// AcceptAppDrift makes an app's live state the baseline drift is detected
+// against. The returned manifest's spec describes the app as it is.
+func (c *Client) AcceptAppDrift(ctx context.Context, appID string) (*AppManifest, error) {
+	var manifest AppManifest
+	err := c.post(ctx, "/v1/apps/"+appID+"/drift/accept", nil, &manifest)
+	return &manifest, err
+}
+
+// SCIMGroupMapping grants members of an identity provider group an org role.
+type SCIMGroupMapping struct {
+	GroupName string `json:"group_name"`
+	Role      string `json:"role"` // owner or member
+}
+
+// SCIMEvent is a recorded SCIM provisioning operation.
+type SCIMEvent struct {
+	ID           string    `json:"id"`
+	Operation    string    `json:"operation"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Status       string    `json:"status"` // success, conflict or error
+	Message      string    `json:"message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SCIMStatus is an org's SCIM configuration and recent sync activity.
+type SCIMStatus struct {
+	Enabled bool `json:"enabled"`
+	Token   *struct {
+		TokenPrefix string     `json:"token_prefix"`
+		CreatedAt   time.Time  `json:"created_at"`
+		LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	} `json:"token,omitempty"`
+	Mappings    []SCIMGroupMapping `json:"mappings"`
+	Users       int                `json:"users"`
+	ActiveUsers int                `json:"active_users"`
+	Groups      int                `json:"groups"`
+	LastSyncAt  *time.Time         `json:"last_sync_at,omitempty"`
+	Events      []SCIMEvent        `json:"events"`
+}
+
+// SCIMToken is a newly generated SCIM token, shown only once.
+type SCIMToken struct {
+	Token       string `json:"token"`
+	TokenPrefix string `json:"token_prefix"`
+	Endpoint    string `json:"endpoint"`
+}
+
+// GetSCIMStatus fetches an organization's SCIM configuration and recent events (owners only).
+func (c *Client) GetSCIMStatus(ctx context.Context, orgID string) (*SCIMStatus, error) {
+	var status SCIMStatus
+	err := c.Get(ctx, "/v1/orgs/"+orgID+"/scim", &status)
+	return &status, err
+}
+
+// GenerateSCIMToken creates or rotates an organization's SCIM token (owners only).
+func (c *Client) GenerateSCIMToken(ctx context.Context, orgID string) (*SCIMToken, error) {
+	var token SCIMToken
+	err := c.post(ctx, "/v1/orgs/"+orgID+"/scim/token", nil, &token)
+	return &token, err
+}
+
+// RevokeSCIMToken disables SCIM provisioning for an organization (owners only).
+func (c *Client) RevokeSCIMToken(ctx context.Context, orgID string) error {
+	return c.delete(ctx, "/v1/orgs/"+orgID+"/scim/token")
+}
+
+// SetSCIMMappings replaces an organization's group to role mappings (owners only).
+func (c *Client) SetSCIMMappings(ctx context.Context, orgID string, mappings []SCIMGroupMapping) (*SCIMStatus, error) {
+	var status SCIMStatus
+	err := c.put(ctx, "/v1/orgs/"+orgID+"/scim/mappings", map[string]interface{}{"mappings": mappings}, &status)
+	return &status, err
+}
+
+// APIKeyScope grants actions (read, deploy or write) on one app, or on all
+// apps when AppID is "*".
+type APIKeyScope struct {
+	AppID   string   `json:"app_id"`
+	Actions []string `json:"actions"`
+}
+
+// APIKey is a long-lived API credential. Keys without scopes have the full
+// access of their user.
+type APIKey struct {
+	ID         string        `json:"id"`
+	Prefix     string        `json:"prefix"`
+	Name       string        `json:"name"`
+	Scopes     []APIKeyScope `json:"scopes"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty"`
+	Key        string        `json:"key,omitempty"` // Only set when the key is created
+}
+
+// CreateAPIKeyRequest is the request body for creating an API key.
+type CreateAPIKeyRequest struct {
+	Name          string        `json:"name"`
+	ExpiresInDays int           `json:"expires_in_days,omitempty"`
+	Scopes        []APIKeyScope `json:"scopes,omitempty"`
+}
+
+// ListAPIKeys lists the current user's API keys.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	err := c.Get(ctx, "/v1/user/api-keys", &keys)
+	return keys, err
+}
+
+// CreateAPIKey creates an API key. The returned Key is only available here.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error) {
+	var key APIKey
+	err := c.post(ctx, "/v1/user/api-keys", req, &key)
+	return &key, err
+}
+
+// DeleteAPIKey revokes one of the current user's API keys.
+func (c *Client) DeleteAPIKey(ctx context.Context, keyID string) error {
+	return c.delete(ctx, "/v1/user/api-keys/"+keyID)
+}
+
+// SSHKey is a public key the current user registered for node SSH sessions.
+type SSHKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	PublicKey   string     `json:"public_key"`
+	Fingerprint string     `json:"fingerprint"`
+	KeyType     string     `json:"key_type"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ListSSHKeys lists the current user's SSH keys.
+func (c *Client) ListSSHKeys(ctx context.Context) ([]SSHKey, error) {
+	var keys []SSHKey
+	err := c.Get(ctx, "/v1/user/ssh-keys", &keys)
+	return keys, err
+}
+
+// CreateSSHKey registers an SSH public key in authorized_keys format. An
+// empty name uses the key's comment.
+func (c *Client) CreateSSHKey(ctx context.Context, name, publicKey string) (*SSHKey, error) {
+	var key SSHKey
+	err := c.post(ctx, "/v1/user/ssh-keys", map[string]string{"name": name, "public_key": publicKey}, &key)
+	return &key, err
+}
+
+// DeleteSSHKey removes one of the current user's SSH keys.
+func (c *Client) DeleteSSHKey(ctx context.Context, keyID string) error {
+	return c.delete(ctx, "/v1/user/ssh-keys/"+keyID)
+}
+
+// Command is an action the command palette offers. GET commands navigate to
+// Path; POST commands submit a form to it.
+type Command struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Section  string `json:"section"`
+	Shortcut string `json:"shortcut,omitempty"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Confirm  string `json:"confirm,omitempty"`
+}
+
+// ListCommands lists the commands the current user can run, including those
+// acting on the given app and service when set.
+func (c *Client) ListCommands(ctx context.Context, appID, serviceName string) ([]Command, error) {
+	params := url.Values{}
+	if appID != "" {
+		params.Set("app_id", appID)
+	}
+	if serviceName != "" {
+		params.Set("service", serviceName)
+	}
+	path := "/v1/commands"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	var commands []Command
+	err := c.Get(ctx, path, &commands)
+	return commands, err
+}
+
+// Session is a sign-in session of the current user.
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// ListSessions lists the current user's active sign-in sessions.
+func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	err := c.Get(ctx, "/v1/auth/sessions", &sessions)
+	return sessions, err
+}
+
+// RevokeSession signs one of the current user's devices out.
+func (c *Client) RevokeSession(ctx context.Context, sessionID string) error {
+	return c.delete(ctx, "/v1/auth/sessions/"+sessionID)
+}
+
+// RevokeOtherSessions signs out every device of the current user but this
+// one and returns how many sessions were revoked.
+func (c *Client) RevokeOtherSessions(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+"/v1/auth/sessions", nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	var resp struct {
+		Revoked int64 `json:"revoked"`
+	}
+	err = c.doRequest(req, &resp)
+	return resp.Revoked, err
+}
+
+// DoctorFinding is the outcome of one installation check; severity is ok,
+// info, warning or error.
+type DoctorFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Remedy   string `json:"remedy,omitempty"`
+}
+
+// DoctorReport lists the findings of an installation health check. Status is
+// the most serious severity among them.
+type DoctorReport struct {
+	Status    string          `json:"status"`
+	Findings  []DoctorFinding `json:"findings"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// Doctor checks the installation for missing prerequisites and
+// misconfigurations. Only instance admins may run it.
+func (c *Client) Doctor(ctx context.Context) (*DoctorReport, error) {
+	var report DoctorReport
+	err := c.Get(ctx, "/v1/server/doctor", &report)
+	return &report, err
+}
+
+// LogLevel is the log level of a control plane component (api, worker or
+// grpc). RevertAt is set when the level was changed at runtime.
+type LogLevel struct {
+	Component    string     `json:"component"`
+	Level        string     `json:"level"`
+	DefaultLevel string     `json:"default_level"`
+	RevertAt     *time.Time `json:"revert_at,omitempty"`
+}
+
+// GetLogLevels returns the log level of each component. Only instance admins
+// may see them.
+func (c *Client) GetLogLevels(ctx context.Context) ([]LogLevel, error) {
+	var levels []LogLevel
+	err := c.Get(ctx, "/v1/server/log-level", &levels)
+	return levels, err
+}
+
+// SetLogLevel changes the log level of a component, or of every component if
+// component is empty, until duration (e.g. "30m", empty for the server's
+// default) has passed. Only instance admins may change it.
+func (c *Client) SetLogLevel(ctx context.Context, component, level, duration string) ([]LogLevel, error) {
+	body := map[string]string{"component": component, "level": level, "duration": duration}
+	var levels []LogLevel
+	err := c.patch(ctx, "/v1/server/log-level", body, &levels)
+	return levels, err
+}
+
+// NotificationProvider is a configured notification channel. Secret config
+// fields (tokens, passwords, Slack and Discord webhook URLs) are returned empty.
+type NotificationProvider struct {
+	ID             string            `json:"id"`
+	Type           string            `json:"type"`
+	Name           string            `json:"name"`
+	Enabled        bool              `json:"enabled"`
+	Config         map[string]string `json:"config"`
+	Events         []string          `json:"events"`
+	LastDeliveryAt *time.Time        `json:"last_delivery_at,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+}
+
+// NotificationProviderRequest creates or updates a notification provider.
+// Secret config fields left empty on update keep their stored value.
+type NotificationProviderRequest struct {
+	Type    string            `json:"type"`
+	Name    string            `json:"name"`
+	Enabled *bool             `json:"enabled,omitempty"`
+	Config  map[string]string `json:"config"`
+	Events  []string          `json:"events,omitempty"`
+}
+
+// ListNotificationProviders lists the configured notification providers (admins only).
+func (c *Client) ListNotificationProviders(ctx context.Context) ([]NotificationProvider, error) {
+	var providers []NotificationProvider
+	err := c.Get(ctx, "/v1/notifications/providers", &providers)
+	return providers, err
+}
+
+// CreateNotificationProvider adds a notification provider (admins only).
+func (c *Client) CreateNotificationProvider(ctx context.Context, req NotificationProviderRequest) (*NotificationProvider, error) {
+	var provider NotificationProvider
+	err := c.post(ctx, "/v1/notifications/providers", req, &provider)
+	return &provider, err
+}
+
+// UpdateNotificationProvider edits a notification provider (admins only).
+func (c *Client) UpdateNotificationProvider(ctx context.Context, id string, req NotificationProviderRequest) (*NotificationProvider, error) {
+	var provider NotificationProvider
+	err := c.put(ctx, "/v1/notifications/providers/"+id, req, &provider)
+	return &provider, err
+}
+
+// DeleteNotificationProvider removes a notification provider (admins only).
+func (c *Client) DeleteNotificationProvider(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/notifications/providers/"+id)
+}
+
+// TestNotificationProvider sends a test notification. A provider failure is
+// returned as an API error with status 502.
+func (c *Client) TestNotificationProvider(ctx context.Context, id string) error {
+	return c.post(ctx, "/v1/notifications/providers/"+id+"/test", nil, nil)
+}
+
 // ============================================================================
 // App Methods
 // ============================================================================
@@ -555,6 +1637,42 @@ func (c *Client) DeleteApp(ctx context.Context, id string) error {
 	return c.delete(ctx, "/v1/apps/"+id)
 }
 
+// ListAppTemplates fetches the app templates new apps can start from.
+func (c *Client) ListAppTemplates(ctx context.Context) ([]models.AppTemplate, error) {
+	var templates []models.AppTemplate
+	err := c.Get(ctx, "/v1/templates", &templates)
+	if templates == nil {
+		templates = []models.AppTemplate{}
+	}
+	return templates, err
+}
+
+// GetAppTemplate fetches an app template by name.
+func (c *Client) GetAppTemplate(ctx context.Context, name string) (*models.AppTemplate, error) {
+	var template models.AppTemplate
+	err := c.Get(ctx, "/v1/templates/"+url.PathEscape(name), &template)
+	return &template, err
+}
+
+// InstantiateAppTemplateRequest is the request body for creating an app from
+// a template.
+type InstantiateAppTemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+}
+
+// InstantiateAppTemplate creates an app, its services and secrets from a
+// template. It returns the new app and the keys of the secrets set.
+func (c *Client) InstantiateAppTemplate(ctx context.Context, name string, req InstantiateAppTemplateRequest) (*App, []string, error) {
+	var resp struct {
+		App     App      `json:"app"`
+		Secrets []string `json:"secrets"`
+	}
+	err := c.post(ctx, "/v1/templates/"+url.PathEscape(name)+"/instantiate", req, &resp)
+	return &resp.App, resp.Secrets, err
+}
+
 // UpdateAppRequest is the request body for updating an app.
 type UpdateAppRequest struct {
 	Name        *string `json:"name,omitempty"`
@@ -593,6 +1711,76 @@ func (c *Client) UpdateServicePort(ctx context.Context, appID, serviceName strin
 	return &service, err
 }
 
+// UpdateServiceEgress replaces a service's egress policy.
+func (c *Client) UpdateServiceEgress(ctx context.Context, appID, serviceName string, policy EgressPolicy) (*Service, error) {
+	req := map[string]interface{}{"egress": policy}
+	var service Service
+	err := c.patch(ctx, "/v1/apps/"+appID+"/services/"+serviceName, req, &service)
+	return &service, err
+}
+
+// GetServiceEgress fetches a service's egress policy and the outbound
+// connections it blocked in the last week.
+func (c *Client) GetServiceEgress(ctx context.Context, appID, serviceName string) (*ServiceEgress, error) {
+	var egress ServiceEgress
+	err := c.Get(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/egress", &egress)
+	return &egress, err
+}
+
+// UpdateServiceOpenAPIURL sets the URL a service publishes its OpenAPI spec
+// at. An empty URL removes the service from the API catalog.
+func (c *Client) UpdateServiceOpenAPIURL(ctx context.Context, appID, serviceName, specURL string) (*Service, error) {
+	req := map[string]interface{}{"openapi_url": specURL}
+	var service Service
+	err := c.patch(ctx, "/v1/apps/"+appID+"/services/"+serviceName, req, &service)
+	return &service, err
+}
+
+// GetServiceAPISpec fetches the OpenAPI spec last fetched for a service.
+func (c *Client) GetServiceAPISpec(ctx context.Context, appID, serviceName string) (*ServiceAPISpec, error) {
+	var spec ServiceAPISpec
+	err := c.Get(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/openapi", &spec)
+	return &spec, err
+}
+
+// RefreshServiceAPISpec fetches a service's OpenAPI spec again now.
+func (c *Client) RefreshServiceAPISpec(ctx context.Context, appID, serviceName string) (*ServiceAPISpec, error) {
+	var spec ServiceAPISpec
+	err := c.post(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/openapi/refresh", nil, &spec)
+	return &spec, err
+}
+
+// SearchCatalog lists the APIs of the org's services matching a query.
+func (c *Client) SearchCatalog(ctx context.Context, query string) ([]CatalogEntry, error) {
+	var resp struct {
+		Entries []CatalogEntry `json:"entries"`
+	}
+	err := c.Get(ctx, "/v1/catalog?q="+url.QueryEscape(query), &resp)
+	return resp.Entries, err
+}
+
+// GetServiceMetrics fetches a service's resource usage over a range such as
+// "1h" or "24h".
+func (c *Client) GetServiceMetrics(ctx context.Context, appID, serviceName, rangeName string) (*ServiceMetrics, error) {
+	var metrics ServiceMetrics
+	err := c.Get(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/metrics?range="+url.QueryEscape(rangeName), &metrics)
+	return &metrics, err
+}
+
+// ListCronRuns lists a cron service's most recent runs, newest first.
+func (c *Client) ListCronRuns(ctx context.Context, appID, serviceName string) ([]CronRun, error) {
+	var runs []CronRun
+	err := c.Get(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/runs", &runs)
+	return runs, err
+}
+
+// TriggerCronRun starts a run of a cron service now.
+func (c *Client) TriggerCronRun(ctx context.Context, appID, serviceName string) (*CronRun, error) {
+	var run CronRun
+	err := c.post(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/runs", nil, &run)
+	return &run, err
+}
+
 // DeleteService removes a service from an app.
 func (c *Client) DeleteService(ctx context.Context, appID, serviceName string) error {
 	return c.delete(ctx, "/v1/apps/"+appID+"/services/"+serviceName)
@@ -609,6 +1797,15 @@ func (c *Client) Deploy(ctx context.Context, appID, serviceName string) (*Deploy
 	return &deployment, err
 }
 
+// DeployWithFreezeOverride deploys a service during an active freeze window.
+// The reason is recorded and the caller must be an owner.
+func (c *Client) DeployWithFreezeOverride(ctx context.Context, appID, serviceName, reason string) (*Deployment, error) {
+	var deployment Deployment
+	req := map[string]interface{}{"freeze_override": map[string]string{"reason": reason}}
+	err := c.post(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/deploy", req, &deployment)
+	return &deployment, err
+}
+
 // StopService stops a running service.
 func (c *Client) StopService(ctx context.Context, appID, serviceName string) error {
 	return c.post(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/stop", nil, nil)
@@ -653,13 +1850,65 @@ func (c *Client) GetDeployment(ctx context.Context, id string) (*Deployment, err
 	return &deployment, err
 }
 
-// RollbackDeployment rolls back a deployment.
+// RollbackDeployment rolls back to a deployment. The API redeploys its
+// artifact, or the last successful one before it if it never ran, as a new
+// deployment linked through RollbackOf.
 func (c *Client) RollbackDeployment(ctx context.Context, id string) (*Deployment, error) {
 	var deployment Deployment
 	err := c.post(ctx, "/v1/deployments/"+id+"/rollback", nil, &deployment)
 	return &deployment, err
 }
 
+// CancelDeployment cancels a deployment that has not started on a node,
+// along with its build.
+func (c *Client) CancelDeployment(ctx context.Context, id string) error {
+	return c.post(ctx, "/v1/deployments/"+id+"/cancel", nil, nil)
+}
+
+// DiffDeployments compares the configuration and runtime metrics of
+// deployment b against deployment a.
+func (c *Client) DiffDeployments(ctx context.Context, a, b string) (*DeploymentComparison, error) {
+	var comparison DeploymentComparison
+	query := url.Values{"a": {a}, "b": {b}}
+	err := c.Get(ctx, "/v1/deployments/compare?"+query.Encode(), &comparison)
+	return &comparison, err
+}
+
+// ListDeploymentPromotions fetches the promotions a deployment was evaluated
+// for or created by.
+func (c *Client) ListDeploymentPromotions(ctx context.Context, deploymentID string) ([]Promotion, error) {
+	var promotions []Promotion
+	err := c.Get(ctx, "/v1/deployments/"+deploymentID+"/promotions", &promotions)
+	return promotions, err
+}
+
+// PromoteDeployment promotes a running deployment into the next environment
+// of its app's pipeline, or into the named later environment if environment
+// is set.
+func (c *Client) PromoteDeployment(ctx context.Context, deploymentID, environment string) (*Promotion, error) {
+	var body any
+	if environment != "" {
+		body = map[string]string{"environment": environment}
+	}
+	var promotion Promotion
+	err := c.post(ctx, "/v1/deployments/"+deploymentID+"/promote", body, &promotion)
+	return &promotion, err
+}
+
+// ApprovePromotion approves a promotion awaiting approval on the source app.
+func (c *Client) ApprovePromotion(ctx context.Context, appID, promotionID string) (*Promotion, error) {
+	var promotion Promotion
+	err := c.post(ctx, "/v1/apps/"+appID+"/promotions/"+promotionID+"/approve", nil, &promotion)
+	return &promotion, err
+}
+
+// RejectPromotion declines a promotion awaiting approval on the source app.
+func (c *Client) RejectPromotion(ctx context.Context, appID, promotionID string) (*Promotion, error) {
+	var promotion Promotion
+	err := c.post(ctx, "/v1/apps/"+appID+"/promotions/"+promotionID+"/reject", nil, &promotion)
+	return &promotion, err
+}
+
 // ============================================================================
 // Secret Methods
 // ============================================================================
@@ -680,6 +1929,29 @@ func (c *Client) CreateSecret(ctx context.Context, appID, key, value string) err
 	return c.post(ctx, "/v1/apps/"+appID+"/secrets", req, nil)
 }
 
+// SecretImportResult reports which keys a secret import created, updated
+// and skipped.
+type SecretImportResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Skipped []string `json:"skipped"`
+}
+
+// ImportSecrets sets an app's secrets from the contents of a .env file.
+// onConflict is fail, skip or overwrite.
+func (c *Client) ImportSecrets(ctx context.Context, appID, dotenv, onConflict string) (*SecretImportResult, error) {
+	path := "/v1/apps/" + appID + "/secrets/import?format=dotenv&on_conflict=" + url.QueryEscape(onConflict)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, strings.NewReader(dotenv))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	var result SecretImportResult
+	err = c.doRequest(req, &result)
+	return &result, err
+}
+
 // DeleteSecret removes a secret from an app.
 func (c *Client) DeleteSecret(ctx context.Context, appID, key string) error {
 	return c.delete(ctx, "/v1/apps/"+appID+"/secrets/"+key)
@@ -711,6 +1983,32 @@ func (c *Client) GetServiceLogs(ctx context.Context, appID, serviceName string)
 	return resp.Logs, err
 }
 
+// SearchLogs searches an app's logs across its deployments, newest first.
+func (c *Client) SearchLogs(ctx context.Context, appID string, search LogSearch) (*LogSearchResult, error) {
+	params := url.Values{}
+	// q makes the request a search even when empty
+	params.Set("q", search.Query)
+	if search.ServiceName != "" {
+		params.Set("service_name", search.ServiceName)
+	}
+	if search.Level != "" {
+		params.Set("level", search.Level)
+	}
+	if search.Cursor != "" {
+		params.Set("cursor", search.Cursor)
+	}
+	if search.Limit > 0 {
+		params.Set("limit", strconv.Itoa(search.Limit))
+	}
+
+	var result LogSearchResult
+	err := c.Get(ctx, "/v1/apps/"+appID+"/logs?"+params.Encode(), &result)
+	if result.Logs == nil {
+		result.Logs = []Log{}
+	}
+	return &result, err
+}
+
 // GetBuildByDeployment fetches a build by its deployment ID.
 func (c *Client) GetBuildByDeployment(ctx context.Context, deploymentID string) (*Build, error) {
 	var builds []Build
@@ -737,6 +2035,18 @@ func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
 	return nodes, err
 }
 
+// ListNodeHealthEvents retrieves the latest health events of a node, or of
+// all nodes if nodeID is empty.
+func (c *Client) ListNodeHealthEvents(ctx context.Context, nodeID string) ([]NodeHealthEvent, error) {
+	var events []NodeHealthEvent
+	path := "/v1/nodes/health-events?limit=100"
+	if nodeID != "" {
+		path += "&node_id=" + url.QueryEscape(nodeID)
+	}
+	err := c.Get(ctx, path, &events)
+	return events, err
+}
+
 // GetNode retrieves a specific node by ID.
 func (c *Client) GetNode(ctx context.Context, id string) (*Node, error) {
 	var node Node
@@ -744,6 +2054,55 @@ func (c *Client) GetNode(ctx context.Context, id string) (*Node, error) {
 	return &node, err
 }
 
+// UpdateNodeStatus applies a lifecycle action to a node: approve, reject,
+// cordon or uncordon.
+func (c *Client) UpdateNodeStatus(ctx context.Context, id, action string) (*Node, error) {
+	var node Node
+	err := c.post(ctx, "/v1/nodes/"+id+"/"+action, nil, &node)
+	return &node, err
+}
+
+// DrainNode starts draining a node and returns the operation following the
+// drain.
+func (c *Client) DrainNode(ctx context.Context, id string) (*Operation, error) {
+	var op Operation
+	err := c.post(ctx, "/v1/nodes/"+id+"/drain", nil, &op)
+	return &op, err
+}
+
+// GetOperation retrieves a long-running operation's progress.
+func (c *Client) GetOperation(ctx context.Context, id string) (*Operation, error) {
+	var op Operation
+	err := c.Get(ctx, "/v1/operations/"+id, &op)
+	return &op, err
+}
+
+// DeleteNode removes a node that no longer runs deployments.
+func (c *Client) DeleteNode(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/nodes/"+id)
+}
+
+// ListNodeJoinTokens retrieves the tokens new nodes can join with.
+func (c *Client) ListNodeJoinTokens(ctx context.Context) ([]NodeJoinToken, error) {
+	var tokens []NodeJoinToken
+	err := c.Get(ctx, "/v1/settings/node-join-tokens", &tokens)
+	return tokens, err
+}
+
+// CreateNodeJoinToken generates a join token. expiresInDays of 0 creates a
+// token that does not expire.
+func (c *Client) CreateNodeJoinToken(ctx context.Context, description string, expiresInDays int) (*NodeJoinToken, error) {
+	var token NodeJoinToken
+	req := map[string]interface{}{"description": description, "expires_in_days": expiresInDays}
+	err := c.post(ctx, "/v1/settings/node-join-tokens", req, &token)
+	return &token, err
+}
+
+// DeleteNodeJoinToken revokes a join token.
+func (c *Client) DeleteNodeJoinToken(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/settings/node-join-tokens/"+id)
+}
+
 // ListBuilds retrieves all builds for the user from the API.
 func (c *Client) ListBuilds(ctx context.Context) ([]Build, error) {
 	var builds []Build
@@ -767,6 +2126,34 @@ func (c *Client) RetryBuild(ctx context.Context, id string) error {
 	return c.post(ctx, "/v1/builds/"+id+"/retry", nil, nil)
 }
 
+// CancelBuild cancels a queued or running build and its deployment. A
+// running build is stopped by its worker shortly after.
+func (c *Client) CancelBuild(ctx context.Context, id string) error {
+	return c.post(ctx, "/v1/builds/"+id+"/cancel", nil, nil)
+}
+
+// GetBuildSnapshot retrieves the environment kept for debugging a failed build.
+func (c *Client) GetBuildSnapshot(ctx context.Context, id string) (*BuildSnapshot, error) {
+	var snapshot BuildSnapshot
+	if err := c.Get(ctx, "/v1/builds/"+id+"/snapshot", &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// DeleteBuildSnapshot discards the environment kept for a failed build.
+func (c *Client) DeleteBuildSnapshot(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/builds/"+id+"/snapshot")
+}
+
+// SubmitBuild records an artifact built outside Narvana and deploys it,
+// skipping the internal builder.
+func (c *Client) SubmitBuild(ctx context.Context, appID, serviceName string, req SubmitBuildRequest) (*SubmitBuildResponse, error) {
+	var resp SubmitBuildResponse
+	err := c.post(ctx, "/v1/apps/"+appID+"/services/"+serviceName+"/builds", req, &resp)
+	return &resp, err
+}
+
 // ============================================================================
 // Domain Methods
 // ============================================================================
@@ -939,6 +2326,292 @@ func (c *Client) DeleteUser(ctx context.Context, userID string) error {
 	return c.delete(ctx, "/v1/users/"+userID)
 }
 
+// ============================================================================
+// Admin Console Methods
+// ============================================================================
+
+// AdminOverview contains instance-wide totals for the admin console.
+type AdminOverview struct {
+	Users               int            `json:"users"`
+	Orgs                int            `json:"orgs"`
+	Apps                int            `json:"apps"`
+	Services            int            `json:"services"`
+	ActiveAnnouncements int            `json:"active_announcements"`
+	Jobs                *JobHealth     `json:"jobs"`
+	License             *LicenseStatus `json:"license"`
+}
+
+// OrgUsage describes resource usage for a single organization.
+type OrgUsage struct {
+	Org                Organization `json:"org"`
+	Members            int          `json:"members"`
+	Apps               int          `json:"apps"`
+	Services           int          `json:"services"`
+	RunningDeployments int          `json:"running_deployments"`
+}
+
+// JobHealth summarizes the state of the background build queue.
+type JobHealth struct {
+	Healthy            bool  `json:"healthy"`
+	QueuedBuilds       int   `json:"queued_builds"`
+	RunningBuilds      int   `json:"running_builds"`
+	StuckBuilds        int   `json:"stuck_builds"`
+	OldestQueuedSecs   int64 `json:"oldest_queued_seconds"`
+	PendingDeployments int   `json:"pending_deployments"`
+}
+
+// LicenseStatus describes the license attached to the instance.
+type LicenseStatus struct {
+	Edition   string     `json:"edition"`
+	Licensed  bool       `json:"licensed"`
+	Expired   bool       `json:"expired"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// GetAdminOverview fetches instance-wide totals (admin only).
+func (c *Client) GetAdminOverview(ctx context.Context) (*AdminOverview, error) {
+	var overview AdminOverview
+	err := c.Get(ctx, "/v1/admin/overview", &overview)
+	return &overview, err
+}
+
+// ListAdminOrgs fetches every organization with its usage (admin only).
+func (c *Client) ListAdminOrgs(ctx context.Context) ([]OrgUsage, error) {
+	var orgs []OrgUsage
+	err := c.Get(ctx, "/v1/admin/orgs", &orgs)
+	if orgs == nil {
+		orgs = []OrgUsage{}
+	}
+	return orgs, err
+}
+
+// ListAdminApps fetches every application on the instance (admin only).
+func (c *Client) ListAdminApps(ctx context.Context) ([]App, error) {
+	var apps []App
+	err := c.Get(ctx, "/v1/admin/apps", &apps)
+	if apps == nil {
+		apps = []App{}
+	}
+	return apps, err
+}
+
+// ListFeatureFlags fetches the instance feature flags (admin only).
+func (c *Client) ListFeatureFlags(ctx context.Context) (map[string]bool, error) {
+	var flags map[string]bool
+	err := c.Get(ctx, "/v1/admin/feature-flags", &flags)
+	if flags == nil {
+		flags = map[string]bool{}
+	}
+	return flags, err
+}
+
+// UpdateFeatureFlags sets the given feature flags (admin only).
+func (c *Client) UpdateFeatureFlags(ctx context.Context, flags map[string]bool) error {
+	return c.put(ctx, "/v1/admin/feature-flags", flags, nil)
+}
+
+// Impersonate returns a short-lived token for acting as another user (admin only).
+func (c *Client) Impersonate(ctx context.Context, userID string) (*AuthResponse, error) {
+	req := map[string]string{"user_id": userID}
+	var resp AuthResponse
+	err := c.post(ctx, "/v1/admin/impersonate", req, &resp)
+	return &resp, err
+}
+
+// ============================================================================
+// Announcement Methods
+// ============================================================================
+
+// CreateAnnouncementRequest is the request body for publishing an announcement.
+type CreateAnnouncementRequest struct {
+	Message  string     `json:"message"`
+	Level    string     `json:"level"`
+	Audience string     `json:"audience"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// ListActiveAnnouncements fetches the announcements visible to the current user.
+func (c *Client) ListActiveAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := c.Get(ctx, "/v1/announcements", &announcements)
+	if announcements == nil {
+		announcements = []models.Announcement{}
+	}
+	return announcements, err
+}
+
+// ListAnnouncements fetches every announcement, including scheduled and expired ones (admin only).
+func (c *Client) ListAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := c.Get(ctx, "/v1/admin/announcements", &announcements)
+	if announcements == nil {
+		announcements = []models.Announcement{}
+	}
+	return announcements, err
+}
+
+// CreateAnnouncement publishes a new announcement (admin only).
+func (c *Client) CreateAnnouncement(ctx context.Context, req CreateAnnouncementRequest) (*models.Announcement, error) {
+	var announcement models.Announcement
+	err := c.post(ctx, "/v1/admin/announcements", req, &announcement)
+	return &announcement, err
+}
+
+// DeleteAnnouncement removes an announcement (admin only).
+func (c *Client) DeleteAnnouncement(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/admin/announcements/"+id)
+}
+
+// FederationPeerRequest is the request body for registering a federation peer.
+type FederationPeerRequest struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	WebURL string `json:"web_url,omitempty"`
+	APIKey string `json:"api_key"`
+}
+
+// GetFederationView fetches the combined view of this instance and its peers (admin only).
+func (c *Client) GetFederationView(ctx context.Context) (*models.FederationView, error) {
+	var view models.FederationView
+	if err := c.Get(ctx, "/v1/admin/federation", &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListFederationPeers fetches the registered federation peers (admin only).
+func (c *Client) ListFederationPeers(ctx context.Context) ([]models.FederationPeer, error) {
+	var peers []models.FederationPeer
+	err := c.Get(ctx, "/v1/admin/federation/peers", &peers)
+	if peers == nil {
+		peers = []models.FederationPeer{}
+	}
+	return peers, err
+}
+
+// CreateFederationPeer registers another instance as a federation peer (admin only).
+func (c *Client) CreateFederationPeer(ctx context.Context, req FederationPeerRequest) (*models.FederationPeer, error) {
+	var peer models.FederationPeer
+	err := c.post(ctx, "/v1/admin/federation/peers", req, &peer)
+	return &peer, err
+}
+
+// DeleteFederationPeer removes a federation peer (admin only).
+func (c *Client) DeleteFederationPeer(ctx context.Context, id string) error {
+	return c.delete(ctx, "/v1/admin/federation/peers/"+id)
+}
+
+// WhatsNewRelease is a single release in the "what's new" feed.
+type WhatsNewRelease struct {
+	Version    string    `json:"version"`
+	Date       time.Time `json:"date"`
+	ReleaseURL string    `json:"release_url,omitempty"`
+	ReleaseChanges
+}
+
+// ReleaseChanges is a list of commits categorized into release note sections.
+type ReleaseChanges struct {
+	Features        []WhatsNewItem `json:"features"`
+	Improvements    []WhatsNewItem `json:"improvements"`
+	BugFixes        []WhatsNewItem `json:"bug_fixes"`
+	BreakingChanges []WhatsNewItem `json:"breaking_changes"`
+	Other           []WhatsNewItem `json:"other"`
+}
+
+// IsEmpty reports whether there are no changes in any section.
+func (c ReleaseChanges) IsEmpty() bool {
+	return len(c.Features)+len(c.Improvements)+len(c.BugFixes)+len(c.BreakingChanges)+len(c.Other) == 0
+}
+
+// WhatsNewItem is a single change within a release.
+type WhatsNewItem struct {
+	Area        string `json:"area,omitempty"`
+	Description string `json:"description"`
+}
+
+// GetWhatsNew fetches recent release notes, newest first.
+func (c *Client) GetWhatsNew(ctx context.Context) ([]WhatsNewRelease, error) {
+	var releases []WhatsNewRelease
+	err := c.Get(ctx, "/v1/whats-new", &releases)
+	if releases == nil {
+		releases = []WhatsNewRelease{}
+	}
+	return releases, err
+}
+
+// Release is the release notes linked to a deployment.
+type Release struct {
+	ID           string         `json:"id"`
+	AppID        string         `json:"app_id"`
+	DeploymentID string         `json:"deployment_id"`
+	ServiceName  string         `json:"service_name"`
+	Version      int            `json:"version"`
+	FromCommit   string         `json:"from_commit,omitempty"`
+	ToCommit     string         `json:"to_commit,omitempty"`
+	Commits      []string       `json:"commits"`
+	Notes        string         `json:"notes,omitempty"`
+	Source       string         `json:"source"`
+	Changes      ReleaseChanges `json:"changes"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// ListReleases fetches release notes for an app, newest first.
+func (c *Client) ListReleases(ctx context.Context, appID string) ([]Release, error) {
+	var releases []Release
+	err := c.Get(ctx, "/v1/apps/"+appID+"/releases", &releases)
+	return releases, err
+}
+
+// GetDeploymentRelease fetches the release notes linked to a deployment.
+func (c *Client) GetDeploymentRelease(ctx context.Context, appID, deploymentID string) (*Release, error) {
+	var release Release
+	err := c.Get(ctx, "/v1/apps/"+appID+"/releases/"+deploymentID, &release)
+	if err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// CommitInfo is a single commit in a deployment commit range.
+type CommitInfo struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	HTMLURL string    `json:"html_url"`
+}
+
+// CommitRange is the set of commits shipped between two deployments of a service.
+type CommitRange struct {
+	ServiceName      string         `json:"service_name"`
+	Repo             string         `json:"repo"`
+	FromDeploymentID string         `json:"from_deployment_id"`
+	ToDeploymentID   string         `json:"to_deployment_id"`
+	FromCommit       string         `json:"from_commit"`
+	ToCommit         string         `json:"to_commit"`
+	TotalCommits     int            `json:"total_commits"`
+	Commits          []CommitInfo   `json:"commits"`
+	Changes          ReleaseChanges `json:"changes"`
+	CompareURL       string         `json:"compare_url,omitempty"`
+	Summary          string         `json:"summary"`
+}
+
+// CompareDeployments fetches the commits between two deployments. An empty
+// fromID compares against the previous deployment of the same service.
+func (c *Client) CompareDeployments(ctx context.Context, appID, fromID, toID string) (*CommitRange, error) {
+	query := url.Values{"to": {toID}}
+	if fromID != "" {
+		query.Set("from", fromID)
+	}
+	var commitRange CommitRange
+	err := c.Get(ctx, "/v1/apps/"+appID+"/deployments/compare?"+query.Encode(), &commitRange)
+	if err != nil {
+		return nil, err
+	}
+	return &commitRange, nil
+}
+
 // ============================================================================
 // Invitation Methods
 // ============================================================================
@@ -950,6 +2623,8 @@ type Invitation struct {
 	Token      string `json:"token,omitempty"`
 	InvitedBy  string `json:"invited_by"`
 	Role       string `json:"role"`
+	OrgID      string `json:"org_id,omitempty"`
+	OrgRole    string `json:"org_role,omitempty"`
 	Status     string `json:"status"`
 	ExpiresAt  string `json:"expires_at"`
 	AcceptedAt string `json:"accepted_at,omitempty"`
@@ -1043,12 +2718,26 @@ func (c *Client) GetDashboardData(ctx context.Context) (*DashboardStats, []Recen
 		if err != nil {
 			return
 		}
+		// Events are newest first, so the first one seen is the latest
+		lastEvents := make(map[string]NodeHealthEvent)
+		if events, err := c.ListNodeHealthEvents(ctx, ""); err == nil {
+			for _, e := range events {
+				if _, ok := lastEvents[e.NodeID]; !ok {
+					lastEvents[e.NodeID] = e
+				}
+			}
+		}
 		mu.Lock()
 		for _, node := range nodes {
 			nh := NodeHealth{
 				Name:    node.Hostname,
 				Address: node.Address,
 				Healthy: node.Healthy,
+				Score:   node.HealthScore,
+			}
+			if e, ok := lastEvents[node.ID]; ok {
+				nh.LastTransition = e.Reason
+				nh.TransitionTimeAgo = formatTimeAgo(e.CreatedAt)
 			}
 			if node.Resources != nil && node.Resources.CPUTotal > 0 {
 				nh.CPUPercent = int(((node.Resources.CPUTotal - node.Resources.CPUAvailable) / node.Resources.CPUTotal) * 100)
@@ -1151,12 +2840,19 @@ func (c *Client) GetRaw(ctx context.Context, path string) ([]byte, string, error
 	if c.orgID != "" {
 		req.Header.Set("X-Org-ID", c.orgID)
 	}
+	if c.clientIP != "" {
+		req.Header.Set("X-Real-IP", c.clientIP)
+	}
+	if token := c.consistency.Token(); token != "" {
+		req.Header.Set("X-Consistency-Token", token)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("making request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.consistency.observe(resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -1206,6 +2902,21 @@ func (c *Client) patch(ctx context.Context, path string, body interface{}, resul
 	return c.doRequest(req, result)
 }
 
+// put performs a PUT request and unmarshals the response.
+func (c *Client) put(ctx context.Context, path string, body interface{}, result interface{}) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshaling request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.baseURL+path, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.doRequest(req, result)
+}
+
 // delete performs a DELETE request.
 func (c *Client) delete(ctx context.Context, path string) error {
 	req, err := http.NewRequestWithContext(ctx, "DELETE", c.baseURL+path, nil)
@@ -1215,6 +2926,12 @@ func (c *Client) delete(ctx context.Context, path string) error {
 	return c.doRequest(req, nil)
 }
 
+// IsRateLimited reports whether err is the API refusing a request for
+// exceeding a rate limit.
+func IsRateLimited(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "API error (429)")
+}
+
 // doRequest executes the HTTP request and handles the response.
 func (c *Client) doRequest(req *http.Request, result interface{}) error {
 	if c.token != "" {
@@ -1223,6 +2940,12 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 	if c.orgID != "" {
 		req.Header.Set("X-Org-ID", c.orgID)
 	}
+	if c.clientIP != "" {
+		req.Header.Set("X-Real-IP", c.clientIP)
+	}
+	if token := c.consistency.Token(); token != "" {
+		req.Header.Set("X-Consistency-Token", token)
+	}
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -1230,6 +2953,7 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 		return fmt.Errorf("making request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.consistency.observe(resp)
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// DevEnvVar is a variable of a service's resolved environment. Secret is set
+// for values that come from app or org shared secrets.
+type DevEnvVar struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+// DevDependency is a service a dev session reaches through tunnels.
+type DevDependency struct {
+	Service string `json:"service"`
+	Ports   []int  `json:"ports"`
+	Running bool   `json:"running"`
+}
+
+// DevSession is everything needed to run a service locally.
+type DevSession struct {
+	AppID        string          `json:"app_id"`
+	Service      string          `json:"service"`
+	StartCommand string          `json:"start_command,omitempty"`
+	Env          []DevEnvVar     `json:"env"`
+	Dependencies []DevDependency `json:"dependencies"`
+}
+
+// CreateDevSession resolves a service's environment and dependencies for
+// running it locally. with names services to tunnel to in addition to the
+// service's depends_on.
+func (c *Client) CreateDevSession(ctx context.Context, appID, serviceName string, with []string) (*DevSession, error) {
+	var session DevSession
+	req := map[string][]string{"with": with}
+	err := c.post(ctx, "/v1/apps/"+url.PathEscape(appID)+"/services/"+url.PathEscape(serviceName)+"/dev", req, &session)
+	return &session, err
+}
+
+// OpenTunnel opens a connection to a port of a service's running deployment,
+// relayed by the control plane over a WebSocket. Like log streams, tunnels
+// are not subject to the client's request timeout.
+func (c *Client) OpenTunnel(ctx context.Context, appID, serviceName string, port int) (io.ReadWriteCloser, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing API URL: %w", err)
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/apps/" + url.PathEscape(appID) + "/services/" + url.PathEscape(serviceName) + "/tunnel/ws"
+	u.RawQuery = "port=" + strconv.Itoa(port)
+
+	header := http.Header{}
+	header.Set("Narvana-API-Version", APIVersion)
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.orgID != "" {
+		header.Set("X-Org-ID", c.orgID)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+		}
+		return nil, fmt.Errorf("opening tunnel: %w", err)
+	}
+	return &tunnelConn{ws: conn}, nil
+}
+
+// tunnelConn reads and writes a tunnel's bytes as binary WebSocket messages.
+type tunnelConn struct {
+	ws     *websocket.Conn
+	reader io.Reader
+}
+
+func (t *tunnelConn) Read(p []byte) (int, error) {
+	for {
+		if t.reader != nil {
+			n, err := t.reader.Read(p)
+			if err != io.EOF {
+				return n, err
+			}
+			// The message is exhausted; continue with the next one
+			t.reader = nil
+			if n > 0 {
+				return n, nil
+			}
+		}
+		_, r, err := t.ws.NextReader()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		t.reader = r
+	}
+}
+
+func (t *tunnelConn) Write(p []byte) (int, error) {
+	if err := t.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (t *tunnelConn) Close() error {
+	return t.ws.Close()
+}
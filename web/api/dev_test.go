@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestOpenTunnel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/apps/my-app/services/db/tunnel/ws" || r.URL.Query().Get("port") != "5432" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, msg, _ := conn.ReadMessage()
+		conn.WriteMessage(websocket.BinaryMessage, append([]byte("got "), msg...))
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer srv.Close()
+
+	tunnel, err := NewClient(srv.URL).WithToken("tok").OpenTunnel(context.Background(), "my-app", "db", 5432)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tunnel.Close()
+	if _, err := tunnel.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(tunnel)
+	if err != nil || string(got) != "got hello" {
+		t.Errorf("read %q, %v; want the reply then EOF", got, err)
+	}
+}
+
+func TestOpenTunnelAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"code": "conflict", "message": "Service db is not running"})
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL).OpenTunnel(context.Background(), "my-app", "db", 5432)
+	if err == nil || !strings.Contains(err.Error(), "API error (409)") || !strings.Contains(err.Error(), "is not running") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LogOptions filters log queries and streams. Empty fields are not sent.
+type LogOptions struct {
+	ServiceName  string
+	DeploymentID string
+	Source       string // "build" or "runtime"
+}
+
+// query renders the options as a URL query string, including the leading "?".
+func (o LogOptions) query() string {
+	query := url.Values{}
+	if o.ServiceName != "" {
+		query.Set("service_name", o.ServiceName)
+	}
+	if o.DeploymentID != "" {
+		query.Set("deployment_id", o.DeploymentID)
+	}
+	if o.Source != "" {
+		query.Set("source", o.Source)
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// ListLogs fetches recent logs for an app's latest deployment, or the given
+// deployment, newest first.
+func (c *Client) ListLogs(ctx context.Context, appID string, opts LogOptions) ([]Log, error) {
+	var resp struct {
+		Logs []Log `json:"logs"`
+	}
+	err := c.Get(ctx, "/v1/apps/"+url.PathEscape(appID)+"/logs"+opts.query(), &resp)
+	if resp.Logs == nil {
+		resp.Logs = []Log{}
+	}
+	return resp.Logs, err
+}
+
+// LogStreamEvent is a single server-sent event from the log stream. Log is
+// set for "log" events; other events (connected, new_deployment,
+// deployment_status, ping) carry their payload in Data.
+type LogStreamEvent struct {
+	Event string
+	Data  json.RawMessage
+	Log   *Log
+}
+
+// StreamLogs follows an app's logs until ctx is cancelled, the server closes
+// the stream or fn returns an error. The stream is not subject to the
+// client's request timeout.
+func (c *Client) StreamLogs(ctx context.Context, appID string, opts LogOptions, fn func(LogStreamEvent) error) error {
+	path := "/v1/apps/" + url.PathEscape(appID) + "/logs/stream" + opts.query()
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.orgID != "" {
+		req.Header.Set("X-Org-ID", c.orgID)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Streams stay open indefinitely, so reuse the transport without the timeout
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	err = readSSE(resp.Body, func(event string, data []byte) error {
+		ev := LogStreamEvent{Event: event, Data: data}
+		if event == "log" {
+			var entry Log
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("decoding log event: %w", err)
+			}
+			ev.Log = &entry
+		}
+		return fn(ev)
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readSSE parses a text/event-stream body, calling fn for each event with its
+// name (default "message") and data lines joined by newlines.
+func readSSE(r io.Reader, fn func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	event := ""
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				if err := fn(event, []byte(strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment line
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamLogs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/apps/my-app/logs/stream" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("service_name"); got != "web" {
+			t.Errorf("service_name = %q, want web", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: connected\ndata: {\"app_id\":\"my-app\"}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: log\ndata: {\"level\":\"info\",\"message\":\"listening on :8080\"}\n\n")
+		fmt.Fprint(w, "event: deployment_status\ndata: {\"status\":\"running\"}\n\n")
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL).WithToken("tok")
+	var events []LogStreamEvent
+	err := client.StreamLogs(context.Background(), "my-app", LogOptions{ServiceName: "web"}, func(ev LogStreamEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamLogs: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	if events[0].Event != "connected" || events[0].Log != nil {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Log == nil || events[1].Log.Message != "listening on :8080" {
+		t.Errorf("log event not decoded: %+v", events[1])
+	}
+	if events[2].Event != "deployment_status" || string(events[2].Data) != `{"status":"running"}` {
+		t.Errorf("unexpected status event: %+v", events[2])
+	}
+}
+
+func TestStreamLogs_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"not_found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL).StreamLogs(context.Background(), "missing", LogOptions{}, func(LogStreamEvent) error {
+		t.Error("no events expected")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error for a 404 response")
+	}
+}
@@ -0,0 +1,221 @@
+// Package assets serves the web UI's static assets. The assets are embedded
+// in the binary, so deploying the web UI doesn't need a web/assets directory
+// next to it; css/output.css must be generated before building.
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+//go:embed css js
+var embedded embed.FS
+
+// Set is a collection of static assets served under /assets/. Assets of an
+// embedded set are also served under a name carrying a hash of their
+// content, which browsers may cache forever, and are compressed ahead of time.
+type Set struct {
+	// files holds assets by name, hashed holds them by hashed name
+	files  map[string]*file
+	hashed map[string]*file
+
+	// dir serves the assets of a directory set
+	dir     http.Handler
+	version string
+}
+
+type file struct {
+	hashedName  string
+	contentType string
+	etag        string
+	data        []byte
+	gzip        []byte
+	brotli      []byte
+}
+
+// Open returns the assets of dir, or the embedded ones when dir is empty.
+func Open(dir string) (*Set, error) {
+	if dir == "" {
+		return Embedded()
+	}
+	return Dir(dir), nil
+}
+
+// Embedded returns the set of assets compiled into the binary.
+func Embedded() (*Set, error) {
+	return Load(embedded)
+}
+
+// Load returns the set of assets in fsys, hashing and compressing every file.
+func Load(fsys fs.FS) (*Set, error) {
+	s := &Set{files: make(map[string]*file), hashed: make(map[string]*file)}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])[:12]
+		ext := path.Ext(name)
+		f := &file{
+			hashedName:  strings.TrimSuffix(name, ext) + "." + hash + ext,
+			contentType: mime.TypeByExtension(ext),
+			etag:        `"` + hash + `"`,
+			data:        data,
+		}
+		if f.contentType == "" {
+			f.contentType = http.DetectContentType(data)
+		}
+		if compressible(f.contentType) {
+			if f.gzip, err = compress(data, func(w *bytes.Buffer) (io.WriteCloser, error) {
+				return gzip.NewWriterLevel(w, gzip.BestCompression)
+			}); err != nil {
+				return fmt.Errorf("compressing %s: %w", name, err)
+			}
+			if f.brotli, err = compress(data, func(w *bytes.Buffer) (io.WriteCloser, error) {
+				return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+			}); err != nil {
+				return fmt.Errorf("compressing %s: %w", name, err)
+			}
+			if len(f.gzip) >= len(data) {
+				f.gzip, f.brotli = nil, nil
+			}
+		}
+		s.files[name] = f
+		s.hashed[f.hashedName] = f
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading assets: %w", err)
+	}
+	return s, nil
+}
+
+// Dir returns the set of assets in a directory. Files are read on every
+// request, so changes show without a restart; for development.
+func Dir(dir string) *Set {
+	return &Set{
+		dir:     http.FileServer(http.Dir(dir)),
+		version: fmt.Sprintf("%d", time.Now().Unix()),
+	}
+}
+
+// URL returns the path an asset is served at, such as js/dialog.min.js.
+func (s *Set) URL(name string) string {
+	if s.dir != nil {
+		return "/assets/" + name + "?v=" + s.version
+	}
+	if f, ok := s.files[name]; ok {
+		return "/assets/" + f.hashedName
+	}
+	return "/assets/" + name
+}
+
+// ServeHTTP serves an asset; the request path is its name or hashed name.
+func (s *Set) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.dir != nil {
+		s.dir.ServeHTTP(w, r)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	f, immutable := s.hashed[name], true
+	if f == nil {
+		f, immutable = s.files[name], false
+	}
+	if f == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h := w.Header()
+	if immutable {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+	h.Set("Content-Type", f.contentType)
+
+	data, etag := f.data, f.etag
+	if f.gzip != nil {
+		h.Add("Vary", "Accept-Encoding")
+		switch {
+		case acceptsEncoding(r, "br"):
+			h.Set("Content-Encoding", "br")
+			data, etag = f.brotli, strings.TrimSuffix(etag, `"`)+`-br"`
+		case acceptsEncoding(r, "gzip"):
+			h.Set("Content-Encoding", "gzip")
+			data, etag = f.gzip, strings.TrimSuffix(etag, `"`)+`-gz"`
+		}
+	}
+	h.Set("ETag", etag)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+var current atomic.Pointer[Set]
+
+// Use makes s the set URL resolves asset names with.
+func Use(s *Set) {
+	current.Store(s)
+}
+
+// URL returns the path an asset is served at by the set in use.
+func URL(name string) string {
+	if s := current.Load(); s != nil {
+		return s.URL(name)
+	}
+	return "/assets/" + name
+}
+
+func compress(data []byte, newWriter func(*bytes.Buffer) (io.WriteCloser, error)) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := newWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func compressible(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "javascript") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "svg")
+}
+
+// acceptsEncoding reports whether the request's Accept-Encoding allows
+// encoding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(name) != encoding {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSet(t *testing.T) {
+	script := strings.Repeat("console.log('narvana');\n", 200)
+	s, err := Load(fstest.MapFS{
+		"js/app.js":    {Data: []byte(script)},
+		"img/logo.png": {Data: []byte{0x89, 'P', 'N', 'G'}},
+	})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	url := s.URL("js/app.js")
+	if !strings.HasPrefix(url, "/assets/js/app.") || !strings.HasSuffix(url, ".js") || url == "/assets/js/app.js" {
+		t.Fatalf("URL = %q, want a hashed name", url)
+	}
+	if got := s.URL("js/missing.js"); got != "/assets/js/missing.js" {
+		t.Errorf("URL of unknown asset = %q", got)
+	}
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		http.StripPrefix("/assets", s).ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("hashed name is immutable", func(t *testing.T) {
+		w := get(url, "")
+		if w.Code != http.StatusOK || w.Body.String() != script {
+			t.Fatalf("status %d, body of %d bytes", w.Code, w.Body.Len())
+		}
+		if cc := w.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+			t.Errorf("Cache-Control = %q", cc)
+		}
+		if ce := w.Header().Get("Content-Encoding"); ce != "" {
+			t.Errorf("Content-Encoding = %q without Accept-Encoding", ce)
+		}
+	})
+
+	t.Run("plain name is revalidated", func(t *testing.T) {
+		w := get("/assets/js/app.js", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d", w.Code)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+			t.Errorf("Cache-Control = %q", cc)
+		}
+	})
+
+	t.Run("negotiates encoding", func(t *testing.T) {
+		for accept, want := range map[string]string{
+			"gzip, deflate, br": "br",
+			"gzip":              "gzip",
+			"br;q=0, gzip":      "gzip",
+			"identity":          "",
+		} {
+			w := get(url, accept)
+			if ce := w.Header().Get("Content-Encoding"); ce != want {
+				t.Errorf("Accept-Encoding %q: Content-Encoding = %q, want %q", accept, ce, want)
+			}
+			if want != "" && w.Body.Len() >= len(script) {
+				t.Errorf("Accept-Encoding %q: body not compressed", accept)
+			}
+		}
+	})
+
+	t.Run("binary assets are not compressed", func(t *testing.T) {
+		w := get("/assets/img/logo.png", "br")
+		if ce := w.Header().Get("Content-Encoding"); ce != "" {
+			t.Errorf("Content-Encoding = %q", ce)
+		}
+	})
+
+	t.Run("etag revalidation", func(t *testing.T) {
+		etag := get(url, "br").Header().Get("ETag")
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set("Accept-Encoding", "br")
+		r.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		http.StripPrefix("/assets", s).ServeHTTP(w, r)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("status %d, want 304", w.Code)
+		}
+	})
+
+	t.Run("unknown asset", func(t *testing.T) {
+		if w := get("/assets/js/missing.js", ""); w.Code != http.StatusNotFound {
+			t.Errorf("status %d", w.Code)
+		}
+	})
+}
+
+func TestEmbedded(t *testing.T) {
+	s, err := Embedded()
+	if err != nil {
+		t.Fatalf("Embedded: %v", err)
+	}
+	if url := s.URL("js/dialog.min.js"); url == "/assets/js/dialog.min.js" {
+		t.Errorf("js/dialog.min.js is not embedded")
+	}
+}
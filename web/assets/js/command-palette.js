@@ -0,0 +1,239 @@
+// Command palette for Narvana Control Plane
+// Ctrl+K / Cmd+K opens a searchable list of the commands the server says the
+// current user can run on this page; "g" sequences such as "g a" run
+// navigation shortcuts. Authorization stays on the server: the palette only
+// offers what /api/commands returns.
+
+(function () {
+    'use strict';
+
+    let commands = null;
+    let loading = null;
+    let palette = null;
+    let input = null;
+    let list = null;
+    let visible = [];
+    let active = 0;
+    let returnFocus = null;
+    let pendingPrefix = '';
+    let prefixTimer = null;
+
+    function loadCommands() {
+        if (commands) return Promise.resolve(commands);
+        if (loading) return loading;
+        loading = fetch('/api/commands?path=' + encodeURIComponent(window.location.pathname), {
+            headers: { 'Accept': 'application/json' },
+            credentials: 'same-origin'
+        })
+            .then(function (resp) {
+                if (!resp.ok) throw new Error('HTTP ' + resp.status);
+                return resp.json();
+            })
+            .then(function (data) {
+                commands = data || [];
+                return commands;
+            })
+            .catch(function (err) {
+                console.warn('Failed to load commands:', err);
+                loading = null;
+                return [];
+            });
+        return loading;
+    }
+
+    function build() {
+        palette = document.createElement('div');
+        palette.className = 'fixed inset-0 z-50 hidden items-start justify-center bg-black/50 pt-[15vh]';
+        palette.setAttribute('data-command-palette', '');
+        palette.innerHTML =
+            '<div role="dialog" aria-modal="true" aria-label="Command palette" class="w-full max-w-lg overflow-hidden rounded-lg border bg-popover text-popover-foreground shadow-lg">' +
+            '<input type="text" role="combobox" aria-expanded="true" aria-controls="command-palette-list" aria-autocomplete="list" ' +
+            'placeholder="Type a command or search..." autocomplete="off" spellcheck="false" ' +
+            'class="w-full border-b bg-transparent px-4 py-3 text-sm outline-none placeholder:text-muted-foreground"/>' +
+            '<ul id="command-palette-list" role="listbox" aria-label="Commands" class="max-h-80 overflow-y-auto p-1"></ul>' +
+            '<div class="border-t px-4 py-2 text-xs text-muted-foreground">' +
+            '<kbd class="rounded border px-1">↑</kbd> <kbd class="rounded border px-1">↓</kbd> to move, ' +
+            '<kbd class="rounded border px-1">Enter</kbd> to run, <kbd class="rounded border px-1">Esc</kbd> to close</div>' +
+            '</div>';
+        document.body.appendChild(palette);
+
+        input = palette.querySelector('input');
+        list = palette.querySelector('ul');
+
+        input.addEventListener('input', function () {
+            active = 0;
+            render();
+        });
+        input.addEventListener('keydown', function (e) {
+            if (e.key === 'ArrowDown') {
+                e.preventDefault();
+                move(1);
+            } else if (e.key === 'ArrowUp') {
+                e.preventDefault();
+                move(-1);
+            } else if (e.key === 'Enter') {
+                e.preventDefault();
+                if (visible[active]) run(visible[active]);
+            } else if (e.key === 'Escape') {
+                e.preventDefault();
+                close();
+            } else if (e.key === 'Tab') {
+                // Keep focus inside the dialog
+                e.preventDefault();
+            }
+        });
+        palette.addEventListener('mousedown', function (e) {
+            if (e.target === palette) close();
+        });
+        list.addEventListener('click', function (e) {
+            const item = e.target.closest('[role="option"]');
+            if (item) run(visible[Number(item.dataset.index)]);
+        });
+    }
+
+    function matches(cmd, words) {
+        const text = (cmd.title + ' ' + cmd.section + ' ' + cmd.id).toLowerCase();
+        return words.every(function (w) { return text.indexOf(w) !== -1; });
+    }
+
+    function render() {
+        const words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+        visible = (commands || []).filter(function (cmd) { return matches(cmd, words); });
+        if (active >= visible.length) active = Math.max(visible.length - 1, 0);
+
+        list.innerHTML = '';
+        if (visible.length === 0) {
+            const empty = document.createElement('li');
+            empty.className = 'px-3 py-6 text-center text-sm text-muted-foreground';
+            empty.textContent = commands ? 'No matching commands' : 'Loading...';
+            list.appendChild(empty);
+            input.removeAttribute('aria-activedescendant');
+            return;
+        }
+
+        let section = null;
+        visible.forEach(function (cmd, i) {
+            if (cmd.section !== section) {
+                section = cmd.section;
+                const heading = document.createElement('li');
+                heading.setAttribute('role', 'presentation');
+                heading.className = 'px-3 pt-2 pb-1 text-xs font-medium text-muted-foreground';
+                heading.textContent = section;
+                list.appendChild(heading);
+            }
+            const item = document.createElement('li');
+            item.id = 'command-palette-option-' + i;
+            item.dataset.index = String(i);
+            item.setAttribute('role', 'option');
+            item.setAttribute('aria-selected', i === active ? 'true' : 'false');
+            item.className = 'flex cursor-pointer items-center justify-between rounded-md px-3 py-2 text-sm ' +
+                (i === active ? 'bg-accent text-accent-foreground' : 'hover:bg-accent/50');
+
+            const title = document.createElement('span');
+            title.textContent = cmd.title;
+            item.appendChild(title);
+            if (cmd.shortcut) {
+                const kbd = document.createElement('kbd');
+                kbd.className = 'rounded border px-1.5 text-xs text-muted-foreground';
+                kbd.textContent = cmd.shortcut;
+                item.appendChild(kbd);
+            }
+            list.appendChild(item);
+        });
+
+        input.setAttribute('aria-activedescendant', 'command-palette-option-' + active);
+        const current = document.getElementById('command-palette-option-' + active);
+        if (current) current.scrollIntoView({ block: 'nearest' });
+    }
+
+    function move(delta) {
+        if (visible.length === 0) return;
+        active = (active + delta + visible.length) % visible.length;
+        render();
+    }
+
+    function open() {
+        if (!palette) build();
+        returnFocus = document.activeElement;
+        input.value = '';
+        active = 0;
+        palette.classList.remove('hidden');
+        palette.classList.add('flex');
+        input.focus();
+        render();
+        loadCommands().then(render);
+    }
+
+    function close() {
+        if (!palette) return;
+        palette.classList.add('hidden');
+        palette.classList.remove('flex');
+        if (returnFocus && typeof returnFocus.focus === 'function') returnFocus.focus();
+    }
+
+    function isOpen() {
+        return palette && !palette.classList.contains('hidden');
+    }
+
+    function run(cmd) {
+        if (!cmd) return;
+        if (cmd.confirm && !window.confirm(cmd.confirm)) return;
+        close();
+        if (cmd.method === 'POST') {
+            const form = document.createElement('form');
+            form.method = 'POST';
+            form.action = cmd.path;
+            document.body.appendChild(form);
+            // submit() skips submit listeners, so add the CSRF token here
+            if (window.NarvanaCSRF) window.NarvanaCSRF.addField(form);
+            form.submit();
+            return;
+        }
+        window.location.href = cmd.path;
+    }
+
+    function isTyping(target) {
+        if (!target) return false;
+        const tag = target.tagName;
+        return tag === 'INPUT' || tag === 'TEXTAREA' || tag === 'SELECT' || target.isContentEditable;
+    }
+
+    document.addEventListener('keydown', function (e) {
+        if ((e.metaKey || e.ctrlKey) && !e.altKey && e.key.toLowerCase() === 'k') {
+            e.preventDefault();
+            if (isOpen()) close(); else open();
+            return;
+        }
+        if (isOpen() || e.metaKey || e.ctrlKey || e.altKey || isTyping(e.target)) return;
+
+        // Two-key shortcuts such as "g a", and "?" to open the palette
+        if (e.key === '?') {
+            e.preventDefault();
+            open();
+            return;
+        }
+        if (!pendingPrefix) {
+            if (e.key === 'g') {
+                pendingPrefix = 'g';
+                clearTimeout(prefixTimer);
+                prefixTimer = setTimeout(function () { pendingPrefix = ''; }, 1500);
+                loadCommands();
+            }
+            return;
+        }
+        const shortcut = pendingPrefix + ' ' + e.key;
+        pendingPrefix = '';
+        clearTimeout(prefixTimer);
+        loadCommands().then(function (cmds) {
+            const cmd = cmds.find(function (c) { return c.shortcut === shortcut; });
+            if (cmd) run(cmd);
+        });
+    });
+
+    document.addEventListener('click', function (e) {
+        if (e.target.closest('[data-command-palette-trigger]')) {
+            e.preventDefault();
+            open();
+        }
+    });
+})();
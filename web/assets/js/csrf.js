@@ -0,0 +1,52 @@
+// CSRF protection for Narvana Control Plane
+// The server rejects unsafe requests that don't repeat the page's CSRF token.
+// Forms rendered by templates carry it in a hidden field; this script adds it
+// to same-origin fetch calls and to forms built by scripts.
+
+(function () {
+    'use strict';
+
+    const meta = document.querySelector('meta[name="csrf-token"]');
+    const token = meta ? meta.getAttribute('content') : '';
+    if (!token) return;
+
+    const safeMethods = ['GET', 'HEAD', 'OPTIONS', 'TRACE'];
+
+    function sameOrigin(url) {
+        try {
+            return new URL(url, window.location.href).origin === window.location.origin;
+        } catch (e) {
+            return false;
+        }
+    }
+
+    const originalFetch = window.fetch;
+    window.fetch = function (input, init) {
+        const request = input instanceof Request ? input : null;
+        const method = ((init && init.method) || (request && request.method) || 'GET').toUpperCase();
+        const url = request ? request.url : String(input);
+        if (safeMethods.indexOf(method) !== -1 || !sameOrigin(url)) {
+            return originalFetch.call(this, input, init);
+        }
+        const headers = new Headers((init && init.headers) || (request && request.headers) || undefined);
+        headers.set('X-CSRF-Token', token);
+        return originalFetch.call(this, input, Object.assign({}, init, { headers: headers }));
+    };
+
+    // addField adds the token to a form posting to this site, if missing
+    function addField(form) {
+        if ((form.getAttribute('method') || 'GET').toUpperCase() !== 'POST' || !sameOrigin(form.action)) return;
+        if (form.querySelector('input[name="csrf_token"]')) return;
+        const input = document.createElement('input');
+        input.type = 'hidden';
+        input.name = 'csrf_token';
+        input.value = token;
+        form.appendChild(input);
+    }
+
+    document.addEventListener('submit', function (e) {
+        if (e.target instanceof HTMLFormElement) addField(e.target);
+    }, true);
+
+    window.NarvanaCSRF = { token: token, addField: addField };
+})();
@@ -0,0 +1,127 @@
+// Live updates for Narvana Control Plane
+// Pages mark the parts of them showing app, service, deployment or build
+// state with data-live-region (and a unique id). While such a page is open it
+// subscribes to /api/events and, when something it shows changes, fetches
+// the page again and swaps in the new regions, so statuses update without a
+// reload. data-live-kinds (a comma-separated list of kinds) and
+// data-live-app narrow which events refresh a region.
+
+(function () {
+    'use strict';
+
+    const REFRESH_DELAY = 500;
+
+    let source = null;
+    let refreshTimer = null;
+    let refreshing = false;
+    let pending = false;
+
+    function regions() {
+        return Array.prototype.slice.call(document.querySelectorAll('[data-live-region][id]'));
+    }
+
+    function kindsOf(el) {
+        const kinds = el.getAttribute('data-live-kinds');
+        return kinds ? kinds.split(',').map(function (k) { return k.trim(); }) : null;
+    }
+
+    function matches(el, evt) {
+        if (evt.kind === 'reset') return true;
+        const kinds = kindsOf(el);
+        if (kinds && kinds.indexOf(evt.kind) === -1) return false;
+        const app = el.getAttribute('data-live-app');
+        return !app || app === evt.app_id;
+    }
+
+    function scheduleRefresh() {
+        if (document.hidden) {
+            pending = true;
+            return;
+        }
+        clearTimeout(refreshTimer);
+        refreshTimer = setTimeout(refresh, REFRESH_DELAY);
+    }
+
+    function refresh() {
+        if (refreshing) {
+            pending = true;
+            return;
+        }
+        refreshing = true;
+        pending = false;
+        fetch(window.location.href, {
+            headers: { 'Accept': 'text/html' },
+            credentials: 'same-origin'
+        })
+            .then(function (resp) {
+                if (!resp.ok) throw new Error('HTTP ' + resp.status);
+                return resp.text();
+            })
+            .then(function (html) {
+                const doc = new DOMParser().parseFromString(html, 'text/html');
+                regions().forEach(function (el) {
+                    // Leave regions being edited alone
+                    if (el.contains(document.activeElement) && document.activeElement !== document.body) return;
+                    const fresh = doc.getElementById(el.id);
+                    if (fresh) el.replaceWith(document.importNode(fresh, true));
+                });
+                document.dispatchEvent(new CustomEvent('narvana:live-refresh'));
+            })
+            .catch(function (err) {
+                console.warn('Failed to refresh live regions:', err);
+            })
+            .finally(function () {
+                refreshing = false;
+                if (pending) scheduleRefresh();
+            });
+    }
+
+    function onEvent(e) {
+        let evt;
+        try {
+            evt = JSON.parse(e.data);
+        } catch (err) {
+            return;
+        }
+        document.dispatchEvent(new CustomEvent('narvana:event', { detail: evt }));
+        if (regions().some(function (el) { return matches(el, evt); })) {
+            scheduleRefresh();
+        }
+    }
+
+    function connect() {
+        const els = regions();
+        if (els.length === 0 || !window.EventSource) return;
+
+        // Narrow the stream to one app when every region is about it
+        let url = '/api/events';
+        const apps = els.map(function (el) { return el.getAttribute('data-live-app'); });
+        if (apps[0] && apps.every(function (a) { return a === apps[0]; })) {
+            url += '?app_id=' + encodeURIComponent(apps[0]);
+        }
+
+        // EventSource reconnects on its own, resuming with Last-Event-ID
+        source = new EventSource(url);
+        ['app', 'service', 'deployment', 'build'].forEach(function (kind) {
+            ['created', 'updated', 'deleted'].forEach(function (action) {
+                source.addEventListener(kind + '.' + action, onEvent);
+            });
+        });
+        source.addEventListener('reset', function () {
+            onEvent({ data: '{"kind":"reset"}' });
+        });
+    }
+
+    document.addEventListener('visibilitychange', function () {
+        if (!document.hidden && pending) scheduleRefresh();
+    });
+    window.addEventListener('pagehide', function () {
+        if (source) source.close();
+    });
+
+    if (document.readyState === 'loading') {
+        document.addEventListener('DOMContentLoaded', connect);
+    } else {
+        connect();
+    }
+})();
@@ -20,6 +20,7 @@
     // Initialize log streaming when the page loads
     document.addEventListener('DOMContentLoaded', function () {
         initLogStream();
+        initBuildLogStream();
         initAutoRefresh();
     });
 
@@ -142,6 +143,56 @@
         };
     }
 
+    // Build output streaming via SSE. The stream replays the output persisted
+    // so far and ends with a complete event once the build finished; the
+    // browser resumes an interrupted stream through Last-Event-ID.
+    function initBuildLogStream() {
+        const container = document.getElementById('build-logs');
+        if (!container) return;
+
+        const buildId = container.dataset.buildId;
+        const initialStatus = container.dataset.buildStatus;
+        const output = document.getElementById('build-logs-output');
+        const empty = document.getElementById('build-logs-empty');
+        const scroller = document.getElementById('logs-container');
+        if (!buildId || !output) return;
+
+        const source = new EventSource(`/api/builds/${encodeURIComponent(buildId)}/logs/stream`);
+
+        source.addEventListener('log', function (event) {
+            try {
+                const chunk = JSON.parse(event.data);
+                if (empty) empty.classList.add('hidden');
+                const atBottom = !scroller || scroller.scrollHeight - scroller.scrollTop - scroller.clientHeight < 40;
+                output.appendChild(document.createTextNode(chunk.content));
+                if (scroller && atBottom) {
+                    scroller.scrollTop = scroller.scrollHeight;
+                }
+            } catch (e) {
+                console.error('Failed to parse build log event:', e);
+            }
+        });
+
+        source.addEventListener('complete', function (event) {
+            source.close();
+            try {
+                const data = JSON.parse(event.data);
+                // Reload once so the status and duration reflect the finished build
+                if (data.status !== initialStatus) {
+                    setTimeout(function () {
+                        window.location.reload();
+                    }, 1000);
+                }
+            } catch (e) {
+                console.error('Failed to parse build complete event:', e);
+            }
+        });
+
+        window.addEventListener('beforeunload', function () {
+            source.close();
+        });
+    }
+
     // Exponential backoff reconnection - Requirements: 8.6
     function scheduleReconnect(streamUrl, logContainer) {
         if (isPaused) return;
@@ -369,6 +420,7 @@
     // Expose functions for manual use
     window.NarvanaLogs = {
         initLogStream: initLogStream,
+        initBuildLogStream: initBuildLogStream,
         initAutoRefresh: initAutoRefresh,
         pause: function () {
             isPaused = true;
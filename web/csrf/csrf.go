@@ -0,0 +1,110 @@
+// Package csrf protects the web UI's form posts and AJAX calls from
+// cross-site request forgery with double-submit cookies: every browser gets a
+// random token in a cookie, and unsafe requests must repeat it in a form field
+// or header, which other sites can neither read nor set.
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+)
+
+const (
+	// CookieName is the cookie holding a browser's token.
+	CookieName = "csrf_token"
+	// FieldName is the form field forms repeat the token in.
+	FieldName = "csrf_token"
+	// HeaderName is the header AJAX calls repeat the token in.
+	HeaderName = "X-CSRF-Token"
+
+	// tokenBytes is the size of a token before encoding.
+	tokenBytes = 32
+)
+
+type tokenKey struct{}
+
+// Token returns the token of the request ctx belongs to, for templates to
+// put in forms. It is empty outside Protect.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
+
+// Protect returns middleware that gives browsers a token and rejects unsafe
+// requests that do not repeat it with 403 Forbidden. Requests exempt reports
+// true for, such as stream proxies, are passed through untouched.
+func Protect(exempt func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt != nil && exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var token string
+			if c, err := r.Cookie(CookieName); err == nil && validToken(c.Value) {
+				token = c.Value
+			} else {
+				token = newToken()
+				http.SetCookie(w, &http.Cookie{
+					Name:     CookieName,
+					Value:    token,
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+				// A browser without a token cannot have submitted it
+				if !safe(r.Method) {
+					forbid(w)
+					return
+				}
+			}
+
+			if !safe(r.Method) && !matches(token, submitted(r)) {
+				forbid(w)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, token)))
+		})
+	}
+}
+
+// safe reports whether method must not change state, per RFC 9110.
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// submitted returns the token a request repeats, from its header or form.
+func submitted(r *http.Request) string {
+	if token := r.Header.Get(HeaderName); token != "" {
+		return token
+	}
+	return r.PostFormValue(FieldName)
+}
+
+func matches(token, got string) bool {
+	return got != "" && subtle.ConstantTimeCompare([]byte(token), []byte(got)) == 1
+}
+
+func forbid(w http.ResponseWriter) {
+	http.Error(w, "Invalid or missing CSRF token. Reload the page and try again.", http.StatusForbidden)
+}
+
+func newToken() string {
+	b := make([]byte, tokenBytes)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// validToken reports whether a cookie holds a token Protect could have set.
+func validToken(token string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && len(b) == tokenBytes
+}
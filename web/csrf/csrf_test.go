@@ -0,0 +1,74 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProtect(t *testing.T) {
+	var seen string
+	h := Protect(func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/stream")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = Token(r.Context())
+	}))
+
+	// A first visit sets the token cookie and exposes the token to templates
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/apps", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: status %d", w.Code)
+	}
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == CookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || cookie.Value != seen || seen == "" {
+		t.Fatalf("cookie = %+v, template token %q", cookie, seen)
+	}
+
+	post := func(form url.Values, header string, withCookie bool) int {
+		r := httptest.NewRequest(http.MethodPost, "/apps/new", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			r.Header.Set(HeaderName, header)
+		}
+		if withCookie {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		header     string
+		withCookie bool
+		want       int
+	}{
+		{"form field", url.Values{FieldName: {cookie.Value}}, "", true, http.StatusOK},
+		{"header", nil, cookie.Value, true, http.StatusOK},
+		{"missing token", url.Values{"name": {"shop"}}, "", true, http.StatusForbidden},
+		{"wrong token", url.Values{FieldName: {newToken()}}, "", true, http.StatusForbidden},
+		{"no cookie", url.Values{FieldName: {cookie.Value}}, "", false, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if got := post(tt.form, tt.header, tt.withCookie); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Exempt requests pass untouched
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/logs/stream", nil))
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("exempt request got a cookie")
+	}
+}
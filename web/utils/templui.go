@@ -4,7 +4,6 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"crypto/rand"
 
@@ -61,7 +60,3 @@ func ToJSON(v any) string {
 	b, _ := json.Marshal(v)
 	return string(b)
 }
-
-// ScriptVersion is a timestamp generated at app start for cache busting.
-// Used in Script() templates to append ?v=<timestamp> to script URLs.
-var ScriptVersion = fmt.Sprintf("%d", time.Now().Unix())